@@ -17,6 +17,7 @@ type accumulator struct {
 	maker     MetricMaker
 	metrics   chan<- telegraf.Metric
 	precision time.Duration
+	now       func() time.Time
 }
 
 func NewAccumulator(
@@ -27,6 +28,7 @@ func NewAccumulator(
 		maker:     maker,
 		metrics:   metrics,
 		precision: time.Nanosecond,
+		now:       time.Now,
 	}
 	return &acc
 }
@@ -114,7 +116,7 @@ func (ac *accumulator) getTime(t []time.Time) time.Time {
 	if len(t) > 0 {
 		timestamp = t[0]
 	} else {
-		timestamp = time.Now()
+		timestamp = ac.now()
 	}
 	return timestamp.Round(ac.precision)
 }
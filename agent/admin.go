@@ -0,0 +1,175 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runtimeStats is the response body of the runtime endpoint
+type runtimeStats struct {
+	Goroutines     int     `json:"goroutines"`
+	HeapAlloc      uint64  `json:"heap_alloc_bytes"`
+	HeapInUse      uint64  `json:"heap_inuse_bytes"`
+	HeapObjects    uint64  `json:"heap_objects"`
+	Sys            uint64  `json:"sys_bytes"`
+	NumGC          uint32  `json:"num_gc"`
+	LastGCPause    float64 `json:"last_gc_pause_seconds"`
+	TotalGCPause   float64 `json:"total_gc_pause_seconds"`
+	GCCPUFraction  float64 `json:"gc_cpu_fraction"`
+	GCPercent      int     `json:"gc_percent"`
+	MemoryLimit    int64   `json:"memory_limit_bytes"`
+	GOMAXPROCS     int     `json:"gomaxprocs"`
+	NumCPU         int     `json:"num_cpu"`
+	Uptime         float64 `json:"uptime_seconds"`
+	RuntimeVersion string  `json:"go_version"`
+}
+
+// adminHandler serves the pprof profiles and execution traces below
+// "/debug/pprof/" and the runtime statistics at "/debug/runtime". The garbage
+// collector can be tuned by sending a "PUT" request to the runtime endpoint
+// with the "gc_percent" and/or "memory_limit" query parameters. All requests
+// require the configured admin token as bearer token.
+func (a *Agent) adminHandler(start time.Time) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/runtime", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if err := tuneRuntime(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(collectRuntimeStats(start)); err != nil {
+			log.Printf("E! [agent] Writing runtime statistics failed: %v", err)
+		}
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ok, err := a.Config.Agent.AdminToken.EqualTo([]byte(token))
+		if err != nil {
+			log.Printf("E! [agent] Checking admin token failed: %v", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (a *Agent) startAdminServer(start time.Time) (*http.Server, error) {
+	if a.Config.Agent.AdminToken.Empty() {
+		return nil, errors.New("'admin_token' required for admin endpoints")
+	}
+
+	listener, err := net.Listen("tcp", a.Config.Agent.AdminServiceAddress)
+	if err != nil {
+		return nil, fmt.Errorf("starting admin server failed: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           a.adminHandler(start),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("E! [agent] Serving admin endpoints failed: %v", err)
+		}
+	}()
+	log.Printf("I! [agent] Serving admin endpoints on %s", listener.Addr())
+
+	return server, nil
+}
+
+// tuneRuntime sets the garbage collector target percentage and the soft
+// memory limit given as query parameters
+func tuneRuntime(r *http.Request) error {
+	params := r.URL.Query()
+
+	var gcPercent, memoryLimit int64
+	var err error
+	if v := params.Get("gc_percent"); v != "" {
+		if gcPercent, err = strconv.ParseInt(v, 10, 32); err != nil {
+			return fmt.Errorf("invalid gc_percent: %w", err)
+		}
+	}
+	if v := params.Get("memory_limit"); v != "" {
+		if memoryLimit, err = strconv.ParseInt(v, 10, 64); err != nil || memoryLimit < 0 {
+			return fmt.Errorf("invalid memory_limit %q", v)
+		}
+	}
+
+	if params.Has("gc_percent") {
+		old := debug.SetGCPercent(int(gcPercent))
+		log.Printf("I! [agent] Changed GC percent from %d to %d", old, gcPercent)
+	}
+	if params.Has("memory_limit") {
+		old := debug.SetMemoryLimit(memoryLimit)
+		log.Printf("I! [agent] Changed memory limit from %d to %d bytes", old, memoryLimit)
+	}
+
+	return nil
+}
+
+func collectRuntimeStats(start time.Time) runtimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	samples := []metrics.Sample{{Name: "/gc/gogc:percent"}}
+	metrics.Read(samples)
+	var gcPercent int
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		gcPercent = int(samples[0].Value.Uint64())
+	}
+
+	// A negative value only queries the current limit
+	memoryLimit := debug.SetMemoryLimit(-1)
+
+	return runtimeStats{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAlloc:      m.HeapAlloc,
+		HeapInUse:      m.HeapInuse,
+		HeapObjects:    m.HeapObjects,
+		Sys:            m.Sys,
+		NumGC:          m.NumGC,
+		LastGCPause:    time.Duration(m.PauseNs[(m.NumGC+255)%256]).Seconds(),
+		TotalGCPause:   time.Duration(m.PauseTotalNs).Seconds(),
+		GCCPUFraction:  m.GCCPUFraction,
+		GCPercent:      gcPercent,
+		MemoryLimit:    memoryLimit,
+		GOMAXPROCS:     runtime.GOMAXPROCS(0),
+		NumCPU:         runtime.NumCPU(),
+		Uptime:         time.Since(start).Seconds(),
+		RuntimeVersion: runtime.Version(),
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fatih/color"
@@ -20,9 +21,16 @@ import (
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 )
 
+// drainRetryInterval is the time to wait before retrying a failed write while
+// draining the outputs on shutdown.
+var drainRetryInterval = time.Second
+
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// ready is set once all plugins are started
+	ready atomic.Bool
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -121,6 +129,30 @@ func (a *Agent) Run(ctx context.Context) error {
 		return err
 	}
 
+	pipelines, err := a.pipelines()
+	if err != nil {
+		return err
+	}
+
+	if a.Config.Agent.HealthServiceAddress != "" {
+		if a.Config.Agent.HealthMaxFailures <= 0 {
+			a.Config.Agent.HealthMaxFailures = 3
+		}
+		server, err := a.startHealthServer()
+		if err != nil {
+			return err
+		}
+		defer stopServer("health", server)
+	}
+
+	if a.Config.Agent.AdminServiceAddress != "" {
+		server, err := a.startAdminServer(time.Now())
+		if err != nil {
+			return err
+		}
+		defer stopServer("admin", server)
+	}
+
 	if a.Config.Persister != nil {
 		log.Printf("D! [agent] Initializing plugin states")
 		if err := a.initPersister(); err != nil {
@@ -136,75 +168,32 @@ func (a *Agent) Run(ctx context.Context) error {
 
 	startTime := time.Now()
 
-	log.Printf("D! [agent] Connecting outputs")
-	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
-	if err != nil {
-		return err
-	}
-
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		aggC := next
-		if len(a.Config.AggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
-			aggC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
-
-		next, au = a.startAggregators(aggC, next, a.Config.Aggregators)
-	}
-
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+	units := make([]*pipelineUnit, 0, len(pipelines))
+	for _, p := range pipelines {
+		u, err := a.startPipeline(ctx, p)
 		if err != nil {
+			for _, u := range units {
+				u.stop()
+			}
 			return err
 		}
+		units = append(units, u)
 	}
+	a.ready.Store(true)
 
-	iu, err := a.startInputs(next, a.Config.Inputs)
-	if err != nil {
-		return err
-	}
-
+	// Run the pipelines independently of each other
 	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.runOutputs(ou)
-	}()
-
-	if au != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(apu)
-		}()
-
+	for _, u := range units {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runAggregators(startTime, au)
+			a.runPipeline(u, startTime, func(iu *inputUnit) {
+				a.runInputs(ctx, startTime, iu)
+			})
 		}()
 	}
-
-	if pu != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(pu)
-		}()
-	}
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.runInputs(ctx, startTime, iu)
-	}()
-
 	wg.Wait()
+	a.ready.Store(false)
 
 	if a.Config.Persister != nil {
 		log.Printf("D! [agent] Persisting plugin states")
@@ -441,6 +430,14 @@ func (a *Agent) runInputs(
 		}(input)
 	}
 	defer stopTickers(tickers)
+
+	if interval := time.Duration(a.Config.Agent.InventoryInterval); interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.inventoryLoop(ctx, unit.dst, startTime, interval)
+		}()
+	}
 	wg.Wait()
 
 	log.Printf("D! [agent] Stopping service inputs")
@@ -691,7 +688,7 @@ func (a *Agent) runAggregators(
 
 	// Before calling Add, initialize the aggregation window.  This ensures
 	// that any metric created after start time will be aggregated.
-	for _, agg := range a.Config.Aggregators {
+	for _, agg := range unit.aggregators {
 		since, until := updateWindow(startTime, a.Config.Agent.RoundInterval, agg.Period())
 		agg.UpdateWindow(since, until)
 	}
@@ -702,7 +699,7 @@ func (a *Agent) runAggregators(
 		defer wg.Done()
 		for metric := range unit.src {
 			var dropOriginal bool
-			for _, agg := range a.Config.Aggregators {
+			for _, agg := range unit.aggregators {
 				if ok := agg.Add(metric); ok {
 					dropOriginal = true
 				}
@@ -717,7 +714,7 @@ func (a *Agent) runAggregators(
 		cancel()
 	}()
 
-	for _, agg := range a.Config.Aggregators {
+	for _, agg := range unit.aggregators {
 		wg.Add(1)
 		go func(agg *models.RunningAggregator) {
 			defer wg.Done()
@@ -861,9 +858,12 @@ func (a *Agent) runOutputs(
 		}(output)
 	}
 
+	// Metrics are only added to the active output of a failover group
+	groups := models.GroupOutputs(unit.outputs)
 	for metric := range unit.src {
-		for i, output := range unit.outputs {
-			if i == len(unit.outputs)-1 {
+		for i, group := range groups {
+			output := group.Active()
+			if i == len(groups)-1 {
 				output.AddMetricNoCopy(metric)
 			} else {
 				output.AddMetric(metric)
@@ -871,7 +871,11 @@ func (a *Agent) runOutputs(
 		}
 	}
 
-	log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
+	if timeout := time.Duration(a.Config.Agent.ShutdownDrainTimeout); timeout > 0 {
+		log.Printf("I! [agent] Hang on, draining cached metrics for up to %s before shutdown", timeout)
+	} else {
+		log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
+	}
 	cancel()
 	wg.Wait()
 
@@ -901,14 +905,14 @@ func (a *Agent) flushLoop(
 		// Favor shutdown over other methods.
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushShutdown(output, ticker))
 			return
 		default:
 		}
 
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushShutdown(output, ticker))
 			return
 		case <-ticker.Elapsed():
 			logError(a.flushOnce(output, ticker, output.Write))
@@ -940,6 +944,55 @@ func (*Agent) flushOnce(output *models.RunningOutput, ticker Ticker, writeFunc f
 	}
 }
 
+// flushShutdown flushes the output a last time on shutdown. With a drain
+// timeout configured, failed writes are retried until all buffered metrics are
+// written or the timeout elapsed.
+func (a *Agent) flushShutdown(output *models.RunningOutput, ticker Ticker) error {
+	timeout := time.Duration(a.Config.Agent.ShutdownDrainTimeout)
+	if timeout <= 0 {
+		return a.flushOnce(output, ticker, output.Write)
+	}
+
+	if n := drainOutput(output, timeout); n > 0 {
+		if output.Config.BufferStrategy == "disk" {
+			log.Printf("W! [agent] [%q] %d metrics not written within the drain timeout remain in the disk buffer",
+				output.LogName(), n)
+		} else {
+			log.Printf("W! [agent] [%q] abandoned %d metrics not written within the drain timeout",
+				output.LogName(), n)
+		}
+	}
+	return nil
+}
+
+// drainOutput writes the buffered metrics of the output batch by batch,
+// retrying failed writes until the buffer is empty or the timeout elapsed.
+// The timeout is checked between the writes so a write in progress is not
+// interrupted. Returns the number of metrics remaining in the buffer.
+func drainOutput(output *models.RunningOutput, timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+
+	// The first write also pushes the metrics of aggregating outputs
+	writeFunc := output.Write
+	for {
+		err := writeFunc()
+		if err != nil {
+			log.Printf("E! [agent] Error writing to %s: %v", output.LogName(), err)
+		}
+		output.LogBufferStatus()
+
+		n := output.BufferLength()
+		remaining := time.Until(deadline)
+		if n == 0 || remaining <= 0 {
+			return n
+		}
+		if err != nil {
+			time.Sleep(min(drainRetryInterval, remaining))
+		}
+		writeFunc = output.WriteBatch
+	}
+}
+
 // flushBatch runs the output's Write function once Unlike flushOnce the interval elapsing is not considered during these flushes.
 func (*Agent) flushBatch(output *models.RunningOutput, writeFunc func() error) error {
 	err := writeFunc()
@@ -997,65 +1050,38 @@ func (a *Agent) runTest(ctx context.Context, wait time.Duration, outputC chan<-
 		return err
 	}
 
-	startTime := time.Now()
-
-	next := outputC
+	pipelines, err := a.pipelines()
+	if err != nil {
+		return err
+	}
 
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		procC := next
-		if len(a.Config.AggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
-			var err error
-			procC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
+	startTime := time.Now()
 
-		next, au = a.startAggregators(procC, next, a.Config.Aggregators)
+	// Each pipeline writes to its own channel merged into the output channel
+	dsts := []chan<- telegraf.Metric{outputC}
+	if len(pipelines) > 1 {
+		dsts = mergeLanes(outputC, len(pipelines))
 	}
 
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		var err error
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+	units := make([]*pipelineUnit, 0, len(pipelines))
+	for i, p := range pipelines {
+		u, err := a.startTestPipeline(dsts[i], p)
 		if err != nil {
 			return err
 		}
+		units = append(units, u)
 	}
 
-	iu := a.testStartInputs(next, a.Config.Inputs)
-
 	var wg sync.WaitGroup
-	if au != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(apu)
-		}()
-
+	for _, u := range units {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runAggregators(startTime, au)
+			a.runPipeline(u, startTime, func(iu *inputUnit) {
+				a.testRunInputs(ctx, wait, iu)
+			})
 		}()
 	}
-
-	if pu != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(pu)
-		}()
-	}
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.testRunInputs(ctx, wait, iu)
-	}()
-
 	wg.Wait()
 
 	log.Printf("D! [agent] Stopped Successfully")
@@ -1102,73 +1128,42 @@ func (a *Agent) runOnce(ctx context.Context, wait time.Duration) error {
 		return err
 	}
 
-	startTime := time.Now()
-
-	log.Printf("D! [agent] Connecting outputs")
-	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
+	pipelines, err := a.pipelines()
 	if err != nil {
 		return err
 	}
 
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		procC := next
-		if len(a.Config.AggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
-			procC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
+	startTime := time.Now()
+
+	units := make([]*pipelineUnit, 0, len(pipelines))
+	for _, p := range pipelines {
+		log.Printf("D! [agent] Connecting outputs of %s", p.logName())
+		next, ou, err := a.startOutputs(ctx, p.outputs)
+		if err != nil {
+			for _, u := range units {
+				u.stop()
 			}
+			return err
 		}
 
-		next, au = a.startAggregators(procC, next, a.Config.Aggregators)
-	}
-
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+		u, err := a.startTestPipeline(next, p)
 		if err != nil {
 			return err
 		}
+		u.ou = ou
+		units = append(units, u)
 	}
 
-	iu := a.testStartInputs(next, a.Config.Inputs)
-
 	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.runOutputs(ou)
-	}()
-
-	if au != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(apu)
-		}()
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runAggregators(startTime, au)
-		}()
-	}
-
-	if pu != nil {
+	for _, u := range units {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(pu)
+			a.runPipeline(u, startTime, func(iu *inputUnit) {
+				a.testRunInputs(ctx, wait, iu)
+			})
 		}()
 	}
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.testRunInputs(ctx, wait, iu)
-	}()
-
 	wg.Wait()
 
 	log.Printf("D! [agent] Stopped Successfully")
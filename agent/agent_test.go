@@ -2,9 +2,18 @@ package agent
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -14,6 +23,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
 	_ "github.com/influxdata/telegraf/plugins/aggregators/all"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
@@ -118,6 +128,262 @@ func TestAgent_LoadOutput(t *testing.T) {
 	require.Len(t, a.Config.Outputs, 3)
 }
 
+func TestAgent_InventoryMetric(t *testing.T) {
+	cfg := []byte(`
+[global_tags]
+  dc = "us-east-1"
+
+[agent]
+  omit_hostname = true
+  inventory_interval = "1h"
+
+[[inputs.cpu]]
+[[inputs.cpu]]
+[[inputs.mem]]
+
+[[outputs.file]]
+`)
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+	require.Equal(t, config.Duration(time.Hour), c.Agent.InventoryInterval)
+	a := NewAgent(c)
+
+	hash := sha256.Sum256(cfg)
+	start := time.Unix(1700000000, 0)
+	now := start.Add(90 * time.Second)
+	m := a.inventoryMetric(start, now)
+	require.Equal(t, "telegraf_agent", m.Name())
+	require.Equal(t, map[string]string{"dc": "us-east-1"}, m.Tags())
+	require.Equal(t, now, m.Time())
+
+	fields := m.Fields()
+	require.Equal(t, hex.EncodeToString(hash[:]), fields["config_hash"])
+	require.Equal(t, "cpu (2x),mem", fields["inputs"])
+	require.Equal(t, "file", fields["outputs"])
+	require.Equal(t, "", fields["processors"])
+	require.Equal(t, runtime.GOOS, fields["os"])
+	require.Equal(t, runtime.GOARCH, fields["arch"])
+	require.Equal(t, int64(90), fields["uptime"])
+}
+
+func TestAgent_Health(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.HealthMaxFailures = 2
+	c.Agent.HealthBufferThreshold = 0.5
+
+	input := &failingInput{}
+	ri := models.NewRunningInput(input, &models.InputConfig{Name: "failing"})
+	c.Inputs = append(c.Inputs, ri)
+	ro := models.NewRunningOutput(&discardOutput{}, &models.OutputConfig{Name: "discard"}, 10, 10)
+	c.Outputs = append(c.Outputs, ro)
+	a := NewAgent(c)
+
+	server := httptest.NewServer(a.healthHandler())
+	defer server.Close()
+
+	check := func(path string) (int, healthReport) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var report healthReport
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		return resp.StatusCode, report
+	}
+
+	// The agent is not ready before the plugins are started
+	status, report := check("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, "not ready", report.Status)
+	a.ready.Store(true)
+	status, _ = check("/readyz")
+	require.Equal(t, http.StatusOK, status)
+
+	// A single failing gather cycle is tolerated
+	var acc testutil.Accumulator
+	input.err = errors.New("connection refused")
+	require.Error(t, ri.Gather(&acc))
+	status, report = check("/healthz")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "ok", report.Status)
+
+	// Consecutive failures and a filling buffer render the agent unhealthy
+	require.Error(t, ri.Gather(&acc))
+	for range 6 {
+		ro.AddMetric(testutil.TestMetric(1))
+	}
+	status, report = check("/healthz")
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, "unhealthy", report.Status)
+	require.Equal(t, []pluginHealth{
+		{Plugin: "inputs.failing", Reason: "2 consecutive gather failures"},
+		{Plugin: "outputs.discard", Reason: "buffer 60% full"},
+	}, report.Plugins)
+
+	// A successful gather cycle and write recover the plugins
+	input.err = nil
+	require.NoError(t, ri.Gather(&acc))
+	require.NoError(t, ro.Write())
+	status, report = check("/healthz")
+	require.Equal(t, http.StatusOK, status)
+	require.Empty(t, report.Plugins)
+}
+
+type failingInput struct {
+	err error
+}
+
+func (*failingInput) SampleConfig() string {
+	return ""
+}
+
+func (i *failingInput) Gather(telegraf.Accumulator) error {
+	return i.err
+}
+
+type discardOutput struct{}
+
+func (*discardOutput) SampleConfig() string {
+	return ""
+}
+
+func (*discardOutput) Connect() error {
+	return nil
+}
+
+func (*discardOutput) Close() error {
+	return nil
+}
+
+func (*discardOutput) Write([]telegraf.Metric) error {
+	return nil
+}
+
+func TestAgent_Admin(t *testing.T) {
+	cfg := []byte(`
+[agent]
+  admin_service_address = "localhost:0"
+  admin_token = "secret"
+`)
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+	a := NewAgent(c)
+
+	server := httptest.NewServer(a.adminHandler(time.Now()))
+	defer server.Close()
+
+	request := func(method, path, token string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Requests without valid token are rejected
+	resp := request(http.MethodGet, "/debug/runtime", "")
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = request(http.MethodGet, "/debug/pprof/", "wrong")
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = request(http.MethodGet, "/debug/pprof/", "secret")
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = request(http.MethodGet, "/debug/runtime", "secret")
+	var stats runtimeStats
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Positive(t, stats.Goroutines)
+	require.Positive(t, stats.HeapAlloc)
+	require.Equal(t, runtime.Version(), stats.RuntimeVersion)
+
+	// Tune the memory limit and restore the original value afterwards
+	defer debug.SetMemoryLimit(stats.MemoryLimit)
+	resp = request(http.MethodPut, "/debug/runtime?memory_limit=1073741824", "secret")
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int64(1073741824), stats.MemoryLimit)
+
+	resp = request(http.MethodPut, "/debug/runtime?memory_limit=-1", "secret")
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestAgent_PipelineLanes(t *testing.T) {
+	cfg := []byte(`
+[agent]
+  pipeline_lanes = 3
+
+[[inputs.cpu]]
+
+[[processors.override]]
+  [processors.override.tags]
+    lane = "true"
+
+[[aggregators.minmax]]
+`)
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+	a := NewAgent(c)
+
+	pipelines, err := a.pipelines()
+	require.NoError(t, err)
+	require.Len(t, pipelines, 1)
+
+	lanes, err := a.pipelineLanes(pipelines[0])
+	require.NoError(t, err)
+	require.Len(t, lanes, 3)
+	require.Same(t, c.Processors[0], lanes[0].processors[0])
+	require.Same(t, c.Aggregators[0], lanes[0].aggregators[0])
+	for _, lane := range lanes[1:] {
+		require.Len(t, lane.processors, 1)
+		require.Equal(t, "processors.override", lane.processors[0].LogName())
+		require.NotSame(t, c.Processors[0], lane.processors[0])
+		require.NotSame(t, c.Processors[0].Processor, lane.processors[0].Processor)
+
+		require.Len(t, lane.aggregators, 1)
+		require.Equal(t, "aggregators.minmax", lane.aggregators[0].LogName())
+		require.NotSame(t, c.Aggregators[0], lane.aggregators[0])
+	}
+}
+
+func TestShardMetricsPreservesSeriesOrder(t *testing.T) {
+	dst := make(chan telegraf.Metric, 1000)
+	src := shardMetrics(mergeLanes(dst, 4))
+
+	for i := range 500 {
+		src <- metric.New("test",
+			map[string]string{"series": strconv.Itoa(i % 10)},
+			map[string]interface{}{"value": i},
+			time.Unix(0, 0),
+		)
+	}
+	close(src)
+
+	// The destination is closed after all metrics are forwarded
+	last := make(map[string]int64)
+	var count int
+	for m := range dst {
+		series, _ := m.GetTag("series")
+		value, _ := m.GetField("value")
+		if prev, found := last[series]; found {
+			require.Greater(t, value.(int64), prev, "series %s out of order", series)
+		}
+		last[series] = value.(int64)
+		count++
+	}
+	require.Equal(t, 500, count)
+	require.Len(t, last, 10)
+}
+
 func TestWindow(t *testing.T) {
 	parse := func(s string) time.Time {
 		tm, err := time.Parse(time.RFC3339, s)
@@ -257,3 +523,192 @@ func collect(ctx context.Context, a *Agent, wait time.Duration) ([]telegraf.Metr
 	}
 	return received, nil
 }
+
+func TestAgent_Pipelines(t *testing.T) {
+	cfg := []byte(`
+[pipelines.logs]
+  flush_interval = "1m"
+
+[[inputs.cpu]]
+
+[[inputs.mem]]
+  pipeline = "logs"
+
+[[processors.override]]
+  pipeline = "logs"
+
+[[outputs.file]]
+
+[[outputs.file]]
+  pipeline = "logs"
+`)
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+	a := NewAgent(c)
+
+	pipelines, err := a.pipelines()
+	require.NoError(t, err)
+	require.Len(t, pipelines, 2)
+
+	require.Empty(t, pipelines[0].name)
+	require.Len(t, pipelines[0].inputs, 1)
+	require.Equal(t, "inputs.cpu", pipelines[0].inputs[0].LogName())
+	require.Empty(t, pipelines[0].processors)
+	require.Len(t, pipelines[0].outputs, 1)
+
+	require.Equal(t, "logs", pipelines[1].name)
+	require.Len(t, pipelines[1].inputs, 1)
+	require.Equal(t, "inputs.mem", pipelines[1].inputs[0].LogName())
+	require.Len(t, pipelines[1].processors, 1)
+	require.Len(t, pipelines[1].outputs, 1)
+	require.Equal(t, time.Minute, pipelines[1].outputs[0].Config.FlushInterval)
+}
+
+func TestAgent_PipelineWithoutOutputs(t *testing.T) {
+	cfg := []byte(`
+[pipelines.logs]
+
+[[inputs.mem]]
+  pipeline = "logs"
+
+[[outputs.file]]
+`)
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+	a := NewAgent(c)
+
+	_, err := a.pipelines()
+	require.ErrorContains(t, err, `pipeline "logs" has no outputs`)
+}
+
+type staticInput struct {
+	name string
+}
+
+func (*staticInput) SampleConfig() string {
+	return ""
+}
+
+func (i *staticInput) Gather(acc telegraf.Accumulator) error {
+	acc.AddFields(i.name, map[string]interface{}{"value": 42}, nil)
+	return nil
+}
+
+type recordingOutput struct {
+	sync.Mutex
+	metrics []telegraf.Metric
+}
+
+func (*recordingOutput) SampleConfig() string {
+	return ""
+}
+
+func (*recordingOutput) Connect() error {
+	return nil
+}
+
+func (*recordingOutput) Close() error {
+	return nil
+}
+
+func (o *recordingOutput) Write(metrics []telegraf.Metric) error {
+	o.Lock()
+	defer o.Unlock()
+	o.metrics = append(o.metrics, metrics...)
+	return nil
+}
+
+func (o *recordingOutput) names() []string {
+	o.Lock()
+	defer o.Unlock()
+	names := make([]string, 0, len(o.metrics))
+	for _, m := range o.metrics {
+		names = append(names, m.Name())
+	}
+	return names
+}
+
+func TestAgent_PipelinesIsolated(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.Interval = config.Duration(time.Second)
+	c.Pipelines["logs"] = &config.PipelineConfig{Name: "logs"}
+
+	for _, pipeline := range []string{"", "logs"} {
+		name := "metrics"
+		if pipeline != "" {
+			name = pipeline
+		}
+		ri := models.NewRunningInput(&staticInput{name: name}, &models.InputConfig{Name: "static", Pipeline: pipeline})
+		c.Inputs = append(c.Inputs, ri)
+	}
+
+	outputMetrics := &recordingOutput{}
+	outputLogs := &recordingOutput{}
+	c.Outputs = append(c.Outputs,
+		models.NewRunningOutput(outputMetrics, &models.OutputConfig{Name: "metrics"}, 1000, 10000),
+		models.NewRunningOutput(outputLogs, &models.OutputConfig{Name: "logs", Pipeline: "logs"}, 1000, 10000),
+	)
+
+	a := NewAgent(c)
+	require.NoError(t, a.Once(context.Background(), 0))
+
+	require.Equal(t, []string{"metrics"}, outputMetrics.names())
+	require.Equal(t, []string{"logs"}, outputLogs.names())
+}
+
+type failingOutput struct {
+	recordingOutput
+	failures int
+}
+
+func (o *failingOutput) Write(metrics []telegraf.Metric) error {
+	o.Lock()
+	if o.failures > 0 {
+		o.failures--
+		o.Unlock()
+		return errors.New("write failed")
+	}
+	o.Unlock()
+	return o.recordingOutput.Write(metrics)
+}
+
+func TestAgent_DrainOutput(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		timeout   time.Duration
+		written   int
+		abandoned int
+	}{
+		{
+			name:    "success",
+			timeout: time.Minute,
+			written: 25,
+		},
+		{
+			name:     "retry",
+			failures: 1,
+			timeout:  time.Minute,
+			written:  25,
+		},
+		{
+			name:      "timeout",
+			failures:  1000,
+			timeout:   100 * time.Millisecond,
+			abandoned: 25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &failingOutput{failures: tt.failures}
+			ro := models.NewRunningOutput(output, &models.OutputConfig{Name: "failing"}, 10, 100)
+			for i := 0; i < 25; i++ {
+				ro.AddMetric(metric.New("test", nil, map[string]interface{}{"value": i}, time.Unix(int64(i), 0)))
+			}
+
+			require.Equal(t, tt.abandoned, drainOutput(ro, tt.timeout))
+			require.Len(t, output.names(), tt.written)
+		})
+	}
+}
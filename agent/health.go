@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// pluginHealth describes an unhealthy plugin
+type pluginHealth struct {
+	Plugin string `json:"plugin"`
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// healthReport is the response body of the health endpoints
+type healthReport struct {
+	Status  string         `json:"status"`
+	Plugins []pluginHealth `json:"plugins,omitempty"`
+}
+
+// healthHandler serves the liveness endpoint "/healthz" reporting the health
+// of all plugins and the readiness endpoint "/readyz" reporting if all plugins
+// are started
+func (a *Agent) healthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		report := healthReport{Status: "ok", Plugins: a.unhealthyPlugins()}
+		if len(report.Plugins) > 0 {
+			report.Status = "unhealthy"
+		}
+		writeHealthReport(w, report)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		report := healthReport{Status: "ok"}
+		if !a.ready.Load() {
+			report.Status = "not ready"
+		}
+		writeHealthReport(w, report)
+	})
+	return mux
+}
+
+func (a *Agent) startHealthServer() (*http.Server, error) {
+	listener, err := net.Listen("tcp", a.Config.Agent.HealthServiceAddress)
+	if err != nil {
+		return nil, fmt.Errorf("starting health server failed: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           a.healthHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("E! [agent] Serving health endpoints failed: %v", err)
+		}
+	}()
+	log.Printf("I! [agent] Serving health endpoints on %s", listener.Addr())
+
+	return server, nil
+}
+
+// stopServer gracefully shuts down the given agent HTTP server
+func stopServer(name string, server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("E! [agent] Stopping %s server failed: %v", name, err)
+	}
+}
+
+func writeHealthReport(w http.ResponseWriter, report healthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("E! [agent] Writing health report failed: %v", err)
+	}
+}
+
+// unhealthyPlugins returns the inputs failing for the configured number of
+// consecutive gather cycles as well as the outputs failing for the configured
+// number of consecutive writes or exceeding the buffer threshold
+func (a *Agent) unhealthyPlugins() []pluginHealth {
+	maxFailures := a.Config.Agent.HealthMaxFailures
+	threshold := a.Config.Agent.HealthBufferThreshold
+
+	var unhealthy []pluginHealth
+	for _, input := range a.Config.Inputs {
+		if n := input.Failures(); maxFailures > 0 && n >= maxFailures {
+			unhealthy = append(unhealthy, pluginHealth{
+				Plugin: input.LogName(),
+				ID:     input.ID(),
+				Reason: fmt.Sprintf("%d consecutive gather failures", n),
+			})
+		}
+	}
+	for _, output := range a.Config.Outputs {
+		if n := output.Failures(); maxFailures > 0 && n >= maxFailures {
+			unhealthy = append(unhealthy, pluginHealth{
+				Plugin: output.LogName(),
+				ID:     output.ID(),
+				Reason: fmt.Sprintf("%d consecutive write failures", n),
+			})
+		}
+		if fullness := output.BufferFullness(); threshold > 0 && fullness >= threshold {
+			unhealthy = append(unhealthy, pluginHealth{
+				Plugin: output.LogName(),
+				ID:     output.ID(),
+				Reason: fmt.Sprintf("buffer %.0f%% full", 100*fullness),
+			})
+		}
+	}
+	return unhealthy
+}
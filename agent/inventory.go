@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// inventoryLoop periodically emits the agent inventory metric to dst until
+// the context is done
+func (a *Agent) inventoryLoop(ctx context.Context, dst chan<- telegraf.Metric, startTime time.Time, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case dst <- a.inventoryMetric(startTime, time.Now()):
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// inventoryMetric returns a metric describing the running agent, i.e. its
+// version, configuration and platform, to allow auditing a fleet of agents
+func (a *Agent) inventoryMetric(startTime, now time.Time) telegraf.Metric {
+	tags := make(map[string]string, len(a.Config.Tags))
+	for k, v := range a.Config.Tags {
+		tags[k] = v
+	}
+
+	fields := map[string]interface{}{
+		"version":     internal.Version,
+		"go_version":  strings.TrimPrefix(runtime.Version(), "go"),
+		"os":          runtime.GOOS,
+		"arch":        runtime.GOARCH,
+		"config_hash": a.Config.ConfigHash(),
+		"inputs":      strings.Join(a.Config.InputNames(), ","),
+		"processors":  strings.Join(a.Config.ProcessorNames(), ","),
+		"aggregators": strings.Join(a.Config.AggregatorNames(), ","),
+		"outputs":     strings.Join(a.Config.OutputNames(), ","),
+		"uptime":      int64(now.Sub(startTime).Seconds()),
+	}
+	if internal.Commit != "" {
+		fields["commit"] = internal.Commit
+	}
+
+	return metric.New("telegraf_agent", tags, fields, now)
+}
@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// pipelineLane is a set of processor and aggregator instances processing a
+// share of the series in parallel to the other lanes.
+//
+//                          ┌──────┐
+//                     ┌──▶ │ Lane │───┐
+//  ______    ┌─────┐  │    └──────┘   │    ┌───────┐     ______
+// ()_____)─▶ │ Hash│──┤    ┌──────┐   ├──▶ │ Merge │──▶ ()_____)
+//            └─────┘  └──▶ │ Lane │───┘    └───────┘
+//                          └──────┘
+
+type pipelineLane struct {
+	processors  models.RunningProcessors
+	aggregators []*models.RunningAggregator
+}
+
+// pipelineLanes returns the configured number of lanes for the pipeline where
+// the first lane uses the configured plugin instances and additional lanes use
+// new instances of the plugins.
+func (a *Agent) pipelineLanes(p *pipeline) ([]*pipelineLane, error) {
+	lanes := []*pipelineLane{{
+		processors:  p.processors,
+		aggregators: p.aggregators,
+	}}
+	if len(p.processors) == 0 && len(p.aggregators) == 0 {
+		return lanes, nil
+	}
+
+	for i := 1; i < a.Config.Agent.PipelineLanes; i++ {
+		processors, aggregators, err := a.Config.NewPipelineLane(p.processors, p.aggregators)
+		if err != nil {
+			return nil, err
+		}
+		for _, processor := range processors {
+			if err := processor.Init(); err != nil {
+				return nil, fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err)
+			}
+		}
+		for _, aggregator := range aggregators {
+			if err := aggregator.Init(); err != nil {
+				return nil, fmt.Errorf("could not initialize aggregator %s: %w", aggregator.LogName(), err)
+			}
+		}
+		lanes = append(lanes, &pipelineLane{processors: processors, aggregators: aggregators})
+	}
+
+	return lanes, nil
+}
+
+// shardMetrics distributes the metrics written to the returned channel onto
+// the lanes by series. All metrics of a series are processed by the same lane
+// to preserve their order. The lanes are closed when the returned channel is
+// closed.
+func shardMetrics(lanes []chan<- telegraf.Metric) chan<- telegraf.Metric {
+	src := make(chan telegraf.Metric, 100)
+	go func() {
+		n := uint64(len(lanes))
+		for m := range src {
+			lanes[m.HashID()%n] <- m
+		}
+		for _, lane := range lanes {
+			close(lane)
+		}
+	}()
+	return src
+}
+
+// mergeLanes returns n channels forwarding the metrics to the destination
+// channel. The destination is closed when all returned channels are closed.
+func mergeLanes(dst chan<- telegraf.Metric, n int) []chan<- telegraf.Metric {
+	var wg sync.WaitGroup
+	lanes := make([]chan<- telegraf.Metric, 0, n)
+	for range n {
+		lane := make(chan telegraf.Metric, 100)
+		lanes = append(lanes, lane)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range lane {
+				dst <- m
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(dst)
+	}()
+
+	return lanes
+}
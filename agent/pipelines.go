@@ -0,0 +1,262 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// pipeline is a group of inputs, processors, aggregators and outputs running
+// independently of the other pipelines. Metrics of the inputs only pass the
+// processors and aggregators of the same pipeline and are only written to the
+// outputs of that pipeline.
+//
+//  ┌───────────────────────────────────────────────────────┐
+//  │ Inputs ──▶ Processors ──▶ Aggregators ──▶ Outputs     │
+//  └───────────────────────────────────────────────────────┘
+//  ┌───────────────────────────────────────────────────────┐
+//  │ Inputs ──▶ Processors ──▶ Aggregators ──▶ Outputs     │
+//  └───────────────────────────────────────────────────────┘
+
+type pipeline struct {
+	name          string
+	inputs        []*models.RunningInput
+	processors    models.RunningProcessors
+	aggProcessors models.RunningProcessors
+	aggregators   []*models.RunningAggregator
+	outputs       []*models.RunningOutput
+}
+
+func (p *pipeline) logName() string {
+	if p.name == "" {
+		return "default pipeline"
+	}
+	return fmt.Sprintf("pipeline %q", p.name)
+}
+
+// pipelineUnit contains the units of a started pipeline
+type pipelineUnit struct {
+	iu  *inputUnit
+	pus [][]*processorUnit
+	apu []*processorUnit
+	aus []*aggregatorUnit
+	ou  *outputUnit
+}
+
+// pipelines groups the configured plugins by their pipeline. The default
+// pipeline contains all plugins without a pipeline setting and comes first
+// followed by the named pipelines sorted by name. Pipelines without plugins
+// are omitted.
+func (a *Agent) pipelines() ([]*pipeline, error) {
+	pipelines := map[string]*pipeline{"": {}}
+	get := func(name string) *pipeline {
+		p, found := pipelines[name]
+		if !found {
+			p = &pipeline{name: name}
+			pipelines[name] = p
+		}
+		return p
+	}
+
+	for _, input := range a.Config.Inputs {
+		p := get(input.Config.Pipeline)
+		p.inputs = append(p.inputs, input)
+	}
+	for _, processor := range a.Config.Processors {
+		p := get(processor.Config.Pipeline)
+		p.processors = append(p.processors, processor)
+	}
+	for _, processor := range a.Config.AggProcessors {
+		p := get(processor.Config.Pipeline)
+		p.aggProcessors = append(p.aggProcessors, processor)
+	}
+	for _, aggregator := range a.Config.Aggregators {
+		p := get(aggregator.Config.Pipeline)
+		p.aggregators = append(p.aggregators, aggregator)
+	}
+	for _, output := range a.Config.Outputs {
+		p := get(output.Config.Pipeline)
+		p.outputs = append(p.outputs, output)
+	}
+
+	names := make([]string, 0, len(pipelines))
+	for name := range pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]*pipeline, 0, len(names))
+	for _, name := range names {
+		p := pipelines[name]
+		if len(p.inputs) == 0 && len(p.processors) == 0 && len(p.aggregators) == 0 && len(p.outputs) == 0 {
+			continue
+		}
+		if p.name != "" && len(p.outputs) == 0 {
+			return nil, fmt.Errorf("%s has no outputs", p.logName())
+		}
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+// startPipeline connects the outputs and starts the processors, aggregators
+// and inputs of the pipeline.
+func (a *Agent) startPipeline(ctx context.Context, p *pipeline) (*pipelineUnit, error) {
+	lanes, err := a.pipelineLanes(p)
+	if err != nil {
+		return nil, err
+	}
+	if len(lanes) > 1 {
+		log.Printf("D! [agent] Running processors and aggregators of %s in %d lanes", p.logName(), len(lanes))
+	}
+
+	log.Printf("D! [agent] Connecting outputs of %s", p.logName())
+	next, ou, err := a.startOutputs(ctx, p.outputs)
+	if err != nil {
+		return nil, err
+	}
+	u := &pipelineUnit{ou: ou}
+
+	// Each lane writes to its own channel merged into the shared channel
+	heads := []chan<- telegraf.Metric{next}
+
+	if len(p.aggregators) != 0 {
+		aggC := next
+		if len(p.aggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
+			aggC, u.apu, err = a.startProcessors(next, p.aggProcessors)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		aggCs := []chan<- telegraf.Metric{aggC}
+		if len(lanes) > 1 {
+			aggCs = mergeLanes(aggC, len(lanes))
+		}
+		heads = make([]chan<- telegraf.Metric, 0, len(lanes))
+		for i, lane := range lanes {
+			src, au := a.startAggregators(aggCs[i], next, lane.aggregators)
+			heads = append(heads, src)
+			u.aus = append(u.aus, au)
+		}
+	} else if len(lanes) > 1 {
+		heads = mergeLanes(next, len(lanes))
+	}
+
+	if len(p.processors) != 0 {
+		for i, lane := range lanes {
+			var pu []*processorUnit
+			heads[i], pu, err = a.startProcessors(heads[i], lane.processors)
+			if err != nil {
+				return nil, err
+			}
+			u.pus = append(u.pus, pu)
+		}
+	}
+
+	next = heads[0]
+	if len(heads) > 1 {
+		next = shardMetrics(heads)
+	}
+
+	u.iu, err = a.startInputs(next, p.inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// startTestPipeline starts the processors, aggregators and inputs of the
+// pipeline for a single gather writing to the given channel.
+func (a *Agent) startTestPipeline(next chan<- telegraf.Metric, p *pipeline) (*pipelineUnit, error) {
+	u := &pipelineUnit{}
+
+	if len(p.aggregators) != 0 {
+		procC := next
+		if len(p.aggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
+			var err error
+			procC, u.apu, err = a.startProcessors(next, p.aggProcessors)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var au *aggregatorUnit
+		next, au = a.startAggregators(procC, next, p.aggregators)
+		u.aus = append(u.aus, au)
+	}
+
+	if len(p.processors) != 0 {
+		var pu []*processorUnit
+		var err error
+		next, pu, err = a.startProcessors(next, p.processors)
+		if err != nil {
+			return nil, err
+		}
+		u.pus = append(u.pus, pu)
+	}
+
+	u.iu = a.testStartInputs(next, p.inputs)
+
+	return u, nil
+}
+
+// runPipeline runs the units of a started pipeline until the inputs are
+// done and all metrics are written.
+func (a *Agent) runPipeline(u *pipelineUnit, startTime time.Time, runInputs func(*inputUnit)) {
+	var wg sync.WaitGroup
+	if u.ou != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runOutputs(u.ou)
+		}()
+	}
+
+	if len(u.aus) != 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(u.apu)
+		}()
+
+		for _, au := range u.aus {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				a.runAggregators(startTime, au)
+			}()
+		}
+	}
+
+	for _, pu := range u.pus {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(pu)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runInputs(u.iu)
+	}()
+
+	wg.Wait()
+}
+
+// stop stops the inputs and closes the outputs of a started pipeline that
+// is not run, e.g. because starting another pipeline failed.
+func (u *pipelineUnit) stop() {
+	stopRunningInputs(u.iu.inputs)
+	stopRunningOutputs(u.ou.outputs)
+}
@@ -0,0 +1,249 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
+)
+
+// virtualClock provides the current time during a replay, i.e. the time of
+// the recorded data instead of the wall-clock time
+type virtualClock struct {
+	now time.Time
+}
+
+func (c *virtualClock) Now() time.Time {
+	return c.now
+}
+
+// Replay pushes the recorded metrics through the processors, aggregators and
+// outputs. Inputs are not used. The metrics are replayed in the order of
+// their timestamps and the aggregation windows follow the timestamps of the
+// recorded metrics instead of the wall-clock time. With a speed of zero the
+// metrics are replayed as fast as possible, otherwise the original pace is
+// kept accelerated by the given factor.
+func (a *Agent) Replay(ctx context.Context, metrics []telegraf.Metric, speed float64) error {
+	if len(metrics) == 0 {
+		return errors.New("no metrics to replay")
+	}
+	if speed < 0 {
+		return fmt.Errorf("invalid replay speed %v", speed)
+	}
+
+	// Set the default for processor skipping
+	if a.Config.Agent.SkipProcessorsAfterAggregators == nil {
+		msg := `The default value of 'skip_processors_after_aggregators' will change to 'true' with Telegraf v1.40.0! `
+		msg += `If you need the current default behavior, please explicitly set the option to 'false'!`
+		log.Print("W! [agent] ", color.YellowString(msg))
+		skipProcessorsAfterAggregators := false
+		a.Config.Agent.SkipProcessorsAfterAggregators = &skipProcessorsAfterAggregators
+	}
+
+	log.Printf("D! [agent] Initializing plugins")
+	if err := a.InitPlugins(); err != nil {
+		return err
+	}
+
+	log.Printf("D! [agent] Connecting outputs")
+	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.runOutputs(ou)
+	}()
+
+	err = a.runReplay(ctx, metrics, speed, next)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	unsent := 0
+	for _, output := range a.Config.Outputs {
+		unsent += output.BufferLength()
+	}
+	if unsent != 0 {
+		return fmt.Errorf("output plugins unable to send %d metrics", unsent)
+	}
+	return nil
+}
+
+// runReplay sends the recorded metrics through the processors and aggregators
+// to the outputC. The channel is closed after all metrics are processed.
+func (a *Agent) runReplay(ctx context.Context, metrics []telegraf.Metric, speed float64, outputC chan<- telegraf.Metric) error {
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Time().Before(metrics[j].Time())
+	})
+	startTime := metrics[0].Time()
+	log.Printf("I! [agent] Replaying %d metrics from %s to %s", len(metrics), startTime, metrics[len(metrics)-1].Time())
+
+	next := outputC
+
+	var apu []*processorUnit
+	var au *aggregatorUnit
+	if len(a.Config.Aggregators) != 0 {
+		procC := next
+		if len(a.Config.AggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
+			var err error
+			procC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
+			if err != nil {
+				close(outputC)
+				return err
+			}
+		}
+
+		next, au = a.startAggregators(procC, next, a.Config.Aggregators)
+	}
+
+	var pu []*processorUnit
+	if len(a.Config.Processors) != 0 {
+		var err error
+		next, pu, err = a.startProcessors(next, a.Config.Processors)
+		if err != nil {
+			for _, u := range apu {
+				u.processor.Stop()
+			}
+			close(outputC)
+			return err
+		}
+	}
+
+	var wg sync.WaitGroup
+	if au != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(apu)
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.replayAggregators(startTime, au)
+		}()
+	}
+
+	if pu != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(pu)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		replayMetrics(ctx, metrics, speed, next)
+	}()
+
+	wg.Wait()
+
+	log.Printf("D! [agent] Replay finished")
+	return nil
+}
+
+// replayMetrics sends the metrics to the destination channel keeping the
+// pace of the timestamps accelerated by the given speed.
+func replayMetrics(ctx context.Context, metrics []telegraf.Metric, speed float64, dst chan<- telegraf.Metric) {
+	defer close(dst)
+
+	start := time.Now()
+	first := metrics[0].Time()
+	for i, m := range metrics {
+		if speed > 0 {
+			offset := time.Duration(float64(m.Time().Sub(first)) / speed)
+			if err := internal.SleepContext(ctx, time.Until(start.Add(offset))); err != nil {
+				log.Printf("W! [agent] Replay cancelled after %d of %d metrics", i, len(metrics))
+				return
+			}
+		} else if ctx.Err() != nil {
+			log.Printf("W! [agent] Replay cancelled after %d of %d metrics", i, len(metrics))
+			return
+		}
+
+		select {
+		case dst <- m:
+		case <-ctx.Done():
+			log.Printf("W! [agent] Replay cancelled after %d of %d metrics", i, len(metrics))
+			return
+		}
+	}
+}
+
+// replayAggregators aggregates the metrics like runAggregators, but pushes
+// the aggregators based on the timestamps of the metrics instead of the
+// wall-clock time. This way the aggregation is deterministic independent of
+// the replay speed.
+func (a *Agent) replayAggregators(startTime time.Time, unit *aggregatorUnit) {
+	interval := time.Duration(a.Config.Agent.Interval)
+	precision := time.Duration(a.Config.Agent.Precision)
+
+	clock := &virtualClock{now: startTime}
+	accs := make([]telegraf.Accumulator, 0, len(a.Config.Aggregators))
+	for _, agg := range a.Config.Aggregators {
+		since, until := updateWindow(startTime, a.Config.Agent.RoundInterval, agg.Period())
+		agg.UpdateWindow(since, until)
+
+		accs = append(accs, &accumulator{
+			maker:     agg,
+			metrics:   unit.aggC,
+			precision: getPrecision(precision, interval),
+			now:       clock.Now,
+		})
+	}
+
+	push := func(agg *models.RunningAggregator, acc telegraf.Accumulator) {
+		clock.now = agg.EndPeriod()
+		agg.Push(acc)
+	}
+
+	for metric := range unit.src {
+		// Push all aggregation windows ending before the metric as the
+		// agent would do when the metric's time is reached.
+		for i, agg := range a.Config.Aggregators {
+			for agg.Period() > 0 && !metric.Time().Before(agg.EndPeriod()) {
+				push(agg, accs[i])
+			}
+		}
+
+		var dropOriginal bool
+		for _, agg := range a.Config.Aggregators {
+			if ok := agg.Add(metric); ok {
+				dropOriginal = true
+			}
+		}
+
+		if !dropOriginal {
+			unit.outputC <- metric // keep original.
+		} else {
+			metric.Drop()
+		}
+	}
+
+	// Push the last incomplete window
+	for i, agg := range a.Config.Aggregators {
+		push(agg, accs[i])
+	}
+
+	// In the case that there are no processors, both aggC and outputC are the
+	// same channel.  If there are processors, we close the aggC and the
+	// processor chain will close the outputC when it finishes processing.
+	close(unit.aggC)
+	log.Printf("D! [agent] Aggregator channel closed")
+}
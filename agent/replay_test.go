@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestReplayAggregation(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[agent]
+  omit_hostname = true
+  skip_processors_after_aggregators = true
+
+[[processors.override]]
+  [processors.override.tags]
+    replayed = "true"
+
+[[aggregators.minmax]]
+  period = "10s"
+  drop_original = true
+`), config.EmptySourcePath))
+
+	// Recorded metrics in random order and far in the past
+	start := time.Unix(1700000000, 0)
+	recorded := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3.0}, start.Add(10*time.Second)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, start),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2.0}, start.Add(5*time.Second)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 4.0}, start.Add(15*time.Second)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 5.0}, start.Add(20*time.Second)),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"replayed": "true"},
+			map[string]interface{}{"value_min": 1.0, "value_max": 2.0},
+			start.Add(10*time.Second),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"replayed": "true"},
+			map[string]interface{}{"value_min": 3.0, "value_max": 4.0},
+			start.Add(20*time.Second),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"replayed": "true"},
+			map[string]interface{}{"value_min": 5.0, "value_max": 5.0},
+			start.Add(30*time.Second),
+		),
+	}
+
+	agent := NewAgent(cfg)
+	require.NoError(t, agent.InitPlugins())
+	actual := replay(t, agent, recorded, 0)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestReplaySpeed(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[agent]
+  omit_hostname = true
+  skip_processors_after_aggregators = true
+`), config.EmptySourcePath))
+
+	start := time.Unix(1700000000, 0)
+	recorded := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, start),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2.0}, start.Add(time.Second)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3.0}, start.Add(2*time.Second)),
+	}
+
+	agent := NewAgent(cfg)
+	require.NoError(t, agent.InitPlugins())
+
+	// Replaying two seconds at ten times the speed takes 200ms
+	before := time.Now()
+	actual := replay(t, agent, recorded, 10)
+	require.GreaterOrEqual(t, time.Since(before), 200*time.Millisecond)
+	testutil.RequireMetricsEqual(t, recorded, actual)
+}
+
+func TestReplayInvalid(t *testing.T) {
+	agent := NewAgent(config.NewConfig())
+	require.ErrorContains(t, agent.Replay(context.Background(), nil, 0), "no metrics to replay")
+
+	recorded := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+	}
+	require.ErrorContains(t, agent.Replay(context.Background(), recorded, -1), "invalid replay speed")
+}
+
+func replay(t *testing.T, a *Agent, metrics []telegraf.Metric, speed float64) []telegraf.Metric {
+	t.Helper()
+
+	var received []telegraf.Metric
+	var mu sync.Mutex
+
+	src := make(chan telegraf.Metric, 100)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for m := range src {
+			mu.Lock()
+			received = append(received, m)
+			mu.Unlock()
+			m.Reject()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, a.runReplay(ctx, metrics, speed, src))
+	wg.Wait()
+
+	return received
+}
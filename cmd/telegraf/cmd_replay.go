@@ -0,0 +1,257 @@
+// Command handling for the "replay" command
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/agent"
+	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+)
+
+type ReplayFlags struct {
+	files          []string
+	dataFormat     string
+	speed          float64
+	timestampUnits time.Duration
+}
+
+func getReplayCommands(configHandlingFlags []cli.Flag, m App) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "replay",
+			Usage: "push recorded metrics through the configured processors, aggregators and outputs",
+			Description: `
+The 'replay' command reads recorded metrics from the given files and pushes
+them through the processors, aggregators and outputs of the configuration.
+Inputs are not started. The metrics are replayed in the order of their
+timestamps using a virtual clock, i.e. aggregation windows are based on the
+recorded timestamps instead of the current time. This allows to validate
+pipeline changes against production-shaped data before rolling them out.
+
+By default the metrics are replayed at their original pace. Use '--speed'
+to accelerate the replay or set it to zero to replay as fast as possible.
+
+Recordings can be in InfluxDB line protocol or in the JSON format of the
+Telegraf JSON serializer. To replay the recording 'metrics.influx' ten times
+faster than recorded use
+
+> telegraf replay --config telegraf.conf --speed 10 metrics.influx
+`,
+			ArgsUsage: "<recording>...[recording]",
+			Flags: append([]cli.Flag{
+				&cli.Float64Flag{
+					Name:  "speed",
+					Usage: "acceleration factor of the replay, zero replays as fast as possible",
+					Value: 1,
+				},
+				&cli.StringFlag{
+					Name:  "data-format",
+					Usage: "format of the recordings, either 'influx' or 'json'",
+					Value: "influx",
+				},
+				&cli.DurationFlag{
+					Name:  "json-timestamp-units",
+					Usage: "units of numeric timestamps in JSON recordings",
+					Value: time.Second,
+				},
+			}, configHandlingFlags...),
+			Action: func(cCtx *cli.Context) error {
+				if cCtx.NArg() == 0 {
+					return errors.New("no recordings specified")
+				}
+
+				r := ReplayFlags{
+					files:          cCtx.Args().Slice(),
+					dataFormat:     cCtx.String("data-format"),
+					speed:          cCtx.Float64("speed"),
+					timestampUnits: cCtx.Duration("json-timestamp-units"),
+				}
+				switch r.dataFormat {
+				case "influx", "json":
+				default:
+					return fmt.Errorf("unknown data format %q", r.dataFormat)
+				}
+				if r.speed < 0 {
+					return fmt.Errorf("invalid speed %v", r.speed)
+				}
+				if r.timestampUnits <= 0 {
+					return fmt.Errorf("invalid timestamp units %v", r.timestampUnits)
+				}
+
+				// Inputs are not used for replaying
+				filters := processFilterFlags(cCtx)
+				filters.input = []string{"-"}
+				g := GlobalFlags{
+					config:     cCtx.StringSlice("config"),
+					configDir:  cCtx.StringSlice("config-directory"),
+					profiles:   cCtx.StringSlice("profile"),
+					plugindDir: cCtx.String("plugin-directory"),
+					password:   cCtx.String("password"),
+					debug:      cCtx.Bool("debug"),
+					quiet:      cCtx.Bool("quiet"),
+				}
+				m.Init(nil, filters, g, WindowFlags{})
+				return m.Replay(r)
+			},
+		},
+	}
+}
+
+func (t *Telegraf) Replay(r ReplayFlags) error {
+	c, err := t.loadConfiguration()
+	if err != nil {
+		return err
+	}
+	if len(c.Outputs) == 0 {
+		return errors.New("no outputs found, probably invalid config file provided")
+	}
+
+	logConfig := &logger.Config{
+		Debug: c.Agent.Debug || t.debug,
+		Quiet: c.Agent.Quiet || t.quiet,
+	}
+	if err := logger.SetupLogging(logConfig); err != nil {
+		return err
+	}
+
+	var metrics []telegraf.Metric
+	for _, fn := range r.files {
+		m, err := readRecording(fn, r.dataFormat, r.timestampUnits)
+		if err != nil {
+			return fmt.Errorf("reading recording %q failed: %w", fn, err)
+		}
+		log.Printf("D! Read %d metrics from %q", len(m), fn)
+		metrics = append(metrics, m...)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	ag := agent.NewAgent(c)
+	return ag.Replay(ctx, metrics, r.speed)
+}
+
+func readRecording(fn, format string, units time.Duration) ([]telegraf.Metric, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if format == "json" {
+		return parseJSONRecording(f, units)
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	parser := &influx.Parser{}
+	if err := parser.Init(); err != nil {
+		return nil, err
+	}
+	return parser.Parse(data)
+}
+
+type jsonMetric struct {
+	Name      string                 `json:"name"`
+	Tags      map[string]string      `json:"tags"`
+	Fields    map[string]interface{} `json:"fields"`
+	Timestamp interface{}            `json:"timestamp"`
+}
+
+type jsonRecord struct {
+	jsonMetric
+	Metrics []jsonMetric `json:"metrics"`
+}
+
+// parseJSONRecording decodes metrics in the format of the JSON serializer
+// either as a sequence of single metrics or of batches
+func parseJSONRecording(r io.Reader, units time.Duration) ([]telegraf.Metric, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var metrics []telegraf.Metric
+	for {
+		var record jsonRecord
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		batch := record.Metrics
+		if len(batch) == 0 {
+			batch = []jsonMetric{record.jsonMetric}
+		}
+		for _, jm := range batch {
+			m, err := jm.toMetric(units)
+			if err != nil {
+				return nil, err
+			}
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+func (jm *jsonMetric) toMetric(units time.Duration) (telegraf.Metric, error) {
+	if jm.Name == "" {
+		return nil, errors.New("metric without name")
+	}
+
+	var ts time.Time
+	switch v := jm.Timestamp.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			ts = time.Unix(0, n*int64(units))
+			break
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q of metric %q", v, jm.Name)
+		}
+		ts = time.Unix(0, int64(f*float64(units)))
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q of metric %q: %w", v, jm.Name, err)
+		}
+		ts = t
+	default:
+		return nil, fmt.Errorf("missing timestamp of metric %q", jm.Name)
+	}
+
+	fields := make(map[string]interface{}, len(jm.Fields))
+	for k, v := range jm.Fields {
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				fields[k] = i
+			} else if f, err := n.Float64(); err == nil {
+				fields[k] = f
+			}
+			continue
+		}
+		fields[k] = v
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("metric %q without fields", jm.Name)
+	}
+
+	return metric.New(jm.Name, jm.Tags, fields, ts), nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestReplayCommand(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := os.Args[0:1]
+	args = append(args,
+		"--debug",
+		"replay",
+		"--config", "telegraf.conf",
+		"--processor-filter", "rename",
+		"--speed", "10",
+		"--data-format", "json",
+		"--json-timestamp-units", "1ms",
+		"first.json", "second.json",
+	)
+
+	m := NewMockTelegraf()
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), m))
+	require.Equal(t, ReplayFlags{
+		files:          []string{"first.json", "second.json"},
+		dataFormat:     "json",
+		speed:          10,
+		timestampUnits: time.Millisecond,
+	}, m.ReplayFlags)
+	require.Equal(t, []string{"telegraf.conf"}, m.GlobalFlags.config)
+	require.True(t, m.GlobalFlags.debug)
+	require.Equal(t, []string{"-"}, m.Filters.input)
+	require.Equal(t, []string{"rename"}, m.Filters.processor)
+}
+
+func TestReplayCommandInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "no recordings",
+			args:     []string{"replay"},
+			expected: "no recordings specified",
+		},
+		{
+			name:     "unknown format",
+			args:     []string{"replay", "--data-format", "csv", "metrics.csv"},
+			expected: `unknown data format "csv"`,
+		},
+		{
+			name:     "negative speed",
+			args:     []string{"replay", "--speed", "-1", "metrics.influx"},
+			expected: "invalid speed -1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			args := append(os.Args[0:1], tt.args...)
+			err := runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf())
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestParseJSONRecording(t *testing.T) {
+	input := `
+{"fields":{"usage":1.5,"count":3},"name":"cpu","tags":{"host":"a"},"timestamp":1700000000123}
+{"metrics":[
+  {"fields":{"used":42},"name":"mem","tags":{"host":"a"},"timestamp":1700000001000},
+  {"fields":{"state":"ok","up":true},"name":"status","tags":{},"timestamp":"2023-11-14T22:13:22Z"}
+]}
+`
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage": 1.5, "count": int64(3)},
+			time.Unix(1700000000, 123000000),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": int64(42)},
+			time.Unix(1700000001, 0),
+		),
+		metric.New(
+			"status",
+			map[string]string{},
+			map[string]interface{}{"state": "ok", "up": true},
+			time.Unix(1700000002, 0),
+		),
+	}
+
+	actual, err := parseJSONRecording(strings.NewReader(input), time.Millisecond)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	_, err = parseJSONRecording(strings.NewReader(`{"fields":{"a":1},"name":"cpu"}`), time.Second)
+	require.ErrorContains(t, err, `missing timestamp of metric "cpu"`)
+}
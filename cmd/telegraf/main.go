@@ -108,6 +108,11 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 			Name:  "config-directory",
 			Usage: "directory containing additional *.conf files",
 		},
+		&cli.StringSliceFlag{
+			Name:    "profile",
+			Usage:   "configuration profile to activate, can be specified multiple times",
+			EnvVars: []string{"TELEGRAF_PROFILE"},
+		},
 		&cli.StringFlag{
 			Name: "section-filter",
 			Usage: "filter the sections to print, separator is ':'. " +
@@ -226,6 +231,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 		g := GlobalFlags{
 			config:                  cCtx.StringSlice("config"),
 			configDir:               cCtx.StringSlice("config-directory"),
+			profiles:                cCtx.StringSlice("profile"),
 			testWait:                cCtx.Int("test-wait"),
 			configURLRetryAttempts:  cCtx.Int("config-url-retry-attempts"),
 			configURLWatchInterval:  cCtx.Duration("config-url-watch-interval"),
@@ -262,6 +268,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 	)
 	commands = append(commands, getPluginCommands(outputBuffer)...)
 	commands = append(commands, getServiceCommands(outputBuffer)...)
+	commands = append(commands, getReplayCommands(configHandlingFlags, m)...)
 
 	app := &cli.App{
 		Name:   "Telegraf",
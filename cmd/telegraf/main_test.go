@@ -42,17 +42,25 @@ var secrets = map[string]map[string][]byte{
 type MockTelegraf struct {
 	GlobalFlags
 	WindowFlags
+	Filters
+	ReplayFlags
 }
 
 func NewMockTelegraf() *MockTelegraf {
 	return &MockTelegraf{}
 }
 
-func (m *MockTelegraf) Init(_ <-chan error, _ Filters, g GlobalFlags, w WindowFlags) {
+func (m *MockTelegraf) Init(_ <-chan error, f Filters, g GlobalFlags, w WindowFlags) {
+	m.Filters = f
 	m.GlobalFlags = g
 	m.WindowFlags = w
 }
 
+func (m *MockTelegraf) Replay(r ReplayFlags) error {
+	m.ReplayFlags = r
+	return nil
+}
+
 func (*MockTelegraf) Run() error {
 	return nil
 }
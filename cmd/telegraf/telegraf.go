@@ -36,6 +36,7 @@ var stop chan struct{}
 type GlobalFlags struct {
 	config                  []string
 	configDir               []string
+	profiles                []string
 	testWait                int
 	configURLRetryAttempts  int
 	configURLWatchInterval  time.Duration
@@ -69,6 +70,9 @@ type App interface {
 	// Secret store commands
 	ListSecretStores() ([]string, error)
 	GetSecretStore(string) (telegraf.SecretStore, error)
+
+	// Replay command
+	Replay(ReplayFlags) error
 }
 
 type Telegraf struct {
@@ -306,6 +310,7 @@ func (t *Telegraf) loadConfiguration() (*config.Config, error) {
 	c.OutputFilters = t.outputFilters
 	c.InputFilters = t.inputFilters
 	c.SecretStoreFilters = t.secretstoreFilters
+	c.Profiles = t.profiles
 
 	if err := t.getConfigFiles(); err != nil {
 		return c, err
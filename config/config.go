@@ -2,9 +2,12 @@ package config
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log"
 	"net/http"
@@ -79,6 +82,9 @@ type Config struct {
 	OutputFilters      []string
 	SecretStoreFilters []string
 
+	// Profiles contains the names of the active configuration profiles
+	Profiles []string
+
 	SecretStores      map[string]telegraf.SecretStore
 	secretStoreSource map[string][]string
 
@@ -92,6 +98,17 @@ type Config struct {
 	fileProcessors    OrderedPlugins
 	fileAggProcessors OrderedPlugins
 
+	// Pipelines contains the settings of the named pipelines
+	Pipelines map[string]*PipelineConfig
+
+	// Outputs referencing a pipeline not defined when loading the output
+	pendingOutputs []pendingOutput
+
+	// Functions creating new instances of the plugins for additional
+	// pipeline lanes
+	processorFactories  map[*models.RunningProcessor]func() (*models.RunningProcessor, error)
+	aggregatorFactories map[*models.RunningAggregator]func() (*models.RunningAggregator, error)
+
 	// Parsers are created by their inputs during gather. Config doesn't keep track of them
 	// like the other plugins because they need to be garbage collected (See issue #11809)
 
@@ -101,6 +118,8 @@ type Config struct {
 
 	NumberSecrets uint64
 
+	configHash hash.Hash
+
 	seenAgentTable     bool
 	seenAgentTableOnce sync.Once
 }
@@ -145,6 +164,11 @@ func NewConfig() *Config {
 		OutputFilters:      make([]string, 0),
 		SecretStoreFilters: make([]string, 0),
 		Deprecations:       make(map[string][]int64),
+		configHash:         sha256.New(),
+
+		Pipelines:           make(map[string]*PipelineConfig),
+		processorFactories:  make(map[*models.RunningProcessor]func() (*models.RunningProcessor, error)),
+		aggregatorFactories: make(map[*models.RunningAggregator]func() (*models.RunningAggregator, error)),
 	}
 
 	// Handle unknown version
@@ -299,6 +323,61 @@ type AgentConfig struct {
 	// BufferDirectory is the directory to store buffer files for serialized
 	// to disk metrics when using the "disk" buffer strategy.
 	BufferDirectory string `toml:"buffer_directory"`
+
+	// InventoryInterval is the interval for emitting the agent inventory
+	// metric. Zero disables the inventory metric.
+	InventoryInterval Duration `toml:"inventory_interval"`
+
+	// HealthServiceAddress is the address to serve the liveness endpoint
+	// "/healthz" and readiness endpoint "/readyz" on. Empty disables the
+	// health endpoints.
+	HealthServiceAddress string `toml:"health_service_address"`
+
+	// HealthMaxFailures is the number of consecutive gather or write failures
+	// after which a plugin is reported as unhealthy.
+	HealthMaxFailures int64 `toml:"health_max_failures"`
+
+	// HealthBufferThreshold is the output buffer fullness, as a fraction of
+	// the buffer limit, above which an output is reported as unhealthy. Zero
+	// disables the check.
+	HealthBufferThreshold float64 `toml:"health_buffer_threshold"`
+
+	// PipelineLanes is the number of parallel lanes running the processors
+	// and aggregators. Metrics are distributed onto the lanes by series.
+	PipelineLanes int `toml:"pipeline_lanes"`
+
+	// AdminServiceAddress is the address to serve the pprof profiles and
+	// runtime statistics on. Empty disables the admin endpoints.
+	AdminServiceAddress string `toml:"admin_service_address"`
+
+	// AdminToken is the bearer token required to access the admin endpoints.
+	AdminToken Secret `toml:"admin_token"`
+
+	// MaxSeriesPerMeasurement is the default limit for the number of active
+	// series per measurement of each input. Zero disables the limit.
+	MaxSeriesPerMeasurement int `toml:"max_series_per_measurement"`
+
+	// SeriesOverflowAction is the default action for metrics of new series
+	// exceeding the series limit, either "drop" or "aggregate".
+	SeriesOverflowAction string `toml:"series_overflow_action"`
+
+	// SeriesTTL is the default time after which series without metrics are
+	// no longer counted as active. Defaults to one hour.
+	SeriesTTL Duration `toml:"series_ttl"`
+
+	// ShutdownDrainTimeout is the maximum time for writing the buffered
+	// metrics of the outputs on shutdown, retrying failed writes. Zero only
+	// flushes the outputs once.
+	ShutdownDrainTimeout Duration `toml:"shutdown_drain_timeout"`
+}
+
+// ConfigHash returns the hex-encoded SHA256 hash over the content of all
+// loaded configuration data
+func (c *Config) ConfigHash() string {
+	if c.configHash == nil {
+		return ""
+	}
+	return hex.EncodeToString(c.configHash.Sum(nil))
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -559,6 +638,12 @@ func (c *Config) LoadAll(configFiles ...string) error {
 		}
 	}
 
+	// Pipelines might be defined in any file so references can only be
+	// resolved after loading all files
+	if err := c.resolvePipelines(); err != nil {
+		return err
+	}
+
 	// Sort the processors according to their `order` setting while
 	// using a stable sort to keep the file loading / file position order.
 	sort.Stable(c.Processors)
@@ -595,11 +680,25 @@ func WithSourcePath(path string) cfgDataOption {
 
 // LoadConfigData loads TOML-formatted config data
 func (c *Config) LoadConfigData(data []byte, path string) error {
+	if c.configHash != nil {
+		c.configHash.Write(data)
+	}
+
 	tbl, err := parseConfig(data)
 	if err != nil {
 		return fmt.Errorf("error parsing data: %w", err)
 	}
 
+	// Merge the active profiles before evaluating any other section
+	if err := c.applyProfiles(tbl); err != nil {
+		return err
+	}
+
+	// Stamp out the plugin instances declared using templates
+	if err := expandTemplates(tbl); err != nil {
+		return err
+	}
+
 	// Parse tags tables first:
 	for _, tableName := range []string{"tags", "global_tags"} {
 		if val, ok := tbl.Fields[tableName]; ok {
@@ -644,6 +743,17 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		c.Tags["host"] = c.Agent.Hostname
 	}
 
+	// Parse the pipelines before the plugins referencing them
+	if val, ok := tbl.Fields["pipelines"]; ok {
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			return errors.New("invalid configuration, error parsing pipelines table")
+		}
+		if err := c.addPipelines(subTable); err != nil {
+			return err
+		}
+	}
+
 	// Warn when explicitly setting the old snmp translator
 	if c.Agent.SnmpTranslator == "netsnmp" {
 		PrintOptionValueDeprecationNotice("agent", "snmp_translator", "netsnmp", telegraf.DeprecationInfo{
@@ -679,7 +789,7 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		}
 
 		switch name {
-		case "agent", "global_tags", "tags":
+		case "agent", "global_tags", "tags", "pipelines":
 		case "outputs":
 			for pluginName, pluginVal := range subTable.Fields {
 				switch pluginSubTable := pluginVal.(type) {
@@ -939,6 +1049,10 @@ func parseConfig(contents []byte) (*ast.Table, error) {
 }
 
 func (c *Config) addAggregator(name, source string, table *ast.Table) error {
+	if enabled, err := c.pluginEnabled(table); err != nil || !enabled {
+		return err
+	}
+
 	creator, ok := aggregators.Aggregators[name]
 	if !ok {
 		// Handle removed, deprecated plugins
@@ -963,7 +1077,20 @@ func (c *Config) addAggregator(name, source string, table *ast.Table) error {
 		return err
 	}
 
-	c.Aggregators = append(c.Aggregators, models.NewRunningAggregator(aggregator, conf))
+	ra := models.NewRunningAggregator(aggregator, conf)
+	c.Aggregators = append(c.Aggregators, ra)
+	c.aggregatorFactories[ra] = func() (*models.RunningAggregator, error) {
+		aggregator := creator()
+		conf, err := c.buildAggregator(name, source, table)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.toml.UnmarshalTable(table, aggregator); err != nil {
+			return nil, err
+		}
+		return models.NewRunningAggregator(aggregator, conf), nil
+	}
+
 	return nil
 }
 
@@ -971,6 +1098,9 @@ func (c *Config) addSecretStore(name, source string, table *ast.Table) error {
 	if len(c.SecretStoreFilters) > 0 && !sliceContains(name, c.SecretStoreFilters) {
 		return nil
 	}
+	if enabled, err := c.pluginEnabled(table); err != nil || !enabled {
+		return err
+	}
 
 	storeID := c.getFieldString(table, "id")
 	if storeID == "" {
@@ -1144,6 +1274,10 @@ func (c *Config) addSerializer(parentname string, table *ast.Table) (*models.Run
 }
 
 func (c *Config) addProcessor(name, source string, table *ast.Table) error {
+	if enabled, err := c.pluginEnabled(table); err != nil || !enabled {
+		return err
+	}
+
 	creator, ok := processors.Processors[name]
 	if !ok {
 		// Handle removed, deprecated plugins
@@ -1176,6 +1310,17 @@ func (c *Config) addProcessor(name, source string, table *ast.Table) error {
 	}
 	rf := models.NewRunningProcessor(processorBefore, processorBeforeConfig)
 	c.fileProcessors = append(c.fileProcessors, &OrderedPlugin{table.Line, rf})
+	c.processorFactories[rf] = func() (*models.RunningProcessor, error) {
+		conf, err := c.buildProcessor("processors", name, source, table)
+		if err != nil {
+			return nil, err
+		}
+		processor, _, err := c.setupProcessor(conf.Name, creator, table)
+		if err != nil {
+			return nil, err
+		}
+		return models.NewRunningProcessor(processor, conf), nil
+	}
 
 	// Setup another (new) processor instance running after the aggregator
 	processorAfterConfig, err := c.buildProcessor("aggprocessors", name, source, table)
@@ -1270,6 +1415,9 @@ func (c *Config) addOutput(name, source string, table *ast.Table) error {
 	if len(c.OutputFilters) > 0 && !sliceContains(name, c.OutputFilters) {
 		return nil
 	}
+	if enabled, err := c.pluginEnabled(table); err != nil || !enabled {
+		return err
+	}
 
 	// For outputs with serializers we need to compute the set of
 	// options that is not covered by both, the serializer and the input.
@@ -1343,6 +1491,17 @@ func (c *Config) addOutput(name, source string, table *ast.Table) error {
 		}
 	}
 
+	// Outputs of pipelines defined later are created when resolving the
+	// pipelines after loading all configuration files
+	if outputConfig.Pipeline != "" {
+		pipeline, found := c.Pipelines[outputConfig.Pipeline]
+		if !found {
+			c.pendingOutputs = append(c.pendingOutputs, pendingOutput{output: output, config: outputConfig})
+			return nil
+		}
+		pipeline.applyTo(outputConfig)
+	}
+
 	ro := models.NewRunningOutput(output, outputConfig, c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
 	c.Outputs = append(c.Outputs, ro)
 
@@ -1353,6 +1512,9 @@ func (c *Config) addInput(name, source string, table *ast.Table) error {
 	if len(c.InputFilters) > 0 && !sliceContains(name, c.InputFilters) {
 		return nil
 	}
+	if enabled, err := c.pluginEnabled(table); err != nil || !enabled {
+		return err
+	}
 
 	// For inputs with parsers we need to compute the set of
 	// options that is not covered by both, the parser and the input.
@@ -1462,6 +1624,7 @@ func (c *Config) buildAggregator(name, source string, tbl *ast.Table) (*models.A
 	conf.NameOverride = c.getFieldString(tbl, "name_override")
 	conf.Alias = c.getFieldString(tbl, "alias")
 	conf.LogLevel = c.getFieldString(tbl, "log_level")
+	conf.Pipeline = c.getFieldString(tbl, "pipeline")
 
 	conf.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
@@ -1499,6 +1662,7 @@ func (c *Config) buildProcessor(category, name, source string, tbl *ast.Table) (
 	conf.Order = c.getFieldInt64(tbl, "order")
 	conf.Alias = c.getFieldString(tbl, "alias")
 	conf.LogLevel = c.getFieldString(tbl, "log_level")
+	conf.Pipeline = c.getFieldString(tbl, "pipeline")
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
@@ -1613,11 +1777,25 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	cp.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	cp.TimeSource = c.getFieldString(tbl, "time_source")
 
+	cp.MaxSeriesPerMeasurement = c.Agent.MaxSeriesPerMeasurement
+	if _, found := tbl.Fields["max_series_per_measurement"]; found {
+		cp.MaxSeriesPerMeasurement = c.getFieldInt(tbl, "max_series_per_measurement")
+	}
+	cp.SeriesOverflowAction = c.Agent.SeriesOverflowAction
+	if action := c.getFieldString(tbl, "series_overflow_action"); action != "" {
+		cp.SeriesOverflowAction = action
+	}
+	cp.SeriesTTL = time.Duration(c.Agent.SeriesTTL)
+	if ttl, found := c.getFieldDuration(tbl, "series_ttl"); found {
+		cp.SeriesTTL = ttl
+	}
+
 	cp.MeasurementPrefix = c.getFieldString(tbl, "name_prefix")
 	cp.MeasurementSuffix = c.getFieldString(tbl, "name_suffix")
 	cp.NameOverride = c.getFieldString(tbl, "name_override")
 	cp.Alias = c.getFieldString(tbl, "alias")
 	cp.LogLevel = c.getFieldString(tbl, "log_level")
+	cp.Pipeline = c.getFieldString(tbl, "pipeline")
 
 	cp.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
@@ -1671,7 +1849,9 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.NameSuffix = c.getFieldString(tbl, "name_suffix")
 	oc.NamePrefix = c.getFieldString(tbl, "name_prefix")
 	oc.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
+	oc.FailoverGroup = c.getFieldString(tbl, "failover_group")
 	oc.LogLevel = c.getFieldString(tbl, "log_level")
+	oc.Pipeline = c.getFieldString(tbl, "pipeline")
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
@@ -1693,14 +1873,15 @@ func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 		"buffer_strategy", "buffer_directory",
 		"collection_jitter", "collection_offset",
 		"data_format", "delay", "drop", "drop_original",
-		"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
+		"failover_group", "fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
 		"grace",
 		"interval",
 		"log_level", "lvm", // What is this used for?
-		"metric_batch_size", "metric_buffer_limit", "metricpass",
+		"max_series_per_measurement", "metric_batch_size", "metric_buffer_limit", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
-		"pass", "period", "precision",
+		"pass", "period", "pipeline", "precision",
+		"series_overflow_action", "series_ttl",
 		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior":
 
 	// Secret-store options to ignore
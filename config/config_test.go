@@ -547,6 +547,281 @@ func TestGetDefaultConfigPathFromEnvURL(t *testing.T) {
 	require.NoError(t, c.LoadConfig(configPath[0]))
 }
 
+func TestConfig_SeriesLimit(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[agent]
+  max_series_per_measurement = 1000
+  series_overflow_action = "aggregate"
+
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+[[inputs.memcached]]
+  servers = ["localhost"]
+  max_series_per_measurement = 10
+  series_overflow_action = "drop"
+  series_ttl = "5m"
+
+[[inputs.memcached]]
+  servers = ["localhost"]
+  max_series_per_measurement = 0
+`), config.EmptySourcePath))
+	require.Len(t, c.Inputs, 3)
+
+	require.Equal(t, 1000, c.Inputs[0].Config.MaxSeriesPerMeasurement)
+	require.Equal(t, "aggregate", c.Inputs[0].Config.SeriesOverflowAction)
+	require.Zero(t, c.Inputs[0].Config.SeriesTTL)
+
+	require.Equal(t, 10, c.Inputs[1].Config.MaxSeriesPerMeasurement)
+	require.Equal(t, "drop", c.Inputs[1].Config.SeriesOverflowAction)
+	require.Equal(t, 5*time.Minute, c.Inputs[1].Config.SeriesTTL)
+
+	require.Zero(t, c.Inputs[2].Config.MaxSeriesPerMeasurement)
+}
+
+func TestConfig_Profiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles []string
+		interval time.Duration
+		servers  []string
+	}{
+		{
+			name:     "no profile",
+			interval: 10 * time.Second,
+			servers:  []string{"base"},
+		},
+		{
+			name:     "single profile",
+			profiles: []string{"production"},
+			interval: time.Minute,
+			servers:  []string{"base", "production"},
+		},
+		{
+			name:     "multiple profiles",
+			profiles: []string{"production", "staging"},
+			interval: time.Minute,
+			servers:  []string{"base", "production", "staging"},
+		},
+		{
+			name:     "unknown profile",
+			profiles: []string{"development"},
+			interval: 10 * time.Second,
+			servers:  []string{"base"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config.NewConfig()
+			c.Profiles = tt.profiles
+			require.NoError(t, c.LoadConfig("./testdata/profiles.toml"))
+			require.Equal(t, config.Duration(tt.interval), c.Agent.Interval)
+
+			servers := make([]string, 0, len(c.Inputs))
+			for _, input := range c.Inputs {
+				servers = append(servers, input.Input.(*MockupInputPlugin).Servers...)
+			}
+			require.ElementsMatch(t, tt.servers, servers)
+		})
+	}
+}
+
+func TestConfig_EnabledIf(t *testing.T) {
+	t.Setenv("TELEGRAF_TEST_DEPLOYMENT", "prod")
+
+	c := config.NewConfig()
+	c.Profiles = []string{"staging"}
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  servers = ["env"]
+  enabled_if = "env.TELEGRAF_TEST_DEPLOYMENT == 'prod'"
+
+[[inputs.memcached]]
+  servers = ["substituted"]
+  enabled_if = "'${TELEGRAF_TEST_DEPLOYMENT}' != 'prod'"
+
+[[inputs.memcached]]
+  servers = ["profile"]
+  enabled_if = "'staging' in profiles"
+
+[[outputs.http]]
+  url = "http://localhost"
+  enabled_if = "'production' in profiles"
+`), config.EmptySourcePath))
+
+	servers := make([]string, 0, len(c.Inputs))
+	for _, input := range c.Inputs {
+		servers = append(servers, input.Input.(*MockupInputPlugin).Servers...)
+	}
+	require.ElementsMatch(t, []string{"env", "profile"}, servers)
+	require.Empty(t, c.Outputs)
+}
+
+func TestConfig_EnabledIfInvalid(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  servers = ["localhost"]
+  enabled_if = "1 + 1"
+`), config.EmptySourcePath)
+	require.ErrorContains(t, err, "'enabled_if' expression needs to return a boolean")
+}
+
+func TestConfig_Templating(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/templating.toml"))
+	require.Len(t, c.Inputs, 5)
+
+	expected := []struct {
+		alias   string
+		servers []string
+		tags    map[string]string
+	}{
+		{alias: "memcached_a", servers: []string{"http://10.0.0.1:8080"}, tags: map[string]string{"site": "east"}},
+		{alias: "memcached_b", servers: []string{"http://10.0.0.2:8081"}, tags: map[string]string{"site": "west"}},
+		{servers: []string{"localhost:1"}, tags: map[string]string{}},
+		{servers: []string{"localhost:2"}, tags: map[string]string{}},
+		{servers: []string{"plain"}, tags: map[string]string{}},
+	}
+	for i, input := range c.Inputs {
+		require.Equal(t, expected[i].alias, input.Config.Alias)
+		require.Equal(t, expected[i].servers, input.Input.(*MockupInputPlugin).Servers)
+		require.Equal(t, expected[i].tags, input.Config.Tags)
+	}
+
+	// Go templates used as setting values must be kept as they are
+	require.Len(t, c.Processors, 2)
+	for i, alias := range []string{"processor_a", "processor_b"} {
+		plugin := c.Processors[i]
+		require.Equal(t, alias, plugin.Config.Alias)
+		p := plugin.Processor.(processors.HasUnwrap).Unwrap().(*MockupProcessorPlugin)
+		require.Equal(t, `{{ .Tag "host" }}`, p.Option)
+	}
+}
+
+func TestConfig_TemplatingMissingKey(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  servers = ["${{.server}}"]
+  foreach = [{ host = "localhost" }]
+`), config.EmptySourcePath)
+	require.ErrorContains(t, err, `map has no entry for key "server"`)
+}
+
+func TestConfig_Pipelines(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[agent]
+  metric_batch_size = 100
+  metric_buffer_limit = 1000
+
+[pipelines.logs]
+  flush_interval = "30s"
+  flush_jitter = "5s"
+  metric_batch_size = 500
+  metric_buffer_limit = 50000
+
+[[inputs.memcached]]
+  servers = ["localhost"]
+  pipeline = "logs"
+
+[[outputs.http]]
+  url = "http://localhost"
+
+[[outputs.http]]
+  url = "http://localhost"
+  pipeline = "logs"
+  flush_interval = "1m"
+`), config.EmptySourcePath))
+
+	require.Contains(t, c.Pipelines, "logs")
+	require.Equal(t, "logs", c.Inputs[0].Config.Pipeline)
+
+	require.Len(t, c.Outputs, 2)
+	var defaults, logs *models.RunningOutput
+	for _, output := range c.Outputs {
+		if output.Config.Pipeline == "logs" {
+			logs = output
+		} else {
+			defaults = output
+		}
+	}
+	require.NotNil(t, defaults)
+	require.NotNil(t, logs)
+
+	require.Zero(t, defaults.Config.FlushInterval)
+	require.Equal(t, 100, defaults.MetricBatchSize)
+
+	// Output settings take precedence over the pipeline settings
+	require.Equal(t, time.Minute, logs.Config.FlushInterval)
+	require.Equal(t, 5*time.Second, logs.Config.FlushJitter)
+	require.Equal(t, 500, logs.MetricBatchSize)
+	require.Equal(t, 50000, logs.Config.MetricBufferLimit)
+}
+
+func TestConfig_PipelinesInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      string
+		expected string
+	}{
+		{
+			name: "unknown option",
+			cfg: `
+[pipelines.logs]
+  foo = "bar"
+`,
+			expected: `configuration specified the fields ["foo"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config.NewConfig()
+			err := c.LoadConfigData([]byte(tt.cfg), config.EmptySourcePath)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestConfig_PipelinesMultipleFiles(t *testing.T) {
+	// The pipeline is defined in a file loaded after the plugins using it
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/pipelines/plugins.toml", "./testdata/pipelines/pipelines.toml"))
+
+	require.Contains(t, c.Pipelines, "logs")
+	require.Equal(t, "logs", c.Inputs[0].Config.Pipeline)
+	require.Equal(t, "logs", c.Processors[0].Config.Pipeline)
+
+	require.Len(t, c.Outputs, 2)
+	var defaults, logs *models.RunningOutput
+	for _, output := range c.Outputs {
+		if output.Config.Pipeline == "logs" {
+			logs = output
+		} else {
+			defaults = output
+		}
+	}
+	require.NotNil(t, defaults)
+	require.NotNil(t, logs)
+
+	require.Zero(t, defaults.Config.FlushInterval)
+	require.Equal(t, 100, defaults.MetricBatchSize)
+
+	require.Equal(t, time.Minute, logs.Config.FlushInterval)
+	require.Equal(t, 500, logs.MetricBatchSize)
+	require.Equal(t, 50000, logs.MetricBufferLimit)
+}
+
+func TestConfig_PipelinesUndefined(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadAll("./testdata/pipelines/plugins.toml")
+	require.ErrorContains(t, err, `undefined pipeline "logs"`)
+}
+
 func TestConfig_URLLikeFileName(t *testing.T) {
 	c := config.NewConfig()
 	err := c.LoadConfig("http:##www.example.com.conf")
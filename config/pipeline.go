@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/toml/ast"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// NewPipelineLane creates new instances of the given processors running
+// before the aggregators and of the given aggregators in the same order.
+// The instances are used to run the processing pipeline in parallel lanes.
+func (c *Config) NewPipelineLane(
+	runningProcessors models.RunningProcessors,
+	runningAggregators []*models.RunningAggregator,
+) (models.RunningProcessors, []*models.RunningAggregator, error) {
+	// The plugin options were already checked when loading the configuration
+	c.setLocalMissingTomlFieldTracker(make(map[string]int))
+	defer c.resetMissingTomlFieldTracker()
+
+	processors := make(models.RunningProcessors, 0, len(runningProcessors))
+	for _, processor := range runningProcessors {
+		factory, found := c.processorFactories[processor]
+		if !found {
+			return nil, nil, fmt.Errorf("processor %s cannot run in multiple lanes", processor.LogName())
+		}
+		p, err := factory()
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating processor %s failed: %w", processor.LogName(), err)
+		}
+		processors = append(processors, p)
+	}
+
+	aggregators := make([]*models.RunningAggregator, 0, len(runningAggregators))
+	for _, aggregator := range runningAggregators {
+		factory, found := c.aggregatorFactories[aggregator]
+		if !found {
+			return nil, nil, fmt.Errorf("aggregator %s cannot run in multiple lanes", aggregator.LogName())
+		}
+		agg, err := factory()
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating aggregator %s failed: %w", aggregator.LogName(), err)
+		}
+		aggregators = append(aggregators, agg)
+	}
+
+	return processors, aggregators, nil
+}
+
+// PipelineConfig contains the settings of a named pipeline. The flush and
+// buffer settings are used for all outputs of the pipeline not setting the
+// options themselves.
+type PipelineConfig struct {
+	Name              string   `toml:"-"`
+	FlushInterval     Duration `toml:"flush_interval"`
+	FlushJitter       Duration `toml:"flush_jitter"`
+	MetricBatchSize   int      `toml:"metric_batch_size"`
+	MetricBufferLimit int      `toml:"metric_buffer_limit"`
+}
+
+// addPipelines parses the named pipeline tables
+func (c *Config) addPipelines(tbl *ast.Table) error {
+	for name, val := range tbl.Fields {
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("invalid configuration, error parsing pipeline %q", name)
+		}
+		if _, found := c.Pipelines[name]; found {
+			return fmt.Errorf("pipeline %q defined multiple times", name)
+		}
+
+		pipeline := &PipelineConfig{Name: name}
+		if err := c.toml.UnmarshalTable(subTable, pipeline); err != nil {
+			return fmt.Errorf("error parsing pipeline %q: %w", name, err)
+		}
+		if len(c.UnusedFields) > 0 {
+			return fmt.Errorf(
+				"pipeline %s: line %d: configuration specified the fields %q, but they were not used. "+
+					"This is either a typo or this config option does not exist in this version.",
+				name, subTable.Line, keys(c.UnusedFields))
+		}
+		c.Pipelines[name] = pipeline
+	}
+	return nil
+}
+
+// applyTo sets the flush and buffer settings of the pipeline for the output
+// unless overridden by the output
+func (p *PipelineConfig) applyTo(oc *models.OutputConfig) {
+	if oc.FlushInterval == 0 {
+		oc.FlushInterval = time.Duration(p.FlushInterval)
+	}
+	if oc.FlushJitter == 0 {
+		oc.FlushJitter = time.Duration(p.FlushJitter)
+	}
+	if oc.MetricBufferLimit == 0 {
+		oc.MetricBufferLimit = p.MetricBufferLimit
+	}
+	if oc.MetricBatchSize == 0 {
+		oc.MetricBatchSize = p.MetricBatchSize
+	}
+}
+
+// pendingOutput is an output referencing a pipeline not defined at the time
+// the output was loaded. The running output is created when resolving the
+// pipelines as its buffer depends on the pipeline settings.
+type pendingOutput struct {
+	output telegraf.Output
+	config *models.OutputConfig
+}
+
+// resolvePipelines checks the pipeline references of all plugins and creates
+// the outputs of pipelines defined after the output. As pipelines might be
+// defined in any configuration file, this must be done after loading all files.
+func (c *Config) resolvePipelines() error {
+	for _, p := range c.pendingOutputs {
+		pipeline, err := c.pipeline(p.config.Pipeline)
+		if err != nil {
+			return fmt.Errorf("outputs.%s: %w", p.config.Name, err)
+		}
+		pipeline.applyTo(p.config)
+		ro := models.NewRunningOutput(p.output, p.config, c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
+		c.Outputs = append(c.Outputs, ro)
+	}
+	c.pendingOutputs = nil
+
+	for _, input := range c.Inputs {
+		if _, err := c.pipeline(input.Config.Pipeline); err != nil {
+			return fmt.Errorf("%s: %w", input.LogName(), err)
+		}
+	}
+	for _, processors := range []models.RunningProcessors{c.Processors, c.AggProcessors} {
+		for _, processor := range processors {
+			if _, err := c.pipeline(processor.Config.Pipeline); err != nil {
+				return fmt.Errorf("%s: %w", processor.LogName(), err)
+			}
+		}
+	}
+	for _, aggregator := range c.Aggregators {
+		if _, err := c.pipeline(aggregator.Config.Pipeline); err != nil {
+			return fmt.Errorf("%s: %w", aggregator.LogName(), err)
+		}
+	}
+	return nil
+}
+
+// pipeline returns the settings of the named pipeline or nil for the default
+// pipeline
+func (c *Config) pipeline(name string) (*PipelineConfig, error) {
+	if name == "" {
+		return nil, nil
+	}
+	pipeline, found := c.Pipelines[name]
+	if !found {
+		return nil, fmt.Errorf("undefined pipeline %q", name)
+	}
+	return pipeline, nil
+}
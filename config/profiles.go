@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/influxdata/toml/ast"
+)
+
+// applyProfiles merges the sections of all active profiles into the given
+// top-level table and removes the profile definitions. Profiles are applied
+// in the order they are activated, so later profiles take precedence.
+func (c *Config) applyProfiles(tbl *ast.Table) error {
+	val, found := tbl.Fields["profiles"]
+	if !found {
+		return nil
+	}
+	delete(tbl.Fields, "profiles")
+
+	profiles, ok := val.(*ast.Table)
+	if !ok {
+		return errors.New("invalid configuration, error parsing profiles table")
+	}
+
+	for _, name := range c.Profiles {
+		val, found := profiles.Fields[name]
+		if !found {
+			continue
+		}
+		profile, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("invalid configuration, error parsing profile %q", name)
+		}
+		if err := mergeTables(tbl, profile); err != nil {
+			return fmt.Errorf("applying profile %q failed: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// mergeTables merges the fields of the source table into the destination.
+// Tables are merged recursively, arrays of tables (e.g. plugin instances)
+// are appended and values of the source override the destination values.
+func mergeTables(dst, src *ast.Table) error {
+	for name, val := range src.Fields {
+		existing, found := dst.Fields[name]
+		if !found {
+			dst.Fields[name] = val
+			continue
+		}
+
+		switch v := val.(type) {
+		case *ast.Table:
+			t, ok := existing.(*ast.Table)
+			if !ok {
+				return fmt.Errorf("line %d: cannot merge table %q with non-table", v.Line, name)
+			}
+			if err := mergeTables(t, v); err != nil {
+				return err
+			}
+		case []*ast.Table:
+			t, ok := existing.([]*ast.Table)
+			if !ok {
+				return fmt.Errorf("cannot merge array of tables %q with non-array", name)
+			}
+			dst.Fields[name] = append(t, v...)
+		default:
+			dst.Fields[name] = val
+		}
+	}
+	return nil
+}
+
+// pluginEnabled evaluates the 'enabled_if' expression of the given plugin
+// table and removes the setting from the table. Plugins without expression
+// are always enabled.
+func (c *Config) pluginEnabled(table *ast.Table) (bool, error) {
+	if _, found := table.Fields["enabled_if"]; !found {
+		return true, nil
+	}
+	expression := c.getFieldString(table, "enabled_if")
+	delete(table.Fields, "enabled_if")
+	if expression == "" {
+		return false, errors.New("'enabled_if' must be a non-empty string")
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable("env", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("profiles", cel.ListType(cel.StringType)),
+	)
+	if err != nil {
+		return false, fmt.Errorf("creating environment for 'enabled_if' failed: %w", err)
+	}
+	compiled, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return false, fmt.Errorf("compiling 'enabled_if' expression failed: %w", issues.Err())
+	}
+	if compiled.OutputType() != cel.BoolType {
+		return false, errors.New("'enabled_if' expression needs to return a boolean")
+	}
+	program, err := env.Program(compiled)
+	if err != nil {
+		return false, fmt.Errorf("creating program for 'enabled_if' failed: %w", err)
+	}
+
+	variables := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			variables[k] = v
+		}
+	}
+	profiles := c.Profiles
+	if profiles == nil {
+		profiles = make([]string, 0)
+	}
+
+	result, _, err := program.Eval(map[string]interface{}{
+		"env":      variables,
+		"profiles": profiles,
+	})
+	if err != nil {
+		return false, fmt.Errorf("evaluating 'enabled_if' expression failed: %w", err)
+	}
+	enabled, ok := result.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("'enabled_if' expression returned non-boolean %v", result.Value())
+	}
+
+	return enabled, nil
+}
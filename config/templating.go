@@ -0,0 +1,200 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/influxdata/toml/ast"
+)
+
+// Delimiters of the placeholders in templated plugin tables. Those differ from
+// the Go template defaults as many plugins accept Go templates as settings
+// which must be kept as they are.
+const (
+	templateLeftDelim  = "${{"
+	templateRightDelim = "}}"
+)
+
+// expandTemplates replaces all plugin tables containing a 'foreach' setting
+// by one plugin table per entry of the setting. Placeholders in the string
+// settings of the plugin table are replaced by the values of the entry.
+func expandTemplates(tbl *ast.Table) error {
+	for _, category := range []string{"inputs", "plugins", "outputs", "processors", "aggregators", "secretstores"} {
+		val, found := tbl.Fields[category]
+		if !found {
+			continue
+		}
+		categoryTbl, ok := val.(*ast.Table)
+		if !ok {
+			continue
+		}
+
+		for name, pluginVal := range categoryTbl.Fields {
+			var tables []*ast.Table
+			switch v := pluginVal.(type) {
+			case *ast.Table:
+				// Legacy [inputs.cpu] tables are only expanded if required
+				if _, found := v.Fields["foreach"]; !found {
+					continue
+				}
+				tables = []*ast.Table{v}
+			case []*ast.Table:
+				tables = v
+			default:
+				continue
+			}
+
+			expanded := make([]*ast.Table, 0, len(tables))
+			for _, t := range tables {
+				instances, err := expandPluginTable(t)
+				if err != nil {
+					return fmt.Errorf("expanding %s.%s failed: %w", category, name, err)
+				}
+				expanded = append(expanded, instances...)
+			}
+			categoryTbl.Fields[name] = expanded
+		}
+	}
+
+	return nil
+}
+
+// expandPluginTable returns one copy of the plugin table per 'foreach' entry
+// with all placeholders replaced by the values of the entry
+func expandPluginTable(tbl *ast.Table) ([]*ast.Table, error) {
+	val, found := tbl.Fields["foreach"]
+	if !found {
+		return []*ast.Table{tbl}, nil
+	}
+	delete(tbl.Fields, "foreach")
+
+	// Accept both, an array of inline tables and an array of tables
+	var entries []*ast.Table
+	switch v := val.(type) {
+	case []*ast.Table:
+		entries = v
+	case *ast.KeyValue:
+		arr, ok := v.Value.(*ast.Array)
+		if !ok {
+			return nil, fmt.Errorf("line %d: 'foreach' must be an array of tables", v.Line)
+		}
+		for _, e := range arr.Value {
+			entry, ok := e.(*ast.Table)
+			if !ok {
+				return nil, fmt.Errorf("line %d: 'foreach' must be an array of tables", v.Line)
+			}
+			entries = append(entries, entry)
+		}
+	default:
+		return nil, fmt.Errorf("line %d: 'foreach' must be an array of tables", tbl.Line)
+	}
+
+	instances := make([]*ast.Table, 0, len(entries))
+	for i, entry := range entries {
+		vars := make(map[string]string, len(entry.Fields))
+		for k, v := range entry.Fields {
+			kv, ok := v.(*ast.KeyValue)
+			if !ok {
+				return nil, fmt.Errorf("line %d: 'foreach' entry %d: value of %q must not be a table", tbl.Line, i, k)
+			}
+			switch value := kv.Value.(type) {
+			case *ast.String:
+				vars[k] = value.Value
+			case *ast.Integer, *ast.Float, *ast.Boolean, *ast.Datetime:
+				vars[k] = value.Source()
+			default:
+				return nil, fmt.Errorf("line %d: 'foreach' entry %d: value of %q must be a scalar", kv.Line, i, k)
+			}
+		}
+
+		instance, err := expandTable(tbl, vars)
+		if err != nil {
+			return nil, fmt.Errorf("'foreach' entry %d: %w", i, err)
+		}
+		instances = append(instances, instance)
+	}
+
+	return instances, nil
+}
+
+// expandTable returns a deep copy of the given table with the placeholders in
+// all string values replaced
+func expandTable(tbl *ast.Table, vars map[string]string) (*ast.Table, error) {
+	expanded := &ast.Table{
+		Position: tbl.Position,
+		Line:     tbl.Line,
+		Name:     tbl.Name,
+		Fields:   make(map[string]interface{}, len(tbl.Fields)),
+		Type:     tbl.Type,
+		Data:     tbl.Data,
+	}
+
+	for name, val := range tbl.Fields {
+		switch v := val.(type) {
+		case *ast.Table:
+			t, err := expandTable(v, vars)
+			if err != nil {
+				return nil, err
+			}
+			expanded.Fields[name] = t
+		case []*ast.Table:
+			tables := make([]*ast.Table, 0, len(v))
+			for _, sub := range v {
+				t, err := expandTable(sub, vars)
+				if err != nil {
+					return nil, err
+				}
+				tables = append(tables, t)
+			}
+			expanded.Fields[name] = tables
+		case *ast.KeyValue:
+			value, err := expandValue(v.Value, vars)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: expanding %q failed: %w", v.Line, v.Key, err)
+			}
+			expanded.Fields[name] = &ast.KeyValue{Key: v.Key, Value: value, Line: v.Line}
+		default:
+			expanded.Fields[name] = val
+		}
+	}
+
+	return expanded, nil
+}
+
+func expandValue(val ast.Value, vars map[string]string) (ast.Value, error) {
+	switch v := val.(type) {
+	case *ast.String:
+		if !strings.Contains(v.Value, templateLeftDelim) {
+			return v, nil
+		}
+		tmpl, err := template.New("").Delims(templateLeftDelim, templateRightDelim).Option("missingkey=error").Parse(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, vars); err != nil {
+			return nil, err
+		}
+		return &ast.String{
+			Position: v.Position,
+			Value:    buf.String(),
+			Data:     []rune(strconv.Quote(buf.String())),
+		}, nil
+	case *ast.Array:
+		values := make([]ast.Value, 0, len(v.Value))
+		for _, e := range v.Value {
+			value, err := expandValue(e, vars)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return &ast.Array{Position: v.Position, Value: values, Data: v.Data}, nil
+	case *ast.Table:
+		return expandTable(v, vars)
+	}
+	return val, nil
+}
@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/golang/snappy"
 	"github.com/klauspost/compress/gzip"
 	"github.com/klauspost/compress/zlib"
 	"github.com/klauspost/compress/zstd"
 	"github.com/klauspost/pgzip"
+	"github.com/pierrec/lz4/v4"
 )
 
 const defaultMaxDecompressionSize int64 = 500 * 1024 * 1024 // 500MB
@@ -113,6 +115,10 @@ func NewContentEncoder(encoding string, options ...EncodingOption) (ContentEncod
 		return NewZlibEncoder(options...)
 	case "zstd":
 		return NewZstdEncoder(options...)
+	case "snappy":
+		return NewSnappyEncoder(options...)
+	case "lz4":
+		return NewLz4Encoder(options...)
 	default:
 		return nil, errors.New("invalid value for content_encoding")
 	}
@@ -156,6 +162,10 @@ func NewContentDecoder(encoding string, options ...DecodingOption) (ContentDecod
 		return NewZlibDecoder(options...), nil
 	case "zstd":
 		return NewZstdDecoder(options...)
+	case "snappy":
+		return NewSnappyDecoder(options...), nil
+	case "lz4":
+		return NewLz4Decoder(options...), nil
 	default:
 		return nil, errors.New("invalid value for content_encoding")
 	}
@@ -318,6 +328,69 @@ func (e *ZstdEncoder) Encode(data []byte) ([]byte, error) {
 	return e.encoder.EncodeAll(data, make([]byte, 0, len(data))), nil
 }
 
+// SnappyEncoder compresses the buffer using the snappy block format. The
+// format does not support compression levels.
+type SnappyEncoder struct{}
+
+func NewSnappyEncoder(options ...EncodingOption) (*SnappyEncoder, error) {
+	if len(options) > 0 {
+		return nil, errors.New("snappy encoder does not support options")
+	}
+
+	return &SnappyEncoder{}, nil
+}
+
+func (*SnappyEncoder) Encode(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+// Lz4Encoder compresses the buffer using the lz4 frame format.
+type Lz4Encoder struct {
+	writer *lz4.Writer
+	buf    *bytes.Buffer
+}
+
+func NewLz4Encoder(options ...EncodingOption) (*Lz4Encoder, error) {
+	cfg := encoderConfig{level: 0}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	// Map the levels with zero being the fastest compression
+	levels := []lz4.CompressionLevel{
+		lz4.Fast, lz4.Level1, lz4.Level2, lz4.Level3, lz4.Level4,
+		lz4.Level5, lz4.Level6, lz4.Level7, lz4.Level8, lz4.Level9,
+	}
+	if cfg.level < 0 || cfg.level >= len(levels) {
+		return nil, errors.New("invalid compression level, only 0 to 9 are supported")
+	}
+
+	var buf bytes.Buffer
+	w := lz4.NewWriter(&buf)
+	if err := w.Apply(lz4.CompressionLevelOption(levels[cfg.level])); err != nil {
+		return nil, err
+	}
+	return &Lz4Encoder{
+		writer: w,
+		buf:    &buf,
+	}, nil
+}
+
+func (e *Lz4Encoder) Encode(data []byte) ([]byte, error) {
+	e.buf.Reset()
+	e.writer.Reset(e.buf)
+
+	_, err := e.writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = e.writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
 // IdentityEncoder is a null encoder that applies no transformation.
 type IdentityEncoder struct{}
 
@@ -480,6 +553,70 @@ func (d *ZstdDecoder) Decode(data []byte) ([]byte, error) {
 	return d.decoder.DecodeAll(data, nil)
 }
 
+// SnappyDecoder decompresses buffers in the snappy block format.
+type SnappyDecoder struct {
+	maxDecompressionSize int64
+}
+
+func NewSnappyDecoder(options ...DecodingOption) *SnappyDecoder {
+	cfg := decoderConfig{maxDecompressionSize: defaultMaxDecompressionSize}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	return &SnappyDecoder{maxDecompressionSize: cfg.maxDecompressionSize}
+}
+
+func (*SnappyDecoder) SetEncoding(string) {}
+
+func (d *SnappyDecoder) Decode(data []byte) ([]byte, error) {
+	// The block format contains the decoded length, so check it before
+	// allocating the buffer
+	n, err := snappy.DecodedLen(data)
+	if err != nil {
+		return nil, err
+	}
+	if int64(n) >= d.maxDecompressionSize {
+		return nil, fmt.Errorf("size of decoded data exceeds allowed size %d", d.maxDecompressionSize)
+	}
+	return snappy.Decode(nil, data)
+}
+
+// Lz4Decoder decompresses buffers in the lz4 frame format.
+type Lz4Decoder struct {
+	reader               *lz4.Reader
+	buf                  *bytes.Buffer
+	maxDecompressionSize int64
+}
+
+func NewLz4Decoder(options ...DecodingOption) *Lz4Decoder {
+	cfg := decoderConfig{maxDecompressionSize: defaultMaxDecompressionSize}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	return &Lz4Decoder{
+		reader:               lz4.NewReader(nil),
+		buf:                  new(bytes.Buffer),
+		maxDecompressionSize: cfg.maxDecompressionSize,
+	}
+}
+
+func (*Lz4Decoder) SetEncoding(string) {}
+
+func (d *Lz4Decoder) Decode(data []byte) ([]byte, error) {
+	d.reader.Reset(bytes.NewBuffer(data))
+	d.buf.Reset()
+
+	n, err := io.CopyN(d.buf, d.reader, d.maxDecompressionSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	} else if n == d.maxDecompressionSize {
+		return nil, fmt.Errorf("size of decoded data exceeds allowed size %d", d.maxDecompressionSize)
+	}
+	return d.buf.Bytes(), nil
+}
+
 // IdentityDecoder is a null decoder that returns the input.
 type IdentityDecoder struct {
 }
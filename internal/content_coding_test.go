@@ -112,6 +112,66 @@ func TestZstdReuse(t *testing.T) {
 	require.Equal(t, "doody", string(actual))
 }
 
+func TestSnappyEncodeDecode(t *testing.T) {
+	enc, err := NewSnappyEncoder()
+	require.NoError(t, err)
+	dec := NewSnappyDecoder(WithMaxDecompressionSize(maxDecompressionSize))
+
+	payload, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	actual, err := dec.Decode(payload)
+	require.NoError(t, err)
+
+	require.Equal(t, "howdy", string(actual))
+}
+
+func TestSnappyEncodeDecodeWithTooLargeMessage(t *testing.T) {
+	enc, err := NewSnappyEncoder()
+	require.NoError(t, err)
+	dec := NewSnappyDecoder(WithMaxDecompressionSize(3))
+
+	payload, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	_, err = dec.Decode(payload)
+	require.ErrorContains(t, err, "size of decoded data exceeds allowed size 3")
+}
+
+func TestLz4Reuse(t *testing.T) {
+	enc, err := NewLz4Encoder(WithCompressionLevel(9))
+	require.NoError(t, err)
+	dec := NewLz4Decoder(WithMaxDecompressionSize(maxDecompressionSize))
+
+	payload, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	actual, err := dec.Decode(payload)
+	require.NoError(t, err)
+
+	require.Equal(t, "howdy", string(actual))
+
+	payload, err = enc.Encode([]byte("doody"))
+	require.NoError(t, err)
+
+	actual, err = dec.Decode(payload)
+	require.NoError(t, err)
+
+	require.Equal(t, "doody", string(actual))
+}
+
+func TestLz4EncodeDecodeWithTooLargeMessage(t *testing.T) {
+	enc, err := NewLz4Encoder()
+	require.NoError(t, err)
+	dec := NewLz4Decoder(WithMaxDecompressionSize(3))
+
+	payload, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	_, err = dec.Decode(payload)
+	require.ErrorContains(t, err, "size of decoded data exceeds allowed size 3")
+}
+
 func TestIdentityEncodeDecode(t *testing.T) {
 	dec := NewIdentityDecoder(WithMaxDecompressionSize(maxDecompressionSize))
 	enc, err := NewIdentityEncoder()
@@ -181,6 +241,15 @@ func TestCompressionLevel(t *testing.T) {
 			validLevels: []int{1, 3, 7, 11},
 			errormsg:    "invalid compression level",
 		},
+		{
+			algorithm:   "lz4",
+			validLevels: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			errormsg:    "invalid compression level",
+		},
+		{
+			algorithm: "snappy",
+			errormsg:  "does not support options",
+		},
 		{
 			algorithm: "identity",
 			errormsg:  "does not support options",
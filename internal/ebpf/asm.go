@@ -0,0 +1,186 @@
+// Package ebpf provides a minimal assembler for eBPF programs and wrappers of
+// the bpf(2) system call to load the programs and access their maps.
+package ebpf
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Instruction classes, sizes, modes and operations as defined in
+// include/uapi/linux/bpf_common.h and include/uapi/linux/bpf.h
+const (
+	ClassLD    = 0x00
+	ClassLDX   = 0x01
+	ClassST    = 0x02
+	ClassSTX   = 0x03
+	ClassALU   = 0x04
+	ClassJMP   = 0x05
+	ClassALU64 = 0x07
+
+	SizeW  = 0x00
+	SizeH  = 0x08
+	SizeB  = 0x10
+	SizeDW = 0x18
+
+	ModeIMM  = 0x00
+	ModeMEM  = 0x60
+	ModeXADD = 0xc0
+
+	SrcK = 0x00
+	SrcX = 0x08
+
+	OpAdd  = 0x00
+	OpSub  = 0x10
+	OpAnd  = 0x50
+	OpLsh  = 0x60
+	OpRsh  = 0x70
+	OpMov  = 0xb0
+	OpArsh = 0xc0
+
+	JmpJA   = 0x00
+	JmpJEQ  = 0x10
+	JmpJGT  = 0x20
+	JmpJNE  = 0x50
+	JmpCall = 0x80
+	JmpExit = 0x90
+	JmpJSLE = 0xd0
+
+	PseudoMapFD = 1
+)
+
+// Helper functions callable by programs
+const (
+	FuncMapLookupElem     = 1
+	FuncMapUpdateElem     = 2
+	FuncProbeRead         = 4
+	FuncGetCurrentPidTgid = 14
+)
+
+// RegisterFramePtr is the read-only frame pointer register
+const RegisterFramePtr = 10
+
+// Instruction is a single eBPF instruction. Jumps may reference a label as
+// target which is resolved when assembling the program.
+type Instruction struct {
+	Opcode uint8
+	Dst    uint8
+	Src    uint8
+	Offset int16
+	Imm    int32
+	Target string
+}
+
+// Assembler collects instructions and resolves symbolic jump targets
+type Assembler struct {
+	instructions []Instruction
+	labels       map[string]int
+}
+
+func NewAssembler() *Assembler {
+	return &Assembler{labels: make(map[string]int)}
+}
+
+func (a *Assembler) Emit(ins Instruction) {
+	a.instructions = append(a.instructions, ins)
+}
+
+// Label marks the position of the next instruction as jump target
+func (a *Assembler) Label(name string) {
+	a.labels[name] = len(a.instructions)
+}
+
+func (a *Assembler) MovReg(dst, src uint8) {
+	a.Emit(Instruction{Opcode: ClassALU64 | OpMov | SrcX, Dst: dst, Src: src})
+}
+
+func (a *Assembler) MovImm(dst uint8, imm int32) {
+	a.Emit(Instruction{Opcode: ClassALU64 | OpMov | SrcK, Dst: dst, Imm: imm})
+}
+
+// MovImm32 loads the immediate zero-extended into the register
+func (a *Assembler) MovImm32(dst uint8, imm uint32) {
+	a.Emit(Instruction{Opcode: ClassALU | OpMov | SrcK, Dst: dst, Imm: int32(imm)})
+}
+
+func (a *Assembler) ALUImm(op, dst uint8, imm int32) {
+	a.Emit(Instruction{Opcode: ClassALU64 | op | SrcK, Dst: dst, Imm: imm})
+}
+
+func (a *Assembler) ALUReg(op, dst, src uint8) {
+	a.Emit(Instruction{Opcode: ClassALU64 | op | SrcX, Dst: dst, Src: src})
+}
+
+func (a *Assembler) Load(size, dst, src uint8, offset int16) {
+	a.Emit(Instruction{Opcode: ClassLDX | ModeMEM | size, Dst: dst, Src: src, Offset: offset})
+}
+
+func (a *Assembler) Store(size, dst, src uint8, offset int16) {
+	a.Emit(Instruction{Opcode: ClassSTX | ModeMEM | size, Dst: dst, Src: src, Offset: offset})
+}
+
+func (a *Assembler) StoreImm(size, dst uint8, offset int16, imm int32) {
+	a.Emit(Instruction{Opcode: ClassST | ModeMEM | size, Dst: dst, Offset: offset, Imm: imm})
+}
+
+// AtomicAdd adds the source register to the memory at the destination
+// register and offset atomically
+func (a *Assembler) AtomicAdd(size, dst, src uint8, offset int16) {
+	a.Emit(Instruction{Opcode: ClassSTX | ModeXADD | size, Dst: dst, Src: src, Offset: offset})
+}
+
+// LoadMapFD loads the map referenced by the file descriptor into the
+// register using the two-instruction wide load
+func (a *Assembler) LoadMapFD(dst uint8, fd int) {
+	a.Emit(Instruction{Opcode: ClassLD | ModeIMM | SizeDW, Dst: dst, Src: PseudoMapFD, Imm: int32(fd)})
+	a.Emit(Instruction{})
+}
+
+func (a *Assembler) Jump(target string) {
+	a.Emit(Instruction{Opcode: ClassJMP | JmpJA, Target: target})
+}
+
+func (a *Assembler) JumpImm(op, dst uint8, imm int32, target string) {
+	a.Emit(Instruction{Opcode: ClassJMP | op | SrcK, Dst: dst, Imm: imm, Target: target})
+}
+
+func (a *Assembler) JumpReg(op, dst, src uint8, target string) {
+	a.Emit(Instruction{Opcode: ClassJMP | op | SrcX, Dst: dst, Src: src, Target: target})
+}
+
+func (a *Assembler) Call(fn int32) {
+	a.Emit(Instruction{Opcode: ClassJMP | JmpCall, Imm: fn})
+}
+
+func (a *Assembler) Exit() {
+	a.Emit(Instruction{Opcode: ClassJMP | JmpExit})
+}
+
+// Assemble resolves the jump targets and returns the encoded program
+func (a *Assembler) Assemble() ([]byte, error) {
+	buf := make([]byte, 0, 8*len(a.instructions))
+	for i, ins := range a.instructions {
+		if ins.Target != "" {
+			pos, found := a.labels[ins.Target]
+			if !found {
+				return nil, fmt.Errorf("undefined label %q", ins.Target)
+			}
+			offset := pos - i - 1
+			if offset < -32768 || offset > 32767 {
+				return nil, fmt.Errorf("jump to %q out of range", ins.Target)
+			}
+			ins.Offset = int16(offset)
+		}
+
+		// The register nibbles are bitfields in struct bpf_insn so their
+		// position depends on the byte order of the machine.
+		regs := ins.Dst&0x0f | ins.Src<<4
+		if binary.NativeEndian.Uint16([]byte{0x00, 0x01}) == 0x0001 {
+			regs = ins.Dst<<4 | ins.Src&0x0f
+		}
+		buf = append(buf, ins.Opcode, regs)
+		buf = binary.NativeEndian.AppendUint16(buf, uint16(ins.Offset))
+		buf = binary.NativeEndian.AppendUint32(buf, uint32(ins.Imm))
+	}
+	return buf, nil
+}
@@ -0,0 +1,47 @@
+package ebpf
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssemble(t *testing.T) {
+	a := NewAssembler()
+	a.MovImm(0, 0)
+	a.JumpImm(JmpJEQ, 1, 42, "out")
+	a.MovImm(0, 1)
+	a.Label("out")
+	a.Exit()
+
+	prog, err := a.Assemble()
+	require.NoError(t, err)
+	require.Len(t, prog, 4*8)
+
+	// The jump skips the following instruction
+	jump := prog[8:16]
+	require.Equal(t, uint8(ClassJMP|JmpJEQ|SrcK), jump[0])
+	require.Equal(t, int16(1), int16(binary.NativeEndian.Uint16(jump[2:4])))
+	require.Equal(t, int32(42), int32(binary.NativeEndian.Uint32(jump[4:8])))
+	require.Equal(t, uint8(ClassJMP|JmpExit), prog[24])
+}
+
+func TestAssemblerUndefinedLabel(t *testing.T) {
+	a := NewAssembler()
+	a.Jump("nowhere")
+	_, err := a.Assemble()
+	require.ErrorContains(t, err, `undefined label "nowhere"`)
+}
+
+func TestAssemblerJumpOutOfRange(t *testing.T) {
+	a := NewAssembler()
+	a.Jump("end")
+	for i := 0; i < 40000; i++ {
+		a.MovImm(0, 0)
+	}
+	a.Label("end")
+	a.Exit()
+	_, err := a.Assemble()
+	require.ErrorContains(t, err, `jump to "end" out of range`)
+}
@@ -0,0 +1,253 @@
+package ebpf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// bpf issues the bpf(2) system call with the given command and attributes
+func bpf(cmd uintptr, attr unsafe.Pointer, size uintptr) (int, error) {
+	fd, _, errno := unix.Syscall(unix.SYS_BPF, cmd, uintptr(attr), size)
+	if errno != 0 {
+		return -1, errno
+	}
+	return int(fd), nil
+}
+
+// CreateMap creates a map of the given type and returns its file descriptor
+func CreateMap(mapType, keySize, valueSize, maxEntries uint32) (int, error) {
+	attr := struct {
+		mapType    uint32
+		keySize    uint32
+		valueSize  uint32
+		maxEntries uint32
+		mapFlags   uint32
+	}{
+		mapType:    mapType,
+		keySize:    keySize,
+		valueSize:  valueSize,
+		maxEntries: maxEntries,
+	}
+	return bpf(unix.BPF_MAP_CREATE, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+}
+
+// LoadProgram loads the program of the given type into the kernel and
+// returns its file descriptor. The kernel version is only checked for kprobe
+// programs by kernels before 5.0. The verifier log is only requested when
+// loading fails, as a log exceeding the buffer causes the load to fail on
+// older kernels even for valid programs.
+func LoadProgram(progType uint32, prog []byte, name string, kernelVersion uint32) (int, error) {
+	fd, err := loadProgram(progType, prog, name, kernelVersion, nil)
+	if err == nil {
+		return fd, nil
+	}
+
+	logBuf := make([]byte, 1024*1024)
+	if _, errLog := loadProgram(progType, prog, name, kernelVersion, logBuf); errLog != nil {
+		if verifierLog := strings.TrimRight(string(logBuf), "\x00"); verifierLog != "" {
+			return -1, fmt.Errorf("%w: %s", err, verifierLog)
+		}
+	}
+	return -1, err
+}
+
+func loadProgram(progType uint32, prog []byte, name string, kernelVersion uint32, logBuf []byte) (int, error) {
+	license := []byte("GPL\x00")
+	attr := struct {
+		progType    uint32
+		insnCnt     uint32
+		insns       uint64
+		license     uint64
+		logLevel    uint32
+		logSize     uint32
+		logBuf      uint64
+		kernVersion uint32
+		progFlags   uint32
+		progName    [16]byte
+	}{
+		progType:    progType,
+		insnCnt:     uint32(len(prog) / 8),
+		insns:       uint64(uintptr(unsafe.Pointer(&prog[0]))),
+		license:     uint64(uintptr(unsafe.Pointer(&license[0]))),
+		kernVersion: kernelVersion,
+	}
+	if len(logBuf) > 0 {
+		attr.logLevel = 1
+		attr.logSize = uint32(len(logBuf))
+		attr.logBuf = uint64(uintptr(unsafe.Pointer(&logBuf[0])))
+	}
+	// The name is truncated to leave room for the terminating null byte
+	copy(attr.progName[:len(attr.progName)-1], name)
+
+	fd, err := bpf(unix.BPF_PROG_LOAD, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	runtime.KeepAlive(prog)
+	runtime.KeepAlive(license)
+	runtime.KeepAlive(logBuf)
+	return fd, err
+}
+
+// CreateLink attaches the program to the target using a BPF link and returns
+// the file descriptor of the link. The program is detached when the link is
+// closed.
+func CreateLink(progFD, targetFD int, attachType, flags uint32) (int, error) {
+	attr := struct {
+		progFD     uint32
+		targetFD   uint32
+		attachType uint32
+		flags      uint32
+	}{
+		progFD:     uint32(progFD),
+		targetFD:   uint32(targetFD),
+		attachType: attachType,
+		flags:      flags,
+	}
+	return bpf(unix.BPF_LINK_CREATE, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+}
+
+// MapLookup copies the value of the key in the map to the given buffer
+func MapLookup(fd int, key, value []byte) error {
+	attr := struct {
+		mapFD uint32
+		_     uint32
+		key   uint64
+		value uint64
+		flags uint64
+	}{
+		mapFD: uint32(fd),
+		key:   uint64(uintptr(unsafe.Pointer(&key[0]))),
+		value: uint64(uintptr(unsafe.Pointer(&value[0]))),
+	}
+	_, err := bpf(unix.BPF_MAP_LOOKUP_ELEM, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	runtime.KeepAlive(key)
+	runtime.KeepAlive(value)
+	return err
+}
+
+// MapDelete removes the key from the map
+func MapDelete(fd int, key []byte) error {
+	attr := struct {
+		mapFD uint32
+		_     uint32
+		key   uint64
+	}{
+		mapFD: uint32(fd),
+		key:   uint64(uintptr(unsafe.Pointer(&key[0]))),
+	}
+	_, err := bpf(unix.BPF_MAP_DELETE_ELEM, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	runtime.KeepAlive(key)
+	return err
+}
+
+// MapGetNextKey returns the key following the given one, or the first key
+// if no key is given
+func MapGetNextKey(fd int, key, next []byte) error {
+	attr := struct {
+		mapFD   uint32
+		_       uint32
+		key     uint64
+		nextKey uint64
+	}{
+		mapFD:   uint32(fd),
+		nextKey: uint64(uintptr(unsafe.Pointer(&next[0]))),
+	}
+	if key != nil {
+		attr.key = uint64(uintptr(unsafe.Pointer(&key[0])))
+	}
+	_, err := bpf(unix.BPF_MAP_GET_NEXT_KEY, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	runtime.KeepAlive(key)
+	runtime.KeepAlive(next)
+	return err
+}
+
+// RunProgram runs the program once on the given input data using
+// BPF_PROG_TEST_RUN (Linux 4.12 and later) and returns the program's result
+func RunProgram(progFD int, data []byte) (uint32, error) {
+	attr := struct {
+		progFD      uint32
+		retval      uint32
+		dataSizeIn  uint32
+		dataSizeOut uint32
+		dataIn      uint64
+		dataOut     uint64
+		repeat      uint32
+		duration    uint32
+	}{
+		progFD:     uint32(progFD),
+		dataSizeIn: uint32(len(data)),
+		dataIn:     uint64(uintptr(unsafe.Pointer(&data[0]))),
+		repeat:     1,
+	}
+	_, err := bpf(unix.BPF_PROG_TEST_RUN, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	runtime.KeepAlive(data)
+	return attr.retval, err
+}
+
+// PossibleCPUs returns the number of possible CPUs which determines the
+// number of values in per-CPU maps
+func PossibleCPUs() (int, error) {
+	buf, err := os.ReadFile("/sys/devices/system/cpu/possible")
+	if err != nil {
+		return 0, err
+	}
+	return parseCPURange(strings.TrimSpace(string(buf)))
+}
+
+func parseCPURange(s string) (int, error) {
+	var count int
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		if !isRange {
+			last = first
+		}
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return 0, err
+		}
+		end, err := strconv.Atoi(last)
+		if err != nil {
+			return 0, err
+		}
+		if end < start {
+			return 0, errors.New("invalid CPU range " + part)
+		}
+		count += end - start + 1
+	}
+	return count, nil
+}
+
+// KernelVersion returns the running kernel version in the format of
+// LINUX_VERSION_CODE
+func KernelVersion() (uint32, error) {
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err != nil {
+		return 0, err
+	}
+	return parseKernelVersion(unix.ByteSliceToString(uts.Release[:]))
+}
+
+func parseKernelVersion(release string) (uint32, error) {
+	// Strip suffixes like "-generic" or "+"
+	if i := strings.IndexFunc(release, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		release = release[:i]
+	}
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return 0, fmt.Errorf("invalid kernel release %q", release)
+	}
+	var version [3]uint64
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid kernel release %q: %w", release, err)
+		}
+		version[i] = v
+	}
+	return uint32(version[0]<<16 | version[1]<<8 | min(version[2], 255)), nil
+}
@@ -0,0 +1,52 @@
+package ebpf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPURange(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{input: "0", expected: 1},
+		{input: "0-7", expected: 8},
+		{input: "0-3,8-11", expected: 8},
+		{input: "0,2,4-5", expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := parseCPURange(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	_, err := parseCPURange("7-0")
+	require.Error(t, err)
+}
+
+func TestParseKernelVersion(t *testing.T) {
+	tests := []struct {
+		release  string
+		expected uint32
+	}{
+		{"4.15.0-213-generic", 4<<16 | 15<<8},
+		{"5.10.209", 5<<16 | 10<<8 | 209},
+		{"6.1.300+rpt-rpi-v8", 6<<16 | 1<<8 | 255},
+		{"6.8", 6<<16 | 8<<8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.release, func(t *testing.T) {
+			actual, err := parseKernelVersion(tt.release)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	_, err := parseKernelVersion("linux")
+	require.Error(t, err)
+}
@@ -14,6 +14,16 @@ import (
 	"github.com/influxdata/telegraf"
 )
 
+// Restart policies of a process
+const (
+	// RestartAlways restarts the process whenever it exits
+	RestartAlways = "always"
+	// RestartOnFailure restarts the process only if it exits with an error
+	RestartOnFailure = "on-failure"
+	// RestartNever keeps the process stopped after it exits
+	RestartNever = "never"
+)
+
 // Process is a long-running process manager that will restart processes if they stop.
 type Process struct {
 	Cmd          *exec.Cmd
@@ -26,12 +36,25 @@ type Process struct {
 	StopOnError  bool
 	Log          telegraf.Logger
 
+	// RestartPolicy determines if the process is restarted after it exited,
+	// an empty policy is equivalent to RestartAlways.
+	RestartPolicy string
+	// RestartDelayMax enables an exponential backoff of the restart delay up
+	// to the given maximum if larger than RestartDelay. The backoff is reset
+	// once the process ran for at least the maximum delay.
+	RestartDelayMax time.Duration
+	// MaxRuntime terminates and restarts the process after it ran for the
+	// given duration if non-zero.
+	MaxRuntime time.Duration
+
 	name       string
 	args       []string
 	envs       []string
 	pid        int32
 	cancel     context.CancelFunc
 	mainLoopWg sync.WaitGroup
+	started    time.Time
+	expired    atomic.Bool
 
 	sync.Mutex
 }
@@ -131,11 +154,14 @@ func (p *Process) cmdStart() error {
 		return fmt.Errorf("error starting process: %w", err)
 	}
 	atomic.StoreInt32(&p.pid, int32(p.Cmd.Process.Pid))
+	p.started = time.Now()
+	p.expired.Store(false)
 	return nil
 }
 
 // cmdLoop watches an already running process, restarting it when appropriate.
 func (p *Process) cmdLoop(ctx context.Context) error {
+	var failures int
 	for {
 		err := p.cmdWait(ctx)
 		if err != nil && p.StopOnError {
@@ -146,13 +172,34 @@ func (p *Process) cmdLoop(ctx context.Context) error {
 			return nil
 		}
 
+		// Processes terminated due to exceeding their runtime are restarted
+		// immediately independent of the restart policy.
+		if p.expired.Load() {
+			p.Log.Infof("Process %s exceeded the maximum runtime of %s, restarting...", p.Cmd.Path, p.MaxRuntime)
+			if err := p.cmdStart(); err != nil {
+				return err
+			}
+			failures = 0
+			continue
+		}
+
 		p.Log.Errorf("Process %s exited: %v", p.Cmd.Path, err)
-		p.Log.Infof("Restarting in %s...", p.RestartDelay)
+		if p.RestartPolicy == RestartNever || (p.RestartPolicy == RestartOnFailure && err == nil) {
+			p.Log.Infof("Not restarting process %s due to restart policy %q", p.Cmd.Path, p.RestartPolicy)
+			return nil
+		}
+
+		if time.Since(p.started) >= p.RestartDelayMax {
+			failures = 0
+		}
+		delay := p.restartDelay(failures)
+		failures++
+		p.Log.Infof("Restarting in %s...", delay)
 
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-time.After(p.RestartDelay):
+		case <-time.After(delay):
 			// Continue the loop and restart the process
 			if err := p.cmdStart(); err != nil {
 				return err
@@ -161,6 +208,19 @@ func (p *Process) cmdLoop(ctx context.Context) error {
 	}
 }
 
+// restartDelay returns the delay before restarting the process after the
+// given number of consecutive failures
+func (p *Process) restartDelay(failures int) time.Duration {
+	delay := p.RestartDelay
+	if p.RestartDelayMax <= delay {
+		return delay
+	}
+	for i := 0; i < failures && delay < p.RestartDelayMax; i++ {
+		delay *= 2
+	}
+	return min(delay, p.RestartDelayMax)
+}
+
 // cmdWait waits for the process to finish.
 func (p *Process) cmdWait(ctx context.Context) error {
 	var wg sync.WaitGroup
@@ -187,11 +247,25 @@ func (p *Process) cmdWait(ctx context.Context) error {
 		wg.Done()
 	}()
 
+	var expiry <-chan time.Time
+	if p.MaxRuntime > 0 {
+		timer := time.NewTimer(p.MaxRuntime - time.Since(p.started))
+		defer timer.Stop()
+		expiry = timer.C
+	}
+
 	wg.Add(1)
 	go func() {
 		select {
 		case <-ctx.Done():
 			p.gracefulStop(processCtx, p.Cmd, 5*time.Second)
+		case <-expiry:
+			// Close stdin to ask the process to shut down gracefully
+			p.expired.Store(true)
+			if err := p.Stdin.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+				p.Log.Errorf("Stdin closed with message: %v", err)
+			}
+			p.gracefulStop(processCtx, p.Cmd, 5*time.Second)
 		case <-processCtx.Done():
 		}
 		wg.Done()
@@ -56,6 +56,59 @@ func TestRestartingRebindsPipes(t *testing.T) {
 	p.Stop()
 }
 
+func TestRestartDelay(t *testing.T) {
+	p := &Process{RestartDelay: time.Second}
+	for failures := 0; failures < 5; failures++ {
+		require.Equal(t, time.Second, p.restartDelay(failures))
+	}
+
+	p.RestartDelayMax = 10 * time.Second
+	expected := []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+	}
+	for failures, delay := range expected {
+		require.Equal(t, delay, p.restartDelay(failures))
+	}
+}
+
+func TestMaxRuntimeRestartsProcess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping long running test in short mode")
+	}
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	p, err := New([]string{exe, "-external"}, []string{"INTERNAL_PROCESS_MODE=application"})
+	require.NoError(t, err)
+	p.RestartPolicy = RestartNever
+	p.MaxRuntime = 100 * time.Millisecond
+	p.Log = testutil.Logger{}
+
+	linesRead := int64(0)
+	p.ReadStdoutFn = func(r io.Reader) {
+		scanner := bufio.NewScanner(r)
+
+		for scanner.Scan() {
+			atomic.AddInt64(&linesRead, 1)
+		}
+	}
+
+	require.NoError(t, p.Start())
+
+	// The process must be restarted despite the restart policy
+	for atomic.LoadInt64(&linesRead) < 2 {
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	p.Stop()
+}
+
 var external = flag.Bool("external", false,
 	"if true, run externalProcess instead of tests")
 
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// outputConstraints enforces the constraints declared by an output
+type outputConstraints struct {
+	telegraf.OutputConstraints
+
+	replacer *strings.Replacer
+
+	namesTruncated selfstat.Stat
+	tagsDropped    selfstat.Stat
+	tagsTruncated  selfstat.Stat
+}
+
+func newOutputConstraints(c telegraf.OutputConstraints, tags map[string]string) *outputConstraints {
+	oc := &outputConstraints{
+		OutputConstraints: c,
+		namesTruncated:    selfstat.Register("write", "names_truncated", tags),
+		tagsDropped:       selfstat.Register("write", "tags_dropped", tags),
+		tagsTruncated:     selfstat.Register("write", "tags_truncated", tags),
+	}
+
+	if c.ForbiddenCharacters != "" {
+		oldnew := make([]string, 0, 2*len(c.ForbiddenCharacters))
+		for _, r := range c.ForbiddenCharacters {
+			oldnew = append(oldnew, string(r), c.Replacement)
+		}
+		oc.replacer = strings.NewReplacer(oldnew...)
+	}
+
+	return oc
+}
+
+// apply modifies the metric in-place to satisfy the constraints
+func (c *outputConstraints) apply(m telegraf.Metric) {
+	name := m.Name()
+	if c.replacer != nil {
+		name = c.replacer.Replace(name)
+	}
+	if c.MaxNameLength > 0 && len(name) > c.MaxNameLength {
+		name = truncate(name, c.MaxNameLength)
+		c.namesTruncated.Incr(1)
+	}
+	if name != m.Name() {
+		m.SetName(name)
+	}
+
+	// Copy the tag list as we are going to modify the tags while iterating
+	var kept int
+	for _, tag := range append([]*telegraf.Tag(nil), m.TagList()...) {
+		key, value := tag.Key, tag.Value
+		if c.replacer != nil {
+			key = c.replacer.Replace(key)
+		}
+
+		if (c.MaxTagKeyLength > 0 && len(key) > c.MaxTagKeyLength) ||
+			(c.MaxTagLength > 0 && len(key) > c.MaxTagLength) ||
+			(c.MaxTags > 0 && kept >= c.MaxTags) {
+			m.RemoveTag(tag.Key)
+			c.tagsDropped.Incr(1)
+			continue
+		}
+		kept++
+
+		// A key using up the whole tag length is kept with an empty value
+		maxValueLength := -1
+		if c.MaxTagValueLength > 0 {
+			maxValueLength = c.MaxTagValueLength
+		}
+		if c.MaxTagLength > 0 && (maxValueLength < 0 || c.MaxTagLength-len(key) < maxValueLength) {
+			maxValueLength = c.MaxTagLength - len(key)
+		}
+		if maxValueLength >= 0 && len(value) > maxValueLength {
+			value = truncate(value, maxValueLength)
+			c.tagsTruncated.Incr(1)
+		}
+
+		if key != tag.Key {
+			m.RemoveTag(tag.Key)
+		}
+		if key != tag.Key || value != tag.Value {
+			m.AddTag(key, value)
+		}
+	}
+}
+
+// truncate shortens the string to at most n bytes without splitting a
+// multi-byte character
+func truncate(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,53 @@
+package models
+
+// OutputGroup is a prioritized list of outputs of a failover group where
+// metrics are only written to the first healthy output. Outputs not being
+// part of a failover group form a group on their own.
+type OutputGroup struct {
+	Name    string
+	Outputs []*RunningOutput
+
+	active *RunningOutput
+}
+
+// GroupOutputs groups the outputs by their failover group using the order of
+// the outputs as priority within the group
+func GroupOutputs(outputs []*RunningOutput) []*OutputGroup {
+	groups := make([]*OutputGroup, 0, len(outputs))
+	named := make(map[string]*OutputGroup)
+	for _, output := range outputs {
+		name := output.Config.FailoverGroup
+		if name == "" {
+			groups = append(groups, &OutputGroup{Outputs: []*RunningOutput{output}})
+			continue
+		}
+
+		if group, found := named[name]; found {
+			group.Outputs = append(group.Outputs, output)
+			continue
+		}
+		group := &OutputGroup{Name: name, Outputs: []*RunningOutput{output}}
+		named[name] = group
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// Active returns the output to add metrics to, i.e. the first healthy output
+// or the output with the highest priority if none of the outputs is healthy
+func (g *OutputGroup) Active() *RunningOutput {
+	active := g.Outputs[0]
+	for _, output := range g.Outputs {
+		if output.Healthy() {
+			active = output
+			break
+		}
+	}
+
+	if g.active != nil && g.active != active {
+		active.Log().Infof("Failover group %q switched from %s", g.Name, g.active.LogName())
+	}
+	g.active = active
+
+	return active
+}
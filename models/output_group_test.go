@@ -0,0 +1,120 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGroupOutputs(t *testing.T) {
+	outputs := []*RunningOutput{
+		NewRunningOutput(&mockOutput{}, &OutputConfig{Name: "a", FailoverGroup: "backend"}, 10, 100),
+		NewRunningOutput(&mockOutput{}, &OutputConfig{Name: "b"}, 10, 100),
+		NewRunningOutput(&mockOutput{}, &OutputConfig{Name: "c", FailoverGroup: "backend"}, 10, 100),
+		NewRunningOutput(&mockOutput{}, &OutputConfig{Name: "d", FailoverGroup: "other"}, 10, 100),
+	}
+
+	groups := GroupOutputs(outputs)
+	require.Len(t, groups, 3)
+	require.Equal(t, "backend", groups[0].Name)
+	require.Equal(t, []*RunningOutput{outputs[0], outputs[2]}, groups[0].Outputs)
+	require.Empty(t, groups[1].Name)
+	require.Equal(t, []*RunningOutput{outputs[1]}, groups[1].Outputs)
+	require.Equal(t, "other", groups[2].Name)
+	require.Equal(t, []*RunningOutput{outputs[3]}, groups[2].Outputs)
+}
+
+func TestOutputGroupFailover(t *testing.T) {
+	primary := &mockOutput{}
+	secondary := &mockOutput{}
+	group := GroupOutputs([]*RunningOutput{
+		NewRunningOutput(primary, &OutputConfig{Name: "primary", FailoverGroup: "backend"}, 10, 100),
+		NewRunningOutput(secondary, &OutputConfig{Name: "secondary", FailoverGroup: "backend"}, 10, 100),
+	})[0]
+	ro1, ro2 := group.Outputs[0], group.Outputs[1]
+	require.NoError(t, ro1.Connect())
+	require.NoError(t, ro2.Connect())
+
+	// Metrics are only written to the primary while being healthy
+	for _, m := range first5 {
+		group.Active().AddMetric(m)
+	}
+	require.NoError(t, ro1.Write())
+	require.NoError(t, ro2.Write())
+	require.Len(t, primary.Metrics(), 5)
+	require.Empty(t, secondary.Metrics())
+
+	// Fail over to the secondary if writing to the primary fails
+	primary.batchAcceptSize = -1
+	for _, m := range next5 {
+		group.Active().AddMetric(m)
+	}
+	require.Error(t, ro1.Write())
+	require.False(t, ro1.Healthy())
+	require.Same(t, ro2, group.Active())
+	for _, m := range first5 {
+		group.Active().AddMetric(m)
+	}
+	require.NoError(t, ro2.Write())
+	require.Len(t, secondary.Metrics(), 5)
+
+	// Fail back after the primary recovered, buffered metrics are kept in the
+	// primary and not written to the secondary
+	primary.batchAcceptSize = 0
+	require.NoError(t, ro1.Write())
+	require.True(t, ro1.Healthy())
+	require.Same(t, ro1, group.Active())
+	require.Len(t, primary.Metrics(), 10)
+	require.Len(t, secondary.Metrics(), 5)
+}
+
+func TestOutputGroupNoneHealthy(t *testing.T) {
+	group := GroupOutputs([]*RunningOutput{
+		NewRunningOutput(&mockOutput{}, &OutputConfig{Name: "primary", FailoverGroup: "backend"}, 10, 100),
+		NewRunningOutput(&mockOutput{}, &OutputConfig{Name: "secondary", FailoverGroup: "backend"}, 10, 100),
+	})[0]
+	group.Outputs[0].unhealthy.Store(true)
+	group.Outputs[1].unhealthy.Store(true)
+	require.Same(t, group.Outputs[0], group.Active())
+}
+
+func TestOutputGroupProbe(t *testing.T) {
+	output := &probeOutput{probeErr: errors.New("unreachable")}
+	ro := NewRunningOutput(output, &OutputConfig{Name: "test", FailoverGroup: "backend"}, 10, 100)
+	require.NoError(t, ro.Connect())
+	ro.unhealthy.Store(true)
+
+	// Outputs without buffered metrics are probed for recovery
+	require.NoError(t, ro.Write())
+	require.Equal(t, 1, output.probes)
+	require.False(t, ro.Healthy())
+
+	output.probeErr = nil
+	require.NoError(t, ro.Write())
+	require.Equal(t, 2, output.probes)
+	require.True(t, ro.Healthy())
+
+	// Healthy outputs are not probed
+	ro.AddMetric(testutil.TestMetric(101, "metric1"))
+	require.NoError(t, ro.Write())
+	require.Equal(t, 2, output.probes)
+	require.Len(t, output.Metrics(), 1)
+}
+
+type probeOutput struct {
+	mockOutput
+
+	probeErr error
+	probes   int
+}
+
+func (m *probeOutput) Probe() error {
+	m.probes++
+	return m.probeErr
+}
+
+var _ telegraf.ProbePlugin = &probeOutput{}
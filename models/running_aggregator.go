@@ -32,6 +32,9 @@ func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConf
 
 	aggErrorsRegister := selfstat.Register("aggregate", "errors", tags)
 	logger := logging.New("aggregators", config.Name, config.Alias)
+	if config.ID != "" {
+		logger.AddAttribute("id", config.ID)
+	}
 	logger.RegisterErrorCallback(func() {
 		aggErrorsRegister.Incr(1)
 	})
@@ -78,6 +81,7 @@ type AggregatorConfig struct {
 	Delay        time.Duration
 	Grace        time.Duration
 	LogLevel     string
+	Pipeline     string
 
 	NameOverride      string
 	MeasurementPrefix string
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -29,11 +30,19 @@ type RunningInput struct {
 	retries     uint64
 	gatherStart time.Time
 	gatherEnd   time.Time
-
-	MetricsGathered selfstat.Stat
-	GatherTime      selfstat.Stat
-	GatherTimeouts  selfstat.Stat
-	StartupErrors   selfstat.Stat
+	// Number of errors logged during the current gather cycle
+	gatherErrors atomic.Int64
+	// Number of consecutive failed gather cycles of this instance
+	failures atomic.Int64
+	// Limiter for the number of series per measurement, nil if unlimited
+	limiter *seriesLimiter
+
+	MetricsGathered     selfstat.Stat
+	GatherTime          selfstat.Stat
+	GatherTimeouts      selfstat.Stat
+	StartupErrors       selfstat.Stat
+	ConsecutiveFailures selfstat.Stat
+	SeriesDropped       selfstat.Stat
 }
 
 func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
@@ -44,16 +53,11 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 
 	inputErrorsRegister := selfstat.Register("gather", "errors", tags)
 	logger := logging.New("inputs", config.Name, config.Alias)
-	logger.RegisterErrorCallback(func() {
-		inputErrorsRegister.Incr(1)
-		GlobalGatherErrors.Incr(1)
-	})
-	if err := logger.SetLogLevel(config.LogLevel); err != nil {
-		logger.Error(err)
+	if config.ID != "" {
+		logger.AddAttribute("id", config.ID)
 	}
-	SetLoggerOnPlugin(input, logger)
 
-	return &RunningInput{
+	r := &RunningInput{
 		Input:  input,
 		Config: config,
 		MetricsGathered: selfstat.Register(
@@ -76,8 +80,30 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 			"startup_errors",
 			tags,
 		),
+		ConsecutiveFailures: selfstat.Register(
+			"gather",
+			"consecutive_failures",
+			tags,
+		),
+		SeriesDropped: selfstat.Register(
+			"gather",
+			"series_dropped",
+			tags,
+		),
 		log: logger,
 	}
+
+	logger.RegisterErrorCallback(func() {
+		inputErrorsRegister.Incr(1)
+		GlobalGatherErrors.Incr(1)
+		r.gatherErrors.Add(1)
+	})
+	if err := logger.SetLogLevel(config.LogLevel); err != nil {
+		logger.Error(err)
+	}
+	SetLoggerOnPlugin(input, logger)
+
+	return r
 }
 
 // InputConfig is the common config for all inputs.
@@ -93,6 +119,11 @@ type InputConfig struct {
 	TimeSource           string
 	StartupErrorBehavior string
 	LogLevel             string
+	Pipeline             string
+
+	MaxSeriesPerMeasurement int
+	SeriesOverflowAction    string
+	SeriesTTL               time.Duration
 
 	NameOverride            string
 	MeasurementPrefix       string
@@ -126,6 +157,20 @@ func (r *RunningInput) Init() error {
 		return fmt.Errorf("invalid 'time_source' setting %q", r.Config.TimeSource)
 	}
 
+	switch r.Config.SeriesOverflowAction {
+	case "":
+		r.Config.SeriesOverflowAction = "drop"
+	case "drop", "aggregate":
+	default:
+		return fmt.Errorf("invalid 'series_overflow_action' setting %q", r.Config.SeriesOverflowAction)
+	}
+	if r.Config.MaxSeriesPerMeasurement > 0 {
+		if r.Config.SeriesTTL <= 0 {
+			r.Config.SeriesTTL = time.Hour
+		}
+		r.limiter = newSeriesLimiter(r.Config.MaxSeriesPerMeasurement, r.Config.SeriesTTL)
+	}
+
 	if p, ok := r.Input.(telegraf.Initializer); ok {
 		return p.Init()
 	}
@@ -234,11 +279,48 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 	default:
 	}
 
+	if r.limiter != nil && !r.limitSeries(metric) {
+		r.metricFiltered(metric)
+		return nil
+	}
+
 	r.MetricsGathered.Incr(1)
 	GlobalMetricsGathered.Incr(1)
 	return metric
 }
 
+// limitSeries checks the metric against the series limit and returns false if
+// the metric should be dropped. For the "aggregate" overflow action, the tags
+// of metrics exceeding the limit are replaced by the "series_overflow" tag to
+// collapse all new series into a single series per measurement.
+func (r *RunningInput) limitSeries(metric telegraf.Metric) bool {
+	accepted, first := r.limiter.accept(metric, time.Now())
+	if accepted {
+		return true
+	}
+	r.SeriesDropped.Incr(1)
+
+	if first {
+		r.log.Warnf("Measurement %q exceeds the limit of %d series, applying %q to new series",
+			metric.Name(), r.Config.MaxSeriesPerMeasurement, r.Config.SeriesOverflowAction)
+	}
+	if r.Config.SeriesOverflowAction != "aggregate" {
+		return false
+	}
+
+	keys := make([]string, 0, len(metric.TagList()))
+	for _, tag := range metric.TagList() {
+		keys = append(keys, tag.Key)
+	}
+	for _, key := range keys {
+		metric.RemoveTag(key)
+	}
+	metric.AddTag("series_overflow", "true")
+	makeMetric(metric, "", "", "", r.Config.Tags, r.defaultTags)
+
+	return true
+}
+
 func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 	// Try to connect if we are not yet started up
 	if plugin, ok := r.Input.(telegraf.ServiceInput); ok && !r.started {
@@ -247,6 +329,7 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 			var serr *internal.StartupError
 			if !errors.As(err, &serr) || !serr.Retry || !serr.Partial {
 				r.StartupErrors.Incr(1)
+				r.ConsecutiveFailures.Set(r.failures.Add(1))
 				return internal.ErrNotConnected
 			}
 			r.log.Debugf("Partially connected after %d attempts", r.retries)
@@ -256,14 +339,28 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	r.gatherErrors.Store(0)
 	r.gatherStart = time.Now()
 	err := r.Input.Gather(acc)
 	r.gatherEnd = time.Now()
 
 	r.GatherTime.Incr(r.gatherEnd.Sub(r.gatherStart).Nanoseconds())
+
+	// A gather cycle failed if an error was returned or added
+	if err != nil || r.gatherErrors.Load() > 0 {
+		r.ConsecutiveFailures.Set(r.failures.Add(1))
+	} else {
+		r.failures.Store(0)
+		r.ConsecutiveFailures.Set(0)
+	}
 	return err
 }
 
+// Failures returns the number of consecutive failed gather cycles
+func (r *RunningInput) Failures() int64 {
+	return r.failures.Load()
+}
+
 func (r *RunningInput) SetDefaultTags(tags map[string]string) {
 	r.defaultTags = tags
 }
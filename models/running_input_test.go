@@ -1,13 +1,16 @@
 package models
 
 import (
+	"bytes"
 	"errors"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
@@ -488,6 +491,129 @@ func TestRunningInputMakeMetricWithGatherEndTimeSource(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestRunningInputConsecutiveFailures(t *testing.T) {
+	input := &errorInput{}
+	ri := NewRunningInput(input, &InputConfig{Name: "TestRunningInput"})
+
+	var acc testutil.Accumulator
+	input.err = errors.New("gather error")
+	require.Error(t, ri.Gather(&acc))
+	require.Equal(t, int64(1), ri.Failures())
+	require.Equal(t, int64(1), ri.ConsecutiveFailures.Get())
+
+	// Errors logged during the gather cycle count as failure
+	input.err = nil
+	input.logError = true
+	require.NoError(t, ri.Gather(&acc))
+	require.Equal(t, int64(2), ri.Failures())
+
+	input.logError = false
+	require.NoError(t, ri.Gather(&acc))
+	require.Equal(t, int64(0), ri.Failures())
+	require.Equal(t, int64(0), ri.ConsecutiveFailures.Get())
+}
+
+func TestRunningInputSeriesLimit(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:                    "TestRunningInputSeriesLimit",
+		MaxSeriesPerMeasurement: 2,
+	})
+	require.NoError(t, ri.Init())
+
+	now := time.Now()
+	for _, id := range []string{"a", "b", "c", "a", "d", "b"} {
+		m := metric.New("cpu", map[string]string{"id": id}, map[string]interface{}{"value": 42}, now)
+		if actual := ri.MakeMetric(m); actual != nil {
+			require.Equal(t, "cpu", actual.Name())
+			require.Contains(t, []string{"a", "b"}, actual.Tags()["id"])
+		}
+	}
+	require.Equal(t, int64(2), ri.SeriesDropped.Get())
+	require.Equal(t, int64(4), ri.MetricsGathered.Get())
+
+	// The limit is per measurement
+	m := metric.New("mem", map[string]string{"id": "c"}, map[string]interface{}{"value": 42}, now)
+	require.NotNil(t, ri.MakeMetric(m))
+}
+
+func TestRunningInputSeriesLimitAggregate(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:                    "TestRunningInputSeriesLimitAggregate",
+		Tags:                    map[string]string{"foo": "bar"},
+		MaxSeriesPerMeasurement: 1,
+		SeriesOverflowAction:    "aggregate",
+	})
+	ri.SetDefaultTags(map[string]string{"host": "localhost"})
+	require.NoError(t, ri.Init())
+
+	now := time.Now()
+	first := metric.New("cpu", map[string]string{"id": "a"}, map[string]interface{}{"value": 1}, now)
+	second := metric.New("cpu", map[string]string{"id": "b"}, map[string]interface{}{"value": 2}, now)
+
+	expected := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"id": "a", "foo": "bar", "host": "localhost"},
+			map[string]interface{}{"value": 1},
+			now,
+		),
+		metric.New("cpu",
+			map[string]string{"series_overflow": "true", "foo": "bar", "host": "localhost"},
+			map[string]interface{}{"value": 2},
+			now,
+		),
+	}
+	actual := []telegraf.Metric{ri.MakeMetric(first), ri.MakeMetric(second)}
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.Equal(t, int64(1), ri.SeriesDropped.Get())
+}
+
+func TestRunningInputSeriesLimitExpiry(t *testing.T) {
+	limiter := newSeriesLimiter(1, time.Minute)
+
+	now := time.Now()
+	a := metric.New("cpu", map[string]string{"id": "a"}, map[string]interface{}{"value": 1}, now)
+	b := metric.New("cpu", map[string]string{"id": "b"}, map[string]interface{}{"value": 1}, now)
+
+	accepted, _ := limiter.accept(a, now)
+	require.True(t, accepted)
+	accepted, first := limiter.accept(b, now)
+	require.False(t, accepted)
+	require.True(t, first)
+	accepted, first = limiter.accept(b, now.Add(30*time.Second))
+	require.False(t, accepted)
+	require.False(t, first)
+
+	// The inactive series expires and frees the slot
+	accepted, _ = limiter.accept(b, now.Add(2*time.Minute))
+	require.True(t, accepted)
+	accepted, _ = limiter.accept(a, now.Add(2*time.Minute))
+	require.False(t, accepted)
+}
+
+func TestRunningInputSeriesLimitInvalidAction(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:                 "TestRunningInput",
+		SeriesOverflowAction: "foo",
+	})
+	require.ErrorContains(t, ri.Init(), "invalid 'series_overflow_action' setting")
+}
+
+func TestRunningInputLogAttributes(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	logger.RedirectLogging(buf)
+	defer logger.RedirectLogging(os.Stderr)
+
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:  "TestRunningInput",
+		Alias: "foo",
+		ID:    "1234",
+	})
+	ri.Log().Error("test")
+
+	require.Contains(t, buf.String(), "alias=foo")
+	require.Contains(t, buf.String(), "id=1234")
+}
+
 func TestRunningInputProbingFailure(t *testing.T) {
 	ri := NewRunningInput(&mockInput{
 		probeReturn: errors.New("probing error"),
@@ -548,3 +674,20 @@ func (m *mockInput) Probe() error {
 func (*mockInput) Gather(telegraf.Accumulator) error {
 	return nil
 }
+
+type errorInput struct {
+	err      error
+	logError bool
+	Log      telegraf.Logger `toml:"-"`
+}
+
+func (*errorInput) SampleConfig() string {
+	return ""
+}
+
+func (i *errorInput) Gather(telegraf.Accumulator) error {
+	if i.logError {
+		i.Log.Error("gather error")
+	}
+	return i.err
+}
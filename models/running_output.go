@@ -28,6 +28,8 @@ type OutputConfig struct {
 	Alias                string
 	ID                   string
 	StartupErrorBehavior string
+	FailoverGroup        string
+	Pipeline             string
 	Filter               Filter
 
 	FlushInterval     time.Duration
@@ -56,17 +58,22 @@ type RunningOutput struct {
 	MetricBufferLimit int
 	MetricBatchSize   int
 
-	MetricsFiltered selfstat.Stat
-	WriteTime       selfstat.Stat
-	StartupErrors   selfstat.Stat
+	MetricsFiltered     selfstat.Stat
+	WriteTime           selfstat.Stat
+	StartupErrors       selfstat.Stat
+	ConsecutiveFailures selfstat.Stat
 
 	BatchReady chan time.Time
 
-	buffer Buffer
-	log    telegraf.Logger
+	buffer      Buffer
+	log         telegraf.Logger
+	constraints *outputConstraints
 
-	started bool
-	retries uint64
+	started   bool
+	retries   uint64
+	unhealthy atomic.Bool
+	// Number of consecutive failed connects or writes of this instance
+	failures atomic.Int64
 
 	aggMutex sync.Mutex
 }
@@ -79,6 +86,9 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 
 	writeErrorsRegister := selfstat.Register("write", "errors", tags)
 	logger := logging.New("outputs", config.Name, config.Alias)
+	if config.ID != "" {
+		logger.AddAttribute("id", config.ID)
+	}
 	logger.RegisterErrorCallback(func() {
 		writeErrorsRegister.Incr(1)
 	})
@@ -127,6 +137,11 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 			"startup_errors",
 			tags,
 		),
+		ConsecutiveFailures: selfstat.Register(
+			"write",
+			"consecutive_failures",
+			tags,
+		),
 		log: logger,
 	}
 
@@ -162,6 +177,14 @@ func (r *RunningOutput) Init() error {
 			return err
 		}
 	}
+
+	if p, ok := r.Output.(telegraf.ConstrainedOutput); ok {
+		tags := map[string]string{"output": r.Config.Name}
+		if r.Config.Alias != "" {
+			tags["alias"] = r.Config.Alias
+		}
+		r.constraints = newOutputConstraints(p.Constraints(), tags)
+	}
 	return nil
 }
 
@@ -242,6 +265,9 @@ func (r *RunningOutput) add(metric telegraf.Metric) {
 	}
 
 	if output, ok := r.Output.(telegraf.AggregatingOutput); ok {
+		if r.constraints != nil {
+			r.constraints.apply(metric)
+		}
 		r.aggMutex.Lock()
 		output.Add(metric)
 		r.aggMutex.Unlock()
@@ -260,6 +286,10 @@ func (r *RunningOutput) add(metric telegraf.Metric) {
 		metric.AddSuffix(r.Config.NameSuffix)
 	}
 
+	if r.constraints != nil {
+		r.constraints.apply(metric)
+	}
+
 	dropped := r.buffer.Add(metric)
 	atomic.AddInt64(&r.droppedMetrics, int64(dropped))
 
@@ -283,6 +313,7 @@ func (r *RunningOutput) Write() error {
 			var serr *internal.StartupError
 			if !errors.As(err, &serr) || !serr.Retry || !serr.Partial {
 				r.StartupErrors.Incr(1)
+				r.updateHealth(err)
 				return internal.ErrNotConnected
 			}
 			r.log.Debugf("Partially connected after %d attempts", r.retries)
@@ -292,6 +323,12 @@ func (r *RunningOutput) Write() error {
 		}
 	}
 
+	// Outputs of a failover group only receive metrics while being healthy,
+	// so probe unhealthy outputs without buffered metrics for recovery.
+	if r.Config.FailoverGroup != "" && r.unhealthy.Load() && r.buffer.Len() == 0 {
+		r.probe()
+	}
+
 	if output, ok := r.Output.(telegraf.AggregatingOutput); ok {
 		r.aggMutex.Lock()
 		metrics := output.Push()
@@ -328,6 +365,7 @@ func (r *RunningOutput) WriteBatch() error {
 		r.retries++
 		if err := r.Output.Connect(); err != nil {
 			r.StartupErrors.Incr(1)
+			r.updateHealth(err)
 			return internal.ErrNotConnected
 		}
 		r.started = true
@@ -356,6 +394,7 @@ func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
 	err := r.Output.Write(metrics)
 	elapsed := time.Since(start)
 	r.WriteTime.Incr(elapsed.Nanoseconds())
+	r.updateHealth(err)
 
 	if err == nil {
 		r.log.Debugf("Wrote batch of %d metrics in %s", len(metrics), elapsed)
@@ -363,6 +402,59 @@ func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
 	return err
 }
 
+// Healthy returns false if the last connection or write attempt failed
+func (r *RunningOutput) Healthy() bool {
+	return !r.unhealthy.Load()
+}
+
+// Failures returns the number of consecutive failed connects or writes
+func (r *RunningOutput) Failures() int64 {
+	return r.failures.Load()
+}
+
+// BufferFullness returns the fraction of the buffer limit currently in use
+func (r *RunningOutput) BufferFullness() float64 {
+	return float64(r.buffer.Len()) / float64(r.MetricBufferLimit)
+}
+
+// updateHealth sets the health of the output depending on the connect or
+// write result. Partial writes with accepted metrics indicate a reachable
+// output.
+func (r *RunningOutput) updateHealth(err error) {
+	var writeErr *internal.PartialWriteError
+	healthy := err == nil || (errors.As(err, &writeErr) && len(writeErr.MetricsAccept) > 0)
+	if healthy {
+		r.failures.Store(0)
+		r.ConsecutiveFailures.Set(0)
+	} else {
+		r.ConsecutiveFailures.Set(r.failures.Add(1))
+	}
+	if r.unhealthy.Swap(!healthy) == healthy && r.Config.FailoverGroup != "" {
+		if healthy {
+			r.log.Infof("Output recovered in failover group %q", r.Config.FailoverGroup)
+		} else {
+			r.log.Warnf("Output failed in failover group %q", r.Config.FailoverGroup)
+		}
+	}
+}
+
+// probe checks if an unhealthy output recovered using the output's probe
+// function. Outputs not supporting probing are considered to be recovered
+// and are checked by the next write.
+func (r *RunningOutput) probe() {
+	p, ok := r.Output.(telegraf.ProbePlugin)
+	if !ok {
+		r.unhealthy.Store(false)
+		return
+	}
+	if err := p.Probe(); err != nil {
+		r.log.Debugf("Probing output failed: %v", err)
+		return
+	}
+	r.log.Infof("Output recovered in failover group %q", r.Config.FailoverGroup)
+	r.unhealthy.Store(false)
+}
+
 func (*RunningOutput) updateTransaction(tx *Transaction, err error) {
 	// No error indicates all metrics were written successfully
 	if err == nil {
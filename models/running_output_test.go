@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -431,16 +432,17 @@ func TestRunningOutputInternalMetrics(t *testing.T) {
 				"alias":  "test_alias",
 			},
 			map[string]interface{}{
-				"buffer_limit":     10,
-				"buffer_size":      0,
-				"errors":           0,
-				"metrics_added":    0,
-				"metrics_rejected": 0,
-				"metrics_dropped":  0,
-				"metrics_filtered": 0,
-				"metrics_written":  0,
-				"write_time_ns":    0,
-				"startup_errors":   0,
+				"buffer_limit":         10,
+				"buffer_size":          0,
+				"consecutive_failures": 0,
+				"errors":               0,
+				"metrics_added":        0,
+				"metrics_rejected":     0,
+				"metrics_dropped":      0,
+				"metrics_filtered":     0,
+				"metrics_written":      0,
+				"write_time_ns":        0,
+				"startup_errors":       0,
 			},
 			time.Unix(0, 0),
 		),
@@ -864,6 +866,98 @@ func TestRunningOutputWriteBatchPartialSuccessAndLoss(t *testing.T) {
 	require.Zero(t, model.buffer.Len())
 }
 
+func TestRunningOutputConstraints(t *testing.T) {
+	m := &constrainedOutput{
+		constraints: telegraf.OutputConstraints{
+			MaxNameLength:       8,
+			MaxTags:             3,
+			MaxTagKeyLength:     8,
+			MaxTagValueLength:   6,
+			MaxTagLength:        10,
+			ForbiddenCharacters: " /",
+			Replacement:         "_",
+		},
+	}
+	ro := NewRunningOutput(m, &OutputConfig{Name: "test_constraints"}, 1000, 10000)
+	require.NoError(t, ro.Init())
+
+	ro.AddMetric(testutil.MustMetric(
+		"cpu load/average",
+		map[string]string{
+			"a":            "short",
+			"b key":        "value",
+			"c":            "verylongvalue",
+			"d":            "dropped",
+			"long_tag_key": "dropped",
+		},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0),
+	))
+	require.NoError(t, ro.Write())
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu_load",
+			map[string]string{
+				"a":     "short",
+				"b_key": "value",
+				"c":     "verylo",
+			},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, m.Metrics())
+
+	stats := make(map[string]interface{})
+	for _, m := range selfstat.Metrics() {
+		output, _ := m.GetTag("output")
+		if m.Name() == "internal_write" && output == "test_constraints" {
+			stats = m.Fields()
+		}
+	}
+	require.Equal(t, int64(1), stats["names_truncated"])
+	require.Equal(t, int64(2), stats["tags_dropped"])
+	require.Equal(t, int64(1), stats["tags_truncated"])
+}
+
+func TestRunningOutputConstraintsTagLength(t *testing.T) {
+	m := &constrainedOutput{
+		constraints: telegraf.OutputConstraints{MaxTagLength: 254},
+	}
+	ro := NewRunningOutput(m, &OutputConfig{}, 1000, 10000)
+	require.NoError(t, ro.Init())
+
+	ro.AddMetric(testutil.MustMetric(
+		"test",
+		map[string]string{
+			strings.Repeat("w", 255): "dropped",
+			strings.Repeat("x", 254): "empty",
+			strings.Repeat("y", 253): "truncated",
+			strings.Repeat("z", 251): "Hi!",
+			"unicode":                strings.Repeat("ü", 125),
+		},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0),
+	))
+	require.NoError(t, ro.Write())
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"test",
+			map[string]string{
+				strings.Repeat("x", 254): "",
+				strings.Repeat("y", 253): "t",
+				strings.Repeat("z", 251): "Hi!",
+				"unicode":                strings.Repeat("ü", 123),
+			},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, m.Metrics())
+}
+
 // Benchmark adding metrics.
 func BenchmarkRunningOutputAddWrite(b *testing.B) {
 	conf := &OutputConfig{
@@ -975,6 +1069,15 @@ func (m *mockOutput) Metrics() []telegraf.Metric {
 	return m.metrics
 }
 
+type constrainedOutput struct {
+	mockOutput
+	constraints telegraf.OutputConstraints
+}
+
+func (m *constrainedOutput) Constraints() telegraf.OutputConstraints {
+	return m.constraints
+}
+
 type perfOutput struct {
 	// if true, mock write failure
 	failWrite bool
@@ -30,6 +30,7 @@ type ProcessorConfig struct {
 	Order    int64
 	Filter   Filter
 	LogLevel string
+	Pipeline string
 }
 
 func NewRunningProcessor(processor telegraf.StreamingProcessor, config *ProcessorConfig) *RunningProcessor {
@@ -40,6 +41,9 @@ func NewRunningProcessor(processor telegraf.StreamingProcessor, config *Processo
 
 	processErrorsRegister := selfstat.Register("process", "errors", tags)
 	logger := logging.New("processors", config.Name, config.Alias)
+	if config.ID != "" {
+		logger.AddAttribute("id", config.ID)
+	}
 	logger.RegisterErrorCallback(func() {
 		processErrorsRegister.Incr(1)
 	})
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// seriesLimiter tracks the active series per measurement and rejects new
+// series once the configured number of series is reached. Series not seen
+// for the configured time-to-live are expired and free their slot.
+type seriesLimiter struct {
+	limit int
+	ttl   time.Duration
+
+	series    map[string]map[uint64]time.Time
+	limited   map[string]bool
+	lastSweep time.Time
+	sync.Mutex
+}
+
+func newSeriesLimiter(limit int, ttl time.Duration) *seriesLimiter {
+	return &seriesLimiter{
+		limit:   limit,
+		ttl:     ttl,
+		series:  make(map[string]map[uint64]time.Time),
+		limited: make(map[string]bool),
+	}
+}
+
+// accept returns true if the series of the metric is active or can be added
+// without exceeding the limit. The second return value is true if the limit
+// of the measurement was hit for the first time.
+func (l *seriesLimiter) accept(m telegraf.Metric, now time.Time) (accepted, first bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	name := m.Name()
+	id := m.HashID()
+
+	active, found := l.series[name]
+	if !found {
+		active = make(map[uint64]time.Time)
+		l.series[name] = active
+	}
+	if _, found := active[id]; found {
+		active[id] = now
+		return true, false
+	}
+
+	// Expire inactive series before rejecting a new one but do not check more
+	// often than necessary as this requires iterating all series
+	if len(active) >= l.limit && l.ttl > 0 && now.Sub(l.lastSweep) >= l.ttl/4 {
+		l.sweep(now)
+	}
+	if len(active) >= l.limit {
+		first = !l.limited[name]
+		l.limited[name] = true
+		return false, first
+	}
+
+	active[id] = now
+	return true, false
+}
+
+func (l *seriesLimiter) sweep(now time.Time) {
+	for name, active := range l.series {
+		for id, seen := range active {
+			if now.Sub(seen) >= l.ttl {
+				delete(active, id)
+			}
+		}
+		if len(active) < l.limit {
+			delete(l.limited, name)
+		}
+	}
+	l.lastSweep = now
+}
@@ -27,3 +27,40 @@ type AggregatingOutput interface {
 	// Reset signals that the aggregator period is completed.
 	Reset()
 }
+
+// ConstrainedOutput is an interface that outputs can optionally implement to
+// declare the limits of the vendor's API on metric names and tags. Telegraf
+// enforces those constraints before the metrics are added to the output's
+// buffer and keeps track of the modifications in the internal statistics.
+type ConstrainedOutput interface {
+	Output
+
+	// Constraints returns the constraints of the output. This function has to
+	// be callable directly after the plugin's Init() function if there is any!
+	Constraints() OutputConstraints
+}
+
+// OutputConstraints describes the limits of an output on the metric names and
+// tags. Lengths are counted in bytes and a value of zero disables the check.
+type OutputConstraints struct {
+	// MaxNameLength is the maximum length of the metric name, longer names
+	// are truncated
+	MaxNameLength int
+	// MaxTags is the maximum number of tags per metric, exceeding tags are
+	// dropped in the order of their keys
+	MaxTags int
+	// MaxTagKeyLength is the maximum length of a tag key, tags with longer
+	// keys are dropped
+	MaxTagKeyLength int
+	// MaxTagValueLength is the maximum length of a tag value, longer values
+	// are truncated
+	MaxTagValueLength int
+	// MaxTagLength is the maximum combined length of a tag's key and value,
+	// the value is truncated if the limit is exceeded and tags with keys
+	// longer than the limit are dropped
+	MaxTagLength int
+	// ForbiddenCharacters contains the characters not allowed in metric names
+	// and tag keys, each of them is replaced by the Replacement string
+	ForbiddenCharacters string
+	Replacement         string
+}
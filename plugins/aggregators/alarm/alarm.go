@@ -0,0 +1,285 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package alarm
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Alarm struct {
+	EventMeasurement string `toml:"event_measurement"`
+	Repeat           bool   `toml:"repeat"`
+	Rules            []rule `toml:"rule"`
+
+	// The state of all rules per series
+	cache map[seriesKey]*series
+}
+
+type rule struct {
+	Name        string  `toml:"name"`
+	Measurement string  `toml:"measurement"`
+	Field       string  `toml:"field"`
+	Function    string  `toml:"function"`
+	Operator    string  `toml:"operator"`
+	Threshold   float64 `toml:"threshold"`
+
+	filter filter.Filter
+}
+
+type seriesKey struct {
+	rule int
+	id   uint64
+}
+
+// series holds the values of the rule's field within the current period and
+// the state carried across periods
+type series struct {
+	name string
+	tags map[string]string
+
+	// Values within the current period
+	count     int
+	first     float64
+	firstTime time.Time
+	last      float64
+	lastTime  time.Time
+	min       float64
+	max       float64
+	sum       float64
+
+	// Reference for computing the delta, i.e. the last value of the
+	// previous period
+	refValid bool
+	ref      float64
+	refTime  time.Time
+
+	firing bool
+}
+
+func NewAlarm() *Alarm {
+	return &Alarm{
+		EventMeasurement: "alarm",
+	}
+}
+
+func (*Alarm) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Alarm) Init() error {
+	if len(a.Rules) == 0 {
+		return errors.New("at least one rule required")
+	}
+	if a.EventMeasurement == "" {
+		return errors.New("'event_measurement' must not be empty")
+	}
+
+	names := make(map[string]bool, len(a.Rules))
+	for i := range a.Rules {
+		r := &a.Rules[i]
+		if r.Name == "" {
+			return fmt.Errorf("rule %d: 'name' must not be empty", i+1)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("duplicate rule %q", r.Name)
+		}
+		names[r.Name] = true
+
+		if r.Field == "" {
+			return fmt.Errorf("rule %q: 'field' must not be empty", r.Name)
+		}
+
+		switch r.Function {
+		case "":
+			r.Function = "last"
+		case "last", "min", "max", "mean", "delta", "rate":
+		default:
+			return fmt.Errorf("rule %q: invalid 'function' %q", r.Name, r.Function)
+		}
+
+		switch r.Operator {
+		case ">", ">=", "<", "<=", "==", "!=":
+		default:
+			return fmt.Errorf("rule %q: invalid 'operator' %q", r.Name, r.Operator)
+		}
+
+		if r.Measurement != "" {
+			f, err := filter.Compile([]string{r.Measurement})
+			if err != nil {
+				return fmt.Errorf("rule %q: creating measurement filter failed: %w", r.Name, err)
+			}
+			r.filter = f
+		}
+	}
+
+	a.cache = make(map[seriesKey]*series)
+
+	return nil
+}
+
+func (a *Alarm) Add(in telegraf.Metric) {
+	for i, r := range a.Rules {
+		if r.filter != nil && !r.filter.Match(in.Name()) {
+			continue
+		}
+		raw, found := in.GetField(r.Field)
+		if !found {
+			continue
+		}
+		value, ok := convert(raw)
+		if !ok {
+			continue
+		}
+
+		key := seriesKey{rule: i, id: in.HashID()}
+		entry, found := a.cache[key]
+		if !found {
+			entry = &series{name: in.Name(), tags: in.Tags()}
+			a.cache[key] = entry
+		}
+		entry.add(value, in.Time())
+	}
+}
+
+func (a *Alarm) Push(acc telegraf.Accumulator) {
+	for key, entry := range a.cache {
+		if entry.count == 0 {
+			continue
+		}
+		r := &a.Rules[key.rule]
+
+		value, ok := entry.evaluate(r.Function)
+		if !ok {
+			continue
+		}
+		breached := compare(value, r.Operator, r.Threshold)
+
+		var state string
+		switch {
+		case breached && (!entry.firing || a.Repeat):
+			state = "firing"
+		case !breached && entry.firing:
+			state = "resolved"
+		}
+		entry.firing = breached
+		if state == "" {
+			continue
+		}
+
+		tags := make(map[string]string, len(entry.tags)+2)
+		for k, v := range entry.tags {
+			tags[k] = v
+		}
+		tags["rule"] = r.Name
+		tags["measurement"] = entry.name
+		fields := map[string]interface{}{
+			"state":     state,
+			"field":     r.Field,
+			"value":     value,
+			"threshold": r.Threshold,
+		}
+		acc.AddFields(a.EventMeasurement, fields, tags)
+	}
+}
+
+// Reset clears the values of the period but keeps the state of the series
+func (a *Alarm) Reset() {
+	for _, entry := range a.cache {
+		if entry.count > 0 {
+			entry.refValid = true
+			entry.ref = entry.last
+			entry.refTime = entry.lastTime
+		}
+		entry.count = 0
+	}
+}
+
+func (s *series) add(value float64, ts time.Time) {
+	if s.count == 0 {
+		s.first, s.firstTime = value, ts
+		s.min, s.max, s.sum = value, value, 0
+	}
+	s.count++
+	s.last, s.lastTime = value, ts
+	s.min = math.Min(s.min, value)
+	s.max = math.Max(s.max, value)
+	s.sum += value
+}
+
+func (s *series) evaluate(function string) (float64, bool) {
+	switch function {
+	case "min":
+		return s.min, true
+	case "max":
+		return s.max, true
+	case "mean":
+		return s.sum / float64(s.count), true
+	case "delta", "rate":
+		ref, refTime := s.first, s.firstTime
+		if s.refValid {
+			ref, refTime = s.ref, s.refTime
+		}
+		delta := s.last - ref
+		if function == "delta" {
+			return delta, true
+		}
+		elapsed := s.lastTime.Sub(refTime).Seconds()
+		if elapsed <= 0 {
+			return 0, false
+		}
+		return delta / elapsed, true
+	}
+	return s.last, true
+}
+
+func compare(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	}
+	return false
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("alarm", func() telegraf.Aggregator {
+		return NewAlarm()
+	})
+}
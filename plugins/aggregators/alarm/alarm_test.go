@@ -0,0 +1,223 @@
+package alarm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []rule
+		expected string
+	}{
+		{
+			name:     "no rules",
+			expected: "at least one rule required",
+		},
+		{
+			name:     "missing name",
+			rules:    []rule{{Field: "value", Operator: ">"}},
+			expected: "rule 1: 'name' must not be empty",
+		},
+		{
+			name: "duplicate name",
+			rules: []rule{
+				{Name: "a", Field: "value", Operator: ">"},
+				{Name: "a", Field: "value", Operator: "<"},
+			},
+			expected: `duplicate rule "a"`,
+		},
+		{
+			name:     "missing field",
+			rules:    []rule{{Name: "a", Operator: ">"}},
+			expected: `rule "a": 'field' must not be empty`,
+		},
+		{
+			name:     "invalid function",
+			rules:    []rule{{Name: "a", Field: "value", Function: "median", Operator: ">"}},
+			expected: `rule "a": invalid 'function' "median"`,
+		},
+		{
+			name:     "invalid operator",
+			rules:    []rule{{Name: "a", Field: "value", Operator: "=>"}},
+			expected: `rule "a": invalid 'operator' "=>"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := NewAlarm()
+			plugin.Rules = tt.rules
+			require.EqualError(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	now := time.Now()
+	values := []int64{10, 40, 20}
+
+	tests := []struct {
+		function string
+		expected float64
+	}{
+		{function: "last", expected: 20},
+		{function: "min", expected: 10},
+		{function: "max", expected: 40},
+		{function: "mean", expected: 70.0 / 3.0},
+		{function: "delta", expected: 10},
+		{function: "rate", expected: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			plugin := NewAlarm()
+			plugin.Rules = []rule{{
+				Name:      "test",
+				Field:     "value",
+				Function:  tt.function,
+				Operator:  ">",
+				Threshold: 0,
+			}}
+			require.NoError(t, plugin.Init())
+
+			for i, v := range values {
+				plugin.Add(metric.New("test",
+					map[string]string{},
+					map[string]interface{}{"value": v},
+					now.Add(time.Duration(i)*time.Second),
+				))
+			}
+
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+			require.Len(t, acc.Metrics, 1)
+			require.InDelta(t, tt.expected, acc.Metrics[0].Fields["value"], 1e-9)
+		})
+	}
+}
+
+func TestStateTransitions(t *testing.T) {
+	plugin := NewAlarm()
+	plugin.Rules = []rule{{
+		Name:        "nginx_5xx",
+		Measurement: "nginx_*",
+		Field:       "responses_5xx",
+		Function:    "delta",
+		Operator:    ">",
+		Threshold:   100,
+	}}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	tags := map[string]string{"upstream": "backend"}
+	periods := [][]int64{
+		{1000, 1050},
+		{1100, 1300},
+		{1350, 1500},
+		{1520, 1540},
+		{1550, 1560},
+	}
+	for i, values := range periods {
+		for j, v := range values {
+			plugin.Add(metric.New("nginx_upstream",
+				tags,
+				map[string]interface{}{"responses_5xx": v},
+				now.Add(time.Duration(2*i+j)*time.Second),
+			))
+		}
+		// Non-matching measurement
+		plugin.Add(metric.New("other",
+			tags,
+			map[string]interface{}{"responses_5xx": int64(1000 * i)},
+			now.Add(time.Duration(2*i)*time.Second),
+		))
+
+		var acc testutil.Accumulator
+		plugin.Push(&acc)
+		plugin.Reset()
+
+		var expected []telegraf.Metric
+		switch i {
+		case 1:
+			expected = append(expected, metric.New("alarm",
+				map[string]string{"upstream": "backend", "rule": "nginx_5xx", "measurement": "nginx_upstream"},
+				map[string]interface{}{"state": "firing", "field": "responses_5xx", "value": 250.0, "threshold": 100.0},
+				time.Unix(0, 0),
+			))
+		case 3:
+			expected = append(expected, metric.New("alarm",
+				map[string]string{"upstream": "backend", "rule": "nginx_5xx", "measurement": "nginx_upstream"},
+				map[string]interface{}{"state": "resolved", "field": "responses_5xx", "value": 40.0, "threshold": 100.0},
+				time.Unix(0, 0),
+			))
+		}
+		testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	plugin := NewAlarm()
+	plugin.EventMeasurement = "event"
+	plugin.Repeat = true
+	plugin.Rules = []rule{{
+		Name:      "high",
+		Field:     "value",
+		Operator:  ">=",
+		Threshold: 5,
+	}}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	var states []string
+	for i, v := range []float64{1, 5, 6, 2, 3} {
+		plugin.Add(metric.New("test",
+			map[string]string{},
+			map[string]interface{}{"value": v},
+			now.Add(time.Duration(i)*time.Second),
+		))
+
+		var acc testutil.Accumulator
+		plugin.Push(&acc)
+		plugin.Reset()
+		for _, m := range acc.GetTelegrafMetrics() {
+			require.Equal(t, "event", m.Name())
+			state, _ := m.GetField("state")
+			states = append(states, state.(string))
+		}
+	}
+	require.Equal(t, []string{"firing", "firing", "resolved"}, states)
+}
+
+func TestSeriesWithoutValues(t *testing.T) {
+	plugin := NewAlarm()
+	plugin.Rules = []rule{{
+		Name:      "up",
+		Field:     "up",
+		Operator:  "==",
+		Threshold: 0,
+	}}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New("service",
+		map[string]string{"name": "a"},
+		map[string]interface{}{"up": false, "status": "down"},
+		time.Now(),
+	))
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	plugin.Reset()
+	require.Len(t, acc.Metrics, 1)
+
+	// Without new values the series must neither be evaluated nor resolved
+	acc.ClearMetrics()
+	plugin.Push(&acc)
+	require.Empty(t, acc.Metrics)
+}
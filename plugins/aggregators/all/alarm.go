@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.alarm
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/alarm" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.state
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/state" // register plugin
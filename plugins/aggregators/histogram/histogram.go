@@ -3,6 +3,9 @@ package histogram
 
 import (
 	_ "embed"
+	"errors"
+	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"time"
@@ -27,6 +30,9 @@ const bucketLeftTag = "gt"
 // bucketNegInf is the left bucket border for infinite values
 const bucketNegInf = "-Inf"
 
+// windowSlots is the number of slots a sliding window is divided into
+const windowSlots = 10
+
 // HistogramAggregator is aggregator with histogram configs and particular histograms for defined metrics
 type HistogramAggregator struct {
 	Configs            []bucketConfig  `toml:"config"`
@@ -34,6 +40,8 @@ type HistogramAggregator struct {
 	Cumulative         bool            `toml:"cumulative"`
 	ExpirationInterval config.Duration `toml:"expiration_interval"`
 	PushOnlyOnUpdate   bool            `toml:"push_only_on_update"`
+	Window             config.Duration `toml:"window"`
+	ExportFormat       string          `toml:"export_format"`
 
 	buckets bucketsByMetrics
 	cache   map[uint64]metricHistogramCollection
@@ -41,9 +49,13 @@ type HistogramAggregator struct {
 
 // bucketConfig is the config, which contains name, field of metric and histogram buckets.
 type bucketConfig struct {
-	Metric  string   `toml:"measurement_name"`
-	Fields  []string `toml:"fields"`
-	Buckets buckets  `toml:"buckets"`
+	Metric         string   `toml:"measurement_name"`
+	Fields         []string `toml:"fields"`
+	Buckets        buckets  `toml:"buckets"`
+	Layout         string   `toml:"layout"`
+	Min            float64  `toml:"min"`
+	Max            float64  `toml:"max"`
+	StepsPerDecade int      `toml:"steps_per_decade"`
 }
 
 // bucketsByMetrics contains the buckets grouped by metric and field name
@@ -58,6 +70,8 @@ type buckets []float64
 // metricHistogramCollection aggregates the histogram data
 type metricHistogramCollection struct {
 	histogramCollection map[string]counts
+	sums                map[string]float64
+	slots               map[string][]slot
 	name                string
 	tags                map[string]string
 	expireTime          time.Time
@@ -67,6 +81,13 @@ type metricHistogramCollection struct {
 // counts is the number of hits in the bucket
 type counts []int64
 
+// slot contains the hits of a part of the sliding window starting at the given time
+type slot struct {
+	start  time.Time
+	counts counts
+	sum    float64
+}
+
 // groupedByCountFields contains grouped fields by their count and fields values
 type groupedByCountFields struct {
 	name            string
@@ -91,6 +112,49 @@ func (*HistogramAggregator) SampleConfig() string {
 	return sampleConfig
 }
 
+func (h *HistogramAggregator) Init() error {
+	switch h.ExportFormat {
+	case "":
+		h.ExportFormat = "buckets"
+	case "buckets":
+		// Do nothing, this is the default
+	case "prometheus_v1", "prometheus_v2":
+		if !h.Cumulative {
+			return fmt.Errorf("export format %q requires cumulative buckets", h.ExportFormat)
+		}
+	default:
+		return fmt.Errorf("invalid 'export_format' %q", h.ExportFormat)
+	}
+
+	if h.Window < 0 {
+		return errors.New("'window' must not be negative")
+	}
+	if h.Window > 0 && h.ResetBuckets {
+		return errors.New("'window' cannot be used together with 'reset'")
+	}
+
+	for _, cfg := range h.Configs {
+		switch cfg.Layout {
+		case "", "explicit":
+			// Do nothing, the buckets are given explicitly
+		case "log_linear":
+			if cfg.Min <= 0 {
+				return fmt.Errorf("'min' must be positive for log-linear layout of %q", cfg.Metric)
+			}
+			if cfg.Max <= cfg.Min {
+				return fmt.Errorf("'max' must be larger than 'min' for log-linear layout of %q", cfg.Metric)
+			}
+			if cfg.StepsPerDecade < 0 {
+				return fmt.Errorf("'steps_per_decade' must not be negative for %q", cfg.Metric)
+			}
+		default:
+			return fmt.Errorf("invalid 'layout' %q for %q", cfg.Layout, cfg.Metric)
+		}
+	}
+
+	return nil
+}
+
 // Add adds new hit to the buckets
 func (h *HistogramAggregator) Add(in telegraf.Metric) {
 	addTime := timeNow()
@@ -114,6 +178,8 @@ func (h *HistogramAggregator) Add(in telegraf.Metric) {
 			name:                in.Name(),
 			tags:                in.Tags(),
 			histogramCollection: make(map[string]counts),
+			sums:                make(map[string]float64),
+			slots:               make(map[string][]slot),
 		}
 	}
 
@@ -125,7 +191,12 @@ func (h *HistogramAggregator) Add(in telegraf.Metric) {
 
 			if value, ok := convert(value); ok {
 				index := sort.SearchFloat64s(buckets, value)
-				agr.histogramCollection[field][index]++
+				if h.Window > 0 {
+					agr.addToSlot(field, addTime.Truncate(h.slotSize()), index, value)
+				} else {
+					agr.histogramCollection[field][index]++
+					agr.sums[field] += value
+				}
 			}
 			if h.ExpirationInterval != 0 {
 				agr.expireTime = addTime.Add(time.Duration(h.ExpirationInterval))
@@ -150,17 +221,111 @@ func (h *HistogramAggregator) Push(acc telegraf.Accumulator) {
 			continue
 		}
 		aggregate.updated = false
+		if h.Window > 0 {
+			aggregate.slide(now.Add(-time.Duration(h.Window)), h.slotSize())
+		}
 		h.cache[id] = aggregate
+
+		switch h.ExportFormat {
+		case "prometheus_v1":
+			for field, counts := range aggregate.histogramCollection {
+				h.addPrometheusV1(acc, aggregate, field, counts)
+			}
+			continue
+		case "prometheus_v2":
+			// The sum and count are reported in a metric without bucket tag
+			fields := make(map[string]interface{}, 2*len(aggregate.histogramCollection))
+			for field, counts := range aggregate.histogramCollection {
+				fields[field+"_sum"] = aggregate.sums[field]
+				fields[field+"_count"] = counts.total()
+			}
+			acc.AddHistogram(aggregate.name, fields, copyTags(aggregate.tags))
+		}
+
 		for field, counts := range aggregate.histogramCollection {
 			h.groupFieldsByBuckets(&metricsWithGroupedFields, aggregate.name, field, copyTags(aggregate.tags), counts)
 		}
 	}
 
 	for _, metric := range metricsWithGroupedFields {
+		if h.ExportFormat == "prometheus_v2" {
+			acc.AddHistogram(metric.name, makeFieldsWithCount(metric.fieldsWithCount), metric.tags)
+			continue
+		}
 		acc.AddFields(metric.name, makeFieldsWithCount(metric.fieldsWithCount), metric.tags)
 	}
 }
 
+// addPrometheusV1 adds the histogram of the field as a single metric with the
+// bucket borders as field names as used by the metric version 1 of the
+// prometheus_client output
+func (h *HistogramAggregator) addPrometheusV1(acc telegraf.Accumulator, aggregate metricHistogramCollection, field string, counts counts) {
+	buckets := h.getBuckets(aggregate.name, field) // note that len(buckets) + 1 == len(counts)
+
+	fields := make(map[string]interface{}, len(buckets)+2)
+	var sum int64
+	for index, bound := range buckets {
+		sum += counts[index]
+		fields[strconv.FormatFloat(bound, 'g', -1, 64)] = sum
+	}
+	fields["sum"] = aggregate.sums[field]
+	fields["count"] = counts.total()
+
+	acc.AddHistogram(aggregate.name+"_"+field, fields, copyTags(aggregate.tags))
+}
+
+// slotSize returns the duration of a slot of the sliding window
+func (h *HistogramAggregator) slotSize() time.Duration {
+	return time.Duration(h.Window) / windowSlots
+}
+
+// addToSlot adds a hit to the slot starting at the given time
+func (agr *metricHistogramCollection) addToSlot(field string, start time.Time, index int, value float64) {
+	slots := agr.slots[field]
+	if len(slots) == 0 || !slots[len(slots)-1].start.Equal(start) {
+		slots = append(slots, slot{start: start, counts: make(counts, len(agr.histogramCollection[field]))})
+	}
+	current := &slots[len(slots)-1]
+	current.counts[index]++
+	current.sum += value
+	agr.slots[field] = slots
+}
+
+// slide drops the slots ending before the given time and computes the counts
+// and sums of the remaining hits
+func (agr *metricHistogramCollection) slide(since time.Time, slotSize time.Duration) {
+	for field, slots := range agr.slots {
+		var first int
+		for first < len(slots) && !slots[first].start.Add(slotSize).After(since) {
+			first++
+		}
+		slots = slots[first:]
+		agr.slots[field] = slots
+
+		counts := agr.histogramCollection[field]
+		for i := range counts {
+			counts[i] = 0
+		}
+		var sum float64
+		for _, s := range slots {
+			for i, c := range s.counts {
+				counts[i] += c
+			}
+			sum += s.sum
+		}
+		agr.sums[field] = sum
+	}
+}
+
+// total returns the number of hits in all buckets
+func (c counts) total() int64 {
+	var total int64
+	for _, count := range c {
+		total += count
+	}
+	return total
+}
+
 // groupFieldsByBuckets groups fields by metric buckets which are represented as tags
 func (h *HistogramAggregator) groupFieldsByBuckets(
 	metricsWithGroupedFields *[]groupedByCountFields, name, field string, tags map[string]string, counts []int64,
@@ -238,13 +403,44 @@ func (h *HistogramAggregator) getBuckets(metric, field string) []float64 {
 				h.buckets[metric] = make(bucketsByFields)
 			}
 
-			h.buckets[metric][field] = sortBuckets(cfg.Buckets)
+			h.buckets[metric][field] = sortBuckets(cfg.boundaries())
 		}
 	}
 
 	return h.buckets[metric][field]
 }
 
+// boundaries returns the right borders of the buckets either given explicitly
+// or generated for the log-linear layout. The log-linear layout divides each
+// decade between min and max into linear steps, e.g. 1, 2, ..., 9, 10, 20, ...
+func (cfg *bucketConfig) boundaries() []float64 {
+	if cfg.Layout != "log_linear" {
+		return cfg.Buckets
+	}
+
+	steps := cfg.StepsPerDecade
+	if steps == 0 {
+		steps = 9
+	}
+
+	var result []float64
+	for exponent := math.Floor(math.Log10(cfg.Min)); ; exponent++ {
+		base := math.Pow(10, exponent)
+		for i := 0; i < steps; i++ {
+			// Round to avoid floating-point artifacts in the bucket tags
+			bound := base * (1 + float64(i)*9/float64(steps))
+			bound, _ = strconv.ParseFloat(strconv.FormatFloat(bound, 'g', 12, 64), 64)
+			if bound < cfg.Min {
+				continue
+			}
+			if bound >= cfg.Max {
+				return append(result, cfg.Max)
+			}
+			result = append(result, bound)
+		}
+	}
+}
+
 // isBucketExists checks if buckets exists for the passed field
 func isBucketExists(field string, cfg bucketConfig) bool {
 	if len(cfg.Fields) == 0 {
@@ -485,6 +485,208 @@ func TestHistogramMetricExpiration(t *testing.T) {
 	)
 }
 
+func TestHistogramSlidingWindow(t *testing.T) {
+	currentTime := time.Unix(100, 0)
+	timeNow = func() time.Time {
+		return currentTime
+	}
+	defer func() {
+		timeNow = time.Now
+	}()
+
+	histogram := NewHistogramAggregator()
+	histogram.Configs = []bucketConfig{{Metric: "m", Buckets: []float64{10.0}}}
+	histogram.Window = config.Duration(10 * time.Second)
+	require.NoError(t, histogram.Init())
+
+	histogram.Add(metric.New("m", nil, fields{"a": float64(5)}, currentTime))
+	currentTime = time.Unix(105, 0)
+	histogram.Add(metric.New("m", nil, fields{"a": float64(15)}, currentTime))
+
+	// All values are within the window
+	currentTime = time.Unix(106, 0)
+	acc := &testutil.Accumulator{}
+	histogram.Push(acc)
+	histogram.Reset()
+	assertContainsTaggedField(t, acc, "m", fields{"a_bucket": int64(1)}, tags{bucketRightTag: "10"})
+	assertContainsTaggedField(t, acc, "m", fields{"a_bucket": int64(2)}, tags{bucketRightTag: bucketPosInf})
+
+	// The first value dropped out of the window
+	currentTime = time.Unix(111, 0)
+	acc = &testutil.Accumulator{}
+	histogram.Push(acc)
+	histogram.Reset()
+	assertContainsTaggedField(t, acc, "m", fields{"a_bucket": int64(0)}, tags{bucketRightTag: "10"})
+	assertContainsTaggedField(t, acc, "m", fields{"a_bucket": int64(1)}, tags{bucketRightTag: bucketPosInf})
+
+	// All values dropped out of the window
+	currentTime = time.Unix(116, 0)
+	acc = &testutil.Accumulator{}
+	histogram.Push(acc)
+	assertContainsTaggedField(t, acc, "m", fields{"a_bucket": int64(0)}, tags{bucketRightTag: "10"})
+	assertContainsTaggedField(t, acc, "m", fields{"a_bucket": int64(0)}, tags{bucketRightTag: bucketPosInf})
+}
+
+func TestHistogramLogLinearLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      bucketConfig
+		expected []float64
+	}{
+		{
+			name:     "default steps",
+			cfg:      bucketConfig{Layout: "log_linear", Min: 1, Max: 100},
+			expected: []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
+		},
+		{
+			name:     "fractional range",
+			cfg:      bucketConfig{Layout: "log_linear", Min: 0.003, Max: 0.012},
+			expected: []float64{0.003, 0.004, 0.005, 0.006, 0.007, 0.008, 0.009, 0.01, 0.012},
+		},
+		{
+			name:     "custom steps",
+			cfg:      bucketConfig{Layout: "log_linear", Min: 1, Max: 100, StepsPerDecade: 3},
+			expected: []float64{1, 4, 7, 10, 40, 70, 100},
+		},
+		{
+			name:     "explicit",
+			cfg:      bucketConfig{Buckets: []float64{1, 2}},
+			expected: []float64{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.cfg.boundaries())
+		})
+	}
+}
+
+func TestHistogramPrometheusV2(t *testing.T) {
+	histogram := NewHistogramAggregator()
+	histogram.Configs = []bucketConfig{{Metric: "cpu", Buckets: []float64{10.0, 50.0}}}
+	histogram.ExportFormat = "prometheus_v2"
+	require.NoError(t, histogram.Init())
+
+	for _, v := range []float64{50, 7, 99, 12} {
+		histogram.Add(metric.New("cpu", tags{"cpu": "cpu1"}, fields{"usage_idle": v}, time.Now()))
+	}
+
+	acc := &testutil.Accumulator{}
+	histogram.Push(acc)
+
+	expected := []telegraf.Metric{
+		metric.New("cpu",
+			tags{"cpu": "cpu1"},
+			fields{"usage_idle_sum": float64(168), "usage_idle_count": int64(4)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New("cpu",
+			tags{"cpu": "cpu1", "le": "10"},
+			fields{"usage_idle_bucket": int64(1)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New("cpu",
+			tags{"cpu": "cpu1", "le": "50"},
+			fields{"usage_idle_bucket": int64(3)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New("cpu",
+			tags{"cpu": "cpu1", "le": "+Inf"},
+			fields{"usage_idle_bucket": int64(4)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestHistogramPrometheusV1(t *testing.T) {
+	histogram := NewHistogramAggregator()
+	histogram.Configs = []bucketConfig{{Metric: "cpu", Buckets: []float64{0.5, 50.0}}}
+	histogram.ExportFormat = "prometheus_v1"
+	require.NoError(t, histogram.Init())
+
+	for _, v := range []float64{50, 7, 99, 12} {
+		histogram.Add(metric.New("cpu", tags{"cpu": "cpu1"}, fields{"usage_idle": v}, time.Now()))
+	}
+
+	acc := &testutil.Accumulator{}
+	histogram.Push(acc)
+
+	expected := []telegraf.Metric{
+		metric.New("cpu_usage_idle",
+			tags{"cpu": "cpu1"},
+			fields{"0.5": int64(0), "50": int64(3), "sum": float64(168), "count": int64(4)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestHistogramInitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(h *HistogramAggregator)
+		expected string
+	}{
+		{
+			name:     "invalid export format",
+			modify:   func(h *HistogramAggregator) { h.ExportFormat = "json" },
+			expected: `invalid 'export_format' "json"`,
+		},
+		{
+			name: "non-cumulative prometheus",
+			modify: func(h *HistogramAggregator) {
+				h.ExportFormat = "prometheus_v2"
+				h.Cumulative = false
+			},
+			expected: "requires cumulative buckets",
+		},
+		{
+			name: "window with reset",
+			modify: func(h *HistogramAggregator) {
+				h.Window = config.Duration(time.Minute)
+				h.ResetBuckets = true
+			},
+			expected: "'window' cannot be used together with 'reset'",
+		},
+		{
+			name: "invalid layout",
+			modify: func(h *HistogramAggregator) {
+				h.Configs = []bucketConfig{{Metric: "m", Layout: "exponential"}}
+			},
+			expected: `invalid 'layout' "exponential"`,
+		},
+		{
+			name: "log-linear without min",
+			modify: func(h *HistogramAggregator) {
+				h.Configs = []bucketConfig{{Metric: "m", Layout: "log_linear", Max: 10}}
+			},
+			expected: "'min' must be positive",
+		},
+		{
+			name: "log-linear with max below min",
+			modify: func(h *HistogramAggregator) {
+				h.Configs = []bucketConfig{{Metric: "m", Layout: "log_linear", Min: 10, Max: 1}}
+			},
+			expected: "'max' must be larger than 'min'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			histogram := NewHistogramAggregator()
+			tt.modify(histogram)
+			require.ErrorContains(t, histogram.Init(), tt.expected)
+		})
+	}
+}
+
 // assertContainsTaggedField is help functions to test histogram data
 func assertContainsTaggedField(t *testing.T, acc *testutil.Accumulator, metricName string, fields map[string]interface{}, tags map[string]string) {
 	acc.Lock()
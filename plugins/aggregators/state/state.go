@@ -0,0 +1,115 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package state
+
+import (
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type State struct {
+	MaxAge   config.Duration `toml:"max_age"`
+	Fields   []string        `toml:"fields"`
+	AgeField string          `toml:"age_field"`
+
+	filter filter.Filter
+	// The last known state of all series
+	cache map[uint64]*series
+}
+
+type series struct {
+	name     string
+	tags     map[string]string
+	fields   map[string]interface{}
+	lastSeen time.Time
+}
+
+func NewState() *State {
+	return &State{
+		MaxAge: config.Duration(time.Hour),
+	}
+}
+
+func (*State) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *State) Init() error {
+	f, err := filter.Compile(s.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	s.filter = f
+
+	s.cache = make(map[uint64]*series)
+
+	return nil
+}
+
+func (s *State) Add(in telegraf.Metric) {
+	id := in.HashID()
+	entry, found := s.cache[id]
+	if !found {
+		entry = &series{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]interface{}, len(in.FieldList())),
+		}
+	} else if in.Time().Before(entry.lastSeen) {
+		// Ignore events older than the current state
+		return
+	}
+
+	var updated bool
+	for _, field := range in.FieldList() {
+		if s.filter != nil && !s.filter.Match(field.Key) {
+			continue
+		}
+		entry.fields[field.Key] = field.Value
+		updated = true
+	}
+	if !updated {
+		return
+	}
+	entry.lastSeen = in.Time()
+	s.cache[id] = entry
+}
+
+func (s *State) Push(acc telegraf.Accumulator) {
+	now := time.Now()
+	for id, entry := range s.cache {
+		age := now.Sub(entry.lastSeen)
+		if s.MaxAge > 0 && age > time.Duration(s.MaxAge) {
+			// The state is unknown after the series expired
+			delete(s.cache, id)
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(entry.fields)+1)
+		for k, v := range entry.fields {
+			fields[k] = v
+		}
+		if s.AgeField != "" {
+			fields[s.AgeField] = age.Seconds()
+		}
+		acc.AddGauge(entry.name, fields, entry.tags, now)
+	}
+}
+
+// Reset does nothing as the state must be kept across periods
+func (*State) Reset() {
+}
+
+func init() {
+	aggregators.Add("state", func() telegraf.Aggregator {
+		return NewState()
+	})
+}
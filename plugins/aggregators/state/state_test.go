@@ -0,0 +1,106 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestLastKnownValue(t *testing.T) {
+	plugin := NewState()
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	plugin.Add(metric.New("service",
+		map[string]string{"name": "a"},
+		map[string]interface{}{"state": int64(1), "pid": int64(42)},
+		now.Add(-3*time.Second),
+	))
+	plugin.Add(metric.New("service",
+		map[string]string{"name": "b"},
+		map[string]interface{}{"state": int64(0)},
+		now.Add(-2*time.Second),
+	))
+	plugin.Add(metric.New("service",
+		map[string]string{"name": "a"},
+		map[string]interface{}{"state": int64(2)},
+		now.Add(-time.Second),
+	))
+	// Outdated event
+	plugin.Add(metric.New("service",
+		map[string]string{"name": "a"},
+		map[string]interface{}{"state": int64(3)},
+		now.Add(-5*time.Second),
+	))
+
+	expected := []telegraf.Metric{
+		metric.New("service",
+			map[string]string{"name": "a"},
+			map[string]interface{}{"state": int64(2), "pid": int64(42)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New("service",
+			map[string]string{"name": "b"},
+			map[string]interface{}{"state": int64(0)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+
+	// The state must be emitted in every period without new events
+	for i := 0; i < 3; i++ {
+		var acc testutil.Accumulator
+		plugin.Push(&acc)
+		plugin.Reset()
+		testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	plugin := &State{MaxAge: config.Duration(time.Minute), AgeField: "age"}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	plugin.Add(metric.New("event", map[string]string{"id": "old"}, map[string]interface{}{"value": true}, now.Add(-2*time.Minute)))
+	plugin.Add(metric.New("event", map[string]string{"id": "new"}, map[string]interface{}{"value": false}, now.Add(-30*time.Second)))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, "new", metrics[0].Tags()["id"])
+	age, found := metrics[0].GetField("age")
+	require.True(t, found)
+	require.InDelta(t, 30.0, age, 5.0)
+	require.NotContains(t, plugin.cache, metric.New("event", map[string]string{"id": "old"}, nil, now).HashID())
+}
+
+func TestFieldFilter(t *testing.T) {
+	plugin := &State{Fields: []string{"state*"}}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	plugin.Add(metric.New("docker", nil, map[string]interface{}{"state": "running", "state_code": int64(0), "exit": int64(1)}, now))
+	plugin.Add(metric.New("other", nil, map[string]interface{}{"value": int64(1)}, now))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("docker",
+			map[string]string{},
+			map[string]interface{}{"state": "running", "state_code": int64(0)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
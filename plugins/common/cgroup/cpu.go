@@ -0,0 +1,113 @@
+// Package cgroup provides parsers for the statistics of control groups in the
+// unified (v2) cgroup hierarchy.
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CPUStat holds the CPU usage, throttling and bandwidth limit of a cgroup as
+// found in the "cpu.stat" and "cpu.max" files. All times are in microseconds.
+type CPUStat struct {
+	// Counters contains all counters of "cpu.stat" by name
+	Counters map[string]int64
+
+	// HasUsage is false if "cpu.stat" does not contain the usage, e.g. for
+	// cgroups without CPU accounting
+	HasUsage         bool
+	Usage            int64
+	User             int64
+	System           int64
+	Periods          int64
+	ThrottledPeriods int64
+	Throttled        int64
+
+	// Bandwidth limit of "cpu.max", the quota is negative if the cgroup is
+	// not limited and the period is zero if the file does not exist
+	Quota  int64
+	Period int64
+}
+
+// ReadCPUStat reads the CPU statistics of the cgroup directory. The returned
+// error wraps fs.ErrNotExist if the directory has no "cpu.stat" file.
+func ReadCPUStat(dir string) (*CPUStat, error) {
+	fn := filepath.Join(dir, "cpu.stat")
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := ParseCPUStat(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %q failed: %w", fn, err)
+	}
+
+	fn = filepath.Join(dir, "cpu.max")
+	raw, err = os.ReadFile(fn)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return stat, nil
+		}
+		return nil, err
+	}
+	if err := stat.parseMax(raw); err != nil {
+		return nil, fmt.Errorf("parsing %q failed: %w", fn, err)
+	}
+	return stat, nil
+}
+
+// ParseCPUStat parses the content of a "cpu.stat" file
+func ParseCPUStat(raw []byte) (*CPUStat, error) {
+	stat := &CPUStat{Counters: make(map[string]int64), Quota: -1}
+	for _, line := range strings.Split(string(raw), "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), " ")
+		if !found {
+			continue
+		}
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q failed: %w", key, err)
+		}
+		stat.Counters[key] = v
+	}
+
+	_, hasUser := stat.Counters["user_usec"]
+	_, hasSystem := stat.Counters["system_usec"]
+	stat.HasUsage = hasUser && hasSystem
+	stat.Usage = stat.Counters["usage_usec"]
+	stat.User = stat.Counters["user_usec"]
+	stat.System = stat.Counters["system_usec"]
+	stat.Periods = stat.Counters["nr_periods"]
+	stat.ThrottledPeriods = stat.Counters["nr_throttled"]
+	stat.Throttled = stat.Counters["throttled_usec"]
+
+	return stat, nil
+}
+
+// parseMax parses the limit in the format "<quota> <period>" with "max" as
+// quota if the cgroup is not limited
+func (s *CPUStat) parseMax(raw []byte) error {
+	quota, period, found := strings.Cut(strings.TrimSpace(string(raw)), " ")
+	if !found {
+		return fmt.Errorf("unexpected content %q", string(raw))
+	}
+	p, err := strconv.ParseInt(period, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parsing period failed: %w", err)
+	}
+	s.Period = p
+	if quota == "max" {
+		return nil
+	}
+	q, err := strconv.ParseInt(quota, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parsing quota failed: %w", err)
+	}
+	s.Quota = q
+	return nil
+}
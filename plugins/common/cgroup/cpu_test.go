@@ -0,0 +1,69 @@
+package cgroup
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCPUStat(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte(
+		"usage_usec 3000000\nuser_usec 2000000\nsystem_usec 1000000\n"+
+			"nr_periods 100\nnr_throttled 10\nthrottled_usec 500000\nnr_bursts 0\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.max"), []byte("50000 100000\n"), 0600))
+
+	stat, err := ReadCPUStat(dir)
+	require.NoError(t, err)
+	expected := &CPUStat{
+		Counters: map[string]int64{
+			"usage_usec":     3000000,
+			"user_usec":      2000000,
+			"system_usec":    1000000,
+			"nr_periods":     100,
+			"nr_throttled":   10,
+			"throttled_usec": 500000,
+			"nr_bursts":      0,
+		},
+		HasUsage:         true,
+		Usage:            3000000,
+		User:             2000000,
+		System:           1000000,
+		Periods:          100,
+		ThrottledPeriods: 10,
+		Throttled:        500000,
+		Quota:            50000,
+		Period:           100000,
+	}
+	require.Equal(t, expected, stat)
+}
+
+func TestReadCPUStatUnlimited(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("nr_periods 0\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.max"), []byte("max 100000\n"), 0600))
+
+	stat, err := ReadCPUStat(dir)
+	require.NoError(t, err)
+	require.False(t, stat.HasUsage)
+	require.Equal(t, int64(-1), stat.Quota)
+	require.Equal(t, int64(100000), stat.Period)
+}
+
+func TestReadCPUStatErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, err := ReadCPUStat(dir)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("user_usec abc\n"), 0600))
+	_, err = ReadCPUStat(dir)
+	require.ErrorContains(t, err, `parsing "user_usec" failed`)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("user_usec 1\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.max"), []byte("max\n"), 0600))
+	_, err = ReadCPUStat(dir)
+	require.ErrorContains(t, err, "unexpected content")
+}
@@ -0,0 +1,128 @@
+// Package gpu implements the vendor-independent schema for GPU metrics shared
+// by the GPU input plugins. Plugins gather their metrics in their native
+// format and use the converting accumulator to emit the metrics in the common
+// schema if configured.
+package gpu
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Measurement names of the common schema
+const (
+	Measurement     = "gpu"
+	LinkMeasurement = "gpu_link"
+)
+
+// Fields of the common schema with integer values, all other fields are
+// floating-point values
+var integerFields = map[string]bool{
+	"memory_total": true,
+	"memory_used":  true,
+	"memory_free":  true,
+	"tx_bytes":     true,
+	"rx_bytes":     true,
+}
+
+// Tag describes the conversion of a native tag to a tag of the common schema,
+// tags converted to an empty value are dropped
+type Tag struct {
+	Name    string
+	Convert func(string) string
+}
+
+// Field describes the conversion of a native field to a field of the common
+// schema. The native value is multiplied with the scale if it is non-zero.
+type Field struct {
+	Name  string
+	Scale float64
+}
+
+// Mapping describes the conversion of a native measurement into the common
+// schema. Tags and fields not contained in the mapping are dropped.
+type Mapping struct {
+	Measurement string
+	Tags        map[string]Tag
+	Fields      map[string]Field
+}
+
+// Schema holds the mappings of all native measurements of a plugin
+type Schema struct {
+	Vendor       string
+	Measurements map[string]Mapping
+}
+
+// Accumulator converts the native metrics of a plugin to the common schema
+// before passing them to the underlying accumulator. Measurements without
+// mapping, e.g. process information, are passed on unchanged.
+type Accumulator struct {
+	telegraf.Accumulator
+	Schema *Schema
+}
+
+// CheckSchema validates the 'metric_schema' setting of a plugin
+func CheckSchema(schema string) error {
+	switch schema {
+	case "", "native", "gpu":
+		return nil
+	}
+	return fmt.Errorf("invalid 'metric_schema' setting %q", schema)
+}
+
+func (a *Accumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	mapping, found := a.Schema.Measurements[measurement]
+	if !found {
+		a.Accumulator.AddFields(measurement, fields, tags, t...)
+		return
+	}
+
+	convertedTags := map[string]string{"vendor": a.Schema.Vendor}
+	for k, v := range tags {
+		tag, found := mapping.Tags[k]
+		if !found {
+			continue
+		}
+		if tag.Convert != nil {
+			v = tag.Convert(v)
+		}
+		if v == "" {
+			continue
+		}
+		convertedTags[tag.Name] = v
+	}
+
+	convertedFields := make(map[string]interface{}, len(mapping.Fields))
+	for k, v := range fields {
+		field, found := mapping.Fields[k]
+		if !found {
+			continue
+		}
+		value, err := internal.ToFloat64(v)
+		if err != nil {
+			a.AddError(fmt.Errorf("converting field %q of %q failed: %w", k, measurement, err))
+			continue
+		}
+		if field.Scale != 0 {
+			value *= field.Scale
+		}
+		if integerFields[field.Name] {
+			convertedFields[field.Name] = int64(math.Round(value))
+		} else {
+			convertedFields[field.Name] = value
+		}
+	}
+	if len(convertedFields) == 0 {
+		return
+	}
+
+	name := mapping.Measurement
+	if name == "" {
+		name = Measurement
+	}
+	a.Accumulator.AddFields(name, convertedFields, convertedTags, t...)
+}
@@ -0,0 +1,109 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestAccumulator(t *testing.T) {
+	schema := &Schema{
+		Vendor: "test",
+		Measurements: map[string]Mapping{
+			"native": {
+				Tags: map[string]Tag{
+					"card": {Name: "index", Convert: func(v string) string { return strings.TrimPrefix(v, "card") }},
+					"uuid": {Name: "uuid"},
+				},
+				Fields: map[string]Field{
+					"util":       {Name: "utilization"},
+					"mem_mib":    {Name: "memory_used", Scale: 1024 * 1024},
+					"power_uw":   {Name: "power_draw", Scale: 1e-6},
+					"temp_milli": {Name: "temperature", Scale: 0.001},
+				},
+			},
+			"native_link": {
+				Measurement: LinkMeasurement,
+				Tags: map[string]Tag{
+					"card": {Name: "index"},
+					"link": {Name: "link"},
+				},
+				Fields: map[string]Field{
+					"tx_kib": {Name: "tx_bytes", Scale: 1024},
+				},
+			},
+		},
+	}
+
+	var acc testutil.Accumulator
+	gpuAcc := &Accumulator{Accumulator: &acc, Schema: schema}
+
+	gpuAcc.AddFields("native",
+		map[string]interface{}{
+			"util":       int64(42),
+			"mem_mib":    int64(2),
+			"power_uw":   uint64(150000000),
+			"temp_milli": int64(45500),
+			"serial":     "1234",
+		},
+		map[string]string{"card": "card1", "uuid": "abc", "pstate": "P0"},
+		time.Unix(0, 0),
+	)
+	gpuAcc.AddFields("native_link",
+		map[string]interface{}{"tx_kib": int64(3)},
+		map[string]string{"card": "0", "link": "1"},
+		time.Unix(0, 0),
+	)
+	gpuAcc.AddFields("native_process",
+		map[string]interface{}{"pid": 1},
+		map[string]string{"name": "test"},
+		time.Unix(0, 0),
+	)
+	// Metrics without any mapped field are dropped
+	gpuAcc.AddFields("native",
+		map[string]interface{}{"serial": "1234"},
+		map[string]string{"card": "card1"},
+		time.Unix(0, 0),
+	)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"gpu",
+			map[string]string{"vendor": "test", "index": "1", "uuid": "abc"},
+			map[string]interface{}{
+				"utilization": float64(42),
+				"memory_used": int64(2 * 1024 * 1024),
+				"power_draw":  float64(150),
+				"temperature": float64(45.5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gpu_link",
+			map[string]string{"vendor": "test", "index": "0", "link": "1"},
+			map[string]interface{}{"tx_bytes": int64(3072)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"native_process",
+			map[string]string{"name": "test"},
+			map[string]interface{}{"pid": 1},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.Empty(t, acc.Errors)
+}
+
+func TestCheckSchema(t *testing.T) {
+	require.NoError(t, CheckSchema(""))
+	require.NoError(t, CheckSchema("native"))
+	require.NoError(t, CheckSchema("gpu"))
+	require.ErrorContains(t, CheckSchema("dcgm"), `invalid 'metric_schema' setting "dcgm"`)
+}
@@ -32,6 +32,7 @@ type WriteConfig struct {
 	MaxRetry         int  `toml:"max_retry"`
 	MaxMessageBytes  int  `toml:"max_message_bytes"`
 	IdempotentWrites bool `toml:"idempotent_writes"`
+	CompressionLevel *int `toml:"compression_level"`
 }
 
 // SetConfig on the sarama.Config object from the WriteConfig struct.
@@ -43,6 +44,9 @@ func (k *WriteConfig) SetConfig(cfg *sarama.Config, log telegraf.Logger) error {
 		cfg.Producer.MaxMessageBytes = k.MaxMessageBytes
 	}
 	cfg.Producer.RequiredAcks = sarama.RequiredAcks(k.RequiredAcks)
+	if k.CompressionLevel != nil {
+		cfg.Producer.CompressionLevel = *k.CompressionLevel
+	}
 	if cfg.Producer.Idempotent {
 		cfg.Net.MaxOpenRequests = 1
 	}
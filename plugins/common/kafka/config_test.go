@@ -4,7 +4,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/IBM/sarama"
 	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestBackoffFunc(t *testing.T) {
@@ -20,3 +23,21 @@ func TestBackoffFunc(t *testing.T) {
 	f = makeBackoffFunc(b, 0)      // max = 0 means no max
 	require.Equal(t, b*8, f(3, 0)) // with no max, it's 2000
 }
+
+func TestCompressionLevel(t *testing.T) {
+	cfg := sarama.NewConfig()
+	k := &WriteConfig{}
+	require.NoError(t, k.SetConfig(cfg, testutil.Logger{}))
+	require.Equal(t, sarama.CompressionLevelDefault, cfg.Producer.CompressionLevel)
+
+	level := 9
+	k = &WriteConfig{
+		Config:           Config{CompressionCodec: int(sarama.CompressionGZIP)},
+		CompressionLevel: &level,
+	}
+	cfg = sarama.NewConfig()
+	require.NoError(t, k.SetConfig(cfg, testutil.Logger{}))
+	require.Equal(t, sarama.CompressionGZIP, cfg.Producer.Compression)
+	require.Equal(t, 9, cfg.Producer.CompressionLevel)
+	require.NoError(t, cfg.Validate())
+}
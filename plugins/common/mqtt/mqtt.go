@@ -15,11 +15,17 @@ import (
 // mqtt v5-specific publish properties.
 // See https://docs.oasis-open.org/mqtt/mqtt/v5.0/os/mqtt-v5.0-os.html#_Toc3901109
 type PublishProperties struct {
-	ContentType    string            `toml:"content_type"`
-	ResponseTopic  string            `toml:"response_topic"`
-	MessageExpiry  config.Duration   `toml:"message_expiry"`
-	TopicAlias     *uint16           `toml:"topic_alias"`
-	UserProperties map[string]string `toml:"user_properties"`
+	ContentType            string            `toml:"content_type"`
+	ResponseTopic          string            `toml:"response_topic"`
+	MessageExpiry          config.Duration   `toml:"message_expiry"`
+	TopicAlias             *uint16           `toml:"topic_alias"`
+	PayloadFormatIndicator bool              `toml:"payload_format_indicator"`
+	UserProperties         map[string]string `toml:"user_properties"`
+}
+
+// PublishOptions overrides the settings of the client for a single message
+type PublishOptions struct {
+	Retain bool
 }
 
 type MqttConfig struct {
@@ -49,6 +55,7 @@ type MqttConfig struct {
 type Client interface {
 	Connect() (bool, error)
 	Publish(topic string, data []byte) error
+	PublishWithOptions(topic string, data []byte, opts PublishOptions) error
 	SubscribeMultiple(filters map[string]byte, callback paho.MessageHandler) error
 	AddRoute(topic string, callback paho.MessageHandler)
 	Close() error
@@ -114,7 +114,11 @@ func (m *mqttv311Client) Connect() (bool, error) {
 }
 
 func (m *mqttv311Client) Publish(topic string, body []byte) error {
-	token := m.client.Publish(topic, byte(m.qos), m.retain, body)
+	return m.PublishWithOptions(topic, body, PublishOptions{Retain: m.retain})
+}
+
+func (m *mqttv311Client) PublishWithOptions(topic string, body []byte, opts PublishOptions) error {
+	token := m.client.Publish(topic, byte(m.qos), opts.Retain, body)
 	if !token.WaitTimeout(m.timeout) {
 		return internal.ErrTimeout
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"time"
+	"unicode/utf8"
 
 	mqttv5auto "github.com/eclipse/paho.golang/autopaho"
 	mqttv5 "github.com/eclipse/paho.golang/paho"
@@ -25,6 +26,9 @@ type mqttv5Client struct {
 	retain      bool
 	clientTrace bool
 	properties  *mqttv5.PublishProperties
+
+	// Mark UTF-8 payloads using the payload format indicator
+	payloadFormatIndicator bool
 }
 
 func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
@@ -77,7 +81,9 @@ func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
 	// Build the v5 specific publish properties if they are present in the config.
 	// These should not change during the lifecycle of the client.
 	var properties *mqttv5.PublishProperties
+	var payloadFormatIndicator bool
 	if cfg.PublishPropertiesV5 != nil {
+		payloadFormatIndicator = cfg.PublishPropertiesV5.PayloadFormatIndicator
 		properties = &mqttv5.PublishProperties{
 			ContentType:   cfg.PublishPropertiesV5.ContentType,
 			ResponseTopic: cfg.PublishPropertiesV5.ResponseTopic,
@@ -104,6 +110,8 @@ func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
 		retain:      cfg.Retain,
 		properties:  properties,
 		clientTrace: cfg.ClientTrace,
+
+		payloadFormatIndicator: payloadFormatIndicator,
 	}, nil
 }
 
@@ -136,15 +144,32 @@ func (m *mqttv5Client) Connect() (bool, error) {
 }
 
 func (m *mqttv5Client) Publish(topic string, body []byte) error {
+	return m.PublishWithOptions(topic, body, PublishOptions{Retain: m.retain})
+}
+
+func (m *mqttv5Client) PublishWithOptions(topic string, body []byte, opts PublishOptions) error {
 	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
 	defer cancel()
 
+	properties := m.properties
+	if m.payloadFormatIndicator && utf8.Valid(body) {
+		// Copy the properties as they are shared by all messages
+		if properties != nil {
+			p := *properties
+			properties = &p
+		} else {
+			properties = &mqttv5.PublishProperties{}
+		}
+		utf8Format := byte(1)
+		properties.PayloadFormat = &utf8Format
+	}
+
 	_, err := m.client.Publish(ctx, &mqttv5.Publish{
 		Topic:      topic,
 		QoS:        byte(m.qos),
-		Retain:     m.retain,
+		Retain:     opts.Retain,
 		Payload:    body,
-		Properties: m.properties,
+		Properties: properties,
 	})
 
 	return err
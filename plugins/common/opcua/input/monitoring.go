@@ -0,0 +1,71 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+)
+
+func checkDataChangeFilterParameters(params *DataChangeFilter) error {
+	switch {
+	case params.Trigger != Status &&
+		params.Trigger != StatusValue &&
+		params.Trigger != StatusValueTimestamp:
+		return fmt.Errorf("trigger '%s' not supported", params.Trigger)
+	case params.DeadbandType != Absolute &&
+		params.DeadbandType != Percent:
+		return fmt.Errorf("deadband_type '%s' not supported", params.DeadbandType)
+	case params.DeadbandValue == nil:
+		return errors.New("deadband_value was not set")
+	case *params.DeadbandValue < 0:
+		return errors.New("negative deadband_value not supported")
+	default:
+		return nil
+	}
+}
+
+// MonitoredItemRequests creates the requests for monitoring the value of all
+// nodes applying the monitoring parameters of the node. The node index is
+// used as client handle of the monitored item.
+func (o *OpcUAInputClient) MonitoredItemRequests() ([]*ua.MonitoredItemCreateRequest, error) {
+	requests := make([]*ua.MonitoredItemCreateRequest, 0, len(o.NodeIDs))
+	for i, nodeID := range o.NodeIDs {
+		req := opcua.NewMonitoredItemCreateRequestWithDefaults(nodeID, ua.AttributeIDValue, uint32(i))
+		if err := assignMonitoringParameters(req, &o.NodeMetricMapping[i].Tag.MonitoringParams); err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+func assignMonitoringParameters(req *ua.MonitoredItemCreateRequest, monParams *MonitoringParameters) error {
+	req.RequestedParameters.SamplingInterval = float64(time.Duration(monParams.SamplingInterval) / time.Millisecond)
+
+	if monParams.QueueSize != nil {
+		req.RequestedParameters.QueueSize = *monParams.QueueSize
+	}
+
+	if monParams.DiscardOldest != nil {
+		req.RequestedParameters.DiscardOldest = *monParams.DiscardOldest
+	}
+
+	if monParams.DataChangeFilter != nil {
+		if err := checkDataChangeFilterParameters(monParams.DataChangeFilter); err != nil {
+			return fmt.Errorf("%w, node '%s'", err, req.ItemToMonitor.NodeID)
+		}
+
+		req.RequestedParameters.Filter = ua.NewExtensionObject(
+			&ua.DataChangeFilter{
+				Trigger:       ua.DataChangeTriggerFromString(string(monParams.DataChangeFilter.Trigger)),
+				DeadbandType:  uint32(ua.DeadbandTypeFromString(string(monParams.DataChangeFilter.DeadbandType))),
+				DeadbandValue: *monParams.DataChangeFilter.DeadbandValue,
+			},
+		)
+	}
+
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	// _ "github.com/my_github_user/my_plugin_repo/plugins/inputs/mypluginname"
 
 	"github.com/influxdata/telegraf/plugins/common/shim"
+	"github.com/influxdata/telegraf/plugins/common/tls"
 )
 
 var pollInterval = flag.Duration("poll_interval", 1*time.Second, "how often to send metrics")
@@ -20,6 +21,14 @@ var pollIntervalDisabled = flag.Bool(
 	"set to true to disable polling. You want to use this when you are sending metrics on your own schedule",
 )
 var configFile = flag.String("config", "", "path to the config file for this plugin")
+var connect = flag.String(
+	"connect",
+	"",
+	"address of a listening inputs.execd plugin e.g. tcp://telegraf:7080, uses stdin/stdout if empty",
+)
+var tlsCA = flag.String("tls_ca", "", "path to the CA certificate for connecting")
+var tlsCert = flag.String("tls_cert", "", "path to the client certificate for connecting")
+var tlsKey = flag.String("tls_key", "", "path to the client key for connecting")
 var err error
 
 // This is designed to be simple; Just change the import above, and you're good.
@@ -56,6 +65,24 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Connect to Telegraf over the network instead of using stdin/stdout
+	if *connect != "" {
+		tlsClientConfig := &tls.ClientConfig{
+			TLSCA:   *tlsCA,
+			TLSCert: *tlsCert,
+			TLSKey:  *tlsKey,
+		}
+		tlsConfig, err := tlsClientConfig.TLSConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Err creating TLS config: %s\n", err)
+			os.Exit(1)
+		}
+		if err = shimLayer.Connect(*connect, tlsConfig); err != nil {
+			fmt.Fprintf(os.Stderr, "Err connecting: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// run a single plugin until stdin closes, or we receive a termination signal
 	if err = shimLayer.Run(*pollInterval); err != nil {
 		fmt.Fprintf(os.Stderr, "Err: %s\n", err)
@@ -2,9 +2,12 @@ package shim
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/url"
 	"os"
 	"os/signal"
 	"strings"
@@ -64,6 +67,44 @@ func New() *Shim {
 	}
 }
 
+// Connect connects the shim to an execd plugin listening on the given address
+// e.g. "tcp://telegraf:7080" or "unix:///run/telegraf/execd.sock" and uses
+// the connection instead of stdin and stdout for exchanging metrics. The
+// connection is encrypted if a TLS configuration is given. This allows to run
+// the plugin independently of Telegraf e.g. in a sidecar container.
+func (s *Shim) Connect(address string, tlsConfig *tls.Config) error {
+	u, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("parsing address failed: %w", err)
+	}
+
+	network, addr := u.Scheme, u.Host
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	case "unix":
+		addr = u.Path
+		if addr == "" {
+			addr = u.Host
+		}
+	default:
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	var conn net.Conn
+	if tlsConfig != nil {
+		conn, err = tls.Dial(network, addr, tlsConfig)
+	} else {
+		conn, err = net.Dial(network, addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", address, err)
+	}
+
+	s.stdin = conn
+	s.stdout = conn
+	return nil
+}
+
 func (*Shim) watchForShutdown(cancel context.CancelFunc) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
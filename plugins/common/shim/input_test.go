@@ -3,6 +3,7 @@ package shim
 import (
 	"bufio"
 	"io"
+	"net"
 	"strings"
 	"testing"
 	"time"
@@ -55,6 +56,42 @@ func TestInputShimStdinSignalingWorks(t *testing.T) {
 	<-exited
 }
 
+func TestInputShimConnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	metricProcessed := make(chan bool, 1)
+	exited := make(chan bool, 1)
+
+	shim := New()
+	require.NoError(t, shim.Connect("tcp://"+l.Addr().String(), nil))
+	require.NoError(t, shim.AddInput(&testInput{metricProcessed: metricProcessed}))
+	go func() {
+		if err := shim.Run(40 * time.Second); err != nil {
+			t.Error(err)
+		}
+		exited <- true
+	}()
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+
+	// Signal the shim over the connection and receive the metric
+	_, err = conn.Write([]byte("\n"))
+	require.NoError(t, err)
+	<-metricProcessed
+
+	r := bufio.NewReader(conn)
+	out, err := r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "measurement,tag=tag field=1i 1234000005678\n", out)
+
+	// Closing the connection must stop the shim
+	require.NoError(t, conn.Close())
+	<-exited
+}
+
 func runInputPlugin(t *testing.T, interval time.Duration, stdin io.Reader, stdout, stderr io.Writer) (chan bool, chan bool) {
 	metricProcessed := make(chan bool, 1)
 	exited := make(chan bool, 1)
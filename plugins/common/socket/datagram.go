@@ -1,6 +1,7 @@
 package socket
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -171,7 +172,7 @@ func (l *packetListener) setupUnixgram(u *url.URL, socketMode string, bufferSize
 	return l.setupDecoder()
 }
 
-func (l *packetListener) setupUDP(u *url.URL, ifname string, bufferSize int) error {
+func (l *packetListener) setupUDP(u *url.URL, ifname string, bufferSize int, reusePort bool) error {
 	var conn *net.UDPConn
 
 	addr, err := net.ResolveUDPAddr(u.Scheme, u.Host)
@@ -191,6 +192,13 @@ func (l *packetListener) setupUDP(u *url.URL, ifname string, bufferSize int) err
 		if err != nil {
 			return fmt.Errorf("listening (udp multicast) failed: %w", err)
 		}
+	} else if reusePort {
+		lc := net.ListenConfig{Control: controlReusePort}
+		c, err := lc.ListenPacket(context.Background(), u.Scheme, addr.String())
+		if err != nil {
+			return fmt.Errorf("listening (udp) failed: %w", err)
+		}
+		conn = c.(*net.UDPConn)
 	} else {
 		conn, err = net.ListenUDP(u.Scheme, addr)
 		if err != nil {
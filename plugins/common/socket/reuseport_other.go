@@ -0,0 +1,16 @@
+//go:build !linux
+
+package socket
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Other platforms either lack SO_REUSEPORT or do not distribute the packets
+// across the sockets
+const reusePortSupported = false
+
+func controlReusePort(string, string, syscall.RawConn) error {
+	return errors.New("reusing ports is not supported on this platform")
+}
@@ -3,6 +3,7 @@ package socket
 import (
 	"bufio"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -47,8 +48,9 @@ type Socket struct {
 	tlsCfg        *tls.Config
 	log           telegraf.Logger
 
-	splitter bufio.SplitFunc
-	listener listener
+	splitter  bufio.SplitFunc
+	listener  listener
+	reusePort bool
 }
 
 func (cfg *Config) NewSocket(address string, splitcfg *SplitConfig, logger telegraf.Logger) (*Socket, error) {
@@ -124,7 +126,7 @@ func (s *Socket) Setup() error {
 		s.listener = l
 	case "udp", "udp4", "udp6":
 		l := newPacketListener(s.ContentEncoding, s.MaxDecompressionSize, s.MaxParallelParsers)
-		if err := l.setupUDP(s.url, s.interfaceName, int(s.ReadBufferSize)); err != nil {
+		if err := l.setupUDP(s.url, s.interfaceName, int(s.ReadBufferSize), s.reusePort); err != nil {
 			return err
 		}
 		s.listener = l
@@ -158,6 +160,23 @@ func (s *Socket) Setup() error {
 	return nil
 }
 
+// EnableReusePort allows multiple sockets to bind to the same UDP address
+// with the kernel distributing the incoming packets across the sockets. This
+// must be called before Setup and is only supported on Linux.
+func (s *Socket) EnableReusePort() error {
+	if !reusePortSupported {
+		return errors.New("reusing ports is not supported on this platform")
+	}
+	switch s.url.Scheme {
+	case "udp", "udp4", "udp6":
+	default:
+		return fmt.Errorf("reusing ports is not supported for protocol %q", s.url.Scheme)
+	}
+	s.reusePort = true
+
+	return nil
+}
+
 func (s *Socket) Listen(onData CallbackData, onError CallbackError) {
 	s.listener.listenData(onData, onError)
 }
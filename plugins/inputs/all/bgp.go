@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.bgp
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/bgp" // register plugin
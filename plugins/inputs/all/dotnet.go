@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.dotnet
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/dotnet" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.expvar
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/expvar" // register plugin
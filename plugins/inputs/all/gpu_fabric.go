@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.gpu_fabric
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/gpu_fabric" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.haproxy_dataplane
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/haproxy_dataplane" // register plugin
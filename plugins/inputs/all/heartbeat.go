@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.heartbeat
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/heartbeat" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.intel_gpu
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/intel_gpu" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.keycloak
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/keycloak" // register plugin
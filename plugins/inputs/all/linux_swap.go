@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.linux_swap
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/linux_swap" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.prober
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/prober" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.ptp
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/ptp" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.squid
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/squid" // register plugin
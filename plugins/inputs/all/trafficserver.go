@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.trafficserver
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/trafficserver" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.win_dns_dhcp
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/win_dns_dhcp" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.xdp
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/xdp" // register plugin
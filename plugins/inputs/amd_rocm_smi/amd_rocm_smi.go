@@ -14,18 +14,68 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	common_gpu "github.com/influxdata/telegraf/plugins/common/gpu"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
 //go:embed sample.conf
 var sampleConfig string
 
-const measurement = "amd_rocm_smi"
+const (
+	measurement        = "amd_rocm_smi"
+	processMeasurement = "amd_rocm_smi_process"
+)
+
+// gpuSchema converts the native metrics into the common GPU schema
+var gpuSchema = &common_gpu.Schema{
+	Vendor: "amd",
+	Measurements: map[string]common_gpu.Mapping{
+		measurement: {
+			Tags: map[string]common_gpu.Tag{
+				"name":          {Name: "index", Convert: cardIndex},
+				"gpu_unique_id": {Name: "uuid", Convert: uniqueID},
+			},
+			Fields: map[string]common_gpu.Field{
+				"utilization_gpu":             {Name: "utilization"},
+				"utilization_memory":          {Name: "memory_utilization"},
+				"memory_total":                {Name: "memory_total"},
+				"memory_used":                 {Name: "memory_used"},
+				"memory_free":                 {Name: "memory_free"},
+				"temperature_sensor_edge":     {Name: "temperature"},
+				"temperature_sensor_junction": {Name: "temperature_hotspot"},
+				"temperature_sensor_memory":   {Name: "temperature_memory"},
+				"power_draw":                  {Name: "power_draw"},
+				"power_cap":                   {Name: "power_limit"},
+				"clocks_current_sm":           {Name: "clock_graphics"},
+				"clocks_current_memory":       {Name: "clock_memory"},
+				"fan_speed":                   {Name: "fan_speed"},
+			},
+		},
+	},
+}
+
+// cardIndex converts card names like "card0" to the index of the card
+func cardIndex(name string) string {
+	return strings.TrimPrefix(name, "card")
+}
+
+// uniqueID drops unique IDs not reported by the card
+func uniqueID(id string) string {
+	if id == "N/A" {
+		return ""
+	}
+	return id
+}
 
 type ROCmSMI struct {
-	BinPath string          `toml:"bin_path"`
-	Timeout config.Duration `toml:"timeout"`
-	Log     telegraf.Logger `toml:"-"`
+	BinPath      string          `toml:"bin_path"`
+	Timeout      config.Duration `toml:"timeout"`
+	UseLibrary   bool            `toml:"use_library"`
+	LibraryPath  string          `toml:"library_path"`
+	MetricSchema string          `toml:"metric_schema"`
+	Log          telegraf.Logger `toml:"-"`
+
+	library *rsmiLibrary
 }
 
 type gpu struct {
@@ -101,6 +151,10 @@ type sysInfo struct {
 	DriverVersion string `json:"Driver version"`
 }
 
+type processInfo struct {
+	System map[string]interface{} `json:"system"`
+}
+
 type metric struct {
 	tags   map[string]string
 	fields map[string]interface{}
@@ -110,7 +164,20 @@ func (*ROCmSMI) SampleConfig() string {
 	return sampleConfig
 }
 
+func (rsmi *ROCmSMI) Init() error {
+	return common_gpu.CheckSchema(rsmi.MetricSchema)
+}
+
 func (rsmi *ROCmSMI) Start(telegraf.Accumulator) error {
+	if rsmi.UseLibrary {
+		library, err := loadLibrary(rsmi.LibraryPath)
+		if err != nil {
+			return &internal.StartupError{Err: err}
+		}
+		rsmi.library = library
+		return nil
+	}
+
 	if _, err := os.Stat(rsmi.BinPath); os.IsNotExist(err) {
 		binPath, err := exec.LookPath("rocm-smi")
 		if err != nil {
@@ -123,6 +190,14 @@ func (rsmi *ROCmSMI) Start(telegraf.Accumulator) error {
 }
 
 func (rsmi *ROCmSMI) Gather(acc telegraf.Accumulator) error {
+	if rsmi.MetricSchema == "gpu" {
+		acc = &common_gpu.Accumulator{Accumulator: acc, Schema: gpuSchema}
+	}
+
+	if rsmi.library != nil {
+		return rsmi.library.gather(acc)
+	}
+
 	data, err := rsmi.pollROCmSMI()
 	if err != nil {
 		return fmt.Errorf("failed to execute command in pollROCmSMI: %w", err)
@@ -131,7 +206,15 @@ func (rsmi *ROCmSMI) Gather(acc telegraf.Accumulator) error {
 	return gatherROCmSMI(data, acc)
 }
 
-func (*ROCmSMI) Stop() {}
+func (rsmi *ROCmSMI) Stop() {
+	if rsmi.library == nil {
+		return
+	}
+	if err := rsmi.library.close(); err != nil {
+		rsmi.Log.Errorf("Closing library failed: %v", err)
+	}
+	rsmi.library = nil
+}
 
 func (rsmi *ROCmSMI) pollROCmSMI() ([]byte, error) {
 	// Construct and execute metrics query, there currently exist (ROCm v4.3.x) a "-a" option
@@ -220,6 +303,7 @@ func genTagsFields(gpus map[string]gpu, system map[string]sysInfo) []metric {
 			setIfUsed("int", fields, "clocks_current_fabric", strings.Trim(payload.GpuFclkClockSpeed, "(Mhz)"))
 			setIfUsed("int", fields, "clocks_current_system", strings.Trim(payload.GpuSocclkClockSpeed, "(Mhz)"))
 			setIfUsed("float", fields, "power_draw", payload.GpuAveragePower)
+			setIfUsed("float", fields, "power_cap", payload.GpuMaxPower)
 			setIfUsed("str", fields, "card_series", payload.GpuCardSeries)
 			setIfUsed("str", fields, "card_model", payload.GpuCardModel)
 			setIfUsed("str", fields, "card_vendor", payload.GpuCardVendor)
@@ -230,6 +314,40 @@ func genTagsFields(gpus map[string]gpu, system map[string]sysInfo) []metric {
 	return metrics
 }
 
+// genProcessTagsFields creates the metrics of the processes reported in the
+// system section as "PID<pid>": "<name>, <number of GPUs>, <VRAM used>,
+// <SDMA usage>, <CU occupancy>"
+func genProcessTagsFields(system map[string]interface{}) []metric {
+	metrics := make([]metric, 0)
+	for key, value := range system {
+		pid, found := strings.CutPrefix(key, "PID")
+		if !found {
+			continue
+		}
+		info, ok := value.(string)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(info, ",")
+		for len(parts) < 5 {
+			parts = append(parts, "")
+		}
+
+		tags := make(map[string]string, 1)
+		setTagIfUsed(tags, "name", strings.TrimSpace(parts[0]))
+
+		fields := make(map[string]interface{}, 5)
+		setIfUsed("int", fields, "pid", pid)
+		setIfUsed("int", fields, "num_gpus", parts[1])
+		setIfUsed("int64", fields, "vram_used", parts[2])
+		setIfUsed("int64", fields, "sdma_usage", parts[3])
+		setIfUsed("int", fields, "cu_occupancy", parts[4])
+
+		metrics = append(metrics, metric{tags, fields})
+	}
+	return metrics
+}
+
 func gatherROCmSMI(ret []byte, acc telegraf.Accumulator) error {
 	var gpus map[string]gpu
 	var sys map[string]sysInfo
@@ -244,10 +362,18 @@ func gatherROCmSMI(ret []byte, acc telegraf.Accumulator) error {
 		return err2
 	}
 
+	var procs processInfo
+	if err := json.Unmarshal(ret, &procs); err != nil {
+		return err
+	}
+
 	metrics := genTagsFields(gpus, sys)
 	for _, metric := range metrics {
 		acc.AddFields(measurement, metric.fields, metric.tags)
 	}
+	for _, metric := range genProcessTagsFields(procs.System) {
+		acc.AddFields(processMeasurement, metric.fields, metric.tags)
+	}
 
 	return nil
 }
@@ -298,8 +424,9 @@ func setIfUsed(t string, m map[string]interface{}, k, v string) {
 func init() {
 	inputs.Add("amd_rocm_smi", func() telegraf.Input {
 		return &ROCmSMI{
-			BinPath: "/opt/rocm/bin/rocm-smi",
-			Timeout: config.Duration(5 * time.Second),
+			BinPath:     "/opt/rocm/bin/rocm-smi",
+			Timeout:     config.Duration(5 * time.Second),
+			LibraryPath: "/opt/rocm/lib/librocm_smi64.so",
 		}
 	})
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/models"
+	common_gpu "github.com/influxdata/telegraf/plugins/common/gpu"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -123,6 +124,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       167,
 						"clocks_current_system":       960,
 						"power_draw":                  15.0,
+						"power_cap":                   170.0,
 					},
 					time.Unix(0, 0)),
 			},
@@ -158,6 +160,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1000,
 						"clocks_current_system":       971,
 						"power_draw":                  26.0,
+						"power_cap":                   225.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -192,6 +195,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1200,
 						"clocks_current_system":       1000,
 						"power_draw":                  39.0,
+						"power_cap":                   290.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -220,6 +224,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1200,
 						"clocks_current_system":       1000,
 						"power_draw":                  37.0,
+						"power_cap":                   290.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -248,6 +253,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1200,
 						"clocks_current_system":       1000,
 						"power_draw":                  35.0,
+						"power_cap":                   290.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -276,6 +282,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1200,
 						"clocks_current_system":       1000,
 						"power_draw":                  39.0,
+						"power_cap":                   290.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -304,6 +311,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1200,
 						"clocks_current_system":       1000,
 						"power_draw":                  39.0,
+						"power_cap":                   290.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -332,9 +340,23 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1200,
 						"clocks_current_system":       1000,
 						"power_draw":                  40.0,
+						"power_cap":                   290.0,
 					},
 					time.Unix(0, 0),
 				),
+				testutil.MustMetric(
+					"amd_rocm_smi_process",
+					map[string]string{
+						"name": "mlir-cpu-runner",
+					},
+					map[string]interface{}{
+						"pid":          104225,
+						"num_gpus":     0,
+						"vram_used":    int64(0),
+						"sdma_usage":   int64(0),
+						"cu_occupancy": 0,
+					},
+					time.Unix(0, 0)),
 			},
 		},
 		{
@@ -366,6 +388,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1200,
 						"clocks_current_system":       1000,
 						"power_draw":                  36.0,
+						"power_cap":                   290.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -394,6 +417,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1200,
 						"clocks_current_system":       1000,
 						"power_draw":                  44.0,
+						"power_cap":                   290.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -422,6 +446,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1200,
 						"clocks_current_system":       1000,
 						"power_draw":                  43.0,
+						"power_cap":                   290.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -450,6 +475,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       1200,
 						"clocks_current_system":       1000,
 						"power_draw":                  39.0,
+						"power_cap":                   290.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -485,6 +511,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       96,
 						"clocks_current_system":       685,
 						"power_draw":                  6.0,
+						"power_cap":                   211.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -520,6 +547,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       96,
 						"clocks_current_system":       685,
 						"power_draw":                  6.0,
+						"power_cap":                   211.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -544,6 +572,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"memory_total":                int64(12868124672),
 						"memory_used":                 int64(1572757504),
 						"memory_free":                 int64(11295367168),
+						"power_cap":                   211.0,
 						"temperature_sensor_edge":     45.0,
 						"temperature_sensor_junction": 47.0,
 						"temperature_sensor_memory":   46.0,
@@ -589,6 +618,7 @@ func TestGatherValidJSON(t *testing.T) {
 						"clocks_current_memory":       96,
 						"clocks_current_system":       685,
 						"power_draw":                  6.0,
+						"power_cap":                   211.0,
 					},
 					time.Unix(0, 0),
 				),
@@ -608,3 +638,135 @@ func TestGatherValidJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestGPUSchema(t *testing.T) {
+	octets, err := os.ReadFile(filepath.Join("testdata", "rx6700xt_rocm612.json"))
+	require.NoError(t, err)
+
+	plugin := &ROCmSMI{MetricSchema: "gpu", Log: &testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, gatherROCmSMI(octets, &common_gpu.Accumulator{Accumulator: &acc, Schema: gpuSchema}))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"gpu",
+			map[string]string{
+				"vendor": "amd",
+				"index":  "0",
+			},
+			map[string]interface{}{
+				"memory_total":        int64(12868124672),
+				"memory_used":         int64(1572745216),
+				"memory_free":         int64(11295379456),
+				"temperature":         45.0,
+				"temperature_hotspot": 47.0,
+				"temperature_memory":  46.0,
+				"utilization":         0.0,
+				"memory_utilization":  12.0,
+				"clock_graphics":      0.0,
+				"clock_memory":        96.0,
+				"power_draw":          6.0,
+				"power_limit":         211.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestLibraryGather(t *testing.T) {
+	const unsupported = 2
+	library := &rsmiLibrary{
+		numMonitorDevices: func(n *uint32) uint32 {
+			*n = 2
+			return rsmiStatusSuccess
+		},
+		devIDGet: func(_ uint32, id *uint16) uint32 {
+			*id = 0x73df
+			return rsmiStatusSuccess
+		},
+		devUniqueIDGet: func(device uint32, id *uint64) uint32 {
+			*id = 0xabcdef0 + uint64(device)
+			return rsmiStatusSuccess
+		},
+		devBusyPercentGet: func(device uint32, p *uint32) uint32 {
+			*p = 10 * (device + 1)
+			return rsmiStatusSuccess
+		},
+		devMemoryBusyPercentGet: func(uint32, *uint32) uint32 {
+			return unsupported
+		},
+		devMemoryTotalGet: func(_, _ uint32, v *uint64) uint32 {
+			*v = 1000
+			return rsmiStatusSuccess
+		},
+		devMemoryUsageGet: func(_, _ uint32, v *uint64) uint32 {
+			*v = 400
+			return rsmiStatusSuccess
+		},
+		devTempMetricGet: func(device, sensor, _ uint32, v *int64) uint32 {
+			if device == 1 && sensor != rsmiTempTypeEdge {
+				return unsupported
+			}
+			*v = 40000 + int64(sensor)*1500
+			return rsmiStatusSuccess
+		},
+		devPowerAveGet: func(_, _ uint32, v *uint64) uint32 {
+			*v = 6500000
+			return rsmiStatusSuccess
+		},
+		devPowerCapGet: func(_, _ uint32, v *uint64) uint32 {
+			*v = 211000000
+			return rsmiStatusSuccess
+		},
+		devFanSpeedGet: func(_, _ uint32, v *int64) uint32 {
+			*v = 64
+			return rsmiStatusSuccess
+		},
+		devFanSpeedMaxGet: func(_, _ uint32, v *uint64) uint32 {
+			*v = 255
+			return rsmiStatusSuccess
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, library.gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"amd_rocm_smi",
+			map[string]string{"name": "card0", "gpu_id": "0x73df", "gpu_unique_id": "0xabcdef0"},
+			map[string]interface{}{
+				"utilization_gpu":             10,
+				"memory_total":                int64(1000),
+				"memory_used":                 int64(400),
+				"memory_free":                 int64(600),
+				"temperature_sensor_edge":     40.0,
+				"temperature_sensor_junction": 41.5,
+				"temperature_sensor_memory":   43.0,
+				"power_draw":                  6.5,
+				"power_cap":                   211.0,
+				"fan_speed":                   25,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"amd_rocm_smi",
+			map[string]string{"name": "card1", "gpu_id": "0x73df", "gpu_unique_id": "0xabcdef1"},
+			map[string]interface{}{
+				"utilization_gpu":         20,
+				"memory_total":            int64(1000),
+				"memory_used":             int64(400),
+				"memory_free":             int64(600),
+				"temperature_sensor_edge": 40.0,
+				"power_draw":              6.5,
+				"power_cap":               211.0,
+				"fan_speed":               25,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,113 @@
+package amd_rocm_smi
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Constants of the ROCm SMI library, see rocm_smi.h
+const (
+	rsmiStatusSuccess = 0
+
+	rsmiMemTypeVRAM = 0
+
+	rsmiTempTypeEdge     = 0
+	rsmiTempTypeJunction = 1
+	rsmiTempTypeMemory   = 2
+	rsmiTempCurrent      = 0
+)
+
+// rsmiLibrary holds the functions of the ROCm SMI library used to query the
+// GPUs without calling the rocm-smi binary. All functions return a status
+// code of type rsmi_status_t.
+type rsmiLibrary struct {
+	close func() error
+
+	numMonitorDevices       func(numDevices *uint32) uint32
+	devIDGet                func(device uint32, id *uint16) uint32
+	devUniqueIDGet          func(device uint32, id *uint64) uint32
+	devBusyPercentGet       func(device uint32, percent *uint32) uint32
+	devMemoryBusyPercentGet func(device uint32, percent *uint32) uint32
+	devMemoryTotalGet       func(device, memType uint32, total *uint64) uint32
+	devMemoryUsageGet       func(device, memType uint32, used *uint64) uint32
+	devTempMetricGet        func(device, sensor, metric uint32, temperature *int64) uint32
+	devPowerAveGet          func(device, sensor uint32, power *uint64) uint32
+	devPowerCapGet          func(device, sensor uint32, power *uint64) uint32
+	devFanSpeedGet          func(device, sensor uint32, speed *int64) uint32
+	devFanSpeedMaxGet       func(device, sensor uint32, speed *uint64) uint32
+}
+
+// gather queries all GPUs via the library and adds the metrics in the same
+// format as the ones parsed from the rocm-smi output. Values not supported
+// by a device are omitted.
+func (l *rsmiLibrary) gather(acc telegraf.Accumulator) error {
+	var count uint32
+	if status := l.numMonitorDevices(&count); status != rsmiStatusSuccess {
+		return fmt.Errorf("getting number of devices failed with status %d", status)
+	}
+
+	for device := uint32(0); device < count; device++ {
+		tags := map[string]string{"name": "card" + strconv.FormatUint(uint64(device), 10)}
+		fields := make(map[string]interface{}, 12)
+
+		var id uint16
+		if l.devIDGet(device, &id) == rsmiStatusSuccess {
+			tags["gpu_id"] = fmt.Sprintf("0x%x", id)
+		}
+		var uniqueID uint64
+		if l.devUniqueIDGet(device, &uniqueID) == rsmiStatusSuccess {
+			tags["gpu_unique_id"] = fmt.Sprintf("0x%x", uniqueID)
+		}
+
+		var busy uint32
+		if l.devBusyPercentGet(device, &busy) == rsmiStatusSuccess {
+			fields["utilization_gpu"] = int(busy)
+		}
+		if l.devMemoryBusyPercentGet(device, &busy) == rsmiStatusSuccess {
+			fields["utilization_memory"] = int(busy)
+		}
+
+		var total, used uint64
+		if l.devMemoryTotalGet(device, rsmiMemTypeVRAM, &total) == rsmiStatusSuccess &&
+			l.devMemoryUsageGet(device, rsmiMemTypeVRAM, &used) == rsmiStatusSuccess {
+			fields["memory_total"] = int64(total)
+			fields["memory_used"] = int64(used)
+			fields["memory_free"] = int64(total) - int64(used)
+		}
+
+		// Temperatures are reported in millidegrees Celsius
+		sensors := map[string]uint32{
+			"temperature_sensor_edge":     rsmiTempTypeEdge,
+			"temperature_sensor_junction": rsmiTempTypeJunction,
+			"temperature_sensor_memory":   rsmiTempTypeMemory,
+		}
+		for name, sensor := range sensors {
+			var temperature int64
+			if l.devTempMetricGet(device, sensor, rsmiTempCurrent, &temperature) == rsmiStatusSuccess {
+				fields[name] = float64(temperature) / 1000.0
+			}
+		}
+
+		// Power values are reported in microwatts
+		var power uint64
+		if l.devPowerAveGet(device, 0, &power) == rsmiStatusSuccess {
+			fields["power_draw"] = float64(power) / 1e6
+		}
+		if l.devPowerCapGet(device, 0, &power) == rsmiStatusSuccess {
+			fields["power_cap"] = float64(power) / 1e6
+		}
+
+		var speed int64
+		var speedMax uint64
+		if l.devFanSpeedGet(device, 0, &speed) == rsmiStatusSuccess &&
+			l.devFanSpeedMaxGet(device, 0, &speedMax) == rsmiStatusSuccess && speedMax > 0 {
+			fields["fan_speed"] = int(speed * 100 / int64(speedMax))
+		}
+
+		acc.AddFields(measurement, fields, tags)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+package amd_rocm_smi
+
+import (
+	"fmt"
+
+	"github.com/ebitengine/purego"
+)
+
+// loadLibrary opens the ROCm SMI shared library at the given path and
+// initializes it
+func loadLibrary(path string) (*rsmiLibrary, error) {
+	handle, err := purego.Dlopen(path, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		return nil, fmt.Errorf("loading library %q failed: %w", path, err)
+	}
+
+	var initialize func(flags uint64) uint32
+	var shutDown func() uint32
+	l := &rsmiLibrary{}
+	symbols := map[string]interface{}{
+		"rsmi_init":                        &initialize,
+		"rsmi_shut_down":                   &shutDown,
+		"rsmi_num_monitor_devices":         &l.numMonitorDevices,
+		"rsmi_dev_id_get":                  &l.devIDGet,
+		"rsmi_dev_unique_id_get":           &l.devUniqueIDGet,
+		"rsmi_dev_busy_percent_get":        &l.devBusyPercentGet,
+		"rsmi_dev_memory_busy_percent_get": &l.devMemoryBusyPercentGet,
+		"rsmi_dev_memory_total_get":        &l.devMemoryTotalGet,
+		"rsmi_dev_memory_usage_get":        &l.devMemoryUsageGet,
+		"rsmi_dev_temp_metric_get":         &l.devTempMetricGet,
+		"rsmi_dev_power_ave_get":           &l.devPowerAveGet,
+		"rsmi_dev_power_cap_get":           &l.devPowerCapGet,
+		"rsmi_dev_fan_speed_get":           &l.devFanSpeedGet,
+		"rsmi_dev_fan_speed_max_get":       &l.devFanSpeedMaxGet,
+	}
+	for name, fptr := range symbols {
+		sym, err := purego.Dlsym(handle, name)
+		if err != nil {
+			//nolint:errcheck // ignore the error as we are already failing
+			purego.Dlclose(handle)
+			return nil, fmt.Errorf("looking up symbol %q failed: %w", name, err)
+		}
+		purego.RegisterFunc(fptr, sym)
+	}
+
+	if status := initialize(0); status != rsmiStatusSuccess {
+		//nolint:errcheck // ignore the error as we are already failing
+		purego.Dlclose(handle)
+		return nil, fmt.Errorf("initializing library failed with status %d", status)
+	}
+
+	l.close = func() error {
+		shutDown()
+		return purego.Dlclose(handle)
+	}
+
+	return l, nil
+}
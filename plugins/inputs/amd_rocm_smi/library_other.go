@@ -0,0 +1,9 @@
+//go:build !linux
+
+package amd_rocm_smi
+
+import "errors"
+
+func loadLibrary(string) (*rsmiLibrary, error) {
+	return nil, errors.New("using the ROCm SMI library is only supported on Linux")
+}
@@ -0,0 +1,149 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package bgp
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	measurementPeer  = "bgp_peer"
+	measurementEvent = "bgp_peer_event"
+)
+
+type BGP struct {
+	Daemon       string          `toml:"daemon"`
+	VtyshCommand []string        `toml:"vtysh_command"`
+	UseSudo      bool            `toml:"use_sudo"`
+	BirdSocket   string          `toml:"bird_socket"`
+	Timeout      config.Duration `toml:"timeout"`
+	PeerEvents   bool            `toml:"peer_events"`
+	Log          telegraf.Logger `toml:"-"`
+
+	query func() ([]peer, error)
+
+	// Last known state and dropped connections per peer
+	states map[string]peerState
+}
+
+// peer holds the tags and fields of a peer and address family
+type peer struct {
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+type peerState struct {
+	state   string
+	dropped int64
+}
+
+func (*BGP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *BGP) Init() error {
+	switch b.Daemon {
+	case "", "frr":
+		b.Daemon = "frr"
+		if len(b.VtyshCommand) == 0 {
+			b.VtyshCommand = []string{"vtysh"}
+		}
+		b.query = b.queryFRR
+	case "bird":
+		if b.BirdSocket == "" {
+			b.BirdSocket = "/run/bird/bird.ctl"
+		}
+		b.query = b.queryBird
+	default:
+		return fmt.Errorf("invalid 'daemon' %q", b.Daemon)
+	}
+
+	if b.Timeout <= 0 {
+		return errors.New("'timeout' must be positive")
+	}
+
+	b.states = make(map[string]peerState)
+
+	return nil
+}
+
+func (b *BGP) Gather(acc telegraf.Accumulator) error {
+	peers, err := b.query()
+	if err != nil {
+		return fmt.Errorf("querying %s failed: %w", b.Daemon, err)
+	}
+
+	for _, p := range peers {
+		p.tags["daemon"] = b.Daemon
+		acc.AddFields(measurementPeer, p.fields, p.tags)
+		if b.PeerEvents {
+			b.checkPeerEvent(acc, p)
+		}
+	}
+
+	return nil
+}
+
+// checkPeerEvent emits an event if the state of the peer changed since the
+// last gather cycle or if the session was dropped in between, i.e. the number
+// of dropped connections increased.
+func (b *BGP) checkPeerEvent(acc telegraf.Accumulator, p peer) {
+	state, _ := p.fields["state"].(string)
+	dropped, hasDropped := p.fields["connections_dropped"].(int64)
+
+	key := seriesKey(p.tags)
+	previous, found := b.states[key]
+	b.states[key] = peerState{state: state, dropped: dropped}
+	if !found {
+		return
+	}
+	if previous.state == state && (!hasDropped || dropped <= previous.dropped) {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"previous_state": previous.state,
+		"state":          state,
+	}
+	if hasDropped && dropped > previous.dropped {
+		fields["connections_dropped"] = dropped - previous.dropped
+	}
+	acc.AddFields(measurementEvent, fields, p.tags)
+}
+
+func seriesKey(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var key strings.Builder
+	for _, k := range keys {
+		key.WriteString(k)
+		key.WriteByte('=')
+		key.WriteString(tags[k])
+		key.WriteByte(',')
+	}
+	return key.String()
+}
+
+func init() {
+	inputs.Add("bgp", func() telegraf.Input {
+		return &BGP{
+			Timeout:    config.Duration(5 * time.Second),
+			PeerEvents: true,
+		}
+	})
+}
@@ -0,0 +1,316 @@
+package bgp
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &BGP{Daemon: "quagga"}
+	require.EqualError(t, plugin.Init(), `invalid 'daemon' "quagga"`)
+
+	plugin = &BGP{Daemon: "frr"}
+	require.EqualError(t, plugin.Init(), "'timeout' must be positive")
+}
+
+func TestFRR(t *testing.T) {
+	var executed []string
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		executed = append([]string{name}, args...)
+		return exec.Command("cat", filepath.Join("testdata", "frr_summary.json"))
+	}
+	defer func() { execCommand = exec.Command }()
+
+	plugin := &BGP{
+		UseSudo: true,
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Equal(t, []string{"sudo", "-n", "vtysh", "-c", "show bgp vrf all summary json"}, executed)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"bgp_peer",
+			map[string]string{
+				"daemon":      "frr",
+				"vrf":         "default",
+				"neighbor":    "192.0.2.2",
+				"afi_safi":    "ipv4_unicast",
+				"remote_as":   "65001",
+				"description": "transit",
+			},
+			map[string]interface{}{
+				"state":                   "Established",
+				"established":             true,
+				"uptime":                  int64(3723),
+				"prefixes_received":       int64(120),
+				"prefixes_sent":           int64(12),
+				"messages_received":       int64(1520),
+				"messages_sent":           int64(1498),
+				"connections_established": int64(3),
+				"connections_dropped":     int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"bgp_peer",
+			map[string]string{
+				"daemon":    "frr",
+				"vrf":       "default",
+				"neighbor":  "192.0.2.3",
+				"afi_safi":  "ipv4_unicast",
+				"remote_as": "65002",
+			},
+			map[string]interface{}{
+				"state":                   "Active",
+				"established":             false,
+				"messages_received":       int64(0),
+				"messages_sent":           int64(0),
+				"connections_established": int64(0),
+				"connections_dropped":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"bgp_peer",
+			map[string]string{
+				"daemon":    "frr",
+				"vrf":       "blue",
+				"neighbor":  "2001:db8::2",
+				"afi_safi":  "ipv6_unicast",
+				"remote_as": "65010",
+			},
+			map[string]interface{}{
+				"state":                   "Established",
+				"established":             true,
+				"uptime":                  int64(60),
+				"prefixes_received":       int64(3),
+				"prefixes_sent":           int64(1),
+				"messages_received":       int64(42),
+				"messages_sent":           int64(40),
+				"connections_established": int64(1),
+				"connections_dropped":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestBirdParse(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "bird_protocols.txt"))
+	require.NoError(t, err)
+	lines, err := readBirdReply(bufio.NewReader(bytes.NewReader(buf)))
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 2, 4, 0, 0, 0, time.Local)
+	var acc testutil.Accumulator
+	for _, p := range parseBird(lines, now) {
+		acc.AddFields(measurementPeer, p.fields, p.tags)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"bgp_peer",
+			map[string]string{
+				"protocol":  "upstream",
+				"neighbor":  "192.0.2.2",
+				"afi_safi":  "ipv4_unicast",
+				"remote_as": "65001",
+			},
+			map[string]interface{}{
+				"state":             "Established",
+				"established":       true,
+				"uptime":            int64(3600),
+				"prefixes_received": int64(120),
+				"prefixes_sent":     int64(12),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"bgp_peer",
+			map[string]string{
+				"protocol":  "upstream",
+				"neighbor":  "192.0.2.2",
+				"afi_safi":  "ipv6_unicast",
+				"remote_as": "65001",
+			},
+			map[string]interface{}{
+				"state":             "Established",
+				"established":       true,
+				"uptime":            int64(3600),
+				"prefixes_received": int64(40),
+				"prefixes_sent":     int64(5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"bgp_peer",
+			map[string]string{
+				"protocol":  "backup",
+				"neighbor":  "192.0.2.3",
+				"afi_safi":  "ipv4_unicast",
+				"remote_as": "65002",
+			},
+			map[string]interface{}{
+				"state":       "Active",
+				"established": false,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestBirdSocket(t *testing.T) {
+	reply, err := os.ReadFile(filepath.Join("testdata", "bird_protocols.txt"))
+	require.NoError(t, err)
+
+	socket := filepath.Join(t.TempDir(), "bird.ctl")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		if _, err := conn.Write([]byte("0001 BIRD 2.0.12 ready.\n")); err != nil {
+			return
+		}
+		cmd, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+		received <- cmd
+		conn.Write(reply) //nolint:errcheck // test server
+	}()
+
+	plugin := &BGP{
+		Daemon:     "bird",
+		BirdSocket: socket,
+		Timeout:    config.Duration(5 * time.Second),
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Equal(t, "show protocols all\n", <-received)
+	require.Len(t, acc.GetTelegrafMetrics(), 3)
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, "bird", m.Tags()["daemon"])
+	}
+}
+
+func TestBirdErrorReply(t *testing.T) {
+	input := "9001 Syntax error\n"
+	_, err := readBirdReply(bufio.NewReader(bytes.NewBufferString(input)))
+	require.EqualError(t, err, "error reply 9001: Syntax error")
+}
+
+func TestPeerEvents(t *testing.T) {
+	plugin := &BGP{
+		Timeout:    config.Duration(5 * time.Second),
+		PeerEvents: true,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	states := []struct {
+		state   string
+		dropped int64
+	}{
+		{state: "Established", dropped: 0},
+		{state: "Established", dropped: 0},
+		{state: "Active", dropped: 1},
+		{state: "Established", dropped: 1},
+		{state: "Established", dropped: 3},
+	}
+
+	tags := map[string]string{"vrf": "default", "neighbor": "192.0.2.2", "afi_safi": "ipv4_unicast"}
+	var acc testutil.Accumulator
+	for _, s := range states {
+		plugin.query = func() ([]peer, error) {
+			p := peer{
+				tags: make(map[string]string, len(tags)),
+				fields: map[string]interface{}{
+					"state":               s.state,
+					"connections_dropped": s.dropped,
+				},
+			}
+			for k, v := range tags {
+				p.tags[k] = v
+			}
+			return []peer{p}, nil
+		}
+		require.NoError(t, plugin.Gather(&acc))
+	}
+
+	eventTags := map[string]string{
+		"daemon":   "frr",
+		"vrf":      "default",
+		"neighbor": "192.0.2.2",
+		"afi_safi": "ipv4_unicast",
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"bgp_peer_event",
+			eventTags,
+			map[string]interface{}{
+				"previous_state":      "Established",
+				"state":               "Active",
+				"connections_dropped": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"bgp_peer_event",
+			eventTags,
+			map[string]interface{}{
+				"previous_state": "Active",
+				"state":          "Established",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"bgp_peer_event",
+			eventTags,
+			map[string]interface{}{
+				"previous_state":      "Established",
+				"state":               "Established",
+				"connections_dropped": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == measurementEvent {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
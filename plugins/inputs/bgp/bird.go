@@ -0,0 +1,276 @@
+package bgp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const birdCommand = "show protocols all"
+
+var (
+	// Protocol summary line, e.g.
+	//   bgp1  BGP  ---  up  2024-01-02 03:04:05  Established
+	birdProtocolRE = regexp.MustCompile(
+		`^(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+` +
+			`(\d{4}-\d{2}-\d{2}(?: \d{2}:\d{2}:\d{2}(?:\.\d+)?)?|\d{2}:\d{2}:\d{2}(?:\.\d+)?)\s*(.*)$`,
+	)
+	birdImportedRE = regexp.MustCompile(`(\d+) imported`)
+	birdExportedRE = regexp.MustCompile(`(\d+) exported`)
+
+	// Mapping of BIRD channel names to address families
+	birdChannels = map[string]string{
+		"ipv4":  "ipv4_unicast",
+		"ipv6":  "ipv6_unicast",
+		"vpn4":  "ipv4_vpn",
+		"vpn6":  "ipv6_vpn",
+		"flow4": "ipv4_flowspec",
+		"flow6": "ipv6_flowspec",
+	}
+)
+
+// birdLine is a line of a reply of the BIRD control socket
+type birdLine struct {
+	code string
+	text string
+}
+
+// birdProtocol collects the information of a BGP protocol instance
+type birdProtocol struct {
+	name     string
+	state    string
+	since    string
+	info     string
+	bgpState string
+	neighbor string
+	remoteAS string
+	channels []*birdChannel
+}
+
+type birdChannel struct {
+	name     string
+	imported *int64
+	exported *int64
+}
+
+func (b *BGP) queryBird() ([]peer, error) {
+	conn, err := net.DialTimeout("unix", b.BirdSocket, time.Duration(b.Timeout))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(time.Duration(b.Timeout))); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	if _, err := readBirdReply(reader); err != nil {
+		return nil, fmt.Errorf("reading greeting failed: %w", err)
+	}
+	if _, err := conn.Write([]byte(birdCommand + "\n")); err != nil {
+		return nil, fmt.Errorf("sending command failed: %w", err)
+	}
+	lines, err := readBirdReply(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading reply failed: %w", err)
+	}
+
+	return parseBird(lines, time.Now()), nil
+}
+
+// readBirdReply reads the lines of a reply up to the final line. Lines start
+// with a four-digit code followed by '-' for continued replies or ' ' for the
+// final line. Lines starting with a space continue the previous code.
+func readBirdReply(r *bufio.Reader) ([]birdLine, error) {
+	var lines []birdLine
+	var code string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && line == "" {
+				return nil, io.ErrUnexpectedEOF
+			}
+			if !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if strings.HasPrefix(line, " ") {
+			lines = append(lines, birdLine{code: code, text: line[1:]})
+			continue
+		}
+		if len(line) < 5 {
+			return nil, fmt.Errorf("malformed line %q", line)
+		}
+		code = line[:4]
+		lines = append(lines, birdLine{code: code, text: line[5:]})
+
+		if line[4] == ' ' {
+			// Codes 8xxx and 9xxx denote errors
+			if code[0] == '8' || code[0] == '9' {
+				return nil, fmt.Errorf("error reply %s: %s", code, line[5:])
+			}
+			return lines, nil
+		}
+	}
+}
+
+// parseBird parses the reply of the "show protocols all" command and returns
+// the peers of all BGP protocols
+func parseBird(lines []birdLine, now time.Time) []peer {
+	var protocols []*birdProtocol
+	var current *birdProtocol
+	var channel *birdChannel
+	for _, line := range lines {
+		switch line.code {
+		case "1002":
+			current, channel = nil, nil
+			matches := birdProtocolRE.FindStringSubmatch(strings.TrimSpace(line.text))
+			if matches == nil || matches[2] != "BGP" {
+				continue
+			}
+			current = &birdProtocol{
+				name:  matches[1],
+				state: matches[4],
+				since: matches[5],
+				info:  strings.TrimSpace(matches[6]),
+			}
+			protocols = append(protocols, current)
+		case "1006":
+			if current == nil {
+				continue
+			}
+			text := strings.TrimSpace(line.text)
+			key, value, _ := strings.Cut(text, ":")
+			value = strings.TrimSpace(value)
+			switch {
+			case strings.HasPrefix(text, "Channel "):
+				channel = &birdChannel{name: strings.TrimSpace(strings.TrimPrefix(text, "Channel "))}
+				current.channels = append(current.channels, channel)
+			case key == "BGP state":
+				current.bgpState = value
+			case key == "Neighbor address":
+				current.neighbor = value
+			case key == "Neighbor AS":
+				current.remoteAS = value
+			case key == "Routes":
+				// BIRD 1.x reports the routes without channels
+				if channel == nil {
+					channel = &birdChannel{}
+					current.channels = append(current.channels, channel)
+				}
+				if v, found := birdRouteCount(birdImportedRE, value); found {
+					channel.imported = &v
+				}
+				if v, found := birdRouteCount(birdExportedRE, value); found {
+					channel.exported = &v
+				}
+			}
+		}
+	}
+
+	var peers []peer
+	for _, p := range protocols {
+		peers = append(peers, p.peers(now)...)
+	}
+	return peers
+}
+
+func birdRouteCount(re *regexp.Regexp, text string) (int64, bool) {
+	matches := re.FindStringSubmatch(text)
+	if matches == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(matches[1], 10, 64)
+	return v, err == nil
+}
+
+func (p *birdProtocol) peers(now time.Time) []peer {
+	// The BGP state is only reported in the details of running protocols, use
+	// the info column otherwise, e.g. "Active" or "Connect"
+	state := p.bgpState
+	if state == "" {
+		state, _, _ = strings.Cut(p.info, " ")
+	}
+	if state == "" {
+		state = p.state
+	}
+	established := state == "Established"
+
+	baseTags := map[string]string{"protocol": p.name}
+	if p.neighbor != "" {
+		baseTags["neighbor"] = p.neighbor
+	}
+	if p.remoteAS != "" {
+		baseTags["remote_as"] = p.remoteAS
+	}
+
+	baseFields := map[string]interface{}{
+		"state":       state,
+		"established": established,
+	}
+	if established {
+		if since, ok := parseBirdTime(p.since, now); ok {
+			baseFields["uptime"] = int64(now.Sub(since).Seconds())
+		}
+	}
+
+	channels := p.channels
+	if len(channels) == 0 {
+		channels = []*birdChannel{{}}
+	}
+	peers := make([]peer, 0, len(channels))
+	for _, c := range channels {
+		tags := make(map[string]string, len(baseTags)+1)
+		for k, v := range baseTags {
+			tags[k] = v
+		}
+		if c.name != "" {
+			afi, found := birdChannels[c.name]
+			if !found {
+				afi = c.name
+			}
+			tags["afi_safi"] = afi
+		}
+
+		fields := make(map[string]interface{}, len(baseFields)+2)
+		for k, v := range baseFields {
+			fields[k] = v
+		}
+		if c.imported != nil {
+			fields["prefixes_received"] = *c.imported
+		}
+		if c.exported != nil {
+			fields["prefixes_sent"] = *c.exported
+		}
+		peers = append(peers, peer{tags: tags, fields: fields})
+	}
+	return peers
+}
+
+// parseBirdTime parses the "since" column of the protocol in local time. Times
+// without a date refer to the current day.
+func parseBirdTime(value string, now time.Time) (time.Time, bool) {
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05.999999999", value, now.Location()); err == nil {
+		return t, true
+	}
+
+	t, err := time.ParseInLocation("15:04:05.999999999", value, now.Location())
+	if err != nil {
+		return time.Time{}, false
+	}
+	year, month, day := now.Date()
+	t = time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), now.Location())
+	if t.After(now) {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t, true
+}
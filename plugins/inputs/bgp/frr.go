@@ -0,0 +1,150 @@
+package bgp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// execCommand is used to mock commands in tests
+var execCommand = exec.Command
+
+const frrCommand = "show bgp vrf all summary json"
+
+type frrAddressFamily struct {
+	VrfName string             `json:"vrfName"`
+	AS      int64              `json:"as"`
+	Peers   map[string]frrPeer `json:"peers"`
+}
+
+type frrPeer struct {
+	RemoteAS               int64  `json:"remoteAs"`
+	LocalAS                int64  `json:"localAs"`
+	MsgRcvd                int64  `json:"msgRcvd"`
+	MsgSent                int64  `json:"msgSent"`
+	PeerUptimeMsec         int64  `json:"peerUptimeMsec"`
+	PfxRcd                 *int64 `json:"pfxRcd"`
+	PfxSnt                 *int64 `json:"pfxSnt"`
+	State                  string `json:"state"`
+	ConnectionsEstablished int64  `json:"connectionsEstablished"`
+	ConnectionsDropped     int64  `json:"connectionsDropped"`
+	Description            string `json:"desc"`
+}
+
+func (b *BGP) queryFRR() ([]peer, error) {
+	args := make([]string, 0, len(b.VtyshCommand)+2)
+	args = append(args, b.VtyshCommand...)
+	args = append(args, "-c", frrCommand)
+	if b.UseSudo {
+		args = append([]string{"sudo", "-n"}, args...)
+	}
+
+	cmd := execCommand(args[0], args[1:]...)
+	out, err := internal.StdOutputTimeout(cmd, time.Duration(b.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("running %q failed: %w", args, err)
+	}
+
+	return parseFRR(out)
+}
+
+// parseFRR parses the output of the "show bgp [vrf all] summary json"
+// command. The address families are either on the top-level of the output or
+// nested within the VRFs when querying all VRFs.
+func parseFRR(buf []byte) ([]peer, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &top); err != nil {
+		return nil, fmt.Errorf("decoding summary failed: %w", err)
+	}
+
+	var peers []peer
+	for name, raw := range top {
+		if isFRRAddressFamily(raw) {
+			p, err := parseFRRAddressFamily(name, raw)
+			if err != nil {
+				return nil, err
+			}
+			peers = append(peers, p...)
+			continue
+		}
+
+		// VRF containing the address families, other entries such as error
+		// messages are skipped
+		var vrf map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &vrf); err != nil {
+			continue
+		}
+		for afi, familyRaw := range vrf {
+			if !isFRRAddressFamily(familyRaw) {
+				continue
+			}
+			p, err := parseFRRAddressFamily(afi, familyRaw)
+			if err != nil {
+				return nil, err
+			}
+			peers = append(peers, p...)
+		}
+	}
+
+	return peers, nil
+}
+
+func isFRRAddressFamily(raw json.RawMessage) bool {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return false
+	}
+	_, found := obj["peers"]
+	return found
+}
+
+func parseFRRAddressFamily(name string, raw json.RawMessage) ([]peer, error) {
+	var family frrAddressFamily
+	if err := json.Unmarshal(raw, &family); err != nil {
+		return nil, fmt.Errorf("decoding address family %q failed: %w", name, err)
+	}
+
+	vrf := family.VrfName
+	if vrf == "" {
+		vrf = "default"
+	}
+
+	peers := make([]peer, 0, len(family.Peers))
+	for address, p := range family.Peers {
+		tags := map[string]string{
+			"vrf":       vrf,
+			"neighbor":  address,
+			"afi_safi":  internal.SnakeCase(name),
+			"remote_as": strconv.FormatInt(p.RemoteAS, 10),
+		}
+		if p.Description != "" {
+			tags["description"] = p.Description
+		}
+
+		established := p.State == "Established"
+		fields := map[string]interface{}{
+			"state":                   p.State,
+			"established":             established,
+			"messages_received":       p.MsgRcvd,
+			"messages_sent":           p.MsgSent,
+			"connections_established": p.ConnectionsEstablished,
+			"connections_dropped":     p.ConnectionsDropped,
+		}
+		if established {
+			fields["uptime"] = p.PeerUptimeMsec / 1000
+		}
+		if p.PfxRcd != nil {
+			fields["prefixes_received"] = *p.PfxRcd
+		}
+		if p.PfxSnt != nil {
+			fields["prefixes_sent"] = *p.PfxSnt
+		}
+
+		peers = append(peers, peer{tags: tags, fields: fields})
+	}
+	return peers, nil
+}
@@ -26,6 +26,13 @@ type Bond struct {
 	BondType       string
 }
 
+// LACP port state bits as defined in IEEE 802.1AX
+const (
+	lacpStateSynchronization = 1 << 3
+	lacpStateCollecting      = 1 << 4
+	lacpStateDistributing    = 1 << 5
+)
+
 type sysFiles struct {
 	ModeFile    string
 	SlaveFile   string
@@ -216,16 +223,36 @@ func (bond *Bond) gatherSlavePart(bondName, rawFile string, acc telegraf.Accumul
 		scanPast = true
 	}
 
+	// LACP slaves list the details of the actor and partner LACP PDU after
+	// the churn counters, so we need to track which block we are in and
+	// can only emit the slave metric once the next slave starts.
+	var lacpDetails string
+	flush := func() {
+		if _, found := tags["interface"]; found {
+			acc.AddFields("bond_slave", fields, tags)
+		}
+		tags = map[string]string{
+			"bond": bondName,
+		}
+		fields = map[string]interface{}{
+			"status": 0,
+		}
+		lacpDetails = ""
+	}
+
 	scanner := bufio.NewScanner(strings.NewReader(rawFile))
 	for scanner.Scan() {
 		line := scanner.Text()
-		stats := strings.Split(line, ":")
+		stats := strings.SplitN(line, ":", 2)
 		if len(stats) < 2 {
 			continue
 		}
 		name := strings.TrimSpace(stats[0])
 		value := strings.TrimSpace(stats[1])
 		if strings.Contains(name, "Slave Interface") {
+			if scanPast {
+				flush()
+			}
 			tags["interface"] = value
 			slaveCount++
 		}
@@ -245,26 +272,66 @@ func (bond *Bond) gatherSlavePart(bondName, rawFile string, acc telegraf.Accumul
 				}
 			}
 		}
-		if strings.Contains(name, "Actor Churned Count") {
+		if !scanPast {
+			continue
+		}
+
+		switch {
+		case name == "Aggregator ID":
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			fields["aggregator_id"] = id
+		case name == "Actor Churn State":
+			fields["actor_churn_state"] = value
+		case name == "Partner Churn State":
+			fields["partner_churn_state"] = value
+		case strings.Contains(name, "Actor Churned Count"):
 			count, err := strconv.Atoi(value)
 			if err != nil {
 				return err
 			}
 			fields["actor_churned"] = count
-		}
-		if strings.Contains(name, "Partner Churned Count") {
+		case strings.Contains(name, "Partner Churned Count"):
 			count, err := strconv.Atoi(value)
 			if err != nil {
 				return err
 			}
 			fields["partner_churned"] = count
-			fields["total_churned"] = fields["actor_churned"].(int) + fields["partner_churned"].(int)
-			acc.AddFields("bond_slave", fields, tags)
-			fields = map[string]interface{}{
-				"status": 0,
+			if actor, ok := fields["actor_churned"].(int); ok {
+				fields["total_churned"] = actor + count
 			}
+		case name == "details actor lacp pdu":
+			lacpDetails = "actor"
+		case name == "details partner lacp pdu":
+			lacpDetails = "partner"
+		case lacpDetails != "" && name == "port state":
+			state, err := strconv.ParseUint(value, 10, 8)
+			if err != nil {
+				return err
+			}
+			fields[lacpDetails+"_port_state"] = state
+			if lacpDetails == "partner" {
+				// Decode the bits relevant for the link being usable
+				// according to IEEE 802.1AX
+				fields["partner_synchronization"] = state&lacpStateSynchronization != 0
+				fields["partner_collecting"] = state&lacpStateCollecting != 0
+				fields["partner_distributing"] = state&lacpStateDistributing != 0
+			}
+		case lacpDetails == "partner" && name == "oper key":
+			key, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			fields["partner_oper_key"] = key
+		case lacpDetails == "partner" && name == "system mac address":
+			fields["partner_system_mac"] = value
 		}
 	}
+	if scanPast {
+		flush()
+	}
 	tags = map[string]string{
 		"bond": bondName,
 	}
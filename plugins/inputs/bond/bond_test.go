@@ -119,6 +119,85 @@ Actor Churned Count: 0
 Partner Churned Count: 0
 `
 
+const sampleTestLACPDetails = `
+Ethernet Channel Bonding Driver: v5.15.0
+Bonding Mode: IEEE 802.3ad Dynamic link aggregation
+Transmit Hash Policy: layer3+4 (1)
+MII Status: up
+MII Polling Interval (ms): 100
+Up Delay (ms): 0
+Down Delay (ms): 0
+Peer Notification Delay (ms): 0
+
+802.3ad info
+LACP active: on
+LACP rate: fast
+Min links: 0
+Aggregator selection policy (ad_select): stable
+System priority: 65535
+System MAC address: 3c:ec:ef:5e:71:58
+Active Aggregator Info:
+	Aggregator ID: 1
+	Number of ports: 1
+	Actor Key: 15
+	Partner Key: 32769
+	Partner Mac Address: 00:1c:73:aa:bb:cc
+
+Slave Interface: eth0
+MII Status: up
+Speed: 10000 Mbps
+Duplex: full
+Link Failure Count: 0
+Permanent HW addr: 3c:ec:ef:5e:71:58
+Slave queue ID: 0
+Aggregator ID: 1
+Actor Churn State: none
+Partner Churn State: none
+Actor Churned Count: 0
+Partner Churned Count: 1
+details actor lacp pdu:
+    system priority: 65535
+    system mac address: 3c:ec:ef:5e:71:58
+    port key: 15
+    port priority: 255
+    port number: 1
+    port state: 63
+details partner lacp pdu:
+    system priority: 32768
+    system mac address: 00:1c:73:aa:bb:cc
+    oper key: 32769
+    port priority: 32768
+    port number: 5
+    port state: 63
+
+Slave Interface: eth1
+MII Status: up
+Speed: 10000 Mbps
+Duplex: full
+Link Failure Count: 3
+Permanent HW addr: 3c:ec:ef:5e:71:59
+Slave queue ID: 0
+Aggregator ID: 2
+Actor Churn State: churned
+Partner Churn State: churned
+Actor Churned Count: 4
+Partner Churned Count: 4
+details actor lacp pdu:
+    system priority: 65535
+    system mac address: 3c:ec:ef:5e:71:58
+    port key: 15
+    port priority: 255
+    port number: 2
+    port state: 71
+details partner lacp pdu:
+    system priority: 65535
+    system mac address: 00:00:00:00:00:00
+    oper key: 1
+    port priority: 255
+    port number: 1
+    port state: 1
+`
+
 const sampleSysMode = "802.3ad 5"
 const sampleSysSlaves = "eth0 eth1 "
 const sampleSysAdPorts = " 2 "
@@ -150,13 +229,31 @@ func TestGatherBondInterface(t *testing.T) {
 	acc.AssertContainsTaggedFields(
 		t,
 		"bond_slave",
-		map[string]interface{}{"failures": 2, "status": 1, "actor_churned": 2, "partner_churned": 0, "total_churned": 2},
+		map[string]interface{}{
+			"failures":            2,
+			"status":              1,
+			"aggregator_id":       2,
+			"actor_churn_state":   "none",
+			"partner_churn_state": "none",
+			"actor_churned":       2,
+			"partner_churned":     0,
+			"total_churned":       2,
+		},
 		map[string]string{"bond": "bondLACP", "interface": "eth0"},
 	)
 	acc.AssertContainsTaggedFields(
 		t,
 		"bond_slave",
-		map[string]interface{}{"failures": 1, "status": 1, "actor_churned": 0, "partner_churned": 0, "total_churned": 0},
+		map[string]interface{}{
+			"failures":            1,
+			"status":              1,
+			"aggregator_id":       2,
+			"actor_churn_state":   "none",
+			"partner_churn_state": "none",
+			"actor_churned":       0,
+			"partner_churned":     0,
+			"total_churned":       0,
+		},
 		map[string]string{"bond": "bondLACP", "interface": "eth1"},
 	)
 	acc.AssertContainsTaggedFields(t, "bond_slave", map[string]interface{}{"count": 2}, map[string]string{"bond": "bondLACP"})
@@ -174,13 +271,31 @@ func TestGatherBondInterface(t *testing.T) {
 	acc.AssertContainsTaggedFields(
 		t,
 		"bond_slave",
-		map[string]interface{}{"failures": 2, "status": 1, "actor_churned": 2, "partner_churned": 0, "total_churned": 2},
+		map[string]interface{}{
+			"failures":            2,
+			"status":              1,
+			"aggregator_id":       2,
+			"actor_churn_state":   "none",
+			"partner_churn_state": "none",
+			"actor_churned":       2,
+			"partner_churned":     0,
+			"total_churned":       2,
+		},
 		map[string]string{"bond": "bondLACPUpDown", "interface": "eth0"},
 	)
 	acc.AssertContainsTaggedFields(
 		t,
 		"bond_slave",
-		map[string]interface{}{"failures": 1, "status": 0, "actor_churned": 0, "partner_churned": 0, "total_churned": 0},
+		map[string]interface{}{
+			"failures":            1,
+			"status":              0,
+			"aggregator_id":       2,
+			"actor_churn_state":   "none",
+			"partner_churn_state": "none",
+			"actor_churned":       0,
+			"partner_churned":     0,
+			"total_churned":       0,
+		},
 		map[string]string{"bond": "bondLACPUpDown", "interface": "eth1"},
 	)
 	acc.AssertContainsTaggedFields(t, "bond_slave", map[string]interface{}{"count": 2}, map[string]string{"bond": "bondLACPUpDown"})
@@ -191,3 +306,55 @@ func TestGatherBondInterface(t *testing.T) {
 		map[string]string{"bond": "bondLACPUpDown", "mode": "802.3ad"},
 	)
 }
+
+func TestGatherBondInterfaceLACPDetails(t *testing.T) {
+	var acc testutil.Accumulator
+	bond := &Bond{}
+	require.NoError(t, bond.gatherBondInterface("bond0", sampleTestLACPDetails, &acc))
+
+	acc.AssertContainsTaggedFields(
+		t,
+		"bond_slave",
+		map[string]interface{}{
+			"failures":                0,
+			"status":                  1,
+			"aggregator_id":           1,
+			"actor_churn_state":       "none",
+			"partner_churn_state":     "none",
+			"actor_churned":           0,
+			"partner_churned":         1,
+			"total_churned":           1,
+			"actor_port_state":        uint64(63),
+			"partner_port_state":      uint64(63),
+			"partner_synchronization": true,
+			"partner_collecting":      true,
+			"partner_distributing":    true,
+			"partner_oper_key":        32769,
+			"partner_system_mac":      "00:1c:73:aa:bb:cc",
+		},
+		map[string]string{"bond": "bond0", "interface": "eth0"},
+	)
+	acc.AssertContainsTaggedFields(
+		t,
+		"bond_slave",
+		map[string]interface{}{
+			"failures":                3,
+			"status":                  1,
+			"aggregator_id":           2,
+			"actor_churn_state":       "churned",
+			"partner_churn_state":     "churned",
+			"actor_churned":           4,
+			"partner_churned":         4,
+			"total_churned":           8,
+			"actor_port_state":        uint64(71),
+			"partner_port_state":      uint64(1),
+			"partner_synchronization": false,
+			"partner_collecting":      false,
+			"partner_distributing":    false,
+			"partner_oper_key":        1,
+			"partner_system_mac":      "00:00:00:00:00:00",
+		},
+		map[string]string{"bond": "bond0", "interface": "eth1"},
+	)
+	acc.AssertContainsTaggedFields(t, "bond_slave", map[string]interface{}{"count": 2}, map[string]string{"bond": "bond0"})
+}
@@ -5,13 +5,19 @@ import (
 	"bytes"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -44,14 +50,55 @@ type Ceph struct {
 	GatherAdminSocketStats bool   `toml:"gather_admin_socket_stats"`
 	GatherClusterStats     bool   `toml:"gather_cluster_stats"`
 
+	GatherRbdImageStats  bool            `toml:"gather_rbd_image_stats"`
+	GatherRgwBucketStats bool            `toml:"gather_rgw_bucket_stats"`
+	PoolInclude          []string        `toml:"pool_include"`
+	PoolExclude          []string        `toml:"pool_exclude"`
+	MgrURL               string          `toml:"mgr_url"`
+	MgrUsername          config.Secret   `toml:"mgr_username"`
+	MgrPassword          config.Secret   `toml:"mgr_password"`
+	MgrTimeout           config.Duration `toml:"mgr_timeout"`
+	common_tls.ClientConfig
+
 	Log        telegraf.Logger `toml:"-"`
 	schemaMaps map[socket]perfSchemaMap
+	poolFilter filter.Filter
+	mgr        *mgrClient
 }
 
 func (*Ceph) SampleConfig() string {
 	return sampleConfig
 }
 
+func (c *Ceph) Init() error {
+	f, err := filter.NewIncludeExcludeFilter(c.PoolInclude, c.PoolExclude)
+	if err != nil {
+		return fmt.Errorf("creating pool filter failed: %w", err)
+	}
+	c.poolFilter = f
+
+	if c.GatherRgwBucketStats {
+		if c.MgrURL == "" {
+			return errors.New("'mgr_url' required for gathering RGW bucket statistics")
+		}
+		tlsCfg, err := c.ClientConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+		c.mgr = &mgrClient{
+			url:      strings.TrimSuffix(c.MgrURL, "/"),
+			username: c.MgrUsername,
+			password: c.MgrPassword,
+			client: &http.Client{
+				Transport: &http.Transport{TLSClientConfig: tlsCfg},
+				Timeout:   time.Duration(c.MgrTimeout),
+			},
+		}
+	}
+
+	return nil
+}
+
 func (c *Ceph) Gather(acc telegraf.Accumulator) error {
 	if c.GatherAdminSocketStats {
 		if err := c.gatherAdminSocketStats(acc); err != nil {
@@ -65,6 +112,18 @@ func (c *Ceph) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if c.GatherRbdImageStats {
+		if err := c.gatherRbdImageStats(acc); err != nil {
+			return err
+		}
+	}
+
+	if c.GatherRgwBucketStats {
+		if err := c.gatherRgwBucketStats(acc); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -760,6 +819,7 @@ func init() {
 			CephConfig:             "/etc/ceph/ceph.conf",
 			GatherAdminSocketStats: true,
 			GatherClusterStats:     false,
+			MgrTimeout:             config.Duration(5 * time.Second),
 		}
 	})
 }
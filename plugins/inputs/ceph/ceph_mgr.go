@@ -0,0 +1,268 @@
+package ceph
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+// Order of the values reported per image by 'ceph rbd perf image stats'
+var rbdImageStatNames = []string{
+	"write_ops",
+	"read_ops",
+	"write_bytes",
+	"read_bytes",
+	"write_latency",
+	"read_latency",
+}
+
+// rbdImageStats is used to unmarshal "ceph rbd perf image stats" output
+type rbdImageStats struct {
+	Stats []map[string][]float64 `json:"stats"`
+}
+
+// gatherRbdImageStats queries the per-image IO statistics of the ceph-mgr
+// rbd_support module
+func (c *Ceph) gatherRbdImageStats(acc telegraf.Accumulator) error {
+	output, err := c.execute("rbd perf image stats")
+	if err != nil {
+		return fmt.Errorf("error executing command: %w", err)
+	}
+	return c.decodeRbdImageStats(acc, output)
+}
+
+// decodeRbdImageStats decodes the output of 'ceph rbd perf image stats'
+func (c *Ceph) decodeRbdImageStats(acc telegraf.Accumulator, input string) error {
+	var data rbdImageStats
+	if err := json.Unmarshal([]byte(input), &data); err != nil {
+		return fmt.Errorf("failed to parse json: %q: %w", input, err)
+	}
+
+	for _, entry := range data.Stats {
+		for spec, values := range entry {
+			// The image specification is either 'pool/image' or
+			// 'pool/namespace/image'
+			parts := strings.Split(spec, "/")
+			var tags map[string]string
+			switch len(parts) {
+			case 2:
+				tags = map[string]string{"pool": parts[0], "image": parts[1]}
+			case 3:
+				tags = map[string]string{"pool": parts[0], "namespace": parts[1], "image": parts[2]}
+			default:
+				acc.AddError(fmt.Errorf("invalid image specification %q", spec))
+				continue
+			}
+			if !c.poolFilter.Match(tags["pool"]) {
+				continue
+			}
+
+			fields := make(map[string]interface{}, len(values))
+			for i, v := range values {
+				if i >= len(rbdImageStatNames) {
+					break
+				}
+				fields[rbdImageStatNames[i]] = v
+			}
+			acc.AddFields("ceph_rbd_image", fields, tags)
+		}
+	}
+
+	return nil
+}
+
+// rgwBucket is used to unmarshal the bucket list of the ceph-mgr dashboard API
+type rgwBucket struct {
+	Bucket    string `json:"bucket"`
+	Tenant    string `json:"tenant"`
+	Owner     string `json:"owner"`
+	Zonegroup string `json:"zonegroup"`
+	Usage     map[string]struct {
+		Size         int64 `json:"size"`
+		SizeActual   int64 `json:"size_actual"`
+		SizeUtilized int64 `json:"size_utilized"`
+		NumObjects   int64 `json:"num_objects"`
+	} `json:"usage"`
+	Quota struct {
+		Enabled    bool  `json:"enabled"`
+		MaxSize    int64 `json:"max_size"`
+		MaxObjects int64 `json:"max_objects"`
+	} `json:"bucket_quota"`
+}
+
+// gatherRgwBucketStats queries the per-bucket usage of the RADOS gateway via
+// the ceph-mgr dashboard REST API
+func (c *Ceph) gatherRgwBucketStats(acc telegraf.Accumulator) error {
+	body, err := c.mgr.get("/api/rgw/bucket?stats=true", "application/vnd.ceph.api.v1.1+json")
+	if err != nil {
+		return fmt.Errorf("querying RGW buckets failed: %w", err)
+	}
+	return decodeRgwBuckets(acc, body)
+}
+
+// decodeRgwBuckets decodes the bucket list of the ceph-mgr dashboard API
+func decodeRgwBuckets(acc telegraf.Accumulator, input []byte) error {
+	var buckets []rgwBucket
+	if err := json.Unmarshal(input, &buckets); err != nil {
+		return fmt.Errorf("failed to parse json: %q: %w", string(input), err)
+	}
+
+	for _, b := range buckets {
+		tags := map[string]string{
+			"bucket": b.Bucket,
+			"owner":  b.Owner,
+		}
+		if b.Tenant != "" {
+			tags["tenant"] = b.Tenant
+		}
+		if b.Zonegroup != "" {
+			tags["zonegroup"] = b.Zonegroup
+		}
+
+		// Sum up all usage categories, e.g. 'rgw.main' and 'rgw.multimeta'
+		var size, sizeActual, sizeUtilized, numObjects int64
+		for _, u := range b.Usage {
+			size += u.Size
+			sizeActual += u.SizeActual
+			sizeUtilized += u.SizeUtilized
+			numObjects += u.NumObjects
+		}
+		fields := map[string]interface{}{
+			"size":          size,
+			"size_actual":   sizeActual,
+			"size_utilized": sizeUtilized,
+			"num_objects":   numObjects,
+		}
+		if b.Quota.Enabled {
+			fields["quota_max_size"] = b.Quota.MaxSize
+			fields["quota_max_objects"] = b.Quota.MaxObjects
+		}
+		acc.AddFields("ceph_rgw_bucket", fields, tags)
+	}
+
+	return nil
+}
+
+// mgrClient queries the REST API of the ceph-mgr dashboard module
+type mgrClient struct {
+	url      string
+	username config.Secret
+	password config.Secret
+	client   *http.Client
+	token    string
+}
+
+// get requests the given path and returns the body of the response, a new
+// token is requested if there is none or the current one expired
+func (m *mgrClient) get(path, accept string) ([]byte, error) {
+	if m.token == "" {
+		if err := m.login(); err != nil {
+			return nil, err
+		}
+	}
+
+	body, status, err := m.request(path, accept)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusUnauthorized {
+		if err := m.login(); err != nil {
+			return nil, err
+		}
+		body, status, err = m.request(path, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("received status %d: %s", status, string(body))
+	}
+
+	return body, nil
+}
+
+func (m *mgrClient) request(path, accept string) ([]byte, int, error) {
+	req, err := http.NewRequest(http.MethodGet, m.url+path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Authorization", "Bearer "+m.token)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading response failed: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// login requests a new access token from the dashboard
+func (m *mgrClient) login() error {
+	m.token = ""
+
+	username, err := m.username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+	password, err := m.password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+
+	payload, err := json.Marshal(map[string]string{
+		"username": username.String(),
+		"password": password.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, m.url+"/api/auth", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.ceph.api.v1.0+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("authenticating failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading authentication response failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("authenticating failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body, &auth); err != nil {
+		return fmt.Errorf("parsing authentication response failed: %w", err)
+	}
+	if auth.Token == "" {
+		return errors.New("no token in authentication response")
+	}
+	m.token = auth.Token
+
+	return nil
+}
@@ -1,16 +1,21 @@
 package ceph
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -100,6 +105,155 @@ func TestDecodeOSDPoolStats(t *testing.T) {
 	}
 }
 
+func TestDecodeRbdImageStats(t *testing.T) {
+	input := `{
+		"stat_sort_by": "write_ops",
+		"stats": [
+			{"rbd/vm-100-disk-0": [256, 10, 1048576, 40960, 1844930, 612043]},
+			{"rbd/tenant/vm-101-disk-0": [1, 2, 4096, 8192, 1000, 2000]},
+			{"scratch/tmp": [0, 0, 0, 0, 0, 0]}
+		]
+	}`
+
+	c := &Ceph{PoolExclude: []string{"scratch"}}
+	require.NoError(t, c.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, c.decodeRbdImageStats(&acc, input))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"ceph_rbd_image",
+			map[string]string{"pool": "rbd", "image": "vm-100-disk-0"},
+			map[string]interface{}{
+				"write_ops":     float64(256),
+				"read_ops":      float64(10),
+				"write_bytes":   float64(1048576),
+				"read_bytes":    float64(40960),
+				"write_latency": float64(1844930),
+				"read_latency":  float64(612043),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"ceph_rbd_image",
+			map[string]string{"pool": "rbd", "namespace": "tenant", "image": "vm-101-disk-0"},
+			map[string]interface{}{
+				"write_ops":     float64(1),
+				"read_ops":      float64(2),
+				"write_bytes":   float64(4096),
+				"read_bytes":    float64(8192),
+				"write_latency": float64(1000),
+				"read_latency":  float64(2000),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherRgwBucketStats(t *testing.T) {
+	var logins int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/auth":
+			var creds map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if creds["username"] != "telegraf" || creds["password"] != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			logins++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": "token%d"}`, logins)
+		case "/api/rgw/bucket":
+			// Simulate an expired token for the first login
+			if r.Header.Get("Authorization") != "Bearer token2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("stats") != "true" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			//nolint:errcheck // ignore the returned error as the test will fail anyway
+			w.Write([]byte(`[
+				{
+					"bucket": "backups",
+					"tenant": "",
+					"owner": "backup",
+					"zonegroup": "default",
+					"usage": {
+						"rgw.main": {"size": 5368709120, "size_actual": 5368971264, "size_utilized": 5368709120, "num_objects": 1207},
+						"rgw.multimeta": {"size": 0, "size_actual": 0, "size_utilized": 0, "num_objects": 1}
+					},
+					"bucket_quota": {"enabled": true, "max_size": 1099511627776, "max_objects": -1}
+				},
+				{
+					"bucket": "empty",
+					"tenant": "team",
+					"owner": "team$user",
+					"zonegroup": "default",
+					"usage": {},
+					"bucket_quota": {"enabled": false, "max_size": -1, "max_objects": -1}
+				}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := &Ceph{
+		GatherRgwBucketStats: true,
+		MgrURL:               ts.URL,
+		MgrUsername:          config.NewSecret([]byte("telegraf")),
+		MgrPassword:          config.NewSecret([]byte("secret")),
+		Log:                  &testutil.Logger{},
+	}
+	require.NoError(t, c.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, c.Gather(&acc))
+	require.Equal(t, 2, logins)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"ceph_rgw_bucket",
+			map[string]string{"bucket": "backups", "owner": "backup", "zonegroup": "default"},
+			map[string]interface{}{
+				"size":              int64(5368709120),
+				"size_actual":       int64(5368971264),
+				"size_utilized":     int64(5368709120),
+				"num_objects":       int64(1208),
+				"quota_max_size":    int64(1099511627776),
+				"quota_max_objects": int64(-1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"ceph_rgw_bucket",
+			map[string]string{"bucket": "empty", "owner": "team$user", "tenant": "team", "zonegroup": "default"},
+			map[string]interface{}{
+				"size":          int64(0),
+				"size_actual":   int64(0),
+				"size_utilized": int64(0),
+				"num_objects":   int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestInitRgwBucketStatsWithoutURL(t *testing.T) {
+	c := &Ceph{GatherRgwBucketStats: true}
+	require.ErrorContains(t, c.Init(), "'mgr_url' required")
+}
+
 func TestGather(t *testing.T) {
 	saveFind := findSockets
 	saveDump := perfDump
@@ -3,8 +3,10 @@ package cgroup
 
 import (
 	_ "embed"
+	"fmt"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -12,10 +14,14 @@ import (
 var sampleConfig string
 
 type CGroup struct {
-	Paths []string `toml:"paths"`
-	Files []string `toml:"files"`
-
-	logged map[string]bool
+	Paths          []string `toml:"paths"`
+	Files          []string `toml:"files"`
+	GatherPressure bool     `toml:"gather_pressure"`
+	SystemdUnits   []string `toml:"systemd_units"`
+	CgroupRoot     string   `toml:"cgroup_root"`
+
+	logged     map[string]bool
+	unitFilter filter.Filter
 }
 
 func (*CGroup) SampleConfig() string {
@@ -25,6 +31,17 @@ func (*CGroup) SampleConfig() string {
 func (cg *CGroup) Init() error {
 	cg.logged = make(map[string]bool)
 
+	if cg.CgroupRoot == "" {
+		cg.CgroupRoot = "/sys/fs/cgroup"
+	}
+	if len(cg.SystemdUnits) > 0 {
+		f, err := filter.Compile(cg.SystemdUnits)
+		if err != nil {
+			return fmt.Errorf("compiling systemd unit filter failed: %w", err)
+		}
+		cg.unitFilter = f
+	}
+
 	return nil
 }
 
@@ -3,7 +3,9 @@
 package cgroup
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -25,19 +27,26 @@ func (g *CGroup) Gather(acc telegraf.Accumulator) error {
 			acc.AddError(dir.err)
 			continue
 		}
-		if err := g.gatherDir(acc, dir.path); err != nil {
-			acc.AddError(err)
+		if len(g.Files) > 0 {
+			if err := g.gatherDir(acc, dir); err != nil {
+				acc.AddError(err)
+			}
+		}
+		if g.GatherPressure {
+			if err := g.gatherPressure(acc, dir); err != nil {
+				acc.AddError(err)
+			}
 		}
 	}
 
 	return nil
 }
 
-func (g *CGroup) gatherDir(acc telegraf.Accumulator, dir string) error {
+func (g *CGroup) gatherDir(acc telegraf.Accumulator, dir pathInfo) error {
 	fields := make(map[string]interface{})
 
 	list := make(chan pathInfo)
-	go g.generateFiles(dir, list)
+	go g.generateFiles(dir.path, list)
 
 	for file := range list {
 		if file.err != nil {
@@ -62,9 +71,7 @@ func (g *CGroup) gatherDir(acc telegraf.Accumulator, dir string) error {
 		}
 	}
 
-	tags := map[string]string{"path": dir}
-
-	acc.AddFields(metricName, fields, tags)
+	acc.AddFields(metricName, fields, dir.tags())
 
 	return nil
 }
@@ -74,6 +81,21 @@ func (g *CGroup) gatherDir(acc telegraf.Accumulator, dir string) error {
 type pathInfo struct {
 	path string
 	err  error
+
+	// systemd unit and slice of discovered cgroups
+	unit  string
+	slice string
+}
+
+func (p pathInfo) tags() map[string]string {
+	tags := map[string]string{"path": p.path}
+	if p.unit != "" {
+		tags["unit"] = p.unit
+	}
+	if p.slice != "" {
+		tags["slice"] = p.slice
+	}
+	return tags
 }
 
 func isDir(pathToCheck string) (bool, error) {
@@ -106,6 +128,42 @@ func (g *CGroup) generateDirs(list chan<- pathInfo) {
 			}
 		}
 	}
+
+	if g.unitFilter != nil {
+		g.discoverUnits(list)
+	}
+}
+
+// discoverUnits walks the cgroup v2 hierarchy and supplies the cgroups of the
+// systemd units matching the filter together with their slice
+func (g *CGroup) discoverUnits(list chan<- pathInfo) {
+	err := filepath.WalkDir(g.CgroupRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// cgroups might vanish while walking the hierarchy
+			if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() || p == g.CgroupRoot || !g.unitFilter.Match(d.Name()) {
+			return nil
+		}
+
+		info := pathInfo{path: p, unit: d.Name()}
+		for parent := filepath.Dir(p); parent != g.CgroupRoot && parent != "." && parent != "/"; parent = filepath.Dir(parent) {
+			if name := filepath.Base(parent); strings.HasSuffix(name, ".slice") {
+				info.slice = name
+				break
+			}
+		}
+		list <- info
+
+		// do not report the cgroups below a unit separately
+		return filepath.SkipDir
+	})
+	if err != nil {
+		list <- pathInfo{err: fmt.Errorf("discovering systemd units failed: %w", err)}
+	}
 }
 
 func (g *CGroup) generateFiles(dir string, list chan<- pathInfo) {
@@ -442,3 +442,108 @@ func TestCgroupEscapeDir(t *testing.T) {
 	require.NoError(t, acc.GatherError(cg.Gather))
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
+
+func TestCgroupPressure(t *testing.T) {
+	var acc testutil.Accumulator
+
+	var cg = &CGroup{
+		Paths:          []string{"testdata/v2/system.slice/nginx.service"},
+		GatherPressure: true,
+	}
+	require.NoError(t, cg.Init())
+	require.NoError(t, acc.GatherError(cg.Gather))
+
+	tags := map[string]string{"path": "testdata/v2/system.slice/nginx.service"}
+	expected := []telegraf.Metric{
+		metric.New(
+			"cgroup_pressure",
+			map[string]string{"path": tags["path"], "resource": "cpu", "type": "some"},
+			map[string]interface{}{
+				"avg10":      float64(12.5),
+				"avg60":      float64(8.25),
+				"avg300":     float64(3.1),
+				"total_usec": int64(1523412),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cgroup_pressure",
+			map[string]string{"path": tags["path"], "resource": "cpu", "type": "full"},
+			map[string]interface{}{
+				"avg10":      float64(4),
+				"avg60":      float64(2),
+				"avg300":     float64(0.75),
+				"total_usec": int64(623110),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cgroup_pressure",
+			map[string]string{"path": tags["path"], "resource": "memory", "type": "some"},
+			map[string]interface{}{
+				"avg10":      float64(0),
+				"avg60":      float64(0),
+				"avg300":     float64(0),
+				"total_usec": int64(1200),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cgroup_pressure",
+			map[string]string{"path": tags["path"], "resource": "memory", "type": "full"},
+			map[string]interface{}{
+				"avg10":      float64(0),
+				"avg60":      float64(0),
+				"avg300":     float64(0),
+				"total_usec": int64(800),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestCgroupSystemdUnits(t *testing.T) {
+	var acc testutil.Accumulator
+
+	var cg = &CGroup{
+		GatherPressure: true,
+		SystemdUnits:   []string{"*.service", "*.scope"},
+		CgroupRoot:     "testdata/v2",
+	}
+	require.NoError(t, cg.Init())
+	require.NoError(t, acc.GatherError(cg.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cgroup_pressure",
+			map[string]string{
+				"path":     "testdata/v2/user.slice/user-1000.slice/session-2.scope",
+				"unit":     "session-2.scope",
+				"slice":    "user-1000.slice",
+				"resource": "io",
+				"type":     "some",
+			},
+			map[string]interface{}{
+				"avg10":      float64(0),
+				"avg60":      float64(0.1),
+				"avg300":     float64(0.05),
+				"total_usec": int64(3120),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	actual := make([]telegraf.Metric, 0, len(expected))
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Tags()["resource"] == "io" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+}
+
+func TestParsePressureInvalid(t *testing.T) {
+	_, err := parsePressure([]byte("some avg10=abc avg60=0.00 avg300=0.00 total=0\n"))
+	require.ErrorContains(t, err, `parsing "avg10" failed`)
+}
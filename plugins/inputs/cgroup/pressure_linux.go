@@ -0,0 +1,82 @@
+//go:build linux
+
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Resources with pressure stall information (PSI) in cgroup v2
+var pressureResources = []string{"cpu", "memory", "io"}
+
+// gatherPressure reports the pressure stall information (PSI) of the resources
+// of a cgroup v2 directory. Missing files are skipped as they depend on the
+// enabled controllers and the kernel configuration.
+func (g *CGroup) gatherPressure(acc telegraf.Accumulator, dir pathInfo) error {
+	for _, resource := range pressureResources {
+		fn := filepath.Join(dir.path, resource+".pressure")
+		raw, err := os.ReadFile(fn)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		lines, err := parsePressure(raw)
+		if err != nil {
+			return fmt.Errorf("parsing %q failed: %w", fn, err)
+		}
+		for stallType, fields := range lines {
+			tags := dir.tags()
+			tags["resource"] = resource
+			tags["type"] = stallType
+			acc.AddFields("cgroup_pressure", fields, tags)
+		}
+	}
+
+	return nil
+}
+
+// parsePressure parses pressure stall information in the format
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func parsePressure(raw []byte) (map[string]map[string]interface{}, error) {
+	result := make(map[string]map[string]interface{}, 2)
+	for _, line := range strings.Split(string(raw), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) == 0 {
+			continue
+		}
+		fields := make(map[string]interface{}, len(parts)-1)
+		for _, part := range parts[1:] {
+			key, value, found := strings.Cut(part, "=")
+			if !found {
+				return nil, fmt.Errorf("invalid entry %q", part)
+			}
+			if key == "total" {
+				v, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("parsing %q failed: %w", key, err)
+				}
+				fields["total_usec"] = v
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %q failed: %w", key, err)
+			}
+			fields[key] = v
+		}
+		result[parts[0]] = fields
+	}
+	return result, nil
+}
@@ -13,6 +13,7 @@ import (
 	"os/user"
 	"path"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -38,6 +39,7 @@ type Chrony struct {
 
 	conn   net.Conn
 	client *fbchrony.Client
+	ntpd   *ntpdClient
 	source string
 	local  string
 }
@@ -67,6 +69,15 @@ func (c *Chrony) Init() error {
 				return fmt.Errorf("path detected in UDP address %q", c.Server)
 			}
 			u = &url.URL{Scheme: "udp", Host: u.Host}
+		case "ntp":
+			// Query ntpd via the NTP control protocol on the NTP port
+			if u.Port() == "" {
+				u.Host += ":123"
+			}
+			if u.Path != "" {
+				return fmt.Errorf("path detected in NTP address %q", c.Server)
+			}
+			u = &url.URL{Scheme: "ntp", Host: u.Host}
 		default:
 			return errors.New("unknown or missing address scheme")
 		}
@@ -79,8 +90,12 @@ func (c *Chrony) Init() error {
 	}
 	for _, m := range c.Metrics {
 		switch m {
-		case "activity", "tracking", "serverstats", "sources", "sourcestats":
-			// Do nothing as those are valid
+		case "tracking", "sources":
+			// Do nothing as those are valid for chronyd and ntpd
+		case "activity", "serverstats", "sourcestats", "ntpdata":
+			if strings.HasPrefix(c.Server, "ntp://") {
+				return fmt.Errorf("metric setting %q not supported for ntpd", m)
+			}
 		default:
 			return fmt.Errorf("invalid metric setting %q", m)
 		}
@@ -119,6 +134,14 @@ func (c *Chrony) Start(_ telegraf.Accumulator) error {
 			}
 			c.conn = conn
 			c.source = u.Host
+		case "ntp":
+			conn, err := net.DialTimeout("udp", u.Host, time.Duration(c.Timeout))
+			if err != nil {
+				return fmt.Errorf("dialing %q failed: %w", c.Server, err)
+			}
+			c.conn = conn
+			c.source = u.Host
+			c.ntpd = &ntpdClient{conn: conn, timeout: time.Duration(c.Timeout)}
 		}
 	} else {
 		// If no server is given, reproduce chronyc's behavior
@@ -146,6 +169,10 @@ func (c *Chrony) Start(_ telegraf.Accumulator) error {
 }
 
 func (c *Chrony) Gather(acc telegraf.Accumulator) error {
+	if c.ntpd != nil {
+		return c.gatherNTPD(acc)
+	}
+
 	for _, m := range c.Metrics {
 		switch m {
 		case "activity":
@@ -158,6 +185,23 @@ func (c *Chrony) Gather(acc telegraf.Accumulator) error {
 			acc.AddError(c.gatherSources(acc))
 		case "sourcestats":
 			acc.AddError(c.gatherSourceStats(acc))
+		case "ntpdata":
+			acc.AddError(c.gatherNTPData(acc))
+		default:
+			return fmt.Errorf("invalid metric setting %q", m)
+		}
+	}
+
+	return nil
+}
+
+func (c *Chrony) gatherNTPD(acc telegraf.Accumulator) error {
+	for _, m := range c.Metrics {
+		switch m {
+		case "tracking":
+			acc.AddError(c.gatherNTPDTracking(acc))
+		case "sources":
+			acc.AddError(c.gatherNTPDPeers(acc))
 		default:
 			return fmt.Errorf("invalid metric setting %q", m)
 		}
@@ -255,21 +299,8 @@ func (c *Chrony) gatherTracking(acc telegraf.Accumulator) error {
 		return fmt.Errorf("got unexpected response type %T while waiting for tracking data", r)
 	}
 
-	// according to https://github.com/mlichvar/chrony/blob/e11b518a1ffa704986fb1f1835c425844ba248ef/ntp.h#L70
-	var leapStatus string
-	switch resp.LeapStatus {
-	case 0:
-		leapStatus = "normal"
-	case 1:
-		leapStatus = "insert second"
-	case 2:
-		leapStatus = "delete second"
-	case 3:
-		leapStatus = "not synchronized"
-	}
-
 	tags := map[string]string{
-		"leap_status":  leapStatus,
+		"leap_status":  leapStatus(resp.LeapStatus),
 		"reference_id": fbchrony.RefidAsHEX(resp.RefID),
 		"stratum":      strconv.FormatUint(uint64(resp.Stratum), 10),
 	}
@@ -387,28 +418,44 @@ func (c *Chrony) getSourceName(ip net.IP) (string, error) {
 	return string(sourceName.Name[:]), nil
 }
 
-func (c *Chrony) gatherSources(acc telegraf.Accumulator) error {
+func (c *Chrony) getSourceCount() (int, error) {
 	sourcesReq := fbchrony.NewSourcesPacket()
 	sourcesRaw, err := c.client.Communicate(sourcesReq)
 	if err != nil {
-		return fmt.Errorf("querying sources failed: %w", err)
+		return 0, fmt.Errorf("querying sources failed: %w", err)
 	}
 
 	sourcesResp, ok := sourcesRaw.(*fbchrony.ReplySources)
 	if !ok {
-		return fmt.Errorf("got unexpected response type %T while waiting for sources", sourcesRaw)
+		return 0, fmt.Errorf("got unexpected response type %T while waiting for sources", sourcesRaw)
 	}
+	return sourcesResp.NSources, nil
+}
 
-	for idx := int32(0); int(idx) < sourcesResp.NSources; idx++ {
+func (c *Chrony) getSourceData(idx int32) (*fbchrony.ReplySourceData, error) {
+	sourceDataReq := fbchrony.NewSourceDataPacket(idx)
+	sourceDataRaw, err := c.client.Communicate(sourceDataReq)
+	if err != nil {
+		return nil, fmt.Errorf("querying data for source %d failed: %w", idx, err)
+	}
+	sourceData, ok := sourceDataRaw.(*fbchrony.ReplySourceData)
+	if !ok {
+		return nil, fmt.Errorf("got unexpected response type %T while waiting for source data", sourceDataRaw)
+	}
+	return sourceData, nil
+}
+
+func (c *Chrony) gatherSources(acc telegraf.Accumulator) error {
+	nsources, err := c.getSourceCount()
+	if err != nil {
+		return err
+	}
+
+	for idx := int32(0); int(idx) < nsources; idx++ {
 		// Getting the source data
-		sourceDataReq := fbchrony.NewSourceDataPacket(idx)
-		sourceDataRaw, err := c.client.Communicate(sourceDataReq)
+		sourceData, err := c.getSourceData(idx)
 		if err != nil {
-			return fmt.Errorf("querying data for source %d failed: %w", idx, err)
-		}
-		sourceData, ok := sourceDataRaw.(*fbchrony.ReplySourceData)
-		if !ok {
-			return fmt.Errorf("got unexpected response type %T while waiting for source data", sourceDataRaw)
+			return err
 		}
 
 		// Trying to resolve the source name
@@ -457,18 +504,12 @@ func (c *Chrony) gatherSources(acc telegraf.Accumulator) error {
 }
 
 func (c *Chrony) gatherSourceStats(acc telegraf.Accumulator) error {
-	sourcesReq := fbchrony.NewSourcesPacket()
-	sourcesRaw, err := c.client.Communicate(sourcesReq)
+	nsources, err := c.getSourceCount()
 	if err != nil {
-		return fmt.Errorf("querying sources failed: %w", err)
-	}
-
-	sourcesResp, ok := sourcesRaw.(*fbchrony.ReplySources)
-	if !ok {
-		return fmt.Errorf("got unexpected response type %T while waiting for sources", sourcesRaw)
+		return err
 	}
 
-	for idx := int32(0); int(idx) < sourcesResp.NSources; idx++ {
+	for idx := int32(0); int(idx) < nsources; idx++ {
 		// Getting the source data
 		sourceStatsReq := fbchrony.NewSourceStatsPacket(idx)
 		sourceStatsRaw, err := c.client.Communicate(sourceStatsReq)
@@ -524,6 +565,93 @@ func (c *Chrony) gatherSourceStats(acc telegraf.Accumulator) error {
 	return nil
 }
 
+func (c *Chrony) gatherNTPData(acc telegraf.Accumulator) error {
+	nsources, err := c.getSourceCount()
+	if err != nil {
+		return err
+	}
+
+	for idx := int32(0); int(idx) < nsources; idx++ {
+		sourceData, err := c.getSourceData(idx)
+		if err != nil {
+			return err
+		}
+
+		// Reference clocks are not queried via NTP so there is no data
+		if sourceData.Mode == fbchrony.SourceModeRef {
+			continue
+		}
+
+		ntpDataReq := fbchrony.NewNTPDataPacket(sourceData.IPAddr)
+		ntpDataRaw, err := c.client.Communicate(ntpDataReq)
+		if err != nil {
+			return fmt.Errorf("querying NTP data for source %q failed: %w", sourceData.IPAddr, err)
+		}
+		ntpData, ok := ntpDataRaw.(*fbchrony.ReplyNTPData)
+		if !ok {
+			return fmt.Errorf("got unexpected response type %T while waiting for NTP data", ntpDataRaw)
+		}
+
+		peer, err := c.getSourceName(sourceData.IPAddr)
+		if err != nil {
+			return err
+		}
+		if peer == "" {
+			peer = sourceData.IPAddr.String()
+		}
+
+		tags := map[string]string{
+			"peer":         peer,
+			"leap_status":  leapStatus(uint16(ntpData.Leap)),
+			"reference_id": fbchrony.RefidAsHEX(ntpData.RefID),
+		}
+		if c.source != "" {
+			tags["source"] = c.source
+		}
+
+		fields := map[string]interface{}{
+			"index":             idx,
+			"ip":                ntpData.RemoteAddr.String(),
+			"port":              ntpData.RemotePort,
+			"local_ip":          ntpData.LocalAddr.String(),
+			"version":           ntpData.NTPData.Version,
+			"mode":              ntpData.Mode,
+			"stratum":           ntpData.Stratum,
+			"poll":              ntpData.Poll,
+			"precision":         ntpData.Precision,
+			"root_delay":        ntpData.RootDelay,
+			"root_dispersion":   ntpData.RootDispersion,
+			"offset":            ntpData.Offset,
+			"peer_delay":        ntpData.PeerDelay,
+			"peer_dispersion":   ntpData.PeerDispersion,
+			"response_time":     ntpData.ResponseTime,
+			"jitter_asymmetry":  ntpData.JitterAsymmetry,
+			"flags":             ntpData.Flags,
+			"total_tx_count":    ntpData.TotalTXCount,
+			"total_rx_count":    ntpData.TotalRXCount,
+			"total_valid_count": ntpData.TotalValidCount,
+		}
+		acc.AddFields("chrony_ntpdata", fields, tags)
+	}
+	return nil
+}
+
+// leapStatus returns the name of the leap indicator, according to
+// https://github.com/mlichvar/chrony/blob/e11b518a1ffa704986fb1f1835c425844ba248ef/ntp.h#L70
+func leapStatus(leap uint16) string {
+	switch leap {
+	case 0:
+		return "normal"
+	case 1:
+		return "insert second"
+	case 2:
+		return "delete second"
+	case 3:
+		return "not synchronized"
+	}
+	return ""
+}
+
 func init() {
 	inputs.Add("chrony", func() telegraf.Input {
 		return &Chrony{Timeout: config.Duration(3 * time.Second)}
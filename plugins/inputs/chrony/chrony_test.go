@@ -647,6 +647,178 @@ func TestGatherSourceStats(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual, options...)
 }
 
+func TestGatherNTPData(t *testing.T) {
+	// Setup a mock server
+	server := Server{
+		SourcesInfo: []source{
+			{
+				name: "ntp1.my.org",
+				data: &fbchrony.SourceData{
+					IPAddr: net.IPv4(192, 168, 0, 1),
+					Mode:   fbchrony.SourceModeClient,
+				},
+				ntpdata: &fbchrony.NTPData{
+					RemoteAddr:      net.IPv4(192, 168, 0, 1),
+					LocalAddr:       net.IPv4(192, 168, 0, 100),
+					RemotePort:      123,
+					Leap:            0,
+					Version:         4,
+					Mode:            4,
+					Stratum:         2,
+					Poll:            6,
+					Precision:       -23,
+					RootDelay:       0.01221,
+					RootDispersion:  0.00034,
+					RefID:           0xC0A80A01,
+					Offset:          0.000125,
+					PeerDelay:       0.00542,
+					PeerDispersion:  0.00002,
+					ResponseTime:    0.00001,
+					JitterAsymmetry: 0.05,
+					Flags:           0x3ff,
+					TotalTXCount:    140,
+					TotalRXCount:    138,
+					TotalValidCount: 137,
+				},
+			},
+			{
+				name: "PPS",
+				data: &fbchrony.SourceData{
+					IPAddr: net.IPv4(0x50, 0x50, 0x53, 0x00),
+					Mode:   fbchrony.SourceModeRef,
+				},
+			},
+			{
+				name: "ntp2.my.org",
+				data: &fbchrony.SourceData{
+					IPAddr: net.IPv4(192, 168, 0, 2),
+					Mode:   fbchrony.SourceModeClient,
+				},
+				ntpdata: &fbchrony.NTPData{
+					RemoteAddr:      net.IPv4(192, 168, 0, 2),
+					LocalAddr:       net.IPv4(192, 168, 0, 100),
+					RemotePort:      123,
+					Leap:            1,
+					Version:         4,
+					Mode:            4,
+					Stratum:         1,
+					Poll:            10,
+					Precision:       -20,
+					RootDelay:       0.0,
+					RootDispersion:  0.00012,
+					RefID:           0x47505300,
+					Offset:          -0.00302,
+					PeerDelay:       0.0315,
+					PeerDispersion:  0.00011,
+					ResponseTime:    0.00003,
+					JitterAsymmetry: -0.12,
+					Flags:           0x3ff,
+					TotalTXCount:    12,
+					TotalRXCount:    10,
+					TotalValidCount: 10,
+				},
+			},
+		},
+	}
+	addr, err := server.Listen(t)
+	require.NoError(t, err)
+	defer server.Shutdown()
+
+	// Setup the plugin
+	plugin := &Chrony{
+		Server:  "udp://" + addr,
+		Metrics: []string{"ntpdata"},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Start the plugin, do a gather and stop everything
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	plugin.Stop()
+	server.Shutdown()
+
+	// Do the comparison
+	expected := []telegraf.Metric{
+		metric.New(
+			"chrony_ntpdata",
+			map[string]string{
+				"source":       addr,
+				"peer":         "ntp1.my.org",
+				"leap_status":  "normal",
+				"reference_id": "C0A80A01",
+			},
+			map[string]interface{}{
+				"index":             0,
+				"ip":                "192.168.0.1",
+				"port":              uint64(123),
+				"local_ip":          "192.168.0.100",
+				"version":           uint64(4),
+				"mode":              uint64(4),
+				"stratum":           uint64(2),
+				"poll":              6,
+				"precision":         -23,
+				"root_delay":        0.01221,
+				"root_dispersion":   0.00034,
+				"offset":            0.000125,
+				"peer_delay":        0.00542,
+				"peer_dispersion":   0.00002,
+				"response_time":     0.00001,
+				"jitter_asymmetry":  0.05,
+				"flags":             uint64(0x3ff),
+				"total_tx_count":    uint64(140),
+				"total_rx_count":    uint64(138),
+				"total_valid_count": uint64(137),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"chrony_ntpdata",
+			map[string]string{
+				"source":       addr,
+				"peer":         "ntp2.my.org",
+				"leap_status":  "insert second",
+				"reference_id": "47505300",
+			},
+			map[string]interface{}{
+				"index":             2,
+				"ip":                "192.168.0.2",
+				"port":              uint64(123),
+				"local_ip":          "192.168.0.100",
+				"version":           uint64(4),
+				"mode":              uint64(4),
+				"stratum":           uint64(1),
+				"poll":              10,
+				"precision":         -20,
+				"root_delay":        0.0,
+				"root_dispersion":   0.00012,
+				"offset":            -0.00302,
+				"peer_delay":        0.0315,
+				"peer_dispersion":   0.00011,
+				"response_time":     0.00003,
+				"jitter_asymmetry":  -0.12,
+				"flags":             uint64(0x3ff),
+				"total_tx_count":    uint64(12),
+				"total_rx_count":    uint64(10),
+				"total_valid_count": uint64(10),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	options := []cmp.Option{
+		// tests on linux with go1.20 will add a warning about code coverage, ignore that tag
+		testutil.IgnoreTags("warning"),
+		testutil.IgnoreTime(),
+		cmpopts.EquateApprox(0.001, 0),
+	}
+
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual, options...)
+}
+
 func TestIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -715,9 +887,10 @@ func TestIntegration(t *testing.T) {
 }
 
 type source struct {
-	name  string
-	data  *fbchrony.SourceData
-	stats *fbchrony.SourceStats
+	name    string
+	data    *fbchrony.SourceData
+	stats   *fbchrony.SourceStats
+	ntpdata *fbchrony.NTPData
 }
 
 type Server struct {
@@ -833,6 +1006,21 @@ func (s *Server) serve(t *testing.T) {
 			} else {
 				t.Log("mock server [serverstats]: successfully wrote reply")
 			}
+		case 57: // ntp data
+			buf := make([]byte, 20)
+			_, err := data.Read(buf)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ip := decodeIP(buf)
+			t.Logf("mock server [ntp data]: querying %v", ip)
+			_, err = s.conn.WriteTo(s.encodeNTPDataReply(seqno, ip), addr)
+			if err != nil {
+				t.Errorf("mock server [ntp data]: writing reply failed: %v", err)
+			} else {
+				t.Log("mock server [ntp data]: successfully wrote reply")
+			}
 		case 65: // source name
 			buf := make([]byte, 20)
 			_, err := data.Read(buf)
@@ -989,6 +1177,46 @@ func (s *Server) encodeSourceStatsReply(sequence uint32, idx int32) []byte {
 	return buf
 }
 
+func (s *Server) encodeNTPDataReply(sequence uint32, ip net.IP) []byte {
+	// Find the correct source
+	var d *fbchrony.NTPData
+	for _, src := range s.SourcesInfo {
+		if src.ntpdata != nil && src.ntpdata.RemoteAddr.Equal(ip) {
+			d = src.ntpdata
+			break
+		}
+	}
+	if d == nil {
+		return encodeHeader(57, 16, 3, sequence) // status invalid
+	}
+
+	// Encode the header
+	buf := encodeHeader(57, 16, 0, sequence) // ntp data request
+
+	// Encode data
+	buf = append(buf, encodeIP(d.RemoteAddr)...)
+	buf = append(buf, encodeIP(d.LocalAddr)...)
+	buf = binary.BigEndian.AppendUint16(buf, d.RemotePort)
+	buf = append(buf, d.Leap, d.Version, d.Mode, d.Stratum, uint8(d.Poll), uint8(d.Precision))
+	buf = binary.BigEndian.AppendUint32(buf, encodeFloat(d.RootDelay))
+	buf = binary.BigEndian.AppendUint32(buf, encodeFloat(d.RootDispersion))
+	buf = binary.BigEndian.AppendUint32(buf, d.RefID)
+	buf = append(buf, make([]byte, 12)...) // reference time
+	buf = binary.BigEndian.AppendUint32(buf, encodeFloat(d.Offset))
+	buf = binary.BigEndian.AppendUint32(buf, encodeFloat(d.PeerDelay))
+	buf = binary.BigEndian.AppendUint32(buf, encodeFloat(d.PeerDispersion))
+	buf = binary.BigEndian.AppendUint32(buf, encodeFloat(d.ResponseTime))
+	buf = binary.BigEndian.AppendUint32(buf, encodeFloat(d.JitterAsymmetry))
+	buf = binary.BigEndian.AppendUint16(buf, d.Flags)
+	buf = append(buf, d.TXTssChar, d.RXTssChar)
+	buf = binary.BigEndian.AppendUint32(buf, d.TotalTXCount)
+	buf = binary.BigEndian.AppendUint32(buf, d.TotalRXCount)
+	buf = binary.BigEndian.AppendUint32(buf, d.TotalValidCount)
+	buf = append(buf, make([]byte, 16)...) // reserved
+
+	return buf
+}
+
 func (s *Server) encodeSourceNameReply(sequence uint32, ip net.IP) []byte {
 	// Encode the header
 	buf := encodeHeader(65, 19, 0, sequence) // source name request
@@ -0,0 +1,337 @@
+package chrony
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	fbchrony "github.com/facebook/time/ntp/chrony"
+
+	"github.com/influxdata/telegraf"
+)
+
+// NTP control protocol (mode 6) as used by ntpq, see RFC 1305 Appendix B
+const (
+	ntpControlHeader   = 0x16 // leap 0, version 2, mode 6
+	ntpOpReadStatus    = 1
+	ntpOpReadVariables = 2
+	ntpFlagResponse    = 0x80
+	ntpFlagError       = 0x40
+	ntpFlagMore        = 0x20
+	ntpOpMask          = 0x1f
+	ntpHeaderLen       = 12
+
+	// Seconds between the NTP epoch (1900) and the unix epoch (1970)
+	ntpEpochOffset = 2208988800
+)
+
+// Tally codes of the peer selection status as shown by ntpq
+var ntpTallyCodes = []string{"", "x", ".", "-", "+", "#", "*", "o"}
+
+type ntpdAssociation struct {
+	id     uint16
+	status uint16
+}
+
+// ntpdClient queries ntpd using the NTP control protocol
+type ntpdClient struct {
+	conn     net.Conn
+	timeout  time.Duration
+	sequence uint16
+}
+
+// query sends a control request and returns the reassembled data of the
+// (possibly fragmented) response
+func (n *ntpdClient) query(opcode uint8, association uint16) ([]byte, error) {
+	n.sequence++
+
+	req := make([]byte, ntpHeaderLen)
+	req[0] = ntpControlHeader
+	req[1] = opcode
+	binary.BigEndian.PutUint16(req[2:4], n.sequence)
+	binary.BigEndian.PutUint16(req[6:8], association)
+
+	if err := n.conn.SetDeadline(time.Now().Add(n.timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := n.conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	fragments := make(map[uint16][]byte)
+	var received int
+	end := -1
+	buf := make([]byte, 4096)
+	for end < 0 || received < end {
+		size, err := n.conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if size < ntpHeaderLen {
+			return nil, fmt.Errorf("response too short (%d bytes)", size)
+		}
+
+		flags := buf[1]
+		if flags&ntpFlagResponse == 0 || flags&ntpOpMask != opcode || binary.BigEndian.Uint16(buf[2:4]) != n.sequence {
+			// Skip late replies to previous requests
+			continue
+		}
+		if flags&ntpFlagError != 0 {
+			return nil, fmt.Errorf("error response with code %d", binary.BigEndian.Uint16(buf[4:6])>>8)
+		}
+
+		offset := binary.BigEndian.Uint16(buf[8:10])
+		count := int(binary.BigEndian.Uint16(buf[10:12]))
+		if ntpHeaderLen+count > size {
+			return nil, fmt.Errorf("fragment at offset %d exceeds packet size", offset)
+		}
+		if _, found := fragments[offset]; found {
+			continue
+		}
+		fragments[offset] = append([]byte(nil), buf[ntpHeaderLen:ntpHeaderLen+count]...)
+		received += count
+		if flags&ntpFlagMore == 0 {
+			end = int(offset) + count
+		}
+	}
+
+	data := make([]byte, end)
+	for offset, fragment := range fragments {
+		if int(offset)+len(fragment) > end {
+			return nil, fmt.Errorf("fragment at offset %d exceeds response size", offset)
+		}
+		copy(data[offset:], fragment)
+	}
+	return data, nil
+}
+
+func (n *ntpdClient) associations() ([]ntpdAssociation, error) {
+	data, err := n.query(ntpOpReadStatus, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	associations := make([]ntpdAssociation, 0, len(data)/4)
+	for i := 0; i+4 <= len(data); i += 4 {
+		associations = append(associations, ntpdAssociation{
+			id:     binary.BigEndian.Uint16(data[i : i+2]),
+			status: binary.BigEndian.Uint16(data[i+2 : i+4]),
+		})
+	}
+	return associations, nil
+}
+
+// variables reads the system variables for association zero or the peer
+// variables of the given association
+func (n *ntpdClient) variables(association uint16) (map[string]string, error) {
+	data, err := n.query(ntpOpReadVariables, association)
+	if err != nil {
+		return nil, err
+	}
+	return parseNTPVariables(string(data)), nil
+}
+
+// parseNTPVariables splits a list of comma-separated "name=value" pairs where
+// values might be quoted strings containing commas
+func parseNTPVariables(data string) map[string]string {
+	vars := make(map[string]string)
+
+	var quoted bool
+	start := 0
+	for i := 0; i <= len(data); i++ {
+		if i < len(data) {
+			if data[i] == '"' {
+				quoted = !quoted
+			}
+			if quoted || data[i] != ',' {
+				continue
+			}
+		}
+
+		item := strings.TrimSpace(data[start:i])
+		start = i + 1
+		if item == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(item, "=")
+		vars[strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return vars
+}
+
+func (c *Chrony) gatherNTPDTracking(acc telegraf.Accumulator) error {
+	vars, err := c.ntpd.variables(0)
+	if err != nil {
+		return fmt.Errorf("querying system variables failed: %w", err)
+	}
+
+	tags := map[string]string{
+		"reference_id": fbchrony.RefidAsHEX(ntpRefID(vars["refid"])),
+		"stratum":      vars["stratum"],
+	}
+	if leap, err := parseNTPLeap(vars["leap"]); err == nil {
+		tags["leap_status"] = leapStatus(leap)
+	}
+	if c.source != "" {
+		tags["source"] = c.source
+	}
+
+	// Times are reported in milliseconds by ntpd
+	fields := make(map[string]interface{}, 5)
+	for name, v := range map[string]struct {
+		variable string
+		factor   float64
+	}{
+		"frequency":       {"frequency", 1},
+		"system_time":     {"offset", 1e-3},
+		"root_delay":      {"rootdelay", 1e-3},
+		"root_dispersion": {"rootdisp", 1e-3},
+		"skew":            {"clk_wander", 1},
+	} {
+		raw, found := vars[v.variable]
+		if !found {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("parsing %q (%v) as float failed: %w", v.variable, raw, err)
+		}
+		fields[name] = value * v.factor
+	}
+	acc.AddFields("chrony", fields, tags)
+
+	return nil
+}
+
+// gatherNTPDPeers reports the peers of ntpd in the same format as the ntpq
+// plugin does for the output of "ntpq -p -n"
+func (c *Chrony) gatherNTPDPeers(acc telegraf.Accumulator) error {
+	associations, err := c.ntpd.associations()
+	if err != nil {
+		return fmt.Errorf("querying associations failed: %w", err)
+	}
+
+	now := time.Now()
+	for _, assoc := range associations {
+		vars, err := c.ntpd.variables(assoc.id)
+		if err != nil {
+			return fmt.Errorf("querying variables of association %d failed: %w", assoc.id, err)
+		}
+
+		remote, _, err := net.SplitHostPort(vars["srcadr"])
+		if err != nil {
+			remote = vars["srcadr"]
+		}
+
+		tags := map[string]string{
+			"remote":  remote,
+			"refid":   vars["refid"],
+			"stratum": vars["stratum"],
+			"type":    ntpPeerType(remote, vars["hmode"]),
+		}
+		if net.ParseIP(tags["refid"]) == nil {
+			tags["refid"] = "." + tags["refid"] + "."
+		}
+		if prefix := ntpTallyCodes[(assoc.status>>8)&0x07]; prefix != "" {
+			tags["state_prefix"] = prefix
+		}
+		if c.source != "" {
+			tags["source"] = c.source
+		}
+
+		fields := make(map[string]interface{}, 6)
+		for _, name := range []string{"delay", "offset", "jitter"} {
+			if raw, found := vars[name]; found {
+				value, err := strconv.ParseFloat(raw, 64)
+				if err != nil {
+					acc.AddError(fmt.Errorf("parsing %q (%v) as float failed: %w", name, raw, err))
+					continue
+				}
+				fields[name] = value
+			}
+		}
+
+		ppoll, errP := strconv.Atoi(vars["ppoll"])
+		hpoll, errH := strconv.Atoi(vars["hpoll"])
+		if errP == nil && errH == nil {
+			fields["poll"] = int64(1) << min(ppoll, hpoll)
+		}
+
+		// Output the reach register in its octal representation like ntpq
+		if reach, err := strconv.ParseUint(vars["reach"], 0, 8); err == nil {
+			if v, err := strconv.ParseInt(strconv.FormatUint(reach, 8), 10, 64); err == nil {
+				fields["reach"] = v
+			}
+		}
+
+		if rec, ok := parseNTPTimestamp(vars["rec"]); ok {
+			fields["when"] = int64(now.Sub(rec).Seconds())
+		}
+
+		acc.AddFields("ntpq", fields, tags)
+	}
+
+	return nil
+}
+
+// parseNTPLeap parses the leap indicator which is either reported as decimal
+// number or as two binary digits
+func parseNTPLeap(value string) (uint16, error) {
+	base := 10
+	if len(value) == 2 {
+		base = 2
+	}
+	leap, err := strconv.ParseUint(value, base, 2)
+	return uint16(leap), err
+}
+
+// parseNTPTimestamp parses a timestamp in the hexadecimal NTP format,
+// e.g. "0xe1d2a5b3.5d3e0f12"
+func parseNTPTimestamp(value string) (time.Time, bool) {
+	secRaw, fracRaw, _ := strings.Cut(strings.TrimPrefix(value, "0x"), ".")
+	sec, err := strconv.ParseUint(secRaw, 16, 32)
+	if err != nil || sec == 0 {
+		return time.Time{}, false
+	}
+	frac, err := strconv.ParseUint(fracRaw, 16, 32)
+	if err != nil {
+		frac = 0
+	}
+	nsec := int64(float64(frac) / math.Exp2(32) * 1e9)
+	return time.Unix(int64(sec)-ntpEpochOffset, nsec), true
+}
+
+// ntpRefID converts the reference ID into its numeric representation, i.e.
+// the IPv4 address of the reference or the ASCII code of a reference clock
+func ntpRefID(value string) uint32 {
+	if ip := net.ParseIP(value).To4(); ip != nil {
+		return binary.BigEndian.Uint32(ip)
+	}
+	var buf [4]byte
+	copy(buf[:], value)
+	return binary.BigEndian.Uint32(buf[:])
+}
+
+// ntpPeerType determines the association type as shown in the "t" column of
+// ntpq from the host mode
+func ntpPeerType(remote, hmode string) string {
+	// Reference clocks use pseudo-addresses in the 127.127.0.0/16 range
+	if strings.HasPrefix(remote, "127.127.") {
+		return "l"
+	}
+	switch hmode {
+	case "1", "2":
+		return "s"
+	case "3":
+		return "u"
+	case "5":
+		return "B"
+	case "6":
+		return "b"
+	}
+	return "-"
+}
@@ -0,0 +1,258 @@
+package chrony
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitNTPD(t *testing.T) {
+	plugin := &Chrony{
+		Server:  "ntp://127.0.0.1",
+		Metrics: []string{"tracking", "sources"},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "ntp://127.0.0.1:123", plugin.Server)
+
+	plugin = &Chrony{
+		Server:  "ntp://127.0.0.1",
+		Metrics: []string{"sourcestats"},
+		Log:     testutil.Logger{},
+	}
+	require.EqualError(t, plugin.Init(), `metric setting "sourcestats" not supported for ntpd`)
+
+	plugin = &Chrony{
+		Server: "ntp://127.0.0.1/foo",
+		Log:    testutil.Logger{},
+	}
+	require.EqualError(t, plugin.Init(), `path detected in NTP address "ntp://127.0.0.1/foo"`)
+}
+
+func TestParseNTPVariables(t *testing.T) {
+	data := "version=\"ntpd 4.2.8p15@1.3728-o, Wed Sep 23 11:46:38 UTC 2020 (1)\",\r\n" +
+		"processor=\"x86_64\", leap=00, stratum=2, refid=192.0.2.1,\r\n  offset=-0.071"
+	expected := map[string]string{
+		"version":   "ntpd 4.2.8p15@1.3728-o, Wed Sep 23 11:46:38 UTC 2020 (1)",
+		"processor": "x86_64",
+		"leap":      "00",
+		"stratum":   "2",
+		"refid":     "192.0.2.1",
+		"offset":    "-0.071",
+	}
+	require.Equal(t, expected, parseNTPVariables(data))
+}
+
+func TestGatherNTPD(t *testing.T) {
+	rec := time.Now().Add(-10500 * time.Millisecond)
+	server := ntpdServer{
+		system: "version=\"ntpd 4.2.8p15\", leap=01, stratum=2, precision=-23, rootdelay=12.500, rootdisp=30.120, " +
+			"refid=192.0.2.1, offset=-0.215, frequency=-16.001, sys_jitter=0.081, clk_jitter=0.044, clk_wander=0.006",
+		associations: []ntpdAssociation{
+			{id: 1, status: 0x961a},
+			{id: 2, status: 0x941a},
+			{id: 3, status: 0x8011},
+		},
+		peers: map[uint16]string{
+			1: "srcadr=192.0.2.1, srcport=123, dstadr=192.0.2.100, leap=00, stratum=1, refid=GPS, " +
+				"hmode=3, ppoll=6, hpoll=6, reach=0xff, delay=1.234, offset=-0.215, jitter=0.081, rec=" + ntpTimestamp(rec),
+			2: "srcadr=192.0.2.2, srcport=123, stratum=2, refid=192.0.2.10, hmode=3, ppoll=10, hpoll=8, reach=0x1f, " +
+				"delay=25.002, offset=3.125, jitter=1.750, rec=" + ntpTimestamp(rec),
+			3: "srcadr=127.127.22.0, srcport=123, stratum=16, refid=INIT, hmode=3, ppoll=4, hpoll=4, reach=0x00, " +
+				"delay=0.000, offset=0.000, jitter=0.000, rec=0x00000000.00000000",
+		},
+	}
+	addr := server.listen(t)
+	defer server.conn.Close()
+
+	plugin := &Chrony{
+		Server:  "ntp://" + addr,
+		Metrics: []string{"tracking", "sources"},
+		Timeout: config.Duration(3 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"chrony",
+			map[string]string{
+				"source":       addr,
+				"leap_status":  "insert second",
+				"reference_id": "C0000201",
+				"stratum":      "2",
+			},
+			map[string]interface{}{
+				"frequency":       -16.001,
+				"system_time":     -0.000215,
+				"root_delay":      0.0125,
+				"root_dispersion": 0.03012,
+				"skew":            0.006,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ntpq",
+			map[string]string{
+				"source":       addr,
+				"remote":       "192.0.2.1",
+				"state_prefix": "*",
+				"refid":        ".GPS.",
+				"stratum":      "1",
+				"type":         "u",
+			},
+			map[string]interface{}{
+				"delay":  1.234,
+				"offset": -0.215,
+				"jitter": 0.081,
+				"poll":   int64(64),
+				"reach":  int64(377),
+				"when":   int64(10),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ntpq",
+			map[string]string{
+				"source":       addr,
+				"remote":       "192.0.2.2",
+				"state_prefix": "+",
+				"refid":        "192.0.2.10",
+				"stratum":      "2",
+				"type":         "u",
+			},
+			map[string]interface{}{
+				"delay":  25.002,
+				"offset": 3.125,
+				"jitter": 1.750,
+				"poll":   int64(256),
+				"reach":  int64(37),
+				"when":   int64(10),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ntpq",
+			map[string]string{
+				"source":  addr,
+				"remote":  "127.127.22.0",
+				"refid":   ".INIT.",
+				"stratum": "16",
+				"type":    "l",
+			},
+			map[string]interface{}{
+				"delay":  0.0,
+				"offset": 0.0,
+				"jitter": 0.0,
+				"poll":   int64(16),
+				"reach":  int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+// ntpdServer mocks the NTP control protocol of ntpd
+type ntpdServer struct {
+	system       string
+	associations []ntpdAssociation
+	peers        map[uint16]string
+
+	conn net.PacketConn
+}
+
+func (s *ntpdServer) listen(t *testing.T) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s.conn = conn
+
+	go s.serve(t)
+
+	return conn.LocalAddr().String()
+}
+
+func (s *ntpdServer) serve(t *testing.T) {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if n < ntpHeaderLen || buf[0] != ntpControlHeader {
+			t.Errorf("mock server: invalid request %x", buf[:n])
+			return
+		}
+		opcode := buf[1]
+		sequence := binary.BigEndian.Uint16(buf[2:4])
+		association := binary.BigEndian.Uint16(buf[6:8])
+
+		var data []byte
+		switch opcode {
+		case ntpOpReadStatus:
+			for _, a := range s.associations {
+				data = binary.BigEndian.AppendUint16(data, a.id)
+				data = binary.BigEndian.AppendUint16(data, a.status)
+			}
+		case ntpOpReadVariables:
+			if association == 0 {
+				data = []byte(s.system)
+			} else {
+				data = []byte(s.peers[association])
+			}
+		default:
+			t.Errorf("mock server: unexpected opcode %d", opcode)
+			return
+		}
+
+		// Send the data in fragments, the last one first, to test reassembly
+		split := len(data) / 2
+		fragments := []struct {
+			offset int
+			data   []byte
+			more   bool
+		}{
+			{offset: split, data: data[split:]},
+			{offset: 0, data: data[:split], more: true},
+		}
+		for _, f := range fragments {
+			flags := ntpFlagResponse | opcode
+			if f.more {
+				flags |= ntpFlagMore
+			}
+			pkt := []byte{ntpControlHeader, flags}
+			pkt = binary.BigEndian.AppendUint16(pkt, sequence)
+			pkt = binary.BigEndian.AppendUint16(pkt, 0) // status
+			pkt = binary.BigEndian.AppendUint16(pkt, association)
+			pkt = binary.BigEndian.AppendUint16(pkt, uint16(f.offset))
+			pkt = binary.BigEndian.AppendUint16(pkt, uint16(len(f.data)))
+			pkt = append(pkt, f.data...)
+			if _, err := s.conn.WriteTo(pkt, addr); err != nil {
+				t.Errorf("mock server: writing reply failed: %v", err)
+				return
+			}
+		}
+	}
+}
+
+func ntpTimestamp(t time.Time) string {
+	sec := uint64(t.Unix()) + ntpEpochOffset
+	frac := uint64(float64(t.Nanosecond()) / 1e9 * math.Exp2(32))
+	return fmt.Sprintf("0x%08x.%08x", sec, frac)
+}
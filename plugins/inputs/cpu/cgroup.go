@@ -0,0 +1,139 @@
+package cpu
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/cgroup"
+)
+
+// cgroupCPU holds the CPU time consumed, the throttling statistics and the
+// bandwidth limit of a cgroup with times in seconds and the limit in CPUs
+type cgroupCPU struct {
+	user             float64
+	system           float64
+	periods          int64
+	throttledPeriods int64
+	throttled        float64
+	limit            float64
+}
+
+func newCgroupCPU(stat *cgroup.CPUStat) cgroupCPU {
+	var limit float64
+	if stat.Quota >= 0 && stat.Period > 0 {
+		limit = float64(stat.Quota) / float64(stat.Period)
+	}
+	return cgroupCPU{
+		user:             float64(stat.User) / 1e6,
+		system:           float64(stat.System) / 1e6,
+		periods:          stat.Periods,
+		throttledPeriods: stat.ThrottledPeriods,
+		throttled:        float64(stat.Throttled) / 1e6,
+		limit:            limit,
+	}
+}
+
+// hostDelta holds the change of the host CPU times between two collections
+type hostDelta struct {
+	total float64
+	busy  float64
+	steal float64
+}
+
+// computeHostDelta determines the change of the host CPU times using the
+// total over all CPUs if available or the sum of the individual CPUs otherwise
+func computeHostDelta(times []cpu.TimesStat, last map[string]cpu.TimesStat) (hostDelta, bool) {
+	var delta hostDelta
+	add := func(cts, lastCts cpu.TimesStat) {
+		delta.total += totalCPUTime(cts) - totalCPUTime(lastCts)
+		delta.busy += cts.User + cts.Nice + cts.System + cts.Irq + cts.Softirq -
+			(lastCts.User + lastCts.Nice + lastCts.System + lastCts.Irq + lastCts.Softirq)
+		delta.steal += cts.Steal - lastCts.Steal
+	}
+
+	for _, cts := range times {
+		if cts.CPU != "cpu-total" {
+			continue
+		}
+		if lastCts, ok := last[cts.CPU]; ok {
+			add(cts, lastCts)
+			return delta, delta.total > 0
+		}
+	}
+	for _, cts := range times {
+		if !strings.HasPrefix(cts.CPU, "cpu") || cts.CPU == "cpu-total" {
+			continue
+		}
+		if lastCts, ok := last[cts.CPU]; ok {
+			add(cts, lastCts)
+		}
+	}
+	return delta, delta.total > 0
+}
+
+// gatherCgroups reports the CPU usage of the selected cgroups relative to the
+// host CPU time. The steal time is only known for the whole host and is
+// attributed to the cgroups in proportion to their share of the busy time.
+func (c *CPUStats) gatherCgroups(acc telegraf.Accumulator, times []cpu.TimesStat, now time.Time) error {
+	cgroups, err := readCgroupsCPU(c.CgroupRoot, c.cgroupDepth)
+	if err != nil {
+		return err
+	}
+
+	host, hostValid := computeHostDelta(times, c.lastStats)
+	for name, cg := range cgroups {
+		if c.cgroupFilter != nil && !c.cgroupFilter.Match(name) {
+			continue
+		}
+		tags := map[string]string{"cgroup": name}
+
+		if c.CollectCPUTime {
+			fieldsC := map[string]interface{}{
+				"time_user":      cg.user,
+				"time_system":    cg.system,
+				"time_throttled": cg.throttled,
+			}
+			if c.ReportActive {
+				fieldsC["time_active"] = cg.user + cg.system
+			}
+			acc.AddCounter("cpu_cgroup", fieldsC, tags, now)
+		}
+
+		last, ok := c.lastCgroups[name]
+		if !ok || !hostValid {
+			continue
+		}
+		user := cg.user - last.user
+		system := cg.system - last.system
+		if user < 0 || system < 0 {
+			// The cgroup was recreated since the last collection
+			continue
+		}
+
+		fieldsG := map[string]interface{}{
+			"usage_user":   100 * user / host.total,
+			"usage_system": 100 * system / host.total,
+			"usage_steal":  0.0,
+		}
+		if host.busy > 0 {
+			share := min((user+system)/host.busy, 1)
+			fieldsG["usage_steal"] = 100 * share * host.steal / host.total
+		}
+		if c.ReportActive {
+			fieldsG["usage_active"] = 100 * (user + system) / host.total
+		}
+		if cg.limit > 0 {
+			fieldsG["limit_cpus"] = cg.limit
+		}
+		if periods := cg.periods - last.periods; periods > 0 {
+			fieldsG["throttled_percent"] = 100 * float64(cg.throttledPeriods-last.throttledPeriods) / float64(periods)
+		}
+		acc.AddGauge("cpu_cgroup", fieldsG, tags, now)
+	}
+	c.lastCgroups = cgroups
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package cpu
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/influxdata/telegraf/plugins/common/cgroup"
+)
+
+const cgroupsSupported = true
+
+// readCgroupsCPU reads the "cpu.stat" and "cpu.max" files of the cgroups below
+// the root of the unified (v2) hierarchy up to the given depth, e.g. the systemd
+// slices at depth one and their services at depth two. The cgroups are keyed by
+// their path relative to the root. Cgroups without CPU accounting are skipped.
+func readCgroupsCPU(root string, depth int) (map[string]cgroupCPU, error) {
+	cgroups := make(map[string]cgroupCPU)
+	if err := walkCgroupsCPU(root, "", depth, cgroups); err != nil {
+		return nil, err
+	}
+	return cgroups, nil
+}
+
+func walkCgroupsCPU(root, prefix string, depth int, cgroups map[string]cgroupCPU) error {
+	entries, err := os.ReadDir(filepath.Join(root, prefix))
+	if err != nil {
+		// The parent cgroup might have been removed in the meantime
+		if prefix != "" && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := path.Join(prefix, entry.Name())
+		stat, err := cgroup.ReadCPUStat(filepath.Join(root, name))
+		if err != nil {
+			// The cgroup might have been removed in the meantime
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		if stat.HasUsage {
+			cgroups[name] = newCgroupCPU(stat)
+		}
+		if depth > 1 {
+			if err := walkCgroupsCPU(root, name, depth-1, cgroups); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
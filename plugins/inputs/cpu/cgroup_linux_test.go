@@ -0,0 +1,220 @@
+package cpu
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs/system"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func writeCPUStat(t *testing.T, root, cgroup, content string) {
+	dir := filepath.Join(root, cgroup)
+	require.NoError(t, os.MkdirAll(dir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte(content), 0600))
+}
+
+func writeCPUMax(t *testing.T, root, cgroup, content string) {
+	require.NoError(t, os.WriteFile(filepath.Join(root, cgroup, "cpu.max"), []byte(content), 0600))
+}
+
+func TestCgroups(t *testing.T) {
+	root := t.TempDir()
+	writeCPUStat(t, root, "system.slice", "usage_usec 3000000\nuser_usec 2000000\nsystem_usec 1000000\n"+
+		"nr_periods 100\nnr_throttled 10\nthrottled_usec 500000\n")
+	writeCPUStat(t, root, "user.slice", "usage_usec 1000000\nuser_usec 1000000\nsystem_usec 0\n")
+	writeCPUStat(t, root, "init.scope", "usage_usec 1000000\nuser_usec 1000000\nsystem_usec 0\n")
+	// Cgroups without CPU accounting must be skipped
+	writeCPUStat(t, root, "legacy.slice", "nr_periods 0\n")
+
+	var mps system.MockPS
+	mps.On("CPUTimes").Return([]cpu.TimesStat{
+		{CPU: "cpu-total", User: 10, System: 5, Idle: 85},
+	}, nil)
+
+	plugin := &CPUStats{
+		ps:            &mps,
+		TotalCPU:      true,
+		ReportActive:  true,
+		ReportCgroups: true,
+		CgroupRoot:    root,
+		Cgroups:       []string{"*.slice"},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Advance the host by 100 seconds of which 40 seconds were busy and 20
+	// seconds stolen
+	writeCPUStat(t, root, "system.slice", "usage_usec 18000000\nuser_usec 12000000\nsystem_usec 6000000\n"+
+		"nr_periods 200\nnr_throttled 35\nthrottled_usec 2500000\n")
+	writeCPUStat(t, root, "user.slice", "usage_usec 26000000\nuser_usec 21000000\nsystem_usec 5000000\n")
+	var mps2 system.MockPS
+	mps2.On("CPUTimes").Return([]cpu.TimesStat{
+		{CPU: "cpu-total", User: 40, System: 15, Idle: 125, Steal: 20},
+	}, nil)
+	plugin.ps = &mps2
+
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu_cgroup",
+			map[string]string{"cgroup": "system.slice"},
+			map[string]interface{}{
+				"usage_user":        10.0,
+				"usage_system":      5.0,
+				"usage_active":      15.0,
+				"usage_steal":       7.5,
+				"throttled_percent": 25.0,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"cpu_cgroup",
+			map[string]string{"cgroup": "user.slice"},
+			map[string]interface{}{
+				"usage_user":   20.0,
+				"usage_system": 5.0,
+				"usage_active": 25.0,
+				"usage_steal":  12.5,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "cpu_cgroup" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestCgroupsTime(t *testing.T) {
+	root := t.TempDir()
+	writeCPUStat(t, root, "system.slice", "usage_usec 3000000\nuser_usec 2000000\nsystem_usec 1000000\n"+
+		"nr_periods 100\nnr_throttled 10\nthrottled_usec 500000\n")
+
+	var mps system.MockPS
+	mps.On("CPUTimes").Return([]cpu.TimesStat{{CPU: "cpu0", User: 10, Idle: 90}}, nil)
+
+	plugin := &CPUStats{
+		ps:             &mps,
+		PerCPU:         true,
+		CollectCPUTime: true,
+		ReportCgroups:  true,
+		CgroupRoot:     root,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu_cgroup",
+			map[string]string{"cgroup": "system.slice"},
+			map[string]interface{}{
+				"time_user":      2.0,
+				"time_system":    1.0,
+				"time_throttled": 0.5,
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "cpu_cgroup" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestCgroupsNested(t *testing.T) {
+	root := t.TempDir()
+	writeCPUStat(t, root, "system.slice", "usage_usec 3000000\nuser_usec 2000000\nsystem_usec 1000000\n")
+	writeCPUMax(t, root, "system.slice", "max 100000\n")
+	writeCPUStat(t, root, "system.slice/nginx.service", "usage_usec 1000000\nuser_usec 1000000\nsystem_usec 0\n"+
+		"nr_periods 100\nnr_throttled 10\nthrottled_usec 500000\n")
+	writeCPUMax(t, root, "system.slice/nginx.service", "50000 100000\n")
+	writeCPUStat(t, root, "system.slice/cron.timer", "usage_usec 1000000\nuser_usec 1000000\nsystem_usec 0\n")
+	writeCPUStat(t, root, "user.slice", "usage_usec 1000000\nuser_usec 1000000\nsystem_usec 0\n")
+	writeCPUStat(t, root, "user.slice/user-1000.slice", "usage_usec 1000000\nuser_usec 1000000\nsystem_usec 0\n")
+
+	var mps system.MockPS
+	mps.On("CPUTimes").Return([]cpu.TimesStat{{CPU: "cpu-total", User: 10, Idle: 90}}, nil)
+
+	plugin := &CPUStats{
+		ps:            &mps,
+		TotalCPU:      true,
+		ReportCgroups: true,
+		CgroupRoot:    root,
+		Cgroups:       []string{"system.slice", "system.slice/*.service"},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	// Advance the host by 100 idle seconds
+	var mps2 system.MockPS
+	mps2.On("CPUTimes").Return([]cpu.TimesStat{{CPU: "cpu-total", User: 10, Idle: 190}}, nil)
+	plugin.ps = &mps2
+	writeCPUStat(t, root, "system.slice/nginx.service", "usage_usec 1000000\nuser_usec 1000000\nsystem_usec 0\n"+
+		"nr_periods 200\nnr_throttled 60\nthrottled_usec 900000\n")
+
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu_cgroup",
+			map[string]string{"cgroup": "system.slice"},
+			map[string]interface{}{
+				"usage_user":   0.0,
+				"usage_system": 0.0,
+				"usage_steal":  0.0,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"cpu_cgroup",
+			map[string]string{"cgroup": "system.slice/nginx.service"},
+			map[string]interface{}{
+				"usage_user":        0.0,
+				"usage_system":      0.0,
+				"usage_steal":       0.0,
+				"throttled_percent": 50.0,
+				"limit_cpus":        0.5,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "cpu_cgroup" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestCgroupsInvalidConfig(t *testing.T) {
+	plugin := &CPUStats{ReportCgroups: true}
+	require.ErrorContains(t, plugin.Init(), "requires either 'percpu' or 'totalcpu'")
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package cpu
+
+import "errors"
+
+const cgroupsSupported = false
+
+func readCgroupsCPU(string, int) (map[string]cgroupCPU, error) {
+	return nil, errors.New("cgroup statistics are only supported on Linux")
+}
@@ -5,11 +5,13 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/shirou/gopsutil/v4/cpu"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/inputs/system"
 )
@@ -30,7 +32,15 @@ type CPUStats struct {
 	ReportActive   bool `toml:"report_active"`
 	CoreTags       bool `toml:"core_tags"`
 
+	ReportCgroups bool     `toml:"report_cgroups"`
+	CgroupRoot    string   `toml:"cgroup_root"`
+	Cgroups       []string `toml:"cgroups"`
+
 	Log telegraf.Logger `toml:"-"`
+
+	lastCgroups  map[string]cgroupCPU
+	cgroupFilter filter.Filter
+	cgroupDepth  int
 }
 
 func (*CPUStats) SampleConfig() string {
@@ -38,6 +48,31 @@ func (*CPUStats) SampleConfig() string {
 }
 
 func (c *CPUStats) Init() error {
+	if c.ReportCgroups {
+		if !cgroupsSupported {
+			return errors.New("reporting cgroups is only supported on Linux")
+		}
+		if !c.PerCPU && !c.TotalCPU {
+			return errors.New("reporting cgroups requires either 'percpu' or 'totalcpu'")
+		}
+		if c.CgroupRoot == "" {
+			c.CgroupRoot = "/sys/fs/cgroup"
+		}
+		// Patterns of nested cgroups contain one path element per level so
+		// the hierarchy only needs to be read down to the deepest pattern
+		c.cgroupDepth = 1
+		for _, pattern := range c.Cgroups {
+			c.cgroupDepth = max(c.cgroupDepth, strings.Count(pattern, "/")+1)
+		}
+		if len(c.Cgroups) > 0 {
+			f, err := filter.Compile(c.Cgroups, '/')
+			if err != nil {
+				return fmt.Errorf("compiling cgroup filter failed: %w", err)
+			}
+			c.cgroupFilter = f
+		}
+	}
+
 	if c.CoreTags {
 		cpuInfo, err := cpu.Info()
 		if err == nil {
@@ -138,6 +173,12 @@ func (c *CPUStats) Gather(acc telegraf.Accumulator) error {
 		acc.AddGauge("cpu", fieldsG, tags, now)
 	}
 
+	if c.ReportCgroups && err == nil {
+		if cerr := c.gatherCgroups(acc, times, now); cerr != nil {
+			acc.AddError(fmt.Errorf("gathering cgroups failed: %w", cerr))
+		}
+	}
+
 	c.lastStats = make(map[string]cpu.TimesStat)
 	for _, cts := range times {
 		c.lastStats[cts.CPU] = cts
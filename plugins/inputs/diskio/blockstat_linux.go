@@ -0,0 +1,143 @@
+package diskio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Size of the sectors in the block statistics independent of the device
+const sectorSize = 512
+
+// blockStat contains the statistics of /sys/class/block/<dev>/stat not
+// available via gopsutil. Discard statistics are available since kernel 4.18,
+// flush statistics since kernel 5.5.
+type blockStat struct {
+	hasDiscard     bool
+	discards       uint64
+	mergedDiscards uint64
+	discardSectors uint64
+	discardTicks   uint64
+
+	hasFlush   bool
+	flushes    uint64
+	flushTicks uint64
+}
+
+func readBlockStat(sysfs, devName string) (blockStat, error) {
+	fn := filepath.Join(sysfs, "class", "block", filepath.Base(devName), "stat")
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return blockStat{}, err
+	}
+
+	parts := strings.Fields(string(buf))
+	values := make([]uint64, 0, len(parts))
+	for _, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return blockStat{}, fmt.Errorf("parsing %q failed: %w", fn, err)
+		}
+		values = append(values, v)
+	}
+
+	var stat blockStat
+	if len(values) >= 15 {
+		stat.hasDiscard = true
+		stat.discards = values[11]
+		stat.mergedDiscards = values[12]
+		stat.discardSectors = values[13]
+		stat.discardTicks = values[14]
+	}
+	if len(values) >= 17 {
+		stat.hasFlush = true
+		stat.flushes = values[15]
+		stat.flushTicks = values[16]
+	}
+	return stat, nil
+}
+
+// nvmeHealth contains the state of a NVMe controller as exposed via sysfs
+type nvmeHealth struct {
+	controller string
+	model      string
+	firmware   string
+	state      string
+
+	hasTemperature      bool
+	temperature         float64
+	hasAlarm            bool
+	temperatureAlarm    bool
+	hasCritical         bool
+	temperatureCritical float64
+}
+
+// readNVMeHealth reads the controller state and the temperature of the
+// controller of a NVMe namespace such as "nvme0n1"
+func readNVMeHealth(sysfs, devName string) (*nvmeHealth, error) {
+	link := filepath.Join(sysfs, "class", "block", filepath.Base(devName), "device")
+	dir, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &nvmeHealth{controller: filepath.Base(dir)}
+	if health.state, err = readSysfsString(filepath.Join(dir, "state")); err != nil {
+		return nil, err
+	}
+	health.model, _ = readSysfsString(filepath.Join(dir, "model"))
+	health.firmware, _ = readSysfsString(filepath.Join(dir, "firmware_rev"))
+
+	// Depending on the kernel version, the hwmon device is registered for the
+	// controller or for the underlying PCI device
+	matches, err := filepath.Glob(filepath.Join(dir, "hwmon*"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		if matches, err = filepath.Glob(filepath.Join(dir, "device", "hwmon*")); err != nil {
+			return nil, err
+		}
+	}
+	if len(matches) == 0 {
+		return health, nil
+	}
+
+	// The first sensor holds the composite temperature in millidegrees
+	hwmon := matches[0]
+	if v, err := readSysfsInt(filepath.Join(hwmon, "temp1_input")); err == nil {
+		health.hasTemperature = true
+		health.temperature = float64(v) / 1000
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if v, err := readSysfsInt(filepath.Join(hwmon, "temp1_alarm")); err == nil {
+		health.hasAlarm = true
+		health.temperatureAlarm = v != 0
+	}
+	if v, err := readSysfsInt(filepath.Join(hwmon, "temp1_crit")); err == nil {
+		health.hasCritical = true
+		health.temperatureCritical = float64(v) / 1000
+	}
+
+	return health, nil
+}
+
+func readSysfsString(fn string) (string, error) {
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(buf)), nil
+}
+
+func readSysfsInt(fn string) (int64, error) {
+	s, err := readSysfsString(fn)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
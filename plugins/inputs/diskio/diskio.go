@@ -4,7 +4,10 @@ package diskio
 import (
 	_ "embed"
 	"fmt"
+	"math"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +24,9 @@ var sampleConfig string
 
 var (
 	varRegex = regexp.MustCompile(`\$(?:\w+|\{\w+\})`)
+
+	// NVMe namespaces excluding their partitions, e.g. "nvme0n1"
+	nvmeNamespaceRegex = regexp.MustCompile(`^nvme\d+n\d+$`)
 )
 
 type DiskIO struct {
@@ -28,14 +34,22 @@ type DiskIO struct {
 	DeviceTags       []string        `toml:"device_tags"`
 	NameTemplates    []string        `toml:"name_templates"`
 	SkipSerialNumber bool            `toml:"skip_serial_number"`
+	ExtendedStats    bool            `toml:"extended_stats"`
+	NVMeHealth       bool            `toml:"nvme_health"`
+	LatencyBuckets   []float64       `toml:"latency_buckets"`
 	Log              telegraf.Logger `toml:"-"`
 
 	ps                system.PS
+	sysfs             string
 	infoCache         map[string]diskInfoCache
 	deviceFilter      filter.Filter
 	warnDiskName      map[string]bool
 	warnDiskTags      map[string]bool
+	warnBlockStat     map[string]bool
+	warnNVMeHealth    map[string]bool
 	lastIOCounterStat map[string]disk.IOCountersStat
+	lastBlockStat     map[string]blockStat
+	latencyCounts     map[string]map[string][]uint64
 	lastCollectTime   time.Time
 }
 
@@ -54,10 +68,25 @@ func (d *DiskIO) Init() error {
 		}
 	}
 
+	for _, b := range d.LatencyBuckets {
+		if math.IsNaN(b) || math.IsInf(b, 0) || b < 0 {
+			return fmt.Errorf("invalid latency bucket %v", b)
+		}
+	}
+	sort.Float64s(d.LatencyBuckets)
+
+	if d.sysfs == "" {
+		d.sysfs = "/sys"
+	}
+
 	d.infoCache = make(map[string]diskInfoCache)
 	d.warnDiskName = make(map[string]bool)
 	d.warnDiskTags = make(map[string]bool)
+	d.warnBlockStat = make(map[string]bool)
+	d.warnNVMeHealth = make(map[string]bool)
 	d.lastIOCounterStat = make(map[string]disk.IOCountersStat)
+	d.lastBlockStat = make(map[string]blockStat)
+	d.latencyCounts = make(map[string]map[string][]uint64)
 
 	return nil
 }
@@ -75,6 +104,7 @@ func (d *DiskIO) Gather(acc telegraf.Accumulator) error {
 		return fmt.Errorf("error getting disk io info: %w", err)
 	}
 	collectTime := time.Now()
+	blockStats := make(map[string]blockStat, len(diskio))
 	for k, io := range diskio {
 		match := false
 		if d.deviceFilter != nil && d.deviceFilter.Match(io.Name) {
@@ -139,13 +169,156 @@ func (d *DiskIO) Gather(acc telegraf.Accumulator) error {
 				fields["io_util"] = 100 * deltaIOTime / itv
 			}
 		}
+
+		var stat blockStat
+		var hasStat bool
+		if d.ExtendedStats || len(d.LatencyBuckets) > 0 {
+			stat, hasStat = d.blockStat(io.Name)
+			if hasStat {
+				blockStats[k] = stat
+			}
+		}
+		if d.ExtendedStats && hasStat {
+			if stat.hasDiscard {
+				fields["discards"] = stat.discards
+				fields["merged_discards"] = stat.mergedDiscards
+				fields["discard_bytes"] = stat.discardSectors * sectorSize
+				fields["discard_time"] = stat.discardTicks
+			}
+			if stat.hasFlush {
+				fields["flushes"] = stat.flushes
+				fields["flush_time"] = stat.flushTicks
+			}
+		}
 		acc.AddCounter("diskio", fields, tags)
+
+		if len(d.LatencyBuckets) > 0 {
+			d.gatherLatency(acc, k, io, stat, tags)
+		}
+		if d.NVMeHealth && nvmeNamespaceRegex.MatchString(io.Name) {
+			d.gatherNVMeHealth(acc, io.Name, tags)
+		}
 	}
 	d.lastCollectTime = collectTime
 	d.lastIOCounterStat = diskio
+	d.lastBlockStat = blockStats
 	return nil
 }
 
+// blockStat reads the extended statistics of the device and warns once per
+// device if those are not available
+func (d *DiskIO) blockStat(devName string) (blockStat, bool) {
+	stat, err := readBlockStat(d.sysfs, devName)
+	if err != nil {
+		if !d.warnBlockStat[devName] {
+			d.warnBlockStat[devName] = true
+			d.Log.Warnf("Unable to gather extended statistics for %q: %s", devName, err)
+		}
+		return blockStat{}, false
+	}
+	return stat, true
+}
+
+// gatherLatency attributes the operations completed since the last collection
+// to the latency bucket containing their average time spent and reports the
+// cumulative number of operations per bucket.
+func (d *DiskIO) gatherLatency(acc telegraf.Accumulator, key string, io disk.IOCountersStat, stat blockStat, tags map[string]string) {
+	last, hasLast := d.lastIOCounterStat[key]
+	lastStat := d.lastBlockStat[key]
+
+	type opDelta struct {
+		op           string
+		count, ticks uint64
+		valid        bool
+	}
+	deltas := []opDelta{
+		{"read", io.ReadCount - last.ReadCount, io.ReadTime - last.ReadTime, hasLast && io.ReadCount >= last.ReadCount},
+		{"write", io.WriteCount - last.WriteCount, io.WriteTime - last.WriteTime, hasLast && io.WriteCount >= last.WriteCount},
+	}
+	if stat.hasDiscard {
+		valid := lastStat.hasDiscard && stat.discards >= lastStat.discards
+		deltas = append(deltas, opDelta{"discard", stat.discards - lastStat.discards, stat.discardTicks - lastStat.discardTicks, valid})
+	}
+	if stat.hasFlush {
+		valid := lastStat.hasFlush && stat.flushes >= lastStat.flushes
+		deltas = append(deltas, opDelta{"flush", stat.flushes - lastStat.flushes, stat.flushTicks - lastStat.flushTicks, valid})
+	}
+
+	counts, found := d.latencyCounts[key]
+	if !found {
+		counts = make(map[string][]uint64, len(deltas))
+		d.latencyCounts[key] = counts
+	}
+
+	for _, delta := range deltas {
+		buckets, found := counts[delta.op]
+		if !found {
+			buckets = make([]uint64, len(d.LatencyBuckets)+1)
+			counts[delta.op] = buckets
+		}
+
+		// Counter resets e.g. due to a device being recreated skip the interval
+		if delta.valid && delta.count > 0 {
+			avg := float64(delta.ticks) / float64(delta.count)
+			buckets[sort.SearchFloat64s(d.LatencyBuckets, avg)] += delta.count
+		}
+
+		var cumulative uint64
+		for i, count := range buckets {
+			cumulative += count
+			le := "+Inf"
+			if i < len(d.LatencyBuckets) {
+				le = strconv.FormatFloat(d.LatencyBuckets[i], 'f', -1, 64)
+			}
+
+			bucketTags := make(map[string]string, len(tags)+2)
+			for k, v := range tags {
+				bucketTags[k] = v
+			}
+			bucketTags["op"] = delta.op
+			bucketTags["le"] = le
+			acc.AddCounter("diskio_latency", map[string]interface{}{"count": cumulative}, bucketTags)
+		}
+	}
+}
+
+func (d *DiskIO) gatherNVMeHealth(acc telegraf.Accumulator, devName string, tags map[string]string) {
+	health, err := readNVMeHealth(d.sysfs, devName)
+	if err != nil {
+		if !d.warnNVMeHealth[devName] {
+			d.warnNVMeHealth[devName] = true
+			d.Log.Warnf("Unable to gather NVMe health for %q: %s", devName, err)
+		}
+		return
+	}
+
+	healthTags := make(map[string]string, len(tags)+3)
+	for k, v := range tags {
+		healthTags[k] = v
+	}
+	healthTags["controller"] = health.controller
+	if health.model != "" {
+		healthTags["model"] = health.model
+	}
+	if health.firmware != "" {
+		healthTags["firmware"] = health.firmware
+	}
+
+	fields := map[string]interface{}{
+		"state": health.state,
+	}
+	if health.hasTemperature {
+		fields["temperature"] = health.temperature
+	}
+	if health.hasAlarm {
+		fields["temperature_alarm"] = health.temperatureAlarm
+	}
+	if health.hasCritical {
+		fields["temperature_critical"] = health.temperatureCritical
+	}
+	acc.AddGauge("diskio_nvme", fields, healthTags)
+}
+
 // hasMeta reports whether s contains any special glob characters.
 func hasMeta(s string) bool {
 	return strings.ContainsAny(s, "*?[")
@@ -4,9 +4,17 @@ package diskio
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/shirou/gopsutil/v4/disk"
 	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs/system"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestDiskInfo(t *testing.T) {
@@ -82,3 +90,158 @@ func TestDiskIOStats_diskTags(t *testing.T) {
 	dt := plugin.diskTags("null")
 	require.Equal(t, map[string]string{"MY_PARAM_2": "myval2"}, dt)
 }
+
+func TestExtendedStats(t *testing.T) {
+	var mps system.MockPS
+	mps.On("DiskIO").Return(map[string]disk.IOCountersStat{
+		"nvme42n1": {Name: "nvme42n1", ReadCount: 12000, WriteCount: 30000},
+		"sdq":      {Name: "sdq", ReadCount: 888, WriteCount: 5341},
+	}, nil)
+
+	plugin := &DiskIO{
+		ExtendedStats:    true,
+		SkipSerialNumber: true,
+		Log:              testutil.Logger{},
+		ps:               &mps,
+		sysfs:            "testdata/sys",
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	fields := make(map[string]map[string]interface{})
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, "diskio", m.Name())
+		name, _ := m.GetTag("name")
+		fields[name] = m.Fields()
+	}
+	require.Len(t, fields, 2)
+
+	// Kernels before 4.18 do not report discard and flush statistics
+	require.NotContains(t, fields["sdq"], "discards")
+	require.NotContains(t, fields["sdq"], "flushes")
+
+	expected := map[string]interface{}{
+		"discards":        uint64(500),
+		"merged_discards": uint64(2),
+		"discard_bytes":   uint64(80000 * 512),
+		"discard_time":    uint64(250),
+		"flushes":         uint64(300),
+		"flush_time":      uint64(600),
+	}
+	for k, v := range expected {
+		require.Equal(t, v, fields["nvme42n1"][k], "field %q", k)
+	}
+}
+
+func TestNVMeHealth(t *testing.T) {
+	var mps system.MockPS
+	mps.On("DiskIO").Return(map[string]disk.IOCountersStat{
+		"nvme42n1":   {Name: "nvme42n1"},
+		"nvme42n1p1": {Name: "nvme42n1p1"},
+		"sdq":        {Name: "sdq"},
+	}, nil)
+
+	plugin := &DiskIO{
+		NVMeHealth:       true,
+		SkipSerialNumber: true,
+		Log:              testutil.Logger{},
+		ps:               &mps,
+		sysfs:            "testdata/sys",
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"diskio_nvme",
+			map[string]string{
+				"name":       "nvme42n1",
+				"controller": "nvme42",
+				"model":      "Samsung SSD 980 PRO 1TB",
+				"firmware":   "5B2QGXA7",
+			},
+			map[string]interface{}{
+				"state":                "live",
+				"temperature":          41.85,
+				"temperature_alarm":    false,
+				"temperature_critical": 84.85,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "diskio_nvme" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	var mps system.MockPS
+	mps.On("DiskIO").Return(map[string]disk.IOCountersStat{
+		"sdq": {Name: "sdq", ReadCount: 888, WriteCount: 5341, ReadTime: 7123, WriteTime: 9087},
+	}, nil)
+
+	plugin := &DiskIO{
+		LatencyBuckets:   []float64{10, 1},
+		SkipSerialNumber: true,
+		Log:              testutil.Logger{},
+		ps:               &mps,
+		sysfs:            "testdata/sys",
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []float64{1, 10}, plugin.LatencyBuckets)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	// Reads with an average latency of 0.5ms and writes with 20ms
+	var mps2 system.MockPS
+	mps2.On("DiskIO").Return(map[string]disk.IOCountersStat{
+		"sdq": {Name: "sdq", ReadCount: 1888, WriteCount: 5441, ReadTime: 7623, WriteTime: 11087},
+	}, nil)
+	plugin.ps = &mps2
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	counts := map[string]uint64{
+		"read/1":     1000,
+		"read/10":    1000,
+		"read/+Inf":  1000,
+		"write/1":    0,
+		"write/10":   0,
+		"write/+Inf": 100,
+	}
+	expected := make([]telegraf.Metric, 0, len(counts))
+	for k, v := range counts {
+		op, le, _ := strings.Cut(k, "/")
+		expected = append(expected, metric.New(
+			"diskio_latency",
+			map[string]string{"name": "sdq", "op": op, "le": le},
+			map[string]interface{}{"count": v},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		))
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "diskio_latency" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
@@ -2,6 +2,8 @@
 
 package diskio
 
+import "errors"
+
 type diskInfoCache struct{}
 
 func (*DiskIO) diskInfo(_ string) (map[string]string, error) {
@@ -15,3 +17,39 @@ func resolveName(name string) string {
 func getDeviceWWID(_ string) string {
 	return ""
 }
+
+type blockStat struct {
+	hasDiscard     bool
+	discards       uint64
+	mergedDiscards uint64
+	discardSectors uint64
+	discardTicks   uint64
+
+	hasFlush   bool
+	flushes    uint64
+	flushTicks uint64
+}
+
+type nvmeHealth struct {
+	controller string
+	model      string
+	firmware   string
+	state      string
+
+	hasTemperature      bool
+	temperature         float64
+	hasAlarm            bool
+	temperatureAlarm    bool
+	hasCritical         bool
+	temperatureCritical float64
+}
+
+const sectorSize = 512
+
+func readBlockStat(_, _ string) (blockStat, error) {
+	return blockStat{}, errors.New("extended statistics are only supported on Linux")
+}
+
+func readNVMeHealth(_, _ string) (*nvmeHealth, error) {
+	return nil, errors.New("NVMe health is only supported on Linux")
+}
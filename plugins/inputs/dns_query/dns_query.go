@@ -2,10 +2,14 @@
 package dns_query
 
 import (
+	"bytes"
 	_ "embed"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"net/url"
 	"slices"
 	"strconv"
 	"sync"
@@ -15,6 +19,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -34,15 +39,25 @@ const (
 )
 
 type DNSQuery struct {
-	Domains       []string        `toml:"domains"`
-	Network       string          `toml:"network"`
-	Servers       []string        `toml:"servers"`
-	RecordType    string          `toml:"record_type"`
-	Port          int             `toml:"port"`
-	Timeout       config.Duration `toml:"timeout"`
-	IncludeFields []string        `toml:"include_fields"`
+	Domains            []string        `toml:"domains"`
+	Network            string          `toml:"network"`
+	Servers            []string        `toml:"servers"`
+	RecordType         string          `toml:"record_type"`
+	RecordTypes        []string        `toml:"record_types"`
+	Port               int             `toml:"port"`
+	Path               string          `toml:"path"`
+	Timeout            config.Duration `toml:"timeout"`
+	IncludeFields      []string        `toml:"include_fields"`
+	DNSSECValidation   bool            `toml:"dnssec_validation"`
+	DNSSECTrustAnchors []string        `toml:"dnssec_trust_anchors"`
+	Log                telegraf.Logger `toml:"-"`
+	common_tls.ClientConfig
 
 	fieldEnabled map[string]bool
+	recordTypes  []uint16
+	client       *dns.Client
+	httpClient   *http.Client
+	anchors      []*dns.DS
 }
 
 func (*DNSQuery) SampleConfig() string {
@@ -73,10 +88,73 @@ func (d *DNSQuery) Init() error {
 	if len(d.Domains) == 0 {
 		d.Domains = []string{"."}
 		d.RecordType = "NS"
+		d.RecordTypes = nil
 	}
 
-	if d.Port < 1 {
-		d.Port = 53
+	if len(d.RecordTypes) == 0 {
+		d.RecordTypes = []string{d.RecordType}
+	}
+	d.recordTypes = make([]uint16, 0, len(d.RecordTypes))
+	for _, name := range d.RecordTypes {
+		recordType, err := parseRecordType(name)
+		if err != nil {
+			return err
+		}
+		d.recordTypes = append(d.recordTypes, recordType)
+	}
+
+	tlsCfg, err := d.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS configuration failed: %w", err)
+	}
+
+	switch d.Network {
+	case "udp", "tcp":
+		if d.Port < 1 {
+			d.Port = 53
+		}
+	case "tcp-tls":
+		// DNS-over-TLS according to RFC 7858
+		if d.Port < 1 {
+			d.Port = 853
+		}
+	case "https":
+		// DNS-over-HTTPS according to RFC 8484
+		if d.Port < 1 {
+			d.Port = 443
+		}
+		if d.Path == "" {
+			d.Path = "/dns-query"
+		}
+		d.httpClient = &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsCfg},
+			Timeout:   time.Duration(d.Timeout),
+		}
+	default:
+		return fmt.Errorf("invalid network %q", d.Network)
+	}
+	d.client = &dns.Client{
+		Net:         d.Network,
+		ReadTimeout: time.Duration(d.Timeout),
+		TLSConfig:   tlsCfg,
+	}
+
+	// Use the root zone trust anchors if no others are given
+	anchors := d.DNSSECTrustAnchors
+	if len(anchors) == 0 {
+		anchors = rootTrustAnchors
+	}
+	d.anchors = make([]*dns.DS, 0, len(anchors))
+	for _, anchor := range anchors {
+		rr, err := dns.NewRR(anchor)
+		if err != nil {
+			return fmt.Errorf("parsing trust anchor %q failed: %w", anchor, err)
+		}
+		ds, ok := rr.(*dns.DS)
+		if !ok {
+			return fmt.Errorf("trust anchor %q is not a DS record", anchor)
+		}
+		d.anchors = append(d.anchors, ds)
 	}
 
 	return nil
@@ -87,19 +165,18 @@ func (d *DNSQuery) Gather(acc telegraf.Accumulator) error {
 
 	for _, domain := range d.Domains {
 		for _, server := range d.Servers {
-			wg.Add(1)
-			go func(domain, server string) {
-				defer wg.Done()
-
-				fields, tags, err := d.query(domain, server)
-				if err != nil && !slices.Contains(ignoredErrors, tags["rcode"]) {
-					var opErr *net.OpError
-					if !errors.As(err, &opErr) || !opErr.Timeout() {
+			for i, recordType := range d.recordTypes {
+				wg.Add(1)
+				go func(domain, server, name string, recordType uint16) {
+					defer wg.Done()
+
+					fields, tags, err := d.query(domain, server, name, recordType)
+					if err != nil && !slices.Contains(ignoredErrors, tags["rcode"]) && !isTimeout(err) {
 						acc.AddError(err)
 					}
-				}
-				acc.AddFields("dns_query", fields, tags)
-			}(domain, server)
+					acc.AddFields("dns_query", fields, tags)
+				}(domain, server, d.RecordTypes[i], recordType)
+			}
 		}
 	}
 	wg.Wait()
@@ -107,11 +184,11 @@ func (d *DNSQuery) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (d *DNSQuery) query(domain, server string) (map[string]interface{}, map[string]string, error) {
+func (d *DNSQuery) query(domain, server, recordTypeName string, recordType uint16) (map[string]interface{}, map[string]string, error) {
 	tags := map[string]string{
 		"server":      server,
 		"domain":      domain,
-		"record_type": d.RecordType,
+		"record_type": recordTypeName,
 		"result":      "error",
 	}
 
@@ -120,28 +197,11 @@ func (d *DNSQuery) query(domain, server string) (map[string]interface{}, map[str
 		"result_code":   uint64(errorResult),
 	}
 
-	c := dns.Client{
-		ReadTimeout: time.Duration(d.Timeout),
-		Net:         d.Network,
-	}
-
-	recordType, err := d.parseRecordType()
+	r, rtt, err := d.exchange(server, dns.Fqdn(domain), recordType)
 	if err != nil {
-		return fields, tags, err
-	}
-
-	var msg dns.Msg
-	msg.SetQuestion(dns.Fqdn(domain), recordType)
-	msg.RecursionDesired = true
-
-	addr := net.JoinHostPort(server, strconv.Itoa(d.Port))
-	r, rtt, err := c.Exchange(&msg, addr)
-	if err != nil {
-		var opErr *net.OpError
-		if errors.As(err, &opErr) && opErr.Timeout() {
+		if isTimeout(err) {
 			tags["result"] = "timeout"
 			fields["result_code"] = uint64(timeoutResult)
-			return fields, tags, err
 		}
 		return fields, tags, err
 	}
@@ -150,10 +210,29 @@ func (d *DNSQuery) query(domain, server string) (map[string]interface{}, map[str
 	tags["rcode"] = dns.RcodeToString[r.Rcode]
 	fields["rcode_value"] = r.Rcode
 	fields["query_time_ms"] = float64(rtt.Nanoseconds()) / 1e6
+	fields["answer_count"] = len(r.Answer)
+	fields["authority_count"] = len(r.Ns)
 
 	// Handle the failure case
 	if r.Rcode != dns.RcodeSuccess {
-		return fields, tags, fmt.Errorf("invalid answer (%s) from %s after %s query for %s", dns.RcodeToString[r.Rcode], server, d.RecordType, domain)
+		return fields, tags, fmt.Errorf("invalid answer (%s) from %s after %s query for %s", dns.RcodeToString[r.Rcode], server, recordTypeName, domain)
+	}
+
+	if d.DNSSECValidation {
+		v := &validator{
+			exchange: func(name string, qtype uint16) (*dns.Msg, error) {
+				r, _, err := d.exchange(server, name, qtype)
+				return r, err
+			},
+			anchors: d.anchors,
+			now:     time.Now(),
+		}
+		status, err := v.validate(r)
+		if err != nil {
+			d.Log.Debugf("DNSSEC validation of %s query for %s at %s: %v", recordTypeName, domain, server, err)
+		}
+		tags["dnssec"] = status
+		fields["authenticated_data"] = r.AuthenticatedData
 	}
 
 	// Success
@@ -201,11 +280,65 @@ func (d *DNSQuery) query(domain, server string) (map[string]interface{}, map[str
 	return fields, tags, nil
 }
 
-func (d *DNSQuery) parseRecordType() (uint16, error) {
+// exchange sends the query to the server using the configured transport
+func (d *DNSQuery) exchange(server, name string, recordType uint16) (*dns.Msg, time.Duration, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(name, recordType)
+	msg.RecursionDesired = true
+	if d.DNSSECValidation {
+		// Request the signatures and disable the validation of the server to
+		// be able to detect bogus answers
+		msg.SetEdns0(4096, true)
+		msg.CheckingDisabled = true
+	}
+
+	addr := net.JoinHostPort(server, strconv.Itoa(d.Port))
+	if d.Network != "https" {
+		return d.client.Exchange(msg, addr)
+	}
+
+	// Use a zero message ID as recommended for DNS-over-HTTPS
+	msg.Id = 0
+	buf, err := msg.Pack()
+	if err != nil {
+		return nil, 0, fmt.Errorf("packing query failed: %w", err)
+	}
+	u := url.URL{Scheme: "https", Host: addr, Path: d.Path}
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(buf))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	start := time.Now()
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("querying %s failed with status %q", u.String(), resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading response failed: %w", err)
+	}
+	rtt := time.Since(start)
+
+	r := new(dns.Msg)
+	if err := r.Unpack(body); err != nil {
+		return nil, 0, fmt.Errorf("unpacking response failed: %w", err)
+	}
+	return r, rtt, nil
+}
+
+func parseRecordType(name string) (uint16, error) {
 	var recordType uint16
 	var err error
 
-	switch d.RecordType {
+	switch name {
 	case "A":
 		recordType = dns.TypeA
 	case "AAAA":
@@ -229,12 +362,17 @@ func (d *DNSQuery) parseRecordType() (uint16, error) {
 	case "TXT":
 		recordType = dns.TypeTXT
 	default:
-		err = fmt.Errorf("record type %s not recognized", d.RecordType)
+		err = fmt.Errorf("record type %s not recognized", name)
 	}
 
 	return recordType, err
 }
 
+func isTimeout(err error) bool {
+	var timeoutErr interface{ Timeout() bool }
+	return errors.As(err, &timeoutErr) && timeoutErr.Timeout()
+}
+
 func extractIP(record dns.RR) (string, bool) {
 	if r, ok := record.(*dns.A); ok {
 		return r.A.String(), true
@@ -1,9 +1,15 @@
 package dns_query
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/require"
 
@@ -266,7 +272,8 @@ func TestRecordTypeParser(t *testing.T) {
 				RecordType: tt.record,
 			}
 			require.NoError(t, plugin.Init())
-			recordType, err := plugin.parseRecordType()
+			require.Equal(t, []uint16{tt.expected}, plugin.recordTypes)
+			recordType, err := parseRecordType(tt.record)
 			require.NoError(t, err)
 			require.Equal(t, tt.expected, recordType)
 		})
@@ -276,9 +283,186 @@ func TestRecordTypeParser(t *testing.T) {
 func TestRecordTypeParserError(t *testing.T) {
 	plugin := DNSQuery{
 		Timeout:    config.Duration(2 * time.Second),
+		Domains:    []string{"example.com"},
 		RecordType: "nil",
 	}
+	require.EqualError(t, plugin.Init(), "record type nil not recognized")
 
-	_, err := plugin.parseRecordType()
+	_, err := parseRecordType("nil")
 	require.Error(t, err)
 }
+
+func TestRecordTypes(t *testing.T) {
+	records := make(mockRecords)
+	records.add(t, nil, nil, newRR(t, "example.org. 3600 IN A 192.0.2.1"))
+	records.add(t, nil, nil, newRR(t, "example.org. 3600 IN A 192.0.2.2"))
+	records.add(t, nil, nil, newRR(t, "example.org. 3600 IN AAAA 2001:db8::1"))
+
+	server := records.listen(t)
+
+	plugin := &DNSQuery{
+		Servers:     []string{"127.0.0.1"},
+		Port:        server.Port,
+		Domains:     []string{"example.org"},
+		RecordTypes: []string{"A", "AAAA", "MX"},
+		Timeout:     config.Duration(2 * time.Second),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"dns_query",
+			map[string]string{
+				"server":      "127.0.0.1",
+				"domain":      "example.org",
+				"record_type": "A",
+				"rcode":       "NOERROR",
+				"result":      "success",
+			},
+			map[string]interface{}{
+				"rcode_value":     0,
+				"result_code":     uint64(0),
+				"answer_count":    2,
+				"authority_count": 0,
+				"name":            "example.org.",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"dns_query",
+			map[string]string{
+				"server":      "127.0.0.1",
+				"domain":      "example.org",
+				"record_type": "AAAA",
+				"rcode":       "NOERROR",
+				"result":      "success",
+			},
+			map[string]interface{}{
+				"rcode_value":     0,
+				"result_code":     uint64(0),
+				"answer_count":    1,
+				"authority_count": 0,
+				"name":            "example.org.",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"dns_query",
+			map[string]string{
+				"server":      "127.0.0.1",
+				"domain":      "example.org",
+				"record_type": "MX",
+				"rcode":       "NOERROR",
+				"result":      "success",
+			},
+			map[string]interface{}{
+				"rcode_value":     0,
+				"result_code":     uint64(0),
+				"answer_count":    0,
+				"authority_count": 0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{
+		testutil.IgnoreFields("query_time_ms"),
+		testutil.IgnoreTime(),
+		testutil.SortMetrics(),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+}
+
+func TestDNSOverHTTPS(t *testing.T) {
+	records := make(mockRecords)
+	records.add(t, nil, nil, newRR(t, "example.org. 3600 IN A 192.0.2.1"))
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/dns-query" || r.Header.Get("Content-Type") != "application/dns-message" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		req := new(dns.Msg)
+		if err := req.Unpack(body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		buf, err := records.reply(req).Pack()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(buf) //nolint:errcheck // test server
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+
+	plugin := &DNSQuery{
+		Servers:       []string{u.Hostname()},
+		Network:       "https",
+		Port:          port,
+		Domains:       []string{"example.org"},
+		RecordType:    "A",
+		Timeout:       config.Duration(2 * time.Second),
+		IncludeFields: []string{"first_ip"},
+		Log:           testutil.Logger{},
+	}
+	plugin.InsecureSkipVerify = true
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "/dns-query", plugin.Path)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"dns_query",
+			map[string]string{
+				"server":      u.Hostname(),
+				"domain":      "example.org",
+				"record_type": "A",
+				"rcode":       "NOERROR",
+				"result":      "success",
+			},
+			map[string]interface{}{
+				"rcode_value":     0,
+				"result_code":     uint64(0),
+				"answer_count":    1,
+				"authority_count": 0,
+				"name":            "example.org.",
+				"ip":              "192.0.2.1",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreFields("query_time_ms"), testutil.IgnoreTime())
+}
+
+func TestInitNetwork(t *testing.T) {
+	for network, port := range map[string]int{"udp": 53, "tcp": 53, "tcp-tls": 853, "https": 443} {
+		plugin := &DNSQuery{Network: network, Domains: []string{"example.org"}}
+		require.NoError(t, plugin.Init())
+		require.Equal(t, port, plugin.Port, network)
+	}
+
+	plugin := &DNSQuery{Network: "quic"}
+	require.EqualError(t, plugin.Init(), `invalid network "quic"`)
+
+	plugin = &DNSQuery{DNSSECTrustAnchors: []string{". IN A 192.0.2.1"}}
+	require.EqualError(t, plugin.Init(), `trust anchor ". IN A 192.0.2.1" is not a DS record`)
+}
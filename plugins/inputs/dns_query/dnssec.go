@@ -0,0 +1,276 @@
+package dns_query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Trust anchors of the root zone as published by IANA at
+// https://data.iana.org/root-anchors/root-anchors.xml
+var rootTrustAnchors = []string{
+	". IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	". IN DS 38696 8 2 683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+}
+
+const (
+	dnssecSecure        = "secure"
+	dnssecInsecure      = "insecure"
+	dnssecBogus         = "bogus"
+	dnssecIndeterminate = "indeterminate"
+)
+
+var (
+	errBogus    = errors.New("bogus")
+	errInsecure = errors.New("insecure delegation")
+)
+
+// validator verifies the chain of trust of the records in a DNS answer
+// starting at the configured trust anchors
+type validator struct {
+	exchange func(name string, qtype uint16) (*dns.Msg, error)
+	anchors  []*dns.DS
+	now      time.Time
+
+	keys map[string][]*dns.DNSKEY
+}
+
+// validate returns the DNSSEC status of the answer. In case the answer is
+// empty, the records of the authority section are validated instead.
+func (v *validator) validate(msg *dns.Msg) (string, error) {
+	records := msg.Answer
+	if len(records) == 0 {
+		records = msg.Ns
+	}
+
+	var insecure bool
+	for _, rrset := range splitRRSets(records) {
+		err := v.verifyRRSet(rrset, signatures(records, rrset[0].Header()))
+		switch {
+		case err == nil:
+		case errors.Is(err, errInsecure):
+			insecure = true
+		case errors.Is(err, errBogus):
+			return dnssecBogus, err
+		default:
+			return dnssecIndeterminate, err
+		}
+	}
+
+	if insecure {
+		return dnssecInsecure, nil
+	}
+	return dnssecSecure, nil
+}
+
+// verifyRRSet checks the signatures of the given record set
+func (v *validator) verifyRRSet(rrset []dns.RR, sigs []*dns.RRSIG) error {
+	owner := rrset[0].Header().Name
+	if len(sigs) == 0 {
+		return v.checkUnsigned(owner)
+	}
+
+	var lastErr error
+	for _, sig := range sigs {
+		if !dns.IsSubDomain(sig.SignerName, owner) {
+			lastErr = fmt.Errorf("%w: signer %q not authoritative for %q", errBogus, sig.SignerName, owner)
+			continue
+		}
+		keys, err := v.zoneKeys(sig.SignerName)
+		if err != nil {
+			return err
+		}
+		if err := v.verifySignature(sig, keys, rrset); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
+
+func (v *validator) verifySignature(sig *dns.RRSIG, keys []*dns.DNSKEY, rrset []dns.RR) error {
+	if !sig.ValidityPeriod(v.now) {
+		return fmt.Errorf("%w: signature of %s %s expired or not yet valid", errBogus, sig.Hdr.Name, dns.TypeToString[sig.TypeCovered])
+	}
+	for _, key := range keys {
+		if key.KeyTag() != sig.KeyTag || key.Algorithm != sig.Algorithm {
+			continue
+		}
+		if err := sig.Verify(key, rrset); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no valid signature for %s %s", errBogus, sig.Hdr.Name, dns.TypeToString[sig.TypeCovered])
+}
+
+// zoneKeys returns the validated DNSKEY records of the given zone. The keys
+// are authenticated by the trust anchors for the root zone or by the signed
+// DS records in the parent zone.
+func (v *validator) zoneKeys(zone string) ([]*dns.DNSKEY, error) {
+	zone = dns.CanonicalName(zone)
+	if keys, found := v.keys[zone]; found {
+		return keys, nil
+	}
+
+	trusted := v.anchors
+	if zone != "." {
+		msg, err := v.exchange(zone, dns.TypeDS)
+		if err != nil {
+			return nil, fmt.Errorf("querying DS of %q failed: %w", zone, err)
+		}
+		var dsset []dns.RR
+		trusted = nil
+		for _, rr := range msg.Answer {
+			if ds, ok := rr.(*dns.DS); ok {
+				dsset = append(dsset, ds)
+				trusted = append(trusted, ds)
+			}
+		}
+		if len(dsset) == 0 {
+			return nil, fmt.Errorf("%w: no DS for %q", errInsecure, zone)
+		}
+
+		// The DS records are signed by the parent zone
+		sigs := signatures(msg.Answer, dsset[0].Header())
+		if len(sigs) == 0 {
+			return nil, fmt.Errorf("%w: unsigned DS for %q", errBogus, zone)
+		}
+		var verified bool
+		for _, sig := range sigs {
+			signer := dns.CanonicalName(sig.SignerName)
+			if signer == zone || !dns.IsSubDomain(signer, zone) {
+				continue
+			}
+			keys, err := v.zoneKeys(signer)
+			if err != nil {
+				return nil, err
+			}
+			if v.verifySignature(sig, keys, dsset) == nil {
+				verified = true
+				break
+			}
+		}
+		if !verified {
+			return nil, fmt.Errorf("%w: no valid signature for DS of %q", errBogus, zone)
+		}
+	}
+
+	msg, err := v.exchange(zone, dns.TypeDNSKEY)
+	if err != nil {
+		return nil, fmt.Errorf("querying DNSKEY of %q failed: %w", zone, err)
+	}
+	var keyset []dns.RR
+	var keys []*dns.DNSKEY
+	for _, rr := range msg.Answer {
+		if key, ok := rr.(*dns.DNSKEY); ok {
+			keyset = append(keyset, key)
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no DNSKEY for %q", errBogus, zone)
+	}
+
+	// Find the key-signing keys matching the trusted DS records and use
+	// those to verify the key set
+	var ksks []*dns.DNSKEY
+	for _, key := range keys {
+		for _, ds := range trusted {
+			if key.KeyTag() != ds.KeyTag || key.Algorithm != ds.Algorithm {
+				continue
+			}
+			if d := key.ToDS(ds.DigestType); d != nil && strings.EqualFold(d.Digest, ds.Digest) {
+				ksks = append(ksks, key)
+				break
+			}
+		}
+	}
+	if len(ksks) == 0 {
+		return nil, fmt.Errorf("%w: no DNSKEY of %q matches the DS records", errBogus, zone)
+	}
+
+	var verified bool
+	for _, sig := range signatures(msg.Answer, keyset[0].Header()) {
+		if v.verifySignature(sig, ksks, keyset) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("%w: no valid signature for DNSKEY of %q", errBogus, zone)
+	}
+
+	if v.keys == nil {
+		v.keys = make(map[string][]*dns.DNSKEY)
+	}
+	v.keys[zone] = keys
+	return keys, nil
+}
+
+// checkUnsigned determines if unsigned records are expected, i.e. if the
+// closest enclosing zone is not signed. Otherwise the records are bogus.
+func (v *validator) checkUnsigned(owner string) error {
+	name := dns.CanonicalName(owner)
+	for {
+		msg, err := v.exchange(name, dns.TypeSOA)
+		if err != nil {
+			return fmt.Errorf("querying SOA of %q failed: %w", name, err)
+		}
+		for _, rr := range msg.Answer {
+			if soa, ok := rr.(*dns.SOA); ok && dns.CanonicalName(soa.Hdr.Name) == name {
+				// Found the zone apex, an unsigned zone has no validated keys
+				if _, err := v.zoneKeys(name); err != nil {
+					return err
+				}
+				return fmt.Errorf("%w: missing signature for %q in signed zone %q", errBogus, owner, name)
+			}
+		}
+
+		if name == "." {
+			return fmt.Errorf("%w: no zone found for %q", errBogus, owner)
+		}
+		if i, end := dns.NextLabel(name, 0); end {
+			name = "."
+		} else {
+			name = name[i:]
+		}
+	}
+}
+
+// splitRRSets groups the records into sets of the same owner, class and type
+// skipping signatures
+func splitRRSets(records []dns.RR) [][]dns.RR {
+	var rrsets [][]dns.RR
+	index := make(map[string]int)
+	for _, rr := range records {
+		h := rr.Header()
+		if h.Rrtype == dns.TypeRRSIG || h.Rrtype == dns.TypeOPT {
+			continue
+		}
+		key := fmt.Sprintf("%s/%d/%d", dns.CanonicalName(h.Name), h.Class, h.Rrtype)
+		if i, found := index[key]; found {
+			rrsets[i] = append(rrsets[i], rr)
+			continue
+		}
+		index[key] = len(rrsets)
+		rrsets = append(rrsets, []dns.RR{rr})
+	}
+	return rrsets
+}
+
+// signatures returns the RRSIG records covering the record set with the given
+// header
+func signatures(records []dns.RR, h *dns.RR_Header) []*dns.RRSIG {
+	var sigs []*dns.RRSIG
+	for _, rr := range records {
+		sig, ok := rr.(*dns.RRSIG)
+		if ok && sig.TypeCovered == h.Rrtype && strings.EqualFold(sig.Hdr.Name, h.Name) {
+			sigs = append(sigs, sig)
+		}
+	}
+	return sigs
+}
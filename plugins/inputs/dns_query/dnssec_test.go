@@ -0,0 +1,146 @@
+package dns_query
+
+import (
+	"crypto"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestDNSSECValidation(t *testing.T) {
+	records := make(mockRecords)
+
+	rootKey, rootSigner := newZoneKey(t, ".")
+	exampleKey, exampleSigner := newZoneKey(t, "example.")
+
+	// Signed root zone delegating to the signed "example." zone and to the
+	// unsigned "insecure." zone
+	records.add(t, rootKey, rootSigner, rootKey)
+	records.add(t, rootKey, rootSigner, exampleKey.ToDS(dns.SHA256))
+	records.add(t, nil, nil, newRR(t, "insecure. 3600 IN SOA ns.insecure. admin.insecure. 1 3600 600 86400 300"))
+
+	// Signed zone
+	records.add(t, exampleKey, exampleSigner, exampleKey)
+	records.add(t, exampleKey, exampleSigner, newRR(t, "example. 3600 IN SOA ns.example. admin.example. 1 3600 600 86400 300"))
+	records.add(t, exampleKey, exampleSigner, newRR(t, "www.example. 3600 IN A 192.0.2.1"))
+	records.add(t, nil, nil, newRR(t, "unsigned.example. 3600 IN A 192.0.2.3"))
+	records.add(t, exampleKey, exampleSigner, newRR(t, "bogus.example. 3600 IN A 192.0.2.2"))
+	records["bogus.example./A"][0].(*dns.A).A = net.ParseIP("192.0.2.66")
+
+	// Unsigned zone
+	records.add(t, nil, nil, newRR(t, "www.insecure. 3600 IN A 192.0.2.4"))
+
+	server := records.listen(t)
+
+	plugin := &DNSQuery{
+		Servers:            []string{"127.0.0.1"},
+		Port:               server.Port,
+		Domains:            []string{"www.example", "unsigned.example", "bogus.example", "www.insecure"},
+		RecordType:         "A",
+		Timeout:            config.Duration(2 * time.Second),
+		DNSSECValidation:   true,
+		DNSSECTrustAnchors: []string{rootKey.ToDS(dns.SHA256).String()},
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	status := make(map[string]string)
+	for _, m := range acc.GetTelegrafMetrics() {
+		domain, _ := m.GetTag("domain")
+		status[domain], _ = m.GetTag("dnssec")
+	}
+	expected := map[string]string{
+		"www.example":      "secure",
+		"unsigned.example": "bogus",
+		"bogus.example":    "bogus",
+		"www.insecure":     "insecure",
+	}
+	require.Equal(t, expected, status)
+}
+
+// mockRecords holds the records of a mock DNS server indexed by name and type
+type mockRecords map[string][]dns.RR
+
+// add adds the record and its signature if a key is given
+func (m mockRecords) add(t *testing.T, key *dns.DNSKEY, signer crypto.Signer, rr dns.RR) {
+	h := rr.Header()
+	index := h.Name + "/" + dns.TypeToString[h.Rrtype]
+	m[index] = append(m[index], rr)
+	if key == nil {
+		return
+	}
+
+	// Sign the whole record set, replacing the previous signature
+	var rrset []dns.RR
+	for _, r := range m[index] {
+		if _, ok := r.(*dns.RRSIG); !ok {
+			rrset = append(rrset, r)
+		}
+	}
+	now := time.Now()
+	sig := &dns.RRSIG{
+		Algorithm:  key.Algorithm,
+		KeyTag:     key.KeyTag(),
+		SignerName: key.Hdr.Name,
+		Inception:  uint32(now.Add(-time.Hour).Unix()),
+		Expiration: uint32(now.Add(time.Hour).Unix()),
+	}
+	require.NoError(t, sig.Sign(signer, rrset))
+	m[index] = append(rrset, sig)
+}
+
+func (m mockRecords) reply(req *dns.Msg) *dns.Msg {
+	msg := new(dns.Msg)
+	msg.SetReply(req)
+	q := req.Question[0]
+	msg.Answer = m[strings.ToLower(q.Name)+"/"+dns.TypeToString[q.Qtype]]
+	return msg
+}
+
+func (m mockRecords) listen(t *testing.T) *net.UDPAddr {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn: conn,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			w.WriteMsg(m.reply(req)) //nolint:errcheck // test server
+		}),
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go server.ActivateAndServe() //nolint:errcheck // test server
+	t.Cleanup(func() { server.Shutdown() })
+	<-started
+
+	return conn.LocalAddr().(*net.UDPAddr)
+}
+
+func newZoneKey(t *testing.T, zone string) (*dns.DNSKEY, crypto.Signer) {
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: zone, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     dns.ZONE | dns.SEP,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	priv, err := key.Generate(256)
+	require.NoError(t, err)
+	return key, priv.(crypto.Signer)
+}
+
+func newRR(t *testing.T, s string) dns.RR {
+	rr, err := dns.NewRR(s)
+	require.NoError(t, err)
+	return rr
+}
@@ -0,0 +1,292 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package dotnet
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type DotNet struct {
+	ProcessNames    []string        `toml:"process_names"`
+	Providers       []string        `toml:"providers"`
+	CounterInterval config.Duration `toml:"counter_interval"`
+	SocketDirectory string          `toml:"socket_directory"`
+	Log             telegraf.Logger `toml:"-"`
+
+	filter   filter.Filter
+	acc      telegraf.Accumulator
+	ctx      context.Context
+	cancel   context.CancelFunc
+	sessions map[int32]*session
+	wg       sync.WaitGroup
+	sync.Mutex
+}
+
+// session is an EventPipe session streaming the counters of one process
+type session struct {
+	pid      int32
+	name     string
+	endpoint string
+
+	conn io.ReadWriteCloser
+	id   uint64
+	done bool
+	sync.Mutex
+}
+
+func (*DotNet) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DotNet) Init() error {
+	if len(d.Providers) == 0 {
+		d.Providers = []string{"System.Runtime"}
+	}
+	if d.CounterInterval < config.Duration(time.Second) {
+		if d.CounterInterval != 0 {
+			return errors.New("counter_interval must be at least one second")
+		}
+		d.CounterInterval = config.Duration(10 * time.Second)
+	}
+
+	if len(d.ProcessNames) > 0 {
+		f, err := filter.Compile(d.ProcessNames)
+		if err != nil {
+			return fmt.Errorf("creating process name filter failed: %w", err)
+		}
+		d.filter = f
+	}
+
+	return nil
+}
+
+func (d *DotNet) Start(acc telegraf.Accumulator) error {
+	d.acc = acc
+	d.sessions = make(map[int32]*session)
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	return nil
+}
+
+// Gather discovers new .NET processes and starts streaming their counters,
+// the metrics are added asynchronously as the runtime reports them
+func (d *DotNet) Gather(telegraf.Accumulator) error {
+	found, err := endpoints(d.SocketDirectory)
+	if err != nil {
+		return fmt.Errorf("discovering diagnostic endpoints failed: %w", err)
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	// Remove finished sessions to reconnect to processes still running
+	for pid, s := range d.sessions {
+		s.Lock()
+		done := s.done
+		s.Unlock()
+		if done {
+			delete(d.sessions, pid)
+		}
+	}
+
+	for pid, endpoint := range found {
+		if _, exists := d.sessions[pid]; exists {
+			continue
+		}
+
+		proc, err := process.NewProcess(pid)
+		if err != nil {
+			// The socket might be a leftover of a terminated process
+			d.Log.Tracef("Skipping process %d: %v", pid, err)
+			continue
+		}
+		name, err := proc.Name()
+		if err != nil {
+			d.Log.Debugf("Getting name of process %d failed: %v", pid, err)
+			continue
+		}
+		if d.filter != nil && !d.filter.Match(name) {
+			continue
+		}
+
+		s := &session{pid: pid, name: name, endpoint: endpoint}
+		d.sessions[pid] = s
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			if err := d.stream(s); err != nil && d.ctx.Err() == nil {
+				d.acc.AddError(fmt.Errorf("process %q (%d): %w", s.name, s.pid, err))
+			}
+			s.Lock()
+			s.done = true
+			s.Unlock()
+		}()
+	}
+
+	return nil
+}
+
+func (d *DotNet) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	d.Lock()
+	for _, s := range d.sessions {
+		s.Lock()
+		if s.conn != nil && !s.done {
+			if err := stopSession(s.endpoint, s.id); err != nil {
+				d.Log.Debugf("Stopping session of process %d failed: %v", s.pid, err)
+			}
+			s.conn.Close()
+		}
+		s.Unlock()
+	}
+	d.Unlock()
+
+	d.wg.Wait()
+}
+
+// stream starts an EventPipe session for the counter providers and processes
+// the events until the session ends
+func (d *DotNet) stream(s *session) error {
+	conn, err := dial(d.ctx, s.endpoint)
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", s.endpoint, err)
+	}
+	defer conn.Close()
+
+	args := "EventCounterIntervalSec=" + strconv.Itoa(int(time.Duration(d.CounterInterval).Seconds()))
+	providers := make([]provider, 0, len(d.Providers))
+	for _, name := range d.Providers {
+		providers = append(providers, provider{name: name, keywords: 0xffffffff, level: 5, filter: args})
+	}
+	if _, err := conn.Write(collectTracingRequest(providers)); err != nil {
+		return fmt.Errorf("requesting session failed: %w", err)
+	}
+	id, err := readSessionResponse(conn)
+	if err != nil {
+		return fmt.Errorf("starting session failed: %w", err)
+	}
+
+	// Register the connection for Stop to end the session
+	s.Lock()
+	if d.ctx.Err() != nil {
+		s.Unlock()
+		return stopSession(s.endpoint, id)
+	}
+	s.conn = conn
+	s.id = id
+	s.Unlock()
+	d.Log.Debugf("Started session %d for process %q (%d)", id, s.name, s.pid)
+
+	reader := newTraceReader(conn, func(meta *eventMetadata, payload []byte) {
+		d.handleEvent(s, meta, payload)
+	})
+	if err := reader.readHeader(); err != nil {
+		return fmt.Errorf("reading trace header failed: %w", err)
+	}
+	for {
+		if err := reader.next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("reading trace failed: %w", err)
+		}
+	}
+}
+
+func (d *DotNet) handleEvent(s *session, meta *eventMetadata, payload []byte) {
+	if meta.name != "EventCounters" {
+		return
+	}
+
+	values, err := decodePayload(meta.fields, payload)
+	if err != nil {
+		d.Log.Errorf("Decoding counter of process %d failed: %v", s.pid, err)
+		return
+	}
+	counter, ok := values["Payload"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	name, ok := counter["Name"].(string)
+	if !ok || name == "" {
+		return
+	}
+	tags := map[string]string{
+		"process":  s.name,
+		"pid":      strconv.Itoa(int(s.pid)),
+		"provider": meta.provider,
+		"counter":  name,
+	}
+	if unit, ok := counter["DisplayUnits"].(string); ok && unit != "" {
+		tags["unit"] = unit
+	}
+
+	fields := make(map[string]interface{})
+	switch counter["CounterType"] {
+	case "Sum":
+		if v, ok := counter["Increment"]; ok {
+			fields["increment"] = v
+		}
+	default:
+		for key, field := range map[string]string{"Mean": "mean", "Min": "min", "Max": "max", "Count": "count"} {
+			if v, ok := counter[key]; ok {
+				fields[field] = v
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	d.acc.AddFields("dotnet", fields, tags)
+}
+
+// stopSession ends the EventPipe session using a separate connection as
+// the runtime only accepts one command per connection
+func stopSession(endpoint string, id uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := dial(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if c, ok := conn.(interface{ SetDeadline(time.Time) error }); ok {
+		if err := c.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := conn.Write(stopTracingRequest(id)); err != nil {
+		return err
+	}
+	_, err = readSessionResponse(conn)
+	return err
+}
+
+func init() {
+	inputs.Add("dotnet", func() telegraf.Input {
+		return &DotNet{}
+	})
+}
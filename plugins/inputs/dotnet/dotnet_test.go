@@ -0,0 +1,442 @@
+package dotnet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInit(t *testing.T) {
+	plugin := &DotNet{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{"System.Runtime"}, plugin.Providers)
+	require.Equal(t, config.Duration(10*time.Second), plugin.CounterInterval)
+
+	plugin = &DotNet{
+		CounterInterval: config.Duration(500 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "counter_interval must be at least one second")
+}
+
+func TestCollectTracingRequest(t *testing.T) {
+	msg := collectTracingRequest([]provider{{name: "A", keywords: 0xffffffff, level: 5, filter: "x=1"}})
+
+	expected := []byte("DOTNET_IPC_V1\x00")
+	expected = append(expected,
+		0x41, 0x00, // size
+		0x02, 0x03, // EventPipe CollectTracing2
+		0x00, 0x00, // reserved
+		0x10, 0x00, 0x00, 0x00, // circular buffer size
+		0x01, 0x00, 0x00, 0x00, // NetTrace format
+		0x00,                   // no rundown
+		0x01, 0x00, 0x00, 0x00, // provider count
+		0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00, // keywords
+		0x05, 0x00, 0x00, 0x00, // level
+		0x02, 0x00, 0x00, 0x00, 'A', 0x00, 0x00, 0x00, // provider name
+		0x04, 0x00, 0x00, 0x00, 'x', 0x00, '=', 0x00, '1', 0x00, 0x00, 0x00, // filter
+	)
+	require.Equal(t, expected, msg)
+}
+
+func TestReadSessionResponse(t *testing.T) {
+	payload := make([]byte, 8)
+	binary.LittleEndian.PutUint64(payload, 42)
+	id, err := readSessionResponse(bytes.NewReader(ipcMessage(commandSetServer, responseOK, payload)))
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), id)
+
+	payload = []byte{0x04, 0x14, 0x13, 0x80}
+	_, err = readSessionResponse(bytes.NewReader(ipcMessage(commandSetServer, responseError, payload)))
+	require.ErrorContains(t, err, "runtime returned error 0x80131404")
+
+	msg := ipcMessage(commandSetServer, responseOK, make([]byte, 8))
+	msg[0] = 'X'
+	_, err = readSessionResponse(bytes.NewReader(msg))
+	require.ErrorContains(t, err, "invalid response magic")
+
+	_, err = readSessionResponse(bytes.NewReader(msg[:10]))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestTraceReader(t *testing.T) {
+	type event struct {
+		provider string
+		name     string
+		values   map[string]interface{}
+	}
+	var events []event
+
+	reader := newTraceReader(bytes.NewReader(counterTrace()), func(meta *eventMetadata, payload []byte) {
+		values, err := decodePayload(meta.fields, payload)
+		require.NoError(t, err)
+		events = append(events, event{provider: meta.provider, name: meta.name, values: values})
+	})
+	require.NoError(t, reader.readHeader())
+	for {
+		err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	require.Len(t, events, 3)
+	require.Equal(t, "System.Runtime", events[0].provider)
+	require.Equal(t, "EventCounters", events[0].name)
+	require.Equal(t, map[string]interface{}{
+		"Payload": map[string]interface{}{
+			"Name":              "cpu-usage",
+			"DisplayName":       "CPU Usage",
+			"Mean":              float64(2.5),
+			"StandardDeviation": float64(0),
+			"Count":             int64(1),
+			"Min":               float64(2.5),
+			"Max":               float64(2.5),
+			"IntervalSec":       float64(1),
+			"Series":            "Interval=1000",
+			"CounterType":       "Mean",
+			"Metadata":          "",
+			"DisplayUnits":      "%",
+		},
+	}, events[0].values)
+	require.Equal(t, "Started", events[2].name)
+	require.Equal(t, map[string]interface{}{"Id": uint64(7)}, events[2].values)
+}
+
+func TestTraceReaderInvalid(t *testing.T) {
+	reader := newTraceReader(bytes.NewReader([]byte("Nettrace\x05\x00\x00\x00hello")), nil)
+	require.ErrorContains(t, reader.readHeader(), "invalid serialization header length 5")
+
+	// Truncate the stream within the first block
+	trace := counterTrace()
+	reader = newTraceReader(bytes.NewReader(trace[:120]), func(*eventMetadata, []byte) {})
+	require.NoError(t, reader.readHeader())
+	require.NoError(t, reader.next())
+	require.ErrorIs(t, reader.next(), io.ErrUnexpectedEOF)
+}
+
+func TestStream(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows as it uses unix sockets")
+	}
+
+	// Serve a fake diagnostic endpoint for the test process
+	pid := os.Getpid()
+	proc, err := process.NewProcess(int32(pid))
+	require.NoError(t, err)
+	name, err := proc.Name()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	listener, err := net.Listen("unix", filepath.Join(dir, fmt.Sprintf("dotnet-diagnostic-%d-12345-socket", pid)))
+	require.NoError(t, err)
+	defer listener.Close()
+
+	requests := make(chan []byte, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveDiagnostics(t, conn, requests)
+		}
+	}()
+
+	plugin := &DotNet{
+		ProcessNames:    []string{name},
+		CounterInterval: config.Duration(time.Second),
+		SocketDirectory: dir,
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+
+	request := <-requests
+	require.Equal(t, byte(commandCollectTracing), request[17])
+	require.Contains(t, string(request), string(utf16Bytes("EventCounterIntervalSec=1")))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"dotnet",
+			map[string]string{
+				"process":  name,
+				"pid":      strconv.Itoa(pid),
+				"provider": "System.Runtime",
+				"counter":  "cpu-usage",
+				"unit":     "%",
+			},
+			map[string]interface{}{
+				"mean":  float64(2.5),
+				"min":   float64(2.5),
+				"max":   float64(2.5),
+				"count": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"dotnet",
+			map[string]string{
+				"process":  name,
+				"pid":      strconv.Itoa(pid),
+				"provider": "System.Runtime",
+				"counter":  "exception-count",
+			},
+			map[string]interface{}{"increment": float64(3)},
+			time.Unix(0, 0),
+		),
+	}
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 3*time.Second, 50*time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Stopping the plugin must end the session in the runtime
+	plugin.Stop()
+	request = <-requests
+	require.Equal(t, byte(commandStopTracing), request[17])
+	require.Equal(t, uint64(42), binary.LittleEndian.Uint64(request[ipcHeaderSize:]))
+	require.Empty(t, acc.Errors)
+}
+
+// serveDiagnostics mimics the diagnostic server of the runtime by accepting
+// a tracing request and streaming the counter trace
+func serveDiagnostics(t *testing.T, conn net.Conn, requests chan []byte) {
+	defer conn.Close()
+
+	header := make([]byte, ipcHeaderSize)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		t.Error(err)
+		return
+	}
+	request := make([]byte, binary.LittleEndian.Uint16(header[14:]))
+	copy(request, header)
+	if _, err := io.ReadFull(conn, request[ipcHeaderSize:]); err != nil {
+		t.Error(err)
+		return
+	}
+	requests <- request
+
+	response := make([]byte, 8)
+	binary.LittleEndian.PutUint64(response, 42)
+	if _, err := conn.Write(ipcMessage(commandSetServer, responseOK, response)); err != nil {
+		t.Error(err)
+		return
+	}
+	if request[17] != commandCollectTracing {
+		return
+	}
+
+	// Keep the stream open until the session is stopped
+	trace := counterTrace()
+	if _, err := conn.Write(trace[:len(trace)-1]); err != nil {
+		t.Error(err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, conn)
+}
+
+// counterTrace creates a NetTrace stream with two counter events and an
+// unrelated event
+func counterTrace() []byte {
+	meanFields := []field{
+		{name: "Name", typeCode: typeString},
+		{name: "DisplayName", typeCode: typeString},
+		{name: "Mean", typeCode: typeDouble},
+		{name: "StandardDeviation", typeCode: typeDouble},
+		{name: "Count", typeCode: typeInt32},
+		{name: "Min", typeCode: typeDouble},
+		{name: "Max", typeCode: typeDouble},
+		{name: "IntervalSec", typeCode: typeSingle},
+		{name: "Series", typeCode: typeString},
+		{name: "CounterType", typeCode: typeString},
+		{name: "Metadata", typeCode: typeString},
+		{name: "DisplayUnits", typeCode: typeString},
+	}
+	sumFields := []field{
+		{name: "Name", typeCode: typeString},
+		{name: "DisplayName", typeCode: typeString},
+		{name: "DisplayRateTimeScale", typeCode: typeString},
+		{name: "Increment", typeCode: typeDouble},
+		{name: "IntervalSec", typeCode: typeSingle},
+		{name: "Metadata", typeCode: typeString},
+		{name: "Series", typeCode: typeString},
+		{name: "CounterType", typeCode: typeString},
+		{name: "DisplayUnits", typeCode: typeString},
+	}
+
+	metadata := [][]byte{
+		metadataPayload(1, "System.Runtime", "EventCounters", []field{
+			{name: "Payload", typeCode: typeObject, fields: meanFields},
+		}),
+		metadataPayload(2, "System.Runtime", "EventCounters", []field{
+			{name: "Payload", typeCode: typeObject, fields: sumFields},
+		}),
+		metadataPayload(3, "Other", "Started", []field{{name: "Id", typeCode: typeUInt32}}),
+	}
+
+	var mean, sum bytes.Buffer
+	mean.Write(utf16Bytes("cpu-usage"))
+	mean.Write(utf16Bytes("CPU Usage"))
+	writeFloat64(&mean, 2.5)
+	writeFloat64(&mean, 0)
+	writeUint32(&mean, 1)
+	writeFloat64(&mean, 2.5)
+	writeFloat64(&mean, 2.5)
+	writeUint32(&mean, math.Float32bits(1))
+	mean.Write(utf16Bytes("Interval=1000"))
+	mean.Write(utf16Bytes("Mean"))
+	mean.Write(utf16Bytes(""))
+	mean.Write(utf16Bytes("%"))
+
+	sum.Write(utf16Bytes("exception-count"))
+	sum.Write(utf16Bytes("Exception Count"))
+	sum.Write(utf16Bytes("00:00:01"))
+	writeFloat64(&sum, 3)
+	writeUint32(&sum, math.Float32bits(1))
+	sum.Write(utf16Bytes(""))
+	sum.Write(utf16Bytes("Interval=1000"))
+	sum.Write(utf16Bytes("Sum"))
+	sum.Write(utf16Bytes(""))
+
+	var started bytes.Buffer
+	writeUint32(&started, 7)
+
+	var buf bytes.Buffer
+	buf.WriteString(netTraceMagic)
+	writeUint32(&buf, uint32(len(serializationHeader)))
+	buf.WriteString(serializationHeader)
+
+	buf.WriteByte(tagBeginPrivateObject)
+	writeType(&buf, "Trace")
+	buf.Write(make([]byte, 48))
+	buf.WriteByte(tagEndObject)
+
+	metaEvents := make([]compressedEvent, 0, len(metadata))
+	for _, payload := range metadata {
+		metaEvents = append(metaEvents, compressedEvent{metadataID: 0, payload: payload})
+	}
+	writeBlock(&buf, "MetadataBlock", compressedBlock(metaEvents))
+	writeBlock(&buf, "StackBlock", make([]byte, 8))
+	writeBlock(&buf, "EventBlock", compressedBlock([]compressedEvent{
+		{metadataID: 1, payload: mean.Bytes()},
+		{metadataID: 2, payload: sum.Bytes()},
+		{metadataID: 3, payload: started.Bytes()},
+		{metadataID: 4, payload: []byte{0x01}}, // unknown metadata is ignored
+	}))
+	buf.WriteByte(tagNullReference)
+
+	return buf.Bytes()
+}
+
+type compressedEvent struct {
+	metadataID uint64
+	payload    []byte
+}
+
+func compressedBlock(events []compressedEvent) []byte {
+	var buf bytes.Buffer
+	var header [20]byte
+	binary.LittleEndian.PutUint16(header[0:], 20)
+	binary.LittleEndian.PutUint16(header[2:], 1)
+	buf.Write(header[:])
+
+	for i, e := range events {
+		flags := byte(flagMetadataID | flagDataLength)
+		if i == 0 {
+			flags |= flagCaptureThreadAndSequence | flagThreadID
+		}
+		buf.WriteByte(flags)
+		buf.Write(binary.AppendUvarint(nil, e.metadataID))
+		if i == 0 {
+			buf.Write(binary.AppendUvarint(nil, 1))   // sequence number
+			buf.Write(binary.AppendUvarint(nil, 300)) // capture thread
+			buf.Write(binary.AppendUvarint(nil, 0))   // processor number
+			buf.Write(binary.AppendUvarint(nil, 300)) // thread
+		}
+		buf.Write(binary.AppendUvarint(nil, 1000)) // timestamp delta
+		buf.Write(binary.AppendUvarint(nil, uint64(len(e.payload))))
+		buf.Write(e.payload)
+	}
+	return buf.Bytes()
+}
+
+func writeBlock(buf *bytes.Buffer, name string, block []byte) {
+	buf.WriteByte(tagBeginPrivateObject)
+	writeType(buf, name)
+	writeUint32(buf, uint32(len(block)))
+	for buf.Len()%4 != 0 {
+		buf.WriteByte(0)
+	}
+	buf.Write(block)
+	buf.WriteByte(tagEndObject)
+}
+
+func writeType(buf *bytes.Buffer, name string) {
+	buf.WriteByte(tagBeginPrivateObject)
+	buf.WriteByte(tagNullReference)
+	writeUint32(buf, 4)
+	writeUint32(buf, 4)
+	writeUint32(buf, uint32(len(name)))
+	buf.WriteString(name)
+	buf.WriteByte(tagEndObject)
+}
+
+func metadataPayload(id uint32, providerName, eventName string, fields []field) []byte {
+	var buf bytes.Buffer
+	writeUint32(&buf, id)
+	buf.Write(utf16Bytes(providerName))
+	writeUint32(&buf, 0)
+	buf.Write(utf16Bytes(eventName))
+	buf.Write(make([]byte, 16))
+	writeFields(&buf, fields)
+	return buf.Bytes()
+}
+
+func writeFields(buf *bytes.Buffer, fields []field) {
+	writeUint32(buf, uint32(len(fields)))
+	for _, f := range fields {
+		writeUint32(buf, uint32(f.typeCode))
+		if f.typeCode == typeObject {
+			writeFields(buf, f.fields)
+		}
+		buf.Write(utf16Bytes(f.name))
+	}
+}
+
+func writeFloat64(buf *bytes.Buffer, v float64) {
+	writeUint64(buf, math.Float64bits(v))
+}
+
+// utf16Bytes encodes the string as null-terminated UTF-16
+func utf16Bytes(s string) []byte {
+	var buf bytes.Buffer
+	writeString(&buf, s)
+	if s == "" {
+		return []byte{0, 0}
+	}
+	return buf.Bytes()[4:]
+}
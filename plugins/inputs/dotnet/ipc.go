@@ -0,0 +1,135 @@
+package dotnet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+)
+
+// Diagnostics IPC protocol of the .NET runtime, see
+// https://github.com/dotnet/diagnostics/blob/main/documentation/design-docs/ipc-protocol.md
+const (
+	ipcMagic      = "DOTNET_IPC_V1\x00"
+	ipcHeaderSize = 20
+
+	commandSetEventPipe = 0x02
+	commandSetServer    = 0xff
+
+	commandStopTracing    = 0x01
+	commandCollectTracing = 0x03 // CollectTracing2 supporting to disable rundown events
+
+	responseOK    = 0x00
+	responseError = 0xff
+
+	formatNetTrace = 1
+
+	// Buffer size of the tracing session in the target process
+	circularBufferMB = 16
+)
+
+type provider struct {
+	name     string
+	keywords uint64
+	level    uint32
+	filter   string
+}
+
+// collectTracingRequest creates the request for starting an EventPipe session
+// streaming the events of the given providers in the NetTrace format
+func collectTracingRequest(providers []provider) []byte {
+	var payload bytes.Buffer
+	writeUint32(&payload, circularBufferMB)
+	writeUint32(&payload, formatNetTrace)
+	payload.WriteByte(0) // no rundown events
+	writeUint32(&payload, uint32(len(providers)))
+	for _, p := range providers {
+		writeUint64(&payload, p.keywords)
+		writeUint32(&payload, p.level)
+		writeString(&payload, p.name)
+		writeString(&payload, p.filter)
+	}
+	return ipcMessage(commandSetEventPipe, commandCollectTracing, payload.Bytes())
+}
+
+func stopTracingRequest(session uint64) []byte {
+	var payload bytes.Buffer
+	writeUint64(&payload, session)
+	return ipcMessage(commandSetEventPipe, commandStopTracing, payload.Bytes())
+}
+
+func ipcMessage(commandSet, command uint8, payload []byte) []byte {
+	msg := make([]byte, ipcHeaderSize, ipcHeaderSize+len(payload))
+	copy(msg, ipcMagic)
+	binary.LittleEndian.PutUint16(msg[14:], uint16(ipcHeaderSize+len(payload)))
+	msg[16] = commandSet
+	msg[17] = command
+	return append(msg, payload...)
+}
+
+// readSessionResponse reads the response to a tracing request returning the
+// session ID
+func readSessionResponse(r io.Reader) (uint64, error) {
+	header := make([]byte, ipcHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, fmt.Errorf("reading response header failed: %w", err)
+	}
+	if string(header[:14]) != ipcMagic {
+		return 0, errors.New("invalid response magic")
+	}
+	size := binary.LittleEndian.Uint16(header[14:])
+	if size < ipcHeaderSize {
+		return 0, fmt.Errorf("invalid response size %d", size)
+	}
+	payload := make([]byte, size-ipcHeaderSize)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, fmt.Errorf("reading response payload failed: %w", err)
+	}
+
+	if header[16] != commandSetServer {
+		return 0, fmt.Errorf("unexpected response command set 0x%02x", header[16])
+	}
+	switch header[17] {
+	case responseOK:
+		if len(payload) < 8 {
+			return 0, errors.New("response payload too short")
+		}
+		return binary.LittleEndian.Uint64(payload), nil
+	case responseError:
+		if len(payload) < 4 {
+			return 0, errors.New("error response payload too short")
+		}
+		return 0, fmt.Errorf("runtime returned error 0x%08x", binary.LittleEndian.Uint32(payload))
+	}
+	return 0, fmt.Errorf("unexpected response command 0x%02x", header[17])
+}
+
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+func writeUint64(buf *bytes.Buffer, v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	buf.Write(b[:])
+}
+
+// writeString writes a string as length-prefixed, null-terminated UTF-16
+// where the length is given in characters including the terminator. Empty
+// strings are written with a zero length.
+func writeString(buf *bytes.Buffer, s string) {
+	if s == "" {
+		writeUint32(buf, 0)
+		return
+	}
+	chars := append(utf16.Encode([]rune(s)), 0)
+	writeUint32(buf, uint32(len(chars)))
+	for _, c := range chars {
+		buf.WriteByte(byte(c))
+		buf.WriteByte(byte(c >> 8))
+	}
+}
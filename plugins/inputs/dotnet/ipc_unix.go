@@ -0,0 +1,56 @@
+//go:build !windows
+
+package dotnet
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// endpoints returns the diagnostic sockets of the .NET processes found in the
+// given directory keyed by the process ID. The sockets are named
+// "dotnet-diagnostic-<pid>-<disambiguation key>-socket".
+func endpoints(dir string) (map[int32]string, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "dotnet-diagnostic-*-*-socket"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[int32]uint64, len(matches))
+	found := make(map[int32]string, len(matches))
+	for _, match := range matches {
+		parts := strings.Split(filepath.Base(match), "-")
+		if len(parts) != 5 {
+			continue
+		}
+		pid, err := strconv.ParseInt(parts[2], 10, 32)
+		if err != nil {
+			continue
+		}
+		key, err := strconv.ParseUint(parts[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		// Stale sockets of a previous process with the same ID might be
+		// left over, use the most recent one
+		if current, exists := keys[int32(pid)]; exists && current > key {
+			continue
+		}
+		keys[int32(pid)] = key
+		found[int32(pid)] = match
+	}
+	return found, nil
+}
+
+func dial(ctx context.Context, endpoint string) (io.ReadWriteCloser, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "unix", endpoint)
+}
@@ -0,0 +1,40 @@
+//go:build windows
+
+package dotnet
+
+import (
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const pipePrefix = `\\.\pipe\`
+
+// endpoints returns the diagnostic named pipes of the .NET processes keyed by
+// the process ID. The pipes are named "dotnet-diagnostic-<pid>".
+func endpoints(string) (map[int32]string, error) {
+	entries, err := os.ReadDir(pipePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int32]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, "dotnet-diagnostic-") {
+			continue
+		}
+		pid, err := strconv.ParseInt(strings.TrimPrefix(name, "dotnet-diagnostic-"), 10, 32)
+		if err != nil {
+			continue
+		}
+		found[int32(pid)] = pipePrefix + name
+	}
+	return found, nil
+}
+
+func dial(_ context.Context, endpoint string) (io.ReadWriteCloser, error) {
+	return os.OpenFile(endpoint, os.O_RDWR, 0)
+}
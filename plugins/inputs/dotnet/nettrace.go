@@ -0,0 +1,582 @@
+package dotnet
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf16"
+)
+
+// Parser for the NetTrace format streamed by EventPipe sessions, see
+// https://github.com/microsoft/perfview/blob/main/src/TraceEvent/EventPipe/EventPipeFormat.md
+const (
+	netTraceMagic       = "Nettrace"
+	serializationHeader = "!FastSerialization.1"
+
+	tagNullReference      = 1
+	tagBeginPrivateObject = 5
+	tagEndObject          = 6
+
+	// Maximum size of a block to protect against corrupted streams
+	maxBlockSize = 64 * 1024 * 1024
+)
+
+// Flags of compressed event headers
+const (
+	flagMetadataID = 1 << iota
+	flagCaptureThreadAndSequence
+	flagThreadID
+	flagStackID
+	flagActivityID
+	flagRelatedActivityID
+	flagSorted
+	flagDataLength
+)
+
+// Type codes of event fields
+const (
+	typeObject   = 1
+	typeBoolean  = 3
+	typeChar     = 4
+	typeSByte    = 5
+	typeByte     = 6
+	typeInt16    = 7
+	typeUInt16   = 8
+	typeInt32    = 9
+	typeUInt32   = 10
+	typeInt64    = 11
+	typeUInt64   = 12
+	typeSingle   = 13
+	typeDouble   = 14
+	typeDecimal  = 15
+	typeDateTime = 16
+	typeGUID     = 17
+	typeString   = 18
+)
+
+type eventMetadata struct {
+	provider string
+	id       int32
+	name     string
+	fields   []field
+}
+
+type field struct {
+	name     string
+	typeCode int32
+	fields   []field
+}
+
+type eventHandler func(meta *eventMetadata, payload []byte)
+
+// traceReader reads the objects of a NetTrace stream and hands all events to
+// the handler
+type traceReader struct {
+	r        *bufio.Reader
+	offset   int64
+	metadata map[int32]*eventMetadata
+	handler  eventHandler
+}
+
+func newTraceReader(r io.Reader, handler eventHandler) *traceReader {
+	return &traceReader{
+		r:        bufio.NewReader(r),
+		metadata: make(map[int32]*eventMetadata),
+		handler:  handler,
+	}
+}
+
+func (t *traceReader) read(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(t.r, buf); err != nil {
+		return nil, err
+	}
+	t.offset += int64(n)
+	return buf, nil
+}
+
+func (t *traceReader) readInt32() (int32, error) {
+	buf, err := t.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(buf)), nil
+}
+
+func (t *traceReader) expectTag(expected byte) error {
+	buf, err := t.read(1)
+	if err != nil {
+		return err
+	}
+	if buf[0] != expected {
+		return fmt.Errorf("unexpected tag %d at offset %d, expected %d", buf[0], t.offset-1, expected)
+	}
+	return nil
+}
+
+// readHeader reads the magic and the serialization header of the stream
+func (t *traceReader) readHeader() error {
+	magic, err := t.read(len(netTraceMagic))
+	if err != nil {
+		return err
+	}
+	if string(magic) != netTraceMagic {
+		return errors.New("invalid NetTrace magic")
+	}
+
+	length, err := t.readInt32()
+	if err != nil {
+		return err
+	}
+	if length != int32(len(serializationHeader)) {
+		return fmt.Errorf("invalid serialization header length %d", length)
+	}
+	header, err := t.read(int(length))
+	if err != nil {
+		return err
+	}
+	if string(header) != serializationHeader {
+		return fmt.Errorf("invalid serialization header %q", string(header))
+	}
+	return nil
+}
+
+// next processes the next object of the stream and returns io.EOF at the end
+// of the stream
+func (t *traceReader) next() error {
+	tag, err := t.read(1)
+	if err != nil {
+		return err
+	}
+	switch tag[0] {
+	case tagNullReference:
+		return io.EOF
+	case tagBeginPrivateObject:
+	default:
+		return fmt.Errorf("unexpected tag %d at offset %d", tag[0], t.offset-1)
+	}
+
+	name, err := t.readType()
+	if err != nil {
+		return fmt.Errorf("reading object type failed: %w", err)
+	}
+
+	switch name {
+	case "Trace":
+		// Start time, clock information and process information
+		if _, err := t.read(48); err != nil {
+			return err
+		}
+	case "EventBlock", "MetadataBlock", "StackBlock", "SPBlock":
+		size, err := t.readInt32()
+		if err != nil {
+			return err
+		}
+		if size < 0 || size > maxBlockSize {
+			return fmt.Errorf("invalid size %d of %s", size, name)
+		}
+		// Blocks are aligned to four bytes relative to the stream start
+		if padding := (4 - t.offset%4) % 4; padding > 0 {
+			if _, err := t.read(int(padding)); err != nil {
+				return err
+			}
+		}
+		block, err := t.read(int(size))
+		if err != nil {
+			return err
+		}
+		switch name {
+		case "EventBlock":
+			if err := t.parseEventBlock(block, false); err != nil {
+				return fmt.Errorf("parsing event block failed: %w", err)
+			}
+		case "MetadataBlock":
+			if err := t.parseEventBlock(block, true); err != nil {
+				return fmt.Errorf("parsing metadata block failed: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown object type %q", name)
+	}
+
+	return t.expectTag(tagEndObject)
+}
+
+func (t *traceReader) readType() (string, error) {
+	if err := t.expectTag(tagBeginPrivateObject); err != nil {
+		return "", err
+	}
+	if err := t.expectTag(tagNullReference); err != nil {
+		return "", err
+	}
+	// Version and minimum reader version
+	if _, err := t.read(8); err != nil {
+		return "", err
+	}
+	length, err := t.readInt32()
+	if err != nil {
+		return "", err
+	}
+	if length < 0 || length > 256 {
+		return "", fmt.Errorf("invalid type name length %d", length)
+	}
+	name, err := t.read(int(length))
+	if err != nil {
+		return "", err
+	}
+	if err := t.expectTag(tagEndObject); err != nil {
+		return "", err
+	}
+	return string(name), nil
+}
+
+// eventHeader holds the header of the current event; in compressed blocks
+// the fields not contained in an event are taken from the previous event
+type eventHeader struct {
+	metadataID  int32
+	payloadSize int
+}
+
+func (t *traceReader) parseEventBlock(block []byte, metadata bool) error {
+	if len(block) < 4 {
+		return errors.New("block too short")
+	}
+	headerSize := int(binary.LittleEndian.Uint16(block))
+	flags := binary.LittleEndian.Uint16(block[2:])
+	if headerSize < 4 || headerSize > len(block) {
+		return fmt.Errorf("invalid block header size %d", headerSize)
+	}
+	compressed := flags&1 != 0
+
+	var header eventHeader
+	b := &buffer{data: block, pos: headerSize}
+	for b.pos < len(b.data) {
+		var err error
+		if compressed {
+			err = b.readCompressedHeader(&header)
+		} else {
+			err = b.readHeader(&header)
+		}
+		if err != nil {
+			return err
+		}
+
+		start := b.pos
+		payload, err := b.read(header.payloadSize)
+		if err != nil {
+			return err
+		}
+		if !compressed {
+			// Uncompressed events are aligned to four bytes
+			b.pos = start + (header.payloadSize+3)&^3
+		}
+
+		if metadata {
+			id, meta, err := parseMetadata(payload)
+			if err != nil {
+				return err
+			}
+			t.metadata[id] = meta
+			continue
+		}
+
+		if meta, found := t.metadata[header.metadataID]; found {
+			t.handler(meta, payload)
+		}
+	}
+	return nil
+}
+
+func (b *buffer) readCompressedHeader(header *eventHeader) error {
+	flags, err := b.readByte()
+	if err != nil {
+		return err
+	}
+	if flags&flagMetadataID != 0 {
+		id, err := b.readVarUint()
+		if err != nil {
+			return err
+		}
+		header.metadataID = int32(id)
+	}
+	if flags&flagCaptureThreadAndSequence != 0 {
+		// Sequence number delta, capture thread and processor number
+		for i := 0; i < 3; i++ {
+			if _, err := b.readVarUint(); err != nil {
+				return err
+			}
+		}
+	}
+	if flags&flagThreadID != 0 {
+		if _, err := b.readVarUint(); err != nil {
+			return err
+		}
+	}
+	if flags&flagStackID != 0 {
+		if _, err := b.readVarUint(); err != nil {
+			return err
+		}
+	}
+	// Timestamp delta
+	if _, err := b.readVarUint(); err != nil {
+		return err
+	}
+	if flags&flagActivityID != 0 {
+		if _, err := b.read(16); err != nil {
+			return err
+		}
+	}
+	if flags&flagRelatedActivityID != 0 {
+		if _, err := b.read(16); err != nil {
+			return err
+		}
+	}
+	if flags&flagDataLength != 0 {
+		size, err := b.readVarUint()
+		if err != nil {
+			return err
+		}
+		header.payloadSize = int(size)
+	}
+	return nil
+}
+
+func (b *buffer) readHeader(header *eventHeader) error {
+	// Event size, metadata ID with the sorted flag in the highest bit,
+	// sequence number, thread ID, capture thread ID, processor number,
+	// stack ID, timestamp, activity ID, related activity ID and payload size
+	buf, err := b.read(80)
+	if err != nil {
+		return err
+	}
+	header.metadataID = int32(binary.LittleEndian.Uint32(buf[4:]) & 0x7fffffff)
+	header.payloadSize = int(binary.LittleEndian.Uint32(buf[76:]))
+	return nil
+}
+
+// parseMetadata decodes the payload of a metadata event returning the
+// metadata ID referenced by the events
+func parseMetadata(payload []byte) (int32, *eventMetadata, error) {
+	b := &buffer{data: payload}
+
+	id, err := b.readInt32()
+	if err != nil {
+		return 0, nil, err
+	}
+	providerName, err := b.readString()
+	if err != nil {
+		return 0, nil, err
+	}
+	eventID, err := b.readInt32()
+	if err != nil {
+		return 0, nil, err
+	}
+	eventName, err := b.readString()
+	if err != nil {
+		return 0, nil, err
+	}
+	// Keywords, version and level
+	if _, err := b.read(16); err != nil {
+		return 0, nil, err
+	}
+	meta := &eventMetadata{provider: providerName, id: eventID, name: eventName}
+
+	// Events without fields might omit the field count
+	if b.pos == len(b.data) {
+		return id, meta, nil
+	}
+	count, err := b.readInt32()
+	if err != nil {
+		return 0, nil, err
+	}
+	meta.fields, err = b.readFields(count, 0)
+	if err != nil {
+		return 0, nil, fmt.Errorf("parsing fields of %s/%s failed: %w", providerName, eventName, err)
+	}
+	return id, meta, nil
+}
+
+func (b *buffer) readFields(count int32, depth int) ([]field, error) {
+	if count < 0 || int(count) > len(b.data)-b.pos {
+		return nil, fmt.Errorf("invalid field count %d", count)
+	}
+	if depth > 8 {
+		return nil, errors.New("fields nested too deeply")
+	}
+
+	fields := make([]field, 0, count)
+	for i := int32(0); i < count; i++ {
+		var f field
+		var err error
+		if f.typeCode, err = b.readInt32(); err != nil {
+			return nil, err
+		}
+		if f.typeCode == typeObject {
+			n, err := b.readInt32()
+			if err != nil {
+				return nil, err
+			}
+			if f.fields, err = b.readFields(n, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		if f.name, err = b.readString(); err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// decodePayload decodes the event payload according to the field definitions
+// of the metadata
+func decodePayload(fields []field, payload []byte) (map[string]interface{}, error) {
+	b := &buffer{data: payload}
+	return b.decodeFields(fields)
+}
+
+func (b *buffer) decodeFields(fields []field) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		v, err := b.decodeValue(f)
+		if err != nil {
+			return nil, fmt.Errorf("decoding field %q failed: %w", f.name, err)
+		}
+		values[f.name] = v
+	}
+	return values, nil
+}
+
+func (b *buffer) decodeValue(f field) (interface{}, error) {
+	switch f.typeCode {
+	case typeObject:
+		return b.decodeFields(f.fields)
+	case typeString:
+		return b.readString()
+	case typeBoolean:
+		v, err := b.readInt32()
+		return v != 0, err
+	case typeSByte:
+		buf, err := b.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return int64(int8(buf[0])), nil
+	case typeByte:
+		buf, err := b.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return uint64(buf[0]), nil
+	case typeInt16:
+		buf, err := b.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return int64(int16(binary.LittleEndian.Uint16(buf))), nil
+	case typeChar, typeUInt16:
+		buf, err := b.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return uint64(binary.LittleEndian.Uint16(buf)), nil
+	case typeInt32:
+		v, err := b.readInt32()
+		return int64(v), err
+	case typeUInt32:
+		buf, err := b.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return uint64(binary.LittleEndian.Uint32(buf)), nil
+	case typeInt64, typeDateTime:
+		buf, err := b.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return int64(binary.LittleEndian.Uint64(buf)), nil
+	case typeUInt64:
+		buf, err := b.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.Uint64(buf), nil
+	case typeSingle:
+		buf, err := b.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(buf))), nil
+	case typeDouble:
+		buf, err := b.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(buf)), nil
+	case typeDecimal, typeGUID:
+		// Not used by counters, skip the value
+		_, err := b.read(16)
+		return nil, err
+	}
+	return nil, fmt.Errorf("unsupported type code %d", f.typeCode)
+}
+
+// buffer reads the little-endian encoded values of a block
+type buffer struct {
+	data []byte
+	pos  int
+}
+
+func (b *buffer) read(n int) ([]byte, error) {
+	if n < 0 || n > len(b.data)-b.pos {
+		return nil, io.ErrUnexpectedEOF
+	}
+	buf := b.data[b.pos : b.pos+n]
+	b.pos += n
+	return buf, nil
+}
+
+func (b *buffer) readByte() (byte, error) {
+	buf, err := b.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+func (b *buffer) readInt32() (int32, error) {
+	buf, err := b.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(buf)), nil
+}
+
+// readVarUint reads a LEB128 encoded unsigned integer
+func (b *buffer) readVarUint() (uint64, error) {
+	v, n := binary.Uvarint(b.data[b.pos:])
+	if n <= 0 {
+		return 0, errors.New("invalid variable-length integer")
+	}
+	b.pos += n
+	return v, nil
+}
+
+// readString reads a null-terminated UTF-16 string
+func (b *buffer) readString() (string, error) {
+	var chars []uint16
+	for {
+		buf, err := b.read(2)
+		if err != nil {
+			return "", err
+		}
+		c := binary.LittleEndian.Uint16(buf)
+		if c == 0 {
+			return string(utf16.Decode(chars)), nil
+		}
+		chars = append(chars, c)
+	}
+}
@@ -51,6 +51,10 @@ type Elasticsearch struct {
 	Username                   string            `toml:"username"`
 	Password                   string            `toml:"password"`
 	NumMostRecentIndices       int               `toml:"num_most_recent_indices"`
+	IndexSummary               []string          `toml:"index_summary"`
+	IndexSummaryInclude        []string          `toml:"index_summary_include"`
+	IndexSummaryExclude        []string          `toml:"index_summary_exclude"`
+	IndexSummaryLimit          int               `toml:"index_summary_limit"`
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -60,6 +64,12 @@ type Elasticsearch struct {
 	serverInfo      map[string]serverInfo
 	serverInfoMutex sync.Mutex
 	indexMatchers   map[string]filter.Filter
+
+	summaryFilter       filter.Filter
+	summaryHistory      map[string]summarySample
+	summaryHistoryMutex sync.Mutex
+	summaryLimitWarning sync.Once
+	ilmWarning          sync.Once
 }
 
 type nodeStat struct {
@@ -138,6 +148,70 @@ type indexStat struct {
 	Total     interface{}              `json:"total"`
 	Shards    map[string][]interface{} `json:"shards"`
 }
+
+// summaryStats holds the subset of the index statistics reported in the
+// index summary
+type summaryStats struct {
+	Docs struct {
+		Count int64 `json:"count"`
+	} `json:"docs"`
+	Store struct {
+		SizeInBytes int64 `json:"size_in_bytes"`
+	} `json:"store"`
+	Indexing struct {
+		IndexTotal        int64 `json:"index_total"`
+		IndexTimeInMillis int64 `json:"index_time_in_millis"`
+	} `json:"indexing"`
+	Search struct {
+		QueryTotal        int64 `json:"query_total"`
+		QueryTimeInMillis int64 `json:"query_time_in_millis"`
+	} `json:"search"`
+}
+
+func (s *summaryStats) add(other summaryStats) {
+	s.Docs.Count += other.Docs.Count
+	s.Store.SizeInBytes += other.Store.SizeInBytes
+	s.Indexing.IndexTotal += other.Indexing.IndexTotal
+	s.Indexing.IndexTimeInMillis += other.Indexing.IndexTimeInMillis
+	s.Search.QueryTotal += other.Search.QueryTotal
+	s.Search.QueryTimeInMillis += other.Search.QueryTimeInMillis
+}
+
+type summaryIndex struct {
+	Primaries summaryStats `json:"primaries"`
+	Total     summaryStats `json:"total"`
+}
+
+type ilmExplain struct {
+	Managed bool   `json:"managed"`
+	Policy  string `json:"policy"`
+	Phase   string `json:"phase"`
+}
+
+type dataStream struct {
+	Name    string `json:"name"`
+	Indices []struct {
+		IndexName string `json:"index_name"`
+	} `json:"indices"`
+}
+
+// summaryEntry is a single index or data stream reported in the summary
+type summaryEntry struct {
+	name      string
+	phase     string
+	policy    string
+	indices   int
+	primaries summaryStats
+	total     summaryStats
+}
+
+// summarySample keeps the counters of the previous gather for computing rates
+type summarySample struct {
+	indexing int64
+	search   int64
+	ts       time.Time
+}
+
 type serverInfo struct {
 	nodeID   string
 	masterID string
@@ -156,6 +230,28 @@ func (e *Elasticsearch) Init() error {
 
 	e.indexMatchers = indexMatchers
 
+	for _, kind := range e.IndexSummary {
+		switch kind {
+		case "indices", "data_streams":
+		default:
+			return fmt.Errorf("invalid index summary type %q", kind)
+		}
+	}
+	if len(e.IndexSummary) > 0 {
+		if e.IndexSummaryLimit < 0 {
+			return errors.New("'index_summary_limit' must not be negative")
+		}
+		include := e.IndexSummaryInclude
+		if len(include) == 0 {
+			include = []string{"*"}
+		}
+		e.summaryFilter, err = filter.NewIncludeExcludeFilter(include, e.IndexSummaryExclude)
+		if err != nil {
+			return fmt.Errorf("creating index summary filter failed: %w", err)
+		}
+		e.summaryHistory = make(map[string]summarySample)
+	}
+
 	return nil
 }
 
@@ -173,7 +269,7 @@ func (e *Elasticsearch) Gather(acc telegraf.Accumulator) error {
 		e.client = client
 	}
 
-	if e.ClusterStats || len(e.IndicesInclude) > 0 || len(e.IndicesLevel) > 0 {
+	if e.ClusterStats || len(e.IndicesInclude) > 0 || len(e.IndicesLevel) > 0 || len(e.IndexSummary) > 0 {
 		var wgC sync.WaitGroup
 		wgC.Add(len(e.Servers))
 
@@ -252,6 +348,13 @@ func (e *Elasticsearch) Gather(acc telegraf.Accumulator) error {
 				}
 			}
 
+			if len(e.IndexSummary) > 0 && (e.serverInfo[s].isMaster() || !e.ClusterStatsOnlyFromMaster || !e.Local) {
+				if err := e.gatherIndexSummary(s, acc); err != nil {
+					acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
+					return
+				}
+			}
+
 			if e.EnrichStats {
 				if err := e.gatherEnrichStats(s+"/_enrich/_stats", acc); err != nil {
 					acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
@@ -650,6 +753,147 @@ func (e *Elasticsearch) gatherSingleIndexStats(name string, index indexStat, now
 	return nil
 }
 
+// gatherIndexSummary reports a compact set of statistics per index and data
+// stream including the ILM phase of the index or the data stream's write index
+// if ILM is available
+func (e *Elasticsearch) gatherIndexSummary(server string, acc telegraf.Accumulator) error {
+	indicesStats := &struct {
+		Indices map[string]summaryIndex `json:"indices"`
+	}{}
+	if err := e.gatherJSONData(server+"/_stats/docs,store,indexing,search?expand_wildcards=open,hidden", indicesStats); err != nil {
+		return err
+	}
+
+	ilm := &struct {
+		Indices map[string]ilmExplain `json:"indices"`
+	}{}
+	// ILM is not available for all clusters e.g. for OSS distributions or
+	// without the required privileges, so report the summary without the
+	// ILM tags in this case
+	if err := e.gatherJSONData(server+"/*,.*/_ilm/explain?only_managed=true", ilm); err != nil {
+		e.ilmWarning.Do(func() {
+			e.Log.Warnf("Querying ILM state of %q failed, reporting index summary without ILM tags; further warnings are suppressed: %v",
+				mask.ReplaceAllString(server, "http(s)://XXX:XXX@"), err)
+		})
+		ilm.Indices = nil
+	}
+	now := time.Now()
+
+	var entries []summaryEntry
+	for _, kind := range e.IndexSummary {
+		switch kind {
+		case "indices":
+			for name, index := range indicesStats.Indices {
+				if !e.summaryFilter.Match(name) {
+					continue
+				}
+				entries = append(entries, summaryEntry{
+					name:      name,
+					phase:     ilm.Indices[name].Phase,
+					policy:    ilm.Indices[name].Policy,
+					primaries: index.Primaries,
+					total:     index.Total,
+				})
+			}
+		case "data_streams":
+			streams := &struct {
+				DataStreams []dataStream `json:"data_streams"`
+			}{}
+			if err := e.gatherJSONData(server+"/_data_stream", streams); err != nil {
+				return fmt.Errorf("querying data streams failed: %w", err)
+			}
+			for _, stream := range streams.DataStreams {
+				if !e.summaryFilter.Match(stream.Name) || len(stream.Indices) == 0 {
+					continue
+				}
+				// The write index is the last backing index and determines
+				// the phase of the data stream
+				writeIndex := stream.Indices[len(stream.Indices)-1].IndexName
+				entry := summaryEntry{
+					name:    stream.Name,
+					phase:   ilm.Indices[writeIndex].Phase,
+					policy:  ilm.Indices[writeIndex].Policy,
+					indices: len(stream.Indices),
+				}
+				for _, backing := range stream.Indices {
+					index := indicesStats.Indices[backing.IndexName]
+					entry.primaries.add(index.Primaries)
+					entry.total.add(index.Total)
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	// Protect against a cardinality explosion by only reporting the largest
+	// indices and data streams
+	if e.IndexSummaryLimit > 0 && len(entries) > e.IndexSummaryLimit {
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].total.Store.SizeInBytes != entries[j].total.Store.SizeInBytes {
+				return entries[i].total.Store.SizeInBytes > entries[j].total.Store.SizeInBytes
+			}
+			return entries[i].name < entries[j].name
+		})
+		e.summaryLimitWarning.Do(func() {
+			e.Log.Warnf("Index summary of %q matched %d entries, only reporting the %d largest; further warnings are suppressed",
+				mask.ReplaceAllString(server, "http(s)://XXX:XXX@"), len(entries), e.IndexSummaryLimit)
+		})
+		entries = entries[:e.IndexSummaryLimit]
+	}
+
+	e.summaryHistoryMutex.Lock()
+	defer e.summaryHistoryMutex.Unlock()
+	for _, entry := range entries {
+		measurement := "elasticsearch_index_summary"
+		tags := map[string]string{"index_name": entry.name}
+		fields := map[string]interface{}{
+			"docs_count":                 entry.primaries.Docs.Count,
+			"primaries_store_size_bytes": entry.primaries.Store.SizeInBytes,
+			"store_size_bytes":           entry.total.Store.SizeInBytes,
+			"indexing_index_total":       entry.primaries.Indexing.IndexTotal,
+			"indexing_index_time_millis": entry.primaries.Indexing.IndexTimeInMillis,
+			"search_query_total":         entry.total.Search.QueryTotal,
+			"search_query_time_millis":   entry.total.Search.QueryTimeInMillis,
+		}
+		if entry.indices > 0 {
+			measurement = "elasticsearch_data_stream_summary"
+			tags = map[string]string{"data_stream": entry.name}
+			fields["backing_indices"] = entry.indices
+		}
+		if entry.phase != "" {
+			tags["ilm_phase"] = entry.phase
+			tags["ilm_policy"] = entry.policy
+		}
+
+		// Derive the rates from the counters of the previous gather
+		key := server + "\x00" + measurement + "\x00" + entry.name
+		current := summarySample{
+			indexing: entry.primaries.Indexing.IndexTotal,
+			search:   entry.total.Search.QueryTotal,
+			ts:       now,
+		}
+		if last, found := e.summaryHistory[key]; found {
+			elapsed := now.Sub(last.ts).Seconds()
+			if elapsed > 0 && current.indexing >= last.indexing && current.search >= last.search {
+				fields["indexing_rate"] = float64(current.indexing-last.indexing) / elapsed
+				fields["search_rate"] = float64(current.search-last.search) / elapsed
+			}
+		}
+		e.summaryHistory[key] = current
+
+		acc.AddFields(measurement, fields, tags, now)
+	}
+
+	// Forget about deleted indices and data streams
+	for key, sample := range e.summaryHistory {
+		if strings.HasPrefix(key, server+"\x00") && !sample.ts.Equal(now) {
+			delete(e.summaryHistory, key)
+		}
+	}
+
+	return nil
+}
+
 func (e *Elasticsearch) getCatMaster(url string) (string, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -773,6 +1017,8 @@ func newElasticsearch() *Elasticsearch {
 	return &Elasticsearch{
 		ClusterStatsOnlyFromMaster: true,
 		ClusterHealthLevel:         "indices",
+		IndexSummaryExclude:        []string{".*"},
+		IndexSummaryLimit:          500,
 		HTTPClientConfig: common_http.HTTPClientConfig{
 			ResponseHeaderTimeout: config.Duration(5 * time.Second),
 			Timeout:               config.Duration(5 * time.Second),
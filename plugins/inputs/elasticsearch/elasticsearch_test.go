@@ -5,9 +5,12 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -48,6 +51,25 @@ func (t *transportMock) RoundTrip(r *http.Request) (*http.Response, error) {
 	return res, nil
 }
 
+type pathTransportMock struct {
+	bodies map[string]string
+}
+
+func (t *pathTransportMock) RoundTrip(r *http.Request) (*http.Response, error) {
+	res := &http.Response{
+		Header:     make(http.Header),
+		Request:    r,
+		StatusCode: http.StatusOK,
+	}
+	body, found := t.bodies[r.URL.Path]
+	if !found {
+		res.StatusCode = http.StatusNotFound
+	}
+	res.Header.Set("Content-Type", "application/json")
+	res.Body = io.NopCloser(strings.NewReader(body))
+	return res, nil
+}
+
 func checkNodeStatsResult(t *testing.T, acc *testutil.Accumulator) {
 	tags := defaultTags()
 	acc.AssertContainsTaggedFields(t, "elasticsearch_indices", nodestatsIndicesExpected, tags)
@@ -366,6 +388,182 @@ func TestGatherClusterIndiceShardsStats(t *testing.T) {
 		replicaTags)
 }
 
+func TestIndexSummaryInvalidType(t *testing.T) {
+	es := newElasticsearch()
+	es.IndexSummary = []string{"aliases"}
+	require.ErrorContains(t, es.Init(), "invalid index summary type")
+}
+
+func TestGatherIndexSummary(t *testing.T) {
+	es := newElasticsearchWithClient()
+	es.IndexSummary = []string{"indices", "data_streams"}
+	es.Log = testutil.Logger{}
+	es.client.Transport = &pathTransportMock{
+		bodies: map[string]string{
+			"/_stats/docs,store,indexing,search": indexSummaryStatsResponse,
+			"/*,.*/_ilm/explain":                 indexSummaryILMResponse,
+			"/_data_stream":                      indexSummaryDataStreamResponse,
+		},
+	}
+	require.NoError(t, es.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, es.gatherIndexSummary("http://example.com:9200", &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"elasticsearch_index_summary",
+			map[string]string{
+				"index_name": "logs",
+				"ilm_phase":  "warm",
+				"ilm_policy": "logs-policy",
+			},
+			map[string]interface{}{
+				"docs_count":                 int64(100),
+				"primaries_store_size_bytes": int64(2000),
+				"store_size_bytes":           int64(4000),
+				"indexing_index_total":       int64(100),
+				"indexing_index_time_millis": int64(50),
+				"search_query_total":         int64(20),
+				"search_query_time_millis":   int64(10),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"elasticsearch_index_summary",
+			map[string]string{"index_name": "users"},
+			map[string]interface{}{
+				"docs_count":                 int64(5),
+				"primaries_store_size_bytes": int64(100),
+				"store_size_bytes":           int64(100),
+				"indexing_index_total":       int64(5),
+				"indexing_index_time_millis": int64(1),
+				"search_query_total":         int64(50),
+				"search_query_time_millis":   int64(20),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"elasticsearch_data_stream_summary",
+			map[string]string{
+				"data_stream": "metrics",
+				"ilm_phase":   "hot",
+				"ilm_policy":  "metrics",
+			},
+			map[string]interface{}{
+				"backing_indices":            2,
+				"docs_count":                 int64(1500),
+				"primaries_store_size_bytes": int64(40000),
+				"store_size_bytes":           int64(80000),
+				"indexing_index_total":       int64(1500),
+				"indexing_index_time_millis": int64(400),
+				"search_query_total":         int64(10),
+				"search_query_time_millis":   int64(4),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The second gather reports the rates
+	acc.ClearMetrics()
+	require.NoError(t, es.gatherIndexSummary("http://example.com:9200", &acc))
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.True(t, m.HasField("indexing_rate"), "missing indexing rate in %v", m)
+		require.True(t, m.HasField("search_rate"), "missing search rate in %v", m)
+	}
+}
+
+func TestGatherIndexSummaryWithoutILM(t *testing.T) {
+	es := newElasticsearchWithClient()
+	es.IndexSummary = []string{"indices", "data_streams"}
+	es.Log = testutil.Logger{}
+	// The ILM endpoint is not available e.g. for OSS distributions
+	es.client.Transport = &pathTransportMock{
+		bodies: map[string]string{
+			"/_stats/docs,store,indexing,search": indexSummaryStatsResponse,
+			"/_data_stream":                      indexSummaryDataStreamResponse,
+		},
+	}
+	require.NoError(t, es.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, es.gatherIndexSummary("http://example.com:9200", &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"elasticsearch_index_summary",
+			map[string]string{"index_name": "logs"},
+			map[string]interface{}{
+				"docs_count":                 int64(100),
+				"primaries_store_size_bytes": int64(2000),
+				"store_size_bytes":           int64(4000),
+				"indexing_index_total":       int64(100),
+				"indexing_index_time_millis": int64(50),
+				"search_query_total":         int64(20),
+				"search_query_time_millis":   int64(10),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"elasticsearch_index_summary",
+			map[string]string{"index_name": "users"},
+			map[string]interface{}{
+				"docs_count":                 int64(5),
+				"primaries_store_size_bytes": int64(100),
+				"store_size_bytes":           int64(100),
+				"indexing_index_total":       int64(5),
+				"indexing_index_time_millis": int64(1),
+				"search_query_total":         int64(50),
+				"search_query_time_millis":   int64(20),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"elasticsearch_data_stream_summary",
+			map[string]string{"data_stream": "metrics"},
+			map[string]interface{}{
+				"backing_indices":            2,
+				"docs_count":                 int64(1500),
+				"primaries_store_size_bytes": int64(40000),
+				"store_size_bytes":           int64(80000),
+				"indexing_index_total":       int64(1500),
+				"indexing_index_time_millis": int64(400),
+				"search_query_total":         int64(10),
+				"search_query_time_millis":   int64(4),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+	require.Empty(t, acc.Errors)
+}
+
+func TestGatherIndexSummaryLimit(t *testing.T) {
+	es := newElasticsearchWithClient()
+	es.IndexSummary = []string{"indices", "data_streams"}
+	es.IndexSummaryLimit = 2
+	es.Log = testutil.Logger{}
+	es.client.Transport = &pathTransportMock{
+		bodies: map[string]string{
+			"/_stats/docs,store,indexing,search": indexSummaryStatsResponse,
+			"/*,.*/_ilm/explain":                 indexSummaryILMResponse,
+			"/_data_stream":                      indexSummaryDataStreamResponse,
+		},
+	}
+	require.NoError(t, es.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, es.gatherIndexSummary("http://example.com:9200", &acc))
+
+	// Only the two largest entries are reported
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 2)
+	require.Equal(t, "elasticsearch_data_stream_summary", metrics[0].Name())
+	require.Equal(t, "elasticsearch_index_summary", metrics[1].Name())
+	require.Equal(t, map[string]string{"index_name": "logs", "ilm_phase": "warm", "ilm_policy": "logs-policy"}, metrics[1].Tags())
+}
+
 func newElasticsearchWithClient() *Elasticsearch {
 	es := newElasticsearch()
 	es.client = &http.Client{}
@@ -5914,3 +5914,91 @@ var clusterIndicesReplicaShardsExpected = map[string]interface{}{
 	"warmer_total":                           float64(3),
 	"warmer_total_time_in_millis":            float64(0),
 }
+
+const indexSummaryStatsResponse = `
+{
+  "indices": {
+    "logs": {
+      "primaries": {
+        "docs": {"count": 100},
+        "store": {"size_in_bytes": 2000},
+        "indexing": {"index_total": 100, "index_time_in_millis": 50},
+        "search": {"query_total": 10, "query_time_in_millis": 5}
+      },
+      "total": {
+        "docs": {"count": 200},
+        "store": {"size_in_bytes": 4000},
+        "indexing": {"index_total": 200, "index_time_in_millis": 100},
+        "search": {"query_total": 20, "query_time_in_millis": 10}
+      }
+    },
+    "users": {
+      "primaries": {
+        "docs": {"count": 5},
+        "store": {"size_in_bytes": 100},
+        "indexing": {"index_total": 5, "index_time_in_millis": 1},
+        "search": {"query_total": 50, "query_time_in_millis": 20}
+      },
+      "total": {
+        "docs": {"count": 5},
+        "store": {"size_in_bytes": 100},
+        "indexing": {"index_total": 5, "index_time_in_millis": 1},
+        "search": {"query_total": 50, "query_time_in_millis": 20}
+      }
+    },
+    ".ds-metrics-2024.01.01-000001": {
+      "primaries": {
+        "docs": {"count": 1000},
+        "store": {"size_in_bytes": 30000},
+        "indexing": {"index_total": 1000, "index_time_in_millis": 300},
+        "search": {"query_total": 7, "query_time_in_millis": 3}
+      },
+      "total": {
+        "docs": {"count": 2000},
+        "store": {"size_in_bytes": 60000},
+        "indexing": {"index_total": 2000, "index_time_in_millis": 600},
+        "search": {"query_total": 7, "query_time_in_millis": 3}
+      }
+    },
+    ".ds-metrics-2024.01.02-000002": {
+      "primaries": {
+        "docs": {"count": 500},
+        "store": {"size_in_bytes": 10000},
+        "indexing": {"index_total": 500, "index_time_in_millis": 100},
+        "search": {"query_total": 3, "query_time_in_millis": 1}
+      },
+      "total": {
+        "docs": {"count": 1000},
+        "store": {"size_in_bytes": 20000},
+        "indexing": {"index_total": 1000, "index_time_in_millis": 200},
+        "search": {"query_total": 3, "query_time_in_millis": 1}
+      }
+    }
+  }
+}
+`
+
+const indexSummaryILMResponse = `
+{
+  "indices": {
+    "logs": {"index": "logs", "managed": true, "policy": "logs-policy", "phase": "warm"},
+    ".ds-metrics-2024.01.01-000001": {"index": ".ds-metrics-2024.01.01-000001", "managed": true, "policy": "metrics", "phase": "warm"},
+    ".ds-metrics-2024.01.02-000002": {"index": ".ds-metrics-2024.01.02-000002", "managed": true, "policy": "metrics", "phase": "hot"}
+  }
+}
+`
+
+const indexSummaryDataStreamResponse = `
+{
+  "data_streams": [
+    {
+      "name": "metrics",
+      "indices": [
+        {"index_name": ".ds-metrics-2024.01.01-000001"},
+        {"index_name": ".ds-metrics-2024.01.02-000002"}
+      ],
+      "ilm_policy": "metrics"
+    }
+  ]
+}
+`
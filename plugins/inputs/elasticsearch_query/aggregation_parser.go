@@ -1,7 +1,12 @@
 package elasticsearch_query
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	elastic5 "gopkg.in/olivere/elastic.v5"
@@ -124,6 +129,149 @@ func recurseResponse(acc telegraf.Accumulator, aggNameFunction map[string]string
 	return m, nil
 }
 
+// parseCompositeResult adds a metric per bucket of the composite aggregation
+// and returns the key to request the next page, nil if all buckets are processed
+func parseCompositeResult(acc telegraf.Accumulator, aggregation esAggregation, searchResult *elastic5.SearchResult) (map[string]interface{}, error) {
+	raw, found := searchResult.Aggregations[compositeName]
+	if !found || raw == nil {
+		return nil, errors.New("composite aggregation not found in response")
+	}
+
+	var result struct {
+		AfterKey map[string]interface{}  `json:"after_key"`
+		Buckets  []elastic5.Aggregations `json:"buckets"`
+	}
+	if err := json.Unmarshal(*raw, &result); err != nil {
+		return nil, fmt.Errorf("parsing composite aggregation failed: %w", err)
+	}
+
+	aggNameFunction := aggregation.aggregationFunctions()
+	for _, bucket := range result.Buckets {
+		m := resultMetric{
+			name:   aggregation.MeasurementName,
+			fields: make(map[string]interface{}),
+			tags:   make(map[string]string),
+		}
+
+		key, docCount, err := parseCompositeBucket(bucket)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range key {
+			// null values only occur for the missing bucket
+			if value == nil {
+				m.tags[name] = aggregation.MissingTagValue
+				continue
+			}
+			m.tags[name] = fmt.Sprint(value)
+		}
+		m.fields["doc_count"] = docCount
+
+		m, err = recurseResponse(acc, aggNameFunction, bucket, m)
+		if err != nil {
+			return nil, err
+		}
+		if len(m.fields) > 0 {
+			acc.AddFields(m.name, m.fields, m.tags)
+		}
+	}
+
+	if len(result.Buckets) == 0 {
+		return nil, nil
+	}
+	return result.AfterKey, nil
+}
+
+func parseCompositeBucket(bucket elastic5.Aggregations) (key map[string]interface{}, docCount int64, err error) {
+	rawKey, found := bucket["key"]
+	if !found || rawKey == nil {
+		return nil, 0, errors.New("composite bucket without key")
+	}
+
+	// keep numeric keys as they are returned instead of converting them to floats
+	decoder := json.NewDecoder(bytes.NewReader(*rawKey))
+	decoder.UseNumber()
+	if err := decoder.Decode(&key); err != nil {
+		return nil, 0, fmt.Errorf("parsing composite bucket key failed: %w", err)
+	}
+
+	if rawCount, found := bucket["doc_count"]; found && rawCount != nil {
+		if err := json.Unmarshal(*rawCount, &docCount); err != nil {
+			return nil, 0, fmt.Errorf("parsing composite bucket document count failed: %w", err)
+		}
+	}
+
+	return key, docCount, nil
+}
+
+// parseDocument adds a metric for a document returned by a document query
+// using the docvalue fields of the hit and the date field as sort value
+func parseDocument(acc telegraf.Accumulator, aggregation esAggregation, docFields map[string]interface{}, sort []interface{}) {
+	tags := make(map[string]string, len(aggregation.Tags))
+	for _, tag := range aggregation.Tags {
+		name := strings.ReplaceAll(tag, ".", "_")
+		if value, ok := firstValue(docFields[tag]); ok {
+			tags[name] = fmt.Sprint(value)
+		} else if aggregation.IncludeMissingTag && aggregation.MissingTagValue != "" {
+			tags[name] = aggregation.MissingTagValue
+		}
+	}
+
+	fields := make(map[string]interface{}, len(aggregation.DocumentFields))
+	for _, field := range aggregation.DocumentFields {
+		if value, ok := firstValue(docFields[field]); ok {
+			fields[strings.ReplaceAll(field, ".", "_")] = value
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	// dates are sorted by their value in milliseconds since epoch
+	if len(sort) > 0 {
+		if ms, ok := sort[0].(float64); ok {
+			acc.AddFields(aggregation.MeasurementName, fields, tags, time.UnixMilli(int64(ms)).UTC())
+			return
+		}
+	}
+	acc.AddFields(aggregation.MeasurementName, fields, tags)
+}
+
+// firstValue returns the first value of a (multi-valued) docvalue field
+func firstValue(v interface{}) (interface{}, bool) {
+	if values, ok := v.([]interface{}); ok {
+		if len(values) == 0 {
+			return nil, false
+		}
+		v = values[0]
+	}
+	switch v.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return nil, false
+	}
+	return v, true
+}
+
+// sumOtherDocCount returns the number of documents not contained in the
+// buckets returned by the terms aggregations
+func sumOtherDocCount(aggNameFunction map[string]string, aggs elastic5.Aggregations) int64 {
+	var count int64
+	for name, function := range aggNameFunction {
+		if function != "terms" {
+			continue
+		}
+		terms, found := aggs.Terms(name)
+		if !found {
+			continue
+		}
+		count += terms.SumOfOtherDocCount
+		for _, bucket := range terms.Buckets {
+			count += sumOtherDocCount(aggNameFunction, bucket.Aggregations)
+		}
+	}
+	return count
+}
+
 func getResponseAggregation(function, aggName string, aggs elastic5.Aggregations) (agg interface{}) {
 	switch function {
 	case "avg":
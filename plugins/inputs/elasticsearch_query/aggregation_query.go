@@ -8,6 +8,13 @@ import (
 	"time"
 
 	elastic5 "gopkg.in/olivere/elastic.v5"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	compositeName   = "composite"
+	defaultPageSize = 1000
 )
 
 type aggKey struct {
@@ -23,8 +30,8 @@ type aggregationQueryData struct {
 	aggregation elastic5.Aggregation
 }
 
-func (e *ElasticsearchQuery) runAggregationQuery(ctx context.Context, aggregation esAggregation) (*elastic5.SearchResult, error) {
-	now := time.Now().UTC()
+// buildQuery returns the filter query of the aggregation for the time window ending at the given time
+func (e *ElasticsearchQuery) buildQuery(aggregation esAggregation, now time.Time) (elastic5.Query, error) {
 	from := now.Add(time.Duration(-aggregation.QueryPeriod))
 	filterQuery := aggregation.FilterQuery
 	if filterQuery == "" {
@@ -45,12 +52,25 @@ func (e *ElasticsearchQuery) runAggregationQuery(ctx context.Context, aggregatio
 	}
 	e.Log.Debugf("{\"query\": %s}", string(data))
 
+	return query, nil
+}
+
+func (e *ElasticsearchQuery) runAggregationQuery(
+	ctx context.Context,
+	aggregation esAggregation,
+	query elastic5.Query,
+	after map[string]interface{},
+) (*elastic5.SearchResult, error) {
 	search := e.esClient.Search().Index(aggregation.Index).Query(query).Size(0)
 
 	// add only parent elastic.Aggregations to the search request, all the rest are subaggregations of these
 	for _, v := range aggregation.aggregationQueryList {
 		if v.isParent && v.aggregation != nil {
-			search.Aggregation(v.aggKey.name, v.aggregation)
+			agg := v.aggregation
+			if composite, ok := agg.(*compositeAggregation); ok {
+				agg = composite.page(after)
+			}
+			search.Aggregation(v.aggKey.name, agg)
 		}
 	}
 
@@ -62,6 +82,77 @@ func (e *ElasticsearchQuery) runAggregationQuery(ctx context.Context, aggregatio
 	return searchResult, err
 }
 
+// runCompositeQuery pages through all buckets of the composite aggregation
+func (e *ElasticsearchQuery) runCompositeQuery(acc telegraf.Accumulator, aggregation esAggregation, query elastic5.Query) error {
+	var after map[string]interface{}
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.Timeout))
+		searchResult, err := e.runAggregationQuery(ctx, aggregation, query, after)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		after, err = parseCompositeResult(acc, aggregation, searchResult)
+		if err != nil {
+			return err
+		}
+		if after == nil {
+			return nil
+		}
+	}
+}
+
+// runDocumentQuery pages through all documents matching the query using
+// search-after and creates a metric per document
+func (e *ElasticsearchQuery) runDocumentQuery(acc telegraf.Accumulator, aggregation esAggregation, query elastic5.Query) error {
+	fields := make([]string, 0, len(aggregation.DocumentFields)+len(aggregation.Tags))
+	fields = append(fields, aggregation.DocumentFields...)
+	fields = append(fields, aggregation.Tags...)
+
+	size := aggregation.pageSize()
+	tiebreaker := aggregation.TiebreakerField
+	if tiebreaker == "" {
+		tiebreaker = "_uid"
+	}
+
+	var after []interface{}
+	for {
+		source := elastic5.NewSearchSource().
+			Query(query).
+			Size(size).
+			FetchSource(false).
+			DocvalueFields(fields...).
+			Sort(aggregation.DateField, true).
+			Sort(tiebreaker, true)
+		if after != nil {
+			source = source.SearchAfter(after...)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.Timeout))
+		searchResult, err := e.esClient.Search().Index(aggregation.Index).SearchSource(source).Do(ctx)
+		cancel()
+		if err != nil {
+			if searchResult != nil && searchResult.Error != nil {
+				return fmt.Errorf("%s - %s", searchResult.Error.Type, searchResult.Error.Reason)
+			}
+			return err
+		}
+		if searchResult.Hits == nil || len(searchResult.Hits.Hits) == 0 {
+			return nil
+		}
+
+		for _, hit := range searchResult.Hits.Hits {
+			parseDocument(acc, aggregation, hit.Fields, hit.Sort)
+		}
+
+		if len(searchResult.Hits.Hits) < size {
+			return nil
+		}
+		after = searchResult.Hits.Hits[len(searchResult.Hits.Hits)-1].Sort
+	}
+}
+
 // getMetricFields function returns a map of fields and field types on Elasticsearch that matches field.MetricFields
 func (e *ElasticsearchQuery) getMetricFields(ctx context.Context, aggregation esAggregation) (map[string]string, error) {
 	mapMetricFields := make(map[string]string)
@@ -162,6 +253,38 @@ func (aggregation *esAggregation) buildAggregationQuery() error {
 		aggregation.aggregationQueryList = append(aggregation.aggregationQueryList, aggregationQuery)
 	}
 
+	// create a single composite aggregation over all tags, paginated when querying
+	if aggregation.isComposite() {
+		agg := &compositeAggregation{
+			size:            aggregation.pageSize(),
+			missingBucket:   aggregation.IncludeMissingTag && aggregation.MissingTagValue != "",
+			subAggregations: make(map[string]elastic5.Aggregation),
+		}
+		for _, term := range aggregation.Tags {
+			agg.sources = append(agg.sources, compositeSource{name: strings.ReplaceAll(term, ".", "_"), field: term})
+		}
+
+		for key, aggMap := range aggregation.aggregationQueryList {
+			if aggMap.isParent {
+				agg.subAggregations[aggMap.name] = aggMap.aggregation
+				aggregation.aggregationQueryList[key].isParent = false
+			}
+		}
+
+		aggregationQuery := aggregationQueryData{
+			aggKey: aggKey{
+				measurement: aggregation.MeasurementName,
+				function:    "composite",
+				name:        compositeName,
+			},
+			isParent:    true,
+			aggregation: agg,
+		}
+		aggregation.aggregationQueryList = append(aggregation.aggregationQueryList, aggregationQuery)
+
+		return nil
+	}
+
 	// create a terms aggregation per tag
 	for _, term := range aggregation.Tags {
 		agg := elastic5.NewTermsAggregation()
@@ -197,6 +320,28 @@ func (aggregation *esAggregation) buildAggregationQuery() error {
 	return nil
 }
 
+// isComposite returns true if the tags are aggregated using a paginated composite aggregation
+func (aggregation *esAggregation) isComposite() bool {
+	return aggregation.Composite && len(aggregation.Tags) > 0
+}
+
+// pageSize returns the number of buckets or documents to request per page
+func (aggregation *esAggregation) pageSize() int {
+	if aggregation.PageSize > 0 {
+		return aggregation.PageSize
+	}
+	return defaultPageSize
+}
+
+// aggregationFunctions returns the aggregation function of each aggregation name
+func (aggregation *esAggregation) aggregationFunctions() map[string]string {
+	functions := make(map[string]string, len(aggregation.aggregationQueryList))
+	for _, q := range aggregation.aggregationQueryList {
+		functions[q.name] = q.function
+	}
+	return functions
+}
+
 func getFunctionAggregation(function, aggfield string) (elastic5.Aggregation, error) {
 	var agg elastic5.Aggregation
 
@@ -215,3 +360,59 @@ func getFunctionAggregation(function, aggfield string) (elastic5.Aggregation, er
 
 	return agg, nil
 }
+
+type compositeSource struct {
+	name  string
+	field string
+}
+
+// compositeAggregation is a composite aggregation of terms sources which is
+// not available in the Elasticsearch client library
+type compositeAggregation struct {
+	size            int
+	sources         []compositeSource
+	missingBucket   bool
+	after           map[string]interface{}
+	subAggregations map[string]elastic5.Aggregation
+}
+
+// page returns a copy of the aggregation requesting the buckets after the given key
+func (c *compositeAggregation) page(after map[string]interface{}) *compositeAggregation {
+	p := *c
+	p.after = after
+	return &p
+}
+
+func (c *compositeAggregation) Source() (interface{}, error) {
+	sources := make([]interface{}, 0, len(c.sources))
+	for _, s := range c.sources {
+		terms := map[string]interface{}{"field": s.field}
+		if c.missingBucket {
+			terms["missing_bucket"] = true
+		}
+		sources = append(sources, map[string]interface{}{s.name: map[string]interface{}{"terms": terms}})
+	}
+
+	composite := map[string]interface{}{
+		"size":    c.size,
+		"sources": sources,
+	}
+	if c.after != nil {
+		composite["after"] = c.after
+	}
+	source := map[string]interface{}{"composite": composite}
+
+	if len(c.subAggregations) > 0 {
+		aggs := make(map[string]interface{}, len(c.subAggregations))
+		for name, agg := range c.subAggregations {
+			src, err := agg.Source()
+			if err != nil {
+				return nil, err
+			}
+			aggs[name] = src
+		}
+		source["aggregations"] = aggs
+	}
+
+	return source, nil
+}
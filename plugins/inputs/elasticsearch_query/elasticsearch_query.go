@@ -51,6 +51,10 @@ type esAggregation struct {
 	Tags                 []string        `toml:"tags"`
 	IncludeMissingTag    bool            `toml:"include_missing_tag"`
 	MissingTagValue      string          `toml:"missing_tag_value"`
+	Composite            bool            `toml:"composite"`
+	DocumentFields       []string        `toml:"document_fields"`
+	PageSize             int             `toml:"page_size"`
+	TiebreakerField      string          `toml:"tiebreaker_field"`
 	mapMetricFields      map[string]string
 	aggregationQueryList []aggregationQueryData
 }
@@ -64,6 +68,21 @@ func (e *ElasticsearchQuery) Init() error {
 		return errors.New("elasticsearch urls is not defined")
 	}
 
+	for _, agg := range e.Aggregations {
+		if agg.MeasurementName == "" {
+			return errors.New("field 'measurement_name' is not set")
+		}
+		if agg.DateField == "" {
+			return errors.New("field 'date_field' is not set")
+		}
+		if agg.PageSize < 0 {
+			return errors.New("field 'page_size' must not be negative")
+		}
+		if len(agg.DocumentFields) > 0 && len(agg.MetricFields) > 0 {
+			return errors.New("fields 'document_fields' and 'metric_fields' are mutually exclusive")
+		}
+	}
+
 	err := e.connectToES()
 	if err != nil {
 		e.Log.Errorf("error connecting to elasticsearch: %s", err)
@@ -74,11 +93,9 @@ func (e *ElasticsearchQuery) Init() error {
 	defer cancel()
 
 	for i, agg := range e.Aggregations {
-		if agg.MeasurementName == "" {
-			return errors.New("field 'measurement_name' is not set")
-		}
-		if agg.DateField == "" {
-			return errors.New("field 'date_field' is not set")
+		// document queries do not use aggregations
+		if len(agg.DocumentFields) > 0 {
+			continue
 		}
 		err = e.initAggregation(ctx, agg, i)
 		if err != nil {
@@ -210,6 +227,16 @@ func (e *ElasticsearchQuery) createHTTPClient() (*http.Client, error) {
 }
 
 func (e *ElasticsearchQuery) esAggregationQuery(acc telegraf.Accumulator, aggregation esAggregation, i int) error {
+	// use the same time window for all requests of a paginated query
+	query, err := e.buildQuery(aggregation, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if len(aggregation.DocumentFields) > 0 {
+		return e.runDocumentQuery(acc, aggregation, query)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.Timeout))
 	defer cancel()
 
@@ -222,7 +249,11 @@ func (e *ElasticsearchQuery) esAggregationQuery(acc telegraf.Accumulator, aggreg
 		aggregation = e.Aggregations[i]
 	}
 
-	searchResult, err := e.runAggregationQuery(ctx, aggregation)
+	if aggregation.isComposite() {
+		return e.runCompositeQuery(acc, aggregation, query)
+	}
+
+	searchResult, err := e.runAggregationQuery(ctx, aggregation, query, nil)
 	if err != nil {
 		return err
 	}
@@ -232,6 +263,13 @@ func (e *ElasticsearchQuery) esAggregationQuery(acc telegraf.Accumulator, aggreg
 		return nil
 	}
 
+	// terms aggregations only return the top buckets, so warn about documents
+	// not being accounted for in the metrics
+	if n := sumOtherDocCount(aggregation.aggregationFunctions(), searchResult.Aggregations); n > 0 {
+		e.Log.Warnf("Terms aggregation for %q is incomplete, %d document(s) are not covered by the returned buckets; "+
+			"consider setting 'composite = true'", aggregation.MeasurementName, n)
+	}
+
 	return parseAggregationResult(acc, aggregation.aggregationQueryList, searchResult)
 }
 
@@ -3,7 +3,10 @@ package elasticsearch_query
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"strings"
@@ -19,6 +22,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	common_http "github.com/influxdata/telegraf/plugins/common/http"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -759,3 +763,198 @@ func TestElasticsearchQuery_buildAggregationQuery(t *testing.T) {
 		})
 	}
 }
+
+func newMockServer(t *testing.T, search func(body map[string]interface{}) string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			_, err := w.Write([]byte(`{"version": {"number": "6.8.23"}}`))
+			require.NoError(t, err)
+			return
+		}
+		if r.URL.Path != "/test/_search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(search(body))); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+}
+
+func TestCompositeAggregation(t *testing.T) {
+	var requests []map[string]interface{}
+	server := newMockServer(t, func(body map[string]interface{}) string {
+		requests = append(requests, body)
+		aggs := body["aggregations"].(map[string]interface{})
+		composite := aggs["composite"].(map[string]interface{})["composite"].(map[string]interface{})
+		if _, found := composite["after"]; !found {
+			return `{"hits": {"total": 3}, "aggregations": {"composite": {
+				"after_key": {"URI_keyword": "/b", "response": 404},
+				"buckets": [
+					{"key": {"URI_keyword": "/a", "response": 200}, "doc_count": 2, "size_avg": {"value": 10}},
+					{"key": {"URI_keyword": "/b", "response": 404}, "doc_count": 1, "size_avg": {"value": 20}}
+				]}}}`
+		}
+		if after := composite["after"].(map[string]interface{}); after["URI_keyword"] == "/b" {
+			return `{"hits": {"total": 3}, "aggregations": {"composite": {
+				"after_key": {"URI_keyword": null, "response": 200},
+				"buckets": [
+					{"key": {"URI_keyword": null, "response": 200}, "doc_count": 4, "size_avg": {"value": null}}
+				]}}}`
+		}
+		return `{"hits": {"total": 3}, "aggregations": {"composite": {"buckets": []}}}`
+	})
+	defer server.Close()
+
+	plugin := &ElasticsearchQuery{
+		URLs: []string{server.URL},
+		HTTPClientConfig: common_http.HTTPClientConfig{
+			Timeout: config.Duration(5 * time.Second),
+		},
+		Aggregations: []esAggregation{
+			{
+				Index:             "test",
+				MeasurementName:   "requests",
+				DateField:         "@timestamp",
+				QueryPeriod:       queryPeriod,
+				MetricFunction:    "avg",
+				Tags:              []string{"URI.keyword", "response"},
+				IncludeMissingTag: true,
+				MissingTagValue:   "none",
+				Composite:         true,
+				PageSize:          2,
+				mapMetricFields:   map[string]string{"size": "long"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.connectToES())
+	require.NoError(t, plugin.Aggregations[0].buildAggregationQuery())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"requests",
+			map[string]string{"URI_keyword": "/a", "response": "200"},
+			map[string]interface{}{"size_avg": float64(10), "doc_count": int64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"requests",
+			map[string]string{"URI_keyword": "/b", "response": "404"},
+			map[string]interface{}{"size_avg": float64(20), "doc_count": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"requests",
+			map[string]string{"URI_keyword": "none", "response": "200"},
+			map[string]interface{}{"size_avg": float64(0), "doc_count": int64(4)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+
+	// all pages must use the same composite sources and time window
+	require.Len(t, requests, 3)
+	for _, r := range requests[1:] {
+		require.Equal(t, requests[0]["query"], r["query"])
+	}
+	composite := requests[0]["aggregations"].(map[string]interface{})["composite"].(map[string]interface{})
+	require.Contains(t, composite, "aggregations")
+	require.Contains(t, composite["aggregations"], "size_avg")
+	require.InDelta(t, 2, composite["composite"].(map[string]interface{})["size"], 0)
+}
+
+func TestDocumentQuery(t *testing.T) {
+	var requests []map[string]interface{}
+	server := newMockServer(t, func(body map[string]interface{}) string {
+		requests = append(requests, body)
+		if _, found := body["search_after"]; !found {
+			return `{"hits": {"total": 3, "hits": [
+				{"_id": "1", "sort": [1718344313000, "t#1"], "fields": {"size": [10], "URI.keyword": ["/a"]}},
+				{"_id": "2", "sort": [1718344314000, "t#2"], "fields": {"size": [20]}}
+			]}}`
+		}
+		return `{"hits": {"total": 3, "hits": [
+			{"_id": "3", "sort": [1718344315000, "t#3"], "fields": {"size": [30, 31], "URI.keyword": ["/b"]}}
+		]}}`
+	})
+	defer server.Close()
+
+	plugin := &ElasticsearchQuery{
+		URLs: []string{server.URL},
+		HTTPClientConfig: common_http.HTTPClientConfig{
+			Timeout: config.Duration(5 * time.Second),
+		},
+		Aggregations: []esAggregation{
+			{
+				Index:           "test",
+				MeasurementName: "requests",
+				DateField:       "@timestamp",
+				QueryPeriod:     queryPeriod,
+				DocumentFields:  []string{"size"},
+				Tags:            []string{"URI.keyword"},
+				PageSize:        2,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"requests",
+			map[string]string{"URI_keyword": "/a"},
+			map[string]interface{}{"size": float64(10)},
+			time.UnixMilli(1718344313000),
+		),
+		metric.New(
+			"requests",
+			map[string]string{},
+			map[string]interface{}{"size": float64(20)},
+			time.UnixMilli(1718344314000),
+		),
+		metric.New(
+			"requests",
+			map[string]string{"URI_keyword": "/b"},
+			map[string]interface{}{"size": float64(30)},
+			time.UnixMilli(1718344315000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	require.Len(t, requests, 2)
+	require.Equal(t, []interface{}{float64(1718344314000), "t#2"}, requests[1]["search_after"])
+	require.Equal(t, requests[0]["query"], requests[1]["query"])
+}
+
+func TestInitDocumentAndMetricFields(t *testing.T) {
+	plugin := &ElasticsearchQuery{
+		URLs: []string{"http://localhost:9200"},
+		Aggregations: []esAggregation{
+			{
+				Index:           "test",
+				MeasurementName: "requests",
+				DateField:       "@timestamp",
+				MetricFields:    []string{"size"},
+				DocumentFields:  []string{"size"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "mutually exclusive")
+}
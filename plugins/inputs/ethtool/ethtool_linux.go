@@ -3,15 +3,18 @@
 package ethtool
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
@@ -25,6 +28,8 @@ const (
 	tagInterface     = "interface"
 	tagNamespace     = "namespace"
 	tagDriverName    = "driver"
+	tagModuleType    = "module_type"
+	tagLane          = "lane"
 	fieldInterfaceUp = "interface_up"
 )
 
@@ -47,6 +52,9 @@ type Ethtool struct {
 	// Normalization on the key names
 	NormalizeKeys []string `toml:"normalize_keys"`
 
+	// Read the digital diagnostics of pluggable transceiver modules
+	ModuleDiagnostics bool `toml:"module_diagnostics"`
+
 	Log telegraf.Logger `toml:"-"`
 
 	interfaceFilter   filter.Filter
@@ -63,6 +71,7 @@ type command interface {
 	interfaces(includeNamespaces bool) ([]namespacedInterface, error)
 	stats(intf namespacedInterface) (map[string]uint64, error)
 	get(intf namespacedInterface) (map[string]uint64, error)
+	moduleEeprom(intf namespacedInterface) ([]byte, error)
 }
 
 type commandEthtool struct {
@@ -189,6 +198,64 @@ func (e *Ethtool) gatherEthtoolStats(iface namespacedInterface, acc telegraf.Acc
 	}
 
 	acc.AddFields(pluginName, fields, tags)
+
+	if e.ModuleDiagnostics {
+		e.gatherModuleDiagnostics(iface, tags, acc)
+	}
+}
+
+// Gather the digital diagnostics of the transceiver module plugged into the
+// interface, if any.
+func (e *Ethtool) gatherModuleDiagnostics(iface namespacedInterface, tags map[string]string, acc telegraf.Accumulator) {
+	eeprom, err := e.command.moduleEeprom(iface)
+	if err != nil {
+		// Interfaces without a pluggable module or without driver support
+		// for reading the module EEPROM are silently skipped.
+		if errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.ENODEV) || errors.Is(err, unix.EIO) {
+			return
+		}
+		acc.AddError(fmt.Errorf("%q module eeprom: %w", iface.Name, err))
+		return
+	}
+
+	diag, err := parseModuleEeprom(eeprom)
+	if err != nil {
+		acc.AddError(fmt.Errorf("%q module eeprom: %w", iface.Name, err))
+		return
+	}
+	if diag == nil {
+		return
+	}
+
+	moduleTags := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		moduleTags[k] = v
+	}
+	moduleTags[tagModuleType] = diag.moduleType
+
+	acc.AddFields(pluginName+"_module", map[string]interface{}{
+		"temperature": diag.temperature,
+		"voltage":     diag.voltage,
+	}, moduleTags)
+
+	for i, lane := range diag.lanes {
+		laneTags := make(map[string]string, len(moduleTags)+1)
+		for k, v := range moduleTags {
+			laneTags[k] = v
+		}
+		laneTags[tagLane] = strconv.Itoa(i + 1)
+
+		fields := map[string]interface{}{
+			"tx_bias":      lane.txBias,
+			"rx_power":     lane.rxPower,
+			"rx_power_dbm": milliwattsToDBm(lane.rxPower),
+		}
+		if lane.hasTxPower {
+			fields["tx_power"] = lane.txPower
+			fields["tx_power_dbm"] = milliwattsToDBm(lane.txPower)
+		}
+		acc.AddFields(pluginName+"_module_lane", fields, laneTags)
+	}
 }
 
 // normalize key string; order matters to avoid replacing whitespace with
@@ -281,6 +348,10 @@ func (*commandEthtool) get(intf namespacedInterface) (stats map[string]uint64, e
 	return intf.namespace.get(intf)
 }
 
+func (*commandEthtool) moduleEeprom(intf namespacedInterface) ([]byte, error) {
+	return intf.namespace.moduleEeprom(intf)
+}
+
 func (c *commandEthtool) interfaces(includeNamespaces bool) ([]namespacedInterface, error) {
 	const namespaceDirectory = "/var/run/netns"
 
@@ -26,6 +26,9 @@ type Ethtool struct {
 	// Normalization on the key names
 	NormalizeKeys []string `toml:"normalize_keys"`
 
+	// Read the digital diagnostics of pluggable transceiver modules
+	ModuleDiagnostics bool `toml:"module_diagnostics"`
+
 	Log telegraf.Logger `toml:"-"`
 }
 
@@ -3,11 +3,14 @@
 package ethtool
 
 import (
+	"encoding/binary"
 	"errors"
+	"math"
 	"net"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
 
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -15,6 +18,7 @@ import (
 var (
 	eth          *Ethtool
 	interfaceMap map[string]*interfaceMock
+	eepromMap    map[string][]byte
 )
 
 type interfaceMock struct {
@@ -51,6 +55,10 @@ func (*namespaceMock) get(_ namespacedInterface) (map[string]uint64, error) {
 	return nil, errors.New("it is a test bug to invoke this function")
 }
 
+func (*namespaceMock) moduleEeprom(_ namespacedInterface) ([]byte, error) {
+	return nil, errors.New("it is a test bug to invoke this function")
+}
+
 type commandEthtoolMock struct {
 	interfaceMap map[string]*interfaceMock
 }
@@ -130,6 +138,13 @@ func (c *commandEthtoolMock) get(intf namespacedInterface) (map[string]uint64, e
 	return nil, errors.New("interface not found")
 }
 
+func (*commandEthtoolMock) moduleEeprom(intf namespacedInterface) ([]byte, error) {
+	if eeprom, found := eepromMap[intf.Name]; found {
+		return eeprom, nil
+	}
+	return nil, unix.EOPNOTSUPP
+}
+
 func setup() {
 	interfaceMap = make(map[string]*interfaceMock)
 
@@ -985,3 +1000,112 @@ func TestNormalizedKeys(t *testing.T) {
 		acc.AssertContainsTaggedFields(t, pluginName, c.expectedFields, expectedTags)
 	}
 }
+
+// sfpEeprom returns an SFP module EEPROM dump with internally calibrated
+// diagnostics
+func sfpEeprom() []byte {
+	data := make([]byte, 512)
+	data[0] = moduleIDSFP
+	data[92] = 0x68
+	page := data[256:]
+	binary.BigEndian.PutUint16(page[96:], 0x2380) // 35.5 °C
+	binary.BigEndian.PutUint16(page[98:], 33000)  // 3.3 V
+	binary.BigEndian.PutUint16(page[100:], 3000)  // 6 mA
+	binary.BigEndian.PutUint16(page[102:], 5000)  // 0.5 mW
+	binary.BigEndian.PutUint16(page[104:], 10000) // 1 mW
+	return data
+}
+
+// qsfpEeprom returns a QSFP28 module EEPROM dump with TX power monitoring
+func qsfpEeprom() []byte {
+	data := make([]byte, 256)
+	data[0] = moduleIDQSFP28
+	data[220] = 0x0c
+	binary.BigEndian.PutUint16(data[22:], 0xfe00) // -2 °C
+	binary.BigEndian.PutUint16(data[26:], 32500)  // 3.25 V
+	for i := 0; i < 4; i++ {
+		binary.BigEndian.PutUint16(data[34+2*i:], uint16(1000*(i+1))) // RX power
+		binary.BigEndian.PutUint16(data[42+2*i:], 4000)               // 8 mA
+		binary.BigEndian.PutUint16(data[50+2*i:], 10000)              // 1 mW
+	}
+	binary.BigEndian.PutUint16(data[40:], 0) // loss of signal on lane 4
+	return data
+}
+
+func TestParseModuleEeprom(t *testing.T) {
+	diag, err := parseModuleEeprom(sfpEeprom())
+	require.NoError(t, err)
+	require.Equal(t, &moduleDiagnostics{
+		moduleType:  "sfp",
+		temperature: 35.5,
+		voltage:     3.3,
+		lanes:       []moduleLane{{txBias: 6, txPower: 0.5, rxPower: 1, hasTxPower: true}},
+	}, diag)
+
+	diag, err = parseModuleEeprom(qsfpEeprom())
+	require.NoError(t, err)
+	require.Equal(t, "qsfp", diag.moduleType)
+	require.InDelta(t, -2.0, diag.temperature, 1e-9)
+	require.InDelta(t, 3.25, diag.voltage, 1e-9)
+	require.Len(t, diag.lanes, 4)
+	require.InDelta(t, 0.2, diag.lanes[1].rxPower, 1e-9)
+	require.InDelta(t, 8.0, diag.lanes[2].txBias, 1e-9)
+	require.InDelta(t, 1.0, diag.lanes[3].txPower, 1e-9)
+	require.Zero(t, diag.lanes[3].rxPower)
+
+	// SFP modules without diagnostics
+	data := sfpEeprom()[:256]
+	data[92] = 0
+	diag, err = parseModuleEeprom(data)
+	require.NoError(t, err)
+	require.Nil(t, diag)
+
+	// Unknown modules
+	diag, err = parseModuleEeprom([]byte{0x01, 0x00})
+	require.NoError(t, err)
+	require.Nil(t, diag)
+
+	_, err = parseModuleEeprom([]byte{moduleIDQSFP, 0x00})
+	require.ErrorContains(t, err, "too short")
+}
+
+func TestGatherModuleDiagnostics(t *testing.T) {
+	setup()
+	eepromMap = map[string][]byte{"eth1": sfpEeprom()}
+	defer func() { eepromMap = nil }()
+	eth.ModuleDiagnostics = true
+	require.NoError(t, eth.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, eth.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	tags := map[string]string{
+		"interface":   "eth1",
+		"driver":      "driver1",
+		"namespace":   "",
+		"module_type": "sfp",
+	}
+	acc.AssertContainsTaggedFields(t, "ethtool_module", map[string]interface{}{
+		"temperature": 35.5,
+		"voltage":     3.3,
+	}, tags)
+
+	tags["lane"] = "1"
+	acc.AssertContainsTaggedFields(t, "ethtool_module_lane", map[string]interface{}{
+		"tx_bias":      6.0,
+		"tx_power":     0.5,
+		"tx_power_dbm": 10 * math.Log10(0.5),
+		"rx_power":     1.0,
+		"rx_power_dbm": 0.0,
+	}, tags)
+
+	// Only eth1 has a module plugged in
+	var modules int
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "ethtool_module" {
+			modules++
+		}
+	}
+	require.Equal(t, 1, modules)
+}
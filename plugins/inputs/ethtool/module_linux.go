@@ -0,0 +1,123 @@
+//go:build linux
+
+package ethtool
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Module identifiers as defined in SFF-8024
+const (
+	moduleIDSFP      = 0x03
+	moduleIDQSFP     = 0x0c
+	moduleIDQSFPPlus = 0x0d
+	moduleIDQSFP28   = 0x11
+)
+
+type moduleLane struct {
+	txBias     float64 // mA
+	txPower    float64 // mW
+	rxPower    float64 // mW
+	hasTxPower bool
+}
+
+type moduleDiagnostics struct {
+	moduleType  string
+	temperature float64 // °C
+	voltage     float64 // V
+	lanes       []moduleLane
+}
+
+// parseModuleEeprom decodes the digital diagnostic monitoring (DDM) values of
+// the given module EEPROM dump. Modules without diagnostics return nil.
+func parseModuleEeprom(data []byte) (*moduleDiagnostics, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	switch data[0] {
+	case moduleIDSFP:
+		return parseSFF8472(data)
+	case moduleIDQSFP, moduleIDQSFPPlus, moduleIDQSFP28:
+		return parseSFF8636(data)
+	}
+	return nil, nil
+}
+
+// parseSFF8472 decodes the diagnostics of SFP modules located in the second
+// page (address A2h) following the 256 byte identification page.
+func parseSFF8472(data []byte) (*moduleDiagnostics, error) {
+	// Byte 92 of the identification page describes the monitoring type
+	const (
+		ddmImplemented        = 0x40
+		internallyCalibrated  = 0x20
+		externallyCalibrated  = 0x10
+		diagnosticsPageOffset = 256
+	)
+
+	if len(data) < 96 {
+		return nil, fmt.Errorf("SFP eeprom too short (%d bytes)", len(data))
+	}
+	monitoring := data[92]
+	if monitoring&ddmImplemented == 0 || len(data) < diagnosticsPageOffset+106 {
+		return nil, nil
+	}
+	if monitoring&internallyCalibrated == 0 && monitoring&externallyCalibrated != 0 {
+		return nil, nil
+	}
+
+	page := data[diagnosticsPageOffset:]
+	return &moduleDiagnostics{
+		moduleType:  "sfp",
+		temperature: float64(int16(binary.BigEndian.Uint16(page[96:]))) / 256,
+		voltage:     float64(binary.BigEndian.Uint16(page[98:])) / 10000,
+		lanes: []moduleLane{{
+			txBias:     float64(binary.BigEndian.Uint16(page[100:])) / 500,
+			txPower:    float64(binary.BigEndian.Uint16(page[102:])) / 10000,
+			rxPower:    float64(binary.BigEndian.Uint16(page[104:])) / 10000,
+			hasTxPower: true,
+		}},
+	}, nil
+}
+
+// parseSFF8636 decodes the diagnostics of QSFP modules with four lanes
+// located in the lower memory page.
+func parseSFF8636(data []byte) (*moduleDiagnostics, error) {
+	// Byte 220 of the upper page 00h describes the monitoring type
+	const txPowerSupported = 0x04
+
+	if len(data) < 58 {
+		return nil, fmt.Errorf("QSFP eeprom too short (%d bytes)", len(data))
+	}
+
+	hasTxPower := len(data) > 220 && data[220]&txPowerSupported != 0
+	diag := &moduleDiagnostics{
+		moduleType:  "qsfp",
+		temperature: float64(int16(binary.BigEndian.Uint16(data[22:]))) / 256,
+		voltage:     float64(binary.BigEndian.Uint16(data[26:])) / 10000,
+		lanes:       make([]moduleLane, 0, 4),
+	}
+	for i := 0; i < 4; i++ {
+		lane := moduleLane{
+			rxPower:    float64(binary.BigEndian.Uint16(data[34+2*i:])) / 10000,
+			txBias:     float64(binary.BigEndian.Uint16(data[42+2*i:])) / 500,
+			hasTxPower: hasTxPower,
+		}
+		if hasTxPower {
+			lane.txPower = float64(binary.BigEndian.Uint16(data[50+2*i:])) / 10000
+		}
+		diag.lanes = append(diag.lanes, lane)
+	}
+	return diag, nil
+}
+
+// milliwattsToDBm converts the optical power to dBm. Zero power is reported
+// as -40 dBm which is the lower bound of the measurement range.
+func milliwattsToDBm(mw float64) float64 {
+	if mw <= 0.0001 {
+		return -40
+	}
+	return 10 * math.Log10(mw)
+}
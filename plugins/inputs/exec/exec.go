@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	osexec "os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
@@ -29,12 +31,17 @@ var once sync.Once
 const maxStderrBytes int = 512
 
 type Exec struct {
-	Commands    []string        `toml:"commands"`
-	Command     string          `toml:"command"`
-	Environment []string        `toml:"environment"`
-	IgnoreError bool            `toml:"ignore_error"`
-	Timeout     config.Duration `toml:"timeout"`
-	Log         telegraf.Logger `toml:"-"`
+	Commands            []string                   `toml:"commands"`
+	Command             string                     `toml:"command"`
+	Environment         []string                   `toml:"environment"`
+	SecretEnvironment   map[string]*config.Secret  `toml:"secret_environment"`
+	IgnoreError         bool                       `toml:"ignore_error"`
+	Timeout             config.Duration            `toml:"timeout"`
+	CommandTimeouts     map[string]config.Duration `toml:"command_timeouts"`
+	MaxParallelCommands int                        `toml:"max_parallel_commands"`
+	StderrCapture       string                     `toml:"stderr_capture"`
+	StderrCaptureKey    string                     `toml:"stderr_capture_key"`
+	Log                 telegraf.Logger            `toml:"-"`
 
 	parser telegraf.Parser
 
@@ -47,6 +54,12 @@ type Exec struct {
 
 type exitCodeHandlerFunc func([]telegraf.Metric, error, []byte) []telegraf.Metric
 
+// job is a command to execute with its timeout
+type job struct {
+	command string
+	timeout time.Duration
+}
+
 type runner interface {
 	run(string, []string, time.Duration) ([]byte, []byte, error)
 }
@@ -59,7 +72,23 @@ func (*Exec) SampleConfig() string {
 	return sampleConfig
 }
 
-func (*Exec) Init() error {
+func (e *Exec) Init() error {
+	switch e.StderrCapture {
+	case "", "field", "tag":
+	default:
+		return fmt.Errorf("invalid 'stderr_capture' setting %q", e.StderrCapture)
+	}
+	if e.StderrCaptureKey == "" {
+		e.StderrCaptureKey = "stderr"
+	}
+	if e.MaxParallelCommands < 0 {
+		return errors.New("'max_parallel_commands' must not be negative")
+	}
+	for command, timeout := range e.CommandTimeouts {
+		if timeout <= 0 {
+			return fmt.Errorf("timeout of command %q must be positive", command)
+		}
+	}
 	return nil
 }
 
@@ -75,20 +104,29 @@ func (e *Exec) SetParser(parser telegraf.Parser) {
 }
 
 func (e *Exec) Gather(acc telegraf.Accumulator) error {
-	var wg sync.WaitGroup
 	// Legacy single command support
 	if e.Command != "" {
 		e.Commands = append(e.Commands, e.Command)
 		e.Command = ""
 	}
 
-	commands := make([]string, 0, len(e.Commands))
+	environment, err := e.environment()
+	if err != nil {
+		return err
+	}
+
+	jobs := make([]job, 0, len(e.Commands))
 	for _, pattern := range e.Commands {
 		cmdAndArgs := strings.SplitN(pattern, " ", 2)
 		if len(cmdAndArgs) == 0 {
 			continue
 		}
 
+		timeout := time.Duration(e.Timeout)
+		if t, found := e.CommandTimeouts[pattern]; found {
+			timeout = time.Duration(t)
+		}
+
 		matches, err := filepath.Glob(cmdAndArgs[0])
 		if err != nil {
 			acc.AddError(err)
@@ -98,29 +136,66 @@ func (e *Exec) Gather(acc telegraf.Accumulator) error {
 		if len(matches) == 0 {
 			// There were no matches with the glob pattern, so let's assume
 			// that the command is in PATH and just run it as it is
-			commands = append(commands, pattern)
+			jobs = append(jobs, job{command: pattern, timeout: timeout})
 		} else {
 			// There were matches, so we'll append each match together with
 			// the arguments to the commands slice
 			for _, match := range matches {
-				if len(cmdAndArgs) == 1 {
-					commands = append(commands, match)
-				} else {
-					commands = append(commands,
-						strings.Join([]string{match, cmdAndArgs[1]}, " "))
+				command := match
+				if len(cmdAndArgs) > 1 {
+					command = strings.Join([]string{match, cmdAndArgs[1]}, " ")
 				}
+				jobs = append(jobs, job{command: command, timeout: timeout})
 			}
 		}
 	}
 
-	wg.Add(len(commands))
-	for _, command := range commands {
-		go e.processCommand(command, acc, &wg)
+	// Limit the number of commands running at the same time if requested
+	workers := len(jobs)
+	if e.MaxParallelCommands > 0 && e.MaxParallelCommands < workers {
+		workers = e.MaxParallelCommands
+	}
+
+	queue := make(chan job, len(jobs))
+	for _, j := range jobs {
+		queue <- j
+	}
+	close(queue)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				e.processCommand(j, environment, acc)
+			}
+		}()
 	}
 	wg.Wait()
 	return nil
 }
 
+// environment returns the configured environment variables including the
+// variables resolved from secret-stores
+func (e *Exec) environment() ([]string, error) {
+	if len(e.SecretEnvironment) == 0 {
+		return e.Environment, nil
+	}
+
+	environment := make([]string, 0, len(e.Environment)+len(e.SecretEnvironment))
+	environment = append(environment, e.Environment...)
+	for name, secret := range e.SecretEnvironment {
+		value, err := secret.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting secret for environment variable %q failed: %w", name, err)
+		}
+		environment = append(environment, name+"="+value.String())
+		value.Destroy()
+	}
+	return environment, nil
+}
+
 func truncate(buf bytes.Buffer) bytes.Buffer {
 	// Limit the number of bytes.
 	didTruncate := false
@@ -160,13 +235,14 @@ func removeWindowsCarriageReturns(b bytes.Buffer) bytes.Buffer {
 	return b
 }
 
-func (e *Exec) processCommand(command string, acc telegraf.Accumulator, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	out, errBuf, runErr := e.runner.run(command, e.Environment, time.Duration(e.Timeout))
+func (e *Exec) processCommand(j job, environment []string, acc telegraf.Accumulator) {
+	out, errBuf, runErr := e.runner.run(j.command, environment, j.timeout)
 	if !e.IgnoreError && !e.parseDespiteError && runErr != nil {
-		err := fmt.Errorf("exec: %w for command %q: %s", runErr, command, string(errBuf))
+		err := fmt.Errorf("exec: %w for command %q: %s", runErr, j.command, string(errBuf))
 		acc.AddError(err)
+		if e.StderrCapture != "" {
+			acc.AddMetric(e.failureMetric(j.command, runErr, errBuf))
+		}
 		return
 	}
 
@@ -186,19 +262,62 @@ func (e *Exec) processCommand(command string, acc telegraf.Accumulator, wg *sync
 		metrics = e.exitCodeHandler(metrics, runErr, errBuf)
 	}
 
+	// Attach the diagnostic output of failed commands
+	if runErr != nil && e.StderrCapture != "" {
+		if len(metrics) == 0 {
+			metrics = append(metrics, e.failureMetric(j.command, runErr, errBuf))
+		} else {
+			for _, m := range metrics {
+				e.addStderr(m, errBuf)
+			}
+		}
+	}
+
 	for _, m := range metrics {
 		acc.AddMetric(m)
 	}
 }
 
+// failureMetric creates a metric describing the failure of the given command
+func (e *Exec) failureMetric(command string, runErr error, stderr []byte) telegraf.Metric {
+	exitCode := -1
+	var exitErr *osexec.ExitError
+	if errors.As(runErr, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
+	m := metric.New(
+		"exec",
+		map[string]string{"command": command},
+		map[string]interface{}{"exit_code": exitCode},
+		time.Now(),
+	)
+	e.addStderr(m, stderr)
+	return m
+}
+
+func (e *Exec) addStderr(m telegraf.Metric, stderr []byte) {
+	msg := strings.TrimSpace(string(stderr))
+	if msg == "" {
+		return
+	}
+	switch e.StderrCapture {
+	case "field":
+		m.AddField(e.StderrCaptureKey, msg)
+	case "tag":
+		m.AddTag(e.StderrCaptureKey, msg)
+	}
+}
+
 func nagiosHandler(metrics []telegraf.Metric, err error, msg []byte) []telegraf.Metric {
 	return nagios.AddState(err, msg, metrics)
 }
 
 func newExec() *Exec {
 	return &Exec{
-		runner:  commandRunner{},
-		Timeout: config.Duration(time.Second * 5),
+		runner:           commandRunner{},
+		Timeout:          config.Duration(time.Second * 5),
+		StderrCaptureKey: "stderr",
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"errors"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
 	"github.com/influxdata/telegraf/testutil"
@@ -480,3 +482,179 @@ func TestCases(t *testing.T) {
 	actual := acc.GetTelegrafMetrics()
 	testutil.RequireMetricsEqual(t, expected, actual, options...)
 }
+
+// recordingRunner records the parameters of all executed commands
+type recordingRunner struct {
+	delay time.Duration
+
+	timeouts    map[string]time.Duration
+	environment []string
+	running     int
+	maxRunning  int
+	sync.Mutex
+}
+
+func (r *recordingRunner) run(command string, environment []string, timeout time.Duration) ([]byte, []byte, error) {
+	r.Lock()
+	if r.timeouts == nil {
+		r.timeouts = make(map[string]time.Duration)
+	}
+	r.timeouts[command] = timeout
+	r.environment = environment
+	r.running++
+	r.maxRunning = max(r.maxRunning, r.running)
+	r.Unlock()
+
+	time.Sleep(r.delay)
+
+	r.Lock()
+	r.running--
+	r.Unlock()
+
+	return []byte("1"), nil, nil
+}
+
+func TestCommandTimeouts(t *testing.T) {
+	runner := &recordingRunner{}
+	e := &Exec{
+		Commands:        []string{"fast", "slow arg"},
+		Timeout:         config.Duration(5 * time.Second),
+		CommandTimeouts: map[string]config.Duration{"slow arg": config.Duration(time.Minute)},
+		Log:             testutil.Logger{},
+		runner:          runner,
+	}
+	require.NoError(t, e.Init())
+	parser := &value.Parser{MetricName: "metric", DataType: "integer"}
+	require.NoError(t, parser.Init())
+	e.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(e.Gather))
+	require.Equal(t, map[string]time.Duration{
+		"fast":     5 * time.Second,
+		"slow arg": time.Minute,
+	}, runner.timeouts)
+}
+
+func TestMaxParallelCommands(t *testing.T) {
+	runner := &recordingRunner{delay: 50 * time.Millisecond}
+	e := &Exec{
+		Commands:            []string{"a", "b", "c", "d", "e"},
+		MaxParallelCommands: 2,
+		Log:                 testutil.Logger{},
+		runner:              runner,
+	}
+	require.NoError(t, e.Init())
+	parser := &value.Parser{MetricName: "metric", DataType: "integer"}
+	require.NoError(t, parser.Init())
+	e.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(e.Gather))
+	require.Len(t, runner.timeouts, 5)
+	require.Equal(t, 2, runner.maxRunning)
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+}
+
+func TestSecretEnvironment(t *testing.T) {
+	secret := config.NewSecret([]byte("secret_value"))
+	runner := &recordingRunner{}
+	e := &Exec{
+		Commands:          []string{"a"},
+		Environment:       []string{"PLAIN=value"},
+		SecretEnvironment: map[string]*config.Secret{"TOKEN": &secret},
+		Log:               testutil.Logger{},
+		runner:            runner,
+	}
+	require.NoError(t, e.Init())
+	parser := &value.Parser{MetricName: "metric", DataType: "integer"}
+	require.NoError(t, parser.Init())
+	e.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(e.Gather))
+	require.Equal(t, []string{"PLAIN=value", "TOKEN=secret_value"}, runner.environment)
+}
+
+func TestStderrCapture(t *testing.T) {
+	tests := []struct {
+		name        string
+		capture     string
+		ignoreError bool
+		out         string
+		expected    []telegraf.Metric
+		expectErr   bool
+	}{
+		{
+			name:      "failure metric as field",
+			capture:   "field",
+			expectErr: true,
+			expected: []telegraf.Metric{
+				metric.New(
+					"exec",
+					map[string]string{"command": "badcommand"},
+					map[string]interface{}{"exit_code": -1, "stderr": "something went wrong"},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:        "attached as tag",
+			capture:     "tag",
+			ignoreError: true,
+			out:         "test value=42i 1",
+			expected: []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"stderr": "something went wrong"},
+					map[string]interface{}{"value": int64(42)},
+					time.Unix(0, 1),
+				),
+			},
+		},
+		{
+			name:        "failure metric without output",
+			capture:     "tag",
+			ignoreError: true,
+			expected: []telegraf.Metric{
+				metric.New(
+					"exec",
+					map[string]string{"command": "badcommand", "stderr": "something went wrong"},
+					map[string]interface{}{"exit_code": -1},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &influx.Parser{}
+			require.NoError(t, parser.Init())
+
+			e := &Exec{
+				Commands:      []string{"badcommand"},
+				IgnoreError:   tt.ignoreError,
+				StderrCapture: tt.capture,
+				Log:           testutil.Logger{},
+				runner:        newRunnerMock([]byte(tt.out), []byte("something went wrong\n"), errors.New("exit status 1")),
+			}
+			require.NoError(t, e.Init())
+			e.SetParser(parser)
+
+			var acc testutil.Accumulator
+			err := acc.GatherError(e.Gather)
+			if tt.expectErr {
+				require.ErrorContains(t, err, "something went wrong")
+			} else {
+				require.NoError(t, err)
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestInitInvalidStderrCapture(t *testing.T) {
+	e := &Exec{StderrCapture: "log"}
+	require.ErrorContains(t, e.Init(), `invalid 'stderr_capture' setting "log"`)
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"strings"
 	"sync"
@@ -17,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/process"
 	"github.com/influxdata/telegraf/models"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 )
@@ -33,9 +35,12 @@ type Execd struct {
 	Signal       string          `toml:"signal"`
 	RestartDelay config.Duration `toml:"restart_delay"`
 	StopOnError  bool            `toml:"stop_on_error"`
+	Listen       string          `toml:"listen"`
 	Log          telegraf.Logger `toml:"-"`
+	common_tls.ServerConfig
 
 	process      *process.Process
+	listener     *listener
 	acc          telegraf.Accumulator
 	parser       telegraf.Parser
 	outputReader func(io.Reader)
@@ -46,6 +51,18 @@ func (*Execd) SampleConfig() string {
 }
 
 func (e *Execd) Init() error {
+	if e.Listen != "" {
+		if len(e.Command) > 0 {
+			return errors.New("'command' and 'listen' are mutually exclusive")
+		}
+		switch e.Signal {
+		case "none", "STDIN":
+		default:
+			return fmt.Errorf("signal %q not supported for listening", e.Signal)
+		}
+		return e.checkListen()
+	}
+
 	if len(e.Command) == 0 {
 		return errors.New("no command specified")
 	}
@@ -66,6 +83,10 @@ func (e *Execd) SetParser(parser telegraf.Parser) {
 
 func (e *Execd) Start(acc telegraf.Accumulator) error {
 	e.acc = acc
+	if e.Listen != "" {
+		return e.startListener()
+	}
+
 	var err error
 	e.process, err = process.New(e.Command, e.Environment)
 	if err != nil {
@@ -92,6 +113,10 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 }
 
 func (e *Execd) Stop() {
+	if e.listener != nil {
+		e.stopListener()
+		return
+	}
 	e.process.Stop()
 }
 
@@ -101,7 +126,7 @@ func (e *Execd) cmdReadOut(out io.Reader) {
 	for {
 		data, err := rdr.ReadBytes('\n')
 		if err != nil {
-			if errors.Is(err, io.EOF) || errors.Is(err, os.ErrClosed) {
+			if errors.Is(err, io.EOF) || errors.Is(err, os.ErrClosed) || errors.Is(err, net.ErrClosed) {
 				break
 			}
 			e.acc.AddError(fmt.Errorf("error reading stdout: %w", err))
@@ -131,7 +156,7 @@ func (e *Execd) cmdReadOutStream(out io.Reader) {
 	for {
 		metric, err := parser.Next()
 		if err != nil {
-			if errors.Is(err, influx.EOF) {
+			if errors.Is(err, influx.EOF) || errors.Is(err, net.ErrClosed) {
 				break // stream ended
 			}
 			var parseErr *influx.ParseError
@@ -0,0 +1,169 @@
+package execd
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// listener accepts connections of external plugins running the shim in
+// network mode and reads their metrics in the same way as from stdout
+type listener struct {
+	net.Listener
+
+	conns map[net.Conn]bool
+	wg    sync.WaitGroup
+	sync.Mutex
+}
+
+// checkListen validates the listen address. The connections are not
+// authenticated on the protocol level, so TCP listeners on addresses other
+// than loopback require clients to authenticate with a certificate.
+func (e *Execd) checkListen() error {
+	u, err := url.Parse(e.Listen)
+	if err != nil {
+		return fmt.Errorf("parsing listen address failed: %w", err)
+	}
+
+	switch u.Scheme {
+	case "tcp", "tcp4", "tcp6":
+		if isLoopback(u.Hostname()) {
+			return nil
+		}
+		if e.TLSCert == "" || e.TLSKey == "" || len(e.TLSAllowedCACerts) == 0 {
+			return fmt.Errorf("listening on non-loopback address %q requires mutual TLS, "+
+				"set 'tls_cert', 'tls_key' and 'tls_allowed_cacerts'", u.Host)
+		}
+	case "unix":
+	default:
+		return fmt.Errorf("unsupported listen scheme %q", u.Scheme)
+	}
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (e *Execd) startListener() error {
+	u, err := url.Parse(e.Listen)
+	if err != nil {
+		return fmt.Errorf("parsing listen address failed: %w", err)
+	}
+
+	var l net.Listener
+	switch u.Scheme {
+	case "tcp", "tcp4", "tcp6":
+		l, err = net.Listen(u.Scheme, u.Host)
+	case "unix":
+		path := u.Path
+		if path == "" {
+			path = u.Host
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing existing socket failed: %w", err)
+		}
+		l, err = net.Listen(u.Scheme, path)
+	default:
+		return fmt.Errorf("unsupported listen scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return fmt.Errorf("listening on %q failed: %w", e.Listen, err)
+	}
+
+	tlsCfg, err := e.ServerConfig.TLSConfig()
+	if err != nil {
+		l.Close()
+		return err
+	}
+	if tlsCfg != nil {
+		l = tls.NewListener(l, tlsCfg)
+	}
+
+	e.listener = &listener{
+		Listener: l,
+		conns:    make(map[net.Conn]bool),
+	}
+	e.Log.Infof("Listening for external plugins on %s", l.Addr())
+
+	e.listener.wg.Add(1)
+	go func() {
+		defer e.listener.wg.Done()
+		e.accept()
+	}()
+
+	return nil
+}
+
+func (e *Execd) accept() {
+	for {
+		conn, err := e.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				e.acc.AddError(fmt.Errorf("accepting connection failed: %w", err))
+			}
+			return
+		}
+
+		e.listener.Lock()
+		e.listener.conns[conn] = true
+		e.listener.Unlock()
+		e.Log.Debugf("External plugin connected from %s", conn.RemoteAddr())
+
+		e.listener.wg.Add(1)
+		go func() {
+			defer e.listener.wg.Done()
+			e.outputReader(conn)
+
+			e.listener.Lock()
+			delete(e.listener.conns, conn)
+			e.listener.Unlock()
+			conn.Close()
+			e.Log.Debugf("External plugin at %s disconnected", conn.RemoteAddr())
+		}()
+	}
+}
+
+// signalConnections requests all connected external plugins to gather metrics
+func (e *Execd) signalConnections() error {
+	if e.Signal != "STDIN" {
+		return nil
+	}
+
+	e.listener.Lock()
+	defer e.listener.Unlock()
+
+	var errs []error
+	for conn := range e.listener.conns {
+		if err := conn.SetWriteDeadline(time.Now().Add(1 * time.Second)); err != nil {
+			errs = append(errs, fmt.Errorf("setting write deadline for %s failed: %w", conn.RemoteAddr(), err))
+			continue
+		}
+		if _, err := io.WriteString(conn, "\n"); err != nil {
+			errs = append(errs, fmt.Errorf("signaling %s failed: %w", conn.RemoteAddr(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *Execd) stopListener() {
+	e.listener.Close()
+
+	e.listener.Lock()
+	for conn := range e.listener.conns {
+		conn.Close()
+	}
+	e.listener.Unlock()
+
+	e.listener.wg.Wait()
+}
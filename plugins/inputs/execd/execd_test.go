@@ -2,6 +2,7 @@ package execd
 
 import (
 	"bufio"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"os"
@@ -17,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/prometheus"
 	serializers_influx "github.com/influxdata/telegraf/plugins/serializers/influx"
@@ -348,6 +350,156 @@ func TestLoggingWithPrefix(t *testing.T) {
 	}
 }
 
+func TestListenInitFail(t *testing.T) {
+	e := &Execd{
+		Command: []string{"a"},
+		Listen:  "tcp://127.0.0.1:0",
+		Signal:  "none",
+	}
+	require.ErrorContains(t, e.Init(), "mutually exclusive")
+
+	e = &Execd{
+		Listen: "tcp://127.0.0.1:0",
+		Signal: "SIGHUP",
+	}
+	require.ErrorContains(t, e.Init(), `signal "SIGHUP" not supported for listening`)
+
+	e = &Execd{
+		Listen: "udp://127.0.0.1:0",
+		Signal: "none",
+	}
+	require.ErrorContains(t, e.Init(), `unsupported listen scheme "udp"`)
+}
+
+func TestListenRequireMutualTLS(t *testing.T) {
+	pki := testutil.NewPKI("../../../testutil/pki")
+	serverTLS := pki.TLSServerConfig()
+	serverOnlyTLS := *serverTLS
+	serverOnlyTLS.TLSAllowedCACerts = nil
+
+	tests := []struct {
+		name     string
+		listen   string
+		tls      common_tls.ServerConfig
+		expected string
+	}{
+		{
+			name:   "loopback",
+			listen: "tcp://127.0.0.1:7080",
+		},
+		{
+			name:   "loopback ipv6",
+			listen: "tcp6://[::1]:7080",
+		},
+		{
+			name:   "localhost",
+			listen: "tcp://localhost:7080",
+		},
+		{
+			name:   "unix socket",
+			listen: "unix:///tmp/execd.sock",
+		},
+		{
+			name:     "all interfaces",
+			listen:   "tcp://:7080",
+			expected: `listening on non-loopback address ":7080" requires mutual TLS`,
+		},
+		{
+			name:     "remote address",
+			listen:   "tcp://192.168.1.1:7080",
+			expected: `listening on non-loopback address "192.168.1.1:7080" requires mutual TLS`,
+		},
+		{
+			name:     "server certificate only",
+			listen:   "tcp://:7080",
+			tls:      serverOnlyTLS,
+			expected: `listening on non-loopback address ":7080" requires mutual TLS`,
+		},
+		{
+			name:   "mutual TLS",
+			listen: "tcp://:7080",
+			tls:    *serverTLS,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Execd{
+				Listen:       tt.listen,
+				Signal:       "none",
+				ServerConfig: tt.tls,
+			}
+			if tt.expected == "" {
+				require.NoError(t, e.Init())
+			} else {
+				require.ErrorContains(t, e.Init(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestListenWithMutualTLS(t *testing.T) {
+	pki := testutil.NewPKI("../../../testutil/pki")
+
+	influxParser := models.NewRunningParser(&influx.Parser{}, &models.ParserConfig{})
+	require.NoError(t, influxParser.Init())
+
+	e := &Execd{
+		Listen:       "tcp://127.0.0.1:0",
+		Signal:       "STDIN",
+		BufferSize:   config.Size(64 * 1024),
+		ServerConfig: *pki.TLSServerConfig(),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, e.Init())
+	e.SetParser(influxParser)
+
+	metrics := make(chan telegraf.Metric, 10)
+	defer close(metrics)
+	acc := agent.NewAccumulator(&TestMetricMaker{}, metrics)
+
+	require.NoError(t, e.Start(acc))
+	defer e.Stop()
+
+	// Connections without client certificate must be rejected
+	clientCfg, err := pki.TLSClientConfig().TLSConfig()
+	require.NoError(t, err)
+	insecureCfg := &tls.Config{RootCAs: clientCfg.RootCAs, ServerName: clientCfg.ServerName}
+	insecureConn, err := tls.Dial("tcp", e.listener.Addr().String(), insecureCfg)
+	if err == nil {
+		defer insecureConn.Close()
+		_, err = insecureConn.Read(make([]byte, 1))
+	}
+	require.Error(t, err)
+
+	// Connect like an external plugin running the shim
+	conn, err := tls.Dial("tcp", e.listener.Addr().String(), clientCfg)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.Handshake())
+
+	// Wait for the connection to be registered and signal the plugin
+	require.Eventually(t, func() bool {
+		e.listener.Lock()
+		defer e.listener.Unlock()
+		return len(e.listener.conns) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, e.Gather(acc))
+
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "\n", line)
+
+	_, err = conn.Write([]byte("test value=42i 1234\n"))
+	require.NoError(t, err)
+
+	m := readChanWithTimeout(t, metrics, 10*time.Second)
+	testutil.RequireMetricEqual(t,
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(42)}, time.Unix(0, 1234)),
+		m,
+	)
+}
+
 func readChanWithTimeout(t *testing.T, metrics chan telegraf.Metric, timeout time.Duration) telegraf.Metric {
 	to := time.NewTimer(timeout)
 	defer to.Stop()
@@ -13,6 +13,10 @@ import (
 )
 
 func (e *Execd) Gather(_ telegraf.Accumulator) error {
+	if e.listener != nil {
+		return e.signalConnections()
+	}
+
 	if e.process == nil {
 		return nil
 	}
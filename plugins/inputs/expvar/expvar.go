@@ -0,0 +1,400 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package expvar
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Expvar struct {
+	URLs       []string          `toml:"urls"`
+	Username   config.Secret     `toml:"username"`
+	Password   config.Secret     `toml:"password"`
+	VarInclude []string          `toml:"var_include"`
+	VarExclude []string          `toml:"var_exclude"`
+	Separator  string            `toml:"separator"`
+	MapTags    map[string]string `toml:"map_tags"`
+	Types      map[string]string `toml:"types"`
+	Log        telegraf.Logger   `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client    *http.Client
+	varFilter filter.Filter
+	mapTags   []pattern
+	types     []pattern
+}
+
+// pattern associates a glob pattern with a value such as a tag name or type
+type pattern struct {
+	filter filter.Filter
+	value  string
+}
+
+func (*Expvar) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Expvar) Init() error {
+	if len(e.URLs) == 0 {
+		e.URLs = []string{"http://localhost:8080/debug/vars"}
+	}
+	if e.Separator == "" {
+		e.Separator = "_"
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(e.VarInclude, e.VarExclude)
+	if err != nil {
+		return fmt.Errorf("creating variable filter failed: %w", err)
+	}
+	e.varFilter = f
+
+	e.mapTags, err = compilePatterns(e.MapTags)
+	if err != nil {
+		return fmt.Errorf("compiling map_tags failed: %w", err)
+	}
+	for _, p := range e.mapTags {
+		if p.value == "" {
+			return errors.New("empty tag name in map_tags")
+		}
+	}
+
+	e.types, err = compilePatterns(e.Types)
+	if err != nil {
+		return fmt.Errorf("compiling types failed: %w", err)
+	}
+	for _, p := range e.types {
+		switch p.value {
+		case "int", "uint", "float", "bool", "string":
+		default:
+			return fmt.Errorf("invalid type %q: use int, uint, float, bool or string", p.value)
+		}
+	}
+
+	client, err := e.HTTPClientConfig.CreateClient(context.Background(), e.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	e.client = client
+
+	return nil
+}
+
+func (e *Expvar) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range e.URLs {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			if err := e.gatherURL(acc, url); err != nil {
+				acc.AddError(fmt.Errorf("gathering %q failed: %w", url, err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (e *Expvar) Stop() {
+	if e.client != nil {
+		e.client.CloseIdleConnections()
+	}
+}
+
+func (e *Expvar) gatherURL(acc telegraf.Accumulator, url string) error {
+	vars, err := e.fetch(url)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if e.varFilter.Match(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	scalars := make(map[string]interface{})
+	for _, name := range names {
+		tags := map[string]string{"url": url}
+
+		// The memory statistics of the Go runtime are published by every
+		// service so convert them to the usual field naming
+		if name == "memstats" {
+			var m runtime.MemStats
+			if err := json.Unmarshal(vars[name], &m); err != nil {
+				acc.AddError(fmt.Errorf("decoding memstats of %q failed: %w", url, err))
+				continue
+			}
+			acc.AddFields("expvar_memstats", memstatsFields(&m), tags, now)
+			continue
+		}
+
+		value, err := decode(vars[name])
+		if err != nil {
+			acc.AddError(fmt.Errorf("decoding variable %q of %q failed: %w", name, url, err))
+			continue
+		}
+
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			if v, ok := e.convert(name, value); ok {
+				scalars[name] = v
+			}
+			continue
+		}
+
+		// Create a metric per entry for maps keyed by e.g. handlers
+		if tag, found := lookup(e.mapTags, name); found {
+			for key, entry := range obj {
+				fields := make(map[string]interface{})
+				if sub, ok := entry.(map[string]interface{}); ok {
+					e.flatten(fields, name, "", sub)
+				} else if v, ok := e.convert(name+e.Separator+"value", entry); ok {
+					fields["value"] = v
+				}
+				if len(fields) == 0 {
+					continue
+				}
+
+				entryTags := map[string]string{"url": url, tag: key}
+				acc.AddFields("expvar_"+name, fields, entryTags, now)
+			}
+			continue
+		}
+
+		fields := make(map[string]interface{})
+		e.flatten(fields, name, "", obj)
+		if len(fields) > 0 {
+			acc.AddFields("expvar_"+name, fields, tags, now)
+		}
+	}
+
+	if len(scalars) > 0 {
+		acc.AddFields("expvar", scalars, map[string]string{"url": url}, now)
+	}
+
+	return nil
+}
+
+func (e *Expvar) fetch(url string) (map[string]json.RawMessage, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("Accept", "application/json")
+
+	if !e.Username.Empty() || !e.Password.Empty() {
+		username, err := e.Username.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting username failed: %w", err)
+		}
+		defer username.Destroy()
+
+		password, err := e.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password failed: %w", err)
+		}
+		defer password.Destroy()
+
+		req.SetBasicAuth(username.String(), password.String())
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %q", resp.Status)
+	}
+
+	var vars map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	return vars, nil
+}
+
+// flatten adds the leaf values of the given object to the fields joining the
+// keys using the separator. Arrays and null values are skipped.
+func (e *Expvar) flatten(fields map[string]interface{}, name, prefix string, obj map[string]interface{}) {
+	for key, value := range obj {
+		if prefix != "" {
+			key = prefix + e.Separator + key
+		}
+		if sub, ok := value.(map[string]interface{}); ok {
+			e.flatten(fields, name, key, sub)
+			continue
+		}
+		if v, ok := e.convert(name+e.Separator+key, value); ok {
+			fields[key] = v
+		}
+	}
+}
+
+// convert applies the type hint matching the path to a leaf value and reports
+// whether the value should be added as field
+func (e *Expvar) convert(path string, value interface{}) (interface{}, bool) {
+	switch value.(type) {
+	case int64, uint64, float64, bool, string:
+	default:
+		return nil, false
+	}
+
+	typ, found := lookup(e.types, path)
+	if !found {
+		return value, true
+	}
+
+	var v interface{}
+	var err error
+	switch typ {
+	case "int":
+		v, err = internal.ToInt64(value)
+	case "uint":
+		v, err = internal.ToUint64(value)
+	case "float":
+		v, err = internal.ToFloat64(value)
+	case "bool":
+		v, err = internal.ToBool(value)
+	case "string":
+		v, err = internal.ToString(value)
+	}
+	if err != nil {
+		e.Log.Debugf("Converting %q to %s failed: %v", path, typ, err)
+		return nil, false
+	}
+	return v, true
+}
+
+// decode parses a variable keeping the precision of integers
+func decode(raw json.RawMessage) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return normalize(value), nil
+}
+
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, entry := range v {
+			v[key] = normalize(entry)
+		}
+		return v
+	case json.Number:
+		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return u
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return nil
+	}
+	return value
+}
+
+func lookup(patterns []pattern, path string) (string, bool) {
+	for _, p := range patterns {
+		if p.filter.Match(path) {
+			return p.value, true
+		}
+	}
+	return "", false
+}
+
+// compilePatterns compiles the keys of the given map sorted by their name to
+// get a deterministic order of matching
+func compilePatterns(m map[string]string) ([]pattern, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	patterns := make([]pattern, 0, len(keys))
+	for _, k := range keys {
+		f, err := filter.Compile([]string{k})
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", k, err)
+		}
+		patterns = append(patterns, pattern{filter: f, value: m[k]})
+	}
+	return patterns, nil
+}
+
+func memstatsFields(m *runtime.MemStats) map[string]interface{} {
+	return map[string]interface{}{
+		"alloc":           m.Alloc,
+		"total_alloc":     m.TotalAlloc,
+		"sys":             m.Sys,
+		"lookups":         m.Lookups,
+		"mallocs":         m.Mallocs,
+		"frees":           m.Frees,
+		"heap_alloc":      m.HeapAlloc,
+		"heap_sys":        m.HeapSys,
+		"heap_idle":       m.HeapIdle,
+		"heap_inuse":      m.HeapInuse,
+		"heap_released":   m.HeapReleased,
+		"heap_objects":    m.HeapObjects,
+		"stack_inuse":     m.StackInuse,
+		"stack_sys":       m.StackSys,
+		"mspan_inuse":     m.MSpanInuse,
+		"mspan_sys":       m.MSpanSys,
+		"mcache_inuse":    m.MCacheInuse,
+		"mcache_sys":      m.MCacheSys,
+		"buck_hash_sys":   m.BuckHashSys,
+		"gc_sys":          m.GCSys,
+		"other_sys":       m.OtherSys,
+		"next_gc":         m.NextGC,
+		"last_gc":         m.LastGC,
+		"pause_total_ns":  m.PauseTotalNs,
+		"pause_ns":        m.PauseNs[(m.NumGC+255)%256],
+		"num_gc":          m.NumGC,
+		"num_forced_gc":   m.NumForcedGC,
+		"gc_cpu_fraction": m.GCCPUFraction,
+	}
+}
+
+func init() {
+	inputs.Add("expvar", func() telegraf.Input {
+		return &Expvar{
+			Separator: "_",
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
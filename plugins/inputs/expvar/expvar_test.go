@@ -0,0 +1,234 @@
+package expvar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInit(t *testing.T) {
+	plugin := &Expvar{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{"http://localhost:8080/debug/vars"}, plugin.URLs)
+	require.Equal(t, "_", plugin.Separator)
+
+	plugin = &Expvar{
+		Types: map[string]string{"foo": "integer"},
+		Log:   testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid type "integer"`)
+
+	plugin = &Expvar{
+		MapTags: map[string]string{"foo": ""},
+		Log:     testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "empty tag name in map_tags")
+}
+
+func TestGather(t *testing.T) {
+	buf, err := os.ReadFile("testdata/vars.json")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/debug/vars" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	url := server.URL + "/debug/vars"
+
+	plugin := &Expvar{
+		URLs: []string{url},
+		MapTags: map[string]string{
+			"http_requests": "handler",
+			"pool":          "worker",
+		},
+		Types: map[string]string{
+			"cache_size": "float",
+			"uptime":     "string",
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"expvar",
+			map[string]string{"url": url},
+			map[string]interface{}{
+				"uptime":  "3600",
+				"version": "1.2.3",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"expvar_cache",
+			map[string]string{"url": url},
+			map[string]interface{}{
+				"hits":   int64(120),
+				"misses": int64(30),
+				"ratio":  float64(0.8),
+				"size":   float64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"expvar_db",
+			map[string]string{"url": url},
+			map[string]interface{}{
+				"conns_open": int64(5),
+				"conns_idle": int64(2),
+				"name":       "users",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"expvar_http_requests",
+			map[string]string{"url": url, "handler": "/api"},
+			map[string]interface{}{"value": int64(12)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"expvar_http_requests",
+			map[string]string{"url": url, "handler": "/health"},
+			map[string]interface{}{"value": int64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"expvar_memstats",
+			map[string]string{"url": url},
+			map[string]interface{}{
+				"alloc":           uint64(2101816),
+				"total_alloc":     uint64(11582072),
+				"sys":             uint64(13219856),
+				"lookups":         uint64(0),
+				"mallocs":         uint64(68713),
+				"frees":           uint64(62019),
+				"heap_alloc":      uint64(2101816),
+				"heap_sys":        uint64(7503872),
+				"heap_idle":       uint64(4276224),
+				"heap_inuse":      uint64(3227648),
+				"heap_released":   uint64(2801664),
+				"heap_objects":    uint64(6694),
+				"stack_inuse":     uint64(491520),
+				"stack_sys":       uint64(491520),
+				"mspan_inuse":     uint64(73440),
+				"mspan_sys":       uint64(97920),
+				"mcache_inuse":    uint64(4800),
+				"mcache_sys":      uint64(15600),
+				"buck_hash_sys":   uint64(1449211),
+				"gc_sys":          uint64(2787984),
+				"other_sys":       uint64(873749),
+				"next_gc":         uint64(4194304),
+				"last_gc":         uint64(1736499998123456789),
+				"pause_total_ns":  uint64(1130794),
+				"pause_ns":        uint64(62711),
+				"num_gc":          uint64(2),
+				"num_forced_gc":   uint64(1),
+				"gc_cpu_fraction": float64(0.0000166),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"expvar_pool",
+			map[string]string{"url": url, "worker": "a"},
+			map[string]interface{}{
+				"busy": true,
+				"jobs": int64(7),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"expvar_pool",
+			map[string]string{"url": url, "worker": "b"},
+			map[string]interface{}{
+				"busy": false,
+				"jobs": uint64(18446744073709551615),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if _, err := w.Write([]byte(`{"memstats": {"Alloc": 1}, "a_count": 1, "a_total": 2, "b_count": 3}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Expvar{
+		URLs:       []string{server.URL},
+		VarInclude: []string{"a_*", "memstats"},
+		VarExclude: []string{"memstats"},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"expvar",
+			map[string]string{"url": server.URL},
+			map[string]interface{}{
+				"a_count": int64(1),
+				"a_total": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if _, err := w.Write([]byte(`{"uptime": 10}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Expvar{
+		URLs:     []string{server.URL},
+		Username: config.NewSecret([]byte("user")),
+		Password: config.NewSecret([]byte("wrong")),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, acc.GatherError(plugin.Gather), "401 Unauthorized")
+
+	plugin.Password = config.NewSecret([]byte("secret"))
+	acc = testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
@@ -4,9 +4,11 @@ package filecount
 import (
 	_ "embed"
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/karrick/godirwalk"
@@ -21,15 +23,16 @@ import (
 var sampleConfig string
 
 type FileCount struct {
-	Directory      string          `toml:"directory" deprecated:"1.9.0;1.35.0;use 'directories' instead"`
-	Directories    []string        `toml:"directories"`
-	Name           string          `toml:"name"`
-	Recursive      bool            `toml:"recursive"`
-	RegularOnly    bool            `toml:"regular_only"`
-	FollowSymlinks bool            `toml:"follow_symlinks"`
-	Size           config.Size     `toml:"size"`
-	MTime          config.Duration `toml:"mtime"`
-	Log            telegraf.Logger `toml:"-"`
+	Directory      string            `toml:"directory" deprecated:"1.9.0;1.35.0;use 'directories' instead"`
+	Directories    []string          `toml:"directories"`
+	Name           string            `toml:"name"`
+	Recursive      bool              `toml:"recursive"`
+	RegularOnly    bool              `toml:"regular_only"`
+	FollowSymlinks bool              `toml:"follow_symlinks"`
+	Size           config.Size       `toml:"size"`
+	MTime          config.Duration   `toml:"mtime"`
+	AgeBuckets     []config.Duration `toml:"age_buckets"`
+	Log            telegraf.Logger   `toml:"-"`
 
 	fs          fileSystem
 	fileFilters []fileFilterFunc
@@ -42,6 +45,18 @@ func (*FileCount) SampleConfig() string {
 	return sampleConfig
 }
 
+func (fc *FileCount) Init() error {
+	for i, bound := range fc.AgeBuckets {
+		if bound <= 0 {
+			return fmt.Errorf("age bucket %s must be positive", time.Duration(bound))
+		}
+		if i > 0 && bound <= fc.AgeBuckets[i-1] {
+			return errors.New("age buckets must be in ascending order")
+		}
+	}
+	return nil
+}
+
 func (fc *FileCount) Gather(acc telegraf.Accumulator) error {
 	if fc.globPaths == nil {
 		fc.initGlobPaths(acc)
@@ -143,6 +158,8 @@ func (fc *FileCount) count(acc telegraf.Accumulator, basedir string, glob globpa
 	childSize := make(map[string]int64)
 	oldestFileTimestamp := make(map[string]int64)
 	newestFileTimestamp := make(map[string]int64)
+	ageCounts := make(map[string][]int64)
+	now := time.Now()
 
 	walkFn := func(path string, _ *godirwalk.Dirent) error {
 		rel, err := filepath.Rel(basedir, path)
@@ -171,6 +188,12 @@ func (fc *FileCount) count(acc telegraf.Accumulator, basedir string, glob globpa
 			if newestFileTimestamp[parent] == 0 || newestFileTimestamp[parent] < file.ModTime().UnixNano() {
 				newestFileTimestamp[parent] = file.ModTime().UnixNano()
 			}
+			if len(fc.AgeBuckets) > 0 {
+				if ageCounts[parent] == nil {
+					ageCounts[parent] = make([]int64, len(fc.AgeBuckets)+1)
+				}
+				ageCounts[parent][fc.ageBucket(now.Sub(file.ModTime()))]++
+			}
 		}
 		if file.IsDir() && !fc.Recursive && !glob.HasSuperMeta {
 			return filepath.SkipDir
@@ -190,6 +213,9 @@ func (fc *FileCount) count(acc telegraf.Accumulator, basedir string, glob globpa
 				map[string]string{
 					"directory": path,
 				})
+			if len(fc.AgeBuckets) > 0 {
+				fc.addAgeHistogram(acc, path, ageCounts[path])
+			}
 		}
 		parent := filepath.Dir(path)
 		if fc.Recursive {
@@ -201,11 +227,20 @@ func (fc *FileCount) count(acc telegraf.Accumulator, basedir string, glob globpa
 			if newestFileTimestamp[parent] == 0 || newestFileTimestamp[parent] < newestFileTimestamp[path] {
 				newestFileTimestamp[parent] = newestFileTimestamp[path]
 			}
+			if counts := ageCounts[path]; counts != nil {
+				if ageCounts[parent] == nil {
+					ageCounts[parent] = make([]int64, len(counts))
+				}
+				for i, c := range counts {
+					ageCounts[parent][i] += c
+				}
+			}
 		}
 		delete(childCount, path)
 		delete(childSize, path)
 		delete(oldestFileTimestamp, path)
 		delete(newestFileTimestamp, path)
+		delete(ageCounts, path)
 		return nil
 	}
 
@@ -227,6 +262,43 @@ func (fc *FileCount) count(acc telegraf.Accumulator, basedir string, glob globpa
 	}
 }
 
+// ageBucket returns the index of the first bucket the age fits in or the
+// index of the overflow bucket
+func (fc *FileCount) ageBucket(age time.Duration) int {
+	for i, bound := range fc.AgeBuckets {
+		if age <= time.Duration(bound) {
+			return i
+		}
+	}
+	return len(fc.AgeBuckets)
+}
+
+// addAgeHistogram emits the cumulative number of files per age bucket of the
+// directory, i.e. the number of files not older than the bucket's bound
+func (fc *FileCount) addAgeHistogram(acc telegraf.Accumulator, path string, counts []int64) {
+	var total int64
+	for i, bound := range fc.AgeBuckets {
+		if counts != nil {
+			total += counts[i]
+		}
+		acc.AddGauge("filecount_age",
+			map[string]interface{}{"count": total},
+			map[string]string{
+				"directory": path,
+				"le":        strconv.FormatFloat(time.Duration(bound).Seconds(), 'f', -1, 64),
+			})
+	}
+	if counts != nil {
+		total += counts[len(fc.AgeBuckets)]
+	}
+	acc.AddGauge("filecount_age",
+		map[string]interface{}{"count": total},
+		map[string]string{
+			"directory": path,
+			"le":        "+Inf",
+		})
+}
+
 func (fc *FileCount) filter(file os.FileInfo) (bool, error) {
 	if fc.fileFilters == nil {
 		fc.initFileFilters()
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -175,6 +176,35 @@ func TestDirectoryWithTrailingSlash(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
 
+func TestInitInvalidAgeBuckets(t *testing.T) {
+	plugin := &FileCount{AgeBuckets: []config.Duration{config.Duration(time.Hour), config.Duration(time.Minute)}}
+	require.ErrorContains(t, plugin.Init(), "ascending order")
+
+	plugin = &FileCount{AgeBuckets: []config.Duration{0}}
+	require.ErrorContains(t, plugin.Init(), "must be positive")
+}
+
+func TestAgeBuckets(t *testing.T) {
+	// Only the file "baz" of 2010 is older than the second bucket
+	bound := time.Since(time.Date(2012, time.January, 1, 0, 0, 0, 0, time.UTC))
+	fc := getNoFilterFileCount()
+	fc.AgeBuckets = []config.Duration{config.Duration(time.Hour), config.Duration(bound)}
+	require.NoError(t, fc.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(fc.Gather))
+
+	expected := map[string]int64{
+		"3600": 0,
+		strconv.FormatFloat(bound.Seconds(), 'f', -1, 64): 8,
+		"+Inf": 9,
+	}
+	for le, count := range expected {
+		tags := map[string]string{"directory": getTestdataDir(), "le": le}
+		require.Truef(t, acc.HasPoint("filecount_age", tags, "count", count), "bucket %q", le)
+	}
+}
+
 func getNoFilterFileCount() FileCount {
 	return FileCount{
 		Log:         testutil.Logger{},
@@ -2,13 +2,21 @@
 package filestat
 
 import (
-	"crypto/md5" //nolint:gosec // G501: Blocklisted import crypto/md5: weak cryptographic primitive - md5 hash is what is desired in this case
+	"crypto/md5"  //nolint:gosec // G501: Blocklisted import crypto/md5: weak cryptographic primitive - md5 hash is what is desired in this case
+	"crypto/sha1" //nolint:gosec // G505: Blocklisted import crypto/sha1: weak cryptographic primitive - sha1 hash is what is desired in this case
+	"crypto/sha256"
 	_ "embed"
 	"encoding/hex"
+	"fmt"
+	"hash"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/internal/globpath"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -16,9 +24,15 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+var hashAlgorithms = []string{"md5", "sha1", "sha256"}
+
 type FileStat struct {
-	Md5   bool     `toml:"md5"`
-	Files []string `toml:"files"`
+	Md5           bool     `toml:"md5"`
+	Hash          []string `toml:"hash"`
+	HashCache     bool     `toml:"hash_cache"`
+	DirectoryHash bool     `toml:"directory_hash"`
+	ChangeEvents  bool     `toml:"change_events"`
+	Files         []string `toml:"files"`
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -29,15 +43,37 @@ type FileStat struct {
 	missingFiles map[string]bool
 	// files that had an error in Stat - we only log the first error.
 	filesWithErrors map[string]bool
+
+	// hashes of the files of the last gather cycle
+	hashCache map[string]*fileState
+	// state of the matched files of the last gather cycle for change events
+	lastState map[string]*fileState
+}
+
+// fileState contains the information used to detect changes of a file
+type fileState struct {
+	size    int64
+	modTime time.Time
+	sums    map[string]string
 }
 
 func (*FileStat) SampleConfig() string {
 	return sampleConfig
 }
 
+func (f *FileStat) Init() error {
+	if err := choice.CheckSlice(f.Hash, hashAlgorithms); err != nil {
+		return fmt.Errorf("invalid 'hash' setting: %w", err)
+	}
+	return nil
+}
+
 func (f *FileStat) Gather(acc telegraf.Accumulator) error {
 	var err error
 
+	algorithms := f.algorithms()
+	hashCache := make(map[string]*fileState)
+	current := make(map[string]*fileState)
 	for _, filepath := range f.Files {
 		// Get the compiled glob object for this filepath
 		g, ok := f.globs[filepath]
@@ -92,42 +128,226 @@ func (f *FileStat) Gather(acc telegraf.Accumulator) error {
 				fields["modification_time"] = fileInfo.ModTime().UnixNano()
 			}
 
-			if f.Md5 {
-				md5Hash, err := getMd5(fileName)
+			var sums map[string]string
+			if len(algorithms) > 0 {
+				if fileInfo != nil && fileInfo.IsDir() && f.DirectoryHash {
+					sums, err = f.hashDirectory(fileName, algorithms, hashCache)
+				} else {
+					sums, err = f.hashFile(fileName, fileInfo, algorithms, hashCache)
+				}
 				if err != nil {
 					acc.AddError(err)
-				} else {
-					fields["md5_sum"] = md5Hash
+				}
+				for algo, sum := range sums {
+					fields[algo+"_sum"] = sum
+				}
+			}
+
+			if fileInfo != nil {
+				current[fileName] = &fileState{
+					size:    fileInfo.Size(),
+					modTime: fileInfo.ModTime(),
+					sums:    sums,
 				}
 			}
 
 			acc.AddFields("filestat", fields, tags)
 		}
 	}
+	f.hashCache = hashCache
+
+	if f.ChangeEvents {
+		if f.lastState != nil {
+			f.emitChangeEvents(acc, current)
+		}
+		f.lastState = current
+	}
 
 	return nil
 }
 
-// Read given file and calculate a md5 hash.
-func getMd5(file string) (string, error) {
+// algorithms returns the list of hash algorithms to compute
+func (f *FileStat) algorithms() []string {
+	algorithms := make([]string, 0, len(f.Hash)+1)
+	if f.Md5 {
+		algorithms = append(algorithms, "md5")
+	}
+	for _, algo := range f.Hash {
+		if !choice.Contains(algo, algorithms) {
+			algorithms = append(algorithms, algo)
+		}
+	}
+	return algorithms
+}
+
+// hashFile computes the hashes of the given file. If the hash cache is enabled
+// and neither size nor modification time changed since the last gather cycle,
+// the hashes of the last cycle are used.
+func (f *FileStat) hashFile(fn string, info os.FileInfo, algorithms []string, cache map[string]*fileState) (map[string]string, error) {
+	if f.HashCache && info != nil {
+		if c, found := f.hashCache[fn]; found && c.size == info.Size() && c.modTime.Equal(info.ModTime()) {
+			cache[fn] = c
+			return c.sums, nil
+		}
+	}
+
+	sums, err := getHashes(fn, algorithms)
+	if err != nil {
+		return nil, err
+	}
+	if info != nil {
+		cache[fn] = &fileState{size: info.Size(), modTime: info.ModTime(), sums: sums}
+	}
+	return sums, nil
+}
+
+// hashDirectory computes the hashes of a directory recursively over the names
+// and content hashes of all regular files contained in the directory tree.
+// Symbolic links are not followed.
+func (f *FileStat) hashDirectory(dir string, algorithms []string, cache map[string]*fileState) (map[string]string, error) {
+	hashers := make(map[string]hash.Hash, len(algorithms))
+	for _, algo := range algorithms {
+		hashers[algo] = newHash(algo)
+	}
+
+	// WalkDir visits the entries in lexical order, so the result is stable
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sums, err := f.hashFile(path, info, algorithms, cache)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		for algo, h := range hashers {
+			fmt.Fprintf(h, "%s\x00%s\n", filepath.ToSlash(rel), sums[algo])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("hashing directory %q failed: %w", dir, err)
+	}
+
+	sums := make(map[string]string, len(hashers))
+	for algo, h := range hashers {
+		sums[algo] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
+
+// emitChangeEvents compares the current state of the files with the one of
+// the last gather cycle and emits an event for each created, modified or
+// deleted file
+func (f *FileStat) emitChangeEvents(acc telegraf.Accumulator, current map[string]*fileState) {
+	for fn, state := range current {
+		var event string
+		if last, found := f.lastState[fn]; !found {
+			event = "created"
+		} else if state.changed(last) {
+			event = "modified"
+		} else {
+			continue
+		}
+
+		fields := map[string]interface{}{
+			"exists":            int64(1),
+			"size_bytes":        state.size,
+			"modification_time": state.modTime.UnixNano(),
+		}
+		for algo, sum := range state.sums {
+			fields[algo+"_sum"] = sum
+		}
+		acc.AddFields("filestat_event", fields, map[string]string{"file": fn, "event": event})
+	}
+
+	for fn := range f.lastState {
+		if _, found := current[fn]; !found {
+			acc.AddFields("filestat_event",
+				map[string]interface{}{"exists": int64(0)},
+				map[string]string{"file": fn, "event": "deleted"},
+			)
+		}
+	}
+}
+
+// changed returns true if the content of the file changed. If hashes are
+// available those are compared, otherwise size and modification time are used.
+func (s *fileState) changed(last *fileState) bool {
+	if len(s.sums) > 0 && len(last.sums) > 0 {
+		for algo, sum := range s.sums {
+			if last.sums[algo] != sum {
+				return true
+			}
+		}
+		return false
+	}
+	return s.size != last.size || !s.modTime.Equal(last.modTime)
+}
+
+func newHash(algo string) hash.Hash {
+	switch algo {
+	case "sha1":
+		//nolint:gosec // G401: Use of weak cryptographic primitive - sha1 hash is what is desired in this case
+		return sha1.New()
+	case "sha256":
+		return sha256.New()
+	default:
+		//nolint:gosec // G401: Use of weak cryptographic primitive - md5 hash is what is desired in this case
+		return md5.New()
+	}
+}
+
+// Read given file and calculate the hashes in a single pass.
+func getHashes(file string, algorithms []string) (map[string]string, error) {
 	of, err := os.Open(file)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer of.Close()
 
-	//nolint:gosec // G401: Use of weak cryptographic primitive - md5 hash is what is desired in this case
-	hash := md5.New()
-	_, err = io.Copy(hash, of)
-	if err != nil {
+	hashers := make(map[string]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, 0, len(algorithms))
+	for _, algo := range algorithms {
+		h := newHash(algo)
+		hashers[algo] = h
+		writers = append(writers, h)
+	}
+
+	if _, err := io.Copy(io.MultiWriter(writers...), of); err != nil {
 		// fatal error
+		return nil, err
+	}
+
+	sums := make(map[string]string, len(hashers))
+	for algo, h := range hashers {
+		sums[algo] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
+
+// Read given file and calculate a md5 hash.
+func getMd5(file string) (string, error) {
+	sums, err := getHashes(file, []string{"md5"})
+	if err != nil {
 		return "", err
 	}
-	return hex.EncodeToString(hash.Sum(nil)), nil
+	return sums["md5"], nil
 }
 
 func newFileStat() *FileStat {
 	return &FileStat{
+		HashCache:       true,
 		globs:           make(map[string]*globpath.GlobPath),
 		missingFiles:    make(map[string]bool),
 		filesWithErrors: make(map[string]bool),
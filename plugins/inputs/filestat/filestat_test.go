@@ -9,9 +9,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -200,6 +203,153 @@ func TestGetMd5(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestInitInvalidHash(t *testing.T) {
+	fs := newFileStat()
+	fs.Hash = []string{"sha256", "crc32"}
+	require.ErrorContains(t, fs.Init(), "invalid 'hash' setting")
+}
+
+func TestGatherHashes(t *testing.T) {
+	fs := newFileStat()
+	fs.Log = testutil.Logger{}
+	fs.Md5 = true
+	fs.Hash = []string{"sha1", "sha256", "md5"}
+	fs.Files = []string{filepath.Join(testdataDir, "test.conf")}
+	require.NoError(t, fs.Init())
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(fs.Gather))
+
+	tags := map[string]string{"file": filepath.Join(testdataDir, "test.conf")}
+	require.True(t, acc.HasPoint("filestat", tags, "md5_sum", "5a7e9b77fa25e7bb411dbd17cf403c1f"))
+	require.True(t, acc.HasPoint("filestat", tags, "sha1_sum", "473020ddf5907cca66503c55912d1226b87548a1"))
+	require.True(t, acc.HasPoint("filestat", tags, "sha256_sum", "9b17fa34411e1ee1f1795b0e326f187ba7acde5ab6fc8e011ff3b0a550f9dbe2"))
+}
+
+func TestHashCache(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "data.txt")
+	require.NoError(t, os.WriteFile(fn, []byte("foo"), 0600))
+	mtime := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(fn, mtime, mtime))
+
+	for _, cached := range []bool{true, false} {
+		fs := newFileStat()
+		fs.Log = testutil.Logger{}
+		fs.Md5 = true
+		fs.HashCache = cached
+		fs.Files = []string{fn}
+		require.NoError(t, fs.Init())
+
+		require.NoError(t, os.WriteFile(fn, []byte("foo"), 0600))
+		require.NoError(t, os.Chtimes(fn, mtime, mtime))
+		var acc testutil.Accumulator
+		require.NoError(t, acc.GatherError(fs.Gather))
+
+		// Change the content without changing size and modification time
+		require.NoError(t, os.WriteFile(fn, []byte("bar"), 0600))
+		require.NoError(t, os.Chtimes(fn, mtime, mtime))
+		acc.ClearMetrics()
+		require.NoError(t, acc.GatherError(fs.Gather))
+
+		expected := "37b51d194a7513e45b56f6524f2d51f2" // md5 of "bar"
+		if cached {
+			expected = "acbd18db4cc2f85cedef654fccc4a4d8" // md5 of "foo"
+		}
+		require.True(t, acc.HasPoint("filestat", map[string]string{"file": fn}, "md5_sum", expected))
+	}
+}
+
+func TestDirectoryHash(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("foo"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("bar"), 0600))
+
+	fs := newFileStat()
+	fs.Log = testutil.Logger{}
+	fs.Hash = []string{"sha256"}
+	fs.DirectoryHash = true
+	fs.Files = []string{dir}
+	require.NoError(t, fs.Init())
+
+	gather := func() string {
+		var acc testutil.Accumulator
+		require.NoError(t, acc.GatherError(fs.Gather))
+		v, found := acc.StringField("filestat", "sha256_sum")
+		require.True(t, found)
+		return v
+	}
+
+	first := gather()
+	require.Equal(t, first, gather())
+
+	// Modifying a file in a subdirectory changes the directory hash
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("baz!"), 0600))
+	require.NotEqual(t, first, gather())
+}
+
+func TestChangeEvents(t *testing.T) {
+	dir := t.TempDir()
+	keep := filepath.Join(dir, "keep.txt")
+	modify := filepath.Join(dir, "modify.txt")
+	remove := filepath.Join(dir, "remove.txt")
+	create := filepath.Join(dir, "create.txt")
+	for _, fn := range []string{keep, modify, remove} {
+		require.NoError(t, os.WriteFile(fn, []byte("foo"), 0600))
+	}
+
+	fs := newFileStat()
+	fs.Log = testutil.Logger{}
+	fs.Md5 = true
+	fs.ChangeEvents = true
+	fs.Files = []string{filepath.Join(dir, "*.txt")}
+	require.NoError(t, fs.Init())
+
+	// The first gather cycle only records the state
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(fs.Gather))
+	require.False(t, acc.HasMeasurement("filestat_event"))
+
+	require.NoError(t, os.WriteFile(modify, []byte("bar"), 0600))
+	require.NoError(t, os.Remove(remove))
+	require.NoError(t, os.WriteFile(create, []byte("foo"), 0600))
+
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(fs.Gather))
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() != "filestat_event" {
+			continue
+		}
+		m.RemoveField("size_bytes")
+		m.RemoveField("modification_time")
+		actual = append(actual, m)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"filestat_event",
+			map[string]string{"file": create, "event": "created"},
+			map[string]interface{}{"exists": int64(1), "md5_sum": "acbd18db4cc2f85cedef654fccc4a4d8"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"filestat_event",
+			map[string]string{"file": modify, "event": "modified"},
+			map[string]interface{}{"exists": int64(1), "md5_sum": "37b51d194a7513e45b56f6524f2d51f2"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"filestat_event",
+			map[string]string{"file": remove, "event": "deleted"},
+			map[string]interface{}{"exists": int64(0)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
 func getTestdataDir() string {
 	dir, err := os.Getwd()
 	if err != nil {
@@ -0,0 +1,309 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package gpu_fabric
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var availableMetrics = []string{"nvlink", "rdma"}
+
+var (
+	// GPU header of the nvidia-smi nvlink output e.g.
+	// "GPU 0: NVIDIA A100-SXM4-80GB (UUID: GPU-513536b6-7d19-9063-b049-1e69664bb298)"
+	nvlinkGPURe = regexp.MustCompile(`^GPU (\d+): (.+) \(UUID: ([^)]+)\)$`)
+	// Link line of the nvidia-smi nvlink output e.g. "Link 0: 25 GB/s" or
+	// "Link 0: Replay Errors: 0"
+	nvlinkLinkRe = regexp.MustCompile(`^Link (\d+): (?:([^:]+): )?(.+)$`)
+)
+
+type GPUFabric struct {
+	Metrics []string        `toml:"metrics"`
+	BinPath string          `toml:"bin_path"`
+	Timeout config.Duration `toml:"timeout"`
+	Devices []string        `toml:"devices"`
+	Log     telegraf.Logger `toml:"-"`
+
+	sysPath string
+	devices filter.Filter
+}
+
+// nvlink holds the status and counters of a single NVLink of a GPU
+type nvlink struct {
+	gpu    string
+	name   string
+	uuid   string
+	link   string
+	fields map[string]interface{}
+}
+
+func (*GPUFabric) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GPUFabric) Init() error {
+	if len(g.Metrics) == 0 {
+		g.Metrics = availableMetrics
+	}
+	if err := choice.CheckSlice(g.Metrics, availableMetrics); err != nil {
+		return fmt.Errorf("config option 'metrics': %w", err)
+	}
+
+	if choice.Contains("nvlink", g.Metrics) {
+		if _, err := os.Stat(g.BinPath); err != nil {
+			binPath, err := exec.LookPath("nvidia-smi")
+			if err != nil {
+				return fmt.Errorf("nvidia-smi not found: %w", err)
+			}
+			g.BinPath = binPath
+		}
+	}
+
+	devices, err := filter.Compile(g.Devices)
+	if err != nil {
+		return fmt.Errorf("compiling device filter failed: %w", err)
+	}
+	g.devices = devices
+	g.sysPath = internal.GetSysPath()
+
+	return nil
+}
+
+func (g *GPUFabric) Gather(acc telegraf.Accumulator) error {
+	if choice.Contains("nvlink", g.Metrics) {
+		if err := g.gatherNVLink(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering NVLink metrics failed: %w", err))
+		}
+	}
+	if choice.Contains("rdma", g.Metrics) {
+		if err := g.gatherRDMA(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering RDMA metrics failed: %w", err))
+		}
+	}
+	return nil
+}
+
+func (g *GPUFabric) gatherNVLink(acc telegraf.Accumulator) error {
+	timestamp := time.Now()
+
+	status, err := internal.CombinedOutputTimeout(exec.Command(g.BinPath, "nvlink", "--status"), time.Duration(g.Timeout))
+	if err != nil {
+		return fmt.Errorf("calling %q failed: %w", g.BinPath, err)
+	}
+	counters, err := internal.CombinedOutputTimeout(exec.Command(g.BinPath, "nvlink", "--errorcounters"), time.Duration(g.Timeout))
+	if err != nil {
+		return fmt.Errorf("calling %q failed: %w", g.BinPath, err)
+	}
+
+	links, err := parseNVLink(nil, status)
+	if err != nil {
+		return err
+	}
+	if links, err = parseNVLink(links, counters); err != nil {
+		return err
+	}
+
+	for _, l := range links {
+		tags := map[string]string{
+			"index": l.gpu,
+			"name":  l.name,
+			"uuid":  l.uuid,
+			"link":  l.link,
+		}
+		acc.AddFields("gpu_fabric_nvlink", l.fields, tags, timestamp)
+	}
+
+	return nil
+}
+
+// parseNVLink adds the link status and counters reported by one of the
+// "nvidia-smi nvlink" commands to the given links
+func parseNVLink(links []*nvlink, data []byte) ([]*nvlink, error) {
+	index := make(map[string]*nvlink, len(links))
+	for _, l := range links {
+		index[l.gpu+"/"+l.link] = l
+	}
+
+	var gpu, name, uuid string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if match := nvlinkGPURe.FindStringSubmatch(line); match != nil {
+			gpu, name, uuid = match[1], match[2], match[3]
+			continue
+		}
+
+		match := nvlinkLinkRe.FindStringSubmatch(line)
+		if match == nil || gpu == "" {
+			continue
+		}
+
+		l, found := index[gpu+"/"+match[1]]
+		if !found {
+			l = &nvlink{
+				gpu:    gpu,
+				name:   name,
+				uuid:   uuid,
+				link:   match[1],
+				fields: make(map[string]interface{}),
+			}
+			index[gpu+"/"+match[1]] = l
+			links = append(links, l)
+		}
+
+		// Status lines only contain the link speed or an inactive marker
+		if match[2] == "" {
+			if match[3] == "<inactive>" {
+				l.fields["active"] = false
+				continue
+			}
+			l.fields["active"] = true
+			if speed, err := strconv.ParseFloat(strings.TrimSuffix(match[3], " GB/s"), 64); err == nil {
+				l.fields["speed"] = speed
+			}
+			continue
+		}
+
+		// Counter names differ between GPU generations, so use the reported
+		// name e.g. "Replay Errors" becomes "replay_errors"
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(match[2])), " ", "_")
+		if value, err := strconv.ParseUint(strings.TrimSpace(match[3]), 10, 64); err == nil {
+			l.fields[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("parsing nvlink output failed: %w", err)
+	}
+
+	return links, nil
+}
+
+func (g *GPUFabric) gatherRDMA(acc telegraf.Accumulator) error {
+	root := filepath.Join(g.sysPath, "class", "infiniband")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		device := entry.Name()
+		if g.devices != nil && !g.devices.Match(device) {
+			continue
+		}
+
+		devicePath := filepath.Join(root, device)
+		nodeType := readSysfsValue(filepath.Join(devicePath, "node_type"))
+
+		ports, err := os.ReadDir(filepath.Join(devicePath, "ports"))
+		if err != nil {
+			acc.AddError(fmt.Errorf("reading ports of device %q failed: %w", device, err))
+			continue
+		}
+		for _, port := range ports {
+			portPath := filepath.Join(devicePath, "ports", port.Name())
+
+			tags := map[string]string{
+				"device": device,
+				"port":   port.Name(),
+			}
+			if v := readSysfsValue(filepath.Join(portPath, "link_layer")); v != "" {
+				tags["link_layer"] = v
+			}
+			if nodeType != "" {
+				tags["node_type"] = nodeType
+			}
+
+			fields := make(map[string]interface{})
+			if v := readSysfsValue(filepath.Join(portPath, "state")); v != "" {
+				fields["state"] = v
+			}
+			if v := readSysfsValue(filepath.Join(portPath, "rate")); v != "" {
+				if rate, err := strconv.ParseFloat(strings.Fields(v)[0], 64); err == nil {
+					fields["rate"] = rate
+				}
+			}
+			readCounters(fields, filepath.Join(portPath, "counters"))
+			readCounters(fields, filepath.Join(portPath, "hw_counters"))
+
+			acc.AddFields("gpu_fabric_rdma", fields, tags)
+		}
+	}
+
+	return nil
+}
+
+// readCounters adds all counters found in the given directory to the fields
+func readCounters(fields map[string]interface{}, path string) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		buf, err := os.ReadFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			continue
+		}
+		value, err := strconv.ParseUint(strings.TrimSpace(string(buf)), 10, 64)
+		if err != nil {
+			continue
+		}
+		fields[entry.Name()] = value
+	}
+}
+
+// readSysfsValue returns the content of the given sysfs attribute without the
+// numeric prefix e.g. "ACTIVE" for "4: ACTIVE"
+func readSysfsValue(path string) string {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	value := strings.TrimSpace(string(buf))
+	if prefix, suffix, found := strings.Cut(value, ": "); found {
+		if _, err := strconv.Atoi(prefix); err == nil {
+			return suffix
+		}
+	}
+	return value
+}
+
+func init() {
+	inputs.Add("gpu_fabric", func() telegraf.Input {
+		return &GPUFabric{
+			BinPath: "/usr/bin/nvidia-smi",
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
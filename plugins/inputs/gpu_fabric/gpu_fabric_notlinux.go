@@ -0,0 +1,32 @@
+//go:build !linux
+
+package gpu_fabric
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type GPUFabric struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*GPUFabric) SampleConfig() string { return sampleConfig }
+
+func (g *GPUFabric) Init() error {
+	g.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*GPUFabric) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("gpu_fabric", func() telegraf.Input {
+		return &GPUFabric{}
+	})
+}
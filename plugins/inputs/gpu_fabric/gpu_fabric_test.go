@@ -0,0 +1,158 @@
+//go:build linux
+
+package gpu_fabric
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalidMetrics(t *testing.T) {
+	plugin := &GPUFabric{Metrics: []string{"rdma", "foo"}}
+	require.ErrorContains(t, plugin.Init(), "config option 'metrics'")
+}
+
+func TestParseNVLink(t *testing.T) {
+	status, err := os.ReadFile("testdata/nvlink_status.txt")
+	require.NoError(t, err)
+	counters, err := os.ReadFile("testdata/nvlink_errors.txt")
+	require.NoError(t, err)
+
+	links, err := parseNVLink(nil, status)
+	require.NoError(t, err)
+	links, err = parseNVLink(links, counters)
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	for _, l := range links {
+		tags := map[string]string{"index": l.gpu, "name": l.name, "uuid": l.uuid, "link": l.link}
+		acc.AddFields("gpu_fabric_nvlink", l.fields, tags)
+	}
+
+	gpu0 := "GPU-513536b6-7d19-9063-b049-1e69664bb298"
+	gpu1 := "GPU-6f3e1a52-93c1-6a4d-3c0b-7b1f2b0a4e11"
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"gpu_fabric_nvlink",
+			map[string]string{"index": "0", "name": "NVIDIA A100-SXM4-80GB", "uuid": gpu0, "link": "0"},
+			map[string]interface{}{
+				"active":          true,
+				"speed":           25.0,
+				"replay_errors":   uint64(0),
+				"recovery_errors": uint64(0),
+				"crc_errors":      uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"gpu_fabric_nvlink",
+			map[string]string{"index": "0", "name": "NVIDIA A100-SXM4-80GB", "uuid": gpu0, "link": "1"},
+			map[string]interface{}{
+				"active":          true,
+				"speed":           25.0,
+				"replay_errors":   uint64(12),
+				"recovery_errors": uint64(1),
+				"crc_errors":      uint64(3),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"gpu_fabric_nvlink",
+			map[string]string{"index": "0", "name": "NVIDIA A100-SXM4-80GB", "uuid": gpu0, "link": "2"},
+			map[string]interface{}{
+				"active": false,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"gpu_fabric_nvlink",
+			map[string]string{"index": "1", "name": "NVIDIA A100-SXM4-80GB", "uuid": gpu1, "link": "0"},
+			map[string]interface{}{
+				"active":          true,
+				"speed":           25.0,
+				"replay_errors":   uint64(0),
+				"recovery_errors": uint64(0),
+				"crc_errors":      uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherRDMA(t *testing.T) {
+	t.Setenv("HOST_SYS", "testdata/sys")
+
+	plugin := &GPUFabric{Metrics: []string{"rdma"}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"gpu_fabric_rdma",
+			map[string]string{"device": "mlx5_0", "port": "1", "link_layer": "InfiniBand", "node_type": "CA"},
+			map[string]interface{}{
+				"state":                 "ACTIVE",
+				"rate":                  200.0,
+				"link_downed":           uint64(1),
+				"port_rcv_data":         uint64(34600185253),
+				"port_xmit_data":        uint64(85378896588),
+				"symbol_error":          uint64(2),
+				"local_ack_timeout_err": uint64(5),
+				"out_of_sequence":       uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"gpu_fabric_rdma",
+			map[string]string{"device": "mlx5_1", "port": "1", "link_layer": "Ethernet", "node_type": "CA"},
+			map[string]interface{}{
+				"state":          "ACTIVE",
+				"rate":           100.0,
+				"port_rcv_data":  uint64(1000),
+				"port_xmit_data": uint64(2000),
+				"np_cnp_sent":    uint64(17),
+				"rp_cnp_handled": uint64(9),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"gpu_fabric_rdma",
+			map[string]string{"device": "switch_0", "port": "1", "link_layer": "InfiniBand", "node_type": "switch"},
+			map[string]interface{}{
+				"state":           "DOWN",
+				"rate":            10.0,
+				"port_rcv_errors": uint64(4),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherRDMADeviceFilter(t *testing.T) {
+	t.Setenv("HOST_SYS", "testdata/sys")
+
+	plugin := &GPUFabric{
+		Metrics: []string{"rdma"},
+		Devices: []string{"switch_*"},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, "switch_0", metrics[0].Tags()["device"])
+}
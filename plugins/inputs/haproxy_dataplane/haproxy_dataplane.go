@@ -0,0 +1,266 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package haproxy_dataplane
+
+import (
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const basePath = "/v2/services/haproxy"
+
+var availableCollectors = []string{"config", "maps", "acls", "stick_tables"}
+
+type HAProxyDataplane struct {
+	URL      string          `toml:"url"`
+	Username config.Secret   `toml:"username"`
+	Password config.Secret   `toml:"password"`
+	Collect  []string        `toml:"collect"`
+	Log      telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+}
+
+type rawConfiguration struct {
+	Version int64  `json:"_version"`
+	Data    string `json:"data"`
+}
+
+type mapFile struct {
+	ID          string `json:"id"`
+	File        string `json:"file"`
+	Description string `json:"description"`
+}
+
+type aclFile struct {
+	ID          string `json:"id"`
+	StorageName string `json:"storage_name"`
+	Description string `json:"description"`
+}
+
+type stickTable struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Process int64  `json:"process"`
+	Size    int64  `json:"size"`
+	Used    int64  `json:"used"`
+}
+
+func (*HAProxyDataplane) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HAProxyDataplane) Init() error {
+	if h.URL == "" {
+		return errors.New("url required")
+	}
+	if _, err := url.Parse(h.URL); err != nil {
+		return fmt.Errorf("parsing url failed: %w", err)
+	}
+	h.URL = strings.TrimSuffix(h.URL, "/")
+
+	if len(h.Collect) == 0 {
+		h.Collect = availableCollectors
+	}
+	if err := choice.CheckSlice(h.Collect, availableCollectors); err != nil {
+		return fmt.Errorf("invalid 'collect' setting: %w", err)
+	}
+
+	return nil
+}
+
+func (h *HAProxyDataplane) Start(telegraf.Accumulator) error {
+	client, err := h.HTTPClientConfig.CreateClient(context.Background(), h.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	h.client = client
+
+	return nil
+}
+
+func (h *HAProxyDataplane) Gather(acc telegraf.Accumulator) error {
+	for _, collector := range h.Collect {
+		var err error
+		switch collector {
+		case "config":
+			err = h.gatherConfig(acc)
+		case "maps":
+			err = h.gatherMaps(acc)
+		case "acls":
+			err = h.gatherACLs(acc)
+		case "stick_tables":
+			err = h.gatherStickTables(acc)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %s failed: %w", collector, err))
+		}
+	}
+
+	return nil
+}
+
+func (h *HAProxyDataplane) Stop() {
+	if h.client != nil {
+		h.client.CloseIdleConnections()
+	}
+}
+
+func (h *HAProxyDataplane) gatherConfig(acc telegraf.Accumulator) error {
+	var cfg rawConfiguration
+	if err := h.getJSON("/configuration/raw", &cfg); err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256([]byte(cfg.Data))
+	fields := map[string]interface{}{
+		"version": cfg.Version,
+		"hash":    hex.EncodeToString(hash[:]),
+	}
+	acc.AddFields("haproxy_dataplane_config", fields, map[string]string{"url": h.URL})
+
+	return nil
+}
+
+func (h *HAProxyDataplane) gatherMaps(acc telegraf.Accumulator) error {
+	var maps []mapFile
+	if err := h.getJSON("/runtime/maps", &maps); err != nil {
+		return err
+	}
+
+	for _, m := range maps {
+		var entries []json.RawMessage
+		if err := h.getJSON("/runtime/maps_entries?map="+url.QueryEscape(m.File), &entries); err != nil {
+			return fmt.Errorf("querying entries of map %q failed: %w", m.File, err)
+		}
+
+		tags := map[string]string{
+			"url": h.URL,
+			"id":  m.ID,
+			"map": m.File,
+		}
+		acc.AddFields("haproxy_dataplane_map", map[string]interface{}{"entries": len(entries)}, tags)
+	}
+
+	return nil
+}
+
+func (h *HAProxyDataplane) gatherACLs(acc telegraf.Accumulator) error {
+	var acls []aclFile
+	if err := h.getJSON("/runtime/acls", &acls); err != nil {
+		return err
+	}
+
+	for _, a := range acls {
+		var entries []json.RawMessage
+		if err := h.getJSON("/runtime/acl_file_entries?acl_id="+url.QueryEscape(a.ID), &entries); err != nil {
+			return fmt.Errorf("querying entries of ACL %q failed: %w", a.ID, err)
+		}
+
+		// ACLs defined inline in the configuration have no storage file
+		name := a.StorageName
+		if name == "" {
+			name = a.Description
+		}
+		tags := map[string]string{
+			"url": h.URL,
+			"id":  a.ID,
+			"acl": name,
+		}
+		acc.AddFields("haproxy_dataplane_acl", map[string]interface{}{"entries": len(entries)}, tags)
+	}
+
+	return nil
+}
+
+func (h *HAProxyDataplane) gatherStickTables(acc telegraf.Accumulator) error {
+	var tables []stickTable
+	if err := h.getJSON("/runtime/stick_tables", &tables); err != nil {
+		return err
+	}
+
+	for _, t := range tables {
+		tags := map[string]string{
+			"url":     h.URL,
+			"table":   t.Name,
+			"type":    t.Type,
+			"process": fmt.Sprint(t.Process),
+		}
+		fields := map[string]interface{}{
+			"size": t.Size,
+			"used": t.Used,
+		}
+		if t.Size > 0 {
+			fields["used_percent"] = 100 * float64(t.Used) / float64(t.Size)
+		}
+		acc.AddFields("haproxy_dataplane_stick_table", fields, tags)
+	}
+
+	return nil
+}
+
+func (h *HAProxyDataplane) getJSON(path string, v interface{}) error {
+	req, err := http.NewRequest("GET", h.URL+basePath+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", internal.ProductToken())
+
+	if !h.Username.Empty() || !h.Password.Empty() {
+		username, err := h.Username.Get()
+		if err != nil {
+			return fmt.Errorf("getting username failed: %w", err)
+		}
+		defer username.Destroy()
+
+		password, err := h.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		defer password.Destroy()
+
+		req.SetBasicAuth(username.String(), password.String())
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status %q", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func init() {
+	inputs.Add("haproxy_dataplane", func() telegraf.Input {
+		return &HAProxyDataplane{
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
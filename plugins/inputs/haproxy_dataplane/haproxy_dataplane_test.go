@@ -0,0 +1,142 @@
+package haproxy_dataplane
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	raw := "global\n  maxconn 100\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/services/haproxy/configuration/raw":
+			fmt.Fprintf(w, `{"_version":7,"data":%q}`, raw)
+		case "/v2/services/haproxy/runtime/maps":
+			fmt.Fprint(w, `[{"id":"1","file":"/etc/haproxy/hosts.map","description":"pattern loaded from file"}]`)
+		case "/v2/services/haproxy/runtime/maps_entries":
+			if r.URL.Query().Get("map") != "/etc/haproxy/hosts.map" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, `[{"id":"0x1","key":"a","value":"1"},{"id":"0x2","key":"b","value":"2"}]`)
+		case "/v2/services/haproxy/runtime/acls":
+			fmt.Fprint(w, `[{"id":"0","storage_name":"/etc/haproxy/block.acl"},{"id":"1","description":"acl 'inline' file 'haproxy.cfg' line 12"}]`)
+		case "/v2/services/haproxy/runtime/acl_file_entries":
+			switch r.URL.Query().Get("acl_id") {
+			case "0":
+				fmt.Fprint(w, `[{"id":"0x1","value":"10.0.0.1"},{"id":"0x2","value":"10.0.0.2"},{"id":"0x3","value":"10.0.0.3"}]`)
+			case "1":
+				fmt.Fprint(w, `[]`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case "/v2/services/haproxy/runtime/stick_tables":
+			fmt.Fprint(w, `[{"name":"front","type":"ip","process":1,"size":1000,"used":250},{"name":"empty","type":"string","process":1,"size":0,"used":0}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &HAProxyDataplane{
+		URL:      server.URL,
+		Username: config.NewSecret([]byte("admin")),
+		Password: config.NewSecret([]byte("secret")),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	hash := sha256.Sum256([]byte(raw))
+	expected := []telegraf.Metric{
+		metric.New("haproxy_dataplane_config",
+			map[string]string{"url": server.URL},
+			map[string]interface{}{"version": int64(7), "hash": hex.EncodeToString(hash[:])},
+			time.Unix(0, 0),
+		),
+		metric.New("haproxy_dataplane_map",
+			map[string]string{"url": server.URL, "id": "1", "map": "/etc/haproxy/hosts.map"},
+			map[string]interface{}{"entries": 2},
+			time.Unix(0, 0),
+		),
+		metric.New("haproxy_dataplane_acl",
+			map[string]string{"url": server.URL, "id": "0", "acl": "/etc/haproxy/block.acl"},
+			map[string]interface{}{"entries": 3},
+			time.Unix(0, 0),
+		),
+		metric.New("haproxy_dataplane_acl",
+			map[string]string{"url": server.URL, "id": "1", "acl": "acl 'inline' file 'haproxy.cfg' line 12"},
+			map[string]interface{}{"entries": 0},
+			time.Unix(0, 0),
+		),
+		metric.New("haproxy_dataplane_stick_table",
+			map[string]string{"url": server.URL, "table": "front", "type": "ip", "process": "1"},
+			map[string]interface{}{"size": int64(1000), "used": int64(250), "used_percent": float64(25)},
+			time.Unix(0, 0),
+		),
+		metric.New("haproxy_dataplane_stick_table",
+			map[string]string{"url": server.URL, "table": "empty", "type": "string", "process": "1"},
+			map[string]interface{}{"size": int64(0), "used": int64(0)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherPartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/services/haproxy/runtime/stick_tables" {
+			fmt.Fprint(w, `[{"name":"front","type":"ip","process":1,"size":10,"used":1}]`)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	plugin := &HAProxyDataplane{
+		URL:     server.URL,
+		Collect: []string{"maps", "stick_tables"},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "gathering maps failed")
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.True(t, acc.HasMeasurement("haproxy_dataplane_stick_table"))
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &HAProxyDataplane{}
+	require.ErrorContains(t, plugin.Init(), "url required")
+
+	plugin = &HAProxyDataplane{URL: "http://localhost:5555", Collect: []string{"servers"}}
+	require.ErrorContains(t, plugin.Init(), "invalid 'collect' setting")
+}
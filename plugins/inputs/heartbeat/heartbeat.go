@@ -0,0 +1,338 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package heartbeat
+
+import (
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Maximum size of a UDP ping datagram
+const maxDatagramSize = 1024
+
+type Heartbeat struct {
+	HTTPAddress  string          `toml:"http_address"`
+	UDPAddress   string          `toml:"udp_address"`
+	PathPrefix   string          `toml:"path_prefix"`
+	ReadTimeout  config.Duration `toml:"read_timeout"`
+	WriteTimeout config.Duration `toml:"write_timeout"`
+	Checks       []*Check        `toml:"check"`
+	Log          telegraf.Logger `toml:"-"`
+	common_tls.ServerConfig
+
+	tlsConf  *tls.Config
+	listener net.Listener
+	server   http.Server
+	conn     net.PacketConn
+	wg       sync.WaitGroup
+
+	acc    telegraf.Accumulator
+	checks map[string]*Check
+	sync.Mutex
+}
+
+// Check defines a named check pinged by a job together with its schedule
+type Check struct {
+	ID     string            `toml:"id"`
+	Period config.Duration   `toml:"period"`
+	Grace  config.Duration   `toml:"grace"`
+	Tags   map[string]string `toml:"tags"`
+
+	// time of the last ping or of the plugin start if never pinged
+	reference time.Time
+	lastPing  time.Time
+	failed    bool
+	missed    bool
+	pings     int64
+	failures  int64
+}
+
+func (*Heartbeat) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *Heartbeat) Init() error {
+	if h.HTTPAddress == "" && h.UDPAddress == "" {
+		return errors.New("either 'http_address' or 'udp_address' must be set")
+	}
+	if len(h.Checks) == 0 {
+		return errors.New("no checks configured")
+	}
+	h.PathPrefix = "/" + strings.Trim(h.PathPrefix, "/")
+
+	h.checks = make(map[string]*Check, len(h.Checks))
+	for _, c := range h.Checks {
+		if c.ID == "" {
+			return errors.New("check without 'id'")
+		}
+		if strings.ContainsAny(c.ID, "/ \t\r\n") {
+			return fmt.Errorf("check ID %q must not contain slashes or whitespace", c.ID)
+		}
+		if _, found := h.checks[c.ID]; found {
+			return fmt.Errorf("duplicate check ID %q", c.ID)
+		}
+		if c.Period <= 0 {
+			return fmt.Errorf("'period' of check %q must be positive", c.ID)
+		}
+		if c.Grace < 0 {
+			return fmt.Errorf("'grace' of check %q must not be negative", c.ID)
+		}
+		h.checks[c.ID] = c
+	}
+
+	var err error
+	h.tlsConf, err = h.ServerConfig.TLSConfig()
+	return err
+}
+
+func (h *Heartbeat) Start(acc telegraf.Accumulator) error {
+	h.acc = acc
+
+	// Start the deadlines of all checks from now on
+	now := time.Now()
+	for _, c := range h.checks {
+		c.reference = now
+	}
+
+	if h.HTTPAddress != "" {
+		var err error
+		if h.tlsConf != nil {
+			h.listener, err = tls.Listen("tcp", h.HTTPAddress, h.tlsConf)
+		} else {
+			h.listener, err = net.Listen("tcp", h.HTTPAddress)
+		}
+		if err != nil {
+			return fmt.Errorf("creating listener failed: %w", err)
+		}
+
+		h.server = http.Server{
+			Addr:         h.HTTPAddress,
+			Handler:      h,
+			ReadTimeout:  time.Duration(h.ReadTimeout),
+			WriteTimeout: time.Duration(h.WriteTimeout),
+			TLSConfig:    h.tlsConf,
+		}
+
+		go func() {
+			if err := h.server.Serve(h.listener); err != nil {
+				if !errors.Is(err, net.ErrClosed) && !errors.Is(err, http.ErrServerClosed) {
+					h.Log.Errorf("Server failed: %v", err)
+				}
+			}
+		}()
+		h.Log.Infof("Listening for HTTP pings on %s", h.listener.Addr().String())
+	}
+
+	if h.UDPAddress != "" {
+		conn, err := net.ListenPacket("udp", h.UDPAddress)
+		if err != nil {
+			h.Stop()
+			return fmt.Errorf("creating UDP listener failed: %w", err)
+		}
+		h.conn = conn
+
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			h.listenUDP()
+		}()
+		h.Log.Infof("Listening for UDP pings on %s", h.conn.LocalAddr().String())
+	}
+
+	return nil
+}
+
+func (h *Heartbeat) Stop() {
+	if h.listener != nil {
+		if err := h.server.Shutdown(context.Background()); err != nil {
+			h.Log.Errorf("Shutting down server failed: %v", err)
+		}
+	}
+	if h.conn != nil {
+		h.conn.Close()
+	}
+	h.wg.Wait()
+}
+
+func (h *Heartbeat) Gather(acc telegraf.Accumulator) error {
+	h.Lock()
+	defer h.Unlock()
+
+	h.gather(acc, time.Now())
+	return nil
+}
+
+func (h *Heartbeat) gather(acc telegraf.Accumulator, now time.Time) {
+	for _, c := range h.Checks {
+		status := c.status(now)
+
+		// Report a missed deadline only once until the next ping
+		if status == "down" && !c.missed {
+			c.missed = true
+			h.addEvent(acc, c, "missed", now)
+		}
+
+		fields := map[string]interface{}{
+			"status":   status,
+			"pings":    c.pings,
+			"failures": c.failures,
+		}
+		if !c.lastPing.IsZero() {
+			fields["last_seen_age"] = now.Sub(c.lastPing).Seconds()
+		}
+		acc.AddFields("heartbeat", fields, c.tags(), now)
+	}
+}
+
+func (h *Heartbeat) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut:
+	default:
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	path, found := strings.CutPrefix(req.URL.Path, strings.TrimSuffix(h.PathPrefix, "/")+"/")
+	if !found {
+		res.WriteHeader(http.StatusNotFound)
+		return
+	}
+	id, suffix, _ := strings.Cut(path, "/")
+
+	var failed bool
+	switch suffix {
+	case "":
+	case "fail":
+		failed = true
+	default:
+		res.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !h.ping(id, failed, time.Now()) {
+		res.WriteHeader(http.StatusNotFound)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+func (h *Heartbeat) listenUDP() {
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := h.conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				h.Log.Errorf("Reading UDP ping failed: %v", err)
+			}
+			return
+		}
+
+		// The datagram contains the check ID optionally followed by "fail"
+		parts := strings.Fields(string(buf[:n]))
+		switch {
+		case len(parts) == 1:
+			h.ping(parts[0], false, time.Now())
+		case len(parts) == 2 && parts[1] == "fail":
+			h.ping(parts[0], true, time.Now())
+		default:
+			h.Log.Debugf("Ignoring invalid UDP ping %q", string(buf[:n]))
+		}
+	}
+}
+
+// ping records a ping for the given check and returns false if the check is
+// unknown
+func (h *Heartbeat) ping(id string, failed bool, now time.Time) bool {
+	h.Lock()
+	defer h.Unlock()
+
+	c, found := h.checks[id]
+	if !found {
+		h.Log.Debugf("Ignoring ping for unknown check %q", id)
+		return false
+	}
+
+	c.pings++
+	switch {
+	case failed:
+		c.failures++
+		if !c.failed {
+			h.addEvent(h.acc, c, "failed", now)
+		}
+	case c.failed || c.missed:
+		h.addEvent(h.acc, c, "recovered", now)
+	}
+
+	c.reference = now
+	c.lastPing = now
+	c.failed = failed
+	c.missed = false
+	return true
+}
+
+func (h *Heartbeat) addEvent(acc telegraf.Accumulator, c *Check, event string, now time.Time) {
+	tags := c.tags()
+	tags["event"] = event
+
+	fields := map[string]interface{}{
+		"deadline": c.deadline().UnixNano(),
+	}
+	if !c.lastPing.IsZero() {
+		fields["last_seen_age"] = now.Sub(c.lastPing).Seconds()
+	}
+	acc.AddFields("heartbeat_event", fields, tags, now)
+}
+
+// deadline returns the time after which the check is considered down
+func (c *Check) deadline() time.Time {
+	return c.reference.Add(time.Duration(c.Period) + time.Duration(c.Grace))
+}
+
+func (c *Check) status(now time.Time) string {
+	switch {
+	case c.failed:
+		return "failed"
+	case now.After(c.deadline()):
+		return "down"
+	case now.After(c.reference.Add(time.Duration(c.Period))):
+		return "grace"
+	case c.lastPing.IsZero():
+		return "new"
+	default:
+		return "up"
+	}
+}
+
+func (c *Check) tags() map[string]string {
+	tags := make(map[string]string, len(c.Tags)+2)
+	for k, v := range c.Tags {
+		tags[k] = v
+	}
+	tags["check"] = c.ID
+	return tags
+}
+
+func init() {
+	inputs.Add("heartbeat", func() telegraf.Input {
+		return &Heartbeat{
+			PathPrefix:   "/ping",
+			ReadTimeout:  config.Duration(5 * time.Second),
+			WriteTimeout: config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package heartbeat
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Heartbeat
+		expected string
+	}{
+		{
+			name:     "no address",
+			plugin:   &Heartbeat{Checks: []*Check{{ID: "a", Period: config.Duration(time.Minute)}}},
+			expected: "either 'http_address' or 'udp_address' must be set",
+		},
+		{
+			name:     "no checks",
+			plugin:   &Heartbeat{HTTPAddress: ":0"},
+			expected: "no checks configured",
+		},
+		{
+			name:     "missing id",
+			plugin:   &Heartbeat{HTTPAddress: ":0", Checks: []*Check{{Period: config.Duration(time.Minute)}}},
+			expected: "check without 'id'",
+		},
+		{
+			name:     "invalid id",
+			plugin:   &Heartbeat{HTTPAddress: ":0", Checks: []*Check{{ID: "a/b", Period: config.Duration(time.Minute)}}},
+			expected: "must not contain slashes or whitespace",
+		},
+		{
+			name: "duplicate id",
+			plugin: &Heartbeat{HTTPAddress: ":0", Checks: []*Check{
+				{ID: "a", Period: config.Duration(time.Minute)},
+				{ID: "a", Period: config.Duration(time.Hour)},
+			}},
+			expected: `duplicate check ID "a"`,
+		},
+		{
+			name:     "missing period",
+			plugin:   &Heartbeat{HTTPAddress: ":0", Checks: []*Check{{ID: "a"}}},
+			expected: `'period' of check "a" must be positive`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestStatusAndEvents(t *testing.T) {
+	plugin := &Heartbeat{
+		HTTPAddress: ":0",
+		Checks: []*Check{
+			{
+				ID:     "backup",
+				Period: config.Duration(time.Hour),
+				Grace:  config.Duration(10 * time.Minute),
+				Tags:   map[string]string{"job": "nightly"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	plugin.acc = &acc
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	plugin.checks["backup"].reference = start
+
+	// Never pinged and within the period
+	plugin.gather(&acc, start.Add(30*time.Minute))
+	// Successful ping
+	require.True(t, plugin.ping("backup", false, start.Add(40*time.Minute)))
+	plugin.gather(&acc, start.Add(50*time.Minute))
+	// Within the grace time
+	plugin.gather(&acc, start.Add(40*time.Minute+65*time.Minute))
+	// Missed the deadline, the event must only be emitted once
+	plugin.gather(&acc, start.Add(40*time.Minute+75*time.Minute))
+	plugin.gather(&acc, start.Add(40*time.Minute+80*time.Minute))
+	// Recovered
+	require.True(t, plugin.ping("backup", false, start.Add(2*time.Hour)))
+	// Failure
+	require.True(t, plugin.ping("backup", true, start.Add(3*time.Hour)))
+	plugin.gather(&acc, start.Add(3*time.Hour+time.Minute))
+	// Unknown check
+	require.False(t, plugin.ping("unknown", false, start.Add(3*time.Hour)))
+
+	tags := map[string]string{"check": "backup", "job": "nightly"}
+	eventTags := func(event string) map[string]string {
+		return map[string]string{"check": "backup", "job": "nightly", "event": event}
+	}
+	expected := []telegraf.Metric{
+		metric.New("heartbeat", tags, map[string]interface{}{
+			"status":   "new",
+			"pings":    int64(0),
+			"failures": int64(0),
+		}, start.Add(30*time.Minute)),
+		metric.New("heartbeat", tags, map[string]interface{}{
+			"status":        "up",
+			"pings":         int64(1),
+			"failures":      int64(0),
+			"last_seen_age": float64(600),
+		}, start.Add(50*time.Minute)),
+		metric.New("heartbeat", tags, map[string]interface{}{
+			"status":        "grace",
+			"pings":         int64(1),
+			"failures":      int64(0),
+			"last_seen_age": float64(3900),
+		}, start.Add(105*time.Minute)),
+		metric.New("heartbeat_event", eventTags("missed"), map[string]interface{}{
+			"deadline":      start.Add(110 * time.Minute).UnixNano(),
+			"last_seen_age": float64(4500),
+		}, start.Add(115*time.Minute)),
+		metric.New("heartbeat", tags, map[string]interface{}{
+			"status":        "down",
+			"pings":         int64(1),
+			"failures":      int64(0),
+			"last_seen_age": float64(4500),
+		}, start.Add(115*time.Minute)),
+		metric.New("heartbeat", tags, map[string]interface{}{
+			"status":        "down",
+			"pings":         int64(1),
+			"failures":      int64(0),
+			"last_seen_age": float64(4800),
+		}, start.Add(120*time.Minute)),
+		metric.New("heartbeat_event", eventTags("recovered"), map[string]interface{}{
+			"deadline":      start.Add(110 * time.Minute).UnixNano(),
+			"last_seen_age": float64(4800),
+		}, start.Add(2*time.Hour)),
+		metric.New("heartbeat_event", eventTags("failed"), map[string]interface{}{
+			"deadline":      start.Add(190 * time.Minute).UnixNano(),
+			"last_seen_age": float64(3600),
+		}, start.Add(3*time.Hour)),
+		metric.New("heartbeat", tags, map[string]interface{}{
+			"status":        "failed",
+			"pings":         int64(3),
+			"failures":      int64(1),
+			"last_seen_age": float64(60),
+		}, start.Add(3*time.Hour+time.Minute)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestHTTPAndUDPPings(t *testing.T) {
+	plugin := &Heartbeat{
+		HTTPAddress: "127.0.0.1:0",
+		UDPAddress:  "127.0.0.1:0",
+		PathPrefix:  "/ping/",
+		Checks: []*Check{
+			{ID: "http", Period: config.Duration(time.Hour)},
+			{ID: "udp", Period: config.Duration(time.Hour)},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	url := "http://" + plugin.listener.Addr().String()
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{path: "/ping/http", expected: http.StatusOK},
+		{path: "/ping/http/fail", expected: http.StatusOK},
+		{path: "/ping/unknown", expected: http.StatusNotFound},
+		{path: "/ping/http/foo", expected: http.StatusNotFound},
+		{path: "/other/http", expected: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(url + tt.path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equalf(t, tt.expected, resp.StatusCode, "path %q", tt.path)
+	}
+
+	conn, err := net.Dial("udp", plugin.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("udp\n"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		plugin.Lock()
+		defer plugin.Unlock()
+		return plugin.checks["udp"].pings == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.True(t, acc.HasField("heartbeat_event", "deadline"))
+	require.True(t, acc.HasPoint("heartbeat", map[string]string{"check": "http"}, "status", "failed"))
+	require.True(t, acc.HasPoint("heartbeat", map[string]string{"check": "http"}, "pings", int64(2)))
+	require.True(t, acc.HasPoint("heartbeat", map[string]string{"check": "udp"}, "status", "up"))
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"os"
 	"regexp"
@@ -57,6 +58,9 @@ type HTTPResponse struct {
 	// HTTP Basic Auth Credentials
 	Username config.Secret `toml:"username"`
 	Password config.Secret `toml:"password"`
+	// Multi-step transaction
+	TransactionName string  `toml:"transaction_name"`
+	Steps           []*Step `toml:"step"`
 	tls.ClientConfig
 	cookie.CookieAuthConfig
 
@@ -64,6 +68,38 @@ type HTTPResponse struct {
 
 	compiledStringMatch *regexp.Regexp
 	clients             []client
+	transactionClient   *http.Client
+}
+
+// Step is a single request of a transaction
+type Step struct {
+	Name                string              `toml:"name"`
+	URL                 string              `toml:"url"`
+	Method              string              `toml:"method"`
+	Body                string              `toml:"body"`
+	BodyForm            map[string][]string `toml:"body_form"`
+	Headers             map[string]string   `toml:"headers"`
+	ResponseStringMatch string              `toml:"response_string_match"`
+	ResponseStatusCode  int                 `toml:"response_status_code"`
+	CaptureHeaders      []string            `toml:"capture_headers"`
+
+	req *request
+}
+
+// request describes a HTTP request and the checks of its response
+type request struct {
+	address     string
+	method      string
+	body        string
+	bodyForm    map[string][]string
+	headers     map[string]string
+	stringMatch *regexp.Regexp
+	statusCode  int
+
+	// Response headers to capture into the shared headers
+	capture []string
+	// Headers shared among the requests of a transaction
+	shared http.Header
 }
 
 type client struct {
@@ -98,34 +134,113 @@ func (h *HTTPResponse) Init() error {
 		h.Method = "GET"
 	}
 
+	// Only probe the default address if there is no transaction
 	if len(h.URLs) == 0 {
-		if h.Address == "" {
-			h.URLs = []string{"http://localhost"}
-		} else {
+		if h.Address != "" {
 			h.URLs = []string{h.Address}
+		} else if len(h.Steps) == 0 {
+			h.URLs = []string{"http://localhost"}
 		}
 	}
 
 	h.clients = make([]client, 0, len(h.URLs))
 	for _, u := range h.URLs {
-		addr, err := url.Parse(u)
+		addr, err := parseAddress(u)
 		if err != nil {
-			return fmt.Errorf("%q is not a valid address: %w", u, err)
-		}
-
-		if addr.Scheme != "http" && addr.Scheme != "https" {
-			return fmt.Errorf("%q is not a valid address: only http and https types are supported", u)
+			return err
 		}
 
 		cl, err := h.createHTTPClient(*addr)
 		if err != nil {
 			return err
 		}
+		if h.CookieAuthConfig.URL != "" {
+			if err := h.CookieAuthConfig.Start(cl, h.Log, clock.New()); err != nil {
+				return err
+			}
+		}
 
 		h.clients = append(h.clients, client{httpClient: cl, address: u})
 	}
 
-	return nil
+	return h.initTransaction()
+}
+
+// initTransaction checks the steps of the transaction and prepares the
+// requests and the client shared by all steps
+func (h *HTTPResponse) initTransaction() error {
+	if len(h.Steps) == 0 {
+		return nil
+	}
+
+	if h.TransactionName == "" {
+		h.TransactionName = "transaction"
+	}
+
+	names := make(map[string]bool, len(h.Steps))
+	for _, s := range h.Steps {
+		if s.Name == "" {
+			return errors.New("step without name")
+		}
+		if names[s.Name] {
+			return fmt.Errorf("duplicate step name %q", s.Name)
+		}
+		names[s.Name] = true
+
+		if _, err := parseAddress(s.URL); err != nil {
+			return fmt.Errorf("invalid URL of step %q: %w", s.Name, err)
+		}
+
+		if s.Method == "" {
+			s.Method = "GET"
+		}
+
+		// Headers of the step take precedence over the global ones
+		headers := make(map[string]string, len(h.Headers)+len(s.Headers))
+		for k, v := range h.Headers {
+			headers[k] = v
+		}
+		for k, v := range s.Headers {
+			headers[k] = v
+		}
+
+		s.req = &request{
+			address:    s.URL,
+			method:     s.Method,
+			body:       s.Body,
+			bodyForm:   s.BodyForm,
+			headers:    headers,
+			statusCode: s.ResponseStatusCode,
+			capture:    s.CaptureHeaders,
+		}
+		if s.ResponseStringMatch != "" {
+			re, err := regexp.Compile(s.ResponseStringMatch)
+			if err != nil {
+				return fmt.Errorf("failed to compile regular expression %q of step %q: %w", s.ResponseStringMatch, s.Name, err)
+			}
+			s.req.stringMatch = re
+		}
+	}
+
+	// The interface address is chosen based on the address of the first step
+	addr, err := url.Parse(h.Steps[0].URL)
+	if err != nil {
+		return err
+	}
+	h.transactionClient, err = h.createHTTPClient(*addr)
+	return err
+}
+
+func parseAddress(u string) (*url.URL, error) {
+	addr, err := url.Parse(u)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid address: %w", u, err)
+	}
+
+	if addr.Scheme != "http" && addr.Scheme != "https" {
+		return nil, fmt.Errorf("%q is not a valid address: only http and https types are supported", u)
+	}
+	return addr, nil
 }
 
 // Gather gets all metric fields and tags and returns any errors it encounters
@@ -146,6 +261,64 @@ func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 		acc.AddFields("http_response", fields, tags)
 	}
 
+	if len(h.Steps) > 0 {
+		acc.AddError(h.gatherTransaction(acc))
+	}
+
+	return nil
+}
+
+// gatherTransaction runs the steps in order sharing cookies and captured
+// headers. The transaction is aborted at the first failing step.
+func (h *HTTPResponse) gatherTransaction(acc telegraf.Accumulator) error {
+	// Start each transaction without cookies of previous runs
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return fmt.Errorf("creating cookie jar failed: %w", err)
+	}
+	h.transactionClient.Jar = jar
+	shared := make(http.Header)
+
+	result := "success"
+	var failedStep string
+	var completed int
+	var responseTime float64
+	for _, s := range h.Steps {
+		s.req.shared = shared
+		fields, tags, err := h.doRequest(h.transactionClient, s.req)
+		if err != nil {
+			return fmt.Errorf("running step %q of transaction %q failed: %w", s.Name, h.TransactionName, err)
+		}
+		tags["transaction"] = h.TransactionName
+		tags["step"] = s.Name
+		acc.AddFields("http_response", fields, tags)
+
+		if v, ok := fields["response_time"].(float64); ok {
+			responseTime += v
+		}
+		if tags["result"] != "success" {
+			result = tags["result"]
+			failedStep = s.Name
+			break
+		}
+		completed++
+	}
+
+	tags := map[string]string{
+		"transaction": h.TransactionName,
+		"result":      result,
+	}
+	if failedStep != "" {
+		tags["failed_step"] = failedStep
+	}
+	fields := map[string]interface{}{
+		"success":         failedStep == "",
+		"response_time":   responseTime,
+		"steps":           len(h.Steps),
+		"steps_completed": completed,
+	}
+	acc.AddFields("http_response_transaction", fields, tags)
+
 	return nil
 }
 
@@ -198,12 +371,6 @@ func (h *HTTPResponse) createHTTPClient(address url.URL) (*http.Client, error) {
 		}
 	}
 
-	if h.CookieAuthConfig.URL != "" {
-		if err := h.CookieAuthConfig.Start(client, h.Log, clock.New()); err != nil {
-			return nil, err
-		}
-	}
-
 	return client, nil
 }
 
@@ -306,16 +473,30 @@ func setError(err error, fields map[string]interface{}, tags map[string]string)
 
 // HTTPGather gathers all fields and returns any errors it encounters
 func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string]string, error) {
+	r := &request{
+		address:     cl.address,
+		method:      h.Method,
+		body:        h.Body,
+		bodyForm:    h.BodyForm,
+		headers:     h.Headers,
+		stringMatch: h.compiledStringMatch,
+		statusCode:  h.ResponseStatusCode,
+	}
+	return h.doRequest(cl.httpClient, r)
+}
+
+// doRequest sends the request and checks the response
+func (h *HTTPResponse) doRequest(cl httpClient, r *request) (map[string]interface{}, map[string]string, error) {
 	// Prepare fields and tags
 	fields := make(map[string]interface{})
-	tags := map[string]string{"server": cl.address, "method": h.Method}
+	tags := map[string]string{"server": r.address, "method": r.method}
 
 	var body io.Reader
-	if h.Body != "" {
-		body = strings.NewReader(h.Body)
-	} else if len(h.BodyForm) != 0 {
+	if r.body != "" {
+		body = strings.NewReader(r.body)
+	} else if len(r.bodyForm) != 0 {
 		values := url.Values{}
-		for k, vs := range h.BodyForm {
+		for k, vs := range r.bodyForm {
 			for _, v := range vs {
 				values.Add(k, v)
 			}
@@ -323,12 +504,12 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 		body = strings.NewReader(values.Encode())
 	}
 
-	request, err := http.NewRequest(h.Method, cl.address, body)
+	request, err := http.NewRequest(r.method, r.address, body)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if _, uaPresent := h.Headers["User-Agent"]; !uaPresent {
+	if _, uaPresent := r.headers["User-Agent"]; !uaPresent {
 		request.Header.Set("User-Agent", internal.ProductToken())
 	}
 
@@ -341,12 +522,15 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 		request.Header.Add("Authorization", bearer)
 	}
 
-	for key, val := range h.Headers {
+	for key, val := range r.headers {
 		request.Header.Add(key, val)
 		if key == "Host" {
 			request.Host = val
 		}
 	}
+	for key, vals := range r.shared {
+		request.Header[key] = vals
+	}
 
 	if err := h.setRequestAuth(request); err != nil {
 		return nil, nil, err
@@ -354,14 +538,14 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 
 	// Start Timer
 	start := time.Now()
-	resp, err := cl.httpClient.Do(request)
+	resp, err := cl.Do(request)
 	responseTime := time.Since(start).Seconds()
 
 	// If an error in returned, it means we are dealing with a network error, as
 	// HTTP error codes do not generate errors in the net/http library
 	if err != nil {
 		// Log error
-		h.Log.Debugf("Network error while polling %s: %s", cl.address, err.Error())
+		h.Log.Debugf("Network error while polling %s: %s", r.address, err.Error())
 
 		// Get error details
 		if setError(err, fields, tags) == nil {
@@ -380,6 +564,13 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 	// required by the net/http library
 	defer resp.Body.Close()
 
+	// Capture the headers for subsequent requests
+	for _, name := range r.capture {
+		if values := resp.Header.Values(name); len(values) > 0 {
+			r.shared[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+
 	// Add the response headers
 	for headerName, tag := range h.HTTPHeaderTags {
 		headerValues, foundHeader := resp.Header[headerName]
@@ -398,10 +589,10 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, int64(h.ResponseBodyMaxSize)+1))
 	// Check first if the response body size exceeds the limit.
 	if err == nil && int64(len(bodyBytes)) > int64(h.ResponseBodyMaxSize) {
-		h.setBodyReadError("The body of the HTTP Response is too large", bodyBytes, fields, tags)
+		h.setBodyReadError("The body of the HTTP Response is too large", r, bodyBytes, fields, tags)
 		return fields, tags, nil
 	} else if err != nil {
-		h.setBodyReadError("Failed to read body of HTTP Response : "+err.Error(), bodyBytes, fields, tags)
+		h.setBodyReadError("Failed to read body of HTTP Response : "+err.Error(), r, bodyBytes, fields, tags)
 		return fields, tags, nil
 	}
 
@@ -409,7 +600,7 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 	if len(h.ResponseBodyField) > 0 {
 		// Check that the content of response contains only valid utf-8 characters.
 		if !utf8.Valid(bodyBytes) {
-			h.setBodyReadError("The body of the HTTP Response is not a valid utf-8 string", bodyBytes, fields, tags)
+			h.setBodyReadError("The body of the HTTP Response is not a valid utf-8 string", r, bodyBytes, fields, tags)
 			return fields, tags, nil
 		}
 		fields[h.ResponseBodyField] = string(bodyBytes)
@@ -419,8 +610,8 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 	var success = true
 
 	// Check the response for a regex
-	if h.ResponseStringMatch != "" {
-		if h.compiledStringMatch.Match(bodyBytes) {
+	if r.stringMatch != nil {
+		if r.stringMatch.Match(bodyBytes) {
 			fields["response_string_match"] = 1
 		} else {
 			success = false
@@ -430,8 +621,8 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 	}
 
 	// Check the response status code
-	if h.ResponseStatusCode > 0 {
-		if resp.StatusCode == h.ResponseStatusCode {
+	if r.statusCode > 0 {
+		if resp.StatusCode == r.statusCode {
 			fields["response_status_code_match"] = 1
 		} else {
 			success = false
@@ -448,11 +639,11 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 }
 
 // Set result in case of a body read error
-func (h *HTTPResponse) setBodyReadError(errorMsg string, bodyBytes []byte, fields map[string]interface{}, tags map[string]string) {
+func (h *HTTPResponse) setBodyReadError(errorMsg string, r *request, bodyBytes []byte, fields map[string]interface{}, tags map[string]string) {
 	h.Log.Debug(errorMsg)
 	setResult("body_read_error", fields, tags)
 	fields["content_length"] = len(bodyBytes)
-	if h.ResponseStringMatch != "" {
+	if r.stringMatch != nil {
 		fields["response_string_match"] = 0
 	}
 }
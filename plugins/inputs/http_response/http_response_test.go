@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -1479,3 +1480,216 @@ func parseURL(t *testing.T, address string) url.URL {
 	require.NotNil(t, u)
 	return *u
 }
+
+func TestTransaction(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.FormValue("user") != "admin" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "42"})
+		w.Header().Set("X-CSRF-Token", "secret")
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != "42" || r.Header.Get("X-CSRF-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, "status: up")
+	})
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", MaxAge: -1})
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	plugin := &HTTPResponse{
+		Log:             testutil.Logger{},
+		ResponseTimeout: config.Duration(time.Second * 2),
+		TransactionName: "journey",
+		Steps: []*Step{
+			{
+				Name:               "login",
+				URL:                ts.URL + "/login",
+				Method:             "POST",
+				BodyForm:           map[string][]string{"user": {"admin"}},
+				Headers:            map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+				ResponseStatusCode: http.StatusOK,
+				CaptureHeaders:     []string{"x-csrf-token"},
+			},
+			{
+				Name:                "fetch",
+				URL:                 ts.URL + "/data",
+				ResponseStringMatch: "status: up",
+				ResponseStatusCode:  http.StatusOK,
+			},
+			{
+				Name:               "logout",
+				URL:                ts.URL + "/logout",
+				ResponseStatusCode: http.StatusOK,
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+	require.Empty(t, plugin.URLs)
+
+	// Run twice to make sure the cookies do not leak into the next run
+	for i := 0; i < 2; i++ {
+		var acc testutil.Accumulator
+		require.NoError(t, plugin.Gather(&acc))
+		require.Empty(t, acc.Errors)
+
+		expected := []telegraf.Metric{
+			metric.New(
+				"http_response",
+				map[string]string{
+					"server":      ts.URL + "/login",
+					"method":      "POST",
+					"status_code": "200",
+					"result":      "success",
+					"transaction": "journey",
+					"step":        "login",
+				},
+				map[string]interface{}{
+					"content_length":             int64(0),
+					"http_response_code":         http.StatusOK,
+					"response_status_code_match": 1,
+					"result_type":                "success",
+					"result_code":                0,
+				},
+				time.Unix(0, 0),
+			),
+			metric.New(
+				"http_response",
+				map[string]string{
+					"server":      ts.URL + "/data",
+					"method":      "GET",
+					"status_code": "200",
+					"result":      "success",
+					"transaction": "journey",
+					"step":        "fetch",
+				},
+				map[string]interface{}{
+					"content_length":             int64(10),
+					"http_response_code":         http.StatusOK,
+					"response_string_match":      1,
+					"response_status_code_match": 1,
+					"result_type":                "success",
+					"result_code":                0,
+				},
+				time.Unix(0, 0),
+			),
+			metric.New(
+				"http_response",
+				map[string]string{
+					"server":      ts.URL + "/logout",
+					"method":      "GET",
+					"status_code": "200",
+					"result":      "success",
+					"transaction": "journey",
+					"step":        "logout",
+				},
+				map[string]interface{}{
+					"content_length":             int64(0),
+					"http_response_code":         http.StatusOK,
+					"response_status_code_match": 1,
+					"result_type":                "success",
+					"result_code":                0,
+				},
+				time.Unix(0, 0),
+			),
+			metric.New(
+				"http_response_transaction",
+				map[string]string{
+					"transaction": "journey",
+					"result":      "success",
+				},
+				map[string]interface{}{
+					"success":         true,
+					"steps":           3,
+					"steps_completed": 3,
+				},
+				time.Unix(0, 0),
+			),
+		}
+		testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.IgnoreFields("response_time"))
+	}
+}
+
+func TestTransactionFailure(t *testing.T) {
+	var logoutCalled bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, _ *http.Request) {
+		logoutCalled = true
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	plugin := &HTTPResponse{
+		Log:             testutil.Logger{},
+		ResponseTimeout: config.Duration(time.Second * 2),
+		Steps: []*Step{
+			{Name: "login", URL: ts.URL + "/login", ResponseStatusCode: http.StatusOK},
+			{Name: "logout", URL: ts.URL + "/logout"},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.False(t, logoutCalled)
+
+	expected := metric.New(
+		"http_response_transaction",
+		map[string]string{
+			"transaction": "transaction",
+			"result":      "response_status_code_mismatch",
+			"failed_step": "login",
+		},
+		map[string]interface{}{
+			"success":         false,
+			"steps":           2,
+			"steps_completed": 0,
+		},
+		time.Unix(0, 0),
+	)
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 2)
+	testutil.RequireMetricEqual(t, expected, metrics[1], testutil.IgnoreTime(), testutil.IgnoreFields("response_time"))
+}
+
+func TestTransactionInvalidSteps(t *testing.T) {
+	plugin := &HTTPResponse{
+		Log:   testutil.Logger{},
+		Steps: []*Step{{URL: "http://localhost/login"}},
+	}
+	require.EqualError(t, plugin.Init(), "step without name")
+
+	plugin = &HTTPResponse{
+		Log: testutil.Logger{},
+		Steps: []*Step{
+			{Name: "login", URL: "http://localhost/login"},
+			{Name: "login", URL: "http://localhost/logout"},
+		},
+	}
+	require.EqualError(t, plugin.Init(), `duplicate step name "login"`)
+
+	plugin = &HTTPResponse{
+		Log:   testutil.Logger{},
+		Steps: []*Step{{Name: "login", URL: "ftp://localhost/login"}},
+	}
+	require.ErrorContains(t, plugin.Init(), "only http and https types are supported")
+}
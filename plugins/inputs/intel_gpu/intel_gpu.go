@@ -0,0 +1,216 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package intel_gpu
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/gpu"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	measurement = "intel_gpu"
+	vendorIntel = "0x8086"
+)
+
+// gpuSchema converts the native metrics into the common GPU schema
+var gpuSchema = &gpu.Schema{
+	Vendor: "intel",
+	Measurements: map[string]gpu.Mapping{
+		measurement: {
+			Tags: map[string]gpu.Tag{
+				"index": {Name: "index"},
+			},
+			Fields: map[string]gpu.Field{
+				"frequency_actual":   {Name: "clock_graphics"},
+				"power_draw":         {Name: "power_draw"},
+				"power_limit":        {Name: "power_limit"},
+				"temperature":        {Name: "temperature"},
+				"temperature_memory": {Name: "temperature_memory"},
+			},
+		},
+	},
+}
+
+var cardRe = regexp.MustCompile(`^card(\d+)$`)
+
+// Frequency files of the i915 and xe drivers relative to the card directory
+var frequencyFiles = map[string][]string{
+	"frequency_actual":    {"gt_act_freq_mhz", "device/tile0/gt0/freq0/act_freq"},
+	"frequency_requested": {"gt_cur_freq_mhz", "device/tile0/gt0/freq0/cur_freq"},
+	"frequency_min":       {"gt_min_freq_mhz", "device/tile0/gt0/freq0/min_freq"},
+	"frequency_max":       {"gt_max_freq_mhz", "device/tile0/gt0/freq0/max_freq"},
+}
+
+type IntelGPU struct {
+	MetricSchema string          `toml:"metric_schema"`
+	Log          telegraf.Logger `toml:"-"`
+
+	drmPath string
+	energy  map[string]energySample
+}
+
+// energySample is the energy counter of a card at the time of the last gather
+// used to compute the power draw
+type energySample struct {
+	joules    float64
+	timestamp time.Time
+}
+
+func (*IntelGPU) SampleConfig() string {
+	return sampleConfig
+}
+
+func (i *IntelGPU) Init() error {
+	if err := gpu.CheckSchema(i.MetricSchema); err != nil {
+		return err
+	}
+	if i.drmPath == "" {
+		i.drmPath = "/sys/class/drm"
+	}
+	i.energy = make(map[string]energySample)
+	return nil
+}
+
+func (i *IntelGPU) Gather(acc telegraf.Accumulator) error {
+	if i.MetricSchema == "gpu" {
+		acc = &gpu.Accumulator{Accumulator: acc, Schema: gpuSchema}
+	}
+
+	cards, err := os.ReadDir(i.drmPath)
+	if err != nil {
+		return fmt.Errorf("reading DRM devices failed: %w", err)
+	}
+
+	for _, card := range cards {
+		m := cardRe.FindStringSubmatch(card.Name())
+		if m == nil {
+			continue
+		}
+		path := filepath.Join(i.drmPath, card.Name())
+		if vendor, err := readString(filepath.Join(path, "device", "vendor")); err != nil || vendor != vendorIntel {
+			continue
+		}
+		if err := i.gatherCard(acc, card.Name(), m[1], path); err != nil {
+			acc.AddError(fmt.Errorf("gathering %q failed: %w", card.Name(), err))
+		}
+	}
+
+	return nil
+}
+
+func (i *IntelGPU) gatherCard(acc telegraf.Accumulator, name, index, path string) error {
+	tags := map[string]string{"index": index}
+	if device, err := readString(filepath.Join(path, "device", "device")); err == nil {
+		tags["device_id"] = device
+	}
+	if driver, err := os.Readlink(filepath.Join(path, "device", "driver")); err == nil {
+		tags["driver"] = filepath.Base(driver)
+	}
+
+	fields := make(map[string]interface{}, 10)
+	for field, candidates := range frequencyFiles {
+		for _, candidate := range candidates {
+			if v, err := readInt(filepath.Join(path, candidate)); err == nil {
+				fields[field] = v
+				break
+			}
+		}
+	}
+
+	hwmons, err := filepath.Glob(filepath.Join(path, "device", "hwmon", "hwmon*"))
+	if err != nil {
+		return err
+	}
+	if len(hwmons) > 0 {
+		i.gatherHwmon(fields, name, hwmons[0], time.Now())
+	}
+
+	if len(fields) == 0 {
+		return errors.New("no metrics available")
+	}
+	acc.AddFields(measurement, fields, tags)
+
+	return nil
+}
+
+// gatherHwmon reads the power, temperature and fan metrics of the hardware
+// monitor of the card
+func (i *IntelGPU) gatherHwmon(fields map[string]interface{}, name, path string, now time.Time) {
+	// Energy is reported as cumulative counter in microjoules
+	if v, err := readInt(filepath.Join(path, "energy1_input")); err == nil {
+		joules := float64(v) / 1e6
+		fields["energy"] = joules
+		if last, found := i.energy[name]; found && joules >= last.joules && now.After(last.timestamp) {
+			fields["power_draw"] = (joules - last.joules) / now.Sub(last.timestamp).Seconds()
+		}
+		i.energy[name] = energySample{joules: joules, timestamp: now}
+	}
+
+	// Power limits are reported in microwatts
+	if v, err := readInt(filepath.Join(path, "power1_max")); err == nil && v > 0 {
+		fields["power_limit"] = float64(v) / 1e6
+	}
+
+	// Temperatures are reported in millidegrees Celsius, the xe driver labels
+	// the package and memory sensors
+	inputs, err := filepath.Glob(filepath.Join(path, "temp*_input"))
+	if err == nil {
+		for _, input := range inputs {
+			v, err := readInt(input)
+			if err != nil {
+				continue
+			}
+			label, err := readString(strings.TrimSuffix(input, "_input") + "_label")
+			if err != nil {
+				label = "pkg"
+			}
+			switch label {
+			case "pkg":
+				fields["temperature"] = float64(v) / 1000.0
+			case "vram":
+				fields["temperature_memory"] = float64(v) / 1000.0
+			}
+		}
+	}
+
+	if v, err := readInt(filepath.Join(path, "fan1_input")); err == nil {
+		fields["fan_speed_rpm"] = v
+	}
+}
+
+func readString(path string) (string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(buf)), nil
+}
+
+func readInt(path string) (int64, error) {
+	s, err := readString(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func init() {
+	inputs.Add("intel_gpu", func() telegraf.Input {
+		return &IntelGPU{}
+	})
+}
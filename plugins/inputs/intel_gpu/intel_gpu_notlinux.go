@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package intel_gpu
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type IntelGPU struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*IntelGPU) SampleConfig() string { return sampleConfig }
+
+func (i *IntelGPU) Init() error {
+	i.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*IntelGPU) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("intel_gpu", func() telegraf.Input {
+		return &IntelGPU{}
+	})
+}
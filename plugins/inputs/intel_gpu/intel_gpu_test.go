@@ -0,0 +1,209 @@
+//go:build linux
+
+package intel_gpu
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, os.WriteFile(path, []byte(content+"\n"), 0600))
+}
+
+// createSysfs creates a DRM sysfs tree with an i915 card, a xe card, a
+// non-Intel card and a connector entry
+func createSysfs(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	drm := filepath.Join(root, "class", "drm")
+	drivers := filepath.Join(root, "bus", "pci", "drivers")
+
+	for _, driver := range []string{"i915", "xe", "nvidia"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(drivers, driver), 0750))
+	}
+
+	// i915 discrete GPU
+	card0 := filepath.Join(drm, "card0")
+	writeFile(t, filepath.Join(card0, "device", "vendor"), "0x8086")
+	writeFile(t, filepath.Join(card0, "device", "device"), "0x56a0")
+	require.NoError(t, os.Symlink(filepath.Join(drivers, "i915"), filepath.Join(card0, "device", "driver")))
+	writeFile(t, filepath.Join(card0, "gt_act_freq_mhz"), "2400")
+	writeFile(t, filepath.Join(card0, "gt_cur_freq_mhz"), "2400")
+	writeFile(t, filepath.Join(card0, "gt_min_freq_mhz"), "300")
+	writeFile(t, filepath.Join(card0, "gt_max_freq_mhz"), "2400")
+	hwmon0 := filepath.Join(card0, "device", "hwmon", "hwmon3")
+	writeFile(t, filepath.Join(hwmon0, "energy1_input"), "1534265893")
+	writeFile(t, filepath.Join(hwmon0, "power1_max"), "190000000")
+	writeFile(t, filepath.Join(hwmon0, "temp1_input"), "51000")
+	writeFile(t, filepath.Join(hwmon0, "fan1_input"), "1181")
+	require.NoError(t, os.MkdirAll(filepath.Join(drm, "card0-DP-1"), 0750))
+
+	// NVIDIA GPU to be ignored
+	card1 := filepath.Join(drm, "card1")
+	writeFile(t, filepath.Join(card1, "device", "vendor"), "0x10de")
+	require.NoError(t, os.Symlink(filepath.Join(drivers, "nvidia"), filepath.Join(card1, "device", "driver")))
+
+	// xe discrete GPU
+	card2 := filepath.Join(drm, "card2")
+	writeFile(t, filepath.Join(card2, "device", "vendor"), "0x8086")
+	writeFile(t, filepath.Join(card2, "device", "device"), "0xe20b")
+	require.NoError(t, os.Symlink(filepath.Join(drivers, "xe"), filepath.Join(card2, "device", "driver")))
+	freq := filepath.Join(card2, "device", "tile0", "gt0", "freq0")
+	writeFile(t, filepath.Join(freq, "act_freq"), "1200")
+	writeFile(t, filepath.Join(freq, "cur_freq"), "1200")
+	writeFile(t, filepath.Join(freq, "min_freq"), "400")
+	writeFile(t, filepath.Join(freq, "max_freq"), "2850")
+	hwmon2 := filepath.Join(card2, "device", "hwmon", "hwmon5")
+	writeFile(t, filepath.Join(hwmon2, "energy1_input"), "873120000")
+	writeFile(t, filepath.Join(hwmon2, "power1_max"), "150000000")
+	writeFile(t, filepath.Join(hwmon2, "temp2_input"), "45000")
+	writeFile(t, filepath.Join(hwmon2, "temp2_label"), "pkg")
+	writeFile(t, filepath.Join(hwmon2, "temp3_input"), "50000")
+	writeFile(t, filepath.Join(hwmon2, "temp3_label"), "vram")
+
+	return drm
+}
+
+func TestGather(t *testing.T) {
+	plugin := &IntelGPU{
+		Log:     testutil.Logger{},
+		drmPath: createSysfs(t),
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"intel_gpu",
+			map[string]string{
+				"index":     "0",
+				"device_id": "0x56a0",
+				"driver":    "i915",
+			},
+			map[string]interface{}{
+				"frequency_actual":    int64(2400),
+				"frequency_requested": int64(2400),
+				"frequency_min":       int64(300),
+				"frequency_max":       int64(2400),
+				"energy":              1534.265893,
+				"power_limit":         190.0,
+				"temperature":         51.0,
+				"fan_speed_rpm":       int64(1181),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"intel_gpu",
+			map[string]string{
+				"index":     "2",
+				"device_id": "0xe20b",
+				"driver":    "xe",
+			},
+			map[string]interface{}{
+				"frequency_actual":    int64(1200),
+				"frequency_requested": int64(1200),
+				"frequency_min":       int64(400),
+				"frequency_max":       int64(2850),
+				"energy":              873.12,
+				"power_limit":         150.0,
+				"temperature":         45.0,
+				"temperature_memory":  50.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestPowerDraw(t *testing.T) {
+	drm := createSysfs(t)
+	plugin := &IntelGPU{
+		Log:     testutil.Logger{},
+		drmPath: drm,
+	}
+	require.NoError(t, plugin.Init())
+
+	hwmon := filepath.Join(drm, "card0", "device", "hwmon", "hwmon3")
+	now := time.Now()
+	fields := make(map[string]interface{})
+	plugin.gatherHwmon(fields, "card0", hwmon, now)
+	require.NotContains(t, fields, "power_draw")
+
+	// 85 joules within two seconds
+	writeFile(t, filepath.Join(hwmon, "energy1_input"), "1619265893")
+	fields = make(map[string]interface{})
+	plugin.gatherHwmon(fields, "card0", hwmon, now.Add(2*time.Second))
+	require.InDelta(t, 42.5, fields["power_draw"], 1e-6)
+
+	// Counter reset must not result in a negative power draw
+	writeFile(t, filepath.Join(hwmon, "energy1_input"), "1000")
+	fields = make(map[string]interface{})
+	plugin.gatherHwmon(fields, "card0", hwmon, now.Add(4*time.Second))
+	require.NotContains(t, fields, "power_draw")
+}
+
+func TestGPUSchema(t *testing.T) {
+	plugin := &IntelGPU{
+		MetricSchema: "gpu",
+		Log:          testutil.Logger{},
+		drmPath:      createSysfs(t),
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"gpu",
+			map[string]string{
+				"vendor": "intel",
+				"index":  "0",
+			},
+			map[string]interface{}{
+				"clock_graphics": 2400.0,
+				"power_limit":    190.0,
+				"temperature":    51.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gpu",
+			map[string]string{
+				"vendor": "intel",
+				"index":  "2",
+			},
+			map[string]interface{}{
+				"clock_graphics":     1200.0,
+				"power_limit":        150.0,
+				"temperature":        45.0,
+				"temperature_memory": 50.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestInvalidMetricSchema(t *testing.T) {
+	plugin := &IntelGPU{
+		MetricSchema: "foo",
+		Log:          testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid 'metric_schema' setting")
+}
@@ -48,7 +48,14 @@ type Ipmi struct {
 	UseSudo       bool            `toml:"use_sudo"`
 	UseCache      bool            `toml:"use_cache"`
 	CachePath     string          `toml:"cache_path"`
+	Mode          string          `toml:"mode"`
+	CipherSuite   int             `toml:"cipher_suite"`
 	Log           telegraf.Logger `toml:"-"`
+
+	privilege    byte
+	bmcKey       []byte
+	sdrCache     map[string][]*sdrRecord
+	sdrCacheLock sync.Mutex
 }
 
 func (*Ipmi) SampleConfig() string {
@@ -56,6 +63,15 @@ func (*Ipmi) SampleConfig() string {
 }
 
 func (m *Ipmi) Init() error {
+	switch m.Mode {
+	case "", "ipmitool":
+		m.Mode = "ipmitool"
+	case "native":
+		return m.initNative()
+	default:
+		return fmt.Errorf("invalid mode %q", m.Mode)
+	}
+
 	// Set defaults
 	if m.Path == "" {
 		path, err := exec.LookPath(cmd)
@@ -82,7 +98,56 @@ func (m *Ipmi) Init() error {
 	return nil
 }
 
+func (m *Ipmi) initNative() error {
+	if len(m.Servers) == 0 {
+		return errors.New("native mode requires at least one server")
+	}
+	if _, found := cipherSuites[m.CipherSuite]; !found {
+		return fmt.Errorf("unsupported cipher suite %d", m.CipherSuite)
+	}
+
+	if m.Privilege == "" {
+		m.Privilege = "ADMINISTRATOR"
+	}
+	privilege, found := privilegeLevels[strings.ToUpper(m.Privilege)]
+	if !found {
+		return fmt.Errorf("invalid privilege level %q", m.Privilege)
+	}
+	m.privilege = privilege
+
+	if m.HexKey != "" {
+		key, err := parseHexKey(m.HexKey)
+		if err != nil {
+			return err
+		}
+		m.bmcKey = key
+	}
+
+	if len(m.Sensors) == 0 {
+		m.Sensors = []string{"sdr"}
+	}
+	if err := choice.CheckSlice(m.Sensors, []string{"sdr", "chassis_power_status", "dcmi_power_reading"}); err != nil {
+		return err
+	}
+	m.sdrCache = make(map[string][]*sdrRecord)
+
+	return nil
+}
+
 func (m *Ipmi) Gather(acc telegraf.Accumulator) error {
+	if m.Mode == "native" {
+		var wg sync.WaitGroup
+		for _, server := range m.Servers {
+			wg.Add(1)
+			go func(s string) {
+				defer wg.Done()
+				acc.AddError(m.gatherNative(acc, s))
+			}(server)
+		}
+		wg.Wait()
+		return nil
+	}
+
 	if len(m.Path) == 0 {
 		return errors.New("ipmitool not found: verify that ipmitool is installed and that ipmitool is in your PATH")
 	}
@@ -403,6 +468,9 @@ func transform(s string) string {
 
 func init() {
 	inputs.Add("ipmi_sensor", func() telegraf.Input {
-		return &Ipmi{Timeout: config.Duration(20 * time.Second)}
+		return &Ipmi{
+			Timeout:     config.Duration(20 * time.Second),
+			CipherSuite: 3,
+		}
 	})
 }
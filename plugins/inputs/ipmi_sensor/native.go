@@ -0,0 +1,305 @@
+package ipmi_sensor
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Number of bytes read per 'Get SDR' request, larger reads are not supported
+// by all BMCs
+const sdrChunkSize = 16
+
+// gatherNative queries the sensors of the given server via IPMI v2.0/RMCP+
+// without calling ipmitool
+func (m *Ipmi) gatherNative(acc telegraf.Accumulator, server string) error {
+	conn := newConnection(server, m.Privilege, m.HexKey)
+	if conn.hostname == "" {
+		return errors.New("no server address specified")
+	}
+	address := conn.hostname
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, defaultRMCPPort)
+	}
+
+	client := &rmcpClient{
+		address:   address,
+		username:  conn.username,
+		password:  conn.password,
+		bmcKey:    m.bmcKey,
+		privilege: m.privilege,
+		suite:     cipherSuites[m.CipherSuite],
+		timeout:   time.Duration(m.Timeout),
+	}
+	defer func() {
+		if err := client.close(); err != nil {
+			m.Log.Debugf("Closing session to %q failed: %v", conn.hostname, err)
+		}
+	}()
+	if err := client.open(); err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", conn.hostname, err)
+	}
+
+	for _, sensor := range m.Sensors {
+		var err error
+		switch sensor {
+		case "sdr":
+			err = m.gatherNativeSDR(acc, client, conn.hostname)
+		case "chassis_power_status":
+			err = gatherNativeChassisPowerStatus(acc, client, conn.hostname)
+		case "dcmi_power_reading":
+			err = gatherNativeDCMIPowerReading(acc, client, conn.hostname)
+		default:
+			err = fmt.Errorf("unknown sensor type %q", sensor)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %q of %q failed: %w", sensor, conn.hostname, err))
+		}
+	}
+
+	return nil
+}
+
+func (m *Ipmi) gatherNativeSDR(acc telegraf.Accumulator, client *rmcpClient, hostname string) error {
+	records, err := m.sdrRecords(client, hostname)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		// Sensors owned by other controllers would require bridging
+		if r.owner != bmcAddress {
+			continue
+		}
+
+		// Sensors not present on the system return an error code
+		response, err := client.request(netFnSensor, r.lun, cmdGetSensorReading, []byte{r.number})
+		var cerr *completionError
+		if err != nil && !errors.As(err, &cerr) {
+			return err
+		}
+		measuredAt := time.Now()
+		available := err == nil && len(response) >= 2 && response[1]&0x40 != 0 && response[1]&0x20 == 0
+
+		var state byte
+		if available && len(response) >= 3 {
+			state = response[2]
+		}
+		status := "ok"
+		if !available {
+			status = "ns"
+		} else if r.analog() {
+			status = thresholdStatus(state)
+		}
+
+		tags := map[string]string{"name": transform(r.name)}
+		if hostname != "" {
+			tags["server"] = hostname
+		}
+		fields := make(map[string]interface{}, 2)
+
+		if m.MetricVersion == 2 {
+			tags["entity_id"] = fmt.Sprintf("%d.%d", r.entityID, r.entityInstance)
+			tags["status_code"] = status
+			switch {
+			case !available:
+				fields["value"] = 0.0
+				tags["status_desc"] = "no_reading"
+			case r.analog():
+				fields["value"] = r.convert(response[0])
+				tags["unit"] = transform(r.unit())
+			default:
+				fields["value"] = 0.0
+				tags["status_desc"] = status
+			}
+		} else {
+			// ipmitool does not report a value for unavailable sensors
+			if !available {
+				continue
+			}
+			if status == "ok" {
+				fields["status"] = 1
+			} else {
+				fields["status"] = 0
+			}
+			if r.analog() {
+				fields["value"] = r.convert(response[0])
+				tags["unit"] = transform(r.unit())
+			} else {
+				fields["value"] = float64(state)
+			}
+		}
+
+		acc.AddFields("ipmi_sensor", fields, tags, measuredAt)
+	}
+
+	return nil
+}
+
+// sdrRecords returns the sensor data records of the BMC, either from the
+// cache or by reading the SDR repository
+func (m *Ipmi) sdrRecords(client *rmcpClient, hostname string) ([]*sdrRecord, error) {
+	if m.UseCache {
+		m.sdrCacheLock.Lock()
+		records, found := m.sdrCache[hostname]
+		m.sdrCacheLock.Unlock()
+		if found {
+			return records, nil
+		}
+	}
+
+	records, err := readSDRRepository(client)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.UseCache {
+		m.sdrCacheLock.Lock()
+		m.sdrCache[hostname] = records
+		m.sdrCacheLock.Unlock()
+	}
+	return records, nil
+}
+
+// readSDRRepository reads all sensor records of the SDR repository
+func readSDRRepository(client *rmcpClient) ([]*sdrRecord, error) {
+	reservation, err := reserveSDRRepository(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*sdrRecord
+	for id := uint16(0); id != 0xffff; {
+		next, data, err := readSDR(client, &reservation, id)
+		if err != nil {
+			return nil, fmt.Errorf("reading record 0x%04x failed: %w", id, err)
+		}
+		record, err := parseSDR(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing record 0x%04x failed: %w", id, err)
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+		if next == id {
+			break
+		}
+		id = next
+	}
+
+	return records, nil
+}
+
+func reserveSDRRepository(client *rmcpClient) (uint16, error) {
+	response, err := client.request(netFnStorage, 0, cmdReserveSDRRepository, nil)
+	if err != nil {
+		return 0, fmt.Errorf("reserving SDR repository failed: %w", err)
+	}
+	if len(response) < 2 {
+		return 0, errors.New("reserving SDR repository failed: response too short")
+	}
+	return binary.LittleEndian.Uint16(response), nil
+}
+
+// readSDR reads a single record in chunks and returns the ID of the next
+// record and the record data including the header. The reservation is
+// renewed if canceled by the BMC.
+func readSDR(client *rmcpClient, reservation *uint16, id uint16) (uint16, []byte, error) {
+	var next uint16
+	data := make([]byte, 0, 64)
+	length := 5
+	for retries := 0; len(data) < length; {
+		count := min(length-len(data), sdrChunkSize)
+		request := binary.LittleEndian.AppendUint16(nil, *reservation)
+		request = binary.LittleEndian.AppendUint16(request, id)
+		request = append(request, byte(len(data)), byte(count))
+
+		response, err := client.request(netFnStorage, 0, cmdGetSDR, request)
+		var cerr *completionError
+		if errors.As(err, &cerr) && cerr.code == completionReservationCancel && retries < 3 {
+			retries++
+			if *reservation, err = reserveSDRRepository(client); err != nil {
+				return 0, nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(response) < 2+count {
+			return 0, nil, errors.New("response too short")
+		}
+
+		next = binary.LittleEndian.Uint16(response)
+		data = append(data, response[2:2+count]...)
+		if len(data) == 5 {
+			length = 5 + int(data[4])
+		}
+	}
+
+	return next, data, nil
+}
+
+func gatherNativeChassisPowerStatus(acc telegraf.Accumulator, client *rmcpClient, hostname string) error {
+	response, err := client.request(netFnChassis, 0, cmdGetChassisStatus, nil)
+	if err != nil {
+		return err
+	}
+	if len(response) < 1 {
+		return errors.New("response too short")
+	}
+
+	acc.AddFields("ipmi_sensor",
+		map[string]interface{}{"value": int(response[0] & 0x01)},
+		map[string]string{"name": "chassis_power_status", "server": hostname},
+		time.Now(),
+	)
+	return nil
+}
+
+func gatherNativeDCMIPowerReading(acc telegraf.Accumulator, client *rmcpClient, hostname string) error {
+	// Request the system power statistics
+	response, err := client.request(netFnDCMI, 0, cmdDCMIGetPowerReading, []byte{dcmiGroupExtension, 0x01, 0x00, 0x00})
+	if err != nil {
+		return err
+	}
+	if len(response) < 9 || response[0] != dcmiGroupExtension {
+		return errors.New("invalid response")
+	}
+	measuredAt := time.Now()
+
+	readings := []string{
+		"instantaneous_power_reading",
+		"minimum_during_sampling_period",
+		"maximum_during_sampling_period",
+		"average_power_reading_over_sample_period",
+	}
+	for i, name := range readings {
+		tags := map[string]string{"name": name, "unit": "watts"}
+		if hostname != "" {
+			tags["server"] = hostname
+		}
+		value := binary.LittleEndian.Uint16(response[1+2*i:])
+		acc.AddFields("ipmi_sensor", map[string]interface{}{"value": float64(value)}, tags, measuredAt)
+	}
+	return nil
+}
+
+// parseHexKey decodes the BMC key given as hex string
+func parseHexKey(key string) ([]byte, error) {
+	key = strings.TrimPrefix(strings.ToLower(key), "0x")
+	result, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex key: %w", err)
+	}
+	if len(result) > 20 {
+		return nil, errors.New("hex key exceeds 20 bytes")
+	}
+	return result, nil
+}
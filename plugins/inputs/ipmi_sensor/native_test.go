@@ -0,0 +1,706 @@
+package ipmi_sensor
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeBMC implements the BMC side of IPMI v2.0/RMCP+ sessions for testing
+type fakeBMC struct {
+	username string
+	password string
+	suite    cipherSuite
+	records  [][]byte
+	readings map[byte][]byte
+
+	conn          net.PacketConn
+	consoleID     uint32
+	bmcID         uint32
+	consoleRandom []byte
+	bmcRandom     []byte
+	guid          []byte
+	user          []byte
+	k1            []byte
+	k2            []byte
+	active        bool
+	reservation   uint16
+	cancelled     bool
+
+	sync.Mutex
+	sdrRequests int
+	sessions    int
+	closed      int
+}
+
+func (b *fakeBMC) start(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	b.conn = conn
+	b.bmcID = 0x0badcafe
+	b.guid = bytes.Repeat([]byte{0xab}, 16)
+	b.bmcRandom = bytes.Repeat([]byte{0x42}, 16)
+	b.reservation = 0x1234
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			response, err := b.handle(buf[:n])
+			if err != nil {
+				t.Logf("fake BMC: %v", err)
+				continue
+			}
+			if _, err := conn.WriteTo(response, addr); err != nil {
+				return
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func (b *fakeBMC) hmac(key []byte, data ...[]byte) []byte {
+	h := hmac.New(b.suite.hash, key)
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+func (b *fakeBMC) handle(packet []byte) ([]byte, error) {
+	// Session-less IPMI v1.5 request
+	if packet[4] == authTypeNone {
+		msg := packet[14 : 14+int(packet[13])]
+		response := responseMessage(msg, 0x00, []byte{0x01, 0x80, 0x14, 0x02, 0, 0, 0, 0})
+		header := []byte{rmcpVersion, 0x00, rmcpNoAck, rmcpClassIPMI, authTypeNone, 0, 0, 0, 0, 0, 0, 0, 0, byte(len(response))}
+		return append(header, response...), nil
+	}
+
+	ptype := packet[5]
+	length := int(binary.LittleEndian.Uint16(packet[14:16]))
+	payload := packet[16 : 16+length]
+
+	consoleID := binary.LittleEndian.AppendUint32(nil, b.consoleID)
+	bmcID := binary.LittleEndian.AppendUint32(nil, b.bmcID)
+
+	switch ptype & 0x3f {
+	case payloadOpenSessionReq:
+		b.active = false
+		b.consoleID = binary.LittleEndian.Uint32(payload[4:8])
+		consoleID = binary.LittleEndian.AppendUint32(nil, b.consoleID)
+		response := []byte{payload[0], 0x00, payload[1], 0x00}
+		response = append(response, consoleID...)
+		response = append(response, bmcID...)
+		response = append(response, 0x00, 0, 0, 0x08, payload[12], 0, 0, 0)
+		response = append(response, 0x01, 0, 0, 0x08, payload[20], 0, 0, 0)
+		response = append(response, 0x02, 0, 0, 0x08, payload[28], 0, 0, 0)
+		return b.encode(payloadOpenSessionResp, response), nil
+	case payloadRAKP1:
+		b.consoleRandom = bytes.Clone(payload[8:24])
+		b.user = bytes.Clone(payload[24:25])
+		b.user = append(b.user, payload[27:28+int(payload[27])]...)
+		response := []byte{payload[0], 0x00, 0x00, 0x00}
+		response = append(response, consoleID...)
+		if string(payload[28:28+int(payload[27])]) != b.username {
+			response[1] = 0x0d
+			return b.encode(payloadRAKP2, response), nil
+		}
+		response = append(response, b.bmcRandom...)
+		response = append(response, b.guid...)
+		response = append(response, b.hmac([]byte(b.password), consoleID, bmcID, b.consoleRandom, b.bmcRandom, b.guid, b.user)...)
+		return b.encode(payloadRAKP2, response), nil
+	case payloadRAKP3:
+		response := []byte{payload[0], 0x00, 0x00, 0x00}
+		response = append(response, consoleID...)
+		if !hmac.Equal(payload[8:], b.hmac([]byte(b.password), b.bmcRandom, consoleID, b.user)) {
+			response[1] = 0x0f
+			return b.encode(payloadRAKP4, response), nil
+		}
+		sik := b.hmac([]byte(b.password), b.consoleRandom, b.bmcRandom, b.user)
+		response = append(response, b.hmac(sik, b.consoleRandom, bmcID, b.guid)[:b.suite.icvLength]...)
+		size := b.suite.hash().Size()
+		b.k1 = b.hmac(sik, bytes.Repeat([]byte{0x01}, size))
+		b.k2 = b.hmac(sik, bytes.Repeat([]byte{0x02}, size))
+		encoded := b.encode(payloadRAKP4, response)
+		b.active = true
+		b.Lock()
+		b.sessions++
+		b.Unlock()
+		return encoded, nil
+	case payloadIPMI:
+		if !b.active || binary.LittleEndian.Uint32(packet[6:10]) != b.bmcID {
+			return nil, errors.New("request outside of session")
+		}
+		if b.suite.integrity != 0 {
+			end := len(packet) - b.suite.icvLength
+			if ptype&payloadAuthenticated == 0 || !hmac.Equal(b.hmac(b.k1, packet[4:end])[:b.suite.icvLength], packet[end:]) {
+				return nil, errors.New("integrity check failed")
+			}
+		}
+		if b.suite.confidentiality != 0 {
+			if ptype&payloadEncrypted == 0 {
+				return nil, errors.New("unencrypted request")
+			}
+			var err error
+			if payload, err = decrypt(b.k2[:16], payload); err != nil {
+				return nil, err
+			}
+		}
+		code, data := b.command(payload)
+		return b.encode(payloadIPMI, responseMessage(payload, code, data)), nil
+	}
+	return nil, fmt.Errorf("unexpected payload type 0x%02x", ptype)
+}
+
+func (b *fakeBMC) command(msg []byte) (code byte, data []byte) {
+	netFn, cmd := msg[1]>>2, msg[5]
+	request := msg[6 : len(msg)-1]
+
+	switch {
+	case netFn == netFnApp && cmd == cmdSetSessionPrivilegeLevel:
+		return 0x00, request[:1]
+	case netFn == netFnApp && cmd == cmdCloseSession:
+		b.Lock()
+		b.closed++
+		b.Unlock()
+		return 0x00, nil
+	case netFn == netFnStorage && cmd == cmdReserveSDRRepository:
+		return 0x00, binary.LittleEndian.AppendUint16(nil, b.reservation)
+	case netFn == netFnStorage && cmd == cmdGetSDR:
+		b.Lock()
+		b.sdrRequests++
+		b.Unlock()
+		// Cancel the first reservation while reading the second record
+		id := binary.LittleEndian.Uint16(request[2:4])
+		if !b.cancelled && id == 1 && request[4] > 0 {
+			b.cancelled = true
+			b.reservation++
+			return completionReservationCancel, nil
+		}
+		if binary.LittleEndian.Uint16(request[0:2]) != b.reservation {
+			return completionReservationCancel, nil
+		}
+		record := b.records[id]
+		next := id + 1
+		if int(next) == len(b.records) {
+			next = 0xffff
+		}
+		offset, count := int(request[4]), int(request[5])
+		data = binary.LittleEndian.AppendUint16(nil, next)
+		return 0x00, append(data, record[offset:offset+count]...)
+	case netFn == netFnSensor && cmd == cmdGetSensorReading:
+		if reading, found := b.readings[request[0]]; found {
+			return 0x00, reading
+		}
+		return 0xcb, nil
+	case netFn == netFnChassis && cmd == cmdGetChassisStatus:
+		return 0x00, []byte{0x01, 0x00, 0x00, 0x00}
+	case netFn == netFnDCMI && cmd == cmdDCMIGetPowerReading:
+		return 0x00, []byte{0xdc, 167, 0, 124, 0, 166, 1, 156, 0, 0, 0, 0, 0, 0xe8, 0x03, 0, 0, 0x40}
+	}
+	return 0xc1, nil
+}
+
+func (b *fakeBMC) encode(ptype byte, payload []byte) []byte {
+	sessionID, sequence := uint32(0), uint32(0)
+	if b.active {
+		sessionID, sequence = b.consoleID, 1
+		if b.suite.confidentiality != 0 {
+			payload, _ = encrypt(b.k2[:16], payload)
+			ptype |= payloadEncrypted
+		}
+		if b.suite.integrity != 0 {
+			ptype |= payloadAuthenticated
+		}
+	}
+	packet := []byte{rmcpVersion, 0x00, rmcpNoAck, rmcpClassIPMI, authTypeRMCPP, ptype}
+	packet = binary.LittleEndian.AppendUint32(packet, sessionID)
+	packet = binary.LittleEndian.AppendUint32(packet, sequence)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(payload)))
+	packet = append(packet, payload...)
+	if ptype&payloadAuthenticated != 0 {
+		padding := (4 - (len(packet)-4+2)%4) % 4
+		packet = append(packet, bytes.Repeat([]byte{0xff}, padding)...)
+		packet = append(packet, byte(padding), rmcpClassIPMI)
+		packet = append(packet, b.hmac(b.k1, packet[4:])[:b.suite.icvLength]...)
+	}
+	return packet
+}
+
+func responseMessage(request []byte, code byte, data []byte) []byte {
+	msg := []byte{consoleAddress, (request[1]>>2+1)<<2 | request[1]&0x03}
+	msg = append(msg, checksum(msg))
+	msg = append(msg, bmcAddress, request[4], request[5], code)
+	msg = append(msg, data...)
+	return append(msg, checksum(msg[3:]))
+}
+
+// fullSDR creates a full sensor record owned by the BMC
+func fullSDR(id uint16, number byte, name string, entity, instance, readingType, units1, unit byte, m, b, bExp, rExp int) []byte {
+	record := make([]byte, 48, 48+len(name))
+	binary.LittleEndian.PutUint16(record[0:2], id)
+	record[2], record[3] = 0x51, sdrTypeFull
+	record[5], record[7] = bmcAddress, number
+	record[8], record[9] = entity, instance
+	record[13] = readingType
+	record[20], record[21] = units1, unit
+	record[24], record[25] = byte(m), byte(m>>2)&0xc0
+	record[26], record[27] = byte(b), byte(b>>2)&0xc0
+	record[29] = byte(rExp)<<4 | byte(bExp)&0x0f
+	record[47] = 0xc0 | byte(len(name))
+	record = append(record, name...)
+	record[4] = byte(len(record) - 5)
+	return record
+}
+
+// compactSDR creates a compact sensor record owned by the BMC
+func compactSDR(id uint16, number byte, name string, entity, instance, readingType byte) []byte {
+	record := make([]byte, 32, 32+len(name))
+	binary.LittleEndian.PutUint16(record[0:2], id)
+	record[2], record[3] = 0x51, sdrTypeCompact
+	record[5], record[7] = bmcAddress, number
+	record[8], record[9] = entity, instance
+	record[13] = readingType
+	record[31] = 0xc0 | byte(len(name))
+	record = append(record, name...)
+	record[4] = byte(len(record) - 5)
+	return record
+}
+
+func newFakeBMC(suite int) *fakeBMC {
+	// Management controller locator record to be ignored
+	locator := []byte{0x06, 0x00, 0x51, 0x12, 0x05, 0x20, 0x00, 0x00, 0x00, 0x00}
+
+	// Sensor owned by the management engine requiring bridging
+	bridged := fullSDR(7, 0x07, "ME Temp", 3, 2, readingTypeThreshold, 0x00, 1, 1, 0, 0, 0)
+	bridged[5] = 0x2c
+
+	return &fakeBMC{
+		username: "admin",
+		password: "secret",
+		suite:    cipherSuites[suite],
+		records: [][]byte{
+			fullSDR(0, 0x01, "CPU Temp", 3, 1, readingTypeThreshold, 0x00, 1, 1, 0, 0, 0),
+			fullSDR(1, 0x02, "12V", 7, 1, readingTypeThreshold, 0x00, 4, 6, -3, -1, -2),
+			fullSDR(2, 0x03, "Fan 1", 29, 1, readingTypeThreshold, 0x00, 18, 75, 0, 0, 0),
+			compactSDR(3, 0x04, "PS1 Status", 10, 1, 0x6f),
+			fullSDR(4, 0x05, "Disabled", 7, 2, readingTypeThreshold, 0x00, 4, 1, 0, 0, 0),
+			fullSDR(5, 0x06, "Absent", 7, 3, readingTypeThreshold, 0x00, 4, 1, 0, 0, 0),
+			locator,
+			bridged,
+		},
+		readings: map[byte][]byte{
+			0x01: {55, 0xc0, 0x00},
+			0x02: {200, 0xc0, 0x00},
+			0x03: {10, 0xc0, 0x02, 0x00},
+			0x04: {0x00, 0xc0, 0x01, 0x00},
+			0x05: {0x00, 0x80, 0x00},
+		},
+	}
+}
+
+func TestNativeGatherV1(t *testing.T) {
+	bmc := newFakeBMC(3)
+	address := bmc.start(t)
+
+	plugin := &Ipmi{
+		Servers:     []string{"admin:secret@lanplus(" + address + ")"},
+		Mode:        "native",
+		CipherSuite: 3,
+		Timeout:     config.Duration(5 * time.Second),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{"name": "cpu_temp", "server": address, "unit": "degrees_c"},
+			map[string]interface{}{"status": 1, "value": 55.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{"name": "12v", "server": address, "unit": "volts"},
+			map[string]interface{}{"status": 1, "value": 11.997},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{"name": "fan_1", "server": address, "unit": "rpm"},
+			map[string]interface{}{"status": 0, "value": 750.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{"name": "ps1_status", "server": address},
+			map[string]interface{}{"status": 1, "value": 1.0},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	bmc.Lock()
+	defer bmc.Unlock()
+	require.Equal(t, 1, bmc.sessions)
+	require.Equal(t, 1, bmc.closed)
+}
+
+func TestNativeGatherV2(t *testing.T) {
+	bmc := newFakeBMC(17)
+	address := bmc.start(t)
+
+	plugin := &Ipmi{
+		Servers:       []string{"admin:secret@lanplus(" + address + ")"},
+		Mode:          "native",
+		CipherSuite:   17,
+		MetricVersion: 2,
+		Privilege:     "USER",
+		Timeout:       config.Duration(5 * time.Second),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{
+				"name":        "cpu_temp",
+				"server":      address,
+				"entity_id":   "3.1",
+				"status_code": "ok",
+				"unit":        "degrees_c",
+			},
+			map[string]interface{}{"value": 55.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{
+				"name":        "12v",
+				"server":      address,
+				"entity_id":   "7.1",
+				"status_code": "ok",
+				"unit":        "volts",
+			},
+			map[string]interface{}{"value": 11.997},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{
+				"name":        "fan_1",
+				"server":      address,
+				"entity_id":   "29.1",
+				"status_code": "cr",
+				"unit":        "rpm",
+			},
+			map[string]interface{}{"value": 750.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{
+				"name":        "ps1_status",
+				"server":      address,
+				"entity_id":   "10.1",
+				"status_code": "ok",
+				"status_desc": "ok",
+			},
+			map[string]interface{}{"value": 0.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{
+				"name":        "disabled",
+				"server":      address,
+				"entity_id":   "7.2",
+				"status_code": "ns",
+				"status_desc": "no_reading",
+			},
+			map[string]interface{}{"value": 0.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{
+				"name":        "absent",
+				"server":      address,
+				"entity_id":   "7.3",
+				"status_code": "ns",
+				"status_desc": "no_reading",
+			},
+			map[string]interface{}{"value": 0.0},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestNativePowerSensors(t *testing.T) {
+	bmc := newFakeBMC(2)
+	address := bmc.start(t)
+
+	plugin := &Ipmi{
+		Servers:     []string{"admin:secret@lanplus(" + address + ")"},
+		Mode:        "native",
+		CipherSuite: 2,
+		Sensors:     []string{"chassis_power_status", "dcmi_power_reading"},
+		Timeout:     config.Duration(5 * time.Second),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{"name": "chassis_power_status", "server": address},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{"name": "instantaneous_power_reading", "server": address, "unit": "watts"},
+			map[string]interface{}{"value": 167.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{"name": "minimum_during_sampling_period", "server": address, "unit": "watts"},
+			map[string]interface{}{"value": 124.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{"name": "maximum_during_sampling_period", "server": address, "unit": "watts"},
+			map[string]interface{}{"value": 422.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sensor",
+			map[string]string{"name": "average_power_reading_over_sample_period", "server": address, "unit": "watts"},
+			map[string]interface{}{"value": 156.0},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestNativeCache(t *testing.T) {
+	bmc := newFakeBMC(16)
+	address := bmc.start(t)
+
+	plugin := &Ipmi{
+		Servers:     []string{"admin:secret@lanplus(" + address + ")"},
+		Mode:        "native",
+		CipherSuite: 16,
+		UseCache:    true,
+		Timeout:     config.Duration(5 * time.Second),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 4)
+
+	bmc.Lock()
+	requests := bmc.sdrRequests
+	bmc.Unlock()
+	require.Positive(t, requests)
+
+	// The second gather must use the cached records
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 4)
+
+	bmc.Lock()
+	defer bmc.Unlock()
+	require.Equal(t, requests, bmc.sdrRequests)
+	require.Equal(t, 2, bmc.sessions)
+}
+
+func TestNativeAuthenticationFailure(t *testing.T) {
+	bmc := newFakeBMC(3)
+	address := bmc.start(t)
+
+	tests := []struct {
+		name     string
+		server   string
+		expected string
+	}{
+		{
+			name:     "wrong username",
+			server:   "root:secret@lanplus(" + address + ")",
+			expected: "RAKP2 failed with status code 0x0d",
+		},
+		{
+			name:     "wrong password",
+			server:   "admin:foo@lanplus(" + address + ")",
+			expected: "RAKP2 authentication failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Ipmi{
+				Servers:     []string{tt.server},
+				Mode:        "native",
+				CipherSuite: 3,
+				Timeout:     config.Duration(5 * time.Second),
+				Log:         testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			require.Len(t, acc.Errors, 1)
+			require.ErrorContains(t, acc.Errors[0], tt.expected)
+		})
+	}
+}
+
+func TestNativeInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Ipmi
+		expected string
+	}{
+		{
+			name:     "invalid mode",
+			plugin:   &Ipmi{Mode: "foo"},
+			expected: `invalid mode "foo"`,
+		},
+		{
+			name:     "no servers",
+			plugin:   &Ipmi{Mode: "native", CipherSuite: 3},
+			expected: "native mode requires at least one server",
+		},
+		{
+			name:     "unsupported cipher suite",
+			plugin:   &Ipmi{Mode: "native", Servers: []string{"a:b@lan(localhost)"}, CipherSuite: 0},
+			expected: "unsupported cipher suite 0",
+		},
+		{
+			name:     "invalid privilege",
+			plugin:   &Ipmi{Mode: "native", Servers: []string{"a:b@lan(localhost)"}, CipherSuite: 3, Privilege: "ROOT"},
+			expected: `invalid privilege level "ROOT"`,
+		},
+		{
+			name:     "invalid hex key",
+			plugin:   &Ipmi{Mode: "native", Servers: []string{"a:b@lan(localhost)"}, CipherSuite: 3, HexKey: "0xZZ"},
+			expected: "invalid hex key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSDRConversion(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   sdrRecord
+		raw      byte
+		expected float64
+	}{
+		{
+			name:     "unsigned",
+			record:   sdrRecord{m: 1},
+			raw:      200,
+			expected: 200,
+		},
+		{
+			name:     "offset and exponents",
+			record:   sdrRecord{m: 6, b: -3, bExp: -1, rExp: -2},
+			raw:      200,
+			expected: 11.997,
+		},
+		{
+			name:     "two's complement",
+			record:   sdrRecord{units1: 0x80, m: 1},
+			raw:      0xfb,
+			expected: -5,
+		},
+		{
+			name:     "one's complement",
+			record:   sdrRecord{units1: 0x40, m: 1},
+			raw:      0xfb,
+			expected: -4,
+		},
+		{
+			name:     "inverse linearization",
+			record:   sdrRecord{m: 1, linearization: 0x07},
+			raw:      8,
+			expected: 0.125,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.InDelta(t, tt.expected, tt.record.convert(tt.raw), 1e-9)
+		})
+	}
+}
+
+func TestParseSDR(t *testing.T) {
+	record, err := parseSDR(fullSDR(1, 0x02, "12V", 7, 1, readingTypeThreshold, 0x00, 4, 6, -3, -1, -2))
+	require.NoError(t, err)
+	require.Equal(t, "12V", record.name)
+	require.Equal(t, byte(0x02), record.number)
+	require.Equal(t, 6, record.m)
+	require.Equal(t, -3, record.b)
+	require.Equal(t, -1, record.bExp)
+	require.Equal(t, -2, record.rExp)
+	require.Equal(t, "Volts", record.unit())
+	require.True(t, record.analog())
+
+	record, err = parseSDR(compactSDR(3, 0x04, "PS1 Status", 10, 1, 0x6f))
+	require.NoError(t, err)
+	require.Equal(t, "PS1 Status", record.name)
+	require.False(t, record.analog())
+
+	record, err = parseSDR([]byte{0x06, 0x00, 0x51, 0x12, 0x05, 0x20, 0x00, 0x00, 0x00, 0x00})
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	_, err = parseSDR(fullSDR(1, 0x02, "12V", 7, 1, readingTypeThreshold, 0x00, 4, 1, 0, 0, 0)[:20])
+	require.ErrorContains(t, err, "too short")
+}
@@ -0,0 +1,556 @@
+package ipmi_sensor
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by the IPMI specification
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net"
+	"time"
+)
+
+// Constants of the IPMI v2.0 specification, see
+// https://www.intel.com/content/dam/www/public/us/en/documents/specification-updates/ipmi-intelligent-platform-mgt-interface-spec-2nd-gen-v2-0-spec-update.pdf
+const (
+	rmcpVersion   = 0x06
+	rmcpNoAck     = 0xff
+	rmcpClassIPMI = 0x07
+
+	authTypeNone  = 0x00
+	authTypeRMCPP = 0x06
+
+	payloadIPMI            = 0x00
+	payloadOpenSessionReq  = 0x10
+	payloadOpenSessionResp = 0x11
+	payloadRAKP1           = 0x12
+	payloadRAKP2           = 0x13
+	payloadRAKP3           = 0x14
+	payloadRAKP4           = 0x15
+
+	payloadEncrypted     = 0x80
+	payloadAuthenticated = 0x40
+
+	netFnChassis = 0x00
+	netFnSensor  = 0x04
+	netFnApp     = 0x06
+	netFnStorage = 0x0a
+	netFnDCMI    = 0x2c
+
+	cmdGetChassisStatus         = 0x01
+	cmdDCMIGetPowerReading      = 0x02
+	cmdReserveSDRRepository     = 0x22
+	cmdGetSDR                   = 0x23
+	cmdGetSensorReading         = 0x2d
+	cmdGetChannelAuthCapability = 0x38
+	cmdSetSessionPrivilegeLevel = 0x3b
+	cmdCloseSession             = 0x3c
+
+	dcmiGroupExtension = 0xdc
+
+	completionCodeOK             = 0x00
+	completionReservationCancel  = 0xc5
+	completionCannotReturnLength = 0xca
+
+	bmcAddress     = 0x20
+	consoleAddress = 0x81
+
+	defaultRMCPPort = "623"
+)
+
+// Session privilege levels
+var privilegeLevels = map[string]byte{
+	"CALLBACK":      0x01,
+	"USER":          0x02,
+	"OPERATOR":      0x03,
+	"ADMINISTRATOR": 0x04,
+}
+
+// cipherSuite describes the authentication, integrity and confidentiality
+// algorithms of a RMCP+ session
+type cipherSuite struct {
+	auth            byte
+	integrity       byte
+	confidentiality byte
+	hash            func() hash.Hash
+	// length of the integrity check value of RAKP4 and of the session
+	// packets if integrity is enabled
+	icvLength int
+}
+
+var cipherSuites = map[int]cipherSuite{
+	1:  {auth: 0x01, hash: sha1.New, icvLength: 12},
+	2:  {auth: 0x01, integrity: 0x01, hash: sha1.New, icvLength: 12},
+	3:  {auth: 0x01, integrity: 0x01, confidentiality: 0x01, hash: sha1.New, icvLength: 12},
+	15: {auth: 0x03, hash: sha256.New, icvLength: 16},
+	16: {auth: 0x03, integrity: 0x04, hash: sha256.New, icvLength: 16},
+	17: {auth: 0x03, integrity: 0x04, confidentiality: 0x01, hash: sha256.New, icvLength: 16},
+}
+
+// completionError is returned for IPMI responses with a non-zero completion
+// code
+type completionError struct {
+	netFn, cmd, code byte
+}
+
+func (e *completionError) Error() string {
+	return fmt.Sprintf("command 0x%02x of netfn 0x%02x failed with completion code 0x%02x", e.cmd, e.netFn, e.code)
+}
+
+// rmcpClient is a minimal IPMI v2.0 client using RMCP+ sessions over UDP
+type rmcpClient struct {
+	address   string
+	username  string
+	password  string
+	bmcKey    []byte
+	privilege byte
+	suite     cipherSuite
+	timeout   time.Duration
+
+	conn      net.Conn
+	consoleID uint32
+	bmcID     uint32
+	sequence  uint32
+	rqSeq     byte
+	k1        []byte
+	k2        []byte
+	active    bool
+}
+
+// open connects to the BMC and establishes an authenticated session
+func (c *rmcpClient) open() error {
+	conn, err := net.DialTimeout("udp", c.address, c.timeout)
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", c.address, err)
+	}
+	c.conn = conn
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+
+	if err := c.checkAuthCapabilities(); err != nil {
+		return err
+	}
+	if err := c.openSession(); err != nil {
+		return err
+	}
+	if err := c.authenticate(); err != nil {
+		return err
+	}
+
+	if _, err := c.request(netFnApp, 0, cmdSetSessionPrivilegeLevel, []byte{c.privilege}); err != nil {
+		return fmt.Errorf("setting session privilege level failed: %w", err)
+	}
+	return nil
+}
+
+// close terminates the session and the connection
+func (c *rmcpClient) close() error {
+	if c.conn == nil {
+		return nil
+	}
+	var err error
+	if c.active {
+		id := binary.LittleEndian.AppendUint32(nil, c.bmcID)
+		_, err = c.request(netFnApp, 0, cmdCloseSession, id)
+		c.active = false
+	}
+	return errors.Join(err, c.conn.Close())
+}
+
+// request sends an IPMI command to the given LUN of the BMC and returns the
+// response data following the completion code
+func (c *rmcpClient) request(netFn, lun, cmd byte, data []byte) ([]byte, error) {
+	seq := c.rqSeq
+	c.rqSeq = (c.rqSeq + 1) & 0x3f
+
+	if err := c.send(payloadIPMI, encodeMessage(netFn, lun, seq, cmd, data)); err != nil {
+		return nil, err
+	}
+	for {
+		ptype, payload, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		if ptype != payloadIPMI {
+			continue
+		}
+		response, matched, err := decodeMessage(netFn, seq, cmd, payload)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			// Response to an earlier request
+			continue
+		}
+		return response, nil
+	}
+}
+
+// checkAuthCapabilities sends a session-less 'Get Channel Authentication
+// Capabilities' request in IPMI v1.5 format to check for IPMI v2.0 support
+func (c *rmcpClient) checkAuthCapabilities() error {
+	msg := encodeMessage(netFnApp, 0, 0, cmdGetChannelAuthCapability, []byte{0x8e, c.privilege})
+	packet := make([]byte, 0, 14+len(msg))
+	packet = append(packet, rmcpVersion, 0x00, rmcpNoAck, rmcpClassIPMI, authTypeNone)
+	packet = append(packet, 0, 0, 0, 0, 0, 0, 0, 0, byte(len(msg)))
+	packet = append(packet, msg...)
+	if _, err := c.conn.Write(packet); err != nil {
+		return fmt.Errorf("sending request failed: %w", err)
+	}
+
+	for {
+		_, payload, err := c.receive()
+		if err != nil {
+			return fmt.Errorf("getting channel authentication capabilities failed: %w", err)
+		}
+		response, matched, err := decodeMessage(netFnApp, 0, cmdGetChannelAuthCapability, payload)
+		if err != nil {
+			return fmt.Errorf("getting channel authentication capabilities failed: %w", err)
+		}
+		if !matched {
+			continue
+		}
+		if len(response) < 4 || response[1]&0x80 == 0 || response[3]&0x02 == 0 {
+			return errors.New("BMC does not support IPMI v2.0")
+		}
+		return nil
+	}
+}
+
+// openSession negotiates the algorithms of the session
+func (c *rmcpClient) openSession() error {
+	var id [4]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	c.consoleID = binary.LittleEndian.Uint32(id[:]) | 1
+
+	request := make([]byte, 32)
+	request[1] = c.privilege
+	binary.LittleEndian.PutUint32(request[4:8], c.consoleID)
+	request[8], request[11], request[12] = 0x00, 0x08, c.suite.auth
+	request[16], request[19], request[20] = 0x01, 0x08, c.suite.integrity
+	request[24], request[27], request[28] = 0x02, 0x08, c.suite.confidentiality
+
+	response, err := c.exchange(payloadOpenSessionReq, request, payloadOpenSessionResp)
+	if err != nil {
+		return fmt.Errorf("opening session failed: %w", err)
+	}
+	if len(response) < 2 {
+		return errors.New("opening session failed: response too short")
+	}
+	if response[1] != 0 {
+		return fmt.Errorf("opening session failed with status code 0x%02x", response[1])
+	}
+	if len(response) < 36 {
+		return errors.New("opening session failed: response too short")
+	}
+	if binary.LittleEndian.Uint32(response[4:8]) != c.consoleID {
+		return errors.New("opening session failed: session ID mismatch")
+	}
+	if response[16] != c.suite.auth || response[24] != c.suite.integrity || response[32] != c.suite.confidentiality {
+		return errors.New("opening session failed: cipher suite not accepted by BMC")
+	}
+	c.bmcID = binary.LittleEndian.Uint32(response[8:12])
+
+	return nil
+}
+
+// authenticate performs the RAKP handshake and derives the session keys
+func (c *rmcpClient) authenticate() error {
+	username := []byte(c.username)
+	if len(username) > 16 {
+		return errors.New("username exceeds 16 characters")
+	}
+	// The user key is the password padded to 20 bytes, HMAC pads keys with
+	// zeros anyway so the password can be used as is.
+	kuid := []byte(c.password)
+	if len(kuid) > 20 {
+		return errors.New("password exceeds 20 characters")
+	}
+	kg := c.bmcKey
+	if len(kg) == 0 {
+		kg = kuid
+	}
+
+	consoleRandom := make([]byte, 16)
+	if _, err := rand.Read(consoleRandom); err != nil {
+		return err
+	}
+	// Request the privilege level using a name-only lookup
+	role := 0x10 | c.privilege
+
+	request := make([]byte, 0, 28+len(username))
+	request = append(request, 0, 0, 0, 0)
+	request = binary.LittleEndian.AppendUint32(request, c.bmcID)
+	request = append(request, consoleRandom...)
+	request = append(request, role, 0, 0, byte(len(username)))
+	request = append(request, username...)
+
+	rakp2, err := c.exchange(payloadRAKP1, request, payloadRAKP2)
+	if err != nil {
+		return fmt.Errorf("RAKP1 failed: %w", err)
+	}
+	if len(rakp2) < 2 {
+		return errors.New("RAKP2 too short")
+	}
+	if rakp2[1] != 0 {
+		return fmt.Errorf("RAKP2 failed with status code 0x%02x, check username and privilege level", rakp2[1])
+	}
+	if len(rakp2) < 40 {
+		return errors.New("RAKP2 too short")
+	}
+	bmcRandom := rakp2[8:24]
+	guid := rakp2[24:40]
+
+	consoleID := binary.LittleEndian.AppendUint32(nil, c.consoleID)
+	bmcID := binary.LittleEndian.AppendUint32(nil, c.bmcID)
+	user := append([]byte{role, byte(len(username))}, username...)
+
+	expected := c.hmac(kuid, consoleID, bmcID, consoleRandom, bmcRandom, guid, user)
+	if !hmac.Equal(expected, rakp2[40:]) {
+		return errors.New("RAKP2 authentication failed, check username and password")
+	}
+
+	request = make([]byte, 0, 8+len(expected))
+	request = append(request, 0, 0, 0, 0)
+	request = append(request, bmcID...)
+	request = append(request, c.hmac(kuid, bmcRandom, consoleID, user)...)
+
+	rakp4, err := c.exchange(payloadRAKP3, request, payloadRAKP4)
+	if err != nil {
+		return fmt.Errorf("RAKP3 failed: %w", err)
+	}
+	if len(rakp4) < 2 {
+		return errors.New("RAKP4 too short")
+	}
+	if rakp4[1] != 0 {
+		return fmt.Errorf("RAKP4 failed with status code 0x%02x", rakp4[1])
+	}
+
+	sik := c.hmac(kg, consoleRandom, bmcRandom, user)
+	icv := c.hmac(sik, consoleRandom, bmcID, guid)[:c.suite.icvLength]
+	if len(rakp4) < 8+len(icv) || !hmac.Equal(icv, rakp4[8:8+len(icv)]) {
+		return errors.New("RAKP4 integrity check failed, check the BMC key")
+	}
+
+	size := c.suite.hash().Size()
+	c.k1 = c.hmac(sik, bytes.Repeat([]byte{0x01}, size))
+	c.k2 = c.hmac(sik, bytes.Repeat([]byte{0x02}, size))
+	c.sequence = 1
+	c.active = true
+
+	return nil
+}
+
+// exchange sends a session setup payload and waits for the response of the
+// given payload type
+func (c *rmcpClient) exchange(ptype byte, payload []byte, expected byte) ([]byte, error) {
+	if err := c.send(ptype, payload); err != nil {
+		return nil, err
+	}
+	for {
+		rtype, response, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		if rtype == expected {
+			return response, nil
+		}
+	}
+}
+
+// send encodes the payload into a RMCP+ packet, encrypting and signing it if
+// the session is active and the cipher suite requires it
+func (c *rmcpClient) send(ptype byte, payload []byte) error {
+	sessionID, sequence := uint32(0), uint32(0)
+	if c.active {
+		sessionID, sequence = c.bmcID, c.sequence
+		c.sequence++
+		if c.suite.confidentiality != 0 {
+			var err error
+			if payload, err = encrypt(c.k2[:16], payload); err != nil {
+				return err
+			}
+			ptype |= payloadEncrypted
+		}
+		if c.suite.integrity != 0 {
+			ptype |= payloadAuthenticated
+		}
+	}
+
+	packet := make([]byte, 0, 16+len(payload)+4+c.suite.icvLength)
+	packet = append(packet, rmcpVersion, 0x00, rmcpNoAck, rmcpClassIPMI, authTypeRMCPP, ptype)
+	packet = binary.LittleEndian.AppendUint32(packet, sessionID)
+	packet = binary.LittleEndian.AppendUint32(packet, sequence)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(payload)))
+	packet = append(packet, payload...)
+	if ptype&payloadAuthenticated != 0 {
+		// Pad the session part including pad length and next header byte
+		// to a multiple of four bytes
+		padding := (4 - (len(packet)-4+2)%4) % 4
+		packet = append(packet, bytes.Repeat([]byte{0xff}, padding)...)
+		packet = append(packet, byte(padding), rmcpClassIPMI)
+		packet = append(packet, c.hmac(c.k1, packet[4:])[:c.suite.icvLength]...)
+	}
+
+	if _, err := c.conn.Write(packet); err != nil {
+		return fmt.Errorf("sending request failed: %w", err)
+	}
+	return nil
+}
+
+// receive reads the next packet from the BMC and returns the payload type
+// and the decrypted payload
+func (c *rmcpClient) receive() (byte, []byte, error) {
+	buf := make([]byte, 1024)
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			return 0, nil, fmt.Errorf("reading response failed: %w", err)
+		}
+		packet := buf[:n]
+		if len(packet) < 5 || packet[0] != rmcpVersion || packet[3] != rmcpClassIPMI {
+			continue
+		}
+
+		// Session-less IPMI v1.5 packet
+		if packet[4] == authTypeNone {
+			if len(packet) < 14 || len(packet) < 14+int(packet[13]) {
+				return 0, nil, errors.New("invalid IPMI v1.5 packet")
+			}
+			return payloadIPMI, packet[14 : 14+int(packet[13])], nil
+		}
+		if packet[4] != authTypeRMCPP || len(packet) < 16 {
+			continue
+		}
+
+		ptype := packet[5]
+		length := int(binary.LittleEndian.Uint16(packet[14:16]))
+		if len(packet) < 16+length {
+			return 0, nil, errors.New("invalid RMCP+ packet length")
+		}
+		payload := packet[16 : 16+length]
+
+		if !c.active {
+			return ptype & 0x3f, payload, nil
+		}
+		if binary.LittleEndian.Uint32(packet[6:10]) != c.consoleID {
+			continue
+		}
+		if c.suite.integrity != 0 {
+			if ptype&payloadAuthenticated == 0 || len(packet) < 16+length+2+c.suite.icvLength {
+				return 0, nil, errors.New("unauthenticated packet in authenticated session")
+			}
+			end := len(packet) - c.suite.icvLength
+			if !hmac.Equal(c.hmac(c.k1, packet[4:end])[:c.suite.icvLength], packet[end:]) {
+				return 0, nil, errors.New("packet integrity check failed")
+			}
+		}
+		if c.suite.confidentiality != 0 {
+			if ptype&payloadEncrypted == 0 {
+				return 0, nil, errors.New("unencrypted packet in encrypted session")
+			}
+			if payload, err = decrypt(c.k2[:16], payload); err != nil {
+				return 0, nil, err
+			}
+		}
+		return ptype & 0x3f, payload, nil
+	}
+}
+
+// hmac computes the keyed hash of the concatenated data using the hash of
+// the cipher suite
+func (c *rmcpClient) hmac(key []byte, data ...[]byte) []byte {
+	h := hmac.New(c.suite.hash, key)
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// encrypt encrypts the payload using AES-CBC-128 with a random IV prepended
+// to the result
+func encrypt(key, payload []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padding := (aes.BlockSize - (len(payload)+1)%aes.BlockSize) % aes.BlockSize
+	plain := make([]byte, 0, len(payload)+padding+1)
+	plain = append(plain, payload...)
+	for i := 1; i <= padding; i++ {
+		plain = append(plain, byte(i))
+	}
+	plain = append(plain, byte(padding))
+
+	result := make([]byte, aes.BlockSize+len(plain))
+	if _, err := rand.Read(result[:aes.BlockSize]); err != nil {
+		return nil, err
+	}
+	cipher.NewCBCEncrypter(block, result[:aes.BlockSize]).CryptBlocks(result[aes.BlockSize:], plain)
+	return result, nil
+}
+
+// decrypt decrypts an AES-CBC-128 payload with the IV prepended
+func decrypt(key, payload []byte) ([]byte, error) {
+	if len(payload) < 2*aes.BlockSize || len(payload)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid encrypted payload length")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(payload)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, payload[:aes.BlockSize]).CryptBlocks(plain, payload[aes.BlockSize:])
+	padding := int(plain[len(plain)-1])
+	if padding >= len(plain) {
+		return nil, errors.New("invalid encrypted payload padding")
+	}
+	return plain[:len(plain)-1-padding], nil
+}
+
+// encodeMessage encodes an IPMI request message from the remote console to
+// the BMC
+func encodeMessage(netFn, lun, seq, cmd byte, data []byte) []byte {
+	msg := make([]byte, 0, 7+len(data))
+	msg = append(msg, bmcAddress, netFn<<2|lun&0x03)
+	msg = append(msg, checksum(msg))
+	msg = append(msg, consoleAddress, seq<<2, cmd)
+	msg = append(msg, data...)
+	return append(msg, checksum(msg[3:]))
+}
+
+// decodeMessage decodes an IPMI response message and returns the data after
+// the completion code. The flag is false for responses not matching the
+// request, e.g. late responses to earlier requests.
+func decodeMessage(netFn, seq, cmd byte, msg []byte) ([]byte, bool, error) {
+	if len(msg) < 8 {
+		return nil, false, errors.New("response message too short")
+	}
+	if checksum(msg[:2]) != msg[2] || checksum(msg[3:len(msg)-1]) != msg[len(msg)-1] {
+		return nil, false, errors.New("response message checksum mismatch")
+	}
+	if msg[1]>>2 != netFn+1 || msg[4]>>2 != seq || msg[5] != cmd {
+		return nil, false, nil
+	}
+	if msg[6] != completionCodeOK {
+		return nil, true, &completionError{netFn: netFn, cmd: cmd, code: msg[6]}
+	}
+	return msg[7 : len(msg)-1], true, nil
+}
+
+func checksum(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return -sum
+}
@@ -0,0 +1,194 @@
+package ipmi_sensor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	sdrTypeFull    = 0x01
+	sdrTypeCompact = 0x02
+
+	readingTypeThreshold = 0x01
+)
+
+// Sensor unit type codes as defined in table 43-15 of the IPMI specification
+var sensorUnits = []string{
+	"unspecified", "degrees C", "degrees F", "degrees K", "Volts", "Amps", "Watts", "Joules", "Coulombs", "VA",
+	"Nits", "lumen", "lux", "Candela", "kPa", "PSI", "Newton", "CFM", "RPM", "Hz",
+	"microsecond", "millisecond", "second", "minute", "hour", "day", "week", "mil", "inches", "feet",
+	"cu in", "cu feet", "mm", "cm", "m", "cu cm", "cu m", "liters", "fluid ounce", "radians",
+	"steradians", "revolutions", "cycles", "gravities", "ounce", "pound", "ft-lb", "oz-in", "gauss", "gilberts",
+	"henry", "millihenry", "farad", "microfarad", "ohms", "siemens", "mole", "becquerel", "PPM", "reserved",
+	"Decibels", "DbA", "DbC", "gray", "sievert", "color temp deg K", "bit", "kilobit", "megabit", "gigabit",
+	"byte", "kilobyte", "megabyte", "gigabyte", "word", "dword", "qword", "line", "hit", "miss",
+	"retry", "reset", "overflow", "underrun", "collision", "packets", "messages", "characters", "error", "correctable error",
+	"uncorrectable error", "fatal error", "grams",
+}
+
+// sdrRecord holds the information of a full or compact sensor data record
+// required to read and convert the sensor values
+type sdrRecord struct {
+	recordType     byte
+	name           string
+	owner          byte
+	lun            byte
+	number         byte
+	entityID       byte
+	entityInstance byte
+	readingType    byte
+	units1         byte
+	baseUnit       byte
+	modifierUnit   byte
+	linearization  byte
+	m              int
+	b              int
+	bExp           int
+	rExp           int
+}
+
+// parseSDR decodes a sensor data record including the record header. Records
+// other than full and compact sensor records are ignored and return nil.
+func parseSDR(data []byte) (*sdrRecord, error) {
+	if len(data) < 5 {
+		return nil, errors.New("record too short")
+	}
+
+	var nameOffset int
+	switch data[3] {
+	case sdrTypeFull:
+		nameOffset = 47
+	case sdrTypeCompact:
+		nameOffset = 31
+	default:
+		return nil, nil
+	}
+	if len(data) < nameOffset+1 {
+		return nil, fmt.Errorf("sensor record of type 0x%02x too short", data[3])
+	}
+
+	r := &sdrRecord{
+		recordType:     data[3],
+		owner:          data[5],
+		lun:            data[6] & 0x03,
+		number:         data[7],
+		entityID:       data[8],
+		entityInstance: data[9] & 0x7f,
+		readingType:    data[13],
+		units1:         data[20],
+		baseUnit:       data[21],
+		modifierUnit:   data[22],
+	}
+
+	if r.recordType == sdrTypeFull {
+		r.linearization = data[23] & 0x7f
+		r.m = signExtend(int(data[24])|int(data[25]&0xc0)<<2, 10)
+		r.b = signExtend(int(data[26])|int(data[27]&0xc0)<<2, 10)
+		r.rExp = signExtend(int(data[29]>>4), 4)
+		r.bExp = signExtend(int(data[29]&0x0f), 4)
+	}
+
+	length := int(data[nameOffset] & 0x1f)
+	end := min(nameOffset+1+length, len(data))
+	r.name = strings.TrimRight(string(data[nameOffset+1:end]), "\x00 ")
+
+	return r, nil
+}
+
+// analog returns true for threshold-based sensors providing a numeric reading
+func (r *sdrRecord) analog() bool {
+	return r.recordType == sdrTypeFull && r.readingType == readingTypeThreshold && r.units1>>6 != 0x03
+}
+
+// convert converts a raw sensor reading using the conversion formula
+// y = L[(M*x + B*10^K1) * 10^K2] of the record
+func (r *sdrRecord) convert(raw byte) float64 {
+	var x float64
+	switch r.units1 >> 6 {
+	case 0x01:
+		// One's complement
+		v := int(int8(raw))
+		if v < 0 {
+			v++
+		}
+		x = float64(v)
+	case 0x02:
+		x = float64(int8(raw))
+	default:
+		x = float64(raw)
+	}
+
+	y := (float64(r.m)*x + float64(r.b)*math.Pow10(r.bExp)) * math.Pow10(r.rExp)
+	switch r.linearization {
+	case 0x01:
+		y = math.Log(y)
+	case 0x02:
+		y = math.Log10(y)
+	case 0x03:
+		y = math.Log2(y)
+	case 0x04:
+		y = math.Exp(y)
+	case 0x05:
+		y = math.Pow(10, y)
+	case 0x06:
+		y = math.Exp2(y)
+	case 0x07:
+		y = 1 / y
+	case 0x08:
+		y *= y
+	case 0x09:
+		y = y * y * y
+	case 0x0a:
+		y = math.Sqrt(y)
+	case 0x0b:
+		y = math.Cbrt(y)
+	}
+
+	// Round to the precision reported by ipmitool
+	return math.Round(y*1000) / 1000
+}
+
+// unit returns the unit of the sensor in the notation of ipmitool
+func (r *sdrRecord) unit() string {
+	if r.units1&0x01 != 0 {
+		return "percent"
+	}
+	unit := unitName(r.baseUnit)
+	switch (r.units1 >> 1) & 0x03 {
+	case 0x01:
+		unit += "/" + unitName(r.modifierUnit)
+	case 0x02:
+		unit += "*" + unitName(r.modifierUnit)
+	}
+	return unit
+}
+
+func unitName(code byte) string {
+	if int(code) < len(sensorUnits) {
+		return sensorUnits[code]
+	}
+	return "unknown"
+}
+
+// thresholdStatus returns the status code of a threshold sensor in the
+// notation of ipmitool
+func thresholdStatus(state byte) string {
+	switch {
+	case state&0x24 != 0:
+		return "nr"
+	case state&0x12 != 0:
+		return "cr"
+	case state&0x09 != 0:
+		return "nc"
+	}
+	return "ok"
+}
+
+func signExtend(v, bits int) int {
+	if v&(1<<(bits-1)) != 0 {
+		return v - 1<<bits
+	}
+	return v
+}
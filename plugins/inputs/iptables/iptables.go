@@ -21,18 +21,24 @@ var sampleConfig string
 var (
 	errParse       = errors.New("cannot parse iptables list information")
 	chainNameRe    = regexp.MustCompile(`^Chain\s+(\S+)`)
+	chainPolicyRe  = regexp.MustCompile(`\(policy\s+(\w+)\s+(\d+)\s+packets,\s+(\d+)\s+bytes\)`)
 	fieldsHeaderRe = regexp.MustCompile(`^\s*pkts\s+bytes\s+target`)
 	valuesRe       = regexp.MustCompile(`^\s*(\d+)\s+(\d+)\s+(\w+).*?/\*\s*(.+?)\s*\*/\s*`)
 )
 
-const measurement = "iptables"
+const (
+	measurement      = "iptables"
+	chainMeasurement = "iptables_chain"
+)
 
 type Iptables struct {
-	UseSudo bool     `toml:"use_sudo"`
-	UseLock bool     `toml:"use_lock"`
-	Binary  string   `toml:"binary"`
-	Table   string   `toml:"table"`
-	Chains  []string `toml:"chains"`
+	UseSudo             bool     `toml:"use_sudo"`
+	UseLock             bool     `toml:"use_lock"`
+	Binary              string   `toml:"binary"`
+	Table               string   `toml:"table"`
+	Chains              []string `toml:"chains"`
+	ResetCounters       bool     `toml:"reset_counters"`
+	GatherChainCounters bool     `toml:"gather_chain_counters"`
 
 	lister chainLister
 }
@@ -75,6 +81,13 @@ func (ipt *Iptables) chainList(table, chain string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	name, args := ipt.command(iptablePath, table, chain)
+	c := exec.Command(name, args...)
+	out, err := c.Output()
+	return string(out), err
+}
+
+func (ipt *Iptables) command(iptablePath, table, chain string) (string, []string) {
 	var args []string
 	name := iptablePath
 	if ipt.UseSudo {
@@ -85,9 +98,12 @@ func (ipt *Iptables) chainList(table, chain string) (string, error) {
 		args = append(args, "-w", "5")
 	}
 	args = append(args, "-nvL", chain, "-t", table, "-x")
-	c := exec.Command(name, args...)
-	out, err := c.Output()
-	return string(out), err
+	if ipt.ResetCounters {
+		// List and zero the counters in one call so no packets are lost
+		// between reading and resetting
+		args = append(args, "-Z")
+	}
+	return name, args
 }
 
 func (ipt *Iptables) parseAndGather(data string, acc telegraf.Accumulator) error {
@@ -102,6 +118,9 @@ func (ipt *Iptables) parseAndGather(data string, acc telegraf.Accumulator) error
 	if !fieldsHeaderRe.MatchString(lines[1]) {
 		return errParse
 	}
+	if ipt.GatherChainCounters {
+		ipt.gatherChainPolicy(lines[0], mchain[1], acc)
+	}
 	for _, line := range lines[2:] {
 		matches := valuesRe.FindStringSubmatch(line)
 		if len(matches) != 5 {
@@ -130,6 +149,32 @@ func (ipt *Iptables) parseAndGather(data string, acc telegraf.Accumulator) error
 	return nil
 }
 
+// gatherChainPolicy adds the policy and counters of built-in chains given in
+// the chain header, user-defined chains without a policy are skipped
+func (ipt *Iptables) gatherChainPolicy(header, chain string, acc telegraf.Accumulator) {
+	matches := chainPolicyRe.FindStringSubmatch(header)
+	if len(matches) != 4 {
+		return
+	}
+
+	pkts, err := strconv.ParseUint(matches[2], 10, 64)
+	if err != nil {
+		return
+	}
+	bytes, err := strconv.ParseUint(matches[3], 10, 64)
+	if err != nil {
+		return
+	}
+
+	tags := map[string]string{"table": ipt.Table, "chain": chain}
+	fields := map[string]interface{}{
+		"policy": matches[1],
+		"pkts":   pkts,
+		"bytes":  bytes,
+	}
+	acc.AddFields(chainMeasurement, fields, tags)
+}
+
 func init() {
 	inputs.Add("iptables", func() telegraf.Input {
 		ipt := &Iptables{}
@@ -6,7 +6,12 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -249,3 +254,89 @@ func TestIptables_Gather_listerError(t *testing.T) {
 		t.Errorf("Expected error %#v got\n%#v\n", errFoo, err)
 	}
 }
+
+func TestIptables_command(t *testing.T) {
+	tests := []struct {
+		name         string
+		ipt          *Iptables
+		expectedName string
+		expectedArgs []string
+	}{
+		{
+			name:         "default",
+			ipt:          &Iptables{},
+			expectedName: "/sbin/iptables",
+			expectedArgs: []string{"-nvL", "INPUT", "-t", "filter", "-x"},
+		},
+		{
+			name:         "sudo and lock",
+			ipt:          &Iptables{UseSudo: true, UseLock: true},
+			expectedName: "sudo",
+			expectedArgs: []string{"/sbin/iptables", "-w", "5", "-nvL", "INPUT", "-t", "filter", "-x"},
+		},
+		{
+			name:         "reset counters",
+			ipt:          &Iptables{ResetCounters: true},
+			expectedName: "/sbin/iptables",
+			expectedArgs: []string{"-nvL", "INPUT", "-t", "filter", "-x", "-Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, args := tt.ipt.command("/sbin/iptables", "filter", "INPUT")
+			if name != tt.expectedName {
+				t.Errorf("Expected command %q got %q", tt.expectedName, name)
+			}
+			if !reflect.DeepEqual(args, tt.expectedArgs) {
+				t.Errorf("Expected arguments %v got %v", tt.expectedArgs, args)
+			}
+		})
+	}
+}
+
+func TestIptables_Gather_chainCounters(t *testing.T) {
+	values := map[string]string{
+		"INPUT": `Chain INPUT (policy DROP 58 packets, 5096 bytes)
+pkts      bytes target     prot opt in     out     source               destination
+  57       4520 RETURN     tcp  --  *      *       0.0.0.0/0            0.0.0.0/0   /* foobar */
+`,
+		"custom": `Chain custom (1 references)
+pkts      bytes target     prot opt in     out     source               destination
+  12        345 ACCEPT     tcp  --  *      *       0.0.0.0/0            0.0.0.0/0   /* custom */
+`,
+	}
+	ipt := &Iptables{
+		Table:               "filter",
+		Chains:              []string{"INPUT", "custom"},
+		GatherChainCounters: true,
+		lister: func(_, chain string) (string, error) {
+			return values[chain], nil
+		},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"iptables_chain",
+			map[string]string{"table": "filter", "chain": "INPUT"},
+			map[string]interface{}{"policy": "DROP", "pkts": uint64(58), "bytes": uint64(5096)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"iptables",
+			map[string]string{"table": "filter", "chain": "INPUT", "target": "RETURN", "ruleid": "foobar"},
+			map[string]interface{}{"pkts": uint64(57), "bytes": uint64(4520)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"iptables",
+			map[string]string{"table": "filter", "chain": "custom", "target": "ACCEPT", "ruleid": "custom"},
+			map[string]interface{}{"pkts": uint64(12), "bytes": uint64(345)},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(ipt.Gather))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
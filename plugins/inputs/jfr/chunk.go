@@ -0,0 +1,774 @@
+package jfr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+// Parser for the chunks of Java Flight Recorder files, see
+// https://github.com/openjdk/jdk/tree/master/src/jdk.jfr/share/classes/jdk/jfr/internal/consumer
+const (
+	chunkMagic      = "FLR\x00"
+	chunkHeaderSize = 68
+
+	// The JVM sets the file state to this value while updating the header
+	// of the chunk, a value of zero denotes a finished chunk
+	fileStateUpdating = 255
+
+	flagCompressedInts = 1 << 0
+
+	eventTypeMetadata     = 0
+	eventTypeConstantPool = 1
+
+	// Maximum nesting of objects to protect against corrupted data
+	maxDepth = 32
+)
+
+// String encodings
+const (
+	stringNull = iota
+	stringEmpty
+	stringConstantPool
+	stringUTF8
+	stringCharArray
+	stringLatin1
+)
+
+type chunkHeader struct {
+	major              uint16
+	minor              uint16
+	size               int64
+	constantPoolOffset int64
+	metadataOffset     int64
+	startNanos         int64
+	durationNanos      int64
+	startTicks         int64
+	ticksPerSecond     int64
+	fileState          byte
+	flags              byte
+}
+
+func parseChunkHeader(buf []byte) (*chunkHeader, error) {
+	if len(buf) < chunkHeaderSize {
+		return nil, errors.New("chunk header too short")
+	}
+	if string(buf[:4]) != chunkMagic {
+		return nil, errors.New("invalid chunk magic")
+	}
+
+	h := &chunkHeader{
+		major:              binary.BigEndian.Uint16(buf[4:]),
+		minor:              binary.BigEndian.Uint16(buf[6:]),
+		size:               int64(binary.BigEndian.Uint64(buf[8:])),
+		constantPoolOffset: int64(binary.BigEndian.Uint64(buf[16:])),
+		metadataOffset:     int64(binary.BigEndian.Uint64(buf[24:])),
+		startNanos:         int64(binary.BigEndian.Uint64(buf[32:])),
+		durationNanos:      int64(binary.BigEndian.Uint64(buf[40:])),
+		startTicks:         int64(binary.BigEndian.Uint64(buf[48:])),
+		ticksPerSecond:     int64(binary.BigEndian.Uint64(buf[56:])),
+		fileState:          buf[64],
+		flags:              buf[67],
+	}
+	if h.major != 2 {
+		return nil, fmt.Errorf("unsupported version %d.%d", h.major, h.minor)
+	}
+	if h.ticksPerSecond <= 0 {
+		return nil, fmt.Errorf("invalid ticks per second %d", h.ticksPerSecond)
+	}
+	return h, nil
+}
+
+func (h *chunkHeader) finished() bool {
+	return h.fileState == 0
+}
+
+// end returns the time up to which the chunk contains events
+func (h *chunkHeader) end() time.Time {
+	return time.Unix(0, h.startNanos+h.durationNanos)
+}
+
+func (h *chunkHeader) toDuration(ticks int64) time.Duration {
+	return time.Duration(float64(ticks) * 1e9 / float64(h.ticksPerSecond))
+}
+
+func (h *chunkHeader) toTime(ticks int64) time.Time {
+	return time.Unix(0, h.startNanos).Add(h.toDuration(ticks - h.startTicks))
+}
+
+// class describes an event type or a type of the fields of events
+type class struct {
+	id     int64
+	name   string
+	fields []*classField
+}
+
+type classField struct {
+	name         string
+	classID      int64
+	class        *class
+	constantPool bool
+	array        bool
+	// Unit of timespans or timestamps, e.g. "TICKS" or "NANOSECONDS"
+	timeUnit string
+}
+
+// constantRef references a value of the constant pool of the given class
+type constantRef struct {
+	classID int64
+	key     int64
+}
+
+// chunk holds the data of a chunk with the type definitions of the metadata
+// and the values of the constant pools
+type chunk struct {
+	header  *chunkHeader
+	data    []byte
+	classes map[int64]*class
+	pools   map[int64]map[int64]interface{}
+}
+
+func parseChunk(data []byte) (*chunk, error) {
+	header, err := parseChunkHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if header.size > int64(len(data)) {
+		return nil, fmt.Errorf("chunk size %d exceeds data size %d", header.size, len(data))
+	}
+
+	c := &chunk{
+		header: header,
+		data:   data[:header.size],
+		pools:  make(map[int64]map[int64]interface{}),
+	}
+	if err := c.parseMetadata(); err != nil {
+		return nil, fmt.Errorf("parsing metadata failed: %w", err)
+	}
+	if err := c.parseConstantPools(); err != nil {
+		return nil, fmt.Errorf("parsing constant pools failed: %w", err)
+	}
+	return c, nil
+}
+
+func (c *chunk) decoder(offset int64) (*decoder, error) {
+	if offset < chunkHeaderSize || offset >= int64(len(c.data)) {
+		return nil, fmt.Errorf("invalid offset %d", offset)
+	}
+	return &decoder{
+		data:       c.data,
+		pos:        int(offset),
+		compressed: c.header.flags&flagCompressedInts != 0,
+	}, nil
+}
+
+// element is a node of the metadata description
+type element struct {
+	name       string
+	attributes map[string]string
+	children   []*element
+}
+
+func (c *chunk) parseMetadata() error {
+	d, err := c.decoder(c.header.metadataOffset)
+	if err != nil {
+		return err
+	}
+
+	// Event size and type followed by the start time, duration and the ID
+	// of the metadata
+	if _, err := d.int(); err != nil {
+		return err
+	}
+	eventType, err := d.long()
+	if err != nil {
+		return err
+	}
+	if eventType != eventTypeMetadata {
+		return fmt.Errorf("unexpected event type %d", eventType)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := d.long(); err != nil {
+			return err
+		}
+	}
+
+	count, err := d.int()
+	if err != nil {
+		return err
+	}
+	if count < 0 || int(count) > len(d.data)-d.pos {
+		return fmt.Errorf("invalid string count %d", count)
+	}
+	strings := make([]string, 0, count)
+	for i := int32(0); i < count; i++ {
+		v, err := d.string()
+		if err != nil {
+			return err
+		}
+		s, ok := v.(string)
+		if !ok && v != nil {
+			return errors.New("unexpected string reference in metadata")
+		}
+		strings = append(strings, s)
+	}
+
+	root, err := d.element(strings, 0)
+	if err != nil {
+		return err
+	}
+
+	// Collect the classes and annotations of the fields
+	c.classes = make(map[int64]*class)
+	type annotatedField struct {
+		field       *classField
+		annotations []*element
+	}
+	var annotated []annotatedField
+	for _, metadata := range root.children {
+		if metadata.name != "metadata" {
+			continue
+		}
+		for _, e := range metadata.children {
+			if e.name != "class" {
+				continue
+			}
+			id, err := strconv.ParseInt(e.attributes["id"], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid class ID %q", e.attributes["id"])
+			}
+			cls := &class{id: id, name: e.attributes["name"]}
+			for _, f := range e.children {
+				if f.name != "field" {
+					continue
+				}
+				classID, err := strconv.ParseInt(f.attributes["class"], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid class ID %q of field %s.%s", f.attributes["class"], cls.name, f.attributes["name"])
+				}
+				field := &classField{
+					name:         f.attributes["name"],
+					classID:      classID,
+					constantPool: f.attributes["constantPool"] == "true",
+					array:        f.attributes["dimension"] == "1",
+				}
+				cls.fields = append(cls.fields, field)
+				annotated = append(annotated, annotatedField{field: field, annotations: f.children})
+			}
+			c.classes[id] = cls
+		}
+	}
+
+	// Resolve the field types and time units
+	for _, cls := range c.classes {
+		for _, f := range cls.fields {
+			t, found := c.classes[f.classID]
+			if !found {
+				return fmt.Errorf("unknown class %d of field %s.%s", f.classID, cls.name, f.name)
+			}
+			f.class = t
+		}
+	}
+	for _, a := range annotated {
+		for _, e := range a.annotations {
+			if e.name != "annotation" {
+				continue
+			}
+			id, err := strconv.ParseInt(e.attributes["class"], 10, 64)
+			if err != nil {
+				continue
+			}
+			if t, found := c.classes[id]; found && (t.name == "jdk.jfr.Timespan" || t.name == "jdk.jfr.Timestamp") {
+				a.field.timeUnit = e.attributes["value"]
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *chunk) parseConstantPools() error {
+	// The constant pools are linked backwards from the last one
+	offset := c.header.constantPoolOffset
+	for offset != 0 {
+		d, err := c.decoder(offset)
+		if err != nil {
+			return err
+		}
+
+		// Event size, type, start time and duration
+		if _, err := d.int(); err != nil {
+			return err
+		}
+		eventType, err := d.long()
+		if err != nil {
+			return err
+		}
+		if eventType != eventTypeConstantPool {
+			return fmt.Errorf("unexpected event type %d at offset %d", eventType, offset)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := d.long(); err != nil {
+				return err
+			}
+		}
+		delta, err := d.long()
+		if err != nil {
+			return err
+		}
+		// Checkpoint type
+		if _, err := d.byte(); err != nil {
+			return err
+		}
+
+		count, err := d.int()
+		if err != nil {
+			return err
+		}
+		for i := int32(0); i < count; i++ {
+			classID, err := d.long()
+			if err != nil {
+				return err
+			}
+			cls, found := c.classes[classID]
+			if !found {
+				return fmt.Errorf("unknown constant pool class %d", classID)
+			}
+			entries, err := d.int()
+			if err != nil {
+				return err
+			}
+			pool, found := c.pools[classID]
+			if !found {
+				pool = make(map[int64]interface{})
+				c.pools[classID] = pool
+			}
+			for j := int32(0); j < entries; j++ {
+				key, err := d.long()
+				if err != nil {
+					return err
+				}
+				v, err := d.value(cls, 0)
+				if err != nil {
+					return fmt.Errorf("decoding constant of %s failed: %w", cls.name, err)
+				}
+				// Later pools take precedence as we walk backwards
+				if _, exists := pool[key]; !exists {
+					pool[key] = v
+				}
+			}
+		}
+
+		if delta == 0 {
+			break
+		}
+		if delta > 0 {
+			return fmt.Errorf("invalid constant pool delta %d", delta)
+		}
+		offset += delta
+	}
+	return nil
+}
+
+// event is a decoded event with the values of its fields
+type event struct {
+	class  *class
+	values map[string]interface{}
+}
+
+// events decodes the events starting at the given offset up to the end of the
+// chunk and skips metadata and constant pools
+func (c *chunk) events(offset int64, handler func(e *event)) error {
+	for offset < int64(len(c.data)) {
+		d, err := c.decoder(offset)
+		if err != nil {
+			return err
+		}
+		size, err := d.int()
+		if err != nil {
+			return err
+		}
+		if size <= 0 || offset+int64(size) > int64(len(c.data)) {
+			return fmt.Errorf("invalid event size %d at offset %d", size, offset)
+		}
+		eventType, err := d.long()
+		if err != nil {
+			return err
+		}
+
+		if eventType != eventTypeMetadata && eventType != eventTypeConstantPool {
+			cls, found := c.classes[eventType]
+			if !found {
+				return fmt.Errorf("unknown event type %d at offset %d", eventType, offset)
+			}
+			d.data = c.data[:offset+int64(size)]
+			values, err := d.fields(cls, 0)
+			if err != nil {
+				return fmt.Errorf("decoding event %s at offset %d failed: %w", cls.name, offset, err)
+			}
+			handler(&event{class: cls, values: values})
+		}
+		offset += int64(size)
+	}
+	return nil
+}
+
+// resolve looks up references into the constant pools
+func (c *chunk) resolve(v interface{}) interface{} {
+	for i := 0; i < maxDepth; i++ {
+		ref, ok := v.(constantRef)
+		if !ok {
+			return v
+		}
+		v = c.pools[ref.classID][ref.key]
+	}
+	return nil
+}
+
+// resolveString returns the string of the value resolving references and
+// unwrapping types with a single field such as the GC names
+func (c *chunk) resolveString(v interface{}) string {
+	for i := 0; i < maxDepth; i++ {
+		switch value := c.resolve(v).(type) {
+		case string:
+			return value
+		case map[string]interface{}:
+			if len(value) != 1 {
+				return ""
+			}
+			for _, inner := range value {
+				v = inner
+			}
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// timespan converts the value of a timespan field to a duration
+func (c *chunk) timespan(e *event, name string) (time.Duration, bool) {
+	for _, f := range e.class.fields {
+		if f.name != name {
+			continue
+		}
+		v, ok := e.values[name].(int64)
+		if !ok {
+			return 0, false
+		}
+		switch f.timeUnit {
+		case "TICKS":
+			return c.header.toDuration(v), true
+		case "NANOSECONDS", "":
+			return time.Duration(v), true
+		case "MICROSECONDS":
+			return time.Duration(v) * time.Microsecond, true
+		case "MILLISECONDS":
+			return time.Duration(v) * time.Millisecond, true
+		case "SECONDS":
+			return time.Duration(v) * time.Second, true
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+// timestamp returns the start time of the event
+func (c *chunk) timestamp(e *event) time.Time {
+	v, ok := e.values["startTime"].(int64)
+	if !ok {
+		return c.header.end()
+	}
+	for _, f := range e.class.fields {
+		if f.name == "startTime" && f.timeUnit != "TICKS" {
+			return time.Unix(0, v)
+		}
+	}
+	return c.header.toTime(v)
+}
+
+// decoder reads the values of a chunk in big-endian or compressed encoding
+type decoder struct {
+	data       []byte
+	pos        int
+	compressed bool
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, io.ErrUnexpectedEOF
+	}
+	buf := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return buf, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	buf, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+// varint reads a compressed integer stored in seven bit groups with the
+// ninth byte holding the remaining eight bits
+func (d *decoder) varint() (uint64, error) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		b, err := d.byte()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint64(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+	b, err := d.byte()
+	if err != nil {
+		return 0, err
+	}
+	return v | uint64(b)<<56, nil
+}
+
+func (d *decoder) short() (int16, error) {
+	if d.compressed {
+		v, err := d.varint()
+		return int16(v), err
+	}
+	buf, err := d.read(2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.BigEndian.Uint16(buf)), nil
+}
+
+func (d *decoder) int() (int32, error) {
+	if d.compressed {
+		v, err := d.varint()
+		return int32(v), err
+	}
+	buf, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(buf)), nil
+}
+
+func (d *decoder) long() (int64, error) {
+	if d.compressed {
+		v, err := d.varint()
+		return int64(v), err
+	}
+	buf, err := d.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(buf)), nil
+}
+
+// string reads a string returning either the string, nil or a reference into
+// the string constant pool
+func (d *decoder) string() (interface{}, error) {
+	encoding, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch encoding {
+	case stringNull:
+		return nil, nil
+	case stringEmpty:
+		return "", nil
+	case stringConstantPool:
+		key, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		// The class is set by the caller
+		return constantRef{key: key}, nil
+	}
+
+	length, err := d.int()
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 || int(length) > len(d.data)-d.pos {
+		return nil, fmt.Errorf("invalid string length %d", length)
+	}
+	switch encoding {
+	case stringUTF8:
+		buf, err := d.read(int(length))
+		return string(buf), err
+	case stringCharArray:
+		chars := make([]uint16, 0, length)
+		for i := int32(0); i < length; i++ {
+			c, err := d.short()
+			if err != nil {
+				return nil, err
+			}
+			chars = append(chars, uint16(c))
+		}
+		return string(utf16.Decode(chars)), nil
+	case stringLatin1:
+		buf, err := d.read(int(length))
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, 0, len(buf))
+		for _, b := range buf {
+			runes = append(runes, rune(b))
+		}
+		return string(runes), nil
+	}
+	return nil, fmt.Errorf("unknown string encoding %d", encoding)
+}
+
+func (d *decoder) element(strings []string, depth int) (*element, error) {
+	if depth > maxDepth {
+		return nil, errors.New("metadata nested too deeply")
+	}
+	lookup := func() (string, error) {
+		idx, err := d.int()
+		if err != nil {
+			return "", err
+		}
+		if idx < 0 || int(idx) >= len(strings) {
+			return "", fmt.Errorf("invalid string index %d", idx)
+		}
+		return strings[idx], nil
+	}
+
+	name, err := lookup()
+	if err != nil {
+		return nil, err
+	}
+	e := &element{name: name, attributes: make(map[string]string)}
+
+	count, err := d.int()
+	if err != nil {
+		return nil, err
+	}
+	for i := int32(0); i < count; i++ {
+		key, err := lookup()
+		if err != nil {
+			return nil, err
+		}
+		value, err := lookup()
+		if err != nil {
+			return nil, err
+		}
+		e.attributes[key] = value
+	}
+
+	count, err = d.int()
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 || int(count) > len(d.data)-d.pos {
+		return nil, fmt.Errorf("invalid element count %d", count)
+	}
+	for i := int32(0); i < count; i++ {
+		child, err := d.element(strings, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		e.children = append(e.children, child)
+	}
+	return e, nil
+}
+
+func (d *decoder) fields(cls *class, depth int) (map[string]interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("values nested too deeply")
+	}
+	values := make(map[string]interface{}, len(cls.fields))
+	for _, f := range cls.fields {
+		v, err := d.field(f, depth)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.name, err)
+		}
+		values[f.name] = v
+	}
+	return values, nil
+}
+
+func (d *decoder) field(f *classField, depth int) (interface{}, error) {
+	if !f.array {
+		return d.fieldValue(f, depth)
+	}
+
+	count, err := d.int()
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 || int(count) > len(d.data)-d.pos {
+		return nil, fmt.Errorf("invalid array length %d", count)
+	}
+	values := make([]interface{}, 0, count)
+	for i := int32(0); i < count; i++ {
+		v, err := d.fieldValue(f, depth)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (d *decoder) fieldValue(f *classField, depth int) (interface{}, error) {
+	if f.constantPool {
+		key, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		return constantRef{classID: f.classID, key: key}, nil
+	}
+	return d.value(f.class, depth+1)
+}
+
+func (d *decoder) value(cls *class, depth int) (interface{}, error) {
+	switch cls.name {
+	case "boolean":
+		b, err := d.byte()
+		return b != 0, err
+	case "byte":
+		b, err := d.byte()
+		return int64(int8(b)), err
+	case "char", "short":
+		v, err := d.short()
+		return int64(v), err
+	case "int":
+		v, err := d.int()
+		return int64(v), err
+	case "long":
+		return d.long()
+	case "float":
+		buf, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(buf))), nil
+	case "double":
+		buf, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(buf)), nil
+	case "java.lang.String":
+		v, err := d.string()
+		if ref, ok := v.(constantRef); ok {
+			// Reference into the string constant pool
+			ref.classID = cls.id
+			return ref, err
+		}
+		return v, err
+	}
+	return d.fields(cls, depth)
+}
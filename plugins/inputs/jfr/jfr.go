@@ -0,0 +1,264 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package jfr
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// The JVM names its default repository "<start time>_<pid>"
+var pidRe = regexp.MustCompile(`_(\d+)$`)
+
+type JFR struct {
+	Repositories []string        `toml:"repositories"`
+	Log          telegraf.Logger `toml:"-"`
+
+	chunks      map[string]*chunkState
+	initialized bool
+}
+
+// chunkState tracks the progress of reading a chunk file
+type chunkState struct {
+	offset   int64
+	end      time.Time
+	finished bool
+}
+
+// stats aggregates the events of a repository within one gather cycle
+type stats struct {
+	window          time.Duration
+	end             time.Time
+	allocated       int64
+	samples         int64
+	safepoints      int64
+	safepointTotal  time.Duration
+	safepointMax    time.Duration
+	safepointSync   time.Duration
+	synchronization int64
+}
+
+func (*JFR) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *JFR) Init() error {
+	if len(j.Repositories) == 0 {
+		return errors.New("no repositories configured")
+	}
+	for _, pattern := range j.Repositories {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+		}
+	}
+	j.chunks = make(map[string]*chunkState)
+
+	return nil
+}
+
+func (j *JFR) Gather(acc telegraf.Accumulator) error {
+	seen := make(map[string]bool, len(j.chunks))
+	for _, pattern := range j.Repositories {
+		dirs, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("resolving repository pattern %q failed: %w", pattern, err)
+		}
+		for _, dir := range dirs {
+			files, err := filepath.Glob(filepath.Join(dir, "*.jfr"))
+			if err != nil {
+				acc.AddError(fmt.Errorf("listing chunks of %q failed: %w", dir, err))
+				continue
+			}
+			if len(files) == 0 {
+				continue
+			}
+			// Chunk files are named by their start time
+			sort.Strings(files)
+			for _, fn := range files {
+				seen[fn] = true
+			}
+			j.gatherRepository(acc, dir, files)
+		}
+	}
+
+	// Forget about chunks removed by the JVM
+	for fn := range j.chunks {
+		if !seen[fn] {
+			delete(j.chunks, fn)
+		}
+	}
+	j.initialized = true
+
+	return nil
+}
+
+func (j *JFR) gatherRepository(acc telegraf.Accumulator, dir string, files []string) {
+	tags := map[string]string{"repository": dir}
+	if match := pidRe.FindStringSubmatch(filepath.Base(dir)); match != nil {
+		tags["pid"] = match[1]
+	}
+
+	s := &stats{}
+	for _, fn := range files {
+		if err := j.gatherChunk(acc, fn, tags, s); err != nil {
+			acc.AddError(fmt.Errorf("reading chunk %q failed: %w", fn, err))
+		}
+	}
+
+	if s.samples > 0 {
+		fields := map[string]interface{}{
+			"allocated_bytes": s.allocated,
+			"samples":         s.samples,
+		}
+		if s.window > 0 {
+			fields["rate_bytes_per_second"] = float64(s.allocated) / s.window.Seconds()
+		}
+		acc.AddFields("jfr_allocation", fields, tags, s.end)
+	}
+	if s.safepoints > 0 || s.synchronization > 0 {
+		acc.AddFields("jfr_safepoint", map[string]interface{}{
+			"count":         s.safepoints,
+			"total_time_ns": s.safepointTotal.Nanoseconds(),
+			"max_time_ns":   s.safepointMax.Nanoseconds(),
+			"sync_time_ns":  s.safepointSync.Nanoseconds(),
+		}, tags, s.end)
+	}
+}
+
+func (j *JFR) gatherChunk(acc telegraf.Accumulator, fn string, tags map[string]string, s *stats) error {
+	state, found := j.chunks[fn]
+	if found && state.finished {
+		return nil
+	}
+
+	// Check the header for new data before reading the whole chunk
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := make([]byte, chunkHeaderSize)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		// The JVM might not have written the header yet
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		return err
+	}
+	header, err := parseChunkHeader(buf)
+	if err != nil {
+		return err
+	}
+	if header.fileState == fileStateUpdating || header.size < chunkHeaderSize {
+		return nil
+	}
+
+	if !found {
+		state = &chunkState{offset: chunkHeaderSize, end: time.Unix(0, header.startNanos)}
+		// Skip the data present at startup and only report new events
+		if !j.initialized {
+			state.offset = header.size
+			state.end = header.end()
+		}
+		j.chunks[fn] = state
+	}
+	if header.size <= state.offset {
+		state.finished = header.finished()
+		return nil
+	}
+
+	data := make([]byte, header.size)
+	copy(data, buf)
+	if _, err := io.ReadFull(f, data[chunkHeaderSize:]); err != nil {
+		return err
+	}
+	c, err := parseChunk(data)
+	if err != nil {
+		return err
+	}
+
+	err = c.events(state.offset, func(e *event) {
+		j.handleEvent(acc, c, e, tags, s)
+	})
+	if err != nil {
+		return err
+	}
+
+	if end := c.header.end(); end.After(state.end) {
+		s.window += end.Sub(state.end)
+		state.end = end
+	}
+	if state.end.After(s.end) {
+		s.end = state.end
+	}
+	state.offset = c.header.size
+	state.finished = c.header.finished()
+
+	return nil
+}
+
+func (*JFR) handleEvent(acc telegraf.Accumulator, c *chunk, e *event, tags map[string]string, s *stats) {
+	switch e.class.name {
+	case "jdk.GarbageCollection":
+		gcTags := make(map[string]string, len(tags)+2)
+		for k, v := range tags {
+			gcTags[k] = v
+		}
+		if name := c.resolveString(e.values["name"]); name != "" {
+			gcTags["name"] = name
+		}
+		if cause := c.resolveString(e.values["cause"]); cause != "" {
+			gcTags["cause"] = cause
+		}
+
+		fields := make(map[string]interface{}, 4)
+		if v, ok := e.values["gcId"].(int64); ok {
+			fields["gc_id"] = v
+		}
+		for name, field := range map[string]string{
+			"duration":     "duration_ns",
+			"sumOfPauses":  "sum_of_pauses_ns",
+			"longestPause": "longest_pause_ns",
+		} {
+			if v, ok := c.timespan(e, name); ok {
+				fields[field] = v.Nanoseconds()
+			}
+		}
+		acc.AddFields("jfr_gc", fields, gcTags, c.timestamp(e))
+	case "jdk.ObjectAllocationSample":
+		if v, ok := e.values["weight"].(int64); ok {
+			s.allocated += v
+			s.samples++
+		}
+	case "jdk.SafepointBegin":
+		if v, ok := c.timespan(e, "duration"); ok {
+			s.safepoints++
+			s.safepointTotal += v
+			s.safepointMax = max(s.safepointMax, v)
+		}
+	case "jdk.SafepointStateSynchronization":
+		if v, ok := c.timespan(e, "duration"); ok {
+			s.synchronization++
+			s.safepointSync += v
+		}
+	}
+}
+
+func init() {
+	inputs.Add("jfr", func() telegraf.Input {
+		return &JFR{}
+	})
+}
@@ -0,0 +1,462 @@
+package jfr
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const (
+	chunkStart     = int64(1736496000000000000)
+	startTicks     = 1000
+	ticksPerSecond = 1000000
+)
+
+func TestInit(t *testing.T) {
+	plugin := &JFR{Log: testutil.Logger{}}
+	require.ErrorContains(t, plugin.Init(), "no repositories configured")
+
+	plugin = &JFR{
+		Repositories: []string{"/tmp/[jfr"},
+		Log:          testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid repository pattern")
+}
+
+func TestParseChunk(t *testing.T) {
+	c, err := parseChunk(buildChunk(testEvents(), 2*time.Second, true))
+	require.NoError(t, err)
+	require.True(t, c.header.finished())
+	require.Equal(t, "jdk.GarbageCollection", c.classes[100].name)
+	require.Equal(t, "G1New", c.resolveString(constantRef{classID: 10, key: 1}))
+	require.Equal(t, "G1 Evacuation Pause", c.resolveString(constantRef{classID: 11, key: 1}))
+
+	var events []*event
+	require.NoError(t, c.events(chunkHeaderSize, func(e *event) {
+		events = append(events, e)
+	}))
+	require.Len(t, events, 7)
+
+	gc := events[0]
+	require.Equal(t, "jdk.GarbageCollection", gc.class.name)
+	require.Equal(t, time.Unix(0, chunkStart+500*int64(time.Millisecond)), c.timestamp(gc))
+	duration, ok := c.timespan(gc, "duration")
+	require.True(t, ok)
+	require.Equal(t, 4213*time.Microsecond, duration)
+
+	other := events[6]
+	require.Equal(t, "jdk.Other", other.class.name)
+	require.Equal(t, map[string]interface{}{
+		"startTime": int64(startTicks),
+		"flag":      true,
+		"values":    []interface{}{int64(1), int64(-2), int64(300)},
+		"ratio":     float64(0.25),
+		"label":     "abc",
+		"empty":     "",
+		"missing":   nil,
+	}, other.values)
+}
+
+func TestParseChunkInvalid(t *testing.T) {
+	data := buildChunk(testEvents(), time.Second, true)
+
+	_, err := parseChunk(data[:40])
+	require.ErrorContains(t, err, "chunk header too short")
+
+	truncated := append([]byte{}, data[:len(data)-10]...)
+	_, err = parseChunk(truncated)
+	require.ErrorContains(t, err, "exceeds data size")
+
+	invalid := append([]byte{}, data...)
+	invalid[0] = 'X'
+	_, err = parseChunk(invalid)
+	require.ErrorContains(t, err, "invalid chunk magic")
+}
+
+func TestGather(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "2025_01_10_08_00_00_4711")
+	require.NoError(t, os.Mkdir(dir, 0750))
+
+	plugin := &JFR{
+		Repositories: []string{filepath.Join(filepath.Dir(dir), "*")},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Initial gather without any chunks
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Create a chunk after startup
+	fn := filepath.Join(dir, "2025_01_10_08_00_00.jfr")
+	require.NoError(t, os.WriteFile(fn, buildChunk(testEvents(), 2*time.Second, true), 0600))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	end := time.Unix(0, chunkStart+2*int64(time.Second))
+	expected := []telegraf.Metric{
+		metric.New(
+			"jfr_gc",
+			map[string]string{
+				"repository": dir,
+				"pid":        "4711",
+				"name":       "G1New",
+				"cause":      "G1 Evacuation Pause",
+			},
+			map[string]interface{}{
+				"gc_id":            int64(17),
+				"duration_ns":      int64(4213000),
+				"sum_of_pauses_ns": int64(4000000),
+				"longest_pause_ns": int64(3000000),
+			},
+			time.Unix(0, chunkStart+500*int64(time.Millisecond)),
+		),
+		metric.New(
+			"jfr_allocation",
+			map[string]string{"repository": dir, "pid": "4711"},
+			map[string]interface{}{
+				"allocated_bytes":       int64(4000),
+				"samples":               int64(2),
+				"rate_bytes_per_second": float64(2000),
+			},
+			end,
+		),
+		metric.New(
+			"jfr_safepoint",
+			map[string]string{"repository": dir, "pid": "4711"},
+			map[string]interface{}{
+				"count":         int64(2),
+				"total_time_ns": int64(400000),
+				"max_time_ns":   int64(300000),
+				"sync_time_ns":  int64(50000),
+			},
+			end,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Finished chunks must not be read again
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.True(t, plugin.chunks[fn].finished)
+
+	// Removed chunks are forgotten
+	require.NoError(t, os.Remove(fn))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, plugin.chunks)
+}
+
+func TestGatherStreaming(t *testing.T) {
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "2025_01_10_08_00_00.jfr")
+
+	// Events existing at startup must be skipped
+	events := testEvents()
+	require.NoError(t, os.WriteFile(fn, buildChunk(events, time.Second, false), 0600))
+
+	plugin := &JFR{
+		Repositories: []string{dir},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.False(t, plugin.chunks[fn].finished)
+
+	// Simulate the JVM flushing new events to the chunk
+	events = append(events,
+		allocationSample(startTicks+1500000, 6000),
+		safepoint(103, startTicks+1600000, 20),
+	)
+	require.NoError(t, os.WriteFile(fn, buildChunk(events, 3*time.Second, false), 0600))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	end := time.Unix(0, chunkStart+3*int64(time.Second))
+	expected := []telegraf.Metric{
+		metric.New(
+			"jfr_allocation",
+			map[string]string{"repository": dir},
+			map[string]interface{}{
+				"allocated_bytes":       int64(6000),
+				"samples":               int64(1),
+				"rate_bytes_per_second": float64(3000),
+			},
+			end,
+		),
+		metric.New(
+			"jfr_safepoint",
+			map[string]string{"repository": dir},
+			map[string]interface{}{
+				"count":         int64(0),
+				"total_time_ns": int64(0),
+				"max_time_ns":   int64(0),
+				"sync_time_ns":  int64(20000),
+			},
+			end,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Chunks being updated by the JVM are skipped
+	acc.ClearMetrics()
+	data := buildChunk(append(events, allocationSample(startTicks, 1)), 4*time.Second, false)
+	data[64] = fileStateUpdating
+	require.NoError(t, os.WriteFile(fn, data, 0600))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Empty(t, acc.Errors)
+}
+
+// testEvents returns the encoded events of a garbage collection, allocation
+// samples, safepoints and an unrelated event
+func testEvents() [][]byte {
+	var gc []byte
+	gc = appendVarint(gc, startTicks+500000)
+	gc = appendVarint(gc, 4213)
+	gc = appendVarint(gc, 17)
+	gc = appendVarint(gc, 1)
+	gc = appendVarint(gc, 1)
+	gc = appendVarint(gc, 4000)
+	gc = appendVarint(gc, 3000)
+
+	var other []byte
+	other = appendVarint(other, startTicks)
+	other = append(other, 1)
+	other = appendVarint(other, 3)
+	other = appendVarint(other, 1)
+	other = appendVarint(other, uint64(0xfffffffe)) // -2 as int
+	other = appendVarint(other, 300)
+	other = binary.BigEndian.AppendUint64(other, math.Float64bits(0.25))
+	other = append(other, stringLatin1, 3, 'a', 'b', 'c')
+	other = append(other, stringEmpty)
+	other = append(other, stringNull)
+
+	return [][]byte{
+		encodeEvent(100, gc),
+		allocationSample(startTicks+600000, 1000),
+		allocationSample(startTicks+700000, 3000),
+		safepoint(102, startTicks+800000, 100),
+		safepoint(102, startTicks+900000, 300),
+		safepoint(103, startTicks+900000, 50),
+		encodeEvent(104, other),
+	}
+}
+
+func allocationSample(ticks, weight uint64) []byte {
+	return encodeEvent(101, appendVarint(appendVarint(nil, ticks), weight))
+}
+
+func safepoint(typeID, ticks, duration uint64) []byte {
+	return encodeEvent(typeID, appendVarint(appendVarint(nil, ticks), duration))
+}
+
+// buildChunk creates a chunk with the metadata and constant pools required
+// for the test events
+func buildChunk(events [][]byte, duration time.Duration, finished bool) []byte {
+	data := make([]byte, chunkHeaderSize)
+
+	metadataOffset := len(data)
+	data = append(data, metadataEvent()...)
+
+	// Strings referenced by other constant pools in the first pool
+	firstPool := len(data)
+	var pool []byte
+	pool = appendVarint(pool, 0) // start time
+	pool = appendVarint(pool, 0) // duration
+	pool = appendVarint(pool, 0) // delta to previous pool
+	pool = append(pool, 0)       // checkpoint type
+	pool = appendVarint(pool, 1) // number of pools
+	pool = appendVarint(pool, 3) // java.lang.String
+	pool = appendVarint(pool, 1)
+	pool = appendVarint(pool, 7)
+	pool = append(pool, stringUTF8, 19)
+	pool = append(pool, "G1 Evacuation Pause"...)
+	data = append(data, encodeEvent(eventTypeConstantPool, pool)...)
+
+	lastPool := len(data)
+	pool = appendVarint(nil, 0)
+	pool = appendVarint(pool, 0)
+	pool = appendVarint(pool, uint64(firstPool-lastPool))
+	pool = append(pool, 0)
+	pool = appendVarint(pool, 2)
+	pool = appendVarint(pool, 10) // jdk.types.GCName
+	pool = appendVarint(pool, 1)
+	pool = appendVarint(pool, 1)
+	pool = append(pool, stringUTF8, 5)
+	pool = append(pool, "G1New"...)
+	pool = appendVarint(pool, 11) // jdk.types.GCCause
+	pool = appendVarint(pool, 1)
+	pool = appendVarint(pool, 1)
+	pool = append(pool, stringConstantPool, 7)
+	data = append(data, encodeEvent(eventTypeConstantPool, pool)...)
+
+	for _, e := range events {
+		data = append(data, e...)
+	}
+
+	copy(data, chunkMagic)
+	binary.BigEndian.PutUint16(data[4:], 2)
+	binary.BigEndian.PutUint16(data[6:], 1)
+	binary.BigEndian.PutUint64(data[8:], uint64(len(data)))
+	binary.BigEndian.PutUint64(data[16:], uint64(lastPool))
+	binary.BigEndian.PutUint64(data[24:], uint64(metadataOffset))
+	binary.BigEndian.PutUint64(data[32:], uint64(chunkStart))
+	binary.BigEndian.PutUint64(data[40:], uint64(duration))
+	binary.BigEndian.PutUint64(data[48:], startTicks)
+	binary.BigEndian.PutUint64(data[56:], ticksPerSecond)
+	if !finished {
+		data[64] = 1
+	}
+	data[67] = flagCompressedInts
+
+	return data
+}
+
+type testElement struct {
+	name       string
+	attributes [][2]string
+	children   []testElement
+}
+
+func metadataEvent() []byte {
+	ticks := func(annotation string) testElement {
+		return testElement{name: "annotation", attributes: [][2]string{{"class", annotation}, {"value", "TICKS"}}}
+	}
+	field := func(name, class string, children ...testElement) testElement {
+		return testElement{name: "field", attributes: [][2]string{{"name", name}, {"class", class}}, children: children}
+	}
+	cpField := func(name, class string) testElement {
+		return testElement{name: "field", attributes: [][2]string{{"name", name}, {"class", class}, {"constantPool", "true"}}}
+	}
+	class := func(id int, name string, fields ...testElement) testElement {
+		return testElement{name: "class", attributes: [][2]string{{"id", strconv.Itoa(id)}, {"name", name}}, children: fields}
+	}
+	startTime := field("startTime", "1", ticks("21"))
+	duration := field("duration", "1", ticks("20"))
+
+	root := testElement{
+		name: "root",
+		children: []testElement{
+			{
+				name: "metadata",
+				children: []testElement{
+					class(1, "long"),
+					class(2, "int"),
+					class(3, "java.lang.String"),
+					class(4, "boolean"),
+					class(5, "double"),
+					class(10, "jdk.types.GCName", field("name", "3")),
+					class(11, "jdk.types.GCCause", field("cause", "3")),
+					class(20, "jdk.jfr.Timespan", field("value", "3")),
+					class(21, "jdk.jfr.Timestamp", field("value", "3")),
+					class(100, "jdk.GarbageCollection",
+						startTime,
+						duration,
+						field("gcId", "2"),
+						cpField("name", "10"),
+						cpField("cause", "11"),
+						field("sumOfPauses", "1", ticks("20")),
+						field("longestPause", "1", ticks("20")),
+					),
+					class(101, "jdk.ObjectAllocationSample", startTime, field("weight", "1")),
+					class(102, "jdk.SafepointBegin", startTime, duration),
+					class(103, "jdk.SafepointStateSynchronization", startTime, duration),
+					class(104, "jdk.Other",
+						startTime,
+						field("flag", "4"),
+						testElement{name: "field", attributes: [][2]string{{"name", "values"}, {"class", "2"}, {"dimension", "1"}}},
+						field("ratio", "5"),
+						field("label", "3"),
+						field("empty", "3"),
+						field("missing", "3"),
+					),
+				},
+			},
+			{name: "region", attributes: [][2]string{{"gmtOffset", "0"}}},
+		},
+	}
+
+	// Collect the strings and encode the element tree
+	var strings []string
+	index := make(map[string]int)
+	lookup := func(s string) uint64 {
+		if idx, found := index[s]; found {
+			return uint64(idx)
+		}
+		index[s] = len(strings)
+		strings = append(strings, s)
+		return uint64(len(strings) - 1)
+	}
+	var encode func(e testElement) []byte
+	encode = func(e testElement) []byte {
+		buf := appendVarint(nil, lookup(e.name))
+		buf = appendVarint(buf, uint64(len(e.attributes)))
+		for _, attr := range e.attributes {
+			buf = appendVarint(buf, lookup(attr[0]))
+			buf = appendVarint(buf, lookup(attr[1]))
+		}
+		buf = appendVarint(buf, uint64(len(e.children)))
+		for _, child := range e.children {
+			buf = append(buf, encode(child)...)
+		}
+		return buf
+	}
+	tree := encode(root)
+
+	var body []byte
+	body = appendVarint(body, 0) // start time
+	body = appendVarint(body, 0) // duration
+	body = appendVarint(body, 1) // metadata ID
+	body = appendVarint(body, uint64(len(strings)))
+	for _, s := range strings {
+		body = append(body, stringUTF8)
+		body = appendVarint(body, uint64(len(s)))
+		body = append(body, s...)
+	}
+	body = append(body, tree...)
+
+	return encodeEvent(eventTypeMetadata, body)
+}
+
+// encodeEvent prefixes the event with its size padded to four bytes as done
+// by the JVM
+func encodeEvent(typeID uint64, body []byte) []byte {
+	payload := appendVarint(nil, typeID)
+	payload = append(payload, body...)
+	size := uint32(4 + len(payload))
+	buf := []byte{
+		byte(size&0x7f) | 0x80,
+		byte(size>>7&0x7f) | 0x80,
+		byte(size>>14&0x7f) | 0x80,
+		byte(size >> 21 & 0x7f),
+	}
+	return append(buf, payload...)
+}
+
+// appendVarint encodes the value using seven bits per byte with the ninth
+// byte holding the remaining eight bits
+func appendVarint(buf []byte, v uint64) []byte {
+	for i := 0; i < 8; i++ {
+		if v < 0x80 {
+			return append(buf, byte(v))
+		}
+		buf = append(buf, byte(v&0x7f)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
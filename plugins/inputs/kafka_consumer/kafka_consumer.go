@@ -29,6 +29,7 @@ var once sync.Once
 const (
 	defaultMaxUndeliveredMessages = 1000
 	defaultMaxProcessingTime      = config.Duration(100 * time.Millisecond)
+	defaultMaxDeliveryRetries     = 3
 	defaultConsumerGroup          = "telegraf_metrics_consumers"
 	reconnectDelay                = 5 * time.Second
 )
@@ -40,6 +41,8 @@ type KafkaConsumer struct {
 	MaxMessageLen                        int             `toml:"max_message_len"`
 	MaxUndeliveredMessages               int             `toml:"max_undelivered_messages"`
 	MaxProcessingTime                    config.Duration `toml:"max_processing_time"`
+	OffsetCommit                         string          `toml:"offset_commit"`
+	MaxDeliveryRetries                   *int            `toml:"max_delivery_retries"`
 	Offset                               string          `toml:"offset"`
 	BalanceStrategy                      string          `toml:"balance_strategy"`
 	Topics                               []string        `toml:"topics"`
@@ -76,6 +79,8 @@ type consumerGroupHandler struct {
 	msgHeadersToTags      map[string]bool
 	msgHeaderToMetricName string
 	timestampSource       string
+	orderedCommit         bool
+	maxDeliveryRetries    int
 
 	acc    telegraf.TrackingAccumulator
 	sem    semaphore
@@ -84,15 +89,31 @@ type consumerGroupHandler struct {
 	cancel context.CancelFunc
 
 	mu          sync.Mutex
+	session     sarama.ConsumerGroupSession
 	undelivered map[telegraf.TrackingID]message
+	pending     map[topicPartition]*pendingOffsets
 
 	log telegraf.Logger
 }
 
-// message is an aggregate type binding the Kafka message and the session so that offsets can be updated.
+// message is an aggregate type binding the Kafka message and the number of
+// failed delivery attempts so that offsets can be updated.
 type message struct {
 	message *sarama.ConsumerMessage
-	session sarama.ConsumerGroupSession
+	retries int
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// pendingOffsets keeps the offsets of a partition in the order of
+// consumption to only commit offsets of messages for which all previous
+// messages are done.
+type pendingOffsets struct {
+	offsets []int64
+	done    map[int64]bool
 }
 
 type (
@@ -141,6 +162,21 @@ func (k *KafkaConsumer) Init() error {
 		return fmt.Errorf("invalid timestamp source %q", k.TimestampSource)
 	}
 
+	switch k.OffsetCommit {
+	case "":
+		k.OffsetCommit = "delivered"
+	case "delivered", "ordered":
+	default:
+		return fmt.Errorf("invalid offset commit strategy %q", k.OffsetCommit)
+	}
+
+	if k.MaxDeliveryRetries == nil {
+		retries := defaultMaxDeliveryRetries
+		k.MaxDeliveryRetries = &retries
+	} else if *k.MaxDeliveryRetries < 0 {
+		return fmt.Errorf("invalid max_delivery_retries %d", *k.MaxDeliveryRetries)
+	}
+
 	cfg := sarama.NewConfig()
 
 	// Kafka version 0.10.2.0 is required for consumer groups.
@@ -260,23 +296,29 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 
 		k.startErrorAdder(acc)
 
-		for ctx.Err() == nil {
-			handler := newConsumerGroupHandler(acc, k.MaxUndeliveredMessages, k.parser, k.Log)
-			handler.maxMessageLen = k.MaxMessageLen
-			handler.topicTag = k.TopicTag
-			handler.msgHeaderToMetricName = k.MsgHeaderAsMetricName
-			// if message headers list specified, put it as map to handler
-			msgHeadersMap := make(map[string]bool, len(k.MsgHeadersAsTags))
-			if len(k.MsgHeadersAsTags) > 0 {
-				for _, header := range k.MsgHeadersAsTags {
-					if k.MsgHeaderAsMetricName != header {
-						msgHeadersMap[header] = true
-					}
+		// The handler is shared by all sessions so messages still in flight
+		// when the group rebalances are tracked until they are delivered.
+		handler := newConsumerGroupHandler(acc, k.MaxUndeliveredMessages, k.parser, k.Log)
+		handler.maxMessageLen = k.MaxMessageLen
+		handler.topicTag = k.TopicTag
+		handler.msgHeaderToMetricName = k.MsgHeaderAsMetricName
+		// if message headers list specified, put it as map to handler
+		msgHeadersMap := make(map[string]bool, len(k.MsgHeadersAsTags))
+		if len(k.MsgHeadersAsTags) > 0 {
+			for _, header := range k.MsgHeadersAsTags {
+				if k.MsgHeaderAsMetricName != header {
+					msgHeadersMap[header] = true
 				}
 			}
-			handler.msgHeadersToTags = msgHeadersMap
-			handler.timestampSource = k.TimestampSource
+		}
+		handler.msgHeadersToTags = msgHeadersMap
+		handler.timestampSource = k.TimestampSource
+		handler.orderedCommit = k.OffsetCommit == "ordered"
+		if k.MaxDeliveryRetries != nil {
+			handler.maxDeliveryRetries = *k.MaxDeliveryRetries
+		}
 
+		for ctx.Err() == nil {
 			// We need to copy allWantedTopics; the Consume() is
 			// long-running and we can easily deadlock if our
 			// topic-update-checker fires.
@@ -284,6 +326,7 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 			k.topicLock.Lock()
 			copy(topics, k.allWantedTopics)
 			k.topicLock.Unlock()
+
 			err := k.consumer.Consume(ctx, topics, handler)
 			if err != nil {
 				acc.AddError(fmt.Errorf("consume: %w", err))
@@ -419,6 +462,7 @@ func newConsumerGroupHandler(acc telegraf.Accumulator, maxUndelivered int, parse
 		acc:         acc.WithTracking(maxUndelivered),
 		sem:         make(chan empty, maxUndelivered),
 		undelivered: make(map[telegraf.TrackingID]message, maxUndelivered),
+		pending:     make(map[topicPartition]*pendingOffsets),
 		parser:      parser,
 		log:         log,
 	}
@@ -426,8 +470,12 @@ func newConsumerGroupHandler(acc telegraf.Accumulator, maxUndelivered int, parse
 }
 
 // Setup is called once when a new session is opened. It setups up the handler and begins processing delivered messages.
-func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
-	h.undelivered = make(map[telegraf.TrackingID]message)
+// Messages of previous sessions still awaiting delivery are kept and their
+// offsets are marked using the new session.
+func (h *consumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	h.mu.Lock()
+	h.session = session
+	h.mu.Unlock()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	h.cancel = cancel
@@ -494,14 +542,83 @@ func (h *consumerGroupHandler) onDelivery(track telegraf.DeliveryInfo) {
 		return
 	}
 
-	if track.Delivered() {
-		msg.session.MarkMessage(msg.message, "")
+	delete(h.undelivered, track.ID())
+
+	switch {
+	case !h.orderedCommit:
+		if track.Delivered() {
+			h.session.MarkMessage(msg.message, "")
+		}
+	case track.Delivered():
+		h.markDone(h.session, msg.message)
+	case msg.retries < h.maxDeliveryRetries:
+		// Do not commit beyond the undelivered message but hand its metrics
+		// to the outputs again keeping the reserved slot.
+		h.log.Warnf("Delivery of message at offset %d of partition %d of topic %q failed, retrying (%d/%d)",
+			msg.message.Offset, msg.message.Partition, msg.message.Topic, msg.retries+1, h.maxDeliveryRetries)
+		metrics, err := h.metrics(msg.message)
+		if err != nil {
+			h.log.Errorf("Reprocessing message at offset %d of partition %d of topic %q failed: %v",
+				msg.message.Offset, msg.message.Partition, msg.message.Topic, err)
+			break
+		}
+		id := h.acc.AddTrackingMetricGroup(metrics)
+		h.undelivered[id] = message{message: msg.message, retries: msg.retries + 1}
+		return
+	default:
+		// Skip the message to not block the partition forever
+		h.log.Errorf("Delivery of message at offset %d of partition %d of topic %q failed %d times, skipping message",
+			msg.message.Offset, msg.message.Partition, msg.message.Topic, msg.retries+1)
+		h.markDone(h.session, msg.message)
 	}
 
-	delete(h.undelivered, track.ID())
 	<-h.sem
 }
 
+// track registers the message offset as pending for the ordered commit.
+// Must be called with the lock held.
+func (h *consumerGroupHandler) track(msg *sarama.ConsumerMessage) {
+	tp := topicPartition{topic: msg.Topic, partition: msg.Partition}
+	p, found := h.pending[tp]
+	if !found {
+		p = &pendingOffsets{done: make(map[int64]bool)}
+		h.pending[tp] = p
+	}
+
+	// Messages consumed again after a rebalance are already pending
+	if n := len(p.offsets); n > 0 && msg.Offset <= p.offsets[n-1] {
+		return
+	}
+	p.offsets = append(p.offsets, msg.Offset)
+}
+
+// markDone marks the message as processed and, for the ordered commit,
+// advances the committed offset of the partition as far as all previous
+// messages are processed. Must be called with the lock held.
+func (h *consumerGroupHandler) markDone(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) {
+	if !h.orderedCommit {
+		session.MarkMessage(msg, "")
+		return
+	}
+
+	tp := topicPartition{topic: msg.Topic, partition: msg.Partition}
+	p, found := h.pending[tp]
+	if !found || len(p.offsets) == 0 || msg.Offset < p.offsets[0] {
+		return
+	}
+	p.done[msg.Offset] = true
+
+	next := int64(-1)
+	for len(p.offsets) > 0 && p.done[p.offsets[0]] {
+		next = p.offsets[0] + 1
+		delete(p.done, p.offsets[0])
+		p.offsets = p.offsets[1:]
+	}
+	if next >= 0 {
+		session.MarkOffset(msg.Topic, msg.Partition, next, "")
+	}
+}
+
 // reserve blocks until there is an available slot for a new message.
 func (h *consumerGroupHandler) reserve(ctx context.Context) error {
 	select {
@@ -518,20 +635,45 @@ func (h *consumerGroupHandler) release() {
 
 // handle processes a message and if successful saves it to be acknowledged after delivery.
 func (h *consumerGroupHandler) handle(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) error {
+	if h.orderedCommit {
+		h.mu.Lock()
+		h.track(msg)
+		h.mu.Unlock()
+	}
+
 	if h.maxMessageLen != 0 && len(msg.Value) > h.maxMessageLen {
-		session.MarkMessage(msg, "")
+		h.mu.Lock()
+		h.markDone(session, msg)
+		h.mu.Unlock()
 		h.release()
 		return fmt.Errorf("message exceeds max_message_len (actual %d, max %d)",
 			len(msg.Value), h.maxMessageLen)
 	}
 
-	metrics, err := h.parser.Parse(msg.Value)
+	metrics, err := h.metrics(msg)
 	if err != nil {
-		session.MarkMessage(msg, "")
+		h.mu.Lock()
+		h.markDone(session, msg)
+		h.mu.Unlock()
 		h.release()
 		return err
 	}
 
+	h.mu.Lock()
+	id := h.acc.AddTrackingMetricGroup(metrics)
+	h.undelivered[id] = message{message: msg}
+	h.mu.Unlock()
+	return nil
+}
+
+// metrics parses the message and applies the message header, topic and
+// timestamp settings to the resulting metrics.
+func (h *consumerGroupHandler) metrics(msg *sarama.ConsumerMessage) ([]telegraf.Metric, error) {
+	metrics, err := h.parser.Parse(msg.Value)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(metrics) == 0 {
 		once.Do(func() {
 			h.log.Debug(internal.NoMetricsCreatedMsg)
@@ -577,11 +719,7 @@ func (h *consumerGroupHandler) handle(session sarama.ConsumerGroupSession, msg *
 		}
 	}
 
-	h.mu.Lock()
-	id := h.acc.AddTrackingMetricGroup(metrics)
-	h.undelivered[id] = message{session: session, message: msg}
-	h.mu.Unlock()
-	return nil
+	return metrics, nil
 }
 
 func init() {
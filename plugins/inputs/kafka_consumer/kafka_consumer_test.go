@@ -203,6 +203,24 @@ func TestInit(t *testing.T) {
 				require.Equal(t, 1000*time.Millisecond, plugin.config.Consumer.MaxProcessingTime)
 			},
 		},
+		{
+			name: "ordered offset commit",
+			plugin: &KafkaConsumer{
+				OffsetCommit: "ordered",
+				Log:          testutil.Logger{},
+			},
+			check: func(t *testing.T, plugin *KafkaConsumer) {
+				require.Equal(t, "ordered", plugin.OffsetCommit)
+			},
+		},
+		{
+			name: "invalid offset commit",
+			plugin: &KafkaConsumer{
+				OffsetCommit: "sometimes",
+				Log:          testutil.Logger{},
+			},
+			initError: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -237,7 +255,8 @@ func TestStartStop(t *testing.T) {
 }
 
 type FakeConsumerGroupSession struct {
-	ctx context.Context
+	ctx     context.Context
+	offsets map[string]int64
 }
 
 func (*FakeConsumerGroupSession) Claims() map[string][]int32 {
@@ -252,8 +271,11 @@ func (*FakeConsumerGroupSession) GenerationID() int32 {
 	panic("not implemented")
 }
 
-func (*FakeConsumerGroupSession) MarkOffset(string, int32, int64, string) {
-	panic("not implemented")
+func (s *FakeConsumerGroupSession) MarkOffset(topic string, partition int32, offset int64, _ string) {
+	if s.offsets == nil {
+		s.offsets = make(map[string]int64)
+	}
+	s.offsets[fmt.Sprintf("%s/%d", topic, partition)] = offset
 }
 
 func (*FakeConsumerGroupSession) ResetOffset(string, int32, int64, string) {
@@ -478,6 +500,129 @@ func TestConsumerGroupHandlerHandle(t *testing.T) {
 	}
 }
 
+type fakeDeliveryInfo struct {
+	id        telegraf.TrackingID
+	delivered bool
+}
+
+func (d *fakeDeliveryInfo) ID() telegraf.TrackingID {
+	return d.id
+}
+
+func (d *fakeDeliveryInfo) Delivered() bool {
+	return d.delivered
+}
+
+func TestConsumerGroupHandlerOrderedCommit(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	parser := value.Parser{
+		MetricName: "cpu",
+		DataType:   "int",
+	}
+	require.NoError(t, parser.Init())
+	cg := newConsumerGroupHandler(acc, 5, &parser, testutil.Logger{})
+	cg.orderedCommit = true
+	cg.maxDeliveryRetries = 1
+
+	ctx := context.Background()
+	session := &FakeConsumerGroupSession{ctx: ctx}
+	cg.session = session
+
+	// Consume messages of two partitions including one failing to parse
+	ids := make(map[int64]telegraf.TrackingID)
+	for _, msg := range []*sarama.ConsumerMessage{
+		{Topic: "telegraf", Partition: 0, Offset: 10, Value: []byte("1")},
+		{Topic: "telegraf", Partition: 0, Offset: 11, Value: []byte("invalid")},
+		{Topic: "telegraf", Partition: 0, Offset: 12, Value: []byte("3")},
+		{Topic: "telegraf", Partition: 1, Offset: 20, Value: []byte("4")},
+		{Topic: "telegraf", Partition: 0, Offset: 13, Value: []byte("5")},
+	} {
+		require.NoError(t, cg.reserve(ctx))
+		//nolint:errcheck // parsing errors are expected
+		cg.handle(session, msg)
+	}
+	for id, msg := range cg.undelivered {
+		ids[msg.message.Offset] = id
+	}
+	require.Len(t, ids, 4)
+
+	// Delivering later messages must not commit the earlier, pending one
+	cg.onDelivery(&fakeDeliveryInfo{id: ids[12], delivered: true})
+	cg.onDelivery(&fakeDeliveryInfo{id: ids[20], delivered: true})
+	require.Equal(t, map[string]int64{"telegraf/1": 21}, session.offsets)
+
+	// Delivering the oldest message commits all done messages
+	cg.onDelivery(&fakeDeliveryInfo{id: ids[10], delivered: true})
+	require.Equal(t, map[string]int64{"telegraf/0": 13, "telegraf/1": 21}, session.offsets)
+
+	// A failed delivery does not commit but hands the metrics to the
+	// outputs again keeping the reserved slot
+	acc.ClearMetrics()
+	cg.onDelivery(&fakeDeliveryInfo{id: ids[13], delivered: false})
+	require.Equal(t, map[string]int64{"telegraf/0": 13, "telegraf/1": 21}, session.offsets)
+	require.Len(t, cg.undelivered, 1)
+	require.Len(t, cg.sem, 1)
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 5}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Exceeding the retries skips the message
+	var retry telegraf.TrackingID
+	for id := range cg.undelivered {
+		retry = id
+	}
+	cg.onDelivery(&fakeDeliveryInfo{id: retry, delivered: false})
+	require.Equal(t, map[string]int64{"telegraf/0": 14, "telegraf/1": 21}, session.offsets)
+	require.Empty(t, cg.undelivered)
+	require.Empty(t, cg.sem)
+}
+
+func TestConsumerGroupHandlerOrderedCommitRebalance(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	parser := value.Parser{
+		MetricName: "cpu",
+		DataType:   "int",
+	}
+	require.NoError(t, parser.Init())
+	cg := newConsumerGroupHandler(acc, 5, &parser, testutil.Logger{})
+	cg.orderedCommit = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := &FakeConsumerGroupSession{ctx: ctx}
+	require.NoError(t, cg.Setup(first))
+
+	for _, msg := range []*sarama.ConsumerMessage{
+		{Topic: "telegraf", Partition: 0, Offset: 10, Value: []byte("1")},
+		{Topic: "telegraf", Partition: 0, Offset: 11, Value: []byte("2")},
+	} {
+		require.NoError(t, cg.reserve(ctx))
+		require.NoError(t, cg.handle(first, msg))
+	}
+	ids := make(map[int64]telegraf.TrackingID)
+	for id, msg := range cg.undelivered {
+		ids[msg.message.Offset] = id
+	}
+	require.NoError(t, cg.Cleanup(first))
+
+	// The messages in flight are kept across sessions and their offsets
+	// are committed using the new session
+	second := &FakeConsumerGroupSession{ctx: ctx}
+	require.NoError(t, cg.Setup(second))
+	defer cg.Cleanup(second) //nolint:errcheck // ignore error in test cleanup
+	require.Len(t, cg.undelivered, 2)
+
+	// Consuming an offset already pending again does not track it twice
+	require.NoError(t, cg.reserve(ctx))
+	require.NoError(t, cg.handle(second, &sarama.ConsumerMessage{Topic: "telegraf", Partition: 0, Offset: 11, Value: []byte("2")}))
+
+	cg.onDelivery(&fakeDeliveryInfo{id: ids[10], delivered: true})
+	cg.onDelivery(&fakeDeliveryInfo{id: ids[11], delivered: true})
+	require.Equal(t, map[string]int64{"telegraf/0": 12}, second.offsets)
+	require.Empty(t, first.offsets)
+}
+
 func TestExponentialBackoff(t *testing.T) {
 	var err error
 
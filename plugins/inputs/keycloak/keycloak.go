@@ -0,0 +1,333 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package keycloak
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Number of events requested per page
+const eventPageSize = 100
+
+type Keycloak struct {
+	URL          string          `toml:"url"`
+	AuthRealm    string          `toml:"auth_realm"`
+	ClientID     string          `toml:"client_id"`
+	ClientSecret config.Secret   `toml:"client_secret"`
+	Username     config.Secret   `toml:"username"`
+	Password     config.Secret   `toml:"password"`
+	Realms       []string        `toml:"realms"`
+	Events       bool            `toml:"events"`
+	Log          telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+	// lastEvent holds the timestamp in milliseconds of the newest event
+	// already counted per realm
+	lastEvent map[string]int64
+	start     int64
+}
+
+type realmInfo struct {
+	ID      string `json:"id"`
+	Realm   string `json:"realm"`
+	Enabled bool   `json:"enabled"`
+}
+
+type clientSessionStats struct {
+	ClientID string `json:"clientId"`
+	Active   string `json:"active"`
+	Offline  string `json:"offline"`
+}
+
+type event struct {
+	Time int64  `json:"time"`
+	Type string `json:"type"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+func (*Keycloak) SampleConfig() string {
+	return sampleConfig
+}
+
+func (k *Keycloak) Init() error {
+	if k.URL == "" {
+		return errors.New("url required")
+	}
+	if _, err := url.Parse(k.URL); err != nil {
+		return fmt.Errorf("parsing url failed: %w", err)
+	}
+	k.URL = strings.TrimSuffix(k.URL, "/")
+
+	if k.AuthRealm == "" {
+		k.AuthRealm = "master"
+	}
+	if k.ClientID == "" {
+		k.ClientID = "admin-cli"
+	}
+	if k.Username.Empty() && k.ClientSecret.Empty() {
+		return errors.New("either 'username' or 'client_secret' required")
+	}
+
+	k.lastEvent = make(map[string]int64)
+	k.start = time.Now().UnixMilli()
+
+	return nil
+}
+
+func (k *Keycloak) Start(telegraf.Accumulator) error {
+	client, err := k.HTTPClientConfig.CreateClient(context.Background(), k.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	k.client = client
+
+	return nil
+}
+
+func (k *Keycloak) Gather(acc telegraf.Accumulator) error {
+	// Request a new token every time to measure the token issuance latency
+	start := time.Now()
+	token, err := k.requestToken()
+	if err != nil {
+		return fmt.Errorf("requesting token failed: %w", err)
+	}
+	acc.AddFields("keycloak_token",
+		map[string]interface{}{"response_time": time.Since(start).Seconds()},
+		map[string]string{"url": k.URL, "realm": k.AuthRealm},
+	)
+
+	var realms []realmInfo
+	if err := k.getJSON(token, "/admin/realms", &realms); err != nil {
+		return fmt.Errorf("querying realms failed: %w", err)
+	}
+
+	for _, realm := range realms {
+		if len(k.Realms) > 0 && !slices.Contains(k.Realms, realm.Realm) {
+			continue
+		}
+		if err := k.gatherRealm(acc, token, realm); err != nil {
+			acc.AddError(fmt.Errorf("gathering realm %q failed: %w", realm.Realm, err))
+		}
+	}
+
+	return nil
+}
+
+func (k *Keycloak) Stop() {
+	if k.client != nil {
+		k.client.CloseIdleConnections()
+	}
+}
+
+func (k *Keycloak) gatherRealm(acc telegraf.Accumulator, token string, realm realmInfo) error {
+	path := "/admin/realms/" + url.PathEscape(realm.Realm)
+
+	var stats []clientSessionStats
+	if err := k.getJSON(token, path+"/client-session-stats", &stats); err != nil {
+		return fmt.Errorf("querying session statistics failed: %w", err)
+	}
+
+	var active, offline int64
+	for _, s := range stats {
+		// Keycloak reports the session counts as strings
+		a, err := strconv.ParseInt(s.Active, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing active sessions of client %q failed: %w", s.ClientID, err)
+		}
+		o, err := strconv.ParseInt(s.Offline, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing offline sessions of client %q failed: %w", s.ClientID, err)
+		}
+		active += a
+		offline += o
+
+		acc.AddFields("keycloak_client",
+			map[string]interface{}{"active_sessions": a, "offline_sessions": o},
+			map[string]string{"url": k.URL, "realm": realm.Realm, "client_id": s.ClientID},
+		)
+	}
+
+	fields := map[string]interface{}{
+		"enabled":          realm.Enabled,
+		"active_sessions":  active,
+		"offline_sessions": offline,
+	}
+
+	if k.Events {
+		logins, failures, err := k.countEvents(token, realm.Realm)
+		if err != nil {
+			return fmt.Errorf("querying events failed: %w", err)
+		}
+		fields["logins"] = logins
+		fields["login_errors"] = failures
+	}
+
+	acc.AddFields("keycloak_realm", fields, map[string]string{"url": k.URL, "realm": realm.Realm})
+
+	return nil
+}
+
+// countEvents counts the login and login error events that occurred since
+// the last call. Keycloak returns the events ordered by time with the newest
+// event first, so we page through the events until reaching a known event.
+func (k *Keycloak) countEvents(token, realm string) (logins, failures int64, err error) {
+	last, found := k.lastEvent[realm]
+	if !found {
+		last = k.start
+	}
+	newest := last
+
+	params := url.Values{}
+	params.Add("type", "LOGIN")
+	params.Add("type", "LOGIN_ERROR")
+	params.Set("max", strconv.Itoa(eventPageSize))
+	path := "/admin/realms/" + url.PathEscape(realm) + "/events"
+	for first := 0; ; first += eventPageSize {
+		params.Set("first", strconv.Itoa(first))
+
+		var events []event
+		if err := k.getJSON(token, path+"?"+params.Encode(), &events); err != nil {
+			return 0, 0, err
+		}
+
+		for _, e := range events {
+			if e.Time <= last {
+				k.lastEvent[realm] = newest
+				return logins, failures, nil
+			}
+			newest = max(newest, e.Time)
+			switch e.Type {
+			case "LOGIN":
+				logins++
+			case "LOGIN_ERROR":
+				failures++
+			}
+		}
+
+		if len(events) < eventPageSize {
+			break
+		}
+	}
+	k.lastEvent[realm] = newest
+
+	return logins, failures, nil
+}
+
+func (k *Keycloak) requestToken() (string, error) {
+	params := url.Values{}
+	params.Set("client_id", k.ClientID)
+
+	if !k.ClientSecret.Empty() {
+		secret, err := k.ClientSecret.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting client secret failed: %w", err)
+		}
+		params.Set("client_secret", secret.String())
+		secret.Destroy()
+	}
+
+	if k.Username.Empty() {
+		params.Set("grant_type", "client_credentials")
+	} else {
+		username, err := k.Username.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting username failed: %w", err)
+		}
+		params.Set("username", username.String())
+		username.Destroy()
+
+		password, err := k.Password.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting password failed: %w", err)
+		}
+		params.Set("password", password.String())
+		password.Destroy()
+
+		params.Set("grant_type", "password")
+	}
+
+	address := k.URL + "/realms/" + url.PathEscape(k.AuthRealm) + "/protocol/openid-connect/token"
+	req, err := http.NewRequest("POST", address, strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", internal.ProductToken())
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return "", fmt.Errorf("received status %q: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var response tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("decoding response failed: %w", err)
+	}
+	if response.AccessToken == "" {
+		return "", errors.New("no access token received")
+	}
+
+	return response.AccessToken, nil
+}
+
+func (k *Keycloak) getJSON(token, path string, v interface{}) error {
+	req, err := http.NewRequest("GET", k.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", internal.ProductToken())
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status %q", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func init() {
+	inputs.Add("keycloak", func() telegraf.Input {
+		return &Keycloak{
+			Events: true,
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package keycloak
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	// Events are returned with the newest event first
+	events := []event{
+		{Time: 3000, Type: "LOGIN"},
+		{Time: 2500, Type: "LOGIN_ERROR"},
+		{Time: 2000, Type: "LOGIN"},
+		{Time: 1000, Type: "LOGIN"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/realms/master/protocol/openid-connect/token" {
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if err := r.ParseForm(); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.PostForm.Get("grant_type") != "password" || r.PostForm.Get("client_id") != "admin-cli" ||
+				r.PostForm.Get("username") != "admin" || r.PostForm.Get("password") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"token","expires_in":60}`)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/admin/realms":
+			fmt.Fprint(w, `[{"id":"1","realm":"master","enabled":true},{"id":"2","realm":"test","enabled":false}]`)
+		case "/admin/realms/master/client-session-stats":
+			fmt.Fprint(w, `[{"id":"a","clientId":"account","active":"2","offline":"1"},{"id":"b","clientId":"admin","active":"1","offline":"0"}]`)
+		case "/admin/realms/master/events":
+			if r.URL.Query()["type"][0] != "LOGIN" || r.URL.Query()["type"][1] != "LOGIN_ERROR" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			first, err := strconv.Atoi(r.URL.Query().Get("first"))
+			if err != nil || first > len(events) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if err := json.NewEncoder(w).Encode(events[first:]); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Keycloak{
+		URL:      server.URL,
+		Username: config.NewSecret([]byte("admin")),
+		Password: config.NewSecret([]byte("secret")),
+		Realms:   []string{"master"},
+		Events:   true,
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.start = 1500
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New("keycloak_token",
+			map[string]string{"url": server.URL, "realm": "master"},
+			map[string]interface{}{"response_time": float64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New("keycloak_client",
+			map[string]string{"url": server.URL, "realm": "master", "client_id": "account"},
+			map[string]interface{}{"active_sessions": int64(2), "offline_sessions": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New("keycloak_client",
+			map[string]string{"url": server.URL, "realm": "master", "client_id": "admin"},
+			map[string]interface{}{"active_sessions": int64(1), "offline_sessions": int64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New("keycloak_realm",
+			map[string]string{"url": server.URL, "realm": "master"},
+			map[string]interface{}{
+				"enabled":          true,
+				"active_sessions":  int64(3),
+				"offline_sessions": int64(1),
+				"logins":           int64(2),
+				"login_errors":     int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{testutil.IgnoreTime(), testutil.IgnoreFields("response_time")}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+
+	// Only new events should be counted in the next cycle
+	events = append([]event{{Time: 4000, Type: "LOGIN_ERROR"}}, events...)
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	realm := acc.GetTelegrafMetrics()[3]
+	logins, _ := realm.GetField("logins")
+	require.Equal(t, int64(0), logins)
+	failures, _ := realm.GetField("login_errors")
+	require.Equal(t, int64(1), failures)
+}
+
+func TestGatherClientCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/realms/admin/protocol/openid-connect/token":
+			if err := r.ParseForm(); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("client_id") != "telegraf" ||
+				r.PostForm.Get("client_secret") != "secret" || r.PostForm.Has("username") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"token"}`)
+		case "/admin/realms":
+			fmt.Fprint(w, `[]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Keycloak{
+		URL:          server.URL,
+		AuthRealm:    "admin",
+		ClientID:     "telegraf",
+		ClientSecret: config.NewSecret([]byte("secret")),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.True(t, acc.HasFloatField("keycloak_token", "response_time"))
+}
+
+func TestGatherTokenFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"invalid_client"}`)
+	}))
+	defer server.Close()
+
+	plugin := &Keycloak{
+		URL:          server.URL,
+		ClientSecret: config.NewSecret([]byte("secret")),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.ErrorContains(t, plugin.Gather(&acc), "invalid_client")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &Keycloak{URL: "http://localhost:8080"}
+	require.ErrorContains(t, plugin.Init(), "either 'username' or 'client_secret' required")
+
+	plugin = &Keycloak{ClientSecret: config.NewSecret([]byte("secret"))}
+	require.ErrorContains(t, plugin.Init(), "url required")
+}
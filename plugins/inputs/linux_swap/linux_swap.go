@@ -0,0 +1,495 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package linux_swap
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var availableMetrics = []string{"devices", "zram", "zswap", "pressure"}
+
+// Counters of /proc/vmstat reported as swap activity
+var vmstatCounters = []string{"pswpin", "pswpout", "zswpin", "zswpout", "zswpwb"}
+
+type LinuxSwap struct {
+	Metrics []string        `toml:"metrics"`
+	Log     telegraf.Logger `toml:"-"`
+
+	procPath  string
+	sysPath   string
+	lastStats map[string]blockStat
+}
+
+// blockStat holds the I/O counters of a block device backing a swap area
+type blockStat struct {
+	reads      uint64
+	readBytes  uint64
+	readTime   uint64
+	writes     uint64
+	writeBytes uint64
+	writeTime  uint64
+}
+
+// swapArea is an entry of /proc/swaps
+type swapArea struct {
+	filename string
+	kind     string
+	size     uint64
+	used     uint64
+	priority int64
+}
+
+func (*LinuxSwap) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *LinuxSwap) Init() error {
+	if len(l.Metrics) == 0 {
+		l.Metrics = availableMetrics
+	}
+	if err := choice.CheckSlice(l.Metrics, availableMetrics); err != nil {
+		return fmt.Errorf("config option 'metrics': %w", err)
+	}
+
+	l.procPath = internal.GetProcPath()
+	l.sysPath = internal.GetSysPath()
+	l.lastStats = make(map[string]blockStat)
+
+	return nil
+}
+
+func (l *LinuxSwap) Gather(acc telegraf.Accumulator) error {
+	// The swap areas are required for the latency estimation
+	var areas []swapArea
+	if choice.Contains("devices", l.Metrics) || choice.Contains("pressure", l.Metrics) {
+		var err error
+		if areas, err = readSwaps(filepath.Join(l.procPath, "swaps")); err != nil {
+			return fmt.Errorf("reading swap areas failed: %w", err)
+		}
+	}
+
+	// Read the block statistics once to keep the device and latency values
+	// consistent within one collection
+	current := make(map[string]blockStat, len(areas))
+	for _, area := range areas {
+		if stat, err := l.readBlockStat(area); err == nil {
+			current[area.filename] = stat
+		}
+	}
+
+	if choice.Contains("devices", l.Metrics) {
+		l.gatherDevices(acc, areas, current)
+	}
+	if choice.Contains("zram", l.Metrics) {
+		if err := l.gatherZram(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering zram statistics failed: %w", err))
+		}
+	}
+	if choice.Contains("zswap", l.Metrics) {
+		if err := l.gatherZswap(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering zswap statistics failed: %w", err))
+		}
+	}
+	if choice.Contains("pressure", l.Metrics) {
+		if err := l.gatherPressure(acc, current); err != nil {
+			acc.AddError(fmt.Errorf("gathering swap pressure failed: %w", err))
+		}
+	}
+
+	// Keep the block statistics of the current swap areas only
+	l.lastStats = current
+
+	return nil
+}
+
+// gatherDevices reports the usage of each swap area and the I/O of the
+// underlying block device if the area is a partition
+func (l *LinuxSwap) gatherDevices(acc telegraf.Accumulator, areas []swapArea, stats map[string]blockStat) {
+	for _, area := range areas {
+		tags := map[string]string{
+			"device": area.filename,
+			"type":   area.kind,
+		}
+		fields := map[string]interface{}{
+			"size":     area.size,
+			"used":     area.used,
+			"priority": area.priority,
+		}
+		if area.size > 0 {
+			fields["used_percent"] = 100 * float64(area.used) / float64(area.size)
+		}
+
+		if stat, found := stats[area.filename]; found {
+			fields["reads"] = stat.reads
+			fields["read_bytes"] = stat.readBytes
+			fields["read_time"] = stat.readTime
+			fields["writes"] = stat.writes
+			fields["write_bytes"] = stat.writeBytes
+			fields["write_time"] = stat.writeTime
+
+			if last, found := l.lastStats[area.filename]; found {
+				if stat.reads > last.reads {
+					fields["read_latency"] = float64(stat.readTime-last.readTime) / float64(stat.reads-last.reads)
+				}
+				if stat.writes > last.writes {
+					fields["write_latency"] = float64(stat.writeTime-last.writeTime) / float64(stat.writes-last.writes)
+				}
+			}
+		}
+
+		acc.AddFields("linux_swap_device", fields, tags)
+	}
+}
+
+// gatherZram reports the compression statistics of the zram devices
+func (l *LinuxSwap) gatherZram(acc telegraf.Accumulator) error {
+	devices, err := filepath.Glob(filepath.Join(l.sysPath, "block", "zram*"))
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range devices {
+		device := filepath.Base(dir)
+		fields, err := readZramStats(dir)
+		if err != nil {
+			acc.AddError(fmt.Errorf("reading statistics of %q failed: %w", device, err))
+			continue
+		}
+		// Unused devices are not initialized
+		if fields == nil {
+			continue
+		}
+
+		tags := map[string]string{"device": device}
+		if raw, err := os.ReadFile(filepath.Join(dir, "comp_algorithm")); err == nil {
+			tags["algorithm"] = selectedAlgorithm(string(raw))
+		}
+		acc.AddFields("linux_swap_zram", fields, tags)
+	}
+	return nil
+}
+
+func readZramStats(dir string) (map[string]interface{}, error) {
+	disksize, err := readUint(filepath.Join(dir, "disksize"))
+	if err != nil {
+		return nil, err
+	}
+	if disksize == 0 {
+		return nil, nil
+	}
+
+	// The memory statistics are available since kernel 4.7
+	values, err := readUintFields(filepath.Join(dir, "mm_stat"))
+	if err != nil {
+		return nil, err
+	}
+	names := []string{
+		"orig_data_size", "compr_data_size", "mem_used_total", "mem_limit", "mem_used_max",
+		"same_pages", "pages_compacted", "huge_pages", "huge_pages_since",
+	}
+	fields := map[string]interface{}{"disksize": disksize}
+	for i, v := range values {
+		if i < len(names) {
+			fields[names[i]] = v
+		}
+	}
+	if orig, compr := values[0], values[1]; compr > 0 {
+		fields["compression_ratio"] = float64(orig) / float64(compr)
+	}
+
+	values, err = readUintFields(filepath.Join(dir, "io_stat"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for i, name := range []string{"failed_reads", "failed_writes", "invalid_io", "notify_free"} {
+		if i < len(values) {
+			fields[name] = values[i]
+		}
+	}
+
+	return fields, nil
+}
+
+// gatherZswap reports the configuration and pool usage of zswap. Detailed
+// statistics are only available with access to debugfs.
+func (l *LinuxSwap) gatherZswap(acc telegraf.Accumulator) error {
+	params := filepath.Join(l.sysPath, "module", "zswap", "parameters")
+	enabled, err := os.ReadFile(filepath.Join(params, "enabled"))
+	if err != nil {
+		// zswap is not built into the kernel
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	fields := map[string]interface{}{
+		"enabled": strings.TrimSpace(string(enabled)) == "Y",
+	}
+	tags := make(map[string]string)
+	if raw, err := os.ReadFile(filepath.Join(params, "compressor")); err == nil {
+		tags["compressor"] = strings.TrimSpace(string(raw))
+	}
+	if v, err := readUint(filepath.Join(params, "max_pool_percent")); err == nil {
+		fields["max_pool_percent"] = v
+	}
+
+	// Pool usage is available in meminfo since kernel 5.19
+	meminfo, err := readKeyValues(filepath.Join(l.procPath, "meminfo"), ":")
+	if err != nil {
+		return err
+	}
+	if v, found := meminfo["Zswap"]; found {
+		fields["pool_size"] = v * 1024
+	}
+	if v, found := meminfo["Zswapped"]; found {
+		fields["stored_size"] = v * 1024
+		if pool := meminfo["Zswap"]; pool > 0 {
+			fields["compression_ratio"] = float64(v) / float64(pool)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(l.sysPath, "kernel", "debug", "zswap"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, fs.ErrPermission) {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if v, err := readUint(filepath.Join(l.sysPath, "kernel", "debug", "zswap", entry.Name())); err == nil {
+			fields[entry.Name()] = v
+		}
+	}
+
+	acc.AddFields("linux_swap_zswap", fields, tags)
+	return nil
+}
+
+// gatherPressure reports the swap activity, the memory pressure and the
+// latency of swap-ins and swap-outs estimated from the I/O of all swap
+// partitions since the last collection
+func (l *LinuxSwap) gatherPressure(acc telegraf.Accumulator, stats map[string]blockStat) error {
+	fields := make(map[string]interface{})
+
+	vmstat, err := readKeyValues(filepath.Join(l.procPath, "vmstat"), " ")
+	if err != nil {
+		return err
+	}
+	for _, name := range vmstatCounters {
+		if v, found := vmstat[name]; found {
+			fields[name] = v
+		}
+	}
+
+	// Pressure stall information is available since kernel 4.20
+	raw, err := os.ReadFile(filepath.Join(l.procPath, "pressure", "memory"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) == 0 {
+			continue
+		}
+		for _, part := range parts[1:] {
+			key, value, found := strings.Cut(part, "=")
+			if !found {
+				continue
+			}
+			name := "memory_" + parts[0] + "_" + key
+			if key == "total" {
+				v, err := strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					return fmt.Errorf("parsing %q failed: %w", name, err)
+				}
+				fields[name] = v
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("parsing %q failed: %w", name, err)
+			}
+			fields[name] = v
+		}
+	}
+
+	var reads, readTime, writes, writeTime uint64
+	for device, stat := range stats {
+		last, found := l.lastStats[device]
+		if !found || stat.reads < last.reads || stat.writes < last.writes {
+			continue
+		}
+		reads += stat.reads - last.reads
+		readTime += stat.readTime - last.readTime
+		writes += stat.writes - last.writes
+		writeTime += stat.writeTime - last.writeTime
+	}
+	if reads > 0 {
+		fields["swapin_latency"] = float64(readTime) / float64(reads)
+	}
+	if writes > 0 {
+		fields["swapout_latency"] = float64(writeTime) / float64(writes)
+	}
+
+	acc.AddFields("linux_swap", fields, nil)
+	return nil
+}
+
+// readBlockStat reads the I/O statistics of the block device of a swap
+// partition. Swap files do not have dedicated statistics.
+func (l *LinuxSwap) readBlockStat(area swapArea) (blockStat, error) {
+	if area.kind != "partition" {
+		return blockStat{}, errors.New("not a partition")
+	}
+
+	// Resolve device mapper names like /dev/mapper/swap to the kernel name
+	device := area.filename
+	if resolved, err := filepath.EvalSymlinks(device); err == nil {
+		device = resolved
+	}
+	values, err := readUintFields(filepath.Join(l.sysPath, "class", "block", filepath.Base(device), "stat"))
+	if err != nil {
+		return blockStat{}, err
+	}
+	if len(values) < 8 {
+		return blockStat{}, fmt.Errorf("unexpected number of statistics %d", len(values))
+	}
+
+	// Sectors are always 512 bytes independent of the device
+	return blockStat{
+		reads:      values[0],
+		readBytes:  values[2] * 512,
+		readTime:   values[3],
+		writes:     values[4],
+		writeBytes: values[6] * 512,
+		writeTime:  values[7],
+	}, nil
+}
+
+// readSwaps parses the swap areas in the format
+//
+//	Filename        Type        Size     Used  Priority
+//	/dev/sda2       partition   8388604  0     -2
+//
+// with sizes in kilobytes
+func readSwaps(fn string) ([]swapArea, error) {
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(raw), "\n")
+	areas := make([]swapArea, 0, len(lines))
+	for _, line := range lines[1:] {
+		parts := strings.Fields(line)
+		if len(parts) < 5 {
+			continue
+		}
+		size, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing size of %q failed: %w", parts[0], err)
+		}
+		used, err := strconv.ParseUint(parts[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing usage of %q failed: %w", parts[0], err)
+		}
+		priority, err := strconv.ParseInt(parts[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing priority of %q failed: %w", parts[0], err)
+		}
+		areas = append(areas, swapArea{
+			// Whitespace in filenames is escaped in octal notation
+			filename: strings.ReplaceAll(parts[0], `\040`, " "),
+			kind:     parts[1],
+			size:     size * 1024,
+			used:     used * 1024,
+			priority: priority,
+		})
+	}
+	return areas, nil
+}
+
+// selectedAlgorithm returns the algorithm in brackets of a list like
+// "lzo lzo-rle [lz4] zstd"
+func selectedAlgorithm(list string) string {
+	for _, algorithm := range strings.Fields(list) {
+		if strings.HasPrefix(algorithm, "[") && strings.HasSuffix(algorithm, "]") {
+			return strings.Trim(algorithm, "[]")
+		}
+	}
+	return strings.TrimSpace(list)
+}
+
+// readKeyValues parses files with lines in the format "<key><sep> <value>"
+// ignoring units and values not being numbers
+func readKeyValues(fn, sep string) (map[string]uint64, error) {
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]uint64)
+	for _, line := range strings.Split(string(raw), "\n") {
+		key, value, found := strings.Cut(line, sep)
+		if !found {
+			continue
+		}
+		parts := strings.Fields(value)
+		if len(parts) == 0 {
+			continue
+		}
+		if v, err := strconv.ParseUint(parts[0], 10, 64); err == nil {
+			values[strings.TrimSpace(key)] = v
+		}
+	}
+	return values, nil
+}
+
+func readUintFields(fn string) ([]uint64, error) {
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Fields(string(raw))
+	values := make([]uint64, 0, len(parts))
+	for _, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q failed: %w", fn, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func readUint(fn string) (uint64, error) {
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+}
+
+func init() {
+	inputs.Add("linux_swap", func() telegraf.Input {
+		return &LinuxSwap{}
+	})
+}
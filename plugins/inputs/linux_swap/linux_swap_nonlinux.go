@@ -0,0 +1,32 @@
+//go:build !linux
+
+package linux_swap
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type LinuxSwap struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*LinuxSwap) SampleConfig() string { return sampleConfig }
+
+func (l *LinuxSwap) Init() error {
+	l.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*LinuxSwap) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("linux_swap", func() telegraf.Input {
+		return &LinuxSwap{}
+	})
+}
@@ -0,0 +1,185 @@
+//go:build linux
+
+package linux_swap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalidMetrics(t *testing.T) {
+	plugin := &LinuxSwap{Metrics: []string{"devices", "foo"}}
+	require.ErrorContains(t, plugin.Init(), "config option 'metrics'")
+}
+
+func TestGather(t *testing.T) {
+	t.Setenv("HOST_PROC", "testdata/proc")
+	t.Setenv("HOST_SYS", "testdata/sys")
+
+	plugin := &LinuxSwap{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"linux_swap_device",
+			map[string]string{"device": "/dev/sda2", "type": "partition"},
+			map[string]interface{}{
+				"size":         uint64(8388604 * 1024),
+				"used":         uint64(2097152 * 1024),
+				"used_percent": float64(2097152) / float64(8388604) * 100,
+				"priority":     int64(-2),
+				"reads":        uint64(100),
+				"read_bytes":   uint64(1600 * 512),
+				"read_time":    uint64(200),
+				"writes":       uint64(400),
+				"write_bytes":  uint64(6400 * 512),
+				"write_time":   uint64(800),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"linux_swap_device",
+			map[string]string{"device": "/dev/zram0", "type": "partition"},
+			map[string]interface{}{
+				"size":         uint64(4194300 * 1024),
+				"used":         uint64(1048576 * 1024),
+				"used_percent": float64(1048576) / float64(4194300) * 100,
+				"priority":     int64(100),
+				"reads":        uint64(20000),
+				"read_bytes":   uint64(160000 * 512),
+				"read_time":    uint64(40),
+				"writes":       uint64(40000),
+				"write_bytes":  uint64(320000 * 512),
+				"write_time":   uint64(120),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"linux_swap_device",
+			map[string]string{"device": "/swap file", "type": "file"},
+			map[string]interface{}{
+				"size":         uint64(1048572 * 1024),
+				"used":         uint64(0),
+				"used_percent": float64(0),
+				"priority":     int64(-3),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"linux_swap_zram",
+			map[string]string{"device": "zram0", "algorithm": "zstd"},
+			map[string]interface{}{
+				"disksize":          uint64(4294967296),
+				"orig_data_size":    uint64(1073741824),
+				"compr_data_size":   uint64(268435456),
+				"mem_used_total":    uint64(285212672),
+				"mem_limit":         uint64(0),
+				"mem_used_max":      uint64(300000000),
+				"same_pages":        uint64(1024),
+				"pages_compacted":   uint64(8),
+				"huge_pages":        uint64(16),
+				"huge_pages_since":  uint64(32),
+				"compression_ratio": float64(4),
+				"failed_reads":      uint64(1),
+				"failed_writes":     uint64(2),
+				"invalid_io":        uint64(0),
+				"notify_free":       uint64(512),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"linux_swap_zswap",
+			map[string]string{"compressor": "zstd"},
+			map[string]interface{}{
+				"enabled":           true,
+				"max_pool_percent":  uint64(20),
+				"pool_size":         uint64(51200 * 1024),
+				"stored_size":       uint64(153600 * 1024),
+				"compression_ratio": float64(3),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"linux_swap",
+			map[string]string{},
+			map[string]interface{}{
+				"pswpin":             uint64(4096),
+				"pswpout":            uint64(8192),
+				"zswpin":             uint64(1024),
+				"zswpout":            uint64(2048),
+				"zswpwb":             uint64(16),
+				"memory_some_avg10":  float64(1.5),
+				"memory_some_avg60":  float64(0.75),
+				"memory_some_avg300": float64(0.25),
+				"memory_some_total":  uint64(123456),
+				"memory_full_avg10":  float64(0.5),
+				"memory_full_avg60":  float64(0.25),
+				"memory_full_avg300": float64(0.1),
+				"memory_full_total":  uint64(23456),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherLatency(t *testing.T) {
+	t.Setenv("HOST_PROC", "testdata/proc")
+	t.Setenv("HOST_SYS", "testdata/sys")
+
+	plugin := &LinuxSwap{Metrics: []string{"devices", "pressure"}}
+	require.NoError(t, plugin.Init())
+
+	// The first collection has no previous statistics
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.False(t, m.HasField("read_latency"))
+		require.False(t, m.HasField("swapin_latency"))
+	}
+
+	// Simulate previous activity on the disk partition only
+	plugin.lastStats["/dev/sda2"] = blockStat{reads: 90, readTime: 150, writes: 300, writeTime: 600}
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	var found int
+	for _, m := range acc.GetTelegrafMetrics() {
+		switch m.Name() {
+		case "linux_swap_device":
+			if device, _ := m.GetTag("device"); device == "/dev/sda2" {
+				require.Equal(t, map[string]interface{}{"read_latency": float64(5), "write_latency": float64(2)}, latencies(m, "read_latency", "write_latency"))
+				found++
+			} else {
+				require.False(t, m.HasField("read_latency"))
+				require.False(t, m.HasField("write_latency"))
+			}
+		case "linux_swap":
+			require.Equal(t, map[string]interface{}{"swapin_latency": float64(5), "swapout_latency": float64(2)}, latencies(m, "swapin_latency", "swapout_latency"))
+			found++
+		}
+	}
+	require.Equal(t, 2, found)
+}
+
+func latencies(m telegraf.Metric, keys ...string) map[string]interface{} {
+	values := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		if v, ok := m.GetField(k); ok {
+			values[k] = v
+		}
+	}
+	return values
+}
@@ -105,8 +105,9 @@ var (
 )
 
 type Mcrouter struct {
-	Servers []string        `toml:"servers"`
-	Timeout config.Duration `toml:"timeout"`
+	Servers            []string        `toml:"servers"`
+	Timeout            config.Duration `toml:"timeout"`
+	GatherDestinations bool            `toml:"gather_destinations"`
 }
 
 func (*Mcrouter) SampleConfig() string {
@@ -128,7 +129,7 @@ func (m *Mcrouter) Gather(acc telegraf.Accumulator) error {
 	}
 
 	for _, serverAddress := range m.Servers {
-		acc.AddError(gatherServer(ctx, serverAddress, acc))
+		acc.AddError(m.gatherServer(ctx, serverAddress, acc))
 	}
 
 	return nil
@@ -181,7 +182,7 @@ func parseAddress(address string) (parsedAddress, protocol string, err error) {
 	return parsedAddress, protocol, nil
 }
 
-func gatherServer(ctx context.Context, address string, acc telegraf.Accumulator) error {
+func (m *Mcrouter) gatherServer(ctx context.Context, address string, acc telegraf.Accumulator) error {
 	var conn net.Conn
 	var err error
 	var protocol string
@@ -244,9 +245,84 @@ func gatherServer(ctx context.Context, address string, acc telegraf.Accumulator)
 		}
 	}
 	acc.AddFields("mcrouter", fields, tags)
+
+	if !m.GatherDestinations {
+		return nil
+	}
+
+	// Get the breakdown by destination of the route handles
+	if _, err := fmt.Fprint(conn, "stats servers\r\n"); err != nil {
+		return err
+	}
+	destinations, err := parseResponse(scanner)
+	if err != nil {
+		return err
+	}
+	for key, value := range destinations {
+		destination, protocol := parseDestination(key)
+		destinationTags := map[string]string{
+			"server":      address,
+			"destination": destination,
+		}
+		if protocol != "" {
+			destinationTags["protocol"] = protocol
+		}
+		acc.AddFields("mcrouter_destination", parseDestinationStats(value), destinationTags)
+	}
+
 	return nil
 }
 
+// parseDestination splits the destination key of the form
+// "host:port:protocol:security:compression-timeout", e.g.
+// "10.0.0.1:11211:ascii:plain:notcompressed-1000", into the address and the
+// protocol of the destination
+func parseDestination(key string) (destination, protocol string) {
+	parts := strings.Split(key, ":")
+	for i := len(parts) - 1; i > 0; i-- {
+		// The timeout suffix follows the last part
+		protocol, _, _ = strings.Cut(parts[i], "-")
+		switch protocol {
+		case "ascii", "caret", "umbrella", "thrift":
+			return strings.Join(parts[:i], ":"), protocol
+		}
+	}
+	return key, ""
+}
+
+// parseDestinationStats parses the statistics of a destination, e.g.
+// "avg_latency_us:302.1 pending_reqs:0 inflight_reqs:0 up:5; found:20 notfound:3"
+// where the values before the semicolon are statistics and the number of
+// connections per state and the values after are the counts per result.
+func parseDestinationStats(value string) map[string]interface{} {
+	fields := make(map[string]interface{})
+
+	stats, results, _ := strings.Cut(value, ";")
+	for i, section := range []string{stats, results} {
+		for _, token := range strings.Fields(section) {
+			name, raw, found := strings.Cut(token, ":")
+			if !found || name == "" {
+				continue
+			}
+			switch {
+			case i == 1:
+				name = "result_" + name
+			case name == "avg_latency_us", name == "pending_reqs", name == "inflight_reqs", strings.HasSuffix(name, "_retrans_ratio"):
+			default:
+				name = "state_" + name
+			}
+
+			if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				fields[name] = v
+			} else if v, err := strconv.ParseFloat(raw, 64); err == nil {
+				fields[name] = v
+			}
+		}
+	}
+
+	return fields
+}
+
 func parseResponse(r *bufio.Scanner) (map[string]string, error) {
 	values := make(map[string]string)
 
@@ -3,6 +3,7 @@ package mcrouter
 import (
 	"bufio"
 	"fmt"
+	"net"
 	"os"
 	"testing"
 	"time"
@@ -11,6 +12,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -186,3 +189,106 @@ func TestMcrouterParseMetrics(t *testing.T) {
 		}
 	}
 }
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		key         string
+		destination string
+		protocol    string
+	}{
+		{"10.0.0.1:11211:ascii:plain:notcompressed-1000", "10.0.0.1:11211", "ascii"},
+		{"[::1]:5000:caret:ssl:compressed-500", "[::1]:5000", "caret"},
+		{"127.0.0.1:5000:ascii-1000", "127.0.0.1:5000", "ascii"},
+		{"unknown", "unknown", ""},
+	}
+	for _, tt := range tests {
+		destination, protocol := parseDestination(tt.key)
+		require.Equal(t, tt.destination, destination, tt.key)
+		require.Equal(t, tt.protocol, protocol, tt.key)
+	}
+}
+
+func TestGatherDestinations(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			switch scanner.Text() {
+			case "stats":
+				fmt.Fprint(conn, "STAT uptime 166\r\nSTAT num_servers 2\r\nEND\r\n")
+			case "stats servers":
+				fmt.Fprint(conn,
+					"STAT 10.0.0.1:11211:ascii:plain:notcompressed-1000 avg_latency_us:302.125 pending_reqs:0 "+
+						"inflight_reqs:1 avg_retrans_ratio:0.000000 max_retrans_ratio:0.000000 min_retrans_ratio:0.000000 "+
+						"up:4; found:20 notfound:3\r\n"+
+						"STAT 10.0.0.2:11211:ascii:plain:notcompressed-1000 avg_latency_us:0.000 pending_reqs:0 "+
+						"inflight_reqs:0 avg_retrans_ratio:0.000000 max_retrans_ratio:0.000000 min_retrans_ratio:0.000000 "+
+						"tko:4;\r\n"+
+						"END\r\n",
+				)
+				return
+			}
+		}
+	}()
+
+	plugin := &Mcrouter{
+		Servers:            []string{"tcp://" + listener.Addr().String()},
+		GatherDestinations: true,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	server := listener.Addr().String()
+	expected := []telegraf.Metric{
+		metric.New(
+			"mcrouter",
+			map[string]string{"server": server},
+			map[string]interface{}{
+				"uptime":      int64(166),
+				"num_servers": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mcrouter_destination",
+			map[string]string{"server": server, "destination": "10.0.0.1:11211", "protocol": "ascii"},
+			map[string]interface{}{
+				"avg_latency_us":    float64(302.125),
+				"pending_reqs":      int64(0),
+				"inflight_reqs":     int64(1),
+				"avg_retrans_ratio": float64(0),
+				"max_retrans_ratio": float64(0),
+				"min_retrans_ratio": float64(0),
+				"state_up":          int64(4),
+				"result_found":      int64(20),
+				"result_notfound":   int64(3),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mcrouter_destination",
+			map[string]string{"server": server, "destination": "10.0.0.2:11211", "protocol": "ascii"},
+			map[string]interface{}{
+				"avg_latency_us":    float64(0),
+				"pending_reqs":      int64(0),
+				"inflight_reqs":     int64(0),
+				"avg_retrans_ratio": float64(0),
+				"max_retrans_ratio": float64(0),
+				"min_retrans_ratio": float64(0),
+				"state_tko":         int64(4),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
@@ -3,6 +3,7 @@ package mem
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"runtime"
 
@@ -15,6 +16,8 @@ import (
 var sampleConfig string
 
 type Mem struct {
+	NUMAStats bool `toml:"numa_stats"`
+
 	ps       system.PS
 	platform string
 }
@@ -25,6 +28,9 @@ func (*Mem) SampleConfig() string {
 
 func (ms *Mem) Init() error {
 	ms.platform = runtime.GOOS
+	if ms.NUMAStats && !numaSupported {
+		return errors.New("NUMA statistics are only supported on Linux")
+	}
 	return nil
 }
 
@@ -96,6 +102,12 @@ func (ms *Mem) Gather(acc telegraf.Accumulator) error {
 
 	acc.AddGauge("mem", fields, nil)
 
+	if ms.NUMAStats {
+		if err := ms.gatherNUMA(acc); err != nil {
+			return fmt.Errorf("error getting NUMA info: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,184 @@
+package mem
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+const numaSupported = true
+
+// Mapping of the per-node meminfo entries to field names, following the naming
+// of the system-wide fields where applicable
+var numaMeminfoFields = map[string]string{
+	"MemTotal":       "total",
+	"MemFree":        "free",
+	"MemUsed":        "used",
+	"SwapCached":     "swap_cached",
+	"Active":         "active",
+	"Inactive":       "inactive",
+	"Active(anon)":   "active_anon",
+	"Inactive(anon)": "inactive_anon",
+	"Active(file)":   "active_file",
+	"Inactive(file)": "inactive_file",
+	"Unevictable":    "unevictable",
+	"Mlocked":        "mlocked",
+	"Dirty":          "dirty",
+	"Writeback":      "write_back",
+	"FilePages":      "file_pages",
+	"Mapped":         "mapped",
+	"AnonPages":      "anon_pages",
+	"Shmem":          "shared",
+	"KernelStack":    "kernel_stack",
+	"PageTables":     "page_tables",
+	"Slab":           "slab",
+	"SReclaimable":   "sreclaimable",
+	"SUnreclaim":     "sunreclaim",
+	"AnonHugePages":  "anon_huge_pages",
+}
+
+// gatherNUMA reports the memory usage, the allocation statistics and the
+// hugepage pools of each NUMA node
+func (*Mem) gatherNUMA(acc telegraf.Accumulator) error {
+	nodes, err := filepath.Glob(filepath.Join(internal.GetSysPath(), "devices", "system", "node", "node[0-9]*"))
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range nodes {
+		node := strings.TrimPrefix(filepath.Base(dir), "node")
+		if err := gatherNode(acc, dir, node); err != nil {
+			acc.AddError(fmt.Errorf("gathering NUMA node %s failed: %w", node, err))
+		}
+	}
+	return nil
+}
+
+func gatherNode(acc telegraf.Accumulator, dir, node string) error {
+	fields, err := readNodeMeminfo(filepath.Join(dir, "meminfo"))
+	if err != nil {
+		return err
+	}
+	if total, ok := fields["total"].(uint64); ok && total > 0 {
+		if used, ok := fields["used"].(uint64); ok {
+			fields["used_percent"] = 100 * float64(used) / float64(total)
+		}
+	}
+
+	// The allocation statistics are counted in pages
+	stats, err := readKeyValues(filepath.Join(dir, "numastat"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for k, v := range stats {
+		fields[k] = v
+	}
+	acc.AddFields("numa", fields, map[string]string{"node": node})
+
+	// Each supported hugepage size has a separate pool per node
+	pools, err := filepath.Glob(filepath.Join(dir, "hugepages", "hugepages-*"))
+	if err != nil {
+		return err
+	}
+	for _, pool := range pools {
+		size := strings.TrimPrefix(filepath.Base(pool), "hugepages-")
+		total, err := readUint(filepath.Join(pool, "nr_hugepages"))
+		if err != nil {
+			return err
+		}
+		free, err := readUint(filepath.Join(pool, "free_hugepages"))
+		if err != nil {
+			return err
+		}
+		surplus, err := readUint(filepath.Join(pool, "surplus_hugepages"))
+		if err != nil {
+			return err
+		}
+
+		fields := map[string]interface{}{
+			"huge_pages_total":   total,
+			"huge_pages_free":    free,
+			"huge_pages_surplus": surplus,
+		}
+		if total > 0 {
+			fields["huge_pages_used_percent"] = 100 * float64(total-free) / float64(total)
+		}
+		tags := map[string]string{
+			"node":           node,
+			"huge_page_size": size,
+		}
+		acc.AddFields("numa", fields, tags)
+	}
+
+	return nil
+}
+
+// readNodeMeminfo parses the per-node meminfo file in the format
+//
+//	Node 0 MemTotal:        6158152 kB
+//
+// returning the values in bytes
+func readNodeMeminfo(fn string) (map[string]interface{}, error) {
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{}, len(numaMeminfoFields))
+	for _, line := range strings.Split(string(raw), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) < 4 {
+			continue
+		}
+		name, found := numaMeminfoFields[strings.TrimSuffix(parts[2], ":")]
+		if !found {
+			continue
+		}
+		v, err := strconv.ParseUint(parts[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q of %q failed: %w", parts[2], fn, err)
+		}
+		if len(parts) > 4 && parts[4] == "kB" {
+			v *= 1024
+		}
+		fields[name] = v
+	}
+	return fields, nil
+}
+
+// readKeyValues parses files with lines in the format "<key> <value>"
+func readKeyValues(fn string) (map[string]interface{}, error) {
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	for _, line := range strings.Split(string(raw), "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), " ")
+		if !found {
+			continue
+		}
+		v, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q of %q failed: %w", key, fn, err)
+		}
+		values[key] = v
+	}
+	return values, nil
+}
+
+func readUint(fn string) (uint64, error) {
+	raw, err := os.ReadFile(fn)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+}
@@ -0,0 +1,101 @@
+package mem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs/system"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestNUMAStats(t *testing.T) {
+	t.Setenv("HOST_SYS", "testdata/sys")
+
+	var mps system.MockPS
+	mps.On("VMStat").Return(&mem.VirtualMemoryStat{Total: 100, Available: 50, Used: 50}, nil)
+
+	plugin := &Mem{ps: &mps, NUMAStats: true}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	// Check the metrics of the second node only
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if node, ok := m.GetTag("node"); ok && node == "1" {
+			actual = append(actual, m)
+		}
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"numa",
+			map[string]string{"node": "1"},
+			map[string]interface{}{
+				"total":           uint64(4194304 * 1024),
+				"free":            uint64(2097152 * 1024),
+				"used":            uint64(2097152 * 1024),
+				"used_percent":    float64(50),
+				"swap_cached":     uint64(0),
+				"active":          uint64(3698480 * 1024),
+				"inactive":        uint64(1780892 * 1024),
+				"active_anon":     uint64(20 * 1024),
+				"inactive_anon":   uint64(244600 * 1024),
+				"active_file":     uint64(3698460 * 1024),
+				"inactive_file":   uint64(1536292 * 1024),
+				"unevictable":     uint64(9524 * 1024),
+				"mlocked":         uint64(9552 * 1024),
+				"dirty":           uint64(4920 * 1024),
+				"write_back":      uint64(0),
+				"file_pages":      uint64(5243944 * 1024),
+				"mapped":          uint64(133952 * 1024),
+				"anon_pages":      uint64(245004 * 1024),
+				"shared":          uint64(9176 * 1024),
+				"kernel_stack":    uint64(1184 * 1024),
+				"page_tables":     uint64(2272 * 1024),
+				"slab":            uint64(391728 * 1024),
+				"sreclaimable":    uint64(342276 * 1024),
+				"sunreclaim":      uint64(49452 * 1024),
+				"anon_huge_pages": uint64(0),
+				"numa_hit":        uint64(1234),
+				"numa_miss":       uint64(12),
+				"numa_foreign":    uint64(0),
+				"interleave_hit":  uint64(1011),
+				"local_node":      uint64(1200),
+				"other_node":      uint64(34),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"numa",
+			map[string]string{"node": "1", "huge_page_size": "1048576kB"},
+			map[string]interface{}{
+				"huge_pages_total":   uint64(0),
+				"huge_pages_free":    uint64(0),
+				"huge_pages_surplus": uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"numa",
+			map[string]string{"node": "1", "huge_page_size": "2048kB"},
+			map[string]interface{}{
+				"huge_pages_total":        uint64(512),
+				"huge_pages_free":         uint64(128),
+				"huge_pages_surplus":      uint64(0),
+				"huge_pages_used_percent": float64(75),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Both nodes report the node statistics and two hugepage pools
+	require.Len(t, acc.GetTelegrafMetrics(), 1+2*3)
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package mem
+
+import (
+	"errors"
+
+	"github.com/influxdata/telegraf"
+)
+
+const numaSupported = false
+
+func (*Mem) gatherNUMA(telegraf.Accumulator) error {
+	return errors.New("NUMA statistics are only supported on Linux")
+}
@@ -6,6 +6,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"sync"
 	"time"
@@ -15,9 +16,12 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers/sparkplug_b"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -33,7 +37,9 @@ var (
 
 type MQTTConsumer struct {
 	Servers                []string             `toml:"servers"`
+	Protocol               string               `toml:"protocol"`
 	Topics                 []string             `toml:"topics"`
+	SharedGroup            string               `toml:"shared_subscription_group"`
 	TopicTag               *string              `toml:"topic_tag"`
 	TopicParserConfig      []topicParsingConfig `toml:"topic_parsing"`
 	Username               config.Secret        `toml:"username"`
@@ -46,24 +52,33 @@ type MQTTConsumer struct {
 	PersistentSession      bool                 `toml:"persistent_session"`
 	ClientTrace            bool                 `toml:"client_trace"`
 	ClientID               string               `toml:"client_id"`
+	SessionExpiry          config.Duration      `toml:"session_expiry"`
+	TopicAliasMaximum      uint16               `toml:"topic_alias_maximum"`
+	UserPropertyTags       []string             `toml:"user_property_tags"`
 	Log                    telegraf.Logger      `toml:"-"`
 	tls.ClientConfig
 
-	parser        telegraf.Parser
-	clientFactory clientFactory
-	client        client
-	opts          *mqtt.ClientOptions
-	acc           telegraf.TrackingAccumulator
-	sem           semaphore
-	messages      map[telegraf.TrackingID]mqtt.Message
-	messagesMutex sync.Mutex
-	topicTagParse string
-	topicParsers  []*topicParser
-	ctx           context.Context
-	cancel        context.CancelFunc
-	payloadSize   selfstat.Stat
-	messagesRecv  selfstat.Stat
-	wg            sync.WaitGroup
+	parser             telegraf.Parser
+	sparkplugParser    *sparkplug_b.Parser
+	clientFactory      clientFactory
+	clientFactoryV5    clientFactoryV5
+	client             client
+	opts               *mqtt.ClientOptions
+	optsV5             optionsV5
+	subscriptions      []string
+	userPropertyFilter filter.Filter
+	acc                telegraf.TrackingAccumulator
+	sem                semaphore
+	messages           map[telegraf.TrackingID]mqtt.Message
+	messagesMutex      sync.Mutex
+	topicTagParse      string
+	topicParsers       []*topicParser
+	ctx                context.Context
+	cancel             context.CancelFunc
+	payloadSize        selfstat.Stat
+	messagesRecv       selfstat.Stat
+	messagesExpired    selfstat.Stat
+	wg                 sync.WaitGroup
 }
 
 type client interface {
@@ -77,6 +92,7 @@ type client interface {
 type empty struct{}
 type semaphore chan empty
 type clientFactory func(o *mqtt.ClientOptions) client
+type clientFactoryV5 func(o *mqtt.ClientOptions, o5 optionsV5, log telegraf.Logger) client
 
 func (*MQTTConsumer) SampleConfig() string {
 	return sampleConfig
@@ -100,6 +116,47 @@ func (m *MQTTConsumer) Init() error {
 	if time.Duration(m.ConnectionTimeout) < 1*time.Second {
 		return fmt.Errorf("connection_timeout must be greater than 1s: %s", time.Duration(m.ConnectionTimeout))
 	}
+
+	switch m.Protocol {
+	case "", "3.1.1":
+		if m.SessionExpiry > 0 || m.TopicAliasMaximum > 0 || len(m.UserPropertyTags) > 0 {
+			return errors.New("session_expiry, topic_alias_maximum and user_property_tags require protocol 5")
+		}
+	case "5":
+		for _, server := range m.Servers {
+			if strings.HasPrefix(server, "ws://") || strings.HasPrefix(server, "wss://") {
+				return fmt.Errorf("websocket server %q is not supported with protocol 5", server)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported protocol %q: must be \"3.1.1\" or \"5\"", m.Protocol)
+	}
+
+	// Without an expiry interval the broker discards MQTT v5 sessions on
+	// disconnect, so persistent sessions never expire unless configured
+	if m.PersistentSession {
+		m.optsV5.sessionExpiry = math.MaxUint32
+		if m.SessionExpiry > 0 {
+			m.optsV5.sessionExpiry = uint32(min(time.Duration(m.SessionExpiry).Seconds(), math.MaxUint32-1))
+		}
+	}
+	m.optsV5.topicAliasMaximum = m.TopicAliasMaximum
+	m.optsV5.manualAck = m.PersistentSession
+
+	userPropertyFilter, err := filter.Compile(m.UserPropertyTags)
+	if err != nil {
+		return fmt.Errorf("compiling user_property_tags failed: %w", err)
+	}
+	m.userPropertyFilter = userPropertyFilter
+
+	m.subscriptions = make([]string, 0, len(m.Topics))
+	for _, topic := range m.Topics {
+		if m.SharedGroup != "" {
+			topic = "$share/" + m.SharedGroup + "/" + topic
+		}
+		m.subscriptions = append(m.subscriptions, topic)
+	}
+
 	m.topicTagParse = "topic"
 	if m.TopicTag != nil {
 		m.topicTagParse = *m.TopicTag
@@ -122,11 +179,20 @@ func (m *MQTTConsumer) Init() error {
 
 	m.payloadSize = selfstat.Register("mqtt_consumer", "payload_size", make(map[string]string))
 	m.messagesRecv = selfstat.Register("mqtt_consumer", "messages_received", make(map[string]string))
+	m.messagesExpired = selfstat.Register("mqtt_consumer", "messages_expired", make(map[string]string))
 	return nil
 }
 
 func (m *MQTTConsumer) SetParser(parser telegraf.Parser) {
 	m.parser = parser
+
+	// The Sparkplug B parser requires the topic of the message
+	if unwrapped, ok := parser.(*models.RunningParser); ok {
+		parser = unwrapped.Parser
+	}
+	if p, ok := parser.(*sparkplug_b.Parser); ok {
+		m.sparkplugParser = p
+	}
 }
 
 func (m *MQTTConsumer) Start(acc telegraf.Accumulator) error {
@@ -170,17 +236,28 @@ func (m *MQTTConsumer) Stop() {
 }
 
 func (m *MQTTConsumer) connect() error {
-	m.client = m.clientFactory(m.opts)
+	if m.Protocol == "5" {
+		m.client = m.clientFactoryV5(m.opts, m.optsV5, m.Log)
+	} else {
+		m.client = m.clientFactory(m.opts)
+	}
 	// AddRoute sets up the function for handling messages.  These need to be
 	// added in case we find a persistent session containing subscriptions so we
 	// know where to dispatch persisted and new messages to.  In the alternate
 	// case that we need to create the subscriptions these will be replaced.
-	for _, topic := range m.Topics {
+	for _, topic := range m.subscriptions {
 		m.client.AddRoute(topic, m.onMessage)
 	}
 	token := m.client.Connect()
 	if token.Wait() && token.Error() != nil {
-		if ct, ok := token.(*mqtt.ConnectToken); ok && ct.ReturnCode() == packets.ErrNetworkError {
+		var networkError bool
+		switch t := token.(type) {
+		case *mqtt.ConnectToken:
+			networkError = t.ReturnCode() == packets.ErrNetworkError
+		case *tokenV5:
+			networkError = t.networkError
+		}
+		if networkError {
 			// Network errors might be retryable, stop the metric-tracking
 			// goroutine and return a retryable error.
 			if m.cancel != nil {
@@ -206,13 +283,13 @@ func (m *MQTTConsumer) connect() error {
 		return nil
 	}
 	topics := make(map[string]byte)
-	for _, topic := range m.Topics {
+	for _, topic := range m.subscriptions {
 		topics[topic] = byte(m.QoS)
 	}
 	subscribeToken := m.client.SubscribeMultiple(topics, m.onMessage)
 	subscribeToken.Wait()
 	if subscribeToken.Error() != nil {
-		m.acc.AddError(fmt.Errorf("subscription error: topics %q: %w", strings.Join(m.Topics[:], ","), subscribeToken.Error()))
+		m.acc.AddError(fmt.Errorf("subscription error: topics %q: %w", strings.Join(m.subscriptions, ","), subscribeToken.Error()))
 	}
 	return nil
 }
@@ -250,7 +327,25 @@ func (m *MQTTConsumer) onMessage(_ mqtt.Client, msg mqtt.Message) {
 	m.payloadSize.Incr(int64(payloadBytes))
 	m.messagesRecv.Incr(1)
 
-	metrics, err := m.parser.Parse(msg.Payload())
+	// Drop MQTT v5 messages that expired while waiting for undelivered
+	// messages to be written
+	msgV5, isV5 := msg.(*messageV5)
+	if isV5 && msgV5.expired() {
+		m.messagesExpired.Incr(1)
+		if m.PersistentSession {
+			msg.Ack()
+		}
+		<-m.sem
+		return
+	}
+
+	var metrics []telegraf.Metric
+	var err error
+	if m.sparkplugParser != nil {
+		metrics, err = m.sparkplugParser.ParseMessage(msg.Topic(), msg.Payload())
+	} else {
+		metrics, err = m.parser.Parse(msg.Payload())
+	}
 	if err != nil || len(metrics) == 0 {
 		if len(metrics) == 0 {
 			once.Do(func() {
@@ -280,6 +375,13 @@ func (m *MQTTConsumer) onMessage(_ mqtt.Client, msg mqtt.Message) {
 				return
 			}
 		}
+		if isV5 && m.userPropertyFilter != nil {
+			for _, property := range msgV5.userProperties() {
+				if m.userPropertyFilter.Match(property.Key) {
+					metric.AddTag(property.Key, property.Value)
+				}
+			}
+		}
 	}
 	m.messagesMutex.Lock()
 	id := m.acc.AddTrackingMetricGroup(metrics)
@@ -355,6 +457,9 @@ func newMQTTConsumer(factory clientFactory) *MQTTConsumer {
 		KeepAliveInterval:      config.Duration(60 * time.Second),
 		PingTimeout:            config.Duration(10 * time.Second),
 		clientFactory:          factory,
+		clientFactoryV5: func(o *mqtt.ClientOptions, o5 optionsV5, log telegraf.Logger) client {
+			return newClientV5(o, o5, log)
+		},
 	}
 }
 func init() {
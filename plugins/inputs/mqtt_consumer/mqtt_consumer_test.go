@@ -2,19 +2,25 @@ package mqtt_consumer
 
 import (
 	"fmt"
+	"math"
 	"path/filepath"
 	"testing"
 	"time"
 
+	mqttv5 "github.com/eclipse/paho.golang/paho"
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/plugins/parsers/sparkplug_b"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -29,6 +35,9 @@ type fakeClient struct {
 	addRouteCallCount   int
 	disconnectCallCount int
 
+	routes  []string
+	filters map[string]byte
+
 	connected bool
 }
 
@@ -39,13 +48,15 @@ func (c *fakeClient) Connect() mqtt.Token {
 	return token
 }
 
-func (c *fakeClient) SubscribeMultiple(map[string]byte, mqtt.MessageHandler) mqtt.Token {
+func (c *fakeClient) SubscribeMultiple(filters map[string]byte, _ mqtt.MessageHandler) mqtt.Token {
 	c.subscribeCallCount++
+	c.filters = filters
 	return c.subscribeMultipleF()
 }
 
-func (c *fakeClient) AddRoute(_ string, callback mqtt.MessageHandler) {
+func (c *fakeClient) AddRoute(topic string, callback mqtt.MessageHandler) {
 	c.addRouteCallCount++
+	c.routes = append(c.routes, topic)
 	c.addRouteF(callback)
 }
 
@@ -162,8 +173,9 @@ func TestPersistentClientIDFail(t *testing.T) {
 }
 
 type message struct {
-	topic string
-	qos   byte
+	topic   string
+	qos     byte
+	payload []byte
 }
 
 func (*message) Duplicate() bool {
@@ -186,7 +198,10 @@ func (*message) MessageID() uint16 {
 	panic("not implemented")
 }
 
-func (*message) Payload() []byte {
+func (m *message) Payload() []byte {
+	if m.payload != nil {
+		return m.payload
+	}
 	return []byte("cpu time_idle=42i")
 }
 
@@ -569,6 +584,87 @@ func TestTopicTag(t *testing.T) {
 	}
 }
 
+func TestSparkplugB(t *testing.T) {
+	var handler mqtt.MessageHandler
+	fClient := &fakeClient{
+		connectF: func() mqtt.Token {
+			return &fakeToken{}
+		},
+		addRouteF: func(callback mqtt.MessageHandler) {
+			handler = callback
+		},
+		subscribeMultipleF: func() mqtt.Token {
+			return &fakeToken{}
+		},
+		disconnectF: func() {
+		},
+	}
+
+	plugin := newMQTTConsumer(func(*mqtt.ClientOptions) client {
+		return fClient
+	})
+	plugin.Log = testutil.Logger{}
+	plugin.Topics = []string{"spBv1.0/#"}
+	plugin.TopicTag = new(string)
+
+	parser, ok := parsers.Parsers["sparkplug_b"]("mqtt_consumer").(*sparkplug_b.Parser)
+	require.True(t, ok)
+	parser.Log = testutil.Logger{}
+	require.NoError(t, parser.Init())
+	plugin.SetParser(models.NewRunningParser(parser, &models.ParserConfig{DataFormat: "sparkplug_b"}))
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Payload with a single double metric with the given name and alias
+	sparkplugPayload := func(name string, alias uint64, value float64) []byte {
+		var data []byte
+		if name != "" {
+			data = protowire.AppendTag(data, 1, protowire.BytesType)
+			data = protowire.AppendString(data, name)
+		}
+		data = protowire.AppendTag(data, 2, protowire.VarintType)
+		data = protowire.AppendVarint(data, alias)
+		data = protowire.AppendTag(data, 4, protowire.VarintType)
+		data = protowire.AppendVarint(data, 10)
+		data = protowire.AppendTag(data, 13, protowire.Fixed64Type)
+		data = protowire.AppendFixed64(data, math.Float64bits(value))
+
+		var buf []byte
+		buf = protowire.AppendTag(buf, 1, protowire.VarintType)
+		buf = protowire.AppendVarint(buf, 1000)
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		return protowire.AppendBytes(buf, data)
+	}
+
+	handler(nil, &message{topic: "spBv1.0/plant/NBIRTH/node1", payload: sparkplugPayload("temperature", 1, 20.5)})
+	handler(nil, &message{topic: "spBv1.0/plant/NDATA/node1", payload: sparkplugPayload("", 1, 21.0)})
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"sparkplug_state",
+			map[string]string{"group_id": "plant", "edge_node_id": "node1"},
+			map[string]interface{}{"online": true},
+			time.UnixMilli(1000),
+		),
+		metric.New(
+			"mqtt_consumer",
+			map[string]string{"group_id": "plant", "edge_node_id": "node1"},
+			map[string]interface{}{"temperature": 20.5},
+			time.UnixMilli(1000),
+		),
+		metric.New(
+			"mqtt_consumer",
+			map[string]string{"group_id": "plant", "edge_node_id": "node1"},
+			map[string]interface{}{"temperature": 21.0},
+			time.UnixMilli(1000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
 func TestAddRouteCalledForEachTopic(t *testing.T) {
 	fClient := &fakeClient{
 		connectF: func() mqtt.Token {
@@ -655,6 +751,224 @@ func TestSubscribeNotCalledIfSession(t *testing.T) {
 	require.Equal(t, 0, fClient.subscribeCallCount)
 }
 
+func TestInitProtocol(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		servers  []string
+		modify   func(*MQTTConsumer)
+		expected string
+	}{
+		{
+			name:     "invalid protocol",
+			protocol: "4",
+			expected: `unsupported protocol "4"`,
+		},
+		{
+			name: "v5 options with v3",
+			modify: func(m *MQTTConsumer) {
+				m.UserPropertyTags = []string{"site"}
+			},
+			expected: "require protocol 5",
+		},
+		{
+			name:     "websocket with v5",
+			protocol: "5",
+			servers:  []string{"ws://127.0.0.1:8080"},
+			expected: "is not supported with protocol 5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newMQTTConsumer(nil)
+			plugin.Log = testutil.Logger{}
+			plugin.Protocol = tt.protocol
+			if tt.servers != nil {
+				plugin.Servers = tt.servers
+			}
+			if tt.modify != nil {
+				tt.modify(plugin)
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitSessionExpiry(t *testing.T) {
+	plugin := newMQTTConsumer(nil)
+	plugin.Log = testutil.Logger{}
+	plugin.Protocol = "5"
+	plugin.ClientID = "telegraf"
+	plugin.PersistentSession = true
+	require.NoError(t, plugin.Init())
+	require.Equal(t, uint32(math.MaxUint32), plugin.optsV5.sessionExpiry)
+	require.True(t, plugin.optsV5.manualAck)
+
+	plugin = newMQTTConsumer(nil)
+	plugin.Log = testutil.Logger{}
+	plugin.Protocol = "5"
+	plugin.ClientID = "telegraf"
+	plugin.PersistentSession = true
+	plugin.SessionExpiry = config.Duration(time.Hour)
+	require.NoError(t, plugin.Init())
+	require.Equal(t, uint32(3600), plugin.optsV5.sessionExpiry)
+}
+
+func TestSharedSubscription(t *testing.T) {
+	fClient := &fakeClient{
+		connectF: func() mqtt.Token {
+			return &fakeToken{}
+		},
+		addRouteF: func(mqtt.MessageHandler) {
+		},
+		subscribeMultipleF: func() mqtt.Token {
+			return &fakeToken{}
+		},
+		disconnectF: func() {
+		},
+	}
+	plugin := newMQTTConsumer(func(*mqtt.ClientOptions) client {
+		return fClient
+	})
+	plugin.Log = testutil.Logger{}
+	plugin.Topics = []string{"sensors/#", "telegraf/+/cpu"}
+	plugin.SharedGroup = "telegraf"
+	plugin.QoS = 1
+
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	plugin.Stop()
+
+	require.Equal(t, []string{"$share/telegraf/sensors/#", "$share/telegraf/telegraf/+/cpu"}, fClient.routes)
+	require.Equal(t, map[string]byte{"$share/telegraf/sensors/#": 1, "$share/telegraf/telegraf/+/cpu": 1}, fClient.filters)
+}
+
+func TestTopicAliasV5(t *testing.T) {
+	var topics []string
+	c := newClientV5(mqtt.NewClientOptions(), optionsV5{topicAliasMaximum: 10}, testutil.Logger{})
+	c.aliases = make(map[uint16]string)
+	c.AddRoute("sensors/#", func(_ mqtt.Client, msg mqtt.Message) {
+		topics = append(topics, msg.Topic())
+	})
+
+	alias := func(topic string, alias uint16) mqttv5.PublishReceived {
+		return mqttv5.PublishReceived{
+			Packet: &mqttv5.Publish{
+				Topic:      topic,
+				Properties: &mqttv5.PublishProperties{TopicAlias: &alias},
+			},
+		}
+	}
+
+	// Establish the alias and use it afterwards
+	_, err := c.onPublishReceived(alias("sensors/room1/temperature", 1))
+	require.NoError(t, err)
+	_, err = c.onPublishReceived(alias("", 1))
+	require.NoError(t, err)
+
+	// Redefine the alias
+	_, err = c.onPublishReceived(alias("sensors/room2/temperature", 1))
+	require.NoError(t, err)
+	_, err = c.onPublishReceived(alias("", 1))
+	require.NoError(t, err)
+
+	// Unknown aliases are dropped
+	_, err = c.onPublishReceived(alias("", 2))
+	require.NoError(t, err)
+
+	expected := []string{
+		"sensors/room1/temperature",
+		"sensors/room1/temperature",
+		"sensors/room2/temperature",
+		"sensors/room2/temperature",
+	}
+	require.Equal(t, expected, topics)
+}
+
+func TestMessageV5(t *testing.T) {
+	var handler mqtt.MessageHandler
+	fClient := &fakeClient{
+		connectF: func() mqtt.Token {
+			return &fakeToken{}
+		},
+		addRouteF: func(callback mqtt.MessageHandler) {
+			handler = callback
+		},
+		subscribeMultipleF: func() mqtt.Token {
+			return &fakeToken{}
+		},
+		disconnectF: func() {
+		},
+	}
+	plugin := newMQTTConsumer(nil)
+	plugin.clientFactoryV5 = func(*mqtt.ClientOptions, optionsV5, telegraf.Logger) client {
+		return fClient
+	}
+	plugin.Log = testutil.Logger{}
+	plugin.Protocol = "5"
+	plugin.Topics = []string{"telegraf"}
+	plugin.UserPropertyTags = []string{"site", "device_*"}
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	expiry := uint32(10)
+	properties := &mqttv5.PublishProperties{
+		MessageExpiry: &expiry,
+		User: mqttv5.UserProperties{
+			{Key: "site", Value: "CLE"},
+			{Key: "device_id", Value: "42"},
+			{Key: "owner", Value: "ops"},
+		},
+	}
+
+	// Valid message with user properties
+	handler(nil, &messageV5{
+		packet: &mqttv5.Publish{
+			Topic:      "telegraf",
+			Payload:    []byte("cpu time_idle=42i"),
+			Properties: properties,
+		},
+		topic:    "telegraf",
+		received: time.Now(),
+	})
+
+	// Message expired while waiting to be processed
+	handler(nil, &messageV5{
+		packet: &mqttv5.Publish{
+			Topic:      "telegraf",
+			Payload:    []byte("cpu time_idle=43i"),
+			Properties: properties,
+		},
+		topic:    "telegraf",
+		received: time.Now().Add(-time.Minute),
+	})
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"topic":     "telegraf",
+				"site":      "CLE",
+				"device_id": "42",
+			},
+			map[string]interface{}{
+				"time_idle": 42,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
 func TestIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -740,6 +1054,91 @@ func TestIntegration(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
 }
 
+func TestIntegrationV5(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	// Startup the container
+	conf, err := filepath.Abs(filepath.Join("testdata", "mosquitto.conf"))
+	require.NoError(t, err, "missing file mosquitto.conf")
+
+	const servicePort = "1883"
+	container := testutil.Container{
+		Image:        "eclipse-mosquitto:2",
+		ExposedPorts: []string{servicePort},
+		WaitingFor:   wait.ForListeningPort(servicePort),
+		Files: map[string]string{
+			"/mosquitto/config/mosquitto.conf": conf,
+		},
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	defer container.Terminate()
+
+	// Setup the plugin and connect to the broker using a shared subscription
+	url := fmt.Sprintf("tcp://%s:%s", container.Address, container.Ports[servicePort])
+	topic := "/telegraf/test"
+	plugin := newMQTTConsumer(nil)
+	plugin.Servers = []string{url}
+	plugin.Protocol = "5"
+	plugin.Topics = []string{topic}
+	plugin.SharedGroup = "telegraf"
+	plugin.QoS = 1
+	plugin.ClientID = "telegraf-v5-test"
+	plugin.PersistentSession = true
+	plugin.ConnectionTimeout = config.Duration(5 * time.Second)
+	plugin.KeepAliveInterval = config.Duration(1 * time.Second)
+	plugin.Log = testutil.Logger{Name: "mqtt-integration-test"}
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Setup a producer to send some metrics to the broker
+	cfg, err := plugin.createOpts()
+	require.NoError(t, err)
+	cfg.SetClientID("telegraf-v5-producer")
+	cfg.SetCleanSession(true)
+	client := mqtt.NewClient(cfg)
+	token := client.Connect()
+	token.Wait()
+	require.NoError(t, token.Error())
+	defer client.Disconnect(100)
+
+	metrics := []string{
+		"test,source=A value=0i 1712780301000000000",
+		"test,source=B value=1i 1712780301000000100",
+	}
+	expected := make([]telegraf.Metric, 0, len(metrics))
+	for _, x := range metrics {
+		metrics, err := parser.Parse([]byte(x))
+		require.NoError(t, err)
+		for i := range metrics {
+			metrics[i].AddTag("topic", topic)
+		}
+		expected = append(expected, metrics...)
+	}
+
+	for _, x := range metrics {
+		xtoken := client.Publish(topic, byte(plugin.QoS), false, []byte(x))
+		xtoken.Wait()
+		require.NoError(t, xtoken.Error())
+	}
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 3*time.Second, 100*time.Millisecond)
+
+	client.Disconnect(100)
+	plugin.Stop()
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
 func TestStartupErrorBehaviorErrorIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -0,0 +1,289 @@
+package mqtt_consumer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	mqttv5 "github.com/eclipse/paho.golang/paho"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/influxdata/telegraf"
+)
+
+// optionsV5 contains the settings only available in MQTT v5, all other
+// settings are taken from the v3 client options
+type optionsV5 struct {
+	sessionExpiry     uint32
+	topicAliasMaximum uint16
+	manualAck         bool
+}
+
+// clientV5 implements the client interface on top of the MQTT v5 library to
+// allow sharing the connection and subscription handling with v3.1.1
+type clientV5 struct {
+	opts    *mqtt.ClientOptions
+	optsV5  optionsV5
+	log     telegraf.Logger
+	client  *mqttv5.Client
+	handler mqtt.MessageHandler
+
+	// Topic aliases are only valid for the lifetime of a connection and
+	// messages are received sequentially, so no locking is required
+	aliases map[uint16]string
+
+	connected atomic.Bool
+}
+
+func newClientV5(opts *mqtt.ClientOptions, optsV5 optionsV5, log telegraf.Logger) *clientV5 {
+	return &clientV5{
+		opts:   opts,
+		optsV5: optsV5,
+		log:    log,
+	}
+}
+
+func (c *clientV5) Connect() mqtt.Token {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.ConnectTimeout)
+	defer cancel()
+
+	var conn net.Conn
+	var err error
+	for _, server := range c.opts.Servers {
+		conn, err = dialV5(ctx, server, c.opts.TLSConfig)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return &tokenV5{err: err, networkError: true}
+	}
+
+	c.aliases = make(map[uint16]string)
+	c.client = mqttv5.NewClient(mqttv5.ClientConfig{
+		ClientID:                   c.opts.ClientID,
+		Conn:                       conn,
+		OnPublishReceived:          []func(mqttv5.PublishReceived) (bool, error){c.onPublishReceived},
+		OnClientError:              c.onConnectionLost,
+		OnServerDisconnect:         c.onServerDisconnect,
+		EnableManualAcknowledgment: c.optsV5.manualAck,
+	})
+
+	connect := &mqttv5.Connect{
+		ClientID:     c.opts.ClientID,
+		KeepAlive:    uint16(c.opts.KeepAlive),
+		CleanStart:   c.opts.CleanSession,
+		Username:     c.opts.Username,
+		UsernameFlag: c.opts.Username != "",
+		Password:     []byte(c.opts.Password),
+		PasswordFlag: c.opts.Password != "",
+		Properties: &mqttv5.ConnectProperties{
+			SessionExpiryInterval: &c.optsV5.sessionExpiry,
+			TopicAliasMaximum:     &c.optsV5.topicAliasMaximum,
+		},
+	}
+	connack, err := c.client.Connect(ctx, connect)
+	if err != nil {
+		return &tokenV5{err: err}
+	}
+	c.connected.Store(true)
+
+	return &tokenV5{sessionPresent: connack.SessionPresent}
+}
+
+// SubscribeMultiple subscribes to the given topics. The message handler is
+// set via AddRoute before connecting, so the callback is not used.
+func (c *clientV5) SubscribeMultiple(filters map[string]byte, _ mqtt.MessageHandler) mqtt.Token {
+	subscribe := &mqttv5.Subscribe{
+		Subscriptions: make([]mqttv5.SubscribeOptions, 0, len(filters)),
+	}
+	for topic, qos := range filters {
+		subscribe.Subscriptions = append(subscribe.Subscriptions, mqttv5.SubscribeOptions{Topic: topic, QoS: qos})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.ConnectTimeout)
+	defer cancel()
+	_, err := c.client.Subscribe(ctx, subscribe)
+	return &tokenV5{err: err}
+}
+
+// AddRoute sets the handler for incoming messages and must be called before
+// connecting. All subscriptions share the same handler, so there is no need
+// to route by topic.
+func (c *clientV5) AddRoute(_ string, callback mqtt.MessageHandler) {
+	c.handler = callback
+}
+
+func (c *clientV5) Disconnect(quiesce uint) {
+	c.connected.Store(false)
+	if c.client == nil {
+		return
+	}
+
+	// Closing the client waits for the message handler to return, which
+	// might block while waiting for undelivered messages
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := c.client.Disconnect(&mqttv5.Disconnect{}); err != nil {
+			c.log.Debugf("Disconnecting failed: %v", err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Duration(quiesce) * time.Millisecond):
+	}
+}
+
+func (c *clientV5) IsConnected() bool {
+	return c.connected.Load()
+}
+
+func (c *clientV5) onPublishReceived(received mqttv5.PublishReceived) (bool, error) {
+	pb := received.Packet
+
+	topic := pb.Topic
+	if pb.Properties != nil && pb.Properties.TopicAlias != nil {
+		alias := *pb.Properties.TopicAlias
+		if topic != "" {
+			c.aliases[alias] = topic
+		} else if topic = c.aliases[alias]; topic == "" {
+			c.log.Errorf("Dropping message with unknown topic alias %d", alias)
+			if c.optsV5.manualAck {
+				if err := received.Client.Ack(pb); err != nil {
+					c.log.Errorf("Acknowledging message failed: %v", err)
+				}
+			}
+			return true, nil
+		}
+	}
+
+	if c.handler == nil {
+		return false, nil
+	}
+	c.handler(nil, &messageV5{
+		client:   received.Client,
+		packet:   pb,
+		topic:    topic,
+		received: time.Now(),
+	})
+	return true, nil
+}
+
+func (c *clientV5) onServerDisconnect(d *mqttv5.Disconnect) {
+	err := fmt.Errorf("disconnected by server with reason code %d", d.ReasonCode)
+	if d.Properties != nil && d.Properties.ReasonString != "" {
+		err = fmt.Errorf("disconnected by server: %s", d.Properties.ReasonString)
+	}
+	c.onConnectionLost(err)
+}
+
+func (c *clientV5) onConnectionLost(err error) {
+	// Errors of the client being disconnected on purpose are ignored
+	if !c.connected.CompareAndSwap(true, false) {
+		return
+	}
+	if c.opts.OnConnectionLost != nil {
+		c.opts.OnConnectionLost(nil, err)
+	}
+}
+
+func dialV5(ctx context.Context, server *url.URL, tlsCfg *tls.Config) (net.Conn, error) {
+	switch server.Scheme {
+	case "tcp", "mqtt":
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "tcp", server.Host)
+	case "ssl", "tls", "tcps", "mqtts":
+		dialer := tls.Dialer{Config: tlsCfg}
+		return dialer.DialContext(ctx, "tcp", server.Host)
+	}
+	return nil, fmt.Errorf("unsupported scheme %q", server.Scheme)
+}
+
+// tokenV5 is a completed token of an MQTT v5 operation
+type tokenV5 struct {
+	err            error
+	sessionPresent bool
+	networkError   bool
+}
+
+func (*tokenV5) Wait() bool {
+	return true
+}
+
+func (*tokenV5) WaitTimeout(time.Duration) bool {
+	return true
+}
+
+func (*tokenV5) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+func (t *tokenV5) Error() error {
+	return t.err
+}
+
+func (t *tokenV5) SessionPresent() bool {
+	return t.sessionPresent
+}
+
+// messageV5 wraps a received MQTT v5 message including its properties
+type messageV5 struct {
+	client   *mqttv5.Client
+	packet   *mqttv5.Publish
+	topic    string
+	received time.Time
+}
+
+func (m *messageV5) Duplicate() bool {
+	return m.packet.Duplicate()
+}
+
+func (m *messageV5) Qos() byte {
+	return m.packet.QoS
+}
+
+func (m *messageV5) Retained() bool {
+	return m.packet.Retain
+}
+
+func (m *messageV5) Topic() string {
+	return m.topic
+}
+
+func (m *messageV5) MessageID() uint16 {
+	return m.packet.PacketID
+}
+
+func (m *messageV5) Payload() []byte {
+	return m.packet.Payload
+}
+
+func (m *messageV5) Ack() {
+	// Errors only occur if manual acknowledgement is disabled or the
+	// connection is gone, in which case the message is redelivered anyway.
+	_ = m.client.Ack(m.packet)
+}
+
+func (m *messageV5) userProperties() mqttv5.UserProperties {
+	if m.packet.Properties == nil {
+		return nil
+	}
+	return m.packet.Properties.User
+}
+
+// expired checks if the message expiry interval elapsed since the message
+// was received, e.g. while waiting for undelivered messages to be written
+func (m *messageV5) expired() bool {
+	if m.packet.Properties == nil || m.packet.Properties.MessageExpiry == nil {
+		return false
+	}
+	expiry := time.Duration(*m.packet.Properties.MessageExpiry) * time.Second
+	return time.Since(m.received) >= expiry
+}
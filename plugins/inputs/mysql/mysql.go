@@ -29,11 +29,14 @@ var sampleConfig string
 
 var tlsRe = regexp.MustCompile(`([\?&])(?:tls=custom)($|&)`)
 
+var heartbeatTableRe = regexp.MustCompile(`^[A-Za-z0-9_$]+(\.[A-Za-z0-9_$]+)?$`)
+
 const (
 	defaultPerfEventsStatementsDigestTextLimit = 120
 	defaultPerfEventsStatementsLimit           = 250
 	defaultPerfEventsStatementsTimeLimit       = 86400
 	defaultGatherGlobalVars                    = true
+	defaultHeartbeatTable                      = "telegraf.heartbeat"
 	localhost                                  = ""
 )
 
@@ -52,6 +55,10 @@ type Mysql struct {
 	GatherAllSlaveChannels              bool             `toml:"gather_all_slave_channels"`
 	MariadbDialect                      bool             `toml:"mariadb_dialect"`
 	GatherBinaryLogs                    bool             `toml:"gather_binary_logs"`
+	GatherGroupReplication              bool             `toml:"gather_group_replication"`
+	GatherHeartbeatLag                  bool             `toml:"gather_heartbeat_lag"`
+	HeartbeatTable                      string           `toml:"heartbeat_table"`
+	HeartbeatWrite                      bool             `toml:"heartbeat_write"`
 	GatherTableIOWaits                  bool             `toml:"gather_table_io_waits"`
 	GatherTableLockWaits                bool             `toml:"gather_table_lock_waits"`
 	GatherIndexIOWaits                  bool             `toml:"gather_index_io_waits"`
@@ -87,6 +94,17 @@ func (m *Mysql) Init() error {
 	default:
 		m.getStatusQuery = slaveStatusQuery
 	}
+	// The heartbeat table name is used verbatim in the queries so make sure
+	// it cannot be abused for injecting arbitrary statements.
+	if m.GatherHeartbeatLag || m.HeartbeatWrite {
+		if m.HeartbeatTable == "" {
+			m.HeartbeatTable = defaultHeartbeatTable
+		}
+		if !heartbeatTableRe.MatchString(m.HeartbeatTable) {
+			return fmt.Errorf("invalid heartbeat table %q", m.HeartbeatTable)
+		}
+	}
+
 	// Default to localhost if nothing specified.
 	if len(m.Servers) == 0 {
 		s := config.NewSecret([]byte(localhost))
@@ -244,6 +262,9 @@ var (
 	}
 )
 
+// ER_NO_SUCH_TABLE error code returned by the server
+const errNoSuchTable = 1146
+
 // Math constants
 const (
 	picoSeconds = 1e12
@@ -429,6 +450,28 @@ const (
 	`
 )
 
+// replication heartbeat and group replication queries
+const (
+	readOnlyQuery              = `SELECT @@global.read_only`
+	heartbeatCreateSchemaQuery = `CREATE DATABASE IF NOT EXISTS %s`
+	heartbeatCreateQuery       = `
+        CREATE TABLE IF NOT EXISTS %s (
+            server_id INT UNSIGNED NOT NULL PRIMARY KEY,
+            ts DATETIME(6) NOT NULL
+        )`
+	heartbeatWriteQuery = `REPLACE INTO %s (server_id, ts) VALUES (@@server_id, UTC_TIMESTAMP(6))`
+	heartbeatReadQuery  = `
+        SELECT server_id, TIMESTAMPDIFF(MICROSECOND, ts, UTC_TIMESTAMP(6))
+        FROM %s
+        WHERE server_id <> @@server_id`
+	groupReplicationMembersQuery = `
+        SELECT *
+        FROM performance_schema.replication_group_members`
+	groupReplicationMemberStatsQuery = `
+        SELECT *
+        FROM performance_schema.replication_group_member_stats`
+)
+
 func (m *Mysql) gatherServer(server *config.Secret, acc telegraf.Accumulator) error {
 	dsnSecret, err := server.Get()
 	if err != nil {
@@ -488,6 +531,26 @@ func (m *Mysql) gatherServer(server *config.Secret, acc telegraf.Accumulator) er
 		}
 	}
 
+	// Replication setups differ between the servers of a topology, so do not
+	// abort gathering the remaining metrics if those fail on this server.
+	if m.HeartbeatWrite {
+		if err := m.writeHeartbeat(db); err != nil {
+			acc.AddError(fmt.Errorf("heartbeat on %q: %w", servtag, err))
+		}
+	}
+
+	if m.GatherHeartbeatLag {
+		if err := m.gatherHeartbeatLag(db, servtag, acc); err != nil {
+			acc.AddError(fmt.Errorf("heartbeat lag on %q: %w", servtag, err))
+		}
+	}
+
+	if m.GatherGroupReplication {
+		if err := gatherGroupReplication(db, servtag, acc); err != nil {
+			acc.AddError(fmt.Errorf("group replication on %q: %w", servtag, err))
+		}
+	}
+
 	if m.GatherInfoSchemaAutoInc {
 		err = m.gatherInfoSchemaAutoIncStatuses(db, servtag, acc)
 		if err != nil {
@@ -710,6 +773,181 @@ func (m *Mysql) gatherSlaveStatuses(db *sql.DB, servtag string, acc telegraf.Acc
 	return nil
 }
 
+// writeHeartbeat updates the heartbeat row of the server so replicas can
+// compute their lag from the replicated timestamp. Servers in read-only mode,
+// i.e. replicas, are skipped to allow using the same configuration for all
+// members of a replication topology.
+func (m *Mysql) writeHeartbeat(db *sql.DB) error {
+	var readOnly bool
+	if err := db.QueryRow(readOnlyQuery).Scan(&readOnly); err != nil {
+		return fmt.Errorf("checking read-only state failed: %w", err)
+	}
+	if readOnly {
+		return nil
+	}
+
+	query := fmt.Sprintf(heartbeatWriteQuery, m.HeartbeatTable)
+	_, err := db.Exec(query)
+
+	// Create the table on first use and retry
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errNoSuchTable {
+		if schema, _, found := strings.Cut(m.HeartbeatTable, "."); found {
+			if _, err := db.Exec(fmt.Sprintf(heartbeatCreateSchemaQuery, schema)); err != nil {
+				return fmt.Errorf("creating heartbeat schema failed: %w", err)
+			}
+		}
+		if _, err := db.Exec(fmt.Sprintf(heartbeatCreateQuery, m.HeartbeatTable)); err != nil {
+			return fmt.Errorf("creating heartbeat table failed: %w", err)
+		}
+		_, err = db.Exec(query)
+	}
+	if err != nil {
+		return fmt.Errorf("writing heartbeat failed: %w", err)
+	}
+	return nil
+}
+
+// gatherHeartbeatLag computes the replication lag as the age of the heartbeat
+// rows written by the upstream servers. In contrast to Seconds_Behind_Master
+// this reflects the true delay even for multi-level or stalled replication.
+func (m *Mysql) gatherHeartbeatLag(db *sql.DB, servtag string, acc telegraf.Accumulator) error {
+	rows, err := db.Query(fmt.Sprintf(heartbeatReadQuery, m.HeartbeatTable))
+	if err != nil {
+		// The table does not exist before the first heartbeat replicated
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errNoSuchTable {
+			return nil
+		}
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var serverID uint64
+		var lag int64
+		if err := rows.Scan(&serverID, &lag); err != nil {
+			return err
+		}
+
+		tags := map[string]string{
+			"server":           servtag,
+			"source_server_id": strconv.FormatUint(serverID, 10),
+		}
+		fields := map[string]interface{}{
+			"lag_seconds": float64(lag) / 1e6,
+		}
+		acc.AddFields("mysql_heartbeat", fields, tags)
+	}
+
+	return rows.Err()
+}
+
+// gatherGroupReplication collects the per-member statistics of a Group
+// Replication cluster together with the member's host, state and role.
+// The available columns vary between server versions, so all numeric columns
+// of the statistics table are exported as fields.
+func gatherGroupReplication(db *sql.DB, servtag string, acc telegraf.Accumulator) error {
+	members, err := queryRowsAsMap(db, groupReplicationMembersQuery)
+	if err != nil {
+		return err
+	}
+	memberInfo := make(map[string]map[string]string, len(members))
+	for _, member := range members {
+		memberInfo[member["member_id"]] = member
+	}
+
+	stats, err := queryRowsAsMap(db, groupReplicationMemberStatsQuery)
+	if err != nil {
+		return err
+	}
+
+	for _, stat := range stats {
+		memberID := stat["member_id"]
+		tags := map[string]string{
+			"server":    servtag,
+			"member_id": memberID,
+		}
+		if channel := stat["channel_name"]; channel != "" {
+			tags["channel"] = channel
+		}
+		fields := make(map[string]interface{}, len(stat))
+
+		if member, found := memberInfo[memberID]; found {
+			for _, key := range []string{"member_host", "member_port", "member_role"} {
+				if v := member[key]; v != "" {
+					tags[key] = v
+				}
+			}
+			if v := member["member_state"]; v != "" {
+				fields["member_state"] = v
+			}
+		}
+
+		for key, raw := range stat {
+			switch key {
+			case "channel_name", "member_id", "view_id",
+				"transactions_committed_all_members", "last_conflict_free_transaction":
+				// Skip identifiers and GTID sets
+				continue
+			}
+			if raw == "" {
+				continue
+			}
+			value, err := v2.ParseValue(sql.RawBytes(raw))
+			if err != nil {
+				acc.AddError(fmt.Errorf("error parsing group replication stat %q=%q: %w", key, raw, err))
+				continue
+			}
+			// Only keep numeric values, the remaining columns are timestamps
+			// or transaction identifiers
+			if _, isString := value.(string); isString {
+				continue
+			}
+			fields[key] = value
+		}
+		acc.AddFields("mysql_group_replication", fields, tags)
+	}
+
+	return nil
+}
+
+// queryRowsAsMap runs the given query and returns each row as a map of the
+// lower-cased column name to the string value. NULL values are returned as
+// empty strings.
+func queryRowsAsMap(db *sql.DB, query string) ([]map[string]string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := columnsToLower(rows.Columns())
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]string
+	for rows.Next() {
+		vals := make([]sql.RawBytes, len(cols))
+		valPtrs := make([]interface{}, len(cols))
+		for i := range vals {
+			valPtrs[i] = &vals[i]
+		}
+		if err := rows.Scan(valPtrs...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]string, len(cols))
+		for i, col := range cols {
+			row[col] = string(vals[i])
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
 // gatherBinaryLogs can be used to collect size and count of all binary files
 // binlogs metric requires the MySQL server to turn it on in configuration
 func gatherBinaryLogs(db *sql.DB, servtag string, acc telegraf.Accumulator) error {
@@ -1985,6 +2223,7 @@ func init() {
 			PerfEventsStatementsLimit:           defaultPerfEventsStatementsLimit,
 			PerfEventsStatementsTimeLimit:       defaultPerfEventsStatementsTimeLimit,
 			GatherGlobalVars:                    defaultGatherGlobalVars,
+			HeartbeatTable:                      defaultHeartbeatTable,
 		}
 	})
 }
@@ -9,10 +9,13 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/docker/go-connections/nat"
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -550,6 +553,155 @@ func TestGatherGlobalVariables(t *testing.T) {
 	}
 }
 
+func TestHeartbeatTableValidation(t *testing.T) {
+	m := &Mysql{
+		GatherHeartbeatLag: true,
+		HeartbeatTable:     "telegraf.heartbeat; DROP TABLE users",
+		Log:                testutil.Logger{},
+	}
+	require.ErrorContains(t, m.Init(), "invalid heartbeat table")
+
+	m = &Mysql{
+		GatherHeartbeatLag: true,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, m.Init())
+	require.Equal(t, defaultHeartbeatTable, m.HeartbeatTable)
+}
+
+func TestGatherHeartbeatLag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := &Mysql{
+		GatherHeartbeatLag: true,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, m.Init())
+
+	rows := sqlmock.NewRows([]string{"server_id", "lag"}).
+		AddRow(1, 1500000).
+		AddRow(2, 250)
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(heartbeatReadQuery, "telegraf.heartbeat"))).WillReturnRows(rows)
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.gatherHeartbeatLag(db, "127.0.0.1:3306", &acc))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"mysql_heartbeat",
+			map[string]string{"server": "127.0.0.1:3306", "source_server_id": "1"},
+			map[string]interface{}{"lag_seconds": float64(1.5)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mysql_heartbeat",
+			map[string]string{"server": "127.0.0.1:3306", "source_server_id": "2"},
+			map[string]interface{}{"lag_seconds": float64(0.00025)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherHeartbeatLagMissingTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := &Mysql{
+		GatherHeartbeatLag: true,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, m.Init())
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(heartbeatReadQuery, "telegraf.heartbeat"))).
+		WillReturnError(&mysql.MySQLError{Number: errNoSuchTable, Message: "Table 'telegraf.heartbeat' doesn't exist"})
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.gatherHeartbeatLag(db, "127.0.0.1:3306", &acc))
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestWriteHeartbeat(t *testing.T) {
+	m := &Mysql{
+		HeartbeatWrite: true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, m.Init())
+
+	t.Run("read-only", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(readOnlyQuery)).WillReturnRows(sqlmock.NewRows([]string{"read_only"}).AddRow(1))
+		require.NoError(t, m.writeHeartbeat(db))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("create missing table", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		write := regexp.QuoteMeta(fmt.Sprintf(heartbeatWriteQuery, "telegraf.heartbeat"))
+		mock.ExpectQuery(regexp.QuoteMeta(readOnlyQuery)).WillReturnRows(sqlmock.NewRows([]string{"read_only"}).AddRow(0))
+		mock.ExpectExec(write).WillReturnError(&mysql.MySQLError{Number: errNoSuchTable})
+		mock.ExpectExec("CREATE DATABASE IF NOT EXISTS telegraf").WillReturnResult(sqlmock.NewResult(1, 0))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS telegraf.heartbeat").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(write).WillReturnResult(sqlmock.NewResult(0, 1))
+		require.NoError(t, m.writeHeartbeat(db))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGatherGroupReplication(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	members := sqlmock.NewRows([]string{
+		"CHANNEL_NAME", "MEMBER_ID", "MEMBER_HOST", "MEMBER_PORT", "MEMBER_STATE", "MEMBER_ROLE", "MEMBER_VERSION",
+	}).AddRow("group_replication_applier", "uuid-1", "db1", "3306", "ONLINE", "PRIMARY", "8.0.36")
+	stats := sqlmock.NewRows([]string{
+		"CHANNEL_NAME", "VIEW_ID", "MEMBER_ID", "COUNT_TRANSACTIONS_IN_QUEUE", "COUNT_TRANSACTIONS_CHECKED",
+		"COUNT_CONFLICTS_DETECTED", "TRANSACTIONS_COMMITTED_ALL_MEMBERS", "LAST_CONFLICT_FREE_TRANSACTION",
+	}).AddRow("group_replication_applier", "1234:1", "uuid-1", "2", "100", "0", "aaaa:1-100", "aaaa:100")
+
+	mock.ExpectQuery(regexp.QuoteMeta(groupReplicationMembersQuery)).WillReturnRows(members)
+	mock.ExpectQuery(regexp.QuoteMeta(groupReplicationMemberStatsQuery)).WillReturnRows(stats)
+
+	var acc testutil.Accumulator
+	require.NoError(t, gatherGroupReplication(db, "127.0.0.1:3306", &acc))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"mysql_group_replication",
+			map[string]string{
+				"server":      "127.0.0.1:3306",
+				"channel":     "group_replication_applier",
+				"member_id":   "uuid-1",
+				"member_host": "db1",
+				"member_port": "3306",
+				"member_role": "PRIMARY",
+			},
+			map[string]interface{}{
+				"member_state":                "ONLINE",
+				"count_transactions_in_queue": int64(2),
+				"count_transactions_checked":  int64(100),
+				"count_conflicts_detected":    int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
 func TestNewNamespace(t *testing.T) {
 	testCases := []struct {
 		words     []string
@@ -0,0 +1,154 @@
+package nats_consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// jetStreamConfig contains the settings of a durable JetStream pull consumer
+type jetStreamConfig struct {
+	Stream         string          `toml:"stream"`
+	Durable        string          `toml:"durable"`
+	Subjects       []string        `toml:"subjects"`
+	CreateStream   bool            `toml:"create_stream"`
+	CreateConsumer bool            `toml:"create_consumer"`
+	DeliverPolicy  string          `toml:"deliver_policy"`
+	AckWait        config.Duration `toml:"ack_wait"`
+	MaxDeliver     int             `toml:"max_deliver"`
+	FetchBatchSize int             `toml:"fetch_batch_size"`
+	FetchTimeout   config.Duration `toml:"fetch_timeout"`
+}
+
+func (cfg *jetStreamConfig) init() error {
+	if cfg.Durable == "" {
+		if cfg.Stream != "" || len(cfg.Subjects) > 0 || cfg.CreateStream || cfg.CreateConsumer {
+			return errors.New("durable consumer name missing")
+		}
+		return nil
+	}
+
+	if cfg.Stream == "" {
+		return errors.New("stream name missing")
+	}
+	if cfg.CreateStream && len(cfg.Subjects) == 0 {
+		return errors.New("subjects required for creating the stream")
+	}
+
+	switch cfg.DeliverPolicy {
+	case "":
+		cfg.DeliverPolicy = "all"
+	case "all", "new", "last", "last_per_subject":
+	default:
+		return fmt.Errorf("invalid deliver_policy %q", cfg.DeliverPolicy)
+	}
+
+	if cfg.AckWait <= 0 {
+		cfg.AckWait = config.Duration(30 * time.Second)
+	}
+	if cfg.FetchBatchSize <= 0 {
+		cfg.FetchBatchSize = 100
+	}
+	if cfg.FetchTimeout <= 0 {
+		cfg.FetchTimeout = config.Duration(time.Second)
+	}
+
+	return nil
+}
+
+func (cfg *jetStreamConfig) deliverPolicy() nats.DeliverPolicy {
+	switch cfg.DeliverPolicy {
+	case "new":
+		return nats.DeliverNewPolicy
+	case "last":
+		return nats.DeliverLastPolicy
+	case "last_per_subject":
+		return nats.DeliverLastPerSubjectPolicy
+	}
+	return nats.DeliverAllPolicy
+}
+
+// subscribePull sets up the stream and the durable consumer if requested and
+// binds a pull subscription to the consumer
+func (n *NatsConsumer) subscribePull(js nats.JetStreamContext) (*nats.Subscription, error) {
+	cfg := &n.JetStream
+
+	if _, err := js.StreamInfo(cfg.Stream); err != nil {
+		if !errors.Is(err, nats.ErrStreamNotFound) || !cfg.CreateStream {
+			return nil, fmt.Errorf("getting stream %q failed: %w", cfg.Stream, err)
+		}
+		if _, err := js.AddStream(&nats.StreamConfig{Name: cfg.Stream, Subjects: cfg.Subjects}); err != nil {
+			return nil, fmt.Errorf("creating stream %q failed: %w", cfg.Stream, err)
+		}
+		n.Log.Infof("Created stream %q", cfg.Stream)
+	}
+
+	info, err := js.ConsumerInfo(cfg.Stream, cfg.Durable)
+	if err != nil {
+		if !errors.Is(err, nats.ErrConsumerNotFound) || !cfg.CreateConsumer {
+			return nil, fmt.Errorf("getting consumer %q failed: %w", cfg.Durable, err)
+		}
+		consumer := &nats.ConsumerConfig{
+			Durable:       cfg.Durable,
+			DeliverPolicy: cfg.deliverPolicy(),
+			AckPolicy:     nats.AckExplicitPolicy,
+			AckWait:       time.Duration(cfg.AckWait),
+			MaxDeliver:    cfg.MaxDeliver,
+			MaxAckPending: n.MaxUndeliveredMessages,
+		}
+		switch len(cfg.Subjects) {
+		case 0:
+		case 1:
+			consumer.FilterSubject = cfg.Subjects[0]
+		default:
+			consumer.FilterSubjects = cfg.Subjects
+		}
+		info, err = js.AddConsumer(cfg.Stream, consumer)
+		if err != nil {
+			return nil, fmt.Errorf("creating consumer %q failed: %w", cfg.Durable, err)
+		}
+		n.Log.Infof("Created consumer %q on stream %q", cfg.Durable, cfg.Stream)
+	}
+
+	// Binding requires the subject to match the filter of the consumer
+	return js.PullSubscribe(info.Config.FilterSubject, cfg.Durable, nats.Bind(cfg.Stream, cfg.Durable))
+}
+
+// fetch pulls batches of messages from the durable consumer until the context
+// is canceled. The messages are acknowledged once delivered to the outputs.
+func (n *NatsConsumer) fetch(ctx context.Context, sub *nats.Subscription) {
+	for {
+		fctx, cancel := context.WithTimeout(ctx, time.Duration(n.JetStream.FetchTimeout))
+		msgs, err := sub.Fetch(n.JetStream.FetchBatchSize, nats.Context(fctx))
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, nats.ErrTimeout) {
+			n.Log.Errorf("Fetching messages from consumer %q failed: %v", n.JetStream.Durable, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(n.JetStream.FetchTimeout)):
+			}
+			continue
+		}
+
+		for _, msg := range msgs {
+			select {
+			case <-ctx.Done():
+				return
+			case n.in <- msg:
+			}
+		}
+	}
+}
+
+func (n *NatsConsumer) isPulled(msg *nats.Msg) bool {
+	return n.pullSub != nil && msg.Sub == n.pullSub
+}
@@ -25,25 +25,33 @@ var (
 )
 
 type NatsConsumer struct {
-	QueueGroup             string          `toml:"queue_group"`
-	Subjects               []string        `toml:"subjects"`
-	Servers                []string        `toml:"servers"`
-	Secure                 bool            `toml:"secure"`
-	Username               string          `toml:"username"`
-	Password               string          `toml:"password"`
-	Credentials            string          `toml:"credentials"`
-	NkeySeed               string          `toml:"nkey_seed"`
-	JsSubjects             []string        `toml:"jetstream_subjects"`
-	PendingMessageLimit    int             `toml:"pending_message_limit"`
-	PendingBytesLimit      int             `toml:"pending_bytes_limit"`
-	MaxUndeliveredMessages int             `toml:"max_undelivered_messages"`
-	Log                    telegraf.Logger `toml:"-"`
+	QueueGroup             string                 `toml:"queue_group"`
+	Subjects               []string               `toml:"subjects"`
+	Servers                []string               `toml:"servers"`
+	Secure                 bool                   `toml:"secure"`
+	Username               string                 `toml:"username"`
+	Password               string                 `toml:"password"`
+	Credentials            string                 `toml:"credentials"`
+	NkeySeed               string                 `toml:"nkey_seed"`
+	JsSubjects             []string               `toml:"jetstream_subjects"`
+	PendingMessageLimit    int                    `toml:"pending_message_limit"`
+	PendingBytesLimit      int                    `toml:"pending_bytes_limit"`
+	MaxUndeliveredMessages int                    `toml:"max_undelivered_messages"`
+	SubjectTag             *string                `toml:"subject_tag"`
+	SubjectParsing         []subjectParsingConfig `toml:"subject_parsing"`
+	JetStream              jetStreamConfig        `toml:"jetstream"`
+	Log                    telegraf.Logger        `toml:"-"`
 	tls.ClientConfig
 
-	conn   *nats.Conn
-	jsConn nats.JetStreamContext
-	subs   []*nats.Subscription
-	jsSubs []*nats.Subscription
+	conn           *nats.Conn
+	jsConn         nats.JetStreamContext
+	subs           []*nats.Subscription
+	jsSubs         []*nats.Subscription
+	pullSub        *nats.Subscription
+	subjectParsers []*subjectParser
+
+	// pulled JetStream messages waiting for delivery to the outputs
+	pending map[telegraf.TrackingID]*nats.Msg
 
 	parser telegraf.Parser
 	// channel for all incoming NATS messages
@@ -79,6 +87,27 @@ func (n *NatsConsumer) SetParser(parser telegraf.Parser) {
 	n.parser = parser
 }
 
+func (n *NatsConsumer) Init() error {
+	if n.SubjectTag == nil {
+		tag := "subject"
+		n.SubjectTag = &tag
+	}
+
+	for i, cfg := range n.SubjectParsing {
+		p, err := cfg.newParser()
+		if err != nil {
+			return fmt.Errorf("subject_parsing %d: %w", i+1, err)
+		}
+		n.subjectParsers = append(n.subjectParsers, p)
+	}
+
+	if err := n.JetStream.init(); err != nil {
+		return fmt.Errorf("jetstream: %w", err)
+	}
+
+	return nil
+}
+
 // Start the nats consumer. Caller must call *NatsConsumer.Stop() to clean up.
 func (n *NatsConsumer) Start(acc telegraf.Accumulator) error {
 	n.acc = acc.WithTracking(n.MaxUndeliveredMessages)
@@ -142,7 +171,7 @@ func (n *NatsConsumer) Start(acc telegraf.Accumulator) error {
 			n.subs = append(n.subs, sub)
 		}
 
-		if len(n.JsSubjects) > 0 {
+		if len(n.JsSubjects) > 0 || n.JetStream.Durable != "" {
 			var connErr error
 			n.jsConn, connErr = n.conn.JetStream(nats.PublishAsyncMaxPending(256))
 			if connErr != nil {
@@ -167,6 +196,14 @@ func (n *NatsConsumer) Start(acc telegraf.Accumulator) error {
 					n.jsSubs = append(n.jsSubs, sub)
 				}
 			}
+
+			if n.JetStream.Durable != "" {
+				sub, err := n.subscribePull(n.jsConn)
+				if err != nil {
+					return err
+				}
+				n.pullSub = sub
+			}
 		}
 	}
 
@@ -174,14 +211,23 @@ func (n *NatsConsumer) Start(acc telegraf.Accumulator) error {
 	n.cancel = cancel
 
 	// Start the message reader
+	n.pending = make(map[telegraf.TrackingID]*nats.Msg)
 	n.wg.Add(1)
 	go func() {
 		defer n.wg.Done()
-		go n.receiver(ctx)
+		n.receiver(ctx)
 	}()
 
-	n.Log.Infof("Started the NATS consumer service, nats: %v, subjects: %v, jssubjects: %v, queue: %v",
-		n.conn.ConnectedUrl(), n.Subjects, n.JsSubjects, n.QueueGroup)
+	if n.pullSub != nil {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			n.fetch(ctx, n.pullSub)
+		}()
+	}
+
+	n.Log.Infof("Started the NATS consumer service, nats: %v, subjects: %v, jssubjects: %v, queue: %v, durable: %v",
+		n.conn.ConnectedUrl(), n.Subjects, n.JsSubjects, n.QueueGroup, n.JetStream.Durable)
 
 	return nil
 }
@@ -213,7 +259,8 @@ func (n *NatsConsumer) receiver(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case <-n.acc.Delivered():
+		case info := <-n.acc.Delivered():
+			n.onDelivery(info)
 			<-sem
 		case err := <-n.errs:
 			n.Log.Error(err)
@@ -224,13 +271,20 @@ func (n *NatsConsumer) receiver(ctx context.Context) {
 			case err := <-n.errs:
 				<-sem
 				n.Log.Error(err)
-			case <-n.acc.Delivered():
+			case info := <-n.acc.Delivered():
+				n.onDelivery(info)
 				<-sem
 				<-sem
 			case msg := <-n.in:
 				metrics, err := n.parser.Parse(msg.Data)
 				if err != nil {
 					n.Log.Errorf("Subject: %s, error: %s", msg.Subject, err.Error())
+					// Stop the redelivery of messages we are never able to parse
+					if n.isPulled(msg) {
+						if err := msg.Term(); err != nil {
+							n.Log.Errorf("Terminating message failed: %v", err)
+						}
+					}
 					<-sem
 					continue
 				}
@@ -240,14 +294,48 @@ func (n *NatsConsumer) receiver(ctx context.Context) {
 					})
 				}
 				for _, m := range metrics {
-					m.AddTag("subject", msg.Subject)
+					n.addSubjectTags(m, msg.Subject)
+				}
+				id := n.acc.AddTrackingMetricGroup(metrics)
+				if n.isPulled(msg) {
+					n.pending[id] = msg
 				}
-				n.acc.AddTrackingMetricGroup(metrics)
 			}
 		}
 	}
 }
 
+func (n *NatsConsumer) addSubjectTags(m telegraf.Metric, subject string) {
+	if *n.SubjectTag != "" {
+		m.AddTag(*n.SubjectTag, subject)
+	}
+	for _, p := range n.subjectParsers {
+		if p.parse(m, subject) {
+			return
+		}
+	}
+}
+
+// onDelivery acknowledges pulled JetStream messages once written to the
+// outputs; rejected messages are redelivered by the server
+func (n *NatsConsumer) onDelivery(info telegraf.DeliveryInfo) {
+	msg, found := n.pending[info.ID()]
+	if !found {
+		return
+	}
+	delete(n.pending, info.ID())
+
+	if info.Delivered() {
+		if err := msg.Ack(); err != nil {
+			n.Log.Errorf("Acknowledging message failed: %v", err)
+		}
+		return
+	}
+	if err := msg.Nak(); err != nil {
+		n.Log.Errorf("Negatively acknowledging message failed: %v", err)
+	}
+}
+
 func (n *NatsConsumer) clean() {
 	for _, sub := range n.subs {
 		if err := sub.Unsubscribe(); err != nil {
@@ -263,6 +351,15 @@ func (n *NatsConsumer) clean() {
 		}
 	}
 
+	// The subscription is bound to the durable consumer, so unsubscribing
+	// keeps the consumer on the server
+	if n.pullSub != nil {
+		if err := n.pullSub.Unsubscribe(); err != nil {
+			n.Log.Errorf("Error unsubscribing from consumer %s: %s", n.JetStream.Durable, err)
+		}
+		n.pullSub = nil
+	}
+
 	if n.conn != nil && !n.conn.IsClosed() {
 		n.conn.Close()
 	}
@@ -5,11 +5,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -38,6 +40,8 @@ func TestStartStop(t *testing.T) {
 		Log:                    testutil.Logger{},
 	}
 
+	require.NoError(t, plugin.Init())
+
 	var acc testutil.Accumulator
 	require.NoError(t, plugin.Start(&acc))
 	plugin.Stop()
@@ -149,6 +153,7 @@ func TestSendReceive(t *testing.T) {
 				MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
 				Log:                    testutil.Logger{},
 			}
+			require.NoError(t, plugin.Init())
 
 			// Add a line-protocol parser
 			parser := &influx.Parser{}
@@ -184,6 +189,211 @@ func TestSendReceive(t *testing.T) {
 	}
 }
 
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *NatsConsumer
+		expected string
+	}{
+		{
+			name:     "jetstream without durable",
+			plugin:   &NatsConsumer{JetStream: jetStreamConfig{Stream: "telegraf"}},
+			expected: "durable consumer name missing",
+		},
+		{
+			name:     "jetstream without stream",
+			plugin:   &NatsConsumer{JetStream: jetStreamConfig{Durable: "telegraf"}},
+			expected: "stream name missing",
+		},
+		{
+			name: "create stream without subjects",
+			plugin: &NatsConsumer{
+				JetStream: jetStreamConfig{Stream: "telegraf", Durable: "telegraf", CreateStream: true},
+			},
+			expected: "subjects required for creating the stream",
+		},
+		{
+			name: "invalid deliver policy",
+			plugin: &NatsConsumer{
+				JetStream: jetStreamConfig{Stream: "telegraf", Durable: "telegraf", DeliverPolicy: "first"},
+			},
+			expected: `invalid deliver_policy "first"`,
+		},
+		{
+			name: "subject parsing token mismatch",
+			plugin: &NatsConsumer{
+				SubjectParsing: []subjectParsingConfig{{Subject: "sensors.*", Tags: "_.site.device"}},
+			},
+			expected: "do not match the number of tokens",
+		},
+		{
+			name: "subject parsing multiple measurements",
+			plugin: &NatsConsumer{
+				SubjectParsing: []subjectParsingConfig{{Subject: "sensors.*", Measurement: "a.b"}},
+			},
+			expected: "must only select one token",
+		},
+		{
+			name: "subject parsing wildcard not last",
+			plugin: &NatsConsumer{
+				SubjectParsing: []subjectParsingConfig{{Subject: "sensors.>.foo"}},
+			},
+			expected: "'>' must be the last token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSubjectParsing(t *testing.T) {
+	tag := "topic"
+	plugin := &NatsConsumer{
+		SubjectTag: &tag,
+		SubjectParsing: []subjectParsingConfig{
+			{Subject: "sensors.*.*.>", Measurement: "_._.measurement._", Tags: "_.site._.device"},
+			{Subject: "sensors.>", Tags: "_.rest"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	tests := []struct {
+		subject  string
+		expected telegraf.Metric
+	}{
+		{
+			subject: "sensors.berlin.temperature.room1.desk",
+			expected: metric.New(
+				"temperature",
+				map[string]string{"topic": "sensors.berlin.temperature.room1.desk", "site": "berlin", "device": "room1.desk"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			subject: "sensors.berlin",
+			expected: metric.New(
+				"test",
+				map[string]string{"topic": "sensors.berlin", "rest": "berlin"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			subject: "other.berlin",
+			expected: metric.New(
+				"test",
+				map[string]string{"topic": "other.berlin"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			m := metric.New("test", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+			plugin.addSubjectTags(m, tt.subject)
+			testutil.RequireMetricEqual(t, tt.expected, m)
+		})
+	}
+}
+
+func TestJetStreamPull(t *testing.T) {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	defer srv.Shutdown()
+	require.True(t, srv.ReadyForConnections(5*time.Second))
+
+	plugin := &NatsConsumer{
+		Servers:                []string{srv.ClientURL()},
+		PendingBytesLimit:      nats.DefaultSubPendingBytesLimit,
+		PendingMessageLimit:    nats.DefaultSubPendingMsgsLimit,
+		MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		JetStream: jetStreamConfig{
+			Stream:         "metrics",
+			Durable:        "telegraf",
+			Subjects:       []string{"metrics.>"},
+			CreateStream:   true,
+			CreateConsumer: true,
+			AckWait:        config.Duration(time.Second),
+			FetchTimeout:   config.Duration(100 * time.Millisecond),
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+	plugin.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Publish the messages to the stream
+	conn, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+	js, err := conn.JetStream()
+	require.NoError(t, err)
+	_, err = js.Publish("metrics.a", []byte("test value=1i"))
+	require.NoError(t, err)
+	_, err = js.Publish("metrics.b", []byte("test value=2i"))
+	require.NoError(t, err)
+	_, err = js.Publish("metrics.c", []byte("invalid"))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"subject": "metrics.a"}, map[string]interface{}{"value": int64(1)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{"subject": "metrics.b"}, map[string]interface{}{"value": int64(2)}, time.Unix(0, 0)),
+	}
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 5*time.Second, 50*time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Nothing is acknowledged before the metrics are delivered
+	info, err := js.ConsumerInfo("metrics", "telegraf")
+	require.NoError(t, err)
+	require.Equal(t, 2, info.NumAckPending)
+
+	// Accept the first metric and reject the second one
+	metrics := acc.GetTelegrafMetrics()
+	for _, m := range metrics {
+		if v, _ := m.GetField("value"); v == int64(1) {
+			m.Accept()
+		} else {
+			m.Reject()
+		}
+	}
+
+	// The rejected message is redelivered
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 3
+	}, 5*time.Second, 50*time.Millisecond)
+	redelivered := acc.GetTelegrafMetrics()[2]
+	require.Equal(t, map[string]string{"subject": "metrics.b"}, redelivered.Tags())
+	redelivered.Accept()
+
+	require.Eventually(t, func() bool {
+		info, err := js.ConsumerInfo("metrics", "telegraf")
+		return err == nil && info.NumAckPending == 0 && info.AckFloor.Stream == 3
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
 type sender struct {
 	addr string
 	conn *nats.Conn
@@ -0,0 +1,106 @@
+package nats_consumer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+type subjectParsingConfig struct {
+	Subject     string `toml:"subject"`
+	Measurement string `toml:"measurement"`
+	Tags        string `toml:"tags"`
+}
+
+// subjectParser extracts the measurement and tags from the tokens of subjects
+// matching the configured pattern
+type subjectParser struct {
+	pattern     []string
+	measurement int
+	tags        map[int]string
+}
+
+func (cfg *subjectParsingConfig) newParser() (*subjectParser, error) {
+	if cfg.Subject == "" {
+		return nil, errors.New("subject pattern missing")
+	}
+	p := &subjectParser{
+		pattern:     strings.Split(cfg.Subject, "."),
+		measurement: -1,
+		tags:        make(map[int]string),
+	}
+	for i, token := range p.pattern {
+		if token == ">" && i != len(p.pattern)-1 {
+			return nil, fmt.Errorf("invalid subject pattern %q: '>' must be the last token", cfg.Subject)
+		}
+	}
+
+	if cfg.Measurement != "" {
+		tokens := strings.Split(cfg.Measurement, ".")
+		if len(tokens) != len(p.pattern) {
+			return nil, fmt.Errorf("measurement %q does not match the number of tokens of subject %q", cfg.Measurement, cfg.Subject)
+		}
+		for i, token := range tokens {
+			if token == "_" {
+				continue
+			}
+			if p.measurement >= 0 {
+				return nil, fmt.Errorf("measurement %q must only select one token", cfg.Measurement)
+			}
+			p.measurement = i
+		}
+	}
+
+	if cfg.Tags != "" {
+		tokens := strings.Split(cfg.Tags, ".")
+		if len(tokens) != len(p.pattern) {
+			return nil, fmt.Errorf("tags %q do not match the number of tokens of subject %q", cfg.Tags, cfg.Subject)
+		}
+		for i, token := range tokens {
+			if token != "_" {
+				p.tags[i] = token
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// match returns the tokens of the subject aligned to the pattern, the token
+// at a trailing '>' holds the remainder of the subject
+func (p *subjectParser) match(subject string) ([]string, bool) {
+	tokens := strings.Split(subject, ".")
+	if len(tokens) < len(p.pattern) {
+		return nil, false
+	}
+
+	for i, expected := range p.pattern {
+		switch expected {
+		case ">":
+			return append(tokens[:i], strings.Join(tokens[i:], ".")), true
+		case "*":
+		default:
+			if tokens[i] != expected {
+				return nil, false
+			}
+		}
+	}
+	return tokens, len(tokens) == len(p.pattern)
+}
+
+func (p *subjectParser) parse(m telegraf.Metric, subject string) bool {
+	tokens, ok := p.match(subject)
+	if !ok {
+		return false
+	}
+
+	if p.measurement >= 0 {
+		m.SetName(tokens[p.measurement])
+	}
+	for i, key := range p.tags {
+		m.AddTag(key, tokens[i])
+	}
+	return true
+}
@@ -24,10 +24,13 @@ var sampleConfig string
 type Net struct {
 	Interfaces          []string `toml:"interfaces"`
 	IgnoreProtocolStats bool     `toml:"ignore_protocol_stats"`
+	TopTalkers          bool     `toml:"top_talkers"`
+	TopTalkersLimit     int      `toml:"top_talkers_limit"`
 
 	filter     filter.Filter
 	ps         system.PS
 	skipChecks bool
+	talkers    *talkerProbe
 }
 
 func (*Net) SampleConfig() string {
@@ -45,9 +48,33 @@ func (n *Net) Init() error {
 		)
 	}
 
+	if n.TopTalkersLimit < 0 {
+		return fmt.Errorf("invalid top_talkers_limit %d", n.TopTalkersLimit)
+	}
+
+	return nil
+}
+
+func (n *Net) Start(telegraf.Accumulator) error {
+	if !n.TopTalkers {
+		return nil
+	}
+
+	talkers, err := newTalkerProbe()
+	if err != nil {
+		return fmt.Errorf("setting up top talkers failed: %w", err)
+	}
+	n.talkers = talkers
 	return nil
 }
 
+func (n *Net) Stop() {
+	if n.talkers != nil {
+		n.talkers.close()
+		n.talkers = nil
+	}
+}
+
 func (n *Net) Gather(acc telegraf.Accumulator) error {
 	netio, err := n.ps.NetIO()
 	if err != nil {
@@ -132,6 +159,15 @@ func (n *Net) Gather(acc telegraf.Accumulator) error {
 		acc.AddFields("net", fields, tags)
 	}
 
+	if n.talkers != nil {
+		talkers, err := n.talkers.read()
+		if err != nil {
+			acc.AddError(fmt.Errorf("reading top talkers failed: %w", err))
+		} else {
+			addTopTalkers(acc, talkers, n.TopTalkersLimit)
+		}
+	}
+
 	return nil
 }
 
@@ -153,6 +189,9 @@ func getInterfaceSpeed(ioName string) int64 {
 
 func init() {
 	inputs.Add("net", func() telegraf.Input {
-		return &Net{ps: system.NewSystemPS()}
+		return &Net{
+			TopTalkersLimit: 10,
+			ps:              system.NewSystemPS(),
+		}
 	})
 }
@@ -0,0 +1,61 @@
+package net
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// talker holds the bytes exchanged by a process with a destination address
+// during the collection interval
+type talker struct {
+	pid  uint32
+	dst  string
+	sent uint64
+	recv uint64
+}
+
+// topTalkers returns the given number of talkers with the most bytes
+// exchanged in descending order
+func topTalkers(talkers []talker, limit int) []talker {
+	sort.SliceStable(talkers, func(i, j int) bool {
+		ti := talkers[i].sent + talkers[i].recv
+		tj := talkers[j].sent + talkers[j].recv
+		if ti != tj {
+			return ti > tj
+		}
+		if talkers[i].pid != talkers[j].pid {
+			return talkers[i].pid < talkers[j].pid
+		}
+		return talkers[i].dst < talkers[j].dst
+	})
+	if limit > 0 && len(talkers) > limit {
+		talkers = talkers[:limit]
+	}
+	return talkers
+}
+
+func addTopTalkers(acc telegraf.Accumulator, talkers []talker, limit int) {
+	procPath := internal.GetProcPath()
+	for _, t := range topTalkers(talkers, limit) {
+		pid := strconv.FormatUint(uint64(t.pid), 10)
+		tags := map[string]string{
+			"pid": pid,
+			"dst": t.dst,
+		}
+		// The process might have terminated since the last collection
+		if comm, err := os.ReadFile(filepath.Join(procPath, pid, "comm")); err == nil {
+			tags["process"] = strings.TrimSpace(string(comm))
+		}
+		fields := map[string]interface{}{
+			"bytes_sent": t.sent,
+			"bytes_recv": t.recv,
+		}
+		acc.AddFields("net_top_talkers", fields, tags)
+	}
+}
@@ -0,0 +1,170 @@
+package net
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf/internal/ebpf"
+)
+
+// Maximum number of (process, destination) pairs tracked per interval
+const talkerMapEntries = 16384
+
+// talkerProbe represents the kprobe programs accounting the socket traffic
+// and their shared counter map
+type talkerProbe struct {
+	mapFD   int
+	progFDs []int
+	perfFDs []int
+}
+
+func newTalkerProbe() (*talkerProbe, error) {
+	pmuType, err := kprobePMUType()
+	if err != nil {
+		return nil, fmt.Errorf("kprobe PMU not available: %w", err)
+	}
+	kernelVersion, err := ebpf.KernelVersion()
+	if err != nil {
+		return nil, fmt.Errorf("determining kernel version failed: %w", err)
+	}
+
+	mapFD, err := ebpf.CreateMap(unix.BPF_MAP_TYPE_HASH, keySize, valueSize, talkerMapEntries)
+	if err != nil {
+		return nil, fmt.Errorf("creating counter map failed: %w", err)
+	}
+	p := &talkerProbe{mapFD: mapFD}
+
+	for _, kp := range talkerKprobes {
+		prog, err := generateProgram(kp, mapFD)
+		if err != nil {
+			p.close()
+			return nil, fmt.Errorf("generating program for %q failed: %w", kp.symbol, err)
+		}
+		// Kernels before 5.0 refuse kprobe programs not matching the running
+		// kernel version
+		progFD, err := ebpf.LoadProgram(unix.BPF_PROG_TYPE_KPROBE, prog, "telegraf_net", kernelVersion)
+		if err != nil {
+			p.close()
+			return nil, fmt.Errorf("loading program for %q failed: %w", kp.symbol, err)
+		}
+		p.progFDs = append(p.progFDs, progFD)
+
+		perfFD, err := attachKprobe(pmuType, kp.symbol, progFD)
+		if err != nil {
+			p.close()
+			return nil, fmt.Errorf("attaching to %q failed: %w", kp.symbol, err)
+		}
+		p.perfFDs = append(p.perfFDs, perfFD)
+	}
+
+	return p, nil
+}
+
+// read returns the traffic accounted since the last call and removes the
+// entries from the map. Traffic accounted between reading and removing an
+// entry is lost.
+func (p *talkerProbe) read() ([]talker, error) {
+	var keys [][keySize]byte
+	var key, next [keySize]byte
+	first := true
+	for len(keys) < talkerMapEntries {
+		var err error
+		if first {
+			err = ebpf.MapGetNextKey(p.mapFD, nil, next[:])
+			first = false
+		} else {
+			err = ebpf.MapGetNextKey(p.mapFD, key[:], next[:])
+		}
+		if errors.Is(err, unix.ENOENT) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iterating counter map failed: %w", err)
+		}
+		keys = append(keys, next)
+		key = next
+	}
+
+	talkers := make([]talker, 0, len(keys))
+	value := make([]byte, valueSize)
+	for _, k := range keys {
+		if err := ebpf.MapLookup(p.mapFD, k[:], value); err != nil {
+			if errors.Is(err, unix.ENOENT) {
+				continue
+			}
+			return nil, fmt.Errorf("reading counter map failed: %w", err)
+		}
+		if err := ebpf.MapDelete(p.mapFD, k[:]); err != nil && !errors.Is(err, unix.ENOENT) {
+			return nil, fmt.Errorf("resetting counter map failed: %w", err)
+		}
+		talkers = append(talkers, talker{
+			pid:  binary.NativeEndian.Uint32(k[0:4]),
+			dst:  net.IP(k[4:8]).String(),
+			sent: binary.NativeEndian.Uint64(value[0:8]),
+			recv: binary.NativeEndian.Uint64(value[8:16]),
+		})
+	}
+	return talkers, nil
+}
+
+// close detaches and unloads the programs
+func (p *talkerProbe) close() {
+	for _, fd := range p.perfFDs {
+		unix.Close(fd)
+	}
+	for _, fd := range p.progFDs {
+		unix.Close(fd)
+	}
+	unix.Close(p.mapFD)
+	p.perfFDs, p.progFDs = nil, nil
+}
+
+// attachKprobe creates a kprobe for the kernel function via the perf event
+// interface and attaches the program. The kprobe is removed when closing the
+// returned file descriptor.
+func attachKprobe(pmuType uint32, symbol string, progFD int) (int, error) {
+	name, err := unix.BytePtrFromString(symbol)
+	if err != nil {
+		return -1, err
+	}
+	attr := unix.PerfEventAttr{
+		Type: pmuType,
+		Ext1: uint64(uintptr(unsafe.Pointer(name))),
+	}
+	attr.Size = uint32(unsafe.Sizeof(attr))
+
+	fd, err := unix.PerfEventOpen(&attr, -1, 0, -1, unix.PERF_FLAG_FD_CLOEXEC)
+	runtime.KeepAlive(name)
+	if err != nil {
+		return -1, err
+	}
+	if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_SET_BPF, progFD); err != nil {
+		unix.Close(fd)
+		return -1, err
+	}
+	if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_ENABLE, 0); err != nil {
+		unix.Close(fd)
+		return -1, err
+	}
+	return fd, nil
+}
+
+// kprobePMUType returns the dynamic type of the kprobe performance
+// monitoring unit (Linux 4.17 and later)
+func kprobePMUType() (uint32, error) {
+	buf, err := os.ReadFile("/sys/bus/event_source/devices/kprobe/type")
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(buf)), 10, 32)
+	return uint32(v), err
+}
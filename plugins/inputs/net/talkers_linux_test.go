@@ -0,0 +1,43 @@
+package net
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf/internal/ebpf"
+)
+
+func TestProgramLoad(t *testing.T) {
+	if _, found := argumentOffsets[runtime.GOARCH]; !found {
+		t.Skipf("Skipping test as architecture %q is not supported", runtime.GOARCH)
+	}
+
+	mapFD, err := ebpf.CreateMap(unix.BPF_MAP_TYPE_HASH, keySize, valueSize, talkerMapEntries)
+	if errors.Is(err, unix.EPERM) {
+		t.Skip("Skipping test as loading BPF programs requires CAP_BPF")
+	}
+	require.NoError(t, err)
+	defer unix.Close(mapFD)
+
+	version, err := ebpf.KernelVersion()
+	require.NoError(t, err)
+
+	// Make sure the verifier accepts the programs
+	for _, kp := range talkerKprobes {
+		prog, err := generateProgram(kp, mapFD)
+		require.NoError(t, err)
+		progFD, err := ebpf.LoadProgram(unix.BPF_PROG_TYPE_KPROBE, prog, "telegraf_net", version)
+		require.NoError(t, err, kp.symbol)
+		unix.Close(progFD)
+	}
+
+	// The map is empty without attaching the programs
+	p := &talkerProbe{mapFD: mapFD}
+	talkers, err := p.read()
+	require.NoError(t, err)
+	require.Empty(t, talkers)
+}
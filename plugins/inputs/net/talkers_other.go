@@ -0,0 +1,15 @@
+//go:build !linux
+
+package net
+
+import "errors"
+
+type talkerProbe struct{}
+
+func newTalkerProbe() (*talkerProbe, error) {
+	return nil, errors.New("top talkers are only supported on Linux")
+}
+
+func (*talkerProbe) read() ([]talker, error) { return nil, nil }
+
+func (*talkerProbe) close() {}
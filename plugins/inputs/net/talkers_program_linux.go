@@ -0,0 +1,137 @@
+package net
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/influxdata/telegraf/internal/ebpf"
+)
+
+// Constants of the kprobe programs, see include/uapi/linux/bpf.h and
+// include/linux/socket.h
+const (
+	flagNoExist = 1
+	familyINET  = 2
+
+	registerContext  = 6
+	registerSock     = 7
+	registerBytes    = 8
+	registerFramePtr = ebpf.RegisterFramePtr
+)
+
+// Offsets in struct sock_common which are stable across kernel versions
+const (
+	sockDaddrOffset  = 0
+	sockFamilyOffset = 16
+)
+
+// Stack layout of the program relative to the frame pointer. The map key
+// consists of the process ID followed by the IPv4 destination address, the
+// value of the bytes sent followed by the bytes received.
+const (
+	stackFamily = -8
+	stackKey    = -16
+	stackDaddr  = -12
+	stackValue  = -32
+	keySize     = 8
+	valueSize   = 16
+)
+
+// Offsets of the first three function arguments in struct pt_regs
+var argumentOffsets = map[string][3]int16{
+	"amd64": {112, 104, 96},
+	"arm64": {0, 8, 16},
+}
+
+// kprobe describes a kernel function accounting the bytes of a TCP socket
+type kprobe struct {
+	symbol string
+	// Argument holding the number of bytes and whether it is a 32-bit int
+	bytesArg int
+	bytes32  bool
+	// Offset of the counter in the map value
+	valueOffset int16
+}
+
+var talkerKprobes = []kprobe{
+	// int tcp_sendmsg(struct sock *sk, struct msghdr *msg, size_t size)
+	{symbol: "tcp_sendmsg", bytesArg: 2, valueOffset: 0},
+	// void tcp_cleanup_rbuf(struct sock *sk, int copied)
+	{symbol: "tcp_cleanup_rbuf", bytesArg: 1, bytes32: true, valueOffset: 8},
+}
+
+// generateProgram creates a kprobe program adding the number of bytes passed
+// to the probed function to the counter of the calling process and the
+// destination of the IPv4 socket in the map referenced by the file descriptor
+func generateProgram(probe kprobe, mapFD int) ([]byte, error) {
+	args, found := argumentOffsets[runtime.GOARCH]
+	if !found {
+		return nil, fmt.Errorf("architecture %q is not supported", runtime.GOARCH)
+	}
+
+	a := ebpf.NewAssembler()
+
+	// probeRead copies the given number of bytes at the offset of the socket
+	// to the stack and leaves on error
+	probeRead := func(stackOffset int16, size, sockOffset int32) {
+		a.MovReg(1, registerFramePtr)
+		a.ALUImm(ebpf.OpAdd, 1, int32(stackOffset))
+		a.MovImm(2, size)
+		a.MovReg(3, registerSock)
+		a.ALUImm(ebpf.OpAdd, 3, sockOffset)
+		a.Call(ebpf.FuncProbeRead)
+		a.JumpImm(ebpf.JmpJNE, 0, 0, "out")
+	}
+
+	// Initialize the stack area as the verifier rejects reading
+	// uninitialized memory
+	for offset := int16(-8); offset >= stackValue; offset -= 8 {
+		a.StoreImm(ebpf.SizeDW, registerFramePtr, offset, 0)
+	}
+
+	// Read the arguments and skip calls without data or with errors
+	a.MovReg(registerContext, 1)
+	a.Load(ebpf.SizeDW, registerSock, registerContext, args[0])
+	a.Load(ebpf.SizeDW, registerBytes, registerContext, args[probe.bytesArg])
+	if probe.bytes32 {
+		a.ALUImm(ebpf.OpLsh, registerBytes, 32)
+		a.ALUImm(ebpf.OpArsh, registerBytes, 32)
+	}
+	a.JumpImm(ebpf.JmpJSLE, registerBytes, 0, "out")
+
+	// Only IPv4 sockets are accounted
+	probeRead(stackFamily, 2, sockFamilyOffset)
+	a.Load(ebpf.SizeH, 1, registerFramePtr, stackFamily)
+	a.JumpImm(ebpf.JmpJNE, 1, familyINET, "out")
+
+	// Build the key from the process ID and the destination address
+	a.Call(ebpf.FuncGetCurrentPidTgid)
+	a.ALUImm(ebpf.OpRsh, 0, 32)
+	a.Store(ebpf.SizeW, registerFramePtr, 0, stackKey)
+	probeRead(stackDaddr, 4, sockDaddrOffset)
+
+	// Update the existing counter atomically or insert a new entry
+	a.LoadMapFD(1, mapFD)
+	a.MovReg(2, registerFramePtr)
+	a.ALUImm(ebpf.OpAdd, 2, stackKey)
+	a.Call(ebpf.FuncMapLookupElem)
+	a.JumpImm(ebpf.JmpJEQ, 0, 0, "insert")
+	a.AtomicAdd(ebpf.SizeDW, 0, registerBytes, probe.valueOffset)
+	a.Jump("out")
+
+	a.Label("insert")
+	a.Store(ebpf.SizeDW, registerFramePtr, registerBytes, stackValue+probe.valueOffset)
+	a.LoadMapFD(1, mapFD)
+	a.MovReg(2, registerFramePtr)
+	a.ALUImm(ebpf.OpAdd, 2, stackKey)
+	a.MovReg(3, registerFramePtr)
+	a.ALUImm(ebpf.OpAdd, 3, stackValue)
+	a.MovImm(4, flagNoExist)
+	a.Call(ebpf.FuncMapUpdateElem)
+
+	a.Label("out")
+	a.MovImm(0, 0)
+	a.Exit()
+
+	return a.Assemble()
+}
@@ -0,0 +1,69 @@
+package net
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestTopTalkers(t *testing.T) {
+	t.Setenv("HOST_PROC", filepath.Join("testdata", "proc"))
+
+	talkers := []talker{
+		{pid: 1234, dst: "10.0.0.1", sent: 100, recv: 200},
+		{pid: 1234, dst: "10.0.0.2", sent: 5000, recv: 10},
+		{pid: 4321, dst: "10.0.0.1", sent: 1, recv: 0},
+		{pid: 99, dst: "192.168.1.1", sent: 0, recv: 300},
+	}
+
+	// Talkers with the same amount of traffic are ordered by process ID
+	var acc testutil.Accumulator
+	addTopTalkers(&acc, talkers, 3)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"net_top_talkers",
+			map[string]string{"pid": "1234", "process": "curl", "dst": "10.0.0.2"},
+			map[string]interface{}{"bytes_sent": uint64(5000), "bytes_recv": uint64(10)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"net_top_talkers",
+			map[string]string{"pid": "99", "dst": "192.168.1.1"},
+			map[string]interface{}{"bytes_sent": uint64(0), "bytes_recv": uint64(300)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"net_top_talkers",
+			map[string]string{"pid": "1234", "process": "curl", "dst": "10.0.0.1"},
+			map[string]interface{}{"bytes_sent": uint64(100), "bytes_recv": uint64(200)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestTopTalkersUnlimited(t *testing.T) {
+	talkers := []talker{
+		{pid: 2, dst: "10.0.0.1", sent: 1},
+		{pid: 1, dst: "10.0.0.1", sent: 1},
+		{pid: 3, dst: "10.0.0.1", recv: 2},
+	}
+	actual := topTalkers(talkers, 0)
+	require.Equal(t, []talker{
+		{pid: 3, dst: "10.0.0.1", recv: 2},
+		{pid: 1, dst: "10.0.0.1", sent: 1},
+		{pid: 2, dst: "10.0.0.1", sent: 1},
+	}, actual)
+}
+
+func TestInitInvalidTopTalkersLimit(t *testing.T) {
+	plugin := &Net{IgnoreProtocolStats: true, TopTalkersLimit: -1}
+	require.ErrorContains(t, plugin.Init(), "invalid top_talkers_limit")
+}
@@ -3,9 +3,12 @@ package net_response
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/tls"
 	_ "embed"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/textproto"
 	"regexp"
@@ -14,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/choice"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -28,15 +32,22 @@ const (
 	connectionFailed resultType = 2
 	readFailed       resultType = 3
 	stringMismatch   resultType = 4
+	tlsFailed        resultType = 5
 )
 
 type NetResponse struct {
-	Address     string          `toml:"address"`
-	Timeout     config.Duration `toml:"timeout"`
-	ReadTimeout config.Duration `toml:"read_timeout"`
-	Send        string          `toml:"send"`
-	Expect      string          `toml:"expect"`
-	Protocol    string          `toml:"protocol"`
+	Address          string          `toml:"address"`
+	Timeout          config.Duration `toml:"timeout"`
+	ReadTimeout      config.Duration `toml:"read_timeout"`
+	Send             string          `toml:"send"`
+	Expect           string          `toml:"expect"`
+	ExpectMultiline  bool            `toml:"expect_multiline"`
+	ExpectReadWindow config.Size     `toml:"expect_read_window"`
+	Protocol         string          `toml:"protocol"`
+	common_tls.ClientConfig
+
+	expectRegex *regexp.Regexp
+	tlsConfig   *tls.Config
 }
 
 func (*NetResponse) SampleConfig() string {
@@ -51,6 +62,9 @@ func (n *NetResponse) Init() error {
 	if n.ReadTimeout == 0 {
 		n.ReadTimeout = config.Duration(time.Second)
 	}
+	if n.ExpectReadWindow == 0 {
+		n.ExpectReadWindow = config.Size(4096)
+	}
 	// Check send and expected string
 	if n.Protocol == "udp" && n.Send == "" {
 		return errors.New("send string cannot be empty")
@@ -64,6 +78,7 @@ func (n *NetResponse) Init() error {
 		return err
 	}
 	if host == "" {
+		host = "localhost"
 		n.Address = "localhost:" + port
 	}
 	if port == "" {
@@ -74,6 +89,34 @@ func (n *NetResponse) Init() error {
 		return fmt.Errorf("config option protocol: %w", err)
 	}
 
+	// Multi-line banners are matched as a whole with "^" and "$" matching at
+	// line boundaries, otherwise the expression has to match within a line
+	if n.Expect != "" {
+		expr := `.*` + n.Expect + `.*`
+		if n.ExpectMultiline {
+			expr = `(?m)` + n.Expect
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("compiling expected string failed: %w", err)
+		}
+		n.expectRegex = re
+	}
+
+	tlsConfig, err := n.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		if n.Protocol != "tcp" {
+			return errors.New("TLS is only supported for the tcp protocol")
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = host
+		}
+		n.tlsConfig = tlsConfig
+	}
+
 	return nil
 }
 
@@ -126,8 +169,7 @@ func (n *NetResponse) tcpGather() (map[string]string, map[string]interface{}, er
 	responseTime := time.Since(start).Seconds()
 	// Handle error
 	if err != nil {
-		var e net.Error
-		if errors.As(err, &e) && e.Timeout() {
+		if isTimeout(err) {
 			setResult(timeout, fields, tags, n.Expect)
 		} else {
 			setResult(connectionFailed, fields, tags, n.Expect)
@@ -135,6 +177,22 @@ func (n *NetResponse) tcpGather() (map[string]string, map[string]interface{}, er
 		return tags, fields, nil
 	}
 	defer conn.Close()
+	// Perform the TLS handshake if needed
+	if n.tlsConfig != nil {
+		tlsConn, err := n.handshake(conn, fields)
+		if err != nil {
+			if isTimeout(err) {
+				setResult(timeout, fields, tags, n.Expect)
+			} else {
+				setResult(tlsFailed, fields, tags, n.Expect)
+			}
+			fields["response_time"] = time.Since(start).Seconds()
+			return tags, fields, nil
+		}
+		conn = tlsConn
+		// Stop timer
+		responseTime = time.Since(start).Seconds()
+	}
 	// Send string if needed
 	if n.Send != "" {
 		msg := []byte(n.Send)
@@ -150,26 +208,16 @@ func (n *NetResponse) tcpGather() (map[string]string, map[string]interface{}, er
 		if gerr := conn.SetReadDeadline(time.Now().Add(time.Duration(n.ReadTimeout))); gerr != nil {
 			return nil, nil, gerr
 		}
-		// Prepare reader
-		reader := bufio.NewReader(conn)
-		tp := textproto.NewReader(reader)
-		// Read
-		data, err := tp.ReadLine()
-		// Stop timer
-		responseTime = time.Since(start).Seconds()
-		// Handle error
-		if err != nil {
-			setResult(readFailed, fields, tags, n.Expect)
+		// Read and look for string in answer
+		var result resultType
+		if n.ExpectMultiline {
+			result = n.readMultiline(conn)
 		} else {
-			// Looking for string in answer
-			regEx := regexp.MustCompile(`.*` + n.Expect + `.*`)
-			find := regEx.FindString(data)
-			if find != "" {
-				setResult(success, fields, tags, n.Expect)
-			} else {
-				setResult(stringMismatch, fields, tags, n.Expect)
-			}
+			result = n.readLine(conn)
 		}
+		// Stop timer
+		responseTime = time.Since(start).Seconds()
+		setResult(result, fields, tags, n.Expect)
 	} else {
 		setResult(success, fields, tags, n.Expect)
 	}
@@ -210,7 +258,7 @@ func (n *NetResponse) udpGather() (map[string]string, map[string]interface{}, er
 	}
 	// Read
 	buf := make([]byte, 1024)
-	_, _, err = conn.ReadFromUDP(buf)
+	size, _, err := conn.ReadFromUDP(buf)
 	// Stop timer
 	responseTime := time.Since(start).Seconds()
 	// Handle error
@@ -220,9 +268,7 @@ func (n *NetResponse) udpGather() (map[string]string, map[string]interface{}, er
 	}
 
 	// Looking for string in answer
-	regEx := regexp.MustCompile(`.*` + n.Expect + `.*`)
-	find := regEx.FindString(string(buf))
-	if find != "" {
+	if n.expectRegex.Match(buf[:size]) {
 		setResult(success, fields, tags, n.Expect)
 	} else {
 		setResult(stringMismatch, fields, tags, n.Expect)
@@ -233,6 +279,70 @@ func (n *NetResponse) udpGather() (map[string]string, map[string]interface{}, er
 	return tags, fields, nil
 }
 
+// handshake performs the TLS handshake within the connection timeout and
+// records the handshake duration and the negotiated parameters
+func (n *NetResponse) handshake(conn net.Conn, fields map[string]interface{}) (net.Conn, error) {
+	if err := conn.SetDeadline(time.Now().Add(time.Duration(n.Timeout))); err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, n.tlsConfig)
+	start := time.Now()
+	err := tlsConn.Handshake()
+	fields["tls_handshake_time"] = time.Since(start).Seconds()
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	state := tlsConn.ConnectionState()
+	fields["tls_version"] = tls.VersionName(state.Version)
+	fields["tls_cipher"] = tls.CipherSuiteName(state.CipherSuite)
+	return tlsConn, nil
+}
+
+// readLine matches the expected string against the first line of the answer
+func (n *NetResponse) readLine(conn net.Conn) resultType {
+	tp := textproto.NewReader(bufio.NewReader(conn))
+	data, err := tp.ReadLine()
+	if err != nil {
+		return readFailed
+	}
+	if n.expectRegex.MatchString(data) {
+		return success
+	}
+	return stringMismatch
+}
+
+// readMultiline matches the expected string against the answer received until
+// either the expression matches, the read window is full, the server closes
+// the connection or the read timeout is hit. Line endings are normalized to
+// "\n" so "$" matches the end of lines of protocols using "\r\n".
+func (n *NetResponse) readMultiline(conn net.Conn) resultType {
+	window := int(n.ExpectReadWindow)
+	data := make([]byte, 0, window)
+	for len(data) < window {
+		size, err := conn.Read(data[len(data):window])
+		data = data[:len(data)+size]
+		if size > 0 && n.expectRegex.Match(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))) {
+			return success
+		}
+		if err != nil {
+			if len(data) > 0 && (errors.Is(err, io.EOF) || isTimeout(err)) {
+				return stringMismatch
+			}
+			return readFailed
+		}
+	}
+	return stringMismatch
+}
+
+func isTimeout(err error) bool {
+	var e net.Error
+	return errors.As(err, &e) && e.Timeout()
+}
+
 func setResult(result resultType, fields map[string]interface{}, tags map[string]string, expect string) {
 	var tag string
 	switch result {
@@ -246,6 +356,8 @@ func setResult(result resultType, fields map[string]interface{}, tags map[string
 		tag = "read_failed"
 	case stringMismatch:
 		tag = "string_mismatch"
+	case tlsFailed:
+		tag = "tls_failed"
 	}
 
 	tags["result"] = tag
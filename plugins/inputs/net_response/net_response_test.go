@@ -1,15 +1,20 @@
 package net_response
 
 import (
+	"crypto/tls"
 	"net"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/testutil"
-
-	"github.com/stretchr/testify/require"
 )
 
 func TestBadProtocol(t *testing.T) {
@@ -339,3 +344,175 @@ func tcpServer(t *testing.T, wg *sync.WaitGroup) {
 		return
 	}
 }
+
+func TestTLSMultilineBanner(t *testing.T) {
+	pki := testutil.NewPKI("../../../testutil/pki")
+	serverCfg, err := pki.TLSServerConfig().TLSConfig()
+	require.NoError(t, err)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverCfg)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// Mimic an SMTP server greeting with a multi-line reply
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("220-mail.example.com ESMTP\r\n"))               //nolint:errcheck // test server
+			time.Sleep(50 * time.Millisecond)                                  // split the banner across reads
+			conn.Write([]byte("220-no UCE\r\n220 mail.example.com ready\r\n")) //nolint:errcheck // test server
+			conn.Close()
+		}
+	}()
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	plugin := &NetResponse{
+		Address:         "localhost:" + port,
+		Expect:          `^220 .* ready$`,
+		ExpectMultiline: true,
+		Protocol:        "tcp",
+		ClientConfig:    *pki.TLSClientConfig(),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"net_response",
+			map[string]string{
+				"result":   "success",
+				"server":   "localhost",
+				"port":     port,
+				"protocol": "tcp",
+			},
+			map[string]interface{}{
+				"result_code":  uint64(0),
+				"result_type":  "success",
+				"string_found": true,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.IgnoreFields("response_time", "tls_handshake_time", "tls_version", "tls_cipher"),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+	m := acc.GetTelegrafMetrics()[0]
+	require.True(t, m.HasField("tls_handshake_time"))
+	require.True(t, m.HasField("tls_version"))
+	require.True(t, m.HasField("tls_cipher"))
+
+	// A pattern matching only the first line fails in single-line mode
+	plugin.ExpectMultiline = false
+	require.NoError(t, plugin.Init())
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	tag, _ := acc.GetTelegrafMetrics()[0].GetTag("result")
+	require.Equal(t, "string_mismatch", tag)
+}
+
+func TestTLSHandshakeFailed(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// Plain-text server closing the connection
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("+OK\r\n")) //nolint:errcheck // test server
+			conn.Close()
+		}
+	}()
+
+	enable := true
+	plugin := &NetResponse{
+		Address:      listener.Addr().String(),
+		Protocol:     "tcp",
+		ClientConfig: common_tls.ClientConfig{Enable: &enable, InsecureSkipVerify: true},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	m := acc.GetTelegrafMetrics()[0]
+	tag, _ := m.GetTag("result")
+	require.Equal(t, "tls_failed", tag)
+	code, _ := m.GetField("result_code")
+	require.Equal(t, uint64(5), code)
+	require.True(t, m.HasField("tls_handshake_time"))
+	require.False(t, m.HasField("tls_version"))
+}
+
+func TestMultilineReadWindow(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.Write([]byte("* OK [CAPABILITY IMAP4rev1] server ready\r\n")) //nolint:errcheck // test server
+				time.Sleep(time.Second)
+				conn.Close()
+			}()
+		}
+	}()
+
+	plugin := &NetResponse{
+		Address:          listener.Addr().String(),
+		Expect:           `(?s)OK.*ready`,
+		ExpectMultiline:  true,
+		ExpectReadWindow: config.Size(8),
+		ReadTimeout:      config.Duration(500 * time.Millisecond),
+		Protocol:         "tcp",
+	}
+	require.NoError(t, plugin.Init())
+
+	// The match is beyond the read window
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	tag, _ := acc.GetTelegrafMetrics()[0].GetTag("result")
+	require.Equal(t, "string_mismatch", tag)
+
+	// The read timeout is hit without a match
+	plugin.Expect = "BYE"
+	plugin.ExpectReadWindow = 0
+	require.NoError(t, plugin.Init())
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	tag, _ = acc.GetTelegrafMetrics()[0].GetTag("result")
+	require.Equal(t, "string_mismatch", tag)
+
+	plugin.Expect = "IMAP4rev1"
+	require.NoError(t, plugin.Init())
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	tag, _ = acc.GetTelegrafMetrics()[0].GetTag("result")
+	require.Equal(t, "success", tag)
+}
+
+func TestTLSUDP(t *testing.T) {
+	enable := true
+	plugin := &NetResponse{
+		Address:      "127.0.0.1:2004",
+		Send:         "test",
+		Expect:       "test",
+		Protocol:     "udp",
+		ClientConfig: common_tls.ClientConfig{Enable: &enable},
+	}
+	require.EqualError(t, plugin.Init(), "TLS is only supported for the tcp protocol")
+}
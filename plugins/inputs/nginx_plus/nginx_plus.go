@@ -27,6 +27,7 @@ var sampleConfig string
 type NginxPlus struct {
 	Urls            []string        `toml:"urls"`
 	ResponseTimeout config.Duration `toml:"response_timeout"`
+	TimestampSource string          `toml:"timestamp_source"`
 	tls.ClientConfig
 
 	client *http.Client
@@ -36,6 +37,17 @@ func (*NginxPlus) SampleConfig() string {
 	return sampleConfig
 }
 
+func (n *NginxPlus) Init() error {
+	switch n.TimestampSource {
+	case "":
+		n.TimestampSource = "gather"
+	case "gather", "timestamp", "load_timestamp":
+	default:
+		return fmt.Errorf("invalid timestamp_source %q", n.TimestampSource)
+	}
+	return nil
+}
+
 func (n *NginxPlus) Gather(acc telegraf.Accumulator) error {
 	var wg sync.WaitGroup
 
@@ -101,7 +113,7 @@ func (n *NginxPlus) gatherURL(addr *url.URL, acc telegraf.Accumulator) error {
 	contentType := strings.Split(resp.Header.Get("Content-Type"), ";")[0]
 	switch contentType {
 	case "application/json":
-		return gatherStatusURL(bufio.NewReader(resp.Body), getTags(addr), acc)
+		return gatherStatusURL(bufio.NewReader(resp.Body), getTags(addr), n.TimestampSource, acc)
 	default:
 		return fmt.Errorf("%s returned unexpected content type %s", addr.String(), contentType)
 	}
@@ -270,28 +282,44 @@ type status struct {
 	} `json:"stream"`
 }
 
-func gatherStatusURL(r *bufio.Reader, tags map[string]string, acc telegraf.Accumulator) error {
+func gatherStatusURL(r *bufio.Reader, tags map[string]string, source string, acc telegraf.Accumulator) error {
 	dec := json.NewDecoder(r)
 	status := &status{}
 	if err := dec.Decode(status); err != nil {
 		return errors.New("error while decoding JSON response")
 	}
-	status.gather(tags, acc)
+
+	// The API reports the timestamps in milliseconds since epoch
+	var ts []time.Time
+	switch source {
+	case "timestamp":
+		if status.Timestamp == 0 {
+			return errors.New("timestamp not provided by the API")
+		}
+		ts = append(ts, time.UnixMilli(status.Timestamp))
+	case "load_timestamp":
+		if status.LoadTimestamp == nil {
+			return fmt.Errorf("load_timestamp not provided by API version %d", status.Version)
+		}
+		ts = append(ts, time.UnixMilli(*status.LoadTimestamp))
+	}
+
+	status.gather(tags, acc, ts...)
 	return nil
 }
 
-func (s *status) gather(tags map[string]string, acc telegraf.Accumulator) {
-	s.gatherProcessesMetrics(tags, acc)
-	s.gatherConnectionsMetrics(tags, acc)
-	s.gatherSslMetrics(tags, acc)
-	s.gatherRequestMetrics(tags, acc)
-	s.gatherZoneMetrics(tags, acc)
-	s.gatherUpstreamMetrics(tags, acc)
-	s.gatherCacheMetrics(tags, acc)
-	s.gatherStreamMetrics(tags, acc)
+func (s *status) gather(tags map[string]string, acc telegraf.Accumulator, ts ...time.Time) {
+	s.gatherProcessesMetrics(tags, acc, ts...)
+	s.gatherConnectionsMetrics(tags, acc, ts...)
+	s.gatherSslMetrics(tags, acc, ts...)
+	s.gatherRequestMetrics(tags, acc, ts...)
+	s.gatherZoneMetrics(tags, acc, ts...)
+	s.gatherUpstreamMetrics(tags, acc, ts...)
+	s.gatherCacheMetrics(tags, acc, ts...)
+	s.gatherStreamMetrics(tags, acc, ts...)
 }
 
-func (s *status) gatherProcessesMetrics(tags map[string]string, acc telegraf.Accumulator) {
+func (s *status) gatherProcessesMetrics(tags map[string]string, acc telegraf.Accumulator, ts ...time.Time) {
 	var respawned int
 
 	if s.Processes.Respawned != nil {
@@ -304,10 +332,11 @@ func (s *status) gatherProcessesMetrics(tags map[string]string, acc telegraf.Acc
 			"respawned": respawned,
 		},
 		tags,
+		ts...,
 	)
 }
 
-func (s *status) gatherConnectionsMetrics(tags map[string]string, acc telegraf.Accumulator) {
+func (s *status) gatherConnectionsMetrics(tags map[string]string, acc telegraf.Accumulator, ts ...time.Time) {
 	acc.AddFields(
 		"nginx_plus_connections",
 		map[string]interface{}{
@@ -317,10 +346,11 @@ func (s *status) gatherConnectionsMetrics(tags map[string]string, acc telegraf.A
 			"idle":     s.Connections.Idle,
 		},
 		tags,
+		ts...,
 	)
 }
 
-func (s *status) gatherSslMetrics(tags map[string]string, acc telegraf.Accumulator) {
+func (s *status) gatherSslMetrics(tags map[string]string, acc telegraf.Accumulator, ts ...time.Time) {
 	acc.AddFields(
 		"nginx_plus_ssl",
 		map[string]interface{}{
@@ -329,10 +359,11 @@ func (s *status) gatherSslMetrics(tags map[string]string, acc telegraf.Accumulat
 			"session_reuses":    s.Ssl.SessionReuses,
 		},
 		tags,
+		ts...,
 	)
 }
 
-func (s *status) gatherRequestMetrics(tags map[string]string, acc telegraf.Accumulator) {
+func (s *status) gatherRequestMetrics(tags map[string]string, acc telegraf.Accumulator, ts ...time.Time) {
 	acc.AddFields(
 		"nginx_plus_requests",
 		map[string]interface{}{
@@ -340,10 +371,11 @@ func (s *status) gatherRequestMetrics(tags map[string]string, acc telegraf.Accum
 			"current": s.Requests.Current,
 		},
 		tags,
+		ts...,
 	)
 }
 
-func (s *status) gatherZoneMetrics(tags map[string]string, acc telegraf.Accumulator) {
+func (s *status) gatherZoneMetrics(tags map[string]string, acc telegraf.Accumulator, ts ...time.Time) {
 	for zoneName, zone := range s.ServerZones {
 		zoneTags := make(map[string]string, len(tags)+1)
 		for k, v := range tags {
@@ -371,11 +403,12 @@ func (s *status) gatherZoneMetrics(tags map[string]string, acc telegraf.Accumula
 				return result
 			}(),
 			zoneTags,
+			ts...,
 		)
 	}
 }
 
-func (s *status) gatherUpstreamMetrics(tags map[string]string, acc telegraf.Accumulator) {
+func (s *status) gatherUpstreamMetrics(tags map[string]string, acc telegraf.Accumulator, ts ...time.Time) {
 	for upstreamName, upstream := range s.Upstreams {
 		upstreamTags := make(map[string]string, len(tags)+1)
 		for k, v := range tags {
@@ -395,6 +428,7 @@ func (s *status) gatherUpstreamMetrics(tags map[string]string, acc telegraf.Accu
 			"nginx_plus_upstream",
 			upstreamFields,
 			upstreamTags,
+			ts...,
 		)
 		for _, peer := range upstream.Peers {
 			var selected int64
@@ -446,12 +480,12 @@ func (s *status) gatherUpstreamMetrics(tags map[string]string, acc telegraf.Accu
 			if peer.ID != nil {
 				peerTags["id"] = strconv.Itoa(*peer.ID)
 			}
-			acc.AddFields("nginx_plus_upstream_peer", peerFields, peerTags)
+			acc.AddFields("nginx_plus_upstream_peer", peerFields, peerTags, ts...)
 		}
 	}
 }
 
-func (s *status) gatherCacheMetrics(tags map[string]string, acc telegraf.Accumulator) {
+func (s *status) gatherCacheMetrics(tags map[string]string, acc telegraf.Accumulator, ts ...time.Time) {
 	for cacheName, cache := range s.Caches {
 		cacheTags := make(map[string]string, len(tags)+1)
 		for k, v := range tags {
@@ -486,11 +520,12 @@ func (s *status) gatherCacheMetrics(tags map[string]string, acc telegraf.Accumul
 				"bypass_bytes_written":      cache.Bypass.BytesWritten,
 			},
 			cacheTags,
+			ts...,
 		)
 	}
 }
 
-func (s *status) gatherStreamMetrics(tags map[string]string, acc telegraf.Accumulator) {
+func (s *status) gatherStreamMetrics(tags map[string]string, acc telegraf.Accumulator, ts ...time.Time) {
 	for zoneName, zone := range s.Stream.ServerZones {
 		zoneTags := make(map[string]string, len(tags)+1)
 		for k, v := range tags {
@@ -506,6 +541,7 @@ func (s *status) gatherStreamMetrics(tags map[string]string, acc telegraf.Accumu
 				"sent":        zone.Sent,
 			},
 			zoneTags,
+			ts...,
 		)
 	}
 	for upstreamName, upstream := range s.Stream.Upstreams {
@@ -520,6 +556,7 @@ func (s *status) gatherStreamMetrics(tags map[string]string, acc telegraf.Accumu
 				"zombies": upstream.Zombies,
 			},
 			upstreamTags,
+			ts...,
 		)
 		for _, peer := range upstream.Peers {
 			peerFields := map[string]interface{}{
@@ -557,7 +594,7 @@ func (s *status) gatherStreamMetrics(tags map[string]string, acc telegraf.Accumu
 			}
 			peerTags["upstream_address"] = peer.Server
 			peerTags["id"] = strconv.Itoa(peer.ID)
-			acc.AddFields("nginx_plus_stream_upstream_peer", peerFields, peerTags)
+			acc.AddFields("nginx_plus_stream_upstream_peer", peerFields, peerTags, ts...)
 		}
 	}
 }
@@ -1,12 +1,15 @@
 package nginx_plus
 
 import (
+	"bufio"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -408,3 +411,47 @@ func TestNginxPlusGeneratesMetrics(t *testing.T) {
 			"id":               "0",
 		})
 }
+
+func TestTimestampSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected time.Time
+	}{
+		{
+			source:   "timestamp",
+			expected: time.UnixMilli(1451606400000),
+		},
+		{
+			source:   "load_timestamp",
+			expected: time.UnixMilli(1451606400000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			n := &NginxPlus{TimestampSource: tt.source}
+			require.NoError(t, n.Init())
+
+			var acc testutil.Accumulator
+			r := bufio.NewReader(strings.NewReader(sampleStatusResponse))
+			require.NoError(t, gatherStatusURL(r, map[string]string{}, n.TimestampSource, &acc))
+
+			require.NotEmpty(t, acc.GetTelegrafMetrics())
+			for _, m := range acc.GetTelegrafMetrics() {
+				require.Equal(t, tt.expected, m.Time(), m.Name())
+			}
+		})
+	}
+}
+
+func TestTimestampSourceMissing(t *testing.T) {
+	var acc testutil.Accumulator
+	r := bufio.NewReader(strings.NewReader(`{"version": 1, "timestamp": 1451606400000}`))
+	require.ErrorContains(t, gatherStatusURL(r, map[string]string{}, "load_timestamp", &acc), "load_timestamp not provided")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestInitInvalidTimestampSource(t *testing.T) {
+	n := &NginxPlus{TimestampSource: "foo"}
+	require.ErrorContains(t, n.Init(), "invalid timestamp_source")
+}
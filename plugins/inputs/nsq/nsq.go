@@ -47,7 +47,8 @@ const (
 )
 
 type NSQ struct {
-	Endpoints []string `toml:"endpoints"`
+	Endpoints      []string `toml:"endpoints"`
+	AdminEndpoints []string `toml:"nsqadmin_endpoints"`
 
 	tls.ClientConfig
 	httpClient *http.Client
@@ -75,6 +76,13 @@ func (n *NSQ) Gather(acc telegraf.Accumulator) error {
 			acc.AddError(n.gatherEndpoint(e, acc))
 		}(e)
 	}
+	for _, e := range n.AdminEndpoints {
+		wg.Add(1)
+		go func(e string) {
+			defer wg.Done()
+			acc.AddError(n.gatherAdminEndpoint(e, acc))
+		}(e)
+	}
 
 	wg.Wait()
 	return nil
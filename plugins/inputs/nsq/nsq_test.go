@@ -6,10 +6,13 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
-
-	"github.com/influxdata/telegraf/testutil"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestNSQStatsV1(t *testing.T) {
@@ -534,3 +537,108 @@ var responsePreV1 = `
   }
 }
 `
+
+func TestNSQAdminStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response string
+		switch r.URL.Path {
+		case "/api/topics":
+			response = `{"topics":["telegraf"],"message":""}`
+		case "/api/topics/telegraf":
+			response = adminTopicResponse
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := fmt.Fprintln(w, response); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	n := newNSQ()
+	n.AdminEndpoints = []string{ts.URL}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(n.Gather))
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nsqadmin_topic",
+			map[string]string{"admin_host": u.Host, "topic": "telegraf"},
+			map[string]interface{}{
+				"depth":         int64(12),
+				"memory_depth":  int64(10),
+				"backend_depth": int64(2),
+				"message_count": int64(1523),
+				"channel_count": int64(1),
+				"node_count":    int64(2),
+				"paused":        false,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nsqadmin_channel",
+			map[string]string{"admin_host": u.Host, "topic": "telegraf", "channel": "consumer"},
+			map[string]interface{}{
+				"depth":          int64(12),
+				"memory_depth":   int64(10),
+				"backend_depth":  int64(2),
+				"inflight_count": int64(20),
+				"deferred_count": int64(4),
+				"requeue_count":  int64(7),
+				"timeout_count":  int64(1),
+				"message_count":  int64(1523),
+				"client_count":   int64(3),
+				"node_count":     int64(2),
+				"paused":         true,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+var adminTopicResponse = `
+{
+  "node": "*",
+  "hostname": "",
+  "topic_name": "telegraf",
+  "depth": 12,
+  "memory_depth": 10,
+  "backend_depth": 2,
+  "message_count": 1523,
+  "nodes": [
+    {"node": "nsqd-1:4151", "topic_name": "telegraf", "depth": 6},
+    {"node": "nsqd-2:4151", "topic_name": "telegraf", "depth": 6}
+  ],
+  "channels": [
+    {
+      "node": "*",
+      "topic_name": "telegraf",
+      "channel_name": "consumer",
+      "depth": 12,
+      "memory_depth": 10,
+      "backend_depth": 2,
+      "in_flight_count": 20,
+      "deferred_count": 4,
+      "requeue_count": 7,
+      "timeout_count": 1,
+      "message_count": 1523,
+      "client_count": 3,
+      "nodes": [
+        {"node": "nsqd-1:4151", "channel_name": "consumer", "client_count": 2},
+        {"node": "nsqd-2:4151", "channel_name": "consumer", "client_count": 1}
+      ],
+      "clients": [],
+      "paused": true
+    }
+  ],
+  "paused": false,
+  "message": ""
+}
+`
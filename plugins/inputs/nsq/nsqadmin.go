@@ -0,0 +1,132 @@
+package nsq
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// adminTopics is the list of topics known to nsqadmin
+type adminTopics struct {
+	Topics []string `json:"topics"`
+}
+
+// adminTopicStats contains the statistics of a topic aggregated over all
+// nsqd nodes of the cluster by nsqadmin
+type adminTopicStats struct {
+	Name         string              `json:"topic_name"`
+	Depth        int64               `json:"depth"`
+	MemoryDepth  int64               `json:"memory_depth"`
+	BackendDepth int64               `json:"backend_depth"`
+	MessageCount int64               `json:"message_count"`
+	Paused       bool                `json:"paused"`
+	Nodes        []json.RawMessage   `json:"nodes"`
+	Channels     []adminChannelStats `json:"channels"`
+}
+
+type adminChannelStats struct {
+	Name          string            `json:"channel_name"`
+	Depth         int64             `json:"depth"`
+	MemoryDepth   int64             `json:"memory_depth"`
+	BackendDepth  int64             `json:"backend_depth"`
+	InFlightCount int64             `json:"in_flight_count"`
+	DeferredCount int64             `json:"deferred_count"`
+	RequeueCount  int64             `json:"requeue_count"`
+	TimeoutCount  int64             `json:"timeout_count"`
+	MessageCount  int64             `json:"message_count"`
+	ClientCount   int64             `json:"client_count"`
+	Paused        bool              `json:"paused"`
+	Nodes         []json.RawMessage `json:"nodes"`
+}
+
+func (n *NSQ) gatherAdminEndpoint(e string, acc telegraf.Accumulator) error {
+	base, err := url.Parse(e)
+	if err != nil {
+		return fmt.Errorf("unable to parse address %q: %w", e, err)
+	}
+
+	var topics adminTopics
+	if err := n.getJSON(base.JoinPath("api", "topics"), &topics); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, topic := range topics.Topics {
+		wg.Add(1)
+		go func(topic string) {
+			defer wg.Done()
+
+			var stats adminTopicStats
+			if err := n.getJSON(base.JoinPath("api", "topics", topic), &stats); err != nil {
+				acc.AddError(err)
+				return
+			}
+			gatherAdminTopicStats(stats, acc, base.Host)
+		}(topic)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (n *NSQ) getJSON(u *url.URL, v interface{}) error {
+	r, err := n.httpClient.Get(u.String())
+	if err != nil {
+		return fmt.Errorf("error while polling %s: %w", u.String(), err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned HTTP status %s", u.String(), r.Status)
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("error parsing response of %s: %w", u.String(), err)
+	}
+	return nil
+}
+
+func gatherAdminTopicStats(t adminTopicStats, acc telegraf.Accumulator, host string) {
+	tags := map[string]string{
+		"admin_host": host,
+		"topic":      t.Name,
+	}
+
+	fields := map[string]interface{}{
+		"depth":         t.Depth,
+		"memory_depth":  t.MemoryDepth,
+		"backend_depth": t.BackendDepth,
+		"message_count": t.MessageCount,
+		"channel_count": int64(len(t.Channels)),
+		"node_count":    int64(len(t.Nodes)),
+		"paused":        t.Paused,
+	}
+	acc.AddFields("nsqadmin_topic", fields, tags)
+
+	for _, c := range t.Channels {
+		tags := map[string]string{
+			"admin_host": host,
+			"topic":      t.Name,
+			"channel":    c.Name,
+		}
+
+		fields := map[string]interface{}{
+			"depth":          c.Depth,
+			"memory_depth":   c.MemoryDepth,
+			"backend_depth":  c.BackendDepth,
+			"inflight_count": c.InFlightCount,
+			"deferred_count": c.DeferredCount,
+			"requeue_count":  c.RequeueCount,
+			"timeout_count":  c.TimeoutCount,
+			"message_count":  c.MessageCount,
+			"client_count":   c.ClientCount,
+			"node_count":     int64(len(c.Nodes)),
+			"paused":         c.Paused,
+		}
+		acc.AddFields("nsqadmin_channel", fields, tags)
+	}
+}
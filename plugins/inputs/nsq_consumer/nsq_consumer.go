@@ -6,10 +6,12 @@ import (
 	_ "embed"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/nsqio/go-nsq"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -18,6 +20,9 @@ var sampleConfig string
 
 const (
 	defaultMaxUndeliveredMessages = 1000
+	defaultMaxAttempts            = 5
+	defaultRequeueDelay           = config.Duration(90 * time.Second)
+	defaultMaxRequeueDelay        = config.Duration(15 * time.Minute)
 )
 
 type NSQConsumer struct {
@@ -28,10 +33,16 @@ type NSQConsumer struct {
 	Channel                string          `toml:"channel"`
 	MaxInFlight            int             `toml:"max_in_flight"`
 	MaxUndeliveredMessages int             `toml:"max_undelivered_messages"`
+	MaxAttempts            uint16          `toml:"max_attempts"`
+	RequeueDelay           config.Duration `toml:"requeue_delay"`
+	MaxRequeueDelay        config.Duration `toml:"max_requeue_delay"`
+	DeadLetterTopic        string          `toml:"dead_letter_topic"`
+	DeadLetterNsqd         string          `toml:"dead_letter_nsqd"`
 	Log                    telegraf.Logger `toml:"-"`
 
-	parser   telegraf.Parser
-	consumer *nsq.Consumer
+	parser     telegraf.Parser
+	consumer   *nsq.Consumer
+	deadLetter *nsq.Producer
 
 	mu       sync.Mutex
 	messages map[telegraf.TrackingID]*nsq.Message
@@ -48,6 +59,21 @@ type logger struct {
 	log telegraf.Logger
 }
 
+// handler processes the messages and handles messages exceeding the maximum
+// number of attempts
+type handler struct {
+	handle func(*nsq.Message) error
+	failed func(*nsq.Message)
+}
+
+func (h *handler) HandleMessage(message *nsq.Message) error {
+	return h.handle(message)
+}
+
+func (h *handler) LogFailedMessage(message *nsq.Message) {
+	h.failed(message)
+}
+
 func (l *logger) Output(_ int, s string) error {
 	l.log.Debug(s)
 	return nil
@@ -68,6 +94,22 @@ func (n *NSQConsumer) Init() error {
 		return errors.New("either 'nsqd' or 'nsqlookupd' needs to be specified")
 	}
 
+	if n.RequeueDelay < 0 || n.MaxRequeueDelay < 0 {
+		return errors.New("'requeue_delay' and 'max_requeue_delay' must not be negative")
+	}
+
+	if n.DeadLetterTopic != "" {
+		if n.DeadLetterTopic == n.Topic {
+			return errors.New("'dead_letter_topic' must differ from 'topic'")
+		}
+		if n.DeadLetterNsqd == "" {
+			if len(n.Nsqd) == 0 {
+				return errors.New("'dead_letter_nsqd' required for 'dead_letter_topic' without 'nsqd'")
+			}
+			n.DeadLetterNsqd = n.Nsqd[0]
+		}
+	}
+
 	return nil
 }
 
@@ -88,11 +130,14 @@ func (n *NSQConsumer) Start(ac telegraf.Accumulator) error {
 		return err
 	}
 	n.consumer.SetLogger(&logger{log: n.Log}, nsq.LogLevelInfo)
-	n.consumer.AddHandler(nsq.HandlerFunc(func(message *nsq.Message) error {
+	handle := func(message *nsq.Message) error {
 		metrics, err := n.parser.Parse(message.Body)
 		if err != nil {
 			acc.AddError(err)
 			// Remove the message from the queue
+			if n.deadLetter != nil {
+				n.publishDeadLetter(message)
+			}
 			message.Finish()
 			return nil
 		}
@@ -114,7 +159,8 @@ func (n *NSQConsumer) Start(ac telegraf.Accumulator) error {
 		n.mu.Unlock()
 		message.DisableAutoResponse()
 		return nil
-	}))
+	}
+	n.consumer.AddHandler(&handler{handle: handle, failed: n.onFailed})
 
 	if len(n.Nsqlookupd) > 0 {
 		err := n.consumer.ConnectToNSQLookupds(n.Nsqlookupd)
@@ -147,6 +193,27 @@ func (n *NSQConsumer) Stop() {
 	n.wg.Wait()
 	n.consumer.Stop()
 	<-n.consumer.StopChan
+	if n.deadLetter != nil {
+		n.deadLetter.Stop()
+	}
+}
+
+// onFailed handles messages exceeding the maximum number of attempts
+func (n *NSQConsumer) onFailed(message *nsq.Message) {
+	if n.deadLetter == nil {
+		n.Log.Warnf("Dropping message %s after %d attempts", message.ID, message.Attempts)
+		return
+	}
+	n.publishDeadLetter(message)
+}
+
+// publishDeadLetter publishes the message to the dead-letter topic
+func (n *NSQConsumer) publishDeadLetter(message *nsq.Message) {
+	if err := n.deadLetter.Publish(n.DeadLetterTopic, message.Body); err != nil {
+		n.Log.Errorf("Publishing message %s to dead-letter topic %q failed: %v", message.ID, n.DeadLetterTopic, err)
+		return
+	}
+	n.Log.Debugf("Published message %s to dead-letter topic %q", message.ID, n.DeadLetterTopic)
 }
 
 func (n *NSQConsumer) onDelivery(ctx context.Context, acc telegraf.TrackingAccumulator, sem semaphore) {
@@ -178,12 +245,24 @@ func (n *NSQConsumer) connect() error {
 	if n.consumer == nil {
 		config := nsq.NewConfig()
 		config.MaxInFlight = n.MaxInFlight
+		config.MaxAttempts = n.MaxAttempts
+		config.DefaultRequeueDelay = time.Duration(n.RequeueDelay)
+		config.MaxRequeueDelay = time.Duration(n.MaxRequeueDelay)
 		consumer, err := nsq.NewConsumer(n.Topic, n.Channel, config)
 		if err != nil {
 			return err
 		}
 		n.consumer = consumer
 	}
+
+	if n.DeadLetterTopic != "" && n.deadLetter == nil {
+		producer, err := nsq.NewProducer(n.DeadLetterNsqd, nsq.NewConfig())
+		if err != nil {
+			return err
+		}
+		producer.SetLogger(&logger{log: n.Log}, nsq.LogLevelInfo)
+		n.deadLetter = producer
+	}
 	return nil
 }
 
@@ -191,6 +270,9 @@ func init() {
 	inputs.Add("nsq_consumer", func() telegraf.Input {
 		return &NSQConsumer{
 			MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+			MaxAttempts:            defaultMaxAttempts,
+			RequeueDelay:           defaultRequeueDelay,
+			MaxRequeueDelay:        defaultMaxRequeueDelay,
 		}
 	})
 }
@@ -8,6 +8,7 @@ import (
 	"log"
 	"net"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -72,6 +73,90 @@ func TestReadsMetricsFromNSQ(t *testing.T) {
 	require.Equal(t, time.Unix(0, 1422568543702900257).Unix(), point.Time.Unix())
 }
 
+func TestDeadLetter(t *testing.T) {
+	msgID := nsq.MessageID{'1', '2', '3', '4', '5', '6', '7', '8', '9', '0', 'a', 's', 'd', 'f', 'g', 'h'}
+	msg := nsq.NewMessage(msgID, []byte("cpu_load_short,host=server01 value=23422.0 1422568543702900257\n"))
+	msg.Attempts = 3
+
+	frameMsg, err := frameMessage(msg)
+	require.NoError(t, err)
+
+	script := []instruction{
+		// SUB
+		{0, nsq.FrameTypeResponse, []byte("OK")},
+		// IDENTIFY
+		{0, nsq.FrameTypeResponse, []byte("OK")},
+		{20 * time.Millisecond, nsq.FrameTypeMessage, frameMsg},
+		// needed to exit test
+		{500 * time.Millisecond, -1, []byte("exit")},
+	}
+	newMockNSQD(t, script, "127.0.0.1:4156")
+
+	deadLetterScript := []instruction{
+		// IDENTIFY
+		{0, nsq.FrameTypeResponse, []byte("OK")},
+		// PUB
+		{50 * time.Millisecond, nsq.FrameTypeResponse, []byte("OK")},
+		// needed to exit test
+		{500 * time.Millisecond, -1, []byte("exit")},
+	}
+	newMockNSQD(t, deadLetterScript, "127.0.0.1:4157")
+
+	logger := &testutil.CaptureLogger{}
+	consumer := &NSQConsumer{
+		Log:                    logger,
+		Topic:                  "telegraf",
+		Channel:                "consume",
+		MaxInFlight:            1,
+		MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		MaxAttempts:            2,
+		DeadLetterTopic:        "telegraf_dead",
+		DeadLetterNsqd:         "127.0.0.1:4157",
+		Nsqd:                   []string{"127.0.0.1:4156"},
+	}
+	require.NoError(t, consumer.Init())
+
+	p := &influx.Parser{}
+	require.NoError(t, p.Init())
+	consumer.SetParser(p)
+	var acc testutil.Accumulator
+	require.NoError(t, consumer.Start(&acc))
+
+	require.Eventually(t, func() bool {
+		for _, entry := range logger.Messages() {
+			if strings.Contains(entry.Text, `Published message 1234567890asdfgh to dead-letter topic "telegraf_dead"`) {
+				return true
+			}
+		}
+		return false
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestInitDeadLetter(t *testing.T) {
+	consumer := &NSQConsumer{
+		Topic:           "telegraf",
+		DeadLetterTopic: "telegraf_dead",
+		Nsqd:            []string{"localhost:4150", "localhost:4151"},
+	}
+	require.NoError(t, consumer.Init())
+	require.Equal(t, "localhost:4150", consumer.DeadLetterNsqd)
+
+	consumer = &NSQConsumer{
+		Topic:           "telegraf",
+		DeadLetterTopic: "telegraf_dead",
+		Nsqlookupd:      []string{"localhost:4161"},
+	}
+	require.ErrorContains(t, consumer.Init(), "'dead_letter_nsqd' required")
+
+	consumer = &NSQConsumer{
+		Topic:           "telegraf",
+		DeadLetterTopic: "telegraf",
+		Nsqd:            []string{"localhost:4150"},
+	}
+	require.ErrorContains(t, consumer.Init(), "'dead_letter_topic' must differ from 'topic'")
+}
+
 // Waits for the metric that was sent to the kafka broker to arrive at the kafka
 // consumer
 func waitForPoint(acc *testutil.Accumulator, t *testing.T) {
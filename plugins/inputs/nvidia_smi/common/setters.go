@@ -45,3 +45,14 @@ func SetIfUsed(t string, m map[string]interface{}, k, v string) {
 		}
 	}
 }
+
+// SetActiveIfUsed sets those fields whose value is either "Active" or
+// "Not Active" as boolean.
+func SetActiveIfUsed(m map[string]interface{}, k, v string) {
+	switch v {
+	case "Active":
+		m[k] = true
+	case "Not Active":
+		m[k] = false
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/gpu"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/inputs/nvidia_smi/schema_v11"
 	"github.com/influxdata/telegraf/plugins/inputs/nvidia_smi/schema_v12"
@@ -25,13 +26,71 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+const mebibyte = 1024 * 1024
+
+// gpuSchema converts the native metrics into the common GPU schema
+var gpuSchema = &gpu.Schema{
+	Vendor: "nvidia",
+	Measurements: map[string]gpu.Mapping{
+		"nvidia_smi": {
+			Tags: map[string]gpu.Tag{
+				"index": {Name: "index"},
+				"uuid":  {Name: "uuid"},
+				"name":  {Name: "name"},
+			},
+			Fields: map[string]gpu.Field{
+				"utilization_gpu":         {Name: "utilization"},
+				"utilization_memory":      {Name: "memory_utilization"},
+				"memory_total":            {Name: "memory_total", Scale: mebibyte},
+				"memory_used":             {Name: "memory_used", Scale: mebibyte},
+				"memory_free":             {Name: "memory_free", Scale: mebibyte},
+				"temperature_gpu":         {Name: "temperature"},
+				"power_draw":              {Name: "power_draw"},
+				"power_limit":             {Name: "power_limit"},
+				"clocks_current_graphics": {Name: "clock_graphics"},
+				"clocks_current_memory":   {Name: "clock_memory"},
+				"fan_speed":               {Name: "fan_speed"},
+			},
+		},
+		"nvidia_smi_mig": {
+			Tags: map[string]gpu.Tag{
+				"uuid":          {Name: "uuid"},
+				"name":          {Name: "name"},
+				"index":         {Name: "mig_index"},
+				"gpu_index":     {Name: "gpu_instance"},
+				"compute_index": {Name: "compute_instance"},
+			},
+			Fields: map[string]gpu.Field{
+				"memory_fb_total": {Name: "memory_total", Scale: mebibyte},
+				"memory_fb_used":  {Name: "memory_used", Scale: mebibyte},
+				"memory_fb_free":  {Name: "memory_free", Scale: mebibyte},
+			},
+		},
+		"nvidia_smi_nvlink": {
+			Measurement: gpu.LinkMeasurement,
+			Tags: map[string]gpu.Tag{
+				"index": {Name: "index"},
+				"uuid":  {Name: "uuid"},
+				"link":  {Name: "link"},
+			},
+			Fields: map[string]gpu.Field{
+				"tx_bytes": {Name: "tx_bytes"},
+				"rx_bytes": {Name: "rx_bytes"},
+			},
+		},
+	},
+}
+
 // NvidiaSMI holds the methods for this plugin
 type NvidiaSMI struct {
-	BinPath string          `toml:"bin_path"`
-	Timeout config.Duration `toml:"timeout"`
-	Log     telegraf.Logger `toml:"-"`
+	BinPath      string          `toml:"bin_path"`
+	Timeout      config.Duration `toml:"timeout"`
+	GatherNVLink bool            `toml:"gather_nvlink"`
+	MetricSchema string          `toml:"metric_schema"`
+	Log          telegraf.Logger `toml:"-"`
 
 	nvidiaSMIArgs []string
+	nvlinkArgs    []string
 	ignorePlugin  bool
 	once          sync.Once
 }
@@ -40,6 +99,10 @@ func (*NvidiaSMI) SampleConfig() string {
 	return sampleConfig
 }
 
+func (smi *NvidiaSMI) Init() error {
+	return gpu.CheckSchema(smi.MetricSchema)
+}
+
 func (smi *NvidiaSMI) Start(telegraf.Accumulator) error {
 	if _, err := os.Stat(smi.BinPath); os.IsNotExist(err) {
 		binPath, err := exec.LookPath("nvidia-smi")
@@ -69,6 +132,10 @@ func (smi *NvidiaSMI) Gather(acc telegraf.Accumulator) error {
 		return nil
 	}
 
+	if smi.MetricSchema == "gpu" {
+		acc = &gpu.Accumulator{Accumulator: acc, Schema: gpuSchema}
+	}
+
 	// Construct and execute metrics query
 	data, err := internal.CombinedOutputTimeout(exec.Command(smi.BinPath, smi.nvidiaSMIArgs...), time.Duration(smi.Timeout))
 	if err != nil {
@@ -76,7 +143,19 @@ func (smi *NvidiaSMI) Gather(acc telegraf.Accumulator) error {
 	}
 
 	// Parse the output
-	return smi.parse(acc, data)
+	if err := smi.parse(acc, data); err != nil {
+		return err
+	}
+
+	if smi.GatherNVLink {
+		data, err := internal.CombinedOutputTimeout(exec.Command(smi.BinPath, smi.nvlinkArgs...), time.Duration(smi.Timeout))
+		if err != nil {
+			return fmt.Errorf("calling %q for NVLink counters failed: %w", smi.BinPath, err)
+		}
+		return parseNVLink(acc, data)
+	}
+
+	return nil
 }
 
 func (smi *NvidiaSMI) parse(acc telegraf.Accumulator, data []byte) error {
@@ -132,6 +211,7 @@ func init() {
 			BinPath:       "/usr/bin/nvidia-smi",
 			Timeout:       config.Duration(5 * time.Second),
 			nvidiaSMIArgs: []string{"-q", "-x"},
+			nvlinkArgs:    []string{"nvlink", "-gt", "d"},
 		}
 	})
 }
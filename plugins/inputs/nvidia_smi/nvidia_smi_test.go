@@ -12,6 +12,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/common/gpu"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -221,6 +222,19 @@ func TestGatherValidXML(t *testing.T) {
 						"utilization_encoder":           0,
 						"utilization_decoder":           0,
 						"vbios_version":                 "90.16.25.00.4C",
+						"clocks_event_reason_applications_clocks_setting": false,
+						"clocks_event_reason_display_clocks_setting":      false,
+						"clocks_event_reason_gpu_idle":                    true,
+						"clocks_event_reason_hw_power_brake_slowdown":     false,
+						"clocks_event_reason_hw_slowdown":                 false,
+						"clocks_event_reason_hw_thermal_slowdown":         false,
+						"clocks_event_reason_sw_power_cap":                false,
+						"clocks_event_reason_sw_thermal_slowdown":         false,
+						"clocks_event_reason_sync_boost":                  false,
+						"power_limit_default":                             130.0,
+						"power_limit_enforced":                            130.0,
+						"power_limit_max":                                 130.0,
+						"power_limit_min":                                 70.0,
 					},
 					time.Unix(0, 0)),
 			},
@@ -266,6 +280,15 @@ func TestGatherValidXML(t *testing.T) {
 						"utilization_encoder":           0,
 						"utilization_decoder":           0,
 						"vbios_version":                 "86.07.3B.00.4A",
+						"clocks_event_reason_applications_clocks_setting": false,
+						"clocks_event_reason_display_clocks_setting":      false,
+						"clocks_event_reason_gpu_idle":                    true,
+						"clocks_event_reason_hw_power_brake_slowdown":     false,
+						"clocks_event_reason_hw_slowdown":                 false,
+						"clocks_event_reason_hw_thermal_slowdown":         false,
+						"clocks_event_reason_sw_power_cap":                false,
+						"clocks_event_reason_sw_thermal_slowdown":         false,
+						"clocks_event_reason_sync_boost":                  false,
 					},
 					time.Unix(0, 0)),
 			},
@@ -316,6 +339,19 @@ func TestGatherValidXML(t *testing.T) {
 						"utilization_encoder":           0,
 						"utilization_decoder":           0,
 						"vbios_version":                 "86.06.3F.00.30",
+						"clocks_event_reason_applications_clocks_setting": false,
+						"clocks_event_reason_display_clocks_setting":      false,
+						"clocks_event_reason_gpu_idle":                    true,
+						"clocks_event_reason_hw_power_brake_slowdown":     false,
+						"clocks_event_reason_hw_slowdown":                 false,
+						"clocks_event_reason_hw_thermal_slowdown":         false,
+						"clocks_event_reason_sw_power_cap":                false,
+						"clocks_event_reason_sw_thermal_slowdown":         false,
+						"clocks_event_reason_sync_boost":                  false,
+						"power_limit_default":                             75.0,
+						"power_limit_enforced":                            75.0,
+						"power_limit_max":                                 75.0,
+						"power_limit_min":                                 75.0,
 					},
 					time.Unix(0, 0)),
 			},
@@ -368,6 +404,19 @@ func TestGatherValidXML(t *testing.T) {
 						"utilization_encoder":               0,
 						"utilization_decoder":               0,
 						"vbios_version":                     "90.04.84.00.06",
+						"clocks_event_reason_applications_clocks_setting": false,
+						"clocks_event_reason_display_clocks_setting":      false,
+						"clocks_event_reason_gpu_idle":                    false,
+						"clocks_event_reason_hw_power_brake_slowdown":     false,
+						"clocks_event_reason_hw_slowdown":                 false,
+						"clocks_event_reason_hw_thermal_slowdown":         false,
+						"clocks_event_reason_sw_power_cap":                false,
+						"clocks_event_reason_sw_thermal_slowdown":         false,
+						"clocks_event_reason_sync_boost":                  false,
+						"power_limit_default":                             70.0,
+						"power_limit_enforced":                            70.0,
+						"power_limit_max":                                 70.0,
+						"power_limit_min":                                 60.0,
 					},
 					time.Unix(0, 0)),
 			},
@@ -421,6 +470,19 @@ func TestGatherValidXML(t *testing.T) {
 						"utilization_encoder":           0,
 						"utilization_decoder":           0,
 						"vbios_version":                 "94.02.75.00.01",
+						"clocks_event_reason_applications_clocks_setting": false,
+						"clocks_event_reason_display_clocks_setting":      false,
+						"clocks_event_reason_gpu_idle":                    true,
+						"clocks_event_reason_hw_power_brake_slowdown":     false,
+						"clocks_event_reason_hw_slowdown":                 false,
+						"clocks_event_reason_hw_thermal_slowdown":         false,
+						"clocks_event_reason_sw_power_cap":                false,
+						"clocks_event_reason_sw_thermal_slowdown":         false,
+						"clocks_event_reason_sync_boost":                  false,
+						"power_limit_default":                             300.0,
+						"power_limit_enforced":                            300.0,
+						"power_limit_max":                                 300.0,
+						"power_limit_min":                                 100.0,
 					},
 					time.Unix(0, 0)),
 			},
@@ -470,6 +532,19 @@ func TestGatherValidXML(t *testing.T) {
 						"utilization_decoder":           0,
 						"utilization_ofa":               0,
 						"vbios_version":                 "94.02.71.40.72",
+						"clocks_event_reason_applications_clocks_setting": false,
+						"clocks_event_reason_display_clocks_setting":      false,
+						"clocks_event_reason_gpu_idle":                    true,
+						"clocks_event_reason_hw_power_brake_slowdown":     false,
+						"clocks_event_reason_hw_slowdown":                 false,
+						"clocks_event_reason_hw_thermal_slowdown":         false,
+						"clocks_event_reason_sw_power_cap":                false,
+						"clocks_event_reason_sw_thermal_slowdown":         false,
+						"clocks_event_reason_sync_boost":                  false,
+						"power_limit_default":                             320.0,
+						"power_limit_enforced":                            336.0,
+						"power_limit_max":                                 336.0,
+						"power_limit_min":                                 100.0,
 					},
 					time.Unix(1689872450, 0)),
 				testutil.MustMetric(
@@ -477,6 +552,7 @@ func TestGatherValidXML(t *testing.T) {
 					map[string]string{
 						"name": "/usr/lib/Xorg",
 						"type": "G",
+						"uuid": "GPU-19d6d965-2acc-f646-00f8-4c76979aabb4",
 					},
 					map[string]interface{}{
 						"pid":         int64(835),
@@ -488,6 +564,7 @@ func TestGatherValidXML(t *testing.T) {
 					map[string]string{
 						"name": "/usr/bin/gnome-shell",
 						"type": "G",
+						"uuid": "GPU-19d6d965-2acc-f646-00f8-4c76979aabb4",
 					},
 					map[string]interface{}{
 						"pid":         int64(1481),
@@ -506,6 +583,7 @@ func TestGatherValidXML(t *testing.T) {
 							"--field-trial-handle=0,i,3110290512380155730," +
 							"7457693378709978105,262144 --variations-seed-version",
 						"type": "G",
+						"uuid": "GPU-19d6d965-2acc-f646-00f8-4c76979aabb4",
 					},
 					map[string]interface{}{
 						"pid":         int64(2214),
@@ -517,6 +595,7 @@ func TestGatherValidXML(t *testing.T) {
 					map[string]string{
 						"name": "/usr/lib/firefox/firefox",
 						"type": "G",
+						"uuid": "GPU-19d6d965-2acc-f646-00f8-4c76979aabb4",
 					},
 					map[string]interface{}{
 						"pid":         int64(4044),
@@ -536,6 +615,7 @@ func TestGatherValidXML(t *testing.T) {
 							"4769839452661094675,262144 --disable-features=" +
 							"CalculateNativeWinOcclusion,SpareRendererForSitePerProcess",
 						"type": "G",
+						"uuid": "GPU-19d6d965-2acc-f646-00f8-4c76979aabb4",
 					},
 					map[string]interface{}{
 						"pid":         int64(42416),
@@ -588,6 +668,19 @@ func TestGatherValidXML(t *testing.T) {
 						"utilization_encoder":           0,
 						"utilization_decoder":           0,
 						"vbios_version":                 "94.02.71.40.72",
+						"clocks_event_reason_applications_clocks_setting": false,
+						"clocks_event_reason_display_clocks_setting":      false,
+						"clocks_event_reason_gpu_idle":                    true,
+						"clocks_event_reason_hw_power_brake_slowdown":     false,
+						"clocks_event_reason_hw_slowdown":                 false,
+						"clocks_event_reason_hw_thermal_slowdown":         false,
+						"clocks_event_reason_sw_power_cap":                false,
+						"clocks_event_reason_sw_thermal_slowdown":         false,
+						"clocks_event_reason_sync_boost":                  false,
+						"power_limit_default":                             350.0,
+						"power_limit_enforced":                            200.0,
+						"power_limit_max":                                 375.0,
+						"power_limit_min":                                 100.0,
 					},
 					time.Unix(1689872450, 0)),
 			},
@@ -632,6 +725,19 @@ func TestGatherValidXML(t *testing.T) {
 						"serial":                        "1650522003820",
 						"temperature_gpu":               27,
 						"vbios_version":                 "92.00.36.00.02",
+						"clocks_event_reason_applications_clocks_setting": false,
+						"clocks_event_reason_display_clocks_setting":      false,
+						"clocks_event_reason_gpu_idle":                    false,
+						"clocks_event_reason_hw_power_brake_slowdown":     false,
+						"clocks_event_reason_hw_slowdown":                 false,
+						"clocks_event_reason_hw_thermal_slowdown":         false,
+						"clocks_event_reason_sw_power_cap":                false,
+						"clocks_event_reason_sw_thermal_slowdown":         false,
+						"clocks_event_reason_sync_boost":                  false,
+						"power_limit_default":                             500.0,
+						"power_limit_enforced":                            500.0,
+						"power_limit_max":                                 500.0,
+						"power_limit_min":                                 100.0,
 					},
 					time.Unix(1689872450, 0)),
 				testutil.MustMetric(
@@ -728,6 +834,97 @@ func TestGatherValidXML(t *testing.T) {
 					time.Unix(1689872450, 0)),
 			},
 		},
+		{
+			name:     "A100-SXM4 MIG process accounting schema v12",
+			filename: "a100-sxm4-mig-accounting-v12.xml",
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"nvidia_smi",
+					map[string]string{
+						"arch":         "Ampere",
+						"compute_mode": "Default",
+						"index":        "0",
+						"name":         "NVIDIA A100-SXM4-80GB",
+						"pstate":       "P0",
+						"uuid":         "GPU-513536b6-7d19-9063-b049-1e69664bb298",
+					},
+					map[string]interface{}{
+						"clocks_event_reason_applications_clocks_setting": false,
+						"clocks_event_reason_display_clocks_setting":      false,
+						"clocks_event_reason_gpu_idle":                    false,
+						"clocks_event_reason_hw_power_brake_slowdown":     false,
+						"clocks_event_reason_hw_slowdown":                 false,
+						"clocks_event_reason_hw_thermal_slowdown":         false,
+						"clocks_event_reason_sw_power_cap":                true,
+						"clocks_event_reason_sw_thermal_slowdown":         false,
+						"clocks_event_reason_sync_boost":                  false,
+						"cuda_version":                                    "12.2",
+						"driver_version":                                  "535.54.03",
+						"power_draw":                                      389.12,
+						"power_limit_default":                             500.0,
+						"power_limit_enforced":                            400.0,
+						"power_limit_max":                                 500.0,
+						"power_limit_min":                                 100.0,
+					},
+					time.Unix(1691149470, 0)),
+				testutil.MustMetric(
+					"nvidia_smi_process",
+					map[string]string{
+						"compute_index": "0",
+						"gpu_index":     "3",
+						"name":          "python3",
+						"type":          "C",
+						"uuid":          "GPU-513536b6-7d19-9063-b049-1e69664bb298",
+					},
+					map[string]interface{}{
+						"pid":         5212,
+						"used_memory": 9876,
+					},
+					time.Unix(1691149470, 0)),
+				testutil.MustMetric(
+					"nvidia_smi_process",
+					map[string]string{
+						"compute_index": "0",
+						"gpu_index":     "4",
+						"name":          "triton",
+						"type":          "C",
+						"uuid":          "GPU-513536b6-7d19-9063-b049-1e69664bb298",
+					},
+					map[string]interface{}{
+						"pid":         5377,
+						"used_memory": 15012,
+					},
+					time.Unix(1691149470, 0)),
+				testutil.MustMetric(
+					"nvidia_smi_accounted_process",
+					map[string]string{
+						"pid":  "5212",
+						"uuid": "GPU-513536b6-7d19-9063-b049-1e69664bb298",
+					},
+					map[string]interface{}{
+						"gpu_util":         87,
+						"is_running":       true,
+						"max_memory_usage": 10240,
+						"memory_util":      41,
+						"time":             734211,
+					},
+					time.Unix(1691149470, 0)),
+				testutil.MustMetric(
+					"nvidia_smi_accounted_process",
+					map[string]string{
+						"pid":  "4890",
+						"uuid": "GPU-513536b6-7d19-9063-b049-1e69664bb298",
+					},
+					map[string]interface{}{
+						"gpu_util":         12,
+						"is_running":       false,
+						"max_memory_usage": 2048,
+						"memory_util":      3,
+						"time":             18002,
+					},
+					time.Unix(1691149470, 0)),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -742,3 +939,104 @@ func TestGatherValidXML(t *testing.T) {
 		})
 	}
 }
+
+func TestGPUSchema(t *testing.T) {
+	octets, err := os.ReadFile(filepath.Join("testdata", "gtx-1070-ti.xml"))
+	require.NoError(t, err)
+
+	plugin := &NvidiaSMI{MetricSchema: "gpu", Log: &testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.parse(&gpu.Accumulator{Accumulator: &acc, Schema: gpuSchema}, octets))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"gpu",
+			map[string]string{
+				"vendor": "nvidia",
+				"index":  "0",
+				"name":   "GeForce GTX 1070 Ti",
+				"uuid":   "GPU-f9ba66fc-a7f5-94c5-da19-019ef2f9c665",
+			},
+			map[string]interface{}{
+				"clock_graphics":     float64(135),
+				"clock_memory":       float64(405),
+				"fan_speed":          float64(100),
+				"memory_free":        int64(4054 * 1024 * 1024),
+				"memory_total":       int64(4096 * 1024 * 1024),
+				"memory_used":        int64(42 * 1024 * 1024),
+				"temperature":        float64(39),
+				"utilization":        float64(0),
+				"memory_utilization": float64(0),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGPUSchemaMIG(t *testing.T) {
+	octets, err := os.ReadFile(filepath.Join("testdata", "a100-sxm4-v12.xml"))
+	require.NoError(t, err)
+
+	plugin := &NvidiaSMI{MetricSchema: "gpu", Log: &testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.parse(&gpu.Accumulator{Accumulator: &acc, Schema: gpuSchema}, octets))
+
+	acc.AssertContainsTaggedFields(t, "gpu",
+		map[string]interface{}{
+			"memory_free":  int64(19955 * 1024 * 1024),
+			"memory_total": int64(19968 * 1024 * 1024),
+			"memory_used":  int64(12 * 1024 * 1024),
+		},
+		map[string]string{
+			"vendor":           "nvidia",
+			"name":             "NVIDIA A100-SXM4-80GB",
+			"uuid":             "GPU-513536b6-7d19-9063-b049-1e69664bb298",
+			"mig_index":        "1",
+			"gpu_instance":     "4",
+			"compute_instance": "0",
+		},
+	)
+}
+
+func TestInvalidMetricSchema(t *testing.T) {
+	plugin := &NvidiaSMI{MetricSchema: "dcgm", Log: &testutil.Logger{}}
+	require.ErrorContains(t, plugin.Init(), "invalid 'metric_schema'")
+}
+
+func TestParseNVLink(t *testing.T) {
+	data := []byte(`GPU 0: NVIDIA A100-SXM4-80GB (UUID: GPU-513536b6-7d19-9063-b049-1e69664bb298)
+	 Link 0: Data Tx: 4486 KiB
+	 Link 0: Data Rx: 4362 KiB
+	 Link 1: Data Tx: 10 KiB
+	 Link 1: Data Rx: 20 KiB
+GPU 1: NVIDIA A100-SXM4-80GB (UUID: GPU-8a5e1b2c-0000-1111-2222-333344445555)
+	 Link 0: Data Tx: 0 KiB
+	 Link 0: Data Rx: 1 KiB
+`)
+
+	var acc testutil.Accumulator
+	require.NoError(t, parseNVLink(&acc, data))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"nvidia_smi_nvlink",
+			map[string]string{"index": "0", "uuid": "GPU-513536b6-7d19-9063-b049-1e69664bb298", "link": "0"},
+			map[string]interface{}{"tx_bytes": int64(4486 * 1024), "rx_bytes": int64(4362 * 1024)},
+			time.Unix(0, 0)),
+		testutil.MustMetric(
+			"nvidia_smi_nvlink",
+			map[string]string{"index": "0", "uuid": "GPU-513536b6-7d19-9063-b049-1e69664bb298", "link": "1"},
+			map[string]interface{}{"tx_bytes": int64(10 * 1024), "rx_bytes": int64(20 * 1024)},
+			time.Unix(0, 0)),
+		testutil.MustMetric(
+			"nvidia_smi_nvlink",
+			map[string]string{"index": "1", "uuid": "GPU-8a5e1b2c-0000-1111-2222-333344445555", "link": "0"},
+			map[string]interface{}{"tx_bytes": int64(0), "rx_bytes": int64(1024)},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,70 @@
+package nvidia_smi
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+var (
+	nvlinkGPURe     = regexp.MustCompile(`^GPU (\d+): .* \(UUID: ([^)]+)\)$`)
+	nvlinkCounterRe = regexp.MustCompile(`^Link (\d+): Data (Tx|Rx): (\d+) KiB$`)
+)
+
+// parseNVLink parses the output of 'nvidia-smi nvlink -gt d' looking like
+//
+//	GPU 0: NVIDIA A100-SXM4-40GB (UUID: GPU-3d6a3b0f-...)
+//		 Link 0: Data Tx: 4486 KiB
+//		 Link 0: Data Rx: 4362 KiB
+func parseNVLink(acc telegraf.Accumulator, data []byte) error {
+	type link struct {
+		index, uuid, link string
+		fields            map[string]interface{}
+	}
+	var links []*link
+	var index, uuid string
+	var current *link
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if m := nvlinkGPURe.FindStringSubmatch(line); m != nil {
+			index, uuid, current = m[1], m[2], nil
+			continue
+		}
+		m := nvlinkCounterRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if index == "" {
+			return fmt.Errorf("link counter %q without GPU", line)
+		}
+		value, err := strconv.ParseInt(m[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing counter in %q failed: %w", line, err)
+		}
+		if current == nil || current.link != m[1] {
+			current = &link{index: index, uuid: uuid, link: m[1], fields: make(map[string]interface{}, 2)}
+			links = append(links, current)
+		}
+		current.fields[strings.ToLower(m[2])+"_bytes"] = value * 1024
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, l := range links {
+		tags := map[string]string{"index": l.index, "uuid": l.uuid, "link": l.link}
+		acc.AddFields("nvidia_smi_nvlink", l.fields, tags)
+	}
+
+	return nil
+}
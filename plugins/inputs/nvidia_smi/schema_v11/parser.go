@@ -68,6 +68,20 @@ func Parse(acc telegraf.Accumulator, buf []byte) error {
 
 		common.SetIfUsed("float", fields, "power_draw", gpu.Power.PowerDraw)
 		common.SetIfUsed("float", fields, "power_limit", gpu.Power.PowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_default", gpu.Power.DefaultPowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_enforced", gpu.Power.EnforcedPowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_min", gpu.Power.MinPowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_max", gpu.Power.MaxPowerLimit)
+
+		common.SetActiveIfUsed(fields, "clocks_event_reason_gpu_idle", gpu.ClockReasons.GPUIdle)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_applications_clocks_setting", gpu.ClockReasons.ApplicationsClocksSetting)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_sw_power_cap", gpu.ClockReasons.SWPowerCap)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_hw_slowdown", gpu.ClockReasons.HWSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_hw_thermal_slowdown", gpu.ClockReasons.HWThermalSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_hw_power_brake_slowdown", gpu.ClockReasons.HWPowerBrakeSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_sync_boost", gpu.ClockReasons.SyncBoost)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_sw_thermal_slowdown", gpu.ClockReasons.SWThermalSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_display_clocks_setting", gpu.ClockReasons.DisplayClocksSetting)
 		acc.AddFields("nvidia_smi", fields, tags)
 	}
 
@@ -10,6 +10,7 @@ type smi struct {
 // gpu defines the structure of the GPU portion of the smi output.
 type gpu struct {
 	Clocks        clockStats         `xml:"clocks"`
+	ClockReasons  clockReasons       `xml:"clocks_throttle_reasons"`
 	ComputeMode   string             `xml:"compute_mode"`
 	DisplayActive string             `xml:"display_active"`
 	DisplayMode   string             `xml:"display_mode"`
@@ -80,8 +81,12 @@ type utilizationStats struct {
 
 // powerReadings defines the structure of the power_readings portion of the smi output.
 type powerReadings struct {
-	PowerDraw  string `xml:"power_draw"`  // float
-	PowerLimit string `xml:"power_limit"` // float
+	PowerDraw          string `xml:"power_draw"`           // float
+	PowerLimit         string `xml:"power_limit"`          // float
+	DefaultPowerLimit  string `xml:"default_power_limit"`  // float
+	EnforcedPowerLimit string `xml:"enforced_power_limit"` // float
+	MinPowerLimit      string `xml:"min_power_limit"`      // float
+	MaxPowerLimit      string `xml:"max_power_limit"`      // float
 }
 
 // pic defines the structure of the pci portion of the smi output.
@@ -117,3 +122,16 @@ type clockStats struct {
 	Memory   string `xml:"mem_clock"`      // int
 	Video    string `xml:"video_clock"`    // int
 }
+
+// clockReasons defines the structure of the clocks_throttle_reasons portion of the smi output.
+type clockReasons struct {
+	GPUIdle                   string `xml:"clocks_throttle_reason_gpu_idle"`                    // Active/Not Active
+	ApplicationsClocksSetting string `xml:"clocks_throttle_reason_applications_clocks_setting"` // Active/Not Active
+	SWPowerCap                string `xml:"clocks_throttle_reason_sw_power_cap"`                // Active/Not Active
+	HWSlowdown                string `xml:"clocks_throttle_reason_hw_slowdown"`                 // Active/Not Active
+	HWThermalSlowdown         string `xml:"clocks_throttle_reason_hw_thermal_slowdown"`         // Active/Not Active
+	HWPowerBrakeSlowdown      string `xml:"clocks_throttle_reason_hw_power_brake_slowdown"`     // Active/Not Active
+	SyncBoost                 string `xml:"clocks_throttle_reason_sync_boost"`                  // Active/Not Active
+	SWThermalSlowdown         string `xml:"clocks_throttle_reason_sw_thermal_slowdown"`         // Active/Not Active
+	DisplayClocksSetting      string `xml:"clocks_throttle_reason_display_clocks_setting"`      // Active/Not Active
+}
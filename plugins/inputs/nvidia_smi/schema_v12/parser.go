@@ -80,7 +80,34 @@ func Parse(acc telegraf.Accumulator, buf []byte) error {
 		common.SetIfUsed("float", fields, "power_limit", gpu.PowerReadings.PowerLimit)
 		common.SetIfUsed("float", fields, "power_draw", gpu.GpuPowerReadings.PowerDraw)
 		common.SetIfUsed("float", fields, "power_limit", gpu.GpuPowerReadings.PowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_default", gpu.PowerReadings.DefaultPowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_enforced", gpu.PowerReadings.EnforcedPowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_min", gpu.PowerReadings.MinPowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_max", gpu.PowerReadings.MaxPowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_default", gpu.GpuPowerReadings.DefaultPowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_enforced", gpu.GpuPowerReadings.CurrentPowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_min", gpu.GpuPowerReadings.MinPowerLimit)
+		common.SetIfUsed("float", fields, "power_limit_max", gpu.GpuPowerReadings.MaxPowerLimit)
 		common.SetIfUsed("float", fields, "module_power_draw", gpu.ModulePowerReadings.PowerDraw)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_gpu_idle", gpu.ClocksEventReasons.ClocksEventReasonGpuIdle)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_applications_clocks_setting", gpu.ClocksEventReasons.ClocksEventReasonApplicationsClocksSetting)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_sw_power_cap", gpu.ClocksEventReasons.ClocksEventReasonSwPowerCap)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_hw_slowdown", gpu.ClocksEventReasons.ClocksEventReasonHwSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_hw_thermal_slowdown", gpu.ClocksEventReasons.ClocksEventReasonHwThermalSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_hw_power_brake_slowdown", gpu.ClocksEventReasons.ClocksEventReasonHwPowerBrakeSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_sync_boost", gpu.ClocksEventReasons.ClocksEventReasonSyncBoost)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_sw_thermal_slowdown", gpu.ClocksEventReasons.ClocksEventReasonSwThermalSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_display_clocks_setting", gpu.ClocksEventReasons.ClocksEventReasonDisplayClocksSetting)
+		// Older drivers report the event reasons as throttle reasons
+		common.SetActiveIfUsed(fields, "clocks_event_reason_gpu_idle", gpu.ClocksThrottleReasons.ClocksThrottleReasonGpuIdle)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_applications_clocks_setting", gpu.ClocksThrottleReasons.ClocksThrottleReasonApplicationsClocksSetting)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_sw_power_cap", gpu.ClocksThrottleReasons.ClocksThrottleReasonSwPowerCap)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_hw_slowdown", gpu.ClocksThrottleReasons.ClocksThrottleReasonHwSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_hw_thermal_slowdown", gpu.ClocksThrottleReasons.ClocksThrottleReasonHwThermalSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_hw_power_brake_slowdown", gpu.ClocksThrottleReasons.ClocksThrottleReasonHwPowerBrakeSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_sync_boost", gpu.ClocksThrottleReasons.ClocksThrottleReasonSyncBoost)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_sw_thermal_slowdown", gpu.ClocksThrottleReasons.ClocksThrottleReasonSwThermalSlowdown)
+		common.SetActiveIfUsed(fields, "clocks_event_reason_display_clocks_setting", gpu.ClocksThrottleReasons.ClocksThrottleReasonDisplayClocksSetting)
 		acc.AddFields("nvidia_smi", fields, tags, timestamp)
 
 		for _, device := range gpu.MigDevices.MigDevice {
@@ -108,9 +135,16 @@ func Parse(acc telegraf.Accumulator, buf []byte) error {
 		}
 
 		for _, process := range gpu.Processes.ProcessInfo {
-			tags := make(map[string]string, 2)
+			tags := make(map[string]string, 5)
 			common.SetTagIfUsed(tags, "name", process.ProcessName)
 			common.SetTagIfUsed(tags, "type", process.Type)
+			common.SetTagIfUsed(tags, "uuid", gpu.UUID)
+			if process.GpuInstanceID != "N/A" {
+				common.SetTagIfUsed(tags, "gpu_index", process.GpuInstanceID)
+			}
+			if process.ComputeInstanceID != "N/A" {
+				common.SetTagIfUsed(tags, "compute_index", process.ComputeInstanceID)
+			}
 
 			fields := make(map[string]interface{}, 2)
 			common.SetIfUsed("int", fields, "pid", process.Pid)
@@ -118,6 +152,26 @@ func Parse(acc telegraf.Accumulator, buf []byte) error {
 
 			acc.AddFields("nvidia_smi_process", fields, tags, timestamp)
 		}
+
+		for _, process := range gpu.AccountedProcesses.AccountedProcessInfo {
+			tags := make(map[string]string, 2)
+			common.SetTagIfUsed(tags, "uuid", gpu.UUID)
+			common.SetTagIfUsed(tags, "pid", process.Pid)
+
+			fields := make(map[string]interface{}, 5)
+			common.SetIfUsed("int", fields, "gpu_util", process.GpuUtil)
+			common.SetIfUsed("int", fields, "memory_util", process.MemoryUtil)
+			common.SetIfUsed("int", fields, "max_memory_usage", process.MaxMemoryUsage)
+			common.SetIfUsed("int", fields, "time", process.Time)
+			switch process.IsRunning {
+			case "Yes":
+				fields["is_running"] = true
+			case "No":
+				fields["is_running"] = false
+			}
+
+			acc.AddFields("nvidia_smi_accounted_process", fields, tags, timestamp)
+		}
 	}
 
 	return nil
@@ -6,11 +6,20 @@ type smi struct {
 	CudaVersion   string `xml:"cuda_version"`
 	DriverVersion string `xml:"driver_version"`
 	Gpu           []struct {
-		ID                       string   `xml:"id,attr"`
-		AccountedProcesses       struct{} `xml:"accounted_processes"`
-		AccountingMode           string   `xml:"accounting_mode"`
-		AccountingModeBufferSize string   `xml:"accounting_mode_buffer_size"`
-		AddressingMode           string   `xml:"addressing_mode"`
+		ID                 string `xml:"id,attr"`
+		AccountedProcesses struct {
+			AccountedProcessInfo []struct {
+				Pid            string `xml:"pid"`
+				GpuUtil        string `xml:"gpu_util"`
+				MemoryUtil     string `xml:"memory_util"`
+				MaxMemoryUsage string `xml:"max_memory_usage"`
+				Time           string `xml:"time"`
+				IsRunning      string `xml:"is_running"`
+			} `xml:"accounted_process_info"`
+		} `xml:"accounted_processes"`
+		AccountingMode           string `xml:"accounting_mode"`
+		AccountingModeBufferSize string `xml:"accounting_mode_buffer_size"`
+		AddressingMode           string `xml:"addressing_mode"`
 		ApplicationsClocks       struct {
 			GraphicsClock string `xml:"graphics_clock"`
 			MemClock      string `xml:"mem_clock"`
@@ -48,6 +57,18 @@ type smi struct {
 			ClocksEventReasonSwThermalSlowdown         string `xml:"clocks_event_reason_sw_thermal_slowdown"`
 			ClocksEventReasonSyncBoost                 string `xml:"clocks_event_reason_sync_boost"`
 		} `xml:"clocks_event_reasons"`
+		// Manually added, older drivers report the event reasons as throttle reasons
+		ClocksThrottleReasons struct {
+			ClocksThrottleReasonApplicationsClocksSetting string `xml:"clocks_throttle_reason_applications_clocks_setting"`
+			ClocksThrottleReasonDisplayClocksSetting      string `xml:"clocks_throttle_reason_display_clocks_setting"`
+			ClocksThrottleReasonGpuIdle                   string `xml:"clocks_throttle_reason_gpu_idle"`
+			ClocksThrottleReasonHwPowerBrakeSlowdown      string `xml:"clocks_throttle_reason_hw_power_brake_slowdown"`
+			ClocksThrottleReasonHwSlowdown                string `xml:"clocks_throttle_reason_hw_slowdown"`
+			ClocksThrottleReasonHwThermalSlowdown         string `xml:"clocks_throttle_reason_hw_thermal_slowdown"`
+			ClocksThrottleReasonSwPowerCap                string `xml:"clocks_throttle_reason_sw_power_cap"`
+			ClocksThrottleReasonSwThermalSlowdown         string `xml:"clocks_throttle_reason_sw_thermal_slowdown"`
+			ClocksThrottleReasonSyncBoost                 string `xml:"clocks_throttle_reason_sync_boost"`
+		} `xml:"clocks_throttle_reasons"`
 		ComputeMode               string `xml:"compute_mode"`
 		DefaultApplicationsClocks struct {
 			GraphicsClock string `xml:"graphics_clock"`
@@ -229,10 +250,12 @@ type smi struct {
 		} `xml:"power_readings"`
 		Processes struct {
 			ProcessInfo []struct {
-				Pid         string `xml:"pid"`
-				Type        string `xml:"type"`
-				ProcessName string `xml:"process_name"`
-				UsedMemory  string `xml:"used_memory"`
+				GpuInstanceID     string `xml:"gpu_instance_id"`
+				ComputeInstanceID string `xml:"compute_instance_id"`
+				Pid               string `xml:"pid"`
+				Type              string `xml:"type"`
+				ProcessName       string `xml:"process_name"`
+				UsedMemory        string `xml:"used_memory"`
 			} `xml:"process_info"`
 		} `xml:"processes"`
 		ProductArchitecture string `xml:"product_architecture"`
@@ -3,6 +3,7 @@ package opcua
 
 import (
 	_ "embed"
+	"fmt"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -16,10 +17,13 @@ import (
 var sampleConfig string
 
 type OpcUA struct {
+	Mode                 string          `toml:"mode"`
+	SubscriptionInterval config.Duration `toml:"subscription_interval"`
 	readClientConfig
 	Log telegraf.Logger `toml:"-"`
 
-	client *readClient
+	client     *readClient
+	subscriber *subscribeClient
 }
 
 func (*OpcUA) SampleConfig() string {
@@ -27,13 +31,30 @@ func (*OpcUA) SampleConfig() string {
 }
 
 func (o *OpcUA) Init() (err error) {
-	o.client, err = o.readClientConfig.createReadClient(o.Log)
+	switch o.Mode {
+	case "", "read":
+		o.client, err = o.readClientConfig.createReadClient(o.Log)
+	case "subscription":
+		o.subscriber, err = o.readClientConfig.createSubscribeClient(o.Log, time.Duration(o.SubscriptionInterval))
+	default:
+		return fmt.Errorf("invalid mode %q", o.Mode)
+	}
 	return err
 }
 
+func (*OpcUA) Start(telegraf.Accumulator) error {
+	return nil
+}
+
 func (o *OpcUA) Gather(acc telegraf.Accumulator) error {
 	// Will (re)connect if the client is disconnected
-	metrics, err := o.client.currentValues()
+	var metrics []telegraf.Metric
+	var err error
+	if o.subscriber != nil {
+		metrics, err = o.subscriber.currentValues()
+	} else {
+		metrics, err = o.client.currentValues()
+	}
 	if err != nil {
 		return err
 	}
@@ -45,10 +66,17 @@ func (o *OpcUA) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+func (o *OpcUA) Stop() {
+	if o.subscriber != nil {
+		o.subscriber.unsubscribe()
+	}
+}
+
 // Add this plugin to telegraf
 func init() {
 	inputs.Add("opcua", func() telegraf.Input {
 		return &OpcUA{
+			SubscriptionInterval: config.Duration(100 * time.Millisecond),
 			readClientConfig: readClientConfig{
 				InputClientConfig: input.InputClientConfig{
 					OpcUAClientConfig: opcua.OpcUAClientConfig{
@@ -437,3 +437,101 @@ use_unregistered_reads = true
 	require.EqualValues(t, map[string]string{"tag1": "override", "tag2": "val2"}, o.client.NodeMetricMapping[3].MetricTags)
 	require.EqualValues(t, map[string]string{"tag1": "val1", "tag2": "val2"}, o.client.NodeMetricMapping[4].MetricTags)
 }
+
+func TestSubscriptionModeConfig(t *testing.T) {
+	toml := `
+[[inputs.opcua]]
+name = "localhost"
+endpoint = "opc.tcp://localhost:4840"
+mode = "subscription"
+subscription_interval = "200ms"
+
+[[inputs.opcua.nodes]]
+  name = "name"
+  namespace = "1"
+  identifier_type = "s"
+  identifier="one"
+
+[[inputs.opcua.nodes]]
+  name = "name2"
+  namespace = "2"
+  identifier_type = "s"
+  identifier="two"
+  [inputs.opcua.nodes.monitoring_params]
+    sampling_interval = "50ms"
+    queue_size = 5
+    discard_oldest = false
+    [inputs.opcua.nodes.monitoring_params.data_change_filter]
+      trigger = "StatusValue"
+      deadband_type = "Absolute"
+      deadband_value = 0.5
+`
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(toml), config.EmptySourcePath))
+	require.Len(t, c.Inputs, 1)
+
+	o, ok := c.Inputs[0].Input.(*OpcUA)
+	require.True(t, ok)
+	require.Equal(t, "subscription", o.Mode)
+	require.Equal(t, config.Duration(200*time.Millisecond), o.SubscriptionInterval)
+
+	require.NoError(t, o.Init())
+	require.Nil(t, o.client)
+	require.NotNil(t, o.subscriber)
+	require.Equal(t, 200*time.Millisecond, o.subscriber.interval)
+	require.Len(t, o.subscriber.requests, 2)
+
+	req := o.subscriber.requests[0]
+	require.Equal(t, "ns=1;s=one", req.ItemToMonitor.NodeID.String())
+	require.Equal(t, uint32(0), req.RequestedParameters.ClientHandle)
+	require.Nil(t, req.RequestedParameters.Filter)
+
+	req = o.subscriber.requests[1]
+	require.Equal(t, "ns=2;s=two", req.ItemToMonitor.NodeID.String())
+	require.Equal(t, uint32(1), req.RequestedParameters.ClientHandle)
+	require.InDelta(t, 50.0, req.RequestedParameters.SamplingInterval, 1e-9)
+	require.Equal(t, uint32(5), req.RequestedParameters.QueueSize)
+	require.False(t, req.RequestedParameters.DiscardOldest)
+	require.NotNil(t, req.RequestedParameters.Filter)
+}
+
+func TestSubscriptionModeInvalidMonitoringParams(t *testing.T) {
+	plugin := &OpcUA{
+		Mode: "subscription",
+		readClientConfig: readClientConfig{
+			InputClientConfig: input.InputClientConfig{
+				OpcUAClientConfig: opcua.OpcUAClientConfig{
+					Endpoint:       "opc.tcp://localhost:4840",
+					SecurityPolicy: "None",
+					SecurityMode:   "None",
+					AuthMethod:     "Anonymous",
+				},
+				MetricName: "testing",
+				RootNodes: []input.NodeSettings{
+					{
+						FieldName:      "value",
+						Namespace:      "3",
+						IdentifierType: "i",
+						Identifier:     "1",
+						MonitoringParams: input.MonitoringParameters{
+							DataChangeFilter: &input.DataChangeFilter{
+								Trigger: "not_valid",
+							},
+						},
+					},
+				},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "trigger 'not_valid' not supported, node 'ns=3;i=1'")
+}
+
+func TestInvalidMode(t *testing.T) {
+	plugin := &OpcUA{
+		Mode: "foo",
+		Log:  testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid mode "foo"`)
+}
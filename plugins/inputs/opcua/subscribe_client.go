@@ -0,0 +1,178 @@
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	gopcua "github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/opcua"
+	"github.com/influxdata/telegraf/plugins/common/opcua/input"
+)
+
+// subscribeClient receives the node values via an OPC UA subscription and
+// buffers the reported changes until the next gather
+type subscribeClient struct {
+	*input.OpcUAInputClient
+
+	interval time.Duration
+	requests []*ua.MonitoredItemCreateRequest
+
+	// internal values
+	sub    *gopcua.Subscription
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	sync.Mutex
+	buffer []telegraf.Metric
+}
+
+func (rc *readClientConfig) createSubscribeClient(log telegraf.Logger, interval time.Duration) (*subscribeClient, error) {
+	inputClient, err := rc.InputClientConfig.CreateInputClient(log)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := inputClient.InitNodeIDs(); err != nil {
+		return nil, err
+	}
+
+	requests, err := inputClient.MonitoredItemRequests()
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscribeClient{
+		OpcUAInputClient: inputClient,
+		interval:         interval,
+		requests:         requests,
+	}, nil
+}
+
+// subscribe connects to the server and creates the subscription including
+// the monitored items for all nodes
+func (o *subscribeClient) subscribe() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := o.OpcUAClient.Connect(ctx); err != nil {
+		cancel()
+		return fmt.Errorf("connect failed: %w", err)
+	}
+
+	notifications := make(chan *gopcua.PublishNotificationData, 100)
+	sub, err := o.Client.Subscribe(ctx, &gopcua.SubscriptionParameters{Interval: o.interval}, notifications)
+	if err != nil {
+		cancel()
+		o.disconnect()
+		return fmt.Errorf("creating subscription failed: %w", err)
+	}
+	o.Log.Debugf("Subscribed with subscription ID %d", sub.SubscriptionID)
+
+	resp, err := sub.Monitor(ctx, ua.TimestampsToReturnBoth, o.requests...)
+	if err != nil {
+		cancel()
+		o.disconnect()
+		return fmt.Errorf("creating monitored items failed: %w", err)
+	}
+	for i, res := range resp.Results {
+		if !o.StatusCodeOK(res.StatusCode) {
+			cancel()
+			o.disconnect()
+			return fmt.Errorf("creating monitored item for node %q (%s) failed: %w",
+				o.NodeMetricMapping[i].Tag.FieldName, o.NodeIDs[i].String(), res.StatusCode)
+		}
+	}
+
+	o.sub = sub
+	o.cancel = cancel
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		o.processNotifications(ctx, notifications)
+	}()
+
+	return nil
+}
+
+// currentValues returns the metrics of all value changes received since
+// the last call and (re)subscribes if necessary
+func (o *subscribeClient) currentValues() ([]telegraf.Metric, error) {
+	if o.sub == nil || o.State() == opcua.Disconnected || o.State() == opcua.Closed {
+		o.unsubscribe()
+		if err := o.subscribe(); err != nil {
+			return nil, err
+		}
+	}
+
+	o.Lock()
+	defer o.Unlock()
+	metrics := o.buffer
+	o.buffer = nil
+
+	return metrics, nil
+}
+
+func (o *subscribeClient) processNotifications(ctx context.Context, notifications <-chan *gopcua.PublishNotificationData) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case res := <-notifications:
+			if res.Error != nil {
+				o.Log.Errorf("Receiving notification failed: %v", res.Error)
+				continue
+			}
+
+			notification, ok := res.Value.(*ua.DataChangeNotification)
+			if !ok {
+				o.Log.Debugf("Ignoring notification of type %s", reflect.TypeOf(res.Value))
+				continue
+			}
+
+			o.Lock()
+			for _, item := range notification.MonitoredItems {
+				i := int(item.ClientHandle)
+				if i >= len(o.NodeIDs) {
+					continue
+				}
+				o.UpdateNodeValue(i, item.Value)
+				if o.StatusCodeOK(o.LastReceivedData[i].Quality) {
+					o.buffer = append(o.buffer, o.MetricForNode(i))
+				}
+			}
+			o.Unlock()
+		}
+	}
+}
+
+// unsubscribe cancels the subscription and closes the connection
+func (o *subscribeClient) unsubscribe() {
+	if o.sub != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := o.sub.Cancel(ctx); err != nil {
+			o.Log.Debugf("Cancelling subscription failed: %v", err)
+		}
+		cancel()
+		o.sub = nil
+	}
+
+	if o.cancel != nil {
+		o.cancel()
+		o.wg.Wait()
+		o.cancel = nil
+	}
+	o.disconnect()
+}
+
+func (o *subscribeClient) disconnect() {
+	if o.Client == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	<-o.OpcUAInputClient.Stop(ctx)
+}
@@ -2,7 +2,6 @@ package opcua_listener
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"reflect"
 	"time"
@@ -35,52 +34,6 @@ type subscribeClient struct {
 	cancel context.CancelFunc
 }
 
-func checkDataChangeFilterParameters(params *input.DataChangeFilter) error {
-	switch {
-	case params.Trigger != input.Status &&
-		params.Trigger != input.StatusValue &&
-		params.Trigger != input.StatusValueTimestamp:
-		return fmt.Errorf("trigger '%s' not supported", params.Trigger)
-	case params.DeadbandType != input.Absolute &&
-		params.DeadbandType != input.Percent:
-		return fmt.Errorf("deadband_type '%s' not supported", params.DeadbandType)
-	case params.DeadbandValue == nil:
-		return errors.New("deadband_value was not set")
-	case *params.DeadbandValue < 0:
-		return errors.New("negative deadband_value not supported")
-	default:
-		return nil
-	}
-}
-
-func assignConfigValuesToRequest(req *ua.MonitoredItemCreateRequest, monParams *input.MonitoringParameters) error {
-	req.RequestedParameters.SamplingInterval = float64(time.Duration(monParams.SamplingInterval) / time.Millisecond)
-
-	if monParams.QueueSize != nil {
-		req.RequestedParameters.QueueSize = *monParams.QueueSize
-	}
-
-	if monParams.DiscardOldest != nil {
-		req.RequestedParameters.DiscardOldest = *monParams.DiscardOldest
-	}
-
-	if monParams.DataChangeFilter != nil {
-		if err := checkDataChangeFilterParameters(monParams.DataChangeFilter); err != nil {
-			return fmt.Errorf(err.Error()+", node '%s'", req.ItemToMonitor.NodeID)
-		}
-
-		req.RequestedParameters.Filter = ua.NewExtensionObject(
-			&ua.DataChangeFilter{
-				Trigger:       ua.DataChangeTriggerFromString(string(monParams.DataChangeFilter.Trigger)),
-				DeadbandType:  uint32(ua.DeadbandTypeFromString(string(monParams.DataChangeFilter.DeadbandType))),
-				DeadbandValue: *monParams.DataChangeFilter.DeadbandValue,
-			},
-		)
-	}
-
-	return nil
-}
-
 func (sc *subscribeClientConfig) createSubscribeClient(log telegraf.Logger) (*subscribeClient, error) {
 	client, err := sc.InputClientConfig.CreateInputClient(log)
 	if err != nil {
@@ -91,11 +44,17 @@ func (sc *subscribeClientConfig) createSubscribeClient(log telegraf.Logger) (*su
 		return nil, err
 	}
 
+	log.Debugf("Creating monitored items")
+	requests, err := client.MonitoredItemRequests()
+	if err != nil {
+		return nil, err
+	}
+
 	processingCtx, processingCancel := context.WithCancel(context.Background())
 	subClient := &subscribeClient{
 		OpcUAInputClient:   client,
 		Config:             *sc,
-		monitoredItemsReqs: make([]*ua.MonitoredItemCreateRequest, len(client.NodeIDs)),
+		monitoredItemsReqs: requests,
 		// 100 was chosen to make sure that the channels will not block when multiple changes come in at the same time.
 		// The channel size should be increased if reports come in on Telegraf blocking when many changes come in at
 		// the same time. It could be made dependent on the number of nodes subscribed to and the subscription interval.
@@ -105,16 +64,6 @@ func (sc *subscribeClientConfig) createSubscribeClient(log telegraf.Logger) (*su
 		cancel:            processingCancel,
 	}
 
-	log.Debugf("Creating monitored items")
-	for i, nodeID := range client.NodeIDs {
-		// The node id index (i) is used as the handle for the monitored item
-		req := opcua.NewMonitoredItemCreateRequestWithDefaults(nodeID, ua.AttributeIDValue, uint32(i))
-		if err := assignConfigValuesToRequest(req, &client.NodeMetricMapping[i].Tag.MonitoringParams); err != nil {
-			return nil, err
-		}
-		subClient.monitoredItemsReqs[i] = req
-	}
-
 	return subClient, nil
 }
 
@@ -0,0 +1,68 @@
+package opensearch_query
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const compositeName = "composite"
+
+type compositeSource struct {
+	name  string
+	field string
+}
+
+// compositeAggregationRequest groups the nested aggregation by all terms
+// sources at once and is paginated using the key of the last bucket
+type compositeAggregationRequest struct {
+	name          string
+	size          int
+	sources       []compositeSource
+	missingBucket bool
+	after         map[string]interface{}
+
+	nested aggregationRequest
+}
+
+func (c *compositeAggregationRequest) addAggregation(name, aggType, field string) error {
+	if aggType != "terms" {
+		return fmt.Errorf("aggregation function %q not supported", aggType)
+	}
+
+	c.sources = append(c.sources, compositeSource{name: name, field: field})
+
+	return nil
+}
+
+// page returns a copy of the request asking for the buckets after the given key
+func (c *compositeAggregationRequest) page(after map[string]interface{}) *compositeAggregationRequest {
+	p := *c
+	p.after = after
+	return &p
+}
+
+func (c *compositeAggregationRequest) MarshalJSON() ([]byte, error) {
+	sources := make([]interface{}, 0, len(c.sources))
+	for _, s := range c.sources {
+		terms := map[string]interface{}{"field": s.field}
+		if c.missingBucket {
+			terms["missing_bucket"] = true
+		}
+		sources = append(sources, map[string]interface{}{s.name: map[string]interface{}{"terms": terms}})
+	}
+
+	composite := map[string]interface{}{
+		"size":    c.size,
+		"sources": sources,
+	}
+	if c.after != nil {
+		composite["after"] = c.after
+	}
+
+	agg := map[string]interface{}{"composite": composite}
+	if c.nested != nil {
+		agg["aggregations"] = c.nested
+	}
+
+	return json.Marshal(map[string]interface{}{c.name: agg})
+}
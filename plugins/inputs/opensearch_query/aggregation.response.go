@@ -1,7 +1,11 @@
 package opensearch_query
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
 )
@@ -12,7 +16,13 @@ type aggregationResponse struct {
 }
 
 type searchHits struct {
-	TotalHits *totalHits `json:"total,omitempty"`
+	TotalHits *totalHits  `json:"total,omitempty"`
+	Hits      []searchHit `json:"hits"`
+}
+
+type searchHit struct {
+	Fields map[string]interface{} `json:"fields"`
+	Sort   []interface{}          `json:"sort"`
 }
 
 type totalHits struct {
@@ -23,8 +33,10 @@ type totalHits struct {
 type metricAggregation map[string]interface{}
 
 type aggregateValue struct {
-	metrics metricAggregation
-	buckets []bucketData
+	metrics          metricAggregation
+	buckets          []bucketData
+	afterKey         map[string]interface{}
+	sumOtherDocCount int64
 }
 
 type aggregation map[string]aggregateValue
@@ -33,6 +45,7 @@ type bucketData struct {
 	DocumentCount int64  `json:"doc_count"`
 	Key           string `json:"key"`
 
+	compositeKey   map[string]interface{}
 	subaggregation aggregation
 }
 
@@ -56,10 +69,21 @@ func (a *aggregation) getMetrics(acc telegraf.Accumulator, measurement string, d
 	for name, agg := range *a {
 		if agg.isAggregation() {
 			for _, bucket := range agg.buckets {
-				tt := map[string]string{name: bucket.Key}
+				tt := make(map[string]string, len(tags)+len(bucket.compositeKey)+1)
 				for k, v := range tags {
 					tt[k] = v
 				}
+				if bucket.compositeKey != nil {
+					// composite buckets contain the values of all tags, null
+					// values are only returned for documents missing the tag
+					for k, v := range bucket.compositeKey {
+						if v != nil {
+							tt[k] = fmt.Sprint(v)
+						}
+					}
+				} else {
+					tt[name] = bucket.Key
+				}
 				err = bucket.subaggregation.getMetrics(acc, measurement, bucket.DocumentCount, tt)
 				if err != nil {
 					return err
@@ -94,6 +118,16 @@ func (a *aggregateValue) UnmarshalJSON(bytes []byte) error {
 
 	// We'll continue to unmarshal if we have buckets
 	if b, found := partial["buckets"]; found {
+		if k, found := partial["after_key"]; found {
+			if err := json.Unmarshal(k, &a.afterKey); err != nil {
+				return err
+			}
+		}
+		if c, found := partial["sum_other_doc_count"]; found {
+			if err := json.Unmarshal(c, &a.sumOtherDocCount); err != nil {
+				return err
+			}
+		}
 		return json.Unmarshal(b, &a.buckets)
 	}
 
@@ -105,11 +139,11 @@ func (a *aggregateValue) isAggregation() bool {
 	return !(a.buckets == nil)
 }
 
-func (b *bucketData) UnmarshalJSON(bytes []byte) error {
+func (b *bucketData) UnmarshalJSON(data []byte) error {
 	var partial map[string]json.RawMessage
 	var err error
 
-	err = json.Unmarshal(bytes, &partial)
+	err = json.Unmarshal(data, &partial)
 	if err != nil {
 		return err
 	}
@@ -120,7 +154,14 @@ func (b *bucketData) UnmarshalJSON(bytes []byte) error {
 	}
 	delete(partial, "doc_count")
 
-	err = json.Unmarshal(partial["key"], &b.Key)
+	// composite aggregations return the values of all sources as key
+	if key := bytes.TrimSpace(partial["key"]); len(key) > 0 && key[0] == '{' {
+		decoder := json.NewDecoder(bytes.NewReader(key))
+		decoder.UseNumber()
+		err = decoder.Decode(&b.compositeKey)
+	} else {
+		err = json.Unmarshal(partial["key"], &b.Key)
+	}
 	if err != nil {
 		return err
 	}
@@ -141,3 +182,97 @@ func (b *bucketData) UnmarshalJSON(bytes []byte) error {
 
 	return nil
 }
+
+// compositeAfterKey returns the key for requesting the next page of the
+// composite aggregation, nil if the last page was reached
+func (a *aggregationResponse) compositeAfterKey() map[string]interface{} {
+	if a.Aggregations == nil {
+		return nil
+	}
+	agg, found := (*a.Aggregations)[compositeName]
+	if !found || len(agg.buckets) == 0 {
+		return nil
+	}
+	return agg.afterKey
+}
+
+// setMissingTags replaces the null values of the composite bucket keys
+func (a *aggregationResponse) setMissingTags(value string) {
+	if a.Aggregations == nil {
+		return
+	}
+	for _, bucket := range (*a.Aggregations)[compositeName].buckets {
+		for k, v := range bucket.compositeKey {
+			if v == nil {
+				bucket.compositeKey[k] = value
+			}
+		}
+	}
+}
+
+// sumOtherDocCount returns the number of documents not contained in the
+// buckets returned by the terms aggregations
+func (a *aggregation) sumOtherDocCount() int64 {
+	var count int64
+	for _, agg := range *a {
+		count += agg.sumOtherDocCount
+		for _, bucket := range agg.buckets {
+			count += bucket.subaggregation.sumOtherDocCount()
+		}
+	}
+	return count
+}
+
+// getDocumentMetrics adds a metric for each document hit using the requested
+// fields and the date field as sort value
+func (a *aggregationResponse) getDocumentMetrics(acc telegraf.Accumulator, agg osAggregation) {
+	if a.Hits == nil {
+		return
+	}
+
+	for _, hit := range a.Hits.Hits {
+		tags := make(map[string]string, len(agg.Tags))
+		for _, tag := range agg.Tags {
+			name := strings.ReplaceAll(tag, ".", "_")
+			if value, ok := firstValue(hit.Fields[tag]); ok {
+				tags[name] = fmt.Sprint(value)
+			} else if agg.IncludeMissingTag && agg.MissingTagValue != "" {
+				tags[name] = agg.MissingTagValue
+			}
+		}
+
+		fields := make(map[string]interface{}, len(agg.DocumentFields))
+		for _, field := range agg.DocumentFields {
+			if value, ok := firstValue(hit.Fields[field]); ok {
+				fields[strings.ReplaceAll(field, ".", "_")] = value
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		// dates are sorted by their value in milliseconds since epoch
+		if len(hit.Sort) > 0 {
+			if ms, ok := hit.Sort[0].(float64); ok {
+				acc.AddFields(agg.MeasurementName, fields, tags, time.UnixMilli(int64(ms)).UTC())
+				continue
+			}
+		}
+		acc.AddFields(agg.MeasurementName, fields, tags)
+	}
+}
+
+// firstValue returns the first value of a (multi-valued) field
+func firstValue(v interface{}) (interface{}, bool) {
+	if values, ok := v.([]interface{}); ok {
+		if len(values) == 0 {
+			return nil, false
+		}
+		v = values[0]
+	}
+	switch v.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return nil, false
+	}
+	return v, true
+}
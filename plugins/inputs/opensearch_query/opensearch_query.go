@@ -25,6 +25,8 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+const defaultPageSize = 1000
+
 type OpensearchQuery struct {
 	URLs                []string        `toml:"urls"`
 	Username            config.Secret   `toml:"username"`
@@ -52,11 +54,24 @@ type osAggregation struct {
 	Tags              []string        `toml:"tags"`
 	IncludeMissingTag bool            `toml:"include_missing_tag"`
 	MissingTagValue   string          `toml:"missing_tag_value"`
+	Composite         bool            `toml:"composite"`
+	RuntimeFields     []runtimeField  `toml:"runtime_field"`
+	DocumentFields    []string        `toml:"document_fields"`
+	PageSize          int             `toml:"page_size"`
+	TiebreakerField   string          `toml:"tiebreaker_field"`
 	mapMetricFields   map[string]string
 
 	aggregation aggregationRequest
 }
 
+// runtimeField is a field computed at query time from a script which is sent
+// as derived field with the search request
+type runtimeField struct {
+	Name   string `toml:"name"`
+	Type   string `toml:"type"`
+	Script string `toml:"script"`
+}
+
 func (*OpensearchQuery) SampleConfig() string {
 	return sampleConfig
 }
@@ -78,6 +93,20 @@ func (o *OpensearchQuery) Init() error {
 		if agg.DateField == "" {
 			return errors.New("field 'date_field' is not set")
 		}
+		if agg.PageSize < 0 {
+			return errors.New("field 'page_size' must not be negative")
+		}
+		if len(agg.DocumentFields) > 0 && len(agg.MetricFields) > 0 {
+			return errors.New("fields 'document_fields' and 'metric_fields' are mutually exclusive")
+		}
+		for _, rf := range agg.RuntimeFields {
+			if rf.Name == "" || rf.Type == "" || rf.Script == "" {
+				return fmt.Errorf("runtime field %q requires 'name', 'type' and 'script'", rf.Name)
+			}
+		}
+		if len(agg.DocumentFields) > 0 {
+			continue
+		}
 		err = o.initAggregation(agg, i)
 		if err != nil {
 			return err
@@ -136,6 +165,14 @@ func (o *OpensearchQuery) newClient() error {
 }
 
 func (o *OpensearchQuery) initAggregation(agg osAggregation, i int) (err error) {
+	// runtime fields are not part of the index mapping
+	if len(agg.RuntimeFields) > 0 && agg.mapMetricFields == nil {
+		agg.mapMetricFields = make(map[string]string, len(agg.RuntimeFields))
+	}
+	for _, rf := range agg.RuntimeFields {
+		agg.mapMetricFields[rf.Name] = rf.Type
+	}
+
 	for _, metricField := range agg.MetricFields {
 		if _, ok := agg.mapMetricFields[metricField]; !ok {
 			return fmt.Errorf("metric field %q not found on index %q", metricField, agg.Index)
@@ -152,48 +189,137 @@ func (o *OpensearchQuery) initAggregation(agg osAggregation, i int) (err error)
 }
 
 func (o *OpensearchQuery) osAggregationQuery(acc telegraf.Accumulator, aggregation osAggregation) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
-	defer cancel()
+	// use the same time window for all requests of a paginated query
+	q := aggregation.newQuery(time.Now().UTC())
+
+	if len(aggregation.DocumentFields) > 0 {
+		return o.runDocumentQuery(acc, aggregation, q)
+	}
 
-	searchResult, err := o.runAggregationQuery(ctx, aggregation)
+	if composite, ok := aggregation.aggregation.(*compositeAggregationRequest); ok {
+		return o.runCompositeQuery(acc, aggregation, q, composite)
+	}
+
+	searchResult, err := o.search(aggregation.Index, q)
 	if err != nil {
 		return err
 	}
 
+	// terms aggregations only return the top buckets, so warn about documents
+	// not being accounted for in the metrics
+	if searchResult.Aggregations != nil {
+		if n := searchResult.Aggregations.sumOtherDocCount(); n > 0 {
+			o.Log.Warnf("Terms aggregation for %q is incomplete, %d document(s) are not covered by the returned buckets; "+
+				"consider setting 'composite = true'", aggregation.MeasurementName, n)
+		}
+	}
+
 	return searchResult.getMetrics(acc, aggregation.MeasurementName)
 }
 
-func (o *OpensearchQuery) runAggregationQuery(ctx context.Context, aggregation osAggregation) (*aggregationResponse, error) {
-	now := time.Now().UTC()
+// runCompositeQuery pages through all buckets of the composite aggregation
+func (o *OpensearchQuery) runCompositeQuery(acc telegraf.Accumulator, aggregation osAggregation, q *query, composite *compositeAggregationRequest) error {
+	var after map[string]interface{}
+	for {
+		q.Aggregations = composite.page(after)
+		searchResult, err := o.search(aggregation.Index, q)
+		if err != nil {
+			return err
+		}
+
+		if aggregation.IncludeMissingTag && aggregation.MissingTagValue != "" {
+			searchResult.setMissingTags(aggregation.MissingTagValue)
+		}
+		if err := searchResult.getMetrics(acc, aggregation.MeasurementName); err != nil {
+			return err
+		}
+
+		after = searchResult.compositeAfterKey()
+		if after == nil {
+			return nil
+		}
+	}
+}
+
+// runDocumentQuery pages through all documents matching the query using
+// search-after and creates a metric per document
+func (o *OpensearchQuery) runDocumentQuery(acc telegraf.Accumulator, aggregation osAggregation, q *query) error {
+	tiebreaker := aggregation.TiebreakerField
+	if tiebreaker == "" {
+		tiebreaker = "_id"
+	}
+
+	q.Size = aggregation.pageSize()
+	q.Aggregations = nil
+	q.Source = false
+	q.Fields = make([]string, 0, len(aggregation.DocumentFields)+len(aggregation.Tags))
+	q.Fields = append(q.Fields, aggregation.DocumentFields...)
+	q.Fields = append(q.Fields, aggregation.Tags...)
+	q.Sort = []map[string]string{{aggregation.DateField: "asc"}, {tiebreaker: "asc"}}
+
+	for {
+		searchResult, err := o.search(aggregation.Index, q)
+		if err != nil {
+			return err
+		}
+		if searchResult.Hits == nil || len(searchResult.Hits.Hits) == 0 {
+			return nil
+		}
+
+		searchResult.getDocumentMetrics(acc, aggregation)
+
+		hits := searchResult.Hits.Hits
+		if len(hits) < q.Size {
+			return nil
+		}
+		q.SearchAfter = hits[len(hits)-1].Sort
+	}
+}
+
+// newQuery returns the search request of the aggregation for the time window
+// ending at the given time
+func (aggregation *osAggregation) newQuery(now time.Time) *query {
 	from := now.Add(time.Duration(-aggregation.QueryPeriod))
 	filterQuery := aggregation.FilterQuery
 	if filterQuery == "" {
 		filterQuery = "*"
 	}
 
-	aq := &query{
+	q := &query{
 		Size:         0,
 		Aggregations: aggregation.aggregation,
-		Query:        nil,
+		Query: &boolQuery{
+			FilterQueryString: filterQuery,
+			TimestampField:    aggregation.DateField,
+			TimeRangeFrom:     from,
+			TimeRangeTo:       now,
+			DateFieldFormat:   aggregation.DateFieldFormat,
+		},
 	}
 
-	boolQuery := &boolQuery{
-		FilterQueryString: filterQuery,
-		TimestampField:    aggregation.DateField,
-		TimeRangeFrom:     from,
-		TimeRangeTo:       now,
-		DateFieldFormat:   aggregation.DateFieldFormat,
+	if len(aggregation.RuntimeFields) > 0 {
+		q.Derived = make(map[string]derivedField, len(aggregation.RuntimeFields))
+		for _, rf := range aggregation.RuntimeFields {
+			q.Derived[rf.Name] = derivedField{Type: rf.Type, Script: map[string]string{"source": rf.Script}}
+		}
 	}
 
-	aq.Query = boolQuery
-	req, err := json.Marshal(aq)
+	return q
+}
+
+// search sends the request with a timeout applying to this single request
+func (o *OpensearchQuery) search(index string, q *query) (*aggregationResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+	defer cancel()
+
+	req, err := json.Marshal(q)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	searchRequest := &opensearchapi.SearchRequest{
 		Body:    strings.NewReader(string(req)),
-		Index:   []string{aggregation.Index},
+		Index:   []string{index},
 		Timeout: time.Duration(o.Timeout),
 	}
 
@@ -201,10 +327,10 @@ func (o *OpensearchQuery) runAggregationQuery(ctx context.Context, aggregation o
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	if resp.IsError() {
 		return nil, fmt.Errorf("opensearch SearchRequest failure: [%d] %s", resp.StatusCode, resp.Status())
 	}
-	defer resp.Body.Close()
 
 	var searchResult aggregationResponse
 
@@ -217,6 +343,14 @@ func (o *OpensearchQuery) runAggregationQuery(ctx context.Context, aggregation o
 	return &searchResult, nil
 }
 
+// pageSize returns the number of buckets or documents to request per page
+func (aggregation *osAggregation) pageSize() int {
+	if aggregation.PageSize > 0 {
+		return aggregation.PageSize
+	}
+	return defaultPageSize
+}
+
 func (aggregation *osAggregation) buildAggregationQuery() error {
 	var agg aggregationRequest
 	agg = &metricAggregationRequest{}
@@ -235,6 +369,24 @@ func (aggregation *osAggregation) buildAggregationQuery() error {
 		}
 	}
 
+	// create a single composite aggregation over all tags, paginated when querying
+	if aggregation.Composite && len(aggregation.Tags) > 0 {
+		composite := &compositeAggregationRequest{
+			name:          compositeName,
+			size:          aggregation.pageSize(),
+			missingBucket: aggregation.IncludeMissingTag && aggregation.MissingTagValue != "",
+			nested:        agg,
+		}
+		for _, term := range aggregation.Tags {
+			if err := composite.addAggregation(strings.ReplaceAll(term, ".", "_"), "terms", term); err != nil {
+				return err
+			}
+		}
+		aggregation.aggregation = composite
+
+		return nil
+	}
+
 	// create a terms aggregation per tag
 	for _, term := range aggregation.Tags {
 		bucket := &bucketAggregationRequest{}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"strings"
@@ -18,6 +20,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -690,3 +693,242 @@ func TestMetricAggregationMarshal(t *testing.T) {
 	_, err = json.Marshal(bucket)
 	require.NoError(t, err)
 }
+
+func newMockServer(t *testing.T, search func(body map[string]interface{}) string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/test/_search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(search(body))); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestCompositeAggregation(t *testing.T) {
+	var requests []map[string]interface{}
+	server := newMockServer(t, func(body map[string]interface{}) string {
+		requests = append(requests, body)
+		aggs := body["aggregations"].(map[string]interface{})
+		composite := aggs["composite"].(map[string]interface{})["composite"].(map[string]interface{})
+		if _, found := composite["after"]; !found {
+			return `{"hits": {"total": {"value": 7}}, "aggregations": {"composite": {
+				"after_key": {"URI_keyword": "/b", "response": 404},
+				"buckets": [
+					{"key": {"URI_keyword": "/a", "response": 200}, "doc_count": 2, "size_avg": {"value": 10}},
+					{"key": {"URI_keyword": "/b", "response": 404}, "doc_count": 1, "size_avg": {"value": 20}}
+				]}}}`
+		}
+		if after := composite["after"].(map[string]interface{}); after["URI_keyword"] == "/b" {
+			return `{"hits": {"total": {"value": 7}}, "aggregations": {"composite": {
+				"after_key": {"URI_keyword": null, "response": 200},
+				"buckets": [
+					{"key": {"URI_keyword": null, "response": 200}, "doc_count": 4, "size_avg": {"value": 30}}
+				]}}}`
+		}
+		return `{"hits": {"total": {"value": 7}}, "aggregations": {"composite": {"buckets": []}}}`
+	})
+	defer server.Close()
+
+	plugin := &OpensearchQuery{
+		URLs:    []string{server.URL},
+		Timeout: config.Duration(5 * time.Second),
+		Aggregations: []osAggregation{
+			{
+				Index:             "test",
+				MeasurementName:   "requests",
+				DateField:         "@timestamp",
+				QueryPeriod:       queryPeriod,
+				MetricFields:      []string{"size"},
+				MetricFunction:    "avg",
+				Tags:              []string{"URI.keyword", "response"},
+				IncludeMissingTag: true,
+				MissingTagValue:   "none",
+				Composite:         true,
+				PageSize:          2,
+				mapMetricFields:   map[string]string{"size": "long"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"requests",
+			map[string]string{"URI_keyword": "/a", "response": "200"},
+			map[string]interface{}{"size_avg_value": float64(10), "doc_count": int64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"requests",
+			map[string]string{"URI_keyword": "/b", "response": "404"},
+			map[string]interface{}{"size_avg_value": float64(20), "doc_count": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"requests",
+			map[string]string{"URI_keyword": "none", "response": "200"},
+			map[string]interface{}{"size_avg_value": float64(30), "doc_count": int64(4)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+
+	// all pages must use the same time window
+	require.Len(t, requests, 3)
+	for _, r := range requests[1:] {
+		require.Equal(t, requests[0]["query"], r["query"])
+	}
+	composite := requests[0]["aggregations"].(map[string]interface{})["composite"].(map[string]interface{})
+	require.Contains(t, composite["aggregations"], "size_avg")
+	require.InDelta(t, 2, composite["composite"].(map[string]interface{})["size"], 0)
+}
+
+func TestDocumentQuery(t *testing.T) {
+	var requests []map[string]interface{}
+	server := newMockServer(t, func(body map[string]interface{}) string {
+		requests = append(requests, body)
+		if _, found := body["search_after"]; !found {
+			return `{"hits": {"total": {"value": 3}, "hits": [
+				{"_id": "1", "sort": [1718344313000, "1"], "fields": {"size": [10], "duration": [1.5], "URI.keyword": ["/a"]}},
+				{"_id": "2", "sort": [1718344314000, "2"], "fields": {"size": [20]}}
+			]}}`
+		}
+		return `{"hits": {"total": {"value": 3}, "hits": [
+			{"_id": "3", "sort": [1718344315000, "3"], "fields": {"size": [30, 31], "URI.keyword": ["/b"]}}
+		]}}`
+	})
+	defer server.Close()
+
+	plugin := &OpensearchQuery{
+		URLs:    []string{server.URL},
+		Timeout: config.Duration(5 * time.Second),
+		Aggregations: []osAggregation{
+			{
+				Index:           "test",
+				MeasurementName: "requests",
+				DateField:       "@timestamp",
+				QueryPeriod:     queryPeriod,
+				DocumentFields:  []string{"size", "duration"},
+				Tags:            []string{"URI.keyword"},
+				PageSize:        2,
+				RuntimeFields: []runtimeField{
+					{
+						Name:   "duration",
+						Type:   "double",
+						Script: "emit(doc['response_time'].value / 1000.0)",
+					},
+				},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"requests",
+			map[string]string{"URI_keyword": "/a"},
+			map[string]interface{}{"size": float64(10), "duration": float64(1.5)},
+			time.UnixMilli(1718344313000),
+		),
+		metric.New(
+			"requests",
+			map[string]string{},
+			map[string]interface{}{"size": float64(20)},
+			time.UnixMilli(1718344314000),
+		),
+		metric.New(
+			"requests",
+			map[string]string{"URI_keyword": "/b"},
+			map[string]interface{}{"size": float64(30)},
+			time.UnixMilli(1718344315000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	require.Len(t, requests, 2)
+	require.Equal(t, []interface{}{float64(1718344314000), "2"}, requests[1]["search_after"])
+	require.Equal(t, requests[0]["query"], requests[1]["query"])
+	require.Equal(t, map[string]interface{}{
+		"duration": map[string]interface{}{
+			"type":   "double",
+			"script": map[string]interface{}{"source": "emit(doc['response_time'].value / 1000.0)"},
+		},
+	}, requests[0]["derived"])
+	require.NotContains(t, requests[0], "aggregations")
+}
+
+func TestTermsAggregationResponse(t *testing.T) {
+	response := `{"hits": {"total": {"value": 10}}, "aggregations": {"URI_keyword": {
+		"sum_other_doc_count": 3,
+		"buckets": [
+			{"key": "/a", "doc_count": 5, "response_keyword": {"sum_other_doc_count": 1, "buckets": [
+				{"key": "200", "doc_count": 4}
+			]}},
+			{"key": "/b", "doc_count": 2, "response_keyword": {"sum_other_doc_count": 0, "buckets": [
+				{"key": "404", "doc_count": 2}
+			]}}
+		]}}}`
+
+	var result aggregationResponse
+	require.NoError(t, json.Unmarshal([]byte(response), &result))
+	require.Equal(t, int64(4), result.Aggregations.sumOtherDocCount())
+	require.Nil(t, result.compositeAfterKey())
+}
+
+func TestInitInvalidAggregation(t *testing.T) {
+	tests := []struct {
+		name        string
+		aggregation osAggregation
+		expected    string
+	}{
+		{
+			name: "document and metric fields",
+			aggregation: osAggregation{
+				MeasurementName: "requests",
+				DateField:       "@timestamp",
+				MetricFields:    []string{"size"},
+				DocumentFields:  []string{"size"},
+			},
+			expected: "mutually exclusive",
+		},
+		{
+			name: "runtime field without script",
+			aggregation: osAggregation{
+				MeasurementName: "requests",
+				DateField:       "@timestamp",
+				RuntimeFields:   []runtimeField{{Name: "duration", Type: "double"}},
+			},
+			expected: "requires 'name', 'type' and 'script'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &OpensearchQuery{
+				URLs:         []string{"http://localhost:9200"},
+				Aggregations: []osAggregation{tt.aggregation},
+				Log:          testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
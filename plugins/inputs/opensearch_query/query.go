@@ -6,9 +6,19 @@ import (
 )
 
 type query struct {
-	Size         int                `json:"size"`
-	Aggregations aggregationRequest `json:"aggregations"`
-	Query        interface{}        `json:"query,omitempty"`
+	Size         int                     `json:"size"`
+	Aggregations aggregationRequest      `json:"aggregations,omitempty"`
+	Query        interface{}             `json:"query,omitempty"`
+	Derived      map[string]derivedField `json:"derived,omitempty"`
+	Source       interface{}             `json:"_source,omitempty"`
+	Fields       []string                `json:"fields,omitempty"`
+	Sort         []map[string]string     `json:"sort,omitempty"`
+	SearchAfter  []interface{}           `json:"search_after,omitempty"`
+}
+
+type derivedField struct {
+	Type   string            `json:"type"`
+	Script map[string]string `json:"script"`
 }
 
 type boolQuery struct {
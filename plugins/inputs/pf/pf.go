@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os/exec"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -66,11 +67,13 @@ const (
 )
 
 type PF struct {
-	UseSudo bool `toml:"use_sudo"`
+	UseSudo bool     `toml:"use_sudo"`
+	Collect []string `toml:"collect"`
 
 	pfctlCommand string
 	pfctlArgs    []string
 	infoFunc     func() (string, error)
+	showFunc     func(args ...string) (string, error)
 }
 
 type pfctlOutputStanza struct {
@@ -89,6 +92,17 @@ func (*PF) SampleConfig() string {
 	return sampleConfig
 }
 
+func (pf *PF) Init() error {
+	for _, c := range pf.Collect {
+		switch c {
+		case "limits", "labels", "queues":
+		default:
+			return fmt.Errorf("invalid 'collect' value %q", c)
+		}
+	}
+	return nil
+}
+
 func (pf *PF) Gather(acc telegraf.Accumulator) error {
 	if pf.pfctlCommand == "" {
 		var err error
@@ -104,9 +118,45 @@ func (pf *PF) Gather(acc telegraf.Accumulator) error {
 		return nil
 	}
 
-	if perr := parsePfctlOutput(o, acc); perr != nil {
+	fields, perr := parsePfctlOutput(o)
+	if perr != nil {
 		acc.AddError(perr)
+		return nil
 	}
+
+	for _, c := range pf.Collect {
+		switch c {
+		case "limits":
+			out, err := pf.showFunc("-s", "memory")
+			if err != nil {
+				acc.AddError(err)
+				continue
+			}
+			if err := parseLimits(out, fields); err != nil {
+				acc.AddError(err)
+			}
+		case "labels":
+			out, err := pf.showFunc("-s", "labels")
+			if err != nil {
+				acc.AddError(err)
+				continue
+			}
+			if err := parseLabels(out, acc); err != nil {
+				acc.AddError(err)
+			}
+		case "queues":
+			out, err := pf.showFunc("-v", "-s", "queue")
+			if err != nil {
+				acc.AddError(err)
+				continue
+			}
+			if err := parseQueues(out, acc); err != nil {
+				acc.AddError(err)
+			}
+		}
+	}
+
+	acc.AddFields(measurement, fields, make(map[string]string))
 	return nil
 }
 
@@ -114,7 +164,7 @@ func errMissingData(tag string) error {
 	return fmt.Errorf("struct data for tag %q not found in %s output", tag, pfctlCommand)
 }
 
-func parsePfctlOutput(pfoutput string, acc telegraf.Accumulator) error {
+func parsePfctlOutput(pfoutput string) (map[string]interface{}, error) {
 	fields := make(map[string]interface{})
 	scanner := bufio.NewScanner(strings.NewReader(pfoutput))
 	for scanner.Scan() {
@@ -133,7 +183,7 @@ func parsePfctlOutput(pfoutput string, acc telegraf.Accumulator) error {
 					line = scanner.Text()
 				}
 				if perr := s.parseFunc(stanzaLines, fields); perr != nil {
-					return perr
+					return nil, perr
 				}
 				s.found = true
 			}
@@ -141,12 +191,11 @@ func parsePfctlOutput(pfoutput string, acc telegraf.Accumulator) error {
 	}
 	for _, s := range pfctlOutputStanzas {
 		if !s.found {
-			return errParseHeader
+			return nil, errParseHeader
 		}
 	}
 
-	acc.AddFields(measurement, fields, make(map[string]string))
-	return nil
+	return fields, nil
 }
 
 func parseStateTable(lines []string, fields map[string]interface{}) error {
@@ -183,7 +232,18 @@ func storeFieldValues(lines []string, regex *regexp.Regexp, fields map[string]in
 }
 
 func (pf *PF) callPfctl() (string, error) {
-	cmd := execCommand(pf.pfctlCommand, pf.pfctlArgs...)
+	return pf.runPfctl(pf.pfctlArgs)
+}
+
+// callPfctlShow runs pfctl with the given arguments instead of "-s info"
+// while keeping the sudo invocation
+func (pf *PF) callPfctlShow(args ...string) (string, error) {
+	prefix := pf.pfctlArgs[:len(pf.pfctlArgs)-2]
+	return pf.runPfctl(append(slices.Clone(prefix), args...))
+}
+
+func (pf *PF) runPfctl(args []string) (string, error) {
+	cmd := execCommand(pf.pfctlCommand, args...)
 	out, oerr := cmd.Output()
 	if oerr != nil {
 		var ee *exec.ExitError
@@ -215,6 +275,7 @@ func init() {
 	inputs.Add("pf", func() telegraf.Input {
 		pf := &PF{}
 		pf.infoFunc = pf.callPfctl
+		pf.showFunc = pf.callPfctlShow
 		return pf
 	})
 }
@@ -4,8 +4,14 @@ import (
 	"log"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -271,3 +277,154 @@ Counters
 		})
 	}
 }
+
+func TestPfCollect(t *testing.T) {
+	info := `Status: Enabled for 0 days 00:26:05           Debug: Urgent
+
+State Table                          Total             Rate
+  current entries                     2500
+  searches                           11325            7.2/s
+  inserts                                5            0.0/s
+  removals                               3            0.0/s
+Counters
+  match                              11226            7.2/s
+  bad-offset                             0            0.0/s
+  fragment                               0            0.0/s
+  short                                  0            0.0/s
+  normalize                              0            0.0/s
+  memory                                 0            0.0/s
+  bad-timestamp                          0            0.0/s
+  congestion                             0            0.0/s
+  ip-option                              0            0.0/s
+  proto-cksum                            0            0.0/s
+  state-mismatch                         0            0.0/s
+  state-insert                           0            0.0/s
+  state-limit                            0            0.0/s
+  src-limit                              0            0.0/s
+  synproxy                               0            0.0/s
+`
+	outputs := map[string]string{
+		"-s memory": `states        hard limit    10000
+src-nodes     hard limit    10000
+frags         hard limit     5000
+table-entries hard limit   200000
+`,
+		"-s labels": `ssh in 120 40 5000 30 3000 10 2000 2
+web 300 200 90000 100 20000 100 70000 12
+web 10 5 1000 5 1000 0 0 1
+`,
+		"-v -s queue": `queue root_em0 on em0 bandwidth 1Gb priority 0 {std, ssh}
+  [ pkts:      12345  bytes:    1234567  dropped pkts:      0 bytes:      0 ]
+  [ qlength:   0/ 50 ]
+queue  std on em0 bandwidth 800Kb cbq( default )
+  [ pkts:       1234  bytes:     123456  dropped pkts:      5 bytes:    300 ]
+  [ qlength:   3/ 50  borrows:      7  suspends:      1 ]
+`,
+	}
+
+	plugin := &PF{
+		Collect:      []string{"limits", "labels", "queues"},
+		pfctlCommand: "pfctl",
+		pfctlArgs:    []string{"-s", "info"},
+		infoFunc: func() (string, error) {
+			return info, nil
+		},
+		showFunc: func(args ...string) (string, error) {
+			return outputs[strings.Join(args, " ")], nil
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New("pf",
+			map[string]string{},
+			map[string]interface{}{
+				"entries":              int64(2500),
+				"searches":             int64(11325),
+				"inserts":              int64(5),
+				"removals":             int64(3),
+				"match":                int64(11226),
+				"bad-offset":           int64(0),
+				"fragment":             int64(0),
+				"short":                int64(0),
+				"normalize":            int64(0),
+				"memory":               int64(0),
+				"bad-timestamp":        int64(0),
+				"congestion":           int64(0),
+				"ip-option":            int64(0),
+				"proto-cksum":          int64(0),
+				"state-mismatch":       int64(0),
+				"state-insert":         int64(0),
+				"state-limit":          int64(0),
+				"src-limit":            int64(0),
+				"synproxy":             int64(0),
+				"states_limit":         int64(10000),
+				"states_usage_percent": float64(25),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("pf_label",
+			map[string]string{"label": "ssh in"},
+			map[string]interface{}{
+				"evaluations": int64(120),
+				"packets":     int64(40),
+				"bytes":       int64(5000),
+				"packets_in":  int64(30),
+				"bytes_in":    int64(3000),
+				"packets_out": int64(10),
+				"bytes_out":   int64(2000),
+				"states":      int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("pf_label",
+			map[string]string{"label": "web"},
+			map[string]interface{}{
+				"evaluations": int64(310),
+				"packets":     int64(205),
+				"bytes":       int64(91000),
+				"packets_in":  int64(105),
+				"bytes_in":    int64(21000),
+				"packets_out": int64(100),
+				"bytes_out":   int64(70000),
+				"states":      int64(13),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("pf_queue",
+			map[string]string{"queue": "root_em0", "interface": "em0"},
+			map[string]interface{}{
+				"packets":         int64(12345),
+				"bytes":           int64(1234567),
+				"dropped_packets": int64(0),
+				"dropped_bytes":   int64(0),
+				"length":          int64(0),
+				"limit":           int64(50),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("pf_queue",
+			map[string]string{"queue": "std", "interface": "em0"},
+			map[string]interface{}{
+				"packets":         int64(1234),
+				"bytes":           int64(123456),
+				"dropped_packets": int64(5),
+				"dropped_bytes":   int64(300),
+				"length":          int64(3),
+				"limit":           int64(50),
+				"borrows":         int64(7),
+				"suspends":        int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestPfCollectInvalid(t *testing.T) {
+	plugin := &PF{Collect: []string{"tables"}}
+	require.EqualError(t, plugin.Init(), `invalid 'collect' value "tables"`)
+}
@@ -0,0 +1,155 @@
+package pf
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+var (
+	limitRE         = regexp.MustCompile(`^(\S+)\s+hard limit\s+(\d+)`)
+	queueHeaderRE   = regexp.MustCompile(`^queue\s+(\S+)\s+on\s+(\S+)`)
+	queueCountersRE = regexp.MustCompile(`pkts:\s*(\d+)\s+bytes:\s*(\d+)\s+dropped pkts:\s*(\d+)\s+bytes:\s*(\d+)`)
+	queueLengthRE   = regexp.MustCompile(`qlength:\s*(\d+)\s*/\s*(\d+)`)
+	queueBorrowsRE  = regexp.MustCompile(`borrows:\s*(\d+)`)
+	queueSuspendsRE = regexp.MustCompile(`suspends:\s*(\d+)`)
+
+	// Names of the counters in the order of the "pfctl -s labels" output
+	labelFields = []string{
+		"evaluations",
+		"packets",
+		"bytes",
+		"packets_in",
+		"bytes_in",
+		"packets_out",
+		"bytes_out",
+		"states",
+	}
+)
+
+// parseLimits adds the hard limit of the state table and its usage based on
+// the current entries to the fields
+func parseLimits(pfoutput string, fields map[string]interface{}) error {
+	scanner := bufio.NewScanner(strings.NewReader(pfoutput))
+	for scanner.Scan() {
+		matches := limitRE.FindStringSubmatch(scanner.Text())
+		if matches == nil || matches[1] != "states" {
+			continue
+		}
+		limit, err := strconv.ParseInt(matches[2], 10, 64)
+		if err != nil {
+			return err
+		}
+		fields["states_limit"] = limit
+		if entries, ok := fields["entries"].(int64); ok && limit > 0 {
+			fields["states_usage_percent"] = float64(entries) / float64(limit) * 100
+		}
+		return nil
+	}
+	return errMissingData("states hard limit")
+}
+
+// parseLabels adds the counters of the rules per label. Counters of rules
+// sharing the same label are summed up.
+func parseLabels(pfoutput string, acc telegraf.Accumulator) error {
+	counters := make(map[string][]int64)
+	var order []string
+
+	scanner := bufio.NewScanner(strings.NewReader(pfoutput))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 0 {
+			continue
+		}
+		if len(parts) <= len(labelFields) {
+			return fmt.Errorf("unexpected label line %q in %s output", scanner.Text(), pfctlCommand)
+		}
+
+		// Labels may contain whitespace, so the counters are taken from the end
+		split := len(parts) - len(labelFields)
+		label := strings.Join(parts[:split], " ")
+		values, found := counters[label]
+		if !found {
+			values = make([]int64, len(labelFields))
+			counters[label] = values
+			order = append(order, label)
+		}
+		for i, raw := range parts[split:] {
+			v, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("parsing counter %q of label %q failed: %w", labelFields[i], label, err)
+			}
+			values[i] += v
+		}
+	}
+
+	for _, label := range order {
+		fields := make(map[string]interface{}, len(labelFields))
+		for i, name := range labelFields {
+			fields[name] = counters[label][i]
+		}
+		acc.AddFields("pf_label", fields, map[string]string{"label": label})
+	}
+	return nil
+}
+
+// parseQueues adds the statistics of the ALTQ queues as reported by
+// "pfctl -v -s queue"
+func parseQueues(pfoutput string, acc telegraf.Accumulator) error {
+	var tags map[string]string
+	var fields map[string]interface{}
+	flush := func() {
+		if len(fields) > 0 {
+			acc.AddFields("pf_queue", fields, tags)
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(pfoutput))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if matches := queueHeaderRE.FindStringSubmatch(line); matches != nil {
+			flush()
+			tags = map[string]string{"queue": matches[1], "interface": matches[2]}
+			fields = make(map[string]interface{}, 8)
+			continue
+		}
+		if tags == nil {
+			continue
+		}
+
+		if matches := queueCountersRE.FindStringSubmatch(line); matches != nil {
+			for i, name := range []string{"packets", "bytes", "dropped_packets", "dropped_bytes"} {
+				v, err := strconv.ParseInt(matches[i+1], 10, 64)
+				if err != nil {
+					return err
+				}
+				fields[name] = v
+			}
+		}
+		if matches := queueLengthRE.FindStringSubmatch(line); matches != nil {
+			for i, name := range []string{"length", "limit"} {
+				v, err := strconv.ParseInt(matches[i+1], 10, 64)
+				if err != nil {
+					return err
+				}
+				fields[name] = v
+			}
+		}
+		for name, re := range map[string]*regexp.Regexp{"borrows": queueBorrowsRE, "suspends": queueSuspendsRE} {
+			if matches := re.FindStringSubmatch(line); matches != nil {
+				v, err := strconv.ParseInt(matches[1], 10, 64)
+				if err != nil {
+					return err
+				}
+				fields[name] = v
+			}
+		}
+	}
+	flush()
+
+	return nil
+}
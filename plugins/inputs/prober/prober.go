@@ -0,0 +1,351 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package prober
+
+import (
+	"crypto/tls"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Maximum size of the response body passed to the expressions
+const maxBodySize = 32 * 1024 * 1024
+
+type Prober struct {
+	URLs            []string          `toml:"urls"`
+	Method          string            `toml:"method"`
+	Body            string            `toml:"body"`
+	Headers         map[string]string `toml:"headers"`
+	Timeout         config.Duration   `toml:"timeout"`
+	FollowRedirects bool              `toml:"follow_redirects"`
+	Check           string            `toml:"check"`
+	Fields          []Extraction      `toml:"field"`
+	Tags            []Extraction      `toml:"tag"`
+	Log             telegraf.Logger   `toml:"-"`
+	common_tls.ClientConfig
+
+	client      *http.Client
+	check       cel.Program
+	extractions []*extraction
+}
+
+// Extraction defines a field or tag computed from the response using a CEL
+// expression
+type Extraction struct {
+	Name       string `toml:"name"`
+	Expression string `toml:"expression"`
+	Optional   bool   `toml:"optional"`
+}
+
+type extraction struct {
+	Extraction
+	tag     bool
+	program cel.Program
+}
+
+func (*Prober) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Prober) Init() error {
+	if len(p.URLs) == 0 {
+		return errors.New("no URLs configured")
+	}
+	if p.Method == "" {
+		p.Method = http.MethodGet
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = config.Duration(5 * time.Second)
+	}
+	if p.Check == "" {
+		p.Check = "status >= 200 && status < 400"
+	}
+
+	env, err := cel.NewEnv(
+		cel.Declarations(
+			decls.NewVar("url", decls.String),
+			decls.NewVar("status", decls.Int),
+			decls.NewVar("headers", decls.NewMapType(decls.String, decls.String)),
+			decls.NewVar("body", decls.String),
+			decls.NewVar("json", decls.Dyn),
+			decls.NewVar("response_time", decls.Double),
+			decls.NewVar("tls", decls.Dyn),
+		),
+		cel.Function(
+			"now",
+			cel.Overload("now", nil, cel.TimestampType),
+			cel.SingletonFunctionBinding(func(_ ...ref.Val) ref.Val { return types.Timestamp{Time: time.Now()} }),
+		),
+		ext.Encoders(),
+		ext.Math(),
+		ext.Strings(),
+	)
+	if err != nil {
+		return fmt.Errorf("creating environment failed: %w", err)
+	}
+
+	// Compile the check which must return a boolean
+	ast, issues := env.Compile(p.Check)
+	if issues.Err() != nil {
+		return fmt.Errorf("compiling check failed: %w", issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return errors.New("check needs to return a boolean")
+	}
+	if p.check, err = env.Program(ast, cel.EvalOptions(cel.OptOptimize)); err != nil {
+		return fmt.Errorf("creating check program failed: %w", err)
+	}
+
+	// Compile the field and tag extractions
+	p.extractions = make([]*extraction, 0, len(p.Fields)+len(p.Tags))
+	for i, e := range append(append(make([]Extraction, 0, len(p.Fields)+len(p.Tags)), p.Fields...), p.Tags...) {
+		if e.Name == "" {
+			return errors.New("extraction without 'name'")
+		}
+		if e.Expression == "" {
+			return fmt.Errorf("extraction %q without 'expression'", e.Name)
+		}
+
+		ast, issues := env.Compile(e.Expression)
+		if issues.Err() != nil {
+			return fmt.Errorf("compiling expression of %q failed: %w", e.Name, issues.Err())
+		}
+		program, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+		if err != nil {
+			return fmt.Errorf("creating program of %q failed: %w", e.Name, err)
+		}
+		p.extractions = append(p.extractions, &extraction{
+			Extraction: e,
+			tag:        i >= len(p.Fields),
+			program:    program,
+		})
+	}
+
+	tlsCfg, err := p.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	p.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+			TLSClientConfig:   tlsCfg,
+		},
+		Timeout: time.Duration(p.Timeout),
+	}
+	if !p.FollowRedirects {
+		p.client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return nil
+}
+
+func (p *Prober) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range p.URLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			p.probe(acc, u)
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (p *Prober) probe(acc telegraf.Accumulator, u string) {
+	tags := map[string]string{"url": u}
+	fields := make(map[string]interface{})
+
+	vars, err := p.request(u)
+	if err != nil {
+		p.Log.Debugf("Probing %q failed: %v", u, err)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			tags["result"] = "timeout"
+		} else {
+			tags["result"] = "connection_failed"
+		}
+		fields["success"] = false
+		acc.AddFields("prober", fields, tags)
+		return
+	}
+	tags["status_code"] = strconv.FormatInt(vars["status"].(int64), 10)
+	fields["http_response_code"] = vars["status"]
+	fields["response_time"] = vars["response_time"]
+	fields["content_length"] = len(vars["body"].(string))
+
+	// Extract the fields and tags before checking so the metric is complete
+	// even if the check fails
+	for _, e := range p.extractions {
+		value, err := evaluate(e.program, vars)
+		if err != nil {
+			if !e.Optional {
+				acc.AddError(fmt.Errorf("evaluating %q for %q failed: %w", e.Name, u, err))
+			}
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		if e.tag {
+			if v := fmt.Sprint(value); v != "" {
+				tags[e.Name] = v
+			}
+			continue
+		}
+		if v, ok := value.(string); ok && v == "" {
+			continue
+		}
+		fields[e.Name] = value
+	}
+
+	value, err := evaluate(p.check, vars)
+	passed, ok := value.(bool)
+	switch {
+	case err != nil:
+		acc.AddError(fmt.Errorf("evaluating check for %q failed: %w", u, err))
+		tags["result"] = "check_error"
+	case !ok:
+		acc.AddError(fmt.Errorf("check for %q returned %T instead of a boolean", u, value))
+		tags["result"] = "check_error"
+	case passed:
+		tags["result"] = "success"
+	default:
+		tags["result"] = "check_failed"
+	}
+	fields["success"] = passed
+	acc.AddFields("prober", fields, tags)
+}
+
+// request performs the HTTP request and assembles the variables available to
+// the expressions from the response
+func (p *Prober) request(u string) (map[string]interface{}, error) {
+	var body io.Reader
+	if p.Body != "" {
+		body = strings.NewReader(p.Body)
+	}
+	req, err := http.NewRequest(p.Method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", internal.ProductToken())
+	for k, v := range p.Headers {
+		if strings.EqualFold(k, "host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	responseTime := time.Since(start).Seconds()
+	if err != nil {
+		return nil, fmt.Errorf("reading body failed: %w", err)
+	}
+
+	headers := make(map[string]string, len(resp.Header))
+	for k, v := range resp.Header {
+		headers[strings.ToLower(k)] = strings.Join(v, ", ")
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(buf, &parsed); err != nil {
+		parsed = nil
+	}
+
+	var tlsInfo interface{}
+	if resp.TLS != nil {
+		info := map[string]interface{}{
+			"version":     tls.VersionName(resp.TLS.Version),
+			"cipher":      tls.CipherSuiteName(resp.TLS.CipherSuite),
+			"server_name": resp.TLS.ServerName,
+		}
+		if len(resp.TLS.PeerCertificates) > 0 {
+			cert := resp.TLS.PeerCertificates[0]
+			info["cert"] = map[string]interface{}{
+				"subject":    cert.Subject.String(),
+				"issuer":     cert.Issuer.String(),
+				"serial":     hex.EncodeToString(cert.SerialNumber.Bytes()),
+				"dns_names":  cert.DNSNames,
+				"not_before": cert.NotBefore,
+				"not_after":  cert.NotAfter,
+			}
+		}
+		tlsInfo = info
+	}
+
+	return map[string]interface{}{
+		"url":           u,
+		"status":        int64(resp.StatusCode),
+		"headers":       headers,
+		"body":          string(buf),
+		"json":          parsed,
+		"response_time": responseTime,
+		"tls":           tlsInfo,
+	}, nil
+}
+
+// evaluate runs the program and converts the result into a metric value.
+// Null results are returned as nil.
+func evaluate(program cel.Program, vars map[string]interface{}) (interface{}, error) {
+	result, _, err := program.Eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := result.Value().(type) {
+	case int64, uint64, float64, bool, string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case time.Time:
+		return v.UnixNano(), nil
+	case time.Duration:
+		return v.Seconds(), nil
+	}
+	if result.Type() == types.NullType {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported result type %q", result.Type().TypeName())
+}
+
+func init() {
+	inputs.Add("prober", func() telegraf.Input {
+		return &Prober{}
+	})
+}
@@ -0,0 +1,255 @@
+package prober
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Prober
+		expected string
+	}{
+		{
+			name:     "no urls",
+			plugin:   &Prober{},
+			expected: "no URLs configured",
+		},
+		{
+			name:     "invalid check",
+			plugin:   &Prober{URLs: []string{"http://localhost"}, Check: "status =="},
+			expected: "compiling check failed",
+		},
+		{
+			name:     "non-boolean check",
+			plugin:   &Prober{URLs: []string{"http://localhost"}, Check: "status + 1"},
+			expected: "check needs to return a boolean",
+		},
+		{
+			name:     "unknown variable",
+			plugin:   &Prober{URLs: []string{"http://localhost"}, Check: "code == 200"},
+			expected: "compiling check failed",
+		},
+		{
+			name: "extraction without name",
+			plugin: &Prober{
+				URLs:   []string{"http://localhost"},
+				Fields: []Extraction{{Expression: "status"}},
+			},
+			expected: "extraction without 'name'",
+		},
+		{
+			name: "extraction without expression",
+			plugin: &Prober{
+				URLs: []string{"http://localhost"},
+				Tags: []Extraction{{Name: "foo"}},
+			},
+			expected: `extraction "foo" without 'expression'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			if r.Header.Get("Accept") != "application/json" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Server", "mock/1.0")
+			fmt.Fprint(w, `{"status": "ok", "version": "1.2.3", "checks": {"db": {"latency_ms": 12}}}`)
+		case "/degraded":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"status": "degraded", "version": "1.2.3"}`)
+		case "/text":
+			fmt.Fprint(w, "ok")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	plugin := &Prober{
+		URLs:    []string{ts.URL + "/health", ts.URL + "/degraded", ts.URL + "/text"},
+		Headers: map[string]string{"Accept": "application/json"},
+		Check:   `status == 200 && json != null && json.status == "ok"`,
+		Fields: []Extraction{
+			{Name: "version", Expression: "json.version", Optional: true},
+			{Name: "db_latency", Expression: "json.checks.db.latency_ms", Optional: true},
+			{Name: "has_content_type", Expression: `"content-type" in headers`},
+		},
+		Tags: []Extraction{
+			{Name: "server", Expression: `headers["server"]`, Optional: true},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"prober",
+			map[string]string{
+				"url":         ts.URL + "/health",
+				"result":      "success",
+				"status_code": "200",
+				"server":      "mock/1.0",
+			},
+			map[string]interface{}{
+				"success":            true,
+				"http_response_code": int64(200),
+				"content_length":     74,
+				"version":            "1.2.3",
+				"db_latency":         float64(12),
+				"has_content_type":   true,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"prober",
+			map[string]string{
+				"url":         ts.URL + "/degraded",
+				"result":      "check_failed",
+				"status_code": "200",
+			},
+			map[string]interface{}{
+				"success":            false,
+				"http_response_code": int64(200),
+				"content_length":     42,
+				"version":            "1.2.3",
+				"has_content_type":   true,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"prober",
+			map[string]string{
+				"url":         ts.URL + "/text",
+				"result":      "check_failed",
+				"status_code": "200",
+			},
+			map[string]interface{}{
+				"success":            false,
+				"http_response_code": int64(200),
+				"content_length":     2,
+				"has_content_type":   true,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{
+		testutil.SortMetrics(),
+		testutil.IgnoreTime(),
+		testutil.IgnoreFields("response_time"),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+}
+
+func TestProbeTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+
+	plugin := &Prober{
+		URLs:  []string{ts.URL},
+		Check: `tls != null && tls.cert.not_after - now() > duration("24h")`,
+		Fields: []Extraction{
+			{Name: "cert_expiry", Expression: `(tls.cert.not_after - now()).getSeconds()`},
+			{Name: "tls_version", Expression: "tls.version"},
+		},
+		Tags: []Extraction{
+			{Name: "issuer", Expression: "tls.cert.issuer"},
+		},
+		ClientConfig: common_tls.ClientConfig{InsecureSkipVerify: true},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	m := metrics[0]
+	result, _ := m.GetTag("result")
+	require.Equal(t, "success", result)
+	issuer, _ := m.GetTag("issuer")
+	require.Equal(t, "O=Acme Co", issuer)
+	expiry, found := m.GetField("cert_expiry")
+	require.True(t, found)
+	require.Greater(t, expiry, int64(24*60*60))
+	version, _ := m.GetField("tls_version")
+	require.Equal(t, "TLS 1.3", version)
+}
+
+func TestProbeErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	address := ts.URL
+	ts.Close()
+
+	plugin := &Prober{
+		URLs:    []string{address},
+		Timeout: config.Duration(time.Second),
+		Fields:  []Extraction{{Name: "version", Expression: "json.version"}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	expected := []telegraf.Metric{
+		metric.New(
+			"prober",
+			map[string]string{
+				"url":    address,
+				"result": "connection_failed",
+			},
+			map[string]interface{}{
+				"success": false,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Evaluation errors of non-optional extractions are reported
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "not json")
+	}))
+	defer ts.Close()
+	plugin.URLs = []string{ts.URL}
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `evaluating "version"`)
+	result, _ := acc.GetTelegrafMetrics()[0].GetTag("result")
+	require.Equal(t, "success", result)
+}
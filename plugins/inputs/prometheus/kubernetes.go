@@ -77,13 +77,20 @@ func (p *Prometheus) startK8s(ctx context.Context) error {
 		}
 	}
 
-	if !p.isNodeScrapeScope {
+	if p.MonitorPods && !p.isNodeScrapeScope {
 		err = p.watchPod(ctx, client)
 		if err != nil {
 			p.Log.Warnf("Error while attempting to watch pod: %s", err.Error())
 		}
 	}
 
+	if p.MonitorKubernetesServices {
+		err = p.watchServices(ctx, client)
+		if err != nil {
+			p.Log.Warnf("Error while attempting to watch services: %s", err.Error())
+		}
+	}
+
 	p.wg.Add(1)
 	go func() {
 		defer p.wg.Done()
@@ -92,7 +99,7 @@ func (p *Prometheus) startK8s(ctx context.Context) error {
 			case <-ctx.Done():
 				return
 			case <-time.After(time.Second):
-				if p.isNodeScrapeScope {
+				if p.MonitorPods && p.isNodeScrapeScope {
 					bearerToken := config.BearerToken
 					if config.BearerTokenFile != "" {
 						bearerTokenBytes, err := os.ReadFile(config.BearerTokenFile)
@@ -138,13 +145,10 @@ func shouldScrapePod(pod *corev1.Pod, p *Prometheus) bool {
 // Share informer per namespace across all instances of this plugin
 var informerfactory map[string]informers.SharedInformerFactory
 
-// An edge case exists if a pod goes offline at the same time a new pod is created
-// (without the scrape annotations). K8s may re-assign the old pod ip to the non-scrape
-// pod, causing errors in the logs. This is only true if the pod going offline is not
-// directed to do so by K8s.
-func (p *Prometheus) watchPod(ctx context.Context, clientset *kubernetes.Clientset) error {
+// informerFactory returns the informer factory shared by all instances of
+// the plugin watching the same namespace.
+func (p *Prometheus) informerFactory(clientset *kubernetes.Clientset) informers.SharedInformerFactory {
 	var resyncinterval time.Duration
-
 	if p.CacheRefreshInterval != 0 {
 		resyncinterval = time.Duration(p.CacheRefreshInterval) * time.Minute
 	} else {
@@ -165,6 +169,15 @@ func (p *Prometheus) watchPod(ctx context.Context, clientset *kubernetes.Clients
 		f = informers.NewSharedInformerFactoryWithOptions(clientset, resyncinterval, informerOptions...)
 		informerfactory[p.PodNamespace] = f
 	}
+	return f
+}
+
+// An edge case exists if a pod goes offline at the same time a new pod is created
+// (without the scrape annotations). K8s may re-assign the old pod ip to the non-scrape
+// pod, causing errors in the logs. This is only true if the pod going offline is not
+// directed to do so by K8s.
+func (p *Prometheus) watchPod(ctx context.Context, clientset *kubernetes.Clientset) error {
+	f := p.informerFactory(clientset)
 
 	if p.nsAnnotationPass != nil || p.nsAnnotationDrop != nil {
 		p.nsStore = f.Core().V1().Namespaces().Informer().GetStore()
@@ -396,7 +409,6 @@ func registerPod(pod *corev1.Pod, p *Prometheus) {
 		return
 	}
 
-	p.Log.Debugf("will scrape metrics from %q", targetURL.String())
 	tags := make(map[string]string, len(pod.Annotations)+len(pod.Labels)+2)
 
 	// add annotation as metrics tags, subject to include/exclude filters
@@ -419,6 +431,13 @@ func registerPod(pod *corev1.Pod, p *Prometheus) {
 			tags[k] = v
 		}
 	}
+
+	targetURL, tags, keep := p.relabelTarget(targetURL, tags, podMetaLabels(pod))
+	if !keep {
+		p.Log.Debugf("pod %s/%s dropped by relabeling", pod.Namespace, pod.Name)
+		return
+	}
+	p.Log.Debugf("will scrape metrics from %q", targetURL.String())
 	podURL := addressToURL(targetURL, targetURL.Hostname())
 
 	// Locks earlier if using cAdvisor calls - makes a new list each time
@@ -436,6 +455,22 @@ func registerPod(pod *corev1.Pod, p *Prometheus) {
 	}
 }
 
+// podMetaLabels returns the meta labels of the pod available for relabeling
+func podMetaLabels(pod *corev1.Pod) map[string]string {
+	meta := make(map[string]string, len(pod.Labels)+len(pod.Annotations)+4)
+	meta[labelMetaPrefix+"namespace"] = pod.Namespace
+	meta[labelMetaPrefix+"pod_name"] = pod.Name
+	meta[labelMetaPrefix+"pod_ip"] = pod.Status.PodIP
+	meta[labelMetaPrefix+"pod_node_name"] = pod.Spec.NodeName
+	for k, v := range pod.Labels {
+		meta[metaLabelName("pod_label", k)] = v
+	}
+	for k, v := range pod.Annotations {
+		meta[metaLabelName("pod_annotation", k)] = v
+	}
+	return meta
+}
+
 func getScrapeURL(pod *corev1.Pod, p *Prometheus) (*url.URL, error) {
 	ip := pod.Status.PodIP
 	if ip == "" {
@@ -0,0 +1,196 @@
+package prometheus
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+type endpointPort struct {
+	name string
+	port string
+}
+
+// watchServices watches the services and their endpoints and registers the
+// endpoints of services with the 'prometheus.io/scrape' annotation for
+// scraping.
+func (p *Prometheus) watchServices(ctx context.Context, clientset *kubernetes.Clientset) error {
+	f := p.informerFactory(clientset)
+
+	if p.nsAnnotationPass != nil || p.nsAnnotationDrop != nil {
+		p.nsStore = f.Core().V1().Namespaces().Informer().GetStore()
+	}
+
+	serviceStore := f.Core().V1().Services().Informer().GetStore()
+	endpointsStore := f.Core().V1().Endpoints().Informer().GetStore()
+
+	// Services and their endpoints share the same key, so re-evaluate the
+	// service on changes of either object.
+	update := func(obj interface{}) {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			p.Log.Errorf("getting key from cache %s", err.Error())
+			return
+		}
+
+		var svc *corev1.Service
+		if obj, exists, err := serviceStore.GetByKey(key); err == nil && exists {
+			svc, _ = obj.(*corev1.Service)
+		}
+		var endpoints *corev1.Endpoints
+		if obj, exists, err := endpointsStore.GetByKey(key); err == nil && exists {
+			endpoints, _ = obj.(*corev1.Endpoints)
+		}
+		p.updateService(key, svc, endpoints)
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    update,
+		UpdateFunc: func(_, newObj interface{}) { update(newObj) },
+		DeleteFunc: update,
+	}
+
+	if _, err := f.Core().V1().Services().Informer().AddEventHandler(handler); err != nil {
+		return err
+	}
+	if _, err := f.Core().V1().Endpoints().Informer().AddEventHandler(handler); err != nil {
+		return err
+	}
+
+	f.Start(ctx.Done())
+	f.WaitForCacheSync(wait.NeverStop)
+	return nil
+}
+
+// updateService registers the endpoints of the service for scraping or
+// unregisters them if the service or its endpoints are gone or the service
+// should not be scraped anymore.
+func (p *Prometheus) updateService(key string, svc *corev1.Service, endpoints *corev1.Endpoints) {
+	var targets []urlAndAddress
+	if svc != nil && endpoints != nil && svc.Annotations["prometheus.io/scrape"] == "true" {
+		targets = p.serviceTargets(svc, endpoints)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if len(targets) == 0 {
+		if _, ok := p.kubernetesServices[key]; ok {
+			p.Log.Debugf("will stop scraping endpoints of service %s", key)
+			delete(p.kubernetesServices, key)
+		}
+		return
+	}
+	p.kubernetesServices[key] = targets
+}
+
+// serviceTargets returns the scrape targets for the ready endpoint addresses
+// of the service honoring the 'prometheus.io/scheme', 'prometheus.io/path'
+// and 'prometheus.io/port' annotations of the service. Without a port
+// annotation, all TCP ports of the endpoints are scraped.
+func (p *Prometheus) serviceTargets(svc *corev1.Service, endpoints *corev1.Endpoints) []urlAndAddress {
+	scheme := "http"
+	if ann := svc.Annotations["prometheus.io/scheme"]; ann != "" {
+		scheme = ann
+	}
+	pathAndQuery := "/metrics"
+	if ann := svc.Annotations["prometheus.io/path"]; ann != "" {
+		pathAndQuery = ann
+	}
+	base, err := url.Parse(pathAndQuery)
+	if err != nil {
+		p.Log.Errorf("could not parse path of service %s/%s: %s", svc.Namespace, svc.Name, err)
+		return nil
+	}
+	base.Scheme = scheme
+
+	// add annotations and labels as metrics tags, subject to the pod filters
+	serviceTags := make(map[string]string, len(svc.Annotations)+len(svc.Labels)+2)
+	for k, v := range svc.Annotations {
+		if models.ShouldPassFilters(p.podAnnotationIncludeFilter, p.podAnnotationExcludeFilter, k) {
+			serviceTags[k] = v
+		}
+	}
+	serviceTags["service_name"] = svc.Name
+	namespaceLabel := "namespace"
+	if p.PodNamespaceLabelName != "" {
+		namespaceLabel = p.PodNamespaceLabelName
+	}
+	serviceTags[namespaceLabel] = svc.Namespace
+	for k, v := range svc.Labels {
+		if models.ShouldPassFilters(p.podLabelIncludeFilter, p.podLabelExcludeFilter, k) {
+			serviceTags[k] = v
+		}
+	}
+
+	serviceMeta := make(map[string]string, len(svc.Annotations)+len(svc.Labels)+2)
+	serviceMeta[labelMetaPrefix+"namespace"] = svc.Namespace
+	serviceMeta[labelMetaPrefix+"service_name"] = svc.Name
+	for k, v := range svc.Labels {
+		serviceMeta[metaLabelName("service_label", k)] = v
+	}
+	for k, v := range svc.Annotations {
+		serviceMeta[metaLabelName("service_annotation", k)] = v
+	}
+
+	targets := make([]urlAndAddress, 0)
+	for _, subset := range endpoints.Subsets {
+		var ports []endpointPort
+		if ann := svc.Annotations["prometheus.io/port"]; ann != "" {
+			ports = append(ports, endpointPort{port: ann})
+		} else {
+			for _, port := range subset.Ports {
+				if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+					continue
+				}
+				ports = append(ports, endpointPort{name: port.Name, port: strconv.Itoa(int(port.Port))})
+			}
+		}
+
+		// Only ready addresses are scraped, not-ready ones are listed separately
+		for _, addr := range subset.Addresses {
+			for _, port := range ports {
+				targetURL := *base
+				targetURL.Host = net.JoinHostPort(addr.IP, port.port)
+
+				tags := make(map[string]string, len(serviceTags)+1)
+				for k, v := range serviceTags {
+					tags[k] = v
+				}
+				meta := make(map[string]string, len(serviceMeta)+3)
+				for k, v := range serviceMeta {
+					meta[k] = v
+				}
+				meta[labelMetaPrefix+"endpoint_port_name"] = port.name
+				if addr.TargetRef != nil && addr.TargetRef.Kind == "Pod" {
+					tags["pod_name"] = addr.TargetRef.Name
+					meta[labelMetaPrefix+"pod_name"] = addr.TargetRef.Name
+				}
+				if addr.NodeName != nil {
+					meta[labelMetaPrefix+"endpoint_node_name"] = *addr.NodeName
+				}
+
+				target, tags, keep := p.relabelTarget(&targetURL, tags, meta)
+				if !keep {
+					continue
+				}
+				p.Log.Debugf("will scrape metrics from %q", target.String())
+				targets = append(targets, urlAndAddress{
+					url:         addressToURL(target, target.Hostname()),
+					address:     target.Hostname(),
+					originalURL: target,
+					tags:        tags,
+					namespace:   svc.Namespace,
+				})
+			}
+		}
+	}
+
+	return targets
+}
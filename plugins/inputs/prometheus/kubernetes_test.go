@@ -359,6 +359,148 @@ func TestLabelFilters(t *testing.T) {
 	}
 }
 
+func TestRelabelPod(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []relabelConfig
+		expected map[string]string
+		url      string
+	}{
+		{
+			name:     "no rules",
+			expected: map[string]string{"pod_name": "myPod", "namespace": "default", "team": "a"},
+			url:      "http://127.0.0.1:9102/metrics",
+		},
+		{
+			name: "drop namespace",
+			rules: []relabelConfig{
+				{SourceLabels: []string{"__meta_kubernetes_namespace"}, Regex: "default", Action: "drop"},
+			},
+		},
+		{
+			name: "keep other namespace",
+			rules: []relabelConfig{
+				{SourceLabels: []string{"__meta_kubernetes_namespace"}, Regex: "monitoring", Action: "keep"},
+			},
+		},
+		{
+			name: "modify target",
+			rules: []relabelConfig{
+				{SourceLabels: []string{"__meta_kubernetes_pod_annotation_example_com_owner"}, TargetLabel: "owner"},
+				{SourceLabels: []string{"__address__"}, Regex: "([^:]+):.*", Replacement: "$1:8080", TargetLabel: "__address__"},
+				{Replacement: "/internal/metrics", TargetLabel: "__metrics_path__"},
+				{Regex: "team", Action: "labeldrop"},
+			},
+			expected: map[string]string{"pod_name": "myPod", "namespace": "default", "owner": "me"},
+			url:      "http://127.0.0.1:8080/internal/metrics",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prom := &Prometheus{
+				Log:                  testutil.Logger{},
+				kubernetesPods:       map[podID]urlAndAddress{},
+				KubernetesRelabel:    tt.rules,
+				PodAnnotationInclude: []string{"none"},
+			}
+			require.NoError(t, prom.initFilters())
+			for _, rule := range tt.rules {
+				rc, err := rule.compile()
+				require.NoError(t, err)
+				prom.relabelConfigs = append(prom.relabelConfigs, rc)
+			}
+
+			p := pod()
+			p.Annotations = map[string]string{"prometheus.io/scrape": "true", "example.com/owner": "me"}
+			p.Labels = map[string]string{"team": "a"}
+			registerPod(p, prom)
+
+			if tt.expected == nil {
+				require.Empty(t, prom.kubernetesPods)
+				return
+			}
+			require.Len(t, prom.kubernetesPods, 1)
+			target := prom.kubernetesPods["default/myPod"]
+			require.Equal(t, tt.expected, target.tags)
+			require.Equal(t, tt.url, target.url.String())
+		})
+	}
+}
+
+func TestInvalidRelabelRule(t *testing.T) {
+	cfg := relabelConfig{Action: "replace", Regex: "foo"}
+	_, err := cfg.compile()
+	require.ErrorContains(t, err, "requires 'target_label' value")
+
+	cfg = relabelConfig{Action: "keep", Regex: "(foo"}
+	_, err = cfg.compile()
+	require.ErrorContains(t, err, "invalid regex")
+}
+
+func TestServiceTargets(t *testing.T) {
+	prom := initPrometheus()
+	prom.kubernetesServices = map[string][]urlAndAddress{}
+
+	nodeName := "node1"
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:        "mySvc",
+		Namespace:   "default",
+		Labels:      map[string]string{"app": "web"},
+		Annotations: map[string]string{"prometheus.io/scrape": "true", "prometheus.io/path": "/stats"},
+	}}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "mySvc", Namespace: "default"},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses: []corev1.EndpointAddress{
+					{IP: "10.0.0.1", NodeName: &nodeName, TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "web-1"}},
+					{IP: "10.0.0.2"},
+				},
+				NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.3"}},
+				Ports: []corev1.EndpointPort{
+					{Name: "metrics", Port: 9000, Protocol: corev1.ProtocolTCP},
+					{Name: "dns", Port: 53, Protocol: corev1.ProtocolUDP},
+				},
+			},
+		},
+	}
+
+	prom.updateService("default/mySvc", svc, endpoints)
+	urls, err := prom.getAllURLs()
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+
+	target := urls["http://10.0.0.1:9000/stats"]
+	require.Equal(t, "10.0.0.1", target.address)
+	require.Equal(t, "web-1", target.tags["pod_name"])
+	require.Equal(t, "mySvc", target.tags["service_name"])
+	require.Equal(t, "default", target.tags["namespace"])
+	require.Equal(t, "web", target.tags["app"])
+	require.Contains(t, urls, "http://10.0.0.2:9000/stats")
+
+	// Port annotation overrides the endpoint ports
+	svc.Annotations["prometheus.io/port"] = "9999"
+	prom.updateService("default/mySvc", svc, endpoints)
+	urls, err = prom.getAllURLs()
+	require.NoError(t, err)
+	require.Contains(t, urls, "http://10.0.0.1:9999/stats")
+	require.Contains(t, urls, "http://10.0.0.2:9999/stats")
+	require.Len(t, urls, 2)
+
+	// Services without scrape annotation are not scraped
+	svc.Annotations["prometheus.io/scrape"] = "false"
+	prom.updateService("default/mySvc", svc, endpoints)
+	require.Empty(t, prom.kubernetesServices)
+
+	// Services without endpoints are not scraped
+	svc.Annotations["prometheus.io/scrape"] = "true"
+	prom.updateService("default/mySvc", svc, endpoints)
+	require.Len(t, prom.kubernetesServices, 1)
+	prom.updateService("default/mySvc", svc, nil)
+	require.Empty(t, prom.kubernetesServices)
+}
+
 func pod() *corev1.Pod {
 	p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{}, Status: corev1.PodStatus{}, Spec: corev1.PodSpec{}}
 	p.Status.PodIP = "127.0.0.1"
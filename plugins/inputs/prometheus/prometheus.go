@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/prometheus/model/relabel"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
@@ -43,6 +44,13 @@ const (
 	monitorMethodSettingsAndAnnotations monitorMethod = "settings+annotations"
 )
 
+// scrapeProtocols maps the supported scrape protocols to their media types
+var scrapeProtocols = map[string]string{
+	"protobuf":         "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited",
+	"openmetrics-text": "application/openmetrics-text;version=1.0.0",
+	"text":             "text/plain;version=0.0.4",
+}
+
 type Prometheus struct {
 	URLs                 []string          `toml:"urls"`
 	BearerToken          string            `toml:"bearer_token"`
@@ -52,6 +60,7 @@ type Prometheus struct {
 	HTTPHeaders          map[string]string `toml:"http_headers"`
 	ContentLengthLimit   config.Size       `toml:"content_length_limit"`
 	ContentTypeOverride  string            `toml:"content_type_override"`
+	ScrapeProtocols      []string          `toml:"scrape_protocols"`
 	EnableRequestMetrics bool              `toml:"enable_request_metrics"`
 	MetricVersion        int               `toml:"metric_version"`
 	URLTag               string            `toml:"url_tag"`
@@ -79,6 +88,9 @@ type Prometheus struct {
 	PodLabelInclude             []string            `toml:"pod_label_include"`
 	PodLabelExclude             []string            `toml:"pod_label_exclude"`
 	CacheRefreshInterval        int                 `toml:"cache_refresh_interval"`
+	MonitorKubernetesServices   bool                `toml:"monitor_kubernetes_services"`
+	KubernetesRelabel           []relabelConfig     `toml:"kubernetes_relabel"`
+	StaleTargetMarkers          bool                `toml:"stale_target_markers"`
 
 	// Consul discovery
 	ConsulConfig consulConfig `toml:"consul"`
@@ -95,10 +107,12 @@ type Prometheus struct {
 	nsAnnotationDrop []models.TagFilter
 
 	// Should we scrape Kubernetes services for prometheus annotations
-	lock           sync.Mutex
-	kubernetesPods map[podID]urlAndAddress
-	cancel         context.CancelFunc
-	wg             sync.WaitGroup
+	lock               sync.Mutex
+	kubernetesPods     map[podID]urlAndAddress
+	kubernetesServices map[string][]urlAndAddress
+	relabelConfigs     []*relabel.Config
+	cancel             context.CancelFunc
+	wg                 sync.WaitGroup
 
 	// Only for monitor_kubernetes_pods=true and pod_scrape_scope="node"
 	podLabelSelector           labels.Selector
@@ -111,6 +125,9 @@ type Prometheus struct {
 
 	// List of consul services to scrape
 	consulServices map[string]urlAndAddress
+
+	// Targets of the last gather for emitting stale target markers
+	lastTargets map[string]urlAndAddress
 }
 
 type urlAndAddress struct {
@@ -222,6 +239,14 @@ func (p *Prometheus) Init() error {
 		return err
 	}
 
+	for i, cfg := range p.KubernetesRelabel {
+		rc, err := cfg.compile()
+		if err != nil {
+			return fmt.Errorf("invalid 'kubernetes_relabel' rule %d: %w", i+1, err)
+		}
+		p.relabelConfigs = append(p.relabelConfigs, rc)
+	}
+
 	if p.MetricVersion == 0 {
 		p.MetricVersion = 1
 	}
@@ -242,12 +267,17 @@ func (p *Prometheus) Init() error {
 		)
 	}
 
+	accept, err := acceptHeaderFor(p.ScrapeProtocols)
+	if err != nil {
+		return err
+	}
 	p.headers = map[string]string{
 		"User-Agent": internal.ProductToken(),
-		"Accept":     acceptHeader,
+		"Accept":     accept,
 	}
 
 	p.kubernetesPods = make(map[podID]urlAndAddress)
+	p.kubernetesServices = make(map[string][]urlAndAddress)
 
 	return nil
 }
@@ -263,7 +293,7 @@ func (p *Prometheus) Start(_ telegraf.Accumulator) error {
 			return err
 		}
 	}
-	if p.MonitorPods {
+	if p.MonitorPods || p.MonitorKubernetesServices {
 		if err := p.startK8s(ctx); err != nil {
 			return err
 		}
@@ -294,6 +324,10 @@ func (p *Prometheus) Gather(acc telegraf.Accumulator) error {
 
 	wg.Wait()
 
+	if p.StaleTargetMarkers {
+		p.addStaleTargetMarkers(acc, allURLs)
+	}
+
 	return nil
 }
 
@@ -306,6 +340,29 @@ func (p *Prometheus) Stop() {
 	}
 }
 
+// acceptHeaderFor returns the Accept header negotiating the given scrape
+// protocols in descending order of preference.
+func acceptHeaderFor(protocols []string) (string, error) {
+	if len(protocols) == 0 {
+		return acceptHeader, nil
+	}
+
+	parts := make([]string, 0, len(protocols)+1)
+	for i, protocol := range protocols {
+		mediaType, found := scrapeProtocols[protocol]
+		if !found {
+			return "", fmt.Errorf("invalid 'scrape_protocols' entry %q", protocol)
+		}
+		q := 1.0 - 0.1*float64(i)
+		parts = append(parts, fmt.Sprintf("%s;q=%.1f", mediaType, q))
+	}
+	// Let endpoints not supporting any of the requested formats fall back to
+	// their default format.
+	parts = append(parts, "*/*;q=0.1")
+
+	return strings.Join(parts, ","), nil
+}
+
 func (p *Prometheus) initFilters() error {
 	if p.PodAnnotationExclude != nil {
 		podAnnotationExclude, err := filter.Compile(p.PodAnnotationExclude)
@@ -380,6 +437,14 @@ func (p *Prometheus) getAllURLs() (map[string]urlAndAddress, error) {
 			allURLs[v.url.String()] = v
 		}
 	}
+	// add all endpoints of services scraped via the prometheus annotation
+	for _, targets := range p.kubernetesServices {
+		for _, v := range targets {
+			if namespaceAnnotationMatch(v.namespace, p) {
+				allURLs[v.url.String()] = v
+			}
+		}
+	}
 
 	for _, service := range p.KubernetesServices {
 		address, err := url.Parse(service)
@@ -597,6 +662,33 @@ func (p *Prometheus) gatherURL(u urlAndAddress, acc telegraf.Accumulator) (map[s
 	return requestFields, tags, nil
 }
 
+// addStaleTargetMarkers emits a marker metric for each target scraped in the
+// previous gather but no longer discovered, e.g. because the pod is gone, to
+// allow detecting targets not being scraped anymore.
+func (p *Prometheus) addStaleTargetMarkers(acc telegraf.Accumulator, targets map[string]urlAndAddress) {
+	now := time.Now()
+	for key, u := range p.lastTargets {
+		if _, found := targets[key]; found {
+			continue
+		}
+
+		tags := make(map[string]string, len(u.tags)+2)
+		if p.URLTag != "" {
+			originalURL := *u.originalURL
+			originalURL.User = nil
+			tags[p.URLTag] = originalURL.String()
+		}
+		if u.address != "" {
+			tags["address"] = u.address
+		}
+		for k, v := range u.tags {
+			tags[k] = v
+		}
+		acc.AddFields("prometheus_target", map[string]interface{}{"stale": true}, tags, now)
+	}
+	p.lastTargets = targets
+}
+
 func (p *Prometheus) addHeaders(req *http.Request) {
 	for header, value := range p.headers {
 		req.Header.Add(header, value)
@@ -630,9 +722,10 @@ func fieldSelectorIsSupported(fieldSelector fields.Selector) (bool, string) {
 func init() {
 	inputs.Add("prometheus", func() telegraf.Input {
 		return &Prometheus{
-			kubernetesPods: make(map[podID]urlAndAddress),
-			consulServices: make(map[string]urlAndAddress),
-			URLTag:         "url",
+			kubernetesPods:     make(map[podID]urlAndAddress),
+			kubernetesServices: make(map[string][]urlAndAddress),
+			consulServices:     make(map[string]urlAndAddress),
+			URLTag:             "url",
 		}
 	})
 }
@@ -82,6 +82,116 @@ func TestPrometheusGeneratesMetrics(t *testing.T) {
 	require.Equal(t, ts.URL+"/metrics", acc.TagValue("test_metric", "url"))
 }
 
+func TestPrometheusStaleTargetMarkers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if _, err := fmt.Fprintln(w, sampleTextFormat); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+	}))
+	defer ts.Close()
+
+	p := &Prometheus{
+		Log:                testutil.Logger{},
+		URLTag:             "url",
+		StaleTargetMarkers: true,
+	}
+	require.NoError(t, p.Init())
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	p.kubernetesPods["default/myPod"] = urlAndAddress{
+		url:         u,
+		originalURL: u,
+		address:     u.Hostname(),
+		tags:        map[string]string{"pod_name": "myPod"},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(p.Gather))
+	require.True(t, acc.HasFloatField("test_metric", "value"))
+	require.False(t, acc.HasMeasurement("prometheus_target"))
+
+	// The pod is gone so a marker is expected once
+	delete(p.kubernetesPods, "default/myPod")
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(p.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"prometheus_target",
+			map[string]string{
+				"url":      ts.URL + "/metrics",
+				"address":  u.Hostname(),
+				"pod_name": "myPod",
+			},
+			map[string]interface{}{"stale": true},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(p.Gather))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestPrometheusScrapeProtocols(t *testing.T) {
+	tests := []struct {
+		name      string
+		protocols []string
+		expected  string
+	}{
+		{
+			name:     "default",
+			expected: acceptHeader,
+		},
+		{
+			name:      "openmetrics preferred",
+			protocols: []string{"openmetrics-text", "protobuf"},
+			expected: "application/openmetrics-text;version=1.0.0;q=1.0," +
+				"application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.9," +
+				"*/*;q=0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accept string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accept = r.Header.Get("Accept")
+				if _, err := fmt.Fprintln(w, sampleTextFormat); err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+			}))
+			defer ts.Close()
+
+			p := &Prometheus{
+				Log:             testutil.Logger{},
+				URLs:            []string{ts.URL},
+				URLTag:          "url",
+				ScrapeProtocols: tt.protocols,
+			}
+			require.NoError(t, p.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, acc.GatherError(p.Gather))
+			require.Equal(t, tt.expected, accept)
+		})
+	}
+}
+
+func TestPrometheusInvalidScrapeProtocol(t *testing.T) {
+	p := &Prometheus{
+		Log:             testutil.Logger{},
+		ScrapeProtocols: []string{"protobuf", "foo"},
+	}
+	require.ErrorContains(t, p.Init(), `invalid 'scrape_protocols' entry "foo"`)
+}
+
 func TestPrometheusCustomHeader(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Header.Get("accept") {
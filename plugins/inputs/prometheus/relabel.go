@@ -0,0 +1,113 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+)
+
+const (
+	labelAddress     = "__address__"
+	labelScheme      = "__scheme__"
+	labelMetricsPath = "__metrics_path__"
+	labelMetaPrefix  = "__meta_kubernetes_"
+)
+
+var invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+type relabelConfig struct {
+	SourceLabels []string `toml:"source_labels"`
+	Separator    string   `toml:"separator"`
+	Regex        string   `toml:"regex"`
+	Modulus      uint64   `toml:"modulus"`
+	TargetLabel  string   `toml:"target_label"`
+	Replacement  string   `toml:"replacement"`
+	Action       string   `toml:"action"`
+}
+
+func (cfg *relabelConfig) compile() (*relabel.Config, error) {
+	rc := relabel.DefaultRelabelConfig
+	if len(cfg.SourceLabels) > 0 {
+		rc.SourceLabels = make(model.LabelNames, 0, len(cfg.SourceLabels))
+		for _, l := range cfg.SourceLabels {
+			rc.SourceLabels = append(rc.SourceLabels, model.LabelName(l))
+		}
+	}
+	if cfg.Separator != "" {
+		rc.Separator = cfg.Separator
+	}
+	if cfg.Regex != "" {
+		re, err := relabel.NewRegexp(cfg.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", cfg.Regex, err)
+		}
+		rc.Regex = re
+	}
+	if cfg.Replacement != "" {
+		rc.Replacement = cfg.Replacement
+	}
+	if cfg.Action != "" {
+		rc.Action = relabel.Action(strings.ToLower(cfg.Action))
+	}
+	rc.Modulus = cfg.Modulus
+	rc.TargetLabel = cfg.TargetLabel
+
+	if err := rc.Validate(); err != nil {
+		return nil, err
+	}
+	return &rc, nil
+}
+
+// metaLabelName returns the name of the meta label for the given Kubernetes
+// label or annotation key, e.g. "__meta_kubernetes_pod_label_app".
+func metaLabelName(prefix, key string) string {
+	return labelMetaPrefix + prefix + "_" + invalidLabelCharRE.ReplaceAllString(key, "_")
+}
+
+// relabelTarget applies the relabeling rules to a discovered target. The
+// rules see the target tags, the meta labels and the "__address__",
+// "__scheme__" and "__metrics_path__" labels of the target URL. Labels
+// starting with "__" are removed after relabeling, all other labels become
+// the tags of the target. Returns false if the target is dropped.
+func (p *Prometheus) relabelTarget(u *url.URL, tags, meta map[string]string) (*url.URL, map[string]string, bool) {
+	if len(p.relabelConfigs) == 0 {
+		return u, tags, true
+	}
+
+	lbls := make(map[string]string, len(tags)+len(meta)+3)
+	for k, v := range meta {
+		lbls[k] = v
+	}
+	for k, v := range tags {
+		lbls[k] = v
+	}
+	lbls[labelAddress] = u.Host
+	lbls[labelScheme] = u.Scheme
+	lbls[labelMetricsPath] = u.Path
+
+	result, keep := relabel.Process(labels.FromMap(lbls), p.relabelConfigs...)
+	if !keep {
+		return nil, nil, false
+	}
+
+	target := *u
+	target.Host = result.Get(labelAddress)
+	target.Scheme = result.Get(labelScheme)
+	target.Path = result.Get(labelMetricsPath)
+	if target.Host == "" {
+		return nil, nil, false
+	}
+
+	newTags := make(map[string]string, result.Len())
+	result.Range(func(l labels.Label) {
+		if !strings.HasPrefix(l.Name, "__") {
+			newTags[l.Name] = l.Value
+		}
+	})
+	return &target, newTags, true
+}
@@ -0,0 +1,204 @@
+//go:build linux
+
+package ptp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// PTP management message constants as defined in IEEE 1588-2008 and the
+// linuxptp implementation
+const (
+	messageTypeManagement = 0x0d
+	controlManagement     = 0x04
+
+	actionGet      = 0
+	actionResponse = 2
+
+	tlvManagement            = 0x0001
+	tlvManagementErrorStatus = 0x0002
+
+	idDefaultDataSet = 0x2000
+	idCurrentDataSet = 0x2001
+	idParentDataSet  = 0x2002
+	idPortDataSet    = 0x2004
+	idTimeStatusNP   = 0xc000
+
+	headerLength     = 34
+	managementLength = headerLength + 14
+	tlvHeaderLength  = 6
+)
+
+var portStates = map[uint8]string{
+	1: "initializing",
+	2: "faulty",
+	3: "disabled",
+	4: "listening",
+	5: "pre_master",
+	6: "master",
+	7: "passive",
+	8: "uncalibrated",
+	9: "slave",
+}
+
+// managementGet encodes a management GET request for the given ID
+// targeting all clocks and ports
+func managementGet(domain uint8, sequence, id uint16) []byte {
+	buf := make([]byte, managementLength+tlvHeaderLength)
+	buf[0] = messageTypeManagement
+	buf[1] = 2 // PTP version
+	binary.BigEndian.PutUint16(buf[2:], uint16(len(buf)))
+	buf[4] = domain
+	binary.BigEndian.PutUint16(buf[30:], sequence)
+	buf[32] = controlManagement
+	buf[33] = 0x7f // log message interval
+
+	// Wildcard target port identity
+	for i := headerLength; i < headerLength+10; i++ {
+		buf[i] = 0xff
+	}
+	buf[headerLength+12] = actionGet
+
+	binary.BigEndian.PutUint16(buf[managementLength:], tlvManagement)
+	binary.BigEndian.PutUint16(buf[managementLength+2:], 2)
+	binary.BigEndian.PutUint16(buf[managementLength+4:], id)
+	return buf
+}
+
+// parseManagementResponse validates the management response and returns
+// the data of the management TLV
+func parseManagementResponse(buf []byte, sequence, id uint16) ([]byte, error) {
+	if len(buf) < managementLength+tlvHeaderLength {
+		return nil, fmt.Errorf("message too short (%d bytes)", len(buf))
+	}
+	if buf[0]&0x0f != messageTypeManagement {
+		return nil, fmt.Errorf("unexpected message type %d", buf[0]&0x0f)
+	}
+	if binary.BigEndian.Uint16(buf[30:]) != sequence {
+		return nil, errSequenceMismatch
+	}
+	if buf[headerLength+12]&0x0f != actionResponse {
+		return nil, fmt.Errorf("unexpected action %d", buf[headerLength+12]&0x0f)
+	}
+
+	tlvType := binary.BigEndian.Uint16(buf[managementLength:])
+	tlvLen := int(binary.BigEndian.Uint16(buf[managementLength+2:]))
+	if tlvLen < 2 || managementLength+4+tlvLen > len(buf) {
+		return nil, fmt.Errorf("invalid TLV length %d", tlvLen)
+	}
+	switch tlvType {
+	case tlvManagement:
+	case tlvManagementErrorStatus:
+		return nil, fmt.Errorf("management error status %d", binary.BigEndian.Uint16(buf[managementLength+4:]))
+	default:
+		return nil, fmt.Errorf("unexpected TLV type %d", tlvType)
+	}
+
+	if respID := binary.BigEndian.Uint16(buf[managementLength+4:]); respID != id {
+		return nil, fmt.Errorf("unexpected management ID 0x%04x", respID)
+	}
+	return buf[managementLength+tlvHeaderLength : managementLength+4+tlvLen], nil
+}
+
+var errSequenceMismatch = errors.New("sequence mismatch")
+
+type defaultDataSet struct {
+	numberPorts uint16
+}
+
+func parseDefaultDataSet(data []byte) (*defaultDataSet, error) {
+	if len(data) < 20 {
+		return nil, fmt.Errorf("default data set too short (%d bytes)", len(data))
+	}
+	return &defaultDataSet{
+		numberPorts: binary.BigEndian.Uint16(data[2:]),
+	}, nil
+}
+
+type currentDataSet struct {
+	stepsRemoved     uint16
+	offsetFromMaster float64 // ns
+	meanPathDelay    float64 // ns
+}
+
+func parseCurrentDataSet(data []byte) (*currentDataSet, error) {
+	if len(data) < 18 {
+		return nil, fmt.Errorf("current data set too short (%d bytes)", len(data))
+	}
+	return &currentDataSet{
+		stepsRemoved:     binary.BigEndian.Uint16(data[0:]),
+		offsetFromMaster: timeInterval(data[2:]),
+		meanPathDelay:    timeInterval(data[10:]),
+	}, nil
+}
+
+type parentDataSet struct {
+	gmPriority1     uint8
+	gmClockClass    uint8
+	gmClockAccuracy uint8
+	gmPriority2     uint8
+	gmIdentity      string
+}
+
+func parseParentDataSet(data []byte) (*parentDataSet, error) {
+	if len(data) < 32 {
+		return nil, fmt.Errorf("parent data set too short (%d bytes)", len(data))
+	}
+	return &parentDataSet{
+		gmPriority1:     data[18],
+		gmClockClass:    data[19],
+		gmClockAccuracy: data[20],
+		gmPriority2:     data[23],
+		gmIdentity:      clockIdentity(data[24:32]),
+	}, nil
+}
+
+type portDataSet struct {
+	portNumber        uint16
+	portState         uint8
+	peerMeanPathDelay float64 // ns
+	logSyncInterval   int8
+	delayMechanism    uint8
+}
+
+func parsePortDataSet(data []byte) (*portDataSet, error) {
+	if len(data) < 26 {
+		return nil, fmt.Errorf("port data set too short (%d bytes)", len(data))
+	}
+	return &portDataSet{
+		portNumber:        binary.BigEndian.Uint16(data[8:]),
+		portState:         data[10],
+		peerMeanPathDelay: timeInterval(data[12:]),
+		logSyncInterval:   int8(data[22]),
+		delayMechanism:    data[23],
+	}, nil
+}
+
+type timeStatus struct {
+	masterOffset int64 // ns
+	gmPresent    bool
+}
+
+func parseTimeStatus(data []byte) (*timeStatus, error) {
+	if len(data) < 50 {
+		return nil, fmt.Errorf("time status too short (%d bytes)", len(data))
+	}
+	return &timeStatus{
+		masterOffset: int64(binary.BigEndian.Uint64(data[0:])),
+		gmPresent:    binary.BigEndian.Uint32(data[38:]) != 0,
+	}, nil
+}
+
+// timeInterval decodes a PTP TimeInterval, i.e. nanoseconds multiplied by 2^16
+func timeInterval(data []byte) float64 {
+	return float64(int64(binary.BigEndian.Uint64(data))) / math.Exp2(16)
+}
+
+// clockIdentity formats the clock identity the same way as linuxptp does
+func clockIdentity(data []byte) string {
+	return fmt.Sprintf("%02x%02x%02x.%02x%02x.%02x%02x%02x",
+		data[0], data[1], data[2], data[3], data[4], data[5], data[6], data[7])
+}
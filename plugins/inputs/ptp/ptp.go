@@ -0,0 +1,288 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package ptp
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Maximum number of samples supported by the PTP_SYS_OFFSET_EXTENDED ioctl
+const maxPHCSamples = 25
+
+var clientCounter atomic.Uint32
+
+type PTP struct {
+	Sockets      []string        `toml:"sockets"`
+	Domain       uint8           `toml:"domain"`
+	PHCDevices   []string        `toml:"phc_devices"`
+	PHCSamples   uint            `toml:"phc_samples"`
+	PHCUTCOffset config.Duration `toml:"phc_utc_offset"`
+	Timeout      config.Duration `toml:"timeout"`
+	Log          telegraf.Logger `toml:"-"`
+
+	sequence uint16
+}
+
+func (*PTP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PTP) Init() error {
+	if len(p.Sockets) == 0 && len(p.PHCDevices) == 0 {
+		return errors.New("neither sockets nor PHC devices configured")
+	}
+
+	if p.PHCSamples == 0 {
+		p.PHCSamples = 5
+	}
+	if p.PHCSamples > maxPHCSamples {
+		return fmt.Errorf("phc_samples must not exceed %d", maxPHCSamples)
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = config.Duration(time.Second)
+	}
+
+	return nil
+}
+
+func (p *PTP) Gather(acc telegraf.Accumulator) error {
+	for _, socket := range p.Sockets {
+		if err := p.gatherSocket(acc, socket); err != nil {
+			acc.AddError(fmt.Errorf("querying %q failed: %w", socket, err))
+		}
+	}
+
+	for _, device := range p.PHCDevices {
+		if err := p.gatherPHC(acc, device); err != nil {
+			acc.AddError(fmt.Errorf("reading PHC %q failed: %w", device, err))
+		}
+	}
+
+	return nil
+}
+
+func (p *PTP) gatherSocket(acc telegraf.Accumulator, socket string) error {
+	// The daemon sends its responses to the address of the requester, so
+	// the client needs to bind to an address. Use the abstract namespace to
+	// avoid leaving files behind.
+	local := &net.UnixAddr{
+		Name: fmt.Sprintf("@telegraf-ptp-%d-%d", os.Getpid(), clientCounter.Add(1)),
+		Net:  "unixgram",
+	}
+	conn, err := net.ListenUnixgram("unixgram", local)
+	if err != nil {
+		return fmt.Errorf("creating client socket failed: %w", err)
+	}
+	defer conn.Close()
+
+	remote := &net.UnixAddr{Name: socket, Net: "unixgram"}
+
+	responses, err := p.request(conn, remote, idDefaultDataSet, 1)
+	if err != nil {
+		return fmt.Errorf("requesting default data set failed: %w", err)
+	}
+	dds, err := parseDefaultDataSet(responses[0])
+	if err != nil {
+		return err
+	}
+
+	responses, err = p.request(conn, remote, idCurrentDataSet, 1)
+	if err != nil {
+		return fmt.Errorf("requesting current data set failed: %w", err)
+	}
+	cds, err := parseCurrentDataSet(responses[0])
+	if err != nil {
+		return err
+	}
+
+	responses, err = p.request(conn, remote, idParentDataSet, 1)
+	if err != nil {
+		return fmt.Errorf("requesting parent data set failed: %w", err)
+	}
+	pds, err := parseParentDataSet(responses[0])
+	if err != nil {
+		return err
+	}
+
+	responses, err = p.request(conn, remote, idTimeStatusNP, 1)
+	if err != nil {
+		return fmt.Errorf("requesting time status failed: %w", err)
+	}
+	status, err := parseTimeStatus(responses[0])
+	if err != nil {
+		return err
+	}
+
+	// Every port of the clock answers the wildcard request separately
+	ports := make([]*portDataSet, 0, dds.numberPorts)
+	if dds.numberPorts > 0 {
+		responses, err = p.request(conn, remote, idPortDataSet, int(dds.numberPorts))
+		if err != nil {
+			return fmt.Errorf("requesting port data set failed: %w", err)
+		}
+		for _, r := range responses {
+			port, err := parsePortDataSet(r)
+			if err != nil {
+				return err
+			}
+			ports = append(ports, port)
+		}
+	}
+
+	tags := map[string]string{"socket": socket}
+	fields := map[string]interface{}{
+		"steps_removed":      uint64(cds.stepsRemoved),
+		"offset_from_master": cds.offsetFromMaster,
+		"mean_path_delay":    cds.meanPathDelay,
+		"master_offset":      status.masterOffset,
+		"gm_present":         status.gmPresent,
+		"gm_identity":        pds.gmIdentity,
+		"gm_clock_class":     uint64(pds.gmClockClass),
+		"gm_clock_accuracy":  uint64(pds.gmClockAccuracy),
+		"gm_priority1":       uint64(pds.gmPriority1),
+		"gm_priority2":       uint64(pds.gmPriority2),
+	}
+	acc.AddFields("ptp", fields, tags)
+
+	for _, port := range ports {
+		state, found := portStates[port.portState]
+		if !found {
+			state = "unknown"
+		}
+		tags := map[string]string{
+			"socket": socket,
+			"port":   strconv.FormatUint(uint64(port.portNumber), 10),
+		}
+		fields := map[string]interface{}{
+			"port_state":           state,
+			"port_state_code":      uint64(port.portState),
+			"peer_mean_path_delay": port.peerMeanPathDelay,
+			"log_sync_interval":    int64(port.logSyncInterval),
+			"delay_mechanism":      delayMechanism(port.delayMechanism),
+		}
+		acc.AddFields("ptp_port", fields, tags)
+	}
+
+	return nil
+}
+
+// request sends a management GET request and waits for the given number of
+// responses; responses to earlier, timed-out requests are discarded
+func (p *PTP) request(conn *net.UnixConn, remote *net.UnixAddr, id uint16, count int) ([][]byte, error) {
+	p.sequence++
+	sequence := p.sequence
+
+	if _, err := conn.WriteToUnix(managementGet(p.Domain, sequence, id), remote); err != nil {
+		return nil, err
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(time.Duration(p.Timeout))); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 1500)
+	responses := make([][]byte, 0, count)
+	for len(responses) < count {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		data, err := parseManagementResponse(buf[:n], sequence, id)
+		if errors.Is(err, errSequenceMismatch) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, append([]byte(nil), data...))
+	}
+	return responses, nil
+}
+
+func (p *PTP) gatherPHC(acc telegraf.Accumulator, device string) error {
+	f, err := os.Open(device)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	samples, err := unix.IoctlPtpSysOffsetExtended(int(f.Fd()), p.PHCSamples)
+	if err != nil {
+		return err
+	}
+	offset, delay := phcOffset(samples)
+
+	tags := map[string]string{"device": device}
+	fields := map[string]interface{}{
+		"offset": offset - int64(p.PHCUTCOffset),
+		"delay":  delay,
+	}
+	acc.AddFields("ptp_phc", fields, tags)
+
+	return nil
+}
+
+// phcOffset returns the offset of the PHC to the system clock and the delay
+// of the reading using the sample with the shortest delay, as phc2sys does
+func phcOffset(samples *unix.PtpSysOffsetExtended) (offset, delay int64) {
+	delay = -1
+	for i := 0; i < int(samples.Samples) && i < len(samples.Ts); i++ {
+		before := clockTime(samples.Ts[i][0])
+		phc := clockTime(samples.Ts[i][1])
+		after := clockTime(samples.Ts[i][2])
+
+		d := after - before
+		if delay >= 0 && d >= delay {
+			continue
+		}
+		delay = d
+		offset = phc - (before + d/2)
+	}
+	return offset, delay
+}
+
+func clockTime(t unix.PtpClockTime) int64 {
+	return t.Sec*int64(time.Second) + int64(t.Nsec)
+}
+
+func delayMechanism(mechanism uint8) string {
+	switch mechanism {
+	case 0x01:
+		return "e2e"
+	case 0x02:
+		return "p2p"
+	case 0x03:
+		return "common_p2p"
+	case 0x04:
+		return "special"
+	case 0xfe:
+		return "none"
+	}
+	return "unknown"
+}
+
+func init() {
+	inputs.Add("ptp", func() telegraf.Input {
+		return &PTP{
+			Sockets:    []string{"/var/run/ptp4l"},
+			PHCSamples: 5,
+			Timeout:    config.Duration(time.Second),
+		}
+	})
+}
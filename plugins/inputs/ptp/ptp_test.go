@@ -0,0 +1,235 @@
+//go:build linux
+
+package ptp
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInit(t *testing.T) {
+	plugin := &PTP{}
+	require.ErrorContains(t, plugin.Init(), "neither sockets nor PHC devices configured")
+
+	plugin = &PTP{PHCDevices: []string{"/dev/ptp0"}, PHCSamples: 26}
+	require.ErrorContains(t, plugin.Init(), "phc_samples must not exceed 25")
+
+	plugin = &PTP{Sockets: []string{"/var/run/ptp4l"}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, uint(5), plugin.PHCSamples)
+	require.Equal(t, config.Duration(time.Second), plugin.Timeout)
+}
+
+func TestManagementGet(t *testing.T) {
+	msg := managementGet(24, 0x1234, idCurrentDataSet)
+	require.Len(t, msg, 54)
+	require.Equal(t, byte(0x0d), msg[0])
+	require.Equal(t, byte(0x02), msg[1])
+	require.Equal(t, uint16(54), binary.BigEndian.Uint16(msg[2:]))
+	require.Equal(t, byte(24), msg[4])
+	require.Equal(t, uint16(0x1234), binary.BigEndian.Uint16(msg[30:]))
+	require.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, msg[34:44])
+	require.Equal(t, uint16(tlvManagement), binary.BigEndian.Uint16(msg[48:]))
+	require.Equal(t, uint16(idCurrentDataSet), binary.BigEndian.Uint16(msg[52:]))
+}
+
+func TestParseManagementResponseError(t *testing.T) {
+	msg := response(managementGet(0, 1, idCurrentDataSet), idCurrentDataSet, nil)
+	binary.BigEndian.PutUint16(msg[48:], tlvManagementErrorStatus)
+	_, err := parseManagementResponse(msg, 1, idCurrentDataSet)
+	require.ErrorContains(t, err, "management error status")
+
+	_, err = parseManagementResponse(msg, 2, idCurrentDataSet)
+	require.ErrorIs(t, err, errSequenceMismatch)
+}
+
+func TestGather(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ptp4l")
+	server := newFakeServer(t, socket)
+	defer server.Close()
+
+	plugin := &PTP{
+		Sockets: []string{socket},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ptp",
+			map[string]string{"socket": socket},
+			map[string]interface{}{
+				"steps_removed":      uint64(1),
+				"offset_from_master": float64(-12.5),
+				"mean_path_delay":    float64(731),
+				"master_offset":      int64(-12),
+				"gm_present":         true,
+				"gm_identity":        "ec4670.fffe.0a9c1d",
+				"gm_clock_class":     uint64(6),
+				"gm_clock_accuracy":  uint64(0x21),
+				"gm_priority1":       uint64(128),
+				"gm_priority2":       uint64(127),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ptp_port",
+			map[string]string{"socket": socket, "port": "1"},
+			map[string]interface{}{
+				"port_state":           "slave",
+				"port_state_code":      uint64(9),
+				"peer_mean_path_delay": float64(0),
+				"log_sync_interval":    int64(-3),
+				"delay_mechanism":      "e2e",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ptp_port",
+			map[string]string{"socket": socket, "port": "2"},
+			map[string]interface{}{
+				"port_state":           "master",
+				"port_state_code":      uint64(6),
+				"peer_mean_path_delay": float64(0),
+				"log_sync_interval":    int64(-3),
+				"delay_mechanism":      "e2e",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherTimeout(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ptp4l")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	plugin := &PTP{
+		Sockets: []string{socket},
+		Timeout: config.Duration(50 * time.Millisecond),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "requesting default data set failed")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestPHCOffset(t *testing.T) {
+	samples := &unix.PtpSysOffsetExtended{Samples: 3}
+	samples.Ts[0] = [3]unix.PtpClockTime{{Sec: 100, Nsec: 0}, {Sec: 137, Nsec: 600}, {Sec: 100, Nsec: 1000}}
+	samples.Ts[1] = [3]unix.PtpClockTime{{Sec: 100, Nsec: 2000}, {Sec: 137, Nsec: 2300}, {Sec: 100, Nsec: 2400}}
+	samples.Ts[2] = [3]unix.PtpClockTime{{Sec: 100, Nsec: 3000}, {Sec: 137, Nsec: 3500}, {Sec: 100, Nsec: 3800}}
+
+	offset, delay := phcOffset(samples)
+	require.Equal(t, int64(400), delay)
+	require.Equal(t, int64(37*time.Second+100), offset)
+}
+
+// fakeServer mimics the management interface of ptp4l with a boundary clock
+// having two ports
+type fakeServer struct {
+	conn *net.UnixConn
+}
+
+func newFakeServer(t *testing.T, socket string) *fakeServer {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+
+	s := &fakeServer{conn: conn}
+	go s.serve()
+	return s
+}
+
+func (s *fakeServer) Close() {
+	s.conn.Close()
+}
+
+func (s *fakeServer) serve() {
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := s.conn.ReadFromUnix(buf)
+		if err != nil {
+			return
+		}
+		request := append([]byte(nil), buf[:n]...)
+		id := binary.BigEndian.Uint16(request[52:])
+
+		var responses [][]byte
+		switch id {
+		case idDefaultDataSet:
+			data := make([]byte, 20)
+			binary.BigEndian.PutUint16(data[2:], 2)
+			responses = append(responses, data)
+		case idCurrentDataSet:
+			data := make([]byte, 18)
+			binary.BigEndian.PutUint16(data[0:], 1)
+			offset := -12.5 * math.Exp2(16)
+			binary.BigEndian.PutUint64(data[2:], uint64(int64(offset)))
+			binary.BigEndian.PutUint64(data[10:], uint64(731<<16))
+			responses = append(responses, data)
+		case idParentDataSet:
+			data := make([]byte, 32)
+			data[18] = 128
+			data[19] = 6
+			data[20] = 0x21
+			data[23] = 127
+			copy(data[24:], []byte{0xec, 0x46, 0x70, 0xff, 0xfe, 0x0a, 0x9c, 0x1d})
+			responses = append(responses, data)
+		case idTimeStatusNP:
+			data := make([]byte, 50)
+			offset := int64(-12)
+			binary.BigEndian.PutUint64(data[0:], uint64(offset))
+			binary.BigEndian.PutUint32(data[38:], 1)
+			responses = append(responses, data)
+		case idPortDataSet:
+			for port, state := range []byte{9, 6} {
+				data := make([]byte, 26)
+				binary.BigEndian.PutUint16(data[8:], uint16(port+1))
+				data[10] = state
+				data[22] = 0xfd // -3
+				data[23] = 1
+				responses = append(responses, data)
+			}
+		}
+
+		for _, data := range responses {
+			if _, err := s.conn.WriteToUnix(response(request, id, data), addr); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// response builds a management response to the given request
+func response(request []byte, id uint16, data []byte) []byte {
+	msg := make([]byte, managementLength+tlvHeaderLength+len(data))
+	copy(msg, request[:managementLength])
+	binary.BigEndian.PutUint16(msg[2:], uint16(len(msg)))
+	msg[headerLength+12] = actionResponse
+	binary.BigEndian.PutUint16(msg[managementLength:], tlvManagement)
+	binary.BigEndian.PutUint16(msg[managementLength+2:], uint16(2+len(data)))
+	binary.BigEndian.PutUint16(msg[managementLength+4:], id)
+	copy(msg[managementLength+tlvHeaderLength:], data)
+	return msg
+}
@@ -0,0 +1,174 @@
+package redfish
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+type eventService struct {
+	ServiceEnabled     *bool
+	ServerSentEventURI string `json:"ServerSentEventUri"`
+}
+
+type event struct {
+	EventType         string
+	EventID           string `json:"EventId"`
+	EventTimestamp    string
+	Severity          string
+	MessageSeverity   string
+	Message           string
+	MessageID         string `json:"MessageId"`
+	MessageArgs       []string
+	OriginOfCondition struct {
+		Ref string `json:"@odata.id"`
+	}
+}
+
+// eventPayload is the data of a server-sent event, either an event record
+// or a metric report
+type eventPayload struct {
+	metricReport
+	Events []event
+}
+
+// listenEvents keeps the event stream connected until the context is
+// cancelled
+func (r *Redfish) listenEvents(ctx context.Context, acc telegraf.Accumulator) {
+	for {
+		err := r.streamEvents(ctx, acc)
+		if ctx.Err() != nil {
+			return
+		}
+		acc.AddError(fmt.Errorf("event stream of %q failed: %w", r.Address, err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(r.EventRetry)):
+		}
+	}
+}
+
+func (r *Redfish) streamEvents(ctx context.Context, acc telegraf.Accumulator) error {
+	loc := r.baseURL.ResolveReference(&url.URL{Path: "/redfish/v1/EventService"})
+	var service eventService
+	if err := r.getData(loc.String(), &service); err != nil {
+		return fmt.Errorf("getting event service failed: %w", err)
+	}
+	if service.ServiceEnabled != nil && !*service.ServiceEnabled {
+		return errors.New("event service is disabled")
+	}
+	if service.ServerSentEventURI == "" {
+		return errors.New("event service does not support server-sent events")
+	}
+
+	u, err := url.Parse(service.ServerSentEventURI)
+	if err != nil {
+		return fmt.Errorf("parsing server-sent event URI failed: %w", err)
+	}
+	u = r.baseURL.ResolveReference(u)
+	if r.EventFilter != "" {
+		query := u.Query()
+		query.Set("$filter", r.EventFilter)
+		u.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if err := r.setAuth(req); err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := r.eventClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status code %d (%s) for event stream, expected 200",
+			resp.StatusCode,
+			http.StatusText(resp.StatusCode))
+	}
+	r.Log.Debugf("Subscribed to events of %q", r.Address)
+
+	// Parse the stream according to the server-sent events specification,
+	// only the data lines are relevant
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				r.addEventPayload(acc, []byte(data.String()))
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("event stream closed by server")
+}
+
+func (r *Redfish) addEventPayload(acc telegraf.Accumulator, data []byte) {
+	var payload eventPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		acc.AddError(fmt.Errorf("parsing event failed: %w", err))
+		return
+	}
+
+	if len(payload.MetricValues) > 0 {
+		r.addMetricReport(acc, &payload.metricReport)
+	}
+
+	for _, e := range payload.Events {
+		severity := e.MessageSeverity
+		if severity == "" {
+			severity = e.Severity
+		}
+		tags := map[string]string{
+			"address":    r.address,
+			"event_type": e.EventType,
+			"severity":   severity,
+			"message_id": e.MessageID,
+		}
+		if e.OriginOfCondition.Ref != "" {
+			tags["origin"] = e.OriginOfCondition.Ref
+		}
+		fields := map[string]interface{}{
+			"message": e.Message,
+		}
+		if e.EventID != "" {
+			fields["event_id"] = e.EventID
+		}
+		if len(e.MessageArgs) > 0 {
+			fields["message_args"] = strings.Join(e.MessageArgs, ",")
+		}
+
+		timestamp, err := time.Parse(time.RFC3339, e.EventTimestamp)
+		if err != nil {
+			timestamp = time.Now()
+		}
+		acc.AddFields("redfish_event", fields, tags, timestamp)
+	}
+}
@@ -2,6 +2,7 @@
 package redfish
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
 	"errors"
@@ -13,10 +14,12 @@ import (
 	"path"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -38,12 +41,24 @@ type Redfish struct {
 	IncludeMetrics   []string        `toml:"include_metrics"`
 	IncludeTagSets   []string        `toml:"include_tag_sets"`
 	Workarounds      []string        `toml:"workarounds"`
+	TelemetryReports []string        `toml:"telemetry_reports"`
+	SubscribeEvents  bool            `toml:"subscribe_events"`
+	EventFilter      string          `toml:"event_filter"`
+	EventRetry       config.Duration `toml:"event_retry_interval"`
 	Timeout          config.Duration `toml:"timeout"`
-
-	tagSet map[string]bool
-	client http.Client
+	Log              telegraf.Logger `toml:"-"`
+
+	tagSet        map[string]bool
+	reportFilter  filter.Filter
+	gatherSystem  bool
+	gatherReports bool
+	client        http.Client
+	eventClient   http.Client
 	tls.ClientConfig
 	baseURL *url.URL
+	address string
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
 }
 
 type system struct {
@@ -166,21 +181,33 @@ func (r *Redfish) Init() error {
 		return errors.New("did not provide username and password")
 	}
 
-	if r.ComputerSystemID == "" {
-		return errors.New("did not provide the computer system ID of the resource")
-	}
-
-	if len(r.IncludeMetrics) == 0 {
+	if len(r.IncludeMetrics) == 0 && !r.SubscribeEvents {
 		return errors.New("no metrics specified to collect")
 	}
 	for _, metric := range r.IncludeMetrics {
 		switch metric {
 		case "thermal", "power":
+			r.gatherSystem = true
+		case "telemetry":
+			r.gatherReports = true
 		default:
 			return fmt.Errorf("unknown metric requested: %s", metric)
 		}
 	}
 
+	if r.gatherSystem && r.ComputerSystemID == "" {
+		return errors.New("did not provide the computer system ID of the resource")
+	}
+
+	var err error
+	r.reportFilter, err = filter.Compile(r.TelemetryReports)
+	if err != nil {
+		return fmt.Errorf("creating telemetry report filter failed: %w", err)
+	}
+	if r.EventRetry <= 0 {
+		r.EventRetry = config.Duration(30 * time.Second)
+	}
+
 	for _, workaround := range r.Workarounds {
 		switch workaround {
 		case "ilo4-thermal":
@@ -193,11 +220,14 @@ func (r *Redfish) Init() error {
 		r.tagSet[setLabel] = true
 	}
 
-	var err error
 	r.baseURL, err = url.Parse(r.Address)
 	if err != nil {
 		return err
 	}
+	r.address, _, err = net.SplitHostPort(r.baseURL.Host)
+	if err != nil {
+		r.address = r.baseURL.Host
+	}
 
 	tlsCfg, err := r.ClientConfig.TLSConfig()
 	if err != nil {
@@ -211,14 +241,52 @@ func (r *Redfish) Init() error {
 		},
 		Timeout: time.Duration(r.Timeout),
 	}
+	// The event stream is long-lived so only limit the time to receive the
+	// response headers
+	r.eventClient = http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:       tlsCfg,
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: time.Duration(r.Timeout),
+		},
+	}
+
+	return nil
+}
+
+func (r *Redfish) Start(acc telegraf.Accumulator) error {
+	if !r.SubscribeEvents {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.listenEvents(ctx, acc)
+	}()
 
 	return nil
 }
 
+func (r *Redfish) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
 func (r *Redfish) Gather(acc telegraf.Accumulator) error {
-	address, _, err := net.SplitHostPort(r.baseURL.Host)
-	if err != nil {
-		address = r.baseURL.Host
+	address := r.address
+
+	if r.gatherReports {
+		if err := r.gatherTelemetry(acc); err != nil {
+			return err
+		}
+	}
+	if !r.gatherSystem {
+		return nil
 	}
 
 	system, err := r.getComputerSystem(r.ComputerSystemID)
@@ -239,6 +307,8 @@ func (r *Redfish) Gather(acc telegraf.Accumulator) error {
 				err = r.gatherThermal(acc, address, system, chassis)
 			case "power":
 				err = r.gatherPower(acc, address, system, chassis)
+			case "telemetry":
+				continue
 			default:
 				return fmt.Errorf("unknown metric requested: %s", metric)
 			}
@@ -250,12 +320,7 @@ func (r *Redfish) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (r *Redfish) getData(address string, payload interface{}) error {
-	req, err := http.NewRequest("GET", address, nil)
-	if err != nil {
-		return err
-	}
-
+func (r *Redfish) setAuth(req *http.Request) error {
 	username, err := r.Username.Get()
 	if err != nil {
 		return fmt.Errorf("getting username failed: %w", err)
@@ -271,6 +336,18 @@ func (r *Redfish) getData(address string, payload interface{}) error {
 	password.Destroy()
 
 	req.SetBasicAuth(user, pass)
+	return nil
+}
+
+func (r *Redfish) getData(address string, payload interface{}) error {
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := r.setAuth(req); err != nil {
+		return err
+	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("OData-Version", "4.0")
@@ -1212,3 +1212,188 @@ func TestIncludeTagSetsConfiguration(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expectedMetricsHp, hpAcc.GetTelegrafMetrics(),
 		testutil.IgnoreTime())
 }
+
+func TestTelemetryReports(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkAuth(r, "test", "test") {
+			http.Error(w, "Unauthorized.", 401)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/redfish/v1/TelemetryService/MetricReports":
+			http.ServeFile(w, r, "testdata/telemetry_reports.json")
+		case "/redfish/v1/TelemetryService/MetricReports/PowerMetrics":
+			http.ServeFile(w, r, "testdata/telemetry_power.json")
+		case "/redfish/v1/TelemetryService/MetricReports/ThermalMetrics":
+			http.ServeFile(w, r, "testdata/telemetry_thermal.json")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	address, _, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"redfish_telemetry",
+			map[string]string{
+				"address":         address,
+				"report":          "PowerMetrics",
+				"metric_id":       "SystemInputPower",
+				"metric_property": "/redfish/v1/Chassis/1/Power#/PowerControl/0/PowerConsumedWatts",
+			},
+			map[string]interface{}{"value": 412.5},
+			time.Date(2024, 5, 21, 10, 15, 0, 0, time.UTC),
+		),
+		testutil.MustMetric(
+			"redfish_telemetry",
+			map[string]string{
+				"address":         address,
+				"report":          "PowerMetrics",
+				"metric_id":       "PSU1InputVoltage",
+				"metric_property": "/redfish/v1/Chassis/1/Power#/PowerSupplies/0/LineInputVoltage",
+			},
+			map[string]interface{}{"value": 230.0},
+			time.Date(2024, 5, 21, 10, 15, 30, 0, time.UTC),
+		),
+		testutil.MustMetric(
+			"redfish_telemetry",
+			map[string]string{
+				"address":   address,
+				"report":    "PowerMetrics",
+				"metric_id": "PSU1State",
+			},
+			map[string]interface{}{"value": "Enabled"},
+			time.Date(2024, 5, 21, 10, 15, 0, 0, time.UTC),
+		),
+	}
+
+	plugin := &Redfish{
+		Address:          ts.URL,
+		Username:         config.NewSecret([]byte("test")),
+		Password:         config.NewSecret([]byte("test")),
+		IncludeMetrics:   []string{"telemetry"},
+		TelemetryReports: []string{"Power*"},
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestMissingComputerSystemID(t *testing.T) {
+	plugin := &Redfish{
+		Address:        "http://127.0.0.1",
+		Username:       config.NewSecret([]byte("test")),
+		Password:       config.NewSecret([]byte("test")),
+		IncludeMetrics: []string{"telemetry", "power"},
+	}
+	require.EqualError(t, plugin.Init(), "did not provide the computer system ID of the resource")
+}
+
+func TestEventSubscription(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkAuth(r, "test", "test") {
+			http.Error(w, "Unauthorized.", 401)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/redfish/v1/EventService":
+			if _, err := w.Write([]byte(`{"ServiceEnabled": true, "ServerSentEventUri": "/redfish/v1/EventService/SSE"}`)); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		case "/redfish/v1/EventService/SSE":
+			if r.URL.Query().Get("$filter") != "EventFormatType eq 'Event'" || r.Header.Get("Accept") != "text/event-stream" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			stream := `: keep-alive
+
+id: 1
+data: {"@odata.type": "#Event.v1_7_0.Event", "Id": "1", "Name": "Event Array",
+data:  "Events": [{"EventType": "Alert", "EventId": "4711", "EventTimestamp": "2024-05-21T10:20:00Z",
+data:  "MessageSeverity": "Critical", "Message": "The power supply PSU1 has failed.", "MessageId": "PowerSupply.1.0.PowerSupplyFailed",
+data:  "MessageArgs": ["PSU1"], "OriginOfCondition": {"@odata.id": "/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/1"}}]}
+
+id: 2
+data: {"@odata.type": "#MetricReport.v1_4_2.MetricReport", "Id": "PowerMetrics", "Timestamp": "2024-05-21T10:20:30Z",
+data:  "MetricValues": [{"MetricId": "SystemInputPower", "MetricValue": "398"}]}
+
+`
+			if _, err := w.Write([]byte(stream)); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	address, _, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"redfish_event",
+			map[string]string{
+				"address":    address,
+				"event_type": "Alert",
+				"severity":   "Critical",
+				"message_id": "PowerSupply.1.0.PowerSupplyFailed",
+				"origin":     "/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/1",
+			},
+			map[string]interface{}{
+				"message":      "The power supply PSU1 has failed.",
+				"event_id":     "4711",
+				"message_args": "PSU1",
+			},
+			time.Date(2024, 5, 21, 10, 20, 0, 0, time.UTC),
+		),
+		testutil.MustMetric(
+			"redfish_telemetry",
+			map[string]string{
+				"address":   address,
+				"report":    "PowerMetrics",
+				"metric_id": "SystemInputPower",
+			},
+			map[string]interface{}{"value": 398.0},
+			time.Date(2024, 5, 21, 10, 20, 30, 0, time.UTC),
+		),
+	}
+
+	plugin := &Redfish{
+		Address:         ts.URL,
+		Username:        config.NewSecret([]byte("test")),
+		Password:        config.NewSecret([]byte("test")),
+		SubscribeEvents: true,
+		EventFilter:     "EventFormatType eq 'Event'",
+		Timeout:         config.Duration(5 * time.Second),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
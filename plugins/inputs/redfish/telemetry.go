@@ -0,0 +1,95 @@
+package redfish
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+type metricReportCollection struct {
+	Members []struct {
+		Ref string `json:"@odata.id"`
+	}
+}
+
+type metricReport struct {
+	ID           string `json:"Id"`
+	Timestamp    string
+	MetricValues []struct {
+		MetricID       string `json:"MetricId"`
+		MetricProperty string
+		// The schema defines the value as string but some implementations
+		// report numbers instead
+		MetricValue interface{}
+		Timestamp   string
+	}
+}
+
+func (r *Redfish) gatherTelemetry(acc telegraf.Accumulator) error {
+	loc := r.baseURL.ResolveReference(&url.URL{Path: "/redfish/v1/TelemetryService/MetricReports"})
+	var collection metricReportCollection
+	if err := r.getData(loc.String(), &collection); err != nil {
+		return fmt.Errorf("getting metric reports failed: %w", err)
+	}
+
+	for _, member := range collection.Members {
+		// The last path element of the report reference is the report ID
+		if !r.reportFilter.Match(path.Base(member.Ref)) {
+			continue
+		}
+
+		loc := r.baseURL.ResolveReference(&url.URL{Path: member.Ref})
+		var report metricReport
+		if err := r.getData(loc.String(), &report); err != nil {
+			acc.AddError(fmt.Errorf("getting metric report %q failed: %w", member.Ref, err))
+			continue
+		}
+		r.addMetricReport(acc, &report)
+	}
+
+	return nil
+}
+
+// addMetricReport adds one metric per value of the report using the
+// timestamp of the value or the report if available
+func (r *Redfish) addMetricReport(acc telegraf.Accumulator, report *metricReport) {
+	reportTime, err := time.Parse(time.RFC3339, report.Timestamp)
+	if err != nil {
+		reportTime = time.Now()
+	}
+
+	for _, v := range report.MetricValues {
+		var value interface{}
+		switch raw := v.MetricValue.(type) {
+		case string:
+			if f, err := strconv.ParseFloat(raw, 64); err == nil {
+				value = f
+			} else {
+				value = raw
+			}
+		case float64, bool:
+			value = raw
+		default:
+			continue
+		}
+
+		tags := map[string]string{
+			"address":   r.address,
+			"report":    report.ID,
+			"metric_id": v.MetricID,
+		}
+		if v.MetricProperty != "" {
+			tags["metric_property"] = v.MetricProperty
+		}
+
+		timestamp, err := time.Parse(time.RFC3339, v.Timestamp)
+		if err != nil {
+			timestamp = reportTime
+		}
+		acc.AddFields("redfish_telemetry", map[string]interface{}{"value": value}, tags, timestamp)
+	}
+}
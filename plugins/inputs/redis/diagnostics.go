@@ -0,0 +1,412 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// Commands where the first argument is a subcommand to keep in fingerprints
+var containerCommands = map[string]bool{
+	"ACL":      true,
+	"CLIENT":   true,
+	"CLUSTER":  true,
+	"COMMAND":  true,
+	"CONFIG":   true,
+	"FUNCTION": true,
+	"LATENCY":  true,
+	"MEMORY":   true,
+	"MODULE":   true,
+	"OBJECT":   true,
+	"PUBSUB":   true,
+	"SCRIPT":   true,
+	"SLOWLOG":  true,
+	"XGROUP":   true,
+	"XINFO":    true,
+}
+
+type keySampling struct {
+	Patterns      []string        `toml:"patterns"`
+	MaxKeys       int             `toml:"max_keys"`
+	ScanCount     int             `toml:"scan_count"`
+	Timeout       config.Duration `toml:"timeout"`
+	MemorySamples int             `toml:"memory_samples"`
+
+	filters []filter.Filter
+}
+
+// serverState keeps track of the diagnostics already reported for a server
+type serverState struct {
+	latencyInit bool
+	latency     map[string]int64
+
+	slowlogInit bool
+	slowlogID   int64
+
+	cursor uint64
+}
+
+func newServerState() *serverState {
+	return &serverState{
+		latency:   make(map[string]int64),
+		slowlogID: -1,
+	}
+}
+
+func (k *keySampling) init() error {
+	if len(k.Patterns) == 0 {
+		return errors.New("no key patterns configured for sampling")
+	}
+	if k.MaxKeys <= 0 {
+		k.MaxKeys = 1000
+	}
+	if k.ScanCount <= 0 {
+		k.ScanCount = 100
+	}
+	if k.Timeout <= 0 {
+		k.Timeout = config.Duration(time.Second)
+	}
+	if k.MemorySamples <= 0 {
+		k.MemorySamples = 5
+	}
+
+	k.filters = make([]filter.Filter, 0, len(k.Patterns))
+	for _, pattern := range k.Patterns {
+		f, err := filter.Compile([]string{pattern})
+		if err != nil {
+			return fmt.Errorf("compiling key pattern %q failed: %w", pattern, err)
+		}
+		k.filters = append(k.filters, f)
+	}
+
+	return nil
+}
+
+func (r *Redis) gatherDiagnostics(client client, acc telegraf.Accumulator) {
+	state, found := r.states[client]
+	if !found {
+		return
+	}
+
+	if r.GatherLatency {
+		if err := gatherLatency(client, state, acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering latency events failed: %w", err))
+		}
+	}
+	if r.GatherSlowlog {
+		if err := gatherSlowlog(client, state, r.SlowlogMaxEntries, acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering slowlog failed: %w", err))
+		}
+	}
+	if r.KeySampling != nil {
+		if err := r.KeySampling.gather(client, state, acc); err != nil {
+			acc.AddError(fmt.Errorf("sampling keys failed: %w", err))
+		}
+	}
+}
+
+// gatherLatency reports the samples of the latency monitor recorded since the
+// last gather. The latency monitor must be enabled on the server by setting
+// "latency-monitor-threshold".
+func gatherLatency(client client, state *serverState, acc telegraf.Accumulator) error {
+	ctx := context.Background()
+
+	latest, err := client.exec(ctx, "latency", "latest")
+	if err != nil {
+		return err
+	}
+	events, ok := latest.([]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected LATENCY LATEST response %T", latest)
+	}
+
+	for _, raw := range events {
+		entry, ok := raw.([]interface{})
+		if !ok || len(entry) == 0 {
+			continue
+		}
+		event, ok := entry[0].(string)
+		if !ok {
+			continue
+		}
+
+		history, err := client.exec(ctx, "latency", "history", event)
+		if err != nil {
+			return fmt.Errorf("getting history of event %q failed: %w", event, err)
+		}
+		samples, ok := history.([]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected LATENCY HISTORY response %T", history)
+		}
+
+		last := state.latency[event]
+		for _, rawSample := range samples {
+			sample, ok := rawSample.([]interface{})
+			if !ok || len(sample) < 2 {
+				continue
+			}
+			ts, ok := sample[0].(int64)
+			if !ok || ts <= state.latency[event] {
+				continue
+			}
+			latency, ok := sample[1].(int64)
+			if !ok {
+				continue
+			}
+			last = max(last, ts)
+
+			// Only report events recorded after startup
+			if !state.latencyInit {
+				continue
+			}
+			tags := client.baseTags()
+			tags["event"] = event
+			acc.AddFields("redis_latency", map[string]interface{}{"latency_ms": latency}, tags, time.Unix(ts, 0))
+		}
+		state.latency[event] = last
+	}
+	state.latencyInit = true
+
+	return nil
+}
+
+// gatherSlowlog reports the slowlog entries added since the last gather
+func gatherSlowlog(client client, state *serverState, maxEntries int, acc telegraf.Accumulator) error {
+	res, err := client.exec(context.Background(), "slowlog", "get", maxEntries)
+	if err != nil {
+		return err
+	}
+	entries, ok := res.([]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected SLOWLOG GET response %T", res)
+	}
+
+	type slowlogEntry struct {
+		id       int64
+		ts       int64
+		duration int64
+		args     []string
+		addr     string
+		name     string
+	}
+	parsed := make([]slowlogEntry, 0, len(entries))
+	maxID := int64(-1)
+	for _, raw := range entries {
+		fields, ok := raw.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		var e slowlogEntry
+		var okID, okTS, okDuration bool
+		e.id, okID = fields[0].(int64)
+		e.ts, okTS = fields[1].(int64)
+		e.duration, okDuration = fields[2].(int64)
+		if !okID || !okTS || !okDuration {
+			continue
+		}
+		if args, ok := fields[3].([]interface{}); ok {
+			for _, a := range args {
+				if s, ok := a.(string); ok {
+					e.args = append(e.args, s)
+				}
+			}
+		}
+		// Client address and name are available since Redis 4.0
+		if len(fields) >= 6 {
+			e.addr, _ = fields[4].(string)
+			e.name, _ = fields[5].(string)
+		}
+		parsed = append(parsed, e)
+		maxID = max(maxID, e.id)
+	}
+
+	// The entry IDs are reset on server restart
+	if maxID < state.slowlogID {
+		state.slowlogID = -1
+	}
+
+	// Only report entries added after startup
+	if state.slowlogInit {
+		for _, e := range parsed {
+			if e.id <= state.slowlogID {
+				continue
+			}
+			command, fp := fingerprint(e.args)
+			tags := client.baseTags()
+			tags["command"] = command
+			tags["fingerprint"] = fp
+
+			fields := map[string]interface{}{
+				"id":          e.id,
+				"duration_us": e.duration,
+			}
+			if e.addr != "" {
+				fields["client_addr"] = e.addr
+			}
+			if e.name != "" {
+				fields["client_name"] = e.name
+			}
+			acc.AddFields("redis_slowlog", fields, tags, time.Unix(e.ts, 0))
+		}
+	}
+	state.slowlogID = max(state.slowlogID, maxID)
+	state.slowlogInit = true
+
+	return nil
+}
+
+// fingerprint replaces the arguments of a command by placeholders to group
+// entries independent of the keys and values, e.g. "SET key value" becomes
+// "SET ? ?"
+func fingerprint(args []string) (command, fp string) {
+	if len(args) == 0 {
+		return "", ""
+	}
+
+	command = strings.ToUpper(args[0])
+	parts := []string{command}
+	remaining := args[1:]
+	if containerCommands[command] && len(remaining) > 0 {
+		parts = append(parts, strings.ToUpper(remaining[0]))
+		remaining = remaining[1:]
+	}
+	for _, arg := range remaining {
+		// Redis truncates commands with many arguments
+		if strings.HasPrefix(arg, "... (") && strings.HasSuffix(arg, " more arguments)") {
+			parts = append(parts, "...")
+			break
+		}
+		parts = append(parts, "?")
+	}
+
+	return command, strings.Join(parts, " ")
+}
+
+// gather samples keys using SCAN, continuing the iteration of the previous
+// gather, and reports the memory usage of the keys matching the patterns. The
+// number of inspected keys and the time spent are limited to keep the load on
+// the server low.
+func (k *keySampling) gather(client client, state *serverState, acc telegraf.Accumulator) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(k.Timeout))
+	defer cancel()
+
+	res, err := client.exec(ctx, "dbsize")
+	if err != nil {
+		return err
+	}
+	dbsize, ok := res.(int64)
+	if !ok {
+		return fmt.Errorf("unexpected DBSIZE response %T", res)
+	}
+
+	keys := make([]int64, len(k.filters))
+	usage := make([]int64, len(k.filters))
+	var scanned int64
+	cursor := state.cursor
+
+scan:
+	for scanned < int64(k.MaxKeys) {
+		count := min(k.ScanCount, k.MaxKeys-int(scanned))
+		res, err := client.exec(ctx, "scan", cursor, "count", count)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			return err
+		}
+		next, batch, err := parseScanResponse(res)
+		if err != nil {
+			return err
+		}
+		cursor = next
+
+		for _, key := range batch {
+			if scanned >= int64(k.MaxKeys) {
+				break scan
+			}
+			scanned++
+
+			for i, f := range k.filters {
+				if !f.Match(key) {
+					continue
+				}
+				res, err := client.exec(ctx, "memory", "usage", key, "samples", k.MemorySamples)
+				if err != nil {
+					if errors.Is(err, context.DeadlineExceeded) {
+						break scan
+					}
+					// The key might have expired in the meantime
+					if errors.Is(err, redis.Nil) {
+						break
+					}
+					return err
+				}
+				if v, ok := res.(int64); ok {
+					keys[i]++
+					usage[i] += v
+				}
+				break
+			}
+		}
+
+		// Stop at the end of a full iteration to not count keys twice
+		if cursor == 0 {
+			break
+		}
+	}
+	state.cursor = cursor
+
+	for i, pattern := range k.Patterns {
+		tags := client.baseTags()
+		tags["pattern"] = pattern
+		fields := map[string]interface{}{
+			"keys_scanned": scanned,
+			"keys_sampled": keys[i],
+			"memory_bytes": usage[i],
+		}
+		if keys[i] > 0 {
+			fields["avg_memory_bytes"] = float64(usage[i]) / float64(keys[i])
+		}
+		if scanned > 0 {
+			fields["keys_estimated"] = keys[i] * dbsize / scanned
+		}
+		acc.AddFields("redis_key_pattern", fields, tags)
+	}
+
+	return nil
+}
+
+func parseScanResponse(res interface{}) (uint64, []string, error) {
+	parts, ok := res.([]interface{})
+	if !ok || len(parts) != 2 {
+		return 0, nil, fmt.Errorf("unexpected SCAN response %v", res)
+	}
+	rawCursor, ok := parts[0].(string)
+	if !ok {
+		return 0, nil, fmt.Errorf("unexpected SCAN cursor %T", parts[0])
+	}
+	cursor, err := strconv.ParseUint(rawCursor, 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("parsing SCAN cursor failed: %w", err)
+	}
+	rawKeys, ok := parts[1].([]interface{})
+	if !ok {
+		return 0, nil, fmt.Errorf("unexpected SCAN keys %T", parts[1])
+	}
+	keys := make([]string, 0, len(rawKeys))
+	for _, k := range rawKeys {
+		if s, ok := k.(string); ok {
+			keys = append(keys, s)
+		}
+	}
+	return cursor, keys, nil
+}
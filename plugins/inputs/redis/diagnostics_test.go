@@ -0,0 +1,297 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// scriptedClient replies to commands with predefined responses
+type scriptedClient struct {
+	testClient
+	responses map[string]interface{}
+	calls     []string
+}
+
+func (c *scriptedClient) exec(_ context.Context, args ...interface{}) (interface{}, error) {
+	parts := make([]string, 0, len(args))
+	for _, a := range args {
+		parts = append(parts, fmt.Sprint(a))
+	}
+	cmd := strings.Join(parts, " ")
+	c.calls = append(c.calls, cmd)
+
+	response, found := c.responses[cmd]
+	if !found {
+		return nil, redis.Nil
+	}
+	if err, ok := response.(error); ok {
+		return nil, err
+	}
+	return response, nil
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		args        []string
+		command     string
+		fingerprint string
+	}{
+		{
+			args:        []string{"set", "user:1", "value"},
+			command:     "SET",
+			fingerprint: "SET ? ?",
+		},
+		{
+			args:        []string{"CONFIG", "get", "maxmemory"},
+			command:     "CONFIG",
+			fingerprint: "CONFIG GET ?",
+		},
+		{
+			args:        []string{"del", "a", "b", "... (30 more arguments)"},
+			command:     "DEL",
+			fingerprint: "DEL ? ? ...",
+		},
+		{
+			args:        []string{"ping"},
+			command:     "PING",
+			fingerprint: "PING",
+		},
+		{},
+	}
+	for _, tt := range tests {
+		command, fp := fingerprint(tt.args)
+		require.Equal(t, tt.command, command)
+		require.Equal(t, tt.fingerprint, fp)
+	}
+}
+
+func TestGatherSlowlog(t *testing.T) {
+	entry := func(id, ts, duration int64, args ...interface{}) []interface{} {
+		return []interface{}{id, ts, duration, args, "127.0.0.1:58732", "worker"}
+	}
+	client := &scriptedClient{
+		responses: map[string]interface{}{
+			"slowlog get 128": []interface{}{
+				entry(1, 1736496000, 12000, "keys", "*"),
+				entry(0, 1736495990, 11000, "get", "foo"),
+			},
+		},
+	}
+	state := newServerState()
+
+	// Entries existing at startup are skipped
+	var acc testutil.Accumulator
+	require.NoError(t, gatherSlowlog(client, state, 128, &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	client.responses["slowlog get 128"] = []interface{}{
+		entry(3, 1736496020, 25000, "hgetall", "session:42"),
+		// Entries of Redis versions before 4.0 do not contain the client
+		[]interface{}{int64(2), int64(1736496010), int64(15000), []interface{}{"sort", "list"}},
+		entry(1, 1736496000, 12000, "keys", "*"),
+	}
+	require.NoError(t, gatherSlowlog(client, state, 128, &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"redis_slowlog",
+			map[string]string{"host": "redis.net", "command": "HGETALL", "fingerprint": "HGETALL ?"},
+			map[string]interface{}{
+				"id":          int64(3),
+				"duration_us": int64(25000),
+				"client_addr": "127.0.0.1:58732",
+				"client_name": "worker",
+			},
+			time.Unix(1736496020, 0),
+		),
+		metric.New(
+			"redis_slowlog",
+			map[string]string{"host": "redis.net", "command": "SORT", "fingerprint": "SORT ?"},
+			map[string]interface{}{
+				"id":          int64(2),
+				"duration_us": int64(15000),
+			},
+			time.Unix(1736496010, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// A server restart resets the IDs
+	acc.ClearMetrics()
+	client.responses["slowlog get 128"] = []interface{}{
+		entry(0, 1736497000, 30000, "flushall"),
+	}
+	require.NoError(t, gatherSlowlog(client, state, 128, &acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.Equal(t, int64(0), state.slowlogID)
+}
+
+func TestGatherLatency(t *testing.T) {
+	client := &scriptedClient{
+		responses: map[string]interface{}{
+			"latency latest": []interface{}{
+				[]interface{}{"command", int64(1736496000), int64(250), int64(1000)},
+			},
+			"latency history command": []interface{}{
+				[]interface{}{int64(1736495990), int64(1000)},
+				[]interface{}{int64(1736496000), int64(250)},
+			},
+		},
+	}
+	state := newServerState()
+
+	// Samples existing at startup are skipped
+	var acc testutil.Accumulator
+	require.NoError(t, gatherLatency(client, state, &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	client.responses["latency latest"] = []interface{}{
+		[]interface{}{"command", int64(1736496010), int64(300), int64(1000)},
+		[]interface{}{"fork", int64(1736496005), int64(40), int64(40)},
+	}
+	client.responses["latency history command"] = []interface{}{
+		[]interface{}{int64(1736495990), int64(1000)},
+		[]interface{}{int64(1736496000), int64(250)},
+		[]interface{}{int64(1736496010), int64(300)},
+	}
+	client.responses["latency history fork"] = []interface{}{
+		[]interface{}{int64(1736496005), int64(40)},
+	}
+	require.NoError(t, gatherLatency(client, state, &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"redis_latency",
+			map[string]string{"host": "redis.net", "event": "command"},
+			map[string]interface{}{"latency_ms": int64(300)},
+			time.Unix(1736496010, 0),
+		),
+		metric.New(
+			"redis_latency",
+			map[string]string{"host": "redis.net", "event": "fork"},
+			map[string]interface{}{"latency_ms": int64(40)},
+			time.Unix(1736496005, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestKeySampling(t *testing.T) {
+	client := &scriptedClient{
+		responses: map[string]interface{}{
+			"dbsize":         int64(100),
+			"scan 0 count 3": []interface{}{"7", []interface{}{"user:1", "session:1", "other"}},
+			"scan 7 count 2": []interface{}{"0", []interface{}{"user:2", "user:3"}},
+			"scan 7 count 3": []interface{}{"0", []interface{}{"user:2", "user:3"}},
+
+			"memory usage user:1 samples 5":    int64(100),
+			"memory usage session:1 samples 5": int64(400),
+			"memory usage user:2 samples 5":    int64(200),
+			// user:3 expired in the meantime
+		},
+	}
+
+	sampling := &keySampling{
+		Patterns:  []string{"user:*", "session:*", "cache:*"},
+		MaxKeys:   5,
+		ScanCount: 3,
+	}
+	require.NoError(t, sampling.init())
+	state := newServerState()
+
+	var acc testutil.Accumulator
+	require.NoError(t, sampling.gather(client, state, &acc))
+	require.Equal(t, uint64(0), state.cursor)
+	require.NotContains(t, client.calls, "memory usage other samples 5")
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"redis_key_pattern",
+			map[string]string{"host": "redis.net", "pattern": "user:*"},
+			map[string]interface{}{
+				"keys_scanned":     int64(5),
+				"keys_sampled":     int64(2),
+				"memory_bytes":     int64(300),
+				"avg_memory_bytes": float64(150),
+				"keys_estimated":   int64(40),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"redis_key_pattern",
+			map[string]string{"host": "redis.net", "pattern": "session:*"},
+			map[string]interface{}{
+				"keys_scanned":     int64(5),
+				"keys_sampled":     int64(1),
+				"memory_bytes":     int64(400),
+				"avg_memory_bytes": float64(400),
+				"keys_estimated":   int64(20),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"redis_key_pattern",
+			map[string]string{"host": "redis.net", "pattern": "cache:*"},
+			map[string]interface{}{
+				"keys_scanned":   int64(5),
+				"keys_sampled":   int64(0),
+				"memory_bytes":   int64(0),
+				"keys_estimated": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestKeySamplingBudget(t *testing.T) {
+	client := &scriptedClient{
+		responses: map[string]interface{}{
+			"dbsize":         int64(10),
+			"scan 0 count 2": []interface{}{"4", []interface{}{"a", "b", "c"}},
+			"scan 4 count 2": []interface{}{"9", []interface{}{"d", "e"}},
+		},
+	}
+
+	sampling := &keySampling{
+		Patterns:  []string{"*"},
+		MaxKeys:   2,
+		ScanCount: 10,
+	}
+	require.NoError(t, sampling.init())
+	state := newServerState()
+
+	// The number of keys must not exceed the budget even if SCAN returns more
+	var acc testutil.Accumulator
+	require.NoError(t, sampling.gather(client, state, &acc))
+	require.Equal(t, uint64(4), state.cursor)
+	require.Equal(t, []string{"dbsize", "scan 0 count 2", "memory usage a samples 5", "memory usage b samples 5"}, client.calls)
+
+	// The next gather continues the iteration
+	client.calls = nil
+	require.NoError(t, sampling.gather(client, state, &acc))
+	require.Equal(t, uint64(9), state.cursor)
+	require.Contains(t, client.calls, "scan 4 count 2")
+}
+
+func TestKeySamplingInit(t *testing.T) {
+	sampling := &keySampling{}
+	require.ErrorContains(t, sampling.init(), "no key patterns configured")
+
+	plugin := &Redis{KeySampling: &keySampling{Patterns: []string{"user:*"}}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, 128, plugin.SlowlogMaxEntries)
+	require.Equal(t, 1000, plugin.KeySampling.MaxKeys)
+	require.Equal(t, 100, plugin.KeySampling.ScanCount)
+	require.Equal(t, 5, plugin.KeySampling.MemorySamples)
+}
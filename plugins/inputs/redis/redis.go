@@ -35,16 +35,21 @@ var (
 )
 
 type Redis struct {
-	Commands []*redisCommand `toml:"commands"`
-	Servers  []string        `toml:"servers"`
-	Username string          `toml:"username"`
-	Password string          `toml:"password"`
+	Commands          []*redisCommand `toml:"commands"`
+	Servers           []string        `toml:"servers"`
+	Username          string          `toml:"username"`
+	Password          string          `toml:"password"`
+	GatherLatency     bool            `toml:"gather_latency"`
+	GatherSlowlog     bool            `toml:"gather_slowlog"`
+	SlowlogMaxEntries int             `toml:"slowlog_max_entries"`
+	KeySampling       *keySampling    `toml:"key_sampling"`
 
 	tls.ClientConfig
 
 	Log telegraf.Logger `toml:"-"`
 
 	clients   []client
+	states    map[client]*serverState
 	connected bool
 }
 
@@ -172,6 +177,7 @@ type redisFieldTypes struct {
 
 type client interface {
 	do(returnType string, args ...interface{}) (interface{}, error)
+	exec(ctx context.Context, args ...interface{}) (interface{}, error)
 	info() *redis.StringCmd
 	baseTags() map[string]string
 	close() error
@@ -188,6 +194,15 @@ func (r *Redis) Init() error {
 		}
 	}
 
+	if r.SlowlogMaxEntries <= 0 {
+		r.SlowlogMaxEntries = 128
+	}
+	if r.KeySampling != nil {
+		if err := r.KeySampling.init(); err != nil {
+			return fmt.Errorf("invalid key sampling settings: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -211,6 +226,7 @@ func (r *Redis) Gather(acc telegraf.Accumulator) error {
 			defer wg.Done()
 			acc.AddError(gatherServer(client, acc))
 			acc.AddError(r.gatherCommandValues(client, acc))
+			r.gatherDiagnostics(client, acc)
 		}(cl)
 	}
 
@@ -238,6 +254,7 @@ func (r *Redis) connect() error {
 	}
 
 	r.clients = make([]client, 0, len(r.Servers))
+	r.states = make(map[client]*serverState, len(r.Servers))
 	for _, serv := range r.Servers {
 		if !strings.HasPrefix(serv, "tcp://") && !strings.HasPrefix(serv, "unix://") {
 			r.Log.Warn("Server URL found without scheme; please update your configuration file")
@@ -296,10 +313,12 @@ func (r *Redis) connect() error {
 			tags["port"] = u.Port()
 		}
 
-		r.clients = append(r.clients, &redisClient{
+		c := &redisClient{
 			client: client,
 			tags:   tags,
-		})
+		}
+		r.clients = append(r.clients, c)
+		r.states[c] = newServerState()
 	}
 
 	r.connected = true
@@ -341,6 +360,10 @@ func (r *redisClient) do(returnType string, args ...interface{}) (interface{}, e
 	}
 }
 
+func (r *redisClient) exec(ctx context.Context, args ...interface{}) (interface{}, error) {
+	return r.client.Do(ctx, args...).Result()
+}
+
 func (r *redisClient) info() *redis.StringCmd {
 	return r.client.Info(context.Background(), "ALL")
 }
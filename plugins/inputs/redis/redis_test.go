@@ -2,7 +2,7 @@ package redis
 
 import (
 	"bufio"
-
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -30,6 +30,10 @@ func (*testClient) do(string, ...interface{}) (interface{}, error) {
 	return 2, nil
 }
 
+func (*testClient) exec(context.Context, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
 func (*testClient) close() error {
 	return nil
 }
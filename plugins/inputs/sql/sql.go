@@ -8,9 +8,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"runtime"
 	"sort"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -18,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -26,6 +29,8 @@ var sampleConfig string
 
 var disconnectedServersBehavior = []string{"error", "ignore"}
 
+var columnRoles = []string{"tag", "field", "time", "measurement", "ignore", "field_name", "field_value"}
+
 const magicIdleCount = -int(^uint(0) >> 1)
 
 type SQL struct {
@@ -62,7 +67,16 @@ type query struct {
 	FieldColumnsBool    []string `toml:"field_columns_bool"`
 	FieldColumnsString  []string `toml:"field_columns_string"`
 
+	Interval    config.Duration   `toml:"interval"`
+	Timeout     config.Duration   `toml:"timeout"`
+	Parameters  []string          `toml:"parameters"`
+	ColumnRoles map[string]string `toml:"column_roles"`
+
 	statement         *dbsql.Stmt
+	args              []interface{}
+	fieldNameColumn   string
+	fieldValueColumn  string
+	lastRun           time.Time
 	tagFilter         filter.Filter
 	fieldFilter       filter.Filter
 	fieldFilterFloat  filter.Filter
@@ -95,6 +109,11 @@ func (s *SQL) Init() error {
 		s.MaxIdleConnections = len(s.Queries) + 2
 	}
 
+	facts, err := newHostFacts()
+	if err != nil {
+		return err
+	}
+
 	for i, q := range s.Queries {
 		if q.Query == "" && q.Script == "" {
 			return errors.New("neither 'query' nor 'query_script' specified")
@@ -118,6 +137,27 @@ func (s *SQL) Init() error {
 			s.Queries[i].TimeFormat = "unix"
 		}
 
+		if q.Interval < 0 {
+			return errors.New("'interval' must not be negative")
+		}
+		if q.Timeout < 0 {
+			return errors.New("'timeout' must not be negative")
+		}
+
+		// Render the query parameters
+		s.Queries[i].args = make([]interface{}, 0, len(q.Parameters))
+		for _, p := range q.Parameters {
+			arg, err := facts.render(p)
+			if err != nil {
+				return fmt.Errorf("rendering parameter %q failed: %w", p, err)
+			}
+			s.Queries[i].args = append(s.Queries[i].args, arg)
+		}
+
+		if err := s.Queries[i].applyColumnRoles(); err != nil {
+			return err
+		}
+
 		// Compile the tag-filter
 		tagfilter, err := filter.NewIncludeExcludeFilterDefaults(q.TagColumnsInclude, q.TagColumnsExclude, false, false)
 		if err != nil {
@@ -246,12 +286,24 @@ func (s *SQL) Gather(acc telegraf.Accumulator) error {
 	}
 
 	var wg sync.WaitGroup
+	var executed int
 	tstart := time.Now()
-	for _, q := range s.Queries {
+	for i, q := range s.Queries {
+		if !q.due(tstart) {
+			continue
+		}
+		s.Queries[i].lastRun = tstart
+		executed++
+
+		timeout := s.Timeout
+		if q.Timeout > 0 {
+			timeout = q.Timeout
+		}
+
 		wg.Add(1)
 		go func(q query) {
 			defer wg.Done()
-			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout))
 			defer cancel()
 			if err := s.executeQuery(ctx, acc, q, tstart); err != nil {
 				acc.AddError(err)
@@ -259,7 +311,7 @@ func (s *SQL) Gather(acc telegraf.Accumulator) error {
 		}(q)
 	}
 	wg.Wait()
-	s.Log.Debugf("Executed %d queries in %s", len(s.Queries), time.Since(tstart).String())
+	s.Log.Debugf("Executed %d queries in %s", executed, time.Since(tstart).String())
 
 	return nil
 }
@@ -345,14 +397,14 @@ func (s *SQL) executeQuery(ctx context.Context, acc telegraf.Accumulator, q quer
 	if q.statement != nil {
 		// Use the previously prepared query
 		var err error
-		rows, err = q.statement.QueryContext(ctx)
+		rows, err = q.statement.QueryContext(ctx, q.args...)
 		if err != nil {
 			return err
 		}
 	} else {
 		// Fallback to unprepared query
 		var err error
-		rows, err = s.db.Query(q.Query)
+		rows, err = s.db.QueryContext(ctx, q.Query, q.args...)
 		if err != nil {
 			return err
 		}
@@ -377,6 +429,50 @@ func (s *SQL) checkDSN() error {
 	return nil
 }
 
+// due returns true if the query should be executed in the gather cycle
+// started at the given time. A tolerance of 10% of the query interval is
+// applied to account for jitter in the gather cycles.
+func (q *query) due(t time.Time) bool {
+	if q.Interval <= 0 || q.lastRun.IsZero() {
+		return true
+	}
+	interval := time.Duration(q.Interval)
+	return t.Sub(q.lastRun) >= interval-interval/10
+}
+
+// applyColumnRoles validates the column roles and sets the corresponding
+// column settings
+func (q *query) applyColumnRoles() error {
+	for column, role := range q.ColumnRoles {
+		if !choice.Contains(role, columnRoles) {
+			return fmt.Errorf("invalid role %q for column %q", role, column)
+		}
+
+		var setting *string
+		switch role {
+		case "time":
+			setting = &q.TimeColumn
+		case "measurement":
+			setting = &q.MeasurementColumn
+		case "field_name":
+			setting = &q.fieldNameColumn
+		case "field_value":
+			setting = &q.fieldValueColumn
+		default:
+			continue
+		}
+		if *setting != "" && *setting != column {
+			return fmt.Errorf("role %q assigned to column %q conflicts with column %q", role, column, *setting)
+		}
+		*setting = column
+	}
+
+	if (q.fieldNameColumn == "") != (q.fieldValueColumn == "") {
+		return errors.New("roles 'field_name' and 'field_value' must be used together")
+	}
+	return nil
+}
+
 func (q *query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time, logger telegraf.Logger) (int, error) {
 	columnNames, err := rows.Columns()
 	if err != nil {
@@ -391,12 +487,21 @@ func (q *query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time, l
 		columnDataPtr[i] = &columnData[i]
 	}
 
+	// Rows containing field names and values are grouped into metrics by
+	// measurement, tags and time
+	var grouper *metric.SeriesGrouper
+	if q.fieldNameColumn != "" {
+		grouper = metric.NewSeriesGrouper()
+	}
+
 	rowCount := 0
 	for rows.Next() {
 		measurement := q.Measurement
 		timestamp := t
 		tags := make(map[string]string)
 		fields := make(map[string]interface{}, len(columnNames))
+		var fieldName string
+		var fieldValue interface{}
 
 		// Do the parsing with (hopefully) automatic type conversion
 		if err := rows.Scan(columnDataPtr...); err != nil {
@@ -404,6 +509,8 @@ func (q *query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time, l
 		}
 
 		for i, name := range columnNames {
+			role := q.ColumnRoles[name]
+
 			if q.MeasurementColumn != "" && name == q.MeasurementColumn {
 				switch raw := columnData[i].(type) {
 				case string:
@@ -439,7 +546,20 @@ func (q *query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time, l
 				}
 			}
 
-			if q.tagFilter.Match(name) {
+			switch role {
+			case "measurement", "time", "ignore":
+				continue
+			case "field_name":
+				if fieldName, err = internal.ToString(columnData[i]); err != nil {
+					return 0, fmt.Errorf("converting field name column %q failed: %w", name, err)
+				}
+				continue
+			case "field_value":
+				fieldValue = columnData[i]
+				continue
+			}
+
+			if role == "tag" || (role == "" && q.tagFilter.Match(name)) {
 				tagvalue, err := internal.ToString(columnData[i])
 				if err != nil {
 					return 0, fmt.Errorf("converting tag column %q failed: %w", name, err)
@@ -447,85 +567,36 @@ func (q *query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time, l
 				if v := strings.TrimSpace(tagvalue); v != "" {
 					tags[name] = v
 				}
-			}
-
-			// Explicit type conversions take precedence
-			if q.fieldFilterFloat.Match(name) {
-				v, err := internal.ToFloat64(columnData[i])
-				if err != nil {
-					return 0, fmt.Errorf("converting field column %q to float failed: %w", name, err)
+				if role == "tag" {
+					continue
 				}
-				fields[name] = v
-				continue
 			}
 
-			if q.fieldFilterInt.Match(name) {
-				v, err := internal.ToInt64(columnData[i])
-				if err != nil {
-					if err != nil {
-						if !errors.Is(err, internal.ErrOutOfRange) {
-							return 0, fmt.Errorf("converting field column %q to int failed: %w", name, err)
-						}
-						logger.Warnf("field column %q: %v", name, err)
-					}
-				}
-				fields[name] = v
-				continue
+			v, ok, err := q.convertField(name, columnData[i], role == "field", logger)
+			if err != nil {
+				return 0, err
 			}
-
-			if q.fieldFilterUint.Match(name) {
-				v, err := internal.ToUint64(columnData[i])
-				if err != nil {
-					if !errors.Is(err, internal.ErrOutOfRange) {
-						return 0, fmt.Errorf("converting field column %q to uint failed: %w", name, err)
-					}
-					logger.Warnf("field column %q: %v", name, err)
-				}
+			if ok {
 				fields[name] = v
-				continue
 			}
+		}
 
-			if q.fieldFilterBool.Match(name) {
-				v, err := internal.ToBool(columnData[i])
-				if err != nil {
-					return 0, fmt.Errorf("converting field column %q to bool failed: %w", name, err)
-				}
-				fields[name] = v
-				continue
+		if grouper != nil {
+			for k, v := range fields {
+				grouper.Add(measurement, tags, timestamp, k, v)
 			}
-
-			if q.fieldFilterString.Match(name) {
-				v, err := internal.ToString(columnData[i])
+			if fieldName != "" {
+				v, ok, err := q.convertField(fieldName, fieldValue, true, logger)
 				if err != nil {
-					return 0, fmt.Errorf("converting field column %q to string failed: %w", name, err)
-				}
-				fields[name] = v
-				continue
-			}
-
-			// Try automatic conversion for all remaining fields
-			if q.fieldFilter.Match(name) {
-				var fieldvalue interface{}
-				switch v := columnData[i].(type) {
-				case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
-					fieldvalue = v
-				case []byte:
-					fieldvalue = string(v)
-				case time.Time:
-					fieldvalue = v.UnixNano()
-				case nil:
-					fieldvalue = nil
-				case fmt.Stringer:
-					fieldvalue = v.String()
-				default:
-					return 0, fmt.Errorf("field column %q of type \"%T\" unsupported", name, columnData[i])
+					return 0, err
 				}
-				if fieldvalue != nil {
-					fields[name] = fieldvalue
+				if ok {
+					grouper.Add(measurement, tags, timestamp, fieldName, v)
 				}
 			}
+		} else {
+			acc.AddFields(measurement, fields, tags, timestamp)
 		}
-		acc.AddFields(measurement, fields, tags, timestamp)
 		rowCount++
 	}
 
@@ -533,9 +604,132 @@ func (q *query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time, l
 		return rowCount, err
 	}
 
+	if grouper != nil {
+		for _, m := range grouper.Metrics() {
+			acc.AddMetric(m)
+		}
+	}
+
 	return rowCount, nil
 }
 
+// convertField converts the value of the named field column. The function
+// returns false if the column should not be added as field. Setting force
+// accepts the column independent of the field filter.
+func (q *query) convertField(name string, value interface{}, force bool, logger telegraf.Logger) (interface{}, bool, error) {
+	// Explicit type conversions take precedence
+	if q.fieldFilterFloat.Match(name) {
+		v, err := internal.ToFloat64(value)
+		if err != nil {
+			return nil, false, fmt.Errorf("converting field column %q to float failed: %w", name, err)
+		}
+		return v, true, nil
+	}
+
+	if q.fieldFilterInt.Match(name) {
+		v, err := internal.ToInt64(value)
+		if err != nil {
+			if !errors.Is(err, internal.ErrOutOfRange) {
+				return nil, false, fmt.Errorf("converting field column %q to int failed: %w", name, err)
+			}
+			logger.Warnf("field column %q: %v", name, err)
+		}
+		return v, true, nil
+	}
+
+	if q.fieldFilterUint.Match(name) {
+		v, err := internal.ToUint64(value)
+		if err != nil {
+			if !errors.Is(err, internal.ErrOutOfRange) {
+				return nil, false, fmt.Errorf("converting field column %q to uint failed: %w", name, err)
+			}
+			logger.Warnf("field column %q: %v", name, err)
+		}
+		return v, true, nil
+	}
+
+	if q.fieldFilterBool.Match(name) {
+		v, err := internal.ToBool(value)
+		if err != nil {
+			return nil, false, fmt.Errorf("converting field column %q to bool failed: %w", name, err)
+		}
+		return v, true, nil
+	}
+
+	if q.fieldFilterString.Match(name) {
+		v, err := internal.ToString(value)
+		if err != nil {
+			return nil, false, fmt.Errorf("converting field column %q to string failed: %w", name, err)
+		}
+		return v, true, nil
+	}
+
+	// Try automatic conversion for all remaining fields
+	if !force && !q.fieldFilter.Match(name) {
+		return nil, false, nil
+	}
+
+	var fieldvalue interface{}
+	switch v := value.(type) {
+	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		fieldvalue = v
+	case []byte:
+		fieldvalue = string(v)
+	case time.Time:
+		fieldvalue = v.UnixNano()
+	case nil:
+		fieldvalue = nil
+	case fmt.Stringer:
+		fieldvalue = v.String()
+	default:
+		return nil, false, fmt.Errorf("field column %q of type \"%T\" unsupported", name, value)
+	}
+	return fieldvalue, fieldvalue != nil, nil
+}
+
+// hostFacts contains the information about the host available in the
+// templates of the query parameters
+type hostFacts struct {
+	Hostname string
+	OS       string
+	Arch     string
+	Env      map[string]string
+}
+
+func newHostFacts() (*hostFacts, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("getting hostname failed: %w", err)
+	}
+
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, found := strings.Cut(kv, "="); found {
+			env[k] = v
+		}
+	}
+
+	return &hostFacts{
+		Hostname: hostname,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Env:      env,
+	}, nil
+}
+
+func (f *hostFacts) render(text string) (string, error) {
+	tmpl, err := template.New("parameter").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, f); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 func init() {
 	inputs.Add("sql", func() telegraf.Input {
 		return &SQL{
@@ -0,0 +1,218 @@
+//go:build !mips && !mipsle && !mips64 && !ppc64 && !riscv64 && !loong64 && !mips64le && !(windows && (386 || arm))
+
+package sql
+
+import (
+	dbsql "database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func setupSqlite(t *testing.T) config.Secret {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	db, err := dbsql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	stmts := []string{
+		"CREATE TABLE status (host TEXT, name TEXT, value INTEGER, ts INTEGER)",
+		"INSERT INTO status VALUES ('a', 'connections', 10, 1700000000), ('a', 'queries', 42, 1700000000), ('b', 'connections', 3, 1700000000)",
+		"CREATE TABLE wide (kind TEXT, host TEXT, load REAL, note TEXT)",
+		"INSERT INTO wide VALUES ('server', 'a', 1.5, 'foo')",
+	}
+	for _, stmt := range stmts {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+
+	return config.NewSecret([]byte(dsn))
+}
+
+func TestSqliteColumnRoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    query
+		expected []telegraf.Metric
+	}{
+		{
+			name: "wide result",
+			query: query{
+				Query: "SELECT kind, host, load, note FROM wide",
+				ColumnRoles: map[string]string{
+					"kind": "measurement",
+					"host": "tag",
+					"load": "field",
+					"note": "ignore",
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"server",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"load": 1.5},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "field name and value",
+			query: query{
+				Query:           "SELECT host, name, value, ts FROM status",
+				Measurement:     "db",
+				FieldColumnsInt: []string{"queries"},
+				ColumnRoles: map[string]string{
+					"host":  "tag",
+					"name":  "field_name",
+					"value": "field_value",
+					"ts":    "time",
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"db",
+					map[string]string{"host": "a"},
+					map[string]interface{}{
+						"connections": int64(10),
+						"queries":     int64(42),
+					},
+					time.Unix(1700000000, 0),
+				),
+				metric.New(
+					"db",
+					map[string]string{"host": "b"},
+					map[string]interface{}{"connections": int64(3)},
+					time.Unix(1700000000, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &SQL{
+				Driver:  "sqlite",
+				Dsn:     setupSqlite(t),
+				Queries: []query{tt.query},
+				Log:     testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.Errors)
+
+			options := []cmp.Option{testutil.SortMetrics()}
+			if tt.query.TimeColumn == "" {
+				options = append(options, testutil.IgnoreTime())
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), options...)
+		})
+	}
+}
+
+func TestSqliteParametersAndInterval(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	t.Setenv("TELEGRAF_TEST_HOST", "b")
+
+	plugin := &SQL{
+		Driver: "sqlite",
+		Dsn:    setupSqlite(t),
+		Queries: []query{
+			{
+				Query:               "SELECT ? AS agent, value FROM status WHERE host = ? AND name = 'connections'",
+				Parameters:          []string{"{{ .Hostname }}", `{{ .Env.TELEGRAF_TEST_HOST }}`},
+				TagColumnsInclude:   []string{"agent"},
+				FieldColumnsInclude: []string{"value"},
+				Interval:            config.Duration(time.Hour),
+				Timeout:             config.Duration(time.Second),
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// The second gather must not execute the query again due to the interval
+	require.NoError(t, plugin.Gather(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"sql",
+			map[string]string{"agent": hostname},
+			map[string]interface{}{"value": int64(3)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestQueryDue(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	q := &query{Interval: config.Duration(time.Minute)}
+	require.True(t, q.due(start))
+
+	q.lastRun = start
+	require.False(t, q.due(start.Add(30*time.Second)))
+	// Jitter of the gather cycle is tolerated
+	require.True(t, q.due(start.Add(58*time.Second)))
+	require.True(t, q.due(start.Add(time.Minute)))
+}
+
+func TestInitInvalidColumnRoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    query
+		expected string
+	}{
+		{
+			name:     "invalid role",
+			query:    query{Query: "SELECT 1", ColumnRoles: map[string]string{"a": "label"}},
+			expected: `invalid role "label" for column "a"`,
+		},
+		{
+			name:     "conflicting time column",
+			query:    query{Query: "SELECT 1", TimeColumn: "b", ColumnRoles: map[string]string{"a": "time"}},
+			expected: `role "time" assigned to column "a" conflicts with column "b"`,
+		},
+		{
+			name:     "field name without value",
+			query:    query{Query: "SELECT 1", ColumnRoles: map[string]string{"a": "field_name"}},
+			expected: "roles 'field_name' and 'field_value' must be used together",
+		},
+		{
+			name:     "invalid parameter template",
+			query:    query{Query: "SELECT 1", Parameters: []string{"{{ .Hostname"}},
+			expected: "rendering parameter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &SQL{
+				Driver:  "sqlite",
+				Dsn:     config.NewSecret([]byte("test.db")),
+				Queries: []query{tt.query},
+				Log:     testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package squid
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+var numberRe = regexp.MustCompile(`-?\d+(?:\.\d+)?`)
+
+// infoField describes how to convert a line of the "info" page to fields
+type infoField struct {
+	name string
+	// kind is one of "int", "float" or "5min_60min" for values reported for
+	// both intervals
+	kind string
+}
+
+// Lines of the "info" page, keyed by their label
+var infoFields = map[string]infoField{
+	"Number of clients accessing cache":            {"clients", "int"},
+	"Number of HTTP requests received":             {"http_requests", "int"},
+	"Request failure ratio":                        {"request_failure_ratio", "float"},
+	"Average HTTP requests per minute since start": {"http_requests_per_minute", "float"},
+	"Hits as % of all requests":                    {"request_hit_percent", "5min_60min"},
+	"Hits as % of bytes sent":                      {"byte_hit_percent", "5min_60min"},
+	"Memory hits as % of hit requests":             {"memory_hit_percent", "5min_60min"},
+	"Disk hits as % of hit requests":               {"disk_hit_percent", "5min_60min"},
+	"Storage Swap size":                            {"storage_swap_size_kb", "int"},
+	"Storage Swap capacity":                        {"storage_swap_used_percent", "float"},
+	"Storage Mem size":                             {"storage_mem_size_kb", "int"},
+	"Storage Mem capacity":                         {"storage_mem_used_percent", "float"},
+	"Mean Object Size":                             {"mean_object_size_kb", "float"},
+	"UP Time":                                      {"uptime_seconds", "float"},
+	"CPU Time":                                     {"cpu_time_seconds", "float"},
+	"CPU Usage":                                    {"cpu_usage_percent", "float"},
+	"Maximum Resident Size":                        {"max_resident_size_kb", "int"},
+	"Maximum number of file descriptors":           {"fd_max", "int"},
+	"Largest file desc currently in use":           {"fd_largest_in_use", "int"},
+	"Number of file desc currently in use":         {"fd_in_use", "int"},
+	"Files queued for open":                        {"fd_queued", "int"},
+	"Available number of file descriptors":         {"fd_available", "int"},
+	"Reserved number of file descriptors":          {"fd_reserved", "int"},
+	"Store Disk files open":                        {"store_disk_files_open", "int"},
+}
+
+// Counts of the "Internal Data Structures" section, keyed by their label
+var infoCounts = map[string]string{
+	"StoreEntries":                 "store_entries",
+	"StoreEntries with MemObjects": "store_entries_with_mem_objects",
+	"Hot Object Cache Items":       "hot_object_cache_items",
+	"on-disk objects":              "on_disk_objects",
+}
+
+// parseCounters parses the "counters" page consisting of lines in the format
+// "<name> = <value>"
+func parseCounters(acc telegraf.Accumulator, tags map[string]string, body []byte) error {
+	fields := make(map[string]interface{})
+	var ts time.Time
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), " = ")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		// The sample time is followed by the formatted date
+		if key == "sample_time" {
+			raw, _, _ := strings.Cut(value, " ")
+			if t, err := internal.ParseTimestamp("unix", raw, nil); err == nil {
+				ts = t
+			}
+			continue
+		}
+
+		name := strings.ReplaceAll(key, ".", "_")
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			fields[name] = v
+		} else if v, err := strconv.ParseFloat(value, 64); err == nil {
+			fields[name] = v
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if ts.IsZero() {
+		acc.AddCounter("squid_counters", fields, tags)
+	} else {
+		acc.AddCounter("squid_counters", fields, tags, ts)
+	}
+	return nil
+}
+
+// parseInfo parses the hit ratios, file descriptor, storage and resource usage
+// of the human-readable "info" page
+func parseInfo(acc telegraf.Accumulator, tags map[string]string, body []byte) error {
+	fields := make(map[string]interface{})
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Lines of the "Internal Data Structures" section start with the count
+		if count, label, found := strings.Cut(line, " "); found {
+			if name, ok := infoCounts[strings.TrimSpace(label)]; ok {
+				if v, err := strconv.ParseInt(count, 10, 64); err == nil {
+					fields[name] = v
+				}
+				continue
+			}
+		}
+
+		label, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		field, ok := infoFields[strings.TrimSpace(label)]
+		if !ok {
+			continue
+		}
+		numbers := numberRe.FindAllString(value, -1)
+		if len(numbers) == 0 {
+			continue
+		}
+
+		switch field.kind {
+		case "int":
+			v, err := strconv.ParseInt(numbers[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parsing %q failed: %w", label, err)
+			}
+			fields[field.name] = v
+		case "float":
+			v, err := strconv.ParseFloat(numbers[0], 64)
+			if err != nil {
+				return fmt.Errorf("parsing %q failed: %w", label, err)
+			}
+			fields[field.name] = v
+		case "5min_60min":
+			// The numbers contain the interval lengths followed by the values,
+			// e.g. "5min: 34.2%, 60min: 33.9%"
+			if len(numbers) != 4 {
+				return fmt.Errorf("unexpected value %q of %q", value, label)
+			}
+			for i, suffix := range []string{"_5min", "_60min"} {
+				v, err := strconv.ParseFloat(numbers[2*i+1], 64)
+				if err != nil {
+					return fmt.Errorf("parsing %q failed: %w", label, err)
+				}
+				fields[field.name+suffix] = v
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(fields) > 0 {
+		acc.AddGauge("squid_info", fields, tags)
+	}
+	return nil
+}
+
+// delayBucket holds the state of a bucket class of a delay pool
+type delayBucket struct {
+	pool    string
+	class   string
+	name    string
+	fields  map[string]interface{}
+	current string
+}
+
+// parseDelayPools parses the "delay" page reporting the buckets of each pool
+func parseDelayPools(acc telegraf.Accumulator, tags map[string]string, body []byte) error {
+	var pool, class string
+	var bucket *delayBucket
+
+	flush := func() error {
+		if bucket == nil {
+			return nil
+		}
+		defer func() { bucket = nil }()
+
+		// Aggregate buckets report a single value while the other buckets
+		// report the values per host, network, user or tag as "<id>:<value>"
+		if bucket.current != "" {
+			if bucket.name == "aggregate" {
+				v, err := strconv.ParseInt(bucket.current, 10, 64)
+				if err != nil {
+					return fmt.Errorf("parsing current value of pool %s failed: %w", bucket.pool, err)
+				}
+				bucket.fields["current"] = v
+			} else {
+				var count int64
+				var minimum int64 = -1
+				for _, entry := range strings.Fields(bucket.current) {
+					_, raw, found := strings.Cut(entry, ":")
+					if !found {
+						// e.g. "Not used yet."
+						continue
+					}
+					v, err := strconv.ParseInt(raw, 10, 64)
+					if err != nil {
+						return fmt.Errorf("parsing current value of pool %s failed: %w", bucket.pool, err)
+					}
+					if minimum < 0 || v < minimum {
+						minimum = v
+					}
+					count++
+				}
+				bucket.fields["buckets"] = count
+				if count > 0 {
+					bucket.fields["current_min"] = minimum
+				}
+			}
+		}
+		if len(bucket.fields) == 0 {
+			return nil
+		}
+
+		bucketTags := make(map[string]string, len(tags)+3)
+		for k, v := range tags {
+			bucketTags[k] = v
+		}
+		bucketTags["pool"] = bucket.pool
+		bucketTags["class"] = bucket.class
+		bucketTags["bucket"] = bucket.name
+		acc.AddGauge("squid_delay_pool", bucket.fields, bucketTags)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		label, value, found := strings.Cut(line, ":")
+		if !found {
+			// e.g. "Misconfigured pool."
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch label {
+		case "Delay pools configured", "Memory Used":
+			if err := flush(); err != nil {
+				return err
+			}
+		case "Pool":
+			if err := flush(); err != nil {
+				return err
+			}
+			pool, class = value, ""
+		case "Class":
+			class = value
+		case "Max", "Restore":
+			if bucket == nil {
+				continue
+			}
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("parsing %q of pool %s failed: %w", label, pool, err)
+			}
+			bucket.fields[strings.ToLower(label)] = v
+		case "Current":
+			if bucket != nil {
+				bucket.current = value
+			}
+		default:
+			// Bucket sections like "Aggregate:" or "Individual:"
+			if value != "" || pool == "" {
+				continue
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+			bucket = &delayBucket{
+				pool:   pool,
+				class:  class,
+				name:   strings.ToLower(label),
+				fields: make(map[string]interface{}, 4),
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
@@ -0,0 +1,141 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package squid
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Squid struct {
+	URL      string          `toml:"url"`
+	Collect  []string        `toml:"collect"`
+	Username config.Secret   `toml:"username"`
+	Password config.Secret   `toml:"password"`
+	Log      telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client  *http.Client
+	baseURL *url.URL
+}
+
+// Parsers of the cache manager pages
+var pages = map[string]func(acc telegraf.Accumulator, tags map[string]string, body []byte) error{
+	"counters": parseCounters,
+	"info":     parseInfo,
+	"delay":    parseDelayPools,
+}
+
+func (*Squid) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Squid) Init() error {
+	if s.URL == "" {
+		s.URL = "http://localhost:3128"
+	}
+	if len(s.Collect) == 0 {
+		s.Collect = []string{"counters", "info"}
+	}
+	for _, page := range s.Collect {
+		if _, found := pages[page]; !found {
+			return fmt.Errorf("invalid collect option %q", page)
+		}
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("parsing URL failed: %w", err)
+	}
+	s.baseURL = u
+
+	client, err := s.HTTPClientConfig.CreateClient(context.Background(), s.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	s.client = client
+
+	return nil
+}
+
+func (s *Squid) Gather(acc telegraf.Accumulator) error {
+	tags := map[string]string{"url": s.URL}
+	for _, page := range s.Collect {
+		body, err := s.fetch(page)
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying page %q failed: %w", page, err))
+			continue
+		}
+		if err := pages[page](acc, tags, body); err != nil {
+			acc.AddError(fmt.Errorf("parsing page %q failed: %w", page, err))
+		}
+	}
+
+	return nil
+}
+
+func (s *Squid) Stop() {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+}
+
+func (s *Squid) fetch(page string) ([]byte, error) {
+	addr := s.baseURL.JoinPath("squid-internal-mgr", page)
+	req, err := http.NewRequest("GET", addr.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", internal.ProductToken())
+
+	if !s.Username.Empty() || !s.Password.Empty() {
+		username, err := s.Username.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting username failed: %w", err)
+		}
+		defer username.Destroy()
+
+		password, err := s.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password failed: %w", err)
+		}
+		defer password.Destroy()
+
+		req.SetBasicAuth(username.String(), password.String())
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %q", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func init() {
+	inputs.Add("squid", func() telegraf.Input {
+		return &Squid{
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
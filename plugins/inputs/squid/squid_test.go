@@ -0,0 +1,210 @@
+package squid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInit(t *testing.T) {
+	plugin := &Squid{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "http://localhost:3128", plugin.URL)
+	require.Equal(t, []string{"counters", "info"}, plugin.Collect)
+
+	plugin = &Squid{
+		Collect: []string{"info", "storedir"},
+		Log:     testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid collect option "storedir"`)
+}
+
+func TestGather(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "manager" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var fn string
+		switch r.URL.Path {
+		case "/squid-internal-mgr/counters":
+			fn = "testdata/counters.txt"
+		case "/squid-internal-mgr/info":
+			fn = "testdata/info.txt"
+		case "/squid-internal-mgr/delay":
+			fn = "testdata/delay.txt"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(fn)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Squid{
+		URL:      server.URL,
+		Collect:  []string{"counters", "info", "delay"},
+		Username: config.NewSecret([]byte("manager")),
+		Password: config.NewSecret([]byte("secret")),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	tags := map[string]string{"url": server.URL}
+	poolTags := func(pool, class, bucket string) map[string]string {
+		return map[string]string{"url": server.URL, "pool": pool, "class": class, "bucket": bucket}
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"squid_counters",
+			tags,
+			map[string]interface{}{
+				"client_http_requests":       int64(4821),
+				"client_http_hits":           int64(1637),
+				"client_http_errors":         int64(12),
+				"client_http_kbytes_in":      int64(2210),
+				"client_http_kbytes_out":     int64(98321),
+				"client_http_hit_kbytes_out": int64(40211),
+				"server_all_requests":        int64(3190),
+				"server_all_errors":          int64(3),
+				"server_all_kbytes_in":       int64(60120),
+				"server_all_kbytes_out":      int64(1980),
+				"icp_pkts_sent":              int64(0),
+				"unlink_requests":            int64(0),
+				"page_faults":                int64(2),
+				"select_loops":               int64(102931),
+				"cpu_time":                   float64(12.345678),
+				"wall_time":                  float64(0.512),
+				"swap_outs":                  int64(1024),
+				"swap_ins":                   int64(311),
+				"swap_files_cleaned":         int64(0),
+				"aborted_requests":           int64(7),
+			},
+			time.Unix(1736496000, 123456000),
+			telegraf.Counter,
+		),
+		metric.New(
+			"squid_info",
+			tags,
+			map[string]interface{}{
+				"clients":                        int64(23),
+				"http_requests":                  int64(4821),
+				"request_failure_ratio":          float64(0),
+				"http_requests_per_minute":       float64(3.3),
+				"request_hit_percent_5min":       float64(34.2),
+				"request_hit_percent_60min":      float64(33.9),
+				"byte_hit_percent_5min":          float64(41.0),
+				"byte_hit_percent_60min":         float64(40.8),
+				"memory_hit_percent_5min":        float64(62.5),
+				"memory_hit_percent_60min":       float64(60.1),
+				"disk_hit_percent_5min":          float64(30.0),
+				"disk_hit_percent_60min":         float64(31.7),
+				"storage_swap_size_kb":           int64(1048576),
+				"storage_swap_used_percent":      float64(50),
+				"storage_mem_size_kb":            int64(262144),
+				"storage_mem_used_percent":       float64(100),
+				"mean_object_size_kb":            float64(27.36),
+				"uptime_seconds":                 float64(86400.123),
+				"cpu_time_seconds":               float64(12.346),
+				"cpu_usage_percent":              float64(0.01),
+				"max_resident_size_kb":           int64(412320),
+				"fd_max":                         int64(16384),
+				"fd_largest_in_use":              int64(87),
+				"fd_in_use":                      int64(61),
+				"fd_queued":                      int64(0),
+				"fd_available":                   int64(16323),
+				"fd_reserved":                    int64(100),
+				"store_disk_files_open":          int64(4),
+				"store_entries":                  int64(38321),
+				"store_entries_with_mem_objects": int64(9312),
+				"hot_object_cache_items":         int64(9288),
+				"on_disk_objects":                int64(38270),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"squid_delay_pool",
+			poolTags("1", "1", "aggregate"),
+			map[string]interface{}{"max": int64(64000), "restore": int64(8000), "current": int64(21000)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"squid_delay_pool",
+			poolTags("2", "2", "aggregate"),
+			map[string]interface{}{"max": int64(-1), "restore": int64(-1)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"squid_delay_pool",
+			poolTags("2", "2", "individual"),
+			map[string]interface{}{"max": int64(16000), "restore": int64(4000), "buckets": int64(3), "current_min": int64(0)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"squid_delay_pool",
+			poolTags("3", "2", "aggregate"),
+			map[string]interface{}{"max": int64(32000), "restore": int64(4000), "current": int64(32000)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"squid_delay_pool",
+			poolTags("3", "2", "individual"),
+			map[string]interface{}{"max": int64(8000), "restore": int64(2000), "buckets": int64(0)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	// Only the counters carry the sample time of the server
+	actual := acc.GetTelegrafMetrics()
+	require.Len(t, actual, len(expected))
+	testutil.RequireMetricsEqual(t, expected[:1], actual[:1])
+	testutil.RequireMetricsEqual(t, expected[1:], actual[1:], testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	plugin := &Squid{
+		URL: server.URL,
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 2)
+	require.ErrorContains(t, acc.Errors[0], `querying page "counters" failed: received status "401 Unauthorized"`)
+}
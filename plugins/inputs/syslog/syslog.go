@@ -3,11 +3,15 @@
 package syslog
 
 import (
+	"bufio"
+	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -29,6 +33,10 @@ var sampleConfig string
 
 const readTimeoutMsg = "Read timeout set! Connections, inactive for the set duration, will be closed!"
 
+// Maximum length of octet-counted or non-transparent frames when parsing
+// streams with RFC3164 or automatically detected messages
+const maxFrameLength = 64 * 1024
+
 type Syslog struct {
 	Address        string                     `toml:"server"`
 	Framing        string                     `toml:"framing"`
@@ -36,6 +44,7 @@ type Syslog struct {
 	Trailer        nontransparent.TrailerType `toml:"trailer"`
 	BestEffort     bool                       `toml:"best_effort"`
 	Separator      string                     `toml:"sdparam_separator"`
+	Listeners      int                        `toml:"listeners"`
 	Log            telegraf.Logger            `toml:"-"`
 	socket.Config
 
@@ -44,6 +53,7 @@ type Syslog struct {
 
 	url    *url.URL
 	socket *socket.Socket
+	shards []*socket.Socket
 }
 
 func (*Syslog) SampleConfig() string {
@@ -55,7 +65,7 @@ func (s *Syslog) Init() error {
 	switch s.Framing {
 	case "":
 		s.Framing = "octet-counting"
-	case "octet-counting", "non-transparent":
+	case "octet-counting", "non-transparent", "auto":
 	default:
 		return fmt.Errorf("invalid 'framing' %q", s.Framing)
 	}
@@ -63,7 +73,7 @@ func (s *Syslog) Init() error {
 	switch s.SyslogStandard {
 	case "":
 		s.SyslogStandard = "RFC5424"
-	case "RFC3164", "RFC5424":
+	case "RFC3164", "RFC5424", "auto":
 	default:
 		return fmt.Errorf("invalid 'syslog_standard' %q", s.SyslogStandard)
 	}
@@ -109,6 +119,16 @@ func (s *Syslog) Init() error {
 	}
 	s.socket = sock
 
+	// Check if we can share the address across multiple listeners
+	if s.Listeners < 0 {
+		return fmt.Errorf("invalid number of listeners %d", s.Listeners)
+	}
+	if s.Listeners > 1 {
+		if err := s.socket.EnableReusePort(); err != nil {
+			return fmt.Errorf("using multiple listeners failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -138,6 +158,26 @@ func (s *Syslog) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("unknown protocol %q in %q", s.url.Scheme, s.Address)
 	}
 
+	// Start the additional listeners sharing the address of the first one. Use
+	// the actual address to get the same port if the OS chose a random one.
+	for i := 1; i < s.Listeners; i++ {
+		sock, err := s.Config.NewSocket(s.url.Scheme+"://"+addr.String(), nil, s.Log)
+		if err != nil {
+			return err
+		}
+		if err := sock.EnableReusePort(); err != nil {
+			return err
+		}
+		if err := sock.Setup(); err != nil {
+			return fmt.Errorf("setting up listener %d failed: %w", i+1, err)
+		}
+		sock.Listen(s.createDatagramDataHandler(acc), onError)
+		s.shards = append(s.shards, sock)
+	}
+	if len(s.shards) > 0 {
+		s.Log.Infof("Sharing address across %d listeners", len(s.shards)+1)
+	}
+
 	return nil
 }
 
@@ -150,6 +190,10 @@ func (s *Syslog) Stop() {
 	defer s.mu.Unlock()
 
 	s.socket.Close()
+	for _, sock := range s.shards {
+		sock.Close()
+	}
+	s.shards = nil
 	s.wg.Wait()
 }
 
@@ -159,20 +203,8 @@ func (s *Syslog) createStreamDataHandler(acc telegraf.Accumulator) socket.Callba
 	if s.BestEffort {
 		opts = append(opts, syslog.WithBestEffort())
 	}
-	if s.Framing == "non-transparent" {
-		opts = append(opts, nontransparent.WithTrailer(s.Trailer))
-	}
 
 	return func(src net.Addr, reader io.ReadCloser) {
-		// Create the parser depending on transport framing and other settings
-		var parser syslog.Parser
-		switch s.Framing {
-		case "octet-counting":
-			parser = octetcounting.NewParser(opts...)
-		case "non-transparent":
-			parser = nontransparent.NewParser(opts...)
-		}
-
 		// Remove port from address
 		var addr string
 		if src.Network() != "unix" {
@@ -182,6 +214,63 @@ func (s *Syslog) createStreamDataHandler(acc telegraf.Accumulator) socket.Callba
 			}
 		}
 
+		// Detect the framing from the first byte of the stream as octet-counted
+		// frames start with the message length while non-transparent framed
+		// messages start with the priority
+		buf := bufio.NewReader(reader)
+		framing := s.Framing
+		if framing == "auto" {
+			first, err := buf.Peek(1)
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					acc.AddError(err)
+				}
+				return
+			}
+			framing = "non-transparent"
+			if first[0] >= '1' && first[0] <= '9' {
+				framing = "octet-counting"
+			}
+		}
+
+		// The parsers of the syslog library use the RFC3164 parser without
+		// the year of the timestamp so handle the framing ourselves if
+		// messages other than RFC5424 are expected
+		if s.SyslogStandard != "RFC5424" {
+			parser := s.newMessageParser()
+			onFrame := func(frame []byte) {
+				message, err := parser.parse(frame)
+				if err != nil {
+					acc.AddError(err)
+				}
+				if message == nil {
+					return
+				}
+				acc.AddFields("syslog", fields(message, s.Separator), tags(message, addr))
+			}
+
+			var err error
+			switch framing {
+			case "octet-counting":
+				err = readOctetCountingFrames(buf, onFrame)
+			case "non-transparent":
+				err = s.readNonTransparentFrames(buf, onFrame)
+			}
+			if err != nil {
+				acc.AddError(err)
+			}
+			return
+		}
+
+		// Create the parser depending on transport framing and other settings
+		var parser syslog.Parser
+		switch framing {
+		case "octet-counting":
+			parser = octetcounting.NewParser(opts...)
+		case "non-transparent":
+			parser = nontransparent.NewParser(append(opts, nontransparent.WithTrailer(s.Trailer))...)
+		}
+
 		parser.WithListener(func(r *syslog.Result) {
 			if r.Error != nil {
 				acc.AddError(r.Error)
@@ -193,26 +282,17 @@ func (s *Syslog) createStreamDataHandler(acc telegraf.Accumulator) socket.Callba
 			// Extract message information
 			acc.AddFields("syslog", fields(r.Message, s.Separator), tags(r.Message, addr))
 		})
-		parser.Parse(reader)
+		parser.Parse(buf)
 	}
 }
 
 func (s *Syslog) createDatagramDataHandler(acc telegraf.Accumulator) socket.CallbackData {
 	// Create the parser depending on syslog standard and other settings
-	var parser syslog.Machine
-	switch s.SyslogStandard {
-	case "RFC3164":
-		parser = rfc3164.NewParser(rfc3164.WithYear(rfc3164.CurrentYear{}))
-	case "RFC5424":
-		parser = rfc5424.NewParser()
-	}
-	if s.BestEffort {
-		parser.WithBestEffort()
-	}
+	parser := s.newMessageParser()
 
 	// Return the OnData function
 	return func(src net.Addr, data []byte, _ time.Time) {
-		message, err := parser.Parse(data)
+		message, err := parser.parse(data)
 		if err != nil {
 			acc.AddError(err)
 		} else if message == nil {
@@ -234,6 +314,127 @@ func (s *Syslog) createDatagramDataHandler(acc telegraf.Accumulator) socket.Call
 	}
 }
 
+// messageParser parses single messages of the configured syslog standard or
+// detects the standard of each message in "auto" mode
+type messageParser struct {
+	standard string
+	rfc3164  syslog.Machine
+	rfc5424  syslog.Machine
+}
+
+func (s *Syslog) newMessageParser() *messageParser {
+	p := &messageParser{standard: s.SyslogStandard}
+	if s.SyslogStandard != "RFC5424" {
+		p.rfc3164 = rfc3164.NewParser(rfc3164.WithYear(rfc3164.CurrentYear{}))
+		if s.BestEffort {
+			p.rfc3164.WithBestEffort()
+		}
+	}
+	if s.SyslogStandard != "RFC3164" {
+		p.rfc5424 = rfc5424.NewParser()
+		if s.BestEffort {
+			p.rfc5424.WithBestEffort()
+		}
+	}
+	return p
+}
+
+func (p *messageParser) parse(data []byte) (syslog.Message, error) {
+	switch p.standard {
+	case "RFC3164":
+		return p.rfc3164.Parse(data)
+	case "RFC5424":
+		return p.rfc5424.Parse(data)
+	}
+	if isRFC5424(data) {
+		return p.rfc5424.Parse(data)
+	}
+	return p.rfc3164.Parse(data)
+}
+
+// isRFC5424 checks if the message starts with the priority followed by a
+// version as required by RFC5424, e.g. "<13>1 ". RFC3164 messages continue
+// with the timestamp or hostname instead.
+func isRFC5424(data []byte) bool {
+	if len(data) < 5 || data[0] != '<' {
+		return false
+	}
+
+	// Skip the priority value of at most three digits
+	i := 1
+	for i < len(data) && i <= 3 && data[i] >= '0' && data[i] <= '9' {
+		i++
+	}
+	if i == 1 || i >= len(data) || data[i] != '>' {
+		return false
+	}
+	i++
+
+	// The version is a non-zero number of at most three digits
+	start := i
+	if i >= len(data) || data[i] < '1' || data[i] > '9' {
+		return false
+	}
+	for i < len(data) && i-start < 3 && data[i] >= '0' && data[i] <= '9' {
+		i++
+	}
+	return i < len(data) && data[i] == ' '
+}
+
+// readOctetCountingFrames splits the stream into frames prefixed by the
+// message length as described in RFC6587#section-3.4.1
+func readOctetCountingFrames(r *bufio.Reader, onFrame func([]byte)) error {
+	for {
+		prefix, err := r.ReadString(' ')
+		if err != nil {
+			if errors.Is(err, io.EOF) && strings.TrimSpace(prefix) == "" {
+				return nil
+			}
+			return fmt.Errorf("reading frame length failed: %w", err)
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(prefix))
+		if err != nil || length <= 0 || length > maxFrameLength {
+			return fmt.Errorf("invalid frame length %q", strings.TrimSpace(prefix))
+		}
+
+		frame := make([]byte, length)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return fmt.Errorf("reading frame failed: %w", err)
+		}
+		onFrame(frame)
+	}
+}
+
+// readNonTransparentFrames splits the stream into frames terminated by the
+// configured trailer as described in RFC6587#section-3.4.2
+func (s *Syslog) readNonTransparentFrames(r *bufio.Reader, onFrame func([]byte)) error {
+	trailer, err := s.Trailer.Value()
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxFrameLength)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.IndexByte(data, byte(trailer)); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	for scanner.Scan() {
+		if frame := bytes.TrimRight(scanner.Bytes(), "\r"); len(frame) > 0 {
+			onFrame(frame)
+		}
+	}
+	return scanner.Err()
+}
+
 func tags(msg syslog.Message, src string) map[string]string {
 	// Extract message information
 	tags := map[string]string{
@@ -355,3 +355,145 @@ func TestIssue10121(t *testing.T) {
 		return err != nil
 	}, 3*time.Second, 250*time.Millisecond)
 }
+
+func TestIsRFC5424(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "<1>1 - - - - - - A", expected: true},
+		{input: "<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47", expected: true},
+		{input: "<4>11 - - - - - -", expected: true},
+		{input: "<13>Dec  2 16:31:03 host app: Test", expected: false},
+		{input: "<13>2024-12-02T16:31:03Z host app: Test", expected: false},
+		{input: "<34>0 - - - - - -", expected: false},
+		{input: "<1234>1 - - - - - -", expected: false},
+		{input: "Dec  2 16:31:03 host app: Test", expected: false},
+		{input: "<1>1", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			require.Equal(t, tt.expected, isRFC5424([]byte(tt.input)))
+		})
+	}
+}
+
+func TestAutoDetectMixedStandards(t *testing.T) {
+	plugin := &Syslog{
+		Address:        "tcp://127.0.0.1:0",
+		Framing:        "auto",
+		SyslogStandard: "auto",
+		Trailer:        nontransparent.LF,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	client, err := net.Dial("tcp", plugin.socket.Address().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Send RFC3164 and RFC5424 messages on the same connection
+	msgs := "<13>Dec  2 16:31:03 host app: Test\n<1>1 - - - - - - A\n"
+	_, err = client.Write([]byte(msgs))
+	require.NoError(t, err)
+	client.Close()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"syslog",
+			map[string]string{
+				"severity": "notice",
+				"facility": "user",
+				"hostname": "host",
+				"appname":  "app",
+				"source":   "127.0.0.1",
+			},
+			map[string]interface{}{
+				"facility_code": 1,
+				"severity_code": 5,
+				"message":       "Test",
+				"timestamp":     time.Date(time.Now().Year(), time.December, 2, 16, 31, 3, 0, time.UTC).UnixNano(),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"syslog",
+			map[string]string{
+				"severity": "alert",
+				"facility": "kern",
+				"source":   "127.0.0.1",
+			},
+			map[string]interface{}{
+				"facility_code": 0,
+				"severity_code": 1,
+				"message":       "A",
+				"version":       uint16(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	require.Eventually(t, func() bool {
+		return int(acc.NMetrics()) >= len(expected)
+	}, 3*time.Second, 100*time.Millisecond)
+	plugin.Stop()
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	require.Empty(t, acc.Errors)
+}
+
+func TestListenersInvalid(t *testing.T) {
+	plugin := &Syslog{
+		Address:   "tcp://127.0.0.1:0",
+		Listeners: 4,
+		Log:       testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "using multiple listeners failed")
+}
+
+func TestListeners(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Skipping test as reusing ports is only supported on Linux")
+	}
+
+	plugin := &Syslog{
+		Address:        "udp://127.0.0.1:0",
+		SyslogStandard: "RFC5424",
+		Listeners:      4,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.Len(t, plugin.shards, 3)
+
+	// All listeners must share the same address
+	addr := plugin.socket.Address().String()
+	for _, sock := range plugin.shards {
+		require.Equal(t, addr, sock.Address().String())
+	}
+
+	// Use multiple clients as the kernel distributes the packets by source
+	const clients, messages = 8, 25
+	for i := 0; i < clients; i++ {
+		client, err := net.Dial("udp", addr)
+		require.NoError(t, err)
+		for j := 0; j < messages; j++ {
+			_, err := client.Write([]byte("<1>1 - - - - - - A"))
+			require.NoError(t, err)
+		}
+		client.Close()
+	}
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= clients*messages
+	}, 3*time.Second, 100*time.Millisecond)
+	plugin.Stop()
+	require.Empty(t, acc.Errors)
+}
@@ -0,0 +1,230 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package trafficserver
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Prefix of the process statistics, trimmed from the field names
+const processPrefix = "proxy.process."
+
+// Statistics counting the cache hits and misses of HTTP transactions. Hits in
+// the RAM cache are a subset of the fresh hits and are therefore not included.
+var (
+	cacheHitStats = []string{
+		"proxy.process.http.cache_hit_fresh",
+		"proxy.process.http.cache_hit_revalidated",
+		"proxy.process.http.cache_hit_ims",
+		"proxy.process.http.cache_hit_stale_served",
+	}
+	cacheMissStats = []string{
+		"proxy.process.http.cache_miss_cold",
+		"proxy.process.http.cache_miss_changed",
+		"proxy.process.http.cache_miss_client_no_cache",
+		"proxy.process.http.cache_miss_client_not_cacheable",
+		"proxy.process.http.cache_miss_ims",
+	}
+)
+
+type TrafficServer struct {
+	URLs         []string        `toml:"urls"`
+	StatsInclude []string        `toml:"stats_include"`
+	StatsExclude []string        `toml:"stats_exclude"`
+	Username     config.Secret   `toml:"username"`
+	Password     config.Secret   `toml:"password"`
+	Log          telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client      *http.Client
+	statsFilter filter.Filter
+}
+
+func (*TrafficServer) SampleConfig() string {
+	return sampleConfig
+}
+
+func (ts *TrafficServer) Init() error {
+	if len(ts.URLs) == 0 {
+		ts.URLs = []string{"http://localhost:8080/_stats"}
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(ts.StatsInclude, ts.StatsExclude)
+	if err != nil {
+		return fmt.Errorf("creating statistics filter failed: %w", err)
+	}
+	ts.statsFilter = f
+
+	client, err := ts.HTTPClientConfig.CreateClient(context.Background(), ts.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	ts.client = client
+
+	return nil
+}
+
+func (ts *TrafficServer) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range ts.URLs {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			if err := ts.gatherURL(acc, url); err != nil {
+				acc.AddError(fmt.Errorf("gathering %q failed: %w", url, err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (ts *TrafficServer) Stop() {
+	if ts.client != nil {
+		ts.client.CloseIdleConnections()
+	}
+}
+
+func (ts *TrafficServer) gatherURL(acc telegraf.Accumulator, url string) error {
+	stats, err := ts.fetch(url)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	fields := make(map[string]interface{}, len(stats))
+	values := make(map[string]float64, len(stats))
+	for name, raw := range stats {
+		// The version of the server is reported along with the statistics
+		if name == "server" {
+			if version, ok := raw.(string); ok {
+				fields["version"] = version
+			}
+			continue
+		}
+
+		value, f, ok := parseValue(raw)
+		if !ok {
+			continue
+		}
+		values[name] = f
+		if ts.statsFilter.Match(name) {
+			fields[strings.TrimPrefix(name, processPrefix)] = value
+		}
+	}
+
+	var hits, misses float64
+	for _, name := range cacheHitStats {
+		hits += values[name]
+	}
+	for _, name := range cacheMissStats {
+		misses += values[name]
+	}
+	if hits+misses > 0 {
+		fields["cache_hit_ratio"] = hits / (hits + misses)
+	}
+
+	if len(fields) > 0 {
+		acc.AddFields("trafficserver", fields, map[string]string{"url": url}, now)
+	}
+	return nil
+}
+
+func (ts *TrafficServer) fetch(url string) (map[string]interface{}, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("Accept", "application/json")
+
+	if !ts.Username.Empty() || !ts.Password.Empty() {
+		username, err := ts.Username.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting username failed: %w", err)
+		}
+		defer username.Destroy()
+
+		password, err := ts.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password failed: %w", err)
+		}
+		defer password.Destroy()
+
+		req.SetBasicAuth(username.String(), password.String())
+	}
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %q", resp.Status)
+	}
+
+	var response struct {
+		Global map[string]interface{} `json:"global"`
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&response); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	return response.Global, nil
+}
+
+// parseValue converts the statistic values, reported as strings by older
+// versions of stats_over_http, to numbers. The value is additionally returned
+// as float for calculating ratios.
+func parseValue(raw interface{}) (interface{}, float64, bool) {
+	var s string
+	switch v := raw.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return nil, 0, false
+	}
+
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v, float64(v), true
+	}
+	if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return v, float64(v), true
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, v, true
+	}
+	return nil, 0, false
+}
+
+func init() {
+	inputs.Add("trafficserver", func() telegraf.Input {
+		return &TrafficServer{
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
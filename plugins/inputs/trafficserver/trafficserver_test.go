@@ -0,0 +1,124 @@
+package trafficserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInit(t *testing.T) {
+	plugin := &TrafficServer{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{"http://localhost:8080/_stats"}, plugin.URLs)
+}
+
+func TestGather(t *testing.T) {
+	buf, err := os.ReadFile("testdata/stats.json")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_stats" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	url := server.URL + "/_stats"
+
+	plugin := &TrafficServer{
+		URLs:         []string{url},
+		StatsInclude: []string{"proxy.process.http.c*", "proxy.process.cache.*"},
+		StatsExclude: []string{"proxy.process.http.cache_*"},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	// The hit ratio is calculated from the cache statistics even if they are
+	// excluded from the output
+	expected := []telegraf.Metric{
+		metric.New(
+			"trafficserver",
+			map[string]string{"url": url},
+			map[string]interface{}{
+				"http.completed_requests":         int64(18234),
+				"http.current_client_connections": int64(42),
+				"http.current_server_connections": int64(17),
+				"cache.bytes_used":                int64(8589934592),
+				"cache.bytes_total":               int64(34359738368),
+				"cache.percent_full":              int64(25),
+				"cache.ram_cache.hits":            int64(6011),
+				"cache.ram_cache.misses":          int64(3719),
+				"cache_hit_ratio":                 float64(9720) / float64(18000),
+				"version":                         "9.2.4",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Newer versions report numbers instead of strings
+		if _, err := w.Write([]byte(`{"global": {"proxy.process.http.incoming_requests": 12, "proxy.node.hostname": "edge01"}}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &TrafficServer{
+		URLs: []string{server.URL},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"trafficserver",
+			map[string]string{"url": server.URL},
+			map[string]interface{}{"http.incoming_requests": int64(12)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	plugin := &TrafficServer{
+		URLs: []string{server.URL},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `received status "403 Forbidden"`)
+}
@@ -30,6 +30,7 @@ func (t *Twemproxy) Gather(acc telegraf.Accumulator) error {
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
 	body, err := io.ReadAll(conn)
 	if err != nil {
 		return err
@@ -66,7 +67,17 @@ func (t *Twemproxy) processStat(acc telegraf.Accumulator, tags map[string]string
 	}
 	acc.AddFields("twemproxy", fields, tags)
 
-	for _, pool := range t.Pools {
+	// Report all pools if none are configured
+	pools := t.Pools
+	if len(pools) == 0 {
+		for key, value := range data {
+			if _, ok := value.(map[string]interface{}); ok {
+				pools = append(pools, key)
+			}
+		}
+	}
+
+	for _, pool := range pools {
 		if poolStat, ok := data[pool]; ok {
 			if data, ok := poolStat.(map[string]interface{}); ok {
 				poolTags := copyTags(tags)
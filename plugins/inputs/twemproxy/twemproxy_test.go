@@ -174,3 +174,34 @@ func TestGather(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "twemproxy_pool_server",
 		poolServerFields2, poolServerTags2)
 }
+
+func TestGatherAllPools(t *testing.T) {
+	mockServer, err := mockTwemproxyServer()
+	require.NoError(t, err)
+	defer mockServer.Close()
+
+	twemproxy := &Twemproxy{
+		Addr: sampleAddr,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, twemproxy.Gather(&acc))
+
+	require.True(t, acc.HasMeasurement("twemproxy_pool"))
+	acc.AssertContainsTaggedFields(t, "twemproxy_pool",
+		map[string]interface{}{
+			"client_connections": float64(1305),
+			"client_eof":         float64(126813),
+			"client_err":         float64(147942),
+			"forward_error":      float64(11684),
+			"fragments":          float64(0),
+			"server_ejects":      float64(0),
+		},
+		map[string]string{
+			"pool":      "demo",
+			"source":    "server1.website.com",
+			"twemproxy": sampleAddr,
+		},
+	)
+	require.Len(t, acc.GetTelegrafMetrics(), 4)
+}
@@ -0,0 +1,109 @@
+//go:build !windows
+
+package varnish
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Parse the output of "varnishadm backend.list -j" and report the health of
+// the backends of the active vcl
+func processBackendHealth(activeVcl string, acc telegraf.Accumulator, out io.Reader) error {
+	b, err := io.ReadAll(out)
+	if err != nil {
+		return err
+	}
+	// workaround for non valid json in varnish 6.6.1 https://github.com/varnishcache/varnish-cache/issues/3687
+	output := strings.TrimPrefix(string(b), "200")
+
+	var jsonOut []json.RawMessage
+	if err := json.Unmarshal([]byte(output), &jsonOut); err != nil {
+		return err
+	}
+	// The response consists of the API version, the command, the timestamp
+	// and the backends
+	if len(jsonOut) < 4 {
+		return fmt.Errorf("unexpected backend.list response with %d elements", len(jsonOut))
+	}
+
+	var backends map[string]map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(string(jsonOut[3])))
+	dec.UseNumber()
+	if err := dec.Decode(&backends); err != nil {
+		return fmt.Errorf("parsing backends failed: %w", err)
+	}
+
+	for name, backend := range backends {
+		// Backends are named "<vcl>.<backend>"
+		vcl, backendName, found := strings.Cut(name, ".")
+		if !found {
+			backendName, vcl = name, ""
+		}
+		if vcl != "" && activeVcl != "" && vcl != activeVcl {
+			// skip not active vcl
+			continue
+		}
+
+		tags := map[string]string{
+			"backend": backendName,
+		}
+		if vcl != "" {
+			tags["vcl"] = vcl
+		}
+		if t, ok := backend["type"].(string); ok && t != "" {
+			tags["type"] = t
+		}
+
+		fields := make(map[string]interface{}, 5)
+		admin, _ := backend["admin_health"].(string)
+		if admin != "" {
+			fields["admin_health"] = admin
+		}
+
+		// Backends without a probe are considered healthy
+		probeHealthy := true
+		switch probe := backend["probe_message"].(type) {
+		case []interface{}:
+			// [<good probes>, <probe window>, "healthy" | "sick"]
+			if len(probe) == 3 {
+				if good, ok := probe[0].(json.Number); ok {
+					if v, err := good.Int64(); err == nil {
+						fields["probe_good"] = v
+					}
+				}
+				if window, ok := probe[1].(json.Number); ok {
+					if v, err := window.Int64(); err == nil {
+						fields["probe_window"] = v
+					}
+				}
+				status, _ := probe[2].(string)
+				probeHealthy = status != "sick"
+			}
+		case string:
+			probeHealthy = !strings.Contains(strings.ToLower(probe), "sick")
+		}
+
+		switch strings.ToLower(admin) {
+		case "healthy":
+			fields["healthy"] = true
+		case "sick":
+			fields["healthy"] = false
+		default:
+			fields["healthy"] = probeHealthy
+		}
+
+		if lastChange, ok := backend["last_change"].(json.Number); ok {
+			if v, err := lastChange.Float64(); err == nil {
+				fields["last_change"] = v
+			}
+		}
+
+		acc.AddFields("varnish_backend", fields, tags)
+	}
+	return nil
+}
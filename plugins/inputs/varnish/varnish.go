@@ -8,6 +8,7 @@ import (
 	"bytes"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
@@ -67,10 +68,13 @@ type Varnish struct {
 	AdmBinaryArgs []string
 	UseSudo       bool
 	InstanceName  string
+	InstanceNames []string
 	Timeout       config.Duration
 	Regexps       []string
 	MetricVersion int
 
+	GatherBackendHealth bool
+
 	filter          filter.Filter
 	run             runner
 	admRun          runner
@@ -103,6 +107,10 @@ func (*Varnish) SampleConfig() string {
 }
 
 func (s *Varnish) Init() error {
+	if s.InstanceName != "" && len(s.InstanceNames) > 0 {
+		return errors.New("instance_name and instance_names cannot be used together")
+	}
+
 	customRegexps := make([]*regexp.Regexp, 0, len(s.Regexps))
 	for _, re := range s.Regexps {
 		compiled, err := regexp.Compile(re)
@@ -138,16 +146,31 @@ func (s *Varnish) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
-	admArgs, statsArgs := s.prepareCmdArgs()
+	// Keep the metrics of a single instance untagged for compatibility
+	if len(s.InstanceNames) == 0 {
+		return s.gatherInstance(acc, s.InstanceName)
+	}
+	for _, instance := range s.InstanceNames {
+		iacc := &instanceAccumulator{Accumulator: acc, instance: instance}
+		if err := s.gatherInstance(iacc, instance); err != nil {
+			acc.AddError(fmt.Errorf("instance %q: %w", instance, err))
+		}
+	}
+	return nil
+}
+
+func (s *Varnish) gatherInstance(acc telegraf.Accumulator, instance string) error {
+	admArgs, statsArgs := s.prepareCmdArgs(instance)
 
 	statOut, err := s.run(s.Binary, s.UseSudo, statsArgs, s.Timeout)
 	if err != nil {
 		return fmt.Errorf("error gathering metrics: %w", err)
 	}
 
+	// run varnishadm to get active vcl
+	var activeVcl string
 	if s.MetricVersion == 2 {
-		// run varnishadm to get active vcl
-		var activeVcl = "boot"
+		activeVcl = "boot"
 		if s.admRun != nil {
 			admOut, err := s.admRun(s.AdmBinary, s.UseSudo, admArgs, s.Timeout)
 			if err != nil {
@@ -158,40 +181,113 @@ func (s *Varnish) Gather(acc telegraf.Accumulator) error {
 				return fmt.Errorf("error gathering metrics: %w", err)
 			}
 		}
-		return s.processMetricsV2(activeVcl, acc, statOut)
+		if err := s.processMetricsV2(activeVcl, acc, statOut); err != nil {
+			return err
+		}
+	} else if err := s.processMetricsV1(acc, statOut); err != nil {
+		return err
+	}
+
+	if s.GatherBackendHealth && s.admRun != nil {
+		args := []string{"backend.list", "-j"}
+		if instance != "" {
+			args = append([]string{"-n", instance}, args...)
+		}
+		admOut, err := s.admRun(s.AdmBinary, s.UseSudo, args, s.Timeout)
+		if err != nil {
+			return fmt.Errorf("error gathering backend health: %w", err)
+		}
+		if err := processBackendHealth(activeVcl, acc, admOut); err != nil {
+			return fmt.Errorf("error gathering backend health: %w", err)
+		}
 	}
-	return s.processMetricsV1(acc, statOut)
+	return nil
 }
 
 // Prepare varnish cli tools arguments
-func (s *Varnish) prepareCmdArgs() ([]string, []string) {
+func (s *Varnish) prepareCmdArgs(instance string) ([]string, []string) {
 	// default varnishadm arguments
 	admArgs := []string{"vcl.list", "-j"}
 
 	// default varnish stats arguments
 	statsArgs := []string{"-j"}
-	if s.MetricVersion == 1 {
-		statsArgs = []string{"-1"}
-	}
 
 	// add optional instance name
-	if s.InstanceName != "" {
-		statsArgs = append(statsArgs, []string{"-n", s.InstanceName}...)
-		admArgs = append([]string{"-n", s.InstanceName}, admArgs...)
+	if instance != "" {
+		statsArgs = append(statsArgs, []string{"-n", instance}...)
+		admArgs = append([]string{"-n", instance}, admArgs...)
 	}
 
-	// override custom arguments
+	// override custom arguments, the instance is still selected when
+	// querying multiple instances
 	if len(s.AdmBinaryArgs) > 0 {
 		admArgs = s.AdmBinaryArgs
+		if len(s.InstanceNames) > 0 {
+			admArgs = append([]string{"-n", instance}, admArgs...)
+		}
 	}
-	// override custom arguments
 	if len(s.BinaryArgs) > 0 {
 		statsArgs = s.BinaryArgs
+		if len(s.InstanceNames) > 0 {
+			statsArgs = append(append([]string{}, statsArgs...), "-n", instance)
+		}
 	}
 	return admArgs, statsArgs
 }
 
+// metrics version 1 - the stats are grouped by section
 func (s *Varnish) processMetricsV1(acc telegraf.Accumulator, out *bytes.Buffer) error {
+	// Custom arguments might still request the legacy column output of
+	// "varnishstat -1"
+	if !bytes.HasPrefix(bytes.TrimSpace(out.Bytes()), []byte("{")) {
+		return s.processMetricsV1Columns(acc, out)
+	}
+
+	rootJSON := make(map[string]interface{})
+	dec := json.NewDecoder(out)
+	dec.UseNumber()
+	if err := dec.Decode(&rootJSON); err != nil {
+		return err
+	}
+
+	sectionMap := make(map[string]map[string]interface{})
+	for stat, raw := range getCountersJSON(rootJSON) {
+		section, field, found := strings.Cut(stat, ".")
+		if !found {
+			continue
+		}
+		if s.filter != nil && !s.filter.Match(stat) {
+			continue
+		}
+		data, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		number, ok := data["value"].(json.Number)
+		if !ok {
+			acc.AddError(fmt.Errorf("expected a numeric value for %s = %v", stat, data["value"]))
+			continue
+		}
+		value, err := strconv.ParseUint(number.String(), 10, 64)
+		if err != nil {
+			acc.AddError(fmt.Errorf("expected a numeric value for %s = %v", stat, number))
+			continue
+		}
+
+		if _, ok := sectionMap[section]; !ok {
+			sectionMap[section] = make(map[string]interface{})
+		}
+		sectionMap[section][field] = value
+	}
+
+	for section, fields := range sectionMap {
+		acc.AddFields("varnish", fields, map[string]string{"section": section})
+	}
+	return nil
+}
+
+// Parses the column output of "varnishstat -1"
+func (s *Varnish) processMetricsV1Columns(acc telegraf.Accumulator, out *bytes.Buffer) error {
 	sectionMap := make(map[string]map[string]interface{})
 	scanner := bufio.NewScanner(out)
 	for scanner.Scan() {
@@ -388,6 +484,33 @@ func (s *Varnish) parseMetricV2(name string) (metric varnishMetric) {
 	return metric
 }
 
+// instanceAccumulator adds the name of the queried instance to all metrics
+type instanceAccumulator struct {
+	telegraf.Accumulator
+	instance string
+}
+
+func (a *instanceAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddFields(measurement, fields, a.addTag(tags), t...)
+}
+
+func (a *instanceAccumulator) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddGauge(measurement, fields, a.addTag(tags), t...)
+}
+
+func (a *instanceAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddCounter(measurement, fields, a.addTag(tags), t...)
+}
+
+func (a *instanceAccumulator) addTag(tags map[string]string) map[string]string {
+	result := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		result[k] = v
+	}
+	result["instance"] = a.instance
+	return result
+}
+
 type varnishMetric struct {
 	measurement string
 	fieldName   string
@@ -4,14 +4,18 @@ package varnish
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -632,3 +636,112 @@ func TestVarnishAdmJson(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "reload_20210723_091821_2056185", activeVcl)
 }
+
+func TestGatherV1JSON(t *testing.T) {
+	output, err := os.ReadFile("test_data/varnish6.6.json")
+	require.NoError(t, err)
+
+	acc := &testutil.Accumulator{}
+	v := &Varnish{
+		run:   fakeVarnishRunner(string(output)),
+		Stats: []string{"MAIN.cache_hit", "MGT.uptime"},
+	}
+	require.NoError(t, v.Gather(acc))
+	require.Len(t, acc.Metrics, 2)
+	acc.AssertContainsTaggedFields(t, "varnish", map[string]interface{}{"uptime": uint64(238359)}, map[string]string{"section": "MGT"})
+
+	v = &Varnish{
+		run:   fakeVarnishRunner(string(output)),
+		Stats: []string{"*"},
+	}
+	acc.ClearMetrics()
+	require.NoError(t, v.Gather(acc))
+	require.Len(t, flatten(acc.Metrics), 358)
+}
+
+func TestBackendHealth(t *testing.T) {
+	output, err := os.ReadFile("test_data/varnishadm-backend.json")
+	require.NoError(t, err)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, processBackendHealth("boot", acc, bytes.NewBuffer(output)))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"varnish_backend",
+			map[string]string{"backend": "default", "vcl": "boot", "type": "backend"},
+			map[string]interface{}{
+				"admin_health": "probe",
+				"healthy":      true,
+				"probe_good":   int64(5),
+				"probe_window": int64(5),
+				"last_change":  1736400000.25,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"varnish_backend",
+			map[string]string{"backend": "api", "vcl": "boot", "type": "backend"},
+			map[string]interface{}{
+				"admin_health": "probe",
+				"healthy":      false,
+				"probe_good":   int64(1),
+				"probe_window": int64(8),
+				"last_change":  1736495000.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"varnish_backend",
+			map[string]string{"backend": "static", "vcl": "boot", "type": "backend"},
+			map[string]interface{}{
+				"admin_health": "sick",
+				"healthy":      false,
+				"last_change":  1736300000.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherMultipleInstances(t *testing.T) {
+	backends, err := os.ReadFile("test_data/varnishadm-backend.json")
+	require.NoError(t, err)
+
+	var statsCalls [][]string
+	v := &Varnish{
+		run: func(_ string, _ bool, args []string, _ config.Duration) (*bytes.Buffer, error) {
+			statsCalls = append(statsCalls, args)
+			if args[len(args)-1] == "broken" {
+				return nil, errors.New("no such instance")
+			}
+			return bytes.NewBufferString(`{"version": 1, "counters": {"MAIN.uptime": {"flag": "c", "value": 42}}}`), nil
+		},
+		admRun: func(string, bool, []string, config.Duration) (*bytes.Buffer, error) {
+			return bytes.NewBuffer(backends), nil
+		},
+		InstanceNames:       []string{"front", "broken"},
+		Stats:               []string{"MAIN.*"},
+		GatherBackendHealth: true,
+	}
+	require.NoError(t, v.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, v.Gather(acc))
+	require.Equal(t, [][]string{{"-j", "-n", "front"}, {"-j", "-n", "broken"}}, statsCalls)
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `instance "broken"`)
+
+	acc.AssertContainsTaggedFields(t, "varnish", map[string]interface{}{"uptime": uint64(42)}, map[string]string{"section": "MAIN", "instance": "front"})
+	// Without the active vcl the backends of all vcls are reported
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, "front", m.Tags()["instance"])
+	}
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+}
+
+func TestInitInstanceNames(t *testing.T) {
+	v := &Varnish{InstanceName: "a", InstanceNames: []string{"b"}}
+	require.ErrorContains(t, v.Init(), "cannot be used together")
+}
@@ -0,0 +1,115 @@
+//go:build windows
+
+package win_dns_dhcp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	dhcpsapi              = windows.NewLazySystemDLL("dhcpsapi.dll")
+	procDhcpGetMibInfoV5  = dhcpsapi.NewProc("DhcpGetMibInfoV5")
+	procDhcpRpcFreeMemory = dhcpsapi.NewProc("DhcpRpcFreeMemory")
+)
+
+// dhcpMibInfoV5 mirrors the DHCP_MIB_INFO_V5 structure of the DHCP server API
+type dhcpMibInfoV5 struct {
+	Discovers               uint32
+	Offers                  uint32
+	Requests                uint32
+	Acks                    uint32
+	Naks                    uint32
+	Declines                uint32
+	Releases                uint32
+	ServerStartTime         windows.Filetime
+	QtnNumLeases            uint32
+	QtnPctQtnLeases         uint32
+	QtnProbationLeases      uint32
+	QtnNonQtnLeases         uint32
+	QtnExemptLeases         uint32
+	QtnCapableClients       uint32
+	QtnIASErrors            uint32
+	DelayedOffers           uint32
+	ScopesWithDelayedOffers uint32
+	Scopes                  uint32
+	ScopeInfo               *scopeMibInfoV5
+}
+
+// scopeMibInfoV5 mirrors the SCOPE_MIB_INFO_V5 structure of the DHCP server API
+type scopeMibInfoV5 struct {
+	Subnet            uint32
+	NumAddressesInuse uint32
+	NumAddressesFree  uint32
+	NumPendingOffers  uint32
+}
+
+// dhcpServer queries the statistics using the DHCP server management API
+type dhcpServer struct {
+	server string
+}
+
+func (d *dhcpServer) mibInfo() (*dhcpMibInfo, error) {
+	if err := procDhcpGetMibInfoV5.Find(); err != nil {
+		return nil, fmt.Errorf("DHCP server API not available: %w", err)
+	}
+
+	var server *uint16
+	if d.server != "" {
+		s, err := windows.UTF16PtrFromString(d.server)
+		if err != nil {
+			return nil, err
+		}
+		server = s
+	}
+
+	var raw *dhcpMibInfoV5
+	r, _, _ := procDhcpGetMibInfoV5.Call(uintptr(unsafe.Pointer(server)), uintptr(unsafe.Pointer(&raw)))
+	if r != 0 {
+		return nil, syscall.Errno(r)
+	}
+	if raw == nil {
+		return nil, errors.New("no statistics returned")
+	}
+	defer func() {
+		if raw.ScopeInfo != nil {
+			procDhcpRpcFreeMemory.Call(uintptr(unsafe.Pointer(raw.ScopeInfo))) //nolint:errcheck // no error returned
+		}
+		procDhcpRpcFreeMemory.Call(uintptr(unsafe.Pointer(raw))) //nolint:errcheck // no error returned
+	}()
+
+	info := &dhcpMibInfo{
+		discovers:     raw.Discovers,
+		offers:        raw.Offers,
+		requests:      raw.Requests,
+		acks:          raw.Acks,
+		naks:          raw.Naks,
+		declines:      raw.Declines,
+		releases:      raw.Releases,
+		delayedOffers: raw.DelayedOffers,
+	}
+	if raw.Scopes > 0 && raw.ScopeInfo != nil {
+		scopes := unsafe.Slice(raw.ScopeInfo, raw.Scopes)
+		info.scopes = make([]dhcpScope, 0, len(scopes))
+		for _, s := range scopes {
+			info.scopes = append(info.scopes, dhcpScope{
+				subnet:        subnetString(s.Subnet),
+				inUse:         s.NumAddressesInuse,
+				free:          s.NumAddressesFree,
+				pendingOffers: s.NumPendingOffers,
+			})
+		}
+	}
+
+	return info, nil
+}
+
+// subnetString formats a DHCP_IP_ADDRESS stored in host byte-order
+func subnetString(addr uint32) string {
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr)).String()
+}
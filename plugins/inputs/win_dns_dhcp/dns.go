@@ -0,0 +1,145 @@
+//go:build windows
+
+package win_dns_dhcp
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// S_FALSE is returned by CoInitializeEx if it was already called on this thread.
+const sFalse = 0x00000001
+
+const dnsQuery = "SELECT CollectionName, Name, Value FROM MicrosoftDNS_Statistic"
+
+// wmiDNS queries the statistics of the MicrosoftDNS WMI provider
+type wmiDNS struct {
+	connectionParams []interface{}
+}
+
+func connectionParams(host string, username, password config.Secret) ([]interface{}, error) {
+	params := make([]interface{}, 0, 4)
+	if host != "" {
+		params = append(params, host)
+	} else {
+		params = append(params, nil)
+	}
+	params = append(params, `root\MicrosoftDNS`)
+	if !username.Empty() {
+		u, err := username.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting username secret failed: %w", err)
+		}
+		params = append(params, u.String())
+		u.Destroy()
+	}
+	if !password.Empty() {
+		p, err := password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password secret failed: %w", err)
+		}
+		params = append(params, p.String())
+		p.Destroy()
+	}
+	return params, nil
+}
+
+func (d *wmiDNS) statistics() ([]dnsStatistic, error) {
+	// Bind the COM initialization to the current OS thread, see the win_wmi
+	// plugin for details
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
+		var oleCode *ole.OleError
+		if errors.As(err, &oleCode) && oleCode.Code() != ole.S_OK && oleCode.Code() != sFalse {
+			return nil, err
+		}
+	}
+	defer ole.CoUninitialize()
+
+	locator, err := oleutil.CreateObject("WbemScripting.SWbemLocator")
+	if err != nil {
+		return nil, err
+	}
+	if locator == nil {
+		return nil, errors.New("failed to create WbemScripting.SWbemLocator, maybe WMI is broken")
+	}
+	defer locator.Release()
+
+	wmi, err := locator.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interface: %w", err)
+	}
+	defer wmi.Release()
+
+	serviceRaw, err := oleutil.CallMethod(wmi, "ConnectServer", d.connectionParams...)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ConnectServer: %w", err)
+	}
+	service := serviceRaw.ToIDispatch()
+	defer serviceRaw.Clear()
+
+	resultRaw, err := oleutil.CallMethod(service, "ExecQuery", dnsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ExecQuery: %w", err)
+	}
+	result := resultRaw.ToIDispatch()
+	defer resultRaw.Clear()
+
+	var stats []dnsStatistic
+	err = oleutil.ForEach(result, func(itemRaw *ole.VARIANT) error {
+		item := itemRaw.ToIDispatch()
+
+		collectionRaw, err := oleutil.GetProperty(item, "CollectionName")
+		if err != nil {
+			return fmt.Errorf("getting property CollectionName failed: %w", err)
+		}
+		collection := collectionRaw.ToString()
+		collectionRaw.Clear()
+
+		nameRaw, err := oleutil.GetProperty(item, "Name")
+		if err != nil {
+			return fmt.Errorf("getting property Name failed: %w", err)
+		}
+		name := nameRaw.ToString()
+		nameRaw.Clear()
+
+		valueRaw, err := oleutil.GetProperty(item, "Value")
+		if err != nil {
+			return fmt.Errorf("getting property Value failed: %w", err)
+		}
+		value := valueRaw.Value()
+		valueRaw.Clear()
+
+		// Some statistics only provide a string value
+		if v, ok := toCounter(value); ok {
+			stats = append(stats, dnsStatistic{collection: collection, name: name, value: v})
+		}
+		return nil
+	})
+
+	return stats, err
+}
+
+// toCounter converts the WMI value of an unsigned 32-bit counter which is
+// reported as signed integer
+func toCounter(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int64(uint32(v)), true
+	case uint32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,209 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build windows
+
+package win_dns_dhcp
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type WinDNSDHCP struct {
+	Host     string          `toml:"host"`
+	Username config.Secret   `toml:"username"`
+	Password config.Secret   `toml:"password"`
+	Collect  []string        `toml:"collect"`
+	Log      telegraf.Logger `toml:"-"`
+
+	dns  dnsProvider
+	dhcp dhcpProvider
+}
+
+// dnsProvider provides the statistics of a DNS server
+type dnsProvider interface {
+	statistics() ([]dnsStatistic, error)
+}
+
+// dhcpProvider provides the statistics of a DHCP server
+type dhcpProvider interface {
+	mibInfo() (*dhcpMibInfo, error)
+}
+
+type dnsStatistic struct {
+	collection string
+	name       string
+	value      int64
+}
+
+type dhcpMibInfo struct {
+	discovers     uint32
+	offers        uint32
+	requests      uint32
+	acks          uint32
+	naks          uint32
+	declines      uint32
+	releases      uint32
+	delayedOffers uint32
+	scopes        []dhcpScope
+}
+
+type dhcpScope struct {
+	subnet        string
+	inUse         uint32
+	free          uint32
+	pendingOffers uint32
+}
+
+func (*WinDNSDHCP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinDNSDHCP) Init() error {
+	if len(w.Collect) == 0 {
+		w.Collect = []string{"dns", "dhcp"}
+	}
+
+	for _, c := range w.Collect {
+		switch c {
+		case "dns":
+			if w.dns != nil {
+				continue
+			}
+			params, err := connectionParams(w.Host, w.Username, w.Password)
+			if err != nil {
+				return err
+			}
+			w.dns = &wmiDNS{connectionParams: params}
+		case "dhcp":
+			if w.dhcp == nil {
+				w.dhcp = &dhcpServer{server: w.Host}
+			}
+		default:
+			return fmt.Errorf("invalid collect option %q", c)
+		}
+	}
+
+	return nil
+}
+
+func (w *WinDNSDHCP) Gather(acc telegraf.Accumulator) error {
+	if w.dns != nil {
+		if err := w.gatherDNS(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering DNS statistics failed: %w", err))
+		}
+	}
+	if w.dhcp != nil {
+		if err := w.gatherDHCP(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering DHCP statistics failed: %w", err))
+		}
+	}
+
+	return nil
+}
+
+func (w *WinDNSDHCP) gatherDNS(acc telegraf.Accumulator) error {
+	stats, err := w.dns.statistics()
+	if err != nil {
+		return err
+	}
+
+	// Group the statistics by their collection, e.g. "Query" or "Recursion"
+	collections := make(map[string]map[string]interface{})
+	for _, s := range stats {
+		name := fieldName(s.name)
+		if name == "" {
+			continue
+		}
+		fields, found := collections[s.collection]
+		if !found {
+			fields = make(map[string]interface{})
+			collections[s.collection] = fields
+		}
+		fields[name] = s.value
+	}
+
+	for collection, fields := range collections {
+		tags := w.tags()
+		tags["collection"] = collection
+		acc.AddFields("win_dns", fields, tags)
+	}
+
+	return nil
+}
+
+func (w *WinDNSDHCP) gatherDHCP(acc telegraf.Accumulator) error {
+	info, err := w.dhcp.mibInfo()
+	if err != nil {
+		return err
+	}
+
+	acc.AddFields("win_dhcp", map[string]interface{}{
+		"discovers":      info.discovers,
+		"offers":         info.offers,
+		"requests":       info.requests,
+		"acks":           info.acks,
+		"naks":           info.naks,
+		"declines":       info.declines,
+		"releases":       info.releases,
+		"delayed_offers": info.delayedOffers,
+		"scopes":         len(info.scopes),
+	}, w.tags())
+
+	for _, s := range info.scopes {
+		tags := w.tags()
+		tags["scope"] = s.subnet
+		fields := map[string]interface{}{
+			"addresses_in_use": s.inUse,
+			"addresses_free":   s.free,
+			"pending_offers":   s.pendingOffers,
+		}
+		if total := uint64(s.inUse) + uint64(s.free); total > 0 {
+			fields["in_use_percent"] = float64(s.inUse) / float64(total) * 100
+		}
+		acc.AddFields("win_dhcp_scope", fields, tags)
+	}
+
+	return nil
+}
+
+func (w *WinDNSDHCP) tags() map[string]string {
+	// Add a source tag if we use remote queries
+	if w.Host != "" {
+		return map[string]string{"source": w.Host}
+	}
+	return make(map[string]string)
+}
+
+// fieldName converts statistic names like "Total Query Received" to
+// "total_query_received"
+func fieldName(name string) string {
+	var b strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(name) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			separate = true
+			continue
+		}
+		if separate && b.Len() > 0 {
+			b.WriteByte('_')
+		}
+		separate = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func init() {
+	inputs.Add("win_dns_dhcp", func() telegraf.Input {
+		return &WinDNSDHCP{}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !windows
+
+package win_dns_dhcp
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type WinDNSDHCP struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*WinDNSDHCP) SampleConfig() string { return sampleConfig }
+
+func (w *WinDNSDHCP) Init() error {
+	w.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*WinDNSDHCP) Gather(telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("win_dns_dhcp", func() telegraf.Input {
+		return &WinDNSDHCP{}
+	})
+}
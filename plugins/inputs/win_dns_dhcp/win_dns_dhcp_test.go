@@ -0,0 +1,172 @@
+//go:build windows
+
+package win_dns_dhcp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type mockDNS struct {
+	stats []dnsStatistic
+	err   error
+}
+
+func (m *mockDNS) statistics() ([]dnsStatistic, error) {
+	return m.stats, m.err
+}
+
+type mockDHCP struct {
+	info *dhcpMibInfo
+	err  error
+}
+
+func (m *mockDHCP) mibInfo() (*dhcpMibInfo, error) {
+	return m.info, m.err
+}
+
+func TestInitInvalidCollect(t *testing.T) {
+	plugin := &WinDNSDHCP{
+		Collect: []string{"dns", "wins"},
+		Log:     testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid collect option "wins"`)
+}
+
+func TestInitDefaults(t *testing.T) {
+	plugin := &WinDNSDHCP{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{"dns", "dhcp"}, plugin.Collect)
+	require.NotNil(t, plugin.dns)
+	require.NotNil(t, plugin.dhcp)
+}
+
+func TestFieldName(t *testing.T) {
+	tests := map[string]string{
+		"Total Query Received":    "total_query_received",
+		"UDP Queries Received":    "udp_queries_received",
+		"  Recursive Send/Resend": "recursive_send_resend",
+		"TCP Client Connections":  "tcp_client_connections",
+		"---":                     "",
+	}
+	for input, expected := range tests {
+		require.Equal(t, expected, fieldName(input), input)
+	}
+}
+
+func TestGather(t *testing.T) {
+	plugin := &WinDNSDHCP{
+		Host:    "dc01",
+		Collect: []string{"dns", "dhcp"},
+		Log:     testutil.Logger{},
+		dns: &mockDNS{
+			stats: []dnsStatistic{
+				{collection: "Query", name: "Total Query Received", value: 4242},
+				{collection: "Query", name: "UDP Queries Received", value: 4200},
+				{collection: "Recursion", name: "Recursive Queries", value: 17},
+			},
+		},
+		dhcp: &mockDHCP{
+			info: &dhcpMibInfo{
+				discovers: 10,
+				offers:    9,
+				requests:  8,
+				acks:      7,
+				naks:      1,
+				releases:  2,
+				scopes: []dhcpScope{
+					{subnet: "192.168.1.0", inUse: 25, free: 75, pendingOffers: 1},
+					{subnet: "10.0.0.0"},
+				},
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"win_dns",
+			map[string]string{"source": "dc01", "collection": "Query"},
+			map[string]interface{}{
+				"total_query_received": int64(4242),
+				"udp_queries_received": int64(4200),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"win_dns",
+			map[string]string{"source": "dc01", "collection": "Recursion"},
+			map[string]interface{}{"recursive_queries": int64(17)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"win_dhcp",
+			map[string]string{"source": "dc01"},
+			map[string]interface{}{
+				"discovers":      uint32(10),
+				"offers":         uint32(9),
+				"requests":       uint32(8),
+				"acks":           uint32(7),
+				"naks":           uint32(1),
+				"declines":       uint32(0),
+				"releases":       uint32(2),
+				"delayed_offers": uint32(0),
+				"scopes":         2,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"win_dhcp_scope",
+			map[string]string{"source": "dc01", "scope": "192.168.1.0"},
+			map[string]interface{}{
+				"addresses_in_use": uint32(25),
+				"addresses_free":   uint32(75),
+				"pending_offers":   uint32(1),
+				"in_use_percent":   float64(25),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"win_dhcp_scope",
+			map[string]string{"source": "dc01", "scope": "10.0.0.0"},
+			map[string]interface{}{
+				"addresses_in_use": uint32(0),
+				"addresses_free":   uint32(0),
+				"pending_offers":   uint32(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherErrors(t *testing.T) {
+	plugin := &WinDNSDHCP{
+		Log:  testutil.Logger{},
+		dns:  &mockDNS{err: errors.New("invalid namespace")},
+		dhcp: &mockDHCP{err: errors.New("access denied")},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 2)
+	require.ErrorContains(t, acc.Errors[0], "gathering DNS statistics failed: invalid namespace")
+	require.ErrorContains(t, acc.Errors[1], "gathering DHCP statistics failed: access denied")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestSubnetString(t *testing.T) {
+	require.Equal(t, "192.168.1.0", subnetString(0xc0a80100))
+}
@@ -0,0 +1,326 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build windows
+
+package win_hyperv
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	namespaceVirtualization = `root\virtualization\v2`
+	namespaceCIM            = `root\cimv2`
+)
+
+// Names of the EnabledState values of virtual machines
+var vmStates = map[int64]string{
+	2:     "running",
+	3:     "off",
+	6:     "saved",
+	9:     "paused",
+	32768: "paused",
+	32769: "saved",
+	32770: "starting",
+	32771: "snapshotting",
+	32773: "saving",
+	32774: "stopping",
+	32776: "pausing",
+	32777: "resuming",
+}
+
+// Names of the HealthState values of virtual machines
+var vmHealth = map[int64]string{
+	5:  "ok",
+	20: "major_failure",
+	25: "critical_failure",
+}
+
+type HyperV struct {
+	Collect   []string        `toml:"collect"`
+	VMInclude []string        `toml:"vm_include"`
+	VMExclude []string        `toml:"vm_exclude"`
+	Log       telegraf.Logger `toml:"-"`
+
+	querier    querier
+	collectors []*collector
+	vmFilter   filter.Filter
+}
+
+// querier executes WMI queries and returns the requested properties of the
+// resulting objects
+type querier interface {
+	query(namespace, wql string, properties []string) ([]map[string]interface{}, error)
+}
+
+// collector describes the WMI class and properties of a measurement
+type collector struct {
+	measurement string
+	namespace   string
+	class       string
+	filter      string
+	// properties used as tags and fields with their names
+	tags   map[string]string
+	fields map[string]string
+	// instance converts the "Name" property of performance counter instances
+	// to tags, returning false for instances to skip
+	instance func(name string) (map[string]string, bool)
+}
+
+var collectors = map[string]*collector{
+	"vm": {
+		measurement: "win_hyperv_vm",
+		namespace:   namespaceVirtualization,
+		class:       "Msvm_ComputerSystem",
+		filter:      `Caption = "Virtual Machine"`,
+		tags: map[string]string{
+			"ElementName": "vm",
+			"Name":        "vm_id",
+		},
+		fields: map[string]string{
+			"EnabledState":         "state_code",
+			"HealthState":          "health_code",
+			"OnTimeInMilliseconds": "uptime_ms",
+			"NumberOfNumaNodes":    "numa_nodes",
+		},
+	},
+	"vcpu": {
+		measurement: "win_hyperv_vcpu",
+		namespace:   namespaceCIM,
+		class:       "Win32_PerfFormattedData_HvStats_HyperVHypervisorVirtualProcessor",
+		fields: map[string]string{
+			"PercentGuestRunTime":      "guest_run_time_percent",
+			"PercentHypervisorRunTime": "hypervisor_run_time_percent",
+			"PercentRemoteRunTime":     "remote_run_time_percent",
+			"PercentTotalRunTime":      "total_run_time_percent",
+			"CPUWaitTimePerDispatch":   "cpu_wait_time_per_dispatch",
+		},
+		instance: func(name string) (map[string]string, bool) {
+			// Instances are named "<vm>:Hv VP <index>"
+			vm, vcpu, found := strings.Cut(name, ":Hv VP ")
+			if !found {
+				return nil, false
+			}
+			return map[string]string{"vm": vm, "vcpu": vcpu}, true
+		},
+	},
+	"memory": {
+		measurement: "win_hyperv_memory",
+		namespace:   namespaceCIM,
+		class:       "Win32_PerfFormattedData_BalancerStats_HyperVDynamicMemoryVM",
+		fields: map[string]string{
+			"AveragePressure":            "average_pressure",
+			"CurrentPressure":            "current_pressure",
+			"MaximumPressure":            "maximum_pressure",
+			"MinimumPressure":            "minimum_pressure",
+			"PhysicalMemory":             "physical_memory_mb",
+			"GuestVisiblePhysicalMemory": "guest_visible_memory_mb",
+			"GuestAvailableMemory":       "guest_available_memory_mb",
+			"AddedMemory":                "added_memory_mb",
+			"RemovedMemory":              "removed_memory_mb",
+			"MemoryAddOperations":        "add_operations",
+			"MemoryRemoveOperations":     "remove_operations",
+		},
+		instance: func(name string) (map[string]string, bool) {
+			return map[string]string{"vm": name}, true
+		},
+	},
+	"vhd": {
+		measurement: "win_hyperv_vhd",
+		namespace:   namespaceCIM,
+		class:       "Win32_PerfFormattedData_Counters_HyperVVirtualStorageDevice",
+		fields: map[string]string{
+			"ReadBytesPersec":       "read_bytes_persec",
+			"WriteBytesPersec":      "write_bytes_persec",
+			"ReadOperationsPerSec":  "read_operations_persec",
+			"WriteOperationsPerSec": "write_operations_persec",
+			"ErrorCount":            "errors",
+			"FlushCount":            "flushes",
+			"QueueLength":           "queue_length",
+			"Latency":               "latency",
+		},
+		instance: func(name string) (map[string]string, bool) {
+			return map[string]string{"disk": name}, true
+		},
+	},
+	"vswitch": {
+		measurement: "win_hyperv_vswitch",
+		namespace:   namespaceCIM,
+		class:       "Win32_PerfFormattedData_NvspSwitchStats_HyperVVirtualSwitch",
+		fields: map[string]string{
+			"BytesReceivedPersec":          "bytes_received_persec",
+			"BytesSentPersec":              "bytes_sent_persec",
+			"PacketsReceivedPersec":        "packets_received_persec",
+			"PacketsSentPersec":            "packets_sent_persec",
+			"DroppedPacketsIncomingPersec": "dropped_packets_incoming_persec",
+			"DroppedPacketsOutgoingPersec": "dropped_packets_outgoing_persec",
+		},
+		instance: func(name string) (map[string]string, bool) {
+			return map[string]string{"switch": name}, true
+		},
+	},
+}
+
+func (*HyperV) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HyperV) Init() error {
+	if len(h.Collect) == 0 {
+		h.Collect = []string{"vm", "vcpu", "memory", "vhd", "vswitch"}
+	}
+
+	h.collectors = make([]*collector, 0, len(h.Collect))
+	for _, name := range h.Collect {
+		c, found := collectors[name]
+		if !found {
+			return fmt.Errorf("invalid collect option %q", name)
+		}
+		h.collectors = append(h.collectors, c)
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(h.VMInclude, h.VMExclude)
+	if err != nil {
+		return fmt.Errorf("creating VM filter failed: %w", err)
+	}
+	h.vmFilter = f
+
+	if h.querier == nil {
+		h.querier = &wmiQuerier{}
+	}
+
+	return nil
+}
+
+func (h *HyperV) Gather(acc telegraf.Accumulator) error {
+	for _, c := range h.collectors {
+		if err := h.gather(acc, c); err != nil {
+			acc.AddError(fmt.Errorf("querying %s failed: %w", c.class, err))
+		}
+	}
+	return nil
+}
+
+func (h *HyperV) gather(acc telegraf.Accumulator, c *collector) error {
+	properties := make([]string, 0, len(c.tags)+len(c.fields)+1)
+	if c.instance != nil {
+		properties = append(properties, "Name")
+	}
+	for property := range c.tags {
+		properties = append(properties, property)
+	}
+	for property := range c.fields {
+		properties = append(properties, property)
+	}
+
+	wql := "SELECT * FROM " + c.class
+	if c.filter != "" {
+		wql += " WHERE " + c.filter
+	}
+	objects, err := h.querier.query(c.namespace, wql, properties)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		tags := make(map[string]string)
+		if c.instance != nil {
+			name, ok := object["Name"].(string)
+			if !ok || name == "" || name == "_Total" {
+				continue
+			}
+			instanceTags, ok := c.instance(name)
+			if !ok {
+				continue
+			}
+			tags = instanceTags
+		}
+		for property, tag := range c.tags {
+			if v, ok := object[property].(string); ok && v != "" {
+				tags[tag] = v
+			}
+		}
+		if vm, found := tags["vm"]; found && !h.vmFilter.Match(vm) {
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(c.fields))
+		for property, field := range c.fields {
+			if v, ok := toNumber(object[property]); ok {
+				fields[field] = v
+			}
+		}
+
+		// Add the names of the state codes of virtual machines
+		if code, ok := fields["state_code"].(int64); ok {
+			if state, found := vmStates[code]; found {
+				fields["state"] = state
+			} else {
+				fields["state"] = "unknown"
+			}
+		}
+		if code, ok := fields["health_code"].(int64); ok {
+			if health, found := vmHealth[code]; found {
+				fields["health"] = health
+			} else {
+				fields["health"] = "unknown"
+			}
+		}
+
+		if len(fields) > 0 {
+			acc.AddFields(c.measurement, fields, tags)
+		}
+	}
+
+	return nil
+}
+
+// toNumber converts WMI property values to numbers. WMI reports 64-bit
+// integers as strings and unsigned 32-bit integers as signed ones.
+func toNumber(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, true
+		}
+		if u, err := strconv.ParseUint(v, 10, 64); err == nil {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+func init() {
+	inputs.Add("win_hyperv", func() telegraf.Input {
+		return &HyperV{}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !windows
+
+package win_hyperv
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type HyperV struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*HyperV) SampleConfig() string { return sampleConfig }
+
+func (h *HyperV) Init() error {
+	h.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*HyperV) Gather(telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("win_hyperv", func() telegraf.Input {
+		return &HyperV{}
+	})
+}
@@ -0,0 +1,203 @@
+//go:build windows
+
+package win_hyperv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// mockQuerier returns the objects of the class named in the query
+type mockQuerier struct {
+	objects map[string][]map[string]interface{}
+	errors  map[string]error
+}
+
+func (m *mockQuerier) query(_, wql string, _ []string) ([]map[string]interface{}, error) {
+	for class, err := range m.errors {
+		if strings.Contains(wql, " "+class) {
+			return nil, err
+		}
+	}
+	for class, objects := range m.objects {
+		if strings.Contains(wql, " "+class) {
+			return objects, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestInitInvalidCollect(t *testing.T) {
+	plugin := &HyperV{
+		Collect: []string{"vm", "gpu"},
+		Log:     testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid collect option "gpu"`)
+}
+
+func TestInitDefaults(t *testing.T) {
+	plugin := &HyperV{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{"vm", "vcpu", "memory", "vhd", "vswitch"}, plugin.Collect)
+	require.Len(t, plugin.collectors, 5)
+	require.NotNil(t, plugin.querier)
+}
+
+func TestToNumber(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		expected interface{}
+		ok       bool
+	}{
+		{input: int32(42), expected: int64(42), ok: true},
+		{input: uint32(42), expected: int64(42), ok: true},
+		{input: "9000000000", expected: int64(9000000000), ok: true},
+		{input: "18446744073709551615", expected: uint64(18446744073709551615), ok: true},
+		{input: float32(1.5), expected: float64(1.5), ok: true},
+		{input: "n/a"},
+		{input: nil},
+	}
+	for _, tt := range tests {
+		actual, ok := toNumber(tt.input)
+		require.Equal(t, tt.ok, ok, tt.input)
+		require.Equal(t, tt.expected, actual, tt.input)
+	}
+}
+
+func TestGather(t *testing.T) {
+	plugin := &HyperV{
+		VMExclude: []string{"template-*"},
+		Log:       testutil.Logger{},
+		querier: &mockQuerier{
+			objects: map[string][]map[string]interface{}{
+				"Msvm_ComputerSystem": {
+					{
+						"ElementName":          "web01",
+						"Name":                 "5C9A1D2E-7B4F-4E8A-9C3D-1F2E3A4B5C6D",
+						"EnabledState":         int32(2),
+						"HealthState":          int32(5),
+						"OnTimeInMilliseconds": "86400000",
+					},
+					{
+						"ElementName":          "db01",
+						"Name":                 "8E1F2A3B-4C5D-6E7F-8091-A2B3C4D5E6F7",
+						"EnabledState":         int32(3),
+						"HealthState":          int32(25),
+						"OnTimeInMilliseconds": "0",
+					},
+					{
+						"ElementName":  "template-base",
+						"Name":         "0A1B2C3D-4E5F-6071-8293-A4B5C6D7E8F9",
+						"EnabledState": int32(3),
+						"HealthState":  int32(5),
+					},
+				},
+				"Win32_PerfFormattedData_HvStats_HyperVHypervisorVirtualProcessor": {
+					{
+						"Name":                     "web01:Hv VP 0",
+						"PercentGuestRunTime":      "12",
+						"PercentHypervisorRunTime": "1",
+						"PercentTotalRunTime":      "13",
+					},
+					{
+						"Name":                "_Total",
+						"PercentTotalRunTime": "13",
+					},
+				},
+				"Win32_PerfFormattedData_BalancerStats_HyperVDynamicMemoryVM": {
+					{
+						"Name":            "web01",
+						"CurrentPressure": int32(72),
+						"PhysicalMemory":  "4096",
+					},
+					{
+						"Name":            "template-base",
+						"CurrentPressure": int32(0),
+					},
+				},
+				"Win32_PerfFormattedData_Counters_HyperVVirtualStorageDevice": {
+					{
+						"Name":             `D:-Hyper-V-web01.vhdx`,
+						"ReadBytesPersec":  "1048576",
+						"WriteBytesPersec": "524288",
+						"QueueLength":      int32(1),
+					},
+				},
+			},
+			errors: map[string]error{
+				"Win32_PerfFormattedData_NvspSwitchStats_HyperVVirtualSwitch": errors.New("invalid class"),
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "querying Win32_PerfFormattedData_NvspSwitchStats_HyperVVirtualSwitch failed")
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"win_hyperv_vm",
+			map[string]string{"vm": "web01", "vm_id": "5C9A1D2E-7B4F-4E8A-9C3D-1F2E3A4B5C6D"},
+			map[string]interface{}{
+				"state_code":  int64(2),
+				"state":       "running",
+				"health_code": int64(5),
+				"health":      "ok",
+				"uptime_ms":   int64(86400000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"win_hyperv_vm",
+			map[string]string{"vm": "db01", "vm_id": "8E1F2A3B-4C5D-6E7F-8091-A2B3C4D5E6F7"},
+			map[string]interface{}{
+				"state_code":  int64(3),
+				"state":       "off",
+				"health_code": int64(25),
+				"health":      "critical_failure",
+				"uptime_ms":   int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"win_hyperv_vcpu",
+			map[string]string{"vm": "web01", "vcpu": "0"},
+			map[string]interface{}{
+				"guest_run_time_percent":      int64(12),
+				"hypervisor_run_time_percent": int64(1),
+				"total_run_time_percent":      int64(13),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"win_hyperv_memory",
+			map[string]string{"vm": "web01"},
+			map[string]interface{}{
+				"current_pressure":   int64(72),
+				"physical_memory_mb": int64(4096),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"win_hyperv_vhd",
+			map[string]string{"disk": `D:-Hyper-V-web01.vhdx`},
+			map[string]interface{}{
+				"read_bytes_persec":  int64(1048576),
+				"write_bytes_persec": int64(524288),
+				"queue_length":       int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
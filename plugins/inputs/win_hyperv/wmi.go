@@ -0,0 +1,82 @@
+//go:build windows
+
+package win_hyperv
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+)
+
+// S_FALSE is returned by CoInitializeEx if it was already called on this thread.
+const sFalse = 0x00000001
+
+// wmiQuerier queries the local WMI service
+type wmiQuerier struct{}
+
+func (*wmiQuerier) query(namespace, wql string, properties []string) ([]map[string]interface{}, error) {
+	// Bind the COM initialization to the current OS thread, see the win_wmi
+	// plugin for details
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
+		var oleCode *ole.OleError
+		if errors.As(err, &oleCode) && oleCode.Code() != ole.S_OK && oleCode.Code() != sFalse {
+			return nil, err
+		}
+	}
+	defer ole.CoUninitialize()
+
+	locator, err := oleutil.CreateObject("WbemScripting.SWbemLocator")
+	if err != nil {
+		return nil, err
+	}
+	if locator == nil {
+		return nil, errors.New("failed to create WbemScripting.SWbemLocator, maybe WMI is broken")
+	}
+	defer locator.Release()
+
+	wmi, err := locator.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interface: %w", err)
+	}
+	defer wmi.Release()
+
+	serviceRaw, err := oleutil.CallMethod(wmi, "ConnectServer", nil, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ConnectServer: %w", err)
+	}
+	service := serviceRaw.ToIDispatch()
+	defer serviceRaw.Clear()
+
+	resultRaw, err := oleutil.CallMethod(service, "ExecQuery", wql)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ExecQuery: %w", err)
+	}
+	result := resultRaw.ToIDispatch()
+	defer resultRaw.Clear()
+
+	var objects []map[string]interface{}
+	err = oleutil.ForEach(result, func(itemRaw *ole.VARIANT) error {
+		item := itemRaw.ToIDispatch()
+		object := make(map[string]interface{}, len(properties))
+		for _, name := range properties {
+			// Properties differ between Windows versions, so skip the ones
+			// not available
+			propertyRaw, err := oleutil.GetProperty(item, name)
+			if err != nil {
+				continue
+			}
+			object[name] = propertyRaw.Value()
+			propertyRaw.Clear()
+		}
+		objects = append(objects, object)
+		return nil
+	})
+
+	return objects, err
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +35,8 @@ type WinPerfCounters struct {
 	UsePerfCounterTime         bool            `toml:"UsePerfCounterTime"`
 	Object                     []perfObject    `toml:"object"`
 	CountersRefreshInterval    config.Duration `toml:"CountersRefreshInterval"`
+	WildcardRefreshInterval    config.Duration `toml:"WildcardRefreshInterval"`
+	InstanceChangeEvents       bool            `toml:"InstanceChangeEvents"`
 	UseWildcardsExpansion      bool            `toml:"UseWildcardsExpansion"`
 	LocalizeWildcardsExpansion bool            `toml:"LocalizeWildcardsExpansion"`
 	IgnoredErrors              []string        `toml:"IgnoredErrors"`
@@ -43,10 +46,16 @@ type WinPerfCounters struct {
 	Log telegraf.Logger `toml:"-"`
 
 	lastRefreshed time.Time
+	lastExpanded  time.Time
 	queryCreator  performanceQueryCreator
 	hostCounters  map[string]*hostCountersInfo
 	// cached os.Hostname()
 	cachedHostname string
+
+	// instances seen in the last gather per computer
+	instances      map[string]map[instanceGrouping]bool
+	refreshPending bool
+	sync.Mutex
 }
 
 type perfObject struct {
@@ -69,6 +78,8 @@ type hostCountersInfo struct {
 	counters  []*counter
 	query     performanceQuery
 	timestamp time.Time
+	// expanded counter paths per wildcard path
+	expansions map[string][]string
 }
 
 type counter struct {
@@ -137,7 +148,7 @@ func (m *WinPerfCounters) Gather(acc telegraf.Accumulator) error {
 	// Parse the config once
 	var err error
 
-	if m.lastRefreshed.IsZero() || (m.CountersRefreshInterval > 0 && m.lastRefreshed.Add(time.Duration(m.CountersRefreshInterval)).Before(time.Now())) {
+	if m.needsRefresh() {
 		if err := m.cleanQueries(); err != nil {
 			return err
 		}
@@ -152,6 +163,7 @@ func (m *WinPerfCounters) Gather(acc telegraf.Accumulator) error {
 			}
 		}
 		m.lastRefreshed = time.Now()
+		m.lastExpanded = m.lastRefreshed
 		// minimum time between collecting two samples
 		time.Sleep(time.Second)
 	}
@@ -189,6 +201,105 @@ func (m *WinPerfCounters) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// needsRefresh checks if the counters must be reread from the configuration
+// because the refresh interval elapsed or the set of instances changed
+func (m *WinPerfCounters) needsRefresh() bool {
+	if m.lastRefreshed.IsZero() {
+		return true
+	}
+	if m.CountersRefreshInterval > 0 && m.lastRefreshed.Add(time.Duration(m.CountersRefreshInterval)).Before(time.Now()) {
+		return true
+	}
+
+	m.Lock()
+	pending := m.refreshPending
+	m.refreshPending = false
+	m.Unlock()
+	if pending {
+		m.Log.Debug("Instances were removed, refreshing counters")
+		return true
+	}
+
+	if !m.UseWildcardsExpansion || m.WildcardRefreshInterval <= 0 {
+		return false
+	}
+	if m.lastExpanded.Add(time.Duration(m.WildcardRefreshInterval)).After(time.Now()) {
+		return false
+	}
+	m.lastExpanded = time.Now()
+	for _, hostCounterInfo := range m.hostCounters {
+		for path, expanded := range hostCounterInfo.expansions {
+			current, err := hostCounterInfo.query.expandWildCardPath(path)
+			if err != nil {
+				m.Log.Debugf("Expanding %q on %s failed: %v", path, hostCounterInfo.computer, err)
+				continue
+			}
+			current = slices.Clone(current)
+			slices.Sort(current)
+			if !slices.Equal(current, expanded) {
+				m.Log.Debugf("Expansion of %q on %s changed, refreshing counters", path, hostCounterInfo.computer)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// trackInstances compares the gathered instances with the previous gather to
+// refresh the counters if instances were removed and to emit discovery events
+// if enabled
+func (m *WinPerfCounters) trackInstances(hostCounterInfo *hostCountersInfo, collectedFields fieldGrouping, acc telegraf.Accumulator) {
+	current := make(map[instanceGrouping]bool, len(collectedFields))
+	for instance := range collectedFields {
+		if instance.instance != "" {
+			current[instance] = true
+		}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	if m.instances == nil {
+		m.instances = make(map[string]map[instanceGrouping]bool)
+	}
+	previous, found := m.instances[hostCounterInfo.computer]
+	m.instances[hostCounterInfo.computer] = current
+	if !found {
+		return
+	}
+
+	for instance := range previous {
+		if current[instance] {
+			continue
+		}
+		// Counters of expanded instances stay in the query until refreshed
+		if m.UseWildcardsExpansion {
+			m.refreshPending = true
+		}
+		if m.InstanceChangeEvents {
+			addInstanceEvent(acc, hostCounterInfo, instance, "removed")
+		}
+	}
+	if m.InstanceChangeEvents {
+		for instance := range current {
+			if !previous[instance] {
+				addInstanceEvent(acc, hostCounterInfo, instance, "added")
+			}
+		}
+	}
+}
+
+func addInstanceEvent(acc telegraf.Accumulator, hostCounterInfo *hostCountersInfo, instance instanceGrouping, event string) {
+	tags := map[string]string{
+		"measurement": instance.name,
+		"objectname":  instance.objectName,
+		"instance":    instance.instance,
+	}
+	if len(hostCounterInfo.tag) > 0 {
+		tags["source"] = hostCounterInfo.tag
+	}
+	acc.AddFields("win_perf_counters_instances", map[string]interface{}{"event": event}, tags, hostCounterInfo.timestamp)
+}
+
 // extractCounterInfoFromCounterPath gets object name, instance name (if available) and counter name from counter path
 // General Counter path pattern is: \\computer\object(parent/instance#index)\counter
 // parent/instance#index part is skipped in single instance objects (e.g. Memory): \\computer\object\counter
@@ -336,6 +447,12 @@ func (m *WinPerfCounters) addItem(counterPath, computer, objectName, instance, c
 		if err != nil {
 			return err
 		}
+		if hostCounter.expansions == nil {
+			hostCounter.expansions = make(map[string][]string)
+		}
+		expanded := slices.Clone(counters)
+		slices.Sort(expanded)
+		hostCounter.expansions[counterPath] = expanded
 
 		_, origObjectName, _, origCounterName, err := extractCounterInfoFromCounterPath(origCounterPath)
 		if err != nil {
@@ -568,6 +685,7 @@ func (m *WinPerfCounters) gatherComputerCounters(hostCounterInfo *hostCountersIn
 		}
 		acc.AddFields(instance.name, fields, tags, hostCounterInfo.timestamp)
 	}
+	m.trackInstances(hostCounterInfo, collectedFields, acc)
 	return nil
 }
 
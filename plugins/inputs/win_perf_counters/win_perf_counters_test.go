@@ -1697,6 +1697,124 @@ func TestGatherRefreshingWithoutExpansion(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGatherWildcardRefresh(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping long taking test in short mode")
+	}
+	measurement := "test"
+	perfObjects := createPerfObject("", measurement, "O", []string{"*"}, []string{"*"}, true, false, false)
+	cps1 := []string{"\\O(I1)\\C1", "\\O(I2)\\C1"}
+	fpm := &fakePerformanceQuery{
+		counters: createCounterMap(append(cps1, "\\O(*)\\*"), []float64{1.1, 1.2, 0}, []uint32{0, 0, 0}),
+		expandPaths: map[string][]string{
+			"\\O(*)\\*": cps1,
+		},
+		vistaAndNewer: true,
+	}
+	m := WinPerfCounters{
+		Log:                   testutil.Logger{},
+		Object:                perfObjects,
+		UseWildcardsExpansion: true,
+		queryCreator: &fakePerformanceQueryCreator{
+			fakeQueries: map[string]*fakePerformanceQuery{"localhost": fpm},
+		},
+		CountersRefreshInterval:    config.Duration(time.Hour),
+		WildcardRefreshInterval:    config.Duration(time.Millisecond),
+		InstanceChangeEvents:       true,
+		LocalizeWildcardsExpansion: true,
+	}
+	var acc1 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc1))
+	require.Len(t, m.hostCounters["localhost"].counters, 2)
+	require.Len(t, acc1.Metrics, 2)
+
+	// A new instance appears and is picked up by re-expanding the wildcards
+	cps2 := []string{"\\O(I1)\\C1", "\\O(I2)\\C1", "\\O(I3)\\C1"}
+	fpm.counters = createCounterMap(append(cps2, "\\O(*)\\*"), []float64{1.1, 1.2, 1.3, 0}, []uint32{0, 0, 0, 0})
+	fpm.expandPaths["\\O(*)\\*"] = cps2
+	time.Sleep(time.Millisecond)
+
+	var acc2 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc2))
+	require.Len(t, m.hostCounters["localhost"].counters, 3)
+	acc2.AssertContainsTaggedFields(t, measurement, map[string]interface{}{"C1": 1.3}, map[string]string{
+		"instance":   "I3",
+		"objectname": "O",
+		"source":     hostname(),
+	})
+	acc2.AssertContainsTaggedFields(t, "win_perf_counters_instances", map[string]interface{}{"event": "added"}, map[string]string{
+		"measurement": measurement,
+		"instance":    "I3",
+		"objectname":  "O",
+		"source":      hostname(),
+	})
+
+	// The removed instance is detected by the missing data and the counters
+	// are refreshed on the next gather
+	m.WildcardRefreshInterval = config.Duration(time.Hour)
+	fpm.counters = createCounterMap(append(cps2, "\\O(*)\\*"), []float64{1.1, 0, 1.3, 0}, []uint32{0, pdhCstatusNoInstance, 0, 0})
+	fpm.expandPaths["\\O(*)\\*"] = []string{"\\O(I1)\\C1", "\\O(I3)\\C1"}
+
+	var acc3 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc3))
+	require.Len(t, acc3.Metrics, 3)
+	acc3.AssertContainsTaggedFields(t, "win_perf_counters_instances", map[string]interface{}{"event": "removed"}, map[string]string{
+		"measurement": measurement,
+		"instance":    "I2",
+		"objectname":  "O",
+		"source":      hostname(),
+	})
+
+	var acc4 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc4))
+	require.Len(t, m.hostCounters["localhost"].counters, 2)
+	require.Len(t, acc4.Metrics, 2)
+	require.NoError(t, m.cleanQueries())
+}
+
+func TestGatherInstanceChangeEventsWithoutExpansion(t *testing.T) {
+	measurement := "test"
+	perfObjects := createPerfObject("", measurement, "O", []string{"*"}, []string{"C1"}, true, false, false)
+	fpm := &fakePerformanceQuery{
+		counters: createCounterMap(
+			[]string{"\\O(*)\\C1", "\\O(I1)\\C1", "\\O(I2)\\C1"},
+			[]float64{0, 1.1, 1.2},
+			[]uint32{0, 0, 0},
+		),
+		expandPaths: map[string][]string{
+			"\\O(*)\\C1": {"\\O(I1)\\C1"},
+		},
+		vistaAndNewer: true,
+	}
+	m := WinPerfCounters{
+		Log:    testutil.Logger{},
+		Object: perfObjects,
+		queryCreator: &fakePerformanceQueryCreator{
+			fakeQueries: map[string]*fakePerformanceQuery{"localhost": fpm},
+		},
+		InstanceChangeEvents: true,
+	}
+	var acc1 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc1))
+	require.Len(t, acc1.Metrics, 1)
+
+	// The instances are resolved on every gather without expansion
+	fpm.expandPaths["\\O(*)\\C1"] = []string{"\\O(I2)\\C1"}
+	var acc2 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc2))
+	require.Len(t, acc2.Metrics, 3)
+	for instance, event := range map[string]string{"I1": "removed", "I2": "added"} {
+		acc2.AssertContainsTaggedFields(t, "win_perf_counters_instances", map[string]interface{}{"event": event}, map[string]string{
+			"measurement": measurement,
+			"instance":    instance,
+			"objectname":  "O",
+			"source":      hostname(),
+		})
+	}
+	require.False(t, m.refreshPending)
+	require.NoError(t, m.cleanQueries())
+}
+
 func TestGatherTotalNoExpansion(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping long taking test in short mode")
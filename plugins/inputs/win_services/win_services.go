@@ -10,12 +10,14 @@ import (
 	"io/fs"
 	"strings"
 	"syscall"
+	"unsafe"
 
 	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/mgr"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -23,9 +25,18 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+var (
+	mpr                       = windows.NewLazySystemDLL("mpr.dll")
+	procWNetAddConnection2    = mpr.NewProc("WNetAddConnection2W")
+	procWNetCancelConnection2 = mpr.NewProc("WNetCancelConnection2W")
+)
+
 type WinServices struct {
-	ServiceNames         []string `toml:"service_names"`
-	ServiceNamesExcluded []string `toml:"excluded_service_names"`
+	ServiceNames         []string      `toml:"service_names"`
+	ServiceNamesExcluded []string      `toml:"excluded_service_names"`
+	RemoteHost           string        `toml:"remote_host"`
+	Username             config.Secret `toml:"username"`
+	Password             config.Secret `toml:"password"`
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -81,12 +92,27 @@ func (m *WinServices) Init() error {
 	}
 	m.servicesFilter = f
 
+	if m.RemoteHost == "" && !m.Username.Empty() {
+		return errors.New("credentials require a remote host")
+	}
+	if m.Username.Empty() && !m.Password.Empty() {
+		return errors.New("password requires a username")
+	}
+	if p, ok := m.mgrProvider.(*mgProvider); ok {
+		p.host = m.RemoteHost
+		p.username = &m.Username
+		p.password = &m.Password
+	}
+
 	return nil
 }
 
 func (m *WinServices) Gather(acc telegraf.Accumulator) error {
 	scmgr, err := m.mgrProvider.connect()
 	if err != nil {
+		if m.RemoteHost != "" {
+			return fmt.Errorf("could not open service manager on %q: %w", m.RemoteHost, err)
+		}
 		return fmt.Errorf("could not open service manager: %w", err)
 	}
 	defer scmgr.disconnect()
@@ -114,6 +140,9 @@ func (m *WinServices) Gather(acc telegraf.Accumulator) error {
 		if len(service.DisplayName) > 0 {
 			tags["display_name"] = service.DisplayName
 		}
+		if m.RemoteHost != "" {
+			tags["source"] = m.RemoteHost
+		}
 
 		fields := map[string]interface{}{
 			"state":        service.State,
@@ -204,10 +233,19 @@ func (e *serviceError) Error() string {
 // winSvcMgr is wrapper for mgr.Mgr implementing winServiceManager interface
 type winSvcMgr struct {
 	realMgr *mgr.Mgr
+
+	// authenticated connection to the remote host to cancel on disconnect
+	connection string
 }
 
 func (m *winSvcMgr) disconnect() error {
-	return m.realMgr.Disconnect()
+	err := m.realMgr.Disconnect()
+	if m.connection != "" {
+		if cerr := cancelConnection(m.connection); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
 }
 
 func (m *winSvcMgr) openService(name string) (winService, error) {
@@ -228,15 +266,117 @@ func (m *winSvcMgr) listServices() ([]string, error) {
 
 // mgProvider is an implementation of WinServiceManagerProvider interface returning winSvcMgr
 type mgProvider struct {
+	host     string
+	username *config.Secret
+	password *config.Secret
 }
 
-func (*mgProvider) connect() (winServiceManager, error) {
-	h, err := windows.OpenSCManager(nil, nil, windows.GENERIC_READ)
+func (p *mgProvider) connect() (winServiceManager, error) {
+	if p.host == "" {
+		h, err := windows.OpenSCManager(nil, nil, windows.GENERIC_READ)
+		if err != nil {
+			return nil, err
+		}
+		return &winSvcMgr{realMgr: &mgr.Mgr{Handle: h}}, nil
+	}
+
+	machine := `\\` + strings.TrimPrefix(p.host, `\\`)
+	machineName, err := syscall.UTF16PtrFromString(machine)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert host name %q: %w", p.host, err)
+	}
+
+	// The service control manager uses the credentials of the current user
+	// unless there is an authenticated connection to the remote host
+	var connection string
+	if p.username != nil && !p.username.Empty() {
+		connection = machine + `\IPC$`
+		if err := p.addConnection(connection); err != nil {
+			return nil, fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	h, err := windows.OpenSCManager(machineName, nil, windows.GENERIC_READ)
 	if err != nil {
+		if connection != "" {
+			if cerr := cancelConnection(connection); cerr != nil {
+				err = errors.Join(err, cerr)
+			}
+		}
 		return nil, err
 	}
-	scmgr := &mgr.Mgr{Handle: h}
-	return &winSvcMgr{scmgr}, nil
+	return &winSvcMgr{realMgr: &mgr.Mgr{Handle: h}, connection: connection}, nil
+}
+
+// netResource mirrors the NETRESOURCEW structure of the Windows API
+type netResource struct {
+	scope       uint32
+	resType     uint32
+	displayType uint32
+	usage       uint32
+	localName   *uint16
+	remoteName  *uint16
+	comment     *uint16
+	provider    *uint16
+}
+
+// addConnection establishes a connection to the given remote resource using
+// the configured credentials
+func (p *mgProvider) addConnection(remote string) error {
+	username, err := p.username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+	user, err := syscall.UTF16PtrFromString(username.String())
+	if err != nil {
+		return fmt.Errorf("cannot convert username: %w", err)
+	}
+
+	var pass *uint16
+	if p.password != nil && !p.password.Empty() {
+		password, err := p.password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		defer password.Destroy()
+		pass, err = syscall.UTF16PtrFromString(password.String())
+		if err != nil {
+			return fmt.Errorf("cannot convert password: %w", err)
+		}
+	}
+
+	remoteName, err := syscall.UTF16PtrFromString(remote)
+	if err != nil {
+		return fmt.Errorf("cannot convert remote name %q: %w", remote, err)
+	}
+	resource := &netResource{
+		resType:    0, // RESOURCETYPE_ANY
+		remoteName: remoteName,
+	}
+
+	r, _, _ := procWNetAddConnection2.Call(
+		uintptr(unsafe.Pointer(resource)),
+		uintptr(unsafe.Pointer(pass)),
+		uintptr(unsafe.Pointer(user)),
+		0,
+	)
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	return nil
+}
+
+func cancelConnection(remote string) error {
+	remoteName, err := syscall.UTF16PtrFromString(remote)
+	if err != nil {
+		return fmt.Errorf("cannot convert remote name %q: %w", remote, err)
+	}
+	r, _, _ := procWNetCancelConnection2.Call(uintptr(unsafe.Pointer(remoteName)), 0, 1)
+	if r != 0 {
+		return fmt.Errorf("cancelling connection to %q failed: %w", remote, syscall.Errno(r))
+	}
+	return nil
 }
 
 func init() {
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/mgr"
 
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -238,3 +239,39 @@ func TestExcludingNamesTag(t *testing.T) {
 		acc1.AssertDoesNotContainsTaggedFields(t, "win_services", fields, tags)
 	}
 }
+
+func TestGatherRemoteHostTag(t *testing.T) {
+	winServices := &WinServices{
+		Log:          testutil.Logger{},
+		ServiceNames: []string{"Service*"},
+		RemoteHost:   "server.example.com",
+		mgrProvider:  &FakeMgProvider{testSimpleData[0]},
+	}
+	require.NoError(t, winServices.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, winServices.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	for _, s := range testSimpleData[0].services {
+		fields := map[string]interface{}{
+			"state":        s.state,
+			"startup_mode": s.startUpMode,
+		}
+		tags := map[string]string{
+			"service_name": s.serviceName,
+			"display_name": s.displayName,
+			"source":       "server.example.com",
+		}
+		acc.AssertContainsTaggedFields(t, "win_services", fields, tags)
+	}
+}
+
+func TestInitCredentialsWithoutRemoteHost(t *testing.T) {
+	winServices := &WinServices{
+		Log:         testutil.Logger{},
+		Username:    config.NewSecret([]byte("user")),
+		mgrProvider: &mgProvider{},
+	}
+	require.ErrorContains(t, winServices.Init(), "credentials require a remote host")
+}
@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"fmt"
 	"strings"
+	"time"
 
 	"golang.zx2c4.com/wireguard/wgctrl"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
@@ -17,8 +18,9 @@ import (
 var sampleConfig string
 
 const (
-	measurementDevice = "wireguard_device"
-	measurementPeer   = "wireguard_peer"
+	measurementDevice         = "wireguard_device"
+	measurementPeer           = "wireguard_peer"
+	measurementEndpointChange = "wireguard_peer_endpoint_change"
 )
 
 var (
@@ -32,10 +34,15 @@ var (
 // Wireguard is an input that enumerates all Wireguard interfaces/devices on
 // the host, and reports gauge metrics for the device itself and its peers.
 type Wireguard struct {
-	Devices []string        `toml:"devices"`
-	Log     telegraf.Logger `toml:"-"`
+	Devices              []string          `toml:"devices"`
+	PeerNames            map[string]string `toml:"peer_names"`
+	EndpointChangeEvents bool              `toml:"endpoint_change_events"`
+	Log                  telegraf.Logger   `toml:"-"`
 
 	client *wgctrl.Client
+
+	// Last known endpoint per device and peer public key
+	endpoints map[string]string
 }
 
 func (*Wireguard) SampleConfig() string {
@@ -45,6 +52,13 @@ func (*Wireguard) SampleConfig() string {
 func (wg *Wireguard) Init() error {
 	var err error
 
+	for key := range wg.PeerNames {
+		if _, err := wgtypes.ParseKey(key); err != nil {
+			return fmt.Errorf("invalid public key %q in 'peer_names': %w", key, err)
+		}
+	}
+	wg.endpoints = make(map[string]string)
+
 	wg.client, err = wgctrl.New()
 
 	return err
@@ -56,11 +70,15 @@ func (wg *Wireguard) Gather(acc telegraf.Accumulator) error {
 		return fmt.Errorf("error enumerating Wireguard devices: %w", err)
 	}
 
+	now := time.Now()
 	for _, device := range devices {
 		gatherDeviceMetrics(acc, device)
 
 		for _, peer := range device.Peers {
-			gatherDevicePeerMetrics(acc, device, peer)
+			wg.gatherDevicePeerMetrics(acc, device, peer, now)
+			if wg.EndpointChangeEvents {
+				wg.checkEndpointChange(acc, device, peer)
+			}
 		}
 	}
 
@@ -108,7 +126,7 @@ func gatherDeviceMetrics(acc telegraf.Accumulator, device *wgtypes.Device) {
 	acc.AddGauge(measurementDevice, gauges, tags)
 }
 
-func gatherDevicePeerMetrics(acc telegraf.Accumulator, device *wgtypes.Device, peer wgtypes.Peer) {
+func (wg *Wireguard) gatherDevicePeerMetrics(acc telegraf.Accumulator, device *wgtypes.Device, peer wgtypes.Peer, now time.Time) {
 	fields := map[string]interface{}{
 		"persistent_keepalive_interval_ns": peer.PersistentKeepaliveInterval.Nanoseconds(),
 		"protocol_version":                 peer.ProtocolVersion,
@@ -129,15 +147,50 @@ func gatherDevicePeerMetrics(acc telegraf.Accumulator, device *wgtypes.Device, p
 		"tx_bytes":               peer.TransmitBytes,
 	}
 
-	tags := map[string]string{
-		"device":     device.Name,
-		"public_key": peer.PublicKey.String(),
+	// Peers without a handshake report the Unix epoch as handshake time
+	if peer.LastHandshakeTime.UnixNano() > 0 {
+		gauges["last_handshake_age_ns"] = now.Sub(peer.LastHandshakeTime).Nanoseconds()
 	}
 
+	tags := wg.peerTags(device, peer)
+
 	acc.AddFields(measurementPeer, fields, tags)
 	acc.AddGauge(measurementPeer, gauges, tags)
 }
 
+// checkEndpointChange emits an event if the endpoint of the peer differs from
+// the endpoint seen in the previous gather cycle
+func (wg *Wireguard) checkEndpointChange(acc telegraf.Accumulator, device *wgtypes.Device, peer wgtypes.Peer) {
+	var endpoint string
+	if peer.Endpoint != nil {
+		endpoint = peer.Endpoint.String()
+	}
+
+	key := device.Name + "/" + peer.PublicKey.String()
+	previous, found := wg.endpoints[key]
+	wg.endpoints[key] = endpoint
+	if !found || previous == endpoint {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"previous_endpoint": previous,
+		"endpoint":          endpoint,
+	}
+	acc.AddFields(measurementEndpointChange, fields, wg.peerTags(device, peer))
+}
+
+func (wg *Wireguard) peerTags(device *wgtypes.Device, peer wgtypes.Peer) map[string]string {
+	tags := map[string]string{
+		"device":     device.Name,
+		"public_key": peer.PublicKey.String(),
+	}
+	if name, found := wg.PeerNames[tags["public_key"]]; found {
+		tags["peer_name"] = name
+	}
+	return tags
+}
+
 func init() {
 	inputs.Add("wireguard", func() telegraf.Input {
 		return &Wireguard{}
@@ -8,6 +8,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -64,6 +66,7 @@ func TestWireguard_gatherDevicePeerMetrics(t *testing.T) {
 	}
 	expectGauges := map[string]interface{}{
 		"last_handshake_time_ns": int64(100000000000),
+		"last_handshake_age_ns":  int64(60000000000),
 		"rx_bytes":               int64(40),
 		"tx_bytes":               int64(60),
 	}
@@ -73,9 +76,10 @@ func TestWireguard_gatherDevicePeerMetrics(t *testing.T) {
 	}
 
 	var acc testutil.Accumulator
-	gatherDevicePeerMetrics(&acc, device, peer)
+	plugin := &Wireguard{}
+	plugin.gatherDevicePeerMetrics(&acc, device, peer, time.Unix(160, 0))
 
-	require.Equal(t, 7, acc.NFields())
+	require.Equal(t, 8, acc.NFields())
 	acc.AssertDoesNotContainMeasurement(t, measurementDevice)
 	acc.AssertContainsTaggedFields(t, measurementPeer, expectFields, expectTags)
 	acc.AssertContainsTaggedFields(t, measurementPeer, expectGauges, expectTags)
@@ -139,9 +143,88 @@ func TestWireguard_allowedPeerCIDR(t *testing.T) {
 			}
 
 			var acc testutil.Accumulator
-			gatherDevicePeerMetrics(&acc, device, peer)
+			plugin := &Wireguard{}
+			plugin.gatherDevicePeerMetrics(&acc, device, peer, time.Now())
 			acc.AssertDoesNotContainMeasurement(t, measurementDevice)
 			acc.AssertContainsFields(t, measurementPeer, expectFields)
 		})
 	}
 }
+
+func TestWireguard_noHandshake(t *testing.T) {
+	device := &wgtypes.Device{Name: "wg0"}
+	peer := wgtypes.Peer{LastHandshakeTime: time.Unix(0, 0)}
+
+	var acc testutil.Accumulator
+	plugin := &Wireguard{}
+	plugin.gatherDevicePeerMetrics(&acc, device, peer, time.Now())
+
+	for _, m := range acc.Metrics {
+		require.NotContains(t, m.Fields, "last_handshake_age_ns")
+	}
+}
+
+func TestWireguard_peerNames(t *testing.T) {
+	pubkey, err := wgtypes.ParseKey("NZTRIrv/ClTcQoNAnChEot+WL7OH7uEGQmx8oAN9rWE=")
+	require.NoError(t, err)
+
+	plugin := &Wireguard{
+		PeerNames: map[string]string{pubkey.String(): "laptop"},
+	}
+	device := &wgtypes.Device{Name: "wg0"}
+	peer := wgtypes.Peer{PublicKey: pubkey}
+
+	var acc testutil.Accumulator
+	plugin.gatherDevicePeerMetrics(&acc, device, peer, time.Now())
+
+	require.NotEmpty(t, acc.Metrics)
+	for _, m := range acc.Metrics {
+		require.Equal(t, "laptop", m.Tags["peer_name"])
+	}
+}
+
+func TestWireguard_invalidPeerName(t *testing.T) {
+	plugin := &Wireguard{
+		PeerNames: map[string]string{"foo": "laptop"},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid public key "foo" in 'peer_names'`)
+}
+
+func TestWireguard_endpointChange(t *testing.T) {
+	pubkey, err := wgtypes.ParseKey("NZTRIrv/ClTcQoNAnChEot+WL7OH7uEGQmx8oAN9rWE=")
+	require.NoError(t, err)
+
+	plugin := &Wireguard{
+		PeerNames: map[string]string{pubkey.String(): "laptop"},
+		endpoints: make(map[string]string),
+	}
+	device := &wgtypes.Device{Name: "wg0"}
+
+	var acc testutil.Accumulator
+	endpoints := []*net.UDPAddr{
+		{IP: net.IPv4(192, 0, 2, 1), Port: 51820},
+		{IP: net.IPv4(192, 0, 2, 1), Port: 51820},
+		{IP: net.IPv4(198, 51, 100, 7), Port: 40000},
+	}
+	for _, endpoint := range endpoints {
+		peer := wgtypes.Peer{PublicKey: pubkey, Endpoint: endpoint}
+		plugin.checkEndpointChange(&acc, device, peer)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			measurementEndpointChange,
+			map[string]string{
+				"device":     "wg0",
+				"public_key": pubkey.String(),
+				"peer_name":  "laptop",
+			},
+			map[string]interface{}{
+				"previous_endpoint": "192.0.2.1:51820",
+				"endpoint":          "198.51.100.7:40000",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,239 @@
+//go:build linux
+
+package xdp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/influxdata/telegraf/internal/ebpf"
+)
+
+// Constants of the XDP program, see include/uapi/linux/bpf.h and the
+// respective protocol specifications
+const (
+	xdpPass             = 2
+	valueSize           = 16
+	ethHeaderLen        = 14
+	vlanHeaderLen       = 4
+	ipv4HeaderMinLen    = 20
+	ipv6HeaderLen       = 40
+	protoTCP            = 6
+	protoUDP            = 17
+	protoSCTP           = 132
+	protoICMP           = 1
+	protoICMPv6         = 58
+	registerContext     = 6
+	registerCursor      = 7
+	registerDataEnd     = 8
+	registerPacketLen   = 9
+	registerFramePtr    = ebpf.RegisterFramePtr
+	registerScratch     = 3
+	registerScratchHigh = 4
+)
+
+// Offsets of the parsed header fields on the stack relative to the frame
+// pointer. Addresses are stored as four consecutive 32-bit words with IPv4
+// addresses occupying the first word only.
+const (
+	stackFamily  = -4
+	stackProto   = -8
+	stackSrcPort = -12
+	stackDstPort = -16
+	stackSrcAddr = -32
+	stackDstAddr = -48
+	stackKey     = -52
+)
+
+// wire16 and wire32 return the value of the given network-order bytes as
+// loaded by the eBPF program on this machine
+func wire16(v uint16) int32 {
+	return int32(binary.NativeEndian.Uint16(binary.BigEndian.AppendUint16(nil, v)))
+}
+
+func wire32(b []byte) uint32 {
+	return binary.NativeEndian.Uint32(b)
+}
+
+// generateProgram creates an XDP program counting packets and bytes for
+// each of the given rules in the map referenced by the file descriptor.
+// All packets are passed on to the network stack unmodified.
+func generateProgram(rules []*rule, mapFD int) ([]byte, error) {
+	a := ebpf.NewAssembler()
+
+	// Initialize the stack area used for the parsed header fields as the
+	// verifier rejects reading uninitialized memory
+	for offset := int16(-8); offset >= stackDstAddr; offset -= 8 {
+		a.StoreImm(ebpf.SizeDW, registerFramePtr, offset, 0)
+	}
+
+	// Load the packet boundaries and determine the packet length
+	a.MovReg(registerContext, 1)
+	a.Load(ebpf.SizeW, registerCursor, registerContext, 0)
+	a.Load(ebpf.SizeW, registerDataEnd, registerContext, 4)
+	a.MovReg(registerPacketLen, registerDataEnd)
+	a.ALUReg(ebpf.OpSub, registerPacketLen, registerCursor)
+
+	// Ethernet header with up to two VLAN tags
+	a.MovReg(2, registerCursor)
+	a.ALUImm(ebpf.OpAdd, 2, ethHeaderLen)
+	a.JumpReg(ebpf.JmpJGT, 2, registerDataEnd, "count")
+	a.Load(ebpf.SizeH, registerScratch, registerCursor, 12)
+	a.ALUImm(ebpf.OpAdd, registerCursor, ethHeaderLen)
+	a.JumpImm(ebpf.JmpJEQ, registerScratch, wire16(0x8100), "vlan")
+	a.JumpImm(ebpf.JmpJEQ, registerScratch, wire16(0x88a8), "vlan")
+	a.Jump("l3")
+	a.Label("vlan")
+	a.MovReg(2, registerCursor)
+	a.ALUImm(ebpf.OpAdd, 2, vlanHeaderLen)
+	a.JumpReg(ebpf.JmpJGT, 2, registerDataEnd, "count")
+	a.Load(ebpf.SizeH, registerScratch, registerCursor, 2)
+	a.ALUImm(ebpf.OpAdd, registerCursor, vlanHeaderLen)
+
+	// 802.1ad (QinQ) frames carry an inner 802.1Q tag
+	a.JumpImm(ebpf.JmpJNE, registerScratch, wire16(0x8100), "l3")
+	a.MovReg(2, registerCursor)
+	a.ALUImm(ebpf.OpAdd, 2, vlanHeaderLen)
+	a.JumpReg(ebpf.JmpJGT, 2, registerDataEnd, "count")
+	a.Load(ebpf.SizeH, registerScratch, registerCursor, 2)
+	a.ALUImm(ebpf.OpAdd, registerCursor, vlanHeaderLen)
+	a.Label("l3")
+	a.JumpImm(ebpf.JmpJEQ, registerScratch, wire16(0x0800), "ipv4")
+	a.JumpImm(ebpf.JmpJEQ, registerScratch, wire16(0x86dd), "ipv6")
+	a.Jump("count")
+
+	// IPv4 header, non-initial fragments do not carry the L4 header
+	a.Label("ipv4")
+	a.MovReg(2, registerCursor)
+	a.ALUImm(ebpf.OpAdd, 2, ipv4HeaderMinLen)
+	a.JumpReg(ebpf.JmpJGT, 2, registerDataEnd, "count")
+	a.StoreImm(ebpf.SizeW, registerFramePtr, stackFamily, 4)
+	a.Load(ebpf.SizeB, registerScratch, registerCursor, 9)
+	a.Store(ebpf.SizeW, registerFramePtr, registerScratch, stackProto)
+	a.Load(ebpf.SizeW, registerScratch, registerCursor, 12)
+	a.Store(ebpf.SizeW, registerFramePtr, registerScratch, stackSrcAddr)
+	a.Load(ebpf.SizeW, registerScratch, registerCursor, 16)
+	a.Store(ebpf.SizeW, registerFramePtr, registerScratch, stackDstAddr)
+	a.Load(ebpf.SizeH, registerScratch, registerCursor, 6)
+	a.ALUImm(ebpf.OpAnd, registerScratch, wire16(0x1fff))
+	a.JumpImm(ebpf.JmpJNE, registerScratch, 0, "count")
+	a.Load(ebpf.SizeB, registerScratch, registerCursor, 0)
+	a.ALUImm(ebpf.OpAnd, registerScratch, 0x0f)
+	a.ALUImm(ebpf.OpLsh, registerScratch, 2)
+	a.ALUReg(ebpf.OpAdd, registerCursor, registerScratch)
+	a.Jump("l4")
+
+	// IPv6 header, extension headers are not followed
+	a.Label("ipv6")
+	a.MovReg(2, registerCursor)
+	a.ALUImm(ebpf.OpAdd, 2, ipv6HeaderLen)
+	a.JumpReg(ebpf.JmpJGT, 2, registerDataEnd, "count")
+	a.StoreImm(ebpf.SizeW, registerFramePtr, stackFamily, 6)
+	a.Load(ebpf.SizeB, registerScratch, registerCursor, 6)
+	a.Store(ebpf.SizeW, registerFramePtr, registerScratch, stackProto)
+	for i := int16(0); i < 4; i++ {
+		a.Load(ebpf.SizeW, registerScratch, registerCursor, 8+4*i)
+		a.Store(ebpf.SizeW, registerFramePtr, registerScratch, stackSrcAddr+4*i)
+		a.Load(ebpf.SizeW, registerScratch, registerCursor, 24+4*i)
+		a.Store(ebpf.SizeW, registerFramePtr, registerScratch, stackDstAddr+4*i)
+	}
+	a.ALUImm(ebpf.OpAdd, registerCursor, ipv6HeaderLen)
+
+	// Ports of the protocols supporting them
+	a.Label("l4")
+	a.Load(ebpf.SizeW, registerScratch, registerFramePtr, stackProto)
+	a.JumpImm(ebpf.JmpJEQ, registerScratch, protoTCP, "ports")
+	a.JumpImm(ebpf.JmpJEQ, registerScratch, protoUDP, "ports")
+	a.JumpImm(ebpf.JmpJEQ, registerScratch, protoSCTP, "ports")
+	a.Jump("count")
+	a.Label("ports")
+	a.MovReg(2, registerCursor)
+	a.ALUImm(ebpf.OpAdd, 2, 4)
+	a.JumpReg(ebpf.JmpJGT, 2, registerDataEnd, "count")
+	a.Load(ebpf.SizeH, registerScratch, registerCursor, 0)
+	a.Store(ebpf.SizeW, registerFramePtr, registerScratch, stackSrcPort)
+	a.Load(ebpf.SizeH, registerScratch, registerCursor, 2)
+	a.Store(ebpf.SizeW, registerFramePtr, registerScratch, stackDstPort)
+
+	// Match the rules and update the counters
+	a.Label("count")
+	for i, r := range rules {
+		next := fmt.Sprintf("next_%d", i)
+		if r.family != 0 {
+			a.Load(ebpf.SizeW, registerScratch, registerFramePtr, stackFamily)
+			a.JumpImm(ebpf.JmpJNE, registerScratch, int32(r.family), next)
+		}
+		if r.proto != 0 {
+			a.Load(ebpf.SizeW, registerScratch, registerFramePtr, stackProto)
+			a.JumpImm(ebpf.JmpJNE, registerScratch, int32(r.proto), next)
+		}
+		if r.SrcPort != 0 {
+			a.Load(ebpf.SizeW, registerScratch, registerFramePtr, stackSrcPort)
+			a.JumpImm(ebpf.JmpJNE, registerScratch, wire16(r.SrcPort), next)
+		}
+		if r.DstPort != 0 {
+			a.Load(ebpf.SizeW, registerScratch, registerFramePtr, stackDstPort)
+			a.JumpImm(ebpf.JmpJNE, registerScratch, wire16(r.DstPort), next)
+		}
+		if r.Port != 0 {
+			matched := fmt.Sprintf("port_%d", i)
+			a.Load(ebpf.SizeW, registerScratch, registerFramePtr, stackSrcPort)
+			a.JumpImm(ebpf.JmpJEQ, registerScratch, wire16(r.Port), matched)
+			a.Load(ebpf.SizeW, registerScratch, registerFramePtr, stackDstPort)
+			a.JumpImm(ebpf.JmpJNE, registerScratch, wire16(r.Port), next)
+			a.Label(matched)
+		}
+		if r.srcNet != nil {
+			emitPrefixMatch(a, stackSrcAddr, r.srcNet, next)
+		}
+		if r.dstNet != nil {
+			emitPrefixMatch(a, stackDstAddr, r.dstNet, next)
+		}
+		if r.anyNet != nil {
+			tryDst := fmt.Sprintf("prefix_dst_%d", i)
+			matched := fmt.Sprintf("prefix_%d", i)
+			emitPrefixMatch(a, stackSrcAddr, r.anyNet, tryDst)
+			a.Jump(matched)
+			a.Label(tryDst)
+			emitPrefixMatch(a, stackDstAddr, r.anyNet, next)
+			a.Label(matched)
+		}
+
+		// The map is a per-CPU array so no atomic operations are required
+		a.StoreImm(ebpf.SizeW, registerFramePtr, stackKey, int32(i))
+		a.LoadMapFD(1, mapFD)
+		a.MovReg(2, registerFramePtr)
+		a.ALUImm(ebpf.OpAdd, 2, stackKey)
+		a.Call(ebpf.FuncMapLookupElem)
+		a.JumpImm(ebpf.JmpJEQ, 0, 0, next)
+		a.Load(ebpf.SizeDW, registerScratch, 0, 0)
+		a.ALUImm(ebpf.OpAdd, registerScratch, 1)
+		a.Store(ebpf.SizeDW, 0, registerScratch, 0)
+		a.Load(ebpf.SizeDW, registerScratch, 0, 8)
+		a.ALUReg(ebpf.OpAdd, registerScratch, registerPacketLen)
+		a.Store(ebpf.SizeDW, 0, registerScratch, 8)
+		a.Label(next)
+	}
+
+	a.MovImm(0, xdpPass)
+	a.Exit()
+
+	return a.Assemble()
+}
+
+// emitPrefixMatch compares the masked address stored at the given stack
+// offset with the network and jumps to the target on mismatch
+func emitPrefixMatch(a *ebpf.Assembler, offset int16, network *net.IPNet, target string) {
+	for w := 0; w < len(network.Mask)/4; w++ {
+		mask := network.Mask[4*w : 4*w+4]
+		if wire32(mask) == 0 {
+			break
+		}
+		a.Load(ebpf.SizeW, registerScratch, registerFramePtr, offset+int16(4*w))
+		a.MovImm32(registerScratchHigh, wire32(mask))
+		a.ALUReg(ebpf.OpAnd, registerScratch, registerScratchHigh)
+		a.MovImm32(registerScratchHigh, wire32(network.IP[4*w:4*w+4]))
+		a.JumpReg(ebpf.JmpJNE, registerScratch, registerScratchHigh, target)
+	}
+}
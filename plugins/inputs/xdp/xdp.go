@@ -0,0 +1,305 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package xdp
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/ebpf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type XDP struct {
+	Interfaces []string        `toml:"interfaces"`
+	Mode       string          `toml:"mode"`
+	Rules      []*rule         `toml:"rule"`
+	Log        telegraf.Logger `toml:"-"`
+
+	probes []*probe
+	ncpu   int
+}
+
+type rule struct {
+	Name      string `toml:"name"`
+	Protocol  string `toml:"protocol"`
+	Port      uint16 `toml:"port"`
+	SrcPort   uint16 `toml:"src_port"`
+	DstPort   uint16 `toml:"dst_port"`
+	Prefix    string `toml:"prefix"`
+	SrcPrefix string `toml:"src_prefix"`
+	DstPrefix string `toml:"dst_prefix"`
+
+	family uint8
+	proto  uint8
+	anyNet *net.IPNet
+	srcNet *net.IPNet
+	dstNet *net.IPNet
+}
+
+func (*XDP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (x *XDP) Init() error {
+	if len(x.Interfaces) == 0 {
+		return errors.New("no interfaces configured")
+	}
+	if len(x.Rules) == 0 {
+		return errors.New("no rules configured")
+	}
+
+	switch x.Mode {
+	case "":
+		x.Mode = "generic"
+	case "auto", "generic", "native":
+	default:
+		return fmt.Errorf("invalid mode %q", x.Mode)
+	}
+
+	seen := make(map[string]bool, len(x.Rules))
+	for i, r := range x.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("duplicate rule name %q", r.Name)
+		}
+		seen[r.Name] = true
+
+		if err := r.init(); err != nil {
+			return fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *rule) init() error {
+	switch r.Protocol {
+	case "", "any":
+	case "tcp":
+		r.proto = protoTCP
+	case "udp":
+		r.proto = protoUDP
+	case "sctp":
+		r.proto = protoSCTP
+	case "icmp":
+		r.proto = protoICMP
+	case "icmpv6":
+		r.proto = protoICMPv6
+	default:
+		return fmt.Errorf("invalid protocol %q", r.Protocol)
+	}
+
+	if r.Port != 0 || r.SrcPort != 0 || r.DstPort != 0 {
+		switch r.proto {
+		case protoTCP, protoUDP, protoSCTP, 0:
+		default:
+			return fmt.Errorf("ports are not supported for protocol %q", r.Protocol)
+		}
+	}
+	if r.Port != 0 && (r.SrcPort != 0 || r.DstPort != 0) {
+		return errors.New("'port' cannot be combined with 'src_port' or 'dst_port'")
+	}
+	if r.Prefix != "" && (r.SrcPrefix != "" || r.DstPrefix != "") {
+		return errors.New("'prefix' cannot be combined with 'src_prefix' or 'dst_prefix'")
+	}
+
+	var err error
+	if r.anyNet, err = r.parsePrefix(r.Prefix); err != nil {
+		return err
+	}
+	if r.srcNet, err = r.parsePrefix(r.SrcPrefix); err != nil {
+		return err
+	}
+	if r.dstNet, err = r.parsePrefix(r.DstPrefix); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parsePrefix parses the CIDR notation and makes sure all prefixes of the
+// rule belong to the same address family
+func (r *rule) parsePrefix(prefix string) (*net.IPNet, error) {
+	if prefix == "" {
+		return nil, nil
+	}
+
+	_, network, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("parsing prefix %q failed: %w", prefix, err)
+	}
+
+	var family uint8 = 6
+	if ip := network.IP.To4(); ip != nil {
+		family = 4
+		network.IP = ip
+	}
+	if r.family != 0 && r.family != family {
+		return nil, errors.New("prefixes must be of the same address family")
+	}
+	r.family = family
+
+	return network, nil
+}
+
+// probe represents the XDP program and its counter map attached to a
+// single interface
+type probe struct {
+	iface  string
+	mapFD  int
+	progFD int
+	linkFD int
+}
+
+func (x *XDP) Start(telegraf.Accumulator) error {
+	var flags uint32
+	switch x.Mode {
+	case "generic":
+		flags = unix.XDP_FLAGS_SKB_MODE
+	case "native":
+		flags = unix.XDP_FLAGS_DRV_MODE
+	}
+
+	ncpu, err := ebpf.PossibleCPUs()
+	if err != nil {
+		return fmt.Errorf("determining number of CPUs failed: %w", err)
+	}
+	x.ncpu = ncpu
+
+	for _, iface := range x.Interfaces {
+		p, err := newProbe(iface, x.Rules, flags)
+		if err != nil {
+			x.Stop()
+			return fmt.Errorf("attaching to interface %q failed: %w", iface, err)
+		}
+		x.probes = append(x.probes, p)
+	}
+
+	return nil
+}
+
+func (x *XDP) Gather(acc telegraf.Accumulator) error {
+	for _, p := range x.probes {
+		counters, err := p.counters(len(x.Rules), x.ncpu)
+		if err != nil {
+			acc.AddError(fmt.Errorf("reading counters on %q failed: %w", p.iface, err))
+			continue
+		}
+
+		for i, r := range x.Rules {
+			tags := map[string]string{
+				"interface": p.iface,
+				"rule":      r.Name,
+			}
+			fields := map[string]interface{}{
+				"packets": counters[i].packets,
+				"bytes":   counters[i].bytes,
+			}
+			acc.AddCounter("xdp", fields, tags)
+		}
+	}
+
+	return nil
+}
+
+func (x *XDP) Stop() {
+	for _, p := range x.probes {
+		p.close()
+	}
+	x.probes = nil
+}
+
+func newProbe(iface string, rules []*rule, flags uint32) (*probe, error) {
+	netif, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, err
+	}
+
+	mapFD, err := ebpf.CreateMap(unix.BPF_MAP_TYPE_PERCPU_ARRAY, 4, valueSize, uint32(len(rules)))
+	if err != nil {
+		return nil, fmt.Errorf("creating counter map failed: %w", err)
+	}
+
+	prog, err := generateProgram(rules, mapFD)
+	if err != nil {
+		unix.Close(mapFD)
+		return nil, fmt.Errorf("generating program failed: %w", err)
+	}
+
+	progFD, err := ebpf.LoadProgram(unix.BPF_PROG_TYPE_XDP, prog, "telegraf_xdp", 0)
+	if err != nil {
+		unix.Close(mapFD)
+		return nil, fmt.Errorf("loading program failed: %w", err)
+	}
+
+	// Attach the program via a BPF link (Linux 5.9 and later) owned by this
+	// process. The kernel detaches the program when the link is closed,
+	// including the case of Telegraf terminating unexpectedly, and refuses to
+	// replace programs attached by other tools.
+	linkFD, err := ebpf.CreateLink(progFD, netif.Index, unix.BPF_XDP, flags)
+	if err != nil {
+		unix.Close(progFD)
+		unix.Close(mapFD)
+		if errors.Is(err, unix.EBUSY) {
+			return nil, fmt.Errorf("%w: another XDP program is attached to the interface", err)
+		}
+		return nil, err
+	}
+
+	return &probe{
+		iface:  iface,
+		mapFD:  mapFD,
+		progFD: progFD,
+		linkFD: linkFD,
+	}, nil
+}
+
+type counter struct {
+	packets uint64
+	bytes   uint64
+}
+
+// counters returns the packet and byte counters of the rules summed up over
+// all CPUs
+func (p *probe) counters(n, ncpu int) ([]counter, error) {
+	result := make([]counter, n)
+	value := make([]byte, valueSize*ncpu)
+	for i := range result {
+		key := binary.NativeEndian.AppendUint32(nil, uint32(i))
+		if err := ebpf.MapLookup(p.mapFD, key, value); err != nil {
+			return nil, err
+		}
+		for cpu := 0; cpu < ncpu; cpu++ {
+			result[i].packets += binary.NativeEndian.Uint64(value[cpu*valueSize:])
+			result[i].bytes += binary.NativeEndian.Uint64(value[cpu*valueSize+8:])
+		}
+	}
+	return result, nil
+}
+
+// close detaches the program by releasing the link; programs attached by
+// others are not affected
+func (p *probe) close() {
+	unix.Close(p.linkFD)
+	unix.Close(p.progFD)
+	unix.Close(p.mapFD)
+}
+
+func init() {
+	inputs.Add("xdp", func() telegraf.Input {
+		return &XDP{}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package xdp
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type XDP struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*XDP) SampleConfig() string { return sampleConfig }
+
+func (x *XDP) Init() error {
+	x.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*XDP) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("xdp", func() telegraf.Input {
+		return &XDP{}
+	})
+}
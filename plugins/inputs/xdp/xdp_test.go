@@ -0,0 +1,292 @@
+//go:build linux
+
+package xdp
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf/internal/ebpf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *XDP
+		expected string
+	}{
+		{
+			name:     "no interfaces",
+			plugin:   &XDP{Rules: []*rule{{Name: "all"}}},
+			expected: "no interfaces configured",
+		},
+		{
+			name:     "no rules",
+			plugin:   &XDP{Interfaces: []string{"eth0"}},
+			expected: "no rules configured",
+		},
+		{
+			name: "invalid mode",
+			plugin: &XDP{
+				Interfaces: []string{"eth0"},
+				Mode:       "offload",
+				Rules:      []*rule{{Name: "all"}},
+			},
+			expected: `invalid mode "offload"`,
+		},
+		{
+			name: "unnamed rule",
+			plugin: &XDP{
+				Interfaces: []string{"eth0"},
+				Rules:      []*rule{{Protocol: "tcp"}},
+			},
+			expected: "rule 1 has no name",
+		},
+		{
+			name: "duplicate rule",
+			plugin: &XDP{
+				Interfaces: []string{"eth0"},
+				Rules:      []*rule{{Name: "a"}, {Name: "a"}},
+			},
+			expected: `duplicate rule name "a"`,
+		},
+		{
+			name: "invalid protocol",
+			plugin: &XDP{
+				Interfaces: []string{"eth0"},
+				Rules:      []*rule{{Name: "a", Protocol: "gre"}},
+			},
+			expected: `invalid protocol "gre"`,
+		},
+		{
+			name: "port with icmp",
+			plugin: &XDP{
+				Interfaces: []string{"eth0"},
+				Rules:      []*rule{{Name: "a", Protocol: "icmp", Port: 80}},
+			},
+			expected: `ports are not supported for protocol "icmp"`,
+		},
+		{
+			name: "port and src_port",
+			plugin: &XDP{
+				Interfaces: []string{"eth0"},
+				Rules:      []*rule{{Name: "a", Port: 80, SrcPort: 80}},
+			},
+			expected: "'port' cannot be combined",
+		},
+		{
+			name: "invalid prefix",
+			plugin: &XDP{
+				Interfaces: []string{"eth0"},
+				Rules:      []*rule{{Name: "a", Prefix: "10.0.0.0"}},
+			},
+			expected: `parsing prefix "10.0.0.0" failed`,
+		},
+		{
+			name: "mixed address families",
+			plugin: &XDP{
+				Interfaces: []string{"eth0"},
+				Rules:      []*rule{{Name: "a", SrcPrefix: "10.0.0.0/8", DstPrefix: "fd00::/8"}},
+			},
+			expected: "prefixes must be of the same address family",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInit(t *testing.T) {
+	plugin := &XDP{
+		Interfaces: []string{"eth0"},
+		Rules: []*rule{
+			{Name: "https", Protocol: "tcp", Port: 443},
+			{Name: "internal", SrcPrefix: "10.1.2.3/8"},
+			{Name: "v6", Prefix: "fd00::/8", Protocol: "udp", DstPort: 53},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "generic", plugin.Mode)
+
+	require.Equal(t, uint8(protoTCP), plugin.Rules[0].proto)
+	require.Zero(t, plugin.Rules[0].family)
+
+	require.Equal(t, uint8(4), plugin.Rules[1].family)
+	require.Equal(t, "10.0.0.0/8", plugin.Rules[1].srcNet.String())
+	require.Len(t, plugin.Rules[1].srcNet.IP, 4)
+
+	require.Equal(t, uint8(6), plugin.Rules[2].family)
+	require.Equal(t, uint8(protoUDP), plugin.Rules[2].proto)
+	require.Equal(t, "fd00::/8", plugin.Rules[2].anyNet.String())
+}
+
+func TestGenerateProgram(t *testing.T) {
+	plugin := &XDP{
+		Interfaces: []string{"eth0"},
+		Rules: []*rule{
+			{Name: "all"},
+			{Name: "https", Protocol: "tcp", Port: 443},
+			{Name: "internal", Prefix: "10.0.0.0/8"},
+			{Name: "v6", SrcPrefix: "2001:db8::/64", DstPort: 53},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	prog, err := generateProgram(plugin.Rules, 42)
+	require.NoError(t, err)
+	require.NotEmpty(t, prog)
+	require.Zero(t, len(prog)%8)
+
+	n := len(prog) / 8
+	var mapLoads int
+	for i := 0; i < n; i++ {
+		ins := prog[i*8 : (i+1)*8]
+		opcode := ins[0]
+
+		// All jumps must stay within the program
+		if opcode&0x07 == ebpf.ClassJMP && opcode != ebpf.ClassJMP|ebpf.JmpCall && opcode != ebpf.ClassJMP|ebpf.JmpExit {
+			offset := int16(binary.NativeEndian.Uint16(ins[2:4]))
+			target := i + 1 + int(offset)
+			require.GreaterOrEqual(t, target, 0, "instruction %d", i)
+			require.Less(t, target, n, "instruction %d", i)
+		}
+
+		// Each rule references the map once
+		if opcode == ebpf.ClassLD|ebpf.ModeIMM|ebpf.SizeDW {
+			require.Equal(t, uint32(42), binary.NativeEndian.Uint32(ins[4:8]))
+			mapLoads++
+			i++
+		}
+	}
+	require.Equal(t, len(plugin.Rules), mapLoads)
+
+	// The program has to pass all packets
+	require.Equal(t, uint8(ebpf.ClassJMP|ebpf.JmpExit), prog[len(prog)-8])
+	require.Equal(t, uint8(ebpf.ClassALU64|ebpf.OpMov|ebpf.SrcK), prog[len(prog)-16])
+	require.Equal(t, uint32(xdpPass), binary.NativeEndian.Uint32(prog[len(prog)-12:]))
+}
+
+func TestProgramRun(t *testing.T) {
+	plugin := &XDP{
+		Interfaces: []string{"eth0"},
+		Rules: []*rule{
+			{Name: "all"},
+			{Name: "https", Protocol: "tcp", Port: 443},
+			{Name: "dns6", Protocol: "udp", DstPort: 53, Prefix: "fd00::/8"},
+			{Name: "internal", SrcPrefix: "10.0.0.0/8"},
+			{Name: "private", DstPrefix: "192.168.0.0/16"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	mapFD, err := ebpf.CreateMap(unix.BPF_MAP_TYPE_PERCPU_ARRAY, 4, valueSize, uint32(len(plugin.Rules)))
+	if errors.Is(err, unix.EPERM) {
+		t.Skip("Skipping test as loading BPF programs requires CAP_BPF")
+	}
+	require.NoError(t, err)
+	defer unix.Close(mapFD)
+
+	prog, err := generateProgram(plugin.Rules, mapFD)
+	require.NoError(t, err)
+	progFD, err := ebpf.LoadProgram(unix.BPF_PROG_TYPE_XDP, prog, "telegraf_xdp", 0)
+	require.NoError(t, err)
+	defer unix.Close(progFD)
+
+	frames := [][]byte{
+		// IPv4/TCP to port 443 from an internal address
+		ethernet(0x0800, ipv4(net.IPv4(10, 1, 1, 1), net.IPv4(172, 16, 0, 1), protoTCP, 40000, 443, 0)),
+		// IPv6/UDP to port 53
+		ethernet(0x86dd, ipv6(net.ParseIP("2001:db8::1"), net.ParseIP("fd00::53"), protoUDP, 5353, 53)),
+		// VLAN tagged IPv4/TCP from port 443
+		ethernet(0x0800, ipv4(net.IPv4(10, 2, 2, 2), net.IPv4(1, 1, 1, 1), protoTCP, 443, 50000, 0), 0x8100),
+		// QinQ tagged IPv4/TCP to port 443
+		ethernet(0x0800, ipv4(net.IPv4(10, 3, 3, 3), net.IPv4(1, 1, 1, 1), protoTCP, 50000, 443, 0), 0x88a8, 0x8100),
+		// Non-initial fragment, the ports must be ignored
+		ethernet(0x0800, ipv4(net.IPv4(10, 4, 4, 4), net.IPv4(1, 1, 1, 1), protoTCP, 443, 443, 185)),
+		// Prefix miss for all address rules
+		ethernet(0x0800, ipv4(net.IPv4(11, 0, 0, 1), net.IPv4(192, 169, 0, 1), protoTCP, 40000, 80, 0)),
+		// Non-IP traffic
+		ethernet(0x0806, make([]byte, 28)),
+	}
+
+	var total uint64
+	for i, frame := range frames {
+		retval, err := ebpf.RunProgram(progFD, frame)
+		require.NoError(t, err, "frame %d", i)
+		require.Equal(t, uint32(xdpPass), retval, "frame %d", i)
+		total += uint64(len(frame))
+	}
+
+	ncpu, err := ebpf.PossibleCPUs()
+	require.NoError(t, err)
+	p := &probe{mapFD: mapFD}
+	counters, err := p.counters(len(plugin.Rules), ncpu)
+	require.NoError(t, err)
+
+	size := func(idx ...int) uint64 {
+		var sum uint64
+		for _, i := range idx {
+			sum += uint64(len(frames[i]))
+		}
+		return sum
+	}
+	expected := []counter{
+		{packets: 7, bytes: total},
+		{packets: 3, bytes: size(0, 2, 3)},
+		{packets: 1, bytes: size(1)},
+		{packets: 4, bytes: size(0, 2, 3, 4)},
+		{packets: 0, bytes: 0},
+	}
+	require.Equal(t, expected, counters)
+}
+
+func ethernet(ethertype uint16, payload []byte, tpids ...uint16) []byte {
+	buf := []byte{0x02, 0, 0, 0, 0, 0x01, 0x02, 0, 0, 0, 0, 0x02}
+	for i, tpid := range tpids {
+		buf = binary.BigEndian.AppendUint16(buf, tpid)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(100+i))
+	}
+	buf = binary.BigEndian.AppendUint16(buf, ethertype)
+	return append(buf, payload...)
+}
+
+func ipv4(src, dst net.IP, proto uint8, sport, dport, fragOffset uint16) []byte {
+	l4 := transport(sport, dport)
+	buf := []byte{0x45, 0}
+	buf = binary.BigEndian.AppendUint16(buf, uint16(20+len(l4)))
+	buf = append(buf, 0, 0)
+	buf = binary.BigEndian.AppendUint16(buf, fragOffset&0x1fff)
+	buf = append(buf, 64, proto, 0, 0)
+	buf = append(buf, src.To4()...)
+	buf = append(buf, dst.To4()...)
+	return append(buf, l4...)
+}
+
+func ipv6(src, dst net.IP, proto uint8, sport, dport uint16) []byte {
+	l4 := transport(sport, dport)
+	buf := []byte{0x60, 0, 0, 0}
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(l4)))
+	buf = append(buf, proto, 64)
+	buf = append(buf, src.To16()...)
+	buf = append(buf, dst.To16()...)
+	return append(buf, l4...)
+}
+
+func transport(sport, dport uint16) []byte {
+	buf := binary.BigEndian.AppendUint16(nil, sport)
+	buf = binary.BigEndian.AppendUint16(buf, dport)
+	return append(buf, make([]byte, 16)...)
+}
@@ -13,6 +13,7 @@ var sampleConfig string
 type Sysctl func(metric string) ([]string, error)
 type Zpool func() ([]string, error)
 type Zdataset func(properties []string) ([]string, error)
+type ZpoolJSON func(command string) ([]byte, error)
 type Uname func() (string, error)
 
 type Zfs struct {
@@ -20,13 +21,16 @@ type Zfs struct {
 	KstatMetrics   []string
 	PoolMetrics    bool
 	DatasetMetrics bool
+	VdevMetrics    bool
 	Log            telegraf.Logger `toml:"-"`
 
 	sysctl   Sysctl   //nolint:unused // False positive - this var is used for non-default build tag: freebsd
 	zpool    Zpool    //nolint:unused // False positive - this var is used for non-default build tag: freebsd
-	zdataset Zdataset //nolint:unused // False positive - this var is used for non-default build tag: freebsd
+	zdataset Zdataset //nolint:unused // False positive - this var is used for non-default build tags: freebsd, linux
 	uname    Uname    //nolint:unused // False positive - this var is used for non-default build tag: freebsd
 	version  int64    //nolint:unused // False positive - this var is used for non-default build tag: freebsd
+
+	zpoolJSON ZpoolJSON //nolint:unused // False positive - this var is used for non-default build tag: linux
 }
 
 func (*Zfs) SampleConfig() string {
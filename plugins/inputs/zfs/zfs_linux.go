@@ -3,6 +3,8 @@
 package zfs
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -233,11 +235,205 @@ func (z *Zfs) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 	acc.AddFields("zfs", fields, tags)
+
+	if z.DatasetMetrics {
+		if err := z.gatherDatasetStats(acc); err != nil {
+			return err
+		}
+	}
+
+	if z.VdevMetrics {
+		if err := z.gatherVdevStats(acc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (z *Zfs) gatherDatasetStats(acc telegraf.Accumulator) error {
+	properties := []string{"name", "avail", "used", "usedsnap", "usedds", "compressratio"}
+
+	lines, err := z.zdataset(properties)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		col := strings.Split(line, "\t")
+		if len(col) != len(properties) {
+			z.Log.Warnf("Invalid number of columns for line: %s", line)
+			continue
+		}
+
+		tags := map[string]string{"dataset": col[0]}
+		fields := make(map[string]interface{}, len(properties)-1)
+
+		for i, key := range properties[1:] {
+			raw := col[i+1]
+			if key == "compressratio" {
+				value, err := strconv.ParseFloat(strings.TrimSuffix(raw, "x"), 64)
+				if err != nil {
+					return fmt.Errorf("parsing %s %q failed: %w", key, raw, err)
+				}
+				fields[key] = value
+				continue
+			}
+
+			// Treat '-' entries as zero
+			if raw == "-" {
+				fields[key] = int64(0)
+				continue
+			}
+			value, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("parsing %s %q failed: %w", key, raw, err)
+			}
+			fields[key] = value
+		}
+
+		acc.AddFields("zfs_dataset", fields, tags)
+	}
+
+	return nil
+}
+
+// zpoolOutput is used to unmarshal the JSON output of 'zpool status' and
+// 'zpool iostat'
+type zpoolOutput struct {
+	Pools map[string]map[string]json.RawMessage `json:"pools"`
+}
+
+// Keys of the pool object containing vdev trees
+var vdevGroups = []string{"vdevs", "dedup", "special", "logs", "l2cache", "spares"}
+
+// vdevEntry holds the properties of a vdev collected from the different
+// zpool commands
+type vdevEntry struct {
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+func (z *Zfs) gatherVdevStats(acc telegraf.Accumulator) error {
+	vdevs := make(map[string]*vdevEntry)
+
+	for _, command := range []string{"status", "iostat"} {
+		buf, err := z.zpoolJSON(command)
+		if err != nil {
+			return err
+		}
+
+		var output zpoolOutput
+		if err := json.Unmarshal(buf, &output); err != nil {
+			return fmt.Errorf("parsing 'zpool %s' output failed: %w", command, err)
+		}
+
+		for pool, entries := range output.Pools {
+			for _, group := range vdevGroups {
+				raw, found := entries[group]
+				if !found {
+					continue
+				}
+				// Decode numbers as json.Number to keep the precision of
+				// large counters
+				var tree map[string]map[string]interface{}
+				decoder := json.NewDecoder(bytes.NewReader(raw))
+				decoder.UseNumber()
+				if err := decoder.Decode(&tree); err != nil {
+					return fmt.Errorf("parsing %q of pool %q in 'zpool %s' output failed: %w", group, pool, command, err)
+				}
+				for name, vdev := range tree {
+					collectVdevs(vdevs, pool, name, vdev)
+				}
+			}
+		}
+	}
+
+	for _, v := range vdevs {
+		acc.AddFields("zfs_vdev", v.fields, v.tags)
+	}
+
 	return nil
 }
 
+// collectVdevs recursively merges the given vdev and its children into the
+// collection of vdevs keyed by pool and vdev name
+func collectVdevs(vdevs map[string]*vdevEntry, pool, name string, vdev map[string]interface{}) {
+	key := pool + "/" + name
+	entry, found := vdevs[key]
+	if !found {
+		entry = &vdevEntry{
+			tags:   map[string]string{"pool": pool, "vdev": name},
+			fields: make(map[string]interface{}),
+		}
+		vdevs[key] = entry
+	}
+
+	for k, v := range vdev {
+		switch k {
+		case "name", "guid", "path", "phys_path", "devid", "class":
+			continue
+		case "vdev_type":
+			if s, ok := v.(string); ok {
+				entry.tags["type"] = s
+			}
+			continue
+		case "state":
+			if s, ok := v.(string); ok {
+				entry.tags["state"] = s
+			}
+			continue
+		case "vdevs":
+			children, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for childName, child := range children {
+				if c, ok := child.(map[string]interface{}); ok {
+					collectVdevs(vdevs, pool, childName, c)
+				}
+			}
+			continue
+		}
+
+		// Values are strings unless '--json-int' is supported, skip all
+		// non-numeric values like '-' for unavailable latencies
+		switch value := v.(type) {
+		case json.Number:
+			if i, err := value.Int64(); err == nil {
+				entry.fields[k] = i
+			}
+		case string:
+			if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+				entry.fields[k] = i
+			}
+		}
+	}
+}
+
+func zpoolJSON(command string) ([]byte, error) {
+	var args []string
+	switch command {
+	case "status":
+		args = []string{"status", "-j", "-p", "--json-int"}
+	case "iostat":
+		args = []string{"iostat", "-j", "-l", "-p", "-v", "--json-int"}
+	default:
+		return nil, fmt.Errorf("unknown zpool command %q", command)
+	}
+
+	lines, err := run("zpool", args...)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
 func init() {
 	inputs.Add("zfs", func() telegraf.Input {
-		return &Zfs{}
+		return &Zfs{
+			zdataset:  zdataset,
+			zpoolJSON: zpoolJSON,
+		}
 	})
 }
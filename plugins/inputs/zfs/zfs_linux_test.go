@@ -3,11 +3,15 @@
 package zfs
 
 import (
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -360,6 +364,233 @@ func TestZfsGeneratesMetrics(t *testing.T) {
 	acc3.AssertContainsTaggedFields(t, "zfs", intMetrics, tags)
 }
 
+func TestZfsDatasetMetrics(t *testing.T) {
+	z := &Zfs{
+		KstatPath:      t.TempDir(),
+		DatasetMetrics: true,
+		zdataset: func(properties []string) ([]string, error) {
+			require.Equal(t, []string{"name", "avail", "used", "usedsnap", "usedds", "compressratio"}, properties)
+			return []string{
+				"rpool\t10741741326336\t8564135526400\t0\t90112\t1.52",
+				"rpool/data\t10741741326336\t-\t0\t24576\t1.00x",
+			}, nil
+		},
+		Log: testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, z.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"zfs_dataset",
+			map[string]string{"dataset": "rpool"},
+			map[string]interface{}{
+				"avail":         int64(10741741326336),
+				"used":          int64(8564135526400),
+				"usedsnap":      int64(0),
+				"usedds":        int64(90112),
+				"compressratio": 1.52,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"zfs_dataset",
+			map[string]string{"dataset": "rpool/data"},
+			map[string]interface{}{
+				"avail":         int64(10741741326336),
+				"used":          int64(0),
+				"usedsnap":      int64(0),
+				"usedds":        int64(24576),
+				"compressratio": 1.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	actual := acc.GetTelegrafMetrics()
+	filtered := make([]telegraf.Metric, 0, len(actual))
+	for _, m := range actual {
+		if m.Name() == "zfs_dataset" {
+			filtered = append(filtered, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, filtered, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+const zpoolStatusJSON = `{
+  "output_version": {"command": "zpool status", "vers_major": 0, "vers_minor": 1},
+  "pools": {
+    "tank": {
+      "name": "tank",
+      "state": "ONLINE",
+      "pool_guid": "12318466238633474939",
+      "vdevs": {
+        "tank": {
+          "name": "tank",
+          "vdev_type": "root",
+          "guid": "12318466238633474939",
+          "state": "ONLINE",
+          "alloc_space": 1578590208,
+          "total_space": 66035122176,
+          "read_errors": 0,
+          "write_errors": 0,
+          "checksum_errors": 0,
+          "vdevs": {
+            "mirror-0": {
+              "name": "mirror-0",
+              "vdev_type": "mirror",
+              "guid": "4482713414283937420",
+              "state": "DEGRADED",
+              "read_errors": 0,
+              "write_errors": 0,
+              "checksum_errors": 0,
+              "vdevs": {
+                "sda": {
+                  "name": "sda",
+                  "vdev_type": "disk",
+                  "guid": "1736479911812134224",
+                  "path": "/dev/sda1",
+                  "state": "FAULTED",
+                  "read_errors": 3,
+                  "write_errors": 1,
+                  "checksum_errors": 7
+                }
+              }
+            }
+          }
+        }
+      },
+      "error_count": "0"
+    }
+  }
+}`
+
+const zpoolIostatJSON = `{
+  "output_version": {"command": "zpool iostat", "vers_major": 0, "vers_minor": 1},
+  "pools": {
+    "tank": {
+      "name": "tank",
+      "state": "ONLINE",
+      "vdevs": {
+        "tank": {
+          "name": "tank",
+          "vdev_type": "root",
+          "read_ops": 120,
+          "write_ops": 340,
+          "read_bytes": 491520,
+          "write_bytes": 1392640,
+          "total_wait_read": 512000,
+          "total_wait_write": 1024000,
+          "vdevs": {
+            "mirror-0": {
+              "name": "mirror-0",
+              "vdev_type": "mirror",
+              "read_ops": 120,
+              "write_ops": 340,
+              "read_bytes": 491520,
+              "write_bytes": 1392640,
+              "total_wait_read": 512000,
+              "total_wait_write": 1024000,
+              "vdevs": {
+                "sda": {
+                  "name": "sda",
+                  "vdev_type": "disk",
+                  "read_ops": 120,
+                  "write_ops": 340,
+                  "read_bytes": 491520,
+                  "write_bytes": 1392640,
+                  "total_wait_read": "-",
+                  "total_wait_write": 1024000
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestZfsVdevMetrics(t *testing.T) {
+	z := &Zfs{
+		KstatPath:   t.TempDir(),
+		VdevMetrics: true,
+		zpoolJSON: func(command string) ([]byte, error) {
+			switch command {
+			case "status":
+				return []byte(zpoolStatusJSON), nil
+			case "iostat":
+				return []byte(zpoolIostatJSON), nil
+			}
+			return nil, errors.New("unexpected command")
+		},
+		Log: testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, z.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"zfs_vdev",
+			map[string]string{"pool": "tank", "vdev": "tank", "type": "root", "state": "ONLINE"},
+			map[string]interface{}{
+				"alloc_space":      int64(1578590208),
+				"total_space":      int64(66035122176),
+				"read_errors":      int64(0),
+				"write_errors":     int64(0),
+				"checksum_errors":  int64(0),
+				"read_ops":         int64(120),
+				"write_ops":        int64(340),
+				"read_bytes":       int64(491520),
+				"write_bytes":      int64(1392640),
+				"total_wait_read":  int64(512000),
+				"total_wait_write": int64(1024000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"zfs_vdev",
+			map[string]string{"pool": "tank", "vdev": "mirror-0", "type": "mirror", "state": "DEGRADED"},
+			map[string]interface{}{
+				"read_errors":      int64(0),
+				"write_errors":     int64(0),
+				"checksum_errors":  int64(0),
+				"read_ops":         int64(120),
+				"write_ops":        int64(340),
+				"read_bytes":       int64(491520),
+				"write_bytes":      int64(1392640),
+				"total_wait_read":  int64(512000),
+				"total_wait_write": int64(1024000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"zfs_vdev",
+			map[string]string{"pool": "tank", "vdev": "sda", "type": "disk", "state": "FAULTED"},
+			map[string]interface{}{
+				"read_errors":      int64(3),
+				"write_errors":     int64(1),
+				"checksum_errors":  int64(7),
+				"read_ops":         int64(120),
+				"write_ops":        int64(340),
+				"read_bytes":       int64(491520),
+				"write_bytes":      int64(1392640),
+				"total_wait_write": int64(1024000),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	actual := acc.GetTelegrafMetrics()
+	filtered := make([]telegraf.Metric, 0, len(actual))
+	for _, m := range actual {
+		if m.Name() == "zfs_vdev" {
+			filtered = append(filtered, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, filtered, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
 func TestGetTags(t *testing.T) {
 	tests := []struct {
 		name     string
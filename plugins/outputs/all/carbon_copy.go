@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.carbon_copy
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/carbon_copy" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.rabbitmq_stream
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/rabbitmq_stream" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.textfile
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/textfile" // register plugin
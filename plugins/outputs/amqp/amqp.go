@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"text/template"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -54,6 +55,9 @@ type AMQP struct {
 	AuthMethod         string            `toml:"auth_method"`
 	RoutingTag         string            `toml:"routing_tag"`
 	RoutingKey         string            `toml:"routing_key"`
+	HeaderTags         []string          `toml:"header_tags"`
+	PublisherConfirms  bool              `toml:"publisher_confirms"`
+	ConfirmTimeout     config.Duration   `toml:"confirm_timeout"`
 	DeliveryMode       string            `toml:"delivery_mode"`
 	Database           string            `toml:"database" deprecated:"1.7.0;1.35.0;use 'headers' instead"`
 	RetentionPolicy    string            `toml:"retention_policy" deprecated:"1.7.0;1.35.0;use 'headers' instead"`
@@ -62,23 +66,32 @@ type AMQP struct {
 	Timeout            config.Duration   `toml:"timeout"`
 	UseBatchFormat     bool              `toml:"use_batch_format"`
 	ContentEncoding    string            `toml:"content_encoding"`
+	CompressionLevel   *int              `toml:"compression_level"`
 	Log                telegraf.Logger   `toml:"-"`
 	tls.ClientConfig
 	proxy.TCPProxy
 
-	serializer   telegraf.Serializer
-	connect      func(*ClientConfig) (Client, error)
-	client       Client
-	config       *ClientConfig
-	sentMessages int
-	encoder      internal.ContentEncoder
+	serializer     telegraf.Serializer
+	connect        func(*ClientConfig) (Client, error)
+	client         Client
+	config         *ClientConfig
+	sentMessages   int
+	encoder        internal.ContentEncoder
+	routingKeyTmpl *template.Template
 }
 
 type Client interface {
-	Publish(key string, body []byte) error
+	Publish(key string, headers amqp.Table, body []byte) error
 	Close() error
 }
 
+// batch contains the metrics published in a single message
+type batch struct {
+	key     string
+	headers amqp.Table
+	metrics []telegraf.Metric
+}
+
 func (*AMQP) SampleConfig() string {
 	return sampleConfig
 }
@@ -88,13 +101,29 @@ func (q *AMQP) SetSerializer(serializer telegraf.Serializer) {
 }
 
 func (q *AMQP) Init() error {
+	if q.PublisherConfirms && q.ConfirmTimeout <= 0 {
+		return errors.New("'confirm_timeout' must be positive")
+	}
+
+	if strings.Contains(q.RoutingKey, "{{") {
+		tmpl, err := template.New("routing_key").Parse(q.RoutingKey)
+		if err != nil {
+			return fmt.Errorf("parsing routing_key template failed: %w", err)
+		}
+		q.routingKeyTmpl = tmpl
+	}
+
 	var err error
 	q.config, err = q.makeClientConfig()
 	if err != nil {
 		return err
 	}
 
-	q.encoder, err = internal.NewContentEncoder(q.ContentEncoding)
+	var options []internal.EncodingOption
+	if q.CompressionLevel != nil {
+		options = append(options, internal.WithCompressionLevel(*q.CompressionLevel))
+	}
+	q.encoder, err = internal.NewContentEncoder(q.ContentEncoding, options...)
 	if err != nil {
 		return err
 	}
@@ -115,36 +144,83 @@ func (q *AMQP) Close() error {
 	return nil
 }
 
-func (q *AMQP) routingKey(metric telegraf.Metric) string {
+func (q *AMQP) routingKey(metric telegraf.Metric) (string, error) {
 	if q.RoutingTag != "" {
 		key, ok := metric.GetTag(q.RoutingTag)
 		if ok {
-			return key
+			return key, nil
+		}
+	}
+	if q.routingKeyTmpl == nil {
+		return q.RoutingKey, nil
+	}
+
+	if wm, ok := metric.(telegraf.UnwrappableMetric); ok {
+		metric = wm.Unwrap()
+	}
+	m, ok := metric.(telegraf.TemplateMetric)
+	if !ok {
+		return "", fmt.Errorf("metric of type %T is not a template metric", metric)
+	}
+	var b strings.Builder
+	if err := q.routingKeyTmpl.Execute(&b, m); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// headers returns the headers of the message containing the metric and an
+// identifier of the header values for grouping the metrics into messages
+func (q *AMQP) headers(metric telegraf.Metric) (amqp.Table, string) {
+	if len(q.HeaderTags) == 0 {
+		return q.config.headers, ""
+	}
+
+	headers := make(amqp.Table, len(q.config.headers)+len(q.HeaderTags))
+	for k, v := range q.config.headers {
+		headers[k] = v
+	}
+	var id strings.Builder
+	for _, key := range q.HeaderTags {
+		if value, ok := metric.GetTag(key); ok {
+			headers[key] = value
+			id.WriteString(key + "=" + value + "\n")
 		}
 	}
-	return q.RoutingKey
+	return headers, id.String()
 }
 
 func (q *AMQP) Write(metrics []telegraf.Metric) error {
-	batches := make(map[string][]telegraf.Metric)
-	if q.ExchangeType == "header" {
-		// Since the routing_key is ignored for this exchange type send as a
-		// single batch.
-		batches[""] = metrics
-	} else {
-		for _, metric := range metrics {
-			routingKey := q.routingKey(metric)
-			if _, ok := batches[routingKey]; !ok {
-				batches[routingKey] = make([]telegraf.Metric, 0)
+	batches := make(map[string]*batch)
+	var order []string
+	for _, metric := range metrics {
+		// Since the routing_key is ignored for this exchange type leave it
+		// empty to only group by headers.
+		var key string
+		if q.ExchangeType != "header" {
+			var err error
+			key, err = q.routingKey(metric)
+			if err != nil {
+				q.Log.Errorf("Creating routing key for metric %q failed: %v", metric.Name(), err)
+				continue
 			}
-
-			batches[routingKey] = append(batches[routingKey], metric)
 		}
+		headers, headersID := q.headers(metric)
+
+		id := key + "\n" + headersID
+		b, ok := batches[id]
+		if !ok {
+			b = &batch{key: key, headers: headers}
+			batches[id] = b
+			order = append(order, id)
+		}
+		b.metrics = append(b.metrics, metric)
 	}
 
 	first := true
-	for key, metrics := range batches {
-		body, err := q.serialize(metrics)
+	for _, id := range order {
+		b := batches[id]
+		body, err := q.serialize(b.metrics)
 		if err != nil {
 			return err
 		}
@@ -154,15 +230,21 @@ func (q *AMQP) Write(metrics []telegraf.Metric) error {
 			return err
 		}
 
-		err = q.publish(key, body)
+		err = q.publish(b.key, b.headers, body)
 		if err != nil {
+			// The broker rejected the message, so keep the connection and
+			// retry the whole batch with the next write.
+			if errors.Is(err, errNack) {
+				return err
+			}
+
 			// If this is the first attempt to publish and the connection is
 			// closed, try to reconnect and retry once.
 
 			var aerr *amqp.Error
 			if first && errors.As(err, &aerr) && errors.Is(aerr, amqp.ErrClosed) {
 				q.client = nil
-				err := q.publish(key, body)
+				err := q.publish(b.key, b.headers, body)
 				if err != nil {
 					return err
 				}
@@ -188,7 +270,7 @@ func (q *AMQP) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
-func (q *AMQP) publish(key string, body []byte) error {
+func (q *AMQP) publish(key string, headers amqp.Table, body []byte) error {
 	if q.client == nil {
 		client, err := q.connect(q.config)
 		if err != nil {
@@ -198,7 +280,7 @@ func (q *AMQP) publish(key string, body []byte) error {
 		q.client = client
 	}
 
-	err := q.client.Publish(key, body)
+	err := q.client.Publish(key, headers, body)
 	if err != nil {
 		return err
 	}
@@ -231,6 +313,8 @@ func (q *AMQP) makeClientConfig() (*ClientConfig, error) {
 		exchangePassive: q.ExchangePassive,
 		encoding:        q.ContentEncoding,
 		timeout:         time.Duration(q.Timeout),
+		confirm:         q.PublisherConfirms,
+		confirmTimeout:  time.Duration(q.ConfirmTimeout),
 		log:             q.Log,
 	}
 
@@ -327,8 +411,9 @@ func init() {
 				"database":         DefaultDatabase,
 				"retention_policy": DefaultRetentionPolicy,
 			},
-			Timeout: config.Duration(time.Second * 5),
-			connect: connect,
+			Timeout:        config.Duration(time.Second * 5),
+			ConfirmTimeout: config.Duration(time.Second * 5),
+			connect:        connect,
 		}
 	})
 }
@@ -7,7 +7,11 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 type MockClient struct {
@@ -16,11 +20,23 @@ type MockClient struct {
 
 	PublishCallCount int
 	CloseCallCount   int
+
+	Messages []message
+}
+
+type message struct {
+	key     string
+	headers amqp.Table
+	body    string
 }
 
-func (c *MockClient) Publish(string, []byte) error {
+func (c *MockClient) Publish(key string, headers amqp.Table, body []byte) error {
 	c.PublishCallCount++
-	return c.PublishF()
+	if err := c.PublishF(); err != nil {
+		return err
+	}
+	c.Messages = append(c.Messages, message{key: key, headers: headers, body: string(body)})
+	return nil
 }
 
 func (c *MockClient) Close() error {
@@ -158,3 +174,179 @@ func TestConnect(t *testing.T) {
 		})
 	}
 }
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   *AMQP
+		expected string
+	}{
+		{
+			name:     "invalid routing key template",
+			output:   &AMQP{RoutingKey: "telegraf.{{.Tag \"host\"}"},
+			expected: "parsing routing_key template failed",
+		},
+		{
+			name:     "confirms without timeout",
+			output:   &AMQP{PublisherConfirms: true},
+			expected: "'confirm_timeout' must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.output.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWriteRouting(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b", "region": "eu"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 4}, time.Unix(1, 0)),
+		metric.New("disk", map[string]string{"device": "sda"}, map[string]interface{}{"value": 5}, time.Unix(0, 0)),
+	}
+
+	tests := []struct {
+		name         string
+		exchangeType string
+		routingTag   string
+		routingKey   string
+		headerTags   []string
+		expected     []message
+	}{
+		{
+			name:       "routing key template",
+			routingKey: `telegraf.{{.Tag "host"}}.{{.Name}}`,
+			expected: []message{
+				{
+					key:     "telegraf.a.cpu",
+					headers: amqp.Table{"database": "telegraf"},
+					body:    "cpu,host=a value=1i 0\ncpu,host=a value=4i 1000000000\n",
+				},
+				{
+					key:     "telegraf.b.cpu",
+					headers: amqp.Table{"database": "telegraf"},
+					body:    "cpu,host=b,region=eu value=2i 0\n",
+				},
+				{
+					key:     "telegraf.a.mem",
+					headers: amqp.Table{"database": "telegraf"},
+					body:    "mem,host=a value=3i 0\n",
+				},
+				{
+					key:     "telegraf..disk",
+					headers: amqp.Table{"database": "telegraf"},
+					body:    "disk,device=sda value=5i 0\n",
+				},
+			},
+		},
+		{
+			name:       "routing tag with template fallback",
+			routingTag: "device",
+			routingKey: "telegraf.{{.Name}}",
+			expected: []message{
+				{
+					key:     "telegraf.cpu",
+					headers: amqp.Table{"database": "telegraf"},
+					body:    "cpu,host=a value=1i 0\ncpu,host=b,region=eu value=2i 0\ncpu,host=a value=4i 1000000000\n",
+				},
+				{
+					key:     "telegraf.mem",
+					headers: amqp.Table{"database": "telegraf"},
+					body:    "mem,host=a value=3i 0\n",
+				},
+				{
+					key:     "sda",
+					headers: amqp.Table{"database": "telegraf"},
+					body:    "disk,device=sda value=5i 0\n",
+				},
+			},
+		},
+		{
+			name:         "header tags",
+			exchangeType: "header",
+			headerTags:   []string{"host", "region", "database"},
+			expected: []message{
+				{
+					headers: amqp.Table{"database": "telegraf", "host": "a"},
+					body:    "cpu,host=a value=1i 0\nmem,host=a value=3i 0\ncpu,host=a value=4i 1000000000\n",
+				},
+				{
+					headers: amqp.Table{"database": "telegraf", "host": "b", "region": "eu"},
+					body:    "cpu,host=b,region=eu value=2i 0\n",
+				},
+				{
+					headers: amqp.Table{"database": "telegraf"},
+					body:    "disk,device=sda value=5i 0\n",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockClient{
+				PublishF: func() error { return nil },
+				CloseF:   func() error { return nil },
+			}
+			plugin := &AMQP{
+				ExchangeType: tt.exchangeType,
+				RoutingTag:   tt.routingTag,
+				RoutingKey:   tt.routingKey,
+				HeaderTags:   tt.headerTags,
+				Headers:      map[string]string{"database": "telegraf"},
+				Log:          testutil.Logger{},
+				connect: func(*ClientConfig) (Client, error) {
+					return client, nil
+				},
+			}
+			serializer := &influx.Serializer{}
+			require.NoError(t, serializer.Init())
+			plugin.SetSerializer(serializer)
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+
+			require.NoError(t, plugin.Write(metrics))
+			require.Equal(t, tt.expected, client.Messages)
+		})
+	}
+}
+
+func TestWriteNack(t *testing.T) {
+	client := &MockClient{
+		PublishF: func() error { return errNack },
+		CloseF:   func() error { return nil },
+	}
+
+	plugin := &AMQP{
+		PublisherConfirms: true,
+		ConfirmTimeout:    config.Duration(time.Second),
+		Log:               testutil.Logger{},
+		connect: func(*ClientConfig) (Client, error) {
+			return client, nil
+		},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	require.True(t, plugin.config.confirm)
+	require.Equal(t, time.Second, plugin.config.confirmTimeout)
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+	}
+
+	// A rejected message must fail the write without dropping the connection
+	require.ErrorIs(t, plugin.Write(metrics), errNack)
+	require.Equal(t, 0, client.CloseCallCount)
+	require.NotNil(t, plugin.client)
+
+	client.PublishF = func() error {
+		return nil
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Len(t, client.Messages, 1)
+}
@@ -27,11 +27,15 @@ type ClientConfig struct {
 	deliveryMode      uint8
 	tlsConfig         *tls.Config
 	timeout           time.Duration
+	confirm           bool
+	confirmTimeout    time.Duration
 	auth              []amqp.Authentication
 	dialer            *proxy.ProxiedDialer
 	log               telegraf.Logger
 }
 
+var errNack = errors.New("message was rejected by the broker")
+
 type client struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
@@ -74,6 +78,12 @@ func newClient(config *ClientConfig) (*client, error) {
 	}
 	client.channel = channel
 
+	if config.confirm {
+		if err := channel.Confirm(false); err != nil {
+			return nil, fmt.Errorf("enabling publisher confirms failed: %w", err)
+		}
+	}
+
 	err = client.DeclareExchange()
 	if err != nil {
 		return nil, err
@@ -115,22 +125,50 @@ func (c *client) DeclareExchange() error {
 	return nil
 }
 
-func (c *client) Publish(key string, body []byte) error {
-	// Note that since the channel is not in confirm mode, the absence of
-	// an error does not indicate successful delivery.
-	return c.channel.PublishWithContext(
+func (c *client) Publish(key string, headers amqp.Table, body []byte) error {
+	msg := amqp.Publishing{
+		Headers:         headers,
+		ContentType:     "text/plain",
+		ContentEncoding: c.config.encoding,
+		Body:            body,
+		DeliveryMode:    c.config.deliveryMode,
+	}
+
+	if !c.config.confirm {
+		// Note that since the channel is not in confirm mode, the absence of
+		// an error does not indicate successful delivery.
+		return c.channel.PublishWithContext(
+			context.Background(),
+			c.config.exchange, // exchange
+			key,               // routing key
+			false,             // mandatory
+			false,             // immediate
+			msg,
+		)
+	}
+
+	confirmation, err := c.channel.PublishWithDeferredConfirmWithContext(
 		context.Background(),
 		c.config.exchange, // exchange
 		key,               // routing key
 		false,             // mandatory
 		false,             // immediate
-		amqp.Publishing{
-			Headers:         c.config.headers,
-			ContentType:     "text/plain",
-			ContentEncoding: c.config.encoding,
-			Body:            body,
-			DeliveryMode:    c.config.deliveryMode,
-		})
+		msg,
+	)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.confirmTimeout)
+	defer cancel()
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("waiting for publisher confirmation failed: %w", err)
+	}
+	if !acked {
+		return errNack
+	}
+	return nil
 }
 
 func (c *client) Close() error {
@@ -0,0 +1,299 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package azure_monitor_logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	apiVersion = "2023-01-01"
+	// Maximum size of a single call to the Logs Ingestion API
+	maxRequestBodySize = 1024 * 1024
+)
+
+type AzureMonitorLogs struct {
+	Endpoint     string          `toml:"endpoint"`
+	RuleID       string          `toml:"dcr_immutable_id"`
+	Stream       string          `toml:"stream"`
+	TenantID     string          `toml:"tenant_id"`
+	ClientID     string          `toml:"client_id"`
+	ClientSecret config.Secret   `toml:"client_secret"`
+	Audience     string          `toml:"audience"`
+	TimeColumn   string          `toml:"time_column"`
+	NameColumn   string          `toml:"name_column"`
+	TagsColumn   string          `toml:"tags_column"`
+	FieldsColumn string          `toml:"fields_column"`
+	MaxBatchSize config.Size     `toml:"max_batch_size"`
+	Timeout      config.Duration `toml:"timeout"`
+	Log          telegraf.Logger `toml:"-"`
+
+	url        string
+	scopes     []string
+	credential azcore.TokenCredential
+	client     *http.Client
+}
+
+func (*AzureMonitorLogs) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *AzureMonitorLogs) Init() error {
+	if a.Endpoint == "" {
+		return errors.New("endpoint required")
+	}
+	if a.RuleID == "" {
+		return errors.New("dcr_immutable_id required")
+	}
+	if a.Stream == "" {
+		return errors.New("stream required")
+	}
+	if a.TimeColumn == "" {
+		return errors.New("time_column required")
+	}
+	if a.MaxBatchSize <= 0 || a.MaxBatchSize > maxRequestBodySize {
+		return fmt.Errorf("max_batch_size has to be between 1 and %d bytes", maxRequestBodySize)
+	}
+
+	a.url = fmt.Sprintf("%s/dataCollectionRules/%s/streams/%s?api-version=%s",
+		strings.TrimSuffix(a.Endpoint, "/"), url.PathEscape(a.RuleID), url.PathEscape(a.Stream), apiVersion)
+	a.scopes = []string{strings.TrimSuffix(a.Audience, "/") + "/.default"}
+
+	// Use the service principal if configured and fall back to the default
+	// credential chain (environment, workload identity, managed identity,
+	// Azure CLI) otherwise
+	if a.credential != nil {
+		return nil
+	}
+	if a.ClientSecret.Empty() {
+		cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{TenantID: a.TenantID})
+		if err != nil {
+			return fmt.Errorf("creating default credential failed: %w", err)
+		}
+		a.credential = cred
+		return nil
+	}
+
+	if a.TenantID == "" || a.ClientID == "" {
+		return errors.New("tenant_id and client_id required when using a client secret")
+	}
+	secret, err := a.ClientSecret.Get()
+	if err != nil {
+		return fmt.Errorf("getting client secret failed: %w", err)
+	}
+	defer secret.Destroy()
+	cred, err := azidentity.NewClientSecretCredential(a.TenantID, a.ClientID, secret.String(), nil)
+	if err != nil {
+		return fmt.Errorf("creating client secret credential failed: %w", err)
+	}
+	a.credential = cred
+
+	return nil
+}
+
+func (a *AzureMonitorLogs) Connect() error {
+	a.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+		},
+		Timeout: time.Duration(a.Timeout),
+	}
+	return nil
+}
+
+func (a *AzureMonitorLogs) Close() error {
+	if a.client != nil {
+		a.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Write sends the metrics as log records in batches limited in size
+func (a *AzureMonitorLogs) Write(metrics []telegraf.Metric) error {
+	writeErr := &internal.PartialWriteError{
+		MetricsAccept: make([]int, 0, len(metrics)),
+	}
+
+	var buffer bytes.Buffer
+	batchIndices := make([]int, 0, len(metrics))
+	for i, m := range metrics {
+		record, err := json.Marshal(a.record(m))
+		if err != nil {
+			a.Log.Errorf("Could not serialize metric %q: %v", m.Name(), err)
+			writeErr.Err = fmt.Errorf("serializing metric failed: %w", err)
+			writeErr.MetricsReject = append(writeErr.MetricsReject, i)
+			continue
+		}
+
+		// The records are sent as JSON array, account for the brackets and
+		// the separating comma
+		if len(record)+2 > int(a.MaxBatchSize) {
+			a.Log.Errorf("Metric %q exceeds the maximum batch size of %d bytes; discarding", m.Name(), a.MaxBatchSize)
+			writeErr.Err = errors.New("metric(s) exceeding maximum batch size")
+			writeErr.MetricsReject = append(writeErr.MetricsReject, i)
+			continue
+		}
+		if buffer.Len() > 0 && buffer.Len()+len(record)+2 > int(a.MaxBatchSize) {
+			if err := a.flush(&buffer, batchIndices, writeErr); err != nil {
+				return err
+			}
+			batchIndices = batchIndices[:0]
+		}
+
+		if buffer.Len() == 0 {
+			buffer.WriteByte('[')
+		} else {
+			buffer.WriteByte(',')
+		}
+		buffer.Write(record)
+		batchIndices = append(batchIndices, i)
+	}
+
+	if buffer.Len() > 0 {
+		if err := a.flush(&buffer, batchIndices, writeErr); err != nil {
+			return err
+		}
+	}
+
+	if writeErr.Err == nil {
+		return nil
+	}
+	return writeErr
+}
+
+// flush sends the buffered records and updates the accepted and rejected
+// metrics accordingly. An error is returned if the write should be retried.
+func (a *AzureMonitorLogs) flush(buffer *bytes.Buffer, indices []int, writeErr *internal.PartialWriteError) error {
+	buffer.WriteByte(']')
+	retryable, err := a.send(buffer.Bytes())
+	buffer.Reset()
+	if err != nil {
+		writeErr.Err = err
+		if retryable {
+			return writeErr
+		}
+		writeErr.MetricsReject = append(writeErr.MetricsReject, indices...)
+		return nil
+	}
+	writeErr.MetricsAccept = append(writeErr.MetricsAccept, indices...)
+	return nil
+}
+
+// record converts the metric to a log record according to the column mapping
+// of the stream. Tags and fields are added as top-level columns if no column
+// is configured for them.
+func (a *AzureMonitorLogs) record(m telegraf.Metric) map[string]interface{} {
+	record := make(map[string]interface{}, len(m.TagList())+len(m.FieldList())+2)
+
+	tags := record
+	if a.TagsColumn != "" {
+		tags = make(map[string]interface{}, len(m.TagList()))
+		record[a.TagsColumn] = tags
+	}
+	for _, tag := range m.TagList() {
+		tags[tag.Key] = tag.Value
+	}
+
+	fields := record
+	if a.FieldsColumn != "" {
+		fields = make(map[string]interface{}, len(m.FieldList()))
+		record[a.FieldsColumn] = fields
+	}
+	for _, field := range m.FieldList() {
+		// JSON does not support non-finite numbers
+		if v, ok := field.Value.(float64); ok && (math.IsNaN(v) || math.IsInf(v, 0)) {
+			continue
+		}
+		fields[field.Key] = field.Value
+	}
+
+	if a.NameColumn != "" {
+		record[a.NameColumn] = m.Name()
+	}
+	record[a.TimeColumn] = m.Time().UTC().Format(time.RFC3339Nano)
+
+	return record
+}
+
+func (a *AzureMonitorLogs) send(body []byte) (bool, error) {
+	var buf bytes.Buffer
+	g := gzip.NewWriter(&buf)
+	if _, err := g.Write(body); err != nil {
+		return false, fmt.Errorf("zipping content failed: %w", err)
+	}
+	if err := g.Close(); err != nil {
+		return false, fmt.Errorf("closing gzip writer failed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
+	defer cancel()
+	token, err := a.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: a.scopes})
+	if err != nil {
+		return true, fmt.Errorf("getting access token failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", a.url, &buf)
+	if err != nil {
+		return false, fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", internal.ProductToken())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return false, nil
+	}
+
+	// Throttling and server errors are temporary, all other errors indicate
+	// records not matching the stream declaration or misconfiguration
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 ||
+		resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden
+	if respbody, err := io.ReadAll(resp.Body); err == nil && len(respbody) > 0 {
+		return retryable, fmt.Errorf("failed to write batch: [%d] %s: %s", resp.StatusCode, resp.Status, string(respbody))
+	}
+	return retryable, fmt.Errorf("failed to write batch: [%d] %s", resp.StatusCode, resp.Status)
+}
+
+func init() {
+	outputs.Add("azure_monitor_logs", func() telegraf.Output {
+		return &AzureMonitorLogs{
+			Audience:     "https://monitor.azure.com",
+			TimeColumn:   "TimeGenerated",
+			NameColumn:   "Name",
+			TagsColumn:   "Tags",
+			FieldsColumn: "Fields",
+			MaxBatchSize: config.Size(maxRequestBodySize),
+			Timeout:      config.Duration(20 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,296 @@
+package azure_monitor_logs
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "secret-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+type server struct {
+	status int
+
+	sync.Mutex
+	requests [][]map[string]interface{}
+}
+
+func (s *server) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dataCollectionRules/dcr-1234/streams/Custom-Telegraf" {
+			w.WriteHeader(http.StatusNotFound)
+			t.Errorf("unexpected path %q", r.URL.Path)
+			return
+		}
+		if r.URL.Query().Get("api-version") != apiVersion {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Errorf("unexpected API version %q", r.URL.Query().Get("api-version"))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+			return
+		}
+
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Error(err)
+			return
+		}
+		body, err := io.ReadAll(gz)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Error(err)
+			return
+		}
+		var records []map[string]interface{}
+		if err := json.Unmarshal(body, &records); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Errorf("invalid body %q: %v", string(body), err)
+			return
+		}
+
+		s.Lock()
+		s.requests = append(s.requests, records)
+		s.Unlock()
+
+		if s.status != 0 {
+			w.WriteHeader(s.status)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newPlugin(endpoint string) *AzureMonitorLogs {
+	return &AzureMonitorLogs{
+		Endpoint:     endpoint,
+		RuleID:       "dcr-1234",
+		Stream:       "Custom-Telegraf",
+		Audience:     "https://monitor.azure.com",
+		TimeColumn:   "TimeGenerated",
+		NameColumn:   "Name",
+		TagsColumn:   "Tags",
+		FieldsColumn: "Fields",
+		MaxBatchSize: config.Size(maxRequestBodySize),
+		Timeout:      config.Duration(5 * time.Second),
+		Log:          testutil.Logger{},
+		credential:   fakeCredential{},
+	}
+}
+
+func testMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{"usage_idle": 91.5, "count": int64(3)},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"disk",
+			map[string]string{"host": "server01", "path": "/"},
+			map[string]interface{}{"used": uint64(42), "mode": "rw"},
+			time.Unix(1700000010, 500),
+		),
+	}
+}
+
+func TestWrite(t *testing.T) {
+	s := &server{}
+	ts := httptest.NewServer(s.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write(testMetrics()))
+
+	expected := [][]map[string]interface{}{
+		{
+			{
+				"TimeGenerated": "2023-11-14T22:13:20Z",
+				"Name":          "cpu",
+				"Tags":          map[string]interface{}{"host": "server01"},
+				"Fields":        map[string]interface{}{"usage_idle": 91.5, "count": float64(3)},
+			},
+			{
+				"TimeGenerated": "2023-11-14T22:13:30.0000005Z",
+				"Name":          "disk",
+				"Tags":          map[string]interface{}{"host": "server01", "path": "/"},
+				"Fields":        map[string]interface{}{"used": float64(42), "mode": "rw"},
+			},
+		},
+	}
+	require.Equal(t, expected, s.requests)
+}
+
+func TestWriteFlattened(t *testing.T) {
+	s := &server{}
+	ts := httptest.NewServer(s.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.TimeColumn = "Timestamp"
+	plugin.NameColumn = ""
+	plugin.TagsColumn = ""
+	plugin.FieldsColumn = ""
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write(testMetrics()[:1]))
+
+	expected := [][]map[string]interface{}{
+		{
+			{
+				"Timestamp":  "2023-11-14T22:13:20Z",
+				"host":       "server01",
+				"usage_idle": 91.5,
+				"count":      float64(3),
+			},
+		},
+	}
+	require.Equal(t, expected, s.requests)
+}
+
+func TestWriteBatching(t *testing.T) {
+	s := &server{}
+	ts := httptest.NewServer(s.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.MaxBatchSize = config.Size(200)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := make([]telegraf.Metric, 0, 5)
+	for i := range 5 {
+		metrics = append(metrics, metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{"value": int64(i)},
+			time.Unix(1700000000, 0),
+		))
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Each record has a size of 85 bytes so only two fit into a batch
+	require.Len(t, s.requests, 3)
+	require.Len(t, s.requests[0], 2)
+	require.Len(t, s.requests[1], 2)
+	require.Len(t, s.requests[2], 1)
+}
+
+func TestWriteOversizedMetric(t *testing.T) {
+	s := &server{}
+	ts := httptest.NewServer(s.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.MaxBatchSize = config.Size(200)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{"value": int64(1)},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{"message": string(make([]byte, 300))},
+			time.Unix(1700000000, 0),
+		),
+	}
+	err := plugin.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Equal(t, []int{0}, writeErr.MetricsAccept)
+	require.Equal(t, []int{1}, writeErr.MetricsReject)
+	require.Len(t, s.requests, 1)
+}
+
+func TestWriteErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		rejected []int
+	}{
+		{
+			name:   "throttled",
+			status: http.StatusTooManyRequests,
+		},
+		{
+			name:   "server error",
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "invalid records",
+			status:   http.StatusBadRequest,
+			rejected: []int{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{status: tt.status}
+			ts := httptest.NewServer(s.handler(t))
+			defer ts.Close()
+
+			plugin := newPlugin(ts.URL)
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			defer plugin.Close()
+
+			err := plugin.Write(testMetrics())
+			var writeErr *internal.PartialWriteError
+			require.ErrorAs(t, err, &writeErr)
+			require.Empty(t, writeErr.MetricsAccept)
+			require.Equal(t, tt.rejected, writeErr.MetricsReject)
+		})
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := newPlugin("")
+	require.ErrorContains(t, plugin.Init(), "endpoint required")
+
+	plugin = newPlugin("https://localhost")
+	plugin.MaxBatchSize = config.Size(2 * maxRequestBodySize)
+	require.ErrorContains(t, plugin.Init(), "max_batch_size")
+
+	plugin = newPlugin("https://localhost")
+	plugin.credential = nil
+	plugin.ClientSecret = config.NewSecret([]byte("secret"))
+	require.ErrorContains(t, plugin.Init(), "tenant_id and client_id required")
+}
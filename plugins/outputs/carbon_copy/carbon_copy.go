@@ -0,0 +1,330 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package carbon_copy
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type CarbonCopy struct {
+	Outputs []*child        `toml:"output"`
+	Log     telegraf.Logger `toml:"-"`
+}
+
+// child is an output the metrics are duplicated to. The plugin and its
+// serializer are created while decoding the configuration as the available
+// options depend on the plugin.
+type child struct {
+	plugin            string
+	alias             string
+	sampleEvery       uint64
+	metricBatchSize   int
+	metricBufferLimit int
+	filter            models.Filter
+	output            telegraf.Output
+
+	running   *models.RunningOutput
+	connected bool
+}
+
+type childConfig struct {
+	Plugin            string              `toml:"plugin"`
+	Alias             string              `toml:"alias"`
+	SampleEvery       uint64              `toml:"sample_every"`
+	MetricBatchSize   int                 `toml:"metric_batch_size"`
+	MetricBufferLimit int                 `toml:"metric_buffer_limit"`
+	NamePass          []string            `toml:"namepass"`
+	NameDrop          []string            `toml:"namedrop"`
+	FieldInclude      []string            `toml:"fieldinclude"`
+	FieldExclude      []string            `toml:"fieldexclude"`
+	TagInclude        []string            `toml:"taginclude"`
+	TagExclude        []string            `toml:"tagexclude"`
+	TagPass           map[string][]string `toml:"tagpass"`
+	TagDrop           map[string][]string `toml:"tagdrop"`
+	MetricPass        string              `toml:"metricpass"`
+	Options           table               `toml:"options"`
+	Serializer        table               `toml:"serializer"`
+}
+
+// table keeps a sub-table of the configuration to decode it into the plugin
+// and serializer instances later on
+type table struct {
+	unmarshal func(interface{}) error
+}
+
+func (t *table) UnmarshalTOML(fn func(interface{}) error) error {
+	t.unmarshal = fn
+	return nil
+}
+
+func (c *child) UnmarshalTOML(fn func(interface{}) error) error {
+	var cfg childConfig
+	if err := fn(&cfg); err != nil {
+		return err
+	}
+
+	if cfg.Plugin == "" {
+		return errors.New("missing output plugin name")
+	}
+	if cfg.Plugin == "carbon_copy" {
+		return errors.New("nesting carbon_copy outputs is not supported")
+	}
+	creator, found := outputs.Outputs[cfg.Plugin]
+	if !found {
+		return fmt.Errorf("undefined but requested output: %s", cfg.Plugin)
+	}
+	output := creator()
+
+	if cfg.Options.unmarshal != nil {
+		// General options such as 'data_format' are silently ignored when
+		// decoding into the plugin, so catch misplaced serializer settings.
+		var options map[string]interface{}
+		if err := cfg.Options.unmarshal(&options); err != nil {
+			return err
+		}
+		if _, found := options["data_format"]; found {
+			return fmt.Errorf("output %q: 'data_format' must be set in the serializer table", cfg.Plugin)
+		}
+		if err := cfg.Options.unmarshal(output); err != nil {
+			return fmt.Errorf("output %q: %w", cfg.Plugin, err)
+		}
+	}
+
+	newSerializer := func() (telegraf.Serializer, error) {
+		dataFormat := "influx"
+		if cfg.Serializer.unmarshal != nil {
+			var options map[string]interface{}
+			if err := cfg.Serializer.unmarshal(&options); err != nil {
+				return nil, err
+			}
+			if v, found := options["data_format"]; found {
+				s, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid data format %v", v)
+				}
+				dataFormat = s
+			}
+		}
+
+		creator, found := serializers.Serializers[dataFormat]
+		if !found {
+			return nil, fmt.Errorf("undefined but requested data format: %s", dataFormat)
+		}
+		serializer := creator()
+		if cfg.Serializer.unmarshal != nil {
+			if err := cfg.Serializer.unmarshal(serializer); err != nil {
+				return nil, err
+			}
+		}
+
+		running := models.NewRunningSerializer(serializer, &models.SerializerConfig{
+			Parent:     cfg.Plugin,
+			Alias:      cfg.Alias,
+			DataFormat: dataFormat,
+		})
+		if err := running.Init(); err != nil {
+			return nil, err
+		}
+		return running, nil
+	}
+
+	switch p := output.(type) {
+	case telegraf.SerializerPlugin:
+		serializer, err := newSerializer()
+		if err != nil {
+			return fmt.Errorf("creating serializer for output %q failed: %w", cfg.Plugin, err)
+		}
+		p.SetSerializer(serializer)
+	case telegraf.SerializerFuncPlugin:
+		// Create a serializer to check the settings before handing out the
+		// function to the plugin
+		if _, err := newSerializer(); err != nil {
+			return fmt.Errorf("creating serializer for output %q failed: %w", cfg.Plugin, err)
+		}
+		p.SetSerializerFunc(newSerializer)
+	default:
+		if cfg.Serializer.unmarshal != nil {
+			return fmt.Errorf("output %q does not support data formats", cfg.Plugin)
+		}
+	}
+
+	c.plugin = cfg.Plugin
+	c.alias = cfg.Alias
+	c.sampleEvery = cfg.SampleEvery
+	c.metricBatchSize = cfg.MetricBatchSize
+	c.metricBufferLimit = cfg.MetricBufferLimit
+	c.output = output
+	c.filter = models.Filter{
+		NamePass:       cfg.NamePass,
+		NameDrop:       cfg.NameDrop,
+		FieldInclude:   cfg.FieldInclude,
+		FieldExclude:   cfg.FieldExclude,
+		TagInclude:     cfg.TagInclude,
+		TagExclude:     cfg.TagExclude,
+		TagPassFilters: tagFilters(cfg.TagPass),
+		TagDropFilters: tagFilters(cfg.TagDrop),
+		MetricPass:     cfg.MetricPass,
+	}
+
+	return nil
+}
+
+func (*CarbonCopy) SampleConfig() string {
+	return sampleConfig
+}
+
+func (cc *CarbonCopy) Init() error {
+	if len(cc.Outputs) == 0 {
+		return errors.New("no outputs configured")
+	}
+
+	for i, c := range cc.Outputs {
+		if c.output == nil {
+			return fmt.Errorf("output %d: missing output plugin", i+1)
+		}
+		if c.sampleEvery == 0 {
+			c.sampleEvery = 1
+		}
+		if err := c.filter.Compile(); err != nil {
+			return fmt.Errorf("output %d (%s): compiling filter failed: %w", i+1, c.plugin, err)
+		}
+
+		alias := c.alias
+		if alias == "" {
+			alias = fmt.Sprintf("carbon_copy-%d", i+1)
+		}
+		c.running = models.NewRunningOutput(c.output, &models.OutputConfig{
+			Name:              c.plugin,
+			Alias:             alias,
+			Filter:            c.filter,
+			MetricBatchSize:   c.metricBatchSize,
+			MetricBufferLimit: c.metricBufferLimit,
+		}, 0, 0)
+		if err := c.running.Init(); err != nil {
+			return fmt.Errorf("output %d (%s): initializing failed: %w", i+1, c.plugin, err)
+		}
+	}
+
+	return nil
+}
+
+func (cc *CarbonCopy) Connect() error {
+	for i, c := range cc.Outputs {
+		if c.connected {
+			continue
+		}
+		if err := c.running.Connect(); err != nil {
+			return fmt.Errorf("output %d (%s): connecting failed: %w", i+1, c.plugin, err)
+		}
+		c.connected = true
+	}
+	return nil
+}
+
+// Close flushes the metrics buffered by the children before closing them.
+// Metrics still buffered afterwards are lost and reported as error.
+func (cc *CarbonCopy) Close() error {
+	errs := make([]error, len(cc.Outputs))
+	var wg sync.WaitGroup
+	for i, c := range cc.Outputs {
+		if c.running == nil || !c.connected {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, c *child) {
+			defer wg.Done()
+			if err := c.running.Write(); err != nil {
+				c.running.Log().Errorf("Flushing on close failed: %v", err)
+			}
+			if n := c.running.BufferLength(); n > 0 {
+				errs[i] = fmt.Errorf("output %d (%s): %d metrics not written", i+1, c.plugin, n)
+			}
+			c.running.Close()
+			c.connected = false
+		}(i, c)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Write hands the metrics to all child outputs in parallel. Each child
+// buffers the metrics it failed to write on its own and retries on the next
+// write. To avoid duplicating the metrics to the other children, all metrics
+// are accepted and failing children are reported in a partial write error.
+func (cc *CarbonCopy) Write(metrics []telegraf.Metric) error {
+	errs := make([]error, len(cc.Outputs))
+	var wg sync.WaitGroup
+	for i, c := range cc.Outputs {
+		wg.Add(1)
+		go func(i int, c *child) {
+			defer wg.Done()
+			for _, m := range metrics {
+				if c.sampleEvery > 1 && sampleHash(m)%c.sampleEvery != 0 {
+					continue
+				}
+				c.running.AddMetric(m)
+			}
+
+			if err := c.running.Write(); err != nil {
+				errs[i] = fmt.Errorf("output %d (%s): %d metrics buffered: %w", i+1, c.plugin, c.running.BufferLength(), err)
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	err := errors.Join(errs...)
+	if err == nil {
+		return nil
+	}
+	accept := make([]int, 0, len(metrics))
+	for i := range metrics {
+		accept = append(accept, i)
+	}
+	return &internal.PartialWriteError{Err: err, MetricsAccept: accept}
+}
+
+// sampleHash computes a hash of the series and timestamp of the metric used
+// for sampling. Other than counting the metrics, this does not depend on the
+// order of the metrics, so metrics of the same series are not always skipped
+// when being collected in a fixed order.
+func sampleHash(m telegraf.Metric) uint64 {
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:8], m.HashID())
+	binary.LittleEndian.PutUint64(buf[8:], uint64(m.Time().UnixNano()))
+
+	h := fnv.New64a()
+	h.Write(buf[:])
+	return h.Sum64()
+}
+
+func tagFilters(tags map[string][]string) []models.TagFilter {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	filters := make([]models.TagFilter, 0, len(tags))
+	for name, values := range tags {
+		filters = append(filters, models.TagFilter{Name: name, Values: values})
+	}
+	sort.Slice(filters, func(i, j int) bool { return filters[i].Name < filters[j].Name })
+	return filters
+}
+
+func init() {
+	outputs.Add("carbon_copy", func() telegraf.Output { return &CarbonCopy{} })
+}
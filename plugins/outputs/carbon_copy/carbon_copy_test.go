@@ -0,0 +1,370 @@
+package carbon_copy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	_ "github.com/influxdata/telegraf/plugins/outputs/discard"
+	_ "github.com/influxdata/telegraf/plugins/outputs/file"
+	_ "github.com/influxdata/telegraf/plugins/serializers/influx"
+	_ "github.com/influxdata/telegraf/plugins/serializers/json"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfg := fmt.Sprintf(`
+[[outputs.carbon_copy]]
+  [[outputs.carbon_copy.output]]
+    plugin = "discard"
+
+  [[outputs.carbon_copy.output]]
+    plugin = "file"
+    alias = "sampled"
+    sample_every = 10
+    namepass = ["cpu"]
+    [outputs.carbon_copy.output.tagpass]
+      host = ["a*"]
+    [outputs.carbon_copy.output.options]
+      files = [%q]
+    [outputs.carbon_copy.output.serializer]
+      data_format = "json"
+      json_timestamp_units = "1ms"
+`, filepath.Join(dir, "out.json"))
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	require.Len(t, c.Outputs, 1)
+
+	plugin, ok := c.Outputs[0].Output.(*CarbonCopy)
+	require.True(t, ok)
+	require.Len(t, plugin.Outputs, 2)
+	require.Equal(t, "discard", plugin.Outputs[0].plugin)
+	require.Equal(t, "file", plugin.Outputs[1].plugin)
+	require.Equal(t, "sampled", plugin.Outputs[1].alias)
+	require.Equal(t, uint64(10), plugin.Outputs[1].sampleEvery)
+	require.Equal(t, []string{"cpu"}, plugin.Outputs[1].filter.NamePass)
+	require.Len(t, plugin.Outputs[1].filter.TagPassFilters, 1)
+}
+
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      string
+		expected string
+	}{
+		{
+			name: "missing plugin",
+			cfg: `
+[[outputs.carbon_copy]]
+  [[outputs.carbon_copy.output]]
+    alias = "foo"
+`,
+			expected: "missing output plugin name",
+		},
+		{
+			name: "unknown plugin",
+			cfg: `
+[[outputs.carbon_copy]]
+  [[outputs.carbon_copy.output]]
+    plugin = "foo"
+`,
+			expected: "undefined but requested output: foo",
+		},
+		{
+			name: "nested",
+			cfg: `
+[[outputs.carbon_copy]]
+  [[outputs.carbon_copy.output]]
+    plugin = "carbon_copy"
+`,
+			expected: "nesting carbon_copy outputs is not supported",
+		},
+		{
+			name: "data format in options",
+			cfg: `
+[[outputs.carbon_copy]]
+  [[outputs.carbon_copy.output]]
+    plugin = "file"
+    [outputs.carbon_copy.output.options]
+      data_format = "json"
+`,
+			expected: "'data_format' must be set in the serializer table",
+		},
+		{
+			name: "unknown data format",
+			cfg: `
+[[outputs.carbon_copy]]
+  [[outputs.carbon_copy.output]]
+    plugin = "file"
+    [outputs.carbon_copy.output.serializer]
+      data_format = "foo"
+`,
+			expected: "undefined but requested data format: foo",
+		},
+		{
+			name: "serializer without support",
+			cfg: `
+[[outputs.carbon_copy]]
+  [[outputs.carbon_copy.output]]
+    plugin = "discard"
+    [outputs.carbon_copy.output.serializer]
+      data_format = "json"
+`,
+			expected: `output "discard" does not support data formats`,
+		},
+		{
+			name: "unknown option",
+			cfg: `
+[[outputs.carbon_copy]]
+  [[outputs.carbon_copy.output]]
+    plugin = "file"
+    [outputs.carbon_copy.output.options]
+      foo = "bar"
+`,
+			expected: "foo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config.NewConfig()
+			err := c.LoadConfigData([]byte(tt.cfg), config.EmptySourcePath)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestInitNoOutputs(t *testing.T) {
+	plugin := &CarbonCopy{Log: testutil.Logger{}}
+	require.ErrorContains(t, plugin.Init(), "no outputs configured")
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	all := filepath.Join(dir, "all.out")
+	sampled := filepath.Join(dir, "sampled.out")
+	cfg := fmt.Sprintf(`
+[[outputs.carbon_copy]]
+  [[outputs.carbon_copy.output]]
+    plugin = "file"
+    [outputs.carbon_copy.output.options]
+      files = [%q]
+
+  [[outputs.carbon_copy.output]]
+    plugin = "file"
+    sample_every = 2
+    namepass = ["cpu"]
+    fieldexclude = ["idle"]
+    [outputs.carbon_copy.output.options]
+      files = [%q]
+    [outputs.carbon_copy.output.serializer]
+      data_format = "json"
+      json_timestamp_units = "1s"
+`, all, sampled)
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	require.Len(t, c.Outputs, 1)
+	plugin := c.Outputs[0].Output.(*CarbonCopy)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := make([]telegraf.Metric, 0, 6)
+	for i := 0; i < 6; i++ {
+		name := "cpu"
+		if i%3 == 2 {
+			name = "mem"
+		}
+		metrics = append(metrics, metric.New(
+			name,
+			map[string]string{},
+			map[string]interface{}{"value": i, "idle": 42},
+			time.Unix(int64(i), 0),
+		))
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Close())
+
+	buf, err := os.ReadFile(all)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(string(buf)), "\n"), 6)
+
+	// The cpu metrics are number 0, 1, 3 and 4 of which a sample depending
+	// on the series and timestamp is written
+	buf, err = os.ReadFile(sampled)
+	require.NoError(t, err)
+	expected := `{"fields":{"value":0},"name":"cpu","tags":{},"timestamp":0}` + "\n" +
+		`{"fields":{"value":3},"name":"cpu","tags":{},"timestamp":3}` + "\n"
+	require.Equal(t, expected, string(buf))
+}
+
+func TestWriteTracking(t *testing.T) {
+	dir := t.TempDir()
+	cfg := fmt.Sprintf(`
+[[outputs.carbon_copy]]
+  [[outputs.carbon_copy.output]]
+    plugin = "discard"
+
+  [[outputs.carbon_copy.output]]
+    plugin = "file"
+    [outputs.carbon_copy.output.options]
+      files = [%q]
+`, filepath.Join(dir, "out.influx"))
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	plugin := c.Outputs[0].Output.(*CarbonCopy)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	var delivered bool
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	tm, _ := metric.WithTracking(m, func(info telegraf.DeliveryInfo) {
+		delivered = info.Delivered()
+	})
+
+	// The children write copies of the metric, so the delivery must only
+	// complete once the original metric is accepted as well
+	require.NoError(t, plugin.Write([]telegraf.Metric{tm}))
+	require.False(t, delivered)
+	tm.Accept()
+	require.True(t, delivered)
+}
+
+func TestSampling(t *testing.T) {
+	metrics := make([]telegraf.Metric, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"cpu": fmt.Sprintf("cpu%d", i%10)},
+			map[string]interface{}{"value": i},
+			time.Unix(int64(i/10), 0),
+		))
+	}
+
+	out := &mockOutput{}
+	plugin := &CarbonCopy{
+		Outputs: []*child{{plugin: "mock", sampleEvery: 10, output: out}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Close())
+
+	// The sample must roughly contain every tenth metric and must not
+	// consist of a single series even though the metrics come in a fixed
+	// order of the series
+	require.InDelta(t, 100, len(out.metrics), 40)
+	series := make(map[string]bool)
+	for _, m := range out.metrics {
+		tag, _ := m.GetTag("cpu")
+		series[tag] = true
+	}
+	require.Greater(t, len(series), 1)
+
+	// The sample is the same for the same metrics
+	out2 := &mockOutput{}
+	plugin = &CarbonCopy{
+		Outputs: []*child{{plugin: "mock", sampleEvery: 10, output: out2}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Close())
+	testutil.RequireMetricsEqual(t, out.metrics, out2.metrics)
+}
+
+func TestWriteFailingChild(t *testing.T) {
+	good := &mockOutput{}
+	bad := &mockOutput{fail: true}
+	plugin := &CarbonCopy{
+		Outputs: []*child{
+			{plugin: "good", output: good},
+			{plugin: "bad", output: bad},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(1, 0)),
+	}
+
+	// All metrics are accepted as the failing child buffers them on its own
+	err := plugin.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.ErrorContains(t, err, "output 2 (bad): 2 metrics buffered")
+	require.Equal(t, []int{0, 1}, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+	testutil.RequireMetricsEqual(t, metrics, good.metrics)
+
+	// Closing flushes the children and reports the metrics not written
+	require.ErrorContains(t, plugin.Close(), "output 2 (bad): 2 metrics not written")
+	require.Empty(t, bad.metrics)
+
+	// Recovered children write the buffered metrics on close
+	bad = &mockOutput{fail: true}
+	plugin = &CarbonCopy{
+		Outputs: []*child{{plugin: "bad", output: bad}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	require.Error(t, plugin.Write(metrics))
+	bad.setFail(false)
+	require.NoError(t, plugin.Close())
+	testutil.RequireMetricsEqual(t, metrics, bad.metrics)
+}
+
+type mockOutput struct {
+	metrics []telegraf.Metric
+	fail    bool
+	sync.Mutex
+}
+
+func (*mockOutput) SampleConfig() string {
+	return ""
+}
+
+func (*mockOutput) Connect() error {
+	return nil
+}
+
+func (*mockOutput) Close() error {
+	return nil
+}
+
+func (m *mockOutput) Write(metrics []telegraf.Metric) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.fail {
+		return errors.New("failed")
+	}
+	m.metrics = append(m.metrics, metrics...)
+	return nil
+}
+
+func (m *mockOutput) setFail(fail bool) {
+	m.Lock()
+	defer m.Unlock()
+	m.fail = fail
+}
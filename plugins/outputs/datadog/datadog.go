@@ -24,6 +24,12 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// Limits of the Datadog API, tags are sent as "key:value" strings
+const (
+	maxMetricNameLength = 200
+	maxTagLength        = 200
+)
+
 type Datadog struct {
 	Apikey       string          `toml:"apikey"`
 	Timeout      config.Duration `toml:"timeout"`
@@ -274,6 +280,14 @@ func (*Datadog) Close() error {
 	return nil
 }
 
+func (*Datadog) Constraints() telegraf.OutputConstraints {
+	return telegraf.OutputConstraints{
+		MaxNameLength: maxMetricNameLength,
+		// Account for the separator between key and value
+		MaxTagLength: maxTagLength - 1,
+	}
+}
+
 func init() {
 	outputs.Add("datadog", func() telegraf.Output {
 		return &Datadog{
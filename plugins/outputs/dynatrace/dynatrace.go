@@ -25,6 +25,14 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// Limits of the metrics ingestion protocol
+const (
+	maxMetricKeyLength      = 250
+	maxDimensions           = 50
+	maxDimensionKeyLength   = 100
+	maxDimensionValueLength = 255
+)
+
 // Dynatrace Configuration for the Dynatrace output plugin
 type Dynatrace struct {
 	URL                       string          `toml:"url"`
@@ -221,6 +229,16 @@ func (d *Dynatrace) Init() error {
 	return nil
 }
 
+func (d *Dynatrace) Constraints() telegraf.OutputConstraints {
+	// The default and static dimensions are added to every metric line
+	return telegraf.OutputConstraints{
+		MaxNameLength:     maxMetricKeyLength,
+		MaxTags:           max(maxDimensions-len(d.DefaultDimensions)-1, 1),
+		MaxTagKeyLength:   maxDimensionKeyLength,
+		MaxTagValueLength: maxDimensionValueLength,
+	}
+}
+
 func init() {
 	outputs.Add("dynatrace", func() telegraf.Output {
 		return &Dynatrace{
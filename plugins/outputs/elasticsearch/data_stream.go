@@ -0,0 +1,176 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/olivere/elastic"
+)
+
+// Priority of the managed index template, it must be higher than the one of
+// the built-in 'metrics-*-*' template to take precedence
+const dataStreamTemplatePriority = 200
+
+// checkDataStreamSupport checks if the given Elasticsearch version supports
+// data streams (7.9+) and time series data streams (8.7+) respectively
+func (a *Elasticsearch) checkDataStreamSupport(version string) error {
+	parts := strings.Split(version, ".")
+	var minor int
+	if len(parts) > 1 {
+		minor, _ = strconv.Atoi(parts[1])
+	}
+
+	if a.majorReleaseNumber < 7 || (a.majorReleaseNumber == 7 && minor < 9) {
+		return fmt.Errorf("data streams require Elasticsearch 7.9 or later but found %s", version)
+	}
+	if a.IndexMode == "time_series" && (a.majorReleaseNumber < 8 || (a.majorReleaseNumber == 8 && minor < 7)) {
+		return fmt.Errorf("time series data streams require Elasticsearch 8.7 or later but found %s", version)
+	}
+	return nil
+}
+
+// manageDataStreamTemplate creates the component templates for the settings
+// and mappings and a composable index template enabling data streams
+func (a *Elasticsearch) manageDataStreamTemplate(ctx context.Context) error {
+	if a.TemplateName == "" {
+		return errors.New("elasticsearch template_name configuration not defined")
+	}
+
+	templatePattern := a.IndexName
+	if strings.Contains(templatePattern, "{{") {
+		templatePattern = templatePattern[0:strings.Index(templatePattern, "{{")]
+	}
+	if templatePattern == "" {
+		return errors.New("template cannot be created for dynamic data stream names without a prefix")
+	}
+
+	resp, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method:       http.MethodHead,
+		Path:         "/_index_template/" + a.TemplateName,
+		IgnoreErrors: []int{http.StatusNotFound},
+	})
+	if err != nil {
+		return fmt.Errorf("elasticsearch template check failed, template name: %s, error: %w", a.TemplateName, err)
+	}
+	if resp.StatusCode == http.StatusOK && !a.OverwriteTemplate {
+		a.Log.Debug("Found existing Elasticsearch index template. Skipping template management")
+		return nil
+	}
+
+	settings, mappings, err := a.createDataStreamComponents()
+	if err != nil {
+		return err
+	}
+	components := map[string]interface{}{
+		a.TemplateName + "-settings": map[string]interface{}{"template": map[string]interface{}{"settings": settings}},
+		a.TemplateName + "-mappings": map[string]interface{}{"template": map[string]interface{}{"mappings": mappings}},
+	}
+	for _, name := range []string{a.TemplateName + "-settings", a.TemplateName + "-mappings"} {
+		if _, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+			Method: http.MethodPut,
+			Path:   "/_component_template/" + name,
+			Body:   components[name],
+		}); err != nil {
+			return fmt.Errorf("elasticsearch failed to create component template %s: %w", name, err)
+		}
+	}
+
+	composedOf := append([]string{a.TemplateName + "-settings", a.TemplateName + "-mappings"}, a.ComponentTemplates...)
+	indexTemplate := map[string]interface{}{
+		"index_patterns": []string{templatePattern + "*"},
+		"data_stream":    map[string]interface{}{},
+		"composed_of":    composedOf,
+		"priority":       dataStreamTemplatePriority,
+		"_meta":          map[string]interface{}{"managed_by": "telegraf"},
+	}
+	if _, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: http.MethodPut,
+		Path:   "/_index_template/" + a.TemplateName,
+		Body:   indexTemplate,
+	}); err != nil {
+		return fmt.Errorf("elasticsearch failed to create index template %s: %w", a.TemplateName, err)
+	}
+
+	a.Log.Debugf("Index template %s created or updated", a.TemplateName)
+	return nil
+}
+
+// createDataStreamComponents returns the settings and mappings of the data
+// stream backing indices. In time series mode the tags and the measurement
+// name are mapped as dimensions and numeric fields as gauge metrics.
+func (a *Elasticsearch) createDataStreamComponents() (settings, mappings map[string]interface{}, err error) {
+	index := a.IndexTemplate
+	if index == nil {
+		if err := json.Unmarshal([]byte(defaultTemplateIndexSettings), &index); err != nil {
+			return nil, nil, err
+		}
+	}
+	// Copy the settings to not modify the user configuration
+	indexSettings := make(map[string]interface{}, len(index)+3)
+	for k, v := range index {
+		indexSettings[k] = v
+	}
+	if a.ILMPolicy != "" {
+		indexSettings["lifecycle.name"] = a.ILMPolicy
+	}
+
+	timeSeries := a.IndexMode == "time_series"
+	if timeSeries {
+		indexSettings["mode"] = "time_series"
+		indexSettings["routing_path"] = []string{"measurement_name", "tag.*"}
+	}
+
+	tagMapping := map[string]interface{}{"type": "keyword"}
+	measurementMapping := map[string]interface{}{"type": "keyword"}
+	metricMapping := map[string]interface{}{"type": "float", "index": false}
+	if timeSeries {
+		tagMapping["time_series_dimension"] = true
+		measurementMapping["time_series_dimension"] = true
+		metricMapping = map[string]interface{}{"type": "double", "time_series_metric": "gauge"}
+	} else {
+		tagMapping["ignore_above"] = 512
+	}
+
+	mappings = map[string]interface{}{
+		"properties": map[string]interface{}{
+			"@timestamp":       map[string]interface{}{"type": "date"},
+			"measurement_name": measurementMapping,
+		},
+		"dynamic_templates": []interface{}{
+			map[string]interface{}{
+				"tags": map[string]interface{}{
+					"match_mapping_type": "string",
+					"path_match":         "tag.*",
+					"mapping":            tagMapping,
+				},
+			},
+			map[string]interface{}{
+				"metrics_long": map[string]interface{}{
+					"match_mapping_type": "long",
+					"mapping":            metricMapping,
+				},
+			},
+			map[string]interface{}{
+				"metrics_double": map[string]interface{}{
+					"match_mapping_type": "double",
+					"mapping":            metricMapping,
+				},
+			},
+			map[string]interface{}{
+				"text_fields": map[string]interface{}{
+					"match": "*",
+					"mapping": map[string]interface{}{
+						"norms": false,
+					},
+				},
+			},
+		},
+	}
+
+	return map[string]interface{}{"index": indexSettings}, mappings, nil
+}
@@ -0,0 +1,266 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type dataStreamServer struct {
+	version        string
+	templateExists bool
+
+	sync.Mutex
+	templates map[string]map[string]interface{}
+	actions   []map[string]map[string]interface{}
+}
+
+func (s *dataStreamServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "ApiKey c2VjcmV0LWlkOnNlY3JldC1rZXk=" {
+			w.WriteHeader(http.StatusUnauthorized)
+			t.Errorf("unexpected authorization %q", auth)
+			return
+		}
+
+		s.Lock()
+		defer s.Unlock()
+
+		switch r.URL.Path {
+		case "/":
+			if _, err := w.Write([]byte(`{"version": {"number": "` + s.version + `"}}`)); err != nil {
+				t.Error(err)
+			}
+		case "/_index_template/telegraf", "/_component_template/telegraf-settings", "/_component_template/telegraf-mappings":
+			if r.Method == http.MethodHead {
+				if !s.templateExists {
+					w.WriteHeader(http.StatusNotFound)
+				}
+				return
+			}
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				t.Error(err)
+				return
+			}
+			if s.templates == nil {
+				s.templates = make(map[string]map[string]interface{})
+			}
+			s.templates[r.URL.Path] = body
+			if _, err := w.Write([]byte(`{"acknowledged": true}`)); err != nil {
+				t.Error(err)
+			}
+		case "/_bulk":
+			// Collect the action lines and skip the documents
+			scanner := bufio.NewScanner(r.Body)
+			for i := 0; scanner.Scan(); i++ {
+				if i%2 != 0 {
+					continue
+				}
+				var action map[string]map[string]interface{}
+				if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					t.Error(err)
+					return
+				}
+				s.actions = append(s.actions, action)
+			}
+			if _, err := w.Write([]byte("{}")); err != nil {
+				t.Error(err)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}
+}
+
+func newDataStreamPlugin(address string) *Elasticsearch {
+	return &Elasticsearch{
+		URLs:           []string{address},
+		IndexName:      "metrics-telegraf-{{tag1}}",
+		DataStream:     true,
+		ManageTemplate: true,
+		TemplateName:   "telegraf",
+		Timeout:        config.Duration(5 * time.Second),
+		APIKey:         config.NewSecret([]byte("c2VjcmV0LWlkOnNlY3JldC1rZXk=")),
+		Log:            testutil.Logger{},
+	}
+}
+
+func TestDataStreamWrite(t *testing.T) {
+	srv := &dataStreamServer{version: "8.11.1"}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	plugin := newDataStreamPlugin(ts.URL)
+	plugin.ILMPolicy = "telegraf-policy"
+	plugin.ComponentTemplates = []string{"telegraf-runtime-fields"}
+	require.NoError(t, plugin.Connect())
+	require.NoError(t, plugin.Write(testutil.MockMetrics()))
+
+	require.Len(t, srv.actions, 1)
+	require.Contains(t, srv.actions[0], "create")
+	require.Equal(t, "metrics-telegraf-value1", srv.actions[0]["create"]["_index"])
+	require.NotContains(t, srv.actions[0]["create"], "_type")
+
+	indexTemplate := srv.templates["/_index_template/telegraf"]
+	require.Equal(t, []interface{}{"metrics-telegraf-*"}, indexTemplate["index_patterns"])
+	require.Equal(t, map[string]interface{}{}, indexTemplate["data_stream"])
+	require.Equal(t,
+		[]interface{}{"telegraf-settings", "telegraf-mappings", "telegraf-runtime-fields"},
+		indexTemplate["composed_of"],
+	)
+	require.InDelta(t, float64(dataStreamTemplatePriority), indexTemplate["priority"], testutil.DefaultDelta)
+
+	settings := srv.templates["/_component_template/telegraf-settings"]["template"].(map[string]interface{})["settings"]
+	index := settings.(map[string]interface{})["index"].(map[string]interface{})
+	require.Equal(t, "telegraf-policy", index["lifecycle.name"])
+	require.Equal(t, "10s", index["refresh_interval"])
+	require.NotContains(t, index, "mode")
+	require.Contains(t, srv.templates, "/_component_template/telegraf-mappings")
+}
+
+func TestDataStreamExistingTemplate(t *testing.T) {
+	srv := &dataStreamServer{version: "8.11.1", templateExists: true}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	plugin := newDataStreamPlugin(ts.URL)
+	require.NoError(t, plugin.Connect())
+	require.Empty(t, srv.templates)
+
+	plugin = newDataStreamPlugin(ts.URL)
+	plugin.OverwriteTemplate = true
+	require.NoError(t, plugin.Connect())
+	require.Len(t, srv.templates, 3)
+}
+
+func TestDataStreamUnsupportedVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		indexMode string
+		expected  string
+	}{
+		{
+			name:     "data stream",
+			version:  "7.8.0",
+			expected: "data streams require Elasticsearch 7.9 or later",
+		},
+		{
+			name:      "time series",
+			version:   "8.6.2",
+			indexMode: "time_series",
+			expected:  "time series data streams require Elasticsearch 8.7 or later",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &dataStreamServer{version: tt.version}
+			ts := httptest.NewServer(srv.handler(t))
+			defer ts.Close()
+
+			plugin := newDataStreamPlugin(ts.URL)
+			plugin.IndexMode = tt.indexMode
+			require.ErrorContains(t, plugin.Connect(), tt.expected)
+		})
+	}
+}
+
+func TestDataStreamInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Elasticsearch)
+		expected string
+	}{
+		{
+			name:     "invalid index mode",
+			modify:   func(e *Elasticsearch) { e.IndexMode = "logsdb" },
+			expected: `invalid index_mode "logsdb"`,
+		},
+		{
+			name: "time series without data stream",
+			modify: func(e *Elasticsearch) {
+				e.DataStream = false
+				e.IndexMode = "time_series"
+			},
+			expected: "requires data_stream to be enabled",
+		},
+		{
+			name: "time series with document id",
+			modify: func(e *Elasticsearch) {
+				e.IndexMode = "time_series"
+				e.ForceDocumentID = true
+			},
+			expected: "force_document_id cannot be used",
+		},
+		{
+			name:     "date specifiers",
+			modify:   func(e *Elasticsearch) { e.IndexName = "telegraf-%Y.%m.%d" },
+			expected: "date specifiers are not supported",
+		},
+		{
+			name: "ilm policy without data stream",
+			modify: func(e *Elasticsearch) {
+				e.DataStream = false
+				e.ILMPolicy = "telegraf-policy"
+			},
+			expected: "require data_stream to be enabled",
+		},
+		{
+			name:     "api key and bearer token",
+			modify:   func(e *Elasticsearch) { e.AuthBearerToken = config.NewSecret([]byte("0123456789abcdef")) },
+			expected: "api_key and auth_bearer_token cannot be used together",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newDataStreamPlugin("http://localhost:9200")
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Connect(), tt.expected)
+		})
+	}
+}
+
+func TestTimeSeriesComponents(t *testing.T) {
+	plugin := &Elasticsearch{
+		IndexMode: "time_series",
+		IndexTemplate: map[string]interface{}{
+			"refresh_interval": "20s",
+		},
+	}
+	settings, mappings, err := plugin.createDataStreamComponents()
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]interface{}{
+		"index": map[string]interface{}{
+			"refresh_interval": "20s",
+			"mode":             "time_series",
+			"routing_path":     []string{"measurement_name", "tag.*"},
+		},
+	}, settings)
+	// The user settings must not be modified
+	require.Len(t, plugin.IndexTemplate, 1)
+
+	properties := mappings["properties"].(map[string]interface{})
+	require.Equal(t,
+		map[string]interface{}{"type": "keyword", "time_series_dimension": true},
+		properties["measurement_name"],
+	)
+	dynamic := mappings["dynamic_templates"].([]interface{})
+	tags := dynamic[0].(map[string]interface{})["tags"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"type": "keyword", "time_series_dimension": true}, tags["mapping"])
+	metrics := dynamic[2].(map[string]interface{})["metrics_double"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"type": "double", "time_series_metric": "gauge"}, metrics["mapping"])
+}
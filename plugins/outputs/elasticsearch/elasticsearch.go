@@ -29,7 +29,10 @@ import (
 var sampleConfig string
 
 type Elasticsearch struct {
+	APIKey              config.Secret          `toml:"api_key"`
 	AuthBearerToken     config.Secret          `toml:"auth_bearer_token"`
+	ComponentTemplates  []string               `toml:"component_templates"`
+	DataStream          bool                   `toml:"data_stream"`
 	DefaultPipeline     string                 `toml:"default_pipeline"`
 	DefaultTagValue     string                 `toml:"default_tag_value"`
 	EnableGzip          bool                   `toml:"enable_gzip"`
@@ -39,6 +42,8 @@ type Elasticsearch struct {
 	ForceDocumentID     bool                   `toml:"force_document_id"`
 	HealthCheckInterval config.Duration        `toml:"health_check_interval"`
 	HealthCheckTimeout  config.Duration        `toml:"health_check_timeout"`
+	ILMPolicy           string                 `toml:"ilm_policy"`
+	IndexMode           string                 `toml:"index_mode"`
 	IndexName           string                 `toml:"index_name"`
 	IndexTemplate       map[string]interface{} `toml:"template_index_settings"`
 	ManageTemplate      bool                   `toml:"manage_template"`
@@ -158,6 +163,27 @@ func (a *Elasticsearch) Connect() error {
 		return fmt.Errorf("invalid float_handling type %q", a.FloatHandling)
 	}
 
+	// Check the data stream settings
+	switch a.IndexMode {
+	case "", "standard":
+		a.IndexMode = "standard"
+	case "time_series":
+		if !a.DataStream {
+			return errors.New("index_mode \"time_series\" requires data_stream to be enabled")
+		}
+		if a.ForceDocumentID {
+			return errors.New("force_document_id cannot be used with index_mode \"time_series\"")
+		}
+	default:
+		return fmt.Errorf("invalid index_mode %q", a.IndexMode)
+	}
+	if a.DataStream && strings.Contains(a.IndexName, "%") {
+		return errors.New("date specifiers are not supported in index_name when using data streams")
+	}
+	if !a.DataStream && (a.ILMPolicy != "" || len(a.ComponentTemplates) > 0) {
+		return errors.New("ilm_policy and component_templates require data_stream to be enabled")
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
 	defer cancel()
 
@@ -238,8 +264,19 @@ func (a *Elasticsearch) Connect() error {
 	a.Client = client
 	a.majorReleaseNumber = majorReleaseNumber
 
+	if a.DataStream {
+		if err := a.checkDataStreamSupport(esVersion); err != nil {
+			return err
+		}
+	}
+
 	if a.ManageTemplate {
-		err := a.manageTemplate(ctx)
+		var err error
+		if a.DataStream {
+			err = a.manageDataStreamTemplate(ctx)
+		} else {
+			err = a.manageTemplate(ctx)
+		}
 		if err != nil {
 			return err
 		}
@@ -305,7 +342,8 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 
 		br := elastic.NewBulkIndexRequest().Index(indexName).Doc(m)
 
-		if a.UseOpTypeCreate {
+		// Data streams are append-only and require the "create" OpType
+		if a.UseOpTypeCreate || a.DataStream {
 			br.OpType("create")
 		}
 
@@ -508,6 +546,10 @@ func (a *Elasticsearch) Close() error {
 func (a *Elasticsearch) getAuthOptions() ([]elastic.ClientOptionFunc, error) {
 	var fns []elastic.ClientOptionFunc
 
+	if !a.APIKey.Empty() && !a.AuthBearerToken.Empty() {
+		return nil, errors.New("api_key and auth_bearer_token cannot be used together")
+	}
+
 	if !a.Username.Empty() && !a.Password.Empty() {
 		username, err := a.Username.Get()
 		if err != nil {
@@ -532,6 +574,16 @@ func (a *Elasticsearch) getAuthOptions() ([]elastic.ClientOptionFunc, error) {
 		fns = append(fns, elastic.SetHeaders(http.Header{"Authorization": auth}))
 		token.Destroy()
 	}
+
+	if !a.APIKey.Empty() {
+		key, err := a.APIKey.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting API key failed: %w", err)
+		}
+		auth := []string{"ApiKey " + key.String()}
+		fns = append(fns, elastic.SetHeaders(http.Header{"Authorization": auth}))
+		key.Destroy()
+	}
 	return fns, nil
 }
 
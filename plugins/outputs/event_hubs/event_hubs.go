@@ -4,9 +4,18 @@ package event_hubs
 import (
 	"context"
 	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
+	"github.com/Azure/azure-amqp-common-go/v4/auth"
 	eventhub "github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
@@ -22,6 +31,7 @@ var sampleConfig string
 
 type EventHubInterface interface {
 	GetHub(s string) error
+	GetHubWithCredential(namespace, name string, credential azcore.TokenCredential) error
 	Close(ctx context.Context) error
 	SendBatch(ctx context.Context, iterator eventhub.BatchIterator, opts ...eventhub.BatchOption) error
 }
@@ -42,6 +52,17 @@ func (eh *eventHub) GetHub(s string) error {
 	return nil
 }
 
+func (eh *eventHub) GetHubWithCredential(namespace, name string, credential azcore.TokenCredential) error {
+	hub, err := eventhub.NewHub(namespace, name, &tokenProvider{credential: credential})
+	if err != nil {
+		return err
+	}
+
+	eh.hub = hub
+
+	return nil
+}
+
 func (eh *eventHub) Close(ctx context.Context) error {
 	return eh.hub.Close(ctx)
 }
@@ -52,20 +73,50 @@ func (eh *eventHub) SendBatch(ctx context.Context, iterator eventhub.BatchIterat
 
 /* End wrapper interface */
 
+// tokenProvider provides Azure Active Directory tokens for the claims-based
+// authorization of the AMQP connection
+type tokenProvider struct {
+	credential azcore.TokenCredential
+}
+
+func (p *tokenProvider) GetToken(string) (*auth.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+
+	token, err := p.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{eventHubsScope}})
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewToken(auth.CBSTokenTypeJWT, token.Token, strconv.FormatInt(token.ExpiresOn.Unix(), 10)), nil
+}
+
 type EventHubs struct {
 	Log              telegraf.Logger `toml:"-"`
 	ConnectionString string          `toml:"connection_string"`
+	Namespace        string          `toml:"namespace"`
+	EventHub         string          `toml:"event_hub"`
+	AuthMethod       string          `toml:"auth_method"`
+	TenantID         string          `toml:"tenant_id"`
+	ClientID         string          `toml:"client_id"`
+	ClientSecret     config.Secret   `toml:"client_secret"`
 	Timeout          config.Duration `toml:"timeout"`
 	PartitionKey     string          `toml:"partition_key"`
 	MaxMessageSize   int             `toml:"max_message_size"`
 
-	Hub          EventHubInterface
-	batchOptions []eventhub.BatchOption
-	serializer   telegraf.Serializer
+	Hub             EventHubInterface
+	batchOptions    []eventhub.BatchOption
+	maxEventSize    int
+	partitionKeyTpl *template.Template
+	serializer      telegraf.Serializer
 }
 
 const (
 	defaultRequestTimeout = time.Second * 30
+	// Default batch size of the Azure Event Hubs client library
+	defaultMaxMessageSize = 1000000
+	// Maximum size of a batch or event for all Event Hubs tiers
+	maxMessageSize = 1024 * 1024
+	eventHubsScope = "https://eventhubs.azure.net/.default"
 )
 
 func (*EventHubs) SampleConfig() string {
@@ -73,19 +124,93 @@ func (*EventHubs) SampleConfig() string {
 }
 
 func (e *EventHubs) Init() error {
-	err := e.Hub.GetHub(e.ConnectionString)
+	if e.MaxMessageSize < 0 || e.MaxMessageSize > maxMessageSize {
+		return fmt.Errorf("max_message_size has to be between 0 and %d bytes", maxMessageSize)
+	}
 
-	if err != nil {
+	// Metrics with a partition key template are sent with the rendered key
+	if strings.Contains(e.PartitionKey, "{{") {
+		tmpl, err := template.New("partition_key").Parse(e.PartitionKey)
+		if err != nil {
+			return fmt.Errorf("parsing partition_key template failed: %w", err)
+		}
+		e.partitionKeyTpl = tmpl
+	}
+
+	if err := e.connectHub(); err != nil {
 		return err
 	}
 
+	e.maxEventSize = defaultMaxMessageSize
 	if e.MaxMessageSize > 0 {
+		e.maxEventSize = e.MaxMessageSize
 		e.batchOptions = append(e.batchOptions, eventhub.BatchWithMaxSizeInBytes(e.MaxMessageSize))
 	}
 
 	return nil
 }
 
+// connectHub creates the hub client either using the connection string or
+// using the Azure Active Directory credential of the configured method
+func (e *EventHubs) connectHub() error {
+	if e.AuthMethod == "" && e.ConnectionString != "" {
+		e.AuthMethod = "connection_string"
+	}
+	if e.AuthMethod == "connection_string" {
+		if e.ConnectionString == "" {
+			return errors.New("connection_string required")
+		}
+		return e.Hub.GetHub(e.ConnectionString)
+	}
+
+	if e.AuthMethod == "" {
+		return errors.New("either connection_string or auth_method required")
+	}
+	if e.Namespace == "" || e.EventHub == "" {
+		return errors.New("namespace and event_hub required for Azure Active Directory authentication")
+	}
+
+	var credential azcore.TokenCredential
+	switch e.AuthMethod {
+	case "managed_identity":
+		var options azidentity.ManagedIdentityCredentialOptions
+		if e.ClientID != "" {
+			options.ID = azidentity.ClientID(e.ClientID)
+		}
+		cred, err := azidentity.NewManagedIdentityCredential(&options)
+		if err != nil {
+			return fmt.Errorf("creating managed identity credential failed: %w", err)
+		}
+		credential = cred
+	case "service_principal":
+		if e.TenantID == "" || e.ClientID == "" || e.ClientSecret.Empty() {
+			return errors.New("tenant_id, client_id and client_secret required for service principal authentication")
+		}
+		secret, err := e.ClientSecret.Get()
+		if err != nil {
+			return fmt.Errorf("getting client secret failed: %w", err)
+		}
+		defer secret.Destroy()
+		cred, err := azidentity.NewClientSecretCredential(e.TenantID, e.ClientID, secret.String(), nil)
+		if err != nil {
+			return fmt.Errorf("creating client secret credential failed: %w", err)
+		}
+		credential = cred
+	case "default":
+		cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{TenantID: e.TenantID})
+		if err != nil {
+			return fmt.Errorf("creating default credential failed: %w", err)
+		}
+		credential = cred
+	default:
+		return fmt.Errorf("invalid auth_method %q", e.AuthMethod)
+	}
+
+	// The client library expects the namespace name without the domain
+	namespace, _, _ := strings.Cut(e.Namespace, ".")
+	return e.Hub.GetHubWithCredential(namespace, e.EventHub, credential)
+}
+
 func (*EventHubs) Connect() error {
 	return nil
 }
@@ -117,15 +242,16 @@ func (e *EventHubs) Write(metrics []telegraf.Metric) error {
 			continue
 		}
 
+		// Events exceeding the batch size can never be sent, so drop them
+		// instead of failing the whole batch over and over again
+		if len(payload) > e.maxEventSize {
+			e.Log.Errorf("Dropping metric %q with size %d exceeding the maximum message size of %d bytes", metric.Name(), len(payload), e.maxEventSize)
+			continue
+		}
+
 		event := eventhub.NewEvent(payload)
-		if e.PartitionKey != "" {
-			if key, ok := metric.GetTag(e.PartitionKey); ok {
-				event.PartitionKey = &key
-			} else if key, ok := metric.GetField(e.PartitionKey); ok {
-				if strKey, ok := key.(string); ok {
-					event.PartitionKey = &strKey
-				}
-			}
+		if key := e.partitionKey(metric); key != "" {
+			event.PartitionKey = &key
 		}
 
 		events = append(events, event)
@@ -143,6 +269,40 @@ func (e *EventHubs) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// partitionKey returns the partition key of the metric, an empty key leaves
+// the partition selection to the service
+func (e *EventHubs) partitionKey(metric telegraf.Metric) string {
+	if e.partitionKeyTpl != nil {
+		if wm, ok := metric.(telegraf.UnwrappableMetric); ok {
+			metric = wm.Unwrap()
+		}
+		m, ok := metric.(telegraf.TemplateMetric)
+		if !ok {
+			e.Log.Errorf("Metric of type %T is not a template metric", metric)
+			return ""
+		}
+		var b strings.Builder
+		if err := e.partitionKeyTpl.Execute(&b, m); err != nil {
+			e.Log.Errorf("Executing partition_key template failed: %v", err)
+			return ""
+		}
+		return b.String()
+	}
+
+	if e.PartitionKey == "" {
+		return ""
+	}
+	if key, ok := metric.GetTag(e.PartitionKey); ok {
+		return key
+	}
+	if key, ok := metric.GetField(e.PartitionKey); ok {
+		if strKey, ok := key.(string); ok {
+			return strKey
+		}
+	}
+	return ""
+}
+
 func init() {
 	outputs.Add("event_hubs", func() telegraf.Output {
 		return &EventHubs{
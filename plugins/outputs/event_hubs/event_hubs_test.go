@@ -5,14 +5,18 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	eventhub "github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/serializers/json"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -30,6 +34,11 @@ func (eh *mockEventHub) GetHub(s string) error {
 	return args.Error(0)
 }
 
+func (eh *mockEventHub) GetHubWithCredential(namespace, name string, credential azcore.TokenCredential) error {
+	args := eh.Called(namespace, name, credential)
+	return args.Error(0)
+}
+
 func (eh *mockEventHub) Close(ctx context.Context) error {
 	args := eh.Called(ctx)
 	return args.Error(0)
@@ -66,6 +75,157 @@ func TestInitAndWrite(t *testing.T) {
 	mockHub.AssertExpectations(t)
 }
 
+func TestInitAuth(t *testing.T) {
+	mockHub := &mockEventHub{}
+	e := &EventHubs{
+		Hub:        mockHub,
+		Namespace:  "telegraf.servicebus.windows.net",
+		EventHub:   "metrics",
+		AuthMethod: "managed_identity",
+		ClientID:   "00000000-0000-0000-0000-000000000000",
+	}
+	mockHub.On("GetHubWithCredential", "telegraf", "metrics", mock.Anything).Return(nil).Once()
+	require.NoError(t, e.Init())
+	mockHub.AssertExpectations(t)
+
+	tests := []struct {
+		name     string
+		plugin   *EventHubs
+		expected string
+	}{
+		{
+			name:     "no auth",
+			plugin:   &EventHubs{},
+			expected: "either connection_string or auth_method required",
+		},
+		{
+			name:     "no namespace",
+			plugin:   &EventHubs{AuthMethod: "default", EventHub: "metrics"},
+			expected: "namespace and event_hub required for Azure Active Directory authentication",
+		},
+		{
+			name:     "invalid method",
+			plugin:   &EventHubs{AuthMethod: "password", Namespace: "telegraf", EventHub: "metrics"},
+			expected: `invalid auth_method "password"`,
+		},
+		{
+			name:     "service principal without secret",
+			plugin:   &EventHubs{AuthMethod: "service_principal", Namespace: "telegraf", EventHub: "metrics", TenantID: "tenant"},
+			expected: "tenant_id, client_id and client_secret required for service principal authentication",
+		},
+		{
+			name:     "message size too large",
+			plugin:   &EventHubs{ConnectionString: "mock", MaxMessageSize: 2 * 1024 * 1024},
+			expected: "max_message_size has to be between 0 and 1048576 bytes",
+		},
+		{
+			name:     "invalid template",
+			plugin:   &EventHubs{ConnectionString: "mock", PartitionKey: "{{ .Tag }"},
+			expected: "parsing partition_key template failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Hub = &mockEventHub{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWritePartitionKey(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a", "region": "eu"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b", "region": "eu"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "a", "region": "us"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"value": 4}, time.Unix(0, 0)),
+	}
+
+	tests := []struct {
+		name         string
+		partitionKey string
+		expected     map[string]int
+	}{
+		{
+			name:     "no key",
+			expected: map[string]int{"NoPartitionKey": 4},
+		},
+		{
+			name:         "tag",
+			partitionKey: "region",
+			expected:     map[string]int{"eu": 2, "us": 1, "NoPartitionKey": 1},
+		},
+		{
+			name:         "template",
+			partitionKey: `{{ .Tag "region" }}-{{ .Tag "host" }}`,
+			expected:     map[string]int{"eu-a": 1, "eu-b": 1, "us-a": 1, "-a": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serializer := &json.Serializer{}
+			require.NoError(t, serializer.Init())
+
+			mockHub := &mockEventHub{}
+			e := &EventHubs{
+				Hub:              mockHub,
+				ConnectionString: "mock",
+				Timeout:          config.Duration(time.Second * 5),
+				PartitionKey:     tt.partitionKey,
+				Log:              testutil.Logger{},
+				serializer:       serializer,
+			}
+			mockHub.On("GetHub", mock.Anything).Return(nil).Once()
+			require.NoError(t, e.Init())
+
+			var iterator *eventhub.EventBatchIterator
+			mockHub.On("SendBatch", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				iterator = args.Get(1).(*eventhub.EventBatchIterator)
+			}).Return(nil).Once()
+			require.NoError(t, e.Write(metrics))
+			mockHub.AssertExpectations(t)
+
+			actual := make(map[string]int)
+			for key, events := range iterator.PartitionEventsMap {
+				actual[key] = len(events)
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestWriteOversizedEvent(t *testing.T) {
+	serializer := &json.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	mockHub := &mockEventHub{}
+	e := &EventHubs{
+		Hub:              mockHub,
+		ConnectionString: "mock",
+		Timeout:          config.Duration(time.Second * 5),
+		MaxMessageSize:   100,
+		Log:              testutil.Logger{},
+		serializer:       serializer,
+	}
+	mockHub.On("GetHub", mock.Anything).Return(nil).Once()
+	require.NoError(t, e.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("small", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("large", map[string]string{}, map[string]interface{}{"value": strings.Repeat("x", 100)}, time.Unix(0, 0)),
+	}
+
+	var iterator *eventhub.EventBatchIterator
+	mockHub.On("SendBatch", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		iterator = args.Get(1).(*eventhub.EventBatchIterator)
+	}).Return(nil).Once()
+	require.NoError(t, e.Write(metrics))
+	mockHub.AssertExpectations(t)
+
+	events := iterator.PartitionEventsMap["NoPartitionKey"]
+	require.Len(t, events, 1)
+	require.Contains(t, string(events[0].Data), `"name":"small"`)
+}
+
 /*
 ** Integration test (requires an Event Hubs instance)
  */
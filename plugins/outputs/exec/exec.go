@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -29,6 +30,7 @@ type Exec struct {
 	Environment    []string        `toml:"environment"`
 	Timeout        config.Duration `toml:"timeout"`
 	UseBatchFormat bool            `toml:"use_batch_format"`
+	BatchMetadata  string          `toml:"batch_metadata"`
 	Log            telegraf.Logger `toml:"-"`
 
 	runner     Runner
@@ -40,6 +42,12 @@ func (*Exec) SampleConfig() string {
 }
 
 func (e *Exec) Init() error {
+	switch e.BatchMetadata {
+	case "", "none", "env", "header":
+	default:
+		return fmt.Errorf("invalid 'batch_metadata' %q", e.BatchMetadata)
+	}
+
 	e.runner = &CommandRunner{log: e.Log}
 
 	return nil
@@ -68,13 +76,14 @@ func (e *Exec) Write(metrics []telegraf.Metric) error {
 		if err != nil {
 			return err
 		}
-		buffer.Write(serializedMetrics)
-
-		if buffer.Len() <= 0 {
+		if len(serializedMetrics) == 0 {
 			return nil
 		}
 
-		return e.runner.Run(time.Duration(e.Timeout), e.Command, e.Environment, &buffer)
+		env := e.writeMetadata(&buffer, metrics...)
+		buffer.Write(serializedMetrics)
+
+		return e.runner.Run(time.Duration(e.Timeout), e.Command, env, &buffer)
 	}
 	errs := make([]error, 0, len(metrics))
 	for _, metric := range metrics {
@@ -83,14 +92,50 @@ func (e *Exec) Write(metrics []telegraf.Metric) error {
 			return err
 		}
 		buffer.Reset()
+		env := e.writeMetadata(&buffer, metric)
 		buffer.Write(serializedMetric)
 
-		err = e.runner.Run(time.Duration(e.Timeout), e.Command, e.Environment, &buffer)
+		err = e.runner.Run(time.Duration(e.Timeout), e.Command, env, &buffer)
 		errs = append(errs, err)
 	}
 	return errors.Join(errs...)
 }
 
+// writeMetadata adds the metadata of the batch either as header line to the
+// buffer or as environment variables to the returned environment, depending
+// on the 'batch_metadata' setting.
+func (e *Exec) writeMetadata(buffer *bytes.Buffer, metrics ...telegraf.Metric) []string {
+	if e.BatchMetadata == "" || e.BatchMetadata == "none" {
+		return e.Environment
+	}
+
+	count := strconv.Itoa(len(metrics))
+	oldest, newest := metrics[0].Time(), metrics[0].Time()
+	for _, m := range metrics[1:] {
+		if m.Time().Before(oldest) {
+			oldest = m.Time()
+		}
+		if m.Time().After(newest) {
+			newest = m.Time()
+		}
+	}
+	first := strconv.FormatInt(oldest.UnixNano(), 10)
+	last := strconv.FormatInt(newest.UnixNano(), 10)
+
+	if e.BatchMetadata == "header" {
+		fmt.Fprintf(buffer, "# batch count=%s oldest=%s newest=%s\n", count, first, last)
+		return e.Environment
+	}
+
+	env := make([]string, 0, len(e.Environment)+3)
+	env = append(env, e.Environment...)
+	return append(env,
+		"TELEGRAF_BATCH_COUNT="+count,
+		"TELEGRAF_BATCH_OLDEST="+first,
+		"TELEGRAF_BATCH_NEWEST="+last,
+	)
+}
+
 // Runner provides an interface for running exec.Cmd.
 type Runner interface {
 	Run(time.Duration, []string, []string, io.Reader) error
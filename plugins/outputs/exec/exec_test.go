@@ -97,6 +97,93 @@ func TestExternalOutputNoBatch(t *testing.T) {
 	require.NoError(t, e.Close())
 }
 
+type recordingRunner struct {
+	env   []string
+	input string
+}
+
+func (r *recordingRunner) Run(_ time.Duration, _, env []string, buffer io.Reader) error {
+	input, err := io.ReadAll(buffer)
+	if err != nil {
+		return err
+	}
+	r.env = env
+	r.input = string(input)
+	return nil
+}
+
+func TestBatchMetadata(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 50}, now.Add(10*time.Second)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 40}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 30}, now.Add(5*time.Second)),
+	}
+
+	tests := []struct {
+		name          string
+		metadata      string
+		expectedEnv   []string
+		expectedInput string
+	}{
+		{
+			name:        "none",
+			expectedEnv: []string{"FOO=bar"},
+			expectedInput: "cpu idle=50i 1593533770000000000\n" +
+				"cpu idle=40i 1593533760000000000\n" +
+				"cpu idle=30i 1593533765000000000\n",
+		},
+		{
+			name:     "env",
+			metadata: "env",
+			expectedEnv: []string{
+				"FOO=bar",
+				"TELEGRAF_BATCH_COUNT=3",
+				"TELEGRAF_BATCH_OLDEST=1593533760000000000",
+				"TELEGRAF_BATCH_NEWEST=1593533770000000000",
+			},
+			expectedInput: "cpu idle=50i 1593533770000000000\n" +
+				"cpu idle=40i 1593533760000000000\n" +
+				"cpu idle=30i 1593533765000000000\n",
+		},
+		{
+			name:        "header",
+			metadata:    "header",
+			expectedEnv: []string{"FOO=bar"},
+			expectedInput: "# batch count=3 oldest=1593533760000000000 newest=1593533770000000000\n" +
+				"cpu idle=50i 1593533770000000000\n" +
+				"cpu idle=40i 1593533760000000000\n" +
+				"cpu idle=30i 1593533765000000000\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serializer := &influx.Serializer{}
+			require.NoError(t, serializer.Init())
+
+			e := &Exec{
+				Environment:    []string{"FOO=bar"},
+				UseBatchFormat: true,
+				BatchMetadata:  tt.metadata,
+				Log:            testutil.Logger{},
+			}
+			require.NoError(t, e.Init())
+			runner := &recordingRunner{}
+			e.runner = runner
+			e.SetSerializer(serializer)
+
+			require.NoError(t, e.Write(metrics))
+			require.Equal(t, tt.expectedEnv, runner.env)
+			require.Equal(t, tt.expectedInput, runner.input)
+		})
+	}
+}
+
+func TestInvalidBatchMetadata(t *testing.T) {
+	e := &Exec{BatchMetadata: "stdin"}
+	require.EqualError(t, e.Init(), `invalid 'batch_metadata' "stdin"`)
+}
+
 func TestExec(t *testing.T) {
 	t.Skip("Skipping test due to OS/executable dependencies and race condition when ran as part of a test-all")
 
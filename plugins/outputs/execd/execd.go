@@ -23,8 +23,12 @@ type Execd struct {
 	Command                  []string        `toml:"command"`
 	Environment              []string        `toml:"environment"`
 	RestartDelay             config.Duration `toml:"restart_delay"`
+	RestartDelayMax          config.Duration `toml:"restart_delay_max"`
+	RestartPolicy            string          `toml:"restart_policy"`
+	MaxRuntime               config.Duration `toml:"max_runtime"`
 	IgnoreSerializationError bool            `toml:"ignore_serialization_error"`
 	UseBatchFormat           bool            `toml:"use_batch_format"`
+	BatchMetadata            string          `toml:"batch_metadata"`
 	Log                      telegraf.Logger
 
 	process    *process.Process
@@ -44,6 +48,24 @@ func (e *Execd) Init() error {
 		return errors.New("no command specified")
 	}
 
+	switch e.RestartPolicy {
+	case "":
+		e.RestartPolicy = process.RestartAlways
+	case process.RestartAlways, process.RestartOnFailure, process.RestartNever:
+	default:
+		return fmt.Errorf("invalid 'restart_policy' %q", e.RestartPolicy)
+	}
+
+	switch e.BatchMetadata {
+	case "", "none":
+	case "header":
+		if !e.UseBatchFormat {
+			return errors.New("'batch_metadata' requires 'use_batch_format'")
+		}
+	default:
+		return fmt.Errorf("invalid 'batch_metadata' %q", e.BatchMetadata)
+	}
+
 	var err error
 
 	e.process, err = process.New(e.Command, e.Environment)
@@ -52,6 +74,9 @@ func (e *Execd) Init() error {
 	}
 	e.process.Log = e.Log
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
+	e.process.RestartDelayMax = time.Duration(e.RestartDelayMax)
+	e.process.RestartPolicy = e.RestartPolicy
+	e.process.MaxRuntime = time.Duration(e.MaxRuntime)
 	e.process.ReadStdoutFn = e.cmdReadOut
 	e.process.ReadStderrFn = e.cmdReadErr
 
@@ -85,6 +110,10 @@ func (e *Execd) Write(metrics []telegraf.Metric) error {
 			return fmt.Errorf("error serializing metrics: %w", err)
 		}
 
+		if e.BatchMetadata == "header" {
+			b = append(batchHeader(metrics), b...)
+		}
+
 		if _, err = e.process.Stdin.Write(b); err != nil {
 			return fmt.Errorf("error writing metrics: %w", err)
 		}
@@ -107,6 +136,23 @@ func (e *Execd) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// batchHeader returns the header line containing the number of metrics and
+// the timestamps of the oldest and newest metric of the batch
+func batchHeader(metrics []telegraf.Metric) []byte {
+	var oldest, newest int64
+	for i, m := range metrics {
+		ts := m.Time().UnixNano()
+		if i == 0 || ts < oldest {
+			oldest = ts
+		}
+		if i == 0 || ts > newest {
+			newest = ts
+		}
+	}
+
+	return fmt.Appendf(nil, "# batch count=%d oldest=%d newest=%d\n", len(metrics), oldest, newest)
+}
+
 func (e *Execd) cmdReadErr(out io.Reader) {
 	scanner := bufio.NewScanner(out)
 
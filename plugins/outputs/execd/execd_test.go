@@ -201,6 +201,48 @@ func TestPartiallyUnserializableCanBeSkipped(t *testing.T) {
 	require.NoError(t, e.Close())
 }
 
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Execd
+		expected string
+	}{
+		{
+			name:     "invalid restart policy",
+			plugin:   &Execd{Command: []string{"cat"}, RestartPolicy: "sometimes"},
+			expected: `invalid 'restart_policy' "sometimes"`,
+		},
+		{
+			name:     "invalid batch metadata",
+			plugin:   &Execd{Command: []string{"cat"}, UseBatchFormat: true, BatchMetadata: "env"},
+			expected: `invalid 'batch_metadata' "env"`,
+		},
+		{
+			name:     "batch metadata without batch format",
+			plugin:   &Execd{Command: []string{"cat"}, BatchMetadata: "header"},
+			expected: "'batch_metadata' requires 'use_batch_format'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestBatchHeader(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 50}, now.Add(10*time.Second)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 40}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 30}, now.Add(5*time.Second)),
+	}
+
+	expected := "# batch count=3 oldest=1593533760000000000 newest=1593533770000000000\n"
+	require.Equal(t, expected, string(batchHeader(metrics)))
+}
+
 var testoutput = flag.Bool("testoutput", false,
 	"if true, act like line input program instead of test")
 
@@ -48,6 +48,7 @@ type HTTP struct {
 	Password                config.Secret             `toml:"password"`
 	Headers                 map[string]*config.Secret `toml:"headers"`
 	ContentEncoding         string                    `toml:"content_encoding"`
+	CompressionLevel        *int                      `toml:"compression_level"`
 	UseBatchFormat          bool                      `toml:"use_batch_format"`
 	AwsService              string                    `toml:"aws_service"`
 	NonRetryableStatusCodes []int                     `toml:"non_retryable_statuscodes"`
@@ -56,6 +57,7 @@ type HTTP struct {
 
 	client     *http.Client
 	serializer telegraf.Serializer
+	encoder    internal.ContentEncoder
 
 	awsCfg *aws.Config
 	common_aws.CredentialConfig
@@ -89,6 +91,16 @@ func (h *HTTP) Connect() error {
 		return fmt.Errorf("invalid method [%s] %s", h.URL, h.Method)
 	}
 
+	var options []internal.EncodingOption
+	if h.CompressionLevel != nil {
+		options = append(options, internal.WithCompressionLevel(*h.CompressionLevel))
+	}
+	encoder, err := internal.NewContentEncoder(h.ContentEncoding, options...)
+	if err != nil {
+		return err
+	}
+	h.encoder = encoder
+
 	ctx := context.Background()
 	client, err := h.HTTPClientConfig.CreateClient(ctx, h.Log)
 	if err != nil {
@@ -132,14 +144,11 @@ func (h *HTTP) Write(metrics []telegraf.Metric) error {
 }
 
 func (h *HTTP) writeMetric(reqBody []byte) error {
-	var reqBodyBuffer io.Reader = bytes.NewBuffer(reqBody)
-
-	var err error
-	if h.ContentEncoding == "gzip" {
-		rc := internal.CompressWithGzip(reqBodyBuffer)
-		defer rc.Close()
-		reqBodyBuffer = rc
+	reqBody, err := h.encoder.Encode(reqBody)
+	if err != nil {
+		return fmt.Errorf("encoding request body failed: %w", err)
 	}
+	var reqBodyBuffer io.Reader = bytes.NewBuffer(reqBody)
 
 	var payloadHash *string
 	if h.awsCfg != nil {
@@ -204,8 +213,14 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 
 	req.Header.Set("User-Agent", internal.ProductToken())
 	req.Header.Set("Content-Type", defaultContentType)
-	if h.ContentEncoding == "gzip" {
-		req.Header.Set("Content-Encoding", "gzip")
+	switch h.ContentEncoding {
+	case "", "identity":
+		// No encoding applied to the body
+	case "zlib":
+		// HTTP uses the name "deflate" for the zlib format
+		req.Header.Set("Content-Encoding", "deflate")
+	default:
+		req.Header.Set("Content-Encoding", h.ContentEncoding)
 	}
 
 	for k, v := range h.Headers {
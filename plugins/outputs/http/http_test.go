@@ -417,6 +417,110 @@ func TestContentEncodingGzip(t *testing.T) {
 	}
 }
 
+func TestContentEncodingCompression(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	u, err := url.Parse("http://" + ts.Listener.Addr().String())
+	require.NoError(t, err)
+
+	level := func(l int) *int { return &l }
+
+	tests := []struct {
+		name      string
+		encoding  string
+		level     *int
+		header    string
+		algorithm string
+	}{
+		{
+			name:      "zlib",
+			encoding:  "zlib",
+			header:    "deflate",
+			algorithm: "zlib",
+		},
+		{
+			name:      "zstd with level",
+			encoding:  "zstd",
+			level:     level(7),
+			header:    "zstd",
+			algorithm: "zstd",
+		},
+		{
+			name:      "snappy",
+			encoding:  "snappy",
+			header:    "snappy",
+			algorithm: "snappy",
+		},
+		{
+			name:      "lz4 with level",
+			encoding:  "lz4",
+			level:     level(9),
+			header:    "lz4",
+			algorithm: "lz4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if contentHeader := r.Header.Get("Content-Encoding"); contentHeader != tt.header {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Errorf("Not equal, expected: %q, actual: %q", tt.header, contentHeader)
+					return
+				}
+
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				decoder, err := internal.NewContentDecoder(tt.algorithm)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				payload, err := decoder.Decode(body)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				if !strings.Contains(string(payload), "cpu value=42") {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Errorf("'payload' should contain %q", "cpu value=42")
+					return
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			plugin := &HTTP{
+				URL:              u.String(),
+				ContentEncoding:  tt.encoding,
+				CompressionLevel: tt.level,
+			}
+			serializer := &influx.Serializer{}
+			require.NoError(t, serializer.Init())
+			plugin.SetSerializer(serializer)
+			require.NoError(t, plugin.Connect())
+			require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+		})
+	}
+}
+
+func TestContentEncodingInvalidLevel(t *testing.T) {
+	level := 5
+	plugin := &HTTP{
+		URL:              defaultURL,
+		ContentEncoding:  "zstd",
+		CompressionLevel: &level,
+	}
+	require.ErrorContains(t, plugin.Connect(), "invalid compression level")
+}
+
 func TestBasicAuth(t *testing.T) {
 	ts := httptest.NewServer(http.NotFoundHandler())
 	defer ts.Close()
@@ -96,6 +96,7 @@ type HTTPConfig struct {
 	Proxy                     *url.URL
 	Headers                   map[string]string
 	ContentEncoding           string
+	CompressionLevel          *int
 	Database                  string
 	DatabaseTag               string
 	ExcludeDatabaseTag        bool
@@ -111,8 +112,9 @@ type HTTPConfig struct {
 }
 
 type httpClient struct {
-	client *http.Client
-	config HTTPConfig
+	client  *http.Client
+	config  HTTPConfig
+	encoder internal.ContentEncoder
 	// Tracks that the 'create database` statement was executed for the
 	// database.  An attempt to create the database is made each time a new
 	// database is encountered in the database_tag and after a "database not
@@ -189,6 +191,25 @@ func NewHTTPClient(cfg HTTPConfig) (*httpClient, error) {
 		return nil, fmt.Errorf("unsupported scheme %q", cfg.URL.Scheme)
 	}
 
+	// All encodings except gzip with the default level require encoding the
+	// serialized batch at once
+	var encoder internal.ContentEncoder
+	switch {
+	case cfg.ContentEncoding == "", cfg.ContentEncoding == "identity":
+		// No encoding applied to the body
+	case cfg.ContentEncoding == "gzip" && cfg.CompressionLevel == nil:
+		// Compressed while streaming the body
+	default:
+		var options []internal.EncodingOption
+		if cfg.CompressionLevel != nil {
+			options = append(options, internal.WithCompressionLevel(*cfg.CompressionLevel))
+		}
+		var err error
+		if encoder, err = internal.NewContentEncoder(cfg.ContentEncoding, options...); err != nil {
+			return nil, err
+		}
+	}
+
 	client := &httpClient{
 		client: &http.Client{
 			Timeout:   cfg.Timeout,
@@ -196,6 +217,7 @@ func NewHTTPClient(cfg HTTPConfig) (*httpClient, error) {
 		},
 		createDatabaseExecuted: make(map[string]bool),
 		config:                 cfg,
+		encoder:                encoder,
 		log:                    cfg.Log,
 	}
 	return client, nil
@@ -344,7 +366,10 @@ func (c *httpClient) writeBatch(ctx context.Context, db, rp string, metrics []te
 		return fmt.Errorf("failed making write url: %w", err)
 	}
 
-	reader := c.requestBodyReader(metrics)
+	reader, err := c.requestBodyReader(metrics)
+	if err != nil {
+		return fmt.Errorf("failed encoding write req: %w", err)
+	}
 	defer reader.Close()
 
 	req, err := c.makeWriteRequest(loc, reader)
@@ -480,8 +505,14 @@ func (c *httpClient) makeWriteRequest(address string, body io.Reader) (*http.Req
 		return nil, err
 	}
 
-	if c.config.ContentEncoding == "gzip" {
-		req.Header.Set("Content-Encoding", "gzip")
+	switch c.config.ContentEncoding {
+	case "", "identity":
+		// No encoding applied to the body
+	case "zlib":
+		// HTTP uses the name "deflate" for the zlib format
+		req.Header.Set("Content-Encoding", "deflate")
+	default:
+		req.Header.Set("Content-Encoding", c.config.ContentEncoding)
 	}
 
 	return req, nil
@@ -489,14 +520,26 @@ func (c *httpClient) makeWriteRequest(address string, body io.Reader) (*http.Req
 
 // requestBodyReader warp io.Reader from influx.NewReader to io.ReadCloser, which is useful to fast close the write
 // side of the connection in case of error
-func (c *httpClient) requestBodyReader(metrics []telegraf.Metric) io.ReadCloser {
+func (c *httpClient) requestBodyReader(metrics []telegraf.Metric) (io.ReadCloser, error) {
 	reader := influx.NewReader(metrics, c.config.Serializer)
 
+	if c.encoder != nil {
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := c.encoder.Encode(body)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(encoded)), nil
+	}
+
 	if c.config.ContentEncoding == "gzip" {
-		return internal.CompressWithGzip(reader)
+		return internal.CompressWithGzip(reader), nil
 	}
 
-	return io.NopCloser(reader)
+	return io.NopCloser(reader), nil
 }
 
 func (c *httpClient) addHeaders(req *http.Request) error {
@@ -647,6 +647,77 @@ func TestHTTP_WriteContentEncodingGzip(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestHTTP_WriteContentEncodingZstd(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/write":
+				if contentHeader := r.Header.Get("Content-Encoding"); contentHeader != "zstd" {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Errorf("Not equal, expected: %q, actual: %q", "zstd", contentHeader)
+					return
+				}
+
+				payload, err := io.ReadAll(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				decoder, err := internal.NewZstdDecoder()
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				body, err := decoder.Decode(payload)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				if !strings.Contains(string(body), "cpu value=42") {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Errorf("'body' should contain %q", "cpu value=42")
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+		},
+		),
+	)
+	defer ts.Close()
+
+	u, err := url.Parse(fmt.Sprintf("http://%s/", ts.Listener.Addr().String()))
+	require.NoError(t, err)
+
+	m := metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+
+	level := 11
+	cfg := influxdb.HTTPConfig{
+		URL:              u,
+		Database:         "telegraf",
+		ContentEncoding:  "zstd",
+		CompressionLevel: &level,
+		Log:              testutil.Logger{},
+	}
+
+	client, err := influxdb.NewHTTPClient(cfg)
+	require.NoError(t, err)
+	require.NoError(t, client.Write(context.Background(), []telegraf.Metric{m}))
+}
+
 func TestHTTP_UnixSocket(t *testing.T) {
 	tmpdir := t.TempDir()
 
@@ -56,6 +56,7 @@ type InfluxDB struct {
 	HTTPProxy                 string            `toml:"http_proxy"`
 	HTTPHeaders               map[string]string `toml:"http_headers"`
 	ContentEncoding           string            `toml:"content_encoding"`
+	CompressionLevel          *int              `toml:"compression_level"`
 	SkipDatabaseCreation      bool              `toml:"skip_database_creation"`
 	InfluxUintSupport         bool              `toml:"influx_uint_support"`
 	OmitTimestamp             bool              `toml:"influx_omit_timestamp"`
@@ -251,6 +252,7 @@ func (i *InfluxDB) httpClient(ctx context.Context, address *url.URL, localAddr *
 		Password:                  i.Password,
 		Proxy:                     proxy,
 		ContentEncoding:           i.ContentEncoding,
+		CompressionLevel:          i.CompressionLevel,
 		Headers:                   i.HTTPHeaders,
 		Database:                  i.Database,
 		DatabaseTag:               i.DatabaseTag,
@@ -0,0 +1,122 @@
+package influxdb_v3
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight/flightsql/driver"
+
+	"github.com/influxdata/telegraf"
+)
+
+// flightSQL returns the FlightSQL connection for the given database, the
+// connection is created on first use
+func (i *InfluxDB) flightSQL(database string) (*sql.DB, error) {
+	if db, found := i.databases[database]; found {
+		return db, nil
+	}
+
+	dsn, err := i.flightSQLDSN(database)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("flightsql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening FlightSQL connection failed: %w", err)
+	}
+	i.databases[database] = db
+
+	return db, nil
+}
+
+func (i *InfluxDB) flightSQLDSN(database string) (string, error) {
+	cfg := &driver.DriverConfig{
+		Address: i.FlightSQLAddress,
+		Timeout: i.timeout(),
+		Params:  map[string]string{"database": database},
+	}
+
+	if !i.Token.Empty() {
+		token, err := i.Token.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting token failed: %w", err)
+		}
+		cfg.Token = token.String()
+		token.Destroy()
+	}
+
+	// The FlightSQL endpoint is served by the same server as the write API
+	// so use TLS if the write URL does
+	if i.writeURL.Scheme == "https" {
+		tlsCfg, err := i.ClientConfig.TLSConfig()
+		if err != nil {
+			return "", err
+		}
+		cfg.TLSEnabled = true
+		cfg.TLSConfig = tlsCfg
+	}
+
+	return cfg.DSN(), nil
+}
+
+// verify checks the written metrics are queryable by counting the rows of
+// each table in the time-range of the metrics
+func (i *InfluxDB) verify(ctx context.Context, database string, metrics []telegraf.Metric) {
+	db, err := i.flightSQL(database)
+	if err != nil {
+		i.Log.Errorf("Verifying writes to database %q failed: %v", database, err)
+		return
+	}
+
+	type timerange struct {
+		min, max time.Time
+	}
+	tables := make(map[string]*timerange)
+	for _, m := range metrics {
+		t := m.Time()
+		r, found := tables[m.Name()]
+		if !found {
+			tables[m.Name()] = &timerange{min: t, max: t}
+			continue
+		}
+		if t.Before(r.min) {
+			r.min = t
+		}
+		if t.After(r.max) {
+			r.max = t
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, i.timeout())
+	defer cancel()
+	for table, r := range tables {
+		var count int64
+		query := verifyQuery(table, r.min, r.max)
+		if err := db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+			i.Log.Errorf("Verifying writes to table %q in database %q failed: %v", table, database, err)
+			continue
+		}
+		if count == 0 {
+			i.Log.Warnf("No rows found in table %q of database %q after writing", table, database)
+			continue
+		}
+		i.Log.Tracef("Verified %d rows in table %q of database %q", count, table, database)
+	}
+}
+
+func verifyQuery(table string, start, end time.Time) string {
+	return fmt.Sprintf(
+		`SELECT COUNT(*) FROM "%s" WHERE time >= to_timestamp_nanos(%d) AND time <= to_timestamp_nanos(%d)`,
+		strings.ReplaceAll(table, `"`, `""`), start.UnixNano(), end.UnixNano(),
+	)
+}
+
+func (i *InfluxDB) timeout() time.Duration {
+	if i.Timeout > 0 {
+		return time.Duration(i.Timeout)
+	}
+	return 5 * time.Second
+}
@@ -0,0 +1,375 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package influxdb_v3
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type InfluxDB struct {
+	URL                string            `toml:"url"`
+	Token              config.Secret     `toml:"token"`
+	Database           string            `toml:"database"`
+	DatabaseTag        string            `toml:"database_tag"`
+	ExcludeDatabaseTag bool              `toml:"exclude_database_tag"`
+	TableTag           string            `toml:"table_tag"`
+	ExcludeTableTag    bool              `toml:"exclude_table_tag"`
+	WriteAPI           string            `toml:"write_api"`
+	AcceptPartial      bool              `toml:"accept_partial"`
+	NoSync             bool              `toml:"no_sync"`
+	ContentEncoding    string            `toml:"content_encoding"`
+	HTTPHeaders        map[string]string `toml:"http_headers"`
+	FlightSQLAddress   string            `toml:"flightsql_address"`
+	VerifyWrites       bool              `toml:"verify_writes"`
+	Log                telegraf.Logger   `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client     *http.Client
+	serializer *influx.Serializer
+	encoder    internal.ContentEncoder
+	writeURL   *url.URL
+	databases  map[string]*sql.DB
+}
+
+// partialWriteResponse is the error body returned by the v3 write API if
+// some of the lines were rejected
+type partialWriteResponse struct {
+	Error string `json:"error"`
+	Data  []struct {
+		OriginalLine string `json:"original_line"`
+		LineNumber   int    `json:"line_number"`
+		ErrorMessage string `json:"error_message"`
+	} `json:"data"`
+}
+
+func (*InfluxDB) SampleConfig() string {
+	return sampleConfig
+}
+
+func (i *InfluxDB) Init() error {
+	if i.URL == "" {
+		i.URL = "http://127.0.0.1:8181"
+	}
+	if i.Database == "" {
+		return errors.New("'database' required")
+	}
+	if i.VerifyWrites && i.FlightSQLAddress == "" {
+		return errors.New("'flightsql_address' required for verifying writes")
+	}
+
+	u, err := url.Parse(i.URL)
+	if err != nil {
+		return fmt.Errorf("parsing URL failed: %w", err)
+	}
+	switch i.WriteAPI {
+	case "", "v3":
+		i.WriteAPI = "v3"
+		u.Path = path.Join(u.Path, "/api/v3/write_lp")
+	case "v2":
+		u.Path = path.Join(u.Path, "/api/v2/write")
+	default:
+		return fmt.Errorf("invalid 'write_api' setting %q", i.WriteAPI)
+	}
+	i.writeURL = u
+
+	switch i.ContentEncoding {
+	case "", "gzip":
+		i.ContentEncoding = "gzip"
+		enc, err := internal.NewGzipEncoder()
+		if err != nil {
+			return fmt.Errorf("setting up gzip encoder failed: %w", err)
+		}
+		i.encoder = enc
+	case "identity":
+	default:
+		return fmt.Errorf("invalid content encoding %q", i.ContentEncoding)
+	}
+
+	// InfluxDB 3 supports unsigned integers natively
+	i.serializer = &influx.Serializer{UintSupport: true}
+	return i.serializer.Init()
+}
+
+func (i *InfluxDB) Connect() error {
+	client, err := i.HTTPClientConfig.CreateClient(context.Background(), i.Log)
+	if err != nil {
+		return err
+	}
+	i.client = client
+
+	if i.FlightSQLAddress == "" {
+		return nil
+	}
+
+	// Use the FlightSQL connection as health check of the database
+	i.databases = make(map[string]*sql.DB)
+	db, err := i.flightSQL(i.Database)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), i.timeout())
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("checking FlightSQL connection failed: %w", err)
+	}
+
+	return nil
+}
+
+func (i *InfluxDB) Close() error {
+	if i.client != nil {
+		i.client.CloseIdleConnections()
+	}
+	for _, db := range i.databases {
+		db.Close()
+	}
+	return nil
+}
+
+func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
+	ctx := context.Background()
+
+	batches := make(map[string][]telegraf.Metric)
+	indices := make(map[string][]int)
+	for idx, m := range metrics {
+		m, database := i.route(m)
+		batches[database] = append(batches[database], m)
+		indices[database] = append(indices[database], idx)
+	}
+
+	var werr internal.PartialWriteError
+	for database, batch := range batches {
+		err := i.writeBatch(ctx, database, batch)
+		if err == nil {
+			werr.MetricsAccept = append(werr.MetricsAccept, indices[database]...)
+			if i.VerifyWrites {
+				i.verify(ctx, database, batch)
+			}
+			continue
+		}
+
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && !apiErr.retryable {
+			i.Log.Errorf("Dropping %d metrics for database %q: %v", len(batch), database, err)
+			werr.MetricsReject = append(werr.MetricsReject, indices[database]...)
+			werr.Err = err
+			continue
+		}
+		werr.Err = err
+	}
+
+	if werr.Err == nil {
+		return nil
+	}
+	return &werr
+}
+
+// route returns the metric with the table name taken from the table tag and
+// the database the metric should be written to
+func (i *InfluxDB) route(m telegraf.Metric) (telegraf.Metric, string) {
+	database := i.Database
+	if i.DatabaseTag != "" {
+		if v, found := m.GetTag(i.DatabaseTag); found {
+			database = v
+		}
+	}
+	table := ""
+	if i.TableTag != "" {
+		table, _ = m.GetTag(i.TableTag)
+	}
+
+	modify := table != "" ||
+		(i.ExcludeDatabaseTag && m.HasTag(i.DatabaseTag)) ||
+		(i.ExcludeTableTag && m.HasTag(i.TableTag))
+	if !modify {
+		return m, database
+	}
+
+	// Avoid modifying the original metric, it might be shared with other outputs
+	m = m.Copy()
+	m.Accept()
+	if table != "" {
+		m.SetName(table)
+	}
+	if i.ExcludeDatabaseTag {
+		m.RemoveTag(i.DatabaseTag)
+	}
+	if i.ExcludeTableTag {
+		m.RemoveTag(i.TableTag)
+	}
+	return m, database
+}
+
+func (i *InfluxDB) writeBatch(ctx context.Context, database string, metrics []telegraf.Metric) error {
+	body, err := i.serializer.SerializeBatch(metrics)
+	if err != nil {
+		return fmt.Errorf("serializing metrics failed: %w", err)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if i.encoder != nil {
+		if body, err = i.encoder.Encode(body); err != nil {
+			return fmt.Errorf("encoding failed: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.makeWriteURL(database), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	if err := i.setHeaders(req); err != nil {
+		return err
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		internal.OnClientError(i.client, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	// Split the batch if the request was too large
+	if resp.StatusCode == http.StatusRequestEntityTooLarge && len(metrics) > 1 {
+		i.Log.Warnf("Request to database %q too large, splitting batch of %d metrics", database, len(metrics))
+		mid := len(metrics) / 2
+		if err := i.writeBatch(ctx, database, metrics[:mid]); err != nil {
+			return err
+		}
+		return i.writeBatch(ctx, database, metrics[mid:])
+	}
+
+	return newAPIError(resp)
+}
+
+func (i *InfluxDB) makeWriteURL(database string) string {
+	params := url.Values{}
+	switch i.WriteAPI {
+	case "v3":
+		params.Set("db", database)
+		params.Set("precision", "nanosecond")
+		if !i.AcceptPartial {
+			params.Set("accept_partial", "false")
+		}
+		if i.NoSync {
+			params.Set("no_sync", "true")
+		}
+	case "v2":
+		params.Set("bucket", database)
+		params.Set("precision", "ns")
+	}
+
+	u := *i.writeURL
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+func (i *InfluxDB) setHeaders(req *http.Request) error {
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", internal.ProductToken())
+	if i.encoder != nil {
+		req.Header.Set("Content-Encoding", i.ContentEncoding)
+	}
+
+	if !i.Token.Empty() {
+		token, err := i.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		// The v2 compatibility API expects the v2 token scheme
+		if i.WriteAPI == "v2" {
+			req.Header.Set("Authorization", "Token "+token.String())
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token.String())
+		}
+		token.Destroy()
+	}
+
+	for k, v := range i.HTTPHeaders {
+		if strings.EqualFold(k, "host") {
+			req.Host = v
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+	return nil
+}
+
+// apiError is an error response of the write API
+type apiError struct {
+	statusCode int
+	message    string
+	retryable  bool
+}
+
+func newAPIError(resp *http.Response) *apiError {
+	//nolint:errcheck // the body is only used for the error message
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	message := strings.TrimSpace(string(body))
+	var partial partialWriteResponse
+	if json.Unmarshal(body, &partial) == nil && partial.Error != "" {
+		lines := make([]string, 0, len(partial.Data)+1)
+		lines = append(lines, partial.Error)
+		for _, d := range partial.Data {
+			lines = append(lines, fmt.Sprintf("line %d: %s", d.LineNumber, d.ErrorMessage))
+		}
+		message = strings.Join(lines, "; ")
+	}
+
+	// Client errors except authentication and rate-limiting failures won't
+	// succeed on retry
+	retryable := true
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized,
+		resp.StatusCode == http.StatusForbidden,
+		resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		retryable = false
+	}
+
+	return &apiError{
+		statusCode: resp.StatusCode,
+		message:    message,
+		retryable:  retryable,
+	}
+}
+
+func (e *apiError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("writing failed with status %d", e.statusCode)
+	}
+	return fmt.Sprintf("writing failed with status %d: %s", e.statusCode, e.message)
+}
+
+func init() {
+	outputs.Add("influxdb_v3", func() telegraf.Output {
+		return &InfluxDB{
+			AcceptPartial: true,
+		}
+	})
+}
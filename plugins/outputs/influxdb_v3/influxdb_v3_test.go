@@ -0,0 +1,373 @@
+package influxdb_v3
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// request is a write request received by the test server
+type request struct {
+	path   string
+	query  url.Values
+	header http.Header
+	body   string
+}
+
+// newServer starts a test server recording all write requests and replying
+// with the given handler
+func newServer(t *testing.T, handler func(w http.ResponseWriter, r request)) (*httptest.Server, func() []request) {
+	var mu sync.Mutex
+	var requests []request
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+			defer gz.Close()
+			reader = gz
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+
+		req := request{
+			path:   r.URL.Path,
+			query:  r.URL.Query(),
+			header: r.Header,
+			body:   string(body),
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		if handler == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		handler(w, req)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts, func() []request {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]request(nil), requests...)
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *InfluxDB
+		expected string
+	}{
+		{
+			name:     "missing database",
+			plugin:   &InfluxDB{},
+			expected: "'database' required",
+		},
+		{
+			name:     "invalid write API",
+			plugin:   &InfluxDB{Database: "telegraf", WriteAPI: "v1"},
+			expected: `invalid 'write_api' setting "v1"`,
+		},
+		{
+			name:     "invalid content encoding",
+			plugin:   &InfluxDB{Database: "telegraf", ContentEncoding: "zstd"},
+			expected: `invalid content encoding "zstd"`,
+		},
+		{
+			name:     "verify without FlightSQL",
+			plugin:   &InfluxDB{Database: "telegraf", VerifyWrites: true},
+			expected: "'flightsql_address' required for verifying writes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWriteV3(t *testing.T) {
+	ts, requests := newServer(t, nil)
+
+	plugin := &InfluxDB{
+		URL:           ts.URL,
+		Token:         config.NewSecret([]byte("secret")),
+		Database:      "telegraf",
+		AcceptPartial: true,
+		NoSync:        true,
+		Log:           &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"count": uint64(3)},
+			time.Unix(0, 1),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	actual := requests()
+	require.Len(t, actual, 1)
+	require.Equal(t, "/api/v3/write_lp", actual[0].path)
+	require.Equal(t, url.Values{
+		"db":        []string{"telegraf"},
+		"precision": []string{"nanosecond"},
+		"no_sync":   []string{"true"},
+	}, actual[0].query)
+	require.Equal(t, "Bearer secret", actual[0].header.Get("Authorization"))
+	require.Equal(t, "gzip", actual[0].header.Get("Content-Encoding"))
+	require.Equal(t, "cpu,host=a count=3u 1\n", actual[0].body)
+}
+
+func TestWriteV2(t *testing.T) {
+	ts, requests := newServer(t, nil)
+
+	plugin := &InfluxDB{
+		URL:             ts.URL,
+		Token:           config.NewSecret([]byte("secret")),
+		Database:        "telegraf",
+		WriteAPI:        "v2",
+		ContentEncoding: "identity",
+		Log:             &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 1)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	actual := requests()
+	require.Len(t, actual, 1)
+	require.Equal(t, "/api/v2/write", actual[0].path)
+	require.Equal(t, url.Values{
+		"bucket":    []string{"telegraf"},
+		"precision": []string{"ns"},
+	}, actual[0].query)
+	require.Equal(t, "Token secret", actual[0].header.Get("Authorization"))
+	require.Empty(t, actual[0].header.Get("Content-Encoding"))
+	require.Equal(t, "cpu value=42 1\n", actual[0].body)
+}
+
+func TestRouting(t *testing.T) {
+	ts, requests := newServer(t, nil)
+
+	plugin := &InfluxDB{
+		URL:                ts.URL,
+		Database:           "telegraf",
+		DatabaseTag:        "db",
+		ExcludeDatabaseTag: true,
+		TableTag:           "table",
+		ExcludeTableTag:    true,
+		AcceptPartial:      true,
+		Log:                &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	input := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"db": "infra", "table": "machine_cpu"},
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, 1),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": 2.0},
+			time.Unix(0, 2),
+		),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	bodies := make(map[string]string)
+	for _, r := range requests() {
+		bodies[r.query.Get("db")] = r.body
+	}
+	require.Equal(t, map[string]string{
+		"infra":    "machine_cpu value=1 1\n",
+		"telegraf": "mem,host=a value=2 2\n",
+	}, bodies)
+
+	// The original metrics must not be modified
+	require.Equal(t, "cpu", input[0].Name())
+	require.True(t, input[0].HasTag("db"))
+	require.True(t, input[0].HasTag("table"))
+}
+
+func TestPartialWrite(t *testing.T) {
+	ts, _ := newServer(t, func(w http.ResponseWriter, r request) {
+		if r.query.Get("db") != "invalid" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		//nolint:errcheck // ignore the returned error as the test will fail anyway
+		w.Write([]byte(`{
+			"error": "partial write of line protocol occurred",
+			"data": [
+				{"original_line": "cpu value=\"a\" 1", "line_number": 1, "error_message": "invalid column type for column 'value'"}
+			]
+		}`))
+	})
+
+	plugin := &InfluxDB{
+		URL:           ts.URL,
+		Database:      "telegraf",
+		DatabaseTag:   "db",
+		AcceptPartial: true,
+		Log:           &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"db": "invalid"}, map[string]interface{}{"value": "a"}, time.Unix(0, 1)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 2)),
+	}
+	err := plugin.Write(metrics)
+	require.ErrorContains(t, err, "line 1: invalid column type for column 'value'")
+
+	var werr *internal.PartialWriteError
+	require.ErrorAs(t, err, &werr)
+	require.Equal(t, []int{1}, werr.MetricsAccept)
+	require.Equal(t, []int{0}, werr.MetricsReject)
+}
+
+func TestRetryableErrors(t *testing.T) {
+	for _, code := range []int{http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(code), func(t *testing.T) {
+			ts, _ := newServer(t, func(w http.ResponseWriter, _ request) {
+				w.WriteHeader(code)
+			})
+
+			plugin := &InfluxDB{
+				URL:      ts.URL,
+				Database: "telegraf",
+				Log:      &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			defer plugin.Close()
+
+			metrics := []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 1)),
+			}
+			err := plugin.Write(metrics)
+			require.Error(t, err)
+
+			var werr *internal.PartialWriteError
+			require.ErrorAs(t, err, &werr)
+			require.Empty(t, werr.MetricsAccept)
+			require.Empty(t, werr.MetricsReject)
+
+			var apiErr *apiError
+			require.True(t, errors.As(err, &apiErr))
+			require.Equal(t, code, apiErr.statusCode)
+		})
+	}
+}
+
+func TestSplitTooLarge(t *testing.T) {
+	ts, requests := newServer(t, func(w http.ResponseWriter, r request) {
+		if strings.Count(r.body, "\n") > 1 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	plugin := &InfluxDB{
+		URL:      ts.URL,
+		Database: "telegraf",
+		Log:      &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 1)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(0, 2)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(0, 3)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	var written []string
+	for _, r := range requests() {
+		if strings.Count(r.body, "\n") == 1 {
+			written = append(written, r.body)
+		}
+	}
+	require.Equal(t, []string{"cpu value=1 1\n", "cpu value=2 2\n", "cpu value=3 3\n"}, written)
+}
+
+func TestFlightSQLDSN(t *testing.T) {
+	plugin := &InfluxDB{
+		URL:              "https://localhost:8181",
+		Token:            config.NewSecret([]byte("secret")),
+		Database:         "telegraf",
+		FlightSQLAddress: "localhost:8181",
+		Log:              &testutil.Logger{},
+	}
+	plugin.ClientConfig.InsecureSkipVerify = true
+	require.NoError(t, plugin.Init())
+
+	dsn, err := plugin.flightSQLDSN("infra")
+	require.NoError(t, err)
+
+	u, err := url.Parse(dsn)
+	require.NoError(t, err)
+	require.Equal(t, "flightsql", u.Scheme)
+	require.Equal(t, "localhost:8181", u.Host)
+	require.Equal(t, "infra", u.Query().Get("database"))
+	require.Equal(t, "secret", u.Query().Get("token"))
+	require.Equal(t, "5s", u.Query().Get("timeout"))
+	require.NotEmpty(t, u.Query().Get("tls"))
+}
+
+func TestVerifyQuery(t *testing.T) {
+	query := verifyQuery(`my"table`, time.Unix(0, 10), time.Unix(0, 20))
+	require.Equal(t,
+		`SELECT COUNT(*) FROM "my""table" WHERE time >= to_timestamp_nanos(10) AND time <= to_timestamp_nanos(20)`,
+		query,
+	)
+}
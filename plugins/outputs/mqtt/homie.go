@@ -24,9 +24,9 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 			return nil, "", fmt.Errorf("generating device name failed: %w", err)
 		}
 		messages = append(messages,
-			message{topic + "/$homie", []byte("4.0")},
-			message{topic + "/$name", []byte(deviceName)},
-			message{topic + "/$state", []byte("ready")},
+			message{topic + "/$homie", []byte("4.0"), false},
+			message{topic + "/$name", []byte(deviceName), false},
+			message{topic + "/$state", []byte("ready"), false},
 		)
 		m.homieSeen[topic] = make(map[string]bool)
 	}
@@ -46,8 +46,8 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 		}
 		sort.Strings(nodeIDs)
 		messages = append(messages,
-			message{topic + "/$nodes", []byte(strings.Join(nodeIDs, ","))},
-			message{topic + "/" + nodeID + "/$name", []byte(nodeName)},
+			message{topic + "/$nodes", []byte(strings.Join(nodeIDs, ",")), false},
+			message{topic + "/" + nodeID + "/$name", []byte(nodeName), false},
 		)
 	}
 
@@ -63,6 +63,7 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 	messages = append(messages, message{
 		topic + "/" + nodeID + "/$properties",
 		[]byte(strings.Join(properties, ",")),
+		false,
 	})
 
 	return messages, nodeID, nil
@@ -11,6 +11,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/mqtt"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -22,6 +23,7 @@ var sampleConfig string
 type message struct {
 	topic   string
 	payload []byte
+	retain  bool
 }
 
 type MQTT struct {
@@ -31,12 +33,14 @@ type MQTT struct {
 	Layout          string          `toml:"layout"`
 	HomieDeviceName string          `toml:"homie_device_name"`
 	HomieNodeID     string          `toml:"homie_node_id"`
+	RetainMetrics   []string        `toml:"retain_measurements"`
 	Log             telegraf.Logger `toml:"-"`
 	mqtt.MqttConfig
 
-	client     mqtt.Client
-	serializer telegraf.Serializer
-	generator  *TopicNameGenerator
+	client       mqtt.Client
+	serializer   telegraf.Serializer
+	generator    *TopicNameGenerator
+	retainFilter filter.Filter
 
 	homieDeviceNameGenerator *HomieGenerator
 	homieNodeIDGenerator     *HomieGenerator
@@ -67,6 +71,11 @@ func (m *MQTT) Init() error {
 		return err
 	}
 
+	m.retainFilter, err = filter.Compile(m.RetainMetrics)
+	if err != nil {
+		return fmt.Errorf("creating retain filter failed: %w", err)
+	}
+
 	switch m.Layout {
 	case "":
 		// For backward compatibility
@@ -98,6 +107,10 @@ func (m *MQTT) Init() error {
 		return fmt.Errorf("invalid layout %q", m.Layout)
 	}
 
+	if m.generator.UsesFieldName() && m.Layout != "field" {
+		return fmt.Errorf("field name in topic is not supported for layout %q", m.Layout)
+	}
+
 	return nil
 }
 
@@ -165,7 +178,8 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 	}
 
 	for _, msg := range topicMessages {
-		if err := m.client.Publish(msg.topic, msg.payload); err != nil {
+		opts := mqtt.PublishOptions{Retain: m.Retain || msg.retain}
+		if err := m.client.PublishWithOptions(msg.topic, msg.payload, opts); err != nil {
 			// We do receive a timeout error if the remote broker is down,
 			// so let's retry the metrics in this case and drop them otherwise.
 			if errors.Is(err, internal.ErrTimeout) {
@@ -194,12 +208,17 @@ func (m *MQTT) collectNonBatch(hostname string, metrics []telegraf.Metric) []mes
 			m.Log.Debugf("metric was: %v", metric)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		collection = append(collection, message{topic, buf, m.retainMetric(metric)})
 	}
 
 	return collection
 }
 
+// retainMetric returns true if the metric should be retained by the broker
+func (m *MQTT) retainMetric(metric telegraf.Metric) bool {
+	return m.retainFilter != nil && m.retainFilter.Match(metric.Name())
+}
+
 func (m *MQTT) collectBatch(hostname string, metrics []telegraf.Metric) []message {
 	metricsCollection := make(map[string][]telegraf.Metric)
 	for _, metric := range metrics {
@@ -219,7 +238,13 @@ func (m *MQTT) collectBatch(hostname string, metrics []telegraf.Metric) []messag
 			m.Log.Warnf("Could not serialize metric batch for topic %q: %v", topic, err)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+
+		// Only retain the batch if all contained metrics should be retained
+		retain := true
+		for _, metric := range ms {
+			retain = retain && m.retainMetric(metric)
+		}
+		collection = append(collection, message{topic, buf, retain})
 	}
 	return collection
 }
@@ -227,21 +252,31 @@ func (m *MQTT) collectBatch(hostname string, metrics []telegraf.Metric) []messag
 func (m *MQTT) collectField(hostname string, metrics []telegraf.Metric) []message {
 	var collection []message
 	for _, metric := range metrics {
-		topic, err := m.generator.Generate(hostname, metric)
-		if err != nil {
-			m.Log.Warnf("Generating topic name failed: %v", err)
-			m.Log.Debugf("metric was: %v", metric)
-			continue
-		}
-
+		retain := m.retainMetric(metric)
 		for n, v := range metric.Fields() {
+			// Use the field name in the topic template if referenced or
+			// append it to the metric topic otherwise
+			var topic string
+			var err error
+			if m.generator.UsesFieldName() {
+				topic, err = m.generator.GenerateField(hostname, metric, n)
+			} else {
+				topic, err = m.generator.Generate(hostname, metric)
+				topic += "/" + n
+			}
+			if err != nil {
+				m.Log.Warnf("Generating topic name failed: %v", err)
+				m.Log.Debugf("metric was: %v", metric)
+				continue
+			}
+
 			buf, err := internal.ToString(v)
 			if err != nil {
 				m.Log.Warnf("Could not serialize metric for topic %q field %q: %v", topic, n, err)
 				m.Log.Debugf("metric was: %v", metric)
 				continue
 			}
-			collection = append(collection, message{topic + "/" + n, []byte(buf)})
+			collection = append(collection, message{topic, []byte(buf), retain})
 		}
 	}
 
@@ -266,13 +301,14 @@ func (m *MQTT) collectHomieV4(hostname string, metrics []telegraf.Metric) []mess
 		}
 		path := topic + "/" + nodeID
 		collection = append(collection, msgs...)
+		retain := m.retainMetric(metric)
 
 		for _, tag := range metric.TagList() {
 			propID := normalizeID(tag.Key)
 			collection = append(collection,
-				message{path + "/" + propID, []byte(tag.Value)},
-				message{path + "/" + propID + "/$name", []byte(tag.Key)},
-				message{path + "/" + propID + "/$datatype", []byte("string")},
+				message{path + "/" + propID, []byte(tag.Value), retain},
+				message{path + "/" + propID + "/$name", []byte(tag.Key), retain},
+				message{path + "/" + propID + "/$datatype", []byte("string"), retain},
 			)
 		}
 
@@ -285,9 +321,9 @@ func (m *MQTT) collectHomieV4(hostname string, metrics []telegraf.Metric) []mess
 			}
 			propID := normalizeID(field.Key)
 			collection = append(collection,
-				message{path + "/" + propID, []byte(v)},
-				message{path + "/" + propID + "/$name", []byte(field.Key)},
-				message{path + "/" + propID + "/$datatype", []byte(dt)},
+				message{path + "/" + propID, []byte(v), retain},
+				message{path + "/" + propID + "/$name", []byte(field.Key), retain},
+				message{path + "/" + propID + "/$datatype", []byte(dt), retain},
 			)
 		}
 	}
@@ -508,7 +508,7 @@ func TestIntegrationMQTTLayoutField(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{msg.Topic(), msg.Payload(), msg.Retained()})
 	}
 
 	// Add routing for the messages
@@ -625,7 +625,7 @@ func TestIntegrationMQTTLayoutHomieV4(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{msg.Topic(), msg.Payload(), msg.Retained()})
 	}
 
 	// Add routing for the messages
@@ -931,3 +931,118 @@ func TestGenerateTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestFieldNameTopicInvalidLayout(t *testing.T) {
+	plugin := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers: []string{"tcp://localhost:1883"},
+		},
+		Topic:  "telegraf/{{ .PluginName }}/{{ .FieldName }}",
+		Layout: "batch",
+	}
+	require.ErrorContains(t, plugin.Init(), "field name in topic is not supported")
+}
+
+func TestCollectFieldTopicTemplate(t *testing.T) {
+	input := metric.New(
+		"modbus",
+		map[string]string{"source": "device 1"},
+		map[string]interface{}{
+			"temperature": 21.4,
+			"supplied":    true,
+		},
+		time.Unix(1676522982, 0),
+	)
+
+	tests := []struct {
+		name     string
+		topic    string
+		expected []string
+	}{
+		{
+			name:  "field appended",
+			topic: `telegraf/{{ .PluginName }}/{{ .Tag "source" }}`,
+			expected: []string{
+				"telegraf/modbus/device 1/temperature 21.4",
+				"telegraf/modbus/device 1/supplied true",
+			},
+		},
+		{
+			name:  "field in template",
+			topic: `telegraf/{{ .FieldName }}/{{ .Tag "source" }}`,
+			expected: []string{
+				"telegraf/temperature/device 1 21.4",
+				"telegraf/supplied/device 1 true",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &MQTT{
+				MqttConfig: mqtt.MqttConfig{
+					Servers: []string{"tcp://localhost:1883"},
+				},
+				Topic:  tt.topic,
+				Layout: "field",
+				Log:    testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := make([]string, 0, len(tt.expected))
+			for _, msg := range plugin.collectField("hostname", []telegraf.Metric{input}) {
+				actual = append(actual, msg.topic+" "+string(msg.payload))
+			}
+			require.ElementsMatch(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestRetainMeasurements(t *testing.T) {
+	s := &serializers_influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	plugin := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers: []string{"tcp://localhost:1883"},
+		},
+		Topic:         "telegraf/{{ .PluginName }}/{{ .Tag \"host\" }}",
+		RetainMetrics: []string{"status*"},
+		Log:           testutil.Logger{},
+		serializer:    s,
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("status", map[string]string{"host": "a"}, map[string]interface{}{"up": true}, time.Unix(0, 0)),
+		metric.New("status_detail", map[string]string{"host": "a"}, map[string]interface{}{"code": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+		metric.New("status", map[string]string{"host": "b"}, map[string]interface{}{"up": false}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage": 23.0}, time.Unix(0, 0)),
+	}
+
+	// Each metric is retained based on its name
+	retained := make(map[string]bool)
+	for _, msg := range plugin.collectNonBatch("", input) {
+		retained[msg.topic] = msg.retain
+	}
+	expected := map[string]bool{
+		"telegraf/status/a":        true,
+		"telegraf/status_detail/a": true,
+		"telegraf/cpu/a":           false,
+		"telegraf/status/b":        true,
+		"telegraf/cpu/b":           false,
+	}
+	require.Equal(t, expected, retained)
+
+	// Batches are only retained if all metrics should be retained
+	plugin.Topic = "telegraf/{{ .Tag \"host\" }}"
+	require.NoError(t, plugin.Init())
+	retained = make(map[string]bool)
+	for _, msg := range plugin.collectBatch("", input[:2]) {
+		retained[msg.topic] = msg.retain
+	}
+	for _, msg := range plugin.collectBatch("", input[3:]) {
+		retained[msg.topic] = msg.retain
+	}
+	require.Equal(t, map[string]bool{"telegraf/a": true, "telegraf/b": false}, retained)
+}
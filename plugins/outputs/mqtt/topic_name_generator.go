@@ -14,8 +14,11 @@ type TopicNameGenerator struct {
 	Hostname    string
 	TopicPrefix string
 	PluginName  string
+	FieldName   string
 	metric      telegraf.Metric
 	template    *template.Template
+
+	usesFieldName bool
 }
 
 func NewTopicNameGenerator(topicPrefix, topic string) (*TopicNameGenerator, error) {
@@ -28,7 +31,16 @@ func NewTopicNameGenerator(topicPrefix, topic string) (*TopicNameGenerator, erro
 			return nil, fmt.Errorf("found forbidden character %s in the topic name %s", p, topic)
 		}
 	}
-	return &TopicNameGenerator{TopicPrefix: topicPrefix, template: tt}, nil
+	return &TopicNameGenerator{
+		TopicPrefix:   topicPrefix,
+		template:      tt,
+		usesFieldName: strings.Contains(topic, ".FieldName"),
+	}, nil
+}
+
+// UsesFieldName returns true if the topic template references the field name
+func (t *TopicNameGenerator) UsesFieldName() bool {
+	return t.usesFieldName
 }
 
 func (t *TopicNameGenerator) Tag(key string) string {
@@ -37,9 +49,15 @@ func (t *TopicNameGenerator) Tag(key string) string {
 }
 
 func (t *TopicNameGenerator) Generate(hostname string, m telegraf.Metric) (string, error) {
+	return t.GenerateField(hostname, m, "")
+}
+
+// GenerateField creates the topic name for the given field of the metric
+func (t *TopicNameGenerator) GenerateField(hostname string, m telegraf.Metric, field string) (string, error) {
 	t.Hostname = hostname
 	t.metric = m
 	t.PluginName = m.Name()
+	t.FieldName = field
 	var b strings.Builder
 	err := t.template.Execute(&b, t)
 	if err != nil {
@@ -58,3 +58,46 @@ func (p *Postgresql) derivePgDatatype(value interface{}) string {
 		return PgText
 	}
 }
+
+// Order of the numeric data types from the narrowest to the widest type
+var numericRank = map[string]int{
+	PgSmallInt:        1,
+	PgInteger:         2,
+	PgBigInt:          3,
+	PgReal:            4,
+	PgDoublePrecision: 5,
+	PgUint8:           6,
+	PgNumeric:         7,
+}
+
+// widenPgDatatype returns the data type able to hold values of both the
+// current and the needed data type. If no such type exists, false is returned.
+func widenPgDatatype(current, needed string) (string, bool) {
+	if current == needed {
+		return current, true
+	}
+
+	cr, cNumeric := numericRank[current]
+	nr, nNumeric := numericRank[needed]
+	switch {
+	case current == PgText:
+		return PgText, true
+	case cNumeric && nNumeric:
+		// A 'real' cannot hold all values of integer types and 'uint8' cannot
+		// hold negative values, so fall back to the next type able to do so
+		wider, other := current, needed
+		if nr > cr {
+			wider, other = needed, current
+		}
+		switch {
+		case wider == PgReal && numericRank[other] <= numericRank[PgBigInt]:
+			return PgDoublePrecision, true
+		case wider == PgUint8:
+			return PgNumeric, true
+		}
+		return wider, true
+	case needed == PgText, current == PgBool && nNumeric, cNumeric && needed == PgBool:
+		return PgText, true
+	}
+	return current, false
+}
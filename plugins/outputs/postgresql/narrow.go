@@ -0,0 +1,42 @@
+package postgresql
+
+import (
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Name of the column holding the field name in the narrow schema mode
+const narrowFieldColumn = "field"
+
+// toNarrowMetrics converts the metrics to the entity-attribute-value layout of
+// the narrow schema mode, i.e. each field is converted to a separate metric
+// holding the field name and the value in a column depending on the value's
+// type.
+func toNarrowMetrics(metrics []telegraf.Metric) []telegraf.Metric {
+	result := make([]telegraf.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		for _, field := range m.FieldList() {
+			fields := map[string]interface{}{
+				narrowFieldColumn:              field.Key,
+				narrowValueColumn(field.Value): field.Value,
+			}
+			result = append(result, metric.New(m.Name(), m.Tags(), fields, m.Time(), m.Type()))
+		}
+	}
+	return result
+}
+
+func narrowValueColumn(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return "value_bool"
+	case int64:
+		return "value_int"
+	case uint64:
+		return "value_uint"
+	case float64:
+		return "value_float"
+	default:
+		return "value_string"
+	}
+}
@@ -42,6 +42,8 @@ type Postgresql struct {
 	ForeignTagConstraint       bool                    `toml:"foreign_tag_constraint"`
 	TagsAsJsonb                bool                    `toml:"tags_as_jsonb"`
 	FieldsAsJsonb              bool                    `toml:"fields_as_jsonb"`
+	SchemaMode                 string                  `toml:"schema_mode"`
+	ColumnTypePolicy           string                  `toml:"column_type_policy"`
 	TimestampColumnName        string                  `toml:"timestamp_column_name"`
 	TimestampColumnType        string                  `toml:"timestamp_column_type"`
 	CreateTemplates            []*sqltemplate.Template `toml:"create_templates"`
@@ -53,6 +55,7 @@ type Postgresql struct {
 	TagCacheSize               int                     `toml:"tag_cache_size"`
 	ColumnNameLenLimit         int                     `toml:"column_name_length_limit"`
 	LogLevel                   string                  `toml:"log_level"`
+	TimescaleDB                TimescaleDB             `toml:"timescaledb"`
 	Logger                     telegraf.Logger         `toml:"-"`
 
 	dbContext       context.Context
@@ -97,6 +100,37 @@ func (p *Postgresql) Init() error {
 		return fmt.Errorf("unknown timestamp column type %q", p.TimestampColumnType)
 	}
 
+	switch p.SchemaMode {
+	case "":
+		p.SchemaMode = "wide"
+	case "wide":
+	case "jsonb":
+		p.TagsAsJsonb = true
+		p.FieldsAsJsonb = true
+	case "narrow":
+		if p.FieldsAsJsonb {
+			return errors.New("'fields_as_jsonb' cannot be used with the narrow schema mode")
+		}
+	default:
+		return fmt.Errorf("unknown schema mode %q", p.SchemaMode)
+	}
+
+	switch p.ColumnTypePolicy {
+	case "":
+		p.ColumnTypePolicy = "strict"
+	case "strict", "widen":
+	default:
+		return fmt.Errorf("unknown column type policy %q", p.ColumnTypePolicy)
+	}
+
+	if p.TimescaleDB.Hypertable {
+		tmpls, err := p.TimescaleDB.createTemplates(p.TimestampColumnName, p.TagsAsForeignKeys, p.TagsAsJsonb)
+		if err != nil {
+			return err
+		}
+		p.CreateTemplates = append(p.CreateTemplates, tmpls...)
+	}
+
 	// Initialize the column prototypes
 	p.timeColumn = utils.Column{
 		Name: p.TimestampColumnName,
@@ -234,6 +268,9 @@ func (p *Postgresql) Write(metrics []telegraf.Metric) error {
 		p.tagsCache.ResetStatistics()
 	}
 
+	if p.SchemaMode == "narrow" {
+		metrics = toNarrowMetrics(metrics)
+	}
 	tableSources := NewTableSources(p, metrics)
 
 	var err error
@@ -490,6 +527,9 @@ func newPostgresql() *Postgresql {
 		RetryMaxBackoff:            config.Duration(time.Second * 15),
 		Logger:                     logger.New("outputs", "postgresql", ""),
 		LogLevel:                   "warn",
+		TimescaleDB: TimescaleDB{
+			ChunkTimeInterval: config.Duration(7 * 24 * time.Hour),
+		},
 	}
 
 	p.CreateTemplates[0].UnmarshalText([]byte(`CREATE TABLE {{ .table }} ({{ .columns }})`))
@@ -1212,3 +1212,106 @@ func TestLongColumnNamesClipIntegration(t *testing.T) {
 		require.EqualValues(t, expected[i], actual)
 	}
 }
+
+func TestInitInvalidSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(p *Postgresql)
+		expected string
+	}{
+		{
+			name:     "unknown schema mode",
+			setup:    func(p *Postgresql) { p.SchemaMode = "tall" },
+			expected: `unknown schema mode "tall"`,
+		},
+		{
+			name: "narrow with fields as jsonb",
+			setup: func(p *Postgresql) {
+				p.SchemaMode = "narrow"
+				p.FieldsAsJsonb = true
+			},
+			expected: "'fields_as_jsonb' cannot be used with the narrow schema mode",
+		},
+		{
+			name:     "unknown column type policy",
+			setup:    func(p *Postgresql) { p.ColumnTypePolicy = "convert" },
+			expected: `unknown column type policy "convert"`,
+		},
+		{
+			name: "invalid chunk interval",
+			setup: func(p *Postgresql) {
+				p.TimescaleDB.Hypertable = true
+				p.TimescaleDB.ChunkTimeInterval = 0
+			},
+			expected: "'chunk_time_interval' must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPostgresql()
+			tt.setup(p)
+			require.ErrorContains(t, p.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitSchemaModeJSONB(t *testing.T) {
+	p := newPostgresql()
+	p.SchemaMode = "jsonb"
+	require.NoError(t, p.Init())
+	require.True(t, p.TagsAsJsonb)
+	require.True(t, p.FieldsAsJsonb)
+}
+
+func TestWriteIntegration_narrow(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	p, err := newPostgresqlTest(t)
+	require.NoError(t, err)
+	p.SchemaMode = "narrow"
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+
+	metrics := []telegraf.Metric{
+		newMetric(t, "", MSS{"host": "a"}, MSI{"usage": 42.5, "count": 3, "status": "ok"}),
+	}
+	require.NoError(t, p.Write(metrics))
+
+	dump := dbTableDump(t, p.db, "")
+	require.Len(t, dump, 3)
+	values := make(map[string]interface{}, len(dump))
+	for _, row := range dump {
+		require.EqualValues(t, "a", row["host"])
+		field := row["field"].(string)
+		for _, col := range []string{"value_float", "value_int", "value_string"} {
+			if row[col] != nil {
+				values[field] = row[col]
+			}
+		}
+	}
+	require.EqualValues(t, MSI{"usage": 42.5, "count": 3, "status": "ok"}, values)
+}
+
+func TestWriteIntegration_widen(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	p, err := newPostgresqlTest(t)
+	require.NoError(t, err)
+	p.ColumnTypePolicy = "widen"
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+
+	require.NoError(t, p.Write([]telegraf.Metric{newMetric(t, "", MSS{}, MSI{"v": 1, "s": true})}))
+	require.NoError(t, p.Write([]telegraf.Metric{newMetric(t, "", MSS{}, MSI{"v": 2.5, "s": "text"})}))
+
+	dump := dbTableDump(t, p.db, "")
+	require.Len(t, dump, 2)
+	require.EqualValues(t, 1, dump[0]["v"])
+	require.EqualValues(t, "true", dump[0]["s"])
+	require.EqualValues(t, 2.5, dump[1]["v"])
+	require.EqualValues(t, "text", dump[1]["s"])
+}
@@ -142,6 +142,85 @@ func (tm *TableManager) MatchSource(ctx context.Context, db dbh, rowSource *Tabl
 			strings.Join(colDefs, ", "))
 	}
 
+	if tm.ColumnTypePolicy == "widen" && !tm.FieldsAsJsonb {
+		if err := tm.widenColumns(ctx, db, metricTable, rowSource.FieldColumns(), tagTable); err != nil {
+			if isTempError(err) {
+				return err
+			}
+			tm.Postgresql.Logger.Errorf("Permanent error widening columns of %s: %v", metricTable.name, err)
+		}
+
+		// Use the types of the table to convert the values if necessary
+		metricTable.RLock()
+		rowSource.fieldColumns.SetTypes(metricTable.columns)
+		metricTable.RUnlock()
+	}
+
+	return nil
+}
+
+// widenColumns alters the type of existing field columns which cannot hold the
+// values of the given columns to a type able to hold both.
+func (tm *TableManager) widenColumns(
+	ctx context.Context,
+	db dbh,
+	tbl *tableState,
+	columns []utils.Column,
+	tagsTable *tableState,
+) error {
+	tbl.RLock()
+	widenCols := diffWidenColumns(tbl.columns, columns)
+	tbl.RUnlock()
+	if len(widenCols) == 0 {
+		return nil
+	}
+
+	// Lock the tables in the same order as in EnsureStructure to prevent deadlocks
+	if tagsTable != nil {
+		tagsTable.RLock()
+		defer tagsTable.RUnlock()
+	}
+	tbl.Lock()
+	defer tbl.Unlock()
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // In case of failure during commit, "err" from commit will be returned
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", schemaAdvisoryLockID); err != nil {
+		return err
+	}
+
+	// Another process might have altered the table in the meantime
+	currCols, err := tm.getColumns(ctx, tx, tbl.name)
+	if err != nil {
+		return err
+	}
+	tbl.columns = currCols
+	widenCols = diffWidenColumns(currCols, columns)
+	if len(widenCols) == 0 {
+		return nil
+	}
+
+	ident := sqltemplate.NewTable(tm.Schema, tbl.name, nil).String()
+	for _, col := range widenCols {
+		tm.Postgresql.Logger.Infof("Changing type of column %q in table %q from %q to %q", col.Name, tbl.name, currCols[col.Name].Type, col.Type)
+		name := sqltemplate.QuoteIdentifier(col.Name)
+		stmt := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s", ident, name, col.Type, name, col.Type)
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("executing %q: %w", stmt, err)
+		}
+	}
+
+	if currCols, err = tm.getColumns(ctx, tx, tbl.name); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	tbl.columns = currCols
+
 	return nil
 }
 
@@ -436,6 +515,23 @@ func diffMissingColumns(dbColumns map[string]utils.Column, srcColumns []utils.Co
 	return missingColumns
 }
 
+// diffWidenColumns returns the srcColumns present in dbColumns with a different
+// type, using the type able to hold the values of both.
+func diffWidenColumns(dbColumns map[string]utils.Column, srcColumns []utils.Column) []utils.Column {
+	var widenColumns []utils.Column
+	for _, srcCol := range srcColumns {
+		dbCol, ok := dbColumns[srcCol.Name]
+		if !ok || dbCol.Role != utils.FieldColType || dbCol.Type == srcCol.Type {
+			continue
+		}
+		if t, ok := widenPgDatatype(dbCol.Type, srcCol.Type); ok && t != dbCol.Type {
+			srcCol.Type = t
+			widenColumns = append(widenColumns, srcCol)
+		}
+	}
+	return widenColumns
+}
+
 func colMapToSlice(colMap map[string]utils.Column) []utils.Column {
 	if colMap == nil {
 		return nil
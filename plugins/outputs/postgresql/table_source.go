@@ -27,6 +27,28 @@ func (cl *columnList) Add(column utils.Column) {
 	cl.indices[column.Name] = len(cl.columns) - 1
 }
 
+// Widen adds the column or widens the type of an existing column with the
+// same name to be able to hold the values of both types
+func (cl *columnList) Widen(column utils.Column) {
+	idx, ok := cl.indices[column.Name]
+	if !ok {
+		cl.Add(column)
+		return
+	}
+	if t, ok := widenPgDatatype(cl.columns[idx].Type, column.Type); ok {
+		cl.columns[idx].Type = t
+	}
+}
+
+// SetTypes sets the type of the columns to the ones of the given columns
+func (cl *columnList) SetTypes(columns map[string]utils.Column) {
+	for i, col := range cl.columns {
+		if c, ok := columns[col.Name]; ok {
+			cl.columns[i].Type = c.Type
+		}
+	}
+}
+
 func (cl *columnList) Remove(name string) bool {
 	idx, ok := cl.indices[name]
 	if !ok {
@@ -114,7 +136,11 @@ func (tsrc *TableSource) AddMetric(metric telegraf.Metric) {
 
 	if !tsrc.postgresql.FieldsAsJsonb {
 		for _, f := range metric.FieldList() {
-			tsrc.fieldColumns.Add(tsrc.postgresql.columnFromField(f.Key, f.Value))
+			if tsrc.postgresql.ColumnTypePolicy == "widen" {
+				tsrc.fieldColumns.Widen(tsrc.postgresql.columnFromField(f.Key, f.Value))
+			} else {
+				tsrc.fieldColumns.Add(tsrc.postgresql.columnFromField(f.Key, f.Value))
+			}
 		}
 	}
 
@@ -302,6 +328,13 @@ func (tsrc *TableSource) getValues() ([]interface{}, error) {
 			if fPos, ok := tsrc.fieldColumns.indices[field.Key]; ok {
 				fieldValues[fPos] = field.Value
 				fieldsEmpty = false
+
+				// Values stored in widened text columns must be converted
+				if tsrc.fieldColumns.columns[fPos].Type == PgText {
+					if _, ok := field.Value.(string); !ok {
+						fieldValues[fPos] = fmt.Sprint(field.Value)
+					}
+				}
 			}
 		}
 		if fieldsEmpty {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/outputs/postgresql/utils"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestTableSource(_ *testing.T) {
@@ -324,3 +325,74 @@ func TestTagTableSourceIntegration_InconsistentTags(t *testing.T) {
 
 	require.ElementsMatch(t, expected, actual)
 }
+
+func TestWidenPgDatatype(t *testing.T) {
+	tests := []struct {
+		current  string
+		needed   string
+		expected string
+		ok       bool
+	}{
+		{current: PgBigInt, needed: PgBigInt, expected: PgBigInt, ok: true},
+		{current: PgSmallInt, needed: PgBigInt, expected: PgBigInt, ok: true},
+		{current: PgBigInt, needed: PgInteger, expected: PgBigInt, ok: true},
+		{current: PgBigInt, needed: PgDoublePrecision, expected: PgDoublePrecision, ok: true},
+		{current: PgReal, needed: PgBigInt, expected: PgDoublePrecision, ok: true},
+		{current: PgBigInt, needed: PgNumeric, expected: PgNumeric, ok: true},
+		{current: PgUint8, needed: PgBigInt, expected: PgNumeric, ok: true},
+		{current: PgBool, needed: PgBigInt, expected: PgText, ok: true},
+		{current: PgDoublePrecision, needed: PgText, expected: PgText, ok: true},
+		{current: PgText, needed: PgBigInt, expected: PgText, ok: true},
+		{current: PgTimestampWithoutTimeZone, needed: PgBigInt, expected: PgTimestampWithoutTimeZone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+"+"+tt.needed, func(t *testing.T) {
+			actual, ok := widenPgDatatype(tt.current, tt.needed)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestTableSourceWiden(t *testing.T) {
+	p := newPostgresql()
+	p.ColumnTypePolicy = "widen"
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("widen", nil, MSI{"a": 1, "b": 1}, time.Unix(0, 0)),
+		testutil.MustMetric("widen", nil, MSI{"a": 1.5, "b": "text"}, time.Unix(1, 0)),
+	}
+	tsrc := NewTableSources(p, metrics)["widen"]
+
+	types := make(map[string]string)
+	for _, col := range tsrc.FieldColumns() {
+		types[col.Name] = col.Type
+	}
+	require.Equal(t, map[string]string{"a": PgDoublePrecision, "b": PgText}, types)
+
+	// Non-string values of text columns are converted
+	row := nextSrcRow(tsrc)
+	require.EqualValues(t, "1", row["b"])
+	row = nextSrcRow(tsrc)
+	require.EqualValues(t, "text", row["b"])
+}
+
+func TestToNarrowMetrics(t *testing.T) {
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "a"},
+			MSI{"usage": 42.5, "count": 3, "total": uint64(5), "ok": true, "state": "idle"},
+			time.Unix(0, 0),
+		),
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, MSI{"field": "usage", "value_float": 42.5}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, MSI{"field": "count", "value_int": 3}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, MSI{"field": "total", "value_uint": uint64(5)}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, MSI{"field": "ok", "value_bool": true}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, MSI{"field": "state", "value_string": "idle"}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, toNarrowMetrics(input), testutil.SortMetrics())
+}
@@ -0,0 +1,69 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/sqltemplate"
+)
+
+// TimescaleDB contains the settings for creating metric tables as TimescaleDB hypertables
+type TimescaleDB struct {
+	Hypertable        bool            `toml:"hypertable"`
+	ChunkTimeInterval config.Duration `toml:"chunk_time_interval"`
+	CompressAfter     config.Duration `toml:"compress_after"`
+}
+
+// createTemplates returns the templates to execute after creating a metric table
+// for converting the table to a hypertable and setting up compression
+func (t *TimescaleDB) createTemplates(timeColumn string, tagsAsForeignKeys, tagsAsJsonb bool) ([]*sqltemplate.Template, error) {
+	if t.ChunkTimeInterval <= 0 {
+		return nil, errors.New("'chunk_time_interval' must be positive")
+	}
+	if t.CompressAfter < 0 {
+		return nil, errors.New("'compress_after' must not be negative")
+	}
+
+	stmts := []string{
+		fmt.Sprintf(
+			"SELECT create_hypertable({{ .table|quoteLiteral }}, %s, chunk_time_interval => %s)",
+			sqltemplate.QuoteLiteral(timeColumn),
+			pgInterval(time.Duration(t.ChunkTimeInterval)),
+		),
+	}
+
+	if t.CompressAfter > 0 {
+		// Segment the compressed data by series, i.e. by the tag columns or by
+		// the tag ID in case of a separate tag table
+		var segmentBy string
+		switch {
+		case tagsAsForeignKeys:
+			segmentBy = ", timescaledb.compress_segmentby = 'tag_id'"
+		case !tagsAsJsonb:
+			segmentBy = `{{ if .columns.Tags }}, timescaledb.compress_segmentby = {{ .columns.Tags.Identifiers|join ","|quoteLiteral }}{{ end }}`
+		}
+		stmts = append(stmts,
+			"ALTER TABLE {{ .table }} SET (timescaledb.compress"+segmentBy+")",
+			fmt.Sprintf(
+				"SELECT add_compression_policy({{ .table|quoteLiteral }}, %s)",
+				pgInterval(time.Duration(t.CompressAfter)),
+			),
+		)
+	}
+
+	tmpls := make([]*sqltemplate.Template, 0, len(stmts))
+	for _, stmt := range stmts {
+		tmpl := &sqltemplate.Template{}
+		if err := tmpl.UnmarshalText([]byte(stmt)); err != nil {
+			return nil, fmt.Errorf("parsing hypertable template failed: %w", err)
+		}
+		tmpls = append(tmpls, tmpl)
+	}
+	return tmpls, nil
+}
+
+func pgInterval(d time.Duration) string {
+	return fmt.Sprintf("INTERVAL '%d seconds'", int64(d.Seconds()))
+}
@@ -0,0 +1,75 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/sqltemplate"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/utils"
+)
+
+func TestTimescaleDBTemplates(t *testing.T) {
+	columns := []utils.Column{
+		{Name: "time", Type: PgTimestampWithoutTimeZone, Role: utils.TimeColType},
+		{Name: "host", Type: PgText, Role: utils.TagColType},
+		{Name: "cpu", Type: PgText, Role: utils.TagColType},
+		{Name: "usage", Type: PgDoublePrecision, Role: utils.FieldColType},
+	}
+	table := sqltemplate.NewTable("public", "cpu", columns)
+	empty := sqltemplate.NewTable("", "", nil)
+
+	tests := []struct {
+		name              string
+		compressAfter     time.Duration
+		tagsAsForeignKeys bool
+		expected          []string
+	}{
+		{
+			name: "hypertable only",
+			expected: []string{
+				`SELECT create_hypertable('"public"."cpu"', 'time', chunk_time_interval => INTERVAL '86400 seconds')`,
+			},
+		},
+		{
+			name:          "compression by tags",
+			compressAfter: 7 * 24 * time.Hour,
+			expected: []string{
+				`SELECT create_hypertable('"public"."cpu"', 'time', chunk_time_interval => INTERVAL '86400 seconds')`,
+				`ALTER TABLE "public"."cpu" SET (timescaledb.compress, timescaledb.compress_segmentby = '"cpu","host"')`,
+				`SELECT add_compression_policy('"public"."cpu"', INTERVAL '604800 seconds')`,
+			},
+		},
+		{
+			name:              "compression by tag ID",
+			compressAfter:     7 * 24 * time.Hour,
+			tagsAsForeignKeys: true,
+			expected: []string{
+				`SELECT create_hypertable('"public"."cpu"', 'time', chunk_time_interval => INTERVAL '86400 seconds')`,
+				`ALTER TABLE "public"."cpu" SET (timescaledb.compress, timescaledb.compress_segmentby = 'tag_id')`,
+				`SELECT add_compression_policy('"public"."cpu"', INTERVAL '604800 seconds')`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &TimescaleDB{
+				Hypertable:        true,
+				ChunkTimeInterval: config.Duration(24 * time.Hour),
+				CompressAfter:     config.Duration(tt.compressAfter),
+			}
+			tmpls, err := ts.createTemplates("time", tt.tagsAsForeignKeys, false)
+			require.NoError(t, err)
+
+			actual := make([]string, 0, len(tmpls))
+			for _, tmpl := range tmpls {
+				sql, err := tmpl.Render(table, columns, table, empty)
+				require.NoError(t, err)
+				actual = append(actual, string(sql))
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
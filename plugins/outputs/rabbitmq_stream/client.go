@@ -0,0 +1,467 @@
+package rabbitmq_stream
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+var errConnectionClosed = errors.New("connection closed")
+
+type clientConfig struct {
+	address   string
+	vhost     string
+	username  string
+	password  string
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	heartbeat time.Duration
+	frameMax  uint32
+}
+
+// entry is a single publishing entry with its publishing ID containing either
+// one message or a sub-entry batch of multiple messages
+type entry struct {
+	id       uint64
+	messages [][]byte
+}
+
+// client implements the publishing subset of the RabbitMQ stream protocol
+type client struct {
+	conn     net.Conn
+	timeout  time.Duration
+	frameMax atomic.Uint32
+	log      telegraf.Logger
+
+	writeLock sync.Mutex
+
+	sync.Mutex
+	correlationID uint32
+	pending       map[uint32]chan []byte
+	outcomes      map[uint64]uint16
+	notify        chan struct{}
+	tune          chan [2]uint32
+	done          chan struct{}
+	err           error
+
+	wg sync.WaitGroup
+}
+
+func dial(cfg *clientConfig, log telegraf.Logger) (*client, error) {
+	dialer := &net.Dialer{Timeout: cfg.timeout}
+	var conn net.Conn
+	var err error
+	if cfg.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", cfg.address, cfg.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", cfg.address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{
+		conn:     conn,
+		timeout:  cfg.timeout,
+		log:      log,
+		pending:  make(map[uint32]chan []byte),
+		outcomes: make(map[uint64]uint16),
+		notify:   make(chan struct{}, 1),
+		tune:     make(chan [2]uint32, 1),
+		done:     make(chan struct{}),
+	}
+	c.wg.Add(1)
+	go c.read()
+
+	heartbeat, err := c.handshake(cfg)
+	if err != nil {
+		c.conn.Close()
+		c.wg.Wait()
+		return nil, err
+	}
+
+	if heartbeat > 0 {
+		c.wg.Add(1)
+		go c.sendHeartbeats(heartbeat)
+	}
+
+	return c, nil
+}
+
+// handshake authenticates the connection using the PLAIN mechanism, tunes
+// the connection parameters and opens the virtual host
+func (c *client) handshake(cfg *clientConfig) (time.Duration, error) {
+	_, err := c.request(cmdPeerProperties, func(e *encoder) {
+		e.properties(map[string]string{
+			"product":         "Telegraf",
+			"version":         internal.Version,
+			"platform":        "Go",
+			"connection_name": "telegraf",
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("exchanging peer properties failed: %w", err)
+	}
+
+	d, err := c.request(cmdSaslHandshake, nil)
+	if err != nil {
+		return 0, fmt.Errorf("SASL handshake failed: %w", err)
+	}
+	mechanisms := make([]string, 0)
+	for n := d.uint32(); n > 0 && d.err == nil; n-- {
+		mechanisms = append(mechanisms, d.string())
+	}
+	if !slices.Contains(mechanisms, "PLAIN") {
+		return 0, fmt.Errorf("PLAIN mechanism not supported by server, supported are %v", mechanisms)
+	}
+
+	if _, err := c.request(cmdSaslAuthenticate, func(e *encoder) {
+		e.string("PLAIN")
+		e.bytes([]byte("\x00" + cfg.username + "\x00" + cfg.password))
+	}); err != nil {
+		return 0, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	// The server sends its tuning parameters after successful authentication
+	// and expects the client to reply with the negotiated values
+	var serverFrameMax, serverHeartbeat uint32
+	select {
+	case params := <-c.tune:
+		serverFrameMax, serverHeartbeat = params[0], params[1]
+	case <-c.done:
+		return 0, c.closeErr()
+	case <-time.After(c.timeout):
+		return 0, errors.New("timeout waiting for tune parameters")
+	}
+	frameMax := negotiate(cfg.frameMax, serverFrameMax)
+	c.frameMax.Store(frameMax)
+	heartbeat := negotiate(uint32(cfg.heartbeat.Seconds()), serverHeartbeat)
+	tune := newFrame(cmdTune)
+	tune.uint32(frameMax)
+	tune.uint32(heartbeat)
+	if err := c.write(tune.frame()); err != nil {
+		return 0, fmt.Errorf("sending tune parameters failed: %w", err)
+	}
+
+	if _, err := c.request(cmdOpen, func(e *encoder) { e.string(cfg.vhost) }); err != nil {
+		return 0, fmt.Errorf("opening virtual host %q failed: %w", cfg.vhost, err)
+	}
+
+	return time.Duration(heartbeat) * time.Second, nil
+}
+
+// negotiate returns the smaller of both values where zero means unlimited
+func negotiate(client, server uint32) uint32 {
+	if client == 0 || (server != 0 && server < client) {
+		return server
+	}
+	return client
+}
+
+func (c *client) write(frame []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// request sends a command with a new correlation ID and waits for the
+// response, non-OK response codes are returned as responseError
+func (c *client) request(key uint16, content func(*encoder)) (*decoder, error) {
+	response := make(chan []byte, 1)
+
+	c.Lock()
+	c.correlationID++
+	id := c.correlationID
+	c.pending[id] = response
+	c.Unlock()
+	defer func() {
+		c.Lock()
+		delete(c.pending, id)
+		c.Unlock()
+	}()
+
+	e := newFrame(key)
+	e.uint32(id)
+	if content != nil {
+		content(e)
+	}
+	if err := c.write(e.frame()); err != nil {
+		return nil, err
+	}
+
+	select {
+	case buf := <-response:
+		d := &decoder{buf: buf}
+		if code := d.uint16(); d.err == nil && code != codeOK {
+			return d, responseError(code)
+		}
+		return d, d.err
+	case <-c.done:
+		return nil, c.closeErr()
+	case <-time.After(c.timeout):
+		return nil, fmt.Errorf("timeout waiting for response to command 0x%04x", key)
+	}
+}
+
+func (c *client) read() {
+	defer c.wg.Done()
+
+	err := c.readFrames()
+
+	c.Lock()
+	c.err = err
+	close(c.done)
+	c.Unlock()
+}
+
+func (c *client) readFrames() error {
+	for {
+		buf, err := readFrame(c.conn, c.frameMax.Load())
+		if err != nil {
+			return err
+		}
+
+		d := &decoder{buf: buf}
+		key := d.uint16()
+		d.uint16() // version
+
+		if key&responseFlag != 0 {
+			id := d.uint32()
+			c.Lock()
+			if response, found := c.pending[id]; found && d.err == nil {
+				response <- d.buf
+			}
+			c.Unlock()
+			continue
+		}
+
+		switch key {
+		case cmdTune:
+			params := [2]uint32{d.uint32(), d.uint32()}
+			select {
+			case c.tune <- params:
+			default:
+			}
+		case cmdPublishConfirm:
+			d.uint8() // publisher ID
+			c.Lock()
+			for n := d.uint32(); n > 0 && d.err == nil; n-- {
+				c.outcomes[d.uint64()] = codeOK
+			}
+			c.Unlock()
+			c.signal()
+		case cmdPublishError:
+			d.uint8() // publisher ID
+			c.Lock()
+			for n := d.uint32(); n > 0 && d.err == nil; n-- {
+				id := d.uint64()
+				c.outcomes[id] = d.uint16()
+			}
+			c.Unlock()
+			c.signal()
+		case cmdHeartbeat:
+		case cmdClose:
+			id := d.uint32()
+			code := d.uint16()
+			reason := d.string()
+			response := newFrame(cmdClose | responseFlag)
+			response.uint32(id)
+			response.uint16(codeOK)
+			if err := c.write(response.frame()); err != nil {
+				c.log.Debugf("Acknowledging close failed: %v", err)
+			}
+			return fmt.Errorf("connection closed by server: %s (code %d)", reason, code)
+		default:
+			c.log.Debugf("Ignoring unexpected command 0x%04x", key)
+		}
+		if d.err != nil {
+			return fmt.Errorf("decoding command 0x%04x failed: %w", key, d.err)
+		}
+	}
+}
+
+func (c *client) signal() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (c *client) closeErr() error {
+	c.Lock()
+	defer c.Unlock()
+	if c.err != nil {
+		return fmt.Errorf("%w: %w", errConnectionClosed, c.err)
+	}
+	return errConnectionClosed
+}
+
+func (c *client) sendHeartbeats(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.write(newFrame(cmdHeartbeat).frame()); err != nil {
+				c.log.Debugf("Sending heartbeat failed: %v", err)
+			}
+		}
+	}
+}
+
+func (c *client) createStream(stream string, arguments map[string]string) error {
+	_, err := c.request(cmdCreate, func(e *encoder) {
+		e.string(stream)
+		e.properties(arguments)
+	})
+	if errors.Is(err, responseError(codeStreamAlreadyExists)) {
+		return nil
+	}
+	return err
+}
+
+func (c *client) declarePublisher(id uint8, reference, stream string) error {
+	_, err := c.request(cmdDeclarePublisher, func(e *encoder) {
+		e.uint8(id)
+		e.string(reference)
+		e.string(stream)
+	})
+	return err
+}
+
+// querySequence returns the last publishing ID stored by the broker for the
+// given producer reference
+func (c *client) querySequence(reference, stream string) (uint64, error) {
+	d, err := c.request(cmdQueryPublisherSequence, func(e *encoder) {
+		e.string(reference)
+		e.string(stream)
+	})
+	if err != nil {
+		return 0, err
+	}
+	seq := d.uint64()
+	return seq, d.err
+}
+
+func (c *client) deletePublisher(id uint8) error {
+	_, err := c.request(cmdDeletePublisher, func(e *encoder) { e.uint8(id) })
+	return err
+}
+
+// publish sends the entries in as few frames as possible without exceeding
+// the negotiated maximum frame size
+func (c *client) publish(publisherID uint8, entries []entry) error {
+	frameMax := c.frameMax.Load()
+	var frame *encoder
+	var count uint32
+	flush := func() error {
+		if count == 0 {
+			return nil
+		}
+		buf := frame.frame()
+		// Patch the number of entries following the publisher ID
+		buf[9], buf[10], buf[11], buf[12] = byte(count>>24), byte(count>>16), byte(count>>8), byte(count)
+		count = 0
+		return c.write(buf)
+	}
+
+	for _, e := range entries {
+		encoded := &encoder{}
+		encoded.uint64(e.id)
+		if len(e.messages) == 1 {
+			encoded.bytes(e.messages[0])
+		} else {
+			var size int
+			for _, m := range e.messages {
+				size += 4 + len(m)
+			}
+			// Sub-entry batch without compression
+			encoded.uint8(0x80)
+			encoded.uint16(uint16(len(e.messages)))
+			encoded.uint32(uint32(size))
+			encoded.uint32(uint32(size))
+			for _, m := range e.messages {
+				encoded.bytes(m)
+			}
+		}
+
+		// Frame header: size, key, version, publisher ID and entry count
+		const header = 4 + 2 + 2 + 1 + 4
+		if frameMax > 0 && uint32(header+encoded.buf.Len()) > frameMax {
+			return fmt.Errorf("entry with publishing ID %d exceeds the maximum frame size of %d", e.id, frameMax)
+		}
+		if count > 0 && frameMax > 0 && uint32(frame.buf.Len()+encoded.buf.Len()) > frameMax {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if count == 0 {
+			frame = newFrame(cmdPublish)
+			frame.uint8(publisherID)
+			frame.uint32(0)
+		}
+		frame.buf.Write(encoded.buf.Bytes())
+		count++
+	}
+	return flush()
+}
+
+// waitOutcomes waits for the confirmation or error of the given publishing
+// IDs until the timeout expires. The returned map contains the response
+// codes of the completed IDs.
+func (c *client) waitOutcomes(ids []uint64) map[uint64]uint16 {
+	results := make(map[uint64]uint16, len(ids))
+	timeout := time.NewTimer(c.timeout)
+	defer timeout.Stop()
+
+	for {
+		c.Lock()
+		for _, id := range ids {
+			if code, found := c.outcomes[id]; found {
+				results[id] = code
+				delete(c.outcomes, id)
+			}
+		}
+		c.Unlock()
+		if len(results) == len(ids) {
+			return results
+		}
+
+		select {
+		case <-c.notify:
+		case <-c.done:
+			return results
+		case <-timeout.C:
+			return results
+		}
+	}
+}
+
+func (c *client) close() error {
+	_, err := c.request(cmdClose, func(e *encoder) {
+		e.uint16(codeOK)
+		e.string("telegraf shutdown")
+	})
+	if cerr := c.conn.Close(); err == nil {
+		err = cerr
+	}
+	c.wg.Wait()
+	return err
+}
@@ -0,0 +1,294 @@
+package rabbitmq_stream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Command keys of the RabbitMQ stream protocol, see
+// https://github.com/rabbitmq/rabbitmq-server/blob/main/deps/rabbitmq_stream/docs/PROTOCOL.adoc
+const (
+	cmdDeclarePublisher       uint16 = 0x0001
+	cmdPublish                uint16 = 0x0002
+	cmdPublishConfirm         uint16 = 0x0003
+	cmdPublishError           uint16 = 0x0004
+	cmdQueryPublisherSequence uint16 = 0x0005
+	cmdDeletePublisher        uint16 = 0x0006
+	cmdCreate                 uint16 = 0x000d
+	cmdPeerProperties         uint16 = 0x0011
+	cmdSaslHandshake          uint16 = 0x0012
+	cmdSaslAuthenticate       uint16 = 0x0013
+	cmdTune                   uint16 = 0x0014
+	cmdOpen                   uint16 = 0x0015
+	cmdClose                  uint16 = 0x0016
+	cmdHeartbeat              uint16 = 0x0017
+
+	responseFlag    uint16 = 0x8000
+	protocolVersion uint16 = 1
+)
+
+// Response codes of the RabbitMQ stream protocol
+const (
+	codeOK                  uint16 = 0x01
+	codeStreamAlreadyExists uint16 = 0x05
+)
+
+var responseCodes = map[uint16]string{
+	0x01: "ok",
+	0x02: "stream does not exist",
+	0x03: "subscription id already exists",
+	0x04: "subscription id does not exist",
+	0x05: "stream already exists",
+	0x06: "stream not available",
+	0x07: "SASL mechanism not supported",
+	0x08: "authentication failure",
+	0x09: "SASL error",
+	0x0a: "SASL challenge",
+	0x0b: "SASL authentication failure loopback",
+	0x0c: "virtual host access failure",
+	0x0d: "unknown frame",
+	0x0e: "frame too large",
+	0x0f: "internal error",
+	0x10: "access refused",
+	0x11: "precondition failed",
+	0x12: "publisher does not exist",
+	0x13: "no offset",
+}
+
+type responseError uint16
+
+func (e responseError) Error() string {
+	if text, found := responseCodes[uint16(e)]; found {
+		return fmt.Sprintf("%s (code %d)", text, uint16(e))
+	}
+	return fmt.Sprintf("unknown response code %d", uint16(e))
+}
+
+// encoder creates frames using the protocol's data types
+type encoder struct {
+	buf bytes.Buffer
+}
+
+// newFrame starts a new frame with the given key, the frame size is filled
+// when calling frame()
+func newFrame(key uint16) *encoder {
+	e := &encoder{}
+	e.uint32(0)
+	e.uint16(key)
+	e.uint16(protocolVersion)
+	return e
+}
+
+func (e *encoder) uint8(v uint8) {
+	e.buf.WriteByte(v)
+}
+
+func (e *encoder) uint16(v uint16) {
+	e.buf.Write(binary.BigEndian.AppendUint16(nil, v))
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.buf.Write(binary.BigEndian.AppendUint32(nil, v))
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.buf.Write(binary.BigEndian.AppendUint64(nil, v))
+}
+
+func (e *encoder) string(s string) {
+	e.uint16(uint16(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.uint32(uint32(len(b)))
+	e.buf.Write(b)
+}
+
+func (e *encoder) properties(props map[string]string) {
+	e.uint32(uint32(len(props)))
+	for k, v := range props {
+		e.string(k)
+		e.string(v)
+	}
+}
+
+func (e *encoder) frame() []byte {
+	b := e.buf.Bytes()
+	binary.BigEndian.PutUint32(b, uint32(len(b)-4))
+	return b
+}
+
+// decoder reads the protocol's data types from a frame, the first error
+// stops decoding and is kept for inspection
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint64() uint64 {
+	if b := d.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint8() uint8 {
+	if b := d.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) string() string {
+	n := int16(d.uint16())
+	if n < 0 {
+		return ""
+	}
+	return string(d.next(int(n)))
+}
+
+func (d *decoder) bytes() []byte {
+	n := int32(d.uint32())
+	if n < 0 {
+		return nil
+	}
+	return d.next(int(n))
+}
+
+// readFrame reads a complete frame without the size prefix
+func readFrame(r io.Reader, maxSize uint32) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n < 4 {
+		return nil, fmt.Errorf("invalid frame size %d", n)
+	}
+	if maxSize > 0 && n > maxSize {
+		return nil, fmt.Errorf("frame size %d exceeds maximum of %d", n, maxSize)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// AMQP 1.0 type constructors and section descriptors used for encoding
+// messages compatible with all stream clients
+const (
+	amqpDescriptor          = 0x00
+	amqpSmallUlong          = 0x53
+	amqpNull                = 0x40
+	amqpTimestamp           = 0x83
+	amqpVbin8               = 0xa0
+	amqpVbin32              = 0xb0
+	amqpStr8                = 0xa1
+	amqpStr32               = 0xb1
+	amqpSym8                = 0xa3
+	amqpSym32               = 0xb3
+	amqpList32              = 0xd0
+	amqpMap32               = 0xd1
+	amqpSectionProperties   = 0x73
+	amqpSectionAppProps     = 0x74
+	amqpSectionData         = 0x75
+	amqpPropsContentType    = 6
+	amqpPropsCreationTime   = 9
+	amqpPropsNumberOfFields = 10
+)
+
+// message is an AMQP 1.0 message with properties, application properties and
+// a single data section
+type message struct {
+	contentType  string
+	creationTime int64
+	appProps     map[string]string
+	data         []byte
+}
+
+func (m *message) encode() []byte {
+	var buf bytes.Buffer
+
+	// Properties section with the content-type and creation-time set
+	var props bytes.Buffer
+	for i := 0; i < amqpPropsNumberOfFields; i++ {
+		switch {
+		case i == amqpPropsContentType && m.contentType != "":
+			amqpVariable(&props, amqpSym8, amqpSym32, []byte(m.contentType))
+		case i == amqpPropsCreationTime:
+			props.WriteByte(amqpTimestamp)
+			props.Write(binary.BigEndian.AppendUint64(nil, uint64(m.creationTime)))
+		default:
+			props.WriteByte(amqpNull)
+		}
+	}
+	buf.Write([]byte{amqpDescriptor, amqpSmallUlong, amqpSectionProperties})
+	amqpCompound(&buf, amqpList32, amqpPropsNumberOfFields, props.Bytes())
+
+	// Application properties section
+	if len(m.appProps) > 0 {
+		var entries bytes.Buffer
+		for k, v := range m.appProps {
+			amqpVariable(&entries, amqpStr8, amqpStr32, []byte(k))
+			amqpVariable(&entries, amqpStr8, amqpStr32, []byte(v))
+		}
+		buf.Write([]byte{amqpDescriptor, amqpSmallUlong, amqpSectionAppProps})
+		amqpCompound(&buf, amqpMap32, 2*len(m.appProps), entries.Bytes())
+	}
+
+	// Data section
+	buf.Write([]byte{amqpDescriptor, amqpSmallUlong, amqpSectionData})
+	amqpVariable(&buf, amqpVbin8, amqpVbin32, m.data)
+
+	return buf.Bytes()
+}
+
+func amqpVariable(buf *bytes.Buffer, short, long byte, data []byte) {
+	if len(data) <= 255 {
+		buf.WriteByte(short)
+		buf.WriteByte(byte(len(data)))
+	} else {
+		buf.WriteByte(long)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(data))))
+	}
+	buf.Write(data)
+}
+
+func amqpCompound(buf *bytes.Buffer, constructor byte, count int, data []byte) {
+	buf.WriteByte(constructor)
+	// The size includes the four bytes of the count
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(data)+4)))
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(count)))
+	buf.Write(data)
+}
@@ -0,0 +1,321 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rabbitmq_stream
+
+import (
+	cryptotls "crypto/tls"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// The plugin uses a single publisher per connection
+const publisherID = 0
+
+type RabbitMQStream struct {
+	URL                 string          `toml:"url"`
+	VirtualHost         string          `toml:"vhost"`
+	Username            config.Secret   `toml:"username"`
+	Password            config.Secret   `toml:"password"`
+	Stream              string          `toml:"stream"`
+	CreateStream        bool            `toml:"create_stream"`
+	MaxLengthBytes      config.Size     `toml:"max_length_bytes"`
+	MaxAge              config.Duration `toml:"max_age"`
+	MaxSegmentSizeBytes config.Size     `toml:"max_segment_size_bytes"`
+	ProducerReference   string          `toml:"producer_reference"`
+	SubEntrySize        int             `toml:"sub_entry_size"`
+	ContentType         string          `toml:"content_type"`
+	Timeout             config.Duration `toml:"timeout"`
+	Heartbeat           config.Duration `toml:"heartbeat"`
+	MaxFrameSize        config.Size     `toml:"max_frame_size"`
+	Log                 telegraf.Logger `toml:"-"`
+	tls.ClientConfig
+
+	serializer telegraf.Serializer
+	cfg        *clientConfig
+	client     *client
+
+	// Next publishing ID and the number of metrics of the last unconfirmed
+	// batch to resend with the same publishing IDs for deduplication
+	nextID  uint64
+	pending int
+}
+
+func (*RabbitMQStream) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *RabbitMQStream) SetSerializer(serializer telegraf.Serializer) {
+	r.serializer = serializer
+}
+
+func (r *RabbitMQStream) Init() error {
+	if r.Stream == "" {
+		return errors.New("stream required")
+	}
+	if r.SubEntrySize < 1 {
+		r.SubEntrySize = 1
+	}
+	if r.SubEntrySize > 65535 {
+		return fmt.Errorf("sub_entry_size %d exceeds the maximum of 65535", r.SubEntrySize)
+	}
+	if r.MaxFrameSize < 0 || r.MaxFrameSize > 1<<32-1 {
+		return fmt.Errorf("invalid max_frame_size %d", r.MaxFrameSize)
+	}
+
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return fmt.Errorf("parsing URL failed: %w", err)
+	}
+
+	tlsCfg, err := r.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	port := "5552"
+	switch u.Scheme {
+	case "rabbitmq-stream":
+	case "rabbitmq-stream+tls":
+		if tlsCfg == nil {
+			tlsCfg = &cryptotls.Config{}
+		}
+		port = "5551"
+	default:
+		return fmt.Errorf("invalid URL scheme %q", u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+
+	r.cfg = &clientConfig{
+		address:   net.JoinHostPort(u.Hostname(), port),
+		vhost:     r.VirtualHost,
+		timeout:   time.Duration(r.Timeout),
+		heartbeat: time.Duration(r.Heartbeat),
+		frameMax:  uint32(r.MaxFrameSize),
+		tlsConfig: tlsCfg,
+	}
+
+	return nil
+}
+
+func (r *RabbitMQStream) Connect() error {
+	username, err := r.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	r.cfg.username = username.String()
+	username.Destroy()
+
+	password, err := r.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	r.cfg.password = password.String()
+	password.Destroy()
+
+	return r.connect()
+}
+
+func (r *RabbitMQStream) connect() error {
+	c, err := dial(r.cfg, r.Log)
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", r.cfg.address, err)
+	}
+
+	if err := r.setup(c); err != nil {
+		if cerr := c.close(); cerr != nil {
+			r.Log.Debugf("Closing connection failed: %v", cerr)
+		}
+		return err
+	}
+
+	r.client = c
+	return nil
+}
+
+// setup creates the stream if requested and declares the publisher
+func (r *RabbitMQStream) setup(c *client) error {
+	if r.CreateStream {
+		arguments := make(map[string]string)
+		if r.MaxLengthBytes > 0 {
+			arguments["max-length-bytes"] = strconv.FormatInt(int64(r.MaxLengthBytes), 10)
+		}
+		if r.MaxAge > 0 {
+			arguments["max-age"] = strconv.FormatInt(int64(time.Duration(r.MaxAge).Seconds()), 10) + "s"
+		}
+		if r.MaxSegmentSizeBytes > 0 {
+			arguments["stream-max-segment-size-bytes"] = strconv.FormatInt(int64(r.MaxSegmentSizeBytes), 10)
+		}
+		if err := c.createStream(r.Stream, arguments); err != nil {
+			return fmt.Errorf("creating stream %q failed: %w", r.Stream, err)
+		}
+	}
+
+	if err := c.declarePublisher(publisherID, r.ProducerReference, r.Stream); err != nil {
+		return fmt.Errorf("declaring publisher for stream %q failed: %w", r.Stream, err)
+	}
+
+	// Continue after the last publishing ID stored by the broker for named
+	// producers on first connect. On reconnects we keep our own sequence to
+	// resend unconfirmed metrics with their original publishing IDs.
+	if r.nextID == 0 && r.ProducerReference != "" {
+		seq, err := c.querySequence(r.ProducerReference, r.Stream)
+		if err != nil {
+			return fmt.Errorf("querying publisher sequence failed: %w", err)
+		}
+		r.nextID = seq + 1
+		r.Log.Debugf("Continuing producer %q at publishing ID %d", r.ProducerReference, r.nextID)
+	}
+	if r.nextID == 0 {
+		r.nextID = 1
+	}
+
+	return nil
+}
+
+func (r *RabbitMQStream) Close() error {
+	if r.client == nil {
+		return nil
+	}
+	if err := r.client.deletePublisher(publisherID); err != nil {
+		r.Log.Debugf("Deleting publisher failed: %v", err)
+	}
+	err := r.client.close()
+	r.client = nil
+	return err
+}
+
+func (r *RabbitMQStream) Write(metrics []telegraf.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	if r.client == nil {
+		if err := r.connect(); err != nil {
+			return err
+		}
+	}
+
+	// Resend exactly the metrics of an unconfirmed batch first so the entries
+	// get the same publishing IDs and the broker can drop duplicates.
+	batch := metrics
+	if r.pending > 0 && r.pending < len(metrics) {
+		batch = metrics[:r.pending]
+	}
+
+	// Serialize the metrics and group them into entries
+	var rejected []int
+	var entries []entry
+	var indices [][]int
+	for i, m := range batch {
+		buf, err := r.serializer.Serialize(m)
+		if err != nil {
+			r.Log.Errorf("Could not serialize metric: %v", err)
+			rejected = append(rejected, i)
+			continue
+		}
+		msg := &message{
+			contentType:  r.ContentType,
+			creationTime: m.Time().UnixMilli(),
+			appProps:     map[string]string{"measurement": m.Name()},
+			data:         buf,
+		}
+
+		n := len(entries)
+		if n == 0 || len(entries[n-1].messages) >= r.SubEntrySize {
+			entries = append(entries, entry{id: r.nextID + uint64(n)})
+			indices = append(indices, nil)
+			n++
+		}
+		entries[n-1].messages = append(entries[n-1].messages, msg.encode())
+		indices[n-1] = append(indices[n-1], i)
+	}
+
+	if len(entries) > 0 {
+		if err := r.client.publish(publisherID, entries); err != nil {
+			r.reset(len(batch))
+			return fmt.Errorf("publishing failed: %w", err)
+		}
+	}
+
+	ids := make([]uint64, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.id)
+	}
+	outcomes := r.client.waitOutcomes(ids)
+	if len(outcomes) < len(ids) {
+		r.reset(len(batch))
+		return fmt.Errorf("%d of %d entries not confirmed within the timeout", len(ids)-len(outcomes), len(ids))
+	}
+
+	// All entries are either confirmed or failed so continue with new IDs
+	r.nextID += uint64(len(entries))
+	r.pending = 0
+
+	var accepted []int
+	var firstErr error
+	for i, e := range entries {
+		if code := outcomes[e.id]; code != codeOK {
+			rejected = append(rejected, indices[i]...)
+			if firstErr == nil {
+				firstErr = responseError(code)
+			}
+			continue
+		}
+		accepted = append(accepted, indices[i]...)
+	}
+
+	if len(accepted) == len(metrics) {
+		return nil
+	}
+	if firstErr == nil && len(rejected) > 0 {
+		firstErr = internal.ErrSerialization
+	}
+	if firstErr == nil {
+		firstErr = errors.New("resent unconfirmed metrics, remaining metrics are written with the next batch")
+	}
+	return &internal.PartialWriteError{
+		Err:           firstErr,
+		MetricsAccept: accepted,
+		MetricsReject: rejected,
+	}
+}
+
+// reset closes the connection after a failed write and remembers the
+// unconfirmed batch size to resend those metrics with the same IDs
+func (r *RabbitMQStream) reset(pending int) {
+	r.pending = pending
+	if err := r.client.close(); err != nil {
+		r.Log.Debugf("Closing connection failed: %v", err)
+	}
+	r.client = nil
+}
+
+func init() {
+	outputs.Add("rabbitmq_stream", func() telegraf.Output {
+		return &RabbitMQStream{
+			URL:          "rabbitmq-stream://localhost:5552",
+			VirtualHost:  "/",
+			Username:     config.NewSecret([]byte("guest")),
+			Password:     config.NewSecret([]byte("guest")),
+			CreateStream: true,
+			SubEntrySize: 1,
+			Timeout:      config.Duration(10 * time.Second),
+			Heartbeat:    config.Duration(60 * time.Second),
+			MaxFrameSize: config.Size(1024 * 1024),
+		}
+	})
+}
@@ -0,0 +1,514 @@
+package rabbitmq_stream
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type storedMessage struct {
+	contentType  string
+	creationTime int64
+	appProps     map[string]string
+	data         string
+}
+
+// server is a minimal RabbitMQ stream broker supporting publishing
+type server struct {
+	listener net.Listener
+
+	// Behavior simulation
+	publishError  uint16
+	dropConfirms  int
+	lastStoredID  uint64
+	existingQueue bool
+
+	sync.Mutex
+	tune       [2]uint32
+	vhost      string
+	arguments  map[string]string
+	references []string
+	entries    []uint64
+	messages   []storedMessage
+}
+
+func newServer(t *testing.T) *server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &server{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(t, conn)
+		}
+	}()
+	return s
+}
+
+func (s *server) close() {
+	s.listener.Close()
+}
+
+func (s *server) url() string {
+	return "rabbitmq-stream://" + s.listener.Addr().String()
+}
+
+func (s *server) serve(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+
+	respond := func(key uint16, id uint32, code uint16, content func(*encoder)) {
+		e := newFrame(key | responseFlag)
+		e.uint32(id)
+		e.uint16(code)
+		if content != nil {
+			content(e)
+		}
+		if _, err := conn.Write(e.frame()); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for {
+		buf, err := readFrame(conn, 0)
+		if err != nil {
+			return
+		}
+		d := &decoder{buf: buf}
+		key := d.uint16()
+		d.uint16()
+
+		switch key {
+		case cmdPeerProperties:
+			respond(key, d.uint32(), codeOK, func(e *encoder) { e.properties(nil) })
+		case cmdSaslHandshake:
+			respond(key, d.uint32(), codeOK, func(e *encoder) {
+				e.uint32(2)
+				e.string("AMQPLAIN")
+				e.string("PLAIN")
+			})
+		case cmdSaslAuthenticate:
+			id := d.uint32()
+			mechanism := d.string()
+			if mechanism != "PLAIN" || string(d.bytes()) != "\x00guest\x00secret" {
+				respond(key, id, 0x08, nil)
+				return
+			}
+			respond(key, id, codeOK, func(e *encoder) { e.bytes(nil) })
+			tune := newFrame(cmdTune)
+			tune.uint32(4096)
+			tune.uint32(0)
+			if _, err := conn.Write(tune.frame()); err != nil {
+				t.Error(err)
+			}
+		case cmdTune:
+			s.Lock()
+			s.tune = [2]uint32{d.uint32(), d.uint32()}
+			s.Unlock()
+		case cmdOpen:
+			id := d.uint32()
+			s.Lock()
+			s.vhost = d.string()
+			s.Unlock()
+			respond(key, id, codeOK, func(e *encoder) { e.properties(nil) })
+		case cmdCreate:
+			id := d.uint32()
+			d.string()
+			s.Lock()
+			s.arguments = make(map[string]string)
+			for n := d.uint32(); n > 0; n-- {
+				k := d.string()
+				s.arguments[k] = d.string()
+			}
+			exists := s.existingQueue
+			s.existingQueue = true
+			s.Unlock()
+			if exists {
+				respond(key, id, codeStreamAlreadyExists, nil)
+			} else {
+				respond(key, id, codeOK, nil)
+			}
+		case cmdDeclarePublisher:
+			id := d.uint32()
+			d.uint8()
+			s.Lock()
+			s.references = append(s.references, d.string())
+			s.Unlock()
+			respond(key, id, codeOK, nil)
+		case cmdQueryPublisherSequence:
+			id := d.uint32()
+			s.Lock()
+			seq := s.lastStoredID
+			s.Unlock()
+			respond(key, id, codeOK, func(e *encoder) { e.uint64(seq) })
+		case cmdPublish:
+			s.handlePublish(t, conn, d)
+		case cmdDeletePublisher:
+			respond(key, d.uint32(), codeOK, nil)
+		case cmdClose:
+			respond(key, d.uint32(), codeOK, nil)
+			return
+		case cmdHeartbeat:
+		default:
+			t.Errorf("unexpected command 0x%04x", key)
+			return
+		}
+		if d.err != nil {
+			t.Errorf("decoding command 0x%04x failed: %v", key, d.err)
+			return
+		}
+	}
+}
+
+func (s *server) handlePublish(t *testing.T, conn net.Conn, d *decoder) {
+	publisher := d.uint8()
+
+	s.Lock()
+	defer s.Unlock()
+
+	var ids []uint64
+	for n := d.uint32(); n > 0 && d.err == nil; n-- {
+		id := d.uint64()
+		var messages [][]byte
+		if d.buf[0]&0x80 != 0 {
+			d.uint8()
+			count := d.uint16()
+			d.uint32()
+			d.uint32()
+			for i := uint16(0); i < count; i++ {
+				messages = append(messages, d.bytes())
+			}
+		} else {
+			messages = append(messages, d.bytes())
+		}
+		ids = append(ids, id)
+		s.entries = append(s.entries, id)
+
+		// Deduplicate the entries
+		if id <= s.lastStoredID || s.publishError != 0 {
+			continue
+		}
+		s.lastStoredID = id
+		for _, m := range messages {
+			msg, err := decodeMessage(m)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			s.messages = append(s.messages, msg)
+		}
+	}
+
+	if s.dropConfirms > 0 {
+		s.dropConfirms--
+		return
+	}
+
+	var e *encoder
+	if s.publishError != 0 {
+		e = newFrame(cmdPublishError)
+		e.uint8(publisher)
+		e.uint32(uint32(len(ids)))
+		for _, id := range ids {
+			e.uint64(id)
+			e.uint16(s.publishError)
+		}
+	} else {
+		e = newFrame(cmdPublishConfirm)
+		e.uint8(publisher)
+		e.uint32(uint32(len(ids)))
+		for _, id := range ids {
+			e.uint64(id)
+		}
+	}
+	if _, err := conn.Write(e.frame()); err != nil {
+		t.Error(err)
+	}
+}
+
+// decodeMessage decodes the AMQP 1.0 message sections written by the plugin
+func decodeMessage(buf []byte) (storedMessage, error) {
+	msg := storedMessage{appProps: make(map[string]string)}
+	readVariable := func() []byte {
+		var n int
+		if buf[0] == amqpVbin8 || buf[0] == amqpStr8 || buf[0] == amqpSym8 {
+			n = int(buf[1])
+			buf = buf[2:]
+		} else {
+			n = int(binary.BigEndian.Uint32(buf[1:]))
+			buf = buf[5:]
+		}
+		v := buf[:n]
+		buf = buf[n:]
+		return v
+	}
+
+	for len(buf) > 0 {
+		if buf[0] != amqpDescriptor || buf[1] != amqpSmallUlong {
+			return msg, errors.New("invalid section descriptor")
+		}
+		section := buf[2]
+		buf = buf[3:]
+		switch section {
+		case amqpSectionProperties:
+			count := int(binary.BigEndian.Uint32(buf[5:]))
+			buf = buf[9:]
+			for i := 0; i < count; i++ {
+				switch {
+				case buf[0] == amqpNull:
+					buf = buf[1:]
+				case buf[0] == amqpTimestamp:
+					msg.creationTime = int64(binary.BigEndian.Uint64(buf[1:]))
+					buf = buf[9:]
+				case i == amqpPropsContentType:
+					msg.contentType = string(readVariable())
+				default:
+					return msg, errors.New("unexpected property")
+				}
+			}
+		case amqpSectionAppProps:
+			count := int(binary.BigEndian.Uint32(buf[5:]))
+			buf = buf[9:]
+			for i := 0; i < count; i += 2 {
+				k := string(readVariable())
+				msg.appProps[k] = string(readVariable())
+			}
+		case amqpSectionData:
+			msg.data = string(readVariable())
+		default:
+			return msg, errors.New("unexpected section")
+		}
+	}
+	return msg, nil
+}
+
+func newPlugin(t *testing.T, address string) *RabbitMQStream {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	plugin := &RabbitMQStream{
+		URL:          address,
+		VirtualHost:  "/",
+		Username:     config.NewSecret([]byte("guest")),
+		Password:     config.NewSecret([]byte("secret")),
+		Stream:       "telegraf",
+		CreateStream: true,
+		SubEntrySize: 1,
+		Timeout:      config.Duration(time.Second),
+		MaxFrameSize: config.Size(1024 * 1024),
+		Log:          testutil.Logger{},
+	}
+	plugin.SetSerializer(serializer)
+	return plugin
+}
+
+func testMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 42.0}, time.Unix(1700000000, 0)),
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"used": int64(23)}, time.Unix(1700000001, 0)),
+		metric.New("disk", map[string]string{"host": "b"}, map[string]interface{}{"free": int64(5)}, time.Unix(1700000002, 0)),
+	}
+}
+
+func TestWrite(t *testing.T) {
+	srv := newServer(t)
+	defer srv.close()
+
+	plugin := newPlugin(t, srv.url())
+	plugin.ContentType = "text/plain"
+	plugin.MaxLengthBytes = config.Size(1024 * 1024 * 1024)
+	plugin.MaxAge = config.Duration(24 * time.Hour)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write(testMetrics()))
+
+	srv.Lock()
+	defer srv.Unlock()
+	require.Equal(t, [2]uint32{4096, 0}, srv.tune)
+	require.Equal(t, "/", srv.vhost)
+	require.Equal(t, map[string]string{"max-length-bytes": "1073741824", "max-age": "86400s"}, srv.arguments)
+	require.Equal(t, []uint64{1, 2, 3}, srv.entries)
+	require.Equal(t, []storedMessage{
+		{
+			contentType:  "text/plain",
+			creationTime: 1700000000000,
+			appProps:     map[string]string{"measurement": "cpu"},
+			data:         "cpu,host=a usage=42 1700000000000000000\n",
+		},
+		{
+			contentType:  "text/plain",
+			creationTime: 1700000001000,
+			appProps:     map[string]string{"measurement": "mem"},
+			data:         "mem,host=a used=23i 1700000001000000000\n",
+		},
+		{
+			contentType:  "text/plain",
+			creationTime: 1700000002000,
+			appProps:     map[string]string{"measurement": "disk"},
+			data:         "disk,host=b free=5i 1700000002000000000\n",
+		},
+	}, srv.messages)
+}
+
+func TestWriteSubEntries(t *testing.T) {
+	srv := newServer(t)
+	defer srv.close()
+	srv.existingQueue = true
+
+	plugin := newPlugin(t, srv.url())
+	plugin.SubEntrySize = 2
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write(testMetrics()))
+	require.NoError(t, plugin.Write(testMetrics()[:1]))
+
+	srv.Lock()
+	defer srv.Unlock()
+	require.Equal(t, []uint64{1, 2, 3}, srv.entries)
+	require.Len(t, srv.messages, 4)
+}
+
+func TestWriteSplitFrames(t *testing.T) {
+	srv := newServer(t)
+	defer srv.close()
+
+	plugin := newPlugin(t, srv.url())
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// The negotiated frame size of 4096 bytes requires multiple frames
+	metrics := make([]telegraf.Metric, 0, 100)
+	for i := 0; i < 100; i++ {
+		metrics = append(metrics, testutil.TestMetric(i, "test"))
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	srv.Lock()
+	defer srv.Unlock()
+	require.Len(t, srv.messages, 100)
+}
+
+func TestDeduplication(t *testing.T) {
+	srv := newServer(t)
+	defer srv.close()
+	srv.lastStoredID = 10
+	srv.dropConfirms = 1
+
+	plugin := newPlugin(t, srv.url())
+	plugin.ProducerReference = "telegraf-1"
+	plugin.Timeout = config.Duration(100 * time.Millisecond)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// The first batch is stored but not confirmed
+	metrics := testMetrics()
+	require.ErrorContains(t, plugin.Write(metrics[:2]), "2 of 2 entries not confirmed")
+
+	// Only the unconfirmed metrics are resent with the same publishing IDs
+	err := plugin.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Equal(t, []int{0, 1}, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+
+	require.NoError(t, plugin.Write(metrics[2:]))
+
+	srv.Lock()
+	defer srv.Unlock()
+	require.Equal(t, []string{"telegraf-1", "telegraf-1"}, srv.references)
+	require.Equal(t, []uint64{11, 12, 11, 12, 13}, srv.entries)
+	require.Len(t, srv.messages, 3)
+}
+
+func TestPublishError(t *testing.T) {
+	srv := newServer(t)
+	defer srv.close()
+	srv.publishError = 0x02
+
+	plugin := newPlugin(t, srv.url())
+	plugin.SubEntrySize = 2
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	err := plugin.Write(testMetrics())
+	require.ErrorContains(t, err, "stream does not exist")
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Empty(t, writeErr.MetricsAccept)
+	require.Equal(t, []int{0, 1, 2}, writeErr.MetricsReject)
+}
+
+func TestAuthenticationFailure(t *testing.T) {
+	srv := newServer(t)
+	defer srv.close()
+
+	plugin := newPlugin(t, srv.url())
+	plugin.Password = config.NewSecret([]byte("wrong"))
+	require.NoError(t, plugin.Init())
+	require.ErrorContains(t, plugin.Connect(), "authentication failure")
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*RabbitMQStream)
+		expected string
+	}{
+		{
+			name:     "missing stream",
+			modify:   func(r *RabbitMQStream) { r.Stream = "" },
+			expected: "stream required",
+		},
+		{
+			name:     "invalid scheme",
+			modify:   func(r *RabbitMQStream) { r.URL = "amqp://localhost:5672" },
+			expected: `invalid URL scheme "amqp"`,
+		},
+		{
+			name:     "sub-entry size too large",
+			modify:   func(r *RabbitMQStream) { r.SubEntrySize = 100000 },
+			expected: "sub_entry_size 100000 exceeds the maximum",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newPlugin(t, "rabbitmq-stream://localhost")
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitAddress(t *testing.T) {
+	plugin := newPlugin(t, "rabbitmq-stream+tls://rabbit.example.com")
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "rabbit.example.com:5551", plugin.cfg.address)
+	require.NotNil(t, plugin.cfg.tlsConfig)
+
+	plugin = newPlugin(t, "rabbitmq-stream://rabbit.example.com")
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "rabbit.example.com:5552", plugin.cfg.address)
+	require.Nil(t, plugin.cfg.tlsConfig)
+}
@@ -25,9 +25,14 @@ import (
 var sampleConfig string
 
 type SocketWriter struct {
-	ContentEncoding string `toml:"content_encoding"`
-	Address         string
-	KeepAlivePeriod *config.Duration
+	ContentEncoding     string `toml:"content_encoding"`
+	CompressionLevel    *int   `toml:"compression_level"`
+	Address             string
+	KeepAlivePeriod     *config.Duration
+	Connections         int             `toml:"connections"`
+	WriteTimeout        config.Duration `toml:"write_timeout"`
+	ReconnectBackoffMin config.Duration `toml:"reconnect_backoff_min"`
+	ReconnectBackoffMax config.Duration `toml:"reconnect_backoff_max"`
 	common_tls.ClientConfig
 	Log telegraf.Logger `toml:"-"`
 
@@ -35,7 +40,20 @@ type SocketWriter struct {
 
 	encoder internal.ContentEncoder
 
+	network   string
+	endpoint  string
+	tlsConfig *tls.Config
+
+	conns []*connection
+	next  int
+}
+
+// connection is a single connection of the pool including its reconnection
+// state. A nil Conn denotes a closed connection.
+type connection struct {
 	net.Conn
+	failures int
+	retryAt  time.Time
 }
 
 func (*SocketWriter) SampleConfig() string {
@@ -46,78 +64,147 @@ func (sw *SocketWriter) SetSerializer(s telegraf.Serializer) {
 	sw.serializer = s
 }
 
-func (sw *SocketWriter) Connect() error {
+func (sw *SocketWriter) Init() error {
 	spl := strings.SplitN(sw.Address, "://", 2)
 	if len(spl) != 2 {
 		return fmt.Errorf("invalid address: %s", sw.Address)
 	}
+	sw.network, sw.endpoint = spl[0], spl[1]
+
+	if sw.Connections < 1 {
+		sw.Connections = 1
+	}
+	if sw.WriteTimeout < 0 {
+		return fmt.Errorf("invalid write_timeout %v", sw.WriteTimeout)
+	}
+	if sw.ReconnectBackoffMin <= 0 {
+		sw.ReconnectBackoffMin = config.Duration(time.Second)
+	}
+	if sw.ReconnectBackoffMax < sw.ReconnectBackoffMin {
+		sw.ReconnectBackoffMax = sw.ReconnectBackoffMin
+	}
 
 	tlsCfg, err := sw.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
 	}
+	if tlsCfg != nil {
+		// Allow resuming sessions to avoid full handshakes on reconnects
+		tlsCfg.ClientSessionCache = tls.NewLRUClientSessionCache(sw.Connections)
+	}
+	sw.tlsConfig = tlsCfg
+
+	var options []internal.EncodingOption
+	if sw.CompressionLevel != nil {
+		options = append(options, internal.WithCompressionLevel(*sw.CompressionLevel))
+	}
+	sw.encoder, err = internal.NewContentEncoder(sw.ContentEncoding, options...)
+	if err != nil {
+		return err
+	}
+
+	sw.conns = make([]*connection, 0, sw.Connections)
+	for range sw.Connections {
+		sw.conns = append(sw.conns, &connection{})
+	}
+
+	return nil
+}
 
-	var c net.Conn
+// Connect establishes all connections of the pool. Failing connections are
+// retried with backoff during write as long as at least one connection could
+// be established.
+func (sw *SocketWriter) Connect() error {
+	var connected int
+	var lastErr error
+	for i, c := range sw.conns {
+		if c.Conn != nil {
+			connected++
+			continue
+		}
+		if err := sw.reconnect(c); err != nil {
+			sw.Log.Warnf("Connection %d to %q failed: %v", i, sw.Address, err)
+			lastErr = err
+			continue
+		}
+		connected++
+	}
+	if connected == 0 {
+		return lastErr
+	}
+	return nil
+}
 
-	if spl[0] == "vsock" {
-		addrTuple := strings.SplitN(spl[1], ":", 2)
+func (sw *SocketWriter) dial() (net.Conn, error) {
+	if sw.network == "vsock" {
+		addrTuple := strings.SplitN(sw.endpoint, ":", 2)
 
 		// Check address string for containing two
 		if len(addrTuple) < 2 {
-			return errors.New("port and/or CID number missing")
+			return nil, errors.New("port and/or CID number missing")
 		}
 
 		// Parse CID and port number from address string both being 32-bit
 		// source: https://man7.org/linux/man-pages/man7/vsock.7.html
 		cid, err := strconv.ParseUint(addrTuple[0], 10, 32)
 		if err != nil {
-			return fmt.Errorf("failed to parse CID %s: %w", addrTuple[0], err)
+			return nil, fmt.Errorf("failed to parse CID %s: %w", addrTuple[0], err)
 		}
 		if (cid >= uint64(math.Pow(2, 32))-1) && (cid <= 0) {
-			return fmt.Errorf("value of CID %d is out of range", cid)
+			return nil, fmt.Errorf("value of CID %d is out of range", cid)
 		}
 		port, err := strconv.ParseUint(addrTuple[1], 10, 32)
 		if err != nil {
-			return fmt.Errorf("failed to parse port number %s: %w", addrTuple[1], err)
+			return nil, fmt.Errorf("failed to parse port number %s: %w", addrTuple[1], err)
 		}
 		if (port >= uint64(math.Pow(2, 32))-1) && (port <= 0) {
-			return fmt.Errorf("port number %d is out of range", port)
-		}
-		c, err = vsock.Dial(uint32(cid), uint32(port), nil)
-		if err != nil {
-			return err
-		}
-	} else {
-		if tlsCfg == nil {
-			c, err = net.Dial(spl[0], spl[1])
-		} else {
-			c, err = tls.Dial(spl[0], spl[1], tlsCfg)
-		}
-		if err != nil {
-			return err
+			return nil, fmt.Errorf("port number %d is out of range", port)
 		}
+		return vsock.Dial(uint32(cid), uint32(port), nil)
 	}
 
-	if err := sw.setKeepAlive(c); err != nil {
-		sw.Log.Debugf("Unable to configure keep alive (%s): %s", sw.Address, err)
+	if sw.tlsConfig == nil {
+		return net.Dial(sw.network, sw.endpoint)
 	}
-	// set encoder
-	sw.encoder, err = internal.NewContentEncoder(sw.ContentEncoding)
+	return tls.Dial(sw.network, sw.endpoint, sw.tlsConfig)
+}
+
+// reconnect dials the given connection and schedules the next attempt on
+// failure, the backoff is reset on the first successful write
+func (sw *SocketWriter) reconnect(c *connection) error {
+	conn, err := sw.dial()
 	if err != nil {
+		sw.backoff(c)
 		return err
 	}
 
-	sw.Conn = c
+	if err := sw.setKeepAlive(conn); err != nil {
+		sw.Log.Debugf("Unable to configure keep alive (%s): %s", sw.Address, err)
+	}
+
+	c.Conn = conn
 	return nil
 }
 
+// backoff schedules the next reconnection attempt of the given connection
+// with exponentially increasing delay
+func (sw *SocketWriter) backoff(c *connection) {
+	delay := time.Duration(sw.ReconnectBackoffMin)
+	for i := 0; i < c.failures && delay < time.Duration(sw.ReconnectBackoffMax); i++ {
+		delay *= 2
+	}
+	delay = min(delay, time.Duration(sw.ReconnectBackoffMax))
+	c.failures++
+	c.retryAt = time.Now().Add(delay)
+}
+
 func (sw *SocketWriter) setKeepAlive(c net.Conn) error {
 	if sw.KeepAlivePeriod == nil {
 		return nil
 	}
 	tcpc, ok := c.(*net.TCPConn)
 	if !ok {
-		return fmt.Errorf("cannot set keep alive on a %s socket", strings.SplitN(sw.Address, "://", 2)[0])
+		return fmt.Errorf("cannot set keep alive on a %s socket", sw.network)
 	}
 	if *sw.KeepAlivePeriod == 0 {
 		return tcpc.SetKeepAlive(false)
@@ -128,58 +215,138 @@ func (sw *SocketWriter) setKeepAlive(c net.Conn) error {
 	return tcpc.SetKeepAlivePeriod(time.Duration(*sw.KeepAlivePeriod))
 }
 
-// Write writes the given metrics to the destination.
-// If an error is encountered, it is up to the caller to retry the same write again later.
-// Not parallel safe.
-func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
-	if sw.Conn == nil {
-		// previous write failed with permanent error and socket was closed.
-		if err := sw.Connect(); err != nil {
-			return err
+// acquire returns the next usable connection in round-robin order,
+// reconnecting closed connections whose backoff expired. Nil is returned
+// if no connection is available.
+func (sw *SocketWriter) acquire() *connection {
+	now := time.Now()
+	for range sw.conns {
+		c := sw.conns[sw.next]
+		idx := sw.next
+		sw.next = (sw.next + 1) % len(sw.conns)
+
+		if c.Conn != nil {
+			return c
+		}
+		if now.Before(c.retryAt) {
+			continue
+		}
+		if err := sw.reconnect(c); err != nil {
+			sw.Log.Debugf("Reconnecting connection %d to %q failed: %v", idx, sw.Address, err)
+			continue
 		}
+		return c
 	}
+	return nil
+}
 
-	for _, m := range metrics {
+// Write writes the given metrics to the destination distributing them
+// across the connections of the pool. A failing connection is closed and
+// the metric is retried on the next connection. Metrics not written because
+// no connection is available are kept for the next write.
+// Not parallel safe.
+func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
+	accepted := make([]int, 0, len(metrics))
+	var rejected []int
+	var lastErr error
+	for i, m := range metrics {
 		bs, err := sw.serializer.Serialize(m)
 		if err != nil {
 			sw.Log.Debugf("Could not serialize metric: %v", err)
+			rejected = append(rejected, i)
 			continue
 		}
 
 		bs, err = sw.encoder.Encode(bs)
 		if err != nil {
 			sw.Log.Debugf("Could not encode metric: %v", err)
+			rejected = append(rejected, i)
 			continue
 		}
 
-		if _, err := sw.Conn.Write(bs); err != nil {
-			// TODO log & keep going with remaining strings
-			var netErr net.Error
-			if errors.As(err, &netErr) {
-				// permanent error. close the connection
-				sw.Close()
-				sw.Conn = nil
-				return fmt.Errorf("closing connection: %w", netErr)
+		// Try each connection at most once per metric
+		var written bool
+		for range sw.conns {
+			c := sw.acquire()
+			if c == nil {
+				break
 			}
-			return err
+			if err := sw.write(c, bs); err != nil {
+				sw.Log.Debugf("Writing to %q failed, closing connection: %v", sw.Address, err)
+				lastErr = err
+				continue
+			}
+			written = true
+			break
+		}
+		if !written {
+			break
 		}
+		accepted = append(accepted, i)
 	}
 
+	if len(accepted)+len(rejected) == len(metrics) {
+		return nil
+	}
+
+	err := errors.New("no connection available")
+	if lastErr != nil {
+		err = fmt.Errorf("no connection available: %w", lastErr)
+	}
+	return &internal.PartialWriteError{
+		Err:           err,
+		MetricsAccept: accepted,
+		MetricsReject: rejected,
+	}
+}
+
+// write sends the data over the given connection and closes the connection
+// on error scheduling a reconnect
+func (sw *SocketWriter) write(c *connection, data []byte) error {
+	if sw.WriteTimeout > 0 {
+		if err := c.SetWriteDeadline(time.Now().Add(time.Duration(sw.WriteTimeout))); err != nil {
+			sw.closeConnection(c)
+			return err
+		}
+	}
+	if _, err := c.Write(data); err != nil {
+		sw.closeConnection(c)
+		return err
+	}
+	c.failures = 0
 	return nil
 }
 
-// Close closes the connection. Noop if already closed.
+func (sw *SocketWriter) closeConnection(c *connection) {
+	if err := c.Close(); err != nil {
+		sw.Log.Debugf("Closing connection to %q failed: %v", sw.Address, err)
+	}
+	c.Conn = nil
+	sw.backoff(c)
+}
+
+// Close closes all connections. Noop if already closed.
 func (sw *SocketWriter) Close() error {
-	if sw.Conn == nil {
-		return nil
+	var errs []error
+	for _, c := range sw.conns {
+		if c.Conn == nil {
+			continue
+		}
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		c.Conn = nil
 	}
-	err := sw.Conn.Close()
-	sw.Conn = nil
-	return err
+	return errors.Join(errs...)
 }
 
 func init() {
 	outputs.Add("socket_writer", func() telegraf.Output {
-		return &SocketWriter{}
+		return &SocketWriter{
+			Connections:         1,
+			WriteTimeout:        config.Duration(5 * time.Second),
+			ReconnectBackoffMin: config.Duration(time.Second),
+			ReconnectBackoffMax: config.Duration(time.Minute),
+		}
 	})
 }
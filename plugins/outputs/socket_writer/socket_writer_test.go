@@ -2,23 +2,31 @@ package socket_writer
 
 import (
 	"bufio"
+	"crypto/tls"
+	"io"
 	"net"
 	"runtime"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
 )
 
+var pki = testutil.NewPKI("../../../testutil/pki")
+
 func newSocketWriter(t *testing.T, addr string) *SocketWriter {
 	serializer := &influx.Serializer{}
 	require.NoError(t, serializer.Init())
 	return &SocketWriter{
 		Address:    addr,
+		Log:        testutil.Logger{},
 		serializer: serializer,
 	}
 }
@@ -28,6 +36,7 @@ func TestSocketWriter_tcp(t *testing.T) {
 	require.NoError(t, err)
 
 	sw := newSocketWriter(t, "tcp://"+listener.Addr().String())
+	require.NoError(t, sw.Init())
 	require.NoError(t, sw.Connect())
 
 	lconn, err := listener.Accept()
@@ -41,6 +50,7 @@ func TestSocketWriter_udp(t *testing.T) {
 	require.NoError(t, err)
 
 	sw := newSocketWriter(t, "udp://"+listener.LocalAddr().String())
+	require.NoError(t, sw.Init())
 	require.NoError(t, sw.Connect())
 
 	testSocketWriterPacket(t, sw, listener)
@@ -53,6 +63,7 @@ func TestSocketWriter_unix(t *testing.T) {
 	require.NoError(t, err)
 
 	sw := newSocketWriter(t, "unix://"+sock)
+	require.NoError(t, sw.Init())
 	require.NoError(t, sw.Connect())
 
 	lconn, err := listener.Accept()
@@ -72,6 +83,7 @@ func TestSocketWriter_unixgram(t *testing.T) {
 	require.NoError(t, err)
 
 	sw := newSocketWriter(t, "unixgram://"+sock)
+	require.NoError(t, sw.Init())
 	require.NoError(t, sw.Connect())
 
 	testSocketWriterPacket(t, sw, listener)
@@ -137,8 +149,9 @@ func TestSocketWriter_Write_err(t *testing.T) {
 	require.NoError(t, err)
 
 	sw := newSocketWriter(t, "tcp://"+listener.Addr().String())
+	require.NoError(t, sw.Init())
 	require.NoError(t, sw.Connect())
-	require.NoError(t, sw.Conn.(*net.TCPConn).SetReadBuffer(256))
+	require.NoError(t, sw.conns[0].Conn.(*net.TCPConn).SetReadBuffer(256))
 
 	lconn, err := listener.Accept()
 	require.NoError(t, err)
@@ -151,12 +164,12 @@ func TestSocketWriter_Write_err(t *testing.T) {
 	err = lconn.Close()
 	require.NoError(t, err)
 
-	err = sw.Conn.Close()
+	err = sw.conns[0].Close()
 	require.NoError(t, err)
 
 	err = sw.Write(metrics)
 	require.Error(t, err)
-	require.Nil(t, sw.Conn)
+	require.Nil(t, sw.conns[0].Conn)
 }
 
 func TestSocketWriter_Write_reconnect(t *testing.T) {
@@ -164,8 +177,9 @@ func TestSocketWriter_Write_reconnect(t *testing.T) {
 	require.NoError(t, err)
 
 	sw := newSocketWriter(t, "tcp://"+listener.Addr().String())
+	require.NoError(t, sw.Init())
 	require.NoError(t, sw.Connect())
-	require.NoError(t, sw.Conn.(*net.TCPConn).SetReadBuffer(256))
+	require.NoError(t, sw.conns[0].Conn.(*net.TCPConn).SetReadBuffer(256))
 
 	lconn, err := listener.Accept()
 	require.NoError(t, err)
@@ -174,7 +188,7 @@ func TestSocketWriter_Write_reconnect(t *testing.T) {
 
 	err = lconn.Close()
 	require.NoError(t, err)
-	sw.Conn = nil
+	require.NoError(t, sw.Close())
 
 	wg := sync.WaitGroup{}
 	wg.Add(1)
@@ -205,7 +219,194 @@ func TestSocketWriter_udp_gzip(t *testing.T) {
 
 	sw := newSocketWriter(t, "udp://"+listener.LocalAddr().String())
 	sw.ContentEncoding = "gzip"
+	require.NoError(t, sw.Init())
 	require.NoError(t, sw.Connect())
 
 	testSocketWriterPacket(t, sw, listener)
 }
+
+func TestSocketWriter_round_robin(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sw := newSocketWriter(t, "tcp://"+listener.Addr().String())
+	sw.Connections = 3
+	require.NoError(t, sw.Init())
+	require.NoError(t, sw.Connect())
+	defer sw.Close()
+
+	lconns := make([]net.Conn, 0, 3)
+	for range 3 {
+		lconn, err := listener.Accept()
+		require.NoError(t, err)
+		defer lconn.Close()
+		lconns = append(lconns, lconn)
+	}
+
+	metrics := make([]telegraf.Metric, 0, 6)
+	for i := range 6 {
+		metrics = append(metrics, testutil.TestMetric(i, "test"))
+	}
+	require.NoError(t, sw.Write(metrics))
+
+	// Each connection must receive two metrics
+	for _, lconn := range lconns {
+		scnr := bufio.NewScanner(lconn)
+		require.True(t, scnr.Scan())
+		require.True(t, scnr.Scan())
+	}
+}
+
+func TestSocketWriter_failover(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sw := newSocketWriter(t, "tcp://"+listener.Addr().String())
+	sw.Connections = 2
+	sw.ReconnectBackoffMin = config.Duration(time.Minute)
+	require.NoError(t, sw.Init())
+	require.NoError(t, sw.Connect())
+	defer sw.Close()
+
+	lconn1, err := listener.Accept()
+	require.NoError(t, err)
+	defer lconn1.Close()
+	lconn2, err := listener.Accept()
+	require.NoError(t, err)
+	defer lconn2.Close()
+
+	// Break the first connection
+	require.NoError(t, sw.conns[0].Close())
+
+	metrics := []telegraf.Metric{
+		testutil.TestMetric(1, "test"),
+		testutil.TestMetric(2, "test"),
+	}
+	require.NoError(t, sw.Write(metrics))
+
+	// The broken connection is closed and waits for reconnection while the
+	// metrics are written to the remaining connection
+	require.Nil(t, sw.conns[0].Conn)
+	require.Equal(t, 1, sw.conns[0].failures)
+	require.True(t, sw.conns[0].retryAt.After(time.Now()))
+	require.NotNil(t, sw.conns[1].Conn)
+
+	// Figure out which of the accepted connections is the remaining one
+	var scnr *bufio.Scanner
+	for _, lconn := range []net.Conn{lconn1, lconn2} {
+		if lconn.RemoteAddr().String() == sw.conns[1].LocalAddr().String() {
+			scnr = bufio.NewScanner(lconn)
+		}
+	}
+	require.NotNil(t, scnr)
+	for _, m := range metrics {
+		expected, err := sw.serializer.Serialize(m)
+		require.NoError(t, err)
+		require.True(t, scnr.Scan())
+		require.Equal(t, string(expected), scnr.Text()+"\n")
+	}
+}
+
+func TestSocketWriter_no_connection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	sw := newSocketWriter(t, "tcp://"+listener.Addr().String())
+	sw.ReconnectBackoffMin = config.Duration(time.Minute)
+	require.NoError(t, sw.Init())
+	require.NoError(t, sw.Connect())
+	defer sw.Close()
+
+	lconn, err := listener.Accept()
+	require.NoError(t, err)
+	require.NoError(t, lconn.Close())
+	require.NoError(t, listener.Close())
+
+	// Break the connection and make sure all metrics are kept for retrying
+	require.NoError(t, sw.conns[0].Close())
+
+	metrics := []telegraf.Metric{
+		testutil.TestMetric(1, "test"),
+		testutil.TestMetric(2, "test"),
+	}
+	err = sw.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Empty(t, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+	require.Nil(t, sw.conns[0].Conn)
+}
+
+func TestSocketWriter_backoff(t *testing.T) {
+	sw := &SocketWriter{
+		ReconnectBackoffMin: config.Duration(time.Second),
+		ReconnectBackoffMax: config.Duration(5 * time.Second),
+	}
+
+	expected := []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	}
+	c := &connection{}
+	for i, delay := range expected {
+		start := time.Now()
+		sw.backoff(c)
+		require.Equal(t, i+1, c.failures)
+		require.WithinDuration(t, start.Add(delay), c.retryAt, 100*time.Millisecond)
+	}
+}
+
+func TestSocketWriter_tls_session_resumption(t *testing.T) {
+	serverTLS, err := pki.TLSServerConfig().TLSConfig()
+	require.NoError(t, err)
+	// Session tickets are only received by write-only clients if the server
+	// issues them during the handshake
+	serverTLS.MaxVersion = tls.VersionTLS12
+	serverTLS.CipherSuites = nil
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverTLS)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	resumed := make(chan bool, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			tlsConn := conn.(*tls.Conn)
+			if err := tlsConn.Handshake(); err != nil {
+				conn.Close()
+				return
+			}
+			resumed <- tlsConn.ConnectionState().DidResume
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+
+	sw := newSocketWriter(t, "tcp://"+listener.Addr().String())
+	sw.ClientConfig = *pki.TLSClientConfig()
+	require.NoError(t, sw.Init())
+	require.NoError(t, sw.Connect())
+	defer sw.Close()
+
+	// Complete the handshake of the first connection by writing to it
+	metrics := []telegraf.Metric{testutil.TestMetric(1, "test")}
+	require.NoError(t, sw.Write(metrics))
+	require.False(t, <-resumed)
+
+	// The second connection must resume the previous session
+	require.NoError(t, sw.Close())
+	require.NoError(t, sw.Connect())
+	require.NoError(t, sw.Write(metrics))
+	require.True(t, <-resumed)
+}
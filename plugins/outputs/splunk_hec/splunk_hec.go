@@ -0,0 +1,465 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package splunk_hec
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	eventPath = "/services/collector/event"
+	ackPath   = "/services/collector/ack"
+)
+
+type SplunkHEC struct {
+	URL             string          `toml:"url"`
+	Token           config.Secret   `toml:"token"`
+	Format          string          `toml:"format"`
+	Index           string          `toml:"index"`
+	IndexTag        string          `toml:"index_tag"`
+	Sourcetype      string          `toml:"sourcetype"`
+	SourcetypeTag   string          `toml:"sourcetype_tag"`
+	Source          string          `toml:"source"`
+	SourceTag       string          `toml:"source_tag"`
+	HostTag         string          `toml:"host_tag"`
+	ContentEncoding string          `toml:"content_encoding"`
+	MaxPayloadSize  config.Size     `toml:"max_payload_size"`
+	UseAck          bool            `toml:"use_ack"`
+	Channel         string          `toml:"channel"`
+	AckTimeout      config.Duration `toml:"ack_timeout"`
+	AckInterval     config.Duration `toml:"ack_poll_interval"`
+	Log             telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	eventURL string
+	ackURL   string
+	encoder  internal.ContentEncoder
+	client   *http.Client
+}
+
+// hecEvent is a single event in the format of the HTTP Event Collector
+type hecEvent struct {
+	Time       float64                `json:"time"`
+	Host       string                 `json:"host,omitempty"`
+	Index      string                 `json:"index,omitempty"`
+	Source     string                 `json:"source,omitempty"`
+	Sourcetype string                 `json:"sourcetype,omitempty"`
+	Event      interface{}            `json:"event"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+}
+
+// hecResponse is the response of the HTTP Event Collector for both events
+// and acknowledgment requests
+type hecResponse struct {
+	Text  string          `json:"text"`
+	Code  int             `json:"code"`
+	AckID *int64          `json:"ackId"`
+	Acks  map[string]bool `json:"acks"`
+}
+
+// pendingRequest holds the indices of the metrics sent with a request
+// waiting for acknowledgment
+type pendingRequest struct {
+	ackID   int64
+	indices []int
+}
+
+func (*SplunkHEC) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SplunkHEC) Init() error {
+	if s.URL == "" {
+		return errors.New("url required")
+	}
+	if s.Token.Empty() {
+		return errors.New("token required")
+	}
+
+	switch s.Format {
+	case "":
+		s.Format = "metric"
+	case "metric", "event":
+	default:
+		return fmt.Errorf("invalid format %q", s.Format)
+	}
+
+	switch s.ContentEncoding {
+	case "", "identity", "gzip":
+	default:
+		return fmt.Errorf("invalid content encoding %q", s.ContentEncoding)
+	}
+	encoder, err := internal.NewContentEncoder(s.ContentEncoding)
+	if err != nil {
+		return err
+	}
+	s.encoder = encoder
+
+	if s.MaxPayloadSize <= 0 {
+		return errors.New("max_payload_size must be positive")
+	}
+
+	if s.UseAck {
+		if s.Channel == "" {
+			s.Channel = uuid.NewString()
+		}
+		if s.AckTimeout <= 0 || s.AckInterval <= 0 {
+			return errors.New("ack_timeout and ack_poll_interval must be positive")
+		}
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("parsing url failed: %w", err)
+	}
+	base := strings.TrimSuffix(u.Path, "/")
+	u.Path = base + eventPath
+	s.eventURL = u.String()
+	u.Path = base + ackPath
+	query := u.Query()
+	query.Set("channel", s.Channel)
+	u.RawQuery = query.Encode()
+	s.ackURL = u.String()
+
+	return nil
+}
+
+func (s *SplunkHEC) Connect() error {
+	client, err := s.HTTPClientConfig.CreateClient(context.Background(), s.Log)
+	if err != nil {
+		return err
+	}
+	s.client = client
+
+	return nil
+}
+
+func (s *SplunkHEC) Close() error {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Write sends the metrics as events in requests limited in size and waits
+// for the acknowledgment of the requests if enabled
+func (s *SplunkHEC) Write(metrics []telegraf.Metric) error {
+	writeErr := &internal.PartialWriteError{
+		MetricsAccept: make([]int, 0, len(metrics)),
+	}
+
+	var pending []pendingRequest
+	var buffer bytes.Buffer
+	indices := make([]int, 0, len(metrics))
+	for i, m := range metrics {
+		e := s.event(m)
+		if e == nil {
+			s.Log.Debugf("Metric %q does not contain any numeric fields, skipping", m.Name())
+			writeErr.MetricsAccept = append(writeErr.MetricsAccept, i)
+			continue
+		}
+
+		event, err := json.Marshal(e)
+		if err != nil {
+			s.Log.Errorf("Could not serialize metric %q: %v", m.Name(), err)
+			writeErr.Err = fmt.Errorf("serializing metric failed: %w", err)
+			writeErr.MetricsReject = append(writeErr.MetricsReject, i)
+			continue
+		}
+
+		if buffer.Len() > 0 && buffer.Len()+len(event) > int(s.MaxPayloadSize) {
+			if !s.flush(&buffer, indices, writeErr, &pending) {
+				// Keep the remaining metrics for the next write
+				indices = nil
+				break
+			}
+			indices = make([]int, 0, len(metrics)-i)
+		}
+
+		buffer.Write(event)
+		indices = append(indices, i)
+	}
+
+	if len(indices) > 0 {
+		s.flush(&buffer, indices, writeErr, &pending)
+	}
+
+	if len(pending) > 0 {
+		s.waitForAcks(pending, writeErr)
+	}
+
+	if writeErr.Err == nil {
+		return nil
+	}
+	return writeErr
+}
+
+// flush sends the buffered events and updates the accepted and rejected
+// metrics accordingly. Metrics of requests requiring an acknowledgment are
+// added to the pending requests instead. False is returned if the write
+// should be retried.
+func (s *SplunkHEC) flush(buffer *bytes.Buffer, indices []int, writeErr *internal.PartialWriteError, pending *[]pendingRequest) bool {
+	ackID, retryable, err := s.send(buffer.Bytes())
+	buffer.Reset()
+	if err != nil {
+		writeErr.Err = err
+		if retryable {
+			return false
+		}
+		writeErr.MetricsReject = append(writeErr.MetricsReject, indices...)
+		return true
+	}
+
+	if s.UseAck {
+		if ackID == nil {
+			writeErr.Err = errors.New("no acknowledgment ID received, is indexer acknowledgment enabled for the token?")
+			return false
+		}
+		*pending = append(*pending, pendingRequest{ackID: *ackID, indices: indices})
+		return true
+	}
+	writeErr.MetricsAccept = append(writeErr.MetricsAccept, indices...)
+	return true
+}
+
+// event converts the metric to an event according to the configured format
+// and returns nil if there is nothing to send
+func (s *SplunkHEC) event(m telegraf.Metric) *hecEvent {
+	e := &hecEvent{
+		Time:       float64(m.Time().UnixNano()) / float64(time.Second),
+		Index:      s.Index,
+		Source:     s.Source,
+		Sourcetype: s.Sourcetype,
+	}
+
+	tags := make(map[string]string, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		switch tag.Key {
+		case s.HostTag:
+			e.Host = tag.Value
+		case s.IndexTag:
+			e.Index = tag.Value
+		case s.SourcetypeTag:
+			e.Sourcetype = tag.Value
+		case s.SourceTag:
+			e.Source = tag.Value
+		default:
+			tags[tag.Key] = tag.Value
+		}
+	}
+
+	if s.Format == "event" {
+		fields := make(map[string]interface{}, len(m.FieldList()))
+		for _, field := range m.FieldList() {
+			// JSON does not support non-finite numbers
+			if v, ok := field.Value.(float64); ok && (math.IsNaN(v) || math.IsInf(v, 0)) {
+				continue
+			}
+			fields[field.Key] = field.Value
+		}
+		e.Event = map[string]interface{}{
+			"name":   m.Name(),
+			"tags":   tags,
+			"fields": fields,
+		}
+		return e
+	}
+
+	// Use the multiple-metric format with the tags as dimensions
+	e.Event = "metric"
+	e.Fields = make(map[string]interface{}, len(tags)+len(m.FieldList()))
+	for k, v := range tags {
+		e.Fields[k] = v
+	}
+	var found bool
+	for _, field := range m.FieldList() {
+		value, ok := metricValue(field.Value)
+		if !ok {
+			continue
+		}
+		e.Fields["metric_name:"+m.Name()+"."+field.Key] = value
+		found = true
+	}
+	if !found {
+		return nil
+	}
+	return e
+}
+
+// metricValue returns the numeric value of the field, booleans are
+// converted to 1 and 0
+func metricValue(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case int64, uint64:
+		return v, true
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return nil, false
+}
+
+// send posts the events and returns the acknowledgment ID if any, whether
+// the request should be retried in case of an error and the error
+func (s *SplunkHEC) send(body []byte) (*int64, bool, error) {
+	payload, err := s.encoder.Encode(body)
+	if err != nil {
+		return nil, false, fmt.Errorf("encoding content failed: %w", err)
+	}
+
+	resp, err := s.post(s.eventURL, payload, true)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	var result hecResponse
+	respBody, err := io.ReadAll(resp.Body)
+	if err == nil {
+		err = json.Unmarshal(respBody, &result)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		if err != nil {
+			return nil, false, nil
+		}
+		return result.AckID, false, nil
+	}
+
+	// Invalid events are rejected, all other errors such as invalid tokens,
+	// a disabled collector or a busy server are temporary or configuration
+	// issues and should be retried
+	retryable := resp.StatusCode != http.StatusBadRequest && resp.StatusCode != http.StatusRequestEntityTooLarge
+	if err == nil && result.Text != "" {
+		return nil, retryable, fmt.Errorf("failed to write events: [%d] %s (code %d)", resp.StatusCode, result.Text, result.Code)
+	}
+	return nil, retryable, fmt.Errorf("failed to write events: [%d] %s", resp.StatusCode, resp.Status)
+}
+
+// waitForAcks polls the acknowledgment status of the pending requests until
+// all requests are acknowledged or the timeout is reached. Metrics of
+// acknowledged requests are accepted, all others are kept for retrying.
+func (s *SplunkHEC) waitForAcks(pending []pendingRequest, writeErr *internal.PartialWriteError) {
+	deadline := time.Now().Add(time.Duration(s.AckTimeout))
+	for {
+		acked, err := s.queryAcks(pending)
+		if err != nil {
+			s.Log.Debugf("Querying acknowledgments failed: %v", err)
+		}
+
+		remaining := pending[:0]
+		for _, p := range pending {
+			if acked[strconv.FormatInt(p.ackID, 10)] {
+				writeErr.MetricsAccept = append(writeErr.MetricsAccept, p.indices...)
+			} else {
+				remaining = append(remaining, p)
+			}
+		}
+		pending = remaining
+		if len(pending) == 0 {
+			return
+		}
+
+		if time.Now().Add(time.Duration(s.AckInterval)).After(deadline) {
+			writeErr.Err = fmt.Errorf("%d request(s) not acknowledged within %s", len(pending), time.Duration(s.AckTimeout))
+			return
+		}
+		time.Sleep(time.Duration(s.AckInterval))
+	}
+}
+
+func (s *SplunkHEC) queryAcks(pending []pendingRequest) (map[string]bool, error) {
+	ids := make([]int64, 0, len(pending))
+	for _, p := range pending {
+		ids = append(ids, p.ackID)
+	}
+	body, err := json.Marshal(map[string][]int64{"acks": ids})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.post(s.ackURL, body, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("received status %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var result hecResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	return result.Acks, nil
+}
+
+func (s *SplunkHEC) post(address string, body []byte, encoded bool) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.Token.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting token failed: %w", err)
+	}
+	req.Header.Set("Authorization", "Splunk "+token.String())
+	token.Destroy()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", internal.ProductToken())
+	if encoded && s.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if s.Channel != "" {
+		req.Header.Set("X-Splunk-Request-Channel", s.Channel)
+	}
+
+	return s.client.Do(req)
+}
+
+func init() {
+	outputs.Add("splunk_hec", func() telegraf.Output {
+		return &SplunkHEC{
+			HostTag:         "host",
+			ContentEncoding: "gzip",
+			MaxPayloadSize:  config.Size(1024 * 1024),
+			AckTimeout:      config.Duration(30 * time.Second),
+			AckInterval:     config.Duration(time.Second),
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
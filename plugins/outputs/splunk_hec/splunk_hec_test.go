@@ -0,0 +1,384 @@
+package splunk_hec
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type server struct {
+	status int
+	ack    bool
+	acked  bool
+
+	sync.Mutex
+	nextAckID int64
+	channels  []string
+	requests  [][]map[string]interface{}
+	ackPolls  int
+}
+
+func (s *server) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Splunk secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+			return
+		}
+
+		s.Lock()
+		defer s.Unlock()
+
+		switch r.URL.Path {
+		case "/services/collector/event":
+		case "/services/collector/ack":
+			if r.URL.Query().Get("channel") != r.Header.Get("X-Splunk-Request-Channel") {
+				w.WriteHeader(http.StatusBadRequest)
+				t.Errorf("unexpected channel %q", r.URL.Query().Get("channel"))
+				return
+			}
+			var req struct {
+				Acks []int64 `json:"acks"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				t.Error(err)
+				return
+			}
+			s.ackPolls++
+			acks := make(map[string]bool, len(req.Acks))
+			for _, id := range req.Acks {
+				// Acknowledge the requests with the second poll
+				acks[fmt.Sprintf("%d", id)] = s.acked && s.ackPolls > 1
+			}
+			if err := json.NewEncoder(w).Encode(map[string]interface{}{"acks": acks}); err != nil {
+				t.Error(err)
+			}
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			t.Errorf("unexpected path %q", r.URL.Path)
+			return
+		}
+
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				t.Error(err)
+				return
+			}
+			body = gz
+		}
+
+		var events []map[string]interface{}
+		decoder := json.NewDecoder(body)
+		for decoder.More() {
+			var event map[string]interface{}
+			if err := decoder.Decode(&event); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				t.Error(err)
+				return
+			}
+			events = append(events, event)
+		}
+		s.requests = append(s.requests, events)
+		s.channels = append(s.channels, r.Header.Get("X-Splunk-Request-Channel"))
+
+		if s.status != 0 {
+			w.WriteHeader(s.status)
+			if _, err := w.Write([]byte(`{"text":"Invalid data format","code":6}`)); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+
+		response := map[string]interface{}{"text": "Success", "code": 0}
+		if s.ack {
+			response["ackId"] = s.nextAckID
+			s.nextAckID++
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func newPlugin(address string) *SplunkHEC {
+	return &SplunkHEC{
+		URL:             address,
+		Token:           config.NewSecret([]byte("secret-token")),
+		HostTag:         "host",
+		ContentEncoding: "gzip",
+		MaxPayloadSize:  config.Size(1024 * 1024),
+		AckTimeout:      config.Duration(time.Second),
+		AckInterval:     config.Duration(10 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+}
+
+func testMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "server01", "cpu": "cpu0", "index": "infra"},
+			map[string]interface{}{"usage_idle": 91.5, "count": int64(3), "active": true, "mode": "rw"},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"disk",
+			map[string]string{"host": "server02", "path": "/"},
+			map[string]interface{}{"used": uint64(42)},
+			time.Unix(1700000010, 500000000),
+		),
+	}
+}
+
+func TestWriteMetricFormat(t *testing.T) {
+	srv := &server{}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.Index = "metrics"
+	plugin.IndexTag = "index"
+	plugin.Sourcetype = "telegraf"
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	input := append(testMetrics(), metric.New(
+		"status",
+		map[string]string{},
+		map[string]interface{}{"message": "ok"},
+		time.Unix(1700000020, 0),
+	))
+	require.NoError(t, plugin.Write(input))
+
+	expected := [][]map[string]interface{}{
+		{
+			{
+				"time":       1700000000.0,
+				"host":       "server01",
+				"index":      "infra",
+				"sourcetype": "telegraf",
+				"event":      "metric",
+				"fields": map[string]interface{}{
+					"cpu":                        "cpu0",
+					"metric_name:cpu.usage_idle": 91.5,
+					"metric_name:cpu.count":      3.0,
+					"metric_name:cpu.active":     1.0,
+				},
+			},
+			{
+				"time":       1700000010.5,
+				"host":       "server02",
+				"index":      "metrics",
+				"sourcetype": "telegraf",
+				"event":      "metric",
+				"fields": map[string]interface{}{
+					"path":                  "/",
+					"metric_name:disk.used": 42.0,
+				},
+			},
+		},
+	}
+	require.Equal(t, expected, srv.requests)
+}
+
+func TestWriteEventFormat(t *testing.T) {
+	srv := &server{}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.Format = "event"
+	plugin.ContentEncoding = "identity"
+	plugin.SourceTag = "path"
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write(testMetrics()))
+
+	expected := [][]map[string]interface{}{
+		{
+			{
+				"time": 1700000000.0,
+				"host": "server01",
+				"event": map[string]interface{}{
+					"name": "cpu",
+					"tags": map[string]interface{}{"cpu": "cpu0", "index": "infra"},
+					"fields": map[string]interface{}{
+						"usage_idle": 91.5,
+						"count":      3.0,
+						"active":     true,
+						"mode":       "rw",
+					},
+				},
+			},
+			{
+				"time":   1700000010.5,
+				"host":   "server02",
+				"source": "/",
+				"event": map[string]interface{}{
+					"name":   "disk",
+					"tags":   map[string]interface{}{},
+					"fields": map[string]interface{}{"used": 42.0},
+				},
+			},
+		},
+	}
+	require.Equal(t, expected, srv.requests)
+}
+
+func TestWriteSplitPayload(t *testing.T) {
+	srv := &server{}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.MaxPayloadSize = 50
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write(testMetrics()))
+	require.Len(t, srv.requests, 2)
+	require.Len(t, srv.requests[0], 1)
+	require.Len(t, srv.requests[1], 1)
+}
+
+func TestWriteRejected(t *testing.T) {
+	srv := &server{status: http.StatusBadRequest}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	err := plugin.Write(testMetrics())
+	require.ErrorContains(t, err, "Invalid data format (code 6)")
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Empty(t, writeErr.MetricsAccept)
+	require.Equal(t, []int{0, 1}, writeErr.MetricsReject)
+}
+
+func TestWriteRetry(t *testing.T) {
+	srv := &server{status: http.StatusServiceUnavailable}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	err := plugin.Write(testMetrics())
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Empty(t, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+}
+
+func TestWriteAcknowledged(t *testing.T) {
+	srv := &server{ack: true, acked: true}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.UseAck = true
+	plugin.MaxPayloadSize = 50
+	require.NoError(t, plugin.Init())
+	require.NotEmpty(t, plugin.Channel)
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write(testMetrics()))
+	require.Len(t, srv.requests, 2)
+	require.Equal(t, []string{plugin.Channel, plugin.Channel}, srv.channels)
+	require.Equal(t, 2, srv.ackPolls)
+}
+
+func TestWriteNotAcknowledged(t *testing.T) {
+	srv := &server{ack: true}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.UseAck = true
+	plugin.AckTimeout = config.Duration(50 * time.Millisecond)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	err := plugin.Write(testMetrics())
+	require.ErrorContains(t, err, "not acknowledged")
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Empty(t, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*SplunkHEC)
+		expected string
+	}{
+		{
+			name:     "missing url",
+			modify:   func(s *SplunkHEC) { s.URL = "" },
+			expected: "url required",
+		},
+		{
+			name:     "missing token",
+			modify:   func(s *SplunkHEC) { s.Token = config.NewSecret(nil) },
+			expected: "token required",
+		},
+		{
+			name:     "invalid format",
+			modify:   func(s *SplunkHEC) { s.Format = "raw" },
+			expected: `invalid format "raw"`,
+		},
+		{
+			name:     "invalid encoding",
+			modify:   func(s *SplunkHEC) { s.ContentEncoding = "zstd" },
+			expected: `invalid content encoding "zstd"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newPlugin("http://localhost:8088")
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestEventURL(t *testing.T) {
+	plugin := newPlugin("https://splunk.example.com:8088/proxy/")
+	plugin.UseAck = true
+	plugin.Channel = "5D4F4C2A-4F7A-4C1B-9A55-1B9B1F6C1A11"
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "https://splunk.example.com:8088/proxy/services/collector/event", plugin.eventURL)
+	require.Equal(t, "https://splunk.example.com:8088/proxy/services/collector/ack?channel=5D4F4C2A-4F7A-4C1B-9A55-1B9B1F6C1A11", plugin.ackURL)
+}
@@ -53,7 +53,9 @@ func (cc *counterCache) GetStartTime(key string, value *monpb.TypedValue, endTim
 	}
 
 	// update of existing entry
-	if value.GetDoubleValue() < lastObserved.LastValue.GetDoubleValue() || value.GetInt64Value() < lastObserved.LastValue.GetInt64Value() {
+	if value.GetDoubleValue() < lastObserved.LastValue.GetDoubleValue() ||
+		value.GetInt64Value() < lastObserved.LastValue.GetInt64Value() ||
+		value.GetDistributionValue().GetCount() < lastObserved.LastValue.GetDistributionValue().GetCount() {
 		// counter reset
 		lastObserved.Reset(endTime)
 	} else {
@@ -0,0 +1,189 @@
+package stackdriver
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"google.golang.org/genproto/googleapis/api/distribution"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Tags and field suffix used by the histogram aggregator
+const (
+	bucketRightTag    = "le"
+	bucketLeftTag     = "gt"
+	bucketFieldSuffix = "_bucket"
+)
+
+// bucketHistogram collects the buckets of a field emitted as separate metrics
+// by the histogram aggregator
+type bucketHistogram struct {
+	// metric without the bucket tags holding the count of all buckets
+	metric telegraf.Metric
+	field  string
+
+	// cumulative is true if the count of a bucket includes all lower buckets
+	cumulative bool
+	counts     map[float64]int64
+}
+
+// splitHistogramBuckets separates the metrics of the histogram aggregator from
+// the other metrics of the batch and combines the buckets of each series
+func splitHistogramBuckets(batch []telegraf.Metric) ([]telegraf.Metric, []*bucketHistogram) {
+	remaining := make([]telegraf.Metric, 0, len(batch))
+	histograms := make(map[uint64]*bucketHistogram)
+	var order []uint64
+	for _, m := range batch {
+		le, ok := m.GetTag(bucketRightTag)
+		if !ok || !isBucketMetric(m) {
+			remaining = append(remaining, m)
+			continue
+		}
+		bound, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			remaining = append(remaining, m)
+			continue
+		}
+		_, hasLeft := m.GetTag(bucketLeftTag)
+
+		for _, f := range m.FieldList() {
+			count, err := internal.ToInt64(f.Value)
+			if err != nil {
+				continue
+			}
+			field := strings.TrimSuffix(f.Key, bucketFieldSuffix)
+
+			id := bucketSeriesID(m, field)
+			h, found := histograms[id]
+			if !found {
+				tags := make(map[string]string, len(m.TagList()))
+				for _, tag := range m.TagList() {
+					if tag.Key != bucketRightTag && tag.Key != bucketLeftTag {
+						tags[tag.Key] = tag.Value
+					}
+				}
+				h = &bucketHistogram{
+					metric:     metric.New(m.Name(), tags, nil, m.Time(), telegraf.Histogram),
+					field:      field,
+					cumulative: !hasLeft,
+					counts:     make(map[float64]int64),
+				}
+				histograms[id] = h
+				order = append(order, id)
+			}
+			h.counts[bound] = count
+		}
+	}
+
+	result := make([]*bucketHistogram, 0, len(order))
+	for _, id := range order {
+		h := histograms[id]
+		h.metric.AddField(h.field, h.count())
+		result = append(result, h)
+	}
+	return remaining, result
+}
+
+// isBucketMetric returns true if all fields of the metric are bucket counts
+func isBucketMetric(m telegraf.Metric) bool {
+	if len(m.FieldList()) == 0 {
+		return false
+	}
+	for _, f := range m.FieldList() {
+		if !strings.HasSuffix(f.Key, bucketFieldSuffix) {
+			return false
+		}
+	}
+	return true
+}
+
+func bucketSeriesID(m telegraf.Metric, field string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(m.Name()))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(field))
+	h.Write([]byte{'\n'})
+	for _, tag := range m.TagList() {
+		if tag.Key == bucketRightTag || tag.Key == bucketLeftTag {
+			continue
+		}
+		h.Write([]byte(tag.Key))
+		h.Write([]byte{'\n'})
+		h.Write([]byte(tag.Value))
+		h.Write([]byte{'\n'})
+	}
+	return h.Sum64()
+}
+
+// bucketCounts returns the finite bucket bounds in ascending order and the
+// number of values in each bucket including the overflow bucket
+func (h *bucketHistogram) bucketCounts() ([]float64, []int64) {
+	bounds := make([]float64, 0, len(h.counts))
+	for bound := range h.counts {
+		if !math.IsInf(bound, 1) {
+			bounds = append(bounds, bound)
+		}
+	}
+	sort.Float64s(bounds)
+
+	counts := make([]int64, 0, len(bounds)+1)
+	for _, bound := range bounds {
+		counts = append(counts, h.counts[bound])
+	}
+	counts = append(counts, h.counts[math.Inf(1)])
+
+	// Convert the running total to the count of the individual buckets
+	if h.cumulative {
+		// A missing overflow bucket does not contain any values
+		if _, found := h.counts[math.Inf(1)]; !found && len(counts) > 1 {
+			counts[len(counts)-1] = counts[len(counts)-2]
+		}
+		for i := len(counts) - 1; i > 0; i-- {
+			counts[i] = max(counts[i]-counts[i-1], 0)
+		}
+	}
+	return bounds, counts
+}
+
+func (h *bucketHistogram) count() int64 {
+	_, counts := h.bucketCounts()
+	var total int64
+	for _, c := range counts {
+		total += c
+	}
+	return total
+}
+
+// distribution converts the buckets to a distribution value. The histogram
+// aggregator does not report the sum of the values, so the mean is unknown
+// and left at zero.
+func (h *bucketHistogram) distribution() *monitoringpb.TypedValue {
+	bounds, counts := h.bucketCounts()
+	var total int64
+	for _, c := range counts {
+		total += c
+	}
+
+	return &monitoringpb.TypedValue{
+		Value: &monitoringpb.TypedValue_DistributionValue{
+			DistributionValue: &distribution.Distribution{
+				Count:        total,
+				BucketCounts: counts,
+				BucketOptions: &distribution.Distribution_BucketOptions{
+					Options: &distribution.Distribution_BucketOptions_ExplicitBuckets{
+						ExplicitBuckets: &distribution.Distribution_BucketOptions_Explicit{
+							Bounds: bounds,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package stackdriver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestSplitHistogramBuckets(t *testing.T) {
+	ts := time.Unix(0, 0)
+	batch := []telegraf.Metric{
+		// Non-cumulative buckets for the values [50, 7, 99, 12]
+		metric.New("cpu", map[string]string{"gt": "-Inf", "le": "0"}, map[string]interface{}{"usage_idle_bucket": int64(0)}, ts),
+		metric.New("cpu", map[string]string{"gt": "0", "le": "10"}, map[string]interface{}{"usage_idle_bucket": int64(1)}, ts),
+		metric.New("cpu", map[string]string{"gt": "10", "le": "50"}, map[string]interface{}{"usage_idle_bucket": int64(2)}, ts),
+		metric.New("cpu", map[string]string{"gt": "50", "le": "100"}, map[string]interface{}{"usage_idle_bucket": int64(1)}, ts),
+		metric.New("cpu", map[string]string{"gt": "100", "le": "+Inf"}, map[string]interface{}{"usage_idle_bucket": int64(0)}, ts),
+		// Metrics not originating from the histogram aggregator
+		metric.New("http", map[string]string{"le": "0.5"}, map[string]interface{}{"duration": 1.0}, ts),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"used_bucket": int64(1)}, ts),
+	}
+
+	remaining, histograms := splitHistogramBuckets(batch)
+	require.Len(t, remaining, 2)
+	require.Len(t, histograms, 1)
+
+	h := histograms[0]
+	require.Equal(t, "usage_idle", h.field)
+	require.False(t, h.cumulative)
+	require.Empty(t, h.metric.TagList())
+	count, found := h.metric.GetField("usage_idle")
+	require.True(t, found)
+	require.Equal(t, int64(4), count)
+
+	bounds, counts := h.bucketCounts()
+	require.Equal(t, []float64{0, 10, 50, 100}, bounds)
+	require.Equal(t, []int64{0, 1, 2, 1, 0}, counts)
+}
+
+func TestBucketCountsWithoutOverflow(t *testing.T) {
+	h := &bucketHistogram{
+		cumulative: true,
+		counts:     map[float64]int64{1: 2, 5: 3, 10: 7},
+	}
+	bounds, counts := h.bucketCounts()
+	require.Equal(t, []float64{1, 5, 10}, bounds)
+	require.Equal(t, []int64{2, 1, 4, 0}, counts)
+	require.Equal(t, int64(7), h.count())
+}
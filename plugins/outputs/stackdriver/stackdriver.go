@@ -16,8 +16,10 @@ import (
 	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
 	"google.golang.org/api/option"
 	"google.golang.org/genproto/googleapis/api/distribution"
+	labelpb "google.golang.org/genproto/googleapis/api/label"
 	metricpb "google.golang.org/genproto/googleapis/api/metric"
 	monitoredrespb "google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -43,6 +45,8 @@ type Stackdriver struct {
 	MetricCounter        []string          `toml:"metric_counter"`
 	MetricGauge          []string          `toml:"metric_gauge"`
 	MetricHistogram      []string          `toml:"metric_histogram"`
+	HistogramBuckets     bool              `toml:"histogram_aggregator_buckets"`
+	CreateDescriptors    bool              `toml:"create_metric_descriptors"`
 	Log                  telegraf.Logger   `toml:"-"`
 
 	client          *monitoring.MetricClient
@@ -50,6 +54,9 @@ type Stackdriver struct {
 	filterCounter   filter.Filter
 	filterGauge     filter.Filter
 	filterHistogram filter.Filter
+
+	// label keys of the metric descriptors created per metric type
+	descriptors map[string]map[string]bool
 }
 
 const (
@@ -68,6 +75,10 @@ const (
 
 	// MaxInt is the max int64 value.
 	MaxInt = int(^uint(0) >> 1)
+
+	// maxTimeSeriesPerRequest is the maximum number of time series in a
+	// single CreateTimeSeries request.
+	maxTimeSeriesPerRequest = 200
 )
 
 func (s *Stackdriver) Init() error {
@@ -134,6 +145,10 @@ func (s *Stackdriver) Connect() error {
 		s.counterCache = NewCounterCache(s.Log)
 	}
 
+	if s.descriptors == nil {
+		s.descriptors = make(map[string]map[string]bool)
+	}
+
 	s.ResourceLabels["project_id"] = s.Project
 
 	if s.client == nil {
@@ -217,6 +232,11 @@ func (s *Stackdriver) Write(metrics []telegraf.Metric) error {
 func (s *Stackdriver) sendBatch(batch []telegraf.Metric) error {
 	ctx := context.Background()
 
+	var histograms []*bucketHistogram
+	if s.HistogramBuckets {
+		batch, histograms = splitHistogramBuckets(batch)
+	}
+
 	buckets := make(timeSeriesBuckets)
 	for _, m := range batch {
 		// Set metric types based on user-provided filter
@@ -367,6 +387,14 @@ func (s *Stackdriver) sendBatch(batch []telegraf.Metric) error {
 		}
 	}
 
+	for _, h := range histograms {
+		s.addBucketHistogram(buckets, h)
+	}
+
+	if s.CreateDescriptors {
+		s.createMetricDescriptors(ctx, buckets)
+	}
+
 	// process the buckets in order
 	keys := make([]uint64, 0, len(buckets))
 	for k := range buckets {
@@ -375,8 +403,9 @@ func (s *Stackdriver) sendBatch(batch []telegraf.Metric) error {
 	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
 
 	for len(buckets) != 0 {
-		// can send up to 200 time series to stackdriver
-		timeSeries := make([]*monitoringpb.TimeSeries, 0, 200)
+		// Each request may contain a limited number of time series and only a
+		// single point per time series
+		timeSeries := make([]*monitoringpb.TimeSeries, 0, maxTimeSeriesPerRequest)
 		for i := 0; i < len(keys) && len(timeSeries) < cap(timeSeries); i++ {
 			k := keys[i]
 			s := buckets[k]
@@ -649,7 +678,11 @@ func buildHistogram(m telegraf.Metric) (*monitoringpb.TypedValue, error) {
 func (s *Stackdriver) getStackdriverLabels(tags []*telegraf.Tag) map[string]string {
 	labels := make(map[string]string)
 	for _, t := range tags {
-		labels[t.Key] = t.Value
+		key := t.Key
+		if s.CreateDescriptors {
+			key = sanitizeLabelKey(key)
+		}
+		labels[key] = t.Value
 	}
 	for k, v := range labels {
 		if len(k) > QuotaStringLengthForLabelKey {
@@ -678,6 +711,144 @@ func (s *Stackdriver) getStackdriverLabels(tags []*telegraf.Tag) map[string]stri
 	return labels
 }
 
+// addBucketHistogram adds the distribution time series of the histogram
+// aggregator buckets
+func (s *Stackdriver) addBucketHistogram(buckets timeSeriesBuckets, h *bucketHistogram) {
+	resourceLabels := make(map[string]string, len(s.ResourceLabels)+len(s.TagsAsResourceLabels))
+	for k, v := range s.ResourceLabels {
+		resourceLabels[k] = v
+	}
+	for _, tag := range s.TagsAsResourceLabels {
+		if val, ok := h.metric.GetTag(tag); ok {
+			resourceLabels[tag] = val
+			h.metric.RemoveTag(tag)
+		}
+	}
+
+	field := h.metric.FieldList()[0]
+	value := h.distribution()
+	kind := metricpb.MetricDescriptor_CUMULATIVE
+	startTime, endTime := getStackdriverIntervalEndpoints(kind, value, h.metric, field, s.counterCache)
+	timeInterval, err := getStackdriverTimeInterval(kind, startTime, endTime)
+	if err != nil {
+		s.Log.Errorf("Get time interval failed: %s", err)
+		return
+	}
+
+	timeSeries := &monitoringpb.TimeSeries{
+		Metric: &metricpb.Metric{
+			Type:   s.generateMetricName(h.metric, telegraf.Histogram, h.field),
+			Labels: s.getStackdriverLabels(h.metric.TagList()),
+		},
+		MetricKind: kind,
+		Resource: &monitoredrespb.MonitoredResource{
+			Type:   s.ResourceType,
+			Labels: resourceLabels,
+		},
+		Points: []*monitoringpb.Point{
+			{
+				Interval: timeInterval,
+				Value:    value,
+			},
+		},
+	}
+	buckets.Add(h.metric, []*telegraf.Field{field}, timeSeries)
+}
+
+// createMetricDescriptors creates the descriptors of metric types not created
+// before or updates them if new labels occur. Failures are logged only as the
+// service creates missing descriptors on write.
+func (s *Stackdriver) createMetricDescriptors(ctx context.Context, buckets timeSeriesBuckets) {
+	for _, series := range buckets {
+		for _, ts := range series {
+			known, found := s.descriptors[ts.Metric.Type]
+			missing := !found
+			for key := range ts.Metric.Labels {
+				if !known[key] {
+					missing = true
+					break
+				}
+			}
+			if !missing {
+				continue
+			}
+
+			labels := make(map[string]bool, len(known)+len(ts.Metric.Labels))
+			for key := range known {
+				labels[key] = true
+			}
+			for key := range ts.Metric.Labels {
+				labels[key] = true
+			}
+			keys := make([]string, 0, len(labels))
+			for key := range labels {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			descriptor := &metricpb.MetricDescriptor{
+				Type:        ts.Metric.Type,
+				MetricKind:  ts.MetricKind,
+				ValueType:   getStackdriverValueType(ts.Points[0].Value),
+				Description: "Telegraf metric " + ts.Metric.Type,
+				Labels:      make([]*labelpb.LabelDescriptor, 0, len(keys)),
+			}
+			for _, key := range keys {
+				descriptor.Labels = append(descriptor.Labels, &labelpb.LabelDescriptor{
+					Key:       key,
+					ValueType: labelpb.LabelDescriptor_STRING,
+				})
+			}
+
+			_, err := s.client.CreateMetricDescriptor(ctx, &monitoringpb.CreateMetricDescriptorRequest{
+				Name:             "projects/" + s.Project,
+				MetricDescriptor: descriptor,
+			})
+			if err != nil && status.Code(err) != codes.AlreadyExists {
+				s.Log.Warnf("Creating metric descriptor for %q failed: %s", ts.Metric.Type, err)
+				continue
+			}
+			s.descriptors[ts.Metric.Type] = labels
+		}
+	}
+}
+
+func getStackdriverValueType(value *monitoringpb.TypedValue) metricpb.MetricDescriptor_ValueType {
+	switch value.GetValue().(type) {
+	case *monitoringpb.TypedValue_BoolValue:
+		return metricpb.MetricDescriptor_BOOL
+	case *monitoringpb.TypedValue_Int64Value:
+		return metricpb.MetricDescriptor_INT64
+	case *monitoringpb.TypedValue_DoubleValue:
+		return metricpb.MetricDescriptor_DOUBLE
+	case *monitoringpb.TypedValue_StringValue:
+		return metricpb.MetricDescriptor_STRING
+	case *monitoringpb.TypedValue_DistributionValue:
+		return metricpb.MetricDescriptor_DISTRIBUTION
+	}
+	return metricpb.MetricDescriptor_VALUE_TYPE_UNSPECIFIED
+}
+
+// sanitizeLabelKey converts the key to a valid label key of a metric
+// descriptor consisting of lowercase letters, digits and underscores and
+// starting with a letter
+func sanitizeLabelKey(key string) string {
+	var b strings.Builder
+	b.Grow(len(key))
+	for _, r := range strings.ToLower(key) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	sanitized := b.String()
+	if sanitized == "" || sanitized[0] < 'a' || sanitized[0] > 'z' {
+		sanitized = "key_" + sanitized
+	}
+	return sanitized
+}
+
 // Close will terminate the session to the backend, returning error if an issue arises.
 func (s *Stackdriver) Close() error {
 	return s.client.Close()
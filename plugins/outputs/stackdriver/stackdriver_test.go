@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -50,6 +51,16 @@ type mockMetricServer struct {
 
 	// responses to return if err == nil
 	resps []proto.Message
+
+	descriptors []*monitoringpb.CreateMetricDescriptorRequest
+}
+
+func (s *mockMetricServer) CreateMetricDescriptor(
+	_ context.Context,
+	req *monitoringpb.CreateMetricDescriptorRequest,
+) (*metricpb.MetricDescriptor, error) {
+	s.descriptors = append(s.descriptors, req)
+	return req.MetricDescriptor, nil
 }
 
 func (s *mockMetricServer) CreateTimeSeries(ctx context.Context, req *monitoringpb.CreateTimeSeriesRequest) (*emptypb.Empty, error) {
@@ -1154,3 +1165,155 @@ func TestStackdriverMetricNameInvalid(t *testing.T) {
 	}
 	require.Error(t, s.Init())
 }
+
+func TestWriteHistogramAggregatorBuckets(t *testing.T) {
+	mockMetric.err = nil
+	mockMetric.reqs = nil
+	mockMetric.resps = append(mockMetric.resps[:0], &emptypb.Empty{})
+
+	c, err := monitoring.NewMetricClient(context.Background(), clientOpt)
+	require.NoError(t, err)
+
+	s := &Stackdriver{
+		Project:          "projects/[PROJECT]",
+		Namespace:        "test",
+		MetricTypePrefix: "custom.googleapis.com",
+		MetricNameFormat: "path",
+		HistogramBuckets: true,
+		Log:              testutil.Logger{},
+		client:           c,
+	}
+	require.NoError(t, s.Connect())
+
+	// Output of the histogram aggregator with cumulative buckets for the
+	// values [50, 7, 99, 12, 150] and an unrelated metric
+	ts := time.Unix(1700000000, 0)
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "cpu1", "le": "0"}, map[string]interface{}{"usage_idle_bucket": int64(0)}, ts),
+		metric.New("cpu", map[string]string{"cpu": "cpu1", "le": "10"}, map[string]interface{}{"usage_idle_bucket": int64(1)}, ts),
+		metric.New("cpu", map[string]string{"cpu": "cpu1", "le": "50"}, map[string]interface{}{"usage_idle_bucket": int64(3)}, ts),
+		metric.New("cpu", map[string]string{"cpu": "cpu1", "le": "100"}, map[string]interface{}{"usage_idle_bucket": int64(4)}, ts),
+		metric.New("cpu", map[string]string{"cpu": "cpu1", "le": "+Inf"}, map[string]interface{}{"usage_idle_bucket": int64(5)}, ts),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"used": int64(42)}, ts),
+	}
+	require.NoError(t, s.Write(metrics))
+
+	require.Len(t, mockMetric.reqs, 1)
+	request := mockMetric.reqs[0].(*monitoringpb.CreateTimeSeriesRequest)
+	require.Len(t, request.TimeSeries, 2)
+
+	var series *monitoringpb.TimeSeries
+	for _, ts := range request.TimeSeries {
+		if ts.Metric.Type == "custom.googleapis.com/test/cpu/usage_idle" {
+			series = ts
+		}
+	}
+	require.NotNil(t, series)
+	require.Equal(t, map[string]string{"cpu": "cpu1"}, series.Metric.Labels)
+	require.Equal(t, metricpb.MetricDescriptor_CUMULATIVE, series.MetricKind)
+
+	dist := series.Points[0].Value.GetDistributionValue()
+	require.NotNil(t, dist)
+	require.Equal(t, int64(5), dist.Count)
+	require.Equal(t, []int64{0, 1, 2, 1, 1}, dist.BucketCounts)
+	require.Equal(t, []float64{0, 10, 50, 100}, dist.BucketOptions.GetExplicitBuckets().Bounds)
+}
+
+func TestWriteCreateMetricDescriptors(t *testing.T) {
+	mockMetric.err = nil
+	mockMetric.reqs = nil
+	mockMetric.descriptors = nil
+	mockMetric.resps = append(mockMetric.resps[:0], &emptypb.Empty{})
+
+	c, err := monitoring.NewMetricClient(context.Background(), clientOpt)
+	require.NoError(t, err)
+
+	s := &Stackdriver{
+		Project:           "[PROJECT]",
+		Namespace:         "test",
+		MetricTypePrefix:  "custom.googleapis.com",
+		MetricNameFormat:  "path",
+		CreateDescriptors: true,
+		Log:               testutil.Logger{},
+		client:            c,
+	}
+	require.NoError(t, s.Connect())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"Host": "server01", "1st.core": "0"},
+		map[string]interface{}{"usage": 42.0},
+		time.Unix(1700000000, 0),
+		telegraf.Gauge,
+	)
+	require.NoError(t, s.Write([]telegraf.Metric{m}))
+
+	// The descriptor is only created once for the same labels
+	m = m.Copy()
+	m.SetTime(time.Unix(1700000010, 0))
+	require.NoError(t, s.Write([]telegraf.Metric{m}))
+	require.Len(t, mockMetric.descriptors, 1)
+
+	request := mockMetric.descriptors[0]
+	require.Equal(t, "projects/[PROJECT]", request.Name)
+	descriptor := request.MetricDescriptor
+	require.Equal(t, "custom.googleapis.com/test/cpu/usage", descriptor.Type)
+	require.Equal(t, metricpb.MetricDescriptor_GAUGE, descriptor.MetricKind)
+	require.Equal(t, metricpb.MetricDescriptor_DOUBLE, descriptor.ValueType)
+	keys := make([]string, 0, len(descriptor.Labels))
+	for _, l := range descriptor.Labels {
+		keys = append(keys, l.Key)
+	}
+	require.Equal(t, []string{"host", "key_1st_core"}, keys)
+
+	series := mockMetric.reqs[0].(*monitoringpb.CreateTimeSeriesRequest).TimeSeries[0]
+	require.Equal(t, map[string]string{"host": "server01", "key_1st_core": "0"}, series.Metric.Labels)
+
+	// New labels update the descriptor
+	m = m.Copy()
+	m.AddTag("region", "eu")
+	m.SetTime(time.Unix(1700000020, 0))
+	require.NoError(t, s.Write([]telegraf.Metric{m}))
+	require.Len(t, mockMetric.descriptors, 2)
+	require.Len(t, mockMetric.descriptors[1].MetricDescriptor.Labels, 3)
+}
+
+func TestWriteRequestLimit(t *testing.T) {
+	mockMetric.err = nil
+	mockMetric.reqs = nil
+	mockMetric.resps = append(mockMetric.resps[:0], &emptypb.Empty{})
+
+	c, err := monitoring.NewMetricClient(context.Background(), clientOpt)
+	require.NoError(t, err)
+
+	s := &Stackdriver{
+		Project:   "[PROJECT]",
+		Namespace: "test",
+		Log:       testutil.Logger{},
+		client:    c,
+	}
+	require.NoError(t, s.Connect())
+
+	metrics := make([]telegraf.Metric, 0, 450)
+	for i := range 450 {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"cpu": strconv.Itoa(i)},
+			map[string]interface{}{"value": int64(i)},
+			time.Unix(1700000000, 0),
+		))
+	}
+	require.NoError(t, s.Write(metrics))
+
+	require.Len(t, mockMetric.reqs, 3)
+	require.Len(t, mockMetric.reqs[0].(*monitoringpb.CreateTimeSeriesRequest).TimeSeries, 200)
+	require.Len(t, mockMetric.reqs[1].(*monitoringpb.CreateTimeSeriesRequest).TimeSeries, 200)
+	require.Len(t, mockMetric.reqs[2].(*monitoringpb.CreateTimeSeriesRequest).TimeSeries, 50)
+}
+
+func TestSanitizeLabelKey(t *testing.T) {
+	require.Equal(t, "host", sanitizeLabelKey("host"))
+	require.Equal(t, "host_name", sanitizeLabelKey("Host-Name"))
+	require.Equal(t, "key_1st", sanitizeLabelKey("1st"))
+	require.Equal(t, "key__private", sanitizeLabelKey("_private"))
+}
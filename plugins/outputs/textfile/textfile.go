@@ -0,0 +1,184 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package textfile
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	serializers_prometheus "github.com/influxdata/telegraf/plugins/serializers/prometheus"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const defaultExpirationInterval = config.Duration(60 * time.Second)
+
+type Textfile struct {
+	Path               string                             `toml:"path"`
+	ExpirationInterval config.Duration                    `toml:"expiration_interval"`
+	StringAsLabel      bool                               `toml:"string_as_label"`
+	RemoveOnClose      bool                               `toml:"remove_on_close"`
+	TypeMappings       serializers_prometheus.MetricTypes `toml:"metric_types"`
+	Log                telegraf.Logger                    `toml:"-"`
+
+	coll *serializers_prometheus.Collection
+	done chan struct{}
+	wg   sync.WaitGroup
+	sync.Mutex
+}
+
+func (*Textfile) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Textfile) Init() error {
+	if t.Path == "" {
+		return errors.New("'path' is required")
+	}
+	if filepath.Ext(t.Path) != ".prom" {
+		return fmt.Errorf("path %q must have a '.prom' extension", t.Path)
+	}
+	if t.ExpirationInterval < 0 {
+		return errors.New("'expiration_interval' must not be negative")
+	}
+
+	if err := t.TypeMappings.Init(); err != nil {
+		return err
+	}
+
+	t.coll = serializers_prometheus.NewCollection(serializers_prometheus.FormatConfig{
+		StringAsLabel: t.StringAsLabel,
+		TypeMappings:  t.TypeMappings,
+	})
+
+	return nil
+}
+
+func (t *Textfile) Connect() error {
+	if _, err := os.Stat(filepath.Dir(t.Path)); err != nil {
+		return fmt.Errorf("checking directory failed: %w", err)
+	}
+
+	// Remove expired metrics from the file even if no new metrics arrive
+	t.done = make(chan struct{})
+	if t.ExpirationInterval > 0 {
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			ticker := time.NewTicker(time.Duration(t.ExpirationInterval))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-t.done:
+					return
+				case <-ticker.C:
+					t.Lock()
+					err := t.update(time.Now())
+					t.Unlock()
+					if err != nil {
+						t.Log.Errorf("Removing expired metrics failed: %v", err)
+					}
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (t *Textfile) Close() error {
+	if t.done != nil {
+		close(t.done)
+		t.wg.Wait()
+		t.done = nil
+	}
+
+	if t.RemoveOnClose {
+		if err := os.Remove(t.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing file failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t *Textfile) Write(metrics []telegraf.Metric) error {
+	t.Lock()
+	defer t.Unlock()
+
+	now := time.Now()
+	for _, m := range metrics {
+		t.coll.Add(m, now)
+	}
+
+	return t.update(now)
+}
+
+// update removes the expired metrics and writes the remaining ones to the file
+func (t *Textfile) update(now time.Time) error {
+	if t.ExpirationInterval > 0 {
+		t.coll.Expire(now, time.Duration(t.ExpirationInterval))
+	}
+
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, mf := range t.coll.GetProto() {
+		if err := enc.Encode(mf); err != nil {
+			return fmt.Errorf("encoding metric family %q failed: %w", mf.GetName(), err)
+		}
+	}
+
+	return writeAtomic(t.Path, buf.Bytes())
+}
+
+// writeAtomic replaces the file by renaming a temporary file in the same
+// directory, so readers never see a partially written file
+func writeAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file failed: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing temporary file failed: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing temporary file failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing temporary file failed: %w", err)
+	}
+
+	// Temporary files are only readable by the owner, but the file is
+	// usually read by the node_exporter running as a different user.
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return fmt.Errorf("setting permissions failed: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("replacing file failed: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	outputs.Add("textfile", func() telegraf.Output {
+		return &Textfile{
+			ExpirationInterval: defaultExpirationInterval,
+			StringAsLabel:      true,
+		}
+	})
+}
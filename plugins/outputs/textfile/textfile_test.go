@@ -0,0 +1,126 @@
+package textfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "missing path",
+			expected: "'path' is required",
+		},
+		{
+			name:     "invalid extension",
+			path:     "/tmp/metrics.txt",
+			expected: "must have a '.prom' extension",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Textfile{Path: tt.path}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegraf.prom")
+	plugin := &Textfile{
+		Path:               path,
+		ExpirationInterval: config.Duration(time.Hour),
+		StringAsLabel:      true,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	input := []telegraf.Metric{
+		metric.New(
+			"disk",
+			map[string]string{"device": "sda", "host": "example.org"},
+			map[string]interface{}{"free": 1024, "used": 512},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"requests",
+			map[string]string{"host": "example.org"},
+			map[string]interface{}{"total": 42, "status": "ok"},
+			time.Unix(1700000000, 0),
+			telegraf.Counter,
+		),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	expected := `# HELP disk_free Telegraf collected metric
+# TYPE disk_free untyped
+disk_free{device="sda",host="example.org"} 1024
+# HELP disk_used Telegraf collected metric
+# TYPE disk_used untyped
+disk_used{device="sda",host="example.org"} 512
+# HELP requests_total Telegraf collected metric
+# TYPE requests_total counter
+requests_total{host="example.org",status="ok"} 42
+`
+	actual, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(actual))
+
+	// No temporary files should be left over
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// Windows does not support Unix permissions
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0644), info.Mode().Perm())
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegraf.prom")
+	plugin := &Textfile{
+		Path:               path,
+		ExpirationInterval: config.Duration(time.Minute),
+		RemoveOnClose:      true,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	actual, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(actual), "cpu_usage 42")
+
+	// Updating the file after the expiration interval removes the metric
+	require.NoError(t, plugin.update(time.Now().Add(2*time.Minute)))
+	actual, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Empty(t, actual)
+
+	// Closing the plugin removes the file
+	require.NoError(t, plugin.Close())
+	require.NoFileExists(t, path)
+}
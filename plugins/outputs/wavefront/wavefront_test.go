@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	wavefront "github.com/wavefronthq/wavefront-sdk-go/senders"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	serializers_wavefront "github.com/influxdata/telegraf/plugins/serializers/wavefront"
 	"github.com/influxdata/telegraf/testutil"
@@ -330,39 +332,89 @@ func TestBuildValueString(t *testing.T) {
 }
 
 func TestTagLimits(t *testing.T) {
-	w := defaultWavefront()
-	w.TruncateTags = true
-
-	// Should fail (all tags skipped)
-	template := make(map[string]string)
-	template[strings.Repeat("x", 255)] = "whatever"
-	_, tags := w.buildTags(template)
-	require.Empty(t, tags, "All tags should have been skipped")
-
-	// Should truncate value
-	template = make(map[string]string)
-	longKey := strings.Repeat("x", 253)
-	template[longKey] = "whatever"
-	_, tags = w.buildTags(template)
-	require.Contains(t, tags, longKey, "Should contain truncated long key")
-	require.Equal(t, "w", tags[longKey])
-
-	// Should not truncate
-	template = make(map[string]string)
-	longKey = strings.Repeat("x", 251)
-	template[longKey] = "Hi!"
-	_, tags = w.buildTags(template)
-	require.Contains(t, tags, longKey, "Should contain non truncated long key")
-	require.Equal(t, "Hi!", tags[longKey])
-
-	// Turn off truncating and make sure it leaves the tags intact
-	w.TruncateTags = false
-	template = make(map[string]string)
-	longKey = strings.Repeat("x", 255)
-	template[longKey] = longKey
-	_, tags = w.buildTags(template)
-	require.Contains(t, tags, longKey, "Should contain non truncated long key")
-	require.Equal(t, longKey, tags[longKey])
+	tests := []struct {
+		name           string
+		truncate       bool
+		tags           map[string]string
+		expectedSource string
+		expected       map[string]string
+	}{
+		{
+			name:     "skip long key",
+			truncate: true,
+			tags:     map[string]string{strings.Repeat("x", 255): "whatever"},
+			expected: map[string]string{},
+		},
+		{
+			name:     "key using up the limit",
+			truncate: true,
+			tags:     map[string]string{strings.Repeat("x", 254): "whatever"},
+			expected: map[string]string{strings.Repeat("x", 254): ""},
+		},
+		{
+			name:     "truncate value",
+			truncate: true,
+			tags:     map[string]string{strings.Repeat("x", 253): "whatever"},
+			expected: map[string]string{strings.Repeat("x", 253): "w"},
+		},
+		{
+			name:     "no truncation",
+			truncate: true,
+			tags:     map[string]string{strings.Repeat("x", 251): "Hi!"},
+			expected: map[string]string{strings.Repeat("x", 251): "Hi!"},
+		},
+		{
+			name:           "source is not a tag",
+			truncate:       true,
+			tags:           map[string]string{"host": strings.Repeat("h", 300), "tag1": "value1"},
+			expectedSource: strings.Repeat("h", 300),
+			expected:       map[string]string{"tag1": "value1"},
+		},
+		{
+			name:     "disabled",
+			tags:     map[string]string{strings.Repeat("x", 255): strings.Repeat("x", 255)},
+			expected: map[string]string{strings.Repeat("x", 255): strings.Repeat("x", 255)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := defaultWavefront()
+			w.TruncateTags = tt.truncate
+
+			// Feed the metric through the model to apply the framework's
+			// processing before the plugin sees the tags
+			model := models.NewRunningOutput(w, &models.OutputConfig{Name: "wavefront"}, 1000, 10000)
+			require.NoError(t, model.Init())
+			require.NoError(t, model.Connect())
+			sender := &mockSender{}
+			w.sender = sender
+
+			model.AddMetric(metric.New("test", tt.tags, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)))
+			require.NoError(t, model.Write())
+
+			require.Len(t, sender.points, 1)
+			require.Equal(t, tt.expectedSource, sender.points[0].Source)
+			require.Equal(t, tt.expected, sender.points[0].Tags)
+		})
+	}
+}
+
+// mockSender records the points sent to Wavefront
+type mockSender struct {
+	wavefront.Sender
+	points []serializers_wavefront.MetricPoint
+}
+
+func (s *mockSender) SendMetric(name string, value float64, ts int64, source string, tags map[string]string) error {
+	s.points = append(s.points, serializers_wavefront.MetricPoint{
+		Metric:    name,
+		Value:     value,
+		Timestamp: ts,
+		Source:    source,
+		Tags:      tags,
+	})
+	return nil
 }
 
 func TestParseConnectionUrlReturnsAnErrorForInvalidUrls(t *testing.T) {
@@ -0,0 +1,321 @@
+package accesslog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// Timestamp layout of the Common Log Format
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// Names of the fields of the Common and Combined Log Format
+var (
+	clfFields      = []string{"client_ip", "ident", "auth_user", "timestamp", "request", "status", "bytes"}
+	combinedFields = []string{"referrer", "user_agent"}
+)
+
+type Parser struct {
+	Format   string   `toml:"accesslog_format"`
+	TagKeys  []string `toml:"accesslog_tag_keys"`
+	Timezone string   `toml:"accesslog_timezone"`
+
+	DefaultTags map[string]string `toml:"-"`
+
+	metricName string
+	tagFilter  filter.Filter
+	location   *time.Location
+
+	// columns of the W3C extended log defined by the last "#Fields" directive
+	w3cFields []string
+}
+
+func (p *Parser) Init() error {
+	switch p.Format {
+	case "":
+		p.Format = "auto"
+	case "auto", "common", "combined", "w3c":
+	default:
+		return fmt.Errorf("invalid format %q", p.Format)
+	}
+
+	var err error
+	if p.tagFilter, err = filter.Compile(p.TagKeys); err != nil {
+		return fmt.Errorf("compiling tag pattern failed: %w", err)
+	}
+
+	p.location = time.UTC
+	if p.Timezone != "" {
+		if p.location, err = time.LoadLocation(p.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Parse converts the given log lines to metrics. W3C directives are used to
+// determine the columns of the subsequent lines and do not produce metrics.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics := make([]telegraf.Metric, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		m, err := p.parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) < 1 {
+		return nil, parsers.ErrEOF
+	}
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) parseLine(line string) (telegraf.Metric, error) {
+	if strings.HasPrefix(line, "#") {
+		if p.Format != "auto" && p.Format != "w3c" {
+			return nil, fmt.Errorf("unexpected directive in %s log: %q", p.Format, line)
+		}
+		p.parseDirective(line)
+		return nil, nil
+	}
+
+	switch p.Format {
+	case "w3c":
+		return p.parseW3C(line)
+	case "auto":
+		if p.w3cFields != nil {
+			return p.parseW3C(line)
+		}
+	}
+	return p.parseCLF(line)
+}
+
+// parseDirective handles the directives of W3C extended logs. Only the
+// "#Fields" directive is relevant, all others are ignored.
+func (p *Parser) parseDirective(line string) {
+	directive, value, _ := strings.Cut(strings.TrimPrefix(line, "#"), ":")
+	if !strings.EqualFold(strings.TrimSpace(directive), "fields") {
+		return
+	}
+
+	columns := strings.Fields(value)
+	p.w3cFields = make([]string, 0, len(columns))
+	for _, c := range columns {
+		p.w3cFields = append(p.w3cFields, w3cFieldName(c))
+	}
+}
+
+func (p *Parser) parseCLF(line string) (telegraf.Metric, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+
+	names := clfFields
+	switch {
+	case len(tokens) < len(clfFields):
+		return nil, fmt.Errorf("expected at least %d columns but got %d in line %q", len(clfFields), len(tokens), line)
+	case p.Format == "combined" && len(tokens) < len(clfFields)+len(combinedFields):
+		return nil, fmt.Errorf("expected at least %d columns but got %d in line %q", len(clfFields)+len(combinedFields), len(tokens), line)
+	case p.Format != "common" && len(tokens) >= len(clfFields)+len(combinedFields):
+		names = append(append(make([]string, 0, len(clfFields)+len(combinedFields)), clfFields...), combinedFields...)
+	}
+
+	ts, err := time.Parse(clfTimeLayout, tokens[3].value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing timestamp failed: %w", err)
+	}
+
+	m := metric.New(p.metricName, make(map[string]string), make(map[string]interface{}), ts)
+	for i, name := range names {
+		switch name {
+		case "timestamp":
+			continue
+		case "request":
+			// Split the request line into its parts if possible
+			parts := strings.Fields(tokens[i].value)
+			if len(parts) == 3 {
+				p.add(m, "method", parts[0], false)
+				p.add(m, "path", parts[1], false)
+				p.add(m, "http_version", strings.TrimPrefix(parts[2], "HTTP/"), false)
+				continue
+			}
+		}
+		p.add(m, name, tokens[i].value, tokens[i].quoted)
+	}
+	p.applyDefaultTags(m)
+
+	return m, nil
+}
+
+func (p *Parser) parseW3C(line string) (telegraf.Metric, error) {
+	if p.w3cFields == nil {
+		return nil, errors.New("missing '#Fields' directive before the first log line")
+	}
+
+	tokens, err := tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) != len(p.w3cFields) {
+		return nil, fmt.Errorf("expected %d columns but got %d in line %q", len(p.w3cFields), len(tokens), line)
+	}
+
+	var date, clock string
+	m := metric.New(p.metricName, make(map[string]string), make(map[string]interface{}), time.Time{})
+	for i, name := range p.w3cFields {
+		switch name {
+		case "date":
+			date = tokens[i].value
+		case "time":
+			clock = tokens[i].value
+		default:
+			p.add(m, name, tokens[i].value, tokens[i].quoted)
+		}
+	}
+
+	ts := time.Now()
+	if date != "" && clock != "" {
+		ts, err = time.ParseInLocation("2006-01-02 15:04:05", date+" "+clock, p.location)
+		if err != nil {
+			return nil, fmt.Errorf("parsing timestamp failed: %w", err)
+		}
+	}
+	m.SetTime(ts)
+	p.applyDefaultTags(m)
+
+	return m, nil
+}
+
+// add adds the value as tag or field with automatic typing. Empty values and
+// placeholders are skipped.
+func (p *Parser) add(m telegraf.Metric, name, value string, quoted bool) {
+	if value == "" || value == "-" {
+		return
+	}
+	if p.tagFilter != nil && p.tagFilter.Match(name) {
+		m.AddTag(name, value)
+		return
+	}
+
+	// Quoted values are always strings
+	if !quoted && !choice.Contains(name, []string{"ident", "auth_user", "http_version"}) {
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			m.AddField(name, v)
+			return
+		}
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			m.AddField(name, v)
+			return
+		}
+	}
+	m.AddField(name, value)
+}
+
+func (p *Parser) applyDefaultTags(m telegraf.Metric) {
+	for k, v := range p.DefaultTags {
+		if !m.HasTag(k) {
+			m.AddTag(k, v)
+		}
+	}
+}
+
+// w3cFieldName converts the W3C field identifiers like "cs-method" or
+// "cs(User-Agent)" to field names like "cs_method" or "cs_user_agent"
+func w3cFieldName(identifier string) string {
+	name := strings.ToLower(identifier)
+	name = strings.NewReplacer("-", "_", "(", "_", ")", "").Replace(name)
+	return strings.Trim(name, "_")
+}
+
+type token struct {
+	value  string
+	quoted bool
+}
+
+// tokenize splits the line at spaces while keeping quoted strings and
+// bracketed timestamps together
+func tokenize(line string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ', '\t':
+			i++
+		case '"':
+			var value strings.Builder
+			j := i + 1
+			for ; j < len(line) && line[j] != '"'; j++ {
+				// Unescape quotes and backslashes but keep other escape
+				// sequences like "\x16" used for binary data
+				if line[j] == '\\' && j+1 < len(line) && (line[j+1] == '"' || line[j+1] == '\\') {
+					j++
+				}
+				value.WriteByte(line[j])
+			}
+			if j >= len(line) {
+				return nil, fmt.Errorf("unterminated quote at position %d", i)
+			}
+			tokens = append(tokens, token{value: value.String(), quoted: true})
+			i = j + 1
+		case '[':
+			j := strings.IndexByte(line[i:], ']')
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated bracket at position %d", i)
+			}
+			tokens = append(tokens, token{value: line[i+1 : i+j]})
+			i += j + 1
+		default:
+			j := strings.IndexAny(line[i:], " \t")
+			if j < 0 {
+				j = len(line) - i
+			}
+			tokens = append(tokens, token{value: line[i : i+j]})
+			i += j
+		}
+	}
+	return tokens, nil
+}
+
+func init() {
+	parsers.Add("accesslog",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{metricName: defaultMetricName}
+		},
+	)
+}
@@ -0,0 +1,219 @@
+package accesslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParseCLF(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		tagKeys  []string
+		input    string
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "common",
+			format: "common",
+			input:  `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`,
+			expected: []telegraf.Metric{
+				metric.New(
+					"access",
+					map[string]string{},
+					map[string]interface{}{
+						"client_ip":    "127.0.0.1",
+						"auth_user":    "frank",
+						"method":       "GET",
+						"path":         "/apache_pb.gif",
+						"http_version": "1.0",
+						"status":       int64(200),
+						"bytes":        int64(2326),
+					},
+					time.Date(2000, time.October, 10, 20, 55, 36, 0, time.UTC),
+				),
+			},
+		},
+		{
+			name:    "combined",
+			format:  "combined",
+			tagKeys: []string{"method", "status"},
+			input: `10.0.0.2 - - [10/Oct/2000:13:55:36 +0000] "POST /api?x=\"1\" HTTP/1.1" 404 - ` +
+				`"http://example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+			expected: []telegraf.Metric{
+				metric.New(
+					"access",
+					map[string]string{
+						"method": "POST",
+						"status": "404",
+					},
+					map[string]interface{}{
+						"client_ip":    "10.0.0.2",
+						"path":         `/api?x="1"`,
+						"http_version": "1.1",
+						"referrer":     "http://example.com/start.html",
+						"user_agent":   "Mozilla/4.08 [en] (Win98; I ;Nav)",
+					},
+					time.Date(2000, time.October, 10, 13, 55, 36, 0, time.UTC),
+				),
+			},
+		},
+		{
+			name:  "auto with combined and malformed request",
+			input: `::1 - - [10/Oct/2000:13:55:36 +0000] "\x16\x03\x01" 400 157 "-" "curl/8.0"`,
+			expected: []telegraf.Metric{
+				metric.New(
+					"access",
+					map[string]string{},
+					map[string]interface{}{
+						"client_ip":  "::1",
+						"request":    `\x16\x03\x01`,
+						"status":     int64(400),
+						"bytes":      int64(157),
+						"user_agent": "curl/8.0",
+					},
+					time.Date(2000, time.October, 10, 13, 55, 36, 0, time.UTC),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{
+				Format:     tt.format,
+				TagKeys:    tt.tagKeys,
+				metricName: "access",
+			}
+			require.NoError(t, parser.Init())
+
+			actual, err := parser.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseW3C(t *testing.T) {
+	parser := &Parser{
+		TagKeys:    []string{"cs_method"},
+		metricName: "iis",
+	}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"source": "web01"})
+
+	header := `#Software: Microsoft Internet Information Services 10.0
+#Version: 1.0
+#Date: 2024-01-01 00:00:00
+#Fields: date time s-ip cs-method cs-uri-stem cs-uri-query sc-status time-taken cs(User-Agent)
+`
+	actual, err := parser.Parse([]byte(header))
+	require.NoError(t, err)
+	require.Empty(t, actual)
+
+	// Lines are usually parsed one by one when tailing the file
+	actual, err = parser.Parse([]byte("2024-01-01 12:30:45 10.0.0.1 GET /index.html - 200 15 Mozilla/5.0+(Windows+NT+10.0)\n"))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"iis",
+			map[string]string{
+				"cs_method": "GET",
+				"source":    "web01",
+			},
+			map[string]interface{}{
+				"s_ip":          "10.0.0.1",
+				"cs_uri_stem":   "/index.html",
+				"sc_status":     int64(200),
+				"time_taken":    int64(15),
+				"cs_user_agent": "Mozilla/5.0+(Windows+NT+10.0)",
+			},
+			time.Date(2024, time.January, 1, 12, 30, 45, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// A new fields directive changes the columns
+	actual, err = parser.Parse([]byte("#Fields: date time c-ip sc-bytes\n2024-01-01 12:31:00 192.168.1.1 1.5\n"))
+	require.NoError(t, err)
+	expected = []telegraf.Metric{
+		metric.New(
+			"iis",
+			map[string]string{"source": "web01"},
+			map[string]interface{}{
+				"c_ip":     "192.168.1.1",
+				"sc_bytes": 1.5,
+			},
+			time.Date(2024, time.January, 1, 12, 31, 0, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		expected string
+	}{
+		{
+			name:     "w3c without fields",
+			format:   "w3c",
+			input:    "2024-01-01 12:30:45 10.0.0.1 GET",
+			expected: "missing '#Fields' directive",
+		},
+		{
+			name:     "w3c column mismatch",
+			format:   "w3c",
+			input:    "#Fields: date time c-ip\n2024-01-01 12:30:45",
+			expected: "expected 3 columns but got 2",
+		},
+		{
+			name:     "directive in common log",
+			format:   "common",
+			input:    "#Fields: date time",
+			expected: "unexpected directive",
+		},
+		{
+			name:     "combined too short",
+			format:   "combined",
+			input:    `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.0" 200 2326`,
+			expected: "expected at least 9 columns but got 7",
+		},
+		{
+			name:     "invalid timestamp",
+			input:    `127.0.0.1 - - [yesterday] "GET / HTTP/1.0" 200 2326`,
+			expected: "parsing timestamp failed",
+		},
+		{
+			name:     "unterminated quote",
+			input:    `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.0 200 2326`,
+			expected: "unterminated quote",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{Format: tt.format, metricName: "access"}
+			require.NoError(t, parser.Init())
+			_, err := parser.Parse([]byte(tt.input))
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	parser := &Parser{Format: "json"}
+	require.ErrorContains(t, parser.Init(), `invalid format "json"`)
+
+	parser = &Parser{Timezone: "Not/A_Zone"}
+	require.ErrorContains(t, parser.Init(), "invalid timezone")
+}
@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.accesslog
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/accesslog" // register plugin
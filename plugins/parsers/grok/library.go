@@ -0,0 +1,75 @@
+package grok
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// patternLibrary caches the pattern files shared by all parser instances so
+// each file is only read and parsed once per modification
+var patternLibrary = struct {
+	sync.Mutex
+	files map[string]*patternFile
+}{files: make(map[string]*patternFile)}
+
+// patternFile contains the patterns defined in a custom pattern file and the
+// file information used to detect modifications
+type patternFile struct {
+	modTime  time.Time
+	size     int64
+	patterns map[string]string
+}
+
+// loadPatternFile returns the patterns of the given file, reading the file
+// only if it was modified since the last call
+func loadPatternFile(filename string) (*patternFile, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	patternLibrary.Lock()
+	defer patternLibrary.Unlock()
+
+	if f, found := patternLibrary.files[filename]; found && f.size == info.Size() && f.modTime.Equal(info.ModTime()) {
+		return f, nil
+	}
+
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &patternFile{
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+		patterns: make(map[string]string),
+	}
+	if err := parsePatterns(bufio.NewScanner(bytes.NewReader(buf)), f.patterns); err != nil {
+		return nil, fmt.Errorf("parsing pattern file %q failed: %w", filename, err)
+	}
+	patternLibrary.files[filename] = f
+
+	return f, nil
+}
+
+// parsePatterns adds the patterns defined one per line as "NAME pattern" to
+// the given map, skipping empty lines and comments
+func parsePatterns(scanner *bufio.Scanner, patterns map[string]string) error {
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) > 0 && line[0] != '#' {
+			names := strings.SplitN(line, " ", 2)
+			if len(names) != 2 {
+				return fmt.Errorf("invalid pattern definition %q", line)
+			}
+			patterns[names[0]] = names[1]
+		}
+	}
+	return scanner.Err()
+}
@@ -5,18 +5,20 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/alecthomas/units"
 	"github.com/vjeantet/grok"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 var timeLayouts = map[string]string{
@@ -47,6 +49,10 @@ const (
 	Float            = "float"
 	String           = "string"
 	Duration         = "duration"
+	Bool             = "bool"
+	Uint             = "uint"
+	Hex              = "hex"
+	Size             = "size"
 	Drop             = "drop"
 	Epoch            = "EPOCH"
 	EpochMilli       = "EPOCH_MILLI"
@@ -78,6 +84,9 @@ type Parser struct {
 	CustomPatterns     string            `toml:"grok_custom_patterns"`
 	CustomPatternFiles []string          `toml:"grok_custom_pattern_files"`
 	Multiline          bool              `toml:"grok_multiline"`
+	CaptureTypes       map[string]string `toml:"grok_capture_types"`
+	ReloadInterval     config.Duration   `toml:"grok_reload_interval"`
+	PatternStats       bool              `toml:"grok_pattern_stats"`
 	Measurement        string            `toml:"-"`
 	DefaultTags        map[string]string `toml:"-"`
 	Log                telegraf.Logger   `toml:"-"`
@@ -126,29 +135,73 @@ type Parser struct {
 	// layouts.
 	foundTsLayouts []string
 
+	// patternFiles are the versions of the custom pattern files the patterns
+	// were compiled from, used to detect modifications of the files
+	patternFiles    map[string]*patternFile
+	lastReloadCheck time.Time
+	// patternStats contains the internal statistics per named pattern
+	patternStats map[string]*patternStats
+
 	timeFunc func() time.Time
 	g        *grok.Grok
 	tsModder *tsModder
 }
 
+// patternStats are the internal statistics of a pattern
+type patternStats struct {
+	matches selfstat.Stat
+	misses  selfstat.Stat
+	timeNS  selfstat.Stat
+}
+
+func newPatternStats(pattern string) *patternStats {
+	tags := map[string]string{"pattern": pattern}
+	return &patternStats{
+		matches: selfstat.Register("grok", "matches", tags),
+		misses:  selfstat.Register("grok", "misses", tags),
+		timeNS:  selfstat.Register("grok", "match_time_ns", tags),
+	}
+}
+
 // Compile is a bound method to Parser which will process the options for our parser
 func (p *Parser) Compile() error {
+	p.tsModder = &tsModder{}
+
+	if p.UniqueTimestamp == "" {
+		p.UniqueTimestamp = "auto"
+	}
+
+	var err error
+	p.loc, err = time.LoadLocation(p.Timezone)
+	if err != nil {
+		p.Log.Warnf("Improper timezone supplied (%s), setting loc to UTC", p.Timezone)
+		p.loc = time.UTC
+	}
+
+	if p.timeFunc == nil {
+		p.timeFunc = time.Now
+	}
+
+	return p.compilePatterns()
+}
+
+// compilePatterns builds the grok patterns from the configured patterns,
+// the custom patterns and the custom pattern files
+func (p *Parser) compilePatterns() error {
 	p.typeMap = make(map[string]map[string]string)
 	p.tsMap = make(map[string]map[string]string)
 	p.patternsMap = make(map[string]string)
-	p.tsModder = &tsModder{}
+	p.patternFiles = make(map[string]*patternFile, len(p.CustomPatternFiles))
+	p.patternStats = make(map[string]*patternStats)
 	var err error
 	p.g, err = grok.NewWithConfig(&grok.Config{NamedCapturesOnly: true})
 	if err != nil {
 		return err
 	}
 
-	if p.UniqueTimestamp == "" {
-		p.UniqueTimestamp = "auto"
-	}
-
 	// Give Patterns fake names so that they can be treated as named
 	// "custom patterns"
+	customPatterns := p.CustomPatterns
 	p.NamedPatterns = make([]string, 0, len(p.Patterns))
 	for i, pattern := range p.Patterns {
 		pattern = strings.TrimSpace(pattern)
@@ -156,8 +209,11 @@ func (p *Parser) Compile() error {
 			continue
 		}
 		name := fmt.Sprintf("GROK_INTERNAL_PATTERN_%d", i)
-		p.CustomPatterns += "\n" + name + " " + pattern + "\n"
+		customPatterns += "\n" + name + " " + pattern + "\n"
 		p.NamedPatterns = append(p.NamedPatterns, "%{"+name+"}")
+		if p.PatternStats {
+			p.patternStats["%{"+name+"}"] = newPatternStats(pattern)
+		}
 	}
 
 	if len(p.NamedPatterns) == 0 {
@@ -166,45 +222,100 @@ func (p *Parser) Compile() error {
 
 	// Combine user-supplied CustomPatterns with DEFAULT_PATTERNS and parse
 	// them together as the same type of pattern.
-	p.CustomPatterns = DefaultPatterns + p.CustomPatterns
-	if len(p.CustomPatterns) != 0 {
-		scanner := bufio.NewScanner(strings.NewReader(p.CustomPatterns))
-		p.addCustomPatterns(scanner)
+	customPatterns = DefaultPatterns + customPatterns
+	if err := parsePatterns(bufio.NewScanner(strings.NewReader(customPatterns)), p.patternsMap); err != nil {
+		return err
 	}
 
-	// Parse any custom pattern files supplied.
+	// Add the patterns of the custom pattern files supplied.
 	for _, filename := range p.CustomPatternFiles {
-		file, fileErr := os.Open(filename)
-		if fileErr != nil {
-			return fileErr
+		f, err := loadPatternFile(filename)
+		if err != nil {
+			return err
 		}
+		p.patternFiles[filename] = f
+		for name, pattern := range f.patterns {
+			p.patternsMap[name] = pattern
+		}
+	}
+
+	return p.compileCustomPatterns()
+}
 
-		scanner := bufio.NewScanner(bufio.NewReader(file))
-		p.addCustomPatterns(scanner)
+// reloadPatterns recompiles the patterns if any of the custom pattern files
+// was modified since the patterns were compiled. The previous patterns are
+// kept if the modified files cannot be compiled.
+func (p *Parser) reloadPatterns() {
+	if p.ReloadInterval <= 0 || len(p.CustomPatternFiles) == 0 {
+		return
 	}
 
-	p.loc, err = time.LoadLocation(p.Timezone)
-	if err != nil {
-		p.Log.Warnf("Improper timezone supplied (%s), setting loc to UTC", p.Timezone)
-		p.loc = time.UTC
+	now := time.Now()
+	if now.Sub(p.lastReloadCheck) < time.Duration(p.ReloadInterval) {
+		return
 	}
+	p.lastReloadCheck = now
 
-	if p.timeFunc == nil {
-		p.timeFunc = time.Now
+	var modified bool
+	for _, filename := range p.CustomPatternFiles {
+		f, err := loadPatternFile(filename)
+		if err != nil {
+			p.Log.Errorf("Checking pattern file %q failed: %v", filename, err)
+			return
+		}
+		if f != p.patternFiles[filename] {
+			modified = true
+		}
+	}
+	if !modified {
+		return
 	}
 
-	return p.compileCustomPatterns()
+	next := *p
+	if err := next.compilePatterns(); err != nil {
+		// Remember the files to only report the error once per modification
+		p.patternFiles = next.patternFiles
+		p.Log.Errorf("Reloading patterns failed, keeping previous patterns: %v", err)
+		return
+	}
+	p.typeMap = next.typeMap
+	p.tsMap = next.tsMap
+	p.patternsMap = next.patternsMap
+	p.patternFiles = next.patternFiles
+	p.patternStats = next.patternStats
+	p.NamedPatterns = next.NamedPatterns
+	p.g = next.g
+	p.Log.Info("Reloaded patterns from modified pattern files")
 }
 
 // ParseLine is the primary function to process individual lines, returning the metrics
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	p.reloadPatterns()
+	return p.parseLine(line)
+}
+
+func (p *Parser) parseLine(line string) (telegraf.Metric, error) {
 	var err error
 	// values are the parsed fields from the log line
 	var values map[string]string
 	// the matching pattern string
 	var patternName string
 	for _, pattern := range p.NamedPatterns {
-		if values, err = p.g.Parse(pattern, line); err != nil {
+		stats, hasStats := p.patternStats[pattern]
+		var start time.Time
+		if hasStats {
+			start = time.Now()
+		}
+		values, err = p.g.Parse(pattern, line)
+		if hasStats {
+			stats.timeNS.Incr(time.Since(start).Nanoseconds())
+			if err == nil && len(values) != 0 {
+				stats.matches.Incr(1)
+			} else {
+				stats.misses.Incr(1)
+			}
+		}
+		if err != nil {
 			return nil, err
 		}
 		if len(values) != 0 {
@@ -246,7 +357,11 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 				}
 			}
 		}
-		// if we didn't find a type OR timestamp modifier, assume string
+		// if we didn't find a type OR timestamp modifier, use the configured
+		// type of the capture and otherwise assume string
+		if t == "" {
+			t = p.CaptureTypes[k]
+		}
 		if t == "" {
 			t = String
 		}
@@ -275,6 +390,34 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 			} else {
 				fields[k] = int64(d)
 			}
+		case Bool:
+			bv, err := strconv.ParseBool(v)
+			if err != nil {
+				p.Log.Errorf("Error parsing %s to bool: %s", v, err)
+			} else {
+				fields[k] = bv
+			}
+		case Uint:
+			uv, err := strconv.ParseUint(v, 0, 64)
+			if err != nil {
+				p.Log.Errorf("Error parsing %s to uint: %s", v, err)
+			} else {
+				fields[k] = uv
+			}
+		case Hex:
+			hv, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimPrefix(v, "0x"), "0X"), 16, 64)
+			if err != nil {
+				p.Log.Errorf("Error parsing %s to hex: %s", v, err)
+			} else {
+				fields[k] = hv
+			}
+		case Size:
+			sv, err := units.ParseStrictBytes(v)
+			if err != nil {
+				p.Log.Errorf("Error parsing %s to size: %s", v, err)
+			} else {
+				fields[k] = sv
+			}
 		case Tag:
 			tags[k] = v
 		case String:
@@ -384,8 +527,10 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	metrics := make([]telegraf.Metric, 0)
 
+	p.reloadPatterns()
+
 	if p.Multiline {
-		m, err := p.ParseLine(string(buf))
+		m, err := p.parseLine(string(buf))
 		if err != nil {
 			return nil, err
 		}
@@ -398,7 +543,7 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	scanner := bufio.NewScanner(bytes.NewReader(buf))
 	for scanner.Scan() {
 		line := scanner.Text()
-		m, err := p.ParseLine(line)
+		m, err := p.parseLine(line)
 		if err != nil {
 			return nil, err
 		}
@@ -416,16 +561,6 @@ func (p *Parser) SetDefaultTags(tags map[string]string) {
 	p.DefaultTags = tags
 }
 
-func (p *Parser) addCustomPatterns(scanner *bufio.Scanner) {
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if len(line) > 0 && line[0] != '#' {
-			names := strings.SplitN(line, " ", 2)
-			p.patternsMap[names[0]] = names[1]
-		}
-	}
-}
-
 func (p *Parser) compileCustomPatterns() error {
 	var err error
 	// check if the pattern contains a subpattern that is already defined
@@ -589,6 +724,18 @@ func (p *Parser) Init() error {
 		p.Timezone = "UTC"
 	}
 
+	if p.ReloadInterval < 0 {
+		return errors.New("'grok_reload_interval' must not be negative")
+	}
+
+	for name, t := range p.CaptureTypes {
+		switch t {
+		case Measurement, Int, Float, String, Duration, Bool, Uint, Hex, Size, Tag, Drop:
+		default:
+			return fmt.Errorf("invalid type %q for capture %q in 'grok_capture_types'", t, name)
+		}
+	}
+
 	return p.Compile()
 }
 
@@ -3,12 +3,14 @@ package grok
 import (
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -1241,3 +1243,130 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestCaptureTypes(t *testing.T) {
+	p := &Parser{
+		Patterns: []string{"%{NUMBER:count} %{WORD:enabled} %{BASE16NUM:address} %{NOTSPACE:memory} %{NUMBER:value:int}"},
+		CaptureTypes: map[string]string{
+			"count":   "uint",
+			"enabled": "bool",
+			"address": "hex",
+			"memory":  "size",
+			"value":   "string",
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine(`18446744073709551615 true 0x1F 1.5KiB 42`)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t,
+		map[string]interface{}{
+			"count":   uint64(18446744073709551615),
+			"enabled": true,
+			"address": int64(31),
+			"memory":  int64(1536),
+			"value":   int64(42),
+		},
+		m.Fields(),
+	)
+}
+
+func TestCaptureTypesInvalid(t *testing.T) {
+	p := &Parser{
+		Patterns:     []string{"%{NUMBER:count}"},
+		CaptureTypes: map[string]string{"count": "ts-epoch"},
+		Log:          testutil.Logger{},
+	}
+	require.ErrorContains(t, p.Init(), `invalid type "ts-epoch" for capture "count"`)
+}
+
+func TestReloadPatternFiles(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "patterns")
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_VALUE %{NUMBER:value:int}\n"), 0600))
+
+	p := &Parser{
+		Measurement:        "test",
+		Patterns:           []string{"%{TEST_VALUE}"},
+		CustomPatternFiles: []string{filename},
+		ReloadInterval:     config.Duration(time.Nanosecond),
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	metrics, err := p.Parse([]byte("42"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{"value": int64(42)}, metrics[0].Fields())
+
+	// Modify the pattern file and make sure the modification time changes
+	modified := time.Now().Add(time.Minute)
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_VALUE %{NUMBER:value:float}\n"), 0600))
+	require.NoError(t, os.Chtimes(filename, modified, modified))
+
+	metrics, err = p.Parse([]byte("42"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{"value": float64(42)}, metrics[0].Fields())
+
+	// Keep the previous patterns if the modified file is invalid
+	modified = modified.Add(time.Minute)
+	invalid := "TEST_VALUE %{NUMBER:a:ts-epoch} %{NUMBER:b:ts-epoch}\n"
+	require.NoError(t, os.WriteFile(filename, []byte(invalid), 0600))
+	require.NoError(t, os.Chtimes(filename, modified, modified))
+
+	metrics, err = p.Parse([]byte("42"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{"value": float64(42)}, metrics[0].Fields())
+}
+
+func TestReloadDisabled(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "patterns")
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_VALUE %{NUMBER:value:int}\n"), 0600))
+
+	p := &Parser{
+		Measurement:        "test",
+		Patterns:           []string{"%{TEST_VALUE}"},
+		CustomPatternFiles: []string{filename},
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	modified := time.Now().Add(time.Minute)
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_VALUE %{NUMBER:value:float}\n"), 0600))
+	require.NoError(t, os.Chtimes(filename, modified, modified))
+
+	m, err := p.ParseLine("42")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": int64(42)}, m.Fields())
+}
+
+func TestPatternStats(t *testing.T) {
+	p := &Parser{
+		Measurement:  "test",
+		Patterns:     []string{"%{NUMBER:value:int} stats-first", "%{WORD:name} stats-second"},
+		PatternStats: true,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	first := p.patternStats["%{GROK_INTERNAL_PATTERN_0}"]
+	second := p.patternStats["%{GROK_INTERNAL_PATTERN_1}"]
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+
+	// The statistics are global, so only check the difference
+	firstMatches, firstMisses := first.matches.Get(), first.misses.Get()
+	secondMatches, secondMisses := second.matches.Get(), second.misses.Get()
+
+	_, err := p.Parse([]byte("42 stats-first\nfoo stats-second\nbar stats-second\n- none"))
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1), first.matches.Get()-firstMatches)
+	require.Equal(t, int64(3), first.misses.Get()-firstMisses)
+	require.Equal(t, int64(2), second.matches.Get()-secondMatches)
+	require.Equal(t, int64(1), second.misses.Get()-secondMisses)
+	require.Positive(t, first.timeNS.Get())
+}
@@ -7,12 +7,6 @@ import (
 	"unsafe"
 )
 
-const (
-	escapes            = " ,="
-	nameEscapes        = " ,"
-	stringFieldEscapes = `\"`
-)
-
 var (
 	unescaper = strings.NewReplacer(
 		`\,`, `,`,
@@ -32,27 +26,43 @@ var (
 	)
 )
 
+// All escape sequences start with a backslash, so checking for the backslash
+// is sufficient to take the fast path. bytes.IndexByte is vectorized by the
+// Go runtime on most architectures.
+
 func unescape(b []byte) string {
-	if bytes.ContainsAny(b, escapes) {
-		return unescaper.Replace(unsafeBytesToString(b))
+	if bytes.IndexByte(b, '\\') >= 0 {
+		return replace(unescaper, b)
 	}
 	return string(b)
 }
 
 func nameUnescape(b []byte) string {
-	if bytes.ContainsAny(b, nameEscapes) {
-		return nameUnescaper.Replace(unsafeBytesToString(b))
+	if bytes.IndexByte(b, '\\') >= 0 {
+		return replace(nameUnescaper, b)
 	}
 	return string(b)
 }
 
 func stringFieldUnescape(b []byte) string {
-	if bytes.ContainsAny(b, stringFieldEscapes) {
-		return stringFieldUnescaper.Replace(unsafeBytesToString(b))
+	if bytes.IndexByte(b, '\\') >= 0 {
+		return replace(stringFieldUnescaper, b)
 	}
 	return string(b)
 }
 
+// replace applies the replacer to the bytes without referencing the
+// underlying buffer in the result as the buffer might be reused
+func replace(r *strings.Replacer, b []byte) string {
+	s := unsafeBytesToString(b)
+	result := r.Replace(s)
+	if len(result) == len(s) {
+		// No replacement took place, so the result is the input string
+		return string(b)
+	}
+	return result
+}
+
 // parseIntBytes is a zero-alloc wrapper around strconv.ParseInt.
 func parseIntBytes(b []byte, base, bitSize int) (i int64, err error) {
 	s := unsafeBytesToString(b)
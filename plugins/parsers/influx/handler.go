@@ -10,17 +10,56 @@ import (
 	"github.com/influxdata/telegraf/metric"
 )
 
+// maxCachedStrings is the maximum number of strings cached per kind before
+// the cache is cleared to limit the memory used for high-cardinality data
+const maxCachedStrings = 4096
+
+// stringCache reuses the strings of repeated byte sequences, e.g. the
+// measurement names and keys of consecutive lines, avoiding to allocate a new
+// string for every occurrence
+type stringCache struct {
+	strings  map[string]string
+	unescape func([]byte) string
+}
+
+func newStringCache(unescape func([]byte) string) *stringCache {
+	return &stringCache{
+		strings:  make(map[string]string),
+		unescape: unescape,
+	}
+}
+
+func (c *stringCache) get(b []byte) string {
+	// The compiler optimizes the conversion in the map lookup to not allocate
+	if s, found := c.strings[string(b)]; found {
+		return s
+	}
+	if len(c.strings) >= maxCachedStrings {
+		clear(c.strings)
+	}
+	s := c.unescape(b)
+	c.strings[string(b)] = s
+	return s
+}
+
 // MetricHandler implements the Handler interface and produces telegraf.Metric.
 type MetricHandler struct {
 	timePrecision time.Duration
 	timeFunc      TimeFunc
 	metric        telegraf.Metric
+
+	names     *stringCache
+	keys      *stringCache
+	tagValues *stringCache
 }
 
 func NewMetricHandler() *MetricHandler {
 	return &MetricHandler{
 		timePrecision: time.Nanosecond,
 		timeFunc:      time.Now,
+		names:         newStringCache(nameUnescape),
+		keys:          newStringCache(unescape),
+		tagValues:     newStringCache(unescape),
 	}
 }
 
@@ -46,20 +85,19 @@ func (h *MetricHandler) Metric() telegraf.Metric {
 }
 
 func (h *MetricHandler) SetMeasurement(name []byte) error {
-	h.metric = metric.New(nameUnescape(name),
-		nil, nil, time.Time{})
+	h.metric = metric.New(h.names.get(name), nil, nil, time.Time{})
 	return nil
 }
 
 func (h *MetricHandler) AddTag(key, value []byte) error {
-	tk := unescape(key)
-	tv := unescape(value)
+	tk := h.keys.get(key)
+	tv := h.tagValues.get(value)
 	h.metric.AddTag(tk, tv)
 	return nil
 }
 
 func (h *MetricHandler) AddInt(key, value []byte) error {
-	fk := unescape(key)
+	fk := h.keys.get(key)
 	fv, err := parseIntBytes(bytes.TrimSuffix(value, []byte("i")), 10, 64)
 	if err != nil {
 		var numErr *strconv.NumError
@@ -73,7 +111,7 @@ func (h *MetricHandler) AddInt(key, value []byte) error {
 }
 
 func (h *MetricHandler) AddUint(key, value []byte) error {
-	fk := unescape(key)
+	fk := h.keys.get(key)
 	fv, err := parseUintBytes(bytes.TrimSuffix(value, []byte("u")), 10, 64)
 	if err != nil {
 		var numErr *strconv.NumError
@@ -87,7 +125,7 @@ func (h *MetricHandler) AddUint(key, value []byte) error {
 }
 
 func (h *MetricHandler) AddFloat(key, value []byte) error {
-	fk := unescape(key)
+	fk := h.keys.get(key)
 	fv, err := parseFloatBytes(value, 64)
 	if err != nil {
 		var numErr *strconv.NumError
@@ -101,14 +139,14 @@ func (h *MetricHandler) AddFloat(key, value []byte) error {
 }
 
 func (h *MetricHandler) AddString(key, value []byte) error {
-	fk := unescape(key)
+	fk := h.keys.get(key)
 	fv := stringFieldUnescape(value)
 	h.metric.AddField(fk, fv)
 	return nil
 }
 
 func (h *MetricHandler) AddBool(key, value []byte) error {
-	fk := unescape(key)
+	fk := h.keys.get(key)
 	fv, err := parseBoolBytes(value)
 	if err != nil {
 		return errors.New("unparsable bool")
@@ -1,6 +1,7 @@
 package influx
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -82,7 +83,9 @@ func (p *Parser) SetTimePrecision(u time.Duration) {
 func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 	p.Lock()
 	defer p.Unlock()
-	metrics := make([]telegraf.Metric, 0)
+	// Each line usually contains one metric, counting the newlines is cheap
+	// compared to growing the slice
+	metrics := make([]telegraf.Metric, 0, bytes.Count(input, []byte("\n"))+1)
 	p.machine.SetData(input)
 
 	for {
@@ -3,6 +3,7 @@ package influx
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"strconv"
 	"strings"
@@ -1038,3 +1039,54 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestParserBufferReuse(t *testing.T) {
+	plugin := &Parser{}
+	require.NoError(t, plugin.Init())
+
+	input := []byte(`cpu\ load,host=server\ 1,path=C:\tmp value="C:\\temp",desc="said \"hi\"" 1653643421`)
+	actual, err := plugin.Parse(input)
+	require.NoError(t, err)
+
+	// Overwrite the buffer to make sure the metric does not reference it
+	for i := range input {
+		input[i] = 'x'
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu load",
+			map[string]string{"host": "server 1", "path": `C:\tmp`},
+			map[string]interface{}{"value": `C:\temp`, "desc": `said "hi"`},
+			time.Unix(0, 1653643421),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestStringCacheLimit(t *testing.T) {
+	cache := newStringCache(unescape)
+	for i := 0; i < maxCachedStrings+10; i++ {
+		require.Equal(t, strconv.Itoa(i), cache.get([]byte(strconv.Itoa(i))))
+	}
+	require.LessOrEqual(t, len(cache.strings), maxCachedStrings)
+	require.Equal(t, "a b", cache.get([]byte(`a\ b`)))
+}
+
+func BenchmarkParsingBatch(b *testing.B) {
+	plugin := &Parser{}
+	require.NoError(b, plugin.Init())
+
+	// Simulate a batch as received by a relay
+	var buf bytes.Buffer
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&buf, "cpu,host=server%02d,cpu=cpu%d usage_idle=%d.5,usage_user=%di,state=\"ok\" %d\n", i%10, i%4, i, i, 1653643421+i)
+	}
+	input := buf.Bytes()
+	b.SetBytes(int64(len(input)))
+
+	for n := 0; n < b.N; n++ {
+		//nolint:errcheck // Benchmarking so skip the error check to avoid the unnecessary operations
+		plugin.Parse(input)
+	}
+}
@@ -0,0 +1,133 @@
+package json_v2
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Computed defines a field or tag computed from the parsed metric using a
+// CEL expression
+type Computed struct {
+	Name       string `toml:"name"`       // REQUIRED
+	Expression string `toml:"expression"` // REQUIRED
+	Optional   bool   `toml:"optional"`   // Will suppress errors if the expression cannot be evaluated
+}
+
+type computation struct {
+	Computed
+	tag     bool
+	program cel.Program
+}
+
+func compileComputations(fields, tags []Computed) ([]*computation, error) {
+	if len(fields) == 0 && len(tags) == 0 {
+		return nil, nil
+	}
+
+	// Declare the computation environment using the same variables as the
+	// metric filter
+	env, err := cel.NewEnv(
+		cel.Declarations(
+			decls.NewVar("name", decls.String),
+			decls.NewVar("tags", decls.NewMapType(decls.String, decls.String)),
+			decls.NewVar("fields", decls.NewMapType(decls.String, decls.Dyn)),
+			decls.NewVar("time", decls.Timestamp),
+		),
+		ext.Encoders(),
+		ext.Math(),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating environment failed: %w", err)
+	}
+
+	// Fields are computed before the tags so tags can use computed fields
+	computations := make([]*computation, 0, len(fields)+len(tags))
+	for i, c := range append(append(make([]Computed, 0, len(fields)+len(tags)), fields...), tags...) {
+		if c.Name == "" {
+			return nil, errors.New("computation without 'name'")
+		}
+		if c.Expression == "" {
+			return nil, fmt.Errorf("computation %q without 'expression'", c.Name)
+		}
+
+		ast, issues := env.Compile(c.Expression)
+		if issues.Err() != nil {
+			return nil, fmt.Errorf("compiling expression of %q failed: %w", c.Name, issues.Err())
+		}
+		program, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+		if err != nil {
+			return nil, fmt.Errorf("creating program of %q failed: %w", c.Name, err)
+		}
+
+		computations = append(computations, &computation{
+			Computed: c,
+			tag:      i >= len(fields),
+			program:  program,
+		})
+	}
+	return computations, nil
+}
+
+// applyComputations evaluates the computations in order and adds the results
+// to the metric. Empty strings and null results are not added.
+func applyComputations(computations []*computation, m telegraf.Metric) error {
+	for _, c := range computations {
+		result, _, err := c.program.Eval(map[string]interface{}{
+			"name":   m.Name(),
+			"tags":   m.Tags(),
+			"fields": m.Fields(),
+			"time":   m.Time(),
+		})
+		if err != nil {
+			if c.Optional {
+				continue
+			}
+			return fmt.Errorf("evaluating expression of %q failed: %w", c.Name, err)
+		}
+
+		value, err := convertResult(result)
+		if err != nil {
+			return fmt.Errorf("converting result of %q failed: %w", c.Name, err)
+		}
+		if value == nil {
+			continue
+		}
+
+		if c.tag {
+			if v := fmt.Sprint(value); v != "" {
+				m.AddTag(c.Name, v)
+			}
+			continue
+		}
+		if v, ok := value.(string); ok && v == "" {
+			continue
+		}
+		m.AddField(c.Name, value)
+	}
+	return nil
+}
+
+func convertResult(result ref.Val) (interface{}, error) {
+	switch v := result.Value().(type) {
+	case int64, uint64, float64, bool, string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case time.Time:
+		return v.UnixNano(), nil
+	}
+	if result.Type() == types.NullType {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported result type %q", result.Type().TypeName())
+}
@@ -37,6 +37,9 @@ type Parser struct {
 	iterateObjects bool
 	// objectConfig contains the config for an object, some info is needed while iterating over the gjson results
 	objectConfig Object
+	// computations contains the compiled computed fields and tags per config
+	computations [][]*computation
+
 	// parseMutex is here because Parse() is not threadsafe.  If it is made threadsafe at some point, then we won't need it anymore.
 	parseMutex sync.Mutex
 }
@@ -48,9 +51,11 @@ type Config struct {
 	TimestampFormat     string `toml:"timestamp_format"`      // OPTIONAL, but REQUIRED when timestamp_path is defined
 	TimestampTimezone   string `toml:"timestamp_timezone"`    // OPTIONAL, but REQUIRES timestamp_path
 
-	Fields      []DataSet `toml:"field"`
-	Tags        []DataSet `toml:"tag"`
-	JSONObjects []Object  `toml:"object"`
+	Fields         []DataSet  `toml:"field"`
+	Tags           []DataSet  `toml:"tag"`
+	JSONObjects    []Object   `toml:"object"`
+	ComputedFields []Computed `toml:"computed_field"`
+	ComputedTags   []Computed `toml:"computed_tag"`
 
 	Location *time.Location
 }
@@ -117,6 +122,16 @@ func (p *Parser) Init() error {
 			p.Configs[i].Location = loc
 		}
 	}
+
+	// Compile the expressions of the computed fields and tags
+	p.computations = make([][]*computation, 0, len(p.Configs))
+	for i, cfg := range p.Configs {
+		computations, err := compileComputations(cfg.ComputedFields, cfg.ComputedTags)
+		if err != nil {
+			return fmt.Errorf("compiling computations in config %d failed: %w", i+1, err)
+		}
+		p.computations = append(p.computations, computations)
+	}
 	return nil
 }
 
@@ -152,7 +167,7 @@ func (p *Parser) parseCriticalPath(input []byte) ([]telegraf.Metric, error) {
 
 	var metrics []telegraf.Metric
 
-	for _, c := range p.Configs {
+	for i, c := range p.Configs {
 		// Measurement name can either be hardcoded, or parsed from the JSON using a GJSON path expression
 		p.measurementName = c.MeasurementName
 		if c.MeasurementNamePath != "" {
@@ -200,13 +215,25 @@ func (p *Parser) parseCriticalPath(input []byte) ([]telegraf.Metric, error) {
 			return nil, err
 		}
 
+		start := len(metrics)
 		metrics = append(metrics, cartesianProduct(tags, fields)...)
 
 		if len(objects) != 0 && len(metrics) != 0 {
 			metrics = cartesianProduct(objects, metrics)
+			start = 0
 		} else {
 			metrics = append(metrics, objects...)
 		}
+
+		// Compute the additional fields and tags of the metrics created by
+		// this config
+		if i < len(p.computations) {
+			for _, m := range metrics[start:] {
+				if err := applyComputations(p.computations[i], m); err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
 
 	for k, v := range p.DefaultTags {
@@ -0,0 +1,136 @@
+package prometheus
+
+import (
+	"math"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// histogramBucket is a cumulative histogram bucket
+type histogramBucket struct {
+	upperBound float64
+	count      float64
+}
+
+// nativeBucket is a non-cumulative bucket of a native histogram
+type nativeBucket struct {
+	index int32
+	count float64
+}
+
+// histogramCount returns the number of samples of the histogram
+func histogramCount(h *dto.Histogram) float64 {
+	if v := h.GetSampleCountFloat(); v > 0 {
+		return v
+	}
+	return float64(h.GetSampleCount())
+}
+
+// histogramBuckets returns the cumulative buckets of the histogram. Native
+// histograms without classic buckets are converted to cumulative buckets
+// with the upper bounds of the exponential buckets.
+func histogramBuckets(h *dto.Histogram) []histogramBucket {
+	if len(h.GetBucket()) == 0 && isNativeHistogram(h) {
+		return nativeHistogramBuckets(h)
+	}
+
+	buckets := make([]histogramBucket, 0, len(h.GetBucket()))
+	for _, b := range h.GetBucket() {
+		count := float64(b.GetCumulativeCount())
+		if v := b.GetCumulativeCountFloat(); v > 0 {
+			count = v
+		}
+		buckets = append(buckets, histogramBucket{upperBound: b.GetUpperBound(), count: count})
+	}
+	return buckets
+}
+
+// isNativeHistogram checks if the histogram contains native histogram data.
+// Native histograms without observations contain an empty span to be
+// distinguishable from classic histograms.
+func isNativeHistogram(h *dto.Histogram) bool {
+	return len(h.GetPositiveSpan()) > 0 ||
+		len(h.GetNegativeSpan()) > 0 ||
+		h.GetZeroThreshold() > 0 ||
+		h.GetZeroCount() > 0 ||
+		h.GetZeroCountFloat() > 0
+}
+
+// nativeHistogramBuckets converts the exponential buckets of a native
+// histogram to cumulative buckets in ascending order of their upper bound,
+// i.e. the negative buckets followed by the zero bucket, the positive buckets
+// and the infinity bucket.
+func nativeHistogramBuckets(h *dto.Histogram) []histogramBucket {
+	schema := h.GetSchema()
+	negative := expandNativeBuckets(h.GetNegativeSpan(), h.GetNegativeDelta(), h.GetNegativeCount())
+	positive := expandNativeBuckets(h.GetPositiveSpan(), h.GetPositiveDelta(), h.GetPositiveCount())
+
+	buckets := make([]histogramBucket, 0, len(negative)+len(positive)+2)
+	var cumulative float64
+
+	// Negative bucket i covers [-base^i, -base^(i-1)) so its upper bound is
+	// the negated lower bound of the corresponding positive bucket.
+	for i := len(negative) - 1; i >= 0; i-- {
+		cumulative += negative[i].count
+		buckets = append(buckets, histogramBucket{
+			upperBound: -nativeBucketBound(negative[i].index-1, schema),
+			count:      cumulative,
+		})
+	}
+
+	if v := h.GetZeroCountFloat(); v > 0 {
+		cumulative += v
+	} else {
+		cumulative += float64(h.GetZeroCount())
+	}
+	buckets = append(buckets, histogramBucket{upperBound: h.GetZeroThreshold(), count: cumulative})
+
+	for _, b := range positive {
+		cumulative += b.count
+		buckets = append(buckets, histogramBucket{
+			upperBound: nativeBucketBound(b.index, schema),
+			count:      cumulative,
+		})
+	}
+
+	return append(buckets, histogramBucket{upperBound: math.Inf(1), count: histogramCount(h)})
+}
+
+// expandNativeBuckets returns the buckets described by the spans with the
+// counts being either delta-encoded integers or absolute float values.
+func expandNativeBuckets(spans []*dto.BucketSpan, deltas []int64, counts []float64) []nativeBucket {
+	var buckets []nativeBucket
+	var index int32
+	var current int64
+	var pos int
+	for _, span := range spans {
+		// The offset of the first span is the start index, the offset of
+		// all other spans is the gap to the previous span.
+		index += span.GetOffset()
+		for i := uint32(0); i < span.GetLength(); i++ {
+			var count float64
+			if len(counts) > 0 {
+				if pos >= len(counts) {
+					return buckets
+				}
+				count = counts[pos]
+			} else {
+				if pos >= len(deltas) {
+					return buckets
+				}
+				current += deltas[pos]
+				count = float64(current)
+			}
+			buckets = append(buckets, nativeBucket{index: index, count: count})
+			index++
+			pos++
+		}
+	}
+	return buckets
+}
+
+// nativeBucketBound returns the upper bound of the positive bucket with the
+// given index, i.e. base^index with base = 2^(2^-schema).
+func nativeBucketBound(index, schema int32) float64 {
+	return math.Exp2(math.Ldexp(float64(index), -int(schema)))
+}
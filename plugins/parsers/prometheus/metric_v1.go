@@ -52,12 +52,13 @@ func (p *Parser) extractMetricsV1(prommetrics *dto.MetricFamily) []telegraf.Metr
 			histogram := pm.GetHistogram()
 
 			// Collect the fields
-			fields := make(map[string]interface{}, len(histogram.Bucket)+2)
-			fields["count"] = float64(pm.GetHistogram().GetSampleCount())
-			fields["sum"] = pm.GetHistogram().GetSampleSum()
-			for _, b := range histogram.Bucket {
-				fname := strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
-				fields[fname] = float64(b.GetCumulativeCount())
+			buckets := histogramBuckets(histogram)
+			fields := make(map[string]interface{}, len(buckets)+2)
+			fields["count"] = histogramCount(histogram)
+			fields["sum"] = histogram.GetSampleSum()
+			for _, b := range buckets {
+				fname := strconv.FormatFloat(b.upperBound, 'g', -1, 64)
+				fields[fname] = b.count
 			}
 			metrics = append(metrics, metric.New(metricName, tags, fields, t, telegraf.Histogram))
 		default:
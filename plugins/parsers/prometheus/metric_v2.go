@@ -57,23 +57,23 @@ func (p *Parser) extractMetricsV2(prommetrics *dto.MetricFamily) []telegraf.Metr
 
 			// Add an overall metric containing the number of samples and and its sum
 			histFields := make(map[string]interface{})
-			histFields[metricName+"_count"] = float64(histogram.GetSampleCount())
+			histFields[metricName+"_count"] = histogramCount(histogram)
 			histFields[metricName+"_sum"] = histogram.GetSampleSum()
 			metrics = append(metrics, metric.New("prometheus", tags, histFields, t, telegraf.Histogram))
 
 			// Add one metric per histogram bucket
 			var infSeen bool
-			for _, b := range histogram.Bucket {
+			for _, b := range histogramBuckets(histogram) {
 				bucketTags := tags
-				bucketTags["le"] = strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
+				bucketTags["le"] = strconv.FormatFloat(b.upperBound, 'g', -1, 64)
 				bucketFields := map[string]interface{}{
-					metricName + "_bucket": float64(b.GetCumulativeCount()),
+					metricName + "_bucket": b.count,
 				}
 				m := metric.New("prometheus", bucketTags, bucketFields, t, telegraf.Histogram)
 				metrics = append(metrics, m)
 
 				// Record if any of the buckets marks an infinite upper bound
-				infSeen = infSeen || math.IsInf(b.GetUpperBound(), +1)
+				infSeen = infSeen || math.IsInf(b.upperBound, +1)
 			}
 
 			// Infinity bucket is required for proper function of histogram in prometheus
@@ -81,7 +81,7 @@ func (p *Parser) extractMetricsV2(prommetrics *dto.MetricFamily) []telegraf.Metr
 				infTags := tags
 				infTags["le"] = "+Inf"
 				infFields := map[string]interface{}{
-					metricName + "_bucket": float64(histogram.GetSampleCount()),
+					metricName + "_bucket": histogramCount(histogram),
 				}
 				m := metric.New("prometheus", infTags, infFields, t, telegraf.Histogram)
 				metrics = append(metrics, m)
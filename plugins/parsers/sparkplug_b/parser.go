@@ -0,0 +1,318 @@
+package sparkplug_b
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// Name of the metric containing the birth/death sequence number of an edge node
+const bdSeqMetric = "bdSeq"
+
+type Parser struct {
+	StateMeasurement string          `toml:"sparkplug_b_state_measurement"`
+	Log              telegraf.Logger `toml:"-"`
+
+	metricName  string
+	defaultTags map[string]string
+
+	nodes map[string]*edgeNode
+	sync.Mutex
+}
+
+// edgeNode holds the state of an edge node announced by its birth certificate
+type edgeNode struct {
+	bdSeq    uint64
+	hasBdSeq bool
+	aliases  map[uint64]string
+	devices  map[string]bool
+}
+
+// topic contains the elements of a Sparkplug B topic in the form
+// 'spBv1.0/<group_id>/<message_type>/<edge_node_id>[/<device_id>]'
+type topic struct {
+	group       string
+	messageType string
+	edgeNode    string
+	device      string
+}
+
+func (p *Parser) Init() error {
+	if p.StateMeasurement == "" {
+		p.StateMeasurement = "sparkplug_state"
+	}
+	p.nodes = make(map[string]*edgeNode)
+
+	return nil
+}
+
+// Parse decodes the payload without any topic information. Aliases cannot
+// be resolved in this case so only metrics with names are reported.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	pl, err := decodePayload(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.dataMetrics(nil, pl, map[string]string{}), nil
+}
+
+// ParseMessage decodes the payload of a message received on the given topic
+// and keeps track of the birth and death certificates of edge nodes and
+// devices. Metric aliases are resolved using the birth certificates.
+func (p *Parser) ParseMessage(topicName string, buf []byte) ([]telegraf.Metric, error) {
+	t, err := parseTopic(topicName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.messageType {
+	case "NBIRTH", "NDEATH", "NDATA", "DBIRTH", "DDEATH", "DDATA":
+	default:
+		// Commands and host application states do not carry metrics
+		return nil, nil
+	}
+
+	pl, err := decodePayload(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding payload of topic %q failed: %w", topicName, err)
+	}
+
+	tags := map[string]string{
+		"group_id":     t.group,
+		"edge_node_id": t.edgeNode,
+	}
+	if t.device != "" {
+		tags["device_id"] = t.device
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	key := t.group + "/" + t.edgeNode
+	node := p.nodes[key]
+
+	switch t.messageType {
+	case "NBIRTH":
+		// A new birth certificate invalidates all aliases and devices
+		node = &edgeNode{
+			aliases: make(map[uint64]string),
+			devices: make(map[string]bool),
+		}
+		p.nodes[key] = node
+		node.register(pl)
+		for _, m := range pl.metrics {
+			if m.name == bdSeqMetric {
+				if v, ok := m.raw.(uint64); ok {
+					node.bdSeq = v
+					node.hasBdSeq = true
+				}
+			}
+		}
+		metrics := []telegraf.Metric{p.stateMetric(tags, true, node.bdSeq, node.hasBdSeq, pl.timestamp)}
+		return append(metrics, p.dataMetrics(node, pl, tags)...), nil
+	case "DBIRTH":
+		if node == nil {
+			p.Log.Warnf("Received device birth for %q before birth of edge node %q", t.device, key)
+			node = &edgeNode{
+				aliases: make(map[uint64]string),
+				devices: make(map[string]bool),
+			}
+			p.nodes[key] = node
+		}
+		node.register(pl)
+		node.devices[t.device] = true
+		metrics := []telegraf.Metric{p.stateMetric(tags, true, 0, false, pl.timestamp)}
+		return append(metrics, p.dataMetrics(node, pl, tags)...), nil
+	case "NDEATH":
+		var bdSeq uint64
+		var hasBdSeq bool
+		for _, m := range pl.metrics {
+			if m.name == bdSeqMetric {
+				bdSeq, hasBdSeq = m.raw.(uint64)
+			}
+		}
+		// A death certificate not matching the current birth belongs to a
+		// previous session of the edge node and must be ignored
+		if node != nil && node.hasBdSeq && hasBdSeq && node.bdSeq != bdSeq {
+			p.Log.Debugf("Ignoring death of edge node %q with outdated bdSeq %d", key, bdSeq)
+			return nil, nil
+		}
+		delete(p.nodes, key)
+
+		// The death of an edge node implies the death of all its devices
+		var metrics []telegraf.Metric
+		if node != nil {
+			devices := make([]string, 0, len(node.devices))
+			for device := range node.devices {
+				devices = append(devices, device)
+			}
+			sort.Strings(devices)
+			for _, device := range devices {
+				deviceTags := map[string]string{"device_id": device}
+				for k, v := range tags {
+					deviceTags[k] = v
+				}
+				metrics = append(metrics, p.stateMetric(deviceTags, false, 0, false, pl.timestamp))
+			}
+		}
+		return append(metrics, p.stateMetric(tags, false, bdSeq, hasBdSeq, pl.timestamp)), nil
+	case "DDEATH":
+		if node != nil {
+			delete(node.devices, t.device)
+		}
+		return []telegraf.Metric{p.stateMetric(tags, false, 0, false, pl.timestamp)}, nil
+	}
+
+	if node == nil {
+		p.Log.Debugf("Received data for edge node %q before its birth certificate", key)
+	}
+	return p.dataMetrics(node, pl, tags), nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, nil
+	}
+	if len(metrics) > 1 {
+		return nil, errors.New("line contains multiple metrics")
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.defaultTags = tags
+}
+
+// register stores the aliases announced in a birth certificate
+func (n *edgeNode) register(pl *payload) {
+	for _, m := range pl.metrics {
+		if m.hasAlias && m.name != "" {
+			n.aliases[m.alias] = m.name
+		}
+	}
+}
+
+// dataMetrics creates one metric per timestamp containing all values of the
+// payload as fields
+func (p *Parser) dataMetrics(node *edgeNode, pl *payload, tags map[string]string) []telegraf.Metric {
+	var timestamps []uint64
+	fieldsByTime := make(map[uint64]map[string]interface{})
+	for _, m := range pl.metrics {
+		name := m.name
+		if name == "" && node != nil {
+			name = node.aliases[m.alias]
+		}
+		if name == "" {
+			p.Log.Debugf("Ignoring metric with unknown alias %d", m.alias)
+			continue
+		}
+		if name == bdSeqMetric {
+			continue
+		}
+
+		value, ok := m.value()
+		if !ok {
+			continue
+		}
+
+		ts := m.timestamp
+		if ts == 0 {
+			ts = pl.timestamp
+		}
+		fields, found := fieldsByTime[ts]
+		if !found {
+			fields = make(map[string]interface{})
+			fieldsByTime[ts] = fields
+			timestamps = append(timestamps, ts)
+		}
+		fields[name] = value
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(timestamps))
+	for _, ts := range timestamps {
+		metrics = append(metrics, metric.New(p.metricName, p.tags(tags), fieldsByTime[ts], toTime(ts)))
+	}
+	return metrics
+}
+
+func (p *Parser) stateMetric(tags map[string]string, online bool, bdSeq uint64, hasBdSeq bool, ts uint64) telegraf.Metric {
+	fields := map[string]interface{}{"online": online}
+	if hasBdSeq {
+		fields["bd_seq"] = bdSeq
+	}
+	return metric.New(p.StateMeasurement, p.tags(tags), fields, toTime(ts))
+}
+
+// tags merges the default tags with the given tags
+func (p *Parser) tags(tags map[string]string) map[string]string {
+	result := make(map[string]string, len(p.defaultTags)+len(tags))
+	for k, v := range p.defaultTags {
+		result[k] = v
+	}
+	for k, v := range tags {
+		result[k] = v
+	}
+	return result
+}
+
+// toTime converts a Sparkplug timestamp in milliseconds since epoch to a
+// time, using the current time if no timestamp is given
+func toTime(ts uint64) time.Time {
+	if ts == 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(int64(ts))
+}
+
+func parseTopic(name string) (*topic, error) {
+	parts := strings.Split(name, "/")
+	if len(parts) < 3 || parts[0] != "spBv1.0" {
+		return nil, fmt.Errorf("topic %q is not a Sparkplug B topic", name)
+	}
+
+	// The host application state uses 'spBv1.0/STATE/<host_id>'
+	if parts[1] == "STATE" {
+		return &topic{messageType: "STATE"}, nil
+	}
+
+	t := &topic{
+		group:       parts[1],
+		messageType: parts[2],
+	}
+	switch len(parts) {
+	case 4:
+		t.edgeNode = parts[3]
+	case 5:
+		t.edgeNode = parts[3]
+		t.device = parts[4]
+	default:
+		return nil, fmt.Errorf("topic %q is not a Sparkplug B topic", name)
+	}
+
+	if strings.HasPrefix(t.messageType, "D") && t.device == "" {
+		return nil, fmt.Errorf("topic %q is missing the device ID", name)
+	}
+	return t, nil
+}
+
+func init() {
+	parsers.Add("sparkplug_b",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{metricName: defaultMetricName}
+		},
+	)
+}
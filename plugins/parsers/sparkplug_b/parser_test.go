@@ -0,0 +1,300 @@
+package sparkplug_b
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type testMetric struct {
+	name      string
+	alias     uint64
+	timestamp uint64
+	datatype  uint32
+	value     interface{}
+}
+
+// encodePayload creates a Sparkplug B protobuf payload
+func encodePayload(timestamp uint64, metrics ...testMetric) []byte {
+	var buf []byte
+	buf = protowire.AppendTag(buf, 1, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, timestamp)
+	for _, m := range metrics {
+		var data []byte
+		if m.name != "" {
+			data = protowire.AppendTag(data, 1, protowire.BytesType)
+			data = protowire.AppendString(data, m.name)
+		}
+		if m.alias != 0 {
+			data = protowire.AppendTag(data, 2, protowire.VarintType)
+			data = protowire.AppendVarint(data, m.alias)
+		}
+		if m.timestamp != 0 {
+			data = protowire.AppendTag(data, 3, protowire.VarintType)
+			data = protowire.AppendVarint(data, m.timestamp)
+		}
+		data = protowire.AppendTag(data, 4, protowire.VarintType)
+		data = protowire.AppendVarint(data, uint64(m.datatype))
+
+		switch v := m.value.(type) {
+		case nil:
+			data = protowire.AppendTag(data, 7, protowire.VarintType)
+			data = protowire.AppendVarint(data, 1)
+		case uint32:
+			data = protowire.AppendTag(data, 10, protowire.VarintType)
+			data = protowire.AppendVarint(data, uint64(v))
+		case uint64:
+			data = protowire.AppendTag(data, 11, protowire.VarintType)
+			data = protowire.AppendVarint(data, v)
+		case float32:
+			data = protowire.AppendTag(data, 12, protowire.Fixed32Type)
+			data = protowire.AppendFixed32(data, math.Float32bits(v))
+		case float64:
+			data = protowire.AppendTag(data, 13, protowire.Fixed64Type)
+			data = protowire.AppendFixed64(data, math.Float64bits(v))
+		case bool:
+			data = protowire.AppendTag(data, 14, protowire.VarintType)
+			data = protowire.AppendVarint(data, protowire.EncodeBool(v))
+		case string:
+			data = protowire.AppendTag(data, 15, protowire.BytesType)
+			data = protowire.AppendString(data, v)
+		case []byte:
+			data = protowire.AppendTag(data, 16, protowire.BytesType)
+			data = protowire.AppendBytes(data, v)
+		}
+
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, data)
+	}
+	buf = protowire.AppendTag(buf, 3, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, 0)
+	return buf
+}
+
+func newParser(t *testing.T) *Parser {
+	parser := &Parser{
+		metricName: "sparkplug",
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, parser.Init())
+	return parser
+}
+
+func TestDataTypes(t *testing.T) {
+	buf := encodePayload(1700000000000,
+		testMetric{name: "int8", datatype: typeInt8, value: uint32(0xff)},
+		testMetric{name: "int16", datatype: typeInt16, value: uint32(0xfffe)},
+		testMetric{name: "int32", datatype: typeInt32, value: uint32(0xfffffffd)},
+		testMetric{name: "int64", datatype: typeInt64, value: uint64(math.MaxUint64)},
+		testMetric{name: "uint8", datatype: typeUInt8, value: uint32(200)},
+		testMetric{name: "uint64", datatype: typeUInt64, value: uint64(math.MaxUint64)},
+		testMetric{name: "float", datatype: typeFloat, value: float32(1.5)},
+		testMetric{name: "double", datatype: typeDouble, value: 2.25},
+		testMetric{name: "boolean", datatype: typeBoolean, value: true},
+		testMetric{name: "string", datatype: typeString, value: "hello"},
+		testMetric{name: "null", datatype: typeDouble, value: nil},
+		testMetric{name: "bytes", datatype: 17, value: []byte{0x01}},
+	)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"sparkplug",
+			map[string]string{},
+			map[string]interface{}{
+				"int8":    int64(-1),
+				"int16":   int64(-2),
+				"int32":   int64(-3),
+				"int64":   int64(-1),
+				"uint8":   uint64(200),
+				"uint64":  uint64(math.MaxUint64),
+				"float":   1.5,
+				"double":  2.25,
+				"boolean": true,
+				"string":  "hello",
+			},
+			time.UnixMilli(1700000000000),
+		),
+	}
+
+	parser := newParser(t)
+	actual, err := parser.Parse(buf)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestLifecycle(t *testing.T) {
+	parser := newParser(t)
+	parser.SetDefaultTags(map[string]string{"source": "test"})
+
+	// Node birth with alias definitions
+	actual, err := parser.ParseMessage("spBv1.0/plant/NBIRTH/node1", encodePayload(1000,
+		testMetric{name: "bdSeq", datatype: typeInt64, value: uint64(3)},
+		testMetric{name: "Node Control/Rebirth", alias: 1, datatype: typeBoolean, value: false},
+		testMetric{name: "temperature", alias: 2, datatype: typeDouble, value: 20.5},
+	))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		metric.New(
+			"sparkplug_state",
+			map[string]string{"source": "test", "group_id": "plant", "edge_node_id": "node1"},
+			map[string]interface{}{"online": true, "bd_seq": uint64(3)},
+			time.UnixMilli(1000),
+		),
+		metric.New(
+			"sparkplug",
+			map[string]string{"source": "test", "group_id": "plant", "edge_node_id": "node1"},
+			map[string]interface{}{"Node Control/Rebirth": false, "temperature": 20.5},
+			time.UnixMilli(1000),
+		),
+	}, actual)
+
+	// Device birth extending the aliases
+	actual, err = parser.ParseMessage("spBv1.0/plant/DBIRTH/node1/pump", encodePayload(2000,
+		testMetric{name: "speed", alias: 10, datatype: typeUInt32, value: uint32(1200)},
+	))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		metric.New(
+			"sparkplug_state",
+			map[string]string{"source": "test", "group_id": "plant", "edge_node_id": "node1", "device_id": "pump"},
+			map[string]interface{}{"online": true},
+			time.UnixMilli(2000),
+		),
+		metric.New(
+			"sparkplug",
+			map[string]string{"source": "test", "group_id": "plant", "edge_node_id": "node1", "device_id": "pump"},
+			map[string]interface{}{"speed": uint64(1200)},
+			time.UnixMilli(2000),
+		),
+	}, actual)
+
+	// Data using aliases only with individual timestamps
+	actual, err = parser.ParseMessage("spBv1.0/plant/DDATA/node1/pump", encodePayload(3000,
+		testMetric{alias: 10, timestamp: 2900, datatype: typeUInt32, value: uint32(1250)},
+		testMetric{alias: 10, timestamp: 3000, datatype: typeUInt32, value: uint32(1300)},
+		testMetric{alias: 99, datatype: typeUInt32, value: uint32(1)},
+	))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		metric.New(
+			"sparkplug",
+			map[string]string{"source": "test", "group_id": "plant", "edge_node_id": "node1", "device_id": "pump"},
+			map[string]interface{}{"speed": uint64(1250)},
+			time.UnixMilli(2900),
+		),
+		metric.New(
+			"sparkplug",
+			map[string]string{"source": "test", "group_id": "plant", "edge_node_id": "node1", "device_id": "pump"},
+			map[string]interface{}{"speed": uint64(1300)},
+			time.UnixMilli(3000),
+		),
+	}, actual)
+
+	actual, err = parser.ParseMessage("spBv1.0/plant/NDATA/node1", encodePayload(4000,
+		testMetric{alias: 2, datatype: typeDouble, value: 21.0},
+	))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		metric.New(
+			"sparkplug",
+			map[string]string{"source": "test", "group_id": "plant", "edge_node_id": "node1"},
+			map[string]interface{}{"temperature": 21.0},
+			time.UnixMilli(4000),
+		),
+	}, actual)
+
+	// Commands are ignored
+	actual, err = parser.ParseMessage("spBv1.0/plant/NCMD/node1", encodePayload(4500,
+		testMetric{alias: 1, datatype: typeBoolean, value: true},
+	))
+	require.NoError(t, err)
+	require.Empty(t, actual)
+
+	// Death of an outdated session is ignored
+	actual, err = parser.ParseMessage("spBv1.0/plant/NDEATH/node1", encodePayload(0,
+		testMetric{name: "bdSeq", datatype: typeInt64, value: uint64(2)},
+	))
+	require.NoError(t, err)
+	require.Empty(t, actual)
+
+	// Node death implies the death of all devices
+	actual, err = parser.ParseMessage("spBv1.0/plant/NDEATH/node1", encodePayload(5000,
+		testMetric{name: "bdSeq", datatype: typeInt64, value: uint64(3)},
+	))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		metric.New(
+			"sparkplug_state",
+			map[string]string{"source": "test", "group_id": "plant", "edge_node_id": "node1", "device_id": "pump"},
+			map[string]interface{}{"online": false},
+			time.UnixMilli(5000),
+		),
+		metric.New(
+			"sparkplug_state",
+			map[string]string{"source": "test", "group_id": "plant", "edge_node_id": "node1"},
+			map[string]interface{}{"online": false, "bd_seq": uint64(3)},
+			time.UnixMilli(5000),
+		),
+	}, actual)
+
+	// Aliases are unknown after the death of the node
+	actual, err = parser.ParseMessage("spBv1.0/plant/NDATA/node1", encodePayload(6000,
+		testMetric{alias: 2, datatype: typeDouble, value: 22.0},
+	))
+	require.NoError(t, err)
+	require.Empty(t, actual)
+}
+
+func TestDeviceDeath(t *testing.T) {
+	parser := newParser(t)
+	parser.StateMeasurement = "state"
+
+	_, err := parser.ParseMessage("spBv1.0/plant/DBIRTH/node1/pump", encodePayload(1000,
+		testMetric{name: "speed", alias: 1, datatype: typeUInt32, value: uint32(1200)},
+	))
+	require.NoError(t, err)
+
+	actual, err := parser.ParseMessage("spBv1.0/plant/DDEATH/node1/pump", encodePayload(2000))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		metric.New(
+			"state",
+			map[string]string{"group_id": "plant", "edge_node_id": "node1", "device_id": "pump"},
+			map[string]interface{}{"online": false},
+			time.UnixMilli(2000),
+		),
+	}, actual)
+}
+
+func TestInvalidTopics(t *testing.T) {
+	parser := newParser(t)
+
+	for _, topic := range []string{
+		"telegraf/metrics",
+		"spBv1.0/plant",
+		"spBv1.0/plant/NDATA/node1/device/extra",
+		"spBv1.0/plant/DDATA/node1",
+	} {
+		_, err := parser.ParseMessage(topic, encodePayload(0))
+		require.ErrorContains(t, err, topic)
+	}
+
+	// Host application states are ignored
+	actual, err := parser.ParseMessage("spBv1.0/STATE/scada", []byte(`{"online":true}`))
+	require.NoError(t, err)
+	require.Empty(t, actual)
+}
+
+func TestInvalidPayload(t *testing.T) {
+	parser := newParser(t)
+
+	_, err := parser.ParseMessage("spBv1.0/plant/NDATA/node1", []byte{0x12, 0xff})
+	require.ErrorContains(t, err, "decoding payload")
+}
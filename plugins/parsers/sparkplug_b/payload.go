@@ -0,0 +1,172 @@
+package sparkplug_b
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Data types as defined in the Sparkplug B specification
+const (
+	typeInt8     = 1
+	typeInt16    = 2
+	typeInt32    = 3
+	typeInt64    = 4
+	typeUInt8    = 5
+	typeUInt16   = 6
+	typeUInt32   = 7
+	typeUInt64   = 8
+	typeFloat    = 9
+	typeDouble   = 10
+	typeBoolean  = 11
+	typeString   = 12
+	typeDateTime = 13
+	typeText     = 14
+	typeUUID     = 15
+)
+
+// payload is the relevant subset of the Sparkplug B protobuf payload
+// 'org.eclipse.tahu.protobuf.Payload'
+type payload struct {
+	timestamp uint64
+	metrics   []sparkplugMetric
+}
+
+type sparkplugMetric struct {
+	name      string
+	alias     uint64
+	hasAlias  bool
+	timestamp uint64
+	datatype  uint32
+	isNull    bool
+
+	// Raw value of the 'value' oneof, nil for unsupported value types such
+	// as datasets or templates
+	raw interface{}
+}
+
+// value converts the raw value according to the metric's data type
+func (m *sparkplugMetric) value() (interface{}, bool) {
+	if m.isNull || m.raw == nil {
+		return nil, false
+	}
+
+	switch v := m.raw.(type) {
+	case uint64:
+		switch m.datatype {
+		case typeInt8:
+			return int64(int8(v)), true
+		case typeInt16:
+			return int64(int16(v)), true
+		case typeInt32:
+			return int64(int32(v)), true
+		case typeInt64, typeDateTime:
+			return int64(v), true
+		case typeUInt8, typeUInt16, typeUInt32, typeUInt64:
+			return v, true
+		case typeBoolean:
+			return v != 0, true
+		}
+		return nil, false
+	case float32:
+		return float64(v), true
+	case float64, bool:
+		return v, true
+	case string:
+		switch m.datatype {
+		case typeString, typeText, typeUUID:
+			return v, true
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+func decodePayload(buf []byte) (*payload, error) {
+	var p payload
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			p.timestamp, n = protowire.ConsumeVarint(buf)
+		case num == 2 && typ == protowire.BytesType:
+			var data []byte
+			data, n = protowire.ConsumeBytes(buf)
+			if n >= 0 {
+				m, err := decodeMetric(data)
+				if err != nil {
+					return nil, fmt.Errorf("decoding metric %d failed: %w", len(p.metrics), err)
+				}
+				p.metrics = append(p.metrics, *m)
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+	}
+
+	return &p, nil
+}
+
+func decodeMetric(buf []byte) (*sparkplugMetric, error) {
+	var m sparkplugMetric
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		var v uint64
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			m.name, n = protowire.ConsumeString(buf)
+		case num == 2 && typ == protowire.VarintType:
+			m.alias, n = protowire.ConsumeVarint(buf)
+			m.hasAlias = true
+		case num == 3 && typ == protowire.VarintType:
+			m.timestamp, n = protowire.ConsumeVarint(buf)
+		case num == 4 && typ == protowire.VarintType:
+			v, n = protowire.ConsumeVarint(buf)
+			m.datatype = uint32(v)
+		case num == 7 && typ == protowire.VarintType:
+			v, n = protowire.ConsumeVarint(buf)
+			m.isNull = v != 0
+		case (num == 10 || num == 11 || num == 14) && typ == protowire.VarintType:
+			// int_value, long_value and boolean_value
+			v, n = protowire.ConsumeVarint(buf)
+			m.raw = v
+		case num == 12 && typ == protowire.Fixed32Type:
+			var bits uint32
+			bits, n = protowire.ConsumeFixed32(buf)
+			m.raw = math.Float32frombits(bits)
+		case num == 13 && typ == protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(buf)
+			m.raw = math.Float64frombits(v)
+		case num == 15 && typ == protowire.BytesType:
+			m.raw, n = protowire.ConsumeString(buf)
+		default:
+			// Skip metadata, properties and unsupported value types
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+	}
+
+	if m.name == "" && !m.hasAlias {
+		return nil, errors.New("neither name nor alias set")
+	}
+	return &m, nil
+}
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.deduplicate
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/deduplicate" // register plugin
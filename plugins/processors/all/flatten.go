@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.flatten
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/flatten" // register plugin
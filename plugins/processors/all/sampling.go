@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.sampling
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/sampling" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.units
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/units" // register plugin
@@ -0,0 +1,214 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package anomaly
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Anomaly struct {
+	IncludeFields    []string        `toml:"include_fields"`
+	ExcludeFields    []string        `toml:"exclude_fields"`
+	Alpha            float64         `toml:"alpha"`
+	Threshold        float64         `toml:"threshold"`
+	Warmup           uint64          `toml:"warmup"`
+	Expiry           config.Duration `toml:"expiry"`
+	Mode             string          `toml:"mode"`
+	TagKey           string          `toml:"tag_key"`
+	EventMeasurement string          `toml:"event_measurement"`
+	Log              telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+	series      map[seriesKey]*stats
+	lastCleanup time.Time
+}
+
+type seriesKey struct {
+	id    uint64
+	field string
+}
+
+// stats holds the exponentially weighted moving average and variance of a
+// series' field
+type stats struct {
+	count    uint64
+	mean     float64
+	variance float64
+	lastSeen time.Time
+}
+
+func (*Anomaly) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Anomaly) Init() error {
+	if a.Alpha <= 0 || a.Alpha > 1 {
+		return errors.New("'alpha' must be in the range (0, 1]")
+	}
+	if a.Threshold <= 0 {
+		return errors.New("'threshold' must be positive")
+	}
+	if a.Expiry < 0 {
+		return errors.New("'expiry' must not be negative")
+	}
+
+	switch a.Mode {
+	case "":
+		a.Mode = "tag"
+		fallthrough
+	case "tag":
+		if a.TagKey == "" {
+			return errors.New("'tag_key' must not be empty")
+		}
+	case "event":
+		if a.EventMeasurement == "" {
+			return errors.New("'event_measurement' must not be empty")
+		}
+	default:
+		return fmt.Errorf("invalid mode %q", a.Mode)
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(a.IncludeFields, a.ExcludeFields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	a.fieldFilter = f
+	a.series = make(map[seriesKey]*stats)
+	a.lastCleanup = time.Now()
+
+	return nil
+}
+
+func (a *Anomaly) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+	out := in
+	for _, m := range in {
+		var anomalous bool
+		id := m.HashID()
+		for _, field := range m.FieldList() {
+			if !a.fieldFilter.Match(field.Key) {
+				continue
+			}
+			if _, ok := field.Value.(bool); ok {
+				continue
+			}
+			value, err := internal.ToFloat64(field.Value)
+			if err != nil {
+				continue
+			}
+
+			key := seriesKey{id: id, field: field.Key}
+			s, found := a.series[key]
+			if !found {
+				s = &stats{}
+				a.series[key] = s
+			}
+
+			mean, stddev := s.mean, math.Sqrt(s.variance)
+			zscore, flagged := s.update(value, a.Alpha, a.Threshold, a.Warmup)
+			s.lastSeen = now
+			if !flagged {
+				continue
+			}
+			anomalous = true
+			if a.Mode == "event" {
+				out = append(out, a.event(m, field.Key, value, mean, stddev, zscore))
+			}
+		}
+		if anomalous && a.Mode == "tag" {
+			m.AddTag(a.TagKey, "true")
+		}
+	}
+
+	a.cleanup(now)
+
+	return out
+}
+
+// update checks the value against the statistics of the series before
+// incorporating the value. It returns the z-score of the value and whether
+// the value is an anomaly.
+func (s *stats) update(value, alpha, threshold float64, warmup uint64) (float64, bool) {
+	diff := value - s.mean
+	zscore := math.NaN()
+	if s.count > 0 {
+		zscore = diff / math.Sqrt(s.variance)
+		if diff == 0 {
+			zscore = 0
+		}
+	}
+	flagged := s.count >= warmup && s.count > 0 && math.Abs(zscore) > threshold
+
+	if s.count == 0 {
+		s.mean = value
+	} else {
+		incr := alpha * diff
+		s.mean += incr
+		s.variance = (1 - alpha) * (s.variance + diff*incr)
+	}
+	s.count++
+
+	return zscore, flagged
+}
+
+// event creates a metric describing the anomalous value of the given field
+// and the statistics of the series the value was compared to
+func (a *Anomaly) event(m telegraf.Metric, field string, value, mean, stddev, zscore float64) telegraf.Metric {
+	tags := m.Tags()
+	tags["measurement"] = m.Name()
+	tags["field"] = field
+
+	fields := map[string]interface{}{
+		"value":  value,
+		"mean":   mean,
+		"stddev": stddev,
+	}
+	// The z-score is infinite for series without any variance
+	if !math.IsInf(zscore, 0) {
+		fields["zscore"] = zscore
+	}
+	return metric.New(a.EventMeasurement, tags, fields, m.Time())
+}
+
+// cleanup removes the state of series not receiving values within the expiry
+// time
+func (a *Anomaly) cleanup(now time.Time) {
+	expiry := time.Duration(a.Expiry)
+	if expiry == 0 || now.Sub(a.lastCleanup) < expiry {
+		return
+	}
+	a.lastCleanup = now
+
+	for key, s := range a.series {
+		if now.Sub(s.lastSeen) >= expiry {
+			delete(a.series, key)
+		}
+	}
+}
+
+func init() {
+	processors.Add("anomaly", func() telegraf.Processor {
+		return &Anomaly{
+			Alpha:            0.1,
+			Threshold:        3.0,
+			Warmup:           10,
+			Expiry:           config.Duration(time.Hour),
+			Mode:             "tag",
+			TagKey:           "anomaly",
+			EventMeasurement: "anomaly",
+		}
+	})
+}
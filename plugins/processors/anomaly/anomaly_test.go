@@ -0,0 +1,231 @@
+package anomaly
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestTagMode(t *testing.T) {
+	plugin := &Anomaly{
+		Alpha:     0.1,
+		Threshold: 3.0,
+		Warmup:    10,
+		Mode:      "tag",
+		TagKey:    "anomaly",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	var input, expected []telegraf.Metric
+	for i := range 10 {
+		value := 10.0 + float64(i%2)
+		ts := now.Add(time.Duration(i) * time.Second)
+		input = append(input, metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": value}, ts))
+		expected = append(expected, metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": value}, ts))
+	}
+	ts := now.Add(10 * time.Second)
+	input = append(input,
+		metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": 100.0, "status": "ok"}, ts),
+		metric.New("m", map[string]string{"host": "b"}, map[string]interface{}{"value": 100.0}, ts),
+	)
+	expected = append(expected,
+		metric.New("m", map[string]string{"host": "a", "anomaly": "true"}, map[string]interface{}{"value": 100.0, "status": "ok"}, ts),
+		metric.New("m", map[string]string{"host": "b"}, map[string]interface{}{"value": 100.0}, ts),
+	)
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestEventMode(t *testing.T) {
+	plugin := &Anomaly{
+		Alpha:            0.5,
+		Threshold:        2.0,
+		Warmup:           2,
+		Mode:             "event",
+		EventMeasurement: "anomaly",
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(0)}, now),
+		metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(2)}, now.Add(time.Second)),
+		metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(4)}, now.Add(2*time.Second)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(0)}, now),
+		metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(2)}, now.Add(time.Second)),
+		metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(4)}, now.Add(2*time.Second)),
+		metric.New(
+			"anomaly",
+			map[string]string{"host": "a", "measurement": "m", "field": "value"},
+			map[string]interface{}{"value": 4.0, "mean": 1.0, "stddev": 1.0, "zscore": 3.0},
+			now.Add(2*time.Second),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestConstantSeries(t *testing.T) {
+	plugin := &Anomaly{
+		Alpha:            0.1,
+		Threshold:        3.0,
+		Warmup:           3,
+		Mode:             "event",
+		EventMeasurement: "anomaly",
+		IncludeFields:    []string{"value"},
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("m", nil, map[string]interface{}{"value": 5, "other": 1}, now),
+		metric.New("m", nil, map[string]interface{}{"value": 5, "other": 1}, now),
+		metric.New("m", nil, map[string]interface{}{"value": 5, "other": 1}, now),
+		metric.New("m", nil, map[string]interface{}{"value": 5, "other": 100}, now),
+		metric.New("m", nil, map[string]interface{}{"value": 6, "other": 1}, now),
+	}
+	expected := make([]telegraf.Metric, 0, len(input)+1)
+	for _, m := range input {
+		expected = append(expected, m.Copy())
+	}
+	expected = append(expected, metric.New(
+		"anomaly",
+		map[string]string{"measurement": "m", "field": "value"},
+		map[string]interface{}{"value": 6.0, "mean": 5.0, "stddev": 0.0},
+		now,
+	))
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestExpiry(t *testing.T) {
+	plugin := &Anomaly{
+		Alpha:     0.1,
+		Threshold: 3.0,
+		Expiry:    config.Duration(time.Minute),
+		TagKey:    "anomaly",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	plugin.Apply(
+		metric.New("m", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, now),
+		metric.New("m", map[string]string{"host": "b"}, map[string]interface{}{"value": 1}, now),
+	)
+	require.Len(t, plugin.series, 2)
+
+	for key, s := range plugin.series {
+		if key.id == metric.New("m", map[string]string{"host": "a"}, nil, now).HashID() {
+			s.lastSeen = now.Add(-2 * time.Minute)
+		}
+	}
+	plugin.lastCleanup = now.Add(-time.Hour)
+	plugin.cleanup(now.Add(time.Second))
+	require.Len(t, plugin.series, 1)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Anomaly
+		expected string
+	}{
+		{
+			name:     "zero alpha",
+			plugin:   &Anomaly{Threshold: 3, TagKey: "anomaly"},
+			expected: "'alpha' must be in the range (0, 1]",
+		},
+		{
+			name:     "alpha too large",
+			plugin:   &Anomaly{Alpha: 1.5, Threshold: 3, TagKey: "anomaly"},
+			expected: "'alpha' must be in the range (0, 1]",
+		},
+		{
+			name:     "zero threshold",
+			plugin:   &Anomaly{Alpha: 0.1, TagKey: "anomaly"},
+			expected: "'threshold' must be positive",
+		},
+		{
+			name:     "invalid mode",
+			plugin:   &Anomaly{Alpha: 0.1, Threshold: 3, Mode: "foo"},
+			expected: `invalid mode "foo"`,
+		},
+		{
+			name:     "empty tag key",
+			plugin:   &Anomaly{Alpha: 0.1, Threshold: 3, Mode: "tag"},
+			expected: "'tag_key' must not be empty",
+		},
+		{
+			name:     "empty event measurement",
+			plugin:   &Anomaly{Alpha: 0.1, Threshold: 3, Mode: "event"},
+			expected: "'event_measurement' must not be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	now := time.Now()
+	inputRaw := []telegraf.Metric{
+		metric.New("m", nil, map[string]interface{}{"value": 0}, now),
+		metric.New("m", nil, map[string]interface{}{"value": 2}, now),
+		metric.New("m", nil, map[string]interface{}{"value": 4}, now),
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Anomaly{
+		Alpha:            0.5,
+		Threshold:        2.0,
+		Warmup:           2,
+		Mode:             "event",
+		EventMeasurement: "anomaly",
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 4)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
@@ -0,0 +1,192 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package deduplicate
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Deduplicate struct {
+	CacheSize          int             `toml:"cache_size"`
+	TTL                config.Duration `toml:"ttl"`
+	TimestampTolerance config.Duration `toml:"timestamp_tolerance"`
+	IgnoreTags         []string        `toml:"ignore_tags"`
+	IgnoreFields       []string        `toml:"ignore_fields"`
+	Log                telegraf.Logger `toml:"-"`
+
+	ignoreTags   filter.Filter
+	ignoreFields filter.Filter
+	cache        *expirable.LRU[uint64, time.Time]
+}
+
+func (*Deduplicate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Deduplicate) Init() error {
+	if d.CacheSize <= 0 {
+		return errors.New("'cache_size' must be positive")
+	}
+	if d.TTL < 0 {
+		return errors.New("'ttl' must not be negative")
+	}
+	if d.TimestampTolerance < 0 {
+		return errors.New("'timestamp_tolerance' must not be negative")
+	}
+
+	var err error
+	if d.ignoreTags, err = filter.Compile(d.IgnoreTags); err != nil {
+		return err
+	}
+	if d.ignoreFields, err = filter.Compile(d.IgnoreFields); err != nil {
+		return err
+	}
+
+	d.cache = expirable.NewLRU[uint64, time.Time](d.CacheSize, nil, time.Duration(d.TTL))
+
+	return nil
+}
+
+func (d *Deduplicate) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := in[:0]
+	for _, m := range in {
+		if d.isDuplicate(m) {
+			d.Log.Tracef("Dropping duplicate metric %q", m.Name())
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// isDuplicate checks if the metric was seen before and remembers it otherwise.
+// With a timestamp tolerance, the timestamps are grouped into windows of the
+// tolerance's size, so duplicates are either found in the window of the
+// metric or in one of the neighboring windows.
+func (d *Deduplicate) isDuplicate(m telegraf.Metric) bool {
+	content := d.contentHash(m)
+	ts := m.Time()
+
+	tolerance := time.Duration(d.TimestampTolerance)
+	if tolerance == 0 {
+		key := windowKey(content, ts.UnixNano())
+		if _, found := d.cache.Get(key); found {
+			return true
+		}
+		d.cache.Add(key, ts)
+		return false
+	}
+
+	window := ts.UnixNano() / int64(tolerance)
+	key := windowKey(content, window)
+	if _, found := d.cache.Get(key); found {
+		return true
+	}
+	for _, neighbor := range []int64{window - 1, window + 1} {
+		if seen, found := d.cache.Get(windowKey(content, neighbor)); found {
+			if diff := ts.Sub(seen).Abs(); diff <= tolerance {
+				return true
+			}
+		}
+	}
+	d.cache.Add(key, ts)
+	return false
+}
+
+// contentHash computes a hash over the name, tags and fields of the metric
+// excluding the ignored tags and fields
+func (d *Deduplicate) contentHash(m telegraf.Metric) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(m.Name()))
+	h.Write([]byte{0})
+
+	// Tags are sorted by key
+	for _, tag := range m.TagList() {
+		if d.ignoreTags != nil && d.ignoreTags.Match(tag.Key) {
+			continue
+		}
+		h.Write([]byte(tag.Key))
+		h.Write([]byte{0})
+		h.Write([]byte(tag.Value))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte{0})
+
+	fields := make([]*telegraf.Field, 0, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		if d.ignoreFields != nil && d.ignoreFields.Match(field.Key) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	slices.SortFunc(fields, func(a, b *telegraf.Field) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	buf := make([]byte, 9)
+	for _, field := range fields {
+		h.Write([]byte(field.Key))
+		h.Write([]byte{0})
+		switch v := field.Value.(type) {
+		case int64:
+			buf[0] = 'i'
+			binary.LittleEndian.PutUint64(buf[1:], uint64(v))
+			h.Write(buf)
+		case uint64:
+			buf[0] = 'u'
+			binary.LittleEndian.PutUint64(buf[1:], v)
+			h.Write(buf)
+		case float64:
+			buf[0] = 'f'
+			binary.LittleEndian.PutUint64(buf[1:], math.Float64bits(v))
+			h.Write(buf)
+		case bool:
+			buf[0], buf[1] = 'b', 0
+			if v {
+				buf[1] = 1
+			}
+			h.Write(buf[:2])
+		case string:
+			h.Write([]byte{'s'})
+			h.Write([]byte(v))
+			h.Write([]byte{0})
+		}
+	}
+
+	return h.Sum64()
+}
+
+// windowKey combines the content hash with the time window of the metric
+func windowKey(content uint64, window int64) uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 16)
+	binary.LittleEndian.PutUint64(buf[:8], content)
+	binary.LittleEndian.PutUint64(buf[8:], uint64(window))
+	h.Write(buf)
+	return h.Sum64()
+}
+
+func init() {
+	processors.Add("deduplicate", func() telegraf.Processor {
+		return &Deduplicate{
+			CacheSize: 100000,
+			TTL:       config.Duration(10 * time.Minute),
+		}
+	})
+}
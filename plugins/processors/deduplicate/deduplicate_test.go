@@ -0,0 +1,210 @@
+package deduplicate
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestCases(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name     string
+		plugin   *Deduplicate
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "exact duplicates",
+			plugin: &Deduplicate{},
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"idle": 42}, now),
+				metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"idle": 42}, now),
+				metric.New("cpu", map[string]string{"cpu": "cpu1"}, map[string]interface{}{"idle": 42}, now),
+				metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"idle": 43}, now),
+				metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"idle": 42}, now.Add(time.Second)),
+				metric.New("mem", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"idle": 42}, now),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"idle": 42}, now),
+				metric.New("cpu", map[string]string{"cpu": "cpu1"}, map[string]interface{}{"idle": 42}, now),
+				metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"idle": 43}, now),
+				metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"idle": 42}, now.Add(time.Second)),
+				metric.New("mem", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"idle": 42}, now),
+			},
+		},
+		{
+			name:   "field order and types",
+			plugin: &Deduplicate{},
+			input: []telegraf.Metric{
+				metric.New("m", nil, map[string]interface{}{"a": int64(1), "b": "x"}, now),
+				metric.New("m", nil, map[string]interface{}{"b": "x", "a": int64(1)}, now),
+				metric.New("m", nil, map[string]interface{}{"a": uint64(1), "b": "x"}, now),
+				metric.New("m", nil, map[string]interface{}{"a": float64(1), "b": "x"}, now),
+				metric.New("m", nil, map[string]interface{}{"a": true, "b": "x"}, now),
+				metric.New("m", nil, map[string]interface{}{"a": false, "b": "x"}, now),
+			},
+			expected: []telegraf.Metric{
+				metric.New("m", nil, map[string]interface{}{"a": int64(1), "b": "x"}, now),
+				metric.New("m", nil, map[string]interface{}{"a": uint64(1), "b": "x"}, now),
+				metric.New("m", nil, map[string]interface{}{"a": float64(1), "b": "x"}, now),
+				metric.New("m", nil, map[string]interface{}{"a": true, "b": "x"}, now),
+				metric.New("m", nil, map[string]interface{}{"a": false, "b": "x"}, now),
+			},
+		},
+		{
+			name:   "ignored tags and fields",
+			plugin: &Deduplicate{IgnoreTags: []string{"host"}, IgnoreFields: []string{"collector_*"}},
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"idle": 42, "collector_ms": 3}, now),
+				metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"idle": 42, "collector_ms": 5}, now),
+				metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"idle": 44, "collector_ms": 5}, now),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"idle": 42, "collector_ms": 3}, now),
+				metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"idle": 44, "collector_ms": 5}, now),
+			},
+		},
+		{
+			name:   "timestamp tolerance",
+			plugin: &Deduplicate{TimestampTolerance: config.Duration(time.Second)},
+			input: []telegraf.Metric{
+				metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now.Add(900*time.Millisecond)),
+				metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now.Add(1100*time.Millisecond)),
+				metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now.Add(1900*time.Millisecond)),
+				metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now.Add(100*time.Millisecond)),
+				metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now.Add(2100*time.Millisecond)),
+				metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now.Add(-200*time.Millisecond)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now.Add(900*time.Millisecond)),
+				metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now.Add(2100*time.Millisecond)),
+				metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now.Add(-200*time.Millisecond)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.CacheSize = 100
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			actual := tt.plugin.Apply(tt.input...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	now := time.Now()
+	plugin := &Deduplicate{CacheSize: 2, Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("a", nil, map[string]interface{}{"value": 1}, now),
+		metric.New("b", nil, map[string]interface{}{"value": 1}, now),
+		metric.New("c", nil, map[string]interface{}{"value": 1}, now),
+		metric.New("a", nil, map[string]interface{}{"value": 1}, now),
+		metric.New("c", nil, map[string]interface{}{"value": 1}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("a", nil, map[string]interface{}{"value": 1}, now),
+		metric.New("b", nil, map[string]interface{}{"value": 1}, now),
+		metric.New("c", nil, map[string]interface{}{"value": 1}, now),
+		metric.New("a", nil, map[string]interface{}{"value": 1}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestTTL(t *testing.T) {
+	now := time.Now()
+	plugin := &Deduplicate{
+		CacheSize: 10,
+		TTL:       config.Duration(50 * time.Millisecond),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now)
+	require.Len(t, plugin.Apply(m.Copy()), 1)
+	require.Empty(t, plugin.Apply(m.Copy()))
+	require.Eventually(t, func() bool {
+		return len(plugin.Apply(m.Copy())) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Deduplicate
+		expected string
+	}{
+		{
+			name:     "zero cache size",
+			plugin:   &Deduplicate{},
+			expected: "'cache_size' must be positive",
+		},
+		{
+			name:     "negative ttl",
+			plugin:   &Deduplicate{CacheSize: 1, TTL: config.Duration(-time.Second)},
+			expected: "'ttl' must not be negative",
+		},
+		{
+			name:     "negative tolerance",
+			plugin:   &Deduplicate{CacheSize: 1, TimestampTolerance: config.Duration(-time.Second)},
+			expected: "'timestamp_tolerance' must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	now := time.Now()
+	inputRaw := []telegraf.Metric{
+		metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now),
+		metric.New("cpu", nil, map[string]interface{}{"idle": 42}, now),
+		metric.New("cpu", nil, map[string]interface{}{"idle": 43}, now),
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Deduplicate{CacheSize: 10, Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 2)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
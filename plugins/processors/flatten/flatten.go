@@ -0,0 +1,225 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package flatten
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Flatten struct {
+	Mode         string          `toml:"mode"`
+	Fields       []string        `toml:"fields"`
+	Separator    string          `toml:"separator"`
+	MaxDepth     int             `toml:"max_depth"`
+	KeepOriginal bool            `toml:"keep_original"`
+	IndexTag     string          `toml:"index_tag"`
+	Log          telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+}
+
+func (*Flatten) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *Flatten) Init() error {
+	switch f.Mode {
+	case "":
+		f.Mode = "flatten"
+	case "flatten", "explode":
+	default:
+		return fmt.Errorf("invalid mode %q", f.Mode)
+	}
+
+	if f.MaxDepth < 0 {
+		return errors.New("'max_depth' must not be negative")
+	}
+
+	var err error
+	if f.fieldFilter, err = filter.Compile(f.Fields); err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+
+	return nil
+}
+
+func (f *Flatten) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	if f.Mode == "explode" {
+		out := make([]telegraf.Metric, 0, len(in))
+		for _, m := range in {
+			out = append(out, f.explode(m)...)
+		}
+		return out
+	}
+
+	for _, m := range in {
+		f.flatten(m)
+	}
+	return in
+}
+
+// flatten replaces the JSON object and array fields of the metric by the
+// flattened leaf values
+func (f *Flatten) flatten(m telegraf.Metric) {
+	for _, field := range m.FieldList() {
+		value, ok := f.decode(field)
+		if !ok {
+			continue
+		}
+		if _, isObject := value.(map[string]interface{}); !isObject {
+			if _, isArray := value.([]interface{}); !isArray {
+				continue
+			}
+		}
+
+		if !f.KeepOriginal {
+			m.RemoveField(field.Key)
+		}
+		f.addFlattened(m, field.Key, value, 1)
+	}
+}
+
+// explode splits the metric into one metric per element of its JSON array
+// fields. If there are multiple array fields, the n-th metric contains the
+// n-th element of each of those arrays.
+func (f *Flatten) explode(m telegraf.Metric) []telegraf.Metric {
+	arrays := make(map[string][]interface{})
+	var n int
+	for _, field := range m.FieldList() {
+		value, ok := f.decode(field)
+		if !ok {
+			continue
+		}
+		array, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		arrays[field.Key] = array
+		n = max(n, len(array))
+	}
+	if len(arrays) == 0 {
+		return []telegraf.Metric{m}
+	}
+
+	if !f.KeepOriginal {
+		for key := range arrays {
+			m.RemoveField(key)
+		}
+	}
+
+	// Keep the remaining fields of metrics with empty arrays only
+	if n == 0 {
+		if len(m.FieldList()) == 0 {
+			m.Drop()
+			return nil
+		}
+		return []telegraf.Metric{m}
+	}
+
+	out := make([]telegraf.Metric, 0, n)
+	for i := 0; i < n; i++ {
+		element := m.Copy()
+		for key, array := range arrays {
+			if i < len(array) {
+				f.addFlattened(element, key, array[i], 1)
+			}
+		}
+		if f.IndexTag != "" {
+			element.AddTag(f.IndexTag, strconv.Itoa(i))
+		}
+		out = append(out, element)
+	}
+	m.Drop()
+
+	return out
+}
+
+// decode returns the decoded value of string fields selected for processing
+// containing a JSON object or array
+func (f *Flatten) decode(field *telegraf.Field) (interface{}, bool) {
+	if f.fieldFilter != nil && !f.fieldFilter.Match(field.Key) {
+		return nil, false
+	}
+	s, ok := field.Value.(string)
+	if !ok {
+		return nil, false
+	}
+	data := bytes.TrimSpace([]byte(s))
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		f.Log.Debugf("Decoding field %q failed: %v", field.Key, err)
+		return nil, false
+	}
+	return value, true
+}
+
+// addFlattened adds the given value to the metric using the path as field
+// name, descending into objects and arrays up to the maximum depth
+func (f *Flatten) addFlattened(m telegraf.Metric, path string, value interface{}, depth int) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if f.MaxDepth > 0 && depth > f.MaxDepth {
+			f.addEncoded(m, path, v)
+			return
+		}
+		for key, child := range v {
+			f.addFlattened(m, path+f.Separator+key, child, depth+1)
+		}
+	case []interface{}:
+		if f.MaxDepth > 0 && depth > f.MaxDepth {
+			f.addEncoded(m, path, v)
+			return
+		}
+		for i, child := range v {
+			f.addFlattened(m, path+f.Separator+strconv.Itoa(i), child, depth+1)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			m.AddField(path, i)
+		} else if fv, err := v.Float64(); err == nil {
+			m.AddField(path, fv)
+		} else {
+			m.AddField(path, v.String())
+		}
+	case nil:
+		// Skip null values
+	default:
+		m.AddField(path, v)
+	}
+}
+
+// addEncoded adds the value nested too deep as JSON string
+func (f *Flatten) addEncoded(m telegraf.Metric, path string, value interface{}) {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		f.Log.Debugf("Encoding field %q failed: %v", path, err)
+		return
+	}
+	m.AddField(path, string(buf))
+}
+
+func init() {
+	processors.Add("flatten", func() telegraf.Processor {
+		return &Flatten{
+			Mode:      "flatten",
+			Separator: ".",
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package flatten
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestFlatten(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		plugin   *Flatten
+		input    telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "object",
+			plugin: &Flatten{},
+			input: metric.New("http",
+				map[string]string{"host": "a"},
+				map[string]interface{}{
+					"response": `{"status":{"code":200,"text":"OK"},"timings":[0.1,0.3],"error":null}`,
+					"other":    "text",
+				},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New("http",
+					map[string]string{"host": "a"},
+					map[string]interface{}{
+						"response.status.code": int64(200),
+						"response.status.text": "OK",
+						"response.timings.0":   0.1,
+						"response.timings.1":   0.3,
+						"other":                "text",
+					},
+					now,
+				),
+			},
+		},
+		{
+			name:   "max depth and separator",
+			plugin: &Flatten{MaxDepth: 1, Separator: "_"},
+			input: metric.New("m",
+				nil,
+				map[string]interface{}{"value": `{"a":{"b":{"c":true}},"d":"x"}`},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New("m",
+					nil,
+					map[string]interface{}{"value_a": `{"b":{"c":true}}`, "value_d": "x"},
+					now,
+				),
+			},
+		},
+		{
+			name:   "keep original and filter",
+			plugin: &Flatten{Fields: []string{"a*"}, KeepOriginal: true},
+			input: metric.New("m",
+				nil,
+				map[string]interface{}{"abc": `[1,2]`, "def": `[3,4]`},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New("m",
+					nil,
+					map[string]interface{}{"abc": `[1,2]`, "abc.0": int64(1), "abc.1": int64(2), "def": `[3,4]`},
+					now,
+				),
+			},
+		},
+		{
+			name:   "invalid json",
+			plugin: &Flatten{},
+			input: metric.New("m",
+				nil,
+				map[string]interface{}{"value": `{"a":`, "number": 42},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New("m",
+					nil,
+					map[string]interface{}{"value": `{"a":`, "number": 42},
+					now,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.plugin.Separator == "" {
+				tt.plugin.Separator = "."
+			}
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			actual := tt.plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestExplode(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		plugin   *Flatten
+		input    telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "objects",
+			plugin: &Flatten{IndexTag: "index"},
+			input: metric.New("disks",
+				map[string]string{"host": "a"},
+				map[string]interface{}{
+					"count": 2,
+					"disks": `[{"name":"sda","used":10},{"name":"sdb","used":20.5}]`,
+				},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New("disks",
+					map[string]string{"host": "a", "index": "0"},
+					map[string]interface{}{"count": 2, "disks.name": "sda", "disks.used": int64(10)},
+					now,
+				),
+				metric.New("disks",
+					map[string]string{"host": "a", "index": "1"},
+					map[string]interface{}{"count": 2, "disks.name": "sdb", "disks.used": 20.5},
+					now,
+				),
+			},
+		},
+		{
+			name:   "multiple arrays",
+			plugin: &Flatten{},
+			input: metric.New("m",
+				nil,
+				map[string]interface{}{"a": `[1,2,3]`, "b": `["x"]`, "c": `{"d":1}`},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New("m", nil, map[string]interface{}{"a": int64(1), "b": "x", "c": `{"d":1}`}, now),
+				metric.New("m", nil, map[string]interface{}{"a": int64(2), "c": `{"d":1}`}, now),
+				metric.New("m", nil, map[string]interface{}{"a": int64(3), "c": `{"d":1}`}, now),
+			},
+		},
+		{
+			name:   "empty array",
+			plugin: &Flatten{},
+			input: metric.New("m",
+				nil,
+				map[string]interface{}{"a": `[]`, "b": 1},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New("m", nil, map[string]interface{}{"b": 1}, now),
+			},
+		},
+		{
+			name:   "no array",
+			plugin: &Flatten{},
+			input: metric.New("m",
+				nil,
+				map[string]interface{}{"a": "x"},
+				now,
+			),
+			expected: []telegraf.Metric{
+				metric.New("m", nil, map[string]interface{}{"a": "x"}, now),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Mode = "explode"
+			tt.plugin.Separator = "."
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			actual := tt.plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Flatten{Mode: "foo"}
+	require.ErrorContains(t, plugin.Init(), `invalid mode "foo"`)
+
+	plugin = &Flatten{MaxDepth: -1}
+	require.ErrorContains(t, plugin.Init(), "'max_depth' must not be negative")
+}
+
+func TestTracking(t *testing.T) {
+	now := time.Now()
+	inputRaw := []telegraf.Metric{
+		metric.New("m", nil, map[string]interface{}{"a": `[1,2,3]`}, now),
+		metric.New("m", nil, map[string]interface{}{"a": `[]`}, now),
+		metric.New("m", nil, map[string]interface{}{"a": 1}, now),
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Flatten{Mode: "explode", Separator: ".", Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 4)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
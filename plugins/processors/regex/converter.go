@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
+	"sync"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
@@ -35,27 +37,65 @@ func (ct converterType) String() string {
 	return fmt.Sprintf("unknown %d", int(ct))
 }
 
+// Compiled patterns shared by all converters to avoid compiling the same
+// pattern multiple times
+var (
+	patterns     = make(map[string]*regexp.Regexp)
+	patternsLock sync.Mutex
+)
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	patternsLock.Lock()
+	defer patternsLock.Unlock()
+
+	if re, found := patterns[pattern]; found {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns[pattern] = re
+	return re, nil
+}
+
 type converter struct {
-	Key         string `toml:"key"`
-	Pattern     string `toml:"pattern"`
-	Replacement string `toml:"replacement"`
-	ResultKey   string `toml:"result_key"`
-	Append      bool   `toml:"append"`
-
-	filter filter.Filter
-	re     *regexp.Regexp
-	groups []string
-	apply  func(m telegraf.Metric)
+	Key              string   `toml:"key"`
+	Pattern          string   `toml:"pattern"`
+	Replacement      string   `toml:"replacement"`
+	ResultKey        string   `toml:"result_key"`
+	Append           bool     `toml:"append"`
+	TagGroups        []string `toml:"tag_groups"`
+	FieldGroups      []string `toml:"field_groups"`
+	ConditionTag     string   `toml:"condition_tag"`
+	ConditionPattern string   `toml:"condition_pattern"`
+
+	filter      filter.Filter
+	re          *regexp.Regexp
+	conditionRe *regexp.Regexp
+	groups      []string
+	groupFields []bool
+	apply       func(m telegraf.Metric)
 }
 
 func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 	// Compile the pattern
-	re, err := regexp.Compile(c.Pattern)
+	re, err := compile(c.Pattern)
 	if err != nil {
 		return err
 	}
 	c.re = re
 
+	// Setup the condition
+	if c.ConditionPattern != "" {
+		if c.ConditionTag == "" {
+			return errors.New("'condition_pattern' requires 'condition_tag'")
+		}
+		if c.conditionRe, err = compile(c.ConditionPattern); err != nil {
+			return fmt.Errorf("invalid 'condition_pattern': %w", err)
+		}
+	}
+
 	switch ct {
 	case convertTags, convertFields:
 		if c.Key == "" {
@@ -80,6 +120,9 @@ func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 			if allNamed {
 				log.Debugf("%s: Using named-group mode...", ct)
 				c.groups = groups[1:]
+				if err := c.setupGroupTypes(ct); err != nil {
+					return err
+				}
 			} else {
 				msg := "Neither 'result_key' nor 'replacement' given with unnamed or mixed groups;"
 				msg += " using explicit, empty replacement!"
@@ -88,6 +131,9 @@ func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 		} else {
 			log.Debugf("%s: Using explicit mode...", ct)
 		}
+		if c.groups == nil && (len(c.TagGroups) > 0 || len(c.FieldGroups) > 0) {
+			return errors.New("'tag_groups' and 'field_groups' require named-group mode")
+		}
 	case convertTagRename, convertFieldRename:
 		switch c.ResultKey {
 		case "":
@@ -116,27 +162,78 @@ func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 	return nil
 }
 
+// setupGroupTypes determines if the named groups result in tags or fields
+// defaulting to the type of the converter
+func (c *converter) setupGroupTypes(ct converterType) error {
+	for _, name := range append(slices.Clone(c.TagGroups), c.FieldGroups...) {
+		if !slices.Contains(c.groups, name) {
+			return fmt.Errorf("unknown group %q", name)
+		}
+	}
+
+	c.groupFields = make([]bool, len(c.groups))
+	for i, name := range c.groups {
+		switch {
+		case slices.Contains(c.FieldGroups, name):
+			c.groupFields[i] = true
+		case slices.Contains(c.TagGroups, name):
+			c.groupFields[i] = false
+		default:
+			c.groupFields[i] = ct == convertFields
+		}
+	}
+	return nil
+}
+
+// process applies the conversion to the metric if the condition is met
+func (c *converter) process(m telegraf.Metric) {
+	if c.ConditionTag != "" {
+		value, found := m.GetTag(c.ConditionTag)
+		if !found || (c.conditionRe != nil && !c.conditionRe.MatchString(value)) {
+			return
+		}
+	}
+	c.apply(m)
+}
+
+// applyGroups adds the content of the named groups matching the value as
+// tags or fields using a single match
+func (c *converter) applyGroups(m telegraf.Metric, value string) {
+	matches := c.re.FindStringSubmatch(value)
+	if matches == nil {
+		return
+	}
+	for i, match := range matches[1:] {
+		if match == "" {
+			continue
+		}
+		name := c.groups[i]
+		if c.groupFields[i] {
+			m.AddField(name, match)
+			continue
+		}
+		if c.Append {
+			if v, ok := m.GetTag(name); ok {
+				match = v + match
+			}
+		}
+		m.AddTag(name, match)
+	}
+}
+
 func (c *converter) applyTags(m telegraf.Metric) {
 	for _, tag := range m.TagList() {
-		if !c.filter.Match(tag.Key) || !c.re.MatchString(tag.Value) {
+		if !c.filter.Match(tag.Key) {
 			continue
 		}
 
 		// Handle named groups
 		if len(c.groups) > 0 {
-			matches := c.re.FindStringSubmatch(tag.Value)
-			for i, match := range matches[1:] {
-				if match == "" {
-					continue
-				}
-				name := c.groups[i]
-				if c.Append {
-					if v, ok := m.GetTag(name); ok {
-						match = v + match
-					}
-				}
-				m.AddTag(name, match)
-			}
+			c.applyGroups(m, tag.Value)
+			continue
+		}
+
+		if !c.re.MatchString(tag.Value) {
 			continue
 		}
 
@@ -163,25 +260,17 @@ func (c *converter) applyFields(m telegraf.Metric) {
 		}
 
 		value, ok := field.Value.(string)
-		if !ok || !c.re.MatchString(value) {
+		if !ok {
 			continue
 		}
 
 		// Handle named groups
 		if len(c.groups) > 0 {
-			matches := c.re.FindStringSubmatch(value)
-			for i, match := range matches[1:] {
-				if match == "" {
-					continue
-				}
-				name := c.groups[i]
-				if c.Append {
-					if v, ok := m.GetTag(name); ok {
-						match = v + match
-					}
-				}
-				m.AddField(name, match)
-			}
+			c.applyGroups(m, value)
+			continue
+		}
+
+		if !c.re.MatchString(value) {
 			continue
 		}
 
@@ -77,23 +77,23 @@ func (r *Regex) Init() error {
 func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	for _, metric := range in {
 		for _, c := range r.Tags {
-			c.apply(metric)
+			c.process(metric)
 		}
 
 		for _, c := range r.Fields {
-			c.apply(metric)
+			c.process(metric)
 		}
 
 		for _, c := range r.TagRename {
-			c.apply(metric)
+			c.process(metric)
 		}
 
 		for _, c := range r.FieldRename {
-			c.apply(metric)
+			c.process(metric)
 		}
 
 		for _, c := range r.MetricRename {
-			c.apply(metric)
+			c.process(metric)
 		}
 	}
 
@@ -820,6 +820,122 @@ func TestNamedGroups(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual)
 }
 
+func TestNamedGroupsMixedTypes(t *testing.T) {
+	regex := Regex{
+		Fields: []converter{
+			{
+				Key:         "message",
+				Pattern:     `^(?P<level>[A-Z]+) \[(?P<component>\w+)\] took (?P<duration>\d+)ms$`,
+				TagGroups:   []string{"level", "component"},
+				FieldGroups: []string{"duration"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, regex.Init())
+
+	input := metric.New(
+		"log",
+		map[string]string{},
+		map[string]interface{}{"message": "WARN [db] took 42ms"},
+		time.Unix(1695243874, 0),
+	)
+	expected := []telegraf.Metric{
+		metric.New(
+			"log",
+			map[string]string{"level": "WARN", "component": "db"},
+			map[string]interface{}{"message": "WARN [db] took 42ms", "duration": "42"},
+			time.Unix(1695243874, 0),
+		),
+	}
+	actual := regex.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestConditions(t *testing.T) {
+	regex := Regex{
+		Tags: []converter{
+			{
+				Key:              "resp_code",
+				Pattern:          `^(\d)\d\d$`,
+				Replacement:      "${1}xx",
+				ConditionTag:     "verb",
+				ConditionPattern: "^(GET|POST)$",
+			},
+		},
+		MetricRename: []converter{
+			{
+				Pattern:      "^access_log$",
+				Replacement:  "api_log",
+				ConditionTag: "api",
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, regex.Init())
+
+	now := time.Unix(1695243874, 0)
+	input := []telegraf.Metric{
+		metric.New("access_log", map[string]string{"verb": "GET", "resp_code": "200"}, map[string]interface{}{"v": 1}, now),
+		metric.New("access_log", map[string]string{"verb": "PUT", "resp_code": "200", "api": "v1"}, map[string]interface{}{"v": 1}, now),
+		metric.New("access_log", map[string]string{"resp_code": "200"}, map[string]interface{}{"v": 1}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("access_log", map[string]string{"verb": "GET", "resp_code": "2xx"}, map[string]interface{}{"v": 1}, now),
+		metric.New("api_log", map[string]string{"verb": "PUT", "resp_code": "200", "api": "v1"}, map[string]interface{}{"v": 1}, now),
+		metric.New("access_log", map[string]string{"resp_code": "200"}, map[string]interface{}{"v": 1}, now),
+	}
+	actual := regex.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		converter converter
+		expected  string
+	}{
+		{
+			name:      "condition pattern without tag",
+			converter: converter{Key: "a", Pattern: "^a$", Replacement: "b", ConditionPattern: "x"},
+			expected:  "'condition_pattern' requires 'condition_tag'",
+		},
+		{
+			name:      "invalid condition pattern",
+			converter: converter{Key: "a", Pattern: "^a$", Replacement: "b", ConditionTag: "x", ConditionPattern: "("},
+			expected:  "invalid 'condition_pattern'",
+		},
+		{
+			name:      "unknown group",
+			converter: converter{Key: "a", Pattern: "^(?P<x>a)$", FieldGroups: []string{"y"}},
+			expected:  `unknown group "y"`,
+		},
+		{
+			name:      "groups without named-group mode",
+			converter: converter{Key: "a", Pattern: "^(a)$", Replacement: "b", TagGroups: []string{"x"}},
+			expected:  "'tag_groups' and 'field_groups' require named-group mode",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regex := Regex{Tags: []converter{tt.converter}, Log: testutil.Logger{}}
+			require.ErrorContains(t, regex.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPatternReuse(t *testing.T) {
+	regex := Regex{
+		Tags: []converter{
+			{Key: "a", Pattern: `^(\d)\d\d$`, Replacement: "${1}xx"},
+			{Key: "b", Pattern: `^(\d)\d\d$`, Replacement: "${1}xx"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, regex.Init())
+	require.Same(t, regex.Tags[0].re, regex.Tags[1].re)
+}
+
 func TestNoMatches(t *testing.T) {
 	tests := []struct {
 		message        string
@@ -0,0 +1,110 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package sampling
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// resolution is the number of distinct sampling buckets, allowing
+// percentages with up to four decimal places
+const resolution = 1_000_000
+
+type Sampling struct {
+	Percentage float64         `toml:"percentage"`
+	Mode       string          `toml:"mode"`
+	Tags       []string        `toml:"tags"`
+	Log        telegraf.Logger `toml:"-"`
+
+	threshold uint64
+}
+
+func (*Sampling) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Sampling) Init() error {
+	if s.Percentage <= 0 || s.Percentage > 100 {
+		return errors.New("'percentage' must be in the range (0, 100]")
+	}
+	s.threshold = uint64(s.Percentage / 100 * resolution)
+
+	switch s.Mode {
+	case "":
+		s.Mode = "hash"
+	case "hash":
+	case "random":
+		if len(s.Tags) > 0 {
+			s.Log.Warn("Option 'tags' is ignored in random mode")
+		}
+	default:
+		return fmt.Errorf("invalid 'mode' %q", s.Mode)
+	}
+
+	return nil
+}
+
+func (s *Sampling) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		if s.keep(m) {
+			out = append(out, m)
+		} else {
+			m.Drop()
+		}
+	}
+	return out
+}
+
+func (s *Sampling) keep(m telegraf.Metric) bool {
+	var n uint64
+	switch s.Mode {
+	case "random":
+		n = rand.Uint64N(resolution)
+	default:
+		n = mix(s.hash(m)) % resolution
+	}
+	return n < s.threshold
+}
+
+// hash computes the hash used for the sampling decision over the configured
+// tags or, if no tags are configured, the series of the metric
+func (s *Sampling) hash(m telegraf.Metric) uint64 {
+	if len(s.Tags) == 0 {
+		return m.HashID()
+	}
+
+	h := fnv.New64a()
+	for _, key := range s.Tags {
+		value, _ := m.GetTag(key)
+		h.Write([]byte(value))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// mix improves the distribution of the lower bits of the hash using the
+// finalizer of MurmurHash3
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+func init() {
+	processors.Add("sampling", func() telegraf.Processor {
+		return &Sampling{}
+	})
+}
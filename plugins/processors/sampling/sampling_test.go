@@ -0,0 +1,152 @@
+package sampling
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Sampling
+		expected string
+	}{
+		{
+			name:     "zero percentage",
+			plugin:   &Sampling{},
+			expected: "'percentage' must be in the range (0, 100]",
+		},
+		{
+			name:     "percentage too large",
+			plugin:   &Sampling{Percentage: 150},
+			expected: "'percentage' must be in the range (0, 100]",
+		},
+		{
+			name:     "invalid mode",
+			plugin:   &Sampling{Percentage: 10, Mode: "foo"},
+			expected: `invalid 'mode' "foo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestKeepAll(t *testing.T) {
+	for _, mode := range []string{"hash", "random"} {
+		t.Run(mode, func(t *testing.T) {
+			plugin := &Sampling{Percentage: 100, Mode: mode, Log: testutil.Logger{}}
+			require.NoError(t, plugin.Init())
+
+			input := generate(1000, "trace_id")
+			actual := plugin.Apply(input...)
+			require.Len(t, actual, len(input))
+		})
+	}
+}
+
+func TestPercentage(t *testing.T) {
+	for _, mode := range []string{"hash", "random"} {
+		t.Run(mode, func(t *testing.T) {
+			plugin := &Sampling{Percentage: 10, Mode: mode, Log: testutil.Logger{}}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.Apply(generate(10000, "trace_id")...)
+			require.InDelta(t, 1000, len(actual), 200)
+		})
+	}
+}
+
+func TestHashConsistent(t *testing.T) {
+	plugin := &Sampling{
+		Percentage: 25,
+		Tags:       []string{"trace_id"},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// All metrics of a trace are kept or dropped independent of their name
+	// and other tags
+	now := time.Now()
+	var kept int
+	for i := range 1000 {
+		id := strconv.Itoa(i)
+		input := []telegraf.Metric{
+			metric.New("span", map[string]string{"trace_id": id, "service": "a"}, map[string]interface{}{"value": 1}, now),
+			metric.New("span", map[string]string{"trace_id": id, "service": "b"}, map[string]interface{}{"value": 2}, now),
+			metric.New("log", map[string]string{"trace_id": id}, map[string]interface{}{"value": 3}, now),
+		}
+		actual := plugin.Apply(input...)
+		require.Contains(t, []int{0, 3}, len(actual), "trace %s partially sampled", id)
+		if len(actual) > 0 {
+			kept++
+		}
+
+		// The decision is repeatable
+		require.Len(t, plugin.Apply(input[0]), len(actual)/3)
+	}
+	require.InDelta(t, 250, kept, 60)
+}
+
+func TestHashSeries(t *testing.T) {
+	plugin := &Sampling{Percentage: 50, Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	// Without tags entire series are kept or dropped
+	now := time.Now()
+	for i := range 100 {
+		tags := map[string]string{"host": strconv.Itoa(i)}
+		first := plugin.Apply(metric.New("cpu", tags, map[string]interface{}{"value": 1}, now))
+		for j := range 10 {
+			actual := plugin.Apply(metric.New("cpu", tags, map[string]interface{}{"value": j}, now.Add(time.Duration(j)*time.Second)))
+			require.Len(t, actual, len(first))
+		}
+	}
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+
+	plugin := &Sampling{Percentage: 50, Mode: "random", Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	input := make([]telegraf.Metric, 0, 100)
+	for _, m := range generate(100, "trace_id") {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	actual := plugin.Apply(input...)
+	for _, m := range actual {
+		m.Accept()
+	}
+	require.Equal(t, 100, delivered)
+}
+
+func generate(n int, tag string) []telegraf.Metric {
+	now := time.Now()
+	metrics := make([]telegraf.Metric, 0, n)
+	for i := range n {
+		metrics = append(metrics, metric.New(
+			"test",
+			map[string]string{tag: strconv.Itoa(i)},
+			map[string]interface{}{"value": i},
+			now,
+		))
+	}
+	return metrics
+}
@@ -5,6 +5,9 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
@@ -21,9 +24,13 @@ type Split struct {
 }
 
 type template struct {
-	Name   string   `toml:"name"`
-	Tags   []string `toml:"tags"`
-	Fields []string `toml:"fields"`
+	Name           string   `toml:"name"`
+	Tags           []string `toml:"tags"`
+	Fields         []string `toml:"fields"`
+	PerField       bool     `toml:"per_field"`
+	ValueField     string   `toml:"value_field"`
+	ValueSeparator string   `toml:"value_separator"`
+	IndexTag       string   `toml:"index_tag"`
 
 	fieldFilters filter.Filter
 	tagFilters   filter.Filter
@@ -39,9 +46,15 @@ func (s *Split) Init() error {
 	}
 
 	for index, template := range s.Templates {
-		if template.Name == "" {
+		if template.Name == "" && !template.PerField {
 			return errors.New("metric name cannot be empty")
 		}
+		if template.PerField && template.ValueField == "" {
+			s.Templates[index].ValueField = "value"
+		}
+		if template.ValueSeparator != "" && template.IndexTag == "" {
+			s.Templates[index].IndexTag = "index"
+		}
 
 		if len(template.Fields) == 0 {
 			return errors.New("at least one field is required for a valid metric")
@@ -96,14 +109,78 @@ func (s *Split) Apply(in ...telegraf.Metric) []telegraf.Metric {
 				continue
 			}
 
-			m := metric.New(template.Name, tags, fields, point.Time())
-			newMetrics = append(newMetrics, m)
+			if !template.PerField {
+				newMetrics = append(newMetrics, template.create(template.Name, tags, fields, point.Time())...)
+				continue
+			}
+
+			// Create one metric per field in the order of the original fields
+			for _, field := range point.FieldList() {
+				value, found := fields[field.Key]
+				if !found {
+					continue
+				}
+				name := field.Key
+				if template.Name != "" {
+					name = template.Name + "_" + field.Key
+				}
+				fieldTags := make(map[string]string, len(tags))
+				for k, v := range tags {
+					fieldTags[k] = v
+				}
+				newFields := map[string]any{template.ValueField: value}
+				newMetrics = append(newMetrics, template.create(name, fieldTags, newFields, point.Time())...)
+			}
 		}
 	}
 
 	return newMetrics
 }
 
+// create creates the metrics for the given fields. If a value separator is
+// configured, string values are split into elements and one metric is
+// created per element, tagged with the element's index. Fields with
+// non-string values are added to all metrics.
+func (t *template) create(name string, tags map[string]string, fields map[string]any, ts time.Time) []telegraf.Metric {
+	if t.ValueSeparator == "" {
+		return []telegraf.Metric{metric.New(name, tags, fields, ts)}
+	}
+
+	elements := make(map[string][]string, len(fields))
+	var n int
+	for k, v := range fields {
+		if sv, ok := v.(string); ok {
+			parts := strings.Split(sv, t.ValueSeparator)
+			elements[k] = parts
+			n = max(n, len(parts))
+		}
+	}
+	if n == 0 {
+		return []telegraf.Metric{metric.New(name, tags, fields, ts)}
+	}
+
+	metrics := make([]telegraf.Metric, 0, n)
+	for i := range n {
+		elementTags := make(map[string]string, len(tags)+1)
+		for k, v := range tags {
+			elementTags[k] = v
+		}
+		elementTags[t.IndexTag] = strconv.Itoa(i)
+
+		elementFields := make(map[string]any, len(fields))
+		for k, v := range fields {
+			parts, found := elements[k]
+			if !found {
+				elementFields[k] = v
+			} else if i < len(parts) {
+				elementFields[k] = strings.TrimSpace(parts[i])
+			}
+		}
+		metrics = append(metrics, metric.New(name, elementTags, elementFields, ts))
+	}
+	return metrics
+}
+
 func init() {
 	processors.Add("split", func() telegraf.Processor {
 		return &Split{}
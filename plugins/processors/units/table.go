@@ -0,0 +1,90 @@
+package units
+
+import "strings"
+
+// unit describes a unit by its dimension and the conversion to the base unit
+// of the dimension, i.e. base = value * factor + offset
+type unit struct {
+	name      string
+	dimension string
+	factor    float64
+	offset    float64
+	// suffix is used as field name suffix when renaming fields
+	suffix string
+	// aliases are the alternative names of the unit also used for detecting
+	// the unit from field name suffixes
+	aliases []string
+}
+
+var units = []unit{
+	// Data with byte as base unit
+	{name: "bit", dimension: "data", factor: 1.0 / 8, suffix: "bits", aliases: []string{"bits"}},
+	{name: "B", dimension: "data", factor: 1, suffix: "bytes", aliases: []string{"byte", "bytes"}},
+	{name: "kB", dimension: "data", factor: 1e3, suffix: "kilobytes", aliases: []string{"kb", "kilobytes"}},
+	{name: "MB", dimension: "data", factor: 1e6, suffix: "megabytes", aliases: []string{"mb", "megabytes"}},
+	{name: "GB", dimension: "data", factor: 1e9, suffix: "gigabytes", aliases: []string{"gb", "gigabytes"}},
+	{name: "TB", dimension: "data", factor: 1e12, suffix: "terabytes", aliases: []string{"tb", "terabytes"}},
+	{name: "KiB", dimension: "data", factor: 1 << 10, suffix: "kib", aliases: []string{"kibibytes"}},
+	{name: "MiB", dimension: "data", factor: 1 << 20, suffix: "mib", aliases: []string{"mebibytes"}},
+	{name: "GiB", dimension: "data", factor: 1 << 30, suffix: "gib", aliases: []string{"gibibytes"}},
+	{name: "TiB", dimension: "data", factor: 1 << 40, suffix: "tib", aliases: []string{"tebibytes"}},
+
+	// Time with second as base unit
+	{name: "ns", dimension: "time", factor: 1e-9, suffix: "ns", aliases: []string{"nanoseconds"}},
+	{name: "us", dimension: "time", factor: 1e-6, suffix: "us", aliases: []string{"µs", "microseconds"}},
+	{name: "ms", dimension: "time", factor: 1e-3, suffix: "ms", aliases: []string{"milliseconds"}},
+	{name: "s", dimension: "time", factor: 1, suffix: "seconds", aliases: []string{"sec", "seconds"}},
+	{name: "min", dimension: "time", factor: 60, suffix: "minutes", aliases: []string{"minutes"}},
+	{name: "h", dimension: "time", factor: 3600, suffix: "hours", aliases: []string{"hours"}},
+	{name: "d", dimension: "time", factor: 86400, suffix: "days", aliases: []string{"days"}},
+
+	// Temperature with kelvin as base unit
+	{name: "C", dimension: "temperature", factor: 1, offset: 273.15, suffix: "celsius", aliases: []string{"°C", "celsius"}},
+	{name: "F", dimension: "temperature", factor: 5.0 / 9, offset: 273.15 - 32*5.0/9, suffix: "fahrenheit", aliases: []string{"°F", "fahrenheit"}},
+	{name: "K", dimension: "temperature", factor: 1, suffix: "kelvin", aliases: []string{"kelvin"}},
+}
+
+// lookupUnit returns the unit with the given name or alias. Aliases are
+// matched case-insensitive.
+func lookupUnit(name string) (*unit, bool) {
+	for i := range units {
+		if units[i].name == name {
+			return &units[i], true
+		}
+	}
+	for i := range units {
+		for _, alias := range units[i].aliases {
+			if strings.EqualFold(alias, name) {
+				return &units[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// detectUnit returns the unit given by the suffix of the field name, i.e.
+// the part after the last underscore, and the field name without the suffix
+func detectUnit(field string) (*unit, string, bool) {
+	idx := strings.LastIndex(field, "_")
+	if idx < 0 {
+		return nil, "", false
+	}
+	suffix := field[idx+1:]
+	for i := range units {
+		if strings.EqualFold(units[i].suffix, suffix) {
+			return &units[i], field[:idx], true
+		}
+		for _, alias := range units[i].aliases {
+			if strings.EqualFold(alias, suffix) {
+				return &units[i], field[:idx], true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// convert converts the value from one unit to another of the same dimension
+func convert(value float64, from, to *unit) float64 {
+	base := value*from.factor + from.offset
+	return (base - to.offset) / to.factor
+}
@@ -0,0 +1,158 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package units
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Units struct {
+	Rename      bool              `toml:"rename"`
+	Detect      map[string]string `toml:"detect"`
+	Conversions []conversion      `toml:"conversion"`
+	Log         telegraf.Logger   `toml:"-"`
+
+	targets map[string]*unit
+}
+
+type conversion struct {
+	Fields []string `toml:"fields"`
+	From   string   `toml:"from"`
+	To     string   `toml:"to"`
+
+	filter filter.Filter
+	from   *unit
+	to     *unit
+}
+
+func (*Units) SampleConfig() string {
+	return sampleConfig
+}
+
+func (u *Units) Init() error {
+	u.targets = make(map[string]*unit, len(u.Detect))
+	for dimension, name := range u.Detect {
+		target, found := lookupUnit(name)
+		if !found {
+			return fmt.Errorf("unknown unit %q", name)
+		}
+		if target.dimension != dimension {
+			return fmt.Errorf("unit %q is not of dimension %q", name, dimension)
+		}
+		u.targets[dimension] = target
+	}
+
+	for i, c := range u.Conversions {
+		if len(c.Fields) == 0 {
+			return errors.New("'fields' required for conversion")
+		}
+		f, err := filter.Compile(c.Fields)
+		if err != nil {
+			return fmt.Errorf("creating field filter failed: %w", err)
+		}
+		u.Conversions[i].filter = f
+
+		from, found := lookupUnit(c.From)
+		if !found {
+			return fmt.Errorf("unknown unit %q", c.From)
+		}
+		to, found := lookupUnit(c.To)
+		if !found {
+			return fmt.Errorf("unknown unit %q", c.To)
+		}
+		if from.dimension != to.dimension {
+			return fmt.Errorf("cannot convert %q to %q", c.From, c.To)
+		}
+		u.Conversions[i].from = from
+		u.Conversions[i].to = to
+	}
+
+	return nil
+}
+
+func (u *Units) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		// Collect the conversions first as renaming modifies the field list
+		type pending struct {
+			key      string
+			value    float64
+			from, to *unit
+		}
+		var conversions []pending
+		for _, field := range m.FieldList() {
+			from, to, ok := u.units(field.Key)
+			if !ok {
+				continue
+			}
+			if _, isBool := field.Value.(bool); isBool {
+				continue
+			}
+			value, err := internal.ToFloat64(field.Value)
+			if err != nil {
+				continue
+			}
+			conversions = append(conversions, pending{key: field.Key, value: value, from: from, to: to})
+		}
+
+		for _, c := range conversions {
+			value := convert(c.value, c.from, c.to)
+			key := c.key
+			if u.Rename {
+				key = rename(key, c.from, c.to)
+				if key != c.key {
+					m.RemoveField(c.key)
+				}
+			}
+			m.AddField(key, value)
+		}
+	}
+	return in
+}
+
+// units returns the source and target unit of the field using the explicit
+// conversions first and the field's unit suffix second
+func (u *Units) units(field string) (*unit, *unit, bool) {
+	for _, c := range u.Conversions {
+		if c.filter.Match(field) {
+			return c.from, c.to, true
+		}
+	}
+
+	if len(u.targets) == 0 {
+		return nil, nil, false
+	}
+	from, _, found := detectUnit(field)
+	if !found {
+		return nil, nil, false
+	}
+	to, found := u.targets[from.dimension]
+	if !found || to == from {
+		return nil, nil, false
+	}
+	return from, to, true
+}
+
+// rename replaces the unit suffix of the field name by the suffix of the
+// target unit, fields without a suffix of the source unit are not renamed
+func rename(field string, from, to *unit) string {
+	detected, base, found := detectUnit(field)
+	if !found || detected != from {
+		return field
+	}
+	return base + "_" + to.suffix
+}
+
+func init() {
+	processors.Add("units", func() telegraf.Processor {
+		return &Units{Rename: true}
+	})
+}
@@ -0,0 +1,206 @@
+package units
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestConversions(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		plugin   *Units
+		input    telegraf.Metric
+		expected telegraf.Metric
+	}{
+		{
+			name: "detect",
+			plugin: &Units{
+				Rename: true,
+				Detect: map[string]string{"data": "MiB", "time": "s", "temperature": "C"},
+			},
+			input: metric.New("app",
+				map[string]string{"host": "a"},
+				map[string]interface{}{
+					"mem_used_bytes":  int64(1048576),
+					"latency_ms":      uint64(250),
+					"temp_fahrenheit": 212.0,
+					"status":          "ok",
+					"uptime":          int64(42),
+				},
+				now,
+			),
+			expected: metric.New("app",
+				map[string]string{"host": "a"},
+				map[string]interface{}{
+					"mem_used_mib":    1.0,
+					"latency_seconds": 0.25,
+					"temp_celsius":    100.0,
+					"status":          "ok",
+					"uptime":          int64(42),
+				},
+				now,
+			),
+		},
+		{
+			name: "detect without rename",
+			plugin: &Units{
+				Detect: map[string]string{"time": "ms"},
+			},
+			input: metric.New("app",
+				nil,
+				map[string]interface{}{"latency_seconds": 1.5, "wait_ms": int64(3)},
+				now,
+			),
+			expected: metric.New("app",
+				nil,
+				map[string]interface{}{"latency_seconds": 1500.0, "wait_ms": int64(3)},
+				now,
+			),
+		},
+		{
+			name: "explicit conversion",
+			plugin: &Units{
+				Rename: true,
+				Conversions: []conversion{
+					{Fields: []string{"mem_*"}, From: "B", To: "GiB"},
+					{Fields: []string{"temp"}, From: "celsius", To: "F"},
+				},
+			},
+			input: metric.New("app",
+				nil,
+				map[string]interface{}{
+					"mem_total":       int64(2147483648),
+					"mem_free_bytes":  int64(1073741824),
+					"temp":            100,
+					"flag_bytes":      true,
+					"disk_used_bytes": int64(10),
+				},
+				now,
+			),
+			expected: metric.New("app",
+				nil,
+				map[string]interface{}{
+					"mem_total":       2.0,
+					"mem_free_gib":    1.0,
+					"temp":            212.0,
+					"flag_bytes":      true,
+					"disk_used_bytes": int64(10),
+				},
+				now,
+			),
+		},
+		{
+			name: "conversion takes precedence",
+			plugin: &Units{
+				Rename:      true,
+				Detect:      map[string]string{"time": "s"},
+				Conversions: []conversion{{Fields: []string{"boot_ms"}, From: "ms", To: "min"}},
+			},
+			input: metric.New("app",
+				nil,
+				map[string]interface{}{"boot_ms": int64(90000), "wait_ms": int64(500)},
+				now,
+			),
+			expected: metric.New("app",
+				nil,
+				map[string]interface{}{"boot_minutes": 1.5, "wait_seconds": 0.5},
+				now,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.plugin.Init())
+			actual := tt.plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual, cmpopts.EquateApprox(0, 1e-9))
+		})
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Units
+		expected string
+	}{
+		{
+			name:     "unknown detect unit",
+			plugin:   &Units{Detect: map[string]string{"data": "furlong"}},
+			expected: `unknown unit "furlong"`,
+		},
+		{
+			name:     "wrong detect dimension",
+			plugin:   &Units{Detect: map[string]string{"data": "s"}},
+			expected: `unit "s" is not of dimension "data"`,
+		},
+		{
+			name:     "missing fields",
+			plugin:   &Units{Conversions: []conversion{{From: "B", To: "MiB"}}},
+			expected: "'fields' required for conversion",
+		},
+		{
+			name:     "unknown conversion unit",
+			plugin:   &Units{Conversions: []conversion{{Fields: []string{"a"}, From: "B", To: "parsec"}}},
+			expected: `unknown unit "parsec"`,
+		},
+		{
+			name:     "incompatible units",
+			plugin:   &Units{Conversions: []conversion{{Fields: []string{"a"}, From: "B", To: "ms"}}},
+			expected: `cannot convert "B" to "ms"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	now := time.Now()
+	inputRaw := []telegraf.Metric{
+		metric.New("m", nil, map[string]interface{}{"size_bytes": int64(1024)}, now),
+		metric.New("m", nil, map[string]interface{}{"value": 42}, now),
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	plugin := &Units{Rename: true, Detect: map[string]string{"data": "KiB"}}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 2)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
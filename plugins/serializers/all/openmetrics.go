@@ -0,0 +1,7 @@
+//go:build !custom || serializers || serializers.openmetrics
+
+package all
+
+import (
+	_ "github.com/influxdata/telegraf/plugins/serializers/openmetrics" // register plugin
+)
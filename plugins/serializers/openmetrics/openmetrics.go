@@ -0,0 +1,147 @@
+package openmetrics
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+)
+
+type Serializer struct {
+	ExportTimestamp bool                   `toml:"openmetrics_export_timestamp"`
+	SortMetrics     bool                   `toml:"openmetrics_sort_metrics"`
+	StringAsLabel   bool                   `toml:"openmetrics_string_as_label"`
+	CompactEncoding bool                   `toml:"openmetrics_compact_encoding"`
+	CreatedLines    bool                   `toml:"openmetrics_created_lines"`
+	ExemplarTags    []string               `toml:"openmetrics_exemplar_tags"`
+	Units           map[string][]string    `toml:"openmetrics_units"`
+	TypeMappings    prometheus.MetricTypes `toml:"openmetrics_metric_types"`
+
+	config      prometheus.FormatConfig
+	unitFilters map[string]filter.Filter
+	options     []expfmt.EncoderOption
+
+	// time the series was first serialized used for the created timestamps
+	created map[string]time.Time
+	sync.Mutex
+}
+
+func (s *Serializer) Init() error {
+	if err := s.TypeMappings.Init(); err != nil {
+		return err
+	}
+
+	s.unitFilters = make(map[string]filter.Filter, len(s.Units))
+	for unit, patterns := range s.Units {
+		f, err := filter.Compile(patterns)
+		if err != nil {
+			return fmt.Errorf("creating filter for unit %q failed: %w", unit, err)
+		}
+		s.unitFilters[unit] = f
+	}
+
+	s.config = prometheus.FormatConfig{
+		ExportTimestamp: s.ExportTimestamp,
+		SortMetrics:     s.SortMetrics,
+		StringAsLabel:   s.StringAsLabel,
+		CompactEncoding: s.CompactEncoding,
+		TypeMappings:    s.TypeMappings,
+		ExemplarTags:    s.ExemplarTags,
+	}
+
+	s.options = []expfmt.EncoderOption{expfmt.WithUnit()}
+	if s.CreatedLines {
+		s.options = append(s.options, expfmt.WithCreatedLines())
+		s.created = make(map[string]time.Time)
+	}
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	coll := prometheus.NewCollection(s.config)
+	for _, metric := range metrics {
+		coll.Add(metric, now)
+	}
+
+	var buf bytes.Buffer
+	for _, mf := range coll.GetProto() {
+		// OpenMetrics requires counters to end in "_total", otherwise the
+		// encoder falls back to the "unknown" type.
+		if mf.GetType() == dto.MetricType_COUNTER && !strings.HasSuffix(mf.GetName(), "_total") {
+			mf.Name = proto.String(mf.GetName() + "_total")
+		}
+		for unit, f := range s.unitFilters {
+			if f.Match(mf.GetName()) {
+				mf.Unit = proto.String(unit)
+				break
+			}
+		}
+		if s.CreatedLines {
+			s.setCreated(mf, now)
+		}
+
+		if _, err := expfmt.MetricFamilyToOpenMetrics(&buf, mf, s.options...); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := expfmt.FinalizeOpenMetrics(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// setCreated sets the created timestamp of all counters, histograms and
+// summaries of the family to the time the series was first serialized
+func (s *Serializer) setCreated(mf *dto.MetricFamily, now time.Time) {
+	for _, m := range mf.Metric {
+		var key strings.Builder
+		key.WriteString(mf.GetName())
+		for _, l := range m.Label {
+			key.WriteString("\x00" + l.GetName() + "\x00" + l.GetValue())
+		}
+		created, found := s.created[key.String()]
+		if !found {
+			created = now
+			s.created[key.String()] = created
+		}
+
+		ts := timestamppb.New(created)
+		switch {
+		case m.Counter != nil:
+			m.Counter.CreatedTimestamp = ts
+		case m.Histogram != nil:
+			m.Histogram.CreatedTimestamp = ts
+		case m.Summary != nil:
+			m.Summary.CreatedTimestamp = ts
+		}
+	}
+}
+
+func init() {
+	serializers.Add("openmetrics",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
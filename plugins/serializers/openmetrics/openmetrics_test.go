@@ -0,0 +1,205 @@
+package openmetrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSerialize(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer *Serializer
+		metrics    []telegraf.Metric
+		expected   string
+	}{
+		{
+			name:       "gauge",
+			serializer: &Serializer{},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{"host": "example.org"},
+					map[string]interface{}{"time_idle": 42.0},
+					time.Unix(0, 0),
+					telegraf.Gauge,
+				),
+			},
+			expected: `
+# HELP cpu_time_idle Telegraf collected metric
+# TYPE cpu_time_idle gauge
+cpu_time_idle{host="example.org"} 42.0
+# EOF
+`,
+		},
+		{
+			name:       "counter with total suffix",
+			serializer: &Serializer{CompactEncoding: true},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"http",
+					map[string]string{"code": "200"},
+					map[string]interface{}{"requests": 3.0},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+			},
+			expected: `
+# TYPE http_requests counter
+http_requests_total{code="200"} 3.0
+# EOF
+`,
+		},
+		{
+			name: "unit",
+			serializer: &Serializer{
+				CompactEncoding: true,
+				Units:           map[string][]string{"seconds": {"*_duration"}},
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"http",
+					map[string]string{"code": "200"},
+					map[string]interface{}{"duration": 0.5},
+					time.Unix(0, 0),
+					telegraf.Gauge,
+				),
+			},
+			expected: `
+# TYPE http_duration_seconds gauge
+# UNIT http_duration_seconds seconds
+http_duration_seconds{code="200"} 0.5
+# EOF
+`,
+		},
+		{
+			name: "exemplar",
+			serializer: &Serializer{
+				CompactEncoding: true,
+				ExemplarTags:    []string{"trace_id"},
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"http",
+					map[string]string{"code": "200", "trace_id": "abc123"},
+					map[string]interface{}{"requests_total": 3.0},
+					time.Unix(1700000000, 0),
+					telegraf.Counter,
+				),
+			},
+			expected: `
+# TYPE http_requests counter
+http_requests_total{code="200"} 3.0 # {trace_id="abc123"} 3.0 1.7e+09
+# EOF
+`,
+		},
+		{
+			name:       "histogram",
+			serializer: &Serializer{CompactEncoding: true},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"prometheus",
+					map[string]string{"le": "0.5"},
+					map[string]interface{}{"latency_bucket": 2.0},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				testutil.MustMetric(
+					"prometheus",
+					map[string]string{"le": "+Inf"},
+					map[string]interface{}{"latency_bucket": 3.0},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				testutil.MustMetric(
+					"prometheus",
+					map[string]string{},
+					map[string]interface{}{
+						"latency_sum":   1.5,
+						"latency_count": 3.0,
+					},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+			},
+			expected: `
+# TYPE latency histogram
+latency_bucket{le="0.5"} 2
+latency_bucket{le="+Inf"} 3
+latency_sum 1.5
+latency_count 3
+# EOF
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.serializer.SortMetrics = true
+			require.NoError(t, tt.serializer.Init())
+			actual, err := tt.serializer.SerializeBatch(tt.metrics)
+			require.NoError(t, err)
+			require.Equal(t, strings.TrimSpace(tt.expected), strings.TrimSpace(string(actual)))
+		})
+	}
+}
+
+func TestCreatedLines(t *testing.T) {
+	serializer := &Serializer{
+		CompactEncoding: true,
+		CreatedLines:    true,
+	}
+	require.NoError(t, serializer.Init())
+
+	m := testutil.MustMetric(
+		"http",
+		map[string]string{"code": "200"},
+		map[string]interface{}{"requests_total": 3.0},
+		time.Unix(0, 0),
+		telegraf.Counter,
+	)
+
+	first, err := serializer.Serialize(m)
+	require.NoError(t, err)
+	require.Contains(t, string(first), `http_requests_created{code="200"}`)
+
+	// The created timestamp must stay the same for the series
+	second, err := serializer.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, string(first), string(second))
+}
+
+func TestInvalidUnitFilter(t *testing.T) {
+	serializer := &Serializer{
+		Units: map[string][]string{"seconds": {"a[b"}},
+	}
+	require.ErrorContains(t, serializer.Init(), "creating filter for unit")
+}
+
+func BenchmarkSerialize(b *testing.B) {
+	s := &Serializer{}
+	require.NoError(b, s.Init())
+	metrics := serializers.BenchmarkMetrics(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := s.Serialize(metrics[i%len(metrics)])
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkSerializeBatch(b *testing.B) {
+	s := &Serializer{}
+	require.NoError(b, s.Init())
+	m := serializers.BenchmarkMetrics(b)
+	metrics := m[:]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := s.SerializeBatch(metrics)
+		require.NoError(b, err)
+	}
+}
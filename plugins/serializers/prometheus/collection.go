@@ -9,8 +9,10 @@ import (
 
 	dto "github.com/prometheus/client_model/go"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
 )
 
 const helpString = "Telegraf collected metric"
@@ -29,6 +31,7 @@ type Metric struct {
 	Scaler    *scaler
 	Histogram *histogram
 	Summary   *summary
+	Exemplar  *exemplar
 }
 
 type labelPair struct {
@@ -40,6 +43,12 @@ type scaler struct {
 	Value float64
 }
 
+type exemplar struct {
+	Labels []labelPair
+	Value  float64
+	Time   time.Time
+}
+
 type bucket struct {
 	Bound float64
 	Count uint64
@@ -137,6 +146,11 @@ func (c *Collection) createLabels(metric telegraf.Metric) []labelPair {
 			}
 		}
 
+		// Exemplar tags are not part of the series
+		if choice.Contains(tag.Key, c.config.ExemplarTags) {
+			continue
+		}
+
 		name, ok := SanitizeLabelName(tag.Key)
 		if !ok {
 			continue
@@ -180,8 +194,25 @@ func (c *Collection) createLabels(metric telegraf.Metric) []labelPair {
 	return labels
 }
 
+func (c *Collection) createExemplarLabels(metric telegraf.Metric) []labelPair {
+	var labels []labelPair
+	for _, key := range c.config.ExemplarTags {
+		value, ok := metric.GetTag(key)
+		if !ok {
+			continue
+		}
+		name, ok := SanitizeLabelName(key)
+		if !ok {
+			continue
+		}
+		labels = append(labels, labelPair{Name: name, Value: value})
+	}
+	return labels
+}
+
 func (c *Collection) Add(metric telegraf.Metric, now time.Time) {
 	labels := c.createLabels(metric)
+	exemplarLabels := c.createExemplarLabels(metric)
 	for _, field := range metric.FieldList() {
 		metricName := MetricName(metric.Name(), field.Key, metric.Type())
 		metricName, ok := SanitizeMetricName(metricName)
@@ -233,6 +264,13 @@ func (c *Collection) Add(metric telegraf.Metric, now time.Time) {
 				AddTime: now,
 				Scaler:  &scaler{Value: value},
 			}
+			if len(exemplarLabels) > 0 {
+				m.Exemplar = &exemplar{
+					Labels: exemplarLabels,
+					Value:  value,
+					Time:   metric.Time(),
+				}
+			}
 
 			singleEntry.Metrics[metricKey] = m
 		case telegraf.Histogram:
@@ -442,6 +480,20 @@ func (c *Collection) GetProto() []*dto.MetricFamily {
 				m.Gauge = &dto.Gauge{Value: proto.Float64(metric.Scaler.Value)}
 			case telegraf.Counter:
 				m.Counter = &dto.Counter{Value: proto.Float64(metric.Scaler.Value)}
+				if metric.Exemplar != nil {
+					l := make([]*dto.LabelPair, 0, len(metric.Exemplar.Labels))
+					for _, label := range metric.Exemplar.Labels {
+						l = append(l, &dto.LabelPair{
+							Name:  proto.String(label.Name),
+							Value: proto.String(label.Value),
+						})
+					}
+					m.Counter.Exemplar = &dto.Exemplar{
+						Label:     l,
+						Value:     proto.Float64(metric.Exemplar.Value),
+						Timestamp: timestamppb.New(metric.Exemplar.Time),
+					}
+				}
 			case telegraf.Untyped:
 				m.Untyped = &dto.Untyped{Value: proto.Float64(metric.Scaler.Value)}
 			case telegraf.Histogram:
@@ -54,6 +54,9 @@ type FormatConfig struct {
 	// helps to reduce payload size.
 	CompactEncoding bool        `toml:"prometheus_compact_encoding"`
 	TypeMappings    MetricTypes `toml:"prometheus_metric_types"`
+	// ExemplarTags defines the tags to use as exemplar labels for counters
+	// instead of metric labels. Exemplars are only supported by OpenMetrics.
+	ExemplarTags []string `toml:"-"`
 }
 
 type Serializer struct {